@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mutagen-io/mutagen/cmd"
+
+	"github.com/mutagen-io/mutagen/pkg/agent"
+	"github.com/mutagen-io/mutagen/pkg/mutagen"
+)
+
+// benchmarkMain is the entry point for the benchmark command.
+func benchmarkMain(_ *cobra.Command, _ []string) error {
+	// Create a channel to track termination signals. We do this before creating
+	// and starting other infrastructure so that we can ensure things terminate
+	// smoothly, not mid-initialization.
+	signalTermination := make(chan os.Signal, 1)
+	signal.Notify(signalTermination, cmd.TerminationSignals...)
+
+	// Create a connection on standard input/output.
+	connection := newStdioConnection()
+
+	// Perform an agent handshake.
+	if err := agent.ServerHandshake(connection); err != nil {
+		return errors.Wrap(err, "server handshake failed")
+	}
+
+	// Perform a version handshake.
+	if err := mutagen.ServerVersionHandshake(connection); err != nil {
+		return errors.Wrap(err, "version handshake error")
+	}
+
+	// Serve a benchmark on standard input/output and monitor for its
+	// termination. Temporary data is staged in the default temporary
+	// directory.
+	benchmarkTermination := make(chan error, 1)
+	go func() {
+		benchmarkTermination <- agent.ServeBenchmark(connection, "")
+	}()
+
+	// Wait for termination from a signal or the benchmark.
+	select {
+	case sig := <-signalTermination:
+		return errors.Errorf("terminated by signal: %s", sig)
+	case err := <-benchmarkTermination:
+		return errors.Wrap(err, "benchmark failed")
+	}
+}
+
+// benchmarkCommand is the benchmark command.
+var benchmarkCommand = &cobra.Command{
+	Use:          agent.ModeBenchmark,
+	Short:        "Run the agent in transport benchmark mode",
+	Args:         cmd.DisallowArguments,
+	RunE:         benchmarkMain,
+	SilenceUsage: true,
+}
+
+// benchmarkConfiguration stores configuration for the benchmark command.
+var benchmarkConfiguration struct {
+	// help indicates whether or not to show help information and exit.
+	help bool
+}
+
+func init() {
+	// Grab a handle for the command line flags.
+	flags := benchmarkCommand.Flags()
+
+	// Manually add a help flag to override the default message. Cobra will
+	// still implement its logic automatically.
+	flags.BoolVarP(&benchmarkConfiguration.help, "help", "h", false, "Show help information")
+}
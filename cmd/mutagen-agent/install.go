@@ -12,7 +12,7 @@ import (
 
 // installMain is the entry point for the install command.
 func installMain(_ *cobra.Command, _ []string) error {
-	return errors.Wrap(agent.Install(), "installation error")
+	return errors.Wrap(agent.Install(installConfiguration.expectedDigest), "installation error")
 }
 
 // installCommand is the install command.
@@ -28,6 +28,8 @@ var installCommand = &cobra.Command{
 var installConfiguration struct {
 	// help indicates whether or not to show help information and exit.
 	help bool
+	// expectedDigest is the expected SHA-256 digest of the agent executable.
+	expectedDigest string
 }
 
 func init() {
@@ -37,4 +39,7 @@ func init() {
 	// Manually add a help flag to override the default message. Cobra will
 	// still implement its logic automatically.
 	flags.BoolVarP(&installConfiguration.help, "help", "h", false, "Show help information")
+
+	// Wire up installation flags.
+	flags.StringVar(&installConfiguration.expectedDigest, agent.FlagExpectedDigest, "", "Verify that the agent executable has the specified SHA-256 digest before installing")
 }
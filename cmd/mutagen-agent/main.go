@@ -4,7 +4,11 @@ import (
 	"log"
 	"os"
 
+	"github.com/pkg/errors"
+
 	"github.com/spf13/cobra"
+
+	"github.com/mutagen-io/mutagen/pkg/agent"
 )
 
 func init() {
@@ -63,14 +67,20 @@ func init() {
 		installCommand,
 		synchronizerCommand,
 		forwarderCommand,
+		benchmarkCommand,
 		versionCommand,
 		legalCommand,
 	)
 }
 
 func main() {
-	// Execute the root command.
+	// Execute the root command. Termination due to exceeding a memory limit is
+	// signaled with a dedicated exit code so that it can be reported clearly.
 	if err := rootCommand.Execute(); err != nil {
+		var limitErr *agent.MemoryLimitError
+		if errors.As(err, &limitErr) {
+			os.Exit(agent.MemoryLimitExitCode)
+		}
 		os.Exit(1)
 	}
 }
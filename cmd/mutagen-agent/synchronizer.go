@@ -61,6 +61,12 @@ func synchronizerMain(_ *cobra.Command, _ []string) error {
 	defer cancel()
 	go housekeepRegularly(ctx, logging.RootLogger.Sublogger("housekeeping"))
 
+	// Enforce any resource limits communicated by the agent's launcher.
+	limitTermination, err := agent.EnforceResourceLimits(ctx)
+	if err != nil {
+		return errors.Wrap(err, "unable to enforce resource limits")
+	}
+
 	// Create a connection on standard input/output.
 	connection := newStdioConnection()
 
@@ -84,10 +90,13 @@ func synchronizerMain(_ *cobra.Command, _ []string) error {
 		)
 	}()
 
-	// Wait for termination from a signal or the synchronizer.
+	// Wait for termination from a signal, the synchronizer, or a resource
+	// limit violation.
 	select {
 	case sig := <-signalTermination:
 		return errors.Errorf("terminated by signal: %s", sig)
+	case err := <-limitTermination:
+		return err
 	case err := <-synchronizationTermination:
 		return errors.Wrap(err, "synchronization terminated")
 	}
@@ -212,7 +212,7 @@ func reconcileSessions(project *compose.Project) error {
 	if len(sessionsToFlush) > 0 {
 		fmt.Println("Performing initial synchronization")
 		flushSelection := &selection.Selection{Specifications: sessionsToFlush}
-		if err := sync.FlushWithSelection(daemonConnection, flushSelection, false); err != nil {
+		if err := sync.FlushWithSelection(daemonConnection, flushSelection, false, nil, false, false); err != nil {
 			return fmt.Errorf("unable to flush synchronization session(s): %w", err)
 		}
 	}
@@ -49,6 +49,7 @@ func init() {
 		runCommand,
 		startCommand,
 		stopCommand,
+		transportsCommand,
 	}
 	if daemon.RegistrationSupported {
 		supportedCommands = append(supportedCommands,
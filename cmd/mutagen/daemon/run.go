@@ -3,6 +3,7 @@ package daemon
 import (
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -12,29 +13,91 @@ import (
 
 	"github.com/mutagen-io/mutagen/cmd"
 
+	"github.com/mutagen-io/mutagen/pkg/agent"
+	"github.com/mutagen-io/mutagen/pkg/configuration/global"
 	"github.com/mutagen-io/mutagen/pkg/daemon"
 	"github.com/mutagen-io/mutagen/pkg/forwarding"
 	"github.com/mutagen-io/mutagen/pkg/grpcutil"
 	"github.com/mutagen-io/mutagen/pkg/ipc"
+	"github.com/mutagen-io/mutagen/pkg/limiting"
 	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/notification"
 	daemonsvc "github.com/mutagen-io/mutagen/pkg/service/daemon"
 	forwardingsvc "github.com/mutagen-io/mutagen/pkg/service/forwarding"
 	promptingsvc "github.com/mutagen-io/mutagen/pkg/service/prompting"
 	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
 	tunnelingsvc "github.com/mutagen-io/mutagen/pkg/service/tunneling"
 	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/endpoint/local"
 	"github.com/mutagen-io/mutagen/pkg/tunneling"
 
 	_ "github.com/mutagen-io/mutagen/pkg/forwarding/protocols/docker"
 	_ "github.com/mutagen-io/mutagen/pkg/forwarding/protocols/local"
 	_ "github.com/mutagen-io/mutagen/pkg/forwarding/protocols/ssh"
 	tunnelforwardingprotocol "github.com/mutagen-io/mutagen/pkg/forwarding/protocols/tunnel"
+	_ "github.com/mutagen-io/mutagen/pkg/forwarding/protocols/wsl"
 	_ "github.com/mutagen-io/mutagen/pkg/synchronization/protocols/docker"
 	_ "github.com/mutagen-io/mutagen/pkg/synchronization/protocols/local"
 	_ "github.com/mutagen-io/mutagen/pkg/synchronization/protocols/ssh"
 	tunnelsynchronizationprotocol "github.com/mutagen-io/mutagen/pkg/synchronization/protocols/tunnel"
+	_ "github.com/mutagen-io/mutagen/pkg/synchronization/protocols/wsl"
 )
 
+// loadGlobalConfiguration loads the global configuration file. It returns an
+// empty configuration if the global configuration file doesn't exist.
+func loadGlobalConfiguration() (*global.Configuration, error) {
+	// Compute the path to the global configuration file.
+	path, err := global.ConfigurationPath()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to compute path to global configuration file")
+	}
+
+	// Load the global configuration, if present.
+	configuration, err := global.LoadConfiguration(path)
+	if os.IsNotExist(err) {
+		return &global.Configuration{}, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "unable to load global configuration")
+	}
+
+	// Success.
+	return configuration, nil
+}
+
+// newNotifier creates a notifier using the webhook configuration specified in
+// the global configuration. It returns a nil notifier if the global
+// configuration doesn't specify any webhooks.
+func newNotifier(configuration *global.Configuration) (*notification.Notifier, error) {
+	return notification.NewNotifier(
+		logging.RootLogger.Sublogger("notification"),
+		configuration.Notifications.Webhooks,
+		time.Duration(configuration.Notifications.DebounceInterval)*time.Second,
+	)
+}
+
+// newHandshakeRetryPolicy creates an agent handshake retry policy using the
+// agent configuration specified in the global configuration, falling back to
+// the default policy for any unspecified settings.
+func newHandshakeRetryPolicy(configuration *global.Configuration) *agent.HandshakeRetryPolicy {
+	// Start with the default policy.
+	policy := agent.DefaultHandshakeRetryPolicy()
+
+	// Apply the retry count.
+	if configuration.Agent.DisableHandshakeRetries {
+		policy.Retries = 0
+	} else if configuration.Agent.HandshakeRetries != 0 {
+		policy.Retries = int(configuration.Agent.HandshakeRetries)
+	}
+
+	// Apply the retry delay.
+	if configuration.Agent.HandshakeRetryDelay != 0 {
+		policy.Delay = time.Duration(configuration.Agent.HandshakeRetryDelay) * time.Millisecond
+	}
+
+	// Done.
+	return policy
+}
+
 // runMain is the entry point for the run command.
 func runMain(_ *cobra.Command, _ []string) error {
 	// Attempt to acquire the daemon lock and defer its release.
@@ -50,6 +113,31 @@ func runMain(_ *cobra.Command, _ []string) error {
 	signalTermination := make(chan os.Signal, 1)
 	signal.Notify(signalTermination, cmd.TerminationSignals...)
 
+	// Load the global configuration (if any).
+	configuration, err := loadGlobalConfiguration()
+	if err != nil {
+		return err
+	}
+
+	// Configure connection establishment limits. This must be done before any
+	// managers are created, since they may immediately establish connections.
+	limiting.ConfigureEstablishmentLimiter(
+		int(configuration.Connections.Limit),
+		int(configuration.Connections.LimitPerHost),
+	)
+
+	// Configure the agent handshake retry policy.
+	agent.ConfigureHandshakeRetryPolicy(newHandshakeRetryPolicy(configuration))
+
+	// Configure the agent upgrade rate limit.
+	agent.ConfigureUpgradeRate(int(configuration.Agent.UpgradeRate))
+
+	// Configure the staging buffer budget for locally hosted endpoints.
+	local.ConfigureStagingBufferBudget(uint64(configuration.Synchronization.StagingBufferBudget))
+
+	// Configure the bandwidth budget for staging transfers.
+	synchronization.ConfigureTransferBandwidthBudget(uint64(configuration.Synchronization.TransferBandwidthBudget))
+
 	// Create a tunnel manager and defer its shutdown.
 	tunnelManager, err := tunneling.NewManager(logging.RootLogger.Sublogger("tunneling"))
 	if err != nil {
@@ -69,8 +157,20 @@ func runMain(_ *cobra.Command, _ []string) error {
 	}
 	defer forwardingManager.Shutdown()
 
+	// Create a notifier based on the global configuration and defer its
+	// shutdown.
+	notifier, err := newNotifier(configuration)
+	if err != nil {
+		return errors.Wrap(err, "unable to create notifier")
+	}
+	defer notifier.Shutdown()
+
 	// Create a synchronization session manager and defer its shutdown.
-	synchronizationManager, err := synchronization.NewManager(logging.RootLogger.Sublogger("synchronization"))
+	synchronizationManager, err := synchronization.NewManager(
+		logging.RootLogger.Sublogger("synchronization"),
+		notifier,
+		configuration.Synchronization.MaximumActiveSessions,
+	)
 	if err != nil {
 		return errors.Wrap(err, "unable to create synchronization session manager")
 	}
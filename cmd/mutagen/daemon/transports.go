@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mutagen-io/mutagen/cmd"
+
+	"github.com/mutagen-io/mutagen/pkg/agent/transports"
+	"github.com/mutagen-io/mutagen/pkg/grpcutil"
+	daemonsvc "github.com/mutagen-io/mutagen/pkg/service/daemon"
+)
+
+// transportsMain is the entry point for the transports command.
+func transportsMain(_ *cobra.Command, _ []string) error {
+	// Connect to the daemon and defer closure of the connection.
+	daemonConnection, err := Connect(true, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to daemon")
+	}
+	defer daemonConnection.Close()
+
+	// Create a daemon service client.
+	daemonService := daemonsvc.NewDaemonClient(daemonConnection)
+
+	// Probe transport availability.
+	response, err := daemonService.Transports(context.Background(), &daemonsvc.TransportsRequest{})
+	if err != nil {
+		return grpcutil.PeelAwayRPCErrorLayer(err)
+	}
+
+	// Print the results.
+	for _, availability := range response.Availability {
+		status := transports.AvailabilityStatus(availability.Status).Description()
+		if availability.Reason != "" {
+			fmt.Printf("%s: %s (%s)\n", availability.Transport, status, availability.Reason)
+		} else {
+			fmt.Printf("%s: %s\n", availability.Transport, status)
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// transportsCommand is the transports command.
+var transportsCommand = &cobra.Command{
+	Use:          "transports",
+	Short:        "Show the availability of agent transports on the daemon's host",
+	Args:         cmd.DisallowArguments,
+	RunE:         transportsMain,
+	SilenceUsage: true,
+}
+
+// transportsConfiguration stores configuration for the transports command.
+var transportsConfiguration struct {
+	// help indicates whether or not to show help information and exit.
+	help bool
+}
+
+func init() {
+	// Grab a handle for the command line flags.
+	flags := transportsCommand.Flags()
+
+	// Disable alphabetical sorting of flags in help output.
+	flags.SortFlags = false
+
+	// Manually add a help flag to override the default message. Cobra will
+	// still implement its logic automatically.
+	flags.BoolVarP(&transportsConfiguration.help, "help", "h", false, "Show help information")
+}
@@ -109,7 +109,7 @@ func flushMain(_ *cobra.Command, _ []string) error {
 	}
 
 	// Flush synchronization sessions.
-	if err := sync.FlushWithSelection(daemonConnection, selection, flushConfiguration.skipWait); err != nil {
+	if err := sync.FlushWithSelection(daemonConnection, selection, flushConfiguration.skipWait, nil, false, false); err != nil {
 		return errors.Wrap(err, "unable to flush synchronization session(s)")
 	}
 
@@ -7,6 +7,7 @@ import (
 	_ "github.com/mutagen-io/mutagen/pkg/forwarding/protocols/docker"
 	_ "github.com/mutagen-io/mutagen/pkg/forwarding/protocols/local"
 	_ "github.com/mutagen-io/mutagen/pkg/forwarding/protocols/ssh"
+	_ "github.com/mutagen-io/mutagen/pkg/forwarding/protocols/wsl"
 )
 
 // projectMain is the entry point for the project command.
@@ -0,0 +1,135 @@
+package sync
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/mutagen-io/mutagen/pkg/agent"
+	"github.com/mutagen-io/mutagen/pkg/agent/transports/docker"
+	"github.com/mutagen-io/mutagen/pkg/agent/transports/ssh"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	sshpkg "github.com/mutagen-io/mutagen/pkg/ssh"
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// benchmarkTransport creates an agent transport for the specified URL.
+// Prompting is performed directly on the terminal (if required by the
+// transport), since no prompter is registered.
+func benchmarkTransport(target *url.URL) (agent.Transport, error) {
+	switch target.Protocol {
+	case url.Protocol_SSH:
+		if len(target.Environment) > 0 {
+			return nil, errors.New("SSH URL contains environment variables")
+		}
+		options, err := sshpkg.LoadOptionsFromURLParameters(target.Parameters)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid SSH URL parameters")
+		}
+		if target.Port != 0 {
+			options.Port = target.Port
+		}
+		return ssh.NewTransport(target.User, target.Host, options, "", false, nil, "")
+	case url.Protocol_Docker:
+		return docker.NewTransport(target.Host, target.User, target.Environment, target.Parameters, "", nil)
+	default:
+		return nil, errors.New("URL does not use an agent-based transport")
+	}
+}
+
+// benchmarkMain is the entry point for the benchmark command.
+func benchmarkMain(_ *cobra.Command, arguments []string) error {
+	// Validate, extract, and parse the URL.
+	if len(arguments) != 1 {
+		return errors.New("a single URL must be specified")
+	}
+	target, err := url.Parse(arguments[0], url.Kind_Synchronization, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse URL")
+	}
+
+	// Parse the data size.
+	size := uint64(agent.DefaultBenchmarkSize)
+	if benchmarkConfiguration.size != "" {
+		if s, err := humanize.ParseBytes(benchmarkConfiguration.size); err != nil {
+			return errors.Wrap(err, "unable to parse data size")
+		} else {
+			size = s
+		}
+	}
+
+	// Create the transport.
+	transport, err := benchmarkTransport(target)
+	if err != nil {
+		return errors.Wrap(err, "unable to create transport")
+	}
+
+	// Run the benchmark.
+	result, err := agent.Benchmark(
+		logging.RootLogger.Sublogger("benchmark"),
+		transport, "",
+		size, benchmarkConfiguration.probes,
+	)
+	if err != nil {
+		return errors.Wrap(err, "benchmark failed")
+	}
+
+	// Print the results.
+	formatDuration := func(duration time.Duration) string {
+		return duration.Round(time.Microsecond).String()
+	}
+	formatThroughput := func(bytesPerSecond float64) string {
+		return humanize.Bytes(uint64(bytesPerSecond)) + "/s"
+	}
+	fmt.Println("Data size:", humanize.Bytes(result.Size))
+	fmt.Printf("Upload: %s (%s)\n", formatThroughput(result.UploadThroughput()), formatDuration(result.UploadDuration))
+	fmt.Printf("Download: %s (%s)\n", formatThroughput(result.DownloadThroughput()), formatDuration(result.DownloadDuration))
+	fmt.Printf("Round-trip latency: %s minimum, %s average, %s maximum\n",
+		formatDuration(result.MinimumLatency),
+		formatDuration(result.AverageLatency),
+		formatDuration(result.MaximumLatency),
+	)
+
+	// Success.
+	return nil
+}
+
+// benchmarkCommand is the benchmark command.
+var benchmarkCommand = &cobra.Command{
+	Use:          "benchmark <url>",
+	Short:        "Measure the throughput and latency of an endpoint's transport",
+	RunE:         benchmarkMain,
+	SilenceUsage: true,
+}
+
+// benchmarkConfiguration stores configuration for the benchmark command.
+var benchmarkConfiguration struct {
+	// help indicates whether or not to show help information and exit.
+	help bool
+	// size is the amount of data to transfer in each direction, in a
+	// human-friendly format.
+	size string
+	// probes is the number of round-trip latency probes to perform.
+	probes uint32
+}
+
+func init() {
+	// Grab a handle for the command line flags.
+	flags := benchmarkCommand.Flags()
+
+	// Disable alphabetical sorting of flags in help output.
+	flags.SortFlags = false
+
+	// Manually add a help flag to override the default message. Cobra will
+	// still implement its logic automatically.
+	flags.BoolVarP(&benchmarkConfiguration.help, "help", "h", false, "Show help information")
+
+	// Wire up benchmark flags.
+	flags.StringVar(&benchmarkConfiguration.size, "size", "", "Specify the amount of data to transfer in each direction")
+	flags.Uint32Var(&benchmarkConfiguration.probes, "probes", agent.DefaultBenchmarkProbes, "Specify the number of round-trip latency probes")
+}
@@ -0,0 +1,181 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mutagen-io/mutagen/cmd"
+	"github.com/mutagen-io/mutagen/cmd/mutagen/daemon"
+
+	"github.com/mutagen-io/mutagen/pkg/grpcutil"
+	promptingsvc "github.com/mutagen-io/mutagen/pkg/service/prompting"
+	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// printComparisonPaths prints a list of paths under the specified heading, if
+// non-empty.
+func printComparisonPaths(heading string, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	fmt.Printf("%s:\n", heading)
+	for _, path := range paths {
+		fmt.Printf("\t%s\n", formatPath(path))
+	}
+}
+
+// compareMain is the entry point for the compare command.
+func compareMain(_ *cobra.Command, arguments []string) error {
+	// Validate, extract, and parse URLs.
+	if len(arguments) != 2 {
+		return errors.New("two URLs must be specified")
+	}
+	alpha, err := url.Parse(arguments[0], url.Kind_Synchronization, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse alpha URL")
+	}
+	beta, err := url.Parse(arguments[1], url.Kind_Synchronization, false)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse beta URL")
+	}
+
+	// Load the base configuration.
+	configuration, err := loadConfiguration(
+		compareConfiguration.noGlobalConfiguration,
+		compareConfiguration.configurationFile,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Validate ignore specifications.
+	for _, ignore := range compareConfiguration.ignores {
+		if !core.ValidIgnorePattern(ignore) {
+			return errors.Errorf("invalid ignore pattern: %s", ignore)
+		}
+	}
+
+	// Validate and convert the VCS ignore mode specification.
+	var ignoreVCSMode core.IgnoreVCSMode
+	if compareConfiguration.ignoreVCS && compareConfiguration.noIgnoreVCS {
+		return errors.New("conflicting VCS ignore behavior specified")
+	} else if compareConfiguration.ignoreVCS {
+		ignoreVCSMode = core.IgnoreVCSMode_IgnoreVCSModeIgnore
+	} else if compareConfiguration.noIgnoreVCS {
+		ignoreVCSMode = core.IgnoreVCSMode_IgnoreVCSModePropagate
+	}
+
+	// Merge command line ignore configuration.
+	configuration = synchronization.MergeConfigurations(configuration, &synchronization.Configuration{
+		Ignores:       compareConfiguration.ignores,
+		IgnoreVCSMode: ignoreVCSMode,
+	})
+
+	// Connect to the daemon and defer closure of the connection.
+	daemonConnection, err := daemon.Connect(true, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to daemon")
+	}
+	defer daemonConnection.Close()
+
+	// Initiate command line prompting.
+	statusLinePrinter := &cmd.StatusLinePrinter{}
+	promptingCtx, promptingCancel := context.WithCancel(context.Background())
+	prompter, promptingErrors, err := promptingsvc.Host(
+		promptingCtx, promptingsvc.NewPromptingClient(daemonConnection),
+		&cmd.StatusLinePrompter{Printer: statusLinePrinter}, true,
+	)
+	if err != nil {
+		promptingCancel()
+		return errors.Wrap(err, "unable to initiate prompting")
+	}
+
+	// Perform the compare operation, cancel prompting, and handle errors.
+	synchronizationService := synchronizationsvc.NewSynchronizationClient(daemonConnection)
+	request := &synchronizationsvc.CompareRequest{
+		Prompter:           prompter,
+		Alpha:              alpha,
+		Beta:               beta,
+		Configuration:      configuration,
+		ConfigurationAlpha: &synchronization.Configuration{},
+		ConfigurationBeta:  &synchronization.Configuration{},
+	}
+	response, err := synchronizationService.Compare(context.Background(), request)
+	promptingCancel()
+	<-promptingErrors
+	if err != nil {
+		statusLinePrinter.BreakIfNonEmpty()
+		return grpcutil.PeelAwayRPCErrorLayer(err)
+	} else if err = response.EnsureValid(); err != nil {
+		statusLinePrinter.BreakIfNonEmpty()
+		return errors.Wrap(err, "invalid compare response received")
+	}
+	statusLinePrinter.Clear()
+
+	// Print the differences.
+	if len(response.AlphaOnly) == 0 && len(response.BetaOnly) == 0 &&
+		len(response.ContentDiffers) == 0 && len(response.ModeDiffers) == 0 {
+		fmt.Println("No differences found")
+		return nil
+	}
+	printComparisonPaths("Only on alpha", response.AlphaOnly)
+	printComparisonPaths("Only on beta", response.BetaOnly)
+	printComparisonPaths("Content differs", response.ContentDiffers)
+	printComparisonPaths("Mode differs", response.ModeDiffers)
+
+	// Success.
+	return nil
+}
+
+// compareCommand is the compare command.
+var compareCommand = &cobra.Command{
+	Use:          "compare <alpha> <beta>",
+	Short:        "Report differences between two endpoints without synchronizing them",
+	RunE:         compareMain,
+	SilenceUsage: true,
+}
+
+// compareConfiguration stores configuration for the compare command.
+var compareConfiguration struct {
+	// help indicates whether or not to show help information and exit.
+	help bool
+	// noGlobalConfiguration specifies whether or not the global configuration
+	// file should be ignored.
+	noGlobalConfiguration bool
+	// configurationFile specifies a file from which to load configuration.
+	configurationFile string
+	// ignores is the list of ignore specifications for the comparison.
+	ignores []string
+	// ignoreVCS specifies whether or not to enable VCS ignores.
+	ignoreVCS bool
+	// noIgnoreVCS specifies whether or not to disable VCS ignores.
+	noIgnoreVCS bool
+}
+
+func init() {
+	// Grab a handle for the command line flags.
+	flags := compareCommand.Flags()
+
+	// Disable alphabetical sorting of flags in help output.
+	flags.SortFlags = false
+
+	// Manually add a help flag to override the default message. Cobra will
+	// still implement its logic automatically.
+	flags.BoolVarP(&compareConfiguration.help, "help", "h", false, "Show help information")
+
+	// Wire up configuration flags.
+	flags.BoolVar(&compareConfiguration.noGlobalConfiguration, "no-global-configuration", false, "Ignore the global configuration file")
+	flags.StringVarP(&compareConfiguration.configurationFile, "configuration-file", "c", "", "Specify a file from which to load configuration")
+
+	// Wire up ignore flags.
+	flags.StringSliceVarP(&compareConfiguration.ignores, "ignore", "i", nil, "Specify ignore paths")
+	flags.BoolVar(&compareConfiguration.ignoreVCS, "ignore-vcs", false, "Ignore VCS directories")
+	flags.BoolVar(&compareConfiguration.noIgnoreVCS, "no-ignore-vcs", false, "Propagate VCS directories")
+}
@@ -73,6 +73,85 @@ func loadAndValidateLegacyTOMLConfiguration(path string) (*synchronization.Confi
 	return configuration, nil
 }
 
+// loadConfiguration loads a cumulative synchronization configuration from the
+// global configuration file (unless noGlobalConfiguration is true, falling back
+// to the legacy global configuration file if the global configuration file
+// doesn't exist) and the specified configuration file (if non-empty).
+func loadConfiguration(noGlobalConfiguration bool, configurationFile string) (*synchronization.Configuration, error) {
+	// Create a default session configuration which will form the basis of our
+	// cumulative configuration.
+	configuration := &synchronization.Configuration{}
+
+	// Unless disabled, load configuration from the global configuration file
+	// and merge it into our cumulative configuration.
+	var globalConfigurationNonExistent bool
+	if !noGlobalConfiguration {
+		// Compute the path to the global configuration file.
+		globalConfigurationPath, err := global.ConfigurationPath()
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to compute path to global configuration file")
+		}
+
+		// Attempt to load the file. We allow it to not exist.
+		globalConfiguration, err := loadAndValidateGlobalSynchronizationConfiguration(globalConfigurationPath)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, errors.Wrap(err, "unable to load global configuration")
+			}
+			globalConfigurationNonExistent = true
+		} else {
+			configuration = synchronization.MergeConfigurations(configuration, globalConfiguration)
+		}
+	}
+
+	// If we tried to load the global configuration and it didn't exist, then
+	// try to load the legacy global configuration.
+	if globalConfigurationNonExistent {
+		// Compute the path to the global configuration file.
+		legacyGlobalConfigurationPath, err := legacy.ConfigurationPath()
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to compute path to legacy global configuration file")
+		}
+
+		// Attempt to load the file. We don't require that the legacy global
+		// configuration exist, but if it does (and since the YAML-based global
+		// configuration file didn't exist), then we warn the user about its
+		// deprecation.
+		globalConfiguration, err := loadAndValidateLegacyTOMLConfiguration(legacyGlobalConfigurationPath)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, errors.Wrap(err, "unable to load legacy global configuration")
+			}
+		} else {
+			configuration = synchronization.MergeConfigurations(configuration, globalConfiguration)
+			cmd.Warning("TOML-based global configuration files are deprecated, please migrate to YAML")
+		}
+	}
+
+	// If a configuration file has been specified, then load it and merge it
+	// into our cumulative configuration. We handle its loading based on the
+	// extension, warning if a legacy TOML configuration file is used.
+	if configurationFile != "" {
+		if filepath.Ext(configurationFile) == ".toml" {
+			cmd.Warning("TOML-based configuration files are deprecated, please migrate to YAML")
+			if c, err := loadAndValidateLegacyTOMLConfiguration(configurationFile); err != nil {
+				return nil, errors.Wrap(err, "unable to load legacy configuration file")
+			} else {
+				configuration = synchronization.MergeConfigurations(configuration, c)
+			}
+		} else {
+			if c, err := loadAndValidateGlobalSynchronizationConfiguration(configurationFile); err != nil {
+				return nil, errors.Wrap(err, "unable to load configuration file")
+			} else {
+				configuration = synchronization.MergeConfigurations(configuration, c)
+			}
+		}
+	}
+
+	// Success.
+	return configuration, nil
+}
+
 // CreateWithSpecification is an orchestration convenience method that performs
 // a create operation using the provided daemon connection and session
 // specification.
@@ -117,7 +196,7 @@ func CreateWithSpecification(
 // createMain is the entry point for the create command.
 func createMain(_ *cobra.Command, arguments []string) error {
 	// Validate, extract, and parse URLs.
-	if len(arguments) != 2 {
+	if len(arguments) < 2 {
 		return errors.New("invalid number of endpoint URLs provided")
 	}
 	alpha, err := url.Parse(arguments[0], url.Kind_Synchronization, true)
@@ -128,6 +207,14 @@ func createMain(_ *cobra.Command, arguments []string) error {
 	if err != nil {
 		return errors.Wrap(err, "unable to parse beta URL")
 	}
+	var additionalBetas []*url.URL
+	for _, argument := range arguments[2:] {
+		additionalBeta, err := url.Parse(argument, url.Kind_Synchronization, false)
+		if err != nil {
+			return errors.Wrap(err, "unable to parse additional beta URL")
+		}
+		additionalBetas = append(additionalBetas, additionalBeta)
+	}
 
 	// Validate the name.
 	if err := selection.EnsureNameValid(createConfiguration.name); err != nil {
@@ -154,94 +241,84 @@ func createMain(_ *cobra.Command, arguments []string) error {
 		labels[key] = value
 	}
 
-	// Create a default session configuration which will form the basis of our
-	// cumulative configuration.
-	configuration := &synchronization.Configuration{}
+	// Load the base configuration from the global configuration file (unless
+	// disabled) and any manually specified configuration file.
+	configuration, err := loadConfiguration(
+		createConfiguration.noGlobalConfiguration,
+		createConfiguration.configurationFile,
+	)
+	if err != nil {
+		return err
+	}
 
-	// Unless disabled, load configuration from the global configuration file
-	// and merge it into our cumulative configuration.
-	var globalConfigurationNonExistent bool
-	if !createConfiguration.noGlobalConfiguration {
-		// Compute the path to the global configuration file.
-		globalConfigurationPath, err := global.ConfigurationPath()
-		if err != nil {
-			return errors.Wrap(err, "unable to compute path to global configuration file")
+	// Validate and convert the synchronization mode specification.
+	var synchronizationMode core.SynchronizationMode
+	if createConfiguration.synchronizationMode != "" {
+		if err := synchronizationMode.UnmarshalText([]byte(createConfiguration.synchronizationMode)); err != nil {
+			return errors.Wrap(err, "unable to parse synchronization mode")
 		}
+	}
 
-		// Attempt to load the file. We allow it to not exist.
-		globalConfiguration, err := loadAndValidateGlobalSynchronizationConfiguration(globalConfigurationPath)
-		if err != nil {
-			if !os.IsNotExist(err) {
-				return errors.Wrap(err, "unable to load global configuration")
-			}
-			globalConfigurationNonExistent = true
+	// There's no need to validate the maximum entry count - any uint64 value is
+	// valid.
+
+	// Validate and convert the maximum staging file size.
+	var maximumStagingFileSize uint64
+	if createConfiguration.maximumStagingFileSize != "" {
+		if s, err := humanize.ParseBytes(createConfiguration.maximumStagingFileSize); err != nil {
+			return errors.Wrap(err, "unable to parse maximum staging file size")
 		} else {
-			configuration = synchronization.MergeConfigurations(configuration, globalConfiguration)
+			maximumStagingFileSize = s
 		}
 	}
 
-	// If we tried to load the global configuration and it didn't exist, then
-	// try to load the legacy global configuration.
-	if globalConfigurationNonExistent {
-		// Compute the path to the global configuration file.
-		legacyGlobalConfigurationPath, err := legacy.ConfigurationPath()
-		if err != nil {
-			return errors.Wrap(err, "unable to compute path to legacy global configuration file")
+	// Validate and convert the maximum file size.
+	var maximumFileSize uint64
+	if createConfiguration.maximumFileSize != "" {
+		if s, err := humanize.ParseBytes(createConfiguration.maximumFileSize); err != nil {
+			return errors.Wrap(err, "unable to parse maximum file size")
+		} else {
+			maximumFileSize = s
 		}
+	}
 
-		// Attempt to load the file. We don't require that the legacy global
-		// configuration exist, but if it does (and since the YAML-based global
-		// configuration file didn't exist), then we warn the user about its
-		// deprecation.
-		globalConfiguration, err := loadAndValidateLegacyTOMLConfiguration(legacyGlobalConfigurationPath)
-		if err != nil {
-			if !os.IsNotExist(err) {
-				return errors.Wrap(err, "unable to load legacy global configuration")
-			}
+	// Validate and convert the clone staging threshold.
+	var cloneStagingThreshold uint64
+	if createConfiguration.cloneStagingThreshold != "" {
+		if s, err := humanize.ParseBytes(createConfiguration.cloneStagingThreshold); err != nil {
+			return errors.Wrap(err, "unable to parse clone staging threshold")
 		} else {
-			configuration = synchronization.MergeConfigurations(configuration, globalConfiguration)
-			cmd.Warning("TOML-based global configuration files are deprecated, please migrate to YAML")
+			cloneStagingThreshold = s
 		}
 	}
 
-	// If a configuration file has been specified, then load it and merge it
-	// into our cumulative configuration. We handle its loading based on the
-	// extension, warning if a legacy TOML configuration file is used.
-	if createConfiguration.configurationFile != "" {
-		if filepath.Ext(createConfiguration.configurationFile) == ".toml" {
-			cmd.Warning("TOML-based configuration files are deprecated, please migrate to YAML")
-			if c, err := loadAndValidateLegacyTOMLConfiguration(createConfiguration.configurationFile); err != nil {
-				return errors.Wrap(err, "unable to load legacy configuration file")
-			} else {
-				configuration = synchronization.MergeConfigurations(configuration, c)
-			}
+	// Validate and convert the compression threshold.
+	var compressionThreshold uint64
+	if createConfiguration.compressionThreshold != "" {
+		if s, err := humanize.ParseBytes(createConfiguration.compressionThreshold); err != nil {
+			return errors.Wrap(err, "unable to parse compression threshold")
 		} else {
-			if c, err := loadAndValidateGlobalSynchronizationConfiguration(createConfiguration.configurationFile); err != nil {
-				return errors.Wrap(err, "unable to load configuration file")
-			} else {
-				configuration = synchronization.MergeConfigurations(configuration, c)
-			}
+			compressionThreshold = s
 		}
 	}
 
-	// Validate and convert the synchronization mode specification.
-	var synchronizationMode core.SynchronizationMode
-	if createConfiguration.synchronizationMode != "" {
-		if err := synchronizationMode.UnmarshalText([]byte(createConfiguration.synchronizationMode)); err != nil {
-			return errors.Wrap(err, "unable to parse synchronization mode")
+	// Validate and convert the agent memory limit.
+	var agentMemoryLimit uint64
+	if createConfiguration.agentMemoryLimit != "" {
+		if s, err := humanize.ParseBytes(createConfiguration.agentMemoryLimit); err != nil {
+			return errors.Wrap(err, "unable to parse agent memory limit")
+		} else {
+			agentMemoryLimit = s
 		}
 	}
 
-	// There's no need to validate the maximum entry count - any uint64 value is
-	// valid.
-
-	// Validate and convert the maximum staging file size.
-	var maximumStagingFileSize uint64
-	if createConfiguration.maximumStagingFileSize != "" {
-		if s, err := humanize.ParseBytes(createConfiguration.maximumStagingFileSize); err != nil {
-			return errors.Wrap(err, "unable to parse maximum staging file size")
+	// Validate and convert the undo maximum size.
+	var undoMaximumSize uint64
+	if createConfiguration.undoMaximumSize != "" {
+		if s, err := humanize.ParseBytes(createConfiguration.undoMaximumSize); err != nil {
+			return errors.Wrap(err, "unable to parse undo maximum size")
 		} else {
-			maximumStagingFileSize = s
+			undoMaximumSize = s
 		}
 	}
 
@@ -299,6 +376,24 @@ func createMain(_ *cobra.Command, arguments []string) error {
 		}
 	}
 
+	// Validate and convert content store mode specifications.
+	var contentStoreMode, contentStoreModeAlpha, contentStoreModeBeta synchronization.ContentStoreMode
+	if createConfiguration.contentStoreMode != "" {
+		if err := contentStoreMode.UnmarshalText([]byte(createConfiguration.contentStoreMode)); err != nil {
+			return errors.Wrap(err, "unable to parse content store mode")
+		}
+	}
+	if createConfiguration.contentStoreModeAlpha != "" {
+		if err := contentStoreModeAlpha.UnmarshalText([]byte(createConfiguration.contentStoreModeAlpha)); err != nil {
+			return errors.Wrap(err, "unable to parse content store mode for alpha")
+		}
+	}
+	if createConfiguration.contentStoreModeBeta != "" {
+		if err := contentStoreModeBeta.UnmarshalText([]byte(createConfiguration.contentStoreModeBeta)); err != nil {
+			return errors.Wrap(err, "unable to parse content store mode for beta")
+		}
+	}
+
 	// Validate and convert the symbolic link mode specification.
 	var symbolicLinkMode core.SymlinkMode
 	if createConfiguration.symbolicLinkMode != "" {
@@ -307,6 +402,82 @@ func createMain(_ *cobra.Command, arguments []string) error {
 		}
 	}
 
+	// Validate and convert the broken symbolic link mode specification.
+	var brokenSymbolicLinkMode core.BrokenSymlinkMode
+	if createConfiguration.brokenSymbolicLinkMode != "" {
+		if err := brokenSymbolicLinkMode.UnmarshalText([]byte(createConfiguration.brokenSymbolicLinkMode)); err != nil {
+			return errors.Wrap(err, "unable to parse broken symbolic link mode")
+		}
+	}
+
+	// Validate and convert the invalid name mode specification.
+	var invalidNameMode core.InvalidNameMode
+	if createConfiguration.invalidNameMode != "" {
+		if err := invalidNameMode.UnmarshalText([]byte(createConfiguration.invalidNameMode)); err != nil {
+			return errors.Wrap(err, "unable to parse invalid name mode")
+		}
+	}
+
+	// Validate and convert the long path mode specification.
+	var longPathMode core.LongPathMode
+	if createConfiguration.longPathMode != "" {
+		if err := longPathMode.UnmarshalText([]byte(createConfiguration.longPathMode)); err != nil {
+			return errors.Wrap(err, "unable to parse long path mode")
+		}
+	}
+
+	// Validate and convert filename encoding specifications.
+	var filenameEncoding, filenameEncodingAlpha, filenameEncodingBeta core.FilenameEncoding
+	if createConfiguration.filenameEncoding != "" {
+		if err := filenameEncoding.UnmarshalText([]byte(createConfiguration.filenameEncoding)); err != nil {
+			return errors.Wrap(err, "unable to parse filename encoding")
+		}
+	}
+	if createConfiguration.filenameEncodingAlpha != "" {
+		if err := filenameEncodingAlpha.UnmarshalText([]byte(createConfiguration.filenameEncodingAlpha)); err != nil {
+			return errors.Wrap(err, "unable to parse filename encoding for alpha")
+		}
+	}
+	if createConfiguration.filenameEncodingBeta != "" {
+		if err := filenameEncodingBeta.UnmarshalText([]byte(createConfiguration.filenameEncodingBeta)); err != nil {
+			return errors.Wrap(err, "unable to parse filename encoding for beta")
+		}
+	}
+
+	// Validate and convert the archive compression mode specification.
+	var archiveCompressionMode synchronization.ArchiveCompressionMode
+	if createConfiguration.archiveCompressionMode != "" {
+		if err := archiveCompressionMode.UnmarshalText([]byte(createConfiguration.archiveCompressionMode)); err != nil {
+			return errors.Wrap(err, "unable to parse archive compression mode")
+		}
+	}
+
+	// Validate and convert the transfer priority specification.
+	var transferPriority synchronization.TransferPriority
+	if createConfiguration.transferPriority != "" {
+		if err := transferPriority.UnmarshalText([]byte(createConfiguration.transferPriority)); err != nil {
+			return errors.Wrap(err, "unable to parse transfer priority")
+		}
+	}
+
+	// Validate and convert the cycle transfer budget.
+	var cycleTransferBudget uint64
+	if createConfiguration.cycleTransferBudget != "" {
+		if s, err := humanize.ParseBytes(createConfiguration.cycleTransferBudget); err != nil {
+			return errors.Wrap(err, "unable to parse cycle transfer budget")
+		} else {
+			cycleTransferBudget = s
+		}
+	}
+
+	// Validate and convert the delta transfer mode specification.
+	var deltaTransferMode synchronization.DeltaTransferMode
+	if createConfiguration.deltaTransferMode != "" {
+		if err := deltaTransferMode.UnmarshalText([]byte(createConfiguration.deltaTransferMode)); err != nil {
+			return errors.Wrap(err, "unable to parse delta transfer mode")
+		}
+	}
+
 	// Validate and convert watch mode specifications.
 	var watchMode, watchModeAlpha, watchModeBeta synchronization.WatchMode
 	if createConfiguration.watchMode != "" {
@@ -335,6 +506,13 @@ func createMain(_ *cobra.Command, arguments []string) error {
 		}
 	}
 
+	// Validate ignore set references.
+	for _, name := range createConfiguration.ignoreSets {
+		if err := synchronization.EnsureIgnoreSetNameValid(name); err != nil {
+			return errors.Wrapf(err, "invalid ignore set reference: %s", name)
+		}
+	}
+
 	// Validate and convert the VCS ignore mode specification.
 	var ignoreVCSMode core.IgnoreVCSMode
 	if createConfiguration.ignoreVCS && createConfiguration.noIgnoreVCS {
@@ -439,58 +617,251 @@ func createMain(_ *cobra.Command, arguments []string) error {
 		}
 	}
 
+	// Validate and convert host verification mode specifications.
+	var hostVerificationMode, hostVerificationModeAlpha, hostVerificationModeBeta synchronization.HostVerificationMode
+	if createConfiguration.hostVerificationMode != "" {
+		if err := hostVerificationMode.UnmarshalText([]byte(createConfiguration.hostVerificationMode)); err != nil {
+			return errors.Wrap(err, "unable to parse host verification mode")
+		}
+	}
+	if createConfiguration.hostVerificationModeAlpha != "" {
+		if err := hostVerificationModeAlpha.UnmarshalText([]byte(createConfiguration.hostVerificationModeAlpha)); err != nil {
+			return errors.Wrap(err, "unable to parse host verification mode for alpha")
+		}
+	}
+	if createConfiguration.hostVerificationModeBeta != "" {
+		if err := hostVerificationModeBeta.UnmarshalText([]byte(createConfiguration.hostVerificationModeBeta)); err != nil {
+			return errors.Wrap(err, "unable to parse host verification mode for beta")
+		}
+	}
+
+	// Validate and convert the content type mode specification.
+	var contentTypeMode core.ContentTypeMode
+	if createConfiguration.contentTypeMode != "" {
+		if err := contentTypeMode.UnmarshalText([]byte(createConfiguration.contentTypeMode)); err != nil {
+			return errors.Wrap(err, "unable to parse content type mode")
+		}
+	}
+
+	// Validate line ending patterns.
+	for _, pattern := range createConfiguration.lineEndingPatterns {
+		if !core.ValidLineEndingPattern(pattern) {
+			return errors.Errorf("invalid line ending pattern: %s", pattern)
+		}
+	}
+
+	// Validate and convert line ending style specifications.
+	var lineEndingStyle, lineEndingStyleAlpha, lineEndingStyleBeta core.LineEndingStyle
+	if createConfiguration.lineEndingStyle != "" {
+		if err := lineEndingStyle.UnmarshalText([]byte(createConfiguration.lineEndingStyle)); err != nil {
+			return errors.Wrap(err, "unable to parse line ending style")
+		}
+	}
+	if createConfiguration.lineEndingStyleAlpha != "" {
+		if err := lineEndingStyleAlpha.UnmarshalText([]byte(createConfiguration.lineEndingStyleAlpha)); err != nil {
+			return errors.Wrap(err, "unable to parse line ending style for alpha")
+		}
+	}
+	if createConfiguration.lineEndingStyleBeta != "" {
+		if err := lineEndingStyleBeta.UnmarshalText([]byte(createConfiguration.lineEndingStyleBeta)); err != nil {
+			return errors.Wrap(err, "unable to parse line ending style for beta")
+		}
+	}
+
+	// Validate and convert ACL mode specifications.
+	var aclMode, aclModeAlpha, aclModeBeta core.ACLMode
+	if createConfiguration.aclMode != "" {
+		if err := aclMode.UnmarshalText([]byte(createConfiguration.aclMode)); err != nil {
+			return errors.Wrap(err, "unable to parse ACL mode")
+		}
+	}
+	if createConfiguration.aclModeAlpha != "" {
+		if err := aclModeAlpha.UnmarshalText([]byte(createConfiguration.aclModeAlpha)); err != nil {
+			return errors.Wrap(err, "unable to parse ACL mode for alpha")
+		}
+	}
+	if createConfiguration.aclModeBeta != "" {
+		if err := aclModeBeta.UnmarshalText([]byte(createConfiguration.aclModeBeta)); err != nil {
+			return errors.Wrap(err, "unable to parse ACL mode for beta")
+		}
+	}
+
+	// Validate and convert durability mode specifications.
+	var durabilityMode, durabilityModeAlpha, durabilityModeBeta core.DurabilityMode
+	if createConfiguration.durabilityMode != "" {
+		if err := durabilityMode.UnmarshalText([]byte(createConfiguration.durabilityMode)); err != nil {
+			return errors.Wrap(err, "unable to parse durability mode")
+		}
+	}
+	if createConfiguration.durabilityModeAlpha != "" {
+		if err := durabilityModeAlpha.UnmarshalText([]byte(createConfiguration.durabilityModeAlpha)); err != nil {
+			return errors.Wrap(err, "unable to parse durability mode for alpha")
+		}
+	}
+	if createConfiguration.durabilityModeBeta != "" {
+		if err := durabilityModeBeta.UnmarshalText([]byte(createConfiguration.durabilityModeBeta)); err != nil {
+			return errors.Wrap(err, "unable to parse durability mode for beta")
+		}
+	}
+
+	// Validate and convert modification handling mode specifications.
+	var modificationHandlingMode, modificationHandlingModeAlpha, modificationHandlingModeBeta synchronization.ModificationHandlingMode
+	if createConfiguration.modificationHandlingMode != "" {
+		if err := modificationHandlingMode.UnmarshalText([]byte(createConfiguration.modificationHandlingMode)); err != nil {
+			return errors.Wrap(err, "unable to parse modification handling mode")
+		}
+	}
+	if createConfiguration.modificationHandlingModeAlpha != "" {
+		if err := modificationHandlingModeAlpha.UnmarshalText([]byte(createConfiguration.modificationHandlingModeAlpha)); err != nil {
+			return errors.Wrap(err, "unable to parse modification handling mode for alpha")
+		}
+	}
+	if createConfiguration.modificationHandlingModeBeta != "" {
+		if err := modificationHandlingModeBeta.UnmarshalText([]byte(createConfiguration.modificationHandlingModeBeta)); err != nil {
+			return errors.Wrap(err, "unable to parse modification handling mode for beta")
+		}
+	}
+
 	// Create the command line configuration and merge it into our cumulative
 	// configuration.
 	configuration = synchronization.MergeConfigurations(configuration, &synchronization.Configuration{
-		SynchronizationMode:    synchronizationMode,
-		MaximumEntryCount:      createConfiguration.maximumEntryCount,
-		MaximumStagingFileSize: maximumStagingFileSize,
-		ProbeMode:              probeMode,
-		ScanMode:               scanMode,
-		StageMode:              stageMode,
-		SymlinkMode:            symbolicLinkMode,
-		WatchMode:              watchMode,
-		WatchPollingInterval:   createConfiguration.watchPollingInterval,
-		Ignores:                createConfiguration.ignores,
-		IgnoreVCSMode:          ignoreVCSMode,
-		DefaultFileMode:        uint32(defaultFileMode),
-		DefaultDirectoryMode:   uint32(defaultDirectoryMode),
-		DefaultOwner:           createConfiguration.defaultOwner,
-		DefaultGroup:           createConfiguration.defaultGroup,
+		SynchronizationMode:      synchronizationMode,
+		MaximumEntryCount:        createConfiguration.maximumEntryCount,
+		MaximumStagingFileSize:   maximumStagingFileSize,
+		MaximumFileSize:          maximumFileSize,
+		ProbeMode:                probeMode,
+		ScanMode:                 scanMode,
+		StageMode:                stageMode,
+		ContentStoreMode:         contentStoreMode,
+		ConflictResolverCommand:  createConfiguration.conflictResolver,
+		ConflictResolverTimeout:  createConfiguration.conflictResolverTimeout,
+		ConflictPauseThreshold:   createConfiguration.conflictPauseThreshold,
+		ConflictSidecars:         createConfiguration.conflictSidecars,
+		SymlinkMode:              symbolicLinkMode,
+		PreserveHardLinks:        createConfiguration.preserveHardLinks,
+		DeferSymlinks:            createConfiguration.deferSymlinks,
+		BrokenSymlinkMode:        brokenSymbolicLinkMode,
+		PreserveMacOSMetadata:    createConfiguration.preserveMacOSMetadata,
+		PreserveFileFlags:        createConfiguration.preserveFileFlags,
+		WatchMode:                watchMode,
+		WatchPollingInterval:     createConfiguration.watchPollingInterval,
+		DeletionGracePeriod:      createConfiguration.deletionGracePeriod,
+		Ignores:                  createConfiguration.ignores,
+		IgnoreVCSMode:            ignoreVCSMode,
+		IgnoreSets:               createConfiguration.ignoreSets,
+		IgnoreGitIgnored:         createConfiguration.ignoreGitIgnored,
+		IgnoreOpenFiles:          createConfiguration.ignoreOpenFiles,
+		ContentTypeMode:          contentTypeMode,
+		DefaultFileMode:          uint32(defaultFileMode),
+		DefaultDirectoryMode:     uint32(defaultDirectoryMode),
+		DefaultOwner:             createConfiguration.defaultOwner,
+		DefaultGroup:             createConfiguration.defaultGroup,
+		AclMode:                  aclMode,
+		HostVerificationMode:     hostVerificationMode,
+		DurabilityMode:           durabilityMode,
+		ModificationHandlingMode: modificationHandlingMode,
+		CloneStagingThreshold:    cloneStagingThreshold,
+		StallTimeout:             createConfiguration.stallTimeout,
+		AbortOnStall:             createConfiguration.abortOnStall,
+		CompressionThreshold:     compressionThreshold,
+		IncompressibleExtensions: createConfiguration.incompressibleExtensions,
+		ProtectedPaths:           createConfiguration.protectedPaths,
+		VerifiedPaths:            createConfiguration.verifiedPaths,
+		ScanConcurrency:          createConfiguration.scanConcurrency,
+		StagingConcurrency:       createConfiguration.stagingConcurrency,
+		ScanWarmup:               createConfiguration.scanWarmup,
+		ScheduleWindows:          createConfiguration.scheduleWindows,
+		ScheduleTimezone:         createConfiguration.scheduleTimezone,
+		StrictCapabilities:       createConfiguration.strictCapabilities,
+		LineEndingPatterns:       createConfiguration.lineEndingPatterns,
+		LineEndingStyle:          lineEndingStyle,
+		AgentMemoryLimit:         agentMemoryLimit,
+		AgentCPULimit:            createConfiguration.agentCPULimit,
+		AgentCacheHost:           createConfiguration.agentCacheHost,
+		UndoMaximumSize:          undoMaximumSize,
+		UndoMaximumAge:           createConfiguration.undoMaximumAge,
+		InvalidNameMode:          invalidNameMode,
+		LongPathMode:             longPathMode,
+		FilenameEncoding:         filenameEncoding,
+		PathMappings:             createConfiguration.pathMappings,
+		PathMappingOneWay:        createConfiguration.pathMappingOneWay,
+		TransferPriority:         transferPriority,
+		DeltaTransferMode:        deltaTransferMode,
+		CycleTransferBudget:      cycleTransferBudget,
+		CheckAvailableSpace:      createConfiguration.checkAvailableSpace,
+		ComputeMerkleRoot:        createConfiguration.computeMerkleRoot,
+		ArchiveCompressionMode:   archiveCompressionMode,
+		DeletionPauseThreshold:   createConfiguration.deletionPauseThreshold,
+		DeletionPausePercentage:  createConfiguration.deletionPausePercentage,
+		BaselineStartup:          createConfiguration.baselineStartup,
+		QuarantineThreshold:      createConfiguration.quarantineThreshold,
 	})
 
 	// Create the creation specification.
 	specification := &synchronizationsvc.CreationSpecification{
-		Alpha:         alpha,
-		Beta:          beta,
-		Configuration: configuration,
+		Alpha:           alpha,
+		Beta:            beta,
+		AdditionalBetas: additionalBetas,
+		Configuration:   configuration,
 		ConfigurationAlpha: &synchronization.Configuration{
-			ProbeMode:            probeModeAlpha,
-			ScanMode:             scanModeAlpha,
-			StageMode:            stageModeAlpha,
-			WatchMode:            watchModeAlpha,
-			WatchPollingInterval: createConfiguration.watchPollingIntervalAlpha,
-			DefaultFileMode:      uint32(defaultFileModeAlpha),
-			DefaultDirectoryMode: uint32(defaultDirectoryModeAlpha),
-			DefaultOwner:         createConfiguration.defaultOwnerAlpha,
-			DefaultGroup:         createConfiguration.defaultGroupAlpha,
+			ProbeMode:                probeModeAlpha,
+			ScanMode:                 scanModeAlpha,
+			StageMode:                stageModeAlpha,
+			ContentStoreMode:         contentStoreModeAlpha,
+			WatchMode:                watchModeAlpha,
+			WatchPollingInterval:     createConfiguration.watchPollingIntervalAlpha,
+			DefaultFileMode:          uint32(defaultFileModeAlpha),
+			DefaultDirectoryMode:     uint32(defaultDirectoryModeAlpha),
+			DefaultOwner:             createConfiguration.defaultOwnerAlpha,
+			DefaultGroup:             createConfiguration.defaultGroupAlpha,
+			AclMode:                  aclModeAlpha,
+			LineEndingStyle:          lineEndingStyleAlpha,
+			FilenameEncoding:         filenameEncodingAlpha,
+			HostVerificationMode:     hostVerificationModeAlpha,
+			DurabilityMode:           durabilityModeAlpha,
+			ModificationHandlingMode: modificationHandlingModeAlpha,
 		},
 		ConfigurationBeta: &synchronization.Configuration{
-			ProbeMode:            probeModeBeta,
-			ScanMode:             scanModeBeta,
-			StageMode:            stageModeBeta,
-			WatchMode:            watchModeBeta,
-			WatchPollingInterval: createConfiguration.watchPollingIntervalBeta,
-			DefaultFileMode:      uint32(defaultFileModeBeta),
-			DefaultDirectoryMode: uint32(defaultDirectoryModeBeta),
-			DefaultOwner:         createConfiguration.defaultOwnerBeta,
-			DefaultGroup:         createConfiguration.defaultGroupBeta,
+			ProbeMode:                probeModeBeta,
+			ScanMode:                 scanModeBeta,
+			StageMode:                stageModeBeta,
+			ContentStoreMode:         contentStoreModeBeta,
+			WatchMode:                watchModeBeta,
+			WatchPollingInterval:     createConfiguration.watchPollingIntervalBeta,
+			DefaultFileMode:          uint32(defaultFileModeBeta),
+			DefaultDirectoryMode:     uint32(defaultDirectoryModeBeta),
+			DefaultOwner:             createConfiguration.defaultOwnerBeta,
+			DefaultGroup:             createConfiguration.defaultGroupBeta,
+			AclMode:                  aclModeBeta,
+			LineEndingStyle:          lineEndingStyleBeta,
+			FilenameEncoding:         filenameEncodingBeta,
+			HostVerificationMode:     hostVerificationModeBeta,
+			DurabilityMode:           durabilityModeBeta,
+			ModificationHandlingMode: modificationHandlingModeBeta,
 		},
 		Name:   createConfiguration.name,
 		Labels: labels,
 		Paused: createConfiguration.paused,
 	}
 
+	// Warn about any SSH endpoints for which host verification is relaxed.
+	for _, endpoint := range []struct {
+		name          string
+		url           *url.URL
+		configuration *synchronization.Configuration
+	}{
+		{"alpha", alpha, specification.ConfigurationAlpha},
+		{"beta", beta, specification.ConfigurationBeta},
+	} {
+		if endpoint.url.Protocol != url.Protocol_SSH {
+			continue
+		}
+		mode := synchronization.MergeConfigurations(configuration, endpoint.configuration).HostVerificationMode
+		if warning := mode.Warning(); warning != "" {
+			cmd.Warning(fmt.Sprintf("%s: %s", endpoint.name, warning))
+		}
+	}
+
 	// Connect to the daemon and defer closure of the connection.
 	daemonConnection, err := daemon.Connect(true, true)
 	if err != nil {
@@ -513,7 +884,7 @@ func createMain(_ *cobra.Command, arguments []string) error {
 
 // createCommand is the create command.
 var createCommand = &cobra.Command{
-	Use:          "create <alpha> <beta>",
+	Use:          "create <alpha> <beta> [<beta>...]",
 	Short:        "Create and start a new synchronization session",
 	RunE:         createMain,
 	SilenceUsage: true,
@@ -544,6 +915,13 @@ var createConfiguration struct {
 	// maximumStagingFileSize is the maximum file size that endpoints will
 	// stage. It can be specified in human-friendly units.
 	maximumStagingFileSize string
+	// maximumFileSize is the maximum file size that endpoints will
+	// synchronize. It can be specified in human-friendly units.
+	maximumFileSize string
+	// cloneStagingThreshold is the minimum size of existing files for which
+	// changes will be staged by cloning. It can be specified in human-friendly
+	// units.
+	cloneStagingThreshold string
 	// probeMode specifies the filesystem probing mode to use for the session.
 	probeMode string
 	// probeModeAlpha specifies the filesystem probing mode to use for the
@@ -568,9 +946,161 @@ var createConfiguration struct {
 	// stageModeBeta specifies the file staging mode to use for the session,
 	// taking priority over stageMode on beta if specified.
 	stageModeBeta string
+	// conflictResolver specifies the conflict resolver command name and its
+	// arguments.
+	conflictResolver []string
+	// conflictResolverTimeout specifies the maximum amount of time (in
+	// seconds) that an invocation of the conflict resolver may take.
+	conflictResolverTimeout uint32
+	// conflictPauseThreshold specifies the maximum number of conflicts that a
+	// synchronization cycle may encounter before the session is automatically
+	// paused.
+	conflictPauseThreshold uint64
+	// conflictSidecars indicates that conflicts should be resolved in alpha's
+	// favor with beta's versions preserved in conflict sidecar files.
+	conflictSidecars bool
+	// stallTimeout specifies the maximum amount of time (in seconds) that the
+	// scan and transition stages may go without making progress.
+	stallTimeout uint32
+	// abortOnStall indicates whether or not synchronization cycles should be
+	// aborted when a stall is detected.
+	abortOnStall bool
+	// compressionThreshold specifies the minimum message size for which
+	// Mutagen-layer compression will be performed.
+	compressionThreshold string
+	// agentMemoryLimit specifies the maximum amount of memory that a remote
+	// agent may use.
+	agentMemoryLimit string
+	// agentCPULimit specifies the maximum amount of CPU time that a remote
+	// agent may use, as a percentage of a single CPU.
+	agentCPULimit uint32
+	// agentCacheHost specifies a regional agent cache host through which
+	// agents should be installed.
+	agentCacheHost string
+	// undoMaximumSize specifies the maximum total size of previous file
+	// contents retained to allow the last synchronization cycle to be undone.
+	undoMaximumSize string
+	// undoMaximumAge specifies the maximum amount of time (in seconds) after a
+	// synchronization cycle for which that cycle can be undone.
+	undoMaximumAge uint32
+	// invalidNameMode specifies the handling of names that can't be
+	// represented on an endpoint's filesystem.
+	invalidNameMode string
+	// longPathMode specifies the handling of content whose paths would exceed
+	// the path length limit of an endpoint's platform.
+	longPathMode string
+	// filenameEncoding specifies the encoding used for names on disk.
+	filenameEncoding string
+	// filenameEncodingAlpha specifies the encoding used for names on disk,
+	// taking priority over filenameEncoding on alpha if specified.
+	filenameEncodingAlpha string
+	// filenameEncodingBeta specifies the encoding used for names on disk,
+	// taking priority over filenameEncoding on beta if specified.
+	filenameEncodingBeta string
+	// pathMappings specifies the rules used to rewrite paths on beta.
+	pathMappings []string
+	// pathMappingOneWay indicates that path mappings don't need to be
+	// reversible.
+	pathMappingOneWay bool
+	// transferPriority specifies the priority with which the session's
+	// staging transfers are scheduled relative to those of other sessions.
+	transferPriority string
+	// deltaTransferMode specifies whether files are transmitted as deltas or
+	// in their entirety.
+	deltaTransferMode string
+	// cycleTransferBudget specifies the maximum number of bytes of file
+	// content to transfer in each synchronization cycle.
+	cycleTransferBudget string
+	// checkAvailableSpace indicates whether or not the space available for
+	// staging on each endpoint should be checked before staging.
+	checkAvailableSpace bool
+	// computeMerkleRoot indicates whether or not a Merkle tree digest of each
+	// endpoint's synchronization root should be computed after each scan.
+	computeMerkleRoot bool
+	// archiveCompressionMode specifies the compression to use when persisting
+	// the session's ancestor archive to disk.
+	archiveCompressionMode string
+	// deletionPauseThreshold specifies the maximum number of entries that a
+	// synchronization cycle may delete on either endpoint before the session
+	// is automatically paused for confirmation.
+	deletionPauseThreshold uint64
+	// deletionPausePercentage specifies the maximum percentage of previously
+	// synchronized entries that a synchronization cycle may delete on either
+	// endpoint before the session is automatically paused for confirmation.
+	deletionPausePercentage uint32
+	// baselineStartup indicates whether or not the existing endpoint contents
+	// should be treated as already synchronized.
+	baselineStartup bool
+	// quarantineThreshold specifies the number of consecutive synchronization
+	// cycles in which a path's transition must fail before it's quarantined.
+	quarantineThreshold uint64
+	// incompressibleExtensions specifies file extensions for which
+	// Mutagen-layer compression will be bypassed during transmission.
+	incompressibleExtensions []string
+	// protectedPaths specifies patterns for paths that synchronization must
+	// never delete or overwrite.
+	protectedPaths []string
+	// verifiedPaths specifies patterns for paths whose staged content must be
+	// verified on disk before being moved into place.
+	verifiedPaths []string
+	// lineEndingPatterns specifies patterns for text files whose line endings
+	// should be translated to each endpoint's line ending style.
+	lineEndingPatterns []string
+	// lineEndingStyle specifies the line ending style to use for translated
+	// files.
+	lineEndingStyle string
+	// lineEndingStyleAlpha specifies the line ending style to use for
+	// translated files, taking priority over lineEndingStyle on alpha if
+	// specified.
+	lineEndingStyleAlpha string
+	// lineEndingStyleBeta specifies the line ending style to use for
+	// translated files, taking priority over lineEndingStyle on beta if
+	// specified.
+	lineEndingStyleBeta string
+	// scanConcurrency specifies the maximum number of files whose digests will
+	// be computed concurrently when scanning.
+	scanConcurrency uint32
+	// stagingConcurrency specifies the maximum number of files that will be
+	// read concurrently when preparing to stage content.
+	stagingConcurrency uint32
+	// scanWarmup indicates whether or not endpoints should perform a
+	// background warmup scan to populate their digest caches ahead of the
+	// first synchronization cycle.
+	scanWarmup bool
+	// scheduleWindows specifies the time windows during which the session will
+	// actively synchronize.
+	scheduleWindows []string
+	// scheduleTimezone specifies the time zone in which schedule windows are
+	// evaluated.
+	scheduleTimezone string
+	// strictCapabilities indicates whether or not session startup should fail
+	// if an endpoint lacks capabilities required by requested features.
+	strictCapabilities bool
+	// contentStoreMode specifies the shared content store mode to use for the
+	// session.
+	contentStoreMode string
+	// contentStoreModeAlpha specifies the shared content store mode to use for
+	// the session, taking priority over contentStoreMode on alpha if specified.
+	contentStoreModeAlpha string
+	// contentStoreModeBeta specifies the shared content store mode to use for
+	// the session, taking priority over contentStoreMode on beta if specified.
+	contentStoreModeBeta string
 	// symbolicLinkMode specifies the symbolic link handling mode to use for
 	// the session.
 	symbolicLinkMode string
+	// preserveHardLinks specifies whether or not to preserve hard links.
+	preserveHardLinks bool
+	// deferSymlinks specifies whether or not to defer the creation of
+	// symlinks until their in-tree targets exist.
+	deferSymlinks bool
+	// brokenSymbolicLinkMode specifies the broken symbolic link handling mode
+	// to use for the session.
+	brokenSymbolicLinkMode string
+	// preserveMacOSMetadata specifies whether or not to preserve macOS
+	// resource forks and Finder metadata.
+	preserveMacOSMetadata bool
+	// preserveFileFlags specifies whether or not to preserve BSD file flags.
+	preserveFileFlags bool
 	// watchMode specifies the filesystem watching mode to use for the session.
 	watchMode string
 	// watchModeAlpha specifies the filesystem watching mode to use for the
@@ -590,6 +1120,10 @@ var createConfiguration struct {
 	// poll-based or hybrid watching, taking priority over watchPollingInterval
 	// on beta if specified.
 	watchPollingIntervalBeta uint32
+	// deletionGracePeriod specifies the amount of time (in milliseconds) for
+	// which apparent deletions should be held and re-checked before being
+	// propagated.
+	deletionGracePeriod uint32
 	// ignores is the list of ignore specifications for the session.
 	ignores []string
 	// ignoreVCS specifies whether or not to enable VCS ignores for the session.
@@ -597,6 +1131,17 @@ var createConfiguration struct {
 	// noIgnoreVCS specifies whether or not to disable VCS ignores for the
 	// session.
 	noIgnoreVCS bool
+	// ignoreSets is the list of shared ignore sets referenced by the session.
+	ignoreSets []string
+	// ignoreGitIgnored specifies whether or not to ignore paths that are
+	// ignored by Git.
+	ignoreGitIgnored bool
+	// ignoreOpenFiles specifies whether or not to transiently ignore files
+	// that are open for writing by another process.
+	ignoreOpenFiles bool
+	// contentTypeMode specifies the content type mode to use for filtering
+	// files based on whether their content is text or binary.
+	contentTypeMode string
 	// defaultFileMode specifies the default permission mode to use for new
 	// files in "portable" permission propagation mode, with endpoint-specific
 	// specifications taking priority.
@@ -649,6 +1194,44 @@ var createConfiguration struct {
 	// permission propagation mode, taking priority over defaultGroup on beta if
 	// specified.
 	defaultGroupBeta string
+	// aclMode specifies the POSIX ACL propagation mode to use for the session.
+	aclMode string
+	// aclModeAlpha specifies the POSIX ACL propagation mode to use for the
+	// session, taking priority over aclMode on alpha if specified.
+	aclModeAlpha string
+	// aclModeBeta specifies the POSIX ACL propagation mode to use for the
+	// session, taking priority over aclMode on beta if specified.
+	aclModeBeta string
+	// hostVerificationMode specifies the remote host verification mode to use
+	// for the session.
+	hostVerificationMode string
+	// hostVerificationModeAlpha specifies the remote host verification mode to
+	// use for the session, taking priority over hostVerificationMode on alpha
+	// if specified.
+	hostVerificationModeAlpha string
+	// hostVerificationModeBeta specifies the remote host verification mode to
+	// use for the session, taking priority over hostVerificationMode on beta if
+	// specified.
+	hostVerificationModeBeta string
+	// durabilityMode specifies the durability mode to use for the session.
+	durabilityMode string
+	// durabilityModeAlpha specifies the durability mode to use for the session,
+	// taking priority over durabilityMode on alpha if specified.
+	durabilityModeAlpha string
+	// durabilityModeBeta specifies the durability mode to use for the session,
+	// taking priority over durabilityMode on beta if specified.
+	durabilityModeBeta string
+	// modificationHandlingMode specifies the mode for handling files modified
+	// during staging to use for the session.
+	modificationHandlingMode string
+	// modificationHandlingModeAlpha specifies the mode for handling files
+	// modified during staging to use for the session, taking priority over
+	// modificationHandlingMode on alpha if specified.
+	modificationHandlingModeAlpha string
+	// modificationHandlingModeBeta specifies the mode for handling files
+	// modified during staging to use for the session, taking priority over
+	// modificationHandlingMode on beta if specified.
+	modificationHandlingModeBeta string
 }
 
 func init() {
@@ -674,9 +1257,11 @@ func init() {
 	flags.StringVarP(&createConfiguration.configurationFile, "configuration-file", "c", "", "Specify a file from which to load session configuration")
 
 	// Wire up synchronization flags.
-	flags.StringVarP(&createConfiguration.synchronizationMode, "sync-mode", "m", "", "Specify synchronization mode (two-way-safe|two-way-resolved|one-way-safe|one-way-replica)")
+	flags.StringVarP(&createConfiguration.synchronizationMode, "sync-mode", "m", "", "Specify synchronization mode (two-way-safe|two-way-resolved|two-way-resolved-beta|one-way-safe|one-way-replica)")
 	flags.Uint64Var(&createConfiguration.maximumEntryCount, "max-entry-count", 0, "Specify the maximum number of entries that endpoints will manage")
 	flags.StringVar(&createConfiguration.maximumStagingFileSize, "max-staging-file-size", "", "Specify the maximum (individual) file size that endpoints will stage")
+	flags.StringVar(&createConfiguration.maximumFileSize, "max-file-size", "", "Specify the maximum (individual) file size that endpoints will synchronize (larger files are skipped and reported)")
+	flags.StringVar(&createConfiguration.cloneStagingThreshold, "clone-staging-threshold", "", "Specify the minimum size of existing files for which changes will be staged by cloning (where reflinks are supported)")
 	flags.StringVar(&createConfiguration.probeMode, "probe-mode", "", "Specify probe mode (probe|assume)")
 	flags.StringVar(&createConfiguration.probeModeAlpha, "probe-mode-alpha", "", "Specify probe mode for alpha (probe|assume)")
 	flags.StringVar(&createConfiguration.probeModeBeta, "probe-mode-beta", "", "Specify probe mode for beta (probe|assume)")
@@ -686,9 +1271,94 @@ func init() {
 	flags.StringVar(&createConfiguration.stageMode, "stage-mode", "", "Specify staging mode (mutagen|neighboring)")
 	flags.StringVar(&createConfiguration.stageModeAlpha, "stage-mode-alpha", "", "Specify staging mode for alpha (mutagen|neighboring)")
 	flags.StringVar(&createConfiguration.stageModeBeta, "stage-mode-beta", "", "Specify staging mode for beta (mutagen|neighboring)")
+	flags.StringVar(&createConfiguration.contentStoreMode, "content-store-mode", "", "Specify shared content store mode (disabled|shared)")
+	flags.StringVar(&createConfiguration.contentStoreModeAlpha, "content-store-mode-alpha", "", "Specify shared content store mode for alpha (disabled|shared)")
+	flags.StringVar(&createConfiguration.contentStoreModeBeta, "content-store-mode-beta", "", "Specify shared content store mode for beta (disabled|shared)")
+	flags.StringSliceVar(&createConfiguration.conflictResolver, "conflict-resolver", nil, "Specify conflict resolver command and arguments (two-way-safe mode only)")
+	flags.Uint32Var(&createConfiguration.conflictResolverTimeout, "conflict-resolver-timeout", 0, "Specify conflict resolver timeout in seconds")
+	flags.Uint64Var(&createConfiguration.conflictPauseThreshold, "conflict-pause-threshold", 0, "Automatically pause the session when a synchronization cycle encounters more than the specified number of conflicts")
+	flags.BoolVar(&createConfiguration.conflictSidecars, "conflict-sidecars", false, "Resolve file conflicts in alpha's favor, preserving beta's versions in conflict sidecar files on beta (two-way-safe mode only)")
+
+	// Wire up stall detection flags.
+	flags.Uint32Var(&createConfiguration.stallTimeout, "stall-timeout", 0, "Specify stall detection timeout in seconds for scanning and transitioning")
+	flags.BoolVar(&createConfiguration.abortOnStall, "abort-on-stall", false, "Abort synchronization cycles when a stall is detected")
+
+	// Wire up compression flags.
+	flags.StringVar(&createConfiguration.compressionThreshold, "compression-threshold", "", "Specify the minimum message size for which compression is performed")
+	flags.StringSliceVar(&createConfiguration.incompressibleExtensions, "incompressible-extension", nil, "Specify file extensions for which compression is bypassed")
+
+	// Wire up agent resource limit flags.
+	flags.StringVar(&createConfiguration.agentMemoryLimit, "agent-memory-limit", "", "Specify the maximum memory usage for remote agents")
+	flags.Uint32Var(&createConfiguration.agentCPULimit, "agent-cpu-limit", 0, "Specify the maximum CPU usage for remote agents as a percentage of a single CPU")
+	flags.StringVar(&createConfiguration.agentCacheHost, "agent-cache-host", "", "Specify a regional cache host ([user@]host) through which agents are installed on SSH remotes")
+	flags.StringVar(&createConfiguration.undoMaximumSize, "undo-max-size", "", "Specify the maximum size of previous content retained to allow undoing the last synchronization cycle (enables undo support)")
+	flags.Uint32Var(&createConfiguration.undoMaximumAge, "undo-max-age", 0, "Specify the maximum time (in seconds) after a synchronization cycle for which it can be undone")
+
+	// Wire up name handling flags.
+	flags.StringVar(&createConfiguration.invalidNameMode, "invalid-name-mode", "", "Specify handling of names that can't be represented on an endpoint, e.g. ':' on Windows (skip|escape)")
+	flags.StringVar(&createConfiguration.longPathMode, "long-path-mode", "", "Specify handling of content whose paths would exceed an endpoint's path length limit, e.g. MAX_PATH on Windows (extended|skip)")
+	flags.StringVar(&createConfiguration.filenameEncoding, "filename-encoding", "", "Specify the encoding used for names on disk (utf-8|iso-8859-1|iso-8859-15|windows-1252|koi8-r|shift-jis|euc-jp|euc-kr|gbk|big5)")
+	flags.StringVar(&createConfiguration.filenameEncodingAlpha, "filename-encoding-alpha", "", "Specify the encoding used for names on disk on alpha (utf-8|iso-8859-1|iso-8859-15|windows-1252|koi8-r|shift-jis|euc-jp|euc-kr|gbk|big5)")
+	flags.StringVar(&createConfiguration.filenameEncodingBeta, "filename-encoding-beta", "", "Specify the encoding used for names on disk on beta (utf-8|iso-8859-1|iso-8859-15|windows-1252|koi8-r|shift-jis|euc-jp|euc-kr|gbk|big5)")
+	flags.StringArrayVar(&createConfiguration.pathMappings, "path-mapping", nil, "Specify a rule for rewriting paths on beta (source=>destination for prefixes or regex:pattern=>replacement)")
+	flags.BoolVar(&createConfiguration.pathMappingOneWay, "path-mapping-one-way", false, "Allow path mappings that can't be reversed (one-way modes only)")
+
+	// Wire up transfer flags.
+	flags.StringVar(&createConfiguration.transferPriority, "transfer-priority", "", "Specify the priority of staging transfers relative to other sessions (low|normal|high)")
+	flags.StringVar(&createConfiguration.deltaTransferMode, "delta-transfer-mode", "", "Specify whether files are transmitted as deltas or in their entirety (auto|delta|whole-file)")
+	flags.StringVar(&createConfiguration.cycleTransferBudget, "cycle-transfer-budget", "", "Specify the maximum amount of file content transferred per synchronization cycle, deferring the remainder to subsequent cycles")
+	flags.BoolVar(&createConfiguration.checkAvailableSpace, "check-available-space", false, "Pause the session instead of staging files that would exceed the space or disk quota available on the receiving endpoint")
+
+	// Wire up integrity flags.
+	flags.BoolVar(&createConfiguration.computeMerkleRoot, "compute-merkle-root", false, "Compute a Merkle tree digest of each endpoint's synchronization root after each scan")
+
+	// Wire up persistence flags.
+	flags.StringVar(&createConfiguration.archiveCompressionMode, "archive-compression", "", "Specify compression for the ancestor archive persisted on disk (none|gzip)")
+
+	// Wire up deletion flags.
+	flags.Uint64Var(&createConfiguration.deletionPauseThreshold, "deletion-pause-threshold", 0, "Pause the session for confirmation when a synchronization cycle would delete more than the specified number of entries on either endpoint")
+	flags.Uint32Var(&createConfiguration.deletionPausePercentage, "deletion-pause-percentage", 0, "Pause the session for confirmation when a synchronization cycle would delete more than the specified percentage of previously synchronized entries on either endpoint")
+
+	// Wire up startup flags.
+	flags.BoolVar(&createConfiguration.baselineStartup, "baseline", false, "Treat the existing (equal) endpoint contents as already synchronized and only synchronize subsequent changes")
+
+	// Wire up quarantine flags.
+	flags.Uint64Var(&createConfiguration.quarantineThreshold, "quarantine-threshold", 0, "Quarantine (skip and report) paths whose transitions fail in the specified number of consecutive synchronization cycles")
+
+	// Wire up protection flags.
+	flags.StringSliceVar(&createConfiguration.protectedPaths, "protected-path", nil, "Specify protected path patterns that synchronization never deletes or overwrites")
+	flags.StringSliceVar(&createConfiguration.verifiedPaths, "verified-path", nil, "Specify path patterns whose staged content is verified on disk before being swapped into place")
+
+	// Wire up line ending flags.
+	flags.StringSliceVar(&createConfiguration.lineEndingPatterns, "line-ending-pattern", nil, "Specify patterns for text files whose line endings should be translated")
+	flags.StringVar(&createConfiguration.lineEndingStyle, "line-ending-style", "", "Specify line ending style for translated files (lf|crlf)")
+	flags.StringVar(&createConfiguration.lineEndingStyleAlpha, "line-ending-style-alpha", "", "Specify line ending style for translated files on alpha (lf|crlf)")
+	flags.StringVar(&createConfiguration.lineEndingStyleBeta, "line-ending-style-beta", "", "Specify line ending style for translated files on beta (lf|crlf)")
+
+	// Wire up concurrency flags.
+	flags.Uint32Var(&createConfiguration.scanConcurrency, "scan-concurrency", 0, "Specify the maximum number of files hashed concurrently when scanning")
+	flags.Uint32Var(&createConfiguration.stagingConcurrency, "staging-concurrency", 0, "Specify the maximum number of files read concurrently when preparing to stage")
+	flags.BoolVar(&createConfiguration.scanWarmup, "scan-warmup", false, "Hash the synchronization root in the background ahead of the first synchronization cycle")
+
+	// Wire up schedule flags. We use a string array for windows since window
+	// specifications can contain commas.
+	flags.StringArrayVar(&createConfiguration.scheduleWindows, "schedule", nil, "Specify a time window during which to synchronize ([days] HH:MM-HH:MM)")
+	flags.StringVar(&createConfiguration.scheduleTimezone, "schedule-timezone", "", "Specify the time zone in which schedule windows are evaluated")
+
+	// Wire up capability flags.
+	flags.BoolVar(&createConfiguration.strictCapabilities, "strict-capabilities", false, "Fail session startup if an endpoint lacks capabilities required by requested features")
 
 	// Wire up symbolic link flags.
 	flags.StringVar(&createConfiguration.symbolicLinkMode, "symlink-mode", "", "Specify symlink mode (ignore|portable|posix-raw)")
+	flags.BoolVar(&createConfiguration.preserveHardLinks, "preserve-hard-links", false, "Preserve hard links between files (POSIX only)")
+	flags.BoolVar(&createConfiguration.deferSymlinks, "defer-symlinks", false, "Defer symlink creation until in-tree targets exist")
+	flags.StringVar(&createConfiguration.brokenSymbolicLinkMode, "broken-symlink-mode", "", "Specify broken symlink handling in portable mode (sync|skip|error)")
+
+	// Wire up macOS metadata flags.
+	flags.BoolVar(&createConfiguration.preserveMacOSMetadata, "preserve-macos-metadata", false, "Preserve macOS resource forks and Finder metadata (using AppleDouble files on other platforms)")
+
+	// Wire up file flag flags.
+	flags.BoolVar(&createConfiguration.preserveFileFlags, "preserve-file-flags", false, "Preserve BSD file flags (e.g. uchg and hidden) on macOS and FreeBSD")
 
 	// Wire up watch flags.
 	flags.StringVar(&createConfiguration.watchMode, "watch-mode", "", "Specify watch mode (portable|force-poll|no-watch)")
@@ -697,11 +1367,16 @@ func init() {
 	flags.Uint32Var(&createConfiguration.watchPollingInterval, "watch-polling-interval", 0, "Specify watch polling interval in seconds")
 	flags.Uint32Var(&createConfiguration.watchPollingIntervalAlpha, "watch-polling-interval-alpha", 0, "Specify watch polling interval in seconds for alpha")
 	flags.Uint32Var(&createConfiguration.watchPollingIntervalBeta, "watch-polling-interval-beta", 0, "Specify watch polling interval in seconds for beta")
+	flags.Uint32Var(&createConfiguration.deletionGracePeriod, "deletion-grace-period", 0, "Specify a period in milliseconds for which deletions are held and re-checked before being propagated")
 
 	// Wire up ignore flags.
 	flags.StringSliceVarP(&createConfiguration.ignores, "ignore", "i", nil, "Specify ignore paths")
 	flags.BoolVar(&createConfiguration.ignoreVCS, "ignore-vcs", false, "Ignore VCS directories")
 	flags.BoolVar(&createConfiguration.noIgnoreVCS, "no-ignore-vcs", false, "Propagate VCS directories")
+	flags.StringSliceVar(&createConfiguration.ignoreSets, "ignore-set", nil, "Specify shared ignore sets")
+	flags.BoolVar(&createConfiguration.ignoreGitIgnored, "ignore-git-ignored", false, "Ignore paths ignored by Git")
+	flags.BoolVar(&createConfiguration.ignoreOpenFiles, "ignore-open-files", false, "Ignore files open for writing by other processes until they're closed")
+	flags.StringVar(&createConfiguration.contentTypeMode, "content-type", "", "Specify the content type of files to synchronize (all|text|binary)")
 
 	// Wire up permission flags.
 	flags.StringVar(&createConfiguration.defaultFileMode, "default-file-mode", "", "Specify default file permission mode")
@@ -716,4 +1391,20 @@ func init() {
 	flags.StringVar(&createConfiguration.defaultGroup, "default-group", "", "Specify default file/directory group")
 	flags.StringVar(&createConfiguration.defaultGroupAlpha, "default-group-alpha", "", "Specify default file/directory group for alpha")
 	flags.StringVar(&createConfiguration.defaultGroupBeta, "default-group-beta", "", "Specify default file/directory group for beta")
+	flags.StringVar(&createConfiguration.aclMode, "acl-mode", "", "Specify POSIX ACL mode (ignore|propagate)")
+	flags.StringVar(&createConfiguration.aclModeAlpha, "acl-mode-alpha", "", "Specify POSIX ACL mode for alpha (ignore|propagate)")
+	flags.StringVar(&createConfiguration.aclModeBeta, "acl-mode-beta", "", "Specify POSIX ACL mode for beta (ignore|propagate)")
+	flags.StringVar(&createConfiguration.hostVerificationMode, "host-verification-mode", "", "Specify remote host verification mode (strict|ephemeral)")
+	flags.StringVar(&createConfiguration.hostVerificationModeAlpha, "host-verification-mode-alpha", "", "Specify remote host verification mode for alpha (strict|ephemeral)")
+	flags.StringVar(&createConfiguration.hostVerificationModeBeta, "host-verification-mode-beta", "", "Specify remote host verification mode for beta (strict|ephemeral)")
+
+	// Wire up durability flags.
+	flags.StringVar(&createConfiguration.durabilityMode, "durability", "", "Specify durability mode (full|metadata|none)")
+	flags.StringVar(&createConfiguration.durabilityModeAlpha, "durability-alpha", "", "Specify durability mode for alpha (full|metadata|none)")
+	flags.StringVar(&createConfiguration.durabilityModeBeta, "durability-beta", "", "Specify durability mode for beta (full|metadata|none)")
+
+	// Wire up modification handling flags.
+	flags.StringVar(&createConfiguration.modificationHandlingMode, "modification-handling", "", "Specify handling of files modified during staging (retry|defer)")
+	flags.StringVar(&createConfiguration.modificationHandlingModeAlpha, "modification-handling-alpha", "", "Specify handling of files modified during staging for alpha (retry|defer)")
+	flags.StringVar(&createConfiguration.modificationHandlingModeBeta, "modification-handling-beta", "", "Specify handling of files modified during staging for beta (retry|defer)")
 }
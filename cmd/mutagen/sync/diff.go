@@ -0,0 +1,148 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mutagen-io/mutagen/cmd"
+	"github.com/mutagen-io/mutagen/cmd/mutagen/daemon"
+
+	"github.com/mutagen-io/mutagen/pkg/grpcutil"
+	promptingsvc "github.com/mutagen-io/mutagen/pkg/service/prompting"
+	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// formatSnapshotModification formats the properties that changed for a
+// modified entry.
+func formatSnapshotModification(modification *core.SnapshotModification) string {
+	if modification.KindChanged() {
+		return "kind"
+	}
+	var changes []string
+	if modification.DigestChanged() {
+		changes = append(changes, "content")
+	}
+	if modification.ExecutabilityChanged() {
+		changes = append(changes, "executability")
+	}
+	if modification.TargetChanged() {
+		changes = append(changes, "target")
+	}
+	return strings.Join(changes, ", ")
+}
+
+// printSnapshotDiff prints a snapshot diff.
+func printSnapshotDiff(diff *core.SnapshotDiff) {
+	// Handle the case of no changes.
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Modified) == 0 && len(diff.Moved) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+
+	// Print changes.
+	for _, removed := range diff.Removed {
+		fmt.Println("-", formatPath(removed.Path))
+	}
+	for _, added := range diff.Added {
+		fmt.Println("+", formatPath(added.Path))
+	}
+	for _, modified := range diff.Modified {
+		fmt.Printf("M %s (%s)\n", formatPath(modified.Path), formatSnapshotModification(modified))
+	}
+	for _, moved := range diff.Moved {
+		fmt.Printf("R %s -> %s\n", formatPath(moved.OldPath), formatPath(moved.NewPath))
+	}
+}
+
+// diffMain is the entry point for the diff command.
+func diffMain(_ *cobra.Command, arguments []string) error {
+	// Validate arguments.
+	if len(arguments) != 1 {
+		return errors.New("a single session must be specified")
+	}
+
+	// Connect to the daemon and defer closure of the connection.
+	daemonConnection, err := daemon.Connect(true, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to daemon")
+	}
+	defer daemonConnection.Close()
+
+	// Initiate command line prompting.
+	statusLinePrinter := &cmd.StatusLinePrinter{}
+	promptingCtx, promptingCancel := context.WithCancel(context.Background())
+	prompter, promptingErrors, err := promptingsvc.Host(
+		promptingCtx, promptingsvc.NewPromptingClient(daemonConnection),
+		&cmd.StatusLinePrompter{Printer: statusLinePrinter}, true,
+	)
+	if err != nil {
+		promptingCancel()
+		return errors.Wrap(err, "unable to initiate prompting")
+	}
+
+	// Perform the diff operation, cancel prompting, and handle errors.
+	synchronizationService := synchronizationsvc.NewSynchronizationClient(daemonConnection)
+	request := &synchronizationsvc.DiffRequest{
+		Prompter:    prompter,
+		Session:     arguments[0],
+		Beta:        diffConfiguration.beta,
+		DetectMoves: diffConfiguration.detectMoves,
+	}
+	response, err := synchronizationService.Diff(context.Background(), request)
+	promptingCancel()
+	<-promptingErrors
+	if err != nil {
+		statusLinePrinter.BreakIfNonEmpty()
+		return grpcutil.PeelAwayRPCErrorLayer(err)
+	} else if err = response.EnsureValid(); err != nil {
+		statusLinePrinter.BreakIfNonEmpty()
+		return errors.Wrap(err, "invalid diff response received")
+	}
+
+	// Print the diff.
+	statusLinePrinter.Clear()
+	printSnapshotDiff(response.Diff)
+
+	// Success.
+	return nil
+}
+
+// diffCommand is the diff command.
+var diffCommand = &cobra.Command{
+	Use:          "diff <session>",
+	Short:        "Show changes made on a synchronization session endpoint since its last synchronization cycle",
+	RunE:         diffMain,
+	SilenceUsage: true,
+}
+
+// diffConfiguration stores configuration for the diff command.
+var diffConfiguration struct {
+	// help indicates whether or not to show help information and exit.
+	help bool
+	// beta indicates whether or not the beta endpoint should be diffed.
+	beta bool
+	// detectMoves indicates whether or not moves should be detected.
+	detectMoves bool
+}
+
+func init() {
+	// Grab a handle for the command line flags.
+	flags := diffCommand.Flags()
+
+	// Disable alphabetical sorting of flags in help output.
+	flags.SortFlags = false
+
+	// Manually add a help flag to override the default message. Cobra will
+	// still implement its logic automatically.
+	flags.BoolVarP(&diffConfiguration.help, "help", "h", false, "Show help information")
+
+	// Wire up diff flags.
+	flags.BoolVar(&diffConfiguration.beta, "beta", false, "Diff the beta endpoint instead of the alpha endpoint")
+	flags.BoolVar(&diffConfiguration.detectMoves, "detect-moves", false, "Report removed and added files with identical content as moves (approximate)")
+}
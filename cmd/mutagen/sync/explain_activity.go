@@ -0,0 +1,84 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mutagen-io/mutagen/cmd/mutagen/daemon"
+
+	"github.com/mutagen-io/mutagen/pkg/grpcutil"
+	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
+)
+
+// explainActivityMain is the entry point for the explain-activity command.
+func explainActivityMain(_ *cobra.Command, arguments []string) error {
+	// Validate arguments.
+	if len(arguments) != 1 {
+		return errors.New("a single session must be specified")
+	}
+
+	// Connect to the daemon and defer closure of the connection.
+	daemonConnection, err := daemon.Connect(true, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to daemon")
+	}
+	defer daemonConnection.Close()
+
+	// Perform the explanation.
+	synchronizationService := synchronizationsvc.NewSynchronizationClient(daemonConnection)
+	request := &synchronizationsvc.ExplainActivityRequest{
+		Session: arguments[0],
+	}
+	response, err := synchronizationService.ExplainActivity(context.Background(), request)
+	if err != nil {
+		return grpcutil.PeelAwayRPCErrorLayer(err)
+	} else if err = response.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid explain activity response received")
+	}
+
+	// Print the explanation.
+	if response.Explanation.Idle {
+		fmt.Println("Session is idle")
+		return nil
+	}
+	for _, reason := range response.Explanation.Reasons {
+		fmt.Println(reason.Description)
+		for _, path := range reason.Paths {
+			fmt.Printf("\t%s\n", formatPath(path))
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// explainActivityCommand is the explain-activity command.
+var explainActivityCommand = &cobra.Command{
+	Use:          "explain-activity <session>",
+	Short:        "Explain why a synchronization session is not idle",
+	RunE:         explainActivityMain,
+	SilenceUsage: true,
+}
+
+// explainActivityConfiguration stores configuration for the explain-activity
+// command.
+var explainActivityConfiguration struct {
+	// help indicates whether or not to show help information and exit.
+	help bool
+}
+
+func init() {
+	// Grab a handle for the command line flags.
+	flags := explainActivityCommand.Flags()
+
+	// Disable alphabetical sorting of flags in help output.
+	flags.SortFlags = false
+
+	// Manually add a help flag to override the default message. Cobra will
+	// still implement its logic automatically.
+	flags.BoolVarP(&explainActivityConfiguration.help, "help", "h", false, "Show help information")
+}
@@ -0,0 +1,94 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mutagen-io/mutagen/cmd/mutagen/daemon"
+
+	"github.com/mutagen-io/mutagen/pkg/grpcutil"
+	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
+)
+
+// explainIgnoreMain is the entry point for the explain-ignore command.
+func explainIgnoreMain(_ *cobra.Command, arguments []string) error {
+	// Validate arguments.
+	if len(arguments) != 2 {
+		return errors.New("a session and path must be specified")
+	}
+
+	// Connect to the daemon and defer closure of the connection.
+	daemonConnection, err := daemon.Connect(true, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to daemon")
+	}
+	defer daemonConnection.Close()
+
+	// Perform the evaluation.
+	synchronizationService := synchronizationsvc.NewSynchronizationClient(daemonConnection)
+	request := &synchronizationsvc.ExplainIgnoreRequest{
+		Session:   arguments[0],
+		Path:      arguments[1],
+		Directory: explainIgnoreConfiguration.directory,
+	}
+	response, err := synchronizationService.ExplainIgnore(context.Background(), request)
+	if err != nil {
+		return grpcutil.PeelAwayRPCErrorLayer(err)
+	} else if err = response.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid explain ignore response received")
+	}
+
+	// Print the decision.
+	if response.Pattern == "" {
+		fmt.Println("Not ignored (no pattern matches)")
+		return nil
+	}
+	status := "Ignored"
+	if !response.Ignored {
+		status = "Not ignored"
+	}
+	fmt.Printf("%s by pattern \"%s\" from %s\n", status, response.Pattern, response.Source)
+	if response.Path != arguments[1] {
+		fmt.Println("Matched parent directory:", formatPath(response.Path))
+	}
+
+	// Success.
+	return nil
+}
+
+// explainIgnoreCommand is the explain-ignore command.
+var explainIgnoreCommand = &cobra.Command{
+	Use:          "explain-ignore <session> <path>",
+	Short:        "Explain whether a path is ignored by a synchronization session and why",
+	RunE:         explainIgnoreMain,
+	SilenceUsage: true,
+}
+
+// explainIgnoreConfiguration stores configuration for the explain-ignore
+// command.
+var explainIgnoreConfiguration struct {
+	// help indicates whether or not to show help information and exit.
+	help bool
+	// directory indicates whether or not the path should be evaluated as a
+	// directory.
+	directory bool
+}
+
+func init() {
+	// Grab a handle for the command line flags.
+	flags := explainIgnoreCommand.Flags()
+
+	// Disable alphabetical sorting of flags in help output.
+	flags.SortFlags = false
+
+	// Manually add a help flag to override the default message. Cobra will
+	// still implement its logic automatically.
+	flags.BoolVarP(&explainIgnoreConfiguration.help, "help", "h", false, "Show help information")
+
+	// Wire up evaluation flags.
+	flags.BoolVarP(&explainIgnoreConfiguration.directory, "directory", "d", false, "Evaluate the path as a directory")
+}
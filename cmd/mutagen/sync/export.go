@@ -0,0 +1,121 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mutagen-io/mutagen/cmd/mutagen/daemon"
+
+	"github.com/mutagen-io/mutagen/pkg/grpcutil"
+	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
+)
+
+// exportMain is the entry point for the export command.
+func exportMain(_ *cobra.Command, arguments []string) (err error) {
+	// Validate arguments.
+	if len(arguments) != 1 {
+		return errors.New("a single session must be specified")
+	} else if exportConfiguration.output == "" {
+		return errors.New("an output path must be specified")
+	}
+
+	// Connect to the daemon and defer closure of the connection.
+	daemonConnection, err := daemon.Connect(true, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to daemon")
+	}
+	defer daemonConnection.Close()
+
+	// Initiate the export. We cancel the stream when we're done in case we
+	// bail before reaching the end of the stream.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	synchronizationService := synchronizationsvc.NewSynchronizationClient(daemonConnection)
+	request := &synchronizationsvc.ExportRequest{
+		Session: arguments[0],
+		Beta:    exportConfiguration.beta,
+		Format:  exportConfiguration.format,
+	}
+	stream, err := synchronizationService.Export(ctx, request)
+	if err != nil {
+		return grpcutil.PeelAwayRPCErrorLayer(err)
+	}
+
+	// Open the output. If we're writing to a file, then defer its closure and
+	// removal on failure.
+	var output io.Writer = os.Stdout
+	if exportConfiguration.output != "-" {
+		file, createErr := os.Create(exportConfiguration.output)
+		if createErr != nil {
+			return errors.Wrap(createErr, "unable to create output file")
+		}
+		defer func() {
+			if closeErr := file.Close(); closeErr != nil && err == nil {
+				err = errors.Wrap(closeErr, "unable to close output file")
+			}
+			if err != nil {
+				os.Remove(exportConfiguration.output)
+			}
+		}()
+		output = file
+	}
+
+	// Receive and write archive data.
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return grpcutil.PeelAwayRPCErrorLayer(err)
+		} else if err = response.EnsureValid(); err != nil {
+			return errors.Wrap(err, "invalid export response received")
+		} else if _, err = output.Write(response.Data); err != nil {
+			return errors.Wrap(err, "unable to write archive data")
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// exportCommand is the export command.
+var exportCommand = &cobra.Command{
+	Use:          "export <session>",
+	Short:        "Export the content of a synchronization session endpoint as an archive",
+	RunE:         exportMain,
+	SilenceUsage: true,
+}
+
+// exportConfiguration stores configuration for the export command.
+var exportConfiguration struct {
+	// help indicates whether or not to show help information and exit.
+	help bool
+	// beta indicates whether or not the beta endpoint should be exported.
+	beta bool
+	// format is the archive format.
+	format string
+	// output is the output path.
+	output string
+}
+
+func init() {
+	// Grab a handle for the command line flags.
+	flags := exportCommand.Flags()
+
+	// Disable alphabetical sorting of flags in help output.
+	flags.SortFlags = false
+
+	// Manually add a help flag to override the default message. Cobra will
+	// still implement its logic automatically.
+	flags.BoolVarP(&exportConfiguration.help, "help", "h", false, "Show help information")
+
+	// Wire up export flags.
+	flags.BoolVar(&exportConfiguration.beta, "beta", false, "Export the beta endpoint instead of the alpha endpoint")
+	flags.StringVar(&exportConfiguration.format, "format", "tar", "Specify the archive format (tar|zip)")
+	flags.StringVarP(&exportConfiguration.output, "output", "o", "", "Specify the output path (or - for standard output)")
+}
@@ -16,14 +16,24 @@ import (
 	"github.com/mutagen-io/mutagen/pkg/selection"
 	promptingsvc "github.com/mutagen-io/mutagen/pkg/service/prompting"
 	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
 )
 
 // FlushWithSelection is an orchestration convenience method that performs a
 // flush operation using the provided daemon connection and session selection.
+// Any specified ignore overrides are applied for the resulting synchronization
+// cycle only. If rehash is specified, then the sessions' endpoints discard their
+// digest caches and recompute all file digests during the resulting
+// synchronization cycle. If retryQuarantined is specified, then the sessions'
+// quarantined paths are released so that they're retried by the resulting
+// synchronization cycle.
 func FlushWithSelection(
 	daemonConnection *grpc.ClientConn,
 	selection *selection.Selection,
 	skipWait bool,
+	ignoreOverrides []string,
+	rehash bool,
+	retryQuarantined bool,
 ) error {
 	// Initiate command line messaging.
 	statusLinePrinter := &cmd.StatusLinePrinter{}
@@ -40,9 +50,12 @@ func FlushWithSelection(
 	// Perform the flush operation, cancel prompting, and handle errors.
 	synchronizationService := synchronizationsvc.NewSynchronizationClient(daemonConnection)
 	request := &synchronizationsvc.FlushRequest{
-		Prompter:  prompter,
-		Selection: selection,
-		SkipWait:  skipWait,
+		Prompter:         prompter,
+		Selection:        selection,
+		SkipWait:         skipWait,
+		IgnoreOverrides:  ignoreOverrides,
+		Rehash:           rehash,
+		RetryQuarantined: retryQuarantined,
 	}
 	response, err := synchronizationService.Flush(context.Background(), request)
 	promptingCancel()
@@ -72,6 +85,13 @@ func flushMain(_ *cobra.Command, arguments []string) error {
 		return errors.Wrap(err, "invalid session selection specification")
 	}
 
+	// Validate ignore overrides.
+	for _, include := range flushConfiguration.include {
+		if err := core.EnsureValidIgnoreOverride(include); err != nil {
+			return errors.Wrapf(err, "invalid include pattern: %s", include)
+		}
+	}
+
 	// Connect to the daemon and defer closure of the connection.
 	daemonConnection, err := daemon.Connect(true, true)
 	if err != nil {
@@ -80,7 +100,7 @@ func flushMain(_ *cobra.Command, arguments []string) error {
 	defer daemonConnection.Close()
 
 	// Perform the flush operation.
-	return FlushWithSelection(daemonConnection, selection, flushConfiguration.skipWait)
+	return FlushWithSelection(daemonConnection, selection, flushConfiguration.skipWait, flushConfiguration.include, flushConfiguration.rehash, flushConfiguration.retryQuarantined)
 }
 
 // flushCommand is the flush command.
@@ -103,6 +123,17 @@ var flushConfiguration struct {
 	// skipWait indicates whether or not the flush operation should block until
 	// a synchronization cycle completes for each sesion requested.
 	skipWait bool
+	// include specifies temporary include patterns that override the sessions'
+	// ignore patterns for the resulting synchronization cycle only.
+	include []string
+	// rehash indicates whether or not the sessions' endpoints should discard
+	// their digest caches and recompute all file digests during the resulting
+	// synchronization cycle.
+	rehash bool
+	// retryQuarantined indicates whether or not the sessions' quarantined
+	// paths should be released so that they're retried by the resulting
+	// synchronization cycle.
+	retryQuarantined bool
 }
 
 func init() {
@@ -120,4 +151,7 @@ func init() {
 	flags.BoolVarP(&flushConfiguration.all, "all", "a", false, "Flush all sessions")
 	flags.StringVar(&flushConfiguration.labelSelector, "label-selector", "", "Flush sessions matching the specified label selector")
 	flags.BoolVar(&flushConfiguration.skipWait, "skip-wait", false, "Avoid waiting for the resulting synchronization cycle(s) to complete")
+	flags.StringSliceVar(&flushConfiguration.include, "include", nil, "Temporarily include normally ignored paths matching the specified pattern for the resulting synchronization cycle(s) only")
+	flags.BoolVar(&flushConfiguration.rehash, "rehash", false, "Discard checksum caches and recompute all file digests during the resulting synchronization cycle(s)")
+	flags.BoolVar(&flushConfiguration.retryQuarantined, "retry-quarantined", false, "Release quarantined paths so that they're retried by the resulting synchronization cycle(s)")
 }
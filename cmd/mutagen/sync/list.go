@@ -3,13 +3,21 @@ package sync
 import (
 	"context"
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/spf13/cobra"
 
+	"github.com/dustin/go-humanize"
+
 	"github.com/fatih/color"
 
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
+
 	"google.golang.org/grpc"
 
 	"github.com/mutagen-io/mutagen/cmd"
@@ -20,6 +28,7 @@ import (
 	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
 	"github.com/mutagen-io/mutagen/pkg/synchronization"
 	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/rsync"
 	"github.com/mutagen-io/mutagen/pkg/url"
 )
 
@@ -39,10 +48,18 @@ func formatConnectionStatus(connected bool) string {
 	return "Disconnected"
 }
 
+// formatClockSkew formats a clock skew for display.
+func formatClockSkew(skew time.Duration) string {
+	if skew < 0 {
+		return fmt.Sprintf("%s behind", (-skew).Round(time.Millisecond))
+	}
+	return fmt.Sprintf("%s ahead", skew.Round(time.Millisecond))
+}
+
 // printEndpointStatus prints the status of a synchronization endpoint.
 func printEndpointStatus(
-	name string, url *url.URL, connected bool,
-	problems []*core.Problem, truncatedProblems uint64,
+	name string, url *url.URL, connected bool, clockSkew *duration.Duration,
+	merkleRoot []byte, problems []*core.Problem, truncatedProblems uint64,
 ) {
 	// Print header.
 	fmt.Printf("%s:\n", name)
@@ -56,6 +73,18 @@ func printEndpointStatus(
 	// Print connection status.
 	fmt.Printf("\tConnection state: %s\n", formatConnectionStatus(connected))
 
+	// Print clock skew, if known.
+	if clockSkew != nil {
+		if skew, err := ptypes.Duration(clockSkew); err == nil {
+			fmt.Println("\tClock skew:", formatClockSkew(skew))
+		}
+	}
+
+	// Print the Merkle root, if known.
+	if len(merkleRoot) > 0 {
+		fmt.Printf("\tMerkle root: %x\n", merkleRoot)
+	}
+
 	// Print problems, if any.
 	if len(problems) > 0 {
 		color.Red("\tProblems:\n")
@@ -68,12 +97,58 @@ func printEndpointStatus(
 	}
 }
 
+// printAdditionalBetaStatuses prints the statuses of a synchronization
+// session's additional beta endpoints.
+func printAdditionalBetaStatuses(state *synchronization.State) {
+	for b, betaURL := range state.Session.AdditionalBetas {
+		// Extract the endpoint state. It may not be available if synchronization
+		// hasn't yet been attempted for the endpoint.
+		betaState := &synchronization.AdditionalBetaState{}
+		if b < len(state.AdditionalBetas) && state.AdditionalBetas[b] != nil {
+			betaState = state.AdditionalBetas[b]
+		}
+
+		// Print the endpoint status.
+		printEndpointStatus(
+			fmt.Sprintf("Additional beta %d", b+1), betaURL, betaState.Connected, nil, nil,
+			betaState.Problems, betaState.TruncatedProblems,
+		)
+
+		// If the endpoint uses a label selector, then print the matching
+		// containers.
+		if _, ok := betaURL.DockerLabelSelector(); ok {
+			if len(betaState.Members) > 0 {
+				fmt.Println("\tMatching containers:", strings.Join(betaState.Members, ", "))
+			} else {
+				fmt.Println("\tMatching containers: None")
+			}
+		}
+
+		// Print the synchronization cycle count and the last error, if any.
+		fmt.Println("\tSuccessful synchronization cycles:", betaState.SuccessfulSynchronizationCycles)
+		if betaState.LastError != "" {
+			color.Red("\tLast error: %s\n", betaState.LastError)
+		}
+	}
+}
+
 // printSessionStatus prints the status of a synchronization session.
 func printSessionStatus(state *synchronization.State) {
 	// Print status.
 	statusString := state.Status.Description()
 	if state.Session.Paused {
 		statusString = color.YellowString("[Paused]")
+		if state.Session.PausedReason != "" {
+			statusString += color.YellowString(" (%s)", state.Session.PausedReason)
+		}
+		if state.QueuePosition > 0 {
+			statusString += color.YellowString(" [Queue position: %d]", state.QueuePosition)
+		}
+	} else if state.Session.Observing {
+		statusString = color.CyanString("[Observing] ") + statusString
+	}
+	if !state.Session.Paused && state.DeferredTransfers > 0 {
+		statusString += color.YellowString(" [Deferred transfers: %d]", state.DeferredTransfers)
 	}
 	fmt.Fprintln(color.Output, "Status:", statusString)
 
@@ -81,6 +156,73 @@ func printSessionStatus(state *synchronization.State) {
 	if state.LastError != "" {
 		color.Red("Last error: %s\n", state.LastError)
 	}
+
+	// Print the last stall, if any.
+	if report := state.StallReport; report != nil {
+		if elapsed, err := ptypes.Duration(report.TimeSinceProgress); err == nil {
+			color.Yellow("Last stall: %s (no progress for %s)\n", report.Status.Description(), elapsed.Round(time.Second))
+		}
+	}
+}
+
+// printStallGoroutines prints the Goroutine dump from a stall report.
+func printStallGoroutines(report *synchronization.StallReport) {
+	fmt.Println("Stalled session Goroutines:")
+	for _, line := range strings.Split(report.Goroutines, "\n") {
+		fmt.Println("\t" + line)
+	}
+}
+
+// formatHistogram formats a histogram summary for display, using the specified
+// function to format values.
+func formatHistogram(histogram *synchronization.Histogram, format func(float64) string) string {
+	// Format quantiles, which may fall into the overflow bucket.
+	formatQuantile := func(quantile float64) string {
+		if value := histogram.Quantile(quantile); !math.IsInf(value, 1) {
+			return "≤ " + format(value)
+		}
+		return "> " + format(histogram.Bounds[len(histogram.Bounds)-1])
+	}
+
+	// Format the summary.
+	return fmt.Sprintf("%d samples, mean %s, p50 %s, p90 %s, p99 %s",
+		histogram.Count,
+		format(histogram.Mean()),
+		formatQuantile(0.5),
+		formatQuantile(0.9),
+		formatQuantile(0.99),
+	)
+}
+
+// printTimings prints a summary of session timing histograms.
+func printTimings(timings *synchronization.Timings) {
+	// Define duration and throughput formatters.
+	formatDuration := func(seconds float64) string {
+		return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+	}
+	formatThroughput := func(bytesPerSecond float64) string {
+		return humanize.Bytes(uint64(bytesPerSecond)) + "/s"
+	}
+
+	// Print the header.
+	fmt.Println("Timings:")
+
+	// Print histograms with recorded values.
+	histograms := []struct {
+		name      string
+		histogram *synchronization.Histogram
+		format    func(float64) string
+	}{
+		{"Connect", timings.ConnectDurations, formatDuration},
+		{"Scan", timings.ScanDurations, formatDuration},
+		{"Staging throughput", timings.StagingThroughputs, formatThroughput},
+		{"Transition", timings.TransitionDurations, formatDuration},
+	}
+	for _, h := range histograms {
+		if h.histogram.GetCount() > 0 {
+			fmt.Printf("\t%s: %s\n", h.name, formatHistogram(h.histogram, h.format))
+		}
+	}
 }
 
 // formatEntry formats an entry for display.
@@ -101,6 +243,14 @@ func formatEntry(entry *core.Entry) string {
 	}
 }
 
+// printQuarantinedPaths prints a list of quarantined paths.
+func printQuarantinedPaths(paths []string) {
+	color.Yellow("Quarantined paths (retry with flush --retry-quarantined):\n")
+	for _, path := range paths {
+		color.Yellow("\t%s\n", formatPath(path))
+	}
+}
+
 // printConflicts prints a list of synchronization conflicts.
 func printConflicts(conflicts []*core.Conflict, truncatedConflicts uint64) {
 	// Print the header.
@@ -141,6 +291,88 @@ func printConflicts(conflicts []*core.Conflict, truncatedConflicts uint64) {
 	}
 }
 
+// printObservedChanges prints the changes that a session in observation mode
+// would apply to its endpoints.
+func printObservedChanges(state *synchronization.State) {
+	// Print the header.
+	color.Cyan("Observed changes (not applied):\n")
+
+	// Print the changes for each endpoint.
+	endpoints := []struct {
+		name      string
+		changes   []*core.Change
+		truncated uint64
+	}{
+		{"alpha", state.ObservedAlphaChanges, state.TruncatedObservedAlphaChanges},
+		{"beta", state.ObservedBetaChanges, state.TruncatedObservedBetaChanges},
+	}
+	for _, endpoint := range endpoints {
+		for _, c := range endpoint.changes {
+			fmt.Printf("\t(%s)%s %s (%s -> %s)\n",
+				endpoint.name,
+				strings.Repeat(" ", len("alpha")-len(endpoint.name)),
+				formatPath(c.Path),
+				formatEntry(c.Old),
+				formatEntry(c.New),
+			)
+		}
+		if endpoint.truncated > 0 {
+			fmt.Printf("\t(%s)%s ...+%d more...\n",
+				endpoint.name,
+				strings.Repeat(" ", len("alpha")-len(endpoint.name)),
+				endpoint.truncated,
+			)
+		}
+	}
+}
+
+// printReconciliationDecisions prints a list of reconciliation decisions.
+func printReconciliationDecisions(decisions []*core.ReconciliationDecision, truncatedDecisions uint64) {
+	// Print the header.
+	fmt.Println("Reconciliation decisions:")
+
+	// Print decisions.
+	for _, d := range decisions {
+		fmt.Printf("\t%s: %s (%s)\n", formatPath(d.Path), d.Action.Description(), d.Rationale)
+		fmt.Println("\t\tAncestor:", formatEntry(d.Ancestor))
+		fmt.Println("\t\tAlpha:", formatEntry(d.Alpha))
+		fmt.Println("\t\tBeta:", formatEntry(d.Beta))
+	}
+
+	// Print truncated decisions.
+	if truncatedDecisions > 0 {
+		fmt.Printf("\t...+%d more...\n", truncatedDecisions)
+	}
+}
+
+// printTransferEfficiencies prints a list of transfer efficiencies.
+func printTransferEfficiencies(efficiencies []*rsync.TransferEfficiency, truncatedEfficiencies uint64) {
+	// Print the header.
+	fmt.Println("Transfer efficiencies (least efficient first):")
+
+	// Print efficiencies.
+	for _, e := range efficiencies {
+		var blockSize string
+		if e.BlockSize == 0 {
+			blockSize = "no base"
+		} else {
+			blockSize = fmt.Sprintf("block size %s", humanize.Bytes(e.BlockSize))
+		}
+		fmt.Printf("\t%s: %.1f%% matched (%s matched, %s sent, %s)\n",
+			formatPath(e.Path),
+			100*e.MatchRatio(),
+			humanize.Bytes(e.MatchedBytes),
+			humanize.Bytes(e.LiteralBytes),
+			blockSize,
+		)
+	}
+
+	// Print truncated efficiencies.
+	if truncatedEfficiencies > 0 {
+		fmt.Printf("\t...+%d more...\n", truncatedEfficiencies)
+	}
+}
+
 // ListWithSelection is an orchestration convenience method that performs a list
 // operation using the provided daemon connection and session selection and then
 // prints status information.
@@ -167,17 +399,36 @@ func ListWithSelection(
 			fmt.Println(cmd.DelimiterLine)
 			printSession(state, long)
 			printEndpointStatus(
-				"Alpha", state.Session.Alpha, state.AlphaConnected,
+				"Alpha", state.Session.Alpha, state.AlphaConnected, state.AlphaClockSkew, state.AlphaMerkleRoot,
 				state.AlphaProblems, state.TruncatedAlphaProblems,
 			)
 			printEndpointStatus(
-				"Beta", state.Session.Beta, state.BetaConnected,
+				"Beta", state.Session.Beta, state.BetaConnected, state.BetaClockSkew, state.BetaMerkleRoot,
 				state.BetaProblems, state.TruncatedBetaProblems,
 			)
+			printAdditionalBetaStatuses(state)
 			printSessionStatus(state)
 			if len(state.Conflicts) > 0 {
 				printConflicts(state.Conflicts, state.TruncatedConflicts)
 			}
+			if len(state.QuarantinedPaths) > 0 {
+				printQuarantinedPaths(state.QuarantinedPaths)
+			}
+			if len(state.ObservedAlphaChanges) > 0 || len(state.ObservedBetaChanges) > 0 {
+				printObservedChanges(state)
+			}
+			if long && len(state.ReconciliationDecisions) > 0 {
+				printReconciliationDecisions(state.ReconciliationDecisions, state.TruncatedReconciliationDecisions)
+			}
+			if long && len(state.TransferEfficiencies) > 0 {
+				printTransferEfficiencies(state.TransferEfficiencies, state.TruncatedTransferEfficiencies)
+			}
+			if long && state.StallReport != nil && state.StallReport.Goroutines != "" {
+				printStallGoroutines(state.StallReport)
+			}
+			if long && state.Timings != nil {
+				printTimings(state.Timings)
+			}
 		}
 		fmt.Println(cmd.DelimiterLine)
 	} else {
@@ -3,11 +3,13 @@ package sync
 import (
 	"fmt"
 	"math"
+	"strings"
 
 	"github.com/dustin/go-humanize"
 
 	"github.com/mutagen-io/mutagen/pkg/selection"
 	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
 	"github.com/mutagen-io/mutagen/pkg/url"
 )
 
@@ -23,12 +25,69 @@ const (
 )
 
 // printEndpoint prints the configuration for a synchronization endpoint.
-func printEndpoint(name string, url *url.URL, configuration *synchronization.Configuration, version synchronization.Version) {
+func printEndpoint(name string, endpointURL *url.URL, configuration *synchronization.Configuration, version synchronization.Version) {
 	// Print the endpoint header.
 	fmt.Println(name, "configuration:")
 
 	// Print the URL.
-	fmt.Println("\tURL:", url.Format("\n\t\t"))
+	fmt.Println("\tURL:", endpointURL.Format("\n\t\t"))
+
+	// Compute and print the host verification mode, so long as we're dealing
+	// with an SSH endpoint. If host verification is relaxed, then make that
+	// obvious.
+	if endpointURL.Protocol == url.Protocol_SSH {
+		hostVerificationMode := configuration.HostVerificationMode
+		hostVerificationModeDescription := hostVerificationMode.Description()
+		if hostVerificationMode.IsDefault() {
+			hostVerificationMode = version.DefaultHostVerificationMode()
+			hostVerificationModeDescription += fmt.Sprintf(" (%s)", hostVerificationMode.Description())
+		}
+		fmt.Println("\tHost verification mode:", hostVerificationModeDescription)
+		if warning := hostVerificationMode.Warning(); warning != "" {
+			fmt.Println("\t\tWarning:", warning)
+		}
+
+		// Print any SSH options specified in the configuration.
+		if options := configuration.SshOptions; !options.Equal(nil) {
+			fmt.Println("\tSSH options:")
+			if options.User != "" {
+				fmt.Println("\t\tUser:", options.User)
+			}
+			if options.Port != 0 {
+				fmt.Println("\t\tPort:", options.Port)
+			}
+			for _, path := range options.IdentityFiles {
+				fmt.Println("\t\tIdentity file:", path)
+			}
+			if options.ProxyJump != "" {
+				fmt.Println("\t\tProxy jump:", options.ProxyJump)
+			}
+			if options.StrictHostKeyChecking != "" {
+				fmt.Println("\t\tStrict host key checking:", options.StrictHostKeyChecking)
+			}
+			if len(options.ExtraArguments) > 0 {
+				fmt.Println("\t\tExtra arguments:", strings.Join(options.ExtraArguments, " "))
+			}
+			if options.ForcePTYForSetup {
+				fmt.Println("\t\tForce PTY for setup: Yes")
+			}
+			if options.RemoteCommandPrefix != "" {
+				fmt.Println("\t\tRemote command prefix:", options.RemoteCommandPrefix)
+			}
+			if options.PrivilegeEscalation != "" {
+				fmt.Println("\t\tPrivilege escalation:", options.PrivilegeEscalation)
+			}
+			if len(options.CredentialCommand) > 0 {
+				fmt.Println("\t\tCredential command:", strings.Join(options.CredentialCommand, " "))
+			}
+			if len(options.SetEnv) > 0 {
+				fmt.Println("\t\tEnvironment variables:")
+				for _, key := range selection.ExtractAndSortLabelKeys(options.SetEnv) {
+					fmt.Printf("\t\t\t%s=%s\n", key, options.SetEnv[key])
+				}
+			}
+		}
+	}
 
 	// Compute and print the watch mode.
 	watchModeDescription := configuration.WatchMode.Description()
@@ -70,6 +129,27 @@ func printEndpoint(name string, url *url.URL, configuration *synchronization.Con
 	}
 	fmt.Println("\tStage mode:", stageModeDescription)
 
+	// Compute and print the content store mode.
+	contentStoreModeDescription := configuration.ContentStoreMode.Description()
+	if configuration.ContentStoreMode.IsDefault() {
+		contentStoreModeDescription += fmt.Sprintf(" (%s)", version.DefaultContentStoreMode().Description())
+	}
+	fmt.Println("\tContent store mode:", contentStoreModeDescription)
+
+	// Compute and print the durability mode.
+	durabilityModeDescription := configuration.DurabilityMode.Description()
+	if configuration.DurabilityMode.IsDefault() {
+		durabilityModeDescription += fmt.Sprintf(" (%s)", version.DefaultDurabilityMode().Description())
+	}
+	fmt.Println("\tDurability mode:", durabilityModeDescription)
+
+	// Compute and print the modification handling mode.
+	modificationHandlingModeDescription := configuration.ModificationHandlingMode.Description()
+	if configuration.ModificationHandlingMode.IsDefault() {
+		modificationHandlingModeDescription += fmt.Sprintf(" (%s)", version.DefaultModificationHandlingMode().Description())
+	}
+	fmt.Println("\tModification handling mode:", modificationHandlingModeDescription)
+
 	// Compute and print the default file mode.
 	var defaultFileModeDescription string
 	if configuration.DefaultFileMode == 0 {
@@ -101,6 +181,49 @@ func printEndpoint(name string, url *url.URL, configuration *synchronization.Con
 		defaultGroupDescription = configuration.DefaultGroup
 	}
 	fmt.Println("\tDefault file/directory group:", defaultGroupDescription)
+
+	// Compute and print the ACL mode.
+	aclModeDescription := configuration.AclMode.Description()
+	if configuration.AclMode.IsDefault() {
+		aclModeDescription += fmt.Sprintf(" (%s)", version.DefaultACLMode().Description())
+	}
+	fmt.Println("\tACL mode:", aclModeDescription)
+
+	// Compute and print the content type mode.
+	contentTypeModeDescription := configuration.ContentTypeMode.Description()
+	if configuration.ContentTypeMode.IsDefault() {
+		contentTypeModeDescription += fmt.Sprintf(" (%s)", core.ContentTypeMode_ContentTypeModeAll.Description())
+	}
+	fmt.Println("\tContent type mode:", contentTypeModeDescription)
+
+	// Compute and print the line ending style, if line ending translation is
+	// enabled.
+	if len(configuration.LineEndingPatterns) > 0 {
+		lineEndingStyleDescription := configuration.LineEndingStyle.Description()
+		if configuration.LineEndingStyle.IsDefault() {
+			lineEndingStyleDescription += " (Native)"
+		}
+		fmt.Println("\tLine ending style:", lineEndingStyleDescription)
+	}
+
+	// Compute and print the filename encoding.
+	filenameEncodingDescription := configuration.FilenameEncoding.Description()
+	if configuration.FilenameEncoding.IsDefault() {
+		filenameEncodingDescription += fmt.Sprintf(" (%s)", core.FilenameEncoding_FilenameEncodingUTF8.Description())
+	}
+	fmt.Println("\tFilename encoding:", filenameEncodingDescription)
+
+	// Print path mappings, if any.
+	if len(configuration.PathMappings) > 0 {
+		if configuration.PathMappingOneWay {
+			fmt.Println("\tPath mappings (one-way):")
+		} else {
+			fmt.Println("\tPath mappings:")
+		}
+		for _, m := range configuration.PathMappings {
+			fmt.Printf("\t\t%s\n", m)
+		}
+	}
 }
 
 // printSession prints the configuration and status of a synchronization
@@ -145,6 +268,123 @@ func printSession(state *synchronization.State, long bool) {
 		}
 		fmt.Println("\tSynchronization mode:", synchronizationMode)
 
+		// Compute and print the conflict resolver configuration, if any.
+		if len(configuration.ConflictResolverCommand) > 0 {
+			fmt.Println("\tConflict resolver:", strings.Join(configuration.ConflictResolverCommand, " "))
+			var conflictResolverTimeoutDescription string
+			if configuration.ConflictResolverTimeout == 0 {
+				conflictResolverTimeoutDescription = fmt.Sprintf(
+					"Default (%d seconds)",
+					state.Session.Version.DefaultConflictResolverTimeout(),
+				)
+			} else {
+				conflictResolverTimeoutDescription = fmt.Sprintf("%d seconds", configuration.ConflictResolverTimeout)
+			}
+			fmt.Println("\tConflict resolver timeout:", conflictResolverTimeoutDescription)
+		}
+
+		// Print the conflict pause threshold, if any.
+		if configuration.ConflictPauseThreshold != 0 {
+			fmt.Println("\tConflict pause threshold:", configuration.ConflictPauseThreshold)
+		}
+
+		// Print the deletion pause threshold and percentage, if any.
+		if configuration.DeletionPauseThreshold != 0 {
+			fmt.Println("\tDeletion pause threshold:", configuration.DeletionPauseThreshold)
+		}
+		if configuration.DeletionPausePercentage != 0 {
+			fmt.Printf("\tDeletion pause percentage: %d%%\n", configuration.DeletionPausePercentage)
+		}
+
+		// Print whether or not baseline startup is enabled and, if so, whether
+		// or not the baseline is still pending.
+		if configuration.BaselineStartup {
+			if state.Session.BaselinePending {
+				fmt.Println("\tBaseline startup: Yes (pending)")
+			} else {
+				fmt.Println("\tBaseline startup: Yes")
+			}
+		}
+
+		// Print the quarantine threshold, if any.
+		if configuration.QuarantineThreshold != 0 {
+			fmt.Println("\tQuarantine threshold:", configuration.QuarantineThreshold)
+		}
+
+		// Print whether or not conflict sidecars are enabled.
+		if configuration.ConflictSidecars {
+			fmt.Println("\tConflict sidecars: Enabled")
+		}
+
+		// Compute and print transfer priority.
+		transferPriorityDescription := configuration.TransferPriority.Description()
+		if configuration.TransferPriority.IsDefault() {
+			defaultTransferPriority := state.Session.Version.DefaultTransferPriority()
+			transferPriorityDescription += fmt.Sprintf(" (%s)", defaultTransferPriority.Description())
+		}
+		fmt.Println("\tTransfer priority:", transferPriorityDescription)
+
+		// Compute and print delta transfer mode.
+		deltaTransferModeDescription := configuration.DeltaTransferMode.Description()
+		if configuration.DeltaTransferMode.IsDefault() {
+			defaultDeltaTransferMode := state.Session.Version.DefaultDeltaTransferMode()
+			deltaTransferModeDescription += fmt.Sprintf(" (%s)", defaultDeltaTransferMode.Description())
+		}
+		fmt.Println("\tDelta transfer mode:", deltaTransferModeDescription)
+
+		// Print the cycle transfer budget, if any.
+		if configuration.CycleTransferBudget != 0 {
+			fmt.Println("\tCycle transfer budget:", humanize.Bytes(configuration.CycleTransferBudget))
+		}
+
+		// Print whether or not available space is checked before staging.
+		if configuration.CheckAvailableSpace {
+			fmt.Println("\tCheck available space: Yes")
+		}
+
+		// Print the deletion grace period, if any.
+		if configuration.DeletionGracePeriod != 0 {
+			fmt.Printf("\tDeletion grace period: %d milliseconds\n", configuration.DeletionGracePeriod)
+		}
+
+		// Print whether or not Merkle roots are computed.
+		fmt.Println("\tCompute Merkle root:", configuration.ComputeMerkleRoot)
+
+		// Compute and print archive compression mode.
+		archiveCompressionModeDescription := configuration.ArchiveCompressionMode.Description()
+		if configuration.ArchiveCompressionMode.IsDefault() {
+			defaultArchiveCompressionMode := state.Session.Version.DefaultArchiveCompressionMode()
+			archiveCompressionModeDescription += fmt.Sprintf(" (%s)", defaultArchiveCompressionMode.Description())
+		}
+		fmt.Println("\tArchive compression:", archiveCompressionModeDescription)
+
+		// Print the agent resource limits, if any.
+		if configuration.AgentMemoryLimit != 0 {
+			fmt.Println("\tAgent memory limit:", humanize.Bytes(configuration.AgentMemoryLimit))
+		}
+		if configuration.AgentCPULimit != 0 {
+			fmt.Println("\tAgent CPU limit:", fmt.Sprintf("%d%%", configuration.AgentCPULimit))
+		}
+
+		// Print the agent cache host, if any.
+		if configuration.AgentCacheHost != "" {
+			fmt.Println("\tAgent cache host:", configuration.AgentCacheHost)
+		}
+
+		// Print the undo configuration, if any.
+		if configuration.UndoMaximumSize != 0 {
+			fmt.Println("\tUndo maximum size:", humanize.Bytes(configuration.UndoMaximumSize))
+		}
+		if configuration.UndoMaximumAge != 0 {
+			fmt.Println("\tUndo maximum age:", fmt.Sprintf("%d seconds", configuration.UndoMaximumAge))
+		}
+
+		// Print the stall detection configuration, if any.
+		if configuration.StallTimeout != 0 {
+			fmt.Println("\tStall timeout:", fmt.Sprintf("%d seconds", configuration.StallTimeout))
+			fmt.Println("\tAbort on stall:", configuration.AbortOnStall)
+		}
+
 		// Compute and print maximum entry count.
 		var maximumEntryCountDescription string
 		if configuration.MaximumEntryCount == 0 {
@@ -174,6 +414,97 @@ func printSession(state *synchronization.State, long bool) {
 		}
 		fmt.Println("\tMaximum staging file size:", maximumStagingFileSizeDescription)
 
+		// Compute and print maximum file size.
+		var maximumFileSizeDescription string
+		if configuration.MaximumFileSize == 0 {
+			maximumFileSizeDescription = "Unlimited"
+		} else {
+			maximumFileSizeDescription = fmt.Sprintf(
+				"%d (%s)",
+				configuration.MaximumFileSize,
+				humanize.Bytes(configuration.MaximumFileSize),
+			)
+		}
+		fmt.Println("\tMaximum file size:", maximumFileSizeDescription)
+
+		// Compute and print the clone staging threshold.
+		var cloneStagingThresholdDescription string
+		if configuration.CloneStagingThreshold == 0 {
+			cloneStagingThresholdDescription = "Disabled"
+		} else {
+			cloneStagingThresholdDescription = fmt.Sprintf(
+				"%d (%s)",
+				configuration.CloneStagingThreshold,
+				humanize.Bytes(configuration.CloneStagingThreshold),
+			)
+		}
+		fmt.Println("\tClone staging threshold:", cloneStagingThresholdDescription)
+
+		// Compute and print the scan and staging concurrency.
+		scanConcurrencyDescription := fmt.Sprintf("%d", configuration.ScanConcurrency)
+		if configuration.ScanConcurrency == 0 {
+			scanConcurrencyDescription = fmt.Sprintf("Default (%d)", state.Session.Version.DefaultScanConcurrency())
+		}
+		fmt.Println("\tScan concurrency:", scanConcurrencyDescription)
+		stagingConcurrencyDescription := fmt.Sprintf("%d", configuration.StagingConcurrency)
+		if configuration.StagingConcurrency == 0 {
+			stagingConcurrencyDescription = fmt.Sprintf("Default (%d)", state.Session.Version.DefaultStagingConcurrency())
+		}
+		fmt.Println("\tStaging concurrency:", stagingConcurrencyDescription)
+
+		// Print whether or not warmup scans are performed.
+		if configuration.ScanWarmup {
+			fmt.Println("\tScan warmup: Yes")
+		}
+
+		// Compute and print the compression threshold.
+		var compressionThresholdDescription string
+		if configuration.CompressionThreshold == 0 {
+			compressionThresholdDescription = fmt.Sprintf(
+				"Default (%s)",
+				humanize.Bytes(state.Session.Version.DefaultCompressionThreshold()),
+			)
+		} else {
+			compressionThresholdDescription = fmt.Sprintf(
+				"%d (%s)",
+				configuration.CompressionThreshold,
+				humanize.Bytes(configuration.CompressionThreshold),
+			)
+		}
+		fmt.Println("\tCompression threshold:", compressionThresholdDescription)
+
+		// Print incompressible extensions, if any.
+		if len(configuration.IncompressibleExtensions) > 0 {
+			fmt.Println("\tIncompressible extensions:", strings.Join(configuration.IncompressibleExtensions, ", "))
+		}
+
+		// Print protected paths, if any.
+		if len(configuration.ProtectedPaths) > 0 {
+			fmt.Println("\tProtected paths:", strings.Join(configuration.ProtectedPaths, ", "))
+		}
+		if len(configuration.VerifiedPaths) > 0 {
+			fmt.Println("\tVerified paths:", strings.Join(configuration.VerifiedPaths, ", "))
+		}
+
+		// Print line ending patterns, if any.
+		if len(configuration.LineEndingPatterns) > 0 {
+			fmt.Println("\tLine ending patterns:", strings.Join(configuration.LineEndingPatterns, ", "))
+		}
+
+		// Print the schedule, if any. We separate windows with semicolons since
+		// their day specifications can contain commas.
+		if len(configuration.ScheduleWindows) > 0 {
+			fmt.Println("\tSchedule:", strings.Join(configuration.ScheduleWindows, "; "))
+			scheduleTimezoneDescription := configuration.ScheduleTimezone
+			if scheduleTimezoneDescription == "" {
+				scheduleTimezoneDescription = "Default (local)"
+			}
+			fmt.Println("\tSchedule time zone:", scheduleTimezoneDescription)
+		}
+
+		// Print whether or not capability requirements are strictly enforced.
+		fmt.Println("\tStrict capabilities:", configuration.StrictCapabilities)
+
 		// Compute and print symlink mode.
 		symlinkModeDescription := configuration.SymlinkMode.Description()
 		if configuration.SymlinkMode.IsDefault() {
@@ -182,6 +513,42 @@ func printSession(state *synchronization.State, long bool) {
 		}
 		fmt.Println("\tSymbolic link mode:", symlinkModeDescription)
 
+		// Print symbolic link deferral.
+		fmt.Println("\tDefer symbolic links:", configuration.DeferSymlinks)
+
+		// Compute and print broken symlink mode.
+		brokenSymlinkModeDescription := configuration.BrokenSymlinkMode.Description()
+		if configuration.BrokenSymlinkMode.IsDefault() {
+			defaultBrokenSymlinkMode := state.Session.Version.DefaultBrokenSymlinkMode()
+			brokenSymlinkModeDescription += fmt.Sprintf(" (%s)", defaultBrokenSymlinkMode.Description())
+		}
+		fmt.Println("\tBroken symbolic link mode:", brokenSymlinkModeDescription)
+
+		// Compute and print invalid name mode.
+		invalidNameModeDescription := configuration.InvalidNameMode.Description()
+		if configuration.InvalidNameMode.IsDefault() {
+			defaultInvalidNameMode := state.Session.Version.DefaultInvalidNameMode()
+			invalidNameModeDescription += fmt.Sprintf(" (%s)", defaultInvalidNameMode.Description())
+		}
+		fmt.Println("\tInvalid name mode:", invalidNameModeDescription)
+
+		// Compute and print long path mode.
+		longPathModeDescription := configuration.LongPathMode.Description()
+		if configuration.LongPathMode.IsDefault() {
+			defaultLongPathMode := state.Session.Version.DefaultLongPathMode()
+			longPathModeDescription += fmt.Sprintf(" (%s)", defaultLongPathMode.Description())
+		}
+		fmt.Println("\tLong path mode:", longPathModeDescription)
+
+		// Print hard link preservation.
+		fmt.Println("\tPreserve hard links:", configuration.PreserveHardLinks)
+
+		// Print macOS metadata preservation.
+		fmt.Println("\tPreserve macOS metadata:", configuration.PreserveMacOSMetadata)
+
+		// Print file flag preservation.
+		fmt.Println("\tPreserve file flags:", configuration.PreserveFileFlags)
+
 		// Compute and print the VCS ignore mode.
 		ignoreVCSModeDescription := configuration.IgnoreVCSMode.Description()
 		if configuration.IgnoreVCSMode.IsDefault() {
@@ -209,6 +576,20 @@ func printSession(state *synchronization.State, long bool) {
 			fmt.Println("\tIgnores: None")
 		}
 
+		// Print shared ignore set references, if any.
+		if len(configuration.IgnoreSets) > 0 {
+			fmt.Println("\tIgnore sets:")
+			for _, n := range configuration.IgnoreSets {
+				fmt.Printf("\t\t%s\n", n)
+			}
+		}
+
+		// Print Git ignore behavior.
+		fmt.Println("\tIgnore Git-ignored paths:", configuration.IgnoreGitIgnored)
+
+		// Print open file ignore behavior.
+		fmt.Println("\tIgnore open files:", configuration.IgnoreOpenFiles)
+
 		// Compute and print alpha-specific configuration.
 		alphaConfigurationMerged := synchronization.MergeConfigurations(
 			state.Session.Configuration,
@@ -222,5 +603,11 @@ func printSession(state *synchronization.State, long bool) {
 			state.Session.ConfigurationBeta,
 		)
 		printEndpoint("Beta", state.Session.Beta, betaConfigurationMerged, state.Session.Version)
+
+		// Print additional beta configurations, which share the beta-specific
+		// configuration.
+		for b, betaURL := range state.Session.AdditionalBetas {
+			printEndpoint(fmt.Sprintf("Additional beta %d", b+1), betaURL, betaConfigurationMerged, state.Session.Version)
+		}
 	}
 }
@@ -0,0 +1,119 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/mutagen-io/mutagen/cmd/mutagen/daemon"
+
+	"github.com/mutagen-io/mutagen/pkg/grpcutil"
+	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+)
+
+// printStagedContent prints the staged content for an endpoint.
+func printStagedContent(name string, content *synchronization.StagedContent, verified bool) {
+	// Print the header.
+	fmt.Printf("%s:\n", name)
+
+	// Handle cases where there's nothing to print.
+	if !content.Listed {
+		fmt.Println("\tStaged content listing not supported for this endpoint")
+		return
+	} else if len(content.Entries) == 0 {
+		fmt.Println("\tNo staged content")
+		return
+	}
+
+	// Print entries.
+	for _, entry := range content.Entries {
+		path := entry.Path
+		if path == "" {
+			path = "<unknown path>"
+		}
+		stagedAt := "unknown time"
+		if t, err := ptypes.Timestamp(entry.StagedAt); err == nil {
+			stagedAt = t.Local().Format(time.RFC3339)
+		}
+		fmt.Printf("\t%s (%s, staged %s, digest %x)\n", path, humanize.Bytes(entry.Size), stagedAt, entry.Digest)
+		if verified && !entry.DigestValid {
+			fmt.Println("\t\tStaged content doesn't match digest")
+		}
+	}
+}
+
+// listStagedMain is the entry point for the list-staged command.
+func listStagedMain(_ *cobra.Command, arguments []string) error {
+	// Validate arguments.
+	if len(arguments) != 1 {
+		return errors.New("a single session must be specified")
+	}
+
+	// Connect to the daemon and defer closure of the connection.
+	daemonConnection, err := daemon.Connect(true, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to daemon")
+	}
+	defer daemonConnection.Close()
+
+	// Perform the list operation.
+	synchronizationService := synchronizationsvc.NewSynchronizationClient(daemonConnection)
+	request := &synchronizationsvc.ListStagedRequest{
+		Session: arguments[0],
+		Verify:  listStagedConfiguration.verify,
+	}
+	response, err := synchronizationService.ListStaged(context.Background(), request)
+	if err != nil {
+		return grpcutil.PeelAwayRPCErrorLayer(err)
+	} else if err = response.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid list staged response received")
+	}
+
+	// Print the staged content.
+	printStagedContent("Alpha", response.Alpha, listStagedConfiguration.verify)
+	printStagedContent("Beta", response.Beta, listStagedConfiguration.verify)
+
+	// Success.
+	return nil
+}
+
+// listStagedCommand is the list-staged command.
+var listStagedCommand = &cobra.Command{
+	Use:          "list-staged <session>",
+	Short:        "List content staged but not yet applied by a synchronization session",
+	RunE:         listStagedMain,
+	SilenceUsage: true,
+}
+
+// listStagedConfiguration stores configuration for the list-staged command.
+var listStagedConfiguration struct {
+	// help indicates whether or not to show help information and exit.
+	help bool
+	// verify indicates whether or not staged content should be verified
+	// against its recorded digests.
+	verify bool
+}
+
+func init() {
+	// Grab a handle for the command line flags.
+	flags := listStagedCommand.Flags()
+
+	// Disable alphabetical sorting of flags in help output.
+	flags.SortFlags = false
+
+	// Manually add a help flag to override the default message. Cobra will
+	// still implement its logic automatically.
+	flags.BoolVarP(&listStagedConfiguration.help, "help", "h", false, "Show help information")
+
+	// Wire up listing flags.
+	flags.BoolVar(&listStagedConfiguration.verify, "verify", false, "Verify staged content against recorded digests")
+}
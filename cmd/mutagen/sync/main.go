@@ -43,5 +43,16 @@ func init() {
 
 	// HACK: In order for the sync commands to have the correct parent, we have
 	// to add them to the sync command after we add them to the root command.
-	// Thus, we add them in the top-level init function.
+	// Thus, we add them in the top-level init function. Commands that never
+	// existed at the root of the command structure can be added directly.
+	SyncCommand.AddCommand(relocateCommand)
+	SyncCommand.AddCommand(compareCommand)
+	SyncCommand.AddCommand(benchmarkCommand)
+	SyncCommand.AddCommand(undoCommand)
+	SyncCommand.AddCommand(reconnectCommand)
+	SyncCommand.AddCommand(explainIgnoreCommand)
+	SyncCommand.AddCommand(explainActivityCommand)
+	SyncCommand.AddCommand(listStagedCommand)
+	SyncCommand.AddCommand(exportCommand)
+	SyncCommand.AddCommand(diffCommand)
 }
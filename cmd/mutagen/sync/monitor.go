@@ -26,22 +26,47 @@ func computeMonitorStatusLine(state *synchronization.State) string {
 	status := "Status: "
 	if state.Session.Paused {
 		status += color.YellowString("[Paused]")
+		if state.QueuePosition > 0 {
+			status += color.YellowString(" [Queued: %d]", state.QueuePosition)
+		}
 	} else {
 		// Add a conflict flag if there are conflicts.
 		if len(state.Conflicts) > 0 {
 			status += color.RedString("[Conflicts] ")
 		}
 
+		// Determine whether or not any additional beta endpoints have problems
+		// or errors.
+		var additionalBetaProblems, additionalBetaErrored bool
+		for _, betaState := range state.AdditionalBetas {
+			if betaState == nil {
+				continue
+			}
+			additionalBetaProblems = additionalBetaProblems || len(betaState.Problems) > 0
+			additionalBetaErrored = additionalBetaErrored || betaState.LastError != ""
+		}
+
 		// Add a problems flag if there are problems.
-		if len(state.AlphaProblems) > 0 || len(state.BetaProblems) > 0 {
+		if len(state.AlphaProblems) > 0 || len(state.BetaProblems) > 0 || additionalBetaProblems {
 			status += color.RedString("[Problems] ")
 		}
 
 		// Add an error flag if there is one present.
-		if state.LastError != "" {
+		if state.LastError != "" || additionalBetaErrored {
 			status += color.RedString("[Errored] ")
 		}
 
+		// Add a deferral flag if files have been deferred by the transfer
+		// budget.
+		if state.DeferredTransfers > 0 {
+			status += color.YellowString("[Deferred: %d] ", state.DeferredTransfers)
+		}
+
+		// Add a quarantine flag if paths have been quarantined.
+		if len(state.QuarantinedPaths) > 0 {
+			status += color.YellowString("[Quarantined: %d] ", len(state.QuarantinedPaths))
+		}
+
 		// Add the status.
 		status += state.Status.Description()
 
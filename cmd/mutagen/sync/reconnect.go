@@ -0,0 +1,93 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mutagen-io/mutagen/cmd"
+	"github.com/mutagen-io/mutagen/cmd/mutagen/daemon"
+
+	"github.com/mutagen-io/mutagen/pkg/grpcutil"
+	promptingsvc "github.com/mutagen-io/mutagen/pkg/service/prompting"
+	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
+)
+
+// reconnectMain is the entry point for the reconnect command.
+func reconnectMain(_ *cobra.Command, arguments []string) error {
+	// Validate arguments.
+	if len(arguments) != 1 {
+		return errors.New("a single session must be specified")
+	}
+
+	// Connect to the daemon and defer closure of the connection.
+	daemonConnection, err := daemon.Connect(true, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to daemon")
+	}
+	defer daemonConnection.Close()
+
+	// Initiate command line prompting.
+	statusLinePrinter := &cmd.StatusLinePrinter{}
+	promptingCtx, promptingCancel := context.WithCancel(context.Background())
+	prompter, promptingErrors, err := promptingsvc.Host(
+		promptingCtx, promptingsvc.NewPromptingClient(daemonConnection),
+		&cmd.StatusLinePrompter{Printer: statusLinePrinter}, false,
+	)
+	if err != nil {
+		promptingCancel()
+		return errors.Wrap(err, "unable to initiate prompting")
+	}
+
+	// Perform the reconnect operation, cancel prompting, and handle errors.
+	synchronizationService := synchronizationsvc.NewSynchronizationClient(daemonConnection)
+	request := &synchronizationsvc.ReconnectRequest{
+		Prompter: prompter,
+		Session:  arguments[0],
+	}
+	response, err := synchronizationService.Reconnect(context.Background(), request)
+	promptingCancel()
+	<-promptingErrors
+	if err != nil {
+		statusLinePrinter.BreakIfNonEmpty()
+		return grpcutil.PeelAwayRPCErrorLayer(err)
+	} else if err = response.EnsureValid(); err != nil {
+		statusLinePrinter.BreakIfNonEmpty()
+		return errors.Wrap(err, "invalid reconnect response received")
+	}
+
+	// Success.
+	statusLinePrinter.Clear()
+	fmt.Println("Alpha:", formatConnectionStatus(response.State.AlphaConnected))
+	fmt.Println("Beta:", formatConnectionStatus(response.State.BetaConnected))
+	return nil
+}
+
+// reconnectCommand is the reconnect command.
+var reconnectCommand = &cobra.Command{
+	Use:          "reconnect <session>",
+	Short:        "Immediately retry connecting a disconnected synchronization session",
+	RunE:         reconnectMain,
+	SilenceUsage: true,
+}
+
+// reconnectConfiguration stores configuration for the reconnect command.
+var reconnectConfiguration struct {
+	// help indicates whether or not to show help information and exit.
+	help bool
+}
+
+func init() {
+	// Grab a handle for the command line flags.
+	flags := reconnectCommand.Flags()
+
+	// Disable alphabetical sorting of flags in help output.
+	flags.SortFlags = false
+
+	// Manually add a help flag to override the default message. Cobra will
+	// still implement its logic automatically.
+	flags.BoolVarP(&reconnectConfiguration.help, "help", "h", false, "Show help information")
+}
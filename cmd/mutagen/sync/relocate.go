@@ -0,0 +1,113 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mutagen-io/mutagen/cmd"
+	"github.com/mutagen-io/mutagen/cmd/mutagen/daemon"
+
+	"github.com/mutagen-io/mutagen/pkg/grpcutil"
+	promptingsvc "github.com/mutagen-io/mutagen/pkg/service/prompting"
+	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// relocateMain is the entry point for the relocate command.
+func relocateMain(_ *cobra.Command, arguments []string) error {
+	// Validate arguments.
+	if len(arguments) != 2 {
+		return errors.New("a session and a URL must be specified")
+	} else if relocateConfiguration.alpha == relocateConfiguration.beta {
+		return errors.New("exactly one of --alpha or --beta must be specified")
+	}
+	beta := relocateConfiguration.beta
+
+	// Parse the URL.
+	target, err := url.Parse(arguments[1], url.Kind_Synchronization, !beta)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse URL")
+	}
+
+	// Connect to the daemon and defer closure of the connection.
+	daemonConnection, err := daemon.Connect(true, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to daemon")
+	}
+	defer daemonConnection.Close()
+
+	// Initiate command line prompting.
+	statusLinePrinter := &cmd.StatusLinePrinter{}
+	promptingCtx, promptingCancel := context.WithCancel(context.Background())
+	prompter, promptingErrors, err := promptingsvc.Host(
+		promptingCtx, promptingsvc.NewPromptingClient(daemonConnection),
+		&cmd.StatusLinePrompter{Printer: statusLinePrinter}, true,
+	)
+	if err != nil {
+		promptingCancel()
+		return errors.Wrap(err, "unable to initiate prompting")
+	}
+
+	// Perform the relocate operation, cancel prompting, and handle errors.
+	synchronizationService := synchronizationsvc.NewSynchronizationClient(daemonConnection)
+	request := &synchronizationsvc.RelocateRequest{
+		Prompter: prompter,
+		Session:  arguments[0],
+		Beta:     beta,
+		Url:      target,
+	}
+	response, err := synchronizationService.Relocate(context.Background(), request)
+	promptingCancel()
+	<-promptingErrors
+	if err != nil {
+		statusLinePrinter.BreakIfNonEmpty()
+		return grpcutil.PeelAwayRPCErrorLayer(err)
+	} else if err = response.EnsureValid(); err != nil {
+		statusLinePrinter.BreakIfNonEmpty()
+		return errors.Wrap(err, "invalid relocate response received")
+	}
+
+	// Success.
+	statusLinePrinter.Clear()
+	if response.Warning != "" {
+		cmd.Warning(response.Warning)
+	}
+	return nil
+}
+
+// relocateCommand is the relocate command.
+var relocateCommand = &cobra.Command{
+	Use:          "relocate {--alpha|--beta} <session> <url>",
+	Short:        "Change the URL of a paused synchronization session endpoint",
+	RunE:         relocateMain,
+	SilenceUsage: true,
+}
+
+// relocateConfiguration stores configuration for the relocate command.
+var relocateConfiguration struct {
+	// help indicates whether or not to show help information and exit.
+	help bool
+	// alpha indicates whether or not the alpha endpoint should be relocated.
+	alpha bool
+	// beta indicates whether or not the beta endpoint should be relocated.
+	beta bool
+}
+
+func init() {
+	// Grab a handle for the command line flags.
+	flags := relocateCommand.Flags()
+
+	// Disable alphabetical sorting of flags in help output.
+	flags.SortFlags = false
+
+	// Manually add a help flag to override the default message. Cobra will
+	// still implement its logic automatically.
+	flags.BoolVarP(&relocateConfiguration.help, "help", "h", false, "Show help information")
+
+	// Wire up relocate flags.
+	flags.BoolVar(&relocateConfiguration.alpha, "alpha", false, "Relocate the alpha endpoint")
+	flags.BoolVar(&relocateConfiguration.beta, "beta", false, "Relocate the beta endpoint")
+}
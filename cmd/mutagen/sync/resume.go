@@ -23,6 +23,17 @@ import (
 func ResumeWithSelection(
 	daemonConnection *grpc.ClientConn,
 	selection *selection.Selection,
+) error {
+	return resumeWithSelection(daemonConnection, selection, false, false)
+}
+
+// resumeWithSelection performs a resume operation using the provided daemon
+// connection and session selection, optionally switching the selected sessions
+// into (if observe is true) or out of (if live is true) observation mode.
+func resumeWithSelection(
+	daemonConnection *grpc.ClientConn,
+	selection *selection.Selection,
+	observe, live bool,
 ) error {
 	// Initiate command line prompting.
 	statusLinePrinter := &cmd.StatusLinePrinter{}
@@ -41,6 +52,8 @@ func ResumeWithSelection(
 	request := &synchronizationsvc.ResumeRequest{
 		Prompter:  prompter,
 		Selection: selection,
+		Observe:   observe,
+		Live:      live,
 	}
 	response, err := synchronizationService.Resume(context.Background(), request)
 	promptingCancel()
@@ -70,6 +83,11 @@ func resumeMain(_ *cobra.Command, arguments []string) error {
 		return errors.Wrap(err, "invalid session selection specification")
 	}
 
+	// Validate the observation mode flags.
+	if resumeConfiguration.observe && resumeConfiguration.live {
+		return errors.New("--observe and --live are mutually exclusive")
+	}
+
 	// Connect to the daemon and defer closure of the connection.
 	daemonConnection, err := daemon.Connect(true, true)
 	if err != nil {
@@ -78,7 +96,7 @@ func resumeMain(_ *cobra.Command, arguments []string) error {
 	defer daemonConnection.Close()
 
 	// Perform the resume operation.
-	return ResumeWithSelection(daemonConnection, selection)
+	return resumeWithSelection(daemonConnection, selection, resumeConfiguration.observe, resumeConfiguration.live)
 }
 
 // resumeCommand is the resume command.
@@ -98,6 +116,12 @@ var resumeConfiguration struct {
 	// labelSelector encodes a label selector to be used in identifying which
 	// sessions should be paused.
 	labelSelector string
+	// observe indicates whether or not sessions should be switched to
+	// observation mode.
+	observe bool
+	// live indicates whether or not sessions should be switched out of
+	// observation mode.
+	live bool
 }
 
 func init() {
@@ -114,4 +138,6 @@ func init() {
 	// Wire up resume flags.
 	flags.BoolVarP(&resumeConfiguration.all, "all", "a", false, "Resume all sessions")
 	flags.StringVar(&resumeConfiguration.labelSelector, "label-selector", "", "Resume sessions matching the specified label selector")
+	flags.BoolVar(&resumeConfiguration.observe, "observe", false, "Switch sessions to observation mode, in which changes are reported but never applied")
+	flags.BoolVar(&resumeConfiguration.live, "live", false, "Switch sessions out of observation mode")
 }
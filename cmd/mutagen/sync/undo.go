@@ -0,0 +1,106 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mutagen-io/mutagen/cmd"
+	"github.com/mutagen-io/mutagen/cmd/mutagen/daemon"
+
+	"github.com/mutagen-io/mutagen/pkg/grpcutil"
+	"github.com/mutagen-io/mutagen/pkg/selection"
+	promptingsvc "github.com/mutagen-io/mutagen/pkg/service/prompting"
+	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
+)
+
+// undoMain is the entry point for the undo command.
+func undoMain(_ *cobra.Command, arguments []string) error {
+	// Create session selection specification.
+	selection := &selection.Selection{
+		All:            undoConfiguration.all,
+		Specifications: arguments,
+		LabelSelector:  undoConfiguration.labelSelector,
+	}
+	if err := selection.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid session selection specification")
+	}
+
+	// Connect to the daemon and defer closure of the connection.
+	daemonConnection, err := daemon.Connect(true, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to daemon")
+	}
+	defer daemonConnection.Close()
+
+	// Initiate command line messaging.
+	statusLinePrinter := &cmd.StatusLinePrinter{}
+	promptingCtx, promptingCancel := context.WithCancel(context.Background())
+	prompter, promptingErrors, err := promptingsvc.Host(
+		promptingCtx, promptingsvc.NewPromptingClient(daemonConnection),
+		&cmd.StatusLinePrompter{Printer: statusLinePrinter}, false,
+	)
+	if err != nil {
+		promptingCancel()
+		return errors.Wrap(err, "unable to initiate prompting")
+	}
+
+	// Perform the undo operation, cancel prompting, and handle errors.
+	synchronizationService := synchronizationsvc.NewSynchronizationClient(daemonConnection)
+	request := &synchronizationsvc.UndoRequest{
+		Prompter:  prompter,
+		Selection: selection,
+	}
+	response, err := synchronizationService.Undo(context.Background(), request)
+	promptingCancel()
+	<-promptingErrors
+	if err != nil {
+		statusLinePrinter.BreakIfNonEmpty()
+		return grpcutil.PeelAwayRPCErrorLayer(err)
+	} else if err = response.EnsureValid(); err != nil {
+		statusLinePrinter.BreakIfNonEmpty()
+		return errors.Wrap(err, "invalid undo response received")
+	}
+
+	// Success.
+	statusLinePrinter.Clear()
+	return nil
+}
+
+// undoCommand is the undo command.
+var undoCommand = &cobra.Command{
+	Use:          "undo [<session>...]",
+	Short:        "Undo the changes applied by the last synchronization cycle",
+	RunE:         undoMain,
+	SilenceUsage: true,
+}
+
+// undoConfiguration stores configuration for the undo command.
+var undoConfiguration struct {
+	// help indicates whether or not to show help information and exit.
+	help bool
+	// all indicates whether or not the last synchronization cycle should be
+	// undone for all sessions.
+	all bool
+	// labelSelector encodes a label selector to be used in identifying which
+	// sessions should have their last synchronization cycle undone.
+	labelSelector string
+}
+
+func init() {
+	// Grab a handle for the command line flags.
+	flags := undoCommand.Flags()
+
+	// Disable alphabetical sorting of flags in help output.
+	flags.SortFlags = false
+
+	// Manually add a help flag to override the default message. Cobra will
+	// still implement its logic automatically.
+	flags.BoolVarP(&undoConfiguration.help, "help", "h", false, "Show help information")
+
+	// Wire up undo flags.
+	flags.BoolVarP(&undoConfiguration.all, "all", "a", false, "Undo the last synchronization cycle for all sessions")
+	flags.StringVar(&undoConfiguration.labelSelector, "label-selector", "", "Undo the last synchronization cycle for sessions matching the specified label selector")
+}
@@ -0,0 +1,304 @@
+package agent
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/random"
+)
+
+const (
+	// DefaultBenchmarkSize is the default number of bytes transferred in each
+	// direction by a benchmark.
+	DefaultBenchmarkSize = 16 * 1024 * 1024
+	// DefaultBenchmarkProbes is the default number of round-trip latency probes
+	// performed by a benchmark.
+	DefaultBenchmarkProbes = 10
+	// maximumBenchmarkProbes is the maximum number of round-trip latency probes
+	// that may be requested by a benchmark.
+	maximumBenchmarkProbes = 1000
+	// benchmarkChunkSize is the size of the random data chunk that's repeated to
+	// generate benchmark data. It's larger than the window used by DEFLATE so
+	// that transport-level compression can't inflate throughput measurements.
+	benchmarkChunkSize = 1024 * 1024
+	// benchmarkTemporaryPrefix is the prefix used for temporary files created
+	// by the benchmark server.
+	benchmarkTemporaryPrefix = "mutagen-benchmark"
+)
+
+const (
+	// benchmarkStatusSuccess is the status byte sent by the benchmark server to
+	// indicate that a benchmark phase completed successfully.
+	benchmarkStatusSuccess byte = iota
+	// benchmarkStatusFailure is the status byte sent by the benchmark server to
+	// indicate that a benchmark phase failed.
+	benchmarkStatusFailure
+)
+
+// BenchmarkResult encodes the measurements taken by a transport benchmark.
+type BenchmarkResult struct {
+	// Size is the number of bytes transferred in each direction.
+	Size uint64
+	// UploadDuration is the time taken to transfer data to the remote.
+	UploadDuration time.Duration
+	// DownloadDuration is the time taken to transfer data from the remote.
+	DownloadDuration time.Duration
+	// MinimumLatency is the smallest observed round-trip latency.
+	MinimumLatency time.Duration
+	// AverageLatency is the mean observed round-trip latency.
+	AverageLatency time.Duration
+	// MaximumLatency is the largest observed round-trip latency.
+	MaximumLatency time.Duration
+}
+
+// throughput computes a throughput in bytes per second.
+func throughput(size uint64, duration time.Duration) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return float64(size) / duration.Seconds()
+}
+
+// UploadThroughput returns the upload throughput in bytes per second.
+func (r *BenchmarkResult) UploadThroughput() float64 {
+	return throughput(r.Size, r.UploadDuration)
+}
+
+// DownloadThroughput returns the download throughput in bytes per second.
+func (r *BenchmarkResult) DownloadThroughput() float64 {
+	return throughput(r.Size, r.DownloadDuration)
+}
+
+// benchmarkReader generates benchmark data by repeating a chunk of random data.
+type benchmarkReader struct {
+	// chunk is the random data chunk.
+	chunk []byte
+	// offset is the current offset within the chunk.
+	offset int
+	// remaining is the number of bytes remaining to be generated.
+	remaining uint64
+}
+
+// Read implements io.Reader.Read.
+func (r *benchmarkReader) Read(buffer []byte) (int, error) {
+	if r.remaining == 0 {
+		return 0, io.EOF
+	}
+	if uint64(len(buffer)) > r.remaining {
+		buffer = buffer[:r.remaining]
+	}
+	count := copy(buffer, r.chunk[r.offset:])
+	r.offset = (r.offset + count) % len(r.chunk)
+	r.remaining -= uint64(count)
+	return count, nil
+}
+
+// receiveBenchmarkStatus reads a status byte from the benchmark server.
+func receiveBenchmarkStatus(reader io.Reader) error {
+	var status [1]byte
+	if _, err := io.ReadFull(reader, status[:]); err != nil {
+		return errors.Wrap(err, "unable to receive status")
+	} else if status[0] != benchmarkStatusSuccess {
+		return errors.New("remote reported failure")
+	}
+	return nil
+}
+
+// RunBenchmark performs the client side of a transport benchmark over the
+// specified stream, which must be connected to a server running
+// ServeBenchmark. It performs the specified number of round-trip latency
+// probes and then transfers the specified number of bytes of random data in
+// each direction, verifying that the data returned by the server matches the
+// data sent to it.
+func RunBenchmark(stream io.ReadWriter, size uint64, probes uint32) (*BenchmarkResult, error) {
+	// Validate parameters.
+	if size == 0 {
+		return nil, errors.New("benchmark size must be non-zero")
+	} else if probes == 0 {
+		return nil, errors.New("benchmark probe count must be non-zero")
+	} else if probes > maximumBenchmarkProbes {
+		return nil, errors.Errorf("benchmark probe count exceeds maximum (%d)", maximumBenchmarkProbes)
+	}
+
+	// Generate the random data chunk before any timing starts.
+	chunkSize := uint64(benchmarkChunkSize)
+	if size < chunkSize {
+		chunkSize = size
+	}
+	chunk, err := random.New(int(chunkSize))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate benchmark data")
+	}
+
+	// Send the benchmark parameters.
+	var header [12]byte
+	binary.BigEndian.PutUint64(header[:8], size)
+	binary.BigEndian.PutUint32(header[8:], probes)
+	if _, err := stream.Write(header[:]); err != nil {
+		return nil, errors.Wrap(err, "unable to send benchmark parameters")
+	}
+
+	// Perform latency probes. Each probe is a single byte echoed back by the
+	// server.
+	result := &BenchmarkResult{Size: size}
+	var total time.Duration
+	for i := uint32(0); i < probes; i++ {
+		probe := [1]byte{byte(i)}
+		start := time.Now()
+		if _, err := stream.Write(probe[:]); err != nil {
+			return nil, errors.Wrap(err, "unable to send latency probe")
+		} else if _, err = io.ReadFull(stream, probe[:]); err != nil {
+			return nil, errors.Wrap(err, "unable to receive latency probe")
+		} else if probe[0] != byte(i) {
+			return nil, errors.New("latency probe response mismatch")
+		}
+		latency := time.Since(start)
+		total += latency
+		if i == 0 || latency < result.MinimumLatency {
+			result.MinimumLatency = latency
+		}
+		if latency > result.MaximumLatency {
+			result.MaximumLatency = latency
+		}
+	}
+	result.AverageLatency = total / time.Duration(probes)
+
+	// Perform the upload, computing a digest of the data as it's sent. The
+	// upload is considered complete once the server acknowledges that the data
+	// has been fully written to storage.
+	uploadDigest := sha256.New()
+	data := io.TeeReader(&benchmarkReader{chunk: chunk, remaining: size}, uploadDigest)
+	start := time.Now()
+	if _, err := io.Copy(stream, data); err != nil {
+		return nil, errors.Wrap(err, "unable to upload benchmark data")
+	} else if err = receiveBenchmarkStatus(stream); err != nil {
+		return nil, errors.Wrap(err, "upload failed")
+	}
+	result.UploadDuration = time.Since(start)
+
+	// Signal the server to begin the download and receive the data, computing
+	// a digest as it arrives.
+	downloadDigest := sha256.New()
+	start = time.Now()
+	if _, err := stream.Write([]byte{benchmarkStatusSuccess}); err != nil {
+		return nil, errors.Wrap(err, "unable to request download")
+	} else if _, err = io.CopyN(downloadDigest, stream, int64(size)); err != nil {
+		return nil, errors.Wrap(err, "unable to download benchmark data")
+	}
+	result.DownloadDuration = time.Since(start)
+
+	// Verify that the downloaded data matches the uploaded data.
+	if !bytes.Equal(uploadDigest.Sum(nil), downloadDigest.Sum(nil)) {
+		return nil, errors.New("downloaded data does not match uploaded data")
+	}
+
+	// Wait for the server to confirm removal of its temporary data.
+	if err := receiveBenchmarkStatus(stream); err != nil {
+		return nil, errors.Wrap(err, "remote cleanup failed")
+	}
+
+	// Success.
+	return result, nil
+}
+
+// ServeBenchmark performs the server side of a transport benchmark over the
+// specified stream. Uploaded data is staged in a temporary file within the
+// specified directory (or the default temporary directory if empty), which is
+// removed before the benchmark completes, regardless of its outcome.
+func ServeBenchmark(stream io.ReadWriter, temporaryDirectory string) error {
+	// Receive the benchmark parameters.
+	var header [12]byte
+	if _, err := io.ReadFull(stream, header[:]); err != nil {
+		return errors.Wrap(err, "unable to receive benchmark parameters")
+	}
+	size := binary.BigEndian.Uint64(header[:8])
+	probes := binary.BigEndian.Uint32(header[8:])
+	if size == 0 {
+		return errors.New("benchmark size must be non-zero")
+	} else if probes == 0 || probes > maximumBenchmarkProbes {
+		return errors.New("invalid benchmark probe count")
+	}
+
+	// Echo latency probes.
+	for i := uint32(0); i < probes; i++ {
+		var probe [1]byte
+		if _, err := io.ReadFull(stream, probe[:]); err != nil {
+			return errors.Wrap(err, "unable to receive latency probe")
+		} else if _, err = stream.Write(probe[:]); err != nil {
+			return errors.Wrap(err, "unable to send latency probe")
+		}
+	}
+
+	// Create a temporary file to stage uploaded data and defer its removal.
+	staging, err := ioutil.TempFile(temporaryDirectory, benchmarkTemporaryPrefix)
+	if err != nil {
+		stream.Write([]byte{benchmarkStatusFailure})
+		return errors.Wrap(err, "unable to create temporary file")
+	}
+	stagingPath := staging.Name()
+	defer func() {
+		staging.Close()
+		os.Remove(stagingPath)
+	}()
+
+	// Receive the uploaded data, ensure that it's been committed to storage,
+	// and acknowledge it.
+	if _, err := io.CopyN(staging, stream, int64(size)); err != nil {
+		return errors.Wrap(err, "unable to receive benchmark data")
+	} else if err = staging.Sync(); err != nil {
+		stream.Write([]byte{benchmarkStatusFailure})
+		return errors.Wrap(err, "unable to sync benchmark data")
+	} else if _, err = stream.Write([]byte{benchmarkStatusSuccess}); err != nil {
+		return errors.Wrap(err, "unable to acknowledge upload")
+	}
+
+	// Wait for the download request and send the staged data back.
+	var request [1]byte
+	if _, err := io.ReadFull(stream, request[:]); err != nil {
+		return errors.Wrap(err, "unable to receive download request")
+	} else if _, err = staging.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrap(err, "unable to rewind benchmark data")
+	} else if _, err = io.CopyN(stream, staging, int64(size)); err != nil {
+		return errors.Wrap(err, "unable to send benchmark data")
+	}
+
+	// Remove the temporary file and report the result.
+	closeErr := staging.Close()
+	removeErr := os.Remove(stagingPath)
+	if removeErr != nil {
+		stream.Write([]byte{benchmarkStatusFailure})
+		return errors.Wrap(removeErr, "unable to remove temporary file")
+	} else if closeErr != nil {
+		stream.Write([]byte{benchmarkStatusFailure})
+		return errors.Wrap(closeErr, "unable to close temporary file")
+	} else if _, err := stream.Write([]byte{benchmarkStatusSuccess}); err != nil {
+		return errors.Wrap(err, "unable to send cleanup status")
+	}
+
+	// Success.
+	return nil
+}
+
+// Benchmark connects to an agent using the specified transport and prompter,
+// runs a benchmark (using the specified data size and probe count), and then
+// closes the connection.
+func Benchmark(logger *logging.Logger, transport Transport, prompter string, size uint64, probes uint32) (*BenchmarkResult, error) {
+	// Connect to the agent in benchmark mode and defer closure of the
+	// connection.
+	connection, err := Dial(logger, transport, ModeBenchmark, prompter)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to connect to agent")
+	}
+	defer connection.Close()
+
+	// Run the benchmark.
+	return RunBenchmark(connection, size, probes)
+}
@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// testBenchmark runs a benchmark over the specified connection pair with a
+// server staging data in a temporary directory and verifies that the resulting
+// measurements are plausible and that the server's temporary data is removed.
+func testBenchmark(t *testing.T, client, server net.Conn) {
+	// Create a temporary directory for the server and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_agent_benchmark")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Serve the benchmark in the background.
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- ServeBenchmark(server, directory)
+	}()
+
+	// Run the benchmark and check the server result.
+	const size = 3*benchmarkChunkSize + 12345
+	const probes = 5
+	result, err := RunBenchmark(client, size, probes)
+	if err != nil {
+		t.Fatal("benchmark failed:", err)
+	} else if err = <-serverErrors; err != nil {
+		t.Fatal("benchmark server failed:", err)
+	}
+
+	// Verify that the measurements are plausible.
+	if result.Size != size {
+		t.Error("unexpected benchmark size:", result.Size)
+	}
+	if result.UploadDuration <= 0 || result.UploadThroughput() <= 0 {
+		t.Error("invalid upload measurement:", result.UploadDuration)
+	}
+	if result.DownloadDuration <= 0 || result.DownloadThroughput() <= 0 {
+		t.Error("invalid download measurement:", result.DownloadDuration)
+	}
+	if result.MinimumLatency <= 0 ||
+		result.MinimumLatency > result.AverageLatency ||
+		result.AverageLatency > result.MaximumLatency {
+		t.Error("inconsistent latency measurements:",
+			result.MinimumLatency, result.AverageLatency, result.MaximumLatency,
+		)
+	}
+	if result.MaximumLatency > 10*time.Second {
+		t.Error("implausible maximum latency:", result.MaximumLatency)
+	}
+
+	// Verify that the server removed its temporary data.
+	if contents, err := ioutil.ReadDir(directory); err != nil {
+		t.Fatal("unable to read temporary directory:", err)
+	} else if len(contents) != 0 {
+		t.Error("benchmark server left temporary data:", len(contents), "entries")
+	}
+}
+
+// TestBenchmarkInMemory tests a benchmark over an in-memory connection.
+func TestBenchmarkInMemory(t *testing.T) {
+	// Create a connection pair and defer their closure.
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	// Run the benchmark.
+	testBenchmark(t, client, server)
+}
+
+// TestBenchmarkLoopback tests a benchmark over a loopback TCP connection.
+func TestBenchmarkLoopback(t *testing.T) {
+	// Create a listener and defer its closure.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("unable to create listener:", err)
+	}
+	defer listener.Close()
+
+	// Accept a connection in the background.
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		connection, err := listener.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- connection
+	}()
+
+	// Connect to the listener and defer closure of the connection.
+	client, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal("unable to connect to listener:", err)
+	}
+	defer client.Close()
+	server, ok := <-accepted
+	if !ok {
+		t.Fatal("unable to accept connection")
+	}
+	defer server.Close()
+
+	// Run the benchmark.
+	testBenchmark(t, client, server)
+}
+
+// TestBenchmarkServerFailureCleanup tests that the benchmark server removes its
+// temporary data when the client disconnects mid-transfer.
+func TestBenchmarkServerFailureCleanup(t *testing.T) {
+	// Create a temporary directory for the server and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_agent_benchmark")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create a connection pair and serve the benchmark in the background.
+	client, server := net.Pipe()
+	defer server.Close()
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- ServeBenchmark(server, directory)
+	}()
+
+	// Send benchmark parameters and a single probe, then send partial upload
+	// data and disconnect.
+	header := []byte{0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 1}
+	if _, err := client.Write(header); err != nil {
+		t.Fatal("unable to send parameters:", err)
+	}
+	probe := make([]byte, 1)
+	if _, err := client.Write(probe); err != nil {
+		t.Fatal("unable to send probe:", err)
+	} else if _, err = client.Read(probe); err != nil {
+		t.Fatal("unable to receive probe:", err)
+	}
+	if _, err := client.Write(make([]byte, 1024)); err != nil {
+		t.Fatal("unable to send partial data:", err)
+	}
+	client.Close()
+
+	// Verify that the server failed and removed its temporary data.
+	if err := <-serverErrors; err == nil {
+		t.Error("benchmark server succeeded with truncated upload")
+	}
+	if contents, err := ioutil.ReadDir(directory); err != nil {
+		t.Fatal("unable to read temporary directory:", err)
+	} else if len(contents) != 0 {
+		t.Error("benchmark server left temporary data:", len(contents), "entries")
+	}
+}
+
+// TestRunBenchmarkInvalidParameters tests that RunBenchmark rejects invalid
+// parameters.
+func TestRunBenchmarkInvalidParameters(t *testing.T) {
+	if _, err := RunBenchmark(nil, 0, 1); err == nil {
+		t.Error("zero benchmark size accepted")
+	}
+	if _, err := RunBenchmark(nil, 1, 0); err == nil {
+		t.Error("zero probe count accepted")
+	}
+	if _, err := RunBenchmark(nil, 1, maximumBenchmarkProbes+1); err == nil {
+		t.Error("excessive probe count accepted")
+	}
+}
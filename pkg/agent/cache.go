@@ -0,0 +1,213 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/google/uuid"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/prompting"
+)
+
+const (
+	// cachedAgentPrefix is the name prefix used for agent executables stored
+	// on a cache host. Cached executables are keyed by digest, so their
+	// presence in the cache implies their freshness.
+	cachedAgentPrefix = "." + BaseName + "-cache-"
+)
+
+// Cache describes a regional agent cache host. Rather than uploading agent
+// executables directly to each remote, the daemon uploads each executable to
+// the cache host once and remotes then pull it from the cache host. This is
+// useful when deploying agents to many remotes that are distant from the
+// daemon but close to one another. Cache hosts must be POSIX systems, and
+// remotes must be able to reach them using scp without interactive
+// authentication.
+type Cache struct {
+	// Transport is the transport used to populate the cache host. Cached
+	// executables are stored in the home directory of the transport's user.
+	Transport Transport
+	// Host is the scp-style host specification (of the form [user@]host) that
+	// remotes use to pull cached executables from the cache host.
+	Host string
+}
+
+// CachingTransport is an optional interface that transports can implement in
+// order to install agents via a regional agent cache. Only POSIX remotes use
+// the cache, with all other remotes (as well as any remotes for which the
+// cache fails) receiving agent executables directly.
+type CachingTransport interface {
+	// AgentCache returns the regional agent cache to use when installing
+	// agents. It may return nil if no cache is used.
+	AgentCache() *Cache
+}
+
+// RelayingTransport is an optional interface that caching transports can
+// implement in order to relay cached agent executables to remotes that can't
+// pull them from the cache host directly (e.g. remotes that can only be reached
+// via a bastion host).
+type RelayingTransport interface {
+	// RelayFromCache copies the cached agent executable with the specified name
+	// from the specified cache to the remote, routing the transfer through the
+	// local host. The remote name has the same semantics as for Copy.
+	RelayFromCache(cache *Cache, cachedName, remoteName string) error
+}
+
+// ParseCacheHost parses a cache host specification of the form [user@]host.
+func ParseCacheHost(specification string) (string, string, error) {
+	// Reject specifications that can't be safely embedded in commands or that
+	// would be interpreted as something other than a host by scp.
+	if specification == "" {
+		return "", "", errors.New("empty cache host specification")
+	} else if strings.ContainsAny(specification, " \t\n:/\\'\"") {
+		return "", "", errors.New("cache host specification contains invalid characters")
+	} else if strings.HasPrefix(specification, "-") {
+		return "", "", errors.New("cache host specification begins with '-'")
+	}
+
+	// Split the user and host.
+	var user, host string
+	if at := strings.LastIndexByte(specification, '@'); at >= 0 {
+		user, host = specification[:at], specification[at+1:]
+		if user == "" {
+			return "", "", errors.New("empty user in cache host specification")
+		}
+	} else {
+		host = specification
+	}
+	if host == "" {
+		return "", "", errors.New("empty host in cache host specification")
+	}
+
+	// Success.
+	return user, host, nil
+}
+
+// agentCache returns the regional agent cache used by a transport if it
+// implements CachingTransport and nil otherwise.
+func agentCache(transport Transport) *Cache {
+	if cachingTransport, ok := transport.(CachingTransport); ok {
+		return cachingTransport.AgentCache()
+	}
+	return nil
+}
+
+// cachedAgentName computes the name of the cached agent executable with the
+// specified digest.
+func cachedAgentName(digest string) string {
+	return cachedAgentPrefix + digest
+}
+
+// pullCommand computes the command that a remote uses to pull the cached
+// executable with the specified name to the specified destination.
+func (c *Cache) pullCommand(name, destination string) string {
+	return fmt.Sprintf("scp -q -o BatchMode=yes %s:%s %s", c.Host, name, destination)
+}
+
+var (
+	// cachePopulationLocksLock serializes access to cachePopulationLocks.
+	cachePopulationLocksLock sync.Mutex
+	// cachePopulationLocks maps cache host and digest combinations to locks
+	// that serialize population of the corresponding cache entries, ensuring
+	// that concurrent installations only upload each executable once.
+	cachePopulationLocks = make(map[string]*sync.Mutex)
+)
+
+// cachePopulationLock returns the lock used to serialize population of the
+// specified cache entry.
+func cachePopulationLock(host, digest string) *sync.Mutex {
+	// Lock the registry and defer its release.
+	cachePopulationLocksLock.Lock()
+	defer cachePopulationLocksLock.Unlock()
+
+	// Look up or create the lock.
+	key := host + "/" + digest
+	lock, ok := cachePopulationLocks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		cachePopulationLocks[key] = lock
+	}
+	return lock
+}
+
+// populate ensures that the cache holds the agent executable at the specified
+// local path, which has the specified digest, uploading it if necessary. The
+// executable is uploaded under a temporary name and then moved into place so
+// that remotes never pull partial uploads.
+func (c *Cache) populate(agentExecutable, digest string) error {
+	// Serialize population of this entry and defer release of the lock.
+	lock := cachePopulationLock(c.Host, digest)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// If the executable is already cached, then we're done.
+	name := cachedAgentName(digest)
+	if err := run(c.Transport, fmt.Sprintf("test -f %s", name)); err == nil {
+		return nil
+	}
+
+	// Upload the executable under a temporary name.
+	randomUUID, err := uuid.NewRandom()
+	if err != nil {
+		return errors.Wrap(err, "unable to generate UUID for cache upload")
+	}
+	temporary := name + "-" + randomUUID.String()
+	if err := c.Transport.Copy(agentExecutable, temporary); err != nil {
+		return errors.Wrap(err, "unable to upload agent to cache")
+	}
+
+	// Move the executable into place.
+	if err := run(c.Transport, fmt.Sprintf("mv -f %s %s", temporary, name)); err != nil {
+		run(c.Transport, fmt.Sprintf("rm -f %s", temporary))
+		return errors.Wrap(err, "unable to move cached agent into place")
+	}
+
+	// Success.
+	return nil
+}
+
+// transferAgent copies the agent executable at the specified local path (which
+// has the specified digest) to the specified destination on the remote. If the
+// transport provides a regional agent cache and the remote is a POSIX system,
+// then the executable is pulled from the cache (after populating the cache if
+// necessary). If the remote can't pull from the cache and the transport
+// implements RelayingTransport, then the executable is relayed from the cache
+// through the local host. Otherwise (or if the cache fails) it's copied
+// directly. In any case, the remote agent verifies its digest before installing
+// itself.
+func transferAgent(logger *logging.Logger, transport Transport, prompter, agentExecutable, digest, destination string, posix bool) error {
+	// Attempt to use the cache, if any.
+	if cache := agentCache(transport); cache != nil && posix {
+		if err := prompting.Message(prompter, "Copying agent via cache..."); err != nil {
+			return errors.Wrap(err, "unable to message prompter")
+		}
+		name := cachedAgentName(digest)
+		if err := cache.populate(agentExecutable, digest); err != nil {
+			logger.Warning("Unable to populate agent cache:", err)
+		} else if err = run(transport, cache.pullCommand(name, destination)); err != nil {
+			logger.Warning("Unable to pull agent from cache:", err)
+			if relayingTransport, ok := transport.(RelayingTransport); ok {
+				if err := prompting.Message(prompter, "Relaying agent via cache..."); err != nil {
+					return errors.Wrap(err, "unable to message prompter")
+				}
+				if err := relayingTransport.RelayFromCache(cache, name, destination); err != nil {
+					logger.Warning("Unable to relay agent from cache:", err)
+				} else {
+					return nil
+				}
+			}
+		} else {
+			return nil
+		}
+	}
+
+	// Copy the agent directly.
+	if err := prompting.Message(prompter, "Copying agent..."); err != nil {
+		return errors.Wrap(err, "unable to message prompter")
+	}
+	return transport.Copy(agentExecutable, destination)
+}
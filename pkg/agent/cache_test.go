@@ -0,0 +1,357 @@
+package agent
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// testDirectoryTransport is a Transport that treats a local directory as the
+// home directory of a remote POSIX system. It counts the copies performed via
+// Copy and runs commands using the local shell.
+type testDirectoryTransport struct {
+	// home is the directory acting as the remote home directory.
+	home string
+	// copies is the number of copies performed via Copy. It must be accessed
+	// atomically.
+	copies int32
+	// failCopies indicates that copy operations should fail.
+	failCopies bool
+}
+
+// Copy implements agent.Transport.Copy.
+func (t *testDirectoryTransport) Copy(localPath, remoteName string) error {
+	if t.failCopies {
+		return errors.New("copying disabled")
+	}
+	atomic.AddInt32(&t.copies, 1)
+	contents, err := ioutil.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(t.home, remoteName), contents, 0700)
+}
+
+// Command implements agent.Transport.Command.
+func (t *testDirectoryTransport) Command(command string) (*exec.Cmd, error) {
+	process := exec.Command("/bin/sh", "-c", command)
+	process.Dir = t.home
+	return process, nil
+}
+
+// ClassifyError implements agent.Transport.ClassifyError.
+func (t *testDirectoryTransport) ClassifyError(_ *os.ProcessState, _ string) (bool, bool, error) {
+	return false, false, errors.New("error classification not supported")
+}
+
+// testCacheClientTransport extends testDirectoryTransport with a regional
+// agent cache, emulating scp-based pulls from the cache by copying directly
+// from the cache transport's home directory.
+type testCacheClientTransport struct {
+	testDirectoryTransport
+	// cache is the regional agent cache.
+	cache *Cache
+	// pulls is the number of pulls performed from the cache. It must be
+	// accessed atomically.
+	pulls int32
+	// failPulls indicates that pulls from the cache should fail, emulating a
+	// remote that can't reach the cache host.
+	failPulls bool
+}
+
+// Command implements agent.Transport.Command.
+func (t *testCacheClientTransport) Command(command string) (*exec.Cmd, error) {
+	if !strings.HasPrefix(command, "scp ") {
+		return t.testDirectoryTransport.Command(command)
+	}
+	atomic.AddInt32(&t.pulls, 1)
+	if t.failPulls {
+		return t.testDirectoryTransport.Command("exit 1")
+	}
+	fields := strings.Fields(command)
+	source := strings.TrimPrefix(fields[len(fields)-2], t.cache.Host+":")
+	cacheHome := t.cache.Transport.(*testDirectoryTransport).home
+	return t.testDirectoryTransport.Command(
+		"cp " + filepath.Join(cacheHome, source) + " " + fields[len(fields)-1],
+	)
+}
+
+// AgentCache implements agent.CachingTransport.AgentCache.
+func (t *testCacheClientTransport) AgentCache() *Cache {
+	return t.cache
+}
+
+// testRelayingTransport extends testCacheClientTransport with relaying from
+// the cache, emulating third-party copies by copying directly from the cache
+// transport's home directory.
+type testRelayingTransport struct {
+	*testCacheClientTransport
+	// relays is the number of relays performed from the cache.
+	relays int
+}
+
+// RelayFromCache implements agent.RelayingTransport.RelayFromCache.
+func (t *testRelayingTransport) RelayFromCache(cache *Cache, cachedName, remoteName string) error {
+	t.relays++
+	cacheHome := cache.Transport.(*testDirectoryTransport).home
+	contents, err := ioutil.ReadFile(filepath.Join(cacheHome, cachedName))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(t.home, remoteName), contents, 0700)
+}
+
+// testCacheEnvironment creates a temporary directory containing a fake agent
+// executable, a cache host, and the specified number of cache clients. It
+// returns the temporary directory (which the caller should remove), the agent
+// executable path, the cache, and the clients.
+func testCacheEnvironment(t *testing.T, clients int) (string, string, *Cache, []*testCacheClientTransport) {
+	// Skip on systems without a POSIX shell.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Create a temporary directory.
+	directory, err := ioutil.TempDir("", "mutagen_agent_cache")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+
+	// Create the fake agent executable.
+	agentExecutable := filepath.Join(directory, "agent")
+	if err := ioutil.WriteFile(agentExecutable, []byte("agent content"), 0700); err != nil {
+		os.RemoveAll(directory)
+		t.Fatal("unable to create agent executable:", err)
+	}
+
+	// Create the cache and its clients.
+	var homes []string
+	for i := 0; i <= clients; i++ {
+		home, err := ioutil.TempDir(directory, "home")
+		if err != nil {
+			os.RemoveAll(directory)
+			t.Fatal("unable to create home directory:", err)
+		}
+		homes = append(homes, home)
+	}
+	cache := &Cache{
+		Transport: &testDirectoryTransport{home: homes[0]},
+		Host:      "cache@cache.example.org",
+	}
+	var transports []*testCacheClientTransport
+	for _, home := range homes[1:] {
+		transports = append(transports, &testCacheClientTransport{
+			testDirectoryTransport: testDirectoryTransport{home: home},
+			cache:                  cache,
+		})
+	}
+
+	// Done.
+	return directory, agentExecutable, cache, transports
+}
+
+// verifyTransferredAgent verifies that the agent was transferred to the
+// specified destination.
+func verifyTransferredAgent(t *testing.T, transport *testCacheClientTransport, destination string) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Verify the content.
+	if content, err := ioutil.ReadFile(filepath.Join(transport.home, destination)); err != nil {
+		t.Error("unable to read transferred agent:", err)
+	} else if string(content) != "agent content" {
+		t.Error("transferred agent content incorrect:", string(content))
+	}
+}
+
+// TestTransferAgentViaCache tests that agent transfers populate the cache on a
+// miss and pull from the cache on a hit without any direct copies.
+func TestTransferAgentViaCache(t *testing.T) {
+	// Create the test environment.
+	directory, agentExecutable, cache, clients := testCacheEnvironment(t, 2)
+	defer os.RemoveAll(directory)
+	cacheTransport := cache.Transport.(*testDirectoryTransport)
+
+	// Perform a transfer that populates the cache.
+	if err := transferAgent(nil, clients[0], "", agentExecutable, "digest1", "destination", true); err != nil {
+		t.Fatal("unable to transfer agent:", err)
+	}
+	verifyTransferredAgent(t, clients[0], "destination")
+	if cacheTransport.copies != 1 {
+		t.Error("cache not populated exactly once:", cacheTransport.copies)
+	} else if clients[0].copies != 0 {
+		t.Error("agent copied directly despite cache")
+	} else if clients[0].pulls != 1 {
+		t.Error("agent not pulled from cache")
+	}
+	if _, err := os.Lstat(filepath.Join(cacheTransport.home, cachedAgentName("digest1"))); err != nil {
+		t.Error("cached agent not found:", err)
+	}
+
+	// Perform a transfer that hits the cache.
+	if err := transferAgent(nil, clients[1], "", agentExecutable, "digest1", "destination", true); err != nil {
+		t.Fatal("unable to transfer agent:", err)
+	}
+	verifyTransferredAgent(t, clients[1], "destination")
+	if cacheTransport.copies != 1 {
+		t.Error("cache repopulated despite hit:", cacheTransport.copies)
+	} else if clients[1].copies != 0 {
+		t.Error("agent copied directly despite cache")
+	} else if clients[1].pulls != 1 {
+		t.Error("agent not pulled from cache")
+	}
+
+	// Perform a transfer with a different digest and verify that it isn't
+	// satisfied by the existing cache entry.
+	if err := transferAgent(nil, clients[1], "", agentExecutable, "digest2", "other", true); err != nil {
+		t.Fatal("unable to transfer agent:", err)
+	} else if cacheTransport.copies != 2 {
+		t.Error("cache not populated for new digest:", cacheTransport.copies)
+	}
+}
+
+// TestTransferAgentViaCacheConcurrent tests that concurrent agent transfers
+// only populate the cache once.
+func TestTransferAgentViaCacheConcurrent(t *testing.T) {
+	// Create the test environment.
+	directory, agentExecutable, cache, clients := testCacheEnvironment(t, 8)
+	defer os.RemoveAll(directory)
+
+	// Perform concurrent transfers.
+	var wait sync.WaitGroup
+	errs := make([]error, len(clients))
+	for c, client := range clients {
+		wait.Add(1)
+		go func(c int, client *testCacheClientTransport) {
+			errs[c] = transferAgent(nil, client, "", agentExecutable, "digest", "destination", true)
+			wait.Done()
+		}(c, client)
+	}
+	wait.Wait()
+
+	// Verify the results.
+	for c, client := range clients {
+		if errs[c] != nil {
+			t.Error("unable to transfer agent:", errs[c])
+		} else {
+			verifyTransferredAgent(t, client, "destination")
+		}
+	}
+	if copies := cache.Transport.(*testDirectoryTransport).copies; copies != 1 {
+		t.Error("cache not populated exactly once:", copies)
+	}
+}
+
+// TestTransferAgentCacheFallback tests that agent transfers fall back to
+// direct copies if the cache can't be used.
+func TestTransferAgentCacheFallback(t *testing.T) {
+	// Create the test environment.
+	directory, agentExecutable, cache, clients := testCacheEnvironment(t, 1)
+	defer os.RemoveAll(directory)
+	cacheTransport := cache.Transport.(*testDirectoryTransport)
+
+	// Verify that non-POSIX remotes bypass the cache.
+	if err := transferAgent(nil, clients[0], "", agentExecutable, "digest", "direct", false); err != nil {
+		t.Fatal("unable to transfer agent:", err)
+	}
+	verifyTransferredAgent(t, clients[0], "direct")
+	if cacheTransport.copies != 0 || clients[0].pulls != 0 {
+		t.Error("cache used for non-POSIX remote")
+	} else if clients[0].copies != 1 {
+		t.Error("agent not copied directly")
+	}
+
+	// Verify that a cache population failure triggers a direct copy.
+	cacheTransport.failCopies = true
+	if err := transferAgent(nil, clients[0], "", agentExecutable, "digest", "fallback", true); err != nil {
+		t.Fatal("unable to transfer agent:", err)
+	}
+	verifyTransferredAgent(t, clients[0], "fallback")
+	if clients[0].pulls != 0 {
+		t.Error("agent pulled from unpopulated cache")
+	} else if clients[0].copies != 2 {
+		t.Error("agent not copied directly after cache failure")
+	}
+}
+
+// TestTransferAgentCacheRelay tests that agent transfers are relayed from the
+// cache if the remote can't pull from the cache and the transport supports
+// relaying, and that they fall back to direct copies otherwise.
+func TestTransferAgentCacheRelay(t *testing.T) {
+	// Create the test environment with a client that can't pull from the
+	// cache.
+	directory, agentExecutable, _, clients := testCacheEnvironment(t, 1)
+	defer os.RemoveAll(directory)
+	clients[0].failPulls = true
+
+	// Verify that a transport without relaying support falls back to a direct
+	// copy.
+	if err := transferAgent(nil, clients[0], "", agentExecutable, "digest", "direct", true); err != nil {
+		t.Fatal("unable to transfer agent:", err)
+	}
+	verifyTransferredAgent(t, clients[0], "direct")
+	if clients[0].pulls != 1 {
+		t.Error("agent pull not attempted")
+	} else if clients[0].copies != 1 {
+		t.Error("agent not copied directly after pull failure")
+	}
+
+	// Verify that a transport with relaying support relays from the cache
+	// rather than copying directly.
+	relaying := &testRelayingTransport{testCacheClientTransport: clients[0]}
+	if err := transferAgent(nil, relaying, "", agentExecutable, "digest", "relayed", true); err != nil {
+		t.Fatal("unable to transfer agent:", err)
+	}
+	verifyTransferredAgent(t, clients[0], "relayed")
+	if relaying.relays != 1 {
+		t.Error("agent not relayed after pull failure")
+	} else if clients[0].copies != 1 {
+		t.Error("agent copied directly despite relaying")
+	}
+}
+
+// TestParseCacheHost tests ParseCacheHost.
+func TestParseCacheHost(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		specification string
+		expectFailure bool
+		expectedUser  string
+		expectedHost  string
+	}{
+		{"", true, "", ""},
+		{"cache.example.org", false, "", "cache.example.org"},
+		{"user@cache.example.org", false, "user", "cache.example.org"},
+		{"user@", true, "", ""},
+		{"@cache.example.org", true, "", ""},
+		{"-oProxyCommand=x", true, "", ""},
+		{"cache.example.org:22", true, "", ""},
+		{"cache example", true, "", ""},
+		{"cache'example", true, "", ""},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		user, host, err := ParseCacheHost(testCase.specification)
+		if testCase.expectFailure {
+			if err == nil {
+				t.Errorf("test case %d: parsing succeeded unexpectedly", i)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("test case %d: parsing failed unexpectedly: %v", i, err)
+		} else if user != testCase.expectedUser {
+			t.Errorf("test case %d: user does not match expected: %s != %s", i, user, testCase.expectedUser)
+		} else if host != testCase.expectedHost {
+			t.Errorf("test case %d: host does not match expected: %s != %s", i, host, testCase.expectedHost)
+		}
+	}
+}
@@ -29,6 +29,25 @@ const (
 	agentKillDelay = 5 * time.Second
 )
 
+// agentPath computes the path to the agent executable for the current Mutagen
+// version (sans any platform-specific suffix), relative to the user's home
+// directory on the remote, using the specified path separator.
+//
+// HACK: We're assuming that none of these path components have spaces in them,
+// but since we control all of them, this is probably okay.
+func agentPath(pathSeparator string) string {
+	dataDirectoryName := filesystem.MutagenDataDirectoryName
+	if mutagen.DevelopmentModeEnabled {
+		dataDirectoryName = filesystem.MutagenDataDirectoryDevelopmentName
+	}
+	return strings.Join([]string{
+		dataDirectoryName,
+		filesystem.MutagenAgentsDirectoryName,
+		mutagen.Version,
+		BaseName,
+	}, pathSeparator)
+}
+
 // connect connects to an agent-based endpoint using the specified transport,
 // connection mode, and prompter. It accepts a hint as to whether or not the
 // remote environment is cmd.exe-based and returns hints as to whether or not
@@ -44,9 +63,6 @@ func connect(logger *logging.Logger, transport Transport, mode, prompter string,
 	// commands with forward slashes is actually the way that we detect cmd.exe
 	// environments.
 	//
-	// HACK: We're assuming that none of these path components have spaces in
-	// them, but since we control all of them, this is probably okay.
-	//
 	// HACK: When invoking on Windows systems (whether inside a POSIX
 	// environment or cmd.exe), we can leave the "exe" suffix off the target
 	// name. Fortunately this allows us to also avoid having to try the
@@ -55,16 +71,7 @@ func connect(logger *logging.Logger, transport Transport, mode, prompter string,
 	if cmdExe {
 		pathSeparator = "\\"
 	}
-	dataDirectoryName := filesystem.MutagenDataDirectoryName
-	if mutagen.DevelopmentModeEnabled {
-		dataDirectoryName = filesystem.MutagenDataDirectoryDevelopmentName
-	}
-	agentInvocationPath := strings.Join([]string{
-		dataDirectoryName,
-		filesystem.MutagenAgentsDirectoryName,
-		mutagen.Version,
-		BaseName,
-	}, pathSeparator)
+	agentInvocationPath := agentPath(pathSeparator)
 
 	// Compute the command to invoke.
 	command := fmt.Sprintf("%s %s", agentInvocationPath, mode)
@@ -77,7 +84,13 @@ func connect(logger *logging.Logger, transport Transport, mode, prompter string,
 	if err := prompting.Message(prompter, message); err != nil {
 		return nil, false, false, errors.Wrap(err, "unable to message prompter")
 	}
-	agentProcess, err := transport.Command(command)
+	limits := resourceLimits(transport)
+	if cmdExe && !limits.IsZero() {
+		return nil, false, false, errors.New("agent resource limits are not supported in cmd.exe environments")
+	} else if cmdExe && escalatesPrivileges(transport) {
+		return nil, false, false, errors.New("privilege escalation is not supported in cmd.exe environments")
+	}
+	agentProcess, err := agentCommand(transport, command)
 	if err != nil {
 		return nil, false, false, errors.Wrap(err, "unable to create agent command")
 	}
@@ -113,7 +126,7 @@ func connect(logger *logging.Logger, transport Transport, mode, prompter string,
 
 	// Perform a handshake with the remote to ensure that we're talking with a
 	// Mutagen agent.
-	if err := ClientHandshake(connection); err != nil {
+	if handshakeErr := ClientHandshake(connection); handshakeErr != nil {
 		// Close the connection to ensure that the underlying process and its
 		// I/O-forwarding Goroutines have terminated. The error returned from
 		// Close will be non-nil if the process exits with a non-0 exit code, so
@@ -128,24 +141,42 @@ func connect(logger *logging.Logger, transport Transport, mode, prompter string,
 			return nil, false, false, errors.New("remote did not return UTF-8 output")
 		}
 
+		// If the agent was running under a memory limit, then check whether or
+		// not it terminated due to exceeding that limit.
+		if err := limits.classifyTermination(agentProcess.ProcessState); err != nil {
+			return nil, false, false, errors.Wrap(err, "agent handshake failed")
+		}
+
 		// See if we can understand the exact nature of the failure. In
 		// particular, we want to identify whether or not we should try to
 		// (re-)install the agent binary and whether or not we're talking to a
 		// Windows cmd.exe environment. We have to delegate this responsibility
 		// to the transport, because each has different error classification
-		// mechanisms. If the transport can't figure it out but we have some
-		// error output, then give it to the user, because they're probably in a
-		// better place to interpret the error output then they are to interpret
-		// the transport's reason for classification failure. If we don't have
+		// mechanisms. If the transport identified a specific cause of failure
+		// from the error output, then report that cause. Otherwise, if the
+		// transport can't figure it out but we have some error output, then
+		// give it to the user, because they're probably in a better place to
+		// interpret the error output then they are to interpret the
+		// transport's reason for classification failure. If we don't have
 		// error output, then just tell the user why the transport failed to
-		// classify the failure.
+		// classify the failure. An exception is made for failures with no
+		// error output that are due to transient stream errors (e.g. a race
+		// with remote shell startup), which are flagged as retryable. Failures
+		// with error output (e.g. authentication failures) aren't retried.
 		tryInstall, cmdExe, err := transport.ClassifyError(agentProcess.ProcessState, errorOutput)
 		if err != nil {
-			if errorOutput != "" {
+			var identified *IdentifiedFailureError
+			if errors.As(err, &identified) {
+				return nil, false, false, errors.Wrap(identified.Err, "agent handshake failed")
+			} else if errorOutput != "" {
 				return nil, false, false, errors.Errorf(
 					"agent handshake failed with error output:\n%s",
 					strings.TrimSpace(errorOutput),
 				)
+			} else if isTransientHandshakeError(handshakeErr) {
+				return nil, false, false, errors.Wrapf(errTransientHandshakeFailure,
+					"unable to handshake with agent process (%v)", handshakeErr,
+				)
 			}
 			return nil, false, false, errors.Wrap(err, "unable to classify agent handshake error")
 		}
@@ -159,32 +190,78 @@ func connect(logger *logging.Logger, transport Transport, mode, prompter string,
 	// process connection.
 	connection.SetKillDelay(time.Duration(0))
 
-	// Perform a version handshake.
+	// Perform a version handshake. If the agent's version doesn't match, then
+	// recommend (re-)installation to upgrade it.
 	if err := mutagen.ClientVersionHandshake(connection); err != nil {
 		connection.Close()
+		if err == mutagen.ErrVersionMismatch {
+			return nil, true, cmdExe, errors.Wrap(err, "version handshake error")
+		}
 		return nil, false, false, errors.Wrap(err, "version handshake error")
 	}
 
+	// If the agent is running under resource limits, then wrap the connection
+	// so that termination due to exceeding those limits is reported clearly.
+	if !limits.IsZero() {
+		return newLimitedConnection(connection, agentProcess, limits), false, false, nil
+	}
+
 	// Done.
 	return connection, false, false, nil
 }
 
+// connectWithRetry is a wrapper around connect that retries connections that
+// fail due to transient handshake failures according to the specified policy.
+func connectWithRetry(
+	logger *logging.Logger,
+	policy *HandshakeRetryPolicy,
+	transport Transport,
+	mode, prompter string,
+	cmdExe bool,
+) (connection net.Conn, tryInstall, cmdExeResult bool, err error) {
+	err = policy.retry(logger, func() error {
+		connection, tryInstall, cmdExeResult, err = connect(logger, transport, mode, prompter, cmdExe)
+		return err
+	})
+	return
+}
+
+// The following functions are used by Dial. They are variables so that they
+// can be overridden in tests.
+var (
+	// connectAgent is used to perform connection attempts.
+	connectAgent = connectWithRetry
+	// installAgent is used to perform installations.
+	installAgent = install
+	// upgradeLimiter is used to grab the agent upgrade limiter.
+	upgradeLimiter = currentUpgradeLimiter
+)
+
 // Dial connects to an agent-based endpoint using the specified transport,
-// connection mode, and prompter.
+// connection mode, and prompter. Connection attempts that fail due to
+// transient handshake failures are retried according to the handshake retry
+// policy configured by ConfigureHandshakeRetryPolicy. If the agent is missing
+// or has a mismatched version, then an installation is attempted, subject to
+// the rate limit configured by ConfigureUpgradeRate (with ErrUpgradeDeferred
+// returned if the installation can't be performed yet). If the newly installed
+// agent fails its handshake, then the installation is rolled back.
 func Dial(logger *logging.Logger, transport Transport, mode, prompter string) (net.Conn, error) {
 	// Validate that the mode is sane.
-	if !(mode == ModeSynchronizer || mode == ModeForwarder) {
+	if !(mode == ModeSynchronizer || mode == ModeForwarder || mode == ModeBenchmark) {
 		panic("invalid agent dial mode")
 	}
 
+	// Grab the handshake retry policy.
+	policy := currentHandshakeRetryPolicy()
+
 	// Attempt a connection. If this fails but we detect a Windows cmd.exe
 	// environment in the process, then re-attempt a connection under the
 	// cmd.exe assumption.
-	connection, tryInstall, cmdExe, err := connect(logger, transport, mode, prompter, false)
+	connection, tryInstall, cmdExe, err := connectAgent(logger, policy, transport, mode, prompter, false)
 	if err == nil {
 		return connection, nil
 	} else if cmdExe {
-		connection, tryInstall, cmdExe, err = connect(logger, transport, mode, prompter, true)
+		connection, tryInstall, cmdExe, err = connectAgent(logger, policy, transport, mode, prompter, true)
 		if err == nil {
 			return connection, nil
 		}
@@ -196,15 +273,28 @@ func Dial(logger *logging.Logger, transport Transport, mode, prompter string) (n
 		return nil, err
 	}
 
+	// Ensure that the installation is admitted by the upgrade limiter. If it
+	// isn't, then the installation will be reattempted on the next connection.
+	if err := upgradeLimiter().admit(); err != nil {
+		logger.Debug("Deferring agent installation due to upgrade rate limit")
+		return nil, err
+	}
+
 	// Attempt to install.
-	if err := install(logger, transport, prompter); err != nil {
+	path, posix, err := installAgent(logger, transport, prompter)
+	if err != nil {
 		return nil, errors.Wrap(err, "unable to install agent")
 	}
 
-	// Re-attempt connectivity.
-	connection, _, _, err = connect(logger, transport, mode, prompter, cmdExe)
+	// Re-attempt connectivity. If this fails, then roll back the installation
+	// so that a faulty agent doesn't replace a functioning one.
+	connection, _, _, err = connectAgent(logger, policy, transport, mode, prompter, cmdExe)
 	if err != nil {
-		return nil, err
+		logger.Warningf("Rolling back agent installation after handshake failure: %v", err)
+		if rollbackErr := run(transport, rollbackCommand(path, posix)); rollbackErr != nil {
+			return nil, errors.Wrapf(err, "installed agent failed (and rollback failed: %v)", rollbackErr)
+		}
+		return nil, errors.Wrap(err, "installed agent failed (installation rolled back)")
 	}
 	return connection, nil
 }
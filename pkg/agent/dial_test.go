@@ -1,3 +1,160 @@
 package agent
 
-// TODO: Implement.
+import (
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/mutagen"
+)
+
+// testUpgradeTransport is an agent.Transport implementation that simulates an
+// endpoint whose agent has a mismatched version. It records the commands that
+// it's asked to create, all of which succeed without doing anything.
+type testUpgradeTransport struct {
+	testCommandTransport
+	// healthy indicates whether or not the installed agent will pass its
+	// handshake.
+	healthy bool
+	// installations is the number of installations performed.
+	installations int
+}
+
+// Command implements agent.Transport.Command.
+func (t *testUpgradeTransport) Command(command string) (*exec.Cmd, error) {
+	t.commands = append(t.commands, command)
+	return exec.Command(os.Args[0], "-test.run=^$"), nil
+}
+
+// testConnectUpgrade is a connectAgent implementation for use with
+// testUpgradeTransport.
+func testConnectUpgrade(
+	_ *logging.Logger,
+	_ *HandshakeRetryPolicy,
+	transport Transport,
+	_, _ string,
+	_ bool,
+) (net.Conn, bool, bool, error) {
+	endpoint := transport.(*testUpgradeTransport)
+	if endpoint.installations == 0 {
+		return nil, true, false, errors.Wrap(mutagen.ErrVersionMismatch, "version handshake error")
+	} else if !endpoint.healthy {
+		return nil, false, false, errors.New("unable to handshake with agent process")
+	}
+	connection, _ := net.Pipe()
+	return connection, false, false, nil
+}
+
+// testInstallUpgrade is an installAgent implementation for use with
+// testUpgradeTransport.
+func testInstallUpgrade(_ *logging.Logger, transport Transport, _ string) (string, bool, error) {
+	transport.(*testUpgradeTransport).installations++
+	return "agent", true, nil
+}
+
+// setTestUpgradeHooks overrides the functions used by Dial and returns a
+// function that restores them.
+func setTestUpgradeHooks(limiter *UpgradeLimiter) func() {
+	originalConnect, originalInstall, originalLimiter := connectAgent, installAgent, upgradeLimiter
+	connectAgent = testConnectUpgrade
+	installAgent = testInstallUpgrade
+	upgradeLimiter = func() *UpgradeLimiter {
+		return limiter
+	}
+	return func() {
+		connectAgent, installAgent, upgradeLimiter = originalConnect, originalInstall, originalLimiter
+	}
+}
+
+// TestDialUpgradeRateLimit tests that upgrades of several version-mismatched
+// endpoints respect the upgrade rate limit, with deferred upgrades performed on
+// subsequent connection attempts once the limit allows.
+func TestDialUpgradeRateLimit(t *testing.T) {
+	// Create a limiter with a controllable clock and set up hooks.
+	now := time.Now()
+	limiter := NewUpgradeLimiter(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+	defer setTestUpgradeHooks(limiter)()
+
+	// Create endpoints.
+	endpoints := make([]*testUpgradeTransport, 5)
+	for e := range endpoints {
+		endpoints[e] = &testUpgradeTransport{healthy: true}
+	}
+
+	// dialAll dials all endpoints that haven't been upgraded and returns the
+	// number of successful connections.
+	dialAll := func() int {
+		var connected int
+		for _, endpoint := range endpoints {
+			if endpoint.installations > 0 {
+				continue
+			}
+			connection, err := Dial(logging.RootLogger, endpoint, ModeSynchronizer, "")
+			if err == nil {
+				connection.Close()
+				connected++
+			} else if err != ErrUpgradeDeferred {
+				t.Fatal("unexpected dial error:", err)
+			}
+		}
+		return connected
+	}
+
+	// Perform an initial connection attempt and verify that only the allowed
+	// number of upgrades occurred.
+	if connected := dialAll(); connected != 2 {
+		t.Fatal("unexpected number of upgraded endpoints:", connected)
+	}
+
+	// Verify that reconnection within the window doesn't allow more upgrades.
+	now = now.Add(30 * time.Second)
+	if connected := dialAll(); connected != 0 {
+		t.Fatal("upgrades performed within rate limit window:", connected)
+	}
+
+	// Verify that upgrades proceed once the window has elapsed.
+	now = now.Add(30 * time.Second)
+	if connected := dialAll(); connected != 2 {
+		t.Fatal("unexpected number of upgraded endpoints after window:", connected)
+	}
+	now = now.Add(time.Minute)
+	if connected := dialAll(); connected != 1 {
+		t.Fatal("final endpoint not upgraded")
+	}
+
+	// Verify that each endpoint was upgraded exactly once.
+	for e, endpoint := range endpoints {
+		if endpoint.installations != 1 {
+			t.Errorf("endpoint %d upgraded %d times", e, endpoint.installations)
+		}
+	}
+}
+
+// TestDialUpgradeRollback tests that an upgrade is rolled back if the newly
+// installed agent fails its handshake.
+func TestDialUpgradeRollback(t *testing.T) {
+	// Set up hooks without upgrade rate limiting.
+	defer setTestUpgradeHooks(nil)()
+
+	// Create endpoints with agents that will fail their handshake.
+	for e := 0; e < 3; e++ {
+		endpoint := &testUpgradeTransport{}
+		if _, err := Dial(logging.RootLogger, endpoint, ModeSynchronizer, ""); err == nil {
+			t.Fatal("dial succeeded with failing agent")
+		}
+		if endpoint.installations != 1 {
+			t.Error("unexpected number of installations:", endpoint.installations)
+		}
+		if len(endpoint.commands) != 1 {
+			t.Fatal("unexpected number of commands:", len(endpoint.commands))
+		} else if endpoint.commands[0] != rollbackCommand("agent", true) {
+			t.Error("rollback command not invoked:", endpoint.commands[0])
+		}
+	}
+}
@@ -3,8 +3,21 @@ package agent
 import (
 	"io"
 	"net"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+)
+
+const (
+	// defaultHandshakeRetries is the default maximum number of times that an
+	// agent connection will be retried after a transient handshake failure.
+	defaultHandshakeRetries = 2
+	// defaultHandshakeRetryDelay is the default delay between agent connection
+	// attempts after a transient handshake failure.
+	defaultHandshakeRetryDelay = 250 * time.Millisecond
 )
 
 // magicNumberBytes is a type capable of holding a Mutagen magic byte sequence.
@@ -47,13 +60,23 @@ func receiveAndCompareMagicNumber(reader io.Reader, expected magicNumberBytes) (
 	return received == expected, nil
 }
 
+// errServerMagicNumberIncorrect indicates that the magic number received by the
+// client didn't match the server magic number.
+var errServerMagicNumberIncorrect = errors.New("server magic number incorrect")
+
+// errTransientHandshakeFailure indicates that an agent handshake failed due to
+// a transient stream error and that the connection may be retried. It is
+// wrapped with additional context, so errors.Cause should be used when checking
+// for it.
+var errTransientHandshakeFailure = errors.New("transient handshake failure")
+
 // ClientHandshake performs a client-side handshake on the connection.
 func ClientHandshake(connection net.Conn) error {
 	// Receive the server's magic number.
 	if magicOk, err := receiveAndCompareMagicNumber(connection, serverMagicNumber); err != nil {
 		return errors.Wrap(err, "unable to receive server magic number")
 	} else if !magicOk {
-		return errors.New("server magic number incorrect")
+		return errServerMagicNumberIncorrect
 	}
 
 	// Send our magic number to the server.
@@ -84,3 +107,76 @@ func ServerHandshake(connection net.Conn) error {
 	// Success.
 	return nil
 }
+
+// isTransientHandshakeError determines whether or not an error returned by
+// ClientHandshake represents a transient stream failure, i.e. a premature end
+// of stream or a framing error (such as output from a remote shell startup
+// banner appearing before the server magic number).
+func isTransientHandshakeError(err error) bool {
+	cause := errors.Cause(err)
+	return cause == io.EOF ||
+		cause == io.ErrUnexpectedEOF ||
+		cause == errServerMagicNumberIncorrect
+}
+
+// HandshakeRetryPolicy specifies how agent connections are retried after
+// transient handshake failures.
+type HandshakeRetryPolicy struct {
+	// Retries is the maximum number of retries after the initial attempt.
+	Retries int
+	// Delay is the delay between attempts.
+	Delay time.Duration
+}
+
+// DefaultHandshakeRetryPolicy returns the default handshake retry policy.
+func DefaultHandshakeRetryPolicy() *HandshakeRetryPolicy {
+	return &HandshakeRetryPolicy{
+		Retries: defaultHandshakeRetries,
+		Delay:   defaultHandshakeRetryDelay,
+	}
+}
+
+var (
+	// handshakeRetryPolicyLock serializes access to handshakeRetryPolicy.
+	handshakeRetryPolicyLock sync.RWMutex
+	// handshakeRetryPolicy is the process-wide handshake retry policy.
+	handshakeRetryPolicy = DefaultHandshakeRetryPolicy()
+)
+
+// ConfigureHandshakeRetryPolicy sets the process-wide handshake retry policy
+// used by Dial. A nil policy restores the default policy.
+func ConfigureHandshakeRetryPolicy(policy *HandshakeRetryPolicy) {
+	// Use the default policy if none was specified.
+	if policy == nil {
+		policy = DefaultHandshakeRetryPolicy()
+	}
+
+	// Store the policy.
+	handshakeRetryPolicyLock.Lock()
+	handshakeRetryPolicy = policy
+	handshakeRetryPolicyLock.Unlock()
+}
+
+// currentHandshakeRetryPolicy returns the process-wide handshake retry policy.
+func currentHandshakeRetryPolicy() *HandshakeRetryPolicy {
+	handshakeRetryPolicyLock.RLock()
+	defer handshakeRetryPolicyLock.RUnlock()
+	return handshakeRetryPolicy
+}
+
+// retry invokes the specified operation, re-invoking it (up to the maximum
+// number of retries) if it fails with an error whose cause is
+// errTransientHandshakeFailure. Other errors are returned immediately. The
+// error from the last attempt is returned if all attempts fail.
+func (p *HandshakeRetryPolicy) retry(logger *logging.Logger, operation func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := operation()
+		if err == nil || errors.Cause(err) != errTransientHandshakeFailure || attempt >= p.Retries {
+			return err
+		}
+		logger.Debugf("Retrying agent connection after transient handshake failure (attempt %d of %d): %v",
+			attempt+1, p.Retries, err,
+		)
+		time.Sleep(p.Delay)
+	}
+}
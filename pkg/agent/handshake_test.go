@@ -1,3 +1,155 @@
 package agent
 
-// TODO: Implement.
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+)
+
+// TestHandshake tests a successful client/server handshake.
+func TestHandshake(t *testing.T) {
+	// Create a connection pair and defer their closure.
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	// Perform the server handshake in the background.
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- ServerHandshake(server)
+	}()
+
+	// Perform the client handshake and check the server result.
+	if err := ClientHandshake(client); err != nil {
+		t.Fatal("client handshake failed:", err)
+	} else if err = <-serverErrors; err != nil {
+		t.Fatal("server handshake failed:", err)
+	}
+}
+
+// TestClientHandshakeTransientErrors tests that stream failures during the
+// client handshake are classified as transient.
+func TestClientHandshakeTransientErrors(t *testing.T) {
+	// Test a premature end of stream.
+	client, server := net.Pipe()
+	server.Close()
+	if err := ClientHandshake(client); err == nil {
+		t.Error("client handshake succeeded on closed stream")
+	} else if !isTransientHandshakeError(err) {
+		t.Error("premature end of stream not classified as transient:", err)
+	}
+	client.Close()
+
+	// Test a framing error (e.g. a shell banner).
+	client, server = net.Pipe()
+	go func() {
+		server.Write([]byte("Welcome!\n"))
+		server.Close()
+	}()
+	if err := ClientHandshake(client); err == nil {
+		t.Error("client handshake succeeded with invalid magic number")
+	} else if !isTransientHandshakeError(err) {
+		t.Error("framing error not classified as transient:", err)
+	}
+	client.Close()
+
+	// Test that other errors aren't classified as transient.
+	if isTransientHandshakeError(errors.New("permission denied")) {
+		t.Error("non-stream error classified as transient")
+	}
+}
+
+// TestHandshakeRetryTransientFailure tests that an operation that fails
+// transiently is retried until it succeeds.
+func TestHandshakeRetryTransientFailure(t *testing.T) {
+	// Create a policy.
+	policy := &HandshakeRetryPolicy{Retries: 3, Delay: time.Millisecond}
+
+	// Create a handshake stub that fails transiently twice before succeeding.
+	var attempts int
+	stub := func() error {
+		attempts++
+		if attempts <= 2 {
+			return errors.Wrap(errTransientHandshakeFailure, "stub failure")
+		}
+		return nil
+	}
+
+	// Perform the operation and verify the result.
+	if err := policy.retry(logging.RootLogger, stub); err != nil {
+		t.Fatal("operation failed:", err)
+	} else if attempts != 3 {
+		t.Error("unexpected number of attempts:", attempts, "!=", 3)
+	}
+}
+
+// TestHandshakeRetryExhausted tests that retries are bounded.
+func TestHandshakeRetryExhausted(t *testing.T) {
+	// Create a policy.
+	policy := &HandshakeRetryPolicy{Retries: 2}
+
+	// Create a handshake stub that always fails transiently.
+	var attempts int
+	stub := func() error {
+		attempts++
+		return errors.Wrap(errTransientHandshakeFailure, "stub failure")
+	}
+
+	// Perform the operation and verify the result.
+	if err := policy.retry(logging.RootLogger, stub); errors.Cause(err) != errTransientHandshakeFailure {
+		t.Fatal("unexpected operation result:", err)
+	} else if attempts != 3 {
+		t.Error("unexpected number of attempts:", attempts, "!=", 3)
+	}
+}
+
+// TestHandshakeRetryPermanentFailure tests that an operation that fails
+// permanently isn't retried.
+func TestHandshakeRetryPermanentFailure(t *testing.T) {
+	// Create a policy.
+	policy := &HandshakeRetryPolicy{Retries: 3, Delay: time.Millisecond}
+
+	// Create a handshake stub that fails permanently (e.g. due to an
+	// authentication failure).
+	permanent := errors.New("agent handshake failed with error output:\nPermission denied")
+	var attempts int
+	stub := func() error {
+		attempts++
+		return permanent
+	}
+
+	// Perform the operation and verify the result.
+	if err := policy.retry(logging.RootLogger, stub); err != permanent {
+		t.Fatal("unexpected operation result:", err)
+	} else if attempts != 1 {
+		t.Error("permanent failure retried:", attempts, "attempts")
+	}
+}
+
+// TestConfigureHandshakeRetryPolicy tests ConfigureHandshakeRetryPolicy.
+func TestConfigureHandshakeRetryPolicy(t *testing.T) {
+	// Defer restoration of the default policy.
+	defer ConfigureHandshakeRetryPolicy(nil)
+
+	// Verify that the default policy is used initially.
+	if policy := currentHandshakeRetryPolicy(); *policy != *DefaultHandshakeRetryPolicy() {
+		t.Error("default policy not used:", *policy)
+	}
+
+	// Configure a custom policy and verify that it's used.
+	custom := &HandshakeRetryPolicy{Retries: 5, Delay: time.Second}
+	ConfigureHandshakeRetryPolicy(custom)
+	if policy := currentHandshakeRetryPolicy(); *policy != *custom {
+		t.Error("custom policy not used:", *policy)
+	}
+
+	// Clear the policy and verify that the default policy is restored.
+	ConfigureHandshakeRetryPolicy(nil)
+	if policy := currentHandshakeRetryPolicy(); *policy != *DefaultHandshakeRetryPolicy() {
+		t.Error("default policy not restored:", *policy)
+	}
+}
@@ -1,7 +1,10 @@
 package agent
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 
@@ -10,12 +13,78 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/process"
 	"github.com/mutagen-io/mutagen/pkg/prompting"
 )
 
+const (
+	// previousExecutableSuffix is the suffix appended to the path of an
+	// existing agent executable when it's replaced by installation, allowing
+	// the installation to be rolled back.
+	previousExecutableSuffix = ".previous"
+)
+
+// executableDigest computes the SHA-256 digest (in hexadecimal) of the file at
+// the specified path.
+func executableDigest(path string) (string, error) {
+	// Open the file and defer its closure.
+	file, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to open file")
+	}
+	defer file.Close()
+
+	// Compute the digest.
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", errors.Wrap(err, "unable to compute digest")
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// installExecutable verifies the agent executable at the specified path against
+// the expected digest (if non-empty) and relocates it to the specified
+// destination. If verification fails, then the executable is removed. Any
+// existing executable at the destination is preserved alongside it (with
+// previousExecutableSuffix appended to its name) so that the installation can
+// be rolled back.
+func installExecutable(executablePath, destination, expectedDigest string) error {
+	// Verify the executable's digest, if requested.
+	if expectedDigest != "" {
+		digest, err := executableDigest(executablePath)
+		if err != nil {
+			return errors.Wrap(err, "unable to compute agent executable digest")
+		} else if digest != expectedDigest {
+			os.Remove(executablePath)
+			return errors.Errorf("agent executable digest mismatch (%s != %s)", digest, expectedDigest)
+		}
+	}
+
+	// If there's an existing executable at the installation path, then
+	// preserve it. Any previously preserved executable is replaced.
+	if _, err := os.Lstat(destination); err == nil {
+		if err := os.Rename(destination, destination+previousExecutableSuffix); err != nil {
+			return errors.Wrap(err, "unable to preserve existing agent executable")
+		}
+	} else if !os.IsNotExist(err) {
+		return errors.Wrap(err, "unable to check for existing agent executable")
+	}
+
+	// Relocate the executable to the installation path.
+	if err := os.Rename(executablePath, destination); err != nil {
+		return errors.Wrap(err, "unable to relocate agent executable")
+	}
+
+	// Success.
+	return nil
+}
+
 // Install installs the current binary to the appropriate location for an agent
-// binary with the current Mutagen version.
-func Install() error {
+// binary with the current Mutagen version. If expectedDigest is non-empty, then
+// the current binary is first verified to have the specified SHA-256 digest
+// (in hexadecimal), with installation refused (and the binary removed) if it
+// doesn't.
+func Install(expectedDigest string) error {
 	// Compute the destination.
 	destination, err := installPath()
 	if err != nil {
@@ -28,46 +97,60 @@ func Install() error {
 		return errors.Wrap(err, "unable to determine executable path")
 	}
 
-	// Relocate the current executable to the installation path.
-	if err = os.Rename(executablePath, destination); err != nil {
-		return errors.Wrap(err, "unable to relocate agent executable")
-	}
+	// Verify and relocate the current executable.
+	return installExecutable(executablePath, destination, expectedDigest)
+}
 
-	// Success.
-	return nil
+// installCommand computes the command used to invoke installation of an agent
+// executable that has been copied to the specified destination on the remote.
+// The remote agent will verify that its digest matches the expected digest
+// before installing itself.
+func installCommand(destination string, posix bool, expectedDigest string) string {
+	if posix {
+		destination = "./" + destination
+	}
+	return fmt.Sprintf("%s %s --%s=%s", destination, ModeInstall, FlagExpectedDigest, expectedDigest)
 }
 
 // install attempts to probe an endpoint and install the appropriate agent
-// binary over the specified transport.
-func install(logger *logging.Logger, transport Transport, prompter string) error {
+// binary over the specified transport. On success, it returns the path of the
+// installed agent (relative to the home directory on the remote) and whether or
+// not the remote is a POSIX environment, which can be used to roll back the
+// installation.
+func install(logger *logging.Logger, transport Transport, prompter string) (string, bool, error) {
 	// Detect the target platform.
 	goos, goarch, posix, err := probe(transport, prompter)
 	if err != nil {
-		return errors.Wrap(err, "unable to probe remote platform")
+		return "", false, errors.Wrap(err, "unable to probe remote platform")
 	}
 
 	// Find the appropriate agent binary. Ensure that it's cleaned up when we're
 	// done with it.
 	if err := prompting.Message(prompter, "Extracting agent..."); err != nil {
-		return errors.Wrap(err, "unable to message prompter")
+		return "", false, errors.Wrap(err, "unable to message prompter")
 	}
 	agentExecutable, err := ExecutableForPlatform(goos, goarch, "")
 	if err != nil {
-		return errors.Wrap(err, "unable to get agent for platform")
+		return "", false, errors.Wrap(err, "unable to get agent for platform")
 	}
 	defer os.Remove(agentExecutable)
 
-	// Copy the agent to the remote. We use a unique identifier for the
-	// temporary destination. For Windows remotes, we add a ".exe" suffix, which
-	// will automatically make the file executable on the remote (POSIX systems
-	// are handled separately below). For POSIX systems, we add a dot prefix to
-	// hide the executable.
-	if err := prompting.Message(prompter, "Copying agent..."); err != nil {
-		return errors.Wrap(err, "unable to message prompter")
+	// Compute the expected digest of the agent binary. Since the binary is
+	// extracted from the bundle shipped with the current build, this binds the
+	// expected digest to the current build.
+	expectedDigest, err := executableDigest(agentExecutable)
+	if err != nil {
+		return "", false, errors.Wrap(err, "unable to compute agent digest")
 	}
+
+	// Copy the agent to the remote (via the regional agent cache, if any). We
+	// use a unique identifier for the temporary destination. For Windows
+	// remotes, we add a ".exe" suffix, which will automatically make the file
+	// executable on the remote (POSIX systems are handled separately below).
+	// For POSIX systems, we add a dot prefix to hide the executable.
 	randomUUID, err := uuid.NewRandom()
 	if err != nil {
-		return errors.Wrap(err, "unable to generate UUID for agent copying")
+		return "", false, errors.Wrap(err, "unable to generate UUID for agent copying")
 	}
 	destination := BaseName + randomUUID.String()
 	if goos == "windows" {
@@ -76,8 +159,8 @@ func install(logger *logging.Logger, transport Transport, prompter string) error
 	if posix {
 		destination = "." + destination
 	}
-	if err = transport.Copy(agentExecutable, destination); err != nil {
-		return errors.Wrap(err, "unable to copy agent binary")
+	if err = transferAgent(logger, transport, prompter, agentExecutable, expectedDigest, destination, posix); err != nil {
+		return "", false, errors.Wrap(err, "unable to copy agent binary")
 	}
 
 	// For cases where we're copying from a Windows system to a POSIX remote,
@@ -88,28 +171,31 @@ func install(logger *logging.Logger, transport Transport, prompter string) error
 	// POSIX remotes, but a "chmod +x" there will just be a no-op.
 	if runtime.GOOS == "windows" && posix {
 		if err := prompting.Message(prompter, "Setting agent executability..."); err != nil {
-			return errors.Wrap(err, "unable to message prompter")
+			return "", false, errors.Wrap(err, "unable to message prompter")
 		}
 		executabilityCommand := fmt.Sprintf("chmod +x %s", destination)
 		if err := run(transport, executabilityCommand); err != nil {
-			return errors.Wrap(err, "unable to set agent executability")
+			return "", false, errors.Wrap(err, "unable to set agent executability")
 		}
 	}
 
-	// Invoke the remote installation.
+	// Invoke the remote installation. The remote agent will verify its own
+	// digest before installing itself, refusing installation if the binary has
+	// been modified in transit.
 	if err := prompting.Message(prompter, "Installing agent..."); err != nil {
-		return errors.Wrap(err, "unable to message prompter")
+		return "", false, errors.Wrap(err, "unable to message prompter")
 	}
-	var installCommand string
-	if posix {
-		installCommand = fmt.Sprintf("./%s %s", destination, ModeInstall)
-	} else {
-		installCommand = fmt.Sprintf("%s %s", destination, ModeInstall)
+	if err := run(transport, installCommand(destination, posix, expectedDigest)); err != nil {
+		return "", false, errors.Wrap(err, "unable to invoke agent installation")
 	}
-	if err := run(transport, installCommand); err != nil {
-		return errors.Wrap(err, "unable to invoke agent installation")
+
+	// Compute the installed agent path.
+	separator := "/"
+	if !posix {
+		separator = "\\"
 	}
+	path := process.ExecutableName(agentPath(separator), goos)
 
 	// Success.
-	return nil
+	return path, posix, nil
 }
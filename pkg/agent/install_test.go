@@ -1,4 +1,139 @@
 package agent
 
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
 // NOTE: Unfortunately the Install() method can't be tested directly, but it is
-// tested indirectly by integration tests.
+// tested indirectly by integration tests. Its verification and relocation logic
+// is tested here via installExecutable.
+
+// createTestAgentExecutable creates a mock agent executable in the specified
+// directory and returns its path and expected digest.
+func createTestAgentExecutable(t *testing.T, directory string) (string, string) {
+	// Write the executable.
+	path := filepath.Join(directory, ".mutagen-agent-upload")
+	if err := ioutil.WriteFile(path, []byte("agent executable content"), 0700); err != nil {
+		t.Fatal("unable to write executable:", err)
+	}
+
+	// Compute its digest.
+	digest, err := executableDigest(path)
+	if err != nil {
+		t.Fatal("unable to compute executable digest:", err)
+	}
+
+	// Done.
+	return path, digest
+}
+
+// TestInstallExecutable tests that an unmodified agent executable is verified
+// and installed.
+func TestInstallExecutable(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_install")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create the executable.
+	path, digest := createTestAgentExecutable(t, directory)
+
+	// Perform installation and verify that the executable was relocated.
+	destination := filepath.Join(directory, BaseName)
+	if err := installExecutable(path, destination, digest); err != nil {
+		t.Fatal("installation failed:", err)
+	}
+	if _, err := os.Lstat(destination); err != nil {
+		t.Error("installed executable not found:", err)
+	}
+}
+
+// TestInstallExecutablePreservesPrevious tests that installation over an
+// existing agent executable preserves the existing executable.
+func TestInstallExecutablePreservesPrevious(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_install")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create an existing executable at the destination.
+	destination := filepath.Join(directory, BaseName)
+	if err := ioutil.WriteFile(destination, []byte("previous"), 0700); err != nil {
+		t.Fatal("unable to write existing executable:", err)
+	}
+
+	// Create the executable and perform installation.
+	path, digest := createTestAgentExecutable(t, directory)
+	if err := installExecutable(path, destination, digest); err != nil {
+		t.Fatal("installation failed:", err)
+	}
+
+	// Verify that the existing executable was preserved.
+	if content, err := ioutil.ReadFile(destination + previousExecutableSuffix); err != nil {
+		t.Fatal("unable to read preserved executable:", err)
+	} else if string(content) != "previous" {
+		t.Error("preserved executable has incorrect content")
+	}
+	if content, err := ioutil.ReadFile(destination); err != nil {
+		t.Fatal("unable to read installed executable:", err)
+	} else if string(content) != "agent executable content" {
+		t.Error("installed executable has incorrect content")
+	}
+}
+
+// TestInstallExecutableCorrupted tests that installation of an agent executable
+// that's been modified after upload is refused.
+func TestInstallExecutableCorrupted(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_install")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create the executable and then corrupt it.
+	path, digest := createTestAgentExecutable(t, directory)
+	if err := ioutil.WriteFile(path, []byte("tampered executable content"), 0700); err != nil {
+		t.Fatal("unable to corrupt executable:", err)
+	}
+
+	// Attempt installation and verify that it's refused.
+	destination := filepath.Join(directory, BaseName)
+	if err := installExecutable(path, destination, digest); err == nil {
+		t.Fatal("installation of corrupted executable succeeded")
+	}
+	if _, err := os.Lstat(destination); !os.IsNotExist(err) {
+		t.Error("corrupted executable installed")
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Error("corrupted executable not removed")
+	}
+}
+
+// TestInstallCommand tests that installation commands include the expected
+// digest.
+func TestInstallCommand(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		destination string
+		posix       bool
+		expected    string
+	}{
+		{".mutagen-agent1234", true, "./.mutagen-agent1234 install --expected-digest=abcd"},
+		{"mutagen-agent1234.exe", false, "mutagen-agent1234.exe install --expected-digest=abcd"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if command := installCommand(testCase.destination, testCase.posix, "abcd"); command != testCase.expected {
+			t.Error("install command mismatch:", command, "!=", testCase.expected)
+		}
+	}
+}
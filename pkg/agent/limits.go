@@ -0,0 +1,313 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/process"
+)
+
+const (
+	// MinimumMemoryLimit is the smallest non-zero memory limit that can be
+	// imposed on an agent. Anything smaller would prevent the agent from
+	// starting reliably.
+	MinimumMemoryLimit = 32 * 1024 * 1024
+	// MemoryLimitExitCode is the exit code used by agents that terminate
+	// because they've exceeded their memory limit.
+	MemoryLimitExitCode = 3
+	// memoryLimitEnvironmentVariable is the environment variable used to
+	// communicate the memory limit (in bytes) to the agent.
+	memoryLimitEnvironmentVariable = "MUTAGEN_AGENT_MEMORY_LIMIT"
+	// cpuLimitEnvironmentVariable is the environment variable used to
+	// communicate the CPU limit (as a percentage of a single CPU) to the agent.
+	cpuLimitEnvironmentVariable = "MUTAGEN_AGENT_CPU_LIMIT"
+	// memoryLimitCheckInterval is the interval at which the agent checks its
+	// memory usage against its memory limit.
+	memoryLimitCheckInterval = time.Second
+	// memoryLimitHeadroomPercentage is the percentage of its memory limit that
+	// an agent may use before terminating itself. The remaining headroom allows
+	// the agent to terminate cleanly before any cgroup limit triggers an
+	// out-of-memory kill.
+	memoryLimitHeadroomPercentage = 90
+)
+
+// ResourceLimits specifies the resource limits under which agents should be
+// launched. A nil or zero-valued ResourceLimits imposes no limits.
+type ResourceLimits struct {
+	// Memory is the maximum amount of memory (in bytes) that the agent may
+	// use. A value of 0 indicates no limit.
+	Memory uint64
+	// CPU is the maximum amount of CPU time that the agent may use, expressed
+	// as a percentage of a single CPU. A value of 0 indicates no limit.
+	CPU uint32
+}
+
+// IsZero indicates whether or not the limits impose no restrictions.
+func (l *ResourceLimits) IsZero() bool {
+	return l == nil || (l.Memory == 0 && l.CPU == 0)
+}
+
+// EnsureValid ensures that ResourceLimits' invariants are respected. A nil
+// ResourceLimits is considered valid.
+func (l *ResourceLimits) EnsureValid() error {
+	// A nil limit specification is valid.
+	if l == nil {
+		return nil
+	}
+
+	// Verify that the memory limit is usable.
+	if l.Memory != 0 && l.Memory < MinimumMemoryLimit {
+		return errors.Errorf("memory limit below minimum (%s)", humanize.Bytes(MinimumMemoryLimit))
+	}
+
+	// Success.
+	return nil
+}
+
+// environment returns the environment variable specifications (of the form
+// "KEY=value") used to communicate the limits to the agent.
+func (l *ResourceLimits) environment() []string {
+	var result []string
+	if l.Memory != 0 {
+		result = append(result, fmt.Sprintf("%s=%d", memoryLimitEnvironmentVariable, l.Memory))
+	}
+	if l.CPU != 0 {
+		result = append(result, fmt.Sprintf("%s=%d", cpuLimitEnvironmentVariable, l.CPU))
+	}
+	return result
+}
+
+// EnvironmentFlags returns the "--env" flags that a Docker exec invocation
+// should use to communicate the limits to the agent.
+func (l *ResourceLimits) EnvironmentFlags() []string {
+	var result []string
+	for _, specification := range l.environment() {
+		result = append(result, "--env", specification)
+	}
+	return result
+}
+
+// SystemdRunCommand wraps the specified POSIX command such that it's launched
+// in a transient systemd scope (and thus a dedicated cgroup) subject to the
+// limits. The limits are also communicated to the agent so that it can
+// terminate cleanly before the scope's memory limit triggers an out-of-memory
+// kill. Like the command itself, the result is lexable by splitting on spaces.
+func (l *ResourceLimits) SystemdRunCommand(command string) string {
+	// Pass limits to the agent via its environment.
+	var arguments []string
+	if environment := l.environment(); len(environment) > 0 {
+		arguments = append(arguments, "env")
+		arguments = append(arguments, environment...)
+	}
+
+	// Create the transient scope with the corresponding cgroup properties.
+	arguments = append(arguments, "systemd-run", "--user", "--scope", "--quiet")
+	if l.Memory != 0 {
+		arguments = append(arguments,
+			"-p", fmt.Sprintf("MemoryMax=%d", l.Memory),
+			"-p", "MemorySwapMax=0",
+		)
+	}
+	if l.CPU != 0 {
+		arguments = append(arguments, "-p", fmt.Sprintf("CPUQuota=%d%%", l.CPU))
+	}
+
+	// Add the command.
+	arguments = append(arguments, command)
+
+	// Done.
+	return strings.Join(arguments, " ")
+}
+
+// ClassifySystemdRunFailure determines whether or not the error output from a
+// command wrapped by SystemdRunCommand indicates that systemd-run itself was
+// unavailable or unable to create a transient scope. If so, it returns an error
+// describing the failure, otherwise it returns nil.
+func ClassifySystemdRunFailure(errorOutput string) error {
+	if strings.Contains(errorOutput, "systemd-run: not found") ||
+		strings.Contains(errorOutput, "systemd-run: command not found") {
+		return errors.New("systemd-run is not available on the remote (it's required for agent resource limits)")
+	} else if strings.Contains(errorOutput, "Failed to connect to bus") ||
+		strings.Contains(errorOutput, "Failed to create bus connection") ||
+		strings.Contains(errorOutput, "Failed to start transient scope unit") {
+		return errors.Errorf(
+			"unable to create systemd scope for agent resource limits: %s",
+			strings.TrimSpace(errorOutput),
+		)
+	}
+	return nil
+}
+
+// MemoryLimitError indicates that an agent terminated because it exceeded its
+// memory limit.
+type MemoryLimitError struct {
+	// Limit is the memory limit (in bytes) that was exceeded.
+	Limit uint64
+}
+
+// Error implements error.Error.
+func (e *MemoryLimitError) Error() string {
+	return fmt.Sprintf("agent exceeded its memory limit (%s)", humanize.Bytes(e.Limit))
+}
+
+// classifyTermination determines whether or not the specified agent process
+// exit state indicates termination due to exceeding the memory limit. If so,
+// it returns a *MemoryLimitError, otherwise it returns nil. Both clean
+// termination by the agent and out-of-memory kills are recognized.
+func (l *ResourceLimits) classifyTermination(state *os.ProcessState) error {
+	if l == nil || l.Memory == 0 || state == nil {
+		return nil
+	} else if state.ExitCode() == MemoryLimitExitCode || process.IsKilled(state) {
+		return &MemoryLimitError{Limit: l.Memory}
+	}
+	return nil
+}
+
+// limitedConnection wraps an agent connection running under resource limits in
+// order to report termination due to exceeding the memory limit.
+type limitedConnection struct {
+	// Conn is the underlying connection, embedded to provide the remaining
+	// net.Conn methods.
+	net.Conn
+	// connection is the underlying process connection.
+	connection *process.Connection
+	// process is the agent process.
+	process *exec.Cmd
+	// limits are the limits under which the agent process is running.
+	limits *ResourceLimits
+	// closedLock restricts access to closed.
+	closedLock sync.Mutex
+	// closed indicates whether or not the connection has been closed locally.
+	closed bool
+	// classifyOnce ensures that termination classification occurs only once.
+	classifyOnce sync.Once
+	// limitErr is the result of termination classification.
+	limitErr error
+}
+
+// newLimitedConnection creates a new limited connection.
+func newLimitedConnection(connection *process.Connection, agentProcess *exec.Cmd, limits *ResourceLimits) *limitedConnection {
+	return &limitedConnection{
+		Conn:       connection,
+		connection: connection,
+		process:    agentProcess,
+		limits:     limits,
+	}
+}
+
+// classify converts an I/O error into a *MemoryLimitError if the agent process
+// terminated due to exceeding its memory limit. The agent process is allowed
+// to exit on its own (up to the standard kill delay) so that its natural exit
+// state can be inspected. Errors that occur after local closure are returned
+// unmodified, since the agent's termination will have been forced.
+func (c *limitedConnection) classify(err error) error {
+	c.closedLock.Lock()
+	closed := c.closed
+	c.closedLock.Unlock()
+	if closed {
+		return err
+	}
+	c.classifyOnce.Do(func() {
+		c.connection.SetKillDelay(agentKillDelay)
+		c.connection.Close()
+		c.limitErr = c.limits.classifyTermination(c.process.ProcessState)
+	})
+	if c.limitErr != nil {
+		return c.limitErr
+	}
+	return err
+}
+
+// Read implements net.Conn.Read.
+func (c *limitedConnection) Read(buffer []byte) (int, error) {
+	count, err := c.Conn.Read(buffer)
+	if err != nil {
+		err = c.classify(err)
+	}
+	return count, err
+}
+
+// Write implements net.Conn.Write.
+func (c *limitedConnection) Write(buffer []byte) (int, error) {
+	count, err := c.Conn.Write(buffer)
+	if err != nil {
+		err = c.classify(err)
+	}
+	return count, err
+}
+
+// Close implements net.Conn.Close.
+func (c *limitedConnection) Close() error {
+	c.closedLock.Lock()
+	c.closed = true
+	c.closedLock.Unlock()
+	return c.Conn.Close()
+}
+
+// EnforceResourceLimits enforces any resource limits communicated to the agent
+// by its launcher. CPU limits are approximated by restricting the number of
+// threads that can execute Go code simultaneously (any cgroup quota remains in
+// effect as well). Memory limits are enforced by periodically checking the
+// agent's memory usage, with the returned channel being populated with a
+// *MemoryLimitError if usage approaches the limit. The agent should then
+// terminate with MemoryLimitExitCode. If no memory limit is specified, then the
+// returned channel will never be populated. Monitoring is halted when the
+// specified context is cancelled.
+func EnforceResourceLimits(ctx context.Context) (<-chan error, error) {
+	// Apply any CPU limit.
+	if value := os.Getenv(cpuLimitEnvironmentVariable); value != "" {
+		percentage, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || percentage == 0 {
+			return nil, errors.Errorf("invalid CPU limit: %s", value)
+		}
+		runtime.GOMAXPROCS(int((percentage + 99) / 100))
+	}
+
+	// Create the result channel and check whether or not there's a memory
+	// limit to enforce.
+	result := make(chan error, 1)
+	value := os.Getenv(memoryLimitEnvironmentVariable)
+	if value == "" {
+		return result, nil
+	}
+	limit, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || limit < MinimumMemoryLimit {
+		return nil, errors.Errorf("invalid memory limit: %s", value)
+	}
+
+	// Start monitoring memory usage. We measure usage as the memory obtained
+	// from the operating system less any that's been returned to it, which is
+	// a reasonable approximation of the agent's resident set size.
+	threshold := limit / 100 * memoryLimitHeadroomPercentage
+	go func() {
+		ticker := time.NewTicker(memoryLimitCheckInterval)
+		defer ticker.Stop()
+		var statistics runtime.MemStats
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				runtime.ReadMemStats(&statistics)
+				if statistics.Sys-statistics.HeapReleased > threshold {
+					result <- &MemoryLimitError{Limit: limit}
+					return
+				}
+			}
+		}
+	}()
+
+	// Success.
+	return result, nil
+}
@@ -0,0 +1,305 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/process"
+)
+
+// testLimitingTransport is an agent.Transport implementation that supports
+// resource limits. It records the agent commands that it's asked to create and
+// launches a fixed stub command in their place.
+type testLimitingTransport struct {
+	testCommandTransport
+	// limits are the resource limits imposed by the transport.
+	limits *ResourceLimits
+	// stub is the POSIX shell command launched in place of agent commands.
+	stub string
+	// agentCommands are the commands created via AgentCommand.
+	agentCommands []string
+}
+
+// ResourceLimits implements agent.LimitingTransport.ResourceLimits.
+func (t *testLimitingTransport) ResourceLimits() *ResourceLimits {
+	return t.limits
+}
+
+// AgentCommand implements agent.LimitingTransport.AgentCommand.
+func (t *testLimitingTransport) AgentCommand(command string) (*exec.Cmd, error) {
+	t.agentCommands = append(t.agentCommands, command)
+	return exec.Command("/bin/sh", "-c", t.stub), nil
+}
+
+// TestResourceLimitsEnsureValid tests ResourceLimits.EnsureValid.
+func TestResourceLimitsEnsureValid(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		limits   *ResourceLimits
+		expected bool
+	}{
+		{nil, true},
+		{&ResourceLimits{}, true},
+		{&ResourceLimits{CPU: 50}, true},
+		{&ResourceLimits{Memory: MinimumMemoryLimit}, true},
+		{&ResourceLimits{Memory: MinimumMemoryLimit - 1}, false},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		if err := testCase.limits.EnsureValid(); (err == nil) != testCase.expected {
+			t.Errorf("test case %d: validity does not match expected: %v", i, err)
+		}
+	}
+}
+
+// TestResourceLimitsSystemdRunCommand tests ResourceLimits.SystemdRunCommand.
+func TestResourceLimitsSystemdRunCommand(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		limits   *ResourceLimits
+		expected string
+	}{
+		{
+			&ResourceLimits{Memory: 64 * 1024 * 1024},
+			"env MUTAGEN_AGENT_MEMORY_LIMIT=67108864 " +
+				"systemd-run --user --scope --quiet -p MemoryMax=67108864 -p MemorySwapMax=0 " +
+				"agent synchronizer",
+		},
+		{
+			&ResourceLimits{CPU: 150},
+			"env MUTAGEN_AGENT_CPU_LIMIT=150 " +
+				"systemd-run --user --scope --quiet -p CPUQuota=150% " +
+				"agent synchronizer",
+		},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		if command := testCase.limits.SystemdRunCommand("agent synchronizer"); command != testCase.expected {
+			t.Errorf("test case %d: command does not match expected: %s != %s", i, command, testCase.expected)
+		}
+	}
+}
+
+// TestResourceLimitsEnvironmentFlags tests ResourceLimits.EnvironmentFlags.
+func TestResourceLimitsEnvironmentFlags(t *testing.T) {
+	limits := &ResourceLimits{Memory: 64 * 1024 * 1024, CPU: 50}
+	expected := []string{
+		"--env", "MUTAGEN_AGENT_MEMORY_LIMIT=67108864",
+		"--env", "MUTAGEN_AGENT_CPU_LIMIT=50",
+	}
+	flags := limits.EnvironmentFlags()
+	if len(flags) != len(expected) {
+		t.Fatal("flag count does not match expected:", flags)
+	}
+	for f, flag := range flags {
+		if flag != expected[f] {
+			t.Error("flag does not match expected:", flag, "!=", expected[f])
+		}
+	}
+}
+
+// TestClassifySystemdRunFailure tests ClassifySystemdRunFailure.
+func TestClassifySystemdRunFailure(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		output   string
+		expected bool
+	}{
+		{"", false},
+		{"bash: systemd-run: command not found\n", true},
+		{"sh: 1: systemd-run: not found\n", true},
+		{"Failed to connect to bus: No medium found\n", true},
+		{"Permission denied (publickey).\n", false},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		if err := ClassifySystemdRunFailure(testCase.output); (err != nil) != testCase.expected {
+			t.Errorf("test case %d: classification does not match expected: %v", i, err)
+		}
+	}
+}
+
+// TestConnectLimitedAgentCommand tests that agent invocations are created using
+// AgentCommand for transports imposing resource limits and that termination
+// due to exceeding the memory limit is reported clearly.
+func TestConnectLimitedAgentCommand(t *testing.T) {
+	// If we're not running in a POSIX environment, then skip this test.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Define test cases covering clean termination by the agent and an
+	// out-of-memory kill.
+	stubs := []string{
+		"exit 3",
+		"kill -9 $$",
+	}
+
+	// Process test cases.
+	logger := logging.RootLogger.Sublogger("test")
+	for i, stub := range stubs {
+		transport := &testLimitingTransport{
+			limits: &ResourceLimits{Memory: 64 * 1024 * 1024},
+			stub:   stub,
+		}
+		_, _, _, err := connect(logger, transport, ModeSynchronizer, "", false)
+		var limitErr *MemoryLimitError
+		if !errors.As(err, &limitErr) {
+			t.Errorf("test case %d: memory limit violation not reported: %v", i, err)
+		} else if limitErr.Limit != transport.limits.Memory {
+			t.Errorf("test case %d: reported limit incorrect: %d", i, limitErr.Limit)
+		}
+		if len(transport.agentCommands) != 1 {
+			t.Errorf("test case %d: agent command not created via AgentCommand", i)
+		} else if len(transport.commands) != 0 {
+			t.Errorf("test case %d: agent command created via Command", i)
+		}
+	}
+}
+
+// TestConnectLimitsUnsupportedWithCmdExe tests that connections under resource
+// limits are rejected in cmd.exe environments.
+func TestConnectLimitsUnsupportedWithCmdExe(t *testing.T) {
+	transport := &testLimitingTransport{limits: &ResourceLimits{CPU: 50}}
+	if _, _, _, err := connect(logging.RootLogger, transport, ModeSynchronizer, "", true); err == nil {
+		t.Error("limited connection succeeded in cmd.exe environment")
+	} else if len(transport.agentCommands) != 0 {
+		t.Error("agent command created in cmd.exe environment")
+	}
+}
+
+// startLimitedConnection starts the specified POSIX shell command and wraps it
+// in a limited connection with the specified limits.
+func startLimitedConnection(t *testing.T, command string, limits *ResourceLimits) *limitedConnection {
+	agentProcess := exec.Command("/bin/sh", "-c", command)
+	connection, err := process.NewConnection(agentProcess, 0)
+	if err != nil {
+		t.Fatal("unable to create process connection:", err)
+	} else if err = agentProcess.Start(); err != nil {
+		t.Fatal("unable to start process:", err)
+	}
+	return newLimitedConnection(connection, agentProcess, limits)
+}
+
+// TestLimitedConnectionReportsMemoryLimit tests that a limited connection
+// reports termination due to exceeding the memory limit when I/O fails.
+func TestLimitedConnectionReportsMemoryLimit(t *testing.T) {
+	// If we're not running in a POSIX environment, then skip this test.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Start a stub agent that emits some data and then terminates as if it had
+	// exceeded its memory limit.
+	limits := &ResourceLimits{Memory: 64 * 1024 * 1024}
+	connection := startLimitedConnection(t, "printf x; exit 3", limits)
+	defer connection.Close()
+
+	// Verify that data is read successfully and that the subsequent failure is
+	// reported as a memory limit violation.
+	buffer := make([]byte, 1)
+	if _, err := io.ReadFull(connection, buffer); err != nil {
+		t.Fatal("unable to read data:", err)
+	}
+	_, err := connection.Read(buffer)
+	var limitErr *MemoryLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatal("memory limit violation not reported:", err)
+	} else if limitErr.Error() != "agent exceeded its memory limit (67 MB)" {
+		t.Error("memory limit violation message incorrect:", limitErr.Error())
+	}
+}
+
+// TestLimitedConnectionOrdinaryFailure tests that a limited connection reports
+// other failures unmodified.
+func TestLimitedConnectionOrdinaryFailure(t *testing.T) {
+	// If we're not running in a POSIX environment, then skip this test.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Verify that ordinary agent failures aren't reported as memory limit
+	// violations.
+	limits := &ResourceLimits{Memory: 64 * 1024 * 1024}
+	connection := startLimitedConnection(t, "exit 1", limits)
+	var limitErr *MemoryLimitError
+	if _, err := connection.Read(make([]byte, 1)); err == nil {
+		t.Error("read succeeded from failed process")
+	} else if errors.As(err, &limitErr) {
+		t.Error("ordinary failure reported as memory limit violation")
+	}
+	connection.Close()
+
+	// Verify that forced termination due to local closure isn't reported as a
+	// memory limit violation.
+	connection = startLimitedConnection(t, "sleep 10", limits)
+	connection.Close()
+	if _, err := connection.Read(make([]byte, 1)); err == nil {
+		t.Error("read succeeded from closed connection")
+	} else if errors.As(err, &limitErr) {
+		t.Error("local closure reported as memory limit violation")
+	}
+}
+
+// TestEnforceResourceLimits tests that EnforceResourceLimits reports memory
+// usage in excess of the limit communicated via the environment.
+func TestEnforceResourceLimits(t *testing.T) {
+	// Create a cancellable context for monitoring and defer its cancellation.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Verify that no violation is reported without limits.
+	os.Unsetenv(memoryLimitEnvironmentVariable)
+	os.Unsetenv(cpuLimitEnvironmentVariable)
+	if termination, err := EnforceResourceLimits(ctx); err != nil {
+		t.Fatal("unable to enforce absent resource limits:", err)
+	} else {
+		select {
+		case err := <-termination:
+			t.Fatal("violation reported without limits:", err)
+		case <-time.After(2 * memoryLimitCheckInterval):
+		}
+	}
+
+	// Verify that invalid limits are rejected.
+	os.Setenv(memoryLimitEnvironmentVariable, "1024")
+	if _, err := EnforceResourceLimits(ctx); err == nil {
+		t.Error("invalid memory limit accepted")
+	}
+
+	// Set a memory limit that the test process is guaranteed to exceed (by
+	// holding a live allocation larger than the limit) and defer its removal.
+	os.Setenv(memoryLimitEnvironmentVariable, "33554432")
+	defer os.Unsetenv(memoryLimitEnvironmentVariable)
+	ballast := make([]byte, 2*MinimumMemoryLimit)
+	for i := range ballast {
+		ballast[i] = byte(i)
+	}
+
+	// Verify that a violation is reported.
+	termination, err := EnforceResourceLimits(ctx)
+	if err != nil {
+		t.Fatal("unable to enforce memory limit:", err)
+	}
+	select {
+	case err := <-termination:
+		var limitErr *MemoryLimitError
+		if !errors.As(err, &limitErr) || limitErr.Limit != MinimumMemoryLimit {
+			t.Error("unexpected termination error:", err)
+		}
+	case <-time.After(10 * memoryLimitCheckInterval):
+		t.Error("memory limit violation not reported")
+	}
+	runtime.KeepAlive(ballast)
+}
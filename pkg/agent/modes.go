@@ -8,8 +8,17 @@ const (
 	ModeSynchronizer = "synchronizer"
 	// ModeForwarder is the agent command to invoke for running as a forwarder.
 	ModeForwarder = "forwarder"
+	// ModeBenchmark is the agent command to invoke for running as a transport
+	// benchmark server.
+	ModeBenchmark = "benchmark"
 	// ModeVersion is the agent command to invoke to print version information.
 	ModeVersion = "version"
 	// ModeLegal is the agent command to invoke to print legal information.
 	ModeLegal = "legal"
 )
+
+const (
+	// FlagExpectedDigest is the install command flag used to specify the
+	// expected SHA-256 digest (in hexadecimal) of the agent executable.
+	FlagExpectedDigest = "expected-digest"
+)
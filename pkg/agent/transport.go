@@ -37,18 +37,121 @@ type Transport interface {
 	// If the second bool changes the dialer's platform hypothesis, it will
 	// attempt to reconnect using the correct command syntax for that platform.
 	// Otherwise, if the first bool indicates that the agent binary simply needs
-	// to be (re-)installed, it will attempt to do so and then reconnect.
+	// to be (re-)installed, it will attempt to do so and then reconnect. If the
+	// error output identifies a specific cause of failure, then the returned
+	// error can be an *IdentifiedFailureError, in which case it will be
+	// returned by the dialer in lieu of the raw error output.
 	ClassifyError(processState *os.ProcessState, errorOutput string) (bool, bool, error)
 }
 
-// run is a utility method that invokes a command via a transport, waits for it
-// to complete, and returns its exit error. If there is an error creating the
-// command, it will be returned wrapped, but otherwise the result of the run
+// IdentifiedFailureError can be returned by Transport.ClassifyError to indicate
+// that the error output identifies a specific cause of failure (e.g. a rejected
+// connection option).
+type IdentifiedFailureError struct {
+	// Err is the error describing the cause of failure.
+	Err error
+}
+
+// Error implements error.Error.
+func (e *IdentifiedFailureError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error describing the cause of failure.
+func (e *IdentifiedFailureError) Unwrap() error {
+	return e.Err
+}
+
+// SetupTransport is an optional interface that transports can implement in
+// order to invoke commands used for probing remote platforms and installing
+// agents differently than other commands. Unlike the commands created by
+// Transport.Command, setup commands are only used with textual input and
+// output, so their transports may perform transformations that would corrupt
+// binary streams (e.g. allocating a pseudo-terminal).
+type SetupTransport interface {
+	// SetupCommand creates (but does not start) a process that will invoke the
+	// specified setup command on the remote. It has the same requirements as
+	// Transport.Command.
+	SetupCommand(command string) (*exec.Cmd, error)
+}
+
+// LimitingTransport is an optional interface that transports can implement in
+// order to launch agents under resource limits. Agent invocations are created
+// using AgentCommand for transports that implement this interface and return
+// non-zero limits, while all other commands continue to use Transport.Command.
+type LimitingTransport interface {
+	// ResourceLimits returns the resource limits under which agents are
+	// launched. It may return nil if no limits are imposed.
+	ResourceLimits() *ResourceLimits
+	// AgentCommand creates (but does not start) a process that will invoke the
+	// specified agent command on the remote under the transport's resource
+	// limits. It has the same requirements as Transport.Command.
+	AgentCommand(command string) (*exec.Cmd, error)
+}
+
+// EscalatingTransport is an optional interface that transports can implement in
+// order to launch agents under privilege escalation. Agent invocations are
+// created using AgentCommand for transports that implement this interface and
+// indicate that they escalate privileges, while all other commands (including
+// those used for probing and installation) continue to use Transport.Command.
+type EscalatingTransport interface {
+	// EscalatesPrivileges indicates whether or not agents are launched under
+	// privilege escalation.
+	EscalatesPrivileges() bool
+	// AgentCommand creates (but does not start) a process that will invoke the
+	// specified agent command on the remote under privilege escalation. It has
+	// the same requirements as Transport.Command.
+	AgentCommand(command string) (*exec.Cmd, error)
+}
+
+// escalatesPrivileges returns whether or not a transport implements
+// EscalatingTransport and escalates privileges.
+func escalatesPrivileges(transport Transport) bool {
+	if escalatingTransport, ok := transport.(EscalatingTransport); ok {
+		return escalatingTransport.EscalatesPrivileges()
+	}
+	return false
+}
+
+// resourceLimits returns the resource limits imposed by a transport if it
+// implements LimitingTransport and nil otherwise.
+func resourceLimits(transport Transport) *ResourceLimits {
+	if limitingTransport, ok := transport.(LimitingTransport); ok {
+		return limitingTransport.ResourceLimits()
+	}
+	return nil
+}
+
+// agentCommand creates a process for an agent invocation using the transport's
+// AgentCommand method if it imposes resource limits or escalates privileges and
+// its Command method otherwise.
+func agentCommand(transport Transport, command string) (*exec.Cmd, error) {
+	if limitingTransport, ok := transport.(LimitingTransport); ok && !limitingTransport.ResourceLimits().IsZero() {
+		return limitingTransport.AgentCommand(command)
+	} else if escalatingTransport, ok := transport.(EscalatingTransport); ok && escalatingTransport.EscalatesPrivileges() {
+		return escalatingTransport.AgentCommand(command)
+	}
+	return transport.Command(command)
+}
+
+// setupCommand creates a process for a setup command using the transport's
+// SetupCommand method if it implements SetupTransport and its Command method
+// otherwise.
+func setupCommand(transport Transport, command string) (*exec.Cmd, error) {
+	if setupTransport, ok := transport.(SetupTransport); ok {
+		return setupTransport.SetupCommand(command)
+	}
+	return transport.Command(command)
+}
+
+// run is a utility method that invokes a setup command via a transport, waits
+// for it to complete, and returns its exit error. If there is an error creating
+// the command, it will be returned wrapped, but otherwise the result of the run
 // method will be returned un-wrapped, so it can be treated as an
 // os/exec.ExitError.
 func run(transport Transport, command string) error {
 	// Create the process.
-	process, err := transport.Command(command)
+	process, err := setupCommand(transport, command)
 	if err != nil {
 		return errors.Wrap(err, "unable to create command")
 	}
@@ -57,14 +160,14 @@ func run(transport Transport, command string) error {
 	return process.Run()
 }
 
-// output is a utility method that invokes a command via a transport, waits for
-// it to complete, and returns its standard output and exit error. If there is
-// an error creating the command, it will be returned wrapped, but otherwise the
-// result of the run method will be returned un-wrapped, so it can be treated as
-// an os/exec.ExitError.
+// output is a utility method that invokes a setup command via a transport,
+// waits for it to complete, and returns its standard output and exit error. If
+// there is an error creating the command, it will be returned wrapped, but
+// otherwise the result of the run method will be returned un-wrapped, so it can
+// be treated as an os/exec.ExitError.
 func output(transport Transport, command string) ([]byte, error) {
 	// Create the process.
-	process, err := transport.Command(command)
+	process, err := setupCommand(transport, command)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to create command")
 	}
@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+)
+
+// testCommandTransport is an agent.Transport implementation that records the
+// commands that it's asked to create without supporting their execution.
+type testCommandTransport struct {
+	// commands are the commands created via Command.
+	commands []string
+}
+
+// Copy implements agent.Transport.Copy.
+func (t *testCommandTransport) Copy(_, _ string) error {
+	return errors.New("copying not supported")
+}
+
+// Command implements agent.Transport.Command.
+func (t *testCommandTransport) Command(command string) (*exec.Cmd, error) {
+	t.commands = append(t.commands, command)
+	return exec.Command("unused"), nil
+}
+
+// ClassifyError implements agent.Transport.ClassifyError.
+func (t *testCommandTransport) ClassifyError(_ *os.ProcessState, _ string) (bool, bool, error) {
+	return false, false, errors.New("error classification not supported")
+}
+
+// testSetupCommandTransport extends testCommandTransport with support for
+// setup commands, which it records separately.
+type testSetupCommandTransport struct {
+	testCommandTransport
+	// setupCommands are the commands created via SetupCommand.
+	setupCommands []string
+}
+
+// SetupCommand implements agent.SetupTransport.SetupCommand.
+func (t *testSetupCommandTransport) SetupCommand(command string) (*exec.Cmd, error) {
+	t.setupCommands = append(t.setupCommands, command)
+	return exec.Command("unused"), nil
+}
+
+// TestSetupCommand tests that setupCommand uses SetupCommand for transports
+// that support it and falls back to Command otherwise.
+func TestSetupCommand(t *testing.T) {
+	// Verify the fallback for transports without setup command support.
+	standard := &testCommandTransport{}
+	if _, err := setupCommand(standard, "uname -s -m"); err != nil {
+		t.Fatal("unable to create setup command:", err)
+	} else if len(standard.commands) != 1 {
+		t.Error("setup command not created via Command")
+	}
+
+	// Verify that setup command support is used when available.
+	setup := &testSetupCommandTransport{}
+	if _, err := setupCommand(setup, "uname -s -m"); err != nil {
+		t.Fatal("unable to create setup command:", err)
+	} else if len(setup.setupCommands) != 1 {
+		t.Error("setup command not created via SetupCommand")
+	} else if len(setup.commands) != 0 {
+		t.Error("setup command created via Command")
+	}
+}
+
+// testEscalatingTransport extends testCommandTransport with support for
+// privilege escalation, recording agent commands separately.
+type testEscalatingTransport struct {
+	testCommandTransport
+	// escalates indicates whether or not the transport escalates privileges.
+	escalates bool
+	// agentCommands are the commands created via AgentCommand.
+	agentCommands []string
+}
+
+// EscalatesPrivileges implements agent.EscalatingTransport.EscalatesPrivileges.
+func (t *testEscalatingTransport) EscalatesPrivileges() bool {
+	return t.escalates
+}
+
+// AgentCommand implements agent.EscalatingTransport.AgentCommand.
+func (t *testEscalatingTransport) AgentCommand(command string) (*exec.Cmd, error) {
+	t.agentCommands = append(t.agentCommands, command)
+	return exec.Command("unused"), nil
+}
+
+// TestAgentCommandEscalation tests that agentCommand uses AgentCommand for
+// transports that escalate privileges and Command otherwise.
+func TestAgentCommandEscalation(t *testing.T) {
+	// Verify that Command is used if escalation is disabled.
+	disabled := &testEscalatingTransport{}
+	if _, err := agentCommand(disabled, "mutagen-agent synchronizer"); err != nil {
+		t.Fatal("unable to create agent command:", err)
+	} else if len(disabled.commands) != 1 || len(disabled.agentCommands) != 0 {
+		t.Error("agent command not created via Command")
+	}
+
+	// Verify that AgentCommand is used if escalation is enabled.
+	enabled := &testEscalatingTransport{escalates: true}
+	if _, err := agentCommand(enabled, "mutagen-agent synchronizer"); err != nil {
+		t.Fatal("unable to create agent command:", err)
+	} else if len(enabled.agentCommands) != 1 || len(enabled.commands) != 0 {
+		t.Error("agent command not created via AgentCommand")
+	}
+}
+
+// TestConnectEscalationUnsupportedWithCmdExe tests that connections under
+// privilege escalation are rejected in cmd.exe environments.
+func TestConnectEscalationUnsupportedWithCmdExe(t *testing.T) {
+	transport := &testEscalatingTransport{escalates: true}
+	if _, _, _, err := connect(logging.RootLogger, transport, ModeSynchronizer, "", true); err == nil {
+		t.Error("escalated connection succeeded in cmd.exe environment")
+	} else if len(transport.agentCommands) != 0 {
+		t.Error("agent command created in cmd.exe environment")
+	}
+}
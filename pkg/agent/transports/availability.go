@@ -0,0 +1,148 @@
+package transports
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/docker"
+	"github.com/mutagen-io/mutagen/pkg/ssh"
+	"github.com/mutagen-io/mutagen/pkg/wsl"
+)
+
+// availabilityProbeTimeout is the maximum amount of time that will be allowed
+// for any single command invoked while probing transport availability.
+const availabilityProbeTimeout = 10 * time.Second
+
+// AvailabilityStatus indicates the availability of a transport.
+type AvailabilityStatus uint8
+
+const (
+	// AvailabilityStatusUnavailable indicates that a transport is unusable.
+	AvailabilityStatusUnavailable AvailabilityStatus = iota
+	// AvailabilityStatusPartial indicates that a transport is usable but that
+	// some of its functionality is unavailable.
+	AvailabilityStatusPartial
+	// AvailabilityStatusAvailable indicates that a transport is fully usable.
+	AvailabilityStatusAvailable
+)
+
+// Description returns a human-readable description of the availability status.
+func (s AvailabilityStatus) Description() string {
+	switch s {
+	case AvailabilityStatusUnavailable:
+		return "Unavailable"
+	case AvailabilityStatusPartial:
+		return "Partially available"
+	case AvailabilityStatusAvailable:
+		return "Available"
+	default:
+		return "Unknown"
+	}
+}
+
+// Availability describes the availability of a transport on the current host.
+type Availability struct {
+	// Transport is the transport name.
+	Transport string
+	// Status is the transport's availability status.
+	Status AvailabilityStatus
+	// Reason describes why the transport is unavailable or only partially
+	// available. It is empty if the transport is fully available.
+	Reason string
+}
+
+// probeSSHAvailability probes the availability of the SSH transport. The ssh
+// command must be present and identify itself as OpenSSH for the transport to
+// be usable. The scp command is only required for agent installation on hosts
+// that don't support resumable uploads (e.g. Windows hosts using cmd.exe), so
+// its absence only results in partial availability.
+func probeSSHAvailability() Availability {
+	// Create the result.
+	result := Availability{Transport: "ssh"}
+
+	// Ensure that ssh is present and runnable.
+	ctx, cancel := context.WithTimeout(context.Background(), availabilityProbeTimeout)
+	defer cancel()
+	if _, err := ssh.SSHVersion(ctx); err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+
+	// Ensure that scp is present.
+	if _, err := ssh.SCPCommand(ctx); err != nil {
+		result.Status = AvailabilityStatusPartial
+		result.Reason = fmt.Sprintf("agent installation on hosts without resumable upload support unavailable: %v", err)
+		return result
+	}
+
+	// Success.
+	result.Status = AvailabilityStatusAvailable
+	return result
+}
+
+// probeDockerAvailability probes the availability of the Docker transport. The
+// docker command must be present and the default Docker daemon (as determined
+// by the current environment) must be reachable.
+func probeDockerAvailability() Availability {
+	// Create the result.
+	result := Availability{Transport: "docker"}
+
+	// Ensure that docker is present.
+	if _, err := docker.CommandPath(); err != nil {
+		result.Reason = fmt.Sprintf("unable to identify 'docker' command: %v", err)
+		return result
+	}
+
+	// Ensure that the daemon is reachable.
+	if _, err := docker.GetDaemonMetadata(docker.DaemonConnectionFlags{}, nil); err != nil {
+		result.Reason = fmt.Sprintf("unable to reach Docker daemon: %v", err)
+		return result
+	}
+
+	// Success.
+	result.Status = AvailabilityStatusAvailable
+	return result
+}
+
+// probeWSLAvailability probes the availability of the WSL transport. The host
+// must be a Windows system and wsl.exe must be present and runnable.
+func probeWSLAvailability() Availability {
+	// Create the result.
+	result := Availability{Transport: "wsl"}
+
+	// Ensure that we're on Windows.
+	if runtime.GOOS != "windows" {
+		result.Reason = "WSL is only supported on Windows"
+		return result
+	}
+
+	// Ensure that wsl.exe is present and runnable.
+	ctx, cancel := context.WithTimeout(context.Background(), availabilityProbeTimeout)
+	defer cancel()
+	if command, err := wsl.Command(ctx, "--list", "--quiet"); err != nil {
+		result.Reason = err.Error()
+		return result
+	} else if err = command.Run(); err != nil {
+		result.Reason = fmt.Sprintf("unable to list WSL distributions: %v", err)
+		return result
+	}
+
+	// Success.
+	result.Status = AvailabilityStatusAvailable
+	return result
+}
+
+// ProbeAvailability probes the prerequisites of each agent transport and
+// reports their availability on the current host. Transports are probed using
+// the same command resolution logic (including environment variable overrides)
+// that they use when establishing connections. Results are returned in a fixed
+// order.
+func ProbeAvailability() []Availability {
+	return []Availability{
+		probeSSHAvailability(),
+		probeDockerAvailability(),
+		probeWSLAvailability(),
+	}
+}
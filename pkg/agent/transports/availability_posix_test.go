@@ -0,0 +1,163 @@
+// +build !windows
+
+package transports
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testStubEnvironment creates a temporary directory containing fake
+// executables with the specified shell script bodies and configures the search
+// path overrides for all transports to use it. It returns a function that
+// restores the environment and removes the temporary directory.
+func testStubEnvironment(t *testing.T, executables map[string]string) func() {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create the temporary directory.
+	directory, err := ioutil.TempDir("", "mutagen_transports_stub")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+
+	// Write the fake executables.
+	for name, script := range executables {
+		content := []byte("#!/bin/sh\n" + script + "\n")
+		if err := ioutil.WriteFile(filepath.Join(directory, name), content, 0700); err != nil {
+			os.RemoveAll(directory)
+			t.Fatal("unable to write fake executable:", err)
+		}
+	}
+
+	// Configure the search paths, recording their previous values.
+	variables := []string{"MUTAGEN_SSH_PATH", "MUTAGEN_DOCKER_PATH", "MUTAGEN_WSL_PATH"}
+	previous := make(map[string]*string, len(variables))
+	for _, variable := range variables {
+		if value, ok := os.LookupEnv(variable); ok {
+			previous[variable] = &value
+		} else {
+			previous[variable] = nil
+		}
+		os.Setenv(variable, directory)
+	}
+
+	// Create the cleanup function.
+	return func() {
+		for variable, value := range previous {
+			if value != nil {
+				os.Setenv(variable, *value)
+			} else {
+				os.Unsetenv(variable)
+			}
+		}
+		os.RemoveAll(directory)
+	}
+}
+
+const (
+	// testFakeSSHScript is a fake ssh implementation.
+	testFakeSSHScript = "echo 'OpenSSH_9.0p1, OpenSSL 3.0.2 15 Mar 2022' >&2"
+	// testFakeSCPScript is a fake scp implementation.
+	testFakeSCPScript = "exit 0"
+	// testFakeDockerScript is a fake docker implementation with a reachable
+	// daemon.
+	testFakeDockerScript = `echo '{"ID":"daemon","OSType":"linux","ServerErrors":null}'`
+	// testFakeDockerUnreachableScript is a fake docker implementation with an
+	// unreachable daemon.
+	testFakeDockerUnreachableScript = `echo '{"ServerErrors":["Cannot connect to the Docker daemon"]}'`
+)
+
+// testFindAvailability locates the availability report for the specified
+// transport.
+func testFindAvailability(t *testing.T, report []Availability, transport string) Availability {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Search for the transport.
+	for _, availability := range report {
+		if availability.Transport == transport {
+			return availability
+		}
+	}
+
+	// Handle failure.
+	t.Fatal("transport missing from report:", transport)
+	return Availability{}
+}
+
+// TestProbeAvailabilityAllAvailable tests availability reporting when all
+// POSIX-supported transports are available.
+func TestProbeAvailabilityAllAvailable(t *testing.T) {
+	defer testStubEnvironment(t, map[string]string{
+		"ssh":    testFakeSSHScript,
+		"scp":    testFakeSCPScript,
+		"docker": testFakeDockerScript,
+	})()
+	report := ProbeAvailability()
+	for _, transport := range []string{"ssh", "docker"} {
+		if a := testFindAvailability(t, report, transport); a.Status != AvailabilityStatusAvailable {
+			t.Errorf("%s not available: %s", transport, a.Reason)
+		} else if a.Reason != "" {
+			t.Errorf("%s available with reason: %s", transport, a.Reason)
+		}
+	}
+	if a := testFindAvailability(t, report, "wsl"); a.Status != AvailabilityStatusUnavailable {
+		t.Error("wsl reported as available on non-Windows system")
+	} else if a.Reason == "" {
+		t.Error("wsl unavailability has no reason")
+	}
+}
+
+// TestProbeAvailabilityNoneAvailable tests availability reporting when no
+// transport commands are present.
+func TestProbeAvailabilityNoneAvailable(t *testing.T) {
+	defer testStubEnvironment(t, nil)()
+	for _, a := range ProbeAvailability() {
+		if a.Status != AvailabilityStatusUnavailable {
+			t.Errorf("%s reported as available without commands", a.Transport)
+		} else if a.Reason == "" {
+			t.Errorf("%s unavailability has no reason", a.Transport)
+		}
+	}
+}
+
+// TestProbeAvailabilitySSHWithoutSCP tests that the SSH transport is reported
+// as partially available if ssh is present but scp is missing.
+func TestProbeAvailabilitySSHWithoutSCP(t *testing.T) {
+	defer testStubEnvironment(t, map[string]string{"ssh": testFakeSSHScript})()
+	a := testFindAvailability(t, ProbeAvailability(), "ssh")
+	if a.Status != AvailabilityStatusPartial {
+		t.Fatal("ssh not reported as partially available:", a.Status.Description())
+	} else if !strings.Contains(a.Reason, "scp") {
+		t.Error("partial availability reason doesn't mention scp:", a.Reason)
+	}
+}
+
+// TestProbeAvailabilitySSHNotRunnable tests that the SSH transport is reported
+// as unavailable if ssh is present but fails to run.
+func TestProbeAvailabilitySSHNotRunnable(t *testing.T) {
+	defer testStubEnvironment(t, map[string]string{
+		"ssh": "exit 1",
+		"scp": testFakeSCPScript,
+	})()
+	if a := testFindAvailability(t, ProbeAvailability(), "ssh"); a.Status != AvailabilityStatusUnavailable {
+		t.Error("non-runnable ssh reported as available")
+	}
+}
+
+// TestProbeAvailabilityDockerDaemonUnreachable tests that the Docker transport
+// is reported as unavailable if docker is present but its daemon can't be
+// reached.
+func TestProbeAvailabilityDockerDaemonUnreachable(t *testing.T) {
+	defer testStubEnvironment(t, map[string]string{"docker": testFakeDockerUnreachableScript})()
+	a := testFindAvailability(t, ProbeAvailability(), "docker")
+	if a.Status != AvailabilityStatusUnavailable {
+		t.Fatal("docker with unreachable daemon reported as available")
+	} else if !strings.Contains(a.Reason, "daemon") {
+		t.Error("unavailability reason doesn't mention daemon:", a.Reason)
+	}
+}
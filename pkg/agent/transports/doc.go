@@ -1,2 +1,16 @@
 // Package transports provides agent transport implementations.
+//
+// Mutagen doesn't implement a direct TCP transport. Each transport reaches its
+// remote through an external tool (OpenSSH, the Docker CLI, or WSL), so
+// proxying is configured through that tool (e.g. with ProxyCommand or
+// ProxyJump in OpenSSH configuration, or with DOCKER_HOST for Docker) rather
+// than by Mutagen.
+//
+// Host name resolution is likewise performed by those tools rather than by
+// Mutagen, so resolution timeouts and caching are governed by the tools and
+// the system resolver configuration.
+//
+// The same applies to TCP socket options (such as keepalives and buffer
+// sizes), which are set through the corresponding tool settings (e.g.
+// TCPKeepAlive, ServerAliveInterval, or IPQoS in OpenSSH configuration).
 package transports
@@ -40,6 +40,9 @@ type transport struct {
 	daemonConnectionFlags []string
 	// prompter is the prompter identifier to use for prompting.
 	prompter string
+	// limits are the resource limits under which agents are launched. They may
+	// be nil.
+	limits *agent.ResourceLimits
 	// containerProbed indicates whether or not container probing has occurred.
 	// If true, then either containerHomeDirectory will be non-empty or
 	// containerProbeError will be non-nil.
@@ -65,7 +68,13 @@ type transport struct {
 }
 
 // NewTransport creates a new Docker transport using the specified parameters.
-func NewTransport(container, user string, environment, parameters map[string]string, prompter string) (agent.Transport, error) {
+// The resource limits may be nil.
+func NewTransport(container, user string, environment, parameters map[string]string, prompter string, limits *agent.ResourceLimits) (agent.Transport, error) {
+	// Validate the resource limits.
+	if err := limits.EnsureValid(); err != nil {
+		return nil, fmt.Errorf("invalid agent resource limits: %w", err)
+	}
+
 	// Convert URL parameters to top-level daemon connection flags.
 	daemonConnectionFlags, err := docker.LoadDaemonConnectionFlagsFromURLParameters(parameters)
 	if err != nil {
@@ -79,14 +88,16 @@ func NewTransport(container, user string, environment, parameters map[string]str
 		environment:           environment,
 		daemonConnectionFlags: daemonConnectionFlags.ToFlags(),
 		prompter:              prompter,
+		limits:                limits,
 	}, nil
 }
 
 // command is an underlying command generation function that allows
 // specification of the working directory inside the container, as well as an
 // override of the executing user. An empty user specification means to use the
-// username specified in the remote URL, if any.
-func (t *transport) command(command, workingDirectory, user string) (*exec.Cmd, error) {
+// username specified in the remote URL, if any. Any additional exec flags (e.g.
+// environment variable specifications) may also be provided.
+func (t *transport) command(command, workingDirectory, user string, execFlags []string) (*exec.Cmd, error) {
 	// Set up top-level command-line flags.
 	var dockerArguments []string
 	dockerArguments = append(dockerArguments, t.daemonConnectionFlags...)
@@ -109,6 +120,9 @@ func (t *transport) command(command, workingDirectory, user string) (*exec.Cmd,
 		dockerArguments = append(dockerArguments, "--workdir", workingDirectory)
 	}
 
+	// Add any additional exec flags.
+	dockerArguments = append(dockerArguments, execFlags...)
+
 	// Set the container name (this is stored as the Hostname field in the URL).
 	dockerArguments = append(dockerArguments, t.container)
 
@@ -174,7 +188,7 @@ func (t *transport) probeContainer() error {
 	// POSIX systems and identify the HOME environment variable value. If we
 	// detect a non-UTF-8 output or detect an empty home directory, we treat
 	// that as an error.
-	if command, err := t.command("env", "", ""); err != nil {
+	if command, err := t.command("env", "", "", nil); err != nil {
 		return errors.Wrap(err, "unable to set up Docker invocation")
 	} else if envBytes, err := command.Output(); err != nil {
 		posixErr = err
@@ -197,7 +211,7 @@ func (t *transport) probeContainer() error {
 	// If we didn't find a POSIX home directory, attempt to a similar procedure
 	// on Windows to identify the USERPROFILE environment variable.
 	if home == "" {
-		if command, err := t.command("cmd /c set", "", ""); err != nil {
+		if command, err := t.command("cmd /c set", "", "", nil); err != nil {
 			return errors.Wrap(err, "unable to set up Docker invocation")
 		} else if envBytes, err := command.Output(); err != nil {
 			windowsErr = err
@@ -239,7 +253,7 @@ func (t *transport) probeContainer() error {
 	var username, group string
 	if !windows {
 		// Query username.
-		if command, err := t.command("id -un", "", ""); err != nil {
+		if command, err := t.command("id -un", "", "", nil); err != nil {
 			return errors.Wrap(err, "unable to set up Docker invocation")
 		} else if usernameBytes, err := command.Output(); err != nil {
 			t.containerProbeError = errors.New("unable to probe POSIX username")
@@ -258,7 +272,7 @@ func (t *transport) probeContainer() error {
 		}
 
 		// Query default group name.
-		if command, err := t.command("id -gn", "", ""); err != nil {
+		if command, err := t.command("id -gn", "", "", nil); err != nil {
 			return errors.Wrap(err, "unable to set up Docker invocation")
 		} else if groupBytes, err := command.Output(); err != nil {
 			t.containerProbeError = errors.New("unable to probe POSIX group name")
@@ -444,7 +458,7 @@ func (t *transport) Copy(localPath, remoteName string) error {
 			t.containerUserGroup,
 			remoteName,
 		)
-		if command, err := t.command(chownCommand, t.containerHomeDirectory, "root"); err != nil {
+		if command, err := t.command(chownCommand, t.containerHomeDirectory, "root", nil); err != nil {
 			return errors.Wrap(err, "unable to set up Docker invocation")
 		} else if err := command.Run(); err != nil {
 			return errors.Wrap(err, "unable to set ownership of copied file")
@@ -471,7 +485,27 @@ func (t *transport) Command(command string) (*exec.Cmd, error) {
 	}
 
 	// Generate the command.
-	return t.command(command, t.containerHomeDirectory, "")
+	return t.command(command, t.containerHomeDirectory, "", nil)
+}
+
+// ResourceLimits implements the ResourceLimits method of
+// agent.LimitingTransport.
+func (t *transport) ResourceLimits() *agent.ResourceLimits {
+	return t.limits
+}
+
+// AgentCommand implements the AgentCommand method of agent.LimitingTransport.
+// Since Docker doesn't support imposing resource constraints on individual
+// exec invocations, the limits are communicated to the agent via its
+// environment and enforced by the agent itself.
+func (t *transport) AgentCommand(command string) (*exec.Cmd, error) {
+	// Ensure that the container has been probed.
+	if err := t.probeContainer(); err != nil {
+		return nil, errors.Wrap(err, "unable to probe container")
+	}
+
+	// Generate the command.
+	return t.command(command, t.containerHomeDirectory, "", t.limits.EnvironmentFlags())
 }
 
 // ClassifyError implements the ClassifyError method of agent.Transport.
@@ -29,28 +29,181 @@ const (
 
 // transport implements the agent.Transport interface using SSH.
 type transport struct {
-	// user is the SSH user under which agents should be invoked.
+	// user is the SSH user under which agents should be invoked. It has
+	// already been resolved against any user specified in the options.
 	user string
 	// host is the target host.
 	host string
-	// port is the target port.
-	port uint16
+	// options are the OpenSSH connection options. They may be nil.
+	options *ssh.Options
 	// prompter is the prompter identifier to use for prompting.
 	prompter string
+	// ephemeralHost indicates whether or not the target host should be treated
+	// as ephemeral, relaxing host key verification.
+	ephemeralHost bool
+	// limits are the resource limits under which agents are launched. They may
+	// be nil.
+	limits *agent.ResourceLimits
+	// cache is the regional agent cache used to install agents. It may be nil.
+	cache *agent.Cache
+	// credentials is the provider consulted for credentials each time that a
+	// connection is established. It may be nil.
+	credentials ssh.CredentialProvider
 }
 
-// NewTransport creates a new SSH transport using the specified parameters.
-func NewTransport(user, host string, port uint16, prompter string) (agent.Transport, error) {
+// NewTransport creates a new SSH transport using the specified parameters. The
+// options may be nil. If the options specify a user, then it takes precedence
+// over the user argument (which is typically taken from the endpoint URL) when
+// composing the destination specification. If ephemeralHost is true, then host key verification is
+// relaxed such that unknown host keys are accepted automatically and never
+// recorded (see ssh.EphemeralHostFlags), though an explicit strict host key
+// checking option takes precedence. The resource limits may be nil. If
+// specified, then agents are launched in a transient systemd scope on the
+// remote (see agent.ResourceLimits.SystemdRunCommand). If a cache host (of the
+// form [user@]host) is specified, then agents are installed via a regional
+// agent cache on that host (see agent.Cache), which is accessed using default
+// SSH options (except when relaying agents to remotes that can't reach the
+// cache host, in which case the transport's options also apply). If the options specify a credential command, then it's invoked
+// to obtain fresh credentials each time that a connection is established (see
+// ssh.NewCommandCredentialProvider). If the options specify a privilege
+// escalation command, then agents are invoked under privilege escalation (see
+// ssh.Options.EscalateCommand), which can't be combined with resource limits.
+func NewTransport(user, host string, options *ssh.Options, prompter string, ephemeralHost bool, limits *agent.ResourceLimits, cacheHost string) (agent.Transport, error) {
+	// Validate the options.
+	if err := options.EnsureValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid SSH options")
+	}
+
+	// Validate the resource limits.
+	if err := limits.EnsureValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid agent resource limits")
+	}
+
+	// Ensure that privilege escalation isn't combined with resource limits.
+	// The transient systemd scope is created in the user's service manager, so
+	// escalating inside it would escape the user's control, while escalating
+	// outside of it would discard the limits communicated to the agent (since
+	// escalation commands reset the environment).
+	if options.GetPrivilegeEscalation() != "" && !limits.IsZero() {
+		return nil, errors.New("privilege escalation can't be combined with agent resource limits")
+	}
+
+	// Create the credential provider, if any.
+	var credentials ssh.CredentialProvider
+	if command := options.GetCredentialCommand(); len(command) > 0 {
+		provider, err := ssh.NewCommandCredentialProvider(command)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to create credential provider")
+		}
+		credentials = provider
+	}
+
+	// Create the regional agent cache, if any.
+	var cache *agent.Cache
+	if cacheHost != "" {
+		cacheUser, cacheHostname, err := agent.ParseCacheHost(cacheHost)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid agent cache host")
+		}
+		cache = &agent.Cache{
+			Transport: &transport{
+				user:     cacheUser,
+				host:     cacheHostname,
+				prompter: prompter,
+			},
+			Host: cacheHost,
+		}
+	}
+
+	// Create the transport.
 	return &transport{
-		user:     user,
-		host:     host,
-		port:     port,
-		prompter: prompter,
+		user:          options.ResolveUser(user),
+		host:          host,
+		options:       options,
+		prompter:      prompter,
+		ephemeralHost: ephemeralHost,
+		limits:        limits,
+		cache:         cache,
+		credentials:   credentials,
 	}, nil
 }
 
+// AgentCache implements agent.CachingTransport.AgentCache.
+func (t *transport) AgentCache() *agent.Cache {
+	return t.cache
+}
+
+// baseConnectionFlags computes the connection flags common to scp and ssh,
+// i.e. all connection flags other than the port flag. Since each invocation of
+// scp or ssh establishes a new connection, it consults the credential provider
+// (if any) on every call.
+func (t *transport) baseConnectionFlags() ([]string, error) {
+	// Add timeout and keepalive flags.
+	var flags []string
+	flags = append(flags, ssh.ConnectTimeoutFlag(connectTimeoutSeconds))
+	flags = append(flags, ssh.ServerAliveFlags(serverAliveIntervalSeconds, serverAliveCountMax)...)
+
+	// Add credential flags, if necessary. OpenSSH tries identities in the order
+	// that they're specified, so these need to precede the structured option
+	// flags in order for fresh credentials to be preferred.
+	if t.credentials != nil {
+		credentials, err := t.credentials.Credentials(context.Background())
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to obtain credentials")
+		}
+		flags = append(flags, credentials.Flags()...)
+	}
+
+	// Add flags for structured options. OpenSSH uses the first value that it
+	// obtains for each configuration option, so these need to precede the
+	// ephemeral host flags in order to take precedence over them.
+	flags = append(flags, t.options.Flags()...)
+
+	// Add ephemeral host flags, if necessary.
+	if t.ephemeralHost {
+		flags = append(flags, ssh.EphemeralHostFlags()...)
+	}
+
+	// Done.
+	return flags, nil
+}
+
+// connectionFlags computes the connection flags to pass to scp (if scp is true)
+// or ssh (otherwise).
+func (t *transport) connectionFlags(scp bool) ([]string, error) {
+	// Compute the flags common to scp and ssh.
+	flags, err := t.baseConnectionFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the port flag, if necessary. Unfortunately scp and ssh use different
+	// flags for this.
+	if port := t.options.GetPort(); port != 0 {
+		if scp {
+			flags = append(flags, "-P", fmt.Sprintf("%d", port))
+		} else {
+			flags = append(flags, "-p", fmt.Sprintf("%d", port))
+		}
+	}
+
+	// Done.
+	return flags, nil
+}
+
 // Copy implements the Copy method of agent.Transport.
 func (t *transport) Copy(localPath, remoteName string) error {
+	// Attempt a chunked, resumable upload over an SSH command stream. This
+	// allows interrupted uploads to resume on subsequent attempts and verifies
+	// the uploaded content before moving it into place. If the remote doesn't
+	// support this mechanism (e.g. because it's a cmd.exe environment), then
+	// fall back to SCP.
+	if err := agent.UploadResumable(t, localPath, remoteName); err == nil {
+		return nil
+	} else if err != agent.ErrResumableUploadUnsupported {
+		return errors.Wrap(err, "unable to perform resumable upload")
+	}
+
 	// HACK: On Windows, we attempt to use SCP executables that might not
 	// understand Windows paths because they're designed to run inside a POSIX-
 	// style environment (e.g. MSYS or Cygwin). To work around this, we run them
@@ -81,14 +234,16 @@ func (t *transport) Copy(localPath, remoteName string) error {
 		destinationURL = fmt.Sprintf("%s@%s", t.user, destinationURL)
 	}
 
+	// Compute connection flags.
+	connectionFlags, err := t.connectionFlags(true)
+	if err != nil {
+		return err
+	}
+
 	// Set up arguments.
 	var scpArguments []string
 	scpArguments = append(scpArguments, ssh.CompressionFlag())
-	scpArguments = append(scpArguments, ssh.ConnectTimeoutFlag(connectTimeoutSeconds))
-	scpArguments = append(scpArguments, ssh.ServerAliveFlags(serverAliveIntervalSeconds, serverAliveCountMax)...)
-	if t.port != 0 {
-		scpArguments = append(scpArguments, "-P", fmt.Sprintf("%d", t.port))
-	}
+	scpArguments = append(scpArguments, connectionFlags...)
 	scpArguments = append(scpArguments, sourceBase, destinationURL)
 
 	// Create the process.
@@ -100,6 +255,77 @@ func (t *transport) Copy(localPath, remoteName string) error {
 	// Set the working directory.
 	scpCommand.Dir = workingDirectory
 
+	// Run the operation.
+	return t.runSCP(scpCommand)
+}
+
+// relayCommand creates (but does not start) a third-party scp process that
+// copies the cached agent executable with the specified name from the specified
+// cache host (of the form [user@]host) to the remote, routing the transfer
+// through the local host. Since scp applies the same connection flags to both
+// hosts in this mode, any options (e.g. ProxyJump) also apply to the cache
+// host, and relaying is refused for ephemeral hosts to avoid relaxing host key
+// verification for the cache host.
+func (t *transport) relayCommand(cacheHost, cachedName, remoteName string) (*exec.Cmd, error) {
+	// Ensure that the remote isn't ephemeral.
+	if t.ephemeralHost {
+		return nil, errors.New("relaying not supported for ephemeral hosts")
+	}
+
+	// Compute connection flags. We can't use a port flag since it would also
+	// apply to the cache host, so any port is specified in the destination.
+	connectionFlags, err := t.baseConnectionFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	// Compute the destination. As in Copy, we rely on the remote name being
+	// interpreted relative to the user's home directory.
+	target := t.host
+	if t.user != "" {
+		target = fmt.Sprintf("%s@%s", t.user, target)
+	}
+	var destination string
+	if port := t.options.GetPort(); port != 0 {
+		destination = fmt.Sprintf("scp://%s:%d/%s", target, port, remoteName)
+	} else {
+		destination = fmt.Sprintf("%s:%s", target, remoteName)
+	}
+
+	// Set up flags.
+	var scpFlags []string
+	scpFlags = append(scpFlags, ssh.CompressionFlag())
+	scpFlags = append(scpFlags, connectionFlags...)
+
+	// Create the process.
+	scpCommand, err := ssh.ThirdPartySCPCommand(
+		context.Background(),
+		fmt.Sprintf("%s:%s", cacheHost, cachedName),
+		destination,
+		scpFlags...,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to set up SCP invocation")
+	}
+
+	// Done.
+	return scpCommand, nil
+}
+
+// RelayFromCache implements agent.RelayingTransport.RelayFromCache.
+func (t *transport) RelayFromCache(cache *agent.Cache, cachedName, remoteName string) error {
+	// Create the process.
+	scpCommand, err := t.relayCommand(cache.Host, cachedName, remoteName)
+	if err != nil {
+		return err
+	}
+
+	// Run the operation.
+	return t.runSCP(scpCommand)
+}
+
+// runSCP runs the specified scp process with the transport's environment.
+func (t *transport) runSCP(scpCommand *exec.Cmd) error {
 	// Force it to run detached.
 	scpCommand.SysProcAttr = process.DetachedProcessAttributes()
 
@@ -110,7 +336,7 @@ func (t *transport) Copy(localPath, remoteName string) error {
 	environment = addLocaleVariables(environment)
 
 	// Set prompting environment variables
-	environment, err = SetPrompterVariables(environment, t.prompter)
+	environment, err := SetPrompterVariables(environment, t.prompter)
 	if err != nil {
 		return errors.Wrap(err, "unable to create prompter environment")
 	}
@@ -120,35 +346,55 @@ func (t *transport) Copy(localPath, remoteName string) error {
 
 	// Run the operation.
 	if err = scpCommand.Run(); err != nil {
-		return errors.Wrap(err, "unable to run SCP process")
+		return errors.Wrap(ssh.NewExecutionError("scp", err), "unable to run SCP process")
 	}
 
 	// Success.
 	return nil
 }
 
-// Command implements the Command method of agent.Transport.
-func (t *transport) Command(command string) (*exec.Cmd, error) {
+// commandArguments computes the ssh arguments for invoking the specified
+// command, wrapping it with the remote command prefix (if any). If forcePTY is
+// true, then pseudo-terminal allocation is forced.
+func (t *transport) commandArguments(command string, forcePTY bool) ([]string, error) {
 	// Compute the target.
 	target := t.host
 	if t.user != "" {
 		target = fmt.Sprintf("%s@%s", t.user, t.host)
 	}
 
+	// Compute connection flags.
+	connectionFlags, err := t.connectionFlags(false)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set up arguments. We intentionally don't use compression on SSH commands
 	// since the agent stream uses the FLATE algorithm internally and it's much
 	// more efficient to compress at that layer, even with the slower Go
 	// implementation.
 	var sshArguments []string
-	sshArguments = append(sshArguments, ssh.ConnectTimeoutFlag(connectTimeoutSeconds))
-	sshArguments = append(sshArguments, ssh.ServerAliveFlags(serverAliveIntervalSeconds, serverAliveCountMax)...)
-	if t.port != 0 {
-		sshArguments = append(sshArguments, "-p", fmt.Sprintf("%d", t.port))
+	sshArguments = append(sshArguments, connectionFlags...)
+	if forcePTY {
+		sshArguments = append(sshArguments, ssh.ForcePTYFlag())
+	}
+	sshArguments = append(sshArguments, target, t.options.WrapRemoteCommand(command))
+
+	// Done.
+	return sshArguments, nil
+}
+
+// command creates a process that invokes the specified command on the remote,
+// forcing pseudo-terminal allocation if forcePTY is true.
+func (t *transport) command(command string, forcePTY bool) (*exec.Cmd, error) {
+	// Compute arguments.
+	arguments, err := t.commandArguments(command, forcePTY)
+	if err != nil {
+		return nil, err
 	}
-	sshArguments = append(sshArguments, target, command)
 
 	// Create the process.
-	sshCommand, err := ssh.SSHCommand(context.Background(), sshArguments...)
+	sshCommand, err := ssh.SSHCommand(context.Background(), arguments...)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to set up SSH invocation")
 	}
@@ -175,8 +421,76 @@ func (t *transport) Command(command string) (*exec.Cmd, error) {
 	return sshCommand, nil
 }
 
+// Command implements the Command method of agent.Transport. Pseudo-terminal
+// allocation is never forced for these commands, since they're used for binary
+// streams (agent connections and resumable uploads).
+func (t *transport) Command(command string) (*exec.Cmd, error) {
+	return t.command(command, false)
+}
+
+// SetupCommand implements the SetupCommand method of agent.SetupTransport.
+// Pseudo-terminal allocation is forced for these commands if the options
+// request it.
+func (t *transport) SetupCommand(command string) (*exec.Cmd, error) {
+	return t.command(command, t.options.GetForcePTYForSetup())
+}
+
+// ResourceLimits implements the ResourceLimits method of
+// agent.LimitingTransport.
+func (t *transport) ResourceLimits() *agent.ResourceLimits {
+	return t.limits
+}
+
+// EscalatesPrivileges implements the EscalatesPrivileges method of
+// agent.EscalatingTransport.
+func (t *transport) EscalatesPrivileges() bool {
+	return t.options.GetPrivilegeEscalation() != ""
+}
+
+// AgentCommand implements the AgentCommand method of agent.LimitingTransport
+// and agent.EscalatingTransport. The agent is launched in a transient systemd
+// scope on the remote if resource limits are specified and is otherwise
+// launched under privilege escalation (if specified).
+func (t *transport) AgentCommand(command string) (*exec.Cmd, error) {
+	if !t.limits.IsZero() {
+		return t.command(t.limits.SystemdRunCommand(command), false)
+	}
+	return t.command(t.options.EscalateCommand(command), false)
+}
+
 // ClassifyError implements the ClassifyError method of agent.Transport.
 func (t *transport) ClassifyError(processState *os.ProcessState, errorOutput string) (bool, bool, error) {
+	// If agents are being launched under resource limits, then check whether
+	// or not systemd-run failed to launch the agent. Its absence would
+	// otherwise be mistaken for a missing agent binary.
+	if !t.limits.IsZero() {
+		if err := agent.ClassifySystemdRunFailure(errorOutput); err != nil {
+			return false, false, &agent.IdentifiedFailureError{Err: err}
+		}
+	}
+
+	// If agents are being launched under privilege escalation, then check
+	// whether or not escalation failed because a password was required. We
+	// also have to check whether or not the escalation command failed to find
+	// the agent, since it reports this itself rather than via the shell.
+	if t.EscalatesPrivileges() {
+		if err := t.options.ClassifyPrivilegeEscalationFailure(errorOutput); err != nil {
+			return false, false, &agent.IdentifiedFailureError{Err: err}
+		} else if t.options.PrivilegeEscalationCommandNotFound(errorOutput) {
+			return true, false, nil
+		}
+	}
+
+	// If environment variables were specified, then check whether or not
+	// OpenSSH rejected the SetEnv option used to set them. In that case, ssh
+	// fails before invoking any remote command, so there's nothing further to
+	// classify.
+	if len(t.options.GetSetEnv()) > 0 {
+		if err := ssh.ClassifySetEnvRejection(errorOutput); err != nil {
+			return false, false, &agent.IdentifiedFailureError{Err: err}
+		}
+	}
+
 	// SSH faithfully returns exit codes and error output, so we can use direct
 	// methods for testing and classification. Note that we may get POSIX-like
 	// error codes back even from Windows remotes, but that indicates a POSIX
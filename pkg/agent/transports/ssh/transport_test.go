@@ -1,6 +1,9 @@
 package ssh
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"os/user"
@@ -8,9 +11,12 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 	"unicode/utf8"
 
+	"github.com/mutagen-io/mutagen/pkg/agent"
 	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/ssh"
 )
 
 func TestCopy(t *testing.T) {
@@ -45,9 +51,9 @@ func TestCopy(t *testing.T) {
 
 	// Create a transport.
 	transport := &transport{
-		user: user.Username,
-		host: "localhost",
-		port: 22,
+		user:    user.Username,
+		host:    "localhost",
+		options: &ssh.Options{Port: 22},
 	}
 
 	// Compute the destination path.
@@ -95,9 +101,9 @@ func TestCommandOutput(t *testing.T) {
 
 	// Create a transport.
 	transport := &transport{
-		user: user.Username,
-		host: "localhost",
-		port: 22,
+		user:    user.Username,
+		host:    "localhost",
+		options: &ssh.Options{Port: 22},
 	}
 
 	// Attempt to execute the command.
@@ -113,3 +119,622 @@ func TestCommandOutput(t *testing.T) {
 		t.Error("output not in UTF-8 encoding")
 	}
 }
+
+// argumentsContain determines whether or not the specified arguments contain
+// the specified flags in order.
+func argumentsContain(arguments, flags []string) bool {
+	for a := range arguments {
+		if a+len(flags) > len(arguments) {
+			return false
+		}
+		match := true
+		for f, flag := range flags {
+			if arguments[a+f] != flag {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCommandEphemeralHostArguments(t *testing.T) {
+	// Compute the expected ephemeral host flags.
+	flags := []string{
+		"-oStrictHostKeyChecking=accept-new",
+		"-oUserKnownHostsFile=/dev/null",
+	}
+
+	// Verify that a standard transport doesn't relax host key verification.
+	standard, err := NewTransport("user", "example.org", nil, "", false, nil, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+	if command, err := standard.Command("true"); err != nil {
+		t.Fatal("unable to create command:", err)
+	} else if argumentsContain(command.Args, flags[:1]) || argumentsContain(command.Args, flags[1:]) {
+		t.Error("standard transport command contains ephemeral host flags:", command.Args)
+	}
+
+	// Verify that an ephemeral host transport includes the combined flags
+	// before the target specification.
+	ephemeral, err := NewTransport("user", "example.org", nil, "", true, nil, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+	if command, err := ephemeral.Command("true"); err != nil {
+		t.Fatal("unable to create command:", err)
+	} else if !argumentsContain(command.Args, append(flags, "user@example.org")) {
+		t.Error("ephemeral host transport command lacks ephemeral host flags:", command.Args)
+	}
+}
+
+func TestCommandOptionsArguments(t *testing.T) {
+	// Create a transport with a set of options that also uses ephemeral host
+	// key verification.
+	options := &ssh.Options{
+		Port:                  2222,
+		IdentityFiles:         []string{"/keys/first", "/keys/second"},
+		ProxyJump:             "bastion.example.org",
+		StrictHostKeyChecking: "yes",
+		ExtraArguments:        []string{"-4"},
+	}
+	transport, err := NewTransport("user", "example.org", options, "", true, nil, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+
+	// Verify that the command includes the option flags (ahead of the
+	// ephemeral host flags, so that explicit options take precedence), followed
+	// by the port and target specification.
+	expected := []string{
+		"-oIdentityFile=/keys/first",
+		"-oIdentityFile=/keys/second",
+		"-oProxyJump=bastion.example.org",
+		"-oStrictHostKeyChecking=yes",
+		"-4",
+		"-oStrictHostKeyChecking=accept-new",
+		"-oUserKnownHostsFile=/dev/null",
+		"-p", "2222",
+		"user@example.org",
+	}
+	if command, err := transport.Command("true"); err != nil {
+		t.Fatal("unable to create command:", err)
+	} else if !argumentsContain(command.Args, expected) {
+		t.Error("transport command lacks expected option flags:", command.Args)
+	}
+}
+
+func TestCommandSetEnvArguments(t *testing.T) {
+	// Create a transport with environment variables.
+	options := &ssh.Options{SetEnv: map[string]string{"TZ": "UTC", "LANG": "C"}}
+	transport, err := NewTransport("user", "example.org", options, "", false, nil, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+
+	// Verify that the command includes a single SetEnv flag ahead of the
+	// target specification.
+	expected := []string{"-oSetEnv=LANG=C TZ=UTC", "user@example.org"}
+	if command, err := transport.Command("true"); err != nil {
+		t.Fatal("unable to create command:", err)
+	} else if !argumentsContain(command.Args, expected) {
+		t.Error("transport command lacks expected SetEnv flag:", command.Args)
+	}
+
+	// Verify that invalid environment variables are rejected.
+	options = &ssh.Options{SetEnv: map[string]string{"LANG": "C\nEVIL=1"}}
+	if _, err := NewTransport("user", "example.org", options, "", false, nil, ""); err == nil {
+		t.Error("transport created with invalid environment variables")
+	}
+}
+
+func TestClassifyErrorSetEnvRejected(t *testing.T) {
+	// Create transports with and without environment variables.
+	options := &ssh.Options{SetEnv: map[string]string{"LANG": "C"}}
+	withSetEnv, err := NewTransport("user", "example.org", options, "", false, nil, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+	withoutSetEnv, err := NewTransport("user", "example.org", nil, "", false, nil, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+
+	// Verify that a rejected SetEnv option is identified as the cause of
+	// failure when environment variables have been specified.
+	output := "command-line line 0: Bad configuration option: setenv\n"
+	_, _, err = withSetEnv.ClassifyError(nil, output)
+	var identified *agent.IdentifiedFailureError
+	if err == nil {
+		t.Fatal("rejected SetEnv option classified as recoverable")
+	} else if !errors.As(err, &identified) {
+		t.Error("rejected SetEnv option not identified as cause of failure:", err)
+	} else if !errors.Is(err, ssh.ErrSetEnvRejected) {
+		t.Error("error does not match ErrSetEnvRejected:", err)
+	}
+
+	// Verify that the output isn't treated specially if no environment
+	// variables have been specified.
+	if _, _, err = withoutSetEnv.ClassifyError(nil, output); err == nil {
+		t.Fatal("unknown error classified as recoverable")
+	} else if errors.As(err, &identified) {
+		t.Error("unknown error identified as specific cause of failure:", err)
+	}
+}
+
+func TestNewTransportInvalidOptions(t *testing.T) {
+	// Verify that invalid options are rejected.
+	options := &ssh.Options{StrictHostKeyChecking: "sometimes"}
+	if _, err := NewTransport("user", "example.org", options, "", false, nil, ""); err == nil {
+		t.Error("transport created with invalid options")
+	}
+}
+
+func TestCommandUserPrecedence(t *testing.T) {
+	// Define test cases covering a user specified only in the URL, only in the
+	// options, in both (where the options take precedence), and in neither.
+	testCases := []struct {
+		urlUser        string
+		options        *ssh.Options
+		expectedTarget string
+	}{
+		{"url", nil, "url@example.org"},
+		{"", &ssh.Options{User: "configured"}, "configured@example.org"},
+		{"url", &ssh.Options{User: "configured"}, "configured@example.org"},
+		{"", &ssh.Options{}, "example.org"},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		transport, err := NewTransport(testCase.urlUser, "example.org", testCase.options, "", false, nil, "")
+		if err != nil {
+			t.Fatalf("test case %d: unable to create transport: %v", i, err)
+		}
+		if command, err := transport.Command("true"); err != nil {
+			t.Fatalf("test case %d: unable to create command: %v", i, err)
+		} else if !argumentsContain(command.Args, []string{testCase.expectedTarget, "true"}) {
+			t.Errorf("test case %d: transport command lacks expected target (%s): %v", i, testCase.expectedTarget, command.Args)
+		}
+	}
+}
+
+func TestCommandForcePTYArguments(t *testing.T) {
+	// Define test cases covering transports with and without forced
+	// pseudo-terminal allocation for setup commands.
+	testCases := []struct {
+		options       *ssh.Options
+		expectedSetup bool
+	}{
+		{nil, false},
+		{&ssh.Options{}, false},
+		{&ssh.Options{ForcePTYForSetup: true}, true},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		transport, err := NewTransport("user", "example.org", testCase.options, "", false, nil, "")
+		if err != nil {
+			t.Fatalf("test case %d: unable to create transport: %v", i, err)
+		}
+		setupTransport, ok := transport.(agent.SetupTransport)
+		if !ok {
+			t.Fatalf("test case %d: transport doesn't support setup commands", i)
+		}
+
+		// Verify that setup commands only force pseudo-terminal allocation
+		// (ahead of the target specification) under the opt-in.
+		if command, err := setupTransport.SetupCommand("uname -s -m"); err != nil {
+			t.Fatalf("test case %d: unable to create setup command: %v", i, err)
+		} else if forced := argumentsContain(command.Args, []string{"-tt", "user@example.org"}); forced != testCase.expectedSetup {
+			t.Errorf("test case %d: setup command pseudo-terminal allocation incorrect: %v", i, command.Args)
+		} else if !forced && argumentsContain(command.Args, []string{"-tt"}) {
+			t.Errorf("test case %d: setup command forces pseudo-terminal allocation: %v", i, command.Args)
+		}
+
+		// Verify that data stream commands never force pseudo-terminal
+		// allocation.
+		if command, err := transport.Command("agent synchronizer"); err != nil {
+			t.Fatalf("test case %d: unable to create command: %v", i, err)
+		} else if argumentsContain(command.Args, []string{"-tt"}) {
+			t.Errorf("test case %d: data stream command forces pseudo-terminal allocation: %v", i, command.Args)
+		}
+	}
+}
+
+func TestCommandRemoteCommandPrefix(t *testing.T) {
+	// Create a transport with a representative wrapper that switches to a
+	// service user.
+	options := &ssh.Options{RemoteCommandPrefix: "sudo -u service sh -c"}
+	transport, err := NewTransport("user", "example.org", options, "", false, nil, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+	setupTransport, ok := transport.(agent.SetupTransport)
+	if !ok {
+		t.Fatal("transport doesn't support setup commands")
+	}
+
+	// Verify that data stream commands are wrapped and quoted.
+	if command, err := transport.Command(".mutagen/agents/0.12.0/mutagen-agent synchronizer"); err != nil {
+		t.Fatal("unable to create command:", err)
+	} else if !argumentsContain(command.Args, []string{
+		"user@example.org",
+		"sudo -u service sh -c '.mutagen/agents/0.12.0/mutagen-agent synchronizer'",
+	}) {
+		t.Error("command not wrapped correctly:", command.Args)
+	}
+
+	// Verify that setup commands are wrapped and that embedded quotes are
+	// escaped.
+	if command, err := setupTransport.SetupCommand("echo 'uname'"); err != nil {
+		t.Fatal("unable to create setup command:", err)
+	} else if !argumentsContain(command.Args, []string{
+		"user@example.org",
+		`sudo -u service sh -c 'echo '\''uname'\'''`,
+	}) {
+		t.Error("setup command not wrapped correctly:", command.Args)
+	}
+}
+
+func TestAgentCommandResourceLimits(t *testing.T) {
+	// Verify that invalid limits are rejected.
+	if _, err := NewTransport("user", "example.org", nil, "", false, &agent.ResourceLimits{Memory: 1024}, ""); err == nil {
+		t.Error("transport creation succeeded with invalid resource limits")
+	}
+
+	// Create a transport with resource limits.
+	limits := &agent.ResourceLimits{Memory: 512 * 1024 * 1024, CPU: 50}
+	transport, err := NewTransport("user", "example.org", nil, "", false, limits, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+	limitingTransport, ok := transport.(agent.LimitingTransport)
+	if !ok {
+		t.Fatal("transport doesn't support resource limits")
+	} else if limitingTransport.ResourceLimits() != limits {
+		t.Error("transport resource limits incorrect")
+	}
+
+	// Verify that agent commands are launched in a limited systemd scope.
+	if command, err := limitingTransport.AgentCommand(".mutagen/agents/0.12.0/mutagen-agent synchronizer"); err != nil {
+		t.Fatal("unable to create agent command:", err)
+	} else if !argumentsContain(command.Args, []string{
+		"user@example.org",
+		"env MUTAGEN_AGENT_MEMORY_LIMIT=536870912 MUTAGEN_AGENT_CPU_LIMIT=50 " +
+			"systemd-run --user --scope --quiet -p MemoryMax=536870912 -p MemorySwapMax=0 -p CPUQuota=50% " +
+			".mutagen/agents/0.12.0/mutagen-agent synchronizer",
+	}) {
+		t.Error("agent command not limited correctly:", command.Args)
+	}
+
+	// Verify that other commands aren't limited.
+	if command, err := transport.Command("uname -s -m"); err != nil {
+		t.Fatal("unable to create command:", err)
+	} else if !argumentsContain(command.Args, []string{"user@example.org", "uname -s -m"}) {
+		t.Error("non-agent command modified:", command.Args)
+	}
+
+	// Verify that a missing systemd-run is identified rather than being
+	// mistaken for a missing agent binary.
+	_, _, err = transport.ClassifyError(nil, "sh: 1: systemd-run: not found\n")
+	var identified *agent.IdentifiedFailureError
+	if !errors.As(err, &identified) {
+		t.Error("missing systemd-run not identified:", err)
+	}
+}
+
+func TestAgentCommandPrivilegeEscalation(t *testing.T) {
+	// Verify that privilege escalation can't be combined with resource limits.
+	options := &ssh.Options{PrivilegeEscalation: "sudo"}
+	if _, err := NewTransport("user", "example.org", options, "", false, &agent.ResourceLimits{CPU: 50}, ""); err == nil {
+		t.Error("transport creation succeeded with privilege escalation and resource limits")
+	}
+
+	// Create a transport that escalates privileges inside a remote command
+	// prefix.
+	options = &ssh.Options{
+		RemoteCommandPrefix: "bash -lc",
+		PrivilegeEscalation: "sudo -u root",
+	}
+	transport, err := NewTransport("user", "example.org", options, "", false, nil, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+	escalatingTransport, ok := transport.(agent.EscalatingTransport)
+	if !ok {
+		t.Fatal("transport doesn't support privilege escalation")
+	} else if !escalatingTransport.EscalatesPrivileges() {
+		t.Fatal("transport doesn't indicate privilege escalation")
+	}
+
+	// Verify that agent commands are escalated non-interactively and then
+	// wrapped by the prefix.
+	if command, err := escalatingTransport.AgentCommand(".mutagen/agents/0.12.0/mutagen-agent synchronizer"); err != nil {
+		t.Fatal("unable to create agent command:", err)
+	} else if !argumentsContain(command.Args, []string{
+		"user@example.org",
+		"bash -lc 'sudo -n -u root .mutagen/agents/0.12.0/mutagen-agent synchronizer'",
+	}) {
+		t.Error("agent command not escalated correctly:", command.Args)
+	}
+
+	// Verify that other commands aren't escalated.
+	if command, err := transport.Command("uname -s -m"); err != nil {
+		t.Fatal("unable to create command:", err)
+	} else if !argumentsContain(command.Args, []string{"user@example.org", "bash -lc 'uname -s -m'"}) {
+		t.Error("non-agent command escalated:", command.Args)
+	}
+
+	// Verify that a missing agent reported by the escalation command triggers
+	// installation.
+	if tryInstall, _, err := transport.ClassifyError(nil, "sudo: .mutagen/agents/0.12.0/mutagen-agent: command not found\n"); err != nil {
+		t.Error("missing agent not classified:", err)
+	} else if !tryInstall {
+		t.Error("missing agent didn't trigger installation")
+	}
+}
+
+// testPasswordRequiringSudo is a stub sudo implementation that emulates sudo
+// when a password is required. It fails with sudo's error output if invoked in
+// non-interactive mode and otherwise hangs (as if waiting on a password
+// prompt).
+const testPasswordRequiringSudo = `#!/bin/sh
+for argument in "$@"; do
+	if [ "$argument" = "-n" ]; then
+		echo "sudo: a password is required" 1>&2
+		exit 1
+	fi
+done
+sleep 60
+`
+
+// testLocalSSH is a stub ssh implementation that runs the remote command (its
+// last argument) locally.
+const testLocalSSH = `#!/bin/sh
+for command in "$@"; do :; done
+exec /bin/sh -c "$command"
+`
+
+func TestAgentCommandPrivilegeEscalationPasswordRequired(t *testing.T) {
+	// Stub commands are POSIX shell scripts.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Create a temporary directory containing stub ssh and sudo commands and
+	// defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_ssh_escalation")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+	if err := ioutil.WriteFile(filepath.Join(directory, "ssh"), []byte(testLocalSSH), 0700); err != nil {
+		t.Fatal("unable to create stub ssh:", err)
+	} else if err := ioutil.WriteFile(filepath.Join(directory, "sudo"), []byte(testPasswordRequiringSudo), 0700); err != nil {
+		t.Fatal("unable to create stub sudo:", err)
+	}
+
+	// Use the stubs for the duration of the test.
+	for variable, value := range map[string]string{
+		"MUTAGEN_SSH_PATH": directory,
+		"PATH":             directory + string(os.PathListSeparator) + os.Getenv("PATH"),
+	} {
+		original, set := os.LookupEnv(variable)
+		if err := os.Setenv(variable, value); err != nil {
+			t.Fatal("unable to set environment variable:", err)
+		}
+		defer func(variable, original string, set bool) {
+			if set {
+				os.Setenv(variable, original)
+			} else {
+				os.Unsetenv(variable)
+			}
+		}(variable, original, set)
+	}
+
+	// Create a transport that escalates privileges.
+	options := &ssh.Options{PrivilegeEscalation: "sudo"}
+	transport, err := NewTransport("user", "example.org", options, "", false, nil, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+
+	// Run an agent command, capturing its error output. If the non-interactive
+	// flag weren't enforced, then the stub would hang until the timeout.
+	command, err := transport.(agent.EscalatingTransport).AgentCommand("mutagen-agent synchronizer")
+	if err != nil {
+		t.Fatal("unable to create agent command:", err)
+	}
+	errorOutput := &strings.Builder{}
+	command.Stderr = errorOutput
+	done := make(chan error, 1)
+	if err := command.Start(); err != nil {
+		t.Fatal("unable to start agent command:", err)
+	}
+	go func() {
+		done <- command.Wait()
+	}()
+	select {
+	case err = <-done:
+		if err == nil {
+			t.Fatal("agent command succeeded")
+		}
+	case <-time.After(30 * time.Second):
+		command.Process.Kill()
+		t.Fatal("agent command hung waiting for password")
+	}
+
+	// Verify that the failure is identified as requiring a password.
+	_, _, err = transport.ClassifyError(command.ProcessState, errorOutput.String())
+	var identified *agent.IdentifiedFailureError
+	if err == nil {
+		t.Fatal("password requirement classified as recoverable")
+	} else if !errors.As(err, &identified) {
+		t.Error("password requirement not identified as cause of failure:", err)
+	} else if !errors.Is(err, ssh.ErrPrivilegeEscalationPasswordRequired) {
+		t.Error("error does not match ErrPrivilegeEscalationPasswordRequired:", err)
+	} else if !strings.Contains(err.Error(), "requires a password") {
+		t.Error("error doesn't clearly indicate password requirement:", err)
+	}
+}
+
+func TestAgentCache(t *testing.T) {
+	// Verify that invalid cache hosts are rejected.
+	if _, err := NewTransport("user", "example.org", nil, "", false, nil, "-oProxyCommand=x"); err == nil {
+		t.Error("transport creation succeeded with invalid cache host")
+	}
+
+	// Verify that transports without a cache host don't provide a cache.
+	transport, err := NewTransport("user", "example.org", nil, "", false, nil, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	} else if transport.(agent.CachingTransport).AgentCache() != nil {
+		t.Error("cache provided without cache host")
+	}
+
+	// Create a transport with a cache host.
+	transport, err = NewTransport("user", "example.org", nil, "", false, nil, "cache@cache.example.org")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+	cache := transport.(agent.CachingTransport).AgentCache()
+	if cache == nil {
+		t.Fatal("cache not provided with cache host")
+	} else if cache.Host != "cache@cache.example.org" {
+		t.Error("cache host incorrect:", cache.Host)
+	}
+
+	// Verify that the cache transport targets the cache host.
+	if command, err := cache.Transport.Command("test -f name"); err != nil {
+		t.Fatal("unable to create cache command:", err)
+	} else if !argumentsContain(command.Args, []string{"cache@cache.example.org", "test -f name"}) {
+		t.Error("cache command not targeted correctly:", command.Args)
+	}
+}
+
+// TestRelayCommand tests that relaying from an agent cache constructs a
+// third-party scp copy with any port specified in the destination.
+func TestRelayCommand(t *testing.T) {
+	// Create a transport with options (including a non-default port) and verify
+	// that it supports relaying.
+	options := &ssh.Options{Port: 2222, ProxyJump: "bastion.example.org"}
+	relaying, err := NewTransport("user", "example.org", options, "", false, nil, "cache@cache.example.org")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	} else if _, ok := relaying.(agent.RelayingTransport); !ok {
+		t.Fatal("transport doesn't support relaying")
+	}
+
+	// Verify that the relay command routes the copy through the local host,
+	// applies the options, and specifies the port in the destination rather
+	// than using a port flag (which would also apply to the cache host).
+	command, err := relaying.(*transport).relayCommand("cache@cache.example.org", "cached", "destination")
+	if err != nil {
+		t.Fatal("unable to create relay command:", err)
+	}
+	if !argumentsContain(command.Args, []string{"-3", "-C"}) {
+		t.Error("relay command doesn't perform a third-party copy:", command.Args)
+	} else if !argumentsContain(command.Args, []string{"-oProxyJump=bastion.example.org"}) {
+		t.Error("relay command lacks option flags:", command.Args)
+	} else if !argumentsContain(command.Args, []string{"cache@cache.example.org:cached", "scp://user@example.org:2222/destination"}) {
+		t.Error("relay command has incorrect operands:", command.Args)
+	} else if argumentsContain(command.Args, []string{"-P"}) {
+		t.Error("relay command uses port flag:", command.Args)
+	}
+
+	// Verify that relaying to ephemeral hosts is refused.
+	ephemeral, err := NewTransport("user", "example.org", nil, "", true, nil, "cache@cache.example.org")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	} else if _, err := ephemeral.(*transport).relayCommand("cache@cache.example.org", "cached", "destination"); err == nil {
+		t.Error("relay command created for ephemeral host")
+	}
+}
+
+// testCredentialProvider is a stub ssh.CredentialProvider that provides a new
+// set of credentials (or a failure) on each request.
+type testCredentialProvider struct {
+	// requests is the number of requests for credentials that have been made.
+	requests int
+	// fail indicates whether or not requests should fail.
+	fail bool
+}
+
+// Credentials implements ssh.CredentialProvider.Credentials.
+func (p *testCredentialProvider) Credentials(_ context.Context) (*ssh.Credentials, error) {
+	p.requests++
+	if p.fail {
+		return nil, errors.New("credentials unavailable")
+	}
+	return &ssh.Credentials{
+		IdentityFiles:    []string{fmt.Sprintf("/fresh/key%d", p.requests)},
+		CertificateFiles: []string{fmt.Sprintf("/fresh/key%d-cert.pub", p.requests)},
+	}, nil
+}
+
+func TestCommandCredentialProvider(t *testing.T) {
+	// Create a transport and attach a stub credential provider.
+	options := &ssh.Options{IdentityFiles: []string{"/keys/static"}}
+	created, err := NewTransport("user", "example.org", options, "", false, nil, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+	provider := &testCredentialProvider{}
+	created.(*transport).credentials = provider
+
+	// Verify that credentials are fetched when connecting and that they take
+	// precedence over statically configured identity files.
+	if command, err := created.Command("true"); err != nil {
+		t.Fatal("unable to create command:", err)
+	} else if provider.requests != 1 {
+		t.Error("credentials not fetched on connect")
+	} else if !argumentsContain(command.Args, []string{
+		"-oIdentityFile=/fresh/key1",
+		"-oCertificateFile=/fresh/key1-cert.pub",
+		"-oIdentityFile=/keys/static",
+	}) {
+		t.Error("command lacks fetched credentials:", command.Args)
+	}
+
+	// Verify that credentials are re-fetched when reconnecting.
+	if command, err := created.Command("true"); err != nil {
+		t.Fatal("unable to create command:", err)
+	} else if provider.requests != 2 {
+		t.Error("credentials not re-fetched on reconnect")
+	} else if !argumentsContain(command.Args, []string{"-oIdentityFile=/fresh/key2", "-oCertificateFile=/fresh/key2-cert.pub"}) {
+		t.Error("command lacks re-fetched credentials:", command.Args)
+	} else if argumentsContain(command.Args, []string{"-oIdentityFile=/fresh/key1"}) {
+		t.Error("command contains stale credentials:", command.Args)
+	}
+
+	// Verify that credential failures prevent connections.
+	provider.fail = true
+	if _, err := created.Command("true"); err == nil {
+		t.Error("command created despite credential failure")
+	}
+}
+
+func TestNewTransportCredentialCommand(t *testing.T) {
+	// Verify that transports without a credential command don't use a
+	// credential provider.
+	created, err := NewTransport("user", "example.org", nil, "", false, nil, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	} else if created.(*transport).credentials != nil {
+		t.Error("credential provider created without credential command")
+	}
+
+	// Verify that transports with a credential command use a credential
+	// provider.
+	options := &ssh.Options{CredentialCommand: []string{"fetch-credentials"}}
+	created, err = NewTransport("user", "example.org", options, "", false, nil, "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	} else if created.(*transport).credentials == nil {
+		t.Error("credential provider not created with credential command")
+	}
+}
@@ -0,0 +1,3 @@
+// Package wsl provides the Windows Subsystem for Linux transport
+// implementation.
+package wsl
@@ -0,0 +1,130 @@
+package wsl
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/agent"
+	"github.com/mutagen-io/mutagen/pkg/process"
+	"github.com/mutagen-io/mutagen/pkg/wsl"
+)
+
+// transport implements the agent.Transport interface using wsl.exe.
+type transport struct {
+	// distribution is the target distribution name.
+	distribution string
+	// user is the distribution user under which agents should be invoked. If
+	// empty, the distribution's default user is used.
+	user string
+}
+
+// NewTransport creates a new WSL transport using the specified parameters.
+func NewTransport(distribution, user string) (agent.Transport, error) {
+	// Ensure that a distribution has been specified. Although wsl.exe will
+	// fall back to the default distribution if none is specified, that's
+	// subject to change and we don't want sessions to silently move.
+	if distribution == "" {
+		return nil, errors.New("empty distribution name")
+	}
+
+	// Success.
+	return &transport{
+		distribution: distribution,
+		user:         user,
+	}, nil
+}
+
+// command is an underlying command generation function that allows the
+// specification of whether or not the command should be executed directly
+// (rather than via the distribution user's default shell). Commands are always
+// executed with the user's home directory as the working directory.
+func (t *transport) command(direct bool, arguments ...string) (*exec.Cmd, error) {
+	// Tell wsl.exe which distribution to target.
+	wslArguments := []string{"-d", t.distribution}
+
+	// If specified, tell wsl.exe which user should be used to execute commands
+	// inside the distribution.
+	if t.user != "" {
+		wslArguments = append(wslArguments, "-u", t.user)
+	}
+
+	// Tell wsl.exe to use the user's home directory as the working directory.
+	wslArguments = append(wslArguments, "--cd", "~")
+
+	// Specify how the command should be executed. Direct execution avoids the
+	// shell entirely, which is necessary for arguments containing whitespace,
+	// but shell execution allows us to identify missing commands using
+	// standard shell exit codes.
+	if direct {
+		wslArguments = append(wslArguments, "--exec")
+	} else {
+		wslArguments = append(wslArguments, "--")
+	}
+
+	// Add the command.
+	wslArguments = append(wslArguments, arguments...)
+
+	// Create the command.
+	wslCommand, err := wsl.Command(context.Background(), wslArguments...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Force it to run detached.
+	wslCommand.SysProcAttr = process.DetachedProcessAttributes()
+
+	// Done.
+	return wslCommand, nil
+}
+
+// Copy implements the Copy method of agent.Transport.
+func (t *transport) Copy(localPath, remoteName string) error {
+	// Translate the local path to its location inside the distribution. This
+	// relies on the local path residing on a drive that's automounted inside
+	// the distribution, which is the case for temporary directories by
+	// default. On non-Windows systems (i.e. when invoked from inside WSL), the
+	// path is already a Linux path and will be left as-is.
+	source, err := wsl.WindowsPathToLinux(t.distribution, localPath)
+	if err != nil {
+		return errors.Wrap(err, "unable to translate local path")
+	}
+
+	// Copy the file using cp inside the distribution. We execute it directly
+	// because the source path may contain whitespace. Because the copy runs as
+	// the target user, the file will have the correct ownership.
+	if command, err := t.command(true, "cp", source, remoteName); err != nil {
+		return errors.Wrap(err, "unable to set up WSL invocation")
+	} else if err := command.Run(); err != nil {
+		return errors.Wrap(err, "unable to run WSL copy command")
+	}
+
+	// Success.
+	return nil
+}
+
+// Command implements the Command method of agent.Transport.
+func (t *transport) Command(command string) (*exec.Cmd, error) {
+	// Lex the command that we want to run since wsl.exe, like Docker, wants
+	// the commands and arguments separately. All agent.Transport interfaces
+	// only need to support commands that can be lexed by splitting on spaces.
+	return t.command(false, strings.Split(command, " ")...)
+}
+
+// ClassifyError implements the ClassifyError method of agent.Transport.
+func (t *transport) ClassifyError(processState *os.ProcessState, errorOutput string) (bool, bool, error) {
+	// WSL distributions are always Linux systems and wsl.exe propagates the
+	// exit code of the shell, so we can rely on standard POSIX shell exit
+	// codes to identify cases where the agent needs to be (re-)installed.
+	if process.IsPOSIXShellInvalidCommand(processState) {
+		return true, false, nil
+	} else if process.IsPOSIXShellCommandNotFound(processState) {
+		return true, false, nil
+	}
+
+	// Otherwise we can't determine the cause of the error.
+	return false, false, errors.New("unknown process exit error")
+}
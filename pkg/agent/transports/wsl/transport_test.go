@@ -0,0 +1,223 @@
+package wsl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/process"
+)
+
+// testStubScript is a POSIX shell script that stands in for wsl.exe. It records
+// its arguments (one per line) to the file specified by MUTAGEN_TEST_WSL_LOG and
+// exits with the code specified by MUTAGEN_TEST_WSL_EXIT_CODE (or 0).
+const testStubScript = `#!/bin/sh
+for argument in "$@"; do
+	echo "$argument"
+done > "$MUTAGEN_TEST_WSL_LOG"
+exit ${MUTAGEN_TEST_WSL_EXIT_CODE:-0}
+`
+
+// testSetenv sets an environment variable for the duration of a test.
+func testSetenv(t *testing.T, key, value string) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Set the variable and register restoration of its previous state.
+	previous, previousSet := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal("unable to set environment variable:", err)
+	}
+	t.Cleanup(func() {
+		if previousSet {
+			os.Setenv(key, previous)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+// testStubWSL installs a stub wsl.exe executable in a temporary directory and
+// redirects wsl.exe lookup to it. It returns the path to the stub and the path
+// to the file in which the stub records its arguments.
+func testStubWSL(t *testing.T) (string, string) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create a temporary directory and register its removal.
+	directory, err := ioutil.TempDir("", "mutagen_wsl_transport")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(directory)
+	})
+
+	// Create the stub.
+	stub := filepath.Join(directory, process.ExecutableName("wsl", runtime.GOOS))
+	if err := ioutil.WriteFile(stub, []byte(testStubScript), 0700); err != nil {
+		t.Fatal("unable to create stub executable:", err)
+	}
+
+	// Redirect lookup and stub output.
+	log := filepath.Join(directory, "arguments")
+	testSetenv(t, "MUTAGEN_WSL_PATH", directory)
+	testSetenv(t, "MUTAGEN_TEST_WSL_LOG", log)
+
+	// Done.
+	return stub, log
+}
+
+// testStubArguments reads the arguments recorded by the stub.
+func testStubArguments(t *testing.T, log string) []string {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Read and split the log.
+	contents, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatal("unable to read stub arguments:", err)
+	}
+	return strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+}
+
+// testArgumentsEqual verifies that two argument lists are equal.
+func testArgumentsEqual(t *testing.T, actual, expected []string) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Compare the lists.
+	if len(actual) != len(expected) {
+		t.Fatalf("argument count mismatch: %q != %q", actual, expected)
+	}
+	for a, argument := range actual {
+		if argument != expected[a] {
+			t.Errorf("argument %d mismatch: %q != %q", a, argument, expected[a])
+		}
+	}
+}
+
+// TestNewTransportEmptyDistribution tests that transports can't be created
+// without a distribution name.
+func TestNewTransportEmptyDistribution(t *testing.T) {
+	if _, err := NewTransport("", "user"); err == nil {
+		t.Error("transport created with empty distribution name")
+	}
+}
+
+// TestTransportCommand tests that commands are constructed correctly.
+func TestTransportCommand(t *testing.T) {
+	// Install the stub.
+	stub, _ := testStubWSL(t)
+
+	// Test command construction with and without a user.
+	testCases := []struct {
+		user     string
+		expected []string
+	}{
+		{"", []string{"-d", "Ubuntu", "--cd", "~", "--", ".mutagen/agent", "synchronizer"}},
+		{"üsér", []string{"-d", "Ubuntu", "-u", "üsér", "--cd", "~", "--", ".mutagen/agent", "synchronizer"}},
+	}
+	for _, testCase := range testCases {
+		transport, err := NewTransport("Ubuntu", testCase.user)
+		if err != nil {
+			t.Fatal("unable to create transport:", err)
+		}
+		command, err := transport.Command(".mutagen/agent synchronizer")
+		if err != nil {
+			t.Fatal("unable to create command:", err)
+		} else if command.Path != stub {
+			t.Errorf("command path incorrect: %q != %q", command.Path, stub)
+		}
+		testArgumentsEqual(t, command.Args[1:], testCase.expected)
+	}
+}
+
+// TestTransportCopy tests that copy operations invoke cp inside the
+// distribution with a translated source path.
+func TestTransportCopy(t *testing.T) {
+	// The stub is a shell script, so we can only run it on POSIX systems.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Install the stub.
+	_, log := testStubWSL(t)
+
+	// Create a transport.
+	transport, err := NewTransport("Ubuntu", "user")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+
+	// Test copy operations with both Windows and Linux local paths.
+	testCases := []struct {
+		localPath string
+		source    string
+	}{
+		{`C:\Users\John Smith\AppData\Local\Temp\agent`, "/mnt/c/Users/John Smith/AppData/Local/Temp/agent"},
+		{"/tmp/the directory/agent", "/tmp/the directory/agent"},
+	}
+	for _, testCase := range testCases {
+		if err := transport.Copy(testCase.localPath, "mutagen-agent-1234"); err != nil {
+			t.Fatal("unable to perform copy:", err)
+		}
+		testArgumentsEqual(t, testStubArguments(t, log), []string{
+			"-d", "Ubuntu", "-u", "user", "--cd", "~", "--exec", "cp", testCase.source, "mutagen-agent-1234",
+		})
+	}
+
+	// Test that copy operations fail for untranslatable paths.
+	if err := transport.Copy(`\\server\share\agent`, "mutagen-agent-1234"); err == nil {
+		t.Error("copy succeeded with untranslatable path")
+	}
+}
+
+// TestTransportClassifyError tests that shell exit codes indicating a missing
+// agent are classified as requiring installation.
+func TestTransportClassifyError(t *testing.T) {
+	// The stub is a shell script, so we can only run it on POSIX systems.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Install the stub.
+	testStubWSL(t)
+
+	// Create a transport.
+	transport, err := NewTransport("Ubuntu", "")
+	if err != nil {
+		t.Fatal("unable to create transport:", err)
+	}
+
+	// Test classification of various exit codes.
+	testCases := []struct {
+		exitCode string
+		install  bool
+		fail     bool
+	}{
+		{"126", true, false},
+		{"127", true, false},
+		{"1", false, true},
+	}
+	for _, testCase := range testCases {
+		testSetenv(t, "MUTAGEN_TEST_WSL_EXIT_CODE", testCase.exitCode)
+		command, err := transport.Command(".mutagen/agent")
+		if err != nil {
+			t.Fatal("unable to create command:", err)
+		} else if err = command.Run(); err == nil {
+			t.Fatal("stub command succeeded unexpectedly")
+		}
+		install, cmdExe, err := transport.ClassifyError(command.ProcessState, "")
+		if (err != nil) != testCase.fail {
+			t.Errorf("classification error for exit code %s incorrect: %v", testCase.exitCode, err)
+		} else if install != testCase.install {
+			t.Errorf("install classification for exit code %s incorrect", testCase.exitCode)
+		} else if cmdExe {
+			t.Errorf("exit code %s classified as cmd.exe environment", testCase.exitCode)
+		}
+	}
+}
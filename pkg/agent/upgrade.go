@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// upgradeWindow is the window over which the agent upgrade rate is
+	// measured.
+	upgradeWindow = time.Minute
+)
+
+// ErrUpgradeDeferred indicates that an agent installation was deferred because
+// the agent upgrade rate limit has been reached. Since the connection attempt
+// fails, the installation will be reattempted on the next reconnection, which
+// is governed by the caller's reconnection backoff.
+var ErrUpgradeDeferred = errors.New("agent upgrade deferred due to upgrade rate limit")
+
+// UpgradeLimiter limits the rate at which agents are installed (or upgraded).
+// Because agents are installed in version-specific locations, rolling out a new
+// version of Mutagen causes every remote endpoint to require an installation
+// on its next connection, and this limiter avoids performing all of those
+// installations at once. Installations are also subject to the connection
+// establishment limits provided by the limiting package, since they occur
+// while dialing. A nil UpgradeLimiter imposes no limits. It is safe for
+// concurrent usage.
+type UpgradeLimiter struct {
+	// limit is the maximum number of upgrades admitted within the window.
+	limit int
+	// window is the window over which upgrades are counted.
+	window time.Duration
+	// now returns the current time. It can be overridden in tests.
+	now func() time.Time
+	// lock serializes access to admitted.
+	lock sync.Mutex
+	// admitted are the times at which upgrades in the current window were
+	// admitted, in chronological order.
+	admitted []time.Time
+}
+
+// NewUpgradeLimiter creates a new upgrade limiter that admits at most the
+// specified number of upgrades within any window of the specified duration.
+// The limit must be positive.
+func NewUpgradeLimiter(limit int, window time.Duration) *UpgradeLimiter {
+	if limit <= 0 {
+		panic("non-positive upgrade limit")
+	}
+	return &UpgradeLimiter{
+		limit:  limit,
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// admit attempts to admit an upgrade, returning ErrUpgradeDeferred if the
+// upgrade rate limit has been reached.
+func (l *UpgradeLimiter) admit() error {
+	// A nil limiter admits everything.
+	if l == nil {
+		return nil
+	}
+
+	// Lock the limiter and defer its release.
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	// Discard upgrades that have fallen outside of the window.
+	now := l.now()
+	expired := 0
+	for _, admitted := range l.admitted {
+		if now.Sub(admitted) < l.window {
+			break
+		}
+		expired++
+	}
+	l.admitted = l.admitted[expired:]
+
+	// Check whether or not the limit has been reached.
+	if len(l.admitted) >= l.limit {
+		return ErrUpgradeDeferred
+	}
+
+	// Record the upgrade.
+	l.admitted = append(l.admitted, now)
+	return nil
+}
+
+var (
+	// sharedUpgradeLimiterLock serializes access to sharedUpgradeLimiter.
+	sharedUpgradeLimiterLock sync.RWMutex
+	// sharedUpgradeLimiter is the process-wide upgrade limiter.
+	sharedUpgradeLimiter *UpgradeLimiter
+)
+
+// ConfigureUpgradeRate sets the maximum number of agent upgrades per minute
+// admitted by the process-wide upgrade limiter used by Dial. A rate of 0
+// indicates that upgrades should not be rate limited.
+func ConfigureUpgradeRate(rate int) {
+	// Create the limiter if necessary.
+	var limiter *UpgradeLimiter
+	if rate > 0 {
+		limiter = NewUpgradeLimiter(rate, upgradeWindow)
+	}
+
+	// Store the limiter.
+	sharedUpgradeLimiterLock.Lock()
+	sharedUpgradeLimiter = limiter
+	sharedUpgradeLimiterLock.Unlock()
+}
+
+// currentUpgradeLimiter returns the process-wide upgrade limiter, which will be
+// nil if no upgrade rate has been configured.
+func currentUpgradeLimiter() *UpgradeLimiter {
+	sharedUpgradeLimiterLock.RLock()
+	defer sharedUpgradeLimiterLock.RUnlock()
+	return sharedUpgradeLimiter
+}
+
+// rollbackCommand computes the command used to roll back an agent installation
+// at the specified path (relative to the home directory) on the remote. If the
+// installation replaced an existing agent executable, then that executable is
+// restored, otherwise the installed executable is removed.
+func rollbackCommand(path string, posix bool) string {
+	previous := path + previousExecutableSuffix
+	if posix {
+		return fmt.Sprintf("if [ -f %[1]s ]; then mv -f %[1]s %[2]s; else rm -f %[2]s; fi", previous, path)
+	}
+	return fmt.Sprintf("if exist %[1]s (move /Y %[1]s %[2]s) else (del /F /Q %[2]s)", previous, path)
+}
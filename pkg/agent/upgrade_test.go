@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"testing"
+	"time"
+)
+
+// TestUpgradeLimiterNil tests that a nil upgrade limiter admits all upgrades.
+func TestUpgradeLimiterNil(t *testing.T) {
+	var limiter *UpgradeLimiter
+	for i := 0; i < 10; i++ {
+		if err := limiter.admit(); err != nil {
+			t.Fatal("nil limiter deferred upgrade:", err)
+		}
+	}
+}
+
+// TestUpgradeLimiterWindow tests that an upgrade limiter admits upgrades based
+// on a sliding window.
+func TestUpgradeLimiterWindow(t *testing.T) {
+	// Create a limiter with a controllable clock.
+	now := time.Now()
+	limiter := NewUpgradeLimiter(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	// Admit upgrades at staggered times until the limit is reached.
+	if err := limiter.admit(); err != nil {
+		t.Fatal("first upgrade deferred:", err)
+	}
+	now = now.Add(20 * time.Second)
+	if err := limiter.admit(); err != nil {
+		t.Fatal("second upgrade deferred:", err)
+	}
+	if err := limiter.admit(); err != ErrUpgradeDeferred {
+		t.Fatal("upgrade beyond limit not deferred:", err)
+	}
+
+	// Verify that a slot becomes available once the first upgrade leaves the
+	// window, but that the second still counts.
+	now = now.Add(40 * time.Second)
+	if err := limiter.admit(); err != nil {
+		t.Fatal("upgrade deferred after window elapsed:", err)
+	}
+	if err := limiter.admit(); err != ErrUpgradeDeferred {
+		t.Fatal("upgrade beyond limit not deferred:", err)
+	}
+}
+
+// TestConfigureUpgradeRate tests ConfigureUpgradeRate.
+func TestConfigureUpgradeRate(t *testing.T) {
+	// Defer restoration of the default limiter.
+	defer ConfigureUpgradeRate(0)
+
+	// Configure a rate and verify that it's applied.
+	ConfigureUpgradeRate(3)
+	if limiter := currentUpgradeLimiter(); limiter == nil {
+		t.Fatal("limiter not created with rate specified")
+	} else if limiter.limit != 3 || limiter.window != upgradeWindow {
+		t.Error("limiter created with incorrect parameters:", limiter.limit, limiter.window)
+	}
+
+	// Clear the rate and verify that upgrades aren't limited.
+	ConfigureUpgradeRate(0)
+	if currentUpgradeLimiter() != nil {
+		t.Error("limiter created without rate specified")
+	}
+}
+
+// TestRollbackCommand tests rollbackCommand.
+func TestRollbackCommand(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		path     string
+		posix    bool
+		expected string
+	}{
+		{
+			".mutagen/agents/0.12.0/mutagen-agent", true,
+			"if [ -f .mutagen/agents/0.12.0/mutagen-agent.previous ]; then " +
+				"mv -f .mutagen/agents/0.12.0/mutagen-agent.previous .mutagen/agents/0.12.0/mutagen-agent; " +
+				"else rm -f .mutagen/agents/0.12.0/mutagen-agent; fi",
+		},
+		{
+			`.mutagen\agents\0.12.0\mutagen-agent.exe`, false,
+			`if exist .mutagen\agents\0.12.0\mutagen-agent.exe.previous ` +
+				`(move /Y .mutagen\agents\0.12.0\mutagen-agent.exe.previous .mutagen\agents\0.12.0\mutagen-agent.exe) ` +
+				`else (del /F /Q .mutagen\agents\0.12.0\mutagen-agent.exe)`,
+		},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if command := rollbackCommand(testCase.path, testCase.posix); command != testCase.expected {
+			t.Errorf("rollback command (%s) does not match expected (%s)", command, testCase.expected)
+		}
+	}
+}
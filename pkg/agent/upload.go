@@ -0,0 +1,309 @@
+package agent
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// uploadChunkSize is the chunk size used for resumable uploads. It's large
+	// enough to amortize the cost of per-chunk acknowledgments across
+	// high-latency connections, but small enough that an interrupted upload
+	// doesn't lose much progress.
+	uploadChunkSize = 256 * 1024
+	// uploadReceiverCommand is the command used to start the receiving end of a
+	// resumable upload. The receiver script itself is sent over the command's
+	// standard input, followed by the framed upload stream.
+	uploadReceiverCommand = "sh -s"
+	// uploadReceiverTemplate is the template for the POSIX shell script that
+	// implements the receiving end of a resumable upload. It should be
+	// formatted with the quoted partial file name and the quoted destination
+	// name. Because the shell reads the script from the same stream that
+	// carries the framed upload, the script is wrapped in a single compound
+	// command. This forces the shell to read the entire script before it
+	// executes any part of it, so it can't mistakenly consume any of the
+	// framed upload as script text. For the same reason, the script doesn't
+	// include a trailing newline, since that's added during transmission.
+	//
+	// The framing protocol is line-based. The receiver starts by reporting
+	// either "unsupported" (if it lacks the tools necessary to decode chunks or
+	// verify digests) or "ready <size>", where size is the size of any partial
+	// file left by a previous upload attempt. The sender then sends
+	// "resume <offset>", where offset is the (chunk-aligned) size of partial
+	// content that it's willing to reuse, and the receiver truncates the
+	// partial file accordingly and responds with "ok <size>". The sender then
+	// sends each remaining chunk as "chunk <base64-data>", with each chunk
+	// acknowledged by "ack <size>". Finally, the sender sends
+	// "commit <sha256-digest>" and the receiver verifies the digest of the
+	// assembled file, responding with "committed" if it matches (in which case
+	// the partial file is moved into place) or "mismatch" if it doesn't (in
+	// which case the partial file is removed).
+	uploadReceiverTemplate = `{
+	umask 077
+	partial=%s
+	destination=%s
+	if ! command -v base64 >/dev/null 2>&1; then
+		echo unsupported
+		exit 0
+	elif printf 'eA==\n' | base64 -d >/dev/null 2>&1; then
+		decode() { base64 -d; }
+	elif printf 'eA==\n' | base64 -D >/dev/null 2>&1; then
+		decode() { base64 -D; }
+	else
+		echo unsupported
+		exit 0
+	fi
+	if command -v sha256sum >/dev/null 2>&1; then
+		digest() { sha256sum "$1" | cut -d ' ' -f 1; }
+	elif command -v shasum >/dev/null 2>&1; then
+		digest() { shasum -a 256 "$1" | cut -d ' ' -f 1; }
+	else
+		echo unsupported
+		exit 0
+	fi
+	size() { if [ -f "$partial" ]; then wc -c < "$partial" | tr -d ' '; else echo 0; fi; }
+	echo "ready $(size)"
+	while IFS= read -r line; do
+		case "$line" in
+		"resume "*)
+			offset="${line#resume }"
+			if [ "$offset" -eq 0 ]; then
+				: > "$partial" || exit 1
+			else
+				dd if=/dev/null of="$partial" bs=1 seek="$offset" count=0 2>/dev/null || exit 1
+			fi
+			echo "ok $(size)"
+			;;
+		"chunk "*)
+			printf '%%s\n' "${line#chunk }" | decode >> "$partial" || exit 1
+			echo "ack $(size)"
+			;;
+		"commit "*)
+			if [ "$(digest "$partial")" = "${line#commit }" ]; then
+				chmod 700 "$partial" && mv -f "$partial" "$destination" || exit 1
+				echo committed
+			else
+				rm -f "$partial"
+				echo mismatch
+			fi
+			exit 0
+			;;
+		*)
+			exit 1
+			;;
+		esac
+	done
+	exit 1
+}`
+)
+
+// ErrResumableUploadUnsupported indicates that a remote doesn't support
+// resumable uploads, e.g. because it lacks a POSIX shell or the tools
+// necessary to decode chunks and verify digests.
+var ErrResumableUploadUnsupported = errors.New("resumable upload unsupported by remote")
+
+// shellQuote quotes a value for use as a single word in a POSIX shell script.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// uploadReceiverScript generates the receiver script for a resumable upload
+// using the specified partial file name and destination name.
+func uploadReceiverScript(partial, destination string) string {
+	return fmt.Sprintf(uploadReceiverTemplate, shellQuote(partial), shellQuote(destination))
+}
+
+// uploadPartialName computes the name of the partial file used to accumulate
+// content with the specified digest. The name is derived from the digest so
+// that subsequent upload attempts for the same content can identify and resume
+// from partial files left by interrupted attempts.
+func uploadPartialName(digest []byte) string {
+	return fmt.Sprintf(".%s-%x.partial", BaseName, digest[:8])
+}
+
+// uploadStream is the sending end of the resumable upload framing protocol.
+type uploadStream struct {
+	// writer is the buffered stream to the receiver.
+	writer *bufio.Writer
+	// reader is the buffered stream from the receiver.
+	reader *bufio.Reader
+}
+
+// send transmits a single protocol line to the receiver.
+func (s *uploadStream) send(line string) error {
+	if _, err := s.writer.WriteString(line); err != nil {
+		return err
+	} else if err = s.writer.WriteByte('\n'); err != nil {
+		return err
+	}
+	return s.writer.Flush()
+}
+
+// receive reads a single protocol line from the receiver.
+func (s *uploadStream) receive() (string, error) {
+	line, err := s.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+// receiveSize reads a protocol line from the receiver that's expected to
+// consist of the specified keyword and a size, returning the size.
+func (s *uploadStream) receiveSize(keyword string) (int64, error) {
+	line, err := s.receive()
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to receive response")
+	} else if !strings.HasPrefix(line, keyword+" ") {
+		return 0, errors.Errorf("unexpected response: %q", line)
+	}
+	size, err := strconv.ParseInt(strings.TrimPrefix(line, keyword+" "), 10, 64)
+	if err != nil || size < 0 {
+		return 0, errors.Errorf("invalid size in response: %q", line)
+	}
+	return size, nil
+}
+
+// UploadResumable copies the specified local file to the remote using a
+// chunked, resumable upload performed over a command stream. The arguments
+// have the same semantics as those of Transport.Copy. Content is accumulated
+// in a partial file on the remote, with each chunk acknowledged by the remote
+// before the next is sent, so an interrupted upload will resume from the last
+// acknowledged chunk when retried. The assembled file is only moved to its
+// destination once its digest has been verified. If the remote doesn't support
+// resumable uploads, then ErrResumableUploadUnsupported is returned and the
+// caller should fall back to another copying mechanism.
+func UploadResumable(transport Transport, localPath, remoteName string) error {
+	_, err := uploadResumable(transport, localPath, remoteName, uploadChunkSize)
+	return err
+}
+
+// uploadResumable implements UploadResumable with a configurable chunk size.
+// It returns the number of chunks that were transmitted.
+func uploadResumable(transport Transport, localPath, remoteName string, chunkSize int) (int, error) {
+	// Open the local file and defer its closure.
+	file, err := os.Open(localPath)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to open file")
+	}
+	defer file.Close()
+
+	// Compute the file's size and digest.
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to compute file digest")
+	}
+	digest := hasher.Sum(nil)
+
+	// Create the receiver process and its streams.
+	process, err := transport.Command(uploadReceiverCommand)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to create receiver command")
+	}
+	stdin, err := process.StdinPipe()
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to redirect receiver input")
+	}
+	stdout, err := process.StdoutPipe()
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to redirect receiver output")
+	}
+	stream := &uploadStream{
+		writer: bufio.NewWriter(stdin),
+		reader: bufio.NewReader(stdout),
+	}
+
+	// Start the receiver process and ensure that it's terminated and reaped
+	// when we're done. On success, the receiver will have already completed
+	// its work by the time we terminate it, so termination is harmless.
+	if err := process.Start(); err != nil {
+		return 0, errors.Wrap(err, "unable to start receiver")
+	}
+	defer func() {
+		stdin.Close()
+		process.Process.Kill()
+		process.Wait()
+	}()
+
+	// Send the receiver script.
+	partial := uploadPartialName(digest)
+	if err := stream.send(uploadReceiverScript(partial, remoteName)); err != nil {
+		return 0, ErrResumableUploadUnsupported
+	}
+
+	// Wait for the receiver to report its readiness and the size of any
+	// existing partial content. Any failure at this stage (e.g. due to the
+	// remote lacking a POSIX shell) is treated as a lack of support.
+	readiness, err := stream.receive()
+	if err != nil || !strings.HasPrefix(readiness, "ready ") {
+		return 0, ErrResumableUploadUnsupported
+	}
+	existing, err := strconv.ParseInt(strings.TrimPrefix(readiness, "ready "), 10, 64)
+	if err != nil || existing < 0 {
+		return 0, errors.Errorf("invalid receiver readiness: %q", readiness)
+	}
+
+	// Compute the offset from which to resume. We only reuse whole chunks
+	// since a chunk may have been partially written if the previous attempt
+	// was interrupted.
+	if existing > size {
+		existing = size
+	}
+	offset := existing - (existing % int64(chunkSize))
+	if err := stream.send(fmt.Sprintf("resume %d", offset)); err != nil {
+		return 0, errors.Wrap(err, "unable to send resume offset")
+	} else if confirmed, err := stream.receiveSize("ok"); err != nil {
+		return 0, errors.Wrap(err, "unable to resume upload")
+	} else if confirmed != offset {
+		return 0, errors.Errorf("receiver resumed at incorrect offset (%d != %d)", confirmed, offset)
+	}
+
+	// Transmit the remaining chunks, waiting for each to be acknowledged.
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, errors.Wrap(err, "unable to seek to resume offset")
+	}
+	buffer := make([]byte, chunkSize)
+	var transmitted int
+	for offset < size {
+		count, err := io.ReadFull(file, buffer)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return transmitted, errors.Wrap(err, "unable to read chunk")
+		}
+		if err := stream.send("chunk " + base64.StdEncoding.EncodeToString(buffer[:count])); err != nil {
+			return transmitted, errors.Wrap(err, "unable to send chunk")
+		}
+		transmitted++
+		offset += int64(count)
+		if acknowledged, err := stream.receiveSize("ack"); err != nil {
+			return transmitted, errors.Wrap(err, "chunk not acknowledged")
+		} else if acknowledged != offset {
+			return transmitted, errors.Errorf("receiver acknowledged incorrect size (%d != %d)", acknowledged, offset)
+		}
+	}
+
+	// Request that the receiver verify the assembled content and move it into
+	// place.
+	if err := stream.send("commit " + hex.EncodeToString(digest)); err != nil {
+		return transmitted, errors.Wrap(err, "unable to send commit request")
+	}
+	if result, err := stream.receive(); err != nil {
+		return transmitted, errors.Wrap(err, "unable to receive commit result")
+	} else if result == "mismatch" {
+		return transmitted, errors.New("assembled file digest does not match")
+	} else if result != "committed" {
+		return transmitted, errors.Errorf("unexpected commit result: %q", result)
+	}
+
+	// Success.
+	return transmitted, nil
+}
@@ -0,0 +1,319 @@
+package agent
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// testUploadChunkSize is the chunk size to use for upload tests.
+	testUploadChunkSize = 1024
+	// testUploadChunkCount is the number of chunks (the last of which is
+	// partial) in test upload content.
+	testUploadChunkCount = 11
+	// testUploadRemoteName is the remote name to use for upload tests.
+	testUploadRemoteName = ".mutagen-agent-test"
+)
+
+// testUploadTransport is an agent.Transport implementation that executes
+// commands locally in a specified directory, optionally truncating the input to
+// those commands in order to simulate an interrupted connection. Truncation is
+// performed using a single-byte dd invocation since it forwards input without
+// any buffering.
+type testUploadTransport struct {
+	// directory is the directory in which to execute commands.
+	directory string
+	// inputLimit is the maximum number of bytes of input that commands will
+	// receive. A value of 0 indicates no limit.
+	inputLimit int
+}
+
+// Copy implements agent.Transport.Copy.
+func (t *testUploadTransport) Copy(_, _ string) error {
+	return errors.New("copying not supported")
+}
+
+// Command implements agent.Transport.Command.
+func (t *testUploadTransport) Command(command string) (*exec.Cmd, error) {
+	if t.inputLimit > 0 {
+		command = fmt.Sprintf("dd bs=1 count=%d 2>/dev/null | %s", t.inputLimit, command)
+	}
+	process := exec.Command("sh", "-c", command)
+	process.Dir = t.directory
+	return process, nil
+}
+
+// ClassifyError implements agent.Transport.ClassifyError.
+func (t *testUploadTransport) ClassifyError(_ *os.ProcessState, _ string) (bool, bool, error) {
+	return false, false, errors.New("error classification not supported")
+}
+
+// createTestUploadContent creates random upload content in the specified
+// directory and returns its path and contents.
+func createTestUploadContent(t *testing.T, directory string) (string, []byte) {
+	// Generate the content.
+	content := make([]byte, (testUploadChunkCount-1)*testUploadChunkSize+testUploadChunkSize/2)
+	if _, err := rand.Read(content); err != nil {
+		t.Fatal("unable to generate content:", err)
+	}
+
+	// Write the content.
+	path := filepath.Join(directory, "agent")
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		t.Fatal("unable to write content:", err)
+	}
+
+	// Done.
+	return path, content
+}
+
+// testUploadPartialPath computes the path of the partial file for the specified
+// content in the specified directory.
+func testUploadPartialPath(directory string, content []byte) string {
+	digest := sha256.Sum256(content)
+	return filepath.Join(directory, uploadPartialName(digest[:]))
+}
+
+// verifyTestUpload verifies that an upload has been completed successfully.
+func verifyTestUpload(t *testing.T, directory string, content []byte) {
+	if uploaded, err := ioutil.ReadFile(filepath.Join(directory, testUploadRemoteName)); err != nil {
+		t.Fatal("unable to read uploaded content:", err)
+	} else if !bytes.Equal(uploaded, content) {
+		t.Error("uploaded content does not match original")
+	}
+	if _, err := os.Lstat(testUploadPartialPath(directory, content)); !os.IsNotExist(err) {
+		t.Error("partial file not removed after upload")
+	}
+}
+
+// TestUploadResumable tests a complete, uninterrupted resumable upload.
+func TestUploadResumable(t *testing.T) {
+	// The receiver is a POSIX shell script.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Create the source content and remote directories.
+	source, err := ioutil.TempDir("", "mutagen_upload_source")
+	if err != nil {
+		t.Fatal("unable to create source directory:", err)
+	}
+	defer os.RemoveAll(source)
+	remote, err := ioutil.TempDir("", "mutagen_upload_remote")
+	if err != nil {
+		t.Fatal("unable to create remote directory:", err)
+	}
+	defer os.RemoveAll(remote)
+	path, content := createTestUploadContent(t, source)
+
+	// Perform the upload and verify that all chunks were transmitted.
+	transport := &testUploadTransport{directory: remote}
+	transmitted, err := uploadResumable(transport, path, testUploadRemoteName, testUploadChunkSize)
+	if err != nil {
+		t.Fatal("upload failed:", err)
+	} else if transmitted != testUploadChunkCount {
+		t.Error("transmitted chunk count incorrect:", transmitted, "!=", testUploadChunkCount)
+	}
+
+	// Verify the result.
+	verifyTestUpload(t, remote, content)
+}
+
+// TestUploadResumableInterrupted tests that an upload interrupted mid-stream
+// resumes from the last acknowledged chunk and transmits only the remaining
+// chunks.
+func TestUploadResumableInterrupted(t *testing.T) {
+	// The receiver is a POSIX shell script.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Create the source content and remote directories.
+	source, err := ioutil.TempDir("", "mutagen_upload_source")
+	if err != nil {
+		t.Fatal("unable to create source directory:", err)
+	}
+	defer os.RemoveAll(source)
+	remote, err := ioutil.TempDir("", "mutagen_upload_remote")
+	if err != nil {
+		t.Fatal("unable to create remote directory:", err)
+	}
+	defer os.RemoveAll(remote)
+	path, content := createTestUploadContent(t, source)
+
+	// Compute an input limit that will interrupt the upload partway through
+	// the transmission of the fifth chunk.
+	digest := sha256.Sum256(content)
+	script := uploadReceiverScript(uploadPartialName(digest[:]), testUploadRemoteName)
+	chunkLineLength := len("chunk ") + base64.StdEncoding.EncodedLen(testUploadChunkSize) + 1
+	inputLimit := len(script) + 1 + len("resume 0\n") + 4*chunkLineLength + chunkLineLength/2
+
+	// Perform an interrupted upload and verify that it fails.
+	transport := &testUploadTransport{directory: remote, inputLimit: inputLimit}
+	if _, err := uploadResumable(transport, path, testUploadRemoteName, testUploadChunkSize); err == nil {
+		t.Fatal("interrupted upload unexpectedly succeeded")
+	}
+
+	// Verify that only the acknowledged chunks were committed to the partial
+	// file and that the destination wasn't created.
+	partial, err := os.Lstat(testUploadPartialPath(remote, content))
+	if err != nil {
+		t.Fatal("unable to query partial file:", err)
+	} else if partial.Size() != 4*testUploadChunkSize {
+		t.Fatal("partial file size incorrect:", partial.Size(), "!=", 4*testUploadChunkSize)
+	}
+	if _, err := os.Lstat(filepath.Join(remote, testUploadRemoteName)); !os.IsNotExist(err) {
+		t.Fatal("destination created by interrupted upload")
+	}
+
+	// Resume the upload and verify that only the remaining chunks were
+	// transmitted.
+	transport.inputLimit = 0
+	transmitted, err := uploadResumable(transport, path, testUploadRemoteName, testUploadChunkSize)
+	if err != nil {
+		t.Fatal("resumed upload failed:", err)
+	} else if expected := testUploadChunkCount - 4; transmitted != expected {
+		t.Error("resumed upload transmitted incorrect chunk count:", transmitted, "!=", expected)
+	}
+
+	// Verify the result.
+	verifyTestUpload(t, remote, content)
+}
+
+// TestUploadResumableUnalignedPartial tests that a resumed upload discards any
+// partially written chunk at the end of a partial file.
+func TestUploadResumableUnalignedPartial(t *testing.T) {
+	// The receiver is a POSIX shell script.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Create the source content and remote directories.
+	source, err := ioutil.TempDir("", "mutagen_upload_source")
+	if err != nil {
+		t.Fatal("unable to create source directory:", err)
+	}
+	defer os.RemoveAll(source)
+	remote, err := ioutil.TempDir("", "mutagen_upload_remote")
+	if err != nil {
+		t.Fatal("unable to create remote directory:", err)
+	}
+	defer os.RemoveAll(remote)
+	path, content := createTestUploadContent(t, source)
+
+	// Simulate a partial file containing two complete chunks and a chunk that
+	// was only partially written.
+	partialLength := 2*testUploadChunkSize + testUploadChunkSize/3
+	if err := ioutil.WriteFile(testUploadPartialPath(remote, content), content[:partialLength], 0600); err != nil {
+		t.Fatal("unable to create partial file:", err)
+	}
+
+	// Perform the upload and verify that the partially written chunk was
+	// retransmitted.
+	transport := &testUploadTransport{directory: remote}
+	transmitted, err := uploadResumable(transport, path, testUploadRemoteName, testUploadChunkSize)
+	if err != nil {
+		t.Fatal("upload failed:", err)
+	} else if expected := testUploadChunkCount - 2; transmitted != expected {
+		t.Error("transmitted chunk count incorrect:", transmitted, "!=", expected)
+	}
+
+	// Verify the result.
+	verifyTestUpload(t, remote, content)
+}
+
+// TestUploadResumableCorruptedPartial tests that digest verification prevents
+// corrupted partial content from being moved into place.
+func TestUploadResumableCorruptedPartial(t *testing.T) {
+	// The receiver is a POSIX shell script.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Create the source content and remote directories.
+	source, err := ioutil.TempDir("", "mutagen_upload_source")
+	if err != nil {
+		t.Fatal("unable to create source directory:", err)
+	}
+	defer os.RemoveAll(source)
+	remote, err := ioutil.TempDir("", "mutagen_upload_remote")
+	if err != nil {
+		t.Fatal("unable to create remote directory:", err)
+	}
+	defer os.RemoveAll(remote)
+	path, content := createTestUploadContent(t, source)
+
+	// Simulate a partial file containing three chunks, one of which is
+	// corrupted.
+	corrupted := make([]byte, 3*testUploadChunkSize)
+	copy(corrupted, content)
+	corrupted[testUploadChunkSize] ^= 0xff
+	partialPath := testUploadPartialPath(remote, content)
+	if err := ioutil.WriteFile(partialPath, corrupted, 0600); err != nil {
+		t.Fatal("unable to create partial file:", err)
+	}
+
+	// Perform the upload and verify that it fails without creating the
+	// destination and that the partial file is discarded.
+	transport := &testUploadTransport{directory: remote}
+	if _, err := uploadResumable(transport, path, testUploadRemoteName, testUploadChunkSize); err == nil {
+		t.Fatal("upload with corrupted partial content unexpectedly succeeded")
+	}
+	if _, err := os.Lstat(filepath.Join(remote, testUploadRemoteName)); !os.IsNotExist(err) {
+		t.Fatal("destination created despite digest mismatch")
+	}
+	if _, err := os.Lstat(partialPath); !os.IsNotExist(err) {
+		t.Fatal("corrupted partial file not removed")
+	}
+
+	// Retry the upload and verify that it transmits all chunks.
+	transmitted, err := uploadResumable(transport, path, testUploadRemoteName, testUploadChunkSize)
+	if err != nil {
+		t.Fatal("retried upload failed:", err)
+	} else if transmitted != testUploadChunkCount {
+		t.Error("transmitted chunk count incorrect:", transmitted, "!=", testUploadChunkCount)
+	}
+
+	// Verify the result.
+	verifyTestUpload(t, remote, content)
+}
+
+// TestUploadResumableUnsupported tests that a remote without a functioning
+// receiver is reported as unsupported.
+func TestUploadResumableUnsupported(t *testing.T) {
+	// The test transport relies on a POSIX shell.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Create the source content and remote directories.
+	source, err := ioutil.TempDir("", "mutagen_upload_source")
+	if err != nil {
+		t.Fatal("unable to create source directory:", err)
+	}
+	defer os.RemoveAll(source)
+	remote, err := ioutil.TempDir("", "mutagen_upload_remote")
+	if err != nil {
+		t.Fatal("unable to create remote directory:", err)
+	}
+	defer os.RemoveAll(remote)
+	path, _ := createTestUploadContent(t, source)
+
+	// Perform an upload with a transport that truncates all input (and thus
+	// the receiver script) and verify that it's reported as unsupported.
+	transport := &testUploadTransport{directory: remote, inputLimit: 1}
+	if _, err := uploadResumable(transport, path, testUploadRemoteName, testUploadChunkSize); err != ErrResumableUploadUnsupported {
+		t.Error("unexpected upload result:", err)
+	}
+}
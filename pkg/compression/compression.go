@@ -1,8 +1,14 @@
 package compression
 
 import (
+	"bufio"
+	"bytes"
 	"compress/flate"
+	"encoding/binary"
 	"io"
+	"io/ioutil"
+	"math"
+	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -13,42 +19,238 @@ const (
 	defaultCompressionLevel = 6
 )
 
+const (
+	// frameKindRaw indicates a frame whose payload is uncompressed.
+	frameKindRaw byte = iota
+	// frameKindCompressed indicates a frame whose payload is an independent
+	// DEFLATE stream.
+	frameKindCompressed
+)
+
+// Reader is a decompressor for streams produced by Writer.
+type Reader struct {
+	// source is the underlying reader.
+	source *bufio.Reader
+	// active indicates whether or not a frame is currently being read.
+	active bool
+	// compressed indicates whether or not the current frame is compressed.
+	compressed bool
+	// remaining is the number of payload bytes remaining in the current frame
+	// if it's uncompressed.
+	remaining uint64
+	// payload is the source of the current frame's payload if it's compressed.
+	payload *io.LimitedReader
+	// decompressor is the decompressor for compressed frames. It is lazily
+	// initialized and reset for each compressed frame.
+	decompressor io.ReadCloser
+}
+
 // NewDecompressingReader wraps an io.Reader in a decompressor.
 func NewDecompressingReader(source io.Reader) io.Reader {
-	// HACK: Technically this function returns an io.ReadCloser, and it
-	// documents that it is the caller's function to call Close on the reader.
-	// However, it turns out that the underlying implementation of Close just
-	// checks for stream errors and that it isn't really necessary to call it.
-	// As a result, we ignore this portion of the interface, but we're sort of
-	// relying on an implementation detail in doing so.
-	return flate.NewReader(source)
-}
-
-// automaticallyFlushingFlateWriter is a type that wraps a flate.Writer and
-// automatically flushes data on every write.
-type automaticallyFlushingFlateWriter struct {
-	// compressor is the underlying flate compressor.
-	compressor *flate.Writer
+	return &Reader{
+		source:  bufio.NewReader(source),
+		payload: &io.LimitedReader{},
+	}
 }
 
-// Write writes data to the compressor and automatically flushes it to the
-// underlying writer.
-func (w *automaticallyFlushingFlateWriter) Write(buffer []byte) (int, error) {
-	count, err := w.compressor.Write(buffer)
+// readFrameHeader reads the header of the next frame and prepares for reading
+// its payload.
+func (r *Reader) readFrameHeader() error {
+	// Read the frame kind.
+	kind, err := r.source.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	// Read the payload length.
+	length, err := binary.ReadUvarint(r.source)
 	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return errors.Wrap(err, "unable to read frame length")
+	} else if length > math.MaxInt64 {
+		return errors.New("frame length too large")
+	}
+
+	// Prepare to read the payload.
+	switch kind {
+	case frameKindRaw:
+		r.compressed = false
+		r.remaining = length
+	case frameKindCompressed:
+		r.compressed = true
+		r.payload.R = r.source
+		r.payload.N = int64(length)
+		if r.decompressor == nil {
+			r.decompressor = flate.NewReader(r.payload)
+		} else if err := r.decompressor.(flate.Resetter).Reset(r.payload, nil); err != nil {
+			return errors.Wrap(err, "unable to reset decompressor")
+		}
+	default:
+		return errors.New("unknown frame kind")
+	}
+	r.active = true
+
+	// Success.
+	return nil
+}
+
+// Read implements io.Reader.Read.
+func (r *Reader) Read(buffer []byte) (int, error) {
+	// Handle empty reads.
+	if len(buffer) == 0 {
+		return 0, nil
+	}
+
+	// Loop until we're able to read data from a frame.
+	for {
+		// If there's no frame being read, then read the next frame header.
+		if !r.active {
+			if err := r.readFrameHeader(); err != nil {
+				return 0, err
+			}
+		}
+
+		// Handle uncompressed frames.
+		if !r.compressed {
+			if r.remaining == 0 {
+				r.active = false
+				continue
+			}
+			if uint64(len(buffer)) > r.remaining {
+				buffer = buffer[:r.remaining]
+			}
+			count, err := r.source.Read(buffer)
+			r.remaining -= uint64(count)
+			if r.remaining == 0 {
+				r.active = false
+			}
+			if err == io.EOF && r.remaining > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return count, err
+		}
+
+		// Handle compressed frames. Once the frame's DEFLATE stream terminates,
+		// discard any unused trailing payload data.
+		count, err := r.decompressor.Read(buffer)
+		if err == io.EOF {
+			if _, err := io.Copy(ioutil.Discard, r.payload); err != nil {
+				return count, errors.Wrap(err, "unable to discard trailing frame data")
+			}
+			r.active = false
+			if count == 0 {
+				continue
+			}
+			return count, nil
+		}
 		return count, err
-	} else if err = w.compressor.Flush(); err != nil {
-		return 0, errors.Wrap(err, "unable to flush compressor")
 	}
-	return count, nil
 }
 
-// NewCompressingWriter wraps an io.Writer in a compressor.
-func NewCompressingWriter(destination io.Writer) io.Writer {
+// Writer is a compressor that compresses each write independently and that can
+// selectively bypass compression. Writes are sent uncompressed if they're
+// smaller than a minimum size, if bypass is enabled, or if compression fails to
+// reduce their size. It is not safe for concurrent usage.
+type Writer struct {
+	// destination is the underlying writer.
+	destination io.Writer
+	// compressor is the underlying flate compressor.
+	compressor *flate.Writer
+	// compressed is the buffer used to store compressed payloads.
+	compressed bytes.Buffer
+	// header is the buffer used to encode frame headers.
+	header [1 + binary.MaxVarintLen64]byte
+	// frame is the buffer used to assemble frames.
+	frame []byte
+	// minimumSize is the minimum write size for which compression will be
+	// attempted.
+	minimumSize uint64
+	// bypass indicates whether or not compression is currently bypassed.
+	bypass bool
+}
+
+// NewCompressingWriter wraps an io.Writer in a compressor. By default, all
+// writes are eligible for compression.
+func NewCompressingWriter(destination io.Writer) *Writer {
 	// Create the compressor. If a sane compression level is provided, the flate
 	// API guarantees that creation of the compressor will succeed.
-	compressor, _ := flate.NewWriter(destination, defaultCompressionLevel)
+	compressor, _ := flate.NewWriter(ioutil.Discard, defaultCompressionLevel)
+
+	// Create the writer.
+	return &Writer{
+		destination: destination,
+		compressor:  compressor,
+	}
+}
 
-	// Wrap the compressor.
-	return &automaticallyFlushingFlateWriter{compressor}
+// SetMinimumSize sets the minimum write size for which compression will be
+// attempted. Smaller writes are sent uncompressed. A value of 0 indicates that
+// all writes are eligible for compression.
+func (w *Writer) SetMinimumSize(size uint64) {
+	w.minimumSize = size
+}
+
+// SetBypass sets whether or not compression should be bypassed for subsequent
+// writes, regardless of their size.
+func (w *Writer) SetBypass(bypass bool) {
+	w.bypass = bypass
+}
+
+// compress attempts to compress the specified data into the compressed payload
+// buffer. It returns false if compression doesn't reduce the size of the data.
+func (w *Writer) compress(data []byte) (bool, error) {
+	w.compressed.Reset()
+	w.compressor.Reset(&w.compressed)
+	if _, err := w.compressor.Write(data); err != nil {
+		return false, errors.Wrap(err, "unable to compress data")
+	} else if err = w.compressor.Close(); err != nil {
+		return false, errors.Wrap(err, "unable to finalize compression")
+	}
+	return w.compressed.Len() < len(data), nil
+}
+
+// Write implements io.Writer.Write. Each write is sent as a single frame.
+func (w *Writer) Write(data []byte) (int, error) {
+	// Determine the frame kind and payload.
+	kind, payload := frameKindRaw, data
+	if !w.bypass && uint64(len(data)) >= w.minimumSize {
+		if reduced, err := w.compress(data); err != nil {
+			return 0, err
+		} else if reduced {
+			kind, payload = frameKindCompressed, w.compressed.Bytes()
+		}
+	}
+
+	// Assemble the frame.
+	w.header[0] = kind
+	headerLength := 1 + binary.PutUvarint(w.header[1:], uint64(len(payload)))
+	w.frame = append(w.frame[:0], w.header[:headerLength]...)
+	w.frame = append(w.frame, payload...)
+
+	// Write the frame.
+	if _, err := w.destination.Write(w.frame); err != nil {
+		return 0, err
+	}
+
+	// Success.
+	return len(data), nil
+}
+
+// Incompressible determines whether or not the specified path has one of the
+// specified (known-incompressible) extensions. Extensions are matched case
+// insensitively and may be specified with or without a leading dot.
+func Incompressible(path string, extensions []string) bool {
+	for _, extension := range extensions {
+		if extension == "" {
+			continue
+		} else if extension[0] != '.' {
+			extension = "." + extension
+		}
+		if len(path) >= len(extension) && strings.EqualFold(path[len(path)-len(extension):], extension) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,187 @@
+package compression
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+)
+
+// testFrameKinds parses a stream produced by Writer and returns the kind of
+// each frame.
+func testFrameKinds(t *testing.T, stream []byte) []byte {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Parse frames.
+	var kinds []byte
+	reader := bytes.NewReader(stream)
+	for reader.Len() > 0 {
+		kind, _ := reader.ReadByte()
+		length, err := binary.ReadUvarint(reader)
+		if err != nil {
+			t.Fatal("unable to read frame length:", err)
+		} else if length > uint64(reader.Len()) {
+			t.Fatal("frame length exceeds stream length")
+		}
+		reader.Seek(int64(length), 1)
+		kinds = append(kinds, kind)
+	}
+
+	// Done.
+	return kinds
+}
+
+// testWrite performs a single write to a Writer with the specified parameters
+// and returns the frame kind used to transmit it, verifying that the data
+// round-trips through a Reader.
+func testWrite(t *testing.T, data []byte, minimumSize uint64, bypass bool) byte {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Perform the write.
+	stream := &bytes.Buffer{}
+	writer := NewCompressingWriter(stream)
+	writer.SetMinimumSize(minimumSize)
+	writer.SetBypass(bypass)
+	if count, err := writer.Write(data); err != nil {
+		t.Fatal("unable to write data:", err)
+	} else if count != len(data) {
+		t.Fatal("write count does not match data length:", count, "!=", len(data))
+	}
+
+	// Extract the frame kind.
+	kinds := testFrameKinds(t, stream.Bytes())
+	if len(kinds) != 1 {
+		t.Fatal("write did not produce a single frame:", len(kinds))
+	}
+
+	// Verify that the data round-trips.
+	if decompressed, err := ioutil.ReadAll(NewDecompressingReader(stream)); err != nil {
+		t.Fatal("unable to read data:", err)
+	} else if !bytes.Equal(decompressed, data) {
+		t.Fatal("decompressed data does not match original")
+	}
+
+	// Done.
+	return kinds[0]
+}
+
+// testIncompressibleData generates random (and thus incompressible) data of the
+// specified length.
+func testIncompressibleData(length int) []byte {
+	data := make([]byte, length)
+	rand.New(rand.NewSource(0)).Read(data)
+	return data
+}
+
+func TestWriterSmallPayloadBypassesCompression(t *testing.T) {
+	if kind := testWrite(t, bytes.Repeat([]byte("a"), 100), 512, false); kind != frameKindRaw {
+		t.Error("payload below minimum size was compressed")
+	}
+}
+
+func TestWriterLargePayloadCompressed(t *testing.T) {
+	if kind := testWrite(t, bytes.Repeat([]byte("compressible"), 1024), 512, false); kind != frameKindCompressed {
+		t.Error("compressible payload above minimum size was not compressed")
+	}
+}
+
+func TestWriterIncompressiblePayloadBypassesCompression(t *testing.T) {
+	if kind := testWrite(t, testIncompressibleData(16*1024), 512, false); kind != frameKindRaw {
+		t.Error("incompressible payload was sent compressed")
+	}
+}
+
+func TestWriterBypass(t *testing.T) {
+	if kind := testWrite(t, bytes.Repeat([]byte("compressible"), 1024), 512, true); kind != frameKindRaw {
+		t.Error("payload was compressed with compression bypassed")
+	}
+}
+
+func TestWriterNoMinimumSize(t *testing.T) {
+	if kind := testWrite(t, bytes.Repeat([]byte("a"), 100), 0, false); kind != frameKindCompressed {
+		t.Error("compressible payload not compressed without minimum size")
+	}
+}
+
+func TestReaderMixedFrames(t *testing.T) {
+	// Create a writer with a minimum size.
+	stream := &bytes.Buffer{}
+	writer := NewCompressingWriter(stream)
+	writer.SetMinimumSize(512)
+
+	// Write a sequence of payloads that will be transmitted using a mix of
+	// frame kinds.
+	payloads := [][]byte{
+		[]byte("small"),
+		bytes.Repeat([]byte("compressible"), 1024),
+		testIncompressibleData(4096),
+		{},
+		bytes.Repeat([]byte("more compressible data"), 512),
+		[]byte("small again"),
+	}
+	var expected []byte
+	for _, payload := range payloads {
+		if _, err := writer.Write(payload); err != nil {
+			t.Fatal("unable to write payload:", err)
+		}
+		expected = append(expected, payload...)
+	}
+
+	// Verify the frame kinds.
+	expectedKinds := []byte{
+		frameKindRaw,
+		frameKindCompressed,
+		frameKindRaw,
+		frameKindRaw,
+		frameKindCompressed,
+		frameKindRaw,
+	}
+	if kinds := testFrameKinds(t, stream.Bytes()); !bytes.Equal(kinds, expectedKinds) {
+		t.Error("frame kinds do not match expected:", kinds, "!=", expectedKinds)
+	}
+
+	// Read the stream back using small reads to exercise frame boundaries.
+	reader := NewDecompressingReader(stream)
+	var decompressed []byte
+	buffer := make([]byte, 7)
+	for {
+		count, err := reader.Read(buffer)
+		decompressed = append(decompressed, buffer[:count]...)
+		if err != nil {
+			break
+		}
+	}
+	if !bytes.Equal(decompressed, expected) {
+		t.Error("decompressed stream does not match original")
+	}
+}
+
+func TestIncompressible(t *testing.T) {
+	// Set up test cases.
+	extensions := []string{".zip", "JPG", ""}
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"archive.zip", true},
+		{"directory/ARCHIVE.ZIP", true},
+		{"photo.jpg", true},
+		{"photo.Jpg", true},
+		{"document.txt", false},
+		{"zip", false},
+		{"jpg", false},
+		{"archive.zip.txt", false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if result := Incompressible(testCase.path, extensions); result != testCase.expected {
+			t.Errorf("incompressibility of %s (%t) does not match expected (%t)",
+				testCase.path, result, testCase.expected,
+			)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package global
 import (
 	"github.com/mutagen-io/mutagen/pkg/configuration/forwarding"
 	"github.com/mutagen-io/mutagen/pkg/configuration/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/configuration/types"
 	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/notification"
 )
 
 // Configuration is the global YAML configuration object type.
@@ -17,7 +19,60 @@ type Configuration struct {
 	Synchronization struct {
 		// Defaults are the global synchronization configuration defaults.
 		Defaults synchronization.Configuration `yaml:"defaults"`
+		// MaximumActiveSessions is the maximum number of synchronization
+		// sessions that may be active at any given time. Sessions beyond this
+		// limit are queued until active sessions are paused or terminated. If
+		// 0, then there is no limit.
+		MaximumActiveSessions uint64 `yaml:"maximumActiveSessions"`
+		// StagingBufferBudget is the total size of the buffers that may be used
+		// for staging by endpoints hosted by the daemon. If 0, then a default
+		// budget is used.
+		StagingBufferBudget types.ByteSize `yaml:"stagingBufferBudget"`
+		// TransferBandwidthBudget is the total bandwidth (in bytes per second)
+		// shared by staging transfers across all sessions. If 0, then there is
+		// no limit.
+		TransferBandwidthBudget types.ByteSize `yaml:"transferBandwidthBudget"`
 	} `yaml:"sync"`
+	// Connections is the daemon connection establishment configuration.
+	Connections struct {
+		// Limit is the maximum number of connections (across all hosts) that
+		// may be undergoing establishment at any given time. If 0, then there
+		// is no limit.
+		Limit uint16 `yaml:"limit"`
+		// LimitPerHost is the maximum number of connections to any single host
+		// that may be undergoing establishment at any given time. If 0, then
+		// there is no limit.
+		LimitPerHost uint16 `yaml:"limitPerHost"`
+	} `yaml:"connections"`
+	// Agent is the agent connection configuration.
+	Agent struct {
+		// HandshakeRetries is the maximum number of times that an agent
+		// connection will be retried after a transient handshake failure. If
+		// 0, then a default retry count is used.
+		HandshakeRetries uint8 `yaml:"handshakeRetries"`
+		// DisableHandshakeRetries disables retrying of agent connections after
+		// transient handshake failures. It takes precedence over
+		// HandshakeRetries.
+		DisableHandshakeRetries bool `yaml:"disableHandshakeRetries"`
+		// HandshakeRetryDelay specifies the delay (in milliseconds) between
+		// agent connection attempts after a transient handshake failure. If 0,
+		// then a default delay is used.
+		HandshakeRetryDelay uint32 `yaml:"handshakeRetryDelay"`
+		// UpgradeRate is the maximum number of agent installations (or
+		// upgrades) that may be performed per minute. If 0, then there is no
+		// limit.
+		UpgradeRate uint16 `yaml:"upgradeRate"`
+	} `yaml:"agent"`
+	// Notifications is the daemon notification configuration.
+	Notifications struct {
+		// Webhooks are the webhooks to which session notifications are
+		// delivered.
+		Webhooks []notification.Webhook `yaml:"webhooks"`
+		// DebounceInterval specifies the minimum interval (in seconds) between
+		// notifications of the same kind for the same session. If 0, then a
+		// default interval is used.
+		DebounceInterval uint32 `yaml:"debounceInterval"`
+	} `yaml:"notifications"`
 }
 
 // LoadConfiguration attempts to load a YAML-based Mutagen global configuration
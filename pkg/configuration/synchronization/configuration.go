@@ -4,6 +4,7 @@ import (
 	"github.com/mutagen-io/mutagen/pkg/configuration/types"
 	"github.com/mutagen-io/mutagen/pkg/filesystem"
 	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+	"github.com/mutagen-io/mutagen/pkg/ssh"
 	"github.com/mutagen-io/mutagen/pkg/synchronization"
 	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
 )
@@ -19,12 +20,244 @@ type Configuration struct {
 	// MaximumStagingFileSize is the maximum (individual) file size that
 	// endpoints will stage. It can be specified in human-friendly units.
 	MaximumStagingFileSize types.ByteSize `yaml:"maxStagingFileSize"`
+	// MaximumFileSize is the maximum (individual) file size that endpoints
+	// will synchronize. Larger files are skipped and reported as problems. It
+	// can be specified in human-friendly units.
+	MaximumFileSize types.ByteSize `yaml:"maxFileSize"`
 	// ProbeMode specifies the filesystem probing mode.
 	ProbeMode behavior.ProbeMode `yaml:"probeMode"`
 	// ScanMode specifies the filesystem scanning mode.
 	ScanMode synchronization.ScanMode `yaml:"scanMode"`
 	// StageMode specifies the filesystem staging mode.
 	StageMode synchronization.StageMode `yaml:"stageMode"`
+	// ModificationHandlingMode specifies the mode for handling files that are
+	// modified while being transmitted for staging.
+	ModificationHandlingMode synchronization.ModificationHandlingMode `yaml:"modificationHandlingMode"`
+	// CloneStagingThreshold is the minimum size of existing files for which
+	// changes are staged by cloning the existing file. It can be specified in
+	// human-friendly units.
+	CloneStagingThreshold types.ByteSize `yaml:"cloneStagingThreshold"`
+	// ContentStoreMode specifies the shared content store mode.
+	ContentStoreMode synchronization.ContentStoreMode `yaml:"contentStoreMode"`
+	// HostVerificationMode specifies the remote host verification mode.
+	HostVerificationMode synchronization.HostVerificationMode `yaml:"hostVerificationMode"`
+	// DurabilityMode specifies the mode for flushing filesystem modifications
+	// to durable storage.
+	DurabilityMode core.DurabilityMode `yaml:"durability"`
+	// SSH contains OpenSSH connection options for SSH endpoints. Options
+	// specified via SSH URLs take precedence over these options.
+	SSH struct {
+		// Port specifies the SSH port.
+		Port uint16 `yaml:"port"`
+		// IdentityFiles specifies the paths of identity (private key) files.
+		IdentityFiles []string `yaml:"identityFiles"`
+		// ProxyJump specifies the OpenSSH ProxyJump specification.
+		ProxyJump string `yaml:"proxyJump"`
+		// StrictHostKeyChecking specifies the OpenSSH StrictHostKeyChecking
+		// value.
+		StrictHostKeyChecking string `yaml:"strictHostKeyChecking"`
+		// ExtraArguments specifies additional flags to pass to OpenSSH.
+		ExtraArguments []string `yaml:"extraArguments"`
+		// User specifies the login user, overriding any user specified in
+		// endpoint URLs.
+		User string `yaml:"user"`
+		// ForcePTYForSetup specifies whether or not pseudo-terminal allocation
+		// should be forced for agent probing and installation commands. It is
+		// only intended for legacy servers that require a pseudo-terminal and
+		// is never applied to agent data streams.
+		ForcePTYForSetup bool `yaml:"forcePTYForSetup"`
+		// RemoteCommandPrefix specifies a command prefix (e.g. a login or
+		// environment wrapper) that wraps commands invoked on the remote.
+		RemoteCommandPrefix string `yaml:"remoteCommandPrefix"`
+		// PrivilegeEscalation specifies a privilege escalation command (e.g.
+		// "sudo") under which agents are invoked on the remote.
+		PrivilegeEscalation string `yaml:"privilegeEscalation"`
+		// SetEnv specifies environment variables to set on the remote using
+		// OpenSSH's SetEnv option. The remote SSH server must be configured to
+		// accept them.
+		SetEnv map[string]string `yaml:"setEnv"`
+		// CredentialCommand specifies a command (and its arguments) to invoke
+		// on each connection to obtain fresh identity and certificate files.
+		CredentialCommand []string `yaml:"credentialCommand"`
+	} `yaml:"ssh"`
+	// ConflictResolver contains parameters related to external conflict
+	// resolution.
+	ConflictResolver struct {
+		// Command specifies the conflict resolver command name and its
+		// arguments.
+		Command []string `yaml:"command"`
+		// Timeout specifies the maximum amount of time (in seconds) that an
+		// invocation of the conflict resolver may take. A value of 0 specifies
+		// that Mutagen's internal default timeout should be used.
+		Timeout uint32 `yaml:"timeout"`
+	} `yaml:"conflictResolver"`
+	// Conflicts contains parameters related to conflict handling.
+	Conflicts struct {
+		// PauseThreshold specifies the maximum number of conflicts that a
+		// synchronization cycle may encounter before the session is
+		// automatically paused. A value of 0 disables automatic pausing.
+		PauseThreshold uint64 `yaml:"pauseThreshold"`
+		// Sidecars specifies that conflicts between modified files should be
+		// resolved in alpha's favor, with beta's version of each file preserved
+		// alongside it in a conflict sidecar file.
+		Sidecars bool `yaml:"sidecars"`
+	} `yaml:"conflicts"`
+	// Agent contains parameters related to remote agents.
+	Agent struct {
+		// MemoryLimit specifies the maximum amount of memory that a remote
+		// agent may use. A value of 0 indicates no limit.
+		MemoryLimit types.ByteSize `yaml:"memoryLimit"`
+		// CPULimit specifies the maximum amount of CPU time that a remote agent
+		// may use, expressed as a percentage of a single CPU. A value of 0
+		// indicates no limit.
+		CPULimit uint32 `yaml:"cpuLimit"`
+		// CacheHost specifies a regional agent cache host (of the form
+		// [user@]host) through which agents should be installed.
+		CacheHost string `yaml:"cacheHost"`
+	} `yaml:"agent"`
+	// Undo contains parameters related to undoing synchronization cycles.
+	Undo struct {
+		// MaximumSize specifies the maximum total size of previous file
+		// contents retained to allow the last synchronization cycle to be
+		// undone. A value of 0 disables undo support.
+		MaximumSize types.ByteSize `yaml:"maxSize"`
+		// MaximumAge specifies the maximum amount of time (in seconds) after a
+		// synchronization cycle for which that cycle can be undone. A value of
+		// 0 indicates no limit.
+		MaximumAge uint32 `yaml:"maxAge"`
+	} `yaml:"undo"`
+	// Names contains parameters related to name handling.
+	Names struct {
+		// Invalid specifies the handling of names that can't be represented on
+		// an endpoint's filesystem.
+		Invalid core.InvalidNameMode `yaml:"invalid"`
+		// LongPaths specifies the handling of content whose paths would exceed
+		// the path length limit of an endpoint's platform.
+		LongPaths core.LongPathMode `yaml:"longPaths"`
+		// Encoding specifies the encoding used for names on an endpoint's
+		// filesystem.
+		Encoding core.FilenameEncoding `yaml:"encoding"`
+		// Mappings specifies the rules used to rewrite paths on beta.
+		Mappings []string `yaml:"mappings"`
+		// MappingOneWay indicates that path mappings don't need to be
+		// reversible.
+		MappingOneWay bool `yaml:"mappingOneWay"`
+	} `yaml:"names"`
+	// Transfers contains parameters related to staging transfers.
+	Transfers struct {
+		// Priority specifies the priority with which the session's staging
+		// transfers are scheduled relative to those of other sessions.
+		Priority synchronization.TransferPriority `yaml:"priority"`
+		// DeltaMode specifies whether files are transmitted as deltas or in
+		// their entirety.
+		DeltaMode synchronization.DeltaTransferMode `yaml:"deltaMode"`
+		// CycleBudget specifies the maximum amount of file content to transfer
+		// in each synchronization cycle.
+		CycleBudget types.ByteSize `yaml:"cycleBudget"`
+		// CheckSpace indicates whether or not the space (and disk quota)
+		// available for staging on each endpoint should be checked before
+		// staging, pausing the session if it's insufficient.
+		CheckSpace bool `yaml:"checkSpace"`
+	} `yaml:"transfers"`
+	// Integrity contains parameters related to integrity reporting.
+	Integrity struct {
+		// MerkleRoot specifies whether or not a Merkle tree digest of each
+		// endpoint's synchronization root should be computed after each scan.
+		MerkleRoot bool `yaml:"merkleRoot"`
+	} `yaml:"integrity"`
+	// Persistence contains parameters related to session data persistence.
+	Persistence struct {
+		// ArchiveCompression specifies the compression to use when persisting
+		// the session's ancestor archive to disk.
+		ArchiveCompression synchronization.ArchiveCompressionMode `yaml:"archiveCompression"`
+	} `yaml:"persistence"`
+	// Deletions contains parameters related to deletion handling.
+	Deletions struct {
+		// PauseThreshold specifies the maximum number of entries that a
+		// synchronization cycle may delete on either endpoint before the
+		// session is automatically paused. A value of 0 disables the check.
+		PauseThreshold uint64 `yaml:"pauseThreshold"`
+		// PausePercentage specifies the maximum percentage of previously
+		// synchronized entries that a synchronization cycle may delete on
+		// either endpoint before the session is automatically paused. A value
+		// of 0 disables the check.
+		PausePercentage uint32 `yaml:"pausePercentage"`
+	} `yaml:"deletions"`
+	// Startup contains parameters related to session startup.
+	Startup struct {
+		// Baseline indicates whether or not the existing endpoint contents
+		// (which must be equal) should be treated as already synchronized, so
+		// that only subsequent changes are synchronized.
+		Baseline bool `yaml:"baseline"`
+	} `yaml:"startup"`
+	// Quarantine contains parameters related to the quarantining of paths
+	// whose transitions repeatedly fail.
+	Quarantine struct {
+		// Threshold specifies the number of consecutive synchronization
+		// cycles in which a path's transition must fail before the path is
+		// quarantined. A value of 0 disables quarantining.
+		Threshold uint64 `yaml:"threshold"`
+	} `yaml:"quarantine"`
+	// StallDetection contains parameters related to the detection of stalled
+	// synchronization stages.
+	StallDetection struct {
+		// Timeout specifies the maximum amount of time (in seconds) that the
+		// scan and transition stages may go without making progress. A value
+		// of 0 disables stall detection.
+		Timeout uint32 `yaml:"timeout"`
+		// Abort specifies whether or not to abort the synchronization cycle
+		// when a stall is detected.
+		Abort bool `yaml:"abort"`
+	} `yaml:"stallDetection"`
+	// Compression contains parameters related to Mutagen-layer compression on
+	// remote endpoint connections.
+	Compression struct {
+		// Threshold specifies the minimum message size for which compression
+		// is performed. It can be specified in human-friendly units. A value of
+		// 0 specifies that Mutagen's internal default threshold should be used.
+		Threshold types.ByteSize `yaml:"threshold"`
+		// IncompressibleExtensions specifies file extensions for which
+		// compression is bypassed during transmission.
+		IncompressibleExtensions []string `yaml:"incompressibleExtensions"`
+	} `yaml:"compression"`
+	// Protection contains parameters related to protected paths.
+	Protection struct {
+		// Paths specifies patterns for paths that synchronization must never
+		// delete or overwrite.
+		Paths []string `yaml:"paths"`
+		// VerifiedPaths specifies patterns for paths whose staged content must
+		// be verified on disk before being moved into place.
+		VerifiedPaths []string `yaml:"verifiedPaths"`
+	} `yaml:"protection"`
+	// Concurrency contains parameters related to concurrent file processing.
+	Concurrency struct {
+		// Scan specifies the maximum number of files whose digests will be
+		// computed concurrently when scanning. A value of 0 specifies that
+		// Mutagen's internal default concurrency should be used.
+		Scan uint32 `yaml:"scan"`
+		// Staging specifies the maximum number of files that will be read
+		// concurrently when preparing to stage content. A value of 0 specifies
+		// that Mutagen's internal default concurrency should be used.
+		Staging uint32 `yaml:"staging"`
+		// ScanWarmup indicates whether or not endpoints should perform a
+		// background warmup scan to populate their digest caches ahead of the
+		// first synchronization cycle.
+		ScanWarmup bool `yaml:"scanWarmup"`
+	} `yaml:"concurrency"`
+	// Schedule contains parameters related to scheduled synchronization.
+	Schedule struct {
+		// Windows specifies the time windows (of the form
+		// "[days] HH:MM-HH:MM") during which the session will actively
+		// synchronize. Outside of these windows, the session will be paused.
+		Windows []string `yaml:"windows"`
+		// Timezone specifies the IANA time zone name in which windows are
+		// evaluated. If empty, the daemon's local time zone is used.
+		Timezone string `yaml:"timezone"`
+	} `yaml:"schedule"`
+	// StrictCapabilities specifies whether or not session startup should fail
+	// if an endpoint lacks the filesystem capabilities required by explicitly
+	// requested features, rather than degrading those features.
+	StrictCapabilities bool `yaml:"strictCapabilities"`
 	// Ignore contains parameters related to synchronization ignore
 	// specifications.
 	Ignore struct {
@@ -32,12 +265,53 @@ type Configuration struct {
 		Paths []string `yaml:"paths"`
 		// VCS specifies the VCS ignore mode.
 		VCS core.IgnoreVCSMode `yaml:"vcs"`
+		// Sets specifies the names of shared ignore sets whose patterns should
+		// be prepended to the ignore specifications in Paths.
+		Sets []string `yaml:"sets"`
+		// Git specifies whether or not paths ignored by Git should be ignored.
+		Git bool `yaml:"git"`
+		// OpenFiles specifies whether or not files open for writing by another
+		// process should be transiently ignored.
+		OpenFiles bool `yaml:"openFiles"`
+		// ContentType specifies the content type mode used to exclude files
+		// based on whether their content is text or binary.
+		ContentType core.ContentTypeMode `yaml:"contentType"`
 	} `yaml:"ignore"`
 	// Symlink contains parameters related to symlink handling.
 	Symlink struct {
 		// Mode specifies the symlink mode.
 		Mode core.SymlinkMode `yaml:"mode"`
+		// Defer specifies whether or not the creation of symlinks should be
+		// deferred until their in-tree targets exist.
+		Defer bool `yaml:"defer"`
+		// Broken specifies the handling of broken symlinks.
+		Broken core.BrokenSymlinkMode `yaml:"broken"`
 	} `yaml:"symlink"`
+	// HardLinks contains parameters related to hard link handling.
+	HardLinks struct {
+		// Preserve specifies whether or not hard links should be preserved.
+		Preserve bool `yaml:"preserve"`
+	} `yaml:"hardLinks"`
+	// MacOSMetadata contains parameters related to macOS metadata handling.
+	MacOSMetadata struct {
+		// Preserve specifies whether or not macOS resource forks and Finder
+		// metadata should be preserved.
+		Preserve bool `yaml:"preserve"`
+	} `yaml:"macOSMetadata"`
+	// FileFlags contains parameters related to BSD file flag handling.
+	FileFlags struct {
+		// Preserve specifies whether or not file flags (e.g. uchg and hidden)
+		// should be preserved.
+		Preserve bool `yaml:"preserve"`
+	} `yaml:"fileFlags"`
+	// LineEndings contains parameters related to line ending translation.
+	LineEndings struct {
+		// Patterns specifies the patterns identifying text files whose line
+		// endings should be translated.
+		Patterns []string `yaml:"patterns"`
+		// Style specifies the line ending style to which files are translated.
+		Style core.LineEndingStyle `yaml:"style"`
+	} `yaml:"lineEndings"`
 	// Watch contains parameters related to filesystem monitoring.
 	Watch struct {
 		// Mode specifies the file watching mode.
@@ -46,6 +320,10 @@ type Configuration struct {
 		// file monitoring. A value of 0 specifies that Mutagen's internal
 		// default interval should be used.
 		PollingInterval uint32 `yaml:"pollingInterval"`
+		// DeletionGracePeriod specifies the amount of time (in milliseconds)
+		// for which apparent deletions should be held and re-checked before
+		// being propagated. A value of 0 disables the grace period.
+		DeletionGracePeriod uint32 `yaml:"deletionGracePeriod"`
 	} `yaml:"watch"`
 	// Permissions contains parameters related to permission handling.
 	Permissions struct {
@@ -63,28 +341,108 @@ type Configuration struct {
 		// setting ownership of new files and directories in "portable"
 		// permission propagation mode.
 		DefaultGroup string `yaml:"defaultGroup"`
+		// ACLs specifies the POSIX ACL propagation mode.
+		ACLs core.ACLMode `yaml:"acls"`
 	} `yaml:"permissions"`
 }
 
+// sshOptions converts the SSH options to their Protocol Buffers
+// representation, returning nil if no options are specified.
+func (c *Configuration) sshOptions() *ssh.Options {
+	options := &ssh.Options{
+		Port:                  uint32(c.SSH.Port),
+		IdentityFiles:         c.SSH.IdentityFiles,
+		ProxyJump:             c.SSH.ProxyJump,
+		StrictHostKeyChecking: c.SSH.StrictHostKeyChecking,
+		ExtraArguments:        c.SSH.ExtraArguments,
+		User:                  c.SSH.User,
+		ForcePTYForSetup:      c.SSH.ForcePTYForSetup,
+		RemoteCommandPrefix:   c.SSH.RemoteCommandPrefix,
+		PrivilegeEscalation:   c.SSH.PrivilegeEscalation,
+		SetEnv:                c.SSH.SetEnv,
+		CredentialCommand:     c.SSH.CredentialCommand,
+	}
+	if options.Equal(nil) {
+		return nil
+	}
+	return options
+}
+
 // Configuration converts a YAML-based session configuration to a Protocol
 // Buffers session configuration. It does not validate the resulting
 // configuration.
 func (c *Configuration) Configuration() *synchronization.Configuration {
 	return &synchronization.Configuration{
-		SynchronizationMode:    c.Mode,
-		MaximumEntryCount:      c.MaximumEntryCount,
-		MaximumStagingFileSize: uint64(c.MaximumStagingFileSize),
-		ProbeMode:              c.ProbeMode,
-		ScanMode:               c.ScanMode,
-		StageMode:              c.StageMode,
-		SymlinkMode:            c.Symlink.Mode,
-		WatchMode:              c.Watch.Mode,
-		WatchPollingInterval:   c.Watch.PollingInterval,
-		Ignores:                c.Ignore.Paths,
-		IgnoreVCSMode:          c.Ignore.VCS,
-		DefaultFileMode:        uint32(c.Permissions.DefaultFileMode),
-		DefaultDirectoryMode:   uint32(c.Permissions.DefaultDirectoryMode),
-		DefaultOwner:           c.Permissions.DefaultOwner,
-		DefaultGroup:           c.Permissions.DefaultGroup,
+		SynchronizationMode:      c.Mode,
+		MaximumEntryCount:        c.MaximumEntryCount,
+		MaximumStagingFileSize:   uint64(c.MaximumStagingFileSize),
+		MaximumFileSize:          uint64(c.MaximumFileSize),
+		ProbeMode:                c.ProbeMode,
+		ScanMode:                 c.ScanMode,
+		StageMode:                c.StageMode,
+		ContentStoreMode:         c.ContentStoreMode,
+		ConflictResolverCommand:  c.ConflictResolver.Command,
+		ConflictResolverTimeout:  c.ConflictResolver.Timeout,
+		SymlinkMode:              c.Symlink.Mode,
+		PreserveHardLinks:        c.HardLinks.Preserve,
+		DeferSymlinks:            c.Symlink.Defer,
+		BrokenSymlinkMode:        c.Symlink.Broken,
+		PreserveMacOSMetadata:    c.MacOSMetadata.Preserve,
+		PreserveFileFlags:        c.FileFlags.Preserve,
+		WatchMode:                c.Watch.Mode,
+		WatchPollingInterval:     c.Watch.PollingInterval,
+		DeletionGracePeriod:      c.Watch.DeletionGracePeriod,
+		Ignores:                  c.Ignore.Paths,
+		IgnoreVCSMode:            c.Ignore.VCS,
+		IgnoreSets:               c.Ignore.Sets,
+		IgnoreGitIgnored:         c.Ignore.Git,
+		IgnoreOpenFiles:          c.Ignore.OpenFiles,
+		ContentTypeMode:          c.Ignore.ContentType,
+		DefaultFileMode:          uint32(c.Permissions.DefaultFileMode),
+		DefaultDirectoryMode:     uint32(c.Permissions.DefaultDirectoryMode),
+		DefaultOwner:             c.Permissions.DefaultOwner,
+		DefaultGroup:             c.Permissions.DefaultGroup,
+		AclMode:                  c.Permissions.ACLs,
+		HostVerificationMode:     c.HostVerificationMode,
+		SshOptions:               c.sshOptions(),
+		DurabilityMode:           c.DurabilityMode,
+		ModificationHandlingMode: c.ModificationHandlingMode,
+		CloneStagingThreshold:    uint64(c.CloneStagingThreshold),
+		StallTimeout:             c.StallDetection.Timeout,
+		AbortOnStall:             c.StallDetection.Abort,
+		CompressionThreshold:     uint64(c.Compression.Threshold),
+		IncompressibleExtensions: c.Compression.IncompressibleExtensions,
+		ProtectedPaths:           c.Protection.Paths,
+		VerifiedPaths:            c.Protection.VerifiedPaths,
+		ScanConcurrency:          c.Concurrency.Scan,
+		StagingConcurrency:       c.Concurrency.Staging,
+		ScanWarmup:               c.Concurrency.ScanWarmup,
+		ScheduleWindows:          c.Schedule.Windows,
+		ScheduleTimezone:         c.Schedule.Timezone,
+		StrictCapabilities:       c.StrictCapabilities,
+		LineEndingPatterns:       c.LineEndings.Patterns,
+		LineEndingStyle:          c.LineEndings.Style,
+		ConflictPauseThreshold:   c.Conflicts.PauseThreshold,
+		ConflictSidecars:         c.Conflicts.Sidecars,
+		AgentMemoryLimit:         uint64(c.Agent.MemoryLimit),
+		AgentCPULimit:            c.Agent.CPULimit,
+		AgentCacheHost:           c.Agent.CacheHost,
+		UndoMaximumSize:          uint64(c.Undo.MaximumSize),
+		UndoMaximumAge:           c.Undo.MaximumAge,
+		InvalidNameMode:          c.Names.Invalid,
+		LongPathMode:             c.Names.LongPaths,
+		FilenameEncoding:         c.Names.Encoding,
+		PathMappings:             c.Names.Mappings,
+		PathMappingOneWay:        c.Names.MappingOneWay,
+		TransferPriority:         c.Transfers.Priority,
+		DeltaTransferMode:        c.Transfers.DeltaMode,
+		CycleTransferBudget:      uint64(c.Transfers.CycleBudget),
+		CheckAvailableSpace:      c.Transfers.CheckSpace,
+		ComputeMerkleRoot:        c.Integrity.MerkleRoot,
+		ArchiveCompressionMode:   c.Persistence.ArchiveCompression,
+		DeletionPauseThreshold:   c.Deletions.PauseThreshold,
+		DeletionPausePercentage:  c.Deletions.PausePercentage,
+		BaselineStartup:          c.Startup.Baseline,
+		QuarantineThreshold:      c.Quarantine.Threshold,
 	}
 }
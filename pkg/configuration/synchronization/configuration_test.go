@@ -7,6 +7,7 @@ import (
 
 	"github.com/mutagen-io/mutagen/pkg/encoding"
 	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+	"github.com/mutagen-io/mutagen/pkg/ssh"
 	"github.com/mutagen-io/mutagen/pkg/synchronization"
 	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
 )
@@ -16,28 +17,144 @@ const (
 mode: "two-way-resolved"
 maxEntryCount: 500
 maxStagingFileSize: "1000 GB"
+maxFileSize: "2 GB"
 probeMode: "assume"
 scanMode: "accelerated"
 stageMode: "neighboring"
+modificationHandlingMode: "retry"
+cloneStagingThreshold: "1 GB"
+contentStoreMode: "shared"
+hostVerificationMode: "ephemeral"
+durability: "metadata"
+
+ssh:
+  port: 2222
+  identityFiles:
+    - "~/.ssh/deploy"
+  proxyJump: "bastion.example.org"
+  strictHostKeyChecking: "yes"
+  extraArguments:
+    - "-4"
+  user: "deploy"
+  setEnv:
+    LANG: "en_US.UTF-8"
+  credentialCommand:
+    - "fetch-credentials"
+    - "--fresh"
+
+stallDetection:
+  timeout: 300
+  abort: true
+
+compression:
+  threshold: "1 KiB"
+  incompressibleExtensions:
+    - ".zip"
+    - "jpg"
+
+conflictResolver:
+  command:
+    - "merge-tool"
+    - "--automatic"
+  timeout: 15
+
+conflicts:
+  pauseThreshold: 25
+
+agent:
+  memoryLimit: "512 MiB"
+  cpuLimit: 50
+  cacheHost: "cache@cache.example.org"
+
+concurrency:
+  scanWarmup: true
+
+undo:
+  maxSize: "64 MiB"
+  maxAge: 3600
+
+names:
+  invalid: "escape"
+  longPaths: "skip"
+  encoding: "shift-jis"
+
+transfers:
+  priority: "high"
+  deltaMode: "whole-file"
+  cycleBudget: "16 MiB"
+  checkSpace: true
+
+integrity:
+  merkleRoot: true
+
+persistence:
+  archiveCompression: "gzip"
+
+deletions:
+  pauseThreshold: 500
+  pausePercentage: 40
+
+startup:
+  baseline: true
+
+quarantine:
+  threshold: 5
 
 symlink:
   mode: "portable"
+  defer: true
+  broken: "skip"
+
+hardLinks:
+  preserve: true
+
+macOSMetadata:
+  preserve: true
+
+fileFlags:
+  preserve: true
+
+lineEndings:
+  patterns:
+    - "*.txt"
+    - "docs/**/*.md"
+  style: "crlf"
 
 watch:
   mode: "force-poll"
   pollingInterval: 5
+  deletionGracePeriod: 250
 
 ignore:
   paths:
     - "ignore/this/**"
     - "!ignore/this/that"
   vcs: true
+  sets:
+    - "node"
+    - "build-outputs"
+  git: true
+  openFiles: true
+  contentType: "text"
 
 permissions:
   defaultFileMode: 644
   defaultDirectoryMode: 0755
   defaultOwner: "george"
   defaultGroup: "presidents"
+  acls: "propagate"
+`
+
+	// testYAMLPathMappingConfiguration is a configuration that specifies path
+	// mappings, which can't be combined with the conflict resolution settings
+	// in testYAMLConfiguration.
+	testYAMLPathMappingConfiguration = `
+mode: "one-way-replica"
+names:
+  mappings:
+    - "src=>lib"
+    - "regex:^docs/(.*)\\.md$=>manual/$1.md"
+  mappingOneWay: true
 `
 )
 
@@ -48,21 +165,85 @@ var expectedConfiguration = &synchronization.Configuration{
 	MaximumEntryCount:   500,
 	// TODO: This will mis-match.
 	MaximumStagingFileSize: 1000000000000,
+	MaximumFileSize:        2000000000,
 	ProbeMode:              behavior.ProbeMode_ProbeModeAssume,
 	ScanMode:               synchronization.ScanMode_ScanModeAccelerated,
 	StageMode:              synchronization.StageMode_StageModeNeighboring,
-	SymlinkMode:            core.SymlinkMode_SymlinkModePortable,
-	WatchMode:              synchronization.WatchMode_WatchModeForcePoll,
-	WatchPollingInterval:   5,
+	ContentStoreMode:       synchronization.ContentStoreMode_ContentStoreModeShared,
+	ConflictResolverCommand: []string{
+		"merge-tool",
+		"--automatic",
+	},
+	ConflictResolverTimeout: 15,
+	ConflictPauseThreshold:  25,
+	AgentMemoryLimit:        512 * 1024 * 1024,
+	AgentCPULimit:           50,
+	AgentCacheHost:          "cache@cache.example.org",
+	ScanWarmup:              true,
+	UndoMaximumSize:         64 * 1024 * 1024,
+	UndoMaximumAge:          3600,
+	InvalidNameMode:         core.InvalidNameMode_InvalidNameModeEscape,
+	LongPathMode:            core.LongPathMode_LongPathModeSkip,
+	FilenameEncoding:        core.FilenameEncoding_FilenameEncodingShiftJIS,
+	TransferPriority:        synchronization.TransferPriority_TransferPriorityHigh,
+	DeltaTransferMode:       synchronization.DeltaTransferMode_DeltaTransferModeWholeFile,
+	CycleTransferBudget:     16 * 1024 * 1024,
+	CheckAvailableSpace:     true,
+	ComputeMerkleRoot:       true,
+	ArchiveCompressionMode:  synchronization.ArchiveCompressionMode_ArchiveCompressionModeGzip,
+	DeletionPauseThreshold:  500,
+	DeletionPausePercentage: 40,
+	BaselineStartup:         true,
+	QuarantineThreshold:     5,
+	SymlinkMode:             core.SymlinkMode_SymlinkModePortable,
+	PreserveHardLinks:       true,
+	DeferSymlinks:           true,
+	BrokenSymlinkMode:       core.BrokenSymlinkMode_BrokenSymlinkModeSkip,
+	PreserveMacOSMetadata:   true,
+	PreserveFileFlags:       true,
+	LineEndingPatterns: []string{
+		"*.txt",
+		"docs/**/*.md",
+	},
+	LineEndingStyle:      core.LineEndingStyle_LineEndingStyleCRLF,
+	WatchMode:            synchronization.WatchMode_WatchModeForcePoll,
+	WatchPollingInterval: 5,
+	DeletionGracePeriod:  250,
 	Ignores: []string{
 		"ignore/this/**",
 		"!ignore/this/that",
 	},
-	IgnoreVCSMode:        core.IgnoreVCSMode_IgnoreVCSModeIgnore,
+	IgnoreVCSMode: core.IgnoreVCSMode_IgnoreVCSModeIgnore,
+	IgnoreSets: []string{
+		"node",
+		"build-outputs",
+	},
+	IgnoreGitIgnored:     true,
+	IgnoreOpenFiles:      true,
+	ContentTypeMode:      core.ContentTypeMode_ContentTypeModeText,
 	DefaultFileMode:      0644,
 	DefaultDirectoryMode: 0755,
 	DefaultOwner:         "george",
 	DefaultGroup:         "presidents",
+	AclMode:              core.ACLMode_ACLModePropagate,
+	HostVerificationMode: synchronization.HostVerificationMode_HostVerificationModeEphemeral,
+	SshOptions: &ssh.Options{
+		Port:                  2222,
+		IdentityFiles:         []string{"~/.ssh/deploy"},
+		ProxyJump:             "bastion.example.org",
+		StrictHostKeyChecking: "yes",
+		ExtraArguments:        []string{"-4"},
+		User:                  "deploy",
+		SetEnv:                map[string]string{"LANG": "en_US.UTF-8"},
+		CredentialCommand:     []string{"fetch-credentials", "--fresh"},
+	},
+	DurabilityMode:           core.DurabilityMode_DurabilityModeMetadata,
+	ModificationHandlingMode: synchronization.ModificationHandlingMode_ModificationHandlingModeRetry,
+	CloneStagingThreshold:    1000000000,
+	StallTimeout:             300,
+	AbortOnStall:             true,
+	CompressionThreshold:     1024,
+	IncompressibleExtensions: []string{".zip", "jpg"},
 }
 
 // TestLoadConfiguration tests loading a YAML-based session configuration.
@@ -102,6 +283,9 @@ func TestLoadConfiguration(t *testing.T) {
 	if configuration.MaximumStagingFileSize != expectedConfiguration.MaximumStagingFileSize {
 		t.Error("maximum staging file size mismatch:", configuration.MaximumStagingFileSize, "!=", expectedConfiguration.MaximumStagingFileSize)
 	}
+	if configuration.MaximumFileSize != expectedConfiguration.MaximumFileSize {
+		t.Error("maximum file size mismatch:", configuration.MaximumFileSize, "!=", expectedConfiguration.MaximumFileSize)
+	}
 	if configuration.ProbeMode != expectedConfiguration.ProbeMode {
 		t.Error("probe mode mismatch:", configuration.ProbeMode, "!=", expectedConfiguration.ProbeMode)
 	}
@@ -111,15 +295,120 @@ func TestLoadConfiguration(t *testing.T) {
 	if configuration.StageMode != expectedConfiguration.StageMode {
 		t.Error("stage mode mismatch:", configuration.StageMode, "!=", expectedConfiguration.StageMode)
 	}
+	if configuration.ContentStoreMode != expectedConfiguration.ContentStoreMode {
+		t.Error("content store mode mismatch:", configuration.ContentStoreMode, "!=", expectedConfiguration.ContentStoreMode)
+	}
+	if len(configuration.ConflictResolverCommand) != len(expectedConfiguration.ConflictResolverCommand) {
+		t.Error("conflict resolver command length mismatch:", len(configuration.ConflictResolverCommand), "!=", len(expectedConfiguration.ConflictResolverCommand))
+	} else {
+		for i, argument := range configuration.ConflictResolverCommand {
+			if argument != expectedConfiguration.ConflictResolverCommand[i] {
+				t.Error("conflict resolver command mismatch:", argument, "!=", expectedConfiguration.ConflictResolverCommand[i], "at index", i)
+			}
+		}
+	}
+	if configuration.ConflictResolverTimeout != expectedConfiguration.ConflictResolverTimeout {
+		t.Error("conflict resolver timeout mismatch:", configuration.ConflictResolverTimeout, "!=", expectedConfiguration.ConflictResolverTimeout)
+	}
+	if configuration.ConflictPauseThreshold != expectedConfiguration.ConflictPauseThreshold {
+		t.Error("conflict pause threshold mismatch:", configuration.ConflictPauseThreshold, "!=", expectedConfiguration.ConflictPauseThreshold)
+	}
+	if configuration.AgentMemoryLimit != expectedConfiguration.AgentMemoryLimit {
+		t.Error("agent memory limit mismatch:", configuration.AgentMemoryLimit, "!=", expectedConfiguration.AgentMemoryLimit)
+	}
+	if configuration.AgentCPULimit != expectedConfiguration.AgentCPULimit {
+		t.Error("agent CPU limit mismatch:", configuration.AgentCPULimit, "!=", expectedConfiguration.AgentCPULimit)
+	}
+	if configuration.AgentCacheHost != expectedConfiguration.AgentCacheHost {
+		t.Error("agent cache host mismatch:", configuration.AgentCacheHost, "!=", expectedConfiguration.AgentCacheHost)
+	}
+	if configuration.ScanWarmup != expectedConfiguration.ScanWarmup {
+		t.Error("scan warmup mismatch:", configuration.ScanWarmup, "!=", expectedConfiguration.ScanWarmup)
+	}
+	if configuration.UndoMaximumSize != expectedConfiguration.UndoMaximumSize {
+		t.Error("undo maximum size mismatch:", configuration.UndoMaximumSize, "!=", expectedConfiguration.UndoMaximumSize)
+	}
+	if configuration.UndoMaximumAge != expectedConfiguration.UndoMaximumAge {
+		t.Error("undo maximum age mismatch:", configuration.UndoMaximumAge, "!=", expectedConfiguration.UndoMaximumAge)
+	}
+	if configuration.InvalidNameMode != expectedConfiguration.InvalidNameMode {
+		t.Error("invalid name mode mismatch:", configuration.InvalidNameMode, "!=", expectedConfiguration.InvalidNameMode)
+	}
+	if configuration.LongPathMode != expectedConfiguration.LongPathMode {
+		t.Error("long path mode mismatch:", configuration.LongPathMode, "!=", expectedConfiguration.LongPathMode)
+	}
+	if configuration.FilenameEncoding != expectedConfiguration.FilenameEncoding {
+		t.Error("filename encoding mismatch:", configuration.FilenameEncoding, "!=", expectedConfiguration.FilenameEncoding)
+	}
+	if configuration.TransferPriority != expectedConfiguration.TransferPriority {
+		t.Error("transfer priority mismatch:", configuration.TransferPriority, "!=", expectedConfiguration.TransferPriority)
+	}
+	if configuration.DeltaTransferMode != expectedConfiguration.DeltaTransferMode {
+		t.Error("delta transfer mode mismatch:", configuration.DeltaTransferMode, "!=", expectedConfiguration.DeltaTransferMode)
+	}
+	if configuration.CycleTransferBudget != expectedConfiguration.CycleTransferBudget {
+		t.Error("cycle transfer budget mismatch:", configuration.CycleTransferBudget, "!=", expectedConfiguration.CycleTransferBudget)
+	}
+	if configuration.CheckAvailableSpace != expectedConfiguration.CheckAvailableSpace {
+		t.Error("check available space mismatch:", configuration.CheckAvailableSpace, "!=", expectedConfiguration.CheckAvailableSpace)
+	}
+	if configuration.ComputeMerkleRoot != expectedConfiguration.ComputeMerkleRoot {
+		t.Error("Merkle root computation mismatch:", configuration.ComputeMerkleRoot, "!=", expectedConfiguration.ComputeMerkleRoot)
+	}
+	if configuration.ArchiveCompressionMode != expectedConfiguration.ArchiveCompressionMode {
+		t.Error("archive compression mode mismatch:", configuration.ArchiveCompressionMode, "!=", expectedConfiguration.ArchiveCompressionMode)
+	}
+	if configuration.DeletionPauseThreshold != expectedConfiguration.DeletionPauseThreshold {
+		t.Error("deletion pause threshold mismatch:", configuration.DeletionPauseThreshold, "!=", expectedConfiguration.DeletionPauseThreshold)
+	}
+	if configuration.DeletionPausePercentage != expectedConfiguration.DeletionPausePercentage {
+		t.Error("deletion pause percentage mismatch:", configuration.DeletionPausePercentage, "!=", expectedConfiguration.DeletionPausePercentage)
+	}
+	if configuration.BaselineStartup != expectedConfiguration.BaselineStartup {
+		t.Error("baseline startup mismatch:", configuration.BaselineStartup, "!=", expectedConfiguration.BaselineStartup)
+	}
+	if configuration.QuarantineThreshold != expectedConfiguration.QuarantineThreshold {
+		t.Error("quarantine threshold mismatch:", configuration.QuarantineThreshold, "!=", expectedConfiguration.QuarantineThreshold)
+	}
 	if configuration.SymlinkMode != expectedConfiguration.SymlinkMode {
 		t.Error("symlink mode mismatch:", configuration.SymlinkMode, "!=", expectedConfiguration.SymlinkMode)
 	}
+	if configuration.PreserveHardLinks != expectedConfiguration.PreserveHardLinks {
+		t.Error("hard link preservation mismatch:", configuration.PreserveHardLinks, "!=", expectedConfiguration.PreserveHardLinks)
+	}
+	if configuration.DeferSymlinks != expectedConfiguration.DeferSymlinks {
+		t.Error("symlink deferral mismatch:", configuration.DeferSymlinks, "!=", expectedConfiguration.DeferSymlinks)
+	}
+	if configuration.BrokenSymlinkMode != expectedConfiguration.BrokenSymlinkMode {
+		t.Error("broken symlink mode mismatch:", configuration.BrokenSymlinkMode, "!=", expectedConfiguration.BrokenSymlinkMode)
+	}
+	if configuration.PreserveMacOSMetadata != expectedConfiguration.PreserveMacOSMetadata {
+		t.Error("macOS metadata preservation mismatch:", configuration.PreserveMacOSMetadata, "!=", expectedConfiguration.PreserveMacOSMetadata)
+	}
+	if configuration.PreserveFileFlags != expectedConfiguration.PreserveFileFlags {
+		t.Error("file flag preservation mismatch:", configuration.PreserveFileFlags, "!=", expectedConfiguration.PreserveFileFlags)
+	}
+	if len(configuration.LineEndingPatterns) != len(expectedConfiguration.LineEndingPatterns) {
+		t.Error("line ending pattern count mismatch:", len(configuration.LineEndingPatterns), "!=", len(expectedConfiguration.LineEndingPatterns))
+	} else {
+		for i, pattern := range configuration.LineEndingPatterns {
+			if pattern != expectedConfiguration.LineEndingPatterns[i] {
+				t.Error("line ending pattern mismatch:", pattern, "!=", expectedConfiguration.LineEndingPatterns[i], "at index", i)
+			}
+		}
+	}
+	if configuration.LineEndingStyle != expectedConfiguration.LineEndingStyle {
+		t.Error("line ending style mismatch:", configuration.LineEndingStyle, "!=", expectedConfiguration.LineEndingStyle)
+	}
 	if configuration.WatchMode != expectedConfiguration.WatchMode {
 		t.Error("watch mode mismatch:", configuration.WatchMode, "!=", expectedConfiguration.WatchMode)
 	}
 	if configuration.WatchPollingInterval != expectedConfiguration.WatchPollingInterval {
 		t.Error("watch polling interval mismatch:", configuration.WatchPollingInterval, "!=", expectedConfiguration.WatchPollingInterval)
 	}
+	if configuration.DeletionGracePeriod != expectedConfiguration.DeletionGracePeriod {
+		t.Error("deletion grace period mismatch:", configuration.DeletionGracePeriod, "!=", expectedConfiguration.DeletionGracePeriod)
+	}
 	if len(configuration.Ignores) != len(expectedConfiguration.Ignores) {
 		t.Error("ignore count mismatch:", len(configuration.Ignores), "!=", len(expectedConfiguration.Ignores))
 	} else {
@@ -132,6 +421,24 @@ func TestLoadConfiguration(t *testing.T) {
 	if configuration.IgnoreVCSMode != expectedConfiguration.IgnoreVCSMode {
 		t.Error("ignore VCS mode mismatch:", configuration.IgnoreVCSMode, "!=", expectedConfiguration.IgnoreVCSMode)
 	}
+	if len(configuration.IgnoreSets) != len(expectedConfiguration.IgnoreSets) {
+		t.Error("ignore set count mismatch:", len(configuration.IgnoreSets), "!=", len(expectedConfiguration.IgnoreSets))
+	} else {
+		for i, name := range configuration.IgnoreSets {
+			if name != expectedConfiguration.IgnoreSets[i] {
+				t.Error("ignore set mismatch:", name, "!=", expectedConfiguration.IgnoreSets[i], "at index", i)
+			}
+		}
+	}
+	if configuration.IgnoreGitIgnored != expectedConfiguration.IgnoreGitIgnored {
+		t.Error("Git ignore behavior mismatch:", configuration.IgnoreGitIgnored, "!=", expectedConfiguration.IgnoreGitIgnored)
+	}
+	if configuration.IgnoreOpenFiles != expectedConfiguration.IgnoreOpenFiles {
+		t.Error("open file ignore behavior mismatch:", configuration.IgnoreOpenFiles, "!=", expectedConfiguration.IgnoreOpenFiles)
+	}
+	if configuration.ContentTypeMode != expectedConfiguration.ContentTypeMode {
+		t.Error("content type mode mismatch:", configuration.ContentTypeMode, "!=", expectedConfiguration.ContentTypeMode)
+	}
 	if configuration.DefaultFileMode != expectedConfiguration.DefaultFileMode {
 		t.Errorf("default file mode mismatch: %o != %o", configuration.DefaultFileMode, expectedConfiguration.DefaultFileMode)
 	}
@@ -144,6 +451,83 @@ func TestLoadConfiguration(t *testing.T) {
 	if configuration.DefaultGroup != expectedConfiguration.DefaultGroup {
 		t.Error("default owner mismatch:", configuration.DefaultGroup, "!=", expectedConfiguration.DefaultGroup)
 	}
+	if configuration.AclMode != expectedConfiguration.AclMode {
+		t.Error("ACL mode mismatch:", configuration.AclMode, "!=", expectedConfiguration.AclMode)
+	}
+	if configuration.HostVerificationMode != expectedConfiguration.HostVerificationMode {
+		t.Error("host verification mode mismatch:", configuration.HostVerificationMode, "!=", expectedConfiguration.HostVerificationMode)
+	}
+	if !configuration.SshOptions.Equal(expectedConfiguration.SshOptions) {
+		t.Error("SSH options mismatch:", configuration.SshOptions, "!=", expectedConfiguration.SshOptions)
+	}
+	if configuration.DurabilityMode != expectedConfiguration.DurabilityMode {
+		t.Error("durability mode mismatch:", configuration.DurabilityMode, "!=", expectedConfiguration.DurabilityMode)
+	}
+	if configuration.ModificationHandlingMode != expectedConfiguration.ModificationHandlingMode {
+		t.Error("modification handling mode mismatch:", configuration.ModificationHandlingMode, "!=", expectedConfiguration.ModificationHandlingMode)
+	}
+	if configuration.CloneStagingThreshold != expectedConfiguration.CloneStagingThreshold {
+		t.Error("clone staging threshold mismatch:", configuration.CloneStagingThreshold, "!=", expectedConfiguration.CloneStagingThreshold)
+	}
+	if configuration.StallTimeout != expectedConfiguration.StallTimeout {
+		t.Error("stall timeout mismatch:", configuration.StallTimeout, "!=", expectedConfiguration.StallTimeout)
+	}
+	if configuration.AbortOnStall != expectedConfiguration.AbortOnStall {
+		t.Error("stall abortion mismatch:", configuration.AbortOnStall, "!=", expectedConfiguration.AbortOnStall)
+	}
+	if configuration.CompressionThreshold != expectedConfiguration.CompressionThreshold {
+		t.Error("compression threshold mismatch:", configuration.CompressionThreshold, "!=", expectedConfiguration.CompressionThreshold)
+	}
+	if len(configuration.IncompressibleExtensions) != len(expectedConfiguration.IncompressibleExtensions) {
+		t.Error("incompressible extension count mismatch:", len(configuration.IncompressibleExtensions), "!=", len(expectedConfiguration.IncompressibleExtensions))
+	} else {
+		for i, extension := range configuration.IncompressibleExtensions {
+			if extension != expectedConfiguration.IncompressibleExtensions[i] {
+				t.Error("incompressible extension mismatch:", extension, "!=", expectedConfiguration.IncompressibleExtensions[i], "at index", i)
+			}
+		}
+	}
+}
+
+// TestLoadConfigurationPathMappings tests loading of path mapping settings.
+func TestLoadConfigurationPathMappings(t *testing.T) {
+	// Write the configuration to a temporary file and defer its cleanup.
+	file, err := ioutil.TempFile("", "mutagen_configuration")
+	if err != nil {
+		t.Fatal("unable to create temporary file:", err)
+	} else if _, err = file.Write([]byte(testYAMLPathMappingConfiguration)); err != nil {
+		t.Fatal("unable to write data to temporary file:", err)
+	} else if err = file.Close(); err != nil {
+		t.Fatal("unable to close temporary file:", err)
+	}
+	defer os.Remove(file.Name())
+
+	// Attempt to load.
+	yamlConfiguration := &Configuration{}
+	if err := encoding.LoadAndUnmarshalYAML(file.Name(), yamlConfiguration); err != nil {
+		t.Fatal("configuration loading failed:", err)
+	}
+
+	// Compute the Protocol Buffers session representation and ensure that it's
+	// valid.
+	configuration := yamlConfiguration.Configuration()
+	if err := configuration.EnsureValid(false); err != nil {
+		t.Error("derived configuration invalid:", err)
+	}
+
+	// Verify the path mapping settings.
+	expected := []string{"src=>lib", `regex:^docs/(.*)\.md$=>manual/$1.md`}
+	if len(configuration.PathMappings) != len(expected) {
+		t.Fatal("path mapping count mismatch:", len(configuration.PathMappings), "!=", len(expected))
+	}
+	for i, mapping := range configuration.PathMappings {
+		if mapping != expected[i] {
+			t.Error("path mapping mismatch:", mapping, "!=", expected[i], "at index", i)
+		}
+	}
+	if !configuration.PathMappingOneWay {
+		t.Error("path mapping not one-way")
+	}
 }
 
 // TODO: Expand tests, including testing for invalid configurations.
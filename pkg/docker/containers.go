@@ -0,0 +1,57 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	environmentpkg "github.com/mutagen-io/mutagen/pkg/environment"
+)
+
+// ContainersMatchingLabels uses the Docker CLI to determine the names of the
+// running containers matching the specified label selector, which consists of
+// one or more comma-separated label filters (each of the form key or
+// key=value), all of which must match. The provided connection flags and
+// environment variables are used when executing the docker ps command. If
+// environment is nil, then the current process' environment will be used.
+// Container names are returned in sorted order.
+func ContainersMatchingLabels(daemonFlags DaemonConnectionFlags, environment map[string]string, selector string) ([]string, error) {
+	// Set up flags and arguments to list matching container names.
+	var arguments []string
+	arguments = append(arguments, daemonFlags.ToFlags()...)
+	arguments = append(arguments, "ps")
+	for _, filter := range strings.Split(selector, ",") {
+		arguments = append(arguments, "--filter", "label="+filter)
+	}
+	arguments = append(arguments, "--format", "{{.Names}}")
+
+	// Set up the command.
+	command, err := Command(context.Background(), arguments...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to set up Docker invocation: %w", err)
+	}
+
+	// Set the command environment.
+	command.Env = environmentpkg.FromMap(environment)
+
+	// Run the command.
+	output, err := command.Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker ps command failed: %w", err)
+	}
+
+	// Extract container names, removing any duplicates.
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Fields(string(output)) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	// Success.
+	return names, nil
+}
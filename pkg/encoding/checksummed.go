@@ -0,0 +1,208 @@
+package encoding
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+)
+
+// checksummedHeader is the header that prefixes checksummed data. It begins
+// with a null byte, which can't begin a valid Protocol Buffers encoding (since
+// field number 0 is invalid), allowing checksummed data to be distinguished
+// from data saved by MarshalAndSave.
+var checksummedHeader = []byte("\x00mutagen-checksummed-1\n")
+
+// compressedHeader is the header that prefixes compressed checksummed data. It
+// is followed by a gzip stream containing checksummed data (including its
+// header). Like checksummedHeader, it begins with a null byte.
+var compressedHeader = []byte("\x00mutagen-gzip-1\n")
+
+// checksummedTable is the CRC-32 table used for checksummed data.
+var checksummedTable = crc32.MakeTable(crc32.Castagnoli)
+
+const (
+	// checksummedLengthSize is the size of the payload length that follows the
+	// checksummed header.
+	checksummedLengthSize = 8
+	// checksummedChecksumSize is the size of the payload checksum that follows
+	// the payload length.
+	checksummedChecksumSize = 4
+)
+
+// ErrCorrupted indicates that checksummed data failed integrity validation or
+// couldn't be decoded. It is wrapped with additional context, so errors.Cause
+// should be used when checking for it.
+var ErrCorrupted = errors.New("data corrupted")
+
+// LoadAndUnmarshalChecksummed is a variant of LoadAndUnmarshal for data saved
+// with MarshalAndSaveChecksummed. It validates the length and checksum of the
+// data before invoking the specified unmarshaling callback. Integrity and
+// unmarshaling failures are reported using errors whose cause is ErrCorrupted.
+// Data saved by MarshalAndSave (which has no checksum) is also accepted, in
+// which case only unmarshaling failures can be detected. Data saved with
+// MarshalAndSaveCompressedChecksummed is detected and decompressed
+// automatically, with decompression failures also reported as corruption.
+func LoadAndUnmarshalChecksummed(path string, unmarshal func([]byte) error) error {
+	// Grab the file contents.
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return err
+		}
+		return errors.Wrap(err, "unable to load file")
+	}
+
+	// If the data is compressed, then decompress it. The decompressed data must
+	// itself be checksummed, which allows the integrity of the payload to be
+	// validated even if the compressed stream is structurally valid.
+	if bytes.HasPrefix(data, compressedHeader) {
+		decompressor, err := gzip.NewReader(bytes.NewReader(data[len(compressedHeader):]))
+		if err != nil {
+			return errors.Wrapf(ErrCorrupted, "invalid compressed data (%v)", err)
+		}
+		data, err = ioutil.ReadAll(decompressor)
+		if err != nil {
+			return errors.Wrapf(ErrCorrupted, "unable to decompress data (%v)", err)
+		} else if !bytes.HasPrefix(data, checksummedHeader) {
+			return errors.Wrap(ErrCorrupted, "compressed data not checksummed")
+		}
+	}
+
+	// If the data has a checksummed header, then validate its integrity and
+	// extract the payload. Otherwise treat the data as a legacy payload.
+	if bytes.HasPrefix(data, checksummedHeader) {
+		data = data[len(checksummedHeader):]
+		if len(data) < checksummedLengthSize+checksummedChecksumSize {
+			return errors.Wrap(ErrCorrupted, "truncated header")
+		}
+		length := binary.BigEndian.Uint64(data[:checksummedLengthSize])
+		checksum := binary.BigEndian.Uint32(data[checksummedLengthSize : checksummedLengthSize+checksummedChecksumSize])
+		data = data[checksummedLengthSize+checksummedChecksumSize:]
+		if uint64(len(data)) != length {
+			return errors.Wrapf(ErrCorrupted, "payload length mismatch (%d != %d)", len(data), length)
+		} else if crc32.Checksum(data, checksummedTable) != checksum {
+			return errors.Wrap(ErrCorrupted, "payload checksum mismatch")
+		}
+	}
+
+	// Perform the unmarshaling.
+	if err := unmarshal(data); err != nil {
+		return errors.Wrapf(ErrCorrupted, "unable to unmarshal data (%v)", err)
+	}
+
+	// Success.
+	return nil
+}
+
+// IsChecksummed indicates whether or not data was saved with
+// MarshalAndSaveChecksummed or MarshalAndSaveCompressedChecksummed (as opposed
+// to MarshalAndSave). It doesn't validate the integrity of the data.
+func IsChecksummed(data []byte) bool {
+	return bytes.HasPrefix(data, checksummedHeader) || IsCompressed(data)
+}
+
+// IsCompressed indicates whether or not data was saved with
+// MarshalAndSaveCompressedChecksummed. It doesn't validate the integrity of the
+// data.
+func IsCompressed(data []byte) bool {
+	return bytes.HasPrefix(data, compressedHeader)
+}
+
+// checksummed prefixes a payload with the checksummed header, its length, and
+// its checksum.
+func checksummed(payload []byte) []byte {
+	data := make([]byte, 0, len(checksummedHeader)+checksummedLengthSize+checksummedChecksumSize+len(payload))
+	data = append(data, checksummedHeader...)
+	var lengthAndChecksum [checksummedLengthSize + checksummedChecksumSize]byte
+	binary.BigEndian.PutUint64(lengthAndChecksum[:checksummedLengthSize], uint64(len(payload)))
+	binary.BigEndian.PutUint32(lengthAndChecksum[checksummedLengthSize:], crc32.Checksum(payload, checksummedTable))
+	data = append(data, lengthAndChecksum[:]...)
+	return append(data, payload...)
+}
+
+// MarshalAndSaveChecksummed is a variant of MarshalAndSave that prefixes the
+// marshaled data with its length and checksum so that its integrity can be
+// validated by LoadAndUnmarshalChecksummed.
+func MarshalAndSaveChecksummed(path string, marshal func() ([]byte, error)) error {
+	// Marshal the message.
+	payload, err := marshal()
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal message")
+	}
+
+	// Prefix the payload with the header, length, and checksum.
+	data := checksummed(payload)
+
+	// Write the file atomically with secure file permissions.
+	if err := filesystem.WriteFileAtomic(path, data, 0600); err != nil {
+		return errors.Wrap(err, "unable to write message data")
+	}
+
+	// Success.
+	return nil
+}
+
+// MarshalAndSaveCompressedChecksummed is a variant of MarshalAndSaveChecksummed
+// that compresses the checksummed data using gzip before saving it. The
+// resulting data is self-describing and can be loaded by
+// LoadAndUnmarshalChecksummed.
+func MarshalAndSaveCompressedChecksummed(path string, marshal func() ([]byte, error)) error {
+	// Marshal the message.
+	payload, err := marshal()
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal message")
+	}
+
+	// Compress the checksummed data behind the compressed header.
+	compressed := bytes.NewBuffer(make([]byte, 0, len(compressedHeader)+len(payload)/2))
+	compressed.Write(compressedHeader)
+	compressor := gzip.NewWriter(compressed)
+	if _, err := compressor.Write(checksummed(payload)); err != nil {
+		return errors.Wrap(err, "unable to compress message data")
+	} else if err := compressor.Close(); err != nil {
+		return errors.Wrap(err, "unable to finalize compressed message data")
+	}
+
+	// Write the file atomically with secure file permissions.
+	if err := filesystem.WriteFileAtomic(path, compressed.Bytes(), 0600); err != nil {
+		return errors.Wrap(err, "unable to write message data")
+	}
+
+	// Success.
+	return nil
+}
+
+// LoadAndUnmarshalChecksummedProtobuf loads checksummed data from the
+// specified path and decodes it into the specified Protocol Buffers message.
+// It has the same semantics as LoadAndUnmarshalChecksummed.
+func LoadAndUnmarshalChecksummedProtobuf(path string, message proto.Message) error {
+	return LoadAndUnmarshalChecksummed(path, func(data []byte) error {
+		return proto.Unmarshal(data, message)
+	})
+}
+
+// MarshalAndSaveChecksummedProtobuf marshals the specified Protocol Buffers
+// message and saves it to the specified path with a length and checksum.
+func MarshalAndSaveChecksummedProtobuf(path string, message proto.Message) error {
+	return MarshalAndSaveChecksummed(path, func() ([]byte, error) {
+		return proto.Marshal(message)
+	})
+}
+
+// MarshalAndSaveCompressedChecksummedProtobuf marshals the specified Protocol
+// Buffers message and saves it to the specified path with a length and
+// checksum, compressing the result.
+func MarshalAndSaveCompressedChecksummedProtobuf(path string, message proto.Message) error {
+	return MarshalAndSaveCompressedChecksummed(path, func() ([]byte, error) {
+		return proto.Marshal(message)
+	})
+}
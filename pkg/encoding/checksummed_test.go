@@ -0,0 +1,217 @@
+package encoding
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// testChecksummedMessage is the message used for checksummed encoding tests.
+var testChecksummedMessage = &url.URL{
+	Protocol: url.Protocol_SSH,
+	User:     "George",
+	Host:     "washington",
+	Port:     1776,
+	Path:     "/by/land/or/by/sea",
+}
+
+// testChecksummedFile creates a temporary file for checksummed encoding tests,
+// returning its path. The caller is responsible for removing the file.
+func testChecksummedFile(t *testing.T) string {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create the file.
+	file, err := ioutil.TempFile("", "mutagen_encoding")
+	if err != nil {
+		t.Fatal("unable to create temporary file:", err)
+	} else if err = file.Close(); err != nil {
+		t.Fatal("unable to close temporary file:", err)
+	}
+	return file.Name()
+}
+
+// TestChecksummedProtobufCycle tests a checksummed Protocol Buffers
+// marshal/save/load/unmarshal cycle.
+func TestChecksummedProtobufCycle(t *testing.T) {
+	// Create a temporary file and defer its cleanup.
+	path := testChecksummedFile(t)
+	defer os.Remove(path)
+
+	// Save and reload the message.
+	if err := MarshalAndSaveChecksummedProtobuf(path, testChecksummedMessage); err != nil {
+		t.Fatal("unable to marshal and save message:", err)
+	}
+	if data, err := ioutil.ReadFile(path); err != nil {
+		t.Fatal("unable to read checksummed data:", err)
+	} else if !IsChecksummed(data) {
+		t.Error("checksummed data not identified as checksummed")
+	}
+	decoded := &url.URL{}
+	if err := LoadAndUnmarshalChecksummedProtobuf(path, decoded); err != nil {
+		t.Fatal("unable to load and unmarshal message:", err)
+	}
+
+	// Verify that contents were preserved.
+	if !decoded.Equal(testChecksummedMessage) {
+		t.Error("decoded message did not match original:", decoded, "!=", testChecksummedMessage)
+	}
+}
+
+// TestChecksummedProtobufLegacy tests that data saved without a checksum can
+// still be loaded.
+func TestChecksummedProtobufLegacy(t *testing.T) {
+	// Create a temporary file and defer its cleanup.
+	path := testChecksummedFile(t)
+	defer os.Remove(path)
+
+	// Save the message without a checksum and reload it.
+	if err := MarshalAndSaveProtobuf(path, testChecksummedMessage); err != nil {
+		t.Fatal("unable to marshal and save message:", err)
+	}
+	if data, err := ioutil.ReadFile(path); err != nil {
+		t.Fatal("unable to read legacy data:", err)
+	} else if IsChecksummed(data) {
+		t.Error("legacy data identified as checksummed")
+	}
+	decoded := &url.URL{}
+	if err := LoadAndUnmarshalChecksummedProtobuf(path, decoded); err != nil {
+		t.Fatal("unable to load and unmarshal legacy message:", err)
+	} else if !decoded.Equal(testChecksummedMessage) {
+		t.Error("decoded message did not match original:", decoded, "!=", testChecksummedMessage)
+	}
+}
+
+// TestChecksummedProtobufCorruption tests that corrupted checksummed data is
+// detected and reported using ErrCorrupted.
+func TestChecksummedProtobufCorruption(t *testing.T) {
+	// Create a temporary file and defer its cleanup.
+	path := testChecksummedFile(t)
+	defer os.Remove(path)
+
+	// Save the message and grab its encoded form.
+	if err := MarshalAndSaveChecksummedProtobuf(path, testChecksummedMessage); err != nil {
+		t.Fatal("unable to marshal and save message:", err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal("unable to read encoded message:", err)
+	}
+
+	// Set up corruptions.
+	flipped := append([]byte{}, data...)
+	flipped[len(flipped)-3] ^= 0x40
+	corruptions := map[string][]byte{
+		"truncated header":  data[:len(checksummedHeader)+4],
+		"truncated payload": data[:len(data)-5],
+		"extended payload":  append(append([]byte{}, data...), 0xff),
+		"flipped bit":       flipped,
+		"legacy garbage":    {0xff, 0xff, 0xff},
+	}
+
+	// Verify that each corruption is detected.
+	for description, corrupted := range corruptions {
+		if err := ioutil.WriteFile(path, corrupted, 0600); err != nil {
+			t.Fatal("unable to write corrupted data:", err)
+		}
+		err := LoadAndUnmarshalChecksummedProtobuf(path, &url.URL{})
+		if err == nil {
+			t.Error("corruption not detected:", description)
+		} else if errors.Cause(err) != ErrCorrupted {
+			t.Errorf("corruption (%s) reported incorrectly: %v", description, err)
+		}
+	}
+}
+
+// TestCompressedChecksummedProtobufCycle tests a compressed checksummed
+// Protocol Buffers marshal/save/load/unmarshal cycle.
+func TestCompressedChecksummedProtobufCycle(t *testing.T) {
+	// Create a temporary file and defer its cleanup.
+	path := testChecksummedFile(t)
+	defer os.Remove(path)
+
+	// Save and reload the message.
+	if err := MarshalAndSaveCompressedChecksummedProtobuf(path, testChecksummedMessage); err != nil {
+		t.Fatal("unable to marshal and save message:", err)
+	}
+	if data, err := ioutil.ReadFile(path); err != nil {
+		t.Fatal("unable to read compressed data:", err)
+	} else if !IsCompressed(data) {
+		t.Error("compressed data not identified as compressed")
+	} else if !IsChecksummed(data) {
+		t.Error("compressed data not identified as checksummed")
+	}
+	decoded := &url.URL{}
+	if err := LoadAndUnmarshalChecksummedProtobuf(path, decoded); err != nil {
+		t.Fatal("unable to load and unmarshal message:", err)
+	}
+
+	// Verify that contents were preserved.
+	if !decoded.Equal(testChecksummedMessage) {
+		t.Error("decoded message did not match original:", decoded, "!=", testChecksummedMessage)
+	}
+
+	// Verify that uncompressed data isn't identified as compressed.
+	if err := MarshalAndSaveChecksummedProtobuf(path, testChecksummedMessage); err != nil {
+		t.Fatal("unable to marshal and save message:", err)
+	} else if data, err := ioutil.ReadFile(path); err != nil {
+		t.Fatal("unable to read checksummed data:", err)
+	} else if IsCompressed(data) {
+		t.Error("uncompressed data identified as compressed")
+	}
+}
+
+// TestCompressedChecksummedProtobufCorruption tests that corrupted compressed
+// data is detected and reported using ErrCorrupted.
+func TestCompressedChecksummedProtobufCorruption(t *testing.T) {
+	// Create a temporary file and defer its cleanup.
+	path := testChecksummedFile(t)
+	defer os.Remove(path)
+
+	// Save the message and grab its encoded form.
+	if err := MarshalAndSaveCompressedChecksummedProtobuf(path, testChecksummedMessage); err != nil {
+		t.Fatal("unable to marshal and save message:", err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal("unable to read encoded message:", err)
+	}
+
+	// Set up corruptions.
+	corruptions := map[string][]byte{
+		"truncated stream": data[:len(data)-5],
+		"invalid stream":   append(append([]byte{}, compressedHeader...), 0xff, 0xff, 0xff),
+	}
+
+	// Verify that each corruption is detected.
+	for description, corrupted := range corruptions {
+		if err := ioutil.WriteFile(path, corrupted, 0600); err != nil {
+			t.Fatal("unable to write corrupted data:", err)
+		}
+		err := LoadAndUnmarshalChecksummedProtobuf(path, &url.URL{})
+		if err == nil {
+			t.Error("corruption not detected:", description)
+		} else if errors.Cause(err) != ErrCorrupted {
+			t.Errorf("corruption (%s) reported incorrectly: %v", description, err)
+		}
+	}
+}
+
+// TestChecksummedProtobufNonExistent tests that loading non-existent data
+// yields an error that can be identified with os.IsNotExist.
+func TestChecksummedProtobufNonExistent(t *testing.T) {
+	// Create and remove a temporary file to get a non-existent path.
+	path := testChecksummedFile(t)
+	if err := os.Remove(path); err != nil {
+		t.Fatal("unable to remove temporary file:", err)
+	}
+
+	// Attempt to load from the path.
+	if err := LoadAndUnmarshalChecksummedProtobuf(path, &url.URL{}); !os.IsNotExist(err) {
+		t.Error("unexpected error for non-existent path:", err)
+	}
+}
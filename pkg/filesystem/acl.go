@@ -0,0 +1,21 @@
+package filesystem
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ACLKind identifies the kind of a POSIX ACL.
+type ACLKind uint8
+
+const (
+	// ACLKindAccess identifies the access ACL, which governs access to the
+	// entry itself.
+	ACLKindAccess ACLKind = iota
+	// ACLKindDefault identifies the default ACL, which is inherited by entries
+	// created within a directory.
+	ACLKindDefault
+)
+
+// ErrACLsUnsupported indicates that POSIX ACLs are not supported by the
+// platform or by the filesystem on which an entry resides.
+var ErrACLsUnsupported = errors.New("POSIX ACLs not supported")
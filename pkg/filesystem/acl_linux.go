@@ -0,0 +1,62 @@
+package filesystem
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// aclAttributeName returns the name of the extended attribute used to store
+// POSIX ACLs of the specified kind.
+func aclAttributeName(kind ACLKind) string {
+	if kind == ACLKindDefault {
+		return "system.posix_acl_default"
+	}
+	return "system.posix_acl_access"
+}
+
+// ReadACL reads the POSIX ACL of the specified kind for the entry at the
+// specified path, without following symbolic links. The ACL is returned in the
+// Linux extended attribute encoding. If the entry has no such ACL (which is the
+// case for access ACLs that can be represented by permission mode bits alone),
+// then it returns nil. If ACLs aren't supported by the underlying filesystem,
+// then it returns ErrACLsUnsupported.
+func ReadACL(path string, kind ACLKind) ([]byte, error) {
+	// Compute the attribute name.
+	name := aclAttributeName(kind)
+
+	// Query the attribute size, then read the attribute. The attribute may
+	// change size between these calls, in which case we retry.
+	for {
+		size, err := unix.Lgetxattr(path, name, nil)
+		if err == unix.ENODATA {
+			return nil, nil
+		} else if err == unix.ENOTSUP {
+			return nil, ErrACLsUnsupported
+		} else if err != nil {
+			return nil, err
+		} else if size == 0 {
+			return nil, nil
+		}
+		data := make([]byte, size)
+		if size, err = unix.Lgetxattr(path, name, data); err == unix.ERANGE {
+			continue
+		} else if err == unix.ENODATA {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	}
+}
+
+// WriteACL sets the POSIX ACL of the specified kind for the entry at the
+// specified path, without following symbolic links. The ACL must be provided
+// in the Linux extended attribute encoding. If ACLs aren't supported by the
+// underlying filesystem, then it returns ErrACLsUnsupported.
+func WriteACL(path string, kind ACLKind, acl []byte) error {
+	if err := unix.Lsetxattr(path, aclAttributeName(kind), acl, 0); err == unix.ENOTSUP {
+		return ErrACLsUnsupported
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+// +build !linux
+
+package filesystem
+
+// ReadACL reads the POSIX ACL of the specified kind for the entry at the
+// specified path. POSIX ACLs are only supported on Linux, so on this platform
+// it always returns ErrACLsUnsupported.
+func ReadACL(_ string, _ ACLKind) ([]byte, error) {
+	return nil, ErrACLsUnsupported
+}
+
+// WriteACL sets the POSIX ACL of the specified kind for the entry at the
+// specified path. POSIX ACLs are only supported on Linux, so on this platform
+// it always returns ErrACLsUnsupported.
+func WriteACL(_ string, _ ACLKind, _ []byte) error {
+	return ErrACLsUnsupported
+}
@@ -0,0 +1,138 @@
+package behavior
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+)
+
+const (
+	// capabilitiesFileNamePrefix is the prefix used for temporary files created
+	// by the capabilities probe. It must contain cased characters (and be in
+	// lowercase) for case sensitivity detection to work.
+	capabilitiesFileNamePrefix = filesystem.TemporaryNamePrefix + "capabilities-test-"
+)
+
+// timestampResolutions are the candidate modification time resolutions that
+// capability probing will detect, in increasing order.
+var timestampResolutions = []time.Duration{
+	time.Nanosecond,
+	10 * time.Nanosecond,
+	100 * time.Nanosecond,
+	time.Microsecond,
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+}
+
+// Capabilities describes the capabilities of a filesystem.
+type Capabilities struct {
+	// CaseSensitive indicates whether or not the filesystem treats file names
+	// differing only in case as distinct.
+	CaseSensitive bool
+	// TimestampResolution is the resolution at which the filesystem stores
+	// modification times.
+	TimestampResolution time.Duration
+	// ExtendedAttributes indicates whether or not the filesystem supports
+	// extended attributes.
+	ExtendedAttributes bool
+	// POSIXACLs indicates whether or not the filesystem supports POSIX ACLs.
+	POSIXACLs bool
+}
+
+// NamesEquivalent determines whether or not two file names refer to the same
+// file on the filesystem. It doesn't account for Unicode normalization.
+func (c *Capabilities) NamesEquivalent(first, second string) bool {
+	if c.CaseSensitive {
+		return first == second
+	}
+	return strings.EqualFold(first, second)
+}
+
+// ModificationTimesEquivalent determines whether or not two modification times
+// are equivalent at the filesystem's timestamp resolution.
+func (c *Capabilities) ModificationTimesEquivalent(first, second time.Time) bool {
+	if c.TimestampResolution <= time.Nanosecond {
+		return first.Equal(second)
+	}
+	return first.Truncate(c.TimestampResolution).Equal(second.Truncate(c.TimestampResolution))
+}
+
+// ProbeCapabilitiesByPath determines the capabilities of the filesystem on
+// which the directory at the specified path resides. The second value returned
+// by this function indicates whether or not probe files were used in
+// determining capabilities. A single probe file is used for all tests and it is
+// removed before this function returns.
+func ProbeCapabilitiesByPath(path string, probeMode ProbeMode) (*Capabilities, bool, error) {
+	// Check the filesystem probing mode and see if we can return an assumption.
+	if probeMode == ProbeMode_ProbeModeAssume {
+		return assumedCapabilities(), false, nil
+	} else if !probeMode.Supported() {
+		panic("invalid probe mode")
+	}
+
+	// Create and close a temporary file and defer its removal.
+	file, err := ioutil.TempFile(path, capabilitiesFileNamePrefix)
+	if err != nil {
+		return nil, true, errors.Wrap(err, "unable to create test file")
+	}
+	probePath := file.Name()
+	defer os.Remove(probePath)
+	if err = file.Close(); err != nil {
+		return nil, true, errors.Wrap(err, "unable to close test file")
+	}
+
+	// Create the result.
+	result := &Capabilities{}
+
+	// Determine case sensitivity by checking whether or not the file is
+	// accessible using an uppercase variant of its name.
+	uppercasePath := filepath.Join(path, strings.ToUpper(filepath.Base(probePath)))
+	if _, err := os.Lstat(uppercasePath); err == nil {
+		result.CaseSensitive = false
+	} else if os.IsNotExist(err) {
+		result.CaseSensitive = true
+	} else {
+		return nil, true, errors.Wrap(err, "unable to query test file using alternate case")
+	}
+
+	// Determine timestamp resolution by setting a modification time with
+	// non-zero digits at every sub-second position (and an odd number of
+	// seconds) and identifying the coarsest resolution at which the stored
+	// modification time is aligned.
+	probeTime := time.Unix(1000000001, 999999999)
+	if err := os.Chtimes(probePath, probeTime, probeTime); err != nil {
+		return nil, true, errors.Wrap(err, "unable to set test file modification time")
+	}
+	metadata, err := os.Lstat(probePath)
+	if err != nil {
+		return nil, true, errors.Wrap(err, "unable to query test file metadata")
+	}
+	stored := metadata.ModTime().UnixNano()
+	for _, resolution := range timestampResolutions {
+		if stored%int64(resolution) == 0 {
+			result.TimestampResolution = resolution
+		}
+	}
+
+	// Determine extended attribute support.
+	result.ExtendedAttributes = probeExtendedAttributeSupport(probePath)
+
+	// Determine POSIX ACL support. The probe file won't have an extended access
+	// ACL, so any result other than a lack of support indicates support.
+	_, err = filesystem.ReadACL(probePath, filesystem.ACLKindAccess)
+	result.POSIXACLs = err != filesystem.ErrACLsUnsupported
+
+	// Success.
+	return result, true, nil
+}
@@ -0,0 +1,17 @@
+package behavior
+
+import (
+	"time"
+)
+
+// assumedCapabilities returns the filesystem capabilities that should be
+// assumed for the platform. On Darwin, these correspond to a default APFS
+// volume.
+func assumedCapabilities() *Capabilities {
+	return &Capabilities{
+		CaseSensitive:       false,
+		TimestampResolution: time.Nanosecond,
+		ExtendedAttributes:  true,
+		POSIXACLs:           false,
+	}
+}
@@ -0,0 +1,20 @@
+// +build !darwin,!windows
+
+package behavior
+
+import (
+	"runtime"
+	"time"
+)
+
+// assumedCapabilities returns the filesystem capabilities that should be
+// assumed for the platform. POSIX ACLs are only assumed to be supported on
+// Linux, since that's the only platform on which they're implemented.
+func assumedCapabilities() *Capabilities {
+	return &Capabilities{
+		CaseSensitive:       true,
+		TimestampResolution: time.Nanosecond,
+		ExtendedAttributes:  true,
+		POSIXACLs:           runtime.GOOS == "linux",
+	}
+}
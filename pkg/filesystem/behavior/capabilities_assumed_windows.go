@@ -0,0 +1,16 @@
+package behavior
+
+import (
+	"time"
+)
+
+// assumedCapabilities returns the filesystem capabilities that should be
+// assumed for the platform. On Windows, these correspond to an NTFS volume.
+func assumedCapabilities() *Capabilities {
+	return &Capabilities{
+		CaseSensitive:       false,
+		TimestampResolution: 100 * time.Nanosecond,
+		ExtendedAttributes:  false,
+		POSIXACLs:           false,
+	}
+}
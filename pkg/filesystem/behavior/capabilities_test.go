@@ -0,0 +1,138 @@
+package behavior
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestProbeCapabilitiesByPathAssumed tests that assumed capabilities are
+// returned without the use of probe files.
+func TestProbeCapabilitiesByPathAssumed(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_capabilities")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Query assumed capabilities and ensure that they match the platform's
+	// assumptions.
+	capabilities, probed, err := ProbeCapabilitiesByPath(directory, ProbeMode_ProbeModeAssume)
+	if err != nil {
+		t.Fatal("unable to query assumed capabilities:", err)
+	} else if probed {
+		t.Error("probe files used for assumed capabilities")
+	} else if *capabilities != *assumedCapabilities() {
+		t.Error("assumed capabilities do not match expected:", *capabilities)
+	}
+}
+
+// TestProbeCapabilitiesByPathTemporaryDirectory tests capability probing on a
+// temporary directory.
+func TestProbeCapabilitiesByPathTemporaryDirectory(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_capabilities")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Probe capabilities.
+	capabilities, probed, err := ProbeCapabilitiesByPath(directory, ProbeMode_ProbeModeProbe)
+	if err != nil {
+		t.Fatal("unable to probe capabilities:", err)
+	} else if !probed {
+		t.Error("probe files not used for probing")
+	}
+
+	// Verify case sensitivity on platforms where its behavior is known for
+	// temporary directories.
+	if runtime.GOOS == "linux" && !capabilities.CaseSensitive {
+		t.Error("temporary directory not detected as case sensitive")
+	} else if runtime.GOOS == "windows" && capabilities.CaseSensitive {
+		t.Error("temporary directory detected as case sensitive")
+	}
+
+	// Verify that the detected timestamp resolution is within range.
+	if capabilities.TimestampResolution < time.Nanosecond || capabilities.TimestampResolution > 2*time.Second {
+		t.Error("timestamp resolution out of range:", capabilities.TimestampResolution)
+	}
+
+	// Verify that extended attributes aren't detected on platforms where they
+	// aren't supported.
+	if runtime.GOOS == "windows" && capabilities.ExtendedAttributes {
+		t.Error("extended attributes detected as supported on Windows")
+	}
+
+	// Verify that POSIX ACLs aren't detected on platforms where they aren't
+	// supported.
+	if runtime.GOOS != "linux" && capabilities.POSIXACLs {
+		t.Error("POSIX ACLs detected as supported on non-Linux platform")
+	}
+
+	// Verify that the probe file was removed.
+	if contents, err := ioutil.ReadDir(directory); err != nil {
+		t.Fatal("unable to read directory contents:", err)
+	} else if len(contents) != 0 {
+		t.Error("probe file not removed")
+	}
+}
+
+// TestCapabilitiesNamesEquivalent tests that name equivalence decisions follow
+// case sensitivity.
+func TestCapabilitiesNamesEquivalent(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		caseSensitive bool
+		first         string
+		second        string
+		expected      bool
+	}{
+		{true, "file", "file", true},
+		{true, "File", "file", false},
+		{true, "file", "other", false},
+		{false, "file", "file", true},
+		{false, "File", "file", true},
+		{false, "file", "other", false},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		capabilities := &Capabilities{CaseSensitive: testCase.caseSensitive}
+		if equivalent := capabilities.NamesEquivalent(testCase.first, testCase.second); equivalent != testCase.expected {
+			t.Errorf("test case %d: name equivalence does not match expected: %t != %t", i, equivalent, testCase.expected)
+		}
+	}
+}
+
+// TestCapabilitiesModificationTimesEquivalent tests that modification time
+// equivalence decisions follow timestamp resolution.
+func TestCapabilitiesModificationTimesEquivalent(t *testing.T) {
+	// Define test cases.
+	base := time.Unix(1000000000, 0)
+	testCases := []struct {
+		resolution time.Duration
+		first      time.Time
+		second     time.Time
+		expected   bool
+	}{
+		{time.Nanosecond, base, base, true},
+		{time.Nanosecond, base, base.Add(time.Nanosecond), false},
+		{time.Second, base, base.Add(500 * time.Millisecond), true},
+		{time.Second, base, base.Add(time.Second), false},
+		{2 * time.Second, base, base.Add(1500 * time.Millisecond), true},
+		{100 * time.Nanosecond, base, base.Add(99 * time.Nanosecond), true},
+		{100 * time.Nanosecond, base, base.Add(100 * time.Nanosecond), false},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		capabilities := &Capabilities{TimestampResolution: testCase.resolution}
+		if equivalent := capabilities.ModificationTimesEquivalent(testCase.first, testCase.second); equivalent != testCase.expected {
+			t.Errorf("test case %d: modification time equivalence does not match expected: %t != %t", i, equivalent, testCase.expected)
+		}
+	}
+}
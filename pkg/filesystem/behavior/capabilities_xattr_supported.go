@@ -0,0 +1,25 @@
+// +build linux darwin
+
+package behavior
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// extendedAttributeProbeName is the name of the extended attribute used to
+	// probe for extended attribute support. It resides in the user namespace,
+	// which is required on Linux for unprivileged usage.
+	extendedAttributeProbeName = "user.mutagen-probe"
+)
+
+// probeExtendedAttributeSupport determines whether or not the filesystem on
+// which the specified file resides supports extended attributes by setting
+// (and then removing) a test attribute on the file.
+func probeExtendedAttributeSupport(path string) bool {
+	if err := unix.Setxattr(path, extendedAttributeProbeName, []byte("probe"), 0); err != nil {
+		return false
+	}
+	unix.Removexattr(path, extendedAttributeProbeName)
+	return true
+}
@@ -0,0 +1,10 @@
+// +build !linux,!darwin
+
+package behavior
+
+// probeExtendedAttributeSupport determines whether or not the filesystem on
+// which the specified file resides supports extended attributes. On this
+// platform, extended attributes are not supported.
+func probeExtendedAttributeSupport(_ string) bool {
+	return false
+}
@@ -0,0 +1,14 @@
+package behavior
+
+import (
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior/internal/format"
+)
+
+// IsNetworkFilesystemByPath determines whether or not the filesystem on which
+// the specified path resides is a network filesystem. On Linux, this is
+// determined by querying the filesystem format. If the format can't be
+// determined, then the filesystem is assumed not to be a network filesystem.
+func IsNetworkFilesystemByPath(path string) bool {
+	f, err := format.QueryByPath(path)
+	return err == nil && f == format.FormatNFS
+}
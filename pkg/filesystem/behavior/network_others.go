@@ -0,0 +1,11 @@
+// +build !linux
+
+package behavior
+
+// IsNetworkFilesystemByPath determines whether or not the filesystem on which
+// the specified path resides is a network filesystem. On this platform,
+// network filesystems can't be detected, so filesystems are assumed not to be
+// network filesystems.
+func IsNetworkFilesystemByPath(_ string) bool {
+	return false
+}
@@ -0,0 +1,9 @@
+package filesystem
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrCloningUnsupported indicates that copy-on-write cloning of files isn't
+// supported on the current platform or by the underlying filesystem.
+var ErrCloningUnsupported = errors.New("file cloning not supported")
@@ -0,0 +1,41 @@
+package filesystem
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// CloneFile creates a new file at the specified target path whose content is a
+// copy-on-write clone of the specified source file. The target must not already
+// exist and must reside on the same filesystem as the source. On macOS, cloning
+// is supported by APFS. If the filesystem doesn't support cloning (or the
+// target resides on a different filesystem), then ErrCloningUnsupported is
+// returned and the target isn't created.
+func CloneFile(source *os.File, target string) error {
+	// Convert the target path to a C string.
+	targetBytes, err := unix.BytePtrFromString(target)
+	if err != nil {
+		return err
+	}
+
+	// Perform cloning using fclonefileat. The target is resolved relative to
+	// the current working directory if it's not absolute.
+	workingDirectory := unix.AT_FDCWD
+	_, _, errno := unix.Syscall6(
+		unix.SYS_FCLONEFILEAT,
+		source.Fd(),
+		uintptr(workingDirectory),
+		uintptr(unsafe.Pointer(targetBytes)),
+		0, 0, 0,
+	)
+	switch errno {
+	case 0:
+		return nil
+	case unix.ENOTSUP, unix.EXDEV, unix.ENOSYS:
+		return ErrCloningUnsupported
+	default:
+		return errno
+	}
+}
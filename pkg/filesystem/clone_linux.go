@@ -0,0 +1,55 @@
+package filesystem
+
+import (
+	"os"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// ficlone is the FICLONE ioctl request code. Its encoding depends on how the
+// architecture represents the ioctl direction.
+var ficlone = func() uintptr {
+	switch runtime.GOARCH {
+	case "mips", "mipsle", "mips64", "mips64le", "ppc64", "ppc64le", "sparc64":
+		return 0x80049409
+	default:
+		return 0x40049409
+	}
+}()
+
+// CloneFile creates a new file at the specified target path whose content is a
+// copy-on-write clone (i.e. a reflink) of the specified source file. The target
+// must not already exist and must reside on the same filesystem as the source.
+// On Linux, cloning is supported by filesystems such as Btrfs and XFS. If the
+// filesystem doesn't support cloning (or the target resides on a different
+// filesystem), then ErrCloningUnsupported is returned and the target isn't
+// created.
+func CloneFile(source *os.File, target string) error {
+	// Create the target.
+	clone, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	// Perform cloning.
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, clone.Fd(), ficlone, source.Fd())
+
+	// Close the target and remove it if cloning failed.
+	closeErr := clone.Close()
+	if errno != 0 {
+		os.Remove(target)
+		switch errno {
+		case unix.EOPNOTSUPP, unix.EXDEV, unix.EINVAL, unix.ENOTTY, unix.ENOSYS:
+			return ErrCloningUnsupported
+		default:
+			return errno
+		}
+	} else if closeErr != nil {
+		os.Remove(target)
+		return closeErr
+	}
+
+	// Success.
+	return nil
+}
@@ -0,0 +1,14 @@
+// +build !linux,!darwin
+
+package filesystem
+
+import (
+	"os"
+)
+
+// CloneFile creates a new file at the specified target path whose content is a
+// copy-on-write clone of the specified source file. Cloning isn't supported on
+// this platform, so it always returns ErrCloningUnsupported.
+func CloneFile(_ *os.File, _ string) error {
+	return ErrCloningUnsupported
+}
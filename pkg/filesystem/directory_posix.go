@@ -75,6 +75,12 @@ func (d *Directory) Close() error {
 	return d.file.Close()
 }
 
+// Sync flushes the directory's contents (i.e. the creation, removal, and
+// renaming of its entries) to durable storage.
+func (d *Directory) Sync() error {
+	return d.file.Sync()
+}
+
 // Descriptor provides access to the raw file descriptor underlying the
 // directory. It should not be used or retained beyond the point in time where
 // the Close method is called, and it should not be closed externally. Its
@@ -151,6 +157,48 @@ func (d *Directory) CreateTemporaryFile(pattern string) (string, WritableFile, e
 	return "", nil, errors.New("exhausted potential file names")
 }
 
+// CreateTemporaryHardLink creates a new hard link inside the directory to the
+// file specified by targetName within the target directory, using a temporary
+// name generated from the specified name pattern. Pattern behavior follows that
+// of CreateTemporaryFile. Both directories must reside on the same device.
+func (d *Directory) CreateTemporaryHardLink(pattern string, target *Directory, targetName string) (string, error) {
+	// Verify that the pattern and target name are valid.
+	if err := ensureValidName(pattern); err != nil {
+		return "", err
+	} else if err := ensureValidName(targetName); err != nil {
+		return "", err
+	}
+
+	// Parse the pattern into prefix and suffix components.
+	var prefix, suffix string
+	if starIndex := strings.LastIndex(pattern, "*"); starIndex != -1 {
+		prefix, suffix = pattern[:starIndex], pattern[starIndex+1:]
+	} else {
+		prefix = pattern
+	}
+
+	// Iterate until we can find a free name.
+	for i := 0; i < maximumTemporaryFileRetries; i++ {
+		// Compute the next potential name.
+		name := prefix + strconv.Itoa(i) + suffix
+
+		// Attempt to create the link. We don't follow symbolic links at the
+		// target location.
+		if err := unix.Linkat(target.descriptor, targetName, d.descriptor, name, 0); err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return "", errors.Wrap(err, "unable to create hard link")
+		}
+
+		// Success.
+		return name, nil
+	}
+
+	// At this point, we've exhausted our maximum number of retries.
+	return "", errors.New("exhausted potential file names")
+}
+
 // CreateSymbolicLink creates a new symbolic link with the specified name and
 // target inside the directory. The symbolic link is created with the default
 // system permissions (which, generally speaking, don't apply to the symbolic
@@ -350,6 +398,7 @@ func (d *Directory) ReadContentMetadata(name string) (*Metadata, error) {
 		ModificationTime: time.Unix(metadata.Mtim.Unix()),
 		DeviceID:         uint64(metadata.Dev),
 		FileID:           uint64(metadata.Ino),
+		LinkCount:        uint64(metadata.Nlink),
 	}, nil
 }
 
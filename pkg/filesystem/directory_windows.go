@@ -12,8 +12,6 @@ import (
 	"golang.org/x/sys/windows"
 
 	aclapi "github.com/hectane/go-acl/api"
-
-	osvendor "github.com/mutagen-io/mutagen/pkg/filesystem/internal/third_party/os"
 )
 
 // ensureValidName verifies that the provided name does not reference the
@@ -72,6 +70,14 @@ func (d *Directory) Close() error {
 	return nil
 }
 
+// Sync flushes the directory's contents (i.e. the creation, removal, and
+// renaming of its entries) to durable storage. On Windows, directory entry
+// modifications are journaled by the filesystem and directory handles can't be
+// flushed without write access, so this is a no-op.
+func (d *Directory) Sync() error {
+	return nil
+}
+
 // Handle provides access to the raw Windows handle underlying the directory. It
 // should not be used or retained beyond the point in time where the Close
 // method is called, and it should not be closed externally. Its usefulness is
@@ -91,7 +97,7 @@ func (d *Directory) CreateDirectory(name string) error {
 	}
 
 	// Create the directory.
-	return os.Mkdir(filepath.Join(d.file.Name(), name), 0700)
+	return os.Mkdir(d.path(name), 0700)
 }
 
 // CreateTemporaryFile creates a new temporary file using the specified name
@@ -105,8 +111,14 @@ func (d *Directory) CreateTemporaryFile(pattern string) (string, WritableFile, e
 		return "", nil, err
 	}
 
+	// Compute the parent path to use when creating the temporary file. We
+	// compute it from a representative full path (accounting for the random
+	// component that will be added to the pattern) so that the extended-length
+	// form is used if the resulting path would be long.
+	parent := filepath.Dir(d.path(pattern + temporaryNameRandomComponentPlaceholder))
+
 	// Create the temporary file using the standard io/ioutil implementation.
-	file, err := ioutil.TempFile(d.file.Name(), pattern)
+	file, err := ioutil.TempFile(parent, pattern)
 	if err != nil {
 		return "", nil, err
 	}
@@ -118,6 +130,15 @@ func (d *Directory) CreateTemporaryFile(pattern string) (string, WritableFile, e
 	return name, file, nil
 }
 
+// CreateTemporaryHardLink creates a new hard link inside the directory to the
+// file specified by targetName within the target directory, using a temporary
+// name generated from the specified name pattern. Hard link creation isn't
+// currently supported on Windows, so this method always returns
+// ErrHardLinksUnsupported.
+func (d *Directory) CreateTemporaryHardLink(pattern string, target *Directory, targetName string) (string, error) {
+	return "", ErrHardLinksUnsupported
+}
+
 // CreateSymbolicLink creates a new symbolic link with the specified name and
 // target inside the directory. The symbolic link is created with the default
 // system permissions (which, generally speaking, don't apply to the symbolic
@@ -129,7 +150,7 @@ func (d *Directory) CreateSymbolicLink(name, target string) error {
 	}
 
 	// Create the symbolic link.
-	return os.Symlink(target, filepath.Join(d.file.Name(), name))
+	return os.Symlink(target, d.path(name))
 }
 
 // SetPermissions sets the permissions on the content within the directory
@@ -147,10 +168,7 @@ func (d *Directory) SetPermissions(name string, ownership *OwnershipSpecificatio
 	}
 
 	// Compute the target path.
-	path := filepath.Join(d.file.Name(), name)
-
-	// Fix long paths.
-	path = osvendor.FixLongPath(path)
+	path := d.path(name)
 
 	// Set ownership information, if specified.
 	if ownership != nil && (ownership.ownerSID != nil || ownership.groupSID != nil) {
@@ -214,10 +232,7 @@ func (d *Directory) openHandle(name string, wantDirectory bool) (string, windows
 	}
 
 	// Compute the full path.
-	path := filepath.Join(d.file.Name(), name)
-
-	// Fix long paths.
-	path = osvendor.FixLongPath(path)
+	path := d.path(name)
 
 	// Convert the path to UTF-16.
 	path16, err := windows.UTF16PtrFromString(path)
@@ -337,7 +352,7 @@ func (d *Directory) ReadContentMetadata(name string) (*Metadata, error) {
 	}
 
 	// Query metadata.
-	metadata, err := os.Lstat(filepath.Join(d.file.Name(), name))
+	metadata, err := os.Lstat(d.path(name))
 	if err != nil {
 		return nil, err
 	}
@@ -432,7 +447,7 @@ func (d *Directory) ReadSymbolicLink(name string) (string, error) {
 	}
 
 	// Read the symbolic link.
-	return os.Readlink(filepath.Join(d.file.Name(), name))
+	return os.Readlink(d.path(name))
 }
 
 // RemoveDirectory deletes a directory with the specified name inside the
@@ -444,10 +459,7 @@ func (d *Directory) RemoveDirectory(name string) error {
 	}
 
 	// Compute the full path.
-	path := filepath.Join(d.file.Name(), name)
-
-	// Fix long paths.
-	path = osvendor.FixLongPath(path)
+	path := d.path(name)
 
 	// Convert the path to UTF-16.
 	path16, err := windows.UTF16PtrFromString(path)
@@ -467,10 +479,7 @@ func (d *Directory) RemoveFile(name string) error {
 	}
 
 	// Compute the full path.
-	path := filepath.Join(d.file.Name(), name)
-
-	// Fix long paths.
-	path = osvendor.FixLongPath(path)
+	path := d.path(name)
 
 	// Convert the path to UTF-16.
 	path16, err := windows.UTF16PtrFromString(path)
@@ -491,7 +500,7 @@ func (d *Directory) RemoveSymbolicLink(name string) error {
 	}
 
 	// Compute the full path.
-	path := filepath.Join(d.file.Name(), name)
+	path := d.path(name)
 
 	// On Windows, we need the same type-based fallback logic used in os.Remove
 	// (i.e. trying file removal first and then directory removal), so we just
@@ -518,7 +527,7 @@ func Rename(
 		if err := ensureValidName(sourceNameOrPath); err != nil {
 			return errors.Wrap(err, "source name invalid")
 		}
-		sourceNameOrPath = filepath.Join(sourceDirectory.file.Name(), sourceNameOrPath)
+		sourceNameOrPath = sourceDirectory.path(sourceNameOrPath)
 	}
 
 	// Adjust the target path if necessary.
@@ -526,7 +535,7 @@ func Rename(
 		if err := ensureValidName(targetNameOrPath); err != nil {
 			return errors.Wrap(err, "target name invalid")
 		}
-		targetNameOrPath = filepath.Join(targetDirectory.file.Name(), targetNameOrPath)
+		targetNameOrPath = targetDirectory.path(targetNameOrPath)
 	}
 
 	// Perform an atomic rename.
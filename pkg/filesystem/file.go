@@ -11,8 +11,9 @@ type ReadableFile interface {
 	io.Closer
 }
 
-// WritableFile is a union of io.Writer and io.Closer.
+// WritableFile is a union of io.Writer, io.Closer, and SyncableFile.
 type WritableFile interface {
 	io.Writer
 	io.Closer
+	SyncableFile
 }
@@ -0,0 +1,59 @@
+package filesystem
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FileFlags represents a set of BSD-style user file flags in a portable form.
+// Flag values are independent of the native flag values used by any particular
+// platform.
+type FileFlags uint32
+
+const (
+	// FileFlagNoDump indicates that a file shouldn't be dumped (the nodump
+	// flag).
+	FileFlagNoDump FileFlags = 1 << iota
+	// FileFlagImmutable indicates that a file can't be modified, renamed, or
+	// removed (the user immutable or uchg flag).
+	FileFlagImmutable
+	// FileFlagAppendOnly indicates that a file can only be appended to (the
+	// user append-only or uappnd flag).
+	FileFlagAppendOnly
+	// FileFlagHidden indicates that a file should be hidden from graphical
+	// file browsers (the hidden flag).
+	FileFlagHidden
+
+	// FileFlagsAll is the set of all portable file flags.
+	FileFlagsAll = FileFlagNoDump | FileFlagImmutable | FileFlagAppendOnly | FileFlagHidden
+	// FileFlagsLocking is the set of file flags that prevent a file from being
+	// modified or removed.
+	FileFlagsLocking = FileFlagImmutable | FileFlagAppendOnly
+)
+
+// fileFlagNames are the chflags names for each portable file flag, in order of
+// bit position.
+var fileFlagNames = []string{"nodump", "uchg", "uappnd", "hidden"}
+
+// String provides a human-readable representation of a set of file flags using
+// the names accepted by chflags.
+func (f FileFlags) String() string {
+	var names []string
+	for i, name := range fileFlagNames {
+		if f&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	if unknown := f &^ FileFlagsAll; unknown != 0 {
+		names = append(names, "unknown")
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// ErrFileFlagsUnsupported indicates that file flags are not supported by the
+// platform.
+var ErrFileFlagsUnsupported = errors.New("file flags not supported")
@@ -0,0 +1,80 @@
+// +build darwin freebsd
+
+package filesystem
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// nativeFlagNoDump is the native value of the UF_NODUMP flag.
+	nativeFlagNoDump = 0x1
+	// nativeFlagImmutable is the native value of the UF_IMMUTABLE flag.
+	nativeFlagImmutable = 0x2
+	// nativeFlagAppendOnly is the native value of the UF_APPEND flag.
+	nativeFlagAppendOnly = 0x4
+	// nativeFlagHidden is the native value of the UF_HIDDEN flag.
+	nativeFlagHidden = 0x8000
+
+	// nativeFlagsManaged is the set of native flags that correspond to
+	// portable flags.
+	nativeFlagsManaged = nativeFlagNoDump | nativeFlagImmutable | nativeFlagAppendOnly | nativeFlagHidden
+)
+
+// SupportedFileFlags is the set of file flags supported on this platform.
+const SupportedFileFlags = FileFlagsAll
+
+// nativeFileFlagMapping maps portable file flags to native file flags.
+var nativeFileFlagMapping = []struct {
+	portable FileFlags
+	native   uint32
+}{
+	{FileFlagNoDump, nativeFlagNoDump},
+	{FileFlagImmutable, nativeFlagImmutable},
+	{FileFlagAppendOnly, nativeFlagAppendOnly},
+	{FileFlagHidden, nativeFlagHidden},
+}
+
+// ReadFileFlags reads the file flags for the entry at the specified path,
+// without following symbolic links. Native flags without a portable
+// representation are ignored.
+func ReadFileFlags(path string) (FileFlags, error) {
+	var metadata unix.Stat_t
+	if err := unix.Lstat(path, &metadata); err != nil {
+		return 0, err
+	}
+	var result FileFlags
+	for _, m := range nativeFileFlagMapping {
+		if uint32(metadata.Flags)&m.native != 0 {
+			result |= m.portable
+		}
+	}
+	return result, nil
+}
+
+// WriteFileFlags sets the file flags for the entry at the specified path.
+// Native flags without a portable representation (e.g. system flags) are left
+// unmodified. Since chflags follows symbolic links, this function shouldn't be
+// used on symbolic links.
+func WriteFileFlags(path string, flags FileFlags) error {
+	// Read the existing native flags.
+	var metadata unix.Stat_t
+	if err := unix.Lstat(path, &metadata); err != nil {
+		return err
+	}
+	existing := uint32(metadata.Flags)
+
+	// Compute the new native flags, preserving unmanaged flags.
+	updated := existing &^ nativeFlagsManaged
+	for _, m := range nativeFileFlagMapping {
+		if flags&m.portable != 0 {
+			updated |= m.native
+		}
+	}
+
+	// Set the flags if they've changed.
+	if updated == existing {
+		return nil
+	}
+	return unix.Chflags(path, int(updated))
+}
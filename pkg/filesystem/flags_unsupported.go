@@ -0,0 +1,20 @@
+// +build !darwin,!freebsd
+
+package filesystem
+
+// SupportedFileFlags is the set of file flags supported on this platform.
+const SupportedFileFlags FileFlags = 0
+
+// ReadFileFlags reads the file flags for the entry at the specified path. File
+// flags aren't supported on this platform, so it always returns
+// ErrFileFlagsUnsupported.
+func ReadFileFlags(_ string) (FileFlags, error) {
+	return 0, ErrFileFlagsUnsupported
+}
+
+// WriteFileFlags sets the file flags for the entry at the specified path. File
+// flags aren't supported on this platform, so it always returns
+// ErrFileFlagsUnsupported.
+func WriteFileFlags(_ string, _ FileFlags) error {
+	return ErrFileFlagsUnsupported
+}
@@ -0,0 +1,9 @@
+package filesystem
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrHardLinksUnsupported indicates that hard links aren't supported on the
+// current platform.
+var ErrHardLinksUnsupported = errors.New("hard links not supported")
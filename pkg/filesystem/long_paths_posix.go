@@ -0,0 +1,13 @@
+// +build !windows
+
+package filesystem
+
+// PathLengthLimits returns the maximum lengths of file and directory paths (in
+// UTF-16 code units) that can be used on the current platform without relying
+// on extended-length path support. A value of 0 indicates that no limit
+// applies. On POSIX systems, all operations on synchronization root contents
+// are performed relative to directory file descriptors, so path lengths aren't
+// limited.
+func PathLengthLimits() (int, int) {
+	return 0, 0
+}
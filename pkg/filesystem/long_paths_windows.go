@@ -0,0 +1,69 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"strings"
+
+	osvendor "github.com/mutagen-io/mutagen/pkg/filesystem/internal/third_party/os"
+)
+
+const (
+	// maximumFilePathLength is the maximum length of a file path on Windows
+	// when using standard path handling. It is MAX_PATH (260) minus one
+	// character for the terminating NUL.
+	maximumFilePathLength = 259
+	// maximumDirectoryPathLength is the maximum length of a directory path on
+	// Windows when using standard path handling. Directory paths must leave
+	// room for an 8.3 file name (12 characters) within MAX_PATH, so they must
+	// be less than 248 characters long.
+	maximumDirectoryPathLength = 247
+	// temporaryNameRandomComponentPlaceholder is a placeholder with the
+	// maximum length of the random component that io/ioutil.TempFile adds to
+	// name patterns (the decimal representation of a uint32 value).
+	temporaryNameRandomComponentPlaceholder = "4294967295"
+)
+
+// PathLengthLimits returns the maximum lengths of file and directory paths (in
+// UTF-16 code units) that can be used on the current platform without relying
+// on extended-length path support. A value of 0 indicates that no limit
+// applies.
+func PathLengthLimits() (int, int) {
+	return maximumFilePathLength, maximumDirectoryPathLength
+}
+
+// fixLongPath returns the extended-length (\\?\-prefixed) form of a path if the
+// path is long enough to exceed standard path length limits. Unlike the vendored
+// os.FixLongPath implementation, UNC paths (\\server\share\...) are converted to
+// their extended-length (\\?\UNC\server\share\...) form. Paths that are already
+// in extended-length or device (\\.\) form are returned unmodified, so this
+// function can safely be applied to paths derived from already-fixed paths.
+func fixLongPath(path string) string {
+	// Leave short paths untouched.
+	if len(path) <= maximumDirectoryPathLength {
+		return path
+	}
+
+	// Leave extended-length and device paths untouched.
+	if strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\\.\`) {
+		return path
+	}
+
+	// Convert UNC paths. Cleaning the path will preserve the leading double
+	// separator, normalize separators, and resolve any . or .. elements, none
+	// of which are interpreted in extended-length paths.
+	if strings.HasPrefix(path, `\\`) || strings.HasPrefix(path, `//`) {
+		return `\\?\UNC\` + filepath.Clean(path)[2:]
+	}
+
+	// Convert other paths.
+	return osvendor.FixLongPath(path)
+}
+
+// path computes the path to the content within the directory specified by
+// name, converting it to extended-length form if it's long enough to exceed
+// standard path length limits. All path-based operations on directory contents
+// must use this method to ensure that extended-length paths are used
+// consistently for long paths.
+func (d *Directory) path(name string) string {
+	return fixLongPath(filepath.Join(d.file.Name(), name))
+}
@@ -0,0 +1,119 @@
+package filesystem
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestFixLongPath tests fixLongPath.
+func TestFixLongPath(t *testing.T) {
+	// Create long path components.
+	long := strings.Repeat("x", windowsLongPathTestingLength)
+
+	// Set up test cases.
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{`C:\short`, `C:\short`},
+		{`\\server\share\short`, `\\server\share\short`},
+		{`C:\` + long, `\\?\C:\` + long},
+		{`C:/dir/` + long, `\\?\C:\dir\` + long},
+		{`\\server\share\` + long, `\\?\UNC\server\share\` + long},
+		{`\\server\share\dir\..\` + long, `\\?\UNC\server\share\` + long},
+		{`\\?\C:\` + long, `\\?\C:\` + long},
+		{`\\?\UNC\server\share\` + long, `\\?\UNC\server\share\` + long},
+		{`\\.\pipe\` + long, `\\.\pipe\` + long},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if fixed := fixLongPath(testCase.path); fixed != testCase.expected {
+			t.Errorf("fixed path (%s) does not match expected (%s)", fixed, testCase.expected)
+		}
+	}
+}
+
+// TestDirectoryExtendedLengthPathConsistency tests that all Directory
+// operations on content nested deeply enough to exceed the standard Windows
+// path length limit (without any single component exceeding it) use
+// extended-length paths consistently.
+func TestDirectoryExtendedLengthPathConsistency(t *testing.T) {
+	// Create a temporary directory and defer its cleanup.
+	temporaryDirectoryPath, err := ioutil.TempDir("", "parent")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(temporaryDirectoryPath)
+
+	// Open the temporary directory for access.
+	closer, _, err := Open(temporaryDirectoryPath, false)
+	if err != nil {
+		t.Fatal("unable to open directory:", err)
+	}
+	directory, ok := closer.(*Directory)
+	if !ok {
+		closer.Close()
+		t.Fatal("opened object is not a directory")
+	}
+
+	// Create a chain of nested directories whose total path length exceeds
+	// MAX_PATH (by a wide margin, so that the directory itself has a long path).
+	component := strings.Repeat("n", 50)
+	for len(directory.path(component)) <= 2*maximumFilePathLength {
+		if err := directory.CreateDirectory(component); err != nil {
+			directory.Close()
+			t.Fatal("unable to create nested directory:", err)
+		}
+		child, err := directory.OpenDirectory(component)
+		directory.Close()
+		if err != nil {
+			t.Fatal("unable to open nested directory:", err)
+		}
+		directory = child
+	}
+	defer directory.Close()
+	if !strings.HasPrefix(directory.path(component), `\\?\`) {
+		t.Fatal("deeply nested path not in extended-length form")
+	}
+
+	// Create a temporary file and rename it into place.
+	temporaryName, file, err := directory.CreateTemporaryFile("temporary")
+	if err != nil {
+		t.Fatal("unable to create temporary file:", err)
+	}
+	if _, err := file.Write([]byte("content")); err != nil {
+		file.Close()
+		t.Fatal("unable to write temporary file:", err)
+	}
+	file.Close()
+	if err := Rename(directory, temporaryName, directory, "file"); err != nil {
+		t.Fatal("unable to rename temporary file:", err)
+	}
+
+	// Query metadata for the file.
+	if metadata, err := directory.ReadContentMetadata("file"); err != nil {
+		t.Error("unable to read file metadata:", err)
+	} else if metadata.Size != 7 {
+		t.Error("file metadata has incorrect size:", metadata.Size)
+	}
+
+	// Open the file.
+	if f, err := directory.OpenFile("file"); err != nil {
+		t.Error("unable to open file:", err)
+	} else {
+		f.Close()
+	}
+
+	// Set permissions on the file.
+	if err := directory.SetPermissions("file", nil, 0600); err != nil {
+		t.Error("unable to set file permissions:", err)
+	}
+
+	// Remove the file.
+	if err := directory.RemoveFile("file"); err != nil {
+		t.Error("unable to remove file:", err)
+	}
+}
@@ -20,4 +20,7 @@ type Metadata struct {
 	// FileID is the file ID for the filesystem entry. On Windows systems it is
 	// always 0.
 	FileID uint64
+	// LinkCount is the number of hard links to the filesystem entry. On Windows
+	// systems it is always 0.
+	LinkCount uint64
 }
@@ -56,6 +56,30 @@ const (
 	// directory.
 	MutagenSynchronizationStagingDirectoryName = "staging"
 
+	// MutagenSynchronizationIgnoreSetsDirectoryName is the name of the
+	// synchronization ignore set storage directory within the Mutagen data
+	// directory.
+	MutagenSynchronizationIgnoreSetsDirectoryName = "ignores"
+
+	// MutagenSynchronizationContentDirectoryName is the name of the shared
+	// synchronization content store directory within the Mutagen data
+	// directory.
+	MutagenSynchronizationContentDirectoryName = "content"
+
+	// MutagenSynchronizationUndoDirectoryName is the name of the
+	// synchronization undo storage directory within the Mutagen data
+	// directory.
+	MutagenSynchronizationUndoDirectoryName = "undo"
+
+	// MutagenSynchronizationFormatName is the name of the file recording the
+	// format version of persisted synchronization data within the Mutagen data
+	// directory.
+	MutagenSynchronizationFormatName = "synchronization.format"
+
+	// MutagenSynchronizationBackupsDirectoryName is the name of the
+	// synchronization data backup directory within the Mutagen data directory.
+	MutagenSynchronizationBackupsDirectoryName = "backups"
+
 	// MutagenForwardingDirectoryName is the name of the forwarding data
 	// directory within the Mutagen data directory.
 	MutagenForwardingDirectoryName = "forwarding"
@@ -0,0 +1,9 @@
+package filesystem
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrOpenFileDetectionUnsupported indicates that detection of files open for
+// writing is not supported on the current platform.
+var ErrOpenFileDetectionUnsupported = errors.New("open file detection not supported")
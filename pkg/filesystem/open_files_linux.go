@@ -0,0 +1,135 @@
+package filesystem
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// procDirectory is the path to the proc filesystem.
+const procDirectory = "/proc"
+
+// readDirectoryNames reads the names of the entries in the specified directory.
+func readDirectoryNames(path string) ([]string, error) {
+	directory, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer directory.Close()
+	return directory.Readdirnames(-1)
+}
+
+// descriptorOpenForWriting determines whether or not the specified file
+// descriptor information (in the format of /proc/<pid>/fdinfo/<fd>) indicates
+// that the descriptor is open for writing.
+func descriptorOpenForWriting(path string) (bool, error) {
+	// Open the file and defer its closure.
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	// Look for the flags line, which contains the open flags in octal.
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "flags:") {
+			continue
+		}
+		flags, err := strconv.ParseUint(strings.TrimSpace(line[len("flags:"):]), 8, 64)
+		if err != nil {
+			return false, errors.Wrap(err, "unable to parse descriptor flags")
+		}
+		mode := flags & unix.O_ACCMODE
+		return mode == unix.O_WRONLY || mode == unix.O_RDWR, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return false, err
+	}
+	return false, errors.New("descriptor flags not found")
+}
+
+// FilesOpenForWriting determines which files within the specified root are open
+// for writing by other processes (i.e. processes other than the current
+// process). The root path must be absolute. Files are identified by their
+// slash-separated paths relative to the root, with the root itself identified
+// by an empty path. Detection is performed by inspecting the file descriptors
+// of all processes via the proc filesystem, so only files opened by processes
+// whose descriptors are visible to the current process (typically those of the
+// same user) are detected. Processes that can't be inspected (e.g. because
+// they've exited) are ignored.
+func FilesOpenForWriting(root string) (map[string]bool, error) {
+	// Resolve any symbolic links in the root path, since the proc filesystem
+	// reports resolved paths.
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	prefix := root
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	// List processes.
+	names, err := readDirectoryNames(procDirectory)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list processes")
+	}
+
+	// Inspect the file descriptors of each process other than our own.
+	self := os.Getpid()
+	result := make(map[string]bool)
+	for _, name := range names {
+		// Ignore non-process entries and our own process.
+		if pid, err := strconv.Atoi(name); err != nil || pid == self {
+			continue
+		}
+
+		// List the process' file descriptors.
+		descriptorDirectory := filepath.Join(procDirectory, name, "fd")
+		descriptors, err := readDirectoryNames(descriptorDirectory)
+		if err != nil {
+			continue
+		}
+
+		// Check each descriptor.
+		for _, descriptor := range descriptors {
+			// Determine the descriptor's target and whether or not it lies
+			// within the root.
+			target, err := os.Readlink(filepath.Join(descriptorDirectory, descriptor))
+			if err != nil {
+				continue
+			}
+			var path string
+			if target == root {
+				path = ""
+			} else if strings.HasPrefix(target, prefix) {
+				path = target[len(prefix):]
+			} else {
+				continue
+			}
+
+			// Skip descriptors that have already been recorded or that aren't
+			// open for writing.
+			if result[path] {
+				continue
+			}
+			writing, err := descriptorOpenForWriting(filepath.Join(procDirectory, name, "fdinfo", descriptor))
+			if err != nil || !writing {
+				continue
+			}
+
+			// Record the path.
+			result[path] = true
+		}
+	}
+
+	// Success.
+	return result, nil
+}
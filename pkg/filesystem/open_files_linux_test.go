@@ -0,0 +1,71 @@
+package filesystem
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestFilesOpenForWriting tests that FilesOpenForWriting detects a file held
+// open for writing by another process and stops detecting it once it's closed.
+func TestFilesOpenForWriting(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_open_files")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create a file that's only open for reading by another process (so that
+	// it shouldn't be detected) and a file that's open for writing.
+	reading := filepath.Join(directory, "reading")
+	if err := ioutil.WriteFile(reading, []byte("content"), 0600); err != nil {
+		t.Fatal("unable to create file:", err)
+	}
+	writing := filepath.Join(directory, "writing.log")
+	file, err := os.Create(writing)
+	if err != nil {
+		t.Fatal("unable to create file:", err)
+	}
+	input, err := os.Open(reading)
+	if err != nil {
+		file.Close()
+		t.Fatal("unable to open file:", err)
+	}
+
+	// Start a process that holds the files open, then close our handles.
+	holder := exec.Command("sleep", "60")
+	holder.Stdin = input
+	holder.Stdout = file
+	err = holder.Start()
+	input.Close()
+	file.Close()
+	if err != nil {
+		t.Skip("unable to start holder process:", err)
+	}
+
+	// Verify that only the file open for writing is detected.
+	open, err := FilesOpenForWriting(directory)
+	if err != nil {
+		holder.Process.Kill()
+		holder.Wait()
+		t.Fatal("unable to detect open files:", err)
+	}
+	if !open["writing.log"] {
+		t.Error("file open for writing not detected")
+	}
+	if open["reading"] {
+		t.Error("file open for reading detected")
+	}
+
+	// Terminate the process and verify that the file is no longer detected.
+	holder.Process.Kill()
+	holder.Wait()
+	if open, err = FilesOpenForWriting(directory); err != nil {
+		t.Fatal("unable to detect open files:", err)
+	} else if len(open) != 0 {
+		t.Error("files detected after closure:", open)
+	}
+}
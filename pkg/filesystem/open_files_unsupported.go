@@ -0,0 +1,10 @@
+// +build !linux
+
+package filesystem
+
+// FilesOpenForWriting determines which files within the specified root are open
+// for writing by other processes. Detection isn't supported on this platform,
+// so it always returns ErrOpenFileDetectionUnsupported.
+func FilesOpenForWriting(_ string) (map[string]bool, error) {
+	return nil, ErrOpenFileDetectionUnsupported
+}
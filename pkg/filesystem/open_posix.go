@@ -78,6 +78,7 @@ func Open(path string, allowSymbolicLinkLeaf bool) (io.Closer, *Metadata, error)
 		ModificationTime: time.Unix(rawMetadata.Mtim.Unix()),
 		DeviceID:         uint64(rawMetadata.Dev),
 		FileID:           uint64(rawMetadata.Ino),
+		LinkCount:        uint64(rawMetadata.Nlink),
 	}
 
 	// Wrap the descriptor up in an os.File object.
@@ -8,8 +8,6 @@ import (
 	"github.com/pkg/errors"
 
 	"golang.org/x/sys/windows"
-
-	osvendor "github.com/mutagen-io/mutagen/pkg/filesystem/internal/third_party/os"
 )
 
 // Open opens a filesystem path for traversal and operations. It will return
@@ -32,7 +30,7 @@ func Open(path string, allowSymbolicLinkLeaf bool) (io.Closer, *Metadata, error)
 	}
 
 	// Fix long paths.
-	path = osvendor.FixLongPath(path)
+	path = fixLongPath(path)
 
 	// Convert the path to UTF-16.
 	path16, err := windows.UTF16PtrFromString(path)
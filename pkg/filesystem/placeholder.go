@@ -0,0 +1,17 @@
+package filesystem
+
+import (
+	"github.com/pkg/errors"
+)
+
+// placeholderAttributeName is the name of the extended attribute used to mark
+// placeholder files and record the digest of their content.
+const placeholderAttributeName = "user.mutagen.placeholder"
+
+// maximumPlaceholderDigestSize is the maximum digest size that will be read
+// from a placeholder marker.
+const maximumPlaceholderDigestSize = 128
+
+// ErrPlaceholdersUnsupported indicates that placeholder files aren't supported
+// on the current platform or by the underlying filesystem.
+var ErrPlaceholdersUnsupported = errors.New("placeholder files not supported")
@@ -0,0 +1,41 @@
+package filesystem
+
+import (
+	"github.com/pkg/errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// MarkPlaceholder marks the file at the specified path as a placeholder for
+// content with the specified digest, without following symbolic links. The
+// marker is stored in an extended attribute. If extended attributes aren't
+// supported by the underlying filesystem, then it returns
+// ErrPlaceholdersUnsupported.
+func MarkPlaceholder(path string, digest []byte) error {
+	if len(digest) == 0 || len(digest) > maximumPlaceholderDigestSize {
+		return errors.New("invalid placeholder digest size")
+	}
+	if err := unix.Lsetxattr(path, placeholderAttributeName, digest, 0); err == unix.ENOTSUP {
+		return ErrPlaceholdersUnsupported
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadPlaceholder reads the content digest recorded for the placeholder file at
+// the specified path, without following symbolic links. If the file isn't
+// marked as a placeholder (or extended attributes aren't supported by the
+// underlying filesystem), then it returns nil.
+func ReadPlaceholder(path string) ([]byte, error) {
+	digest := make([]byte, maximumPlaceholderDigestSize)
+	if size, err := unix.Lgetxattr(path, placeholderAttributeName, digest); err == unix.ENODATA || err == unix.ENOTSUP {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	} else if size == 0 {
+		return nil, nil
+	} else {
+		return digest[:size], nil
+	}
+}
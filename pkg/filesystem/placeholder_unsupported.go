@@ -0,0 +1,17 @@
+// +build !linux,!darwin
+
+package filesystem
+
+// MarkPlaceholder marks the file at the specified path as a placeholder for
+// content with the specified digest. Placeholders aren't supported on this
+// platform, so it always returns ErrPlaceholdersUnsupported.
+func MarkPlaceholder(_ string, _ []byte) error {
+	return ErrPlaceholdersUnsupported
+}
+
+// ReadPlaceholder reads the content digest recorded for the placeholder file at
+// the specified path. Placeholders aren't supported on this platform, so it
+// always returns nil.
+func ReadPlaceholder(_ string) ([]byte, error) {
+	return nil, nil
+}
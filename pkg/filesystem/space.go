@@ -0,0 +1,9 @@
+package filesystem
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrSpaceQueryUnsupported indicates that querying available space is not
+// supported on the current platform.
+var ErrSpaceQueryUnsupported = errors.New("available space query not supported")
@@ -0,0 +1,165 @@
+package filesystem
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unsafe"
+
+	"github.com/pkg/errors"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// mountInformationPath is the path to the mount information for the
+	// current process.
+	mountInformationPath = "/proc/self/mountinfo"
+
+	// quotaGetQuota is the Q_GETQUOTA quotactl command.
+	quotaGetQuota = 0x800007
+	// quotaSubcommandShift is the shift applied to quotactl commands when
+	// combining them with quota types (SUBCMDSHIFT).
+	quotaSubcommandShift = 8
+	// quotaTypeUser is the user quota type (USRQUOTA).
+	quotaTypeUser = 0
+	// quotaTypeGroup is the group quota type (GRPQUOTA).
+	quotaTypeGroup = 1
+	// quotaValidBlockLimits is the flag indicating that block limits are valid
+	// in a quota query result (QIF_BLIMITS).
+	quotaValidBlockLimits = 1
+	// quotaBlockSize is the unit used for block limits in quota query results
+	// (QIF_DQBLKSIZE).
+	quotaBlockSize = 1024
+)
+
+// quotaBlockInformation is the result of a quota query (struct if_dqblk).
+type quotaBlockInformation struct {
+	// hardLimit is the hard limit on space usage, in quota blocks.
+	hardLimit uint64
+	// softLimit is the soft limit on space usage, in quota blocks.
+	softLimit uint64
+	// currentSpace is the current space usage, in bytes.
+	currentSpace uint64
+	// inodeHardLimit is the hard limit on inode usage.
+	inodeHardLimit uint64
+	// inodeSoftLimit is the soft limit on inode usage.
+	inodeSoftLimit uint64
+	// currentInodes is the current inode usage.
+	currentInodes uint64
+	// blockGraceTime is the time limit for excessive space usage.
+	blockGraceTime uint64
+	// inodeGraceTime is the time limit for excessive inode usage.
+	inodeGraceTime uint64
+	// valid is the bitmask indicating which fields are valid.
+	valid uint32
+}
+
+// mountSourceForPath determines the source (typically a block device) of the
+// mount containing the specified path.
+func mountSourceForPath(path string) (string, error) {
+	// Determine the device identifier for the path.
+	var metadata unix.Stat_t
+	if err := unix.Stat(path, &metadata); err != nil {
+		return "", errors.Wrap(err, "unable to query path metadata")
+	}
+	device := uint64(metadata.Dev)
+	identifier := fmt.Sprintf("%d:%d", unix.Major(device), unix.Minor(device))
+
+	// Open the mount information and defer its closure.
+	file, err := os.Open(mountInformationPath)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to open mount information")
+	}
+	defer file.Close()
+
+	// Look for the mount with a matching device identifier. Each line has the
+	// form "<id> <parent> <major:minor> <root> <mount point> <options>
+	// <optional fields...> - <type> <source> <super options>".
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[2] != identifier {
+			continue
+		}
+		for f, field := range fields {
+			if field == "-" && f+2 < len(fields) {
+				return fields[f+2], nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", errors.Wrap(err, "unable to read mount information")
+	}
+	return "", errors.New("mount not found")
+}
+
+// quotaRemaining queries the quota of the specified type and identifier on the
+// specified mount source and returns the number of bytes remaining before its
+// limit is reached. It returns false if no limit applies.
+func quotaRemaining(source string, quotaType int, identifier int) (uint64, bool) {
+	// Convert the source to a C string.
+	source8, err := unix.BytePtrFromString(source)
+	if err != nil {
+		return 0, false
+	}
+
+	// Query the quota. Failure indicates that quotas aren't enabled or aren't
+	// visible to us, so we treat it as the absence of a limit.
+	var information quotaBlockInformation
+	command := (quotaGetQuota << quotaSubcommandShift) | (quotaType & 0xff)
+	if _, _, errno := unix.Syscall6(
+		unix.SYS_QUOTACTL,
+		uintptr(command),
+		uintptr(unsafe.Pointer(source8)),
+		uintptr(identifier),
+		uintptr(unsafe.Pointer(&information)),
+		0, 0,
+	); errno != 0 {
+		return 0, false
+	}
+
+	// Determine the effective limit. Writes are permitted past the soft limit
+	// until a grace period expires, so we use the hard limit if it's set.
+	if information.valid&quotaValidBlockLimits == 0 {
+		return 0, false
+	}
+	limit := information.hardLimit
+	if limit == 0 {
+		limit = information.softLimit
+	}
+	if limit == 0 {
+		return 0, false
+	}
+	limit *= quotaBlockSize
+
+	// Compute the remaining space.
+	if information.currentSpace >= limit {
+		return 0, true
+	}
+	return limit - information.currentSpace, true
+}
+
+// quotaAvailableSpace returns the number of bytes that the current user can
+// write to the filesystem containing the specified path before exceeding a
+// user or group disk quota. It returns false if no quota applies or if quotas
+// can't be queried.
+func quotaAvailableSpace(path string) (uint64, bool) {
+	// Determine the mount source.
+	source, err := mountSourceForPath(path)
+	if err != nil {
+		return 0, false
+	}
+
+	// Query user and group quotas and use the most restrictive.
+	var result uint64
+	var limited bool
+	if remaining, ok := quotaRemaining(source, quotaTypeUser, os.Getuid()); ok {
+		result, limited = remaining, true
+	}
+	if remaining, ok := quotaRemaining(source, quotaTypeGroup, os.Getgid()); ok && (!limited || remaining < result) {
+		result, limited = remaining, true
+	}
+	return result, limited
+}
@@ -0,0 +1,11 @@
+// +build darwin
+
+package filesystem
+
+// quotaAvailableSpace returns the number of bytes that the current user can
+// write to the filesystem containing the specified path before exceeding a
+// disk quota. Quota queries aren't supported on this platform, so it always
+// indicates that no quota applies.
+func quotaAvailableSpace(_ string) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,29 @@
+// +build darwin linux
+
+package filesystem
+
+import (
+	"github.com/pkg/errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// AvailableSpace returns the number of bytes available to the current user on
+// the filesystem containing the specified path. Where supported, disk quotas
+// applying to the current user are taken into account.
+func AvailableSpace(path string) (uint64, error) {
+	// Query the space available to unprivileged users.
+	var metadata unix.Statfs_t
+	if err := unix.Statfs(path, &metadata); err != nil {
+		return 0, errors.Wrap(err, "unable to query filesystem metadata")
+	}
+	available := uint64(metadata.Bavail) * uint64(metadata.Bsize)
+
+	// Restrict the result to any applicable quota.
+	if quota, ok := quotaAvailableSpace(path); ok && quota < available {
+		available = quota
+	}
+
+	// Done.
+	return available, nil
+}
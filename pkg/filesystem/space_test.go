@@ -0,0 +1,45 @@
+package filesystem
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestAvailableSpace tests that AvailableSpace reports available space for a
+// temporary directory.
+func TestAvailableSpace(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_filesystem")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Query the available space.
+	available, err := AvailableSpace(directory)
+	if err == ErrSpaceQueryUnsupported {
+		t.Skip("available space query not supported on this platform")
+	} else if err != nil {
+		t.Fatal("unable to query available space:", err)
+	} else if available == 0 {
+		t.Error("no space available in temporary directory")
+	}
+}
+
+// TestAvailableSpaceNonExistent tests that AvailableSpace fails for a path that
+// doesn't exist.
+func TestAvailableSpaceNonExistent(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_filesystem")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Query a non-existent path.
+	if _, err := AvailableSpace(filepath.Join(directory, "nonexistent")); err == nil {
+		t.Error("available space query succeeded for non-existent path")
+	}
+}
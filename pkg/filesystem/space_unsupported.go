@@ -0,0 +1,10 @@
+// +build !darwin,!linux,!windows
+
+package filesystem
+
+// AvailableSpace returns the number of bytes available to the current user on
+// the filesystem containing the specified path. Querying isn't supported on
+// this platform, so it always returns ErrSpaceQueryUnsupported.
+func AvailableSpace(_ string) (uint64, error) {
+	return 0, ErrSpaceQueryUnsupported
+}
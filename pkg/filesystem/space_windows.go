@@ -0,0 +1,27 @@
+package filesystem
+
+import (
+	"github.com/pkg/errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// AvailableSpace returns the number of bytes available to the current user on
+// the volume containing the specified path. The result accounts for any disk
+// quotas applying to the current user.
+func AvailableSpace(path string) (uint64, error) {
+	// Convert the path to UTF-16.
+	path16, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to convert path to UTF-16")
+	}
+
+	// Query the space available to the caller.
+	var available, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(path16, &available, &total, &free); err != nil {
+		return 0, errors.Wrap(err, "unable to query volume space")
+	}
+
+	// Done.
+	return available, nil
+}
@@ -0,0 +1,350 @@
+package filesystem
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SparseBlockSize is the granularity at which runs of zero bytes are
+	// converted to holes when writing sparse files. Runs of zero bytes that
+	// don't cover a full, aligned block of this size are written as data. It's
+	// chosen to match the most common filesystem block size.
+	SparseBlockSize = 1 << 12
+)
+
+// zeroBlock is a block of zero bytes used for hole detection and zero filling.
+var zeroBlock [SparseBlockSize]byte
+
+// sparseReader is an io.Reader implementation that reads a file using hole
+// detection, synthesizing zero bytes for holes rather than reading them.
+type sparseReader struct {
+	// file is the underlying file.
+	file ReadableFile
+	// offset is the current logical offset within the file.
+	offset int64
+	// holeEnd is the end offset of the hole containing the current offset. If
+	// the current offset isn't within a hole, then it will be less than or
+	// equal to the current offset.
+	holeEnd int64
+	// dataEnd is the end offset of the data region containing (or following
+	// the hole containing) the current offset.
+	dataEnd int64
+}
+
+// NewSparseReader creates a reader for the specified file that uses hole
+// detection (where supported by the platform and filesystem) to avoid reading
+// holes, instead synthesizing the corresponding zero bytes. The file must be
+// positioned at its start and shouldn't be read or seeked by other code while
+// the resulting reader is in use. If hole detection isn't supported, then the
+// file itself is returned.
+func NewSparseReader(file ReadableFile) io.Reader {
+	// Check whether or not hole detection is supported. Probing for the first
+	// hole will fail if it's not.
+	if !holeDetectionSupported {
+		return file
+	} else if _, err := file.Seek(0, seekHole); err != nil {
+		return file
+	}
+
+	// Create the reader. There's no need to reset the file offset since the
+	// reader will position the file before reading any data.
+	return &sparseReader{file: file}
+}
+
+// HasHoles indicates whether or not the specified file, whose size must be
+// specified, contains holes. It returns false if hole detection isn't supported
+// by the platform or filesystem. The file offset may be modified, but the file
+// will be positioned at its start if true is returned.
+func HasHoles(file ReadableFile, size int64) bool {
+	// Check whether or not hole detection is supported and locate the first
+	// hole. Every file has an implicit hole at its end, so only a hole before
+	// that indicates sparseness.
+	if !holeDetectionSupported {
+		return false
+	}
+	hole, err := file.Seek(0, seekHole)
+	if err != nil {
+		return false
+	}
+
+	// Reset the file offset. If that fails, then claim that there are no holes
+	// since callers will then only use positional reads.
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return false
+	}
+
+	// Done.
+	return hole < size
+}
+
+// locate identifies the hole (if any) and data region at the current offset.
+func (r *sparseReader) locate() error {
+	// Find the next data region. If there's no data at or beyond the current
+	// offset, then the remainder of the file (if any) is a hole.
+	data, err := r.file.Seek(r.offset, seekData)
+	if err != nil {
+		size, err := r.file.Seek(0, io.SeekEnd)
+		if err != nil {
+			return errors.Wrap(err, "unable to determine file size")
+		}
+		r.holeEnd = size
+		r.dataEnd = size
+		return nil
+	}
+
+	// Find the end of the data region.
+	hole, err := r.file.Seek(data, seekHole)
+	if err != nil {
+		return errors.Wrap(err, "unable to locate end of data region")
+	}
+
+	// Position the file at the start of the data region.
+	if _, err := r.file.Seek(data, io.SeekStart); err != nil {
+		return errors.Wrap(err, "unable to seek to data region")
+	}
+
+	// Record the regions.
+	r.holeEnd = data
+	r.dataEnd = hole
+	return nil
+}
+
+// Read implements io.Reader.Read.
+func (r *sparseReader) Read(buffer []byte) (int, error) {
+	// Handle the trivial case of an empty buffer.
+	if len(buffer) == 0 {
+		return 0, nil
+	}
+
+	// If we've exhausted the current regions, then locate the next ones. If
+	// that fails to yield any new content, then we're at the end of the file.
+	if r.offset >= r.holeEnd && r.offset >= r.dataEnd {
+		if err := r.locate(); err != nil {
+			return 0, err
+		} else if r.offset >= r.holeEnd && r.offset >= r.dataEnd {
+			return 0, io.EOF
+		}
+	}
+
+	// If we're in a hole, then synthesize zero bytes.
+	if r.offset < r.holeEnd {
+		count := len(buffer)
+		if remaining := r.holeEnd - r.offset; int64(count) > remaining {
+			count = int(remaining)
+		}
+		for i := range buffer[:count] {
+			buffer[i] = 0
+		}
+		r.offset += int64(count)
+		return count, nil
+	}
+
+	// Otherwise read from the data region. If the file is truncated while
+	// we're reading, then we'll hit the end of the file early, in which case
+	// we adjust the data region so that we'll report the end of the file.
+	count := len(buffer)
+	if remaining := r.dataEnd - r.offset; int64(count) > remaining {
+		count = int(remaining)
+	}
+	n, err := r.file.Read(buffer[:count])
+	r.offset += int64(n)
+	if err == io.EOF {
+		r.dataEnd = r.offset
+		if n > 0 {
+			err = nil
+		}
+	}
+	return n, err
+}
+
+// sparseFile is the interface required to write sparse files.
+type sparseFile interface {
+	io.Writer
+	io.Seeker
+	// Truncate changes the size of the file.
+	Truncate(size int64) error
+}
+
+// SparseWriter is an io.Writer implementation that writes to a file while
+// seeking over (rather than writing) full, aligned blocks of zero bytes, thereby
+// creating holes on filesystems that support sparse files. On filesystems that
+// don't support sparse files, the skipped regions are zero-filled by the
+// filesystem, so the resulting content is identical. If the underlying writer
+// doesn't support seeking and truncation, then all data is written directly.
+// Data for partially written blocks is buffered, so the writer's Finish method
+// must be invoked once writing is complete.
+type SparseWriter struct {
+	// writer is the underlying writer.
+	writer io.Writer
+	// file is the underlying writer as a sparseFile. It's nil if the writer
+	// doesn't support sparse writing.
+	file sparseFile
+	// block is the buffered content of the current block, which hasn't yet been
+	// written to the underlying file. Its length is always less than
+	// SparseBlockSize outside of method calls.
+	block []byte
+	// pending is the length of the hole preceding the current block that
+	// hasn't yet been seeked over.
+	pending int64
+}
+
+// NewSparseWriter creates a new sparse writer that writes to the specified
+// writer, which should be positioned at the start of an empty file.
+func NewSparseWriter(writer io.Writer) *SparseWriter {
+	file, _ := writer.(sparseFile)
+	return &SparseWriter{
+		writer: writer,
+		file:   file,
+		block:  make([]byte, 0, SparseBlockSize),
+	}
+}
+
+// write writes data to the underlying file after seeking over any pending hole.
+func (w *SparseWriter) write(data []byte) error {
+	if w.pending > 0 {
+		if _, err := w.file.Seek(w.pending, io.SeekCurrent); err != nil {
+			return err
+		}
+		w.pending = 0
+	}
+	_, err := w.file.Write(data)
+	return err
+}
+
+// commit commits the buffered block once it's full, converting it to a hole if
+// it consists entirely of zero bytes.
+func (w *SparseWriter) commit() error {
+	if len(w.block) < SparseBlockSize {
+		return nil
+	} else if bytes.Equal(w.block, zeroBlock[:]) {
+		w.pending += SparseBlockSize
+	} else if err := w.write(w.block); err != nil {
+		return err
+	}
+	w.block = w.block[:0]
+	return nil
+}
+
+// Write implements io.Writer.Write.
+func (w *SparseWriter) Write(data []byte) (int, error) {
+	// If sparse writing isn't supported, then write directly.
+	if w.file == nil {
+		return w.writer.Write(data)
+	}
+
+	// Loop until all data has been written, buffered, or skipped.
+	var consumed int
+	for len(data) > 0 {
+		// If we're partway through a block or don't have a full block of data,
+		// then buffer as much data as we can and commit the block if full.
+		if len(w.block) > 0 || len(data) < SparseBlockSize {
+			size := SparseBlockSize - len(w.block)
+			if size > len(data) {
+				size = len(data)
+			}
+			w.block = append(w.block, data[:size]...)
+			if err := w.commit(); err != nil {
+				return consumed, err
+			}
+			consumed += size
+			data = data[size:]
+			continue
+		}
+
+		// Otherwise we're at a block boundary and have at least one full block
+		// of data. If the first block consists entirely of zero bytes, then add
+		// it to the pending hole. Otherwise, write as many consecutive blocks
+		// as possible that don't consist entirely of zero bytes.
+		var length int
+		for length+SparseBlockSize <= len(data) {
+			if bytes.Equal(data[length:length+SparseBlockSize], zeroBlock[:]) {
+				break
+			}
+			length += SparseBlockSize
+		}
+		if length > 0 {
+			if err := w.write(data[:length]); err != nil {
+				return consumed, err
+			}
+		} else {
+			length = SparseBlockSize
+			w.pending += SparseBlockSize
+		}
+		consumed += length
+		data = data[length:]
+	}
+
+	// Success.
+	return consumed, nil
+}
+
+// WriteHole writes the specified number of zero bytes, creating a hole if
+// possible.
+func (w *SparseWriter) WriteHole(length uint64) error {
+	// If sparse writing is supported, then fill out any partial block with
+	// zero bytes, add any full blocks to the pending hole, and buffer any
+	// remaining zero bytes.
+	if w.file != nil {
+		if len(w.block) > 0 {
+			size := uint64(SparseBlockSize - len(w.block))
+			if size > length {
+				size = length
+			}
+			w.block = append(w.block, zeroBlock[:size]...)
+			if err := w.commit(); err != nil {
+				return err
+			}
+			length -= size
+		}
+		w.pending += int64(length - length%SparseBlockSize)
+		w.block = append(w.block, zeroBlock[:length%SparseBlockSize]...)
+		return nil
+	}
+
+	// Otherwise write zero bytes directly.
+	for length > 0 {
+		size := uint64(SparseBlockSize)
+		if size > length {
+			size = length
+		}
+		if _, err := w.writer.Write(zeroBlock[:size]); err != nil {
+			return err
+		}
+		length -= size
+	}
+	return nil
+}
+
+// Finish completes writing by writing any buffered data and extending the file
+// to cover any trailing hole.
+func (w *SparseWriter) Finish() error {
+	// If sparse writing isn't supported, then there's nothing to complete.
+	if w.file == nil {
+		return nil
+	}
+
+	// Write any buffered data.
+	if len(w.block) > 0 {
+		if err := w.write(w.block); err != nil {
+			return errors.Wrap(err, "unable to write buffered data")
+		}
+		w.block = w.block[:0]
+	}
+
+	// Extend the file to cover any trailing hole.
+	if w.pending > 0 {
+		offset, err := w.file.Seek(w.pending, io.SeekCurrent)
+		if err != nil {
+			return errors.Wrap(err, "unable to seek over trailing hole")
+		} else if err = w.file.Truncate(offset); err != nil {
+			return errors.Wrap(err, "unable to set file size")
+		}
+		w.pending = 0
+	}
+
+	// Success.
+	return nil
+}
@@ -0,0 +1,13 @@
+package filesystem
+
+const (
+	// holeDetectionSupported indicates whether or not the platform supports
+	// hole detection via seeking.
+	holeDetectionSupported = true
+	// seekHole is the seek mode used to locate the next hole in a file. On
+	// macOS, the values of SEEK_HOLE and SEEK_DATA are reversed relative to
+	// other platforms.
+	seekHole = 3
+	// seekData is the seek mode used to locate the next data region in a file.
+	seekData = 4
+)
@@ -0,0 +1,17 @@
+// +build !windows,!darwin
+
+package filesystem
+
+const (
+	// holeDetectionSupported indicates whether or not the platform supports
+	// hole detection via seeking. Platforms that don't recognize the hole
+	// detection seek modes will reject them, in which case hole detection will
+	// be disabled at runtime.
+	holeDetectionSupported = true
+	// seekData is the seek mode used to locate the next data region in a file
+	// (SEEK_DATA).
+	seekData = 3
+	// seekHole is the seek mode used to locate the next hole in a file
+	// (SEEK_HOLE).
+	seekHole = 4
+)
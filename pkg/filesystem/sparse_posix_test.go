@@ -0,0 +1,245 @@
+// +build !windows
+
+package filesystem
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+const (
+	// testSparseFileHoleSize is the size of holes created in test sparse files.
+	testSparseFileHoleSize = 1 << 20
+)
+
+// testSparseFileData is the data written between holes in test sparse files.
+var testSparseFileData = []byte("sparse file data")
+
+// testSparseFileContent returns the expected content of a test sparse file.
+func testSparseFileContent() []byte {
+	var content []byte
+	content = append(content, make([]byte, testSparseFileHoleSize)...)
+	content = append(content, testSparseFileData...)
+	content = append(content, make([]byte, testSparseFileHoleSize)...)
+	return content
+}
+
+// allocatedSize returns the number of bytes allocated on disk for a file.
+func allocatedSize(t *testing.T, path string) int64 {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Query file metadata.
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal("unable to query file metadata:", err)
+	}
+
+	// Extract the allocated block count, which is always in 512-byte units.
+	return info.Sys().(*syscall.Stat_t).Blocks * 512
+}
+
+// createTestSparseFile creates a test sparse file at the specified path,
+// skipping the test if the underlying filesystem doesn't create holes.
+func createTestSparseFile(t *testing.T, path string) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create the file with a leading hole, data, and a trailing hole.
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal("unable to create file:", err)
+	}
+	if _, err := file.WriteAt(testSparseFileData, testSparseFileHoleSize); err != nil {
+		file.Close()
+		t.Fatal("unable to write file data:", err)
+	} else if err = file.Truncate(2*testSparseFileHoleSize + int64(len(testSparseFileData))); err != nil {
+		file.Close()
+		t.Fatal("unable to extend file:", err)
+	} else if err = file.Close(); err != nil {
+		t.Fatal("unable to close file:", err)
+	}
+
+	// Verify that the filesystem created holes.
+	if allocatedSize(t, path) >= testSparseFileHoleSize {
+		t.Skip("filesystem does not support sparse files")
+	}
+}
+
+// TestSparseReader tests that NewSparseReader correctly reads sparse files.
+func TestSparseReader(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_filesystem_sparse")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create a sparse file.
+	path := filepath.Join(directory, "sparse")
+	createTestSparseFile(t, path)
+
+	// Open the file and defer its closure.
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal("unable to open file:", err)
+	}
+	defer file.Close()
+
+	// Read the file and verify its content.
+	if content, err := ioutil.ReadAll(NewSparseReader(file)); err != nil {
+		t.Fatal("unable to read file:", err)
+	} else if !bytes.Equal(content, testSparseFileContent()) {
+		t.Error("sparse file content does not match expected")
+	}
+}
+
+// TestSparseWriter tests that SparseWriter preserves content while creating
+// holes.
+func TestSparseWriter(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_filesystem_sparse")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Verify that the filesystem supports sparse files.
+	createTestSparseFile(t, filepath.Join(directory, "probe"))
+
+	// Set up test cases. Each test case writes the same content in a different
+	// manner.
+	expected := testSparseFileContent()
+	testCases := []struct {
+		name  string
+		write func(*SparseWriter) error
+	}{
+		{"single", func(w *SparseWriter) error {
+			_, err := w.Write(expected)
+			return err
+		}},
+		{"unaligned", func(w *SparseWriter) error {
+			for data := expected; len(data) > 0; {
+				size := 1000
+				if size > len(data) {
+					size = len(data)
+				}
+				if _, err := w.Write(data[:size]); err != nil {
+					return err
+				}
+				data = data[size:]
+			}
+			return nil
+		}},
+		{"holes", func(w *SparseWriter) error {
+			if err := w.WriteHole(testSparseFileHoleSize); err != nil {
+				return err
+			} else if _, err = w.Write(testSparseFileData); err != nil {
+				return err
+			}
+			return w.WriteHole(testSparseFileHoleSize)
+		}},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		// Create the file.
+		path := filepath.Join(directory, testCase.name)
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("unable to create file for %s test: %v", testCase.name, err)
+		}
+
+		// Write the content.
+		writer := NewSparseWriter(file)
+		if err := testCase.write(writer); err != nil {
+			file.Close()
+			t.Fatalf("unable to write content for %s test: %v", testCase.name, err)
+		} else if err = writer.Finish(); err != nil {
+			file.Close()
+			t.Fatalf("unable to finish writing for %s test: %v", testCase.name, err)
+		} else if err = file.Close(); err != nil {
+			t.Fatalf("unable to close file for %s test: %v", testCase.name, err)
+		}
+
+		// Verify the content.
+		if content, err := ioutil.ReadFile(path); err != nil {
+			t.Fatalf("unable to read file for %s test: %v", testCase.name, err)
+		} else if !bytes.Equal(content, expected) {
+			t.Errorf("file content does not match expected for %s test", testCase.name)
+		}
+
+		// Verify that holes were created.
+		if allocated := allocatedSize(t, path); allocated >= testSparseFileHoleSize {
+			t.Errorf("holes not preserved for %s test: %d bytes allocated", testCase.name, allocated)
+		}
+	}
+}
+
+// TestSparseWriterNonSeekable tests that SparseWriter writes zero bytes
+// directly to writers that don't support seeking.
+func TestSparseWriterNonSeekable(t *testing.T) {
+	// Write content with a hole to a buffer.
+	buffer := &bytes.Buffer{}
+	writer := NewSparseWriter(buffer)
+	if err := writer.WriteHole(testSparseFileHoleSize); err != nil {
+		t.Fatal("unable to write hole:", err)
+	} else if _, err = writer.Write(testSparseFileData); err != nil {
+		t.Fatal("unable to write data:", err)
+	} else if err = writer.WriteHole(testSparseFileHoleSize); err != nil {
+		t.Fatal("unable to write hole:", err)
+	} else if err = writer.Finish(); err != nil {
+		t.Fatal("unable to finish writing:", err)
+	}
+
+	// Verify the content.
+	if !bytes.Equal(buffer.Bytes(), testSparseFileContent()) {
+		t.Error("buffer content does not match expected")
+	}
+}
+
+// TestHasHoles tests that HasHoles distinguishes sparse files from dense files.
+func TestHasHoles(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_filesystem_sparse")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create a dense file and verify that it isn't reported as having holes.
+	densePath := filepath.Join(directory, "dense")
+	if err := ioutil.WriteFile(densePath, testSparseFileContent(), 0600); err != nil {
+		t.Fatal("unable to create dense file:", err)
+	}
+	dense, err := os.Open(densePath)
+	if err != nil {
+		t.Fatal("unable to open dense file:", err)
+	}
+	defer dense.Close()
+	if HasHoles(dense, int64(len(testSparseFileContent()))) {
+		t.Error("dense file reported as having holes")
+	}
+
+	// Create a sparse file and verify that it's reported as having holes and
+	// that it's positioned at its start afterward.
+	sparsePath := filepath.Join(directory, "sparse")
+	createTestSparseFile(t, sparsePath)
+	sparse, err := os.Open(sparsePath)
+	if err != nil {
+		t.Fatal("unable to open sparse file:", err)
+	}
+	defer sparse.Close()
+	if !HasHoles(sparse, int64(len(testSparseFileContent()))) {
+		t.Error("sparse file not reported as having holes")
+	} else if offset, err := sparse.Seek(0, io.SeekCurrent); err != nil {
+		t.Fatal("unable to query file offset:", err)
+	} else if offset != 0 {
+		t.Error("sparse file not positioned at start:", offset)
+	}
+}
@@ -0,0 +1,12 @@
+package filesystem
+
+const (
+	// holeDetectionSupported indicates whether or not the platform supports
+	// hole detection via seeking. Windows only supports hole detection through
+	// device I/O control operations, which we don't currently use.
+	holeDetectionSupported = false
+	// seekData is unused on Windows.
+	seekData = 0
+	// seekHole is unused on Windows.
+	seekHole = 0
+)
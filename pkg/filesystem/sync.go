@@ -0,0 +1,37 @@
+package filesystem
+
+// SyncableFile is the interface for files whose contents can be flushed to
+// durable storage. It is implemented by *os.File.
+type SyncableFile interface {
+	// Sync flushes the file's contents and metadata to durable storage.
+	Sync() error
+}
+
+// Syncer is the interface used to flush filesystem modifications to durable
+// storage. It exists primarily to allow flushing behavior to be observed (or
+// overridden) in tests.
+type Syncer interface {
+	// SyncFile flushes the contents and metadata of a file to durable storage.
+	SyncFile(file SyncableFile) error
+	// SyncDirectory flushes the contents of a directory (i.e. the creation,
+	// removal, and renaming of its entries) to durable storage.
+	SyncDirectory(directory *Directory) error
+}
+
+// systemSyncer is the Syncer implementation that uses the operating system's
+// flushing facilities.
+type systemSyncer struct{}
+
+// SyncFile implements Syncer.SyncFile.
+func (systemSyncer) SyncFile(file SyncableFile) error {
+	return file.Sync()
+}
+
+// SyncDirectory implements Syncer.SyncDirectory.
+func (systemSyncer) SyncDirectory(directory *Directory) error {
+	return directory.Sync()
+}
+
+// SystemSyncer is the Syncer that uses the operating system's flushing
+// facilities.
+var SystemSyncer Syncer = systemSyncer{}
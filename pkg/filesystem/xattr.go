@@ -0,0 +1,9 @@
+package filesystem
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrExtendedAttributesUnsupported indicates that extended attributes are not
+// supported by the platform or by the filesystem on which an entry resides.
+var ErrExtendedAttributesUnsupported = errors.New("extended attributes not supported")
@@ -0,0 +1,92 @@
+package filesystem
+
+import (
+	"bytes"
+
+	"golang.org/x/sys/unix"
+)
+
+// ListExtendedAttributes lists the names of the extended attributes for the
+// entry at the specified path, without following symbolic links. If extended
+// attributes aren't supported by the underlying filesystem, then it returns
+// ErrExtendedAttributesUnsupported.
+func ListExtendedAttributes(path string) ([]string, error) {
+	// Query the list size, then read the list. The list may change size
+	// between these calls, in which case we retry.
+	for {
+		size, err := unix.Llistxattr(path, nil)
+		if err == unix.ENOTSUP {
+			return nil, ErrExtendedAttributesUnsupported
+		} else if err != nil {
+			return nil, err
+		} else if size == 0 {
+			return nil, nil
+		}
+		data := make([]byte, size)
+		if size, err = unix.Llistxattr(path, data); err == unix.ERANGE {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, name := range bytes.Split(data[:size], []byte{0}) {
+			if len(name) > 0 {
+				names = append(names, string(name))
+			}
+		}
+		return names, nil
+	}
+}
+
+// ReadExtendedAttribute reads the value of the specified extended attribute for
+// the entry at the specified path, without following symbolic links. If
+// extended attributes aren't supported by the underlying filesystem, then it
+// returns ErrExtendedAttributesUnsupported.
+func ReadExtendedAttribute(path, name string) ([]byte, error) {
+	// Query the attribute size, then read the attribute. The attribute may
+	// change size between these calls, in which case we retry.
+	for {
+		size, err := unix.Lgetxattr(path, name, nil)
+		if err == unix.ENOTSUP {
+			return nil, ErrExtendedAttributesUnsupported
+		} else if err != nil {
+			return nil, err
+		} else if size == 0 {
+			return nil, nil
+		}
+		data := make([]byte, size)
+		if size, err = unix.Lgetxattr(path, name, data); err == unix.ERANGE {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	}
+}
+
+// WriteExtendedAttribute sets the value of the specified extended attribute for
+// the entry at the specified path, without following symbolic links. If
+// extended attributes aren't supported by the underlying filesystem, then it
+// returns ErrExtendedAttributesUnsupported.
+func WriteExtendedAttribute(path, name string, value []byte) error {
+	if err := unix.Lsetxattr(path, name, value, 0); err == unix.ENOTSUP {
+		return ErrExtendedAttributesUnsupported
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RemoveExtendedAttribute removes the specified extended attribute from the
+// entry at the specified path, without following symbolic links. It isn't an
+// error if the attribute doesn't exist. If extended attributes aren't supported
+// by the underlying filesystem, then it returns
+// ErrExtendedAttributesUnsupported.
+func RemoveExtendedAttribute(path, name string) error {
+	if err := unix.Lremovexattr(path, name); err == unix.ENOTSUP {
+		return ErrExtendedAttributesUnsupported
+	} else if err != nil && err != unix.ENOATTR {
+		return err
+	}
+	return nil
+}
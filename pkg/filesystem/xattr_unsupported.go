@@ -0,0 +1,35 @@
+// +build !darwin
+
+package filesystem
+
+// ListExtendedAttributes lists the names of the extended attributes for the
+// entry at the specified path. Extended attributes are currently only supported
+// on macOS, so on this platform it always returns
+// ErrExtendedAttributesUnsupported.
+func ListExtendedAttributes(_ string) ([]string, error) {
+	return nil, ErrExtendedAttributesUnsupported
+}
+
+// ReadExtendedAttribute reads the value of the specified extended attribute for
+// the entry at the specified path. Extended attributes are currently only
+// supported on macOS, so on this platform it always returns
+// ErrExtendedAttributesUnsupported.
+func ReadExtendedAttribute(_, _ string) ([]byte, error) {
+	return nil, ErrExtendedAttributesUnsupported
+}
+
+// WriteExtendedAttribute sets the value of the specified extended attribute for
+// the entry at the specified path. Extended attributes are currently only
+// supported on macOS, so on this platform it always returns
+// ErrExtendedAttributesUnsupported.
+func WriteExtendedAttribute(_, _ string, _ []byte) error {
+	return ErrExtendedAttributesUnsupported
+}
+
+// RemoveExtendedAttribute removes the specified extended attribute from the
+// entry at the specified path. Extended attributes are currently only supported
+// on macOS, so on this platform it always returns
+// ErrExtendedAttributesUnsupported.
+func RemoveExtendedAttribute(_, _ string) error {
+	return ErrExtendedAttributesUnsupported
+}
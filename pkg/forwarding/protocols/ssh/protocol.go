@@ -11,6 +11,7 @@ import (
 	"github.com/mutagen-io/mutagen/pkg/forwarding"
 	"github.com/mutagen-io/mutagen/pkg/forwarding/endpoint/remote"
 	"github.com/mutagen-io/mutagen/pkg/logging"
+	sshpkg "github.com/mutagen-io/mutagen/pkg/ssh"
 	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
 	forwardingurlpkg "github.com/mutagen-io/mutagen/pkg/url/forwarding"
 )
@@ -61,7 +62,7 @@ func (p *protocolHandler) Connect(
 	}
 
 	// Create an SSH agent transport.
-	transport, err := ssh.NewTransport(url.User, url.Host, uint16(url.Port), prompter)
+	transport, err := ssh.NewTransport(url.User, url.Host, &sshpkg.Options{Port: url.Port}, prompter, false, nil, "")
 	if err != nil {
 		return nil, fmt.Errorf("unable to create SSH transport: %w", err)
 	}
@@ -0,0 +1,3 @@
+// Package wsl provides the Windows Subsystem for Linux forwarding session
+// protocol implementation.
+package wsl
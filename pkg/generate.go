@@ -10,10 +10,11 @@
 //go:generate protoc --plugin=./protoc-gen-go -I. --go_out=paths=source_relative,plugins=grpc:. service/prompting/prompting.proto
 //go:generate protoc --plugin=./protoc-gen-go -I. --go_out=paths=source_relative,plugins=grpc:. service/synchronization/synchronization.proto
 //go:generate protoc --plugin=./protoc-gen-go -I. --go_out=paths=source_relative,plugins=grpc:. service/tunneling/tunneling.proto
-//go:generate protoc --plugin=./protoc-gen-go -I. --go_out=paths=source_relative:. synchronization/configuration.proto synchronization/scan_mode.proto synchronization/session.proto synchronization/stage_mode.proto synchronization/state.proto synchronization/version.proto synchronization/watch_mode.proto
-//go:generate protoc --plugin=./protoc-gen-go -I. --go_out=paths=source_relative:. synchronization/core/archive.proto synchronization/core/cache.proto synchronization/core/change.proto synchronization/core/conflict.proto synchronization/core/entry.proto synchronization/core/ignore_vcs_mode.proto synchronization/core/mode.proto synchronization/core/problem.proto synchronization/core/symlink_mode.proto
+//go:generate protoc --plugin=./protoc-gen-go -I. --go_out=paths=source_relative:. ssh/options.proto
+//go:generate protoc --plugin=./protoc-gen-go -I. --go_out=paths=source_relative:. synchronization/activity.proto synchronization/archive_compression_mode.proto synchronization/configuration.proto synchronization/content_store_mode.proto synchronization/delta_transfer_mode.proto synchronization/host_verification_mode.proto synchronization/modification_handling_mode.proto synchronization/problem_event.proto synchronization/scan_mode.proto synchronization/session.proto synchronization/staged.proto synchronization/stage_mode.proto synchronization/state.proto synchronization/transfer_priority.proto synchronization/version.proto synchronization/watch_mode.proto
+//go:generate protoc --plugin=./protoc-gen-go -I. --go_out=paths=source_relative:. synchronization/core/acl.proto synchronization/core/acl_mode.proto synchronization/core/archive.proto synchronization/core/broken_symlink_mode.proto synchronization/core/cache.proto synchronization/core/change.proto synchronization/core/conflict.proto synchronization/core/content_type.proto synchronization/core/decision.proto synchronization/core/durability_mode.proto synchronization/core/entry.proto synchronization/core/filename_encoding.proto synchronization/core/ignore_vcs_mode.proto synchronization/core/invalid_name_mode.proto synchronization/core/line_ending_style.proto synchronization/core/long_path_mode.proto synchronization/core/macos_metadata.proto synchronization/core/mode.proto synchronization/core/problem.proto synchronization/core/snapshot_diff.proto synchronization/core/symlink_mode.proto
 //go:generate protoc --plugin=./protoc-gen-go -I. --go_out=paths=source_relative:. synchronization/endpoint/remote/protocol.proto
-//go:generate protoc --plugin=./protoc-gen-go -I. --go_out=paths=source_relative:. synchronization/rsync/engine.proto synchronization/rsync/receive.proto synchronization/rsync/transmission.proto
+//go:generate protoc --plugin=./protoc-gen-go -I. --go_out=paths=source_relative:. synchronization/rsync/efficiency.proto synchronization/rsync/engine.proto synchronization/rsync/receive.proto synchronization/rsync/transmission.proto
 //go:generate protoc --plugin=./protoc-gen-go -I. --go_out=paths=source_relative:. tunneling/configuration.proto tunneling/protocol.proto tunneling/state.proto tunneling/tunnel.proto tunneling/version.proto
 //go:generate protoc --plugin=./protoc-gen-go -I. --go_out=paths=source_relative:. url/url.proto
 //go:generate rm ./protoc-gen-go
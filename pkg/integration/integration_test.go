@@ -72,7 +72,7 @@ func testMainInternal(m *testing.M) (int, error) {
 
 	// Create a session manager and defer its shutdown. Note that we assign to
 	// the global instance here.
-	synchronizationManager, err = synchronization.NewManager(logging.RootLogger.Sublogger("sync"))
+	synchronizationManager, err = synchronization.NewManager(logging.RootLogger.Sublogger("sync"), nil, 0)
 	if err != nil {
 		return -1, errors.Wrap(err, "unable to create synchronization session manager")
 	}
@@ -56,6 +56,7 @@ func testSessionLifecycle(ctx context.Context, prompter string, alpha, beta *url
 	sessionId, err := synchronizationManager.Create(
 		ctx,
 		alpha, beta,
+		nil,
 		configuration,
 		&synchronization.Configuration{},
 		&synchronization.Configuration{},
@@ -93,7 +94,7 @@ func testSessionLifecycle(ctx context.Context, prompter string, alpha, beta *url
 	}
 
 	// Resume the session.
-	if err := synchronizationManager.Resume(ctx, selection, ""); err != nil {
+	if err := synchronizationManager.Resume(ctx, selection, "", synchronization.ObservationModeChangeNone); err != nil {
 		return errors.Wrap(err, "unable to resume session")
 	}
 
@@ -104,7 +105,7 @@ func testSessionLifecycle(ctx context.Context, prompter string, alpha, beta *url
 	}
 
 	// Attempt an additional resume (this should be a no-op).
-	if err := synchronizationManager.Resume(ctx, selection, ""); err != nil {
+	if err := synchronizationManager.Resume(ctx, selection, "", synchronization.ObservationModeChangeNone); err != nil {
 		return errors.Wrap(err, "unable to perform additional resume")
 	}
 
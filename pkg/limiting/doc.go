@@ -0,0 +1,3 @@
+// Package limiting provides facilities for limiting the concurrency of
+// connection establishment.
+package limiting
@@ -0,0 +1,41 @@
+package limiting
+
+import (
+	"sync"
+)
+
+var (
+	// establishmentLimiterLock serializes access to establishmentLimiter.
+	establishmentLimiterLock sync.RWMutex
+	// establishmentLimiter is the process-wide connection establishment
+	// limiter.
+	establishmentLimiter *Limiter
+)
+
+// ConfigureEstablishmentLimiter sets the global and per-host limits for the
+// process-wide connection establishment limiter. A limit of 0 indicates that no
+// limit should be applied. It should be invoked before any connections are
+// established, since connections already holding slots aren't tracked by the
+// new limiter.
+func ConfigureEstablishmentLimiter(global, perHost int) {
+	// Create the limiter if necessary.
+	var limiter *Limiter
+	if global > 0 || perHost > 0 {
+		limiter = NewLimiter(global, perHost)
+	}
+
+	// Store the limiter.
+	establishmentLimiterLock.Lock()
+	establishmentLimiter = limiter
+	establishmentLimiterLock.Unlock()
+}
+
+// EstablishmentLimiter returns the process-wide limiter for connection
+// establishment, as configured by ConfigureEstablishmentLimiter. If no limits
+// have been configured, then the returned limiter will be nil (which is valid
+// and imposes no limits).
+func EstablishmentLimiter() *Limiter {
+	establishmentLimiterLock.RLock()
+	defer establishmentLimiterLock.RUnlock()
+	return establishmentLimiter
+}
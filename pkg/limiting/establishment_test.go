@@ -0,0 +1,31 @@
+package limiting
+
+import (
+	"testing"
+)
+
+// TestConfigureEstablishmentLimiter tests ConfigureEstablishmentLimiter and
+// EstablishmentLimiter.
+func TestConfigureEstablishmentLimiter(t *testing.T) {
+	// Defer restoration of the original limiter.
+	original := EstablishmentLimiter()
+	defer func() {
+		establishmentLimiterLock.Lock()
+		establishmentLimiter = original
+		establishmentLimiterLock.Unlock()
+	}()
+
+	// Configure limits and verify that they're applied.
+	ConfigureEstablishmentLimiter(4, 2)
+	if limiter := EstablishmentLimiter(); limiter == nil {
+		t.Fatal("limiter not created with limits specified")
+	} else if limiter.global != 4 || limiter.perHost != 2 {
+		t.Error("limiter created with incorrect limits:", limiter.global, limiter.perHost)
+	}
+
+	// Clear limits and verify that no limiter is used.
+	ConfigureEstablishmentLimiter(0, 0)
+	if EstablishmentLimiter() != nil {
+		t.Error("limiter created without limits specified")
+	}
+}
@@ -0,0 +1,156 @@
+package limiting
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// waiter represents a queued acquisition request.
+type waiter struct {
+	// host is the host for which the slot is being requested.
+	host string
+	// ready is closed when the slot has been granted.
+	ready chan struct{}
+}
+
+// Limiter limits the number of concurrent operations, both globally and on a
+// per-host basis. Acquisition requests that can't be satisfied immediately are
+// queued and granted in the order in which they were made, with the exception
+// that requests blocked by a per-host limit don't prevent requests for other
+// hosts from being granted. It is safe for concurrent usage.
+type Limiter struct {
+	// global is the global concurrency limit. A value of 0 indicates no limit.
+	global int
+	// perHost is the per-host concurrency limit. A value of 0 indicates no
+	// limit.
+	perHost int
+	// lock serializes access to the fields below.
+	lock sync.Mutex
+	// active is the number of operations currently holding a slot.
+	active int
+	// activeByHost is the number of operations currently holding a slot for
+	// each host. Hosts with no active operations are removed.
+	activeByHost map[string]int
+	// queue is the queue of waiting acquisition requests, stored as *waiter
+	// values.
+	queue *list.List
+}
+
+// NewLimiter creates a new limiter with the specified global and per-host
+// concurrency limits. A limit of 0 indicates that no limit should be applied.
+func NewLimiter(global, perHost int) *Limiter {
+	return &Limiter{
+		global:       global,
+		perHost:      perHost,
+		activeByHost: make(map[string]int),
+		queue:        list.New(),
+	}
+}
+
+// available indicates whether or not a slot for the specified host is
+// available. The caller must hold the limiter lock.
+func (l *Limiter) available(host string) bool {
+	if l.global > 0 && l.active >= l.global {
+		return false
+	} else if l.perHost > 0 && l.activeByHost[host] >= l.perHost {
+		return false
+	}
+	return true
+}
+
+// grant records the allocation of a slot to the specified host. The caller
+// must hold the limiter lock.
+func (l *Limiter) grant(host string) {
+	l.active++
+	l.activeByHost[host]++
+}
+
+// Acquire acquires a slot for an operation against the specified host,
+// blocking until either a slot is available or the context is cancelled. On
+// success, it returns a function that must be invoked exactly once to release
+// the slot. A nil limiter imposes no limits.
+func (l *Limiter) Acquire(ctx context.Context, host string) (func(), error) {
+	// If there's no limiter, then there's nothing to acquire.
+	if l == nil {
+		return func() {}, nil
+	}
+
+	// Lock the limiter.
+	l.lock.Lock()
+
+	// If a slot is available, then grant it immediately. We don't need to
+	// check for queued waiters that should be granted first, because any
+	// waiter for which a slot is available is granted one when slots are
+	// released, so a slot being available for us implies that any remaining
+	// waiters are blocked by limits that we aren't subject to.
+	if l.available(host) {
+		l.grant(host)
+		l.lock.Unlock()
+		return l.releaser(host), nil
+	}
+
+	// Otherwise queue a waiter.
+	w := &waiter{host: host, ready: make(chan struct{})}
+	element := l.queue.PushBack(w)
+	l.lock.Unlock()
+
+	// Wait for the slot to be granted or for cancellation.
+	select {
+	case <-w.ready:
+		return l.releaser(host), nil
+	case <-ctx.Done():
+		// Remove the waiter from the queue. If the slot was granted between
+		// cancellation and our acquisition of the lock, then the waiter will
+		// have already been removed, in which case we need to release the slot
+		// that we were granted.
+		l.lock.Lock()
+		select {
+		case <-w.ready:
+			l.release(host)
+		default:
+			l.queue.Remove(element)
+		}
+		l.lock.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// releaser creates a release function for a slot held for the specified host.
+func (l *Limiter) releaser(host string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.lock.Lock()
+			l.release(host)
+			l.lock.Unlock()
+		})
+	}
+}
+
+// release releases a slot held for the specified host and grants slots to any
+// waiters that can now proceed, in queue order. The caller must hold the
+// limiter lock.
+func (l *Limiter) release(host string) {
+	// Release the slot.
+	l.active--
+	l.activeByHost[host]--
+	if l.activeByHost[host] == 0 {
+		delete(l.activeByHost, host)
+	}
+
+	// Grant slots to waiters in order.
+	for e := l.queue.Front(); e != nil; {
+		next := e.Next()
+		w := e.Value.(*waiter)
+		if l.available(w.host) {
+			l.grant(w.host)
+			l.queue.Remove(e)
+			close(w.ready)
+		}
+		if l.global > 0 && l.active >= l.global {
+			break
+		}
+		e = next
+	}
+}
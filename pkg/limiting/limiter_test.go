@@ -0,0 +1,220 @@
+package limiting
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrencyTracker tracks the current and maximum concurrency of operations.
+type concurrencyTracker struct {
+	// lock serializes access to the fields below.
+	lock sync.Mutex
+	// current is the current concurrency.
+	current int
+	// maximum is the maximum observed concurrency.
+	maximum int
+	// currentByHost is the current concurrency for each host.
+	currentByHost map[string]int
+	// maximumByHost is the maximum observed concurrency for each host.
+	maximumByHost map[string]int
+}
+
+// newConcurrencyTracker creates a new concurrency tracker.
+func newConcurrencyTracker() *concurrencyTracker {
+	return &concurrencyTracker{
+		currentByHost: make(map[string]int),
+		maximumByHost: make(map[string]int),
+	}
+}
+
+// operate simulates an operation against the specified host.
+func (t *concurrencyTracker) operate(host string) {
+	t.lock.Lock()
+	t.current++
+	if t.current > t.maximum {
+		t.maximum = t.current
+	}
+	t.currentByHost[host]++
+	if t.currentByHost[host] > t.maximumByHost[host] {
+		t.maximumByHost[host] = t.currentByHost[host]
+	}
+	t.lock.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	t.lock.Lock()
+	t.current--
+	t.currentByHost[host]--
+	t.lock.Unlock()
+}
+
+// runLimited runs the specified number of operations against each of the
+// specified hosts concurrently using the limiter.
+func runLimited(t *testing.T, limiter *Limiter, hosts []string, count int) *concurrencyTracker {
+	tracker := newConcurrencyTracker()
+	var group sync.WaitGroup
+	for _, host := range hosts {
+		for i := 0; i < count; i++ {
+			group.Add(1)
+			go func(host string) {
+				defer group.Done()
+				release, err := limiter.Acquire(context.Background(), host)
+				if err != nil {
+					t.Error("unable to acquire slot:", err)
+					return
+				}
+				tracker.operate(host)
+				release()
+			}(host)
+		}
+	}
+	group.Wait()
+	return tracker
+}
+
+// TestLimiterGlobal tests that a limiter enforces its global limit.
+func TestLimiterGlobal(t *testing.T) {
+	tracker := runLimited(t, NewLimiter(3, 0), []string{"a", "b", "c"}, 10)
+	if tracker.maximum > 3 {
+		t.Error("global concurrency exceeded limit:", tracker.maximum)
+	}
+}
+
+// TestLimiterPerHost tests that a limiter enforces its per-host limit without
+// preventing concurrency across hosts.
+func TestLimiterPerHost(t *testing.T) {
+	tracker := runLimited(t, NewLimiter(0, 2), []string{"a", "b", "c"}, 10)
+	for host, maximum := range tracker.maximumByHost {
+		if maximum > 2 {
+			t.Error("per-host concurrency exceeded limit for host", host, ":", maximum)
+		}
+	}
+	if tracker.maximum > 6 {
+		t.Error("global concurrency exceeded combined per-host limits:", tracker.maximum)
+	}
+}
+
+// TestLimiterNil tests that a nil limiter imposes no limits.
+func TestLimiterNil(t *testing.T) {
+	var limiter *Limiter
+	release, err := limiter.Acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatal("unable to acquire slot from nil limiter:", err)
+	}
+	release()
+}
+
+// TestLimiterFairOrdering tests that queued acquisitions are granted in the
+// order in which they were made.
+func TestLimiterFairOrdering(t *testing.T) {
+	// Create a limiter and acquire its only slot.
+	limiter := NewLimiter(1, 0)
+	release, err := limiter.Acquire(context.Background(), "host")
+	if err != nil {
+		t.Fatal("unable to acquire initial slot:", err)
+	}
+
+	// Queue waiters one at a time, ensuring that each is queued before the
+	// next is started.
+	const waiters = 5
+	order := make(chan int, waiters)
+	for i := 0; i < waiters; i++ {
+		go func(i int) {
+			release, err := limiter.Acquire(context.Background(), "host")
+			if err != nil {
+				t.Error("unable to acquire slot:", err)
+				return
+			}
+			order <- i
+			release()
+		}(i)
+		for {
+			limiter.lock.Lock()
+			queued := limiter.queue.Len()
+			limiter.lock.Unlock()
+			if queued == i+1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// Release the initial slot and verify the order of acquisition.
+	release()
+	for i := 0; i < waiters; i++ {
+		if o := <-order; o != i {
+			t.Error("acquisition order incorrect:", o, "!=", i)
+		}
+	}
+}
+
+// TestLimiterPerHostDoesNotBlockOtherHosts tests that a waiter blocked on a
+// per-host limit doesn't block waiters for other hosts.
+func TestLimiterPerHostDoesNotBlockOtherHosts(t *testing.T) {
+	// Create a limiter and saturate the per-host limit for one host.
+	limiter := NewLimiter(0, 1)
+	release, err := limiter.Acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatal("unable to acquire initial slot:", err)
+	}
+	defer release()
+
+	// Start a blocked waiter for the same host.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go limiter.Acquire(ctx, "a")
+
+	// Verify that a different host can still acquire a slot.
+	otherCtx, otherCancel := context.WithTimeout(context.Background(), time.Second)
+	defer otherCancel()
+	if otherRelease, err := limiter.Acquire(otherCtx, "b"); err != nil {
+		t.Fatal("unable to acquire slot for other host:", err)
+	} else {
+		otherRelease()
+	}
+}
+
+// TestLimiterCancellation tests that queued acquisitions can be cancelled and
+// that cancellation doesn't leak slots.
+func TestLimiterCancellation(t *testing.T) {
+	// Create a limiter and acquire its only slot.
+	limiter := NewLimiter(1, 0)
+	release, err := limiter.Acquire(context.Background(), "host")
+	if err != nil {
+		t.Fatal("unable to acquire initial slot:", err)
+	}
+
+	// Attempt to acquire a slot with a context that will be cancelled.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(ctx, "host"); err != context.DeadlineExceeded {
+		t.Fatal("unexpected acquisition result:", err)
+	}
+
+	// Verify that the cancelled waiter was removed from the queue.
+	limiter.lock.Lock()
+	queued := limiter.queue.Len()
+	limiter.lock.Unlock()
+	if queued != 0 {
+		t.Error("cancelled waiter remains queued")
+	}
+
+	// Release the initial slot (twice, to verify idempotency) and verify that
+	// a new slot can be acquired.
+	release()
+	release()
+	if release, err := limiter.Acquire(context.Background(), "host"); err != nil {
+		t.Fatal("unable to acquire slot after cancellation:", err)
+	} else {
+		release()
+	}
+
+	// Verify that no slots remain held.
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+	if limiter.active != 0 {
+		t.Error("slots leaked:", limiter.active)
+	}
+}
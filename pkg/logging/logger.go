@@ -40,6 +40,12 @@ func (l *Logger) Sublogger(name string) *Logger {
 	}
 }
 
+// Enabled indicates whether or not the logger will output messages at the
+// specified level. It always returns false for a nil logger.
+func (l *Logger) Enabled(level Level) bool {
+	return l != nil && currentLevel >= level
+}
+
 // output is the shared internal logging method.
 func (l *Logger) output(level, line string) {
 	// Compute the formatted line.
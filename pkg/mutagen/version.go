@@ -37,6 +37,10 @@ func init() {
 	}
 }
 
+// ErrVersionMismatch indicates that a version handshake failed because the
+// remote version is not compatible with the local version.
+var ErrVersionMismatch = errors.New("version mismatch")
+
 // versionBytes is a type that can be used to send and receive version
 // information over the wire.
 type versionBytes [12]byte
@@ -99,7 +103,7 @@ func ClientVersionHandshake(connection net.Conn) error {
 	// implementation from that version.
 	versionMatch := serverMajor == VersionMajor && serverMinor == VersionMinor
 	if !versionMatch {
-		return errors.New("version mismatch")
+		return ErrVersionMismatch
 	}
 
 	// Success.
@@ -127,7 +131,7 @@ func ServerVersionHandshake(connection net.Conn) error {
 	// equal at the minor release level.
 	versionMatch := clientMajor == VersionMajor && clientMinor == VersionMinor
 	if !versionMatch {
-		return errors.New("version mismatch")
+		return ErrVersionMismatch
 	}
 
 	// Success.
@@ -0,0 +1,3 @@
+// Package notification provides facilities for delivering session event
+// notifications to external services via webhooks.
+package notification
@@ -0,0 +1,326 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/mutagen"
+)
+
+const (
+	// DefaultDebounceInterval is the default minimum interval between
+	// notifications of the same kind for the same session.
+	DefaultDebounceInterval = time.Minute
+	// deliveryQueueSize is the number of events that can be queued for
+	// delivery to each webhook before new events are dropped.
+	deliveryQueueSize = 64
+	// deliveryTimeout is the maximum amount of time allowed for a single
+	// delivery attempt.
+	deliveryTimeout = 10 * time.Second
+	// defaultMaximumDeliveryAttempts is the default maximum number of delivery
+	// attempts for each event.
+	defaultMaximumDeliveryAttempts = 5
+	// defaultInitialRetryDelay is the default delay before the first delivery
+	// retry. The delay doubles with each subsequent retry.
+	defaultInitialRetryDelay = time.Second
+	// defaultMaximumRetryDelay is the default maximum delay between delivery
+	// retries.
+	defaultMaximumRetryDelay = 30 * time.Second
+)
+
+// Kind represents the kind of condition that an event reports.
+type Kind string
+
+const (
+	// KindConflicted indicates that a session has entered a conflicted state.
+	KindConflicted Kind = "conflicted"
+	// KindErrored indicates that a session has entered an errored state.
+	KindErrored Kind = "errored"
+)
+
+// Event is the JSON payload delivered to webhooks.
+type Event struct {
+	// Time is the time at which the condition was observed.
+	Time time.Time `json:"time"`
+	// Kind is the kind of condition being reported.
+	Kind Kind `json:"kind"`
+	// Session is the session identifier.
+	Session string `json:"session"`
+	// Name is the session name, if any.
+	Name string `json:"name,omitempty"`
+	// Labels are the session labels, if any.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Alpha is the formatted alpha endpoint URL.
+	Alpha string `json:"alpha"`
+	// Beta is the formatted beta endpoint URL.
+	Beta string `json:"beta"`
+	// Status is a human-readable description of the session status.
+	Status string `json:"status"`
+	// Conflicts is the number of conflicts currently reported for the session.
+	Conflicts uint64 `json:"conflicts"`
+	// Error is the last error reported by the session, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// debounceKey is the key type used to track the most recent notification for
+// a particular session and event kind.
+type debounceKey struct {
+	// session is the session identifier.
+	session string
+	// kind is the event kind.
+	kind Kind
+}
+
+// Notifier delivers events to a set of webhooks. Deliveries are performed
+// asynchronously, with retries and exponential backoff, and failed deliveries
+// are logged rather than reported to the caller. Events of the same kind for
+// the same session are debounced to avoid alerts for flapping conditions. A
+// nil Notifier is valid and discards all events. It is safe for concurrent
+// usage.
+type Notifier struct {
+	// logger is the underlying logger.
+	logger *logging.Logger
+	// client is the HTTP client used for deliveries.
+	client *http.Client
+	// debounce is the minimum interval between notifications of the same kind
+	// for the same session.
+	debounce time.Duration
+	// maximumDeliveryAttempts is the maximum number of delivery attempts for
+	// each event.
+	maximumDeliveryAttempts int
+	// initialRetryDelay is the delay before the first delivery retry.
+	initialRetryDelay time.Duration
+	// maximumRetryDelay is the maximum delay between delivery retries.
+	maximumRetryDelay time.Duration
+	// ctx is the context regulating delivery. It is cancelled on shutdown.
+	ctx context.Context
+	// cancel cancels ctx.
+	cancel context.CancelFunc
+	// queues are the delivery queues for each webhook.
+	queues []chan *Event
+	// done tracks the completion of delivery Goroutines.
+	done sync.WaitGroup
+	// lastNotifiedLock guards lastNotified.
+	lastNotifiedLock sync.Mutex
+	// lastNotified records the time of the most recent notification for each
+	// session and event kind.
+	lastNotified map[debounceKey]time.Time
+}
+
+// NewNotifier creates a new notifier that delivers events to the specified
+// webhooks, suppressing events of the same kind for the same session that occur
+// within the specified debounce interval. If the debounce interval is 0, then
+// DefaultDebounceInterval is used. If no webhooks are specified, then the
+// resulting notifier will be nil.
+func NewNotifier(logger *logging.Logger, webhooks []Webhook, debounce time.Duration) (*Notifier, error) {
+	// If there aren't any webhooks, then there's no need for a notifier.
+	if len(webhooks) == 0 {
+		return nil, nil
+	}
+
+	// Validate webhooks.
+	for w := range webhooks {
+		if err := webhooks[w].EnsureValid(); err != nil {
+			return nil, errors.Wrapf(err, "invalid webhook at index %d", w)
+		}
+	}
+
+	// Set the debounce interval.
+	if debounce == 0 {
+		debounce = DefaultDebounceInterval
+	} else if debounce < 0 {
+		return nil, errors.New("negative debounce interval")
+	}
+
+	// Create a cancellable context to regulate delivery.
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Create the notifier.
+	notifier := &Notifier{
+		logger:                  logger,
+		client:                  &http.Client{Timeout: deliveryTimeout},
+		debounce:                debounce,
+		maximumDeliveryAttempts: defaultMaximumDeliveryAttempts,
+		initialRetryDelay:       defaultInitialRetryDelay,
+		maximumRetryDelay:       defaultMaximumRetryDelay,
+		ctx:                     ctx,
+		cancel:                  cancel,
+		lastNotified:            make(map[debounceKey]time.Time),
+	}
+
+	// Start a delivery Goroutine for each webhook.
+	for _, webhook := range webhooks {
+		queue := make(chan *Event, deliveryQueueSize)
+		notifier.queues = append(notifier.queues, queue)
+		notifier.done.Add(1)
+		go notifier.run(webhook, queue)
+	}
+
+	// Success.
+	return notifier, nil
+}
+
+// run implements the delivery loop for a single webhook.
+func (n *Notifier) run(webhook Webhook, queue <-chan *Event) {
+	// Signal completion when we're done.
+	defer n.done.Done()
+
+	// Loop and deliver events until cancelled.
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case event := <-queue:
+			if err := n.deliver(webhook, event); err != nil {
+				n.logger.Warningf("Unable to deliver %s notification for session %s to %s: %v",
+					event.Kind, event.Session, webhook.URL, err,
+				)
+			}
+		}
+	}
+}
+
+// deliver attempts to deliver a single event to a webhook, retrying with
+// exponential backoff in the event of transient failures.
+func (n *Notifier) deliver(webhook Webhook, event *Event) error {
+	// Encode the payload.
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "unable to encode payload")
+	}
+
+	// Perform delivery attempts.
+	delay := n.initialRetryDelay
+	for attempt := 1; ; attempt++ {
+		// Attempt delivery. If it succeeds or fails permanently, then we're
+		// done.
+		retry, err := n.attempt(webhook, payload)
+		if err == nil {
+			return nil
+		} else if !retry {
+			return err
+		} else if attempt >= n.maximumDeliveryAttempts {
+			return errors.Wrapf(err, "delivery failed after %d attempts", attempt)
+		}
+		n.logger.Debugf("Notification delivery attempt %d to %s failed: %v", attempt, webhook.URL, err)
+
+		// Wait before retrying.
+		select {
+		case <-n.ctx.Done():
+			return errors.New("delivery cancelled")
+		case <-time.After(delay):
+		}
+
+		// Update the delay.
+		delay *= 2
+		if delay > n.maximumRetryDelay {
+			delay = n.maximumRetryDelay
+		}
+	}
+}
+
+// attempt performs a single delivery attempt. It returns whether or not a
+// failed attempt should be retried.
+func (n *Notifier) attempt(webhook Webhook, payload []byte) (bool, error) {
+	// Create the request.
+	request, err := http.NewRequestWithContext(n.ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return false, errors.Wrap(err, "unable to create request")
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "mutagen/"+mutagen.Version)
+	if webhook.Authorization != "" {
+		request.Header.Set("Authorization", webhook.Authorization)
+	}
+
+	// Perform the request. We drain the response body so that the underlying
+	// connection can be reused.
+	response, err := n.client.Do(request)
+	if err != nil {
+		return true, errors.Wrap(err, "unable to perform request")
+	}
+	io.Copy(ioutil.Discard, response.Body)
+	response.Body.Close()
+
+	// Check the response status. We treat server errors, timeouts, and rate
+	// limiting as transient and all other failures as permanent.
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return false, nil
+	}
+	transient := response.StatusCode >= 500 ||
+		response.StatusCode == http.StatusRequestTimeout ||
+		response.StatusCode == http.StatusTooManyRequests
+	return transient, errors.Errorf("webhook responded with status %d", response.StatusCode)
+}
+
+// Notify queues an event for delivery to all webhooks. It doesn't block. The
+// event is discarded if an event of the same kind was delivered for the same
+// session within the debounce interval or if a webhook's delivery queue is
+// full. The event should not be modified after it is passed to Notify.
+func (n *Notifier) Notify(event *Event) {
+	// If the notifier is nil or has been shut down, then there's nothing to
+	// do.
+	if n == nil || n.ctx.Err() != nil {
+		return
+	}
+
+	// Check whether or not the event should be debounced. If not, then record
+	// its notification time.
+	key := debounceKey{event.Session, event.Kind}
+	n.lastNotifiedLock.Lock()
+	if last, ok := n.lastNotified[key]; ok && event.Time.Sub(last) < n.debounce {
+		n.lastNotifiedLock.Unlock()
+		n.logger.Debugf("Suppressing %s notification for session %s", event.Kind, event.Session)
+		return
+	}
+	n.lastNotified[key] = event.Time
+	n.lastNotifiedLock.Unlock()
+
+	// Queue the event for delivery.
+	for _, queue := range n.queues {
+		select {
+		case queue <- event:
+		default:
+			n.logger.Warningf("Notification queue full, dropping %s notification for session %s",
+				event.Kind, event.Session,
+			)
+		}
+	}
+}
+
+// Forget discards debouncing information for the specified session. It should
+// be called when a session is terminated.
+func (n *Notifier) Forget(session string) {
+	// If the notifier is nil, then there's nothing to do.
+	if n == nil {
+		return
+	}
+
+	// Remove any debouncing information.
+	n.lastNotifiedLock.Lock()
+	delete(n.lastNotified, debounceKey{session, KindConflicted})
+	delete(n.lastNotified, debounceKey{session, KindErrored})
+	n.lastNotifiedLock.Unlock()
+}
+
+// Shutdown terminates delivery, cancelling any in-flight deliveries and
+// discarding any queued events.
+func (n *Notifier) Shutdown() {
+	// If the notifier is nil, then there's nothing to do.
+	if n == nil {
+		return
+	}
+
+	// Cancel delivery and wait for delivery Goroutines to exit.
+	n.cancel()
+	n.done.Wait()
+}
@@ -0,0 +1,290 @@
+package notification
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testDeliveryTimeout is the maximum amount of time that tests will wait for a
+// delivery.
+const testDeliveryTimeout = 5 * time.Second
+
+// testDelivery represents a delivery received by a test server.
+type testDelivery struct {
+	// authorization is the Authorization header received.
+	authorization string
+	// contentType is the Content-Type header received.
+	contentType string
+	// event is the decoded event.
+	event *Event
+}
+
+// newTestServer creates a test webhook server that responds to each request
+// with the status code provided by the specified callback (which is invoked
+// with the 1-based request index) and reports successfully decoded deliveries
+// on the returned channel.
+func newTestServer(t *testing.T, status func(int) int) (*httptest.Server, <-chan *testDelivery) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create the server. Handlers are invoked concurrently, but our notifier
+	// only performs one delivery attempt at a time for each webhook.
+	deliveries := make(chan *testDelivery, 16)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := int(atomic.AddInt32(&requests, 1))
+		event := &Event{}
+		if err := json.NewDecoder(r.Body).Decode(event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		code := status(request)
+		w.WriteHeader(code)
+		if code >= 200 && code < 300 {
+			deliveries <- &testDelivery{
+				authorization: r.Header.Get("Authorization"),
+				contentType:   r.Header.Get("Content-Type"),
+				event:         event,
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	// Done.
+	return server, deliveries
+}
+
+// newTestNotifier creates a notifier for the specified webhook with shortened
+// retry delays.
+func newTestNotifier(t *testing.T, webhook Webhook, debounce time.Duration) *Notifier {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create the notifier and register its shutdown.
+	notifier, err := NewNotifier(nil, []Webhook{webhook}, debounce)
+	if err != nil {
+		t.Fatal("unable to create notifier:", err)
+	}
+	t.Cleanup(notifier.Shutdown)
+
+	// Shorten retry delays. This is safe since delivery Goroutines won't read
+	// these values until an event is queued.
+	notifier.initialRetryDelay = 10 * time.Millisecond
+	notifier.maximumRetryDelay = 20 * time.Millisecond
+
+	// Done.
+	return notifier
+}
+
+// expectDelivery waits for a delivery on the specified channel.
+func expectDelivery(t *testing.T, deliveries <-chan *testDelivery) *testDelivery {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Wait for the delivery.
+	select {
+	case delivery := <-deliveries:
+		return delivery
+	case <-time.After(testDeliveryTimeout):
+		t.Fatal("timed out waiting for delivery")
+		return nil
+	}
+}
+
+// expectNoDelivery verifies that no delivery arrives on the specified channel
+// within a short period of time.
+func expectNoDelivery(t *testing.T, deliveries <-chan *testDelivery) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Wait for a short period.
+	select {
+	case delivery := <-deliveries:
+		t.Fatal("unexpected delivery:", delivery.event.Kind, delivery.event.Session)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TestNewNotifierNoWebhooks tests that NewNotifier returns a nil notifier when
+// no webhooks are specified and that a nil notifier is usable.
+func TestNewNotifierNoWebhooks(t *testing.T) {
+	notifier, err := NewNotifier(nil, nil, 0)
+	if err != nil {
+		t.Fatal("unable to create notifier:", err)
+	} else if notifier != nil {
+		t.Fatal("non-nil notifier created without webhooks")
+	}
+	notifier.Notify(&Event{Kind: KindConflicted, Session: "session"})
+	notifier.Forget("session")
+	notifier.Shutdown()
+}
+
+// TestNewNotifierInvalidWebhook tests that NewNotifier rejects invalid
+// webhooks.
+func TestNewNotifierInvalidWebhook(t *testing.T) {
+	for _, url := range []string{"", "ftp://example.com", "http://", "://"} {
+		if notifier, err := NewNotifier(nil, []Webhook{{URL: url}}, 0); err == nil {
+			notifier.Shutdown()
+			t.Error("notifier created with invalid webhook URL:", url)
+		}
+	}
+}
+
+// TestNotifierPayload tests that events are delivered with the expected
+// payload and headers.
+func TestNotifierPayload(t *testing.T) {
+	// Create a test server and notifier.
+	server, deliveries := newTestServer(t, func(int) int { return http.StatusOK })
+	notifier := newTestNotifier(t, Webhook{URL: server.URL, Authorization: "Bearer token"}, 0)
+
+	// Send an event.
+	event := &Event{
+		Time:      time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+		Kind:      KindConflicted,
+		Session:   "sync_identifier",
+		Name:      "name",
+		Labels:    map[string]string{"team": "web"},
+		Alpha:     "/alpha",
+		Beta:      "user@host:/beta",
+		Status:    "Watching for changes",
+		Conflicts: 2,
+	}
+	notifier.Notify(event)
+
+	// Verify the delivery.
+	delivery := expectDelivery(t, deliveries)
+	if delivery.authorization != "Bearer token" {
+		t.Error("authorization header incorrect:", delivery.authorization)
+	}
+	if delivery.contentType != "application/json" {
+		t.Error("content type header incorrect:", delivery.contentType)
+	}
+	received := delivery.event
+	if !received.Time.Equal(event.Time) {
+		t.Error("event time incorrect:", received.Time)
+	}
+	if received.Kind != event.Kind || received.Session != event.Session ||
+		received.Name != event.Name || received.Alpha != event.Alpha ||
+		received.Beta != event.Beta || received.Status != event.Status ||
+		received.Conflicts != event.Conflicts || received.Error != event.Error {
+		t.Errorf("event payload incorrect: %+v", received)
+	}
+	if len(received.Labels) != 1 || received.Labels["team"] != "web" {
+		t.Error("event labels incorrect:", received.Labels)
+	}
+}
+
+// TestNotifierDebouncing tests that events of the same kind for the same
+// session are debounced.
+func TestNotifierDebouncing(t *testing.T) {
+	// Create a test server and notifier.
+	server, deliveries := newTestServer(t, func(int) int { return http.StatusOK })
+	notifier := newTestNotifier(t, Webhook{URL: server.URL}, time.Minute)
+
+	// Send an initial event and verify that it's delivered.
+	now := time.Now()
+	notifier.Notify(&Event{Time: now, Kind: KindConflicted, Session: "a"})
+	expectDelivery(t, deliveries)
+
+	// Send a flapping event for the same session and verify that it's
+	// suppressed.
+	notifier.Notify(&Event{Time: now.Add(time.Second), Kind: KindConflicted, Session: "a"})
+	expectNoDelivery(t, deliveries)
+
+	// Verify that events of a different kind or for a different session aren't
+	// suppressed.
+	notifier.Notify(&Event{Time: now.Add(time.Second), Kind: KindErrored, Session: "a"})
+	if delivery := expectDelivery(t, deliveries); delivery.event.Kind != KindErrored {
+		t.Error("unexpected event kind delivered:", delivery.event.Kind)
+	}
+	notifier.Notify(&Event{Time: now.Add(time.Second), Kind: KindConflicted, Session: "b"})
+	if delivery := expectDelivery(t, deliveries); delivery.event.Session != "b" {
+		t.Error("unexpected event session delivered:", delivery.event.Session)
+	}
+
+	// Verify that events are delivered once the debounce interval has elapsed.
+	notifier.Notify(&Event{Time: now.Add(time.Minute), Kind: KindConflicted, Session: "a"})
+	expectDelivery(t, deliveries)
+
+	// Verify that forgetting a session resets its debouncing.
+	notifier.Forget("b")
+	notifier.Notify(&Event{Time: now.Add(2 * time.Second), Kind: KindConflicted, Session: "b"})
+	expectDelivery(t, deliveries)
+}
+
+// TestNotifierRetry tests that transient delivery failures are retried.
+func TestNotifierRetry(t *testing.T) {
+	// Create a test server that fails the first two requests and a notifier.
+	var attempts int32
+	server, deliveries := newTestServer(t, func(request int) int {
+		atomic.StoreInt32(&attempts, int32(request))
+		if request <= 2 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+	notifier := newTestNotifier(t, Webhook{URL: server.URL}, 0)
+
+	// Send an event and verify that it's eventually delivered.
+	notifier.Notify(&Event{Time: time.Now(), Kind: KindErrored, Session: "session", Error: "failure"})
+	if delivery := expectDelivery(t, deliveries); delivery.event.Error != "failure" {
+		t.Error("delivered event error incorrect:", delivery.event.Error)
+	}
+	if attempts := atomic.LoadInt32(&attempts); attempts != 3 {
+		t.Error("unexpected number of delivery attempts:", attempts, "!= 3")
+	}
+}
+
+// TestNotifierRetryExhaustion tests that deliveries are abandoned after the
+// maximum number of attempts and that permanent failures aren't retried.
+func TestNotifierRetryExhaustion(t *testing.T) {
+	// Create a test server that responds with a status code based on the
+	// session identifier and records attempts for each session.
+	attempts := make(chan string, 16)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := &Event{}
+		json.NewDecoder(r.Body).Decode(event)
+		attempts <- event.Session
+		if event.Session == "permanent" {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a notifier.
+	notifier := newTestNotifier(t, Webhook{URL: server.URL}, 0)
+
+	// Send an event that fails transiently and one that fails permanently.
+	notifier.Notify(&Event{Time: time.Now(), Kind: KindErrored, Session: "transient"})
+	notifier.Notify(&Event{Time: time.Now(), Kind: KindErrored, Session: "permanent"})
+
+	// Verify that the transient failure was attempted the maximum number of
+	// times and the permanent failure only once. Since deliveries to a webhook
+	// are sequential, all transient attempts will precede the permanent one.
+	expected := make([]string, 0, defaultMaximumDeliveryAttempts+1)
+	for i := 0; i < defaultMaximumDeliveryAttempts; i++ {
+		expected = append(expected, "transient")
+	}
+	expected = append(expected, "permanent")
+	for i, session := range expected {
+		select {
+		case attempt := <-attempts:
+			if attempt != session {
+				t.Fatalf("attempt %d for unexpected session: %s != %s", i, attempt, session)
+			}
+		case <-time.After(testDeliveryTimeout):
+			t.Fatal("timed out waiting for delivery attempt")
+		}
+	}
+	select {
+	case attempt := <-attempts:
+		t.Error("unexpected additional delivery attempt for session:", attempt)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
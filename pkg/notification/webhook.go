@@ -0,0 +1,39 @@
+package notification
+
+import (
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// Webhook is the YAML representation of a webhook to which notifications are
+// delivered.
+type Webhook struct {
+	// URL is the URL to which notification payloads are POSTed.
+	URL string `yaml:"url"`
+	// Authorization is an optional value for the Authorization header included
+	// with each delivery.
+	Authorization string `yaml:"authorization"`
+}
+
+// EnsureValid ensures that Webhook's invariants are respected.
+func (w *Webhook) EnsureValid() error {
+	// A nil webhook is not valid.
+	if w == nil {
+		return errors.New("nil webhook")
+	}
+
+	// Ensure that the URL is a valid HTTP(S) URL.
+	if w.URL == "" {
+		return errors.New("empty webhook URL")
+	} else if parsed, err := url.Parse(w.URL); err != nil {
+		return errors.Wrap(err, "invalid webhook URL")
+	} else if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return errors.Errorf("unsupported webhook URL scheme: %s", parsed.Scheme)
+	} else if parsed.Host == "" {
+		return errors.New("webhook URL has no host")
+	}
+
+	// Success.
+	return nil
+}
@@ -25,6 +25,11 @@ const (
 	// TODO: Figure out if other shells return different exit codes when a
 	// command isn't found. Is this exit code defined in a standard somewhere?
 	posixShellCommandNotFoundExitCode = 127
+
+	// posixShellKilledExitCode is the exit code returned by POSIX shells (and
+	// tools that mimic their conventions, such as Docker) when a child process
+	// is terminated by SIGKILL. It's computed as 128 plus the signal number.
+	posixShellKilledExitCode = 128 + 9
 )
 
 // IsPOSIXShellInvalidCommand returns whether or not a process state represents
@@ -38,3 +43,11 @@ func IsPOSIXShellInvalidCommand(state *os.ProcessState) bool {
 func IsPOSIXShellCommandNotFound(state *os.ProcessState) bool {
 	return state.ExitCode() == posixShellCommandNotFoundExitCode
 }
+
+// IsKilled returns whether or not a process state represents a process that
+// was forcibly terminated with SIGKILL, either directly or (as reported by an
+// intermediate POSIX shell) indirectly. This is the termination signature of an
+// out-of-memory kill.
+func IsKilled(state *os.ProcessState) bool {
+	return state.ExitCode() == posixShellKilledExitCode || terminatedBySIGKILL(state)
+}
@@ -52,3 +52,36 @@ func TestIsPOSIXShellCommandNotFound(t *testing.T) {
 		t.Error("expected POSIX command not found classification")
 	}
 }
+
+// TestIsKilled tests that the IsKilled function correctly identifies processes
+// terminated by SIGKILL, both directly and as reported by a POSIX shell.
+func TestIsKilled(t *testing.T) {
+	// If we're not running in a POSIX environment, then skip this test.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Verify that a process killed directly is identified.
+	command := exec.Command("/bin/sh", "-c", "kill -9 $$")
+	if err := command.Run(); err == nil {
+		t.Fatal("expected non-nil error when running killed command")
+	} else if !IsKilled(command.ProcessState) {
+		t.Error("expected classification of directly killed process")
+	}
+
+	// Verify that a kill reported by a shell is identified.
+	command = exec.Command("/bin/sh", "-c", "exit 137")
+	if err := command.Run(); err == nil {
+		t.Fatal("expected non-nil error when running failing command")
+	} else if !IsKilled(command.ProcessState) {
+		t.Error("expected classification of shell-reported kill")
+	}
+
+	// Verify that other failures aren't identified.
+	command = exec.Command("/bin/sh", "-c", "exit 1")
+	if err := command.Run(); err == nil {
+		t.Fatal("expected non-nil error when running failing command")
+	} else if IsKilled(command.ProcessState) {
+		t.Error("ordinary failure classified as kill")
+	}
+}
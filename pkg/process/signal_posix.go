@@ -0,0 +1,15 @@
+// +build !windows,!plan9
+
+package process
+
+import (
+	"os"
+	"syscall"
+)
+
+// terminatedBySIGKILL returns whether or not a process state represents a
+// process that was terminated by SIGKILL.
+func terminatedBySIGKILL(state *os.ProcessState) bool {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == syscall.SIGKILL
+}
@@ -0,0 +1,14 @@
+// +build windows plan9
+
+package process
+
+import (
+	"os"
+)
+
+// terminatedBySIGKILL returns whether or not a process state represents a
+// process that was terminated by SIGKILL. Signal-based termination isn't
+// reported on this platform, so it always returns false.
+func terminatedBySIGKILL(_ *os.ProcessState) bool {
+	return false
+}
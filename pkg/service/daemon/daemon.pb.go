@@ -29,6 +29,61 @@ const (
 // of the legacy proto package is being used.
 const _ = proto.ProtoPackageIsVersion4
 
+// TransportAvailabilityStatus indicates the availability of an agent transport.
+// Its values correspond to those of transports.AvailabilityStatus.
+type TransportAvailabilityStatus int32
+
+const (
+	// TransportUnavailable indicates that a transport is unusable.
+	TransportAvailabilityStatus_TransportUnavailable TransportAvailabilityStatus = 0
+	// TransportPartiallyAvailable indicates that a transport is usable but that
+	// some of its functionality is unavailable.
+	TransportAvailabilityStatus_TransportPartiallyAvailable TransportAvailabilityStatus = 1
+	// TransportAvailable indicates that a transport is fully usable.
+	TransportAvailabilityStatus_TransportAvailable TransportAvailabilityStatus = 2
+)
+
+// Enum value maps for TransportAvailabilityStatus.
+var (
+	TransportAvailabilityStatus_name = map[int32]string{
+		0: "TransportUnavailable",
+		1: "TransportPartiallyAvailable",
+		2: "TransportAvailable",
+	}
+	TransportAvailabilityStatus_value = map[string]int32{
+		"TransportUnavailable":        0,
+		"TransportPartiallyAvailable": 1,
+		"TransportAvailable":          2,
+	}
+)
+
+func (x TransportAvailabilityStatus) Enum() *TransportAvailabilityStatus {
+	p := new(TransportAvailabilityStatus)
+	*p = x
+	return p
+}
+
+func (x TransportAvailabilityStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TransportAvailabilityStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_service_daemon_daemon_proto_enumTypes[0].Descriptor()
+}
+
+func (TransportAvailabilityStatus) Type() protoreflect.EnumType {
+	return &file_service_daemon_daemon_proto_enumTypes[0]
+}
+
+func (x TransportAvailabilityStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TransportAvailabilityStatus.Descriptor instead.
+func (TransportAvailabilityStatus) EnumDescriptor() ([]byte, []int) {
+	return file_service_daemon_daemon_proto_rawDescGZIP(), []int{0}
+}
+
 type VersionRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -216,6 +271,161 @@ func (*TerminateResponse) Descriptor() ([]byte, []int) {
 	return file_service_daemon_daemon_proto_rawDescGZIP(), []int{3}
 }
 
+// TransportAvailability describes the availability of an agent transport on
+// the daemon's host.
+type TransportAvailability struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Transport is the transport name.
+	Transport string `protobuf:"bytes,1,opt,name=transport,proto3" json:"transport,omitempty"`
+	// Status is the transport's availability status.
+	Status TransportAvailabilityStatus `protobuf:"varint,2,opt,name=status,proto3,enum=daemon.TransportAvailabilityStatus" json:"status,omitempty"`
+	// Reason describes why the transport is unavailable or only partially
+	// available. It is empty if the transport is fully available.
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *TransportAvailability) Reset() {
+	*x = TransportAvailability{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_service_daemon_daemon_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransportAvailability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransportAvailability) ProtoMessage() {}
+
+func (x *TransportAvailability) ProtoReflect() protoreflect.Message {
+	mi := &file_service_daemon_daemon_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransportAvailability.ProtoReflect.Descriptor instead.
+func (*TransportAvailability) Descriptor() ([]byte, []int) {
+	return file_service_daemon_daemon_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TransportAvailability) GetTransport() string {
+	if x != nil {
+		return x.Transport
+	}
+	return ""
+}
+
+func (x *TransportAvailability) GetStatus() TransportAvailabilityStatus {
+	if x != nil {
+		return x.Status
+	}
+	return TransportAvailabilityStatus_TransportUnavailable
+}
+
+func (x *TransportAvailability) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type TransportsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *TransportsRequest) Reset() {
+	*x = TransportsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_service_daemon_daemon_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransportsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransportsRequest) ProtoMessage() {}
+
+func (x *TransportsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_daemon_daemon_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransportsRequest.ProtoReflect.Descriptor instead.
+func (*TransportsRequest) Descriptor() ([]byte, []int) {
+	return file_service_daemon_daemon_proto_rawDescGZIP(), []int{5}
+}
+
+type TransportsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Availability is the availability of each agent transport.
+	Availability []*TransportAvailability `protobuf:"bytes,1,rep,name=availability,proto3" json:"availability,omitempty"`
+}
+
+func (x *TransportsResponse) Reset() {
+	*x = TransportsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_service_daemon_daemon_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransportsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransportsResponse) ProtoMessage() {}
+
+func (x *TransportsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_daemon_daemon_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransportsResponse.ProtoReflect.Descriptor instead.
+func (*TransportsResponse) Descriptor() ([]byte, []int) {
+	return file_service_daemon_daemon_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TransportsResponse) GetAvailability() []*TransportAvailability {
+	if x != nil {
+		return x.Availability
+	}
+	return nil
+}
+
 var File_service_daemon_daemon_proto protoreflect.FileDescriptor
 
 var file_service_daemon_daemon_proto_rawDesc = []byte{
@@ -231,19 +441,47 @@ var file_service_daemon_daemon_proto_rawDesc = []byte{
 	0x74, 0x61, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x61, 0x67, 0x22, 0x12,
 	0x0a, 0x10, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
 	0x73, 0x74, 0x22, 0x13, 0x0a, 0x11, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0x8a, 0x01, 0x0a, 0x06, 0x44, 0x61, 0x65, 0x6d,
-	0x6f, 0x6e, 0x12, 0x3c, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x2e,
-	0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x56,
-	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
-	0x12, 0x42, 0x0a, 0x09, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x12, 0x18, 0x2e,
-	0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e,
-	0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x00, 0x42, 0x32, 0x5a, 0x30, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
-	0x6f, 0x6d, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e, 0x2d, 0x69, 0x6f, 0x2f, 0x6d, 0x75,
-	0x74, 0x61, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
-	0x65, 0x2f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x8a, 0x01, 0x0a, 0x15, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x70, 0x6f, 0x72, 0x74, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x12,
+	0x3b, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x23, 0x2e, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f,
+	0x72, 0x74, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x22, 0x13, 0x0a, 0x11, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72,
+	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x57, 0x0a, 0x12, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x41, 0x0a, 0x0c, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69,
+	0x6c, 0x69, 0x74, 0x79, 0x52, 0x0c, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69,
+	0x74, 0x79, 0x2a, 0x70, 0x0a, 0x1b, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x41,
+	0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x18, 0x0a, 0x14, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x55, 0x6e,
+	0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x10, 0x00, 0x12, 0x1f, 0x0a, 0x1b, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x50, 0x61, 0x72, 0x74, 0x69, 0x61, 0x6c, 0x6c,
+	0x79, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x10, 0x01, 0x12, 0x16, 0x0a, 0x12,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62,
+	0x6c, 0x65, 0x10, 0x02, 0x32, 0xd1, 0x01, 0x0a, 0x06, 0x44, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x12,
+	0x3c, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x2e, 0x64, 0x61, 0x65,
+	0x6d, 0x6f, 0x6e, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x17, 0x2e, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x42, 0x0a,
+	0x09, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x12, 0x18, 0x2e, 0x64, 0x61, 0x65,
+	0x6d, 0x6f, 0x6e, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x54, 0x65,
+	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x00, 0x12, 0x45, 0x0a, 0x0a, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x12,
+	0x19, 0x2e, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f,
+	0x72, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x64, 0x61, 0x65,
+	0x6d, 0x6f, 0x6e, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x32, 0x5a, 0x30, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e, 0x2d, 0x69,
+	0x6f, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -258,23 +496,32 @@ func file_service_daemon_daemon_proto_rawDescGZIP() []byte {
 	return file_service_daemon_daemon_proto_rawDescData
 }
 
-var file_service_daemon_daemon_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_service_daemon_daemon_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_service_daemon_daemon_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_service_daemon_daemon_proto_goTypes = []interface{}{
-	(*VersionRequest)(nil),    // 0: daemon.VersionRequest
-	(*VersionResponse)(nil),   // 1: daemon.VersionResponse
-	(*TerminateRequest)(nil),  // 2: daemon.TerminateRequest
-	(*TerminateResponse)(nil), // 3: daemon.TerminateResponse
+	(TransportAvailabilityStatus)(0), // 0: daemon.TransportAvailabilityStatus
+	(*VersionRequest)(nil),           // 1: daemon.VersionRequest
+	(*VersionResponse)(nil),          // 2: daemon.VersionResponse
+	(*TerminateRequest)(nil),         // 3: daemon.TerminateRequest
+	(*TerminateResponse)(nil),        // 4: daemon.TerminateResponse
+	(*TransportAvailability)(nil),    // 5: daemon.TransportAvailability
+	(*TransportsRequest)(nil),        // 6: daemon.TransportsRequest
+	(*TransportsResponse)(nil),       // 7: daemon.TransportsResponse
 }
 var file_service_daemon_daemon_proto_depIdxs = []int32{
-	0, // 0: daemon.Daemon.Version:input_type -> daemon.VersionRequest
-	2, // 1: daemon.Daemon.Terminate:input_type -> daemon.TerminateRequest
-	1, // 2: daemon.Daemon.Version:output_type -> daemon.VersionResponse
-	3, // 3: daemon.Daemon.Terminate:output_type -> daemon.TerminateResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: daemon.TransportAvailability.status:type_name -> daemon.TransportAvailabilityStatus
+	5, // 1: daemon.TransportsResponse.availability:type_name -> daemon.TransportAvailability
+	1, // 2: daemon.Daemon.Version:input_type -> daemon.VersionRequest
+	3, // 3: daemon.Daemon.Terminate:input_type -> daemon.TerminateRequest
+	6, // 4: daemon.Daemon.Transports:input_type -> daemon.TransportsRequest
+	2, // 5: daemon.Daemon.Version:output_type -> daemon.VersionResponse
+	4, // 6: daemon.Daemon.Terminate:output_type -> daemon.TerminateResponse
+	7, // 7: daemon.Daemon.Transports:output_type -> daemon.TransportsResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_service_daemon_daemon_proto_init() }
@@ -331,19 +578,56 @@ func file_service_daemon_daemon_proto_init() {
 				return nil
 			}
 		}
+		file_service_daemon_daemon_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransportAvailability); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_service_daemon_daemon_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransportsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_service_daemon_daemon_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransportsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_service_daemon_daemon_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      1,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_service_daemon_daemon_proto_goTypes,
 		DependencyIndexes: file_service_daemon_daemon_proto_depIdxs,
+		EnumInfos:         file_service_daemon_daemon_proto_enumTypes,
 		MessageInfos:      file_service_daemon_daemon_proto_msgTypes,
 	}.Build()
 	File_service_daemon_daemon_proto = out.File
@@ -366,6 +650,7 @@ const _ = grpc.SupportPackageIsVersion6
 type DaemonClient interface {
 	Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionResponse, error)
 	Terminate(ctx context.Context, in *TerminateRequest, opts ...grpc.CallOption) (*TerminateResponse, error)
+	Transports(ctx context.Context, in *TransportsRequest, opts ...grpc.CallOption) (*TransportsResponse, error)
 }
 
 type daemonClient struct {
@@ -394,10 +679,20 @@ func (c *daemonClient) Terminate(ctx context.Context, in *TerminateRequest, opts
 	return out, nil
 }
 
+func (c *daemonClient) Transports(ctx context.Context, in *TransportsRequest, opts ...grpc.CallOption) (*TransportsResponse, error) {
+	out := new(TransportsResponse)
+	err := c.cc.Invoke(ctx, "/daemon.Daemon/Transports", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DaemonServer is the server API for Daemon service.
 type DaemonServer interface {
 	Version(context.Context, *VersionRequest) (*VersionResponse, error)
 	Terminate(context.Context, *TerminateRequest) (*TerminateResponse, error)
+	Transports(context.Context, *TransportsRequest) (*TransportsResponse, error)
 }
 
 // UnimplementedDaemonServer can be embedded to have forward compatible implementations.
@@ -410,6 +705,9 @@ func (*UnimplementedDaemonServer) Version(context.Context, *VersionRequest) (*Ve
 func (*UnimplementedDaemonServer) Terminate(context.Context, *TerminateRequest) (*TerminateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Terminate not implemented")
 }
+func (*UnimplementedDaemonServer) Transports(context.Context, *TransportsRequest) (*TransportsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transports not implemented")
+}
 
 func RegisterDaemonServer(s *grpc.Server, srv DaemonServer) {
 	s.RegisterService(&_Daemon_serviceDesc, srv)
@@ -451,6 +749,24 @@ func _Daemon_Terminate_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Daemon_Transports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransportsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServer).Transports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/daemon.Daemon/Transports",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServer).Transports(ctx, req.(*TransportsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Daemon_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "daemon.Daemon",
 	HandlerType: (*DaemonServer)(nil),
@@ -463,6 +779,10 @@ var _Daemon_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Terminate",
 			Handler:    _Daemon_Terminate_Handler,
 		},
+		{
+			MethodName: "Transports",
+			Handler:    _Daemon_Transports_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "service/daemon/daemon.proto",
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/mutagen-io/mutagen/pkg/agent/transports"
 	"github.com/mutagen-io/mutagen/pkg/housekeeping"
 	"github.com/mutagen-io/mutagen/pkg/mutagen"
 )
@@ -98,3 +99,25 @@ func (s *Server) Terminate(_ context.Context, _ *TerminateRequest) (*TerminateRe
 	// Success.
 	return &TerminateResponse{}, nil
 }
+
+// Transports reports the availability of agent transports on the daemon's
+// host.
+func (s *Server) Transports(_ context.Context, _ *TransportsRequest) (*TransportsResponse, error) {
+	// Probe transport availability.
+	availability := transports.ProbeAvailability()
+
+	// Convert the results.
+	response := &TransportsResponse{
+		Availability: make([]*TransportAvailability, len(availability)),
+	}
+	for a, entry := range availability {
+		response.Availability[a] = &TransportAvailability{
+			Transport: entry.Transport,
+			Status:    TransportAvailabilityStatus(entry.Status),
+			Reason:    entry.Reason,
+		}
+	}
+
+	// Success.
+	return response, nil
+}
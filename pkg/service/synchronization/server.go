@@ -1,10 +1,12 @@
 package synchronization
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 
 	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
 )
 
 const (
@@ -14,6 +16,27 @@ const (
 	// maximumProblems is the maximum number of problems that will be reported
 	// for a single endpoint before conflict list truncation.
 	maximumProblems = 10
+	// maximumReconciliationDecisions is the maximum number of reconciliation
+	// decisions that will be reported for a single session before decision list
+	// truncation.
+	maximumReconciliationDecisions = 100
+	// maximumObservedChanges is the maximum number of observed changes that
+	// will be reported for a single endpoint before change list truncation.
+	maximumObservedChanges = 100
+	// maximumTransferEfficiencies is the maximum number of transfer
+	// efficiencies that will be reported for a single session before
+	// efficiency list truncation.
+	maximumTransferEfficiencies = 100
+	// exportChunkSize is the maximum size of the archive data chunks sent in
+	// export responses.
+	exportChunkSize = 64 * 1024
+)
+
+const (
+	// exportFormatTar is the export format name for tar archives.
+	exportFormatTar = "tar"
+	// exportFormatZip is the export format name for zip archives.
+	exportFormatZip = "zip"
 )
 
 // Server provides an implementation of the Synchronization service.
@@ -41,6 +64,7 @@ func (s *Server) Create(ctx context.Context, request *CreateRequest) (*CreateRes
 		ctx,
 		request.Specification.Alpha,
 		request.Specification.Beta,
+		request.Specification.AdditionalBetas,
 		request.Specification.Configuration,
 		request.Specification.ConfigurationAlpha,
 		request.Specification.ConfigurationBeta,
@@ -77,7 +101,9 @@ func (s *Server) List(ctx context.Context, request *ListRequest) (*ListResponse,
 	// making it impossible to determine the nature of the underlying issue(s).
 	// It's worth noting that the slicing/reassignment operations here are safe,
 	// even though the underlying memory is shared between multiple state
-	// instances, because we're only mutating the value-based slice header.
+	// instances, because we're only mutating the value-based slice header. The
+	// exception is additional beta states, which are shared between state
+	// copies, so we replace (rather than modify) those that need truncation.
 	for _, state := range states {
 		if len(state.Conflicts) > maximumConflicts {
 			state.TruncatedConflicts = uint64(len(state.Conflicts) - maximumConflicts)
@@ -91,6 +117,33 @@ func (s *Server) List(ctx context.Context, request *ListRequest) (*ListResponse,
 			state.TruncatedBetaProblems = uint64(len(state.BetaProblems) - maximumProblems)
 			state.BetaProblems = state.BetaProblems[:maximumProblems]
 		}
+		if len(state.ReconciliationDecisions) > maximumReconciliationDecisions {
+			state.TruncatedReconciliationDecisions = uint64(len(state.ReconciliationDecisions) - maximumReconciliationDecisions)
+			state.ReconciliationDecisions = state.ReconciliationDecisions[:maximumReconciliationDecisions]
+		}
+		if len(state.ObservedAlphaChanges) > maximumObservedChanges {
+			state.TruncatedObservedAlphaChanges = uint64(len(state.ObservedAlphaChanges) - maximumObservedChanges)
+			state.ObservedAlphaChanges = state.ObservedAlphaChanges[:maximumObservedChanges]
+		}
+		if len(state.ObservedBetaChanges) > maximumObservedChanges {
+			state.TruncatedObservedBetaChanges = uint64(len(state.ObservedBetaChanges) - maximumObservedChanges)
+			state.ObservedBetaChanges = state.ObservedBetaChanges[:maximumObservedChanges]
+		}
+		if len(state.TransferEfficiencies) > maximumTransferEfficiencies {
+			state.TruncatedTransferEfficiencies = uint64(len(state.TransferEfficiencies) - maximumTransferEfficiencies)
+			state.TransferEfficiencies = state.TransferEfficiencies[:maximumTransferEfficiencies]
+		}
+		for b, betaState := range state.AdditionalBetas {
+			if len(betaState.Problems) > maximumProblems {
+				state.AdditionalBetas[b] = &synchronization.AdditionalBetaState{
+					Connected:                       betaState.Connected,
+					LastError:                       betaState.LastError,
+					SuccessfulSynchronizationCycles: betaState.SuccessfulSynchronizationCycles,
+					Problems:                        betaState.Problems[:maximumProblems],
+					TruncatedProblems:               uint64(len(betaState.Problems) - maximumProblems),
+				}
+			}
+		}
 	}
 
 	// Success.
@@ -107,8 +160,16 @@ func (s *Server) Flush(ctx context.Context, request *FlushRequest) (*FlushRespon
 		return nil, fmt.Errorf("invalid flush request: %w", err)
 	}
 
+	// If quarantined paths should be retried, then release them before
+	// flushing so that they're retried by the forced synchronization cycle.
+	if request.RetryQuarantined {
+		if err := s.manager.RetryQuarantined(request.Selection); err != nil {
+			return nil, err
+		}
+	}
+
 	// Perform flushing.
-	if err := s.manager.Flush(ctx, request.Selection, request.Prompter, request.SkipWait); err != nil {
+	if err := s.manager.Flush(ctx, request.Selection, request.Prompter, request.SkipWait, request.IgnoreOverrides, request.Rehash); err != nil {
 		return nil, err
 	}
 
@@ -139,8 +200,16 @@ func (s *Server) Resume(ctx context.Context, request *ResumeRequest) (*ResumeRes
 		return nil, fmt.Errorf("invalid resume request: %w", err)
 	}
 
+	// Determine the requested observation mode change.
+	observationModeChange := synchronization.ObservationModeChangeNone
+	if request.Observe {
+		observationModeChange = synchronization.ObservationModeChangeObserve
+	} else if request.Live {
+		observationModeChange = synchronization.ObservationModeChangeLive
+	}
+
 	// Perform resuming.
-	if err := s.manager.Resume(ctx, request.Selection, request.Prompter); err != nil {
+	if err := s.manager.Resume(ctx, request.Selection, request.Prompter, observationModeChange); err != nil {
 		return nil, err
 	}
 
@@ -179,3 +248,208 @@ func (s *Server) Terminate(ctx context.Context, request *TerminateRequest) (*Ter
 	// Success.
 	return &TerminateResponse{}, nil
 }
+
+// Relocate relocates a paused session's endpoint.
+func (s *Server) Relocate(ctx context.Context, request *RelocateRequest) (*RelocateResponse, error) {
+	// Validate the request.
+	if err := request.ensureValid(); err != nil {
+		return nil, fmt.Errorf("invalid relocate request: %w", err)
+	}
+
+	// Perform relocation.
+	warning, err := s.manager.Relocate(ctx, request.Session, request.Beta, request.Url, request.Prompter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return &RelocateResponse{Warning: warning}, nil
+}
+
+// Compare compares the contents of two endpoints without synchronizing them.
+func (s *Server) Compare(ctx context.Context, request *CompareRequest) (*CompareResponse, error) {
+	// Validate the request.
+	if err := request.ensureValid(); err != nil {
+		return nil, fmt.Errorf("invalid compare request: %w", err)
+	}
+
+	// Perform the comparison.
+	divergence, err := s.manager.Compare(
+		ctx,
+		request.Alpha, request.Beta,
+		request.Configuration, request.ConfigurationAlpha, request.ConfigurationBeta,
+		request.Prompter,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return &CompareResponse{
+		AlphaOnly:      divergence.AlphaOnly,
+		BetaOnly:       divergence.BetaOnly,
+		ContentDiffers: divergence.ContentDiffers,
+		ModeDiffers:    divergence.ModeDiffers,
+	}, nil
+}
+
+// TailProblems streams problem and conflict events for sessions. The first
+// response contains the current set of problems and conflicts, after which
+// responses are sent as problems and conflicts are added or resolved.
+func (s *Server) TailProblems(request *TailProblemsRequest, stream Synchronization_TailProblemsServer) error {
+	// Validate the request.
+	if err := request.ensureValid(); err != nil {
+		return fmt.Errorf("invalid tail problems request: %w", err)
+	}
+
+	// Stream events until the client disconnects or tailing fails.
+	return s.manager.TailProblems(stream.Context(), request.Selection, func(events []*synchronization.ProblemEvent) error {
+		return stream.Send(&TailProblemsResponse{Events: events})
+	})
+}
+
+// Reconnect forces an immediate reconnection attempt for a session that's
+// waiting to reconnect.
+func (s *Server) Reconnect(ctx context.Context, request *ReconnectRequest) (*ReconnectResponse, error) {
+	// Validate the request.
+	if err := request.ensureValid(); err != nil {
+		return nil, fmt.Errorf("invalid reconnect request: %w", err)
+	}
+
+	// Perform reconnection.
+	state, err := s.manager.Reconnect(ctx, request.Session, request.Prompter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return &ReconnectResponse{State: state}, nil
+}
+
+// Undo undoes the changes applied by sessions' last synchronization cycle.
+func (s *Server) Undo(ctx context.Context, request *UndoRequest) (*UndoResponse, error) {
+	// Validate the request.
+	if err := request.ensureValid(); err != nil {
+		return nil, fmt.Errorf("invalid undo request: %w", err)
+	}
+
+	// Perform the undo operation.
+	if err := s.manager.Undo(ctx, request.Selection, request.Prompter); err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return &UndoResponse{}, nil
+}
+
+// ExplainIgnore explains the ignore status of a path within a session.
+func (s *Server) ExplainIgnore(_ context.Context, request *ExplainIgnoreRequest) (*ExplainIgnoreResponse, error) {
+	// Validate the request.
+	if err := request.ensureValid(); err != nil {
+		return nil, fmt.Errorf("invalid explain ignore request: %w", err)
+	}
+
+	// Evaluate the path.
+	decision, err := s.manager.ExplainIgnore(request.Session, request.Path, request.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return &ExplainIgnoreResponse{
+		Ignored: decision.Ignored,
+		Path:    decision.Path,
+		Pattern: decision.Pattern,
+		Source:  decision.Source,
+	}, nil
+}
+
+// ExplainActivity explains why a session is not idle.
+func (s *Server) ExplainActivity(_ context.Context, request *ExplainActivityRequest) (*ExplainActivityResponse, error) {
+	// Validate the request.
+	if err := request.ensureValid(); err != nil {
+		return nil, fmt.Errorf("invalid explain activity request: %w", err)
+	}
+
+	// Explain the session's activity.
+	explanation, err := s.manager.ExplainActivity(request.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return &ExplainActivityResponse{Explanation: explanation}, nil
+}
+
+// ListStaged lists the content staged by a session's endpoints.
+func (s *Server) ListStaged(_ context.Context, request *ListStagedRequest) (*ListStagedResponse, error) {
+	// Validate the request.
+	if err := request.ensureValid(); err != nil {
+		return nil, fmt.Errorf("invalid list staged request: %w", err)
+	}
+
+	// Perform listing.
+	alpha, beta, err := s.manager.ListStaged(request.Session, request.Verify)
+	if err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return &ListStagedResponse{Alpha: alpha, Beta: beta}, nil
+}
+
+// exportStreamWriter is an io.Writer that sends data as export responses.
+type exportStreamWriter struct {
+	// stream is the export response stream.
+	stream Synchronization_ExportServer
+}
+
+// Write implements io.Writer.Write.
+func (w *exportStreamWriter) Write(data []byte) (int, error) {
+	if err := w.stream.Send(&ExportResponse{Data: data}); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// Export streams the content of a session endpoint as an archive.
+func (s *Server) Export(request *ExportRequest, stream Synchronization_ExportServer) error {
+	// Validate the request.
+	if err := request.ensureValid(); err != nil {
+		return fmt.Errorf("invalid export request: %w", err)
+	}
+
+	// Determine the archive format.
+	format := core.ExportFormatTar
+	if request.Format == exportFormatZip {
+		format = core.ExportFormatZip
+	}
+
+	// Perform the export, buffering archive data so that it's sent in
+	// reasonably sized chunks.
+	writer := bufio.NewWriterSize(&exportStreamWriter{stream}, exportChunkSize)
+	if err := s.manager.Export(stream.Context(), request.Session, request.Beta, format, writer); err != nil {
+		return err
+	}
+
+	// Send any remaining data.
+	return writer.Flush()
+}
+
+// Diff diffs the content of a session endpoint against the session's last
+// synchronized state.
+func (s *Server) Diff(ctx context.Context, request *DiffRequest) (*DiffResponse, error) {
+	// Validate the request.
+	if err := request.ensureValid(); err != nil {
+		return nil, fmt.Errorf("invalid diff request: %w", err)
+	}
+
+	// Perform diffing.
+	diff, err := s.manager.DiffSnapshots(ctx, request.Session, request.Beta, request.DetectMoves, request.Prompter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return &DiffResponse{Diff: diff}, nil
+}
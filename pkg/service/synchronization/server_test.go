@@ -1,3 +1,362 @@
 package synchronization
 
-// TODO: Implement.
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/endpoint/local"
+	_ "github.com/mutagen-io/mutagen/pkg/synchronization/protocols/local"
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// withTestServer runs the specified test callback with a server backed by a
+// manager that uses a temporary data directory. The manager contains a single
+// paused session (whose identifier is passed to the callback) with the
+// specified configuration and synchronization roots in a temporary directory
+// (whose path is also passed to the callback).
+func withTestServer(t *testing.T, configuration *synchronization.Configuration, callback func(*Server, string, string)) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_service_test")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Redirect the data directory and defer restoration of the environment.
+	previous, previousSet := os.LookupEnv("MUTAGEN_DATA_DIRECTORY")
+	if err := os.Setenv("MUTAGEN_DATA_DIRECTORY", filepath.Join(directory, "data")); err != nil {
+		t.Fatal("unable to set data directory environment variable:", err)
+	}
+	defer func() {
+		if previousSet {
+			os.Setenv("MUTAGEN_DATA_DIRECTORY", previous)
+		} else {
+			os.Unsetenv("MUTAGEN_DATA_DIRECTORY")
+		}
+	}()
+
+	// Create the manager and defer its shutdown.
+	manager, err := synchronization.NewManager(logging.RootLogger.Sublogger("test"), nil, 0)
+	if err != nil {
+		t.Fatal("unable to create manager:", err)
+	}
+	defer manager.Shutdown()
+
+	// Create a paused session.
+	alpha := &url.URL{Kind: url.Kind_Synchronization, Path: filepath.Join(directory, "alpha")}
+	beta := &url.URL{Kind: url.Kind_Synchronization, Path: filepath.Join(directory, "beta")}
+	session, err := manager.Create(
+		context.Background(),
+		alpha, beta,
+		nil,
+		configuration, &synchronization.Configuration{}, &synchronization.Configuration{},
+		"",
+		nil,
+		true,
+		"",
+	)
+	if err != nil {
+		t.Fatal("unable to create session:", err)
+	}
+
+	// Invoke the callback.
+	callback(NewServer(manager), session, directory)
+}
+
+// TestServerExplainIgnore tests Server.ExplainIgnore.
+func TestServerExplainIgnore(t *testing.T) {
+	// Create a session configuration with layered ignores.
+	configuration := &synchronization.Configuration{
+		Ignores: []string{"*.log", "!keep.log", "build/"},
+	}
+
+	// Set up test cases.
+	testCases := []struct {
+		path      string
+		directory bool
+		expected  *ExplainIgnoreResponse
+	}{
+		{"main.go", false, &ExplainIgnoreResponse{Path: "main.go"}},
+		{"debug.log", false, &ExplainIgnoreResponse{
+			Ignored: true, Path: "debug.log", Pattern: "*.log", Source: "session configuration",
+		}},
+		{"keep.log", false, &ExplainIgnoreResponse{
+			Path: "keep.log", Pattern: "!keep.log", Source: "session configuration",
+		}},
+		{"build", true, &ExplainIgnoreResponse{
+			Ignored: true, Path: "build", Pattern: "build/", Source: "session configuration",
+		}},
+		{"build/output", false, &ExplainIgnoreResponse{
+			Ignored: true, Path: "build", Pattern: "build/", Source: "session configuration",
+		}},
+	}
+
+	// Process test cases.
+	withTestServer(t, configuration, func(server *Server, session, _ string) {
+		for _, testCase := range testCases {
+			response, err := server.ExplainIgnore(context.Background(), &ExplainIgnoreRequest{
+				Session:   session,
+				Path:      testCase.path,
+				Directory: testCase.directory,
+			})
+			if err != nil {
+				t.Errorf("unable to explain ignore status for %s: %v", testCase.path, err)
+				continue
+			} else if err = response.EnsureValid(); err != nil {
+				t.Errorf("invalid response for %s: %v", testCase.path, err)
+			}
+			if response.Ignored != testCase.expected.Ignored ||
+				response.Path != testCase.expected.Path ||
+				response.Pattern != testCase.expected.Pattern ||
+				response.Source != testCase.expected.Source {
+				t.Errorf("unexpected response for %s: %v", testCase.path, response)
+			}
+		}
+
+		// Verify that an invalid path is rejected.
+		if _, err := server.ExplainIgnore(context.Background(), &ExplainIgnoreRequest{
+			Session: session,
+			Path:    "../outside",
+		}); err == nil {
+			t.Error("invalid path unexpectedly accepted")
+		}
+
+		// Verify that a missing session specification is rejected.
+		if _, err := server.ExplainIgnore(context.Background(), &ExplainIgnoreRequest{
+			Path: "main.go",
+		}); err == nil {
+			t.Error("request without session unexpectedly accepted")
+		}
+	})
+}
+
+// TestServerExplainActivity tests Server.ExplainActivity.
+func TestServerExplainActivity(t *testing.T) {
+	withTestServer(t, &synchronization.Configuration{}, func(server *Server, session, _ string) {
+		// Verify that the paused session's activity is explained.
+		response, err := server.ExplainActivity(context.Background(), &ExplainActivityRequest{
+			Session: session,
+		})
+		if err != nil {
+			t.Fatal("unable to explain activity:", err)
+		} else if err = response.EnsureValid(); err != nil {
+			t.Fatal("invalid response:", err)
+		}
+		explanation := response.Explanation
+		if explanation.Idle {
+			t.Error("paused session reported as idle")
+		} else if len(explanation.Reasons) != 1 {
+			t.Error("unexpected number of activity reasons:", len(explanation.Reasons))
+		} else if explanation.Reasons[0].Kind != synchronization.ActivityReasonKind_ActivityReasonKindPaused {
+			t.Error("unexpected activity reason kind:", explanation.Reasons[0].Kind)
+		}
+
+		// Verify that an unknown session is rejected.
+		if _, err := server.ExplainActivity(context.Background(), &ExplainActivityRequest{
+			Session: "unknown",
+		}); err == nil {
+			t.Error("unknown session unexpectedly accepted")
+		}
+	})
+}
+
+// TestServerListStaged tests Server.ListStaged.
+func TestServerListStaged(t *testing.T) {
+	// Create a session configuration that uses neighboring staging so that
+	// staging roots are located alongside the synchronization roots.
+	configuration := &synchronization.Configuration{
+		StageMode: synchronization.StageMode_StageModeNeighboring,
+	}
+
+	withTestServer(t, configuration, func(server *Server, session, directory string) {
+		// Stage a file for alpha using the staging layout used by the local
+		// endpoint, recording its path in the staging manifest.
+		content := []byte("staged content")
+		digest := sha1.Sum(content)
+		root, err := local.StagingRoot(session, true, filepath.Join(directory, "alpha"), configuration.StageMode)
+		if err != nil {
+			t.Fatal("unable to compute staging root:", err)
+		}
+		name := fmt.Sprintf("%x_%x", sha1.Sum([]byte("file")), digest)
+		prefix := filepath.Join(root, fmt.Sprintf("%x", digest[:1]))
+		if err := os.MkdirAll(prefix, 0700); err != nil {
+			t.Fatal("unable to create staging prefix directory:", err)
+		} else if err = ioutil.WriteFile(filepath.Join(prefix, name), content, 0600); err != nil {
+			t.Fatal("unable to write staged file:", err)
+		}
+		manifest := fmt.Sprintf("%s %s\n", name, strconv.Quote("file"))
+		if err := ioutil.WriteFile(filepath.Join(root, "manifest"), []byte(manifest), 0600); err != nil {
+			t.Fatal("unable to write staging manifest:", err)
+		}
+
+		// List and verify staged content.
+		response, err := server.ListStaged(context.Background(), &ListStagedRequest{
+			Session: session,
+			Verify:  true,
+		})
+		if err != nil {
+			t.Fatal("unable to list staged content:", err)
+		} else if err = response.EnsureValid(); err != nil {
+			t.Fatal("invalid response:", err)
+		}
+
+		// Verify alpha's staged content.
+		if !response.Alpha.Listed {
+			t.Error("alpha staged content not listed")
+		} else if len(response.Alpha.Entries) != 1 {
+			t.Fatal("unexpected number of alpha staged entries:", len(response.Alpha.Entries))
+		}
+		entry := response.Alpha.Entries[0]
+		if entry.Path != "file" {
+			t.Error("unexpected staged path:", entry.Path)
+		} else if entry.Size != uint64(len(content)) {
+			t.Error("unexpected staged size:", entry.Size)
+		} else if !entry.DigestValid {
+			t.Error("staged digest not verified")
+		}
+
+		// Verify that beta has no staged content.
+		if !response.Beta.Listed {
+			t.Error("beta staged content not listed")
+		} else if len(response.Beta.Entries) != 0 {
+			t.Error("unexpected beta staged entries:", response.Beta.Entries)
+		}
+	})
+}
+
+// testExportStream is an implementation of Synchronization_ExportServer that
+// records the archive data that it receives.
+type testExportStream struct {
+	// ServerStream is embedded to satisfy the remainder of the interface. It
+	// is nil and must not be used.
+	grpc.ServerStream
+	// data is the archive data received.
+	data bytes.Buffer
+}
+
+// Context implements grpc.ServerStream.Context.
+func (s *testExportStream) Context() context.Context {
+	return context.Background()
+}
+
+// Send implements Synchronization_ExportServer.Send.
+func (s *testExportStream) Send(response *ExportResponse) error {
+	s.data.Write(response.Data)
+	return nil
+}
+
+// TestServerExport tests Server.Export.
+func TestServerExport(t *testing.T) {
+	// Create a session configuration that ignores log files.
+	configuration := &synchronization.Configuration{
+		Ignores: []string{"*.log"},
+	}
+
+	withTestServer(t, configuration, func(server *Server, session, directory string) {
+		// Create alpha content.
+		content := map[string]string{
+			"file":           "file content",
+			"directory/file": "nested content",
+			"debug.log":      "ignored content",
+		}
+		alpha := filepath.Join(directory, "alpha")
+		for path, data := range content {
+			path = filepath.Join(alpha, filepath.FromSlash(path))
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				t.Fatal("unable to create directory:", err)
+			} else if err = ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+				t.Fatal("unable to create file:", err)
+			}
+		}
+
+		// Perform the export.
+		stream := &testExportStream{}
+		if err := server.Export(&ExportRequest{Session: session, Format: "tar"}, stream); err != nil {
+			t.Fatal("unable to export:", err)
+		}
+
+		// Verify the archive contents.
+		exported := make(map[string]string)
+		reader := tar.NewReader(&stream.data)
+		for {
+			header, err := reader.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal("unable to read archive:", err)
+			}
+			if header.Typeflag == tar.TypeReg {
+				data, err := ioutil.ReadAll(reader)
+				if err != nil {
+					t.Fatal("unable to read archive entry:", err)
+				}
+				exported[header.Name] = string(data)
+			}
+		}
+		if len(exported) != 2 {
+			t.Error("unexpected number of exported files:", len(exported))
+		}
+		for _, path := range []string{"file", "directory/file"} {
+			if exported[path] != content[path] {
+				t.Error("exported content incorrect for path:", path)
+			}
+		}
+
+		// Verify that an unsupported format is rejected.
+		if err := server.Export(&ExportRequest{Session: session, Format: "rar"}, &testExportStream{}); err == nil {
+			t.Error("unsupported format unexpectedly accepted")
+		}
+	})
+}
+
+// TestServerDiff tests Server.Diff.
+func TestServerDiff(t *testing.T) {
+	withTestServer(t, &synchronization.Configuration{}, func(server *Server, session, directory string) {
+		// Create alpha content. Since the session hasn't synchronized, all
+		// content will be reported as added.
+		alpha := filepath.Join(directory, "alpha")
+		if err := os.MkdirAll(filepath.Join(alpha, "directory"), 0700); err != nil {
+			t.Fatal("unable to create directory:", err)
+		} else if err = ioutil.WriteFile(filepath.Join(alpha, "directory", "file"), []byte("content"), 0600); err != nil {
+			t.Fatal("unable to create file:", err)
+		}
+
+		// Perform diffing.
+		response, err := server.Diff(context.Background(), &DiffRequest{
+			Prompter: "test",
+			Session:  session,
+		})
+		if err != nil {
+			t.Fatal("unable to diff:", err)
+		} else if err = response.EnsureValid(); err != nil {
+			t.Fatal("invalid response:", err)
+		}
+
+		// Verify the diff.
+		diff := response.Diff
+		if len(diff.Added) != 3 {
+			t.Fatal("unexpected number of added entries:", len(diff.Added))
+		}
+		for a, path := range []string{"", "directory", "directory/file"} {
+			if diff.Added[a].Path != path {
+				t.Errorf("unexpected added path at index %d: %s != %s", a, diff.Added[a].Path, path)
+			}
+		}
+		if len(diff.Removed) != 0 || len(diff.Modified) != 0 || len(diff.Moved) != 0 {
+			t.Error("unexpected removals, modifications, or moves")
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/mutagen-io/mutagen/pkg/selection"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
 	"github.com/mutagen-io/mutagen/pkg/url"
 )
 
@@ -29,6 +30,16 @@ func (s *CreationSpecification) ensureValid() error {
 		return errors.New("beta URL is not a synchronization URL")
 	}
 
+	// Verify that any additional beta URLs are valid and are synchronization
+	// URLs.
+	for _, b := range s.AdditionalBetas {
+		if err := b.EnsureValid(); err != nil {
+			return fmt.Errorf("invalid additional beta URL: %w", err)
+		} else if b.Kind != url.Kind_Synchronization {
+			return errors.New("additional beta URL is not a synchronization URL")
+		}
+	}
+
 	// Verify that the configuration is valid.
 	if err := s.Configuration.EnsureValid(false); err != nil {
 		return fmt.Errorf("invalid session configuration: %w", err)
@@ -156,6 +167,13 @@ func (r *FlushRequest) ensureValid() error {
 
 	// Any value of SkipWait is considered valid.
 
+	// Ensure that all ignore overrides are valid.
+	for _, override := range r.IgnoreOverrides {
+		if err := core.EnsureValidIgnoreOverride(override); err != nil {
+			return fmt.Errorf("invalid ignore override (%s): %w", override, err)
+		}
+	}
+
 	// Success.
 	return nil
 }
@@ -220,6 +238,11 @@ func (r *ResumeRequest) ensureValid() error {
 		return fmt.Errorf("invalid selection specification: %w", err)
 	}
 
+	// Ensure that at most one observation mode change has been requested.
+	if r.Observe && r.Live {
+		return errors.New("observation and live modes both requested")
+	}
+
 	// Success.
 	return nil
 }
@@ -298,3 +321,389 @@ func (r *TerminateResponse) EnsureValid() error {
 	// Success.
 	return nil
 }
+
+// ensureValid verifies that a RelocateRequest is valid.
+func (r *RelocateRequest) ensureValid() error {
+	// A nil relocate request is not valid.
+	if r == nil {
+		return errors.New("nil relocate request")
+	}
+
+	// Ensure that a prompter has been specified.
+	if r.Prompter == "" {
+		return errors.New("no prompter specified")
+	}
+
+	// Ensure that a session has been specified.
+	if r.Session == "" {
+		return errors.New("no session specified")
+	}
+
+	// There's no need to validate the Beta field - either value is valid.
+
+	// Verify that the URL is valid and is a synchronization URL.
+	if err := r.Url.EnsureValid(); err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	} else if r.Url.Kind != url.Kind_Synchronization {
+		return errors.New("URL is not a synchronization URL")
+	}
+
+	// Success.
+	return nil
+}
+
+// EnsureValid verifies that a RelocateResponse is valid.
+func (r *RelocateResponse) EnsureValid() error {
+	// A nil relocate response is not valid.
+	if r == nil {
+		return errors.New("nil relocate response")
+	}
+
+	// There's no need to validate the warning - any value is valid.
+
+	// Success.
+	return nil
+}
+
+// ensureValid verifies that a CompareRequest is valid.
+func (r *CompareRequest) ensureValid() error {
+	// A nil compare request is not valid.
+	if r == nil {
+		return errors.New("nil compare request")
+	}
+
+	// Ensure that a prompter has been specified.
+	if r.Prompter == "" {
+		return errors.New("no prompter specified")
+	}
+
+	// Verify that the alpha URL is valid and is a synchronization URL.
+	if err := r.Alpha.EnsureValid(); err != nil {
+		return fmt.Errorf("invalid alpha URL: %w", err)
+	} else if r.Alpha.Kind != url.Kind_Synchronization {
+		return errors.New("alpha URL is not a synchronization URL")
+	}
+
+	// Verify that the beta URL is valid and is a synchronization URL.
+	if err := r.Beta.EnsureValid(); err != nil {
+		return fmt.Errorf("invalid beta URL: %w", err)
+	} else if r.Beta.Kind != url.Kind_Synchronization {
+		return errors.New("beta URL is not a synchronization URL")
+	}
+
+	// Verify that the configuration is valid.
+	if err := r.Configuration.EnsureValid(false); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Verify that the alpha-specific configuration is valid.
+	if err := r.ConfigurationAlpha.EnsureValid(true); err != nil {
+		return fmt.Errorf("invalid alpha-specific configuration: %w", err)
+	}
+
+	// Verify that the beta-specific configuration is valid.
+	if err := r.ConfigurationBeta.EnsureValid(true); err != nil {
+		return fmt.Errorf("invalid beta-specific configuration: %w", err)
+	}
+
+	// Success.
+	return nil
+}
+
+// EnsureValid verifies that a CompareResponse is valid.
+func (r *CompareResponse) EnsureValid() error {
+	// A nil compare response is not valid.
+	if r == nil {
+		return errors.New("nil compare response")
+	}
+
+	// There's no need to validate the paths - any value is valid.
+
+	// Success.
+	return nil
+}
+
+// ensureValid verifies that a TailProblemsRequest is valid.
+func (r *TailProblemsRequest) ensureValid() error {
+	// A nil tail problems request is not valid.
+	if r == nil {
+		return errors.New("nil tail problems request")
+	}
+
+	// Validate the session specification.
+	if err := r.Selection.EnsureValid(); err != nil {
+		return fmt.Errorf("invalid selection specification: %w", err)
+	}
+
+	// Success.
+	return nil
+}
+
+// EnsureValid verifies that a TailProblemsResponse is valid.
+func (r *TailProblemsResponse) EnsureValid() error {
+	// A nil tail problems response is not valid.
+	if r == nil {
+		return errors.New("nil tail problems response")
+	}
+
+	// Ensure that all events are valid.
+	for _, e := range r.Events {
+		if err := e.EnsureValid(); err != nil {
+			return fmt.Errorf("invalid problem event: %w", err)
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// ensureValid verifies that a ReconnectRequest is valid.
+func (r *ReconnectRequest) ensureValid() error {
+	// A nil reconnect request is not valid.
+	if r == nil {
+		return errors.New("nil reconnect request")
+	}
+
+	// Ensure that a prompter has been specified.
+	if r.Prompter == "" {
+		return errors.New("no prompter specified")
+	}
+
+	// Ensure that a session has been specified.
+	if r.Session == "" {
+		return errors.New("no session specified")
+	}
+
+	// Success.
+	return nil
+}
+
+// EnsureValid verifies that a ReconnectResponse is valid.
+func (r *ReconnectResponse) EnsureValid() error {
+	// A nil reconnect response is not valid.
+	if r == nil {
+		return errors.New("nil reconnect response")
+	}
+
+	// Ensure that the session state is valid.
+	if err := r.State.EnsureValid(); err != nil {
+		return fmt.Errorf("invalid session state: %w", err)
+	}
+
+	// Success.
+	return nil
+}
+
+// ensureValid verifies that an UndoRequest is valid.
+func (r *UndoRequest) ensureValid() error {
+	// A nil undo request is not valid.
+	if r == nil {
+		return errors.New("nil undo request")
+	}
+
+	// Ensure that a prompter has been specified.
+	if r.Prompter == "" {
+		return errors.New("no prompter specified")
+	}
+
+	// Ensure that the session selection is valid.
+	if err := r.Selection.EnsureValid(); err != nil {
+		return fmt.Errorf("invalid selection specification: %w", err)
+	}
+
+	// Success.
+	return nil
+}
+
+// EnsureValid verifies that an UndoResponse is valid.
+func (r *UndoResponse) EnsureValid() error {
+	// A nil undo response is not valid.
+	if r == nil {
+		return errors.New("nil undo response")
+	}
+
+	// Success.
+	return nil
+}
+
+// ensureValid verifies that an ExplainIgnoreRequest is valid.
+func (r *ExplainIgnoreRequest) ensureValid() error {
+	// A nil explain ignore request is not valid.
+	if r == nil {
+		return errors.New("nil explain ignore request")
+	}
+
+	// Ensure that a session has been specified.
+	if r.Session == "" {
+		return errors.New("no session specified")
+	}
+
+	// There's no need to validate the path or directory fields, since the path
+	// is validated when evaluated.
+
+	// Success.
+	return nil
+}
+
+// EnsureValid verifies that an ExplainIgnoreResponse is valid.
+func (r *ExplainIgnoreResponse) EnsureValid() error {
+	// A nil explain ignore response is not valid.
+	if r == nil {
+		return errors.New("nil explain ignore response")
+	}
+
+	// Ensure that an ignored path is attributed to a pattern.
+	if r.Ignored && r.Pattern == "" {
+		return errors.New("ignored path not attributed to pattern")
+	}
+
+	// Success.
+	return nil
+}
+
+// ensureValid verifies that an ExplainActivityRequest is valid.
+func (r *ExplainActivityRequest) ensureValid() error {
+	// A nil explain activity request is not valid.
+	if r == nil {
+		return errors.New("nil explain activity request")
+	}
+
+	// Ensure that a session has been specified.
+	if r.Session == "" {
+		return errors.New("no session specified")
+	}
+
+	// Success.
+	return nil
+}
+
+// EnsureValid verifies that an ExplainActivityResponse is valid.
+func (r *ExplainActivityResponse) EnsureValid() error {
+	// A nil explain activity response is not valid.
+	if r == nil {
+		return errors.New("nil explain activity response")
+	}
+
+	// Ensure that the explanation is valid.
+	if err := r.Explanation.EnsureValid(); err != nil {
+		return fmt.Errorf("invalid activity explanation: %w", err)
+	}
+
+	// Success.
+	return nil
+}
+
+// ensureValid verifies that a ListStagedRequest is valid.
+func (r *ListStagedRequest) ensureValid() error {
+	// A nil list staged request is not valid.
+	if r == nil {
+		return errors.New("nil list staged request")
+	}
+
+	// Ensure that a session has been specified.
+	if r.Session == "" {
+		return errors.New("no session specified")
+	}
+
+	// There's no need to validate the Verify field - either value is valid.
+
+	// Success.
+	return nil
+}
+
+// EnsureValid verifies that a ListStagedResponse is valid.
+func (r *ListStagedResponse) EnsureValid() error {
+	// A nil list staged response is not valid.
+	if r == nil {
+		return errors.New("nil list staged response")
+	}
+
+	// Ensure that the alpha staged content is valid.
+	if err := r.Alpha.EnsureValid(); err != nil {
+		return fmt.Errorf("invalid alpha staged content: %w", err)
+	}
+
+	// Ensure that the beta staged content is valid.
+	if err := r.Beta.EnsureValid(); err != nil {
+		return fmt.Errorf("invalid beta staged content: %w", err)
+	}
+
+	// Success.
+	return nil
+}
+
+// ensureValid verifies that an ExportRequest is valid.
+func (r *ExportRequest) ensureValid() error {
+	// A nil export request is not valid.
+	if r == nil {
+		return errors.New("nil export request")
+	}
+
+	// Ensure that a session has been specified.
+	if r.Session == "" {
+		return errors.New("no session specified")
+	}
+
+	// There's no need to validate the Beta field - either value is valid.
+
+	// Ensure that the format is supported.
+	if r.Format != exportFormatTar && r.Format != exportFormatZip {
+		return fmt.Errorf("unsupported export format: %s", r.Format)
+	}
+
+	// Success.
+	return nil
+}
+
+// EnsureValid verifies that an ExportResponse is valid.
+func (r *ExportResponse) EnsureValid() error {
+	// A nil export response is not valid.
+	if r == nil {
+		return errors.New("nil export response")
+	}
+
+	// There's no need to validate the data - any value is valid.
+
+	// Success.
+	return nil
+}
+
+// ensureValid verifies that a DiffRequest is valid.
+func (r *DiffRequest) ensureValid() error {
+	// A nil diff request is not valid.
+	if r == nil {
+		return errors.New("nil diff request")
+	}
+
+	// Ensure that a prompter has been specified.
+	if r.Prompter == "" {
+		return errors.New("no prompter specified")
+	}
+
+	// Ensure that a session has been specified.
+	if r.Session == "" {
+		return errors.New("no session specified")
+	}
+
+	// There's no need to validate the Beta and DetectMoves fields - any values
+	// are valid.
+
+	// Success.
+	return nil
+}
+
+// EnsureValid verifies that a DiffResponse is valid.
+func (r *DiffResponse) EnsureValid() error {
+	// A nil diff response is not valid.
+	if r == nil {
+		return errors.New("nil diff response")
+	}
+
+	// Ensure that the diff is valid.
+	if err := r.Diff.EnsureValid(); err != nil {
+		return fmt.Errorf("invalid diff: %w", err)
+	}
+
+	// Success.
+	return nil
+}
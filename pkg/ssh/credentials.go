@@ -0,0 +1,185 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// credentialCommandTimeout is the maximum duration of a single credential
+	// command invocation.
+	credentialCommandTimeout = 30 * time.Second
+	// credentialCommandIdentityPrefix is the prefix for credential command
+	// output lines that specify an identity file.
+	credentialCommandIdentityPrefix = "identity "
+	// credentialCommandCertificatePrefix is the prefix for credential command
+	// output lines that specify a certificate file.
+	credentialCommandCertificatePrefix = "certificate "
+)
+
+// Credentials are authentication credentials to use for a single connection.
+type Credentials struct {
+	// IdentityFiles are the paths to identity (private key) files, in order of
+	// preference.
+	IdentityFiles []string
+	// CertificateFiles are the paths to certificate files, in order of
+	// preference.
+	CertificateFiles []string
+}
+
+// Flags converts the credentials to flags that can be passed to scp or ssh.
+// Credentials may be nil, in which case no flags are returned.
+func (c *Credentials) Flags() []string {
+	// Nil credentials correspond to no flags.
+	if c == nil {
+		return nil
+	}
+
+	// Add flags as necessary.
+	var result []string
+	for _, path := range c.IdentityFiles {
+		result = append(result, IdentityFileFlag(path))
+	}
+	for _, path := range c.CertificateFiles {
+		result = append(result, CertificateFileFlag(path))
+	}
+
+	// Done.
+	return result
+}
+
+// ensureValid ensures that Credentials' invariants are respected.
+func (c *Credentials) ensureValid() error {
+	for _, path := range c.IdentityFiles {
+		if path == "" {
+			return errors.New("empty identity file path")
+		}
+	}
+	for _, path := range c.CertificateFiles {
+		if path == "" {
+			return errors.New("empty certificate file path")
+		}
+	}
+	return nil
+}
+
+// CredentialProvider is the interface for types that provide authentication
+// credentials at connection time. Transports consult their provider each time
+// that they establish a connection (including reconnections), so providers
+// can return fresh credentials to replace those that have expired.
+type CredentialProvider interface {
+	// Credentials returns the credentials to use for a new connection. The
+	// returned credentials may be nil if no credentials are necessary.
+	Credentials(ctx context.Context) (*Credentials, error)
+}
+
+// staticCredentialProvider is a CredentialProvider that always returns the
+// same set of credential files.
+type staticCredentialProvider struct {
+	// credentials are the credentials to return.
+	credentials *Credentials
+}
+
+// NewStaticCredentialProvider creates a new credential provider that always
+// provides the specified identity and certificate files. Since the files are
+// read by OpenSSH on each connection, changes to their contents are still
+// picked up on reconnection.
+func NewStaticCredentialProvider(identityFiles, certificateFiles []string) (CredentialProvider, error) {
+	// Create and validate the credentials.
+	credentials := &Credentials{
+		IdentityFiles:    identityFiles,
+		CertificateFiles: certificateFiles,
+	}
+	if err := credentials.ensureValid(); err != nil {
+		return nil, errors.Wrap(err, "invalid credentials")
+	}
+
+	// Create the provider.
+	return &staticCredentialProvider{credentials}, nil
+}
+
+// Credentials implements CredentialProvider.Credentials.
+func (p *staticCredentialProvider) Credentials(_ context.Context) (*Credentials, error) {
+	return p.credentials, nil
+}
+
+// commandCredentialProvider is a CredentialProvider that obtains credentials
+// by invoking an external command.
+type commandCredentialProvider struct {
+	// command is the command name and its arguments.
+	command []string
+}
+
+// NewCommandCredentialProvider creates a new credential provider that invokes
+// the specified command (a command name followed by its arguments) each time
+// that credentials are requested. The command must print one credential per
+// line to standard output, with each line taking the form "identity <path>" or
+// "certificate <path>". Empty lines are ignored. The command must complete
+// within 30 seconds.
+func NewCommandCredentialProvider(command []string) (CredentialProvider, error) {
+	// Validate the command.
+	if len(command) == 0 || command[0] == "" {
+		return nil, errors.New("empty credential command")
+	}
+
+	// Create the provider.
+	return &commandCredentialProvider{command}, nil
+}
+
+// parseCredentialCommandOutput parses credential command output.
+func parseCredentialCommandOutput(output []byte) (*Credentials, error) {
+	result := &Credentials{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		} else if strings.HasPrefix(line, credentialCommandIdentityPrefix) {
+			path := strings.TrimSpace(line[len(credentialCommandIdentityPrefix):])
+			result.IdentityFiles = append(result.IdentityFiles, path)
+		} else if strings.HasPrefix(line, credentialCommandCertificatePrefix) {
+			path := strings.TrimSpace(line[len(credentialCommandCertificatePrefix):])
+			result.CertificateFiles = append(result.CertificateFiles, path)
+		} else {
+			return nil, errors.Errorf("unrecognized output line: %s", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "unable to read output")
+	}
+	if err := result.ensureValid(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Credentials implements CredentialProvider.Credentials.
+func (p *commandCredentialProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	// Create a subcontext that enforces the timeout and defer its
+	// cancellation.
+	ctx, cancel := context.WithTimeout(ctx, credentialCommandTimeout)
+	defer cancel()
+
+	// Run the command and check for timeouts and errors.
+	output, err := exec.CommandContext(ctx, p.command[0], p.command[1:]...).Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, errors.New("credential command timed out")
+	} else if err != nil {
+		return nil, errors.Wrap(err, "credential command failed")
+	}
+
+	// Parse the output.
+	credentials, err := parseCredentialCommandOutput(output)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid credential command output")
+	}
+
+	// Success.
+	return credentials, nil
+}
@@ -0,0 +1,117 @@
+package ssh
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestCredentialsFlags(t *testing.T) {
+	// Verify that nil credentials don't generate flags.
+	if flags := (*Credentials)(nil).Flags(); len(flags) != 0 {
+		t.Error("nil credentials generated flags:", flags)
+	}
+
+	// Verify that credentials generate the expected flags.
+	credentials := &Credentials{
+		IdentityFiles:    []string{"/key"},
+		CertificateFiles: []string{"/key-cert.pub"},
+	}
+	flags := credentials.Flags()
+	if len(flags) != 2 || flags[0] != "-oIdentityFile=/key" || flags[1] != "-oCertificateFile=/key-cert.pub" {
+		t.Error("credential flags incorrect:", flags)
+	}
+}
+
+func TestParseCredentialCommandOutput(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		output           string
+		expectFailure    bool
+		identityFiles    []string
+		certificateFiles []string
+	}{
+		{"", false, nil, nil},
+		{"identity /a\n\ncertificate /a-cert.pub\nidentity /b c\n", false, []string{"/a", "/b c"}, []string{"/a-cert.pub"}},
+		{"identity /a\r\n", false, []string{"/a"}, nil},
+		{"password hunter2\n", true, nil, nil},
+		{"identity \n", true, nil, nil},
+		{"certificate\n", true, nil, nil},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		credentials, err := parseCredentialCommandOutput([]byte(testCase.output))
+		if testCase.expectFailure {
+			if err == nil {
+				t.Errorf("test case %d: parsing succeeded unexpectedly", i)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("test case %d: unable to parse output: %v", i, err)
+			continue
+		}
+		if !stringSlicesEqual(credentials.IdentityFiles, testCase.identityFiles) {
+			t.Errorf("test case %d: identity files mismatch: %v != %v", i, credentials.IdentityFiles, testCase.identityFiles)
+		}
+		if !stringSlicesEqual(credentials.CertificateFiles, testCase.certificateFiles) {
+			t.Errorf("test case %d: certificate files mismatch: %v != %v", i, credentials.CertificateFiles, testCase.certificateFiles)
+		}
+	}
+}
+
+func TestStaticCredentialProvider(t *testing.T) {
+	// Verify that invalid credentials are rejected.
+	if _, err := NewStaticCredentialProvider([]string{""}, nil); err == nil {
+		t.Error("static credential provider created with empty identity file path")
+	}
+
+	// Create a static provider and verify that it provides its credentials.
+	provider, err := NewStaticCredentialProvider([]string{"/key"}, []string{"/key-cert.pub"})
+	if err != nil {
+		t.Fatal("unable to create static credential provider:", err)
+	}
+	credentials, err := provider.Credentials(context.Background())
+	if err != nil {
+		t.Fatal("unable to obtain credentials:", err)
+	} else if !stringSlicesEqual(credentials.IdentityFiles, []string{"/key"}) {
+		t.Error("identity files incorrect:", credentials.IdentityFiles)
+	} else if !stringSlicesEqual(credentials.CertificateFiles, []string{"/key-cert.pub"}) {
+		t.Error("certificate files incorrect:", credentials.CertificateFiles)
+	}
+}
+
+func TestCommandCredentialProvider(t *testing.T) {
+	// This test relies on a POSIX shell.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Verify that empty commands are rejected.
+	if _, err := NewCommandCredentialProvider(nil); err == nil {
+		t.Error("command credential provider created with empty command")
+	}
+
+	// Create a command provider and verify that it provides credentials.
+	provider, err := NewCommandCredentialProvider([]string{"sh", "-c", "echo identity /key; echo certificate /key-cert.pub"})
+	if err != nil {
+		t.Fatal("unable to create command credential provider:", err)
+	}
+	credentials, err := provider.Credentials(context.Background())
+	if err != nil {
+		t.Fatal("unable to obtain credentials:", err)
+	} else if !stringSlicesEqual(credentials.IdentityFiles, []string{"/key"}) {
+		t.Error("identity files incorrect:", credentials.IdentityFiles)
+	} else if !stringSlicesEqual(credentials.CertificateFiles, []string{"/key-cert.pub"}) {
+		t.Error("certificate files incorrect:", credentials.CertificateFiles)
+	}
+
+	// Verify that command failures are reported.
+	provider, err = NewCommandCredentialProvider([]string{"sh", "-c", "exit 1"})
+	if err != nil {
+		t.Fatal("unable to create command credential provider:", err)
+	}
+	if _, err := provider.Credentials(context.Background()); err == nil {
+		t.Error("credentials obtained from failing command")
+	}
+}
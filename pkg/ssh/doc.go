@@ -1,2 +1,8 @@
 // Package ssh provides utility functions for interfacing with OpenSSH.
+//
+// This package doesn't read or interpret OpenSSH configuration files itself.
+// All configuration handling (including host aliases and the resolution of
+// Include directives) is left to the OpenSSH executables that it invokes, with
+// options specified by Mutagen being passed as command line flags that take
+// precedence over configuration file settings.
 package ssh
@@ -0,0 +1,201 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+var (
+	// ErrCommandNotFound indicates that an OpenSSH command couldn't be
+	// located. Errors of type *CommandNotFoundError match this error.
+	ErrCommandNotFound = errors.New("command not found")
+	// ErrInvalidArgument indicates that an invalid argument was provided.
+	// Errors of type *InvalidArgumentError match this error.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrVersionUnsupported indicates that the OpenSSH installation isn't a
+	// supported version. Errors of type *VersionUnsupportedError match this
+	// error.
+	ErrVersionUnsupported = errors.New("unsupported OpenSSH version")
+	// ErrExecutionFailed indicates that an OpenSSH command failed to execute
+	// successfully. Errors of type *ExecutionError match this error.
+	ErrExecutionFailed = errors.New("execution failed")
+	// ErrSetEnvRejected indicates that OpenSSH rejected a SetEnv configuration
+	// option. Errors of type *SetEnvRejectedError match this error.
+	ErrSetEnvRejected = errors.New("SetEnv option rejected")
+	// ErrPrivilegeEscalationPasswordRequired indicates that privilege
+	// escalation on the remote failed because a password was required. Errors
+	// of type *PrivilegeEscalationPasswordRequiredError match this error.
+	ErrPrivilegeEscalationPasswordRequired = errors.New("privilege escalation requires a password")
+)
+
+// CommandNotFoundError is the error returned when an OpenSSH command can't be
+// located.
+type CommandNotFoundError struct {
+	// Command is the name of the command that couldn't be located.
+	Command string
+	// Err is the underlying lookup error.
+	Err error
+}
+
+// Error implements error.Error.
+func (e *CommandNotFoundError) Error() string {
+	return fmt.Sprintf("unable to identify '%s' command: %v", e.Command, e.Err)
+}
+
+// Unwrap returns the underlying lookup error.
+func (e *CommandNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// Is indicates whether or not the error matches the specified target. It
+// matches ErrCommandNotFound.
+func (e *CommandNotFoundError) Is(target error) bool {
+	return target == ErrCommandNotFound
+}
+
+// InvalidArgumentError is the error returned when an invalid argument is
+// provided.
+type InvalidArgumentError struct {
+	// Argument is a description of the invalid argument.
+	Argument string
+	// Reason is a description of why the argument is invalid.
+	Reason string
+}
+
+// Error implements error.Error.
+func (e *InvalidArgumentError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Argument, e.Reason)
+}
+
+// Is indicates whether or not the error matches the specified target. It
+// matches ErrInvalidArgument.
+func (e *InvalidArgumentError) Is(target error) bool {
+	return target == ErrInvalidArgument
+}
+
+// VersionUnsupportedError is the error returned when the OpenSSH installation
+// isn't a supported version.
+type VersionUnsupportedError struct {
+	// Version is the version reported by the OpenSSH installation. It may be
+	// empty if the version couldn't be determined.
+	Version string
+	// Minimum is the minimum supported version, if any.
+	Minimum string
+}
+
+// Error implements error.Error.
+func (e *VersionUnsupportedError) Error() string {
+	if e.Version == "" {
+		return "unable to determine OpenSSH version"
+	} else if e.Minimum == "" {
+		return fmt.Sprintf("unsupported OpenSSH version: %s", e.Version)
+	}
+	return fmt.Sprintf("unsupported OpenSSH version: %s (%s or later required)", e.Version, e.Minimum)
+}
+
+// Is indicates whether or not the error matches the specified target. It
+// matches ErrVersionUnsupported.
+func (e *VersionUnsupportedError) Is(target error) bool {
+	return target == ErrVersionUnsupported
+}
+
+// ExecutionError is the error returned when an OpenSSH command fails to execute
+// successfully.
+type ExecutionError struct {
+	// Command is the name of the command that failed.
+	Command string
+	// ExitCode is the exit code of the command. It is -1 if the command didn't
+	// exit normally (e.g. if it couldn't be started or was terminated by a
+	// signal).
+	ExitCode int
+	// Err is the underlying execution error.
+	Err error
+}
+
+// NewExecutionError creates an ExecutionError for the specified command based
+// on the error returned from running it, extracting the exit code if one is
+// available. It returns nil if err is nil.
+func NewExecutionError(command string, err error) error {
+	// Handle successful execution.
+	if err == nil {
+		return nil
+	}
+
+	// Extract the exit code, if any.
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+
+	// Create the error.
+	return &ExecutionError{
+		Command:  command,
+		ExitCode: exitCode,
+		Err:      err,
+	}
+}
+
+// Error implements error.Error.
+func (e *ExecutionError) Error() string {
+	if e.ExitCode < 0 {
+		return fmt.Sprintf("%s execution failed: %v", e.Command, e.Err)
+	}
+	return fmt.Sprintf("%s execution failed with exit code %d: %v", e.Command, e.ExitCode, e.Err)
+}
+
+// Unwrap returns the underlying execution error.
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// Is indicates whether or not the error matches the specified target. It
+// matches ErrExecutionFailed.
+func (e *ExecutionError) Is(target error) bool {
+	return target == ErrExecutionFailed
+}
+
+// SetEnvRejectedError is the error returned when OpenSSH rejects a SetEnv
+// configuration option.
+type SetEnvRejectedError struct {
+	// Output is the error output identifying the rejection.
+	Output string
+}
+
+// Error implements error.Error.
+func (e *SetEnvRejectedError) Error() string {
+	return fmt.Sprintf(
+		"SetEnv option rejected (OpenSSH 7.8 or later is required to set environment variables): %s",
+		e.Output,
+	)
+}
+
+// Is indicates whether or not the error matches the specified target. It
+// matches ErrSetEnvRejected.
+func (e *SetEnvRejectedError) Is(target error) bool {
+	return target == ErrSetEnvRejected
+}
+
+// PrivilegeEscalationPasswordRequiredError is the error returned when privilege
+// escalation on the remote fails because a password was required.
+type PrivilegeEscalationPasswordRequiredError struct {
+	// Command is the name of the privilege escalation command.
+	Command string
+	// Output is the error output identifying the failure.
+	Output string
+}
+
+// Error implements error.Error.
+func (e *PrivilegeEscalationPasswordRequiredError) Error() string {
+	return fmt.Sprintf(
+		"%s requires a password on the remote (passwordless privilege escalation must be configured for the agent): %s",
+		e.Command, e.Output,
+	)
+}
+
+// Is indicates whether or not the error matches the specified target. It
+// matches ErrPrivilegeEscalationPasswordRequired.
+func (e *PrivilegeEscalationPasswordRequiredError) Is(target error) bool {
+	return target == ErrPrivilegeEscalationPasswordRequired
+}
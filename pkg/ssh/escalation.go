@@ -0,0 +1,149 @@
+package ssh
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// privilegeEscalationNonInteractiveFlag is the flag that disables password
+	// prompting for all supported privilege escalation commands.
+	privilegeEscalationNonInteractiveFlag = "-n"
+)
+
+// supportedPrivilegeEscalationCommands is the set of supported privilege
+// escalation command names. Each must support non-interactive operation via
+// privilegeEscalationNonInteractiveFlag.
+var supportedPrivilegeEscalationCommands = map[string]bool{
+	"sudo": true,
+	"doas": true,
+}
+
+// privilegeEscalationPasswordRequiredFragments are (lowercase) fragments of the
+// error output that supported privilege escalation commands generate when a
+// password is required in non-interactive mode.
+var privilegeEscalationPasswordRequiredFragments = []string{
+	"a password is required",
+	"a terminal is required",
+	"authorization required",
+}
+
+// ensurePrivilegeEscalationValid ensures that a privilege escalation command is
+// valid. An empty command is valid and indicates that no escalation should be
+// performed.
+func ensurePrivilegeEscalationValid(command string) error {
+	// An empty command is valid.
+	if command == "" {
+		return nil
+	}
+
+	// Verify that the command doesn't contain characters that would terminate
+	// or otherwise split the remote command line.
+	if strings.ContainsAny(command, "\x00\r\n") {
+		return errors.New("invalid privilege escalation command: contains line break or null byte")
+	}
+
+	// Verify that the command is supported, since we need to know how to
+	// disable password prompting.
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return errors.New("invalid privilege escalation command: empty command name")
+	} else if !supportedPrivilegeEscalationCommands[fields[0]] {
+		return errors.Errorf("unsupported privilege escalation command: %s (only sudo and doas are supported)", fields[0])
+	}
+
+	// Success.
+	return nil
+}
+
+// EscalateCommand wraps the specified remote command with the privilege
+// escalation command (if any), ensuring that the non-interactive flag is
+// specified so that the command fails (rather than hanging on a password
+// prompt) if a password would be required. If no privilege escalation command
+// is specified, then the command is returned unmodified. The options may be
+// nil, but should be valid (as determined by EnsureValid).
+func (o *Options) EscalateCommand(command string) string {
+	// Handle the case of no escalation.
+	fields := strings.Fields(o.GetPrivilegeEscalation())
+	if len(fields) == 0 {
+		return command
+	}
+
+	// Enforce the non-interactive flag, inserting it immediately after the
+	// command name if it's not already present.
+	var nonInteractive bool
+	for _, field := range fields[1:] {
+		if field == privilegeEscalationNonInteractiveFlag {
+			nonInteractive = true
+			break
+		}
+	}
+	arguments := []string{fields[0]}
+	if !nonInteractive {
+		arguments = append(arguments, privilegeEscalationNonInteractiveFlag)
+	}
+	arguments = append(arguments, fields[1:]...)
+
+	// Add the command.
+	arguments = append(arguments, command)
+
+	// Done.
+	return strings.Join(arguments, " ")
+}
+
+// ClassifyPrivilegeEscalationFailure determines whether or not the error output
+// from a command wrapped by EscalateCommand indicates that privilege escalation
+// failed because a password was required, returning a
+// *PrivilegeEscalationPasswordRequiredError if so and nil otherwise. The
+// options may be nil, in which case nil is always returned.
+func (o *Options) ClassifyPrivilegeEscalationFailure(errorOutput string) error {
+	// If there's no escalation, then there's nothing to classify.
+	fields := strings.Fields(o.GetPrivilegeEscalation())
+	if len(fields) == 0 {
+		return nil
+	}
+
+	// Check each line of output originating from the escalation command.
+	prefix := fields[0] + ":"
+	for _, line := range strings.Split(errorOutput, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		lowered := strings.ToLower(line)
+		for _, fragment := range privilegeEscalationPasswordRequiredFragments {
+			if strings.Contains(lowered, fragment) {
+				return &PrivilegeEscalationPasswordRequiredError{Command: fields[0], Output: line}
+			}
+		}
+	}
+
+	// No failure was identified.
+	return nil
+}
+
+// PrivilegeEscalationCommandNotFound determines whether or not the error output
+// from a command wrapped by EscalateCommand indicates that the escalation
+// command was unable to locate the command being escalated. Privilege
+// escalation commands report this condition themselves (rather than via the
+// shell's "command not found" exit code), so it has to be detected separately.
+// The options may be nil, in which case false is always returned.
+func (o *Options) PrivilegeEscalationCommandNotFound(errorOutput string) bool {
+	// If there's no escalation, then there's nothing to classify.
+	fields := strings.Fields(o.GetPrivilegeEscalation())
+	if len(fields) == 0 {
+		return false
+	}
+
+	// Check each line of output originating from the escalation command.
+	prefix := fields[0] + ":"
+	for _, line := range strings.Split(errorOutput, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, prefix) &&
+			(strings.HasSuffix(line, "command not found") || strings.HasSuffix(line, "No such file or directory")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package ssh
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOptionsEscalateCommand(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		options  *Options
+		command  string
+		expected string
+	}{
+		{nil, "mutagen-agent synchronizer", "mutagen-agent synchronizer"},
+		{&Options{}, "mutagen-agent synchronizer", "mutagen-agent synchronizer"},
+		{&Options{PrivilegeEscalation: "sudo"}, "mutagen-agent synchronizer", "sudo -n mutagen-agent synchronizer"},
+		{&Options{PrivilegeEscalation: "sudo -n"}, "mutagen-agent synchronizer", "sudo -n mutagen-agent synchronizer"},
+		{&Options{PrivilegeEscalation: "sudo -u root"}, "mutagen-agent synchronizer", "sudo -n -u root mutagen-agent synchronizer"},
+		{&Options{PrivilegeEscalation: "sudo  -u root -n"}, "mutagen-agent synchronizer", "sudo -u root -n mutagen-agent synchronizer"},
+		{&Options{PrivilegeEscalation: "doas"}, "mutagen-agent synchronizer", "doas -n mutagen-agent synchronizer"},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		if escalated := testCase.options.EscalateCommand(testCase.command); escalated != testCase.expected {
+			t.Errorf("test case %d: escalated command does not match expected: %s != %s", i, escalated, testCase.expected)
+		}
+	}
+}
+
+func TestOptionsClassifyPrivilegeEscalationFailure(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		options          *Options
+		output           string
+		passwordRequired bool
+	}{
+		{nil, "sudo: a password is required\n", false},
+		{&Options{PrivilegeEscalation: "sudo"}, "sudo: a password is required\n", true},
+		{&Options{PrivilegeEscalation: "sudo"}, "Welcome!\nsudo: a terminal is required to read the password; either use the -S option to read from standard input or configure an askpass helper\n", true},
+		{&Options{PrivilegeEscalation: "doas"}, "doas: Authorization required\n", true},
+		{&Options{PrivilegeEscalation: "doas"}, "sudo: a password is required\n", false},
+		{&Options{PrivilegeEscalation: "sudo"}, "sudo: mutagen-agent: command not found\n", false},
+		{&Options{PrivilegeEscalation: "sudo"}, "", false},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		err := testCase.options.ClassifyPrivilegeEscalationFailure(testCase.output)
+		if !testCase.passwordRequired {
+			if err != nil {
+				t.Errorf("test case %d: failure incorrectly classified: %v", i, err)
+			}
+		} else if err == nil {
+			t.Errorf("test case %d: password requirement not classified", i)
+		} else if !errors.Is(err, ErrPrivilegeEscalationPasswordRequired) {
+			t.Errorf("test case %d: error does not match ErrPrivilegeEscalationPasswordRequired: %v", i, err)
+		}
+	}
+}
+
+func TestOptionsPrivilegeEscalationCommandNotFound(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		options  *Options
+		output   string
+		notFound bool
+	}{
+		{nil, "sudo: mutagen-agent: command not found\n", false},
+		{&Options{PrivilegeEscalation: "sudo"}, "sudo: mutagen-agent: command not found\n", true},
+		{&Options{PrivilegeEscalation: "doas"}, "doas: mutagen-agent: No such file or directory\n", true},
+		{&Options{PrivilegeEscalation: "sudo"}, "sudo: a password is required\n", false},
+		{&Options{PrivilegeEscalation: "sudo"}, "sh: 1: mutagen-agent: command not found\n", false},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		if notFound := testCase.options.PrivilegeEscalationCommandNotFound(testCase.output); notFound != testCase.notFound {
+			t.Errorf("test case %d: classification does not match expected: %t != %t", i, notFound, testCase.notFound)
+		}
+	}
+}
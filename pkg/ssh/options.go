@@ -0,0 +1,356 @@
+package ssh
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// strictHostKeyCheckingValues is the set of values supported by OpenSSH's
+// StrictHostKeyChecking configuration option.
+var strictHostKeyCheckingValues = map[string]bool{
+	"yes":        true,
+	"accept-new": true,
+	"no":         true,
+	"off":        true,
+	"ask":        true,
+}
+
+// EnsureValid ensures that Options' invariants are respected. Errors identify
+// the option that failed validation. A nil set of options is considered valid
+// and corresponds to the absence of any options.
+func (o *Options) EnsureValid() error {
+	// A nil set of options is valid.
+	if o == nil {
+		return nil
+	}
+
+	// Verify that the port is within the allowed range.
+	if o.Port > math.MaxUint16 {
+		return errors.Errorf("invalid port: %d", o.Port)
+	}
+
+	// Verify that identity file paths are non-empty.
+	for _, path := range o.IdentityFiles {
+		if path == "" {
+			return errors.New("invalid identity file: empty path")
+		}
+	}
+
+	// The proxy jump specification doesn't need to be validated - its
+	// interpretation is left to OpenSSH.
+
+	// Verify that the strict host key checking value is supported.
+	if o.StrictHostKeyChecking != "" && !strictHostKeyCheckingValues[o.StrictHostKeyChecking] {
+		return errors.Errorf("invalid strict host key checking value: %s", o.StrictHostKeyChecking)
+	}
+
+	// Verify that the user can't be misinterpreted as a flag or as part of the
+	// host specification.
+	if strings.HasPrefix(o.User, "-") || strings.ContainsAny(o.User, "@:/\\ ") {
+		return errors.Errorf("invalid user: %s", o.User)
+	}
+
+	// Verify that extra arguments are flags. Allowing non-flag arguments would
+	// allow them to be interpreted as the target host or remote command.
+	for _, argument := range o.ExtraArguments {
+		if !strings.HasPrefix(argument, "-") {
+			return errors.Errorf("invalid extra argument (%s): arguments must be flags", argument)
+		}
+	}
+
+	// Verify that the remote command prefix doesn't contain characters that
+	// would terminate or otherwise split the remote command line.
+	if strings.ContainsAny(o.RemoteCommandPrefix, "\x00\r\n") {
+		return errors.New("invalid remote command prefix: contains line break or null byte")
+	}
+
+	// Verify that environment variables can be represented using OpenSSH's
+	// SetEnv configuration option.
+	if err := ensureSetEnvValid(o.SetEnv); err != nil {
+		return err
+	}
+
+	// Verify that the credential command, if any, specifies a command name.
+	if len(o.CredentialCommand) > 0 && o.CredentialCommand[0] == "" {
+		return errors.New("invalid credential command: empty command name")
+	}
+
+	// Verify that the privilege escalation command, if any, is supported.
+	if err := ensurePrivilegeEscalationValid(o.PrivilegeEscalation); err != nil {
+		return err
+	}
+
+	// Success.
+	return nil
+}
+
+// Equal returns whether or not the options are equivalent to other options. A
+// nil set of options is considered equivalent to an empty set of options.
+func (o *Options) Equal(other *Options) bool {
+	// Convert nil options to empty options.
+	if o == nil {
+		o = &Options{}
+	}
+	if other == nil {
+		other = &Options{}
+	}
+
+	// Perform an equivalence check.
+	return o.Port == other.Port &&
+		stringSlicesEqual(o.IdentityFiles, other.IdentityFiles) &&
+		o.ProxyJump == other.ProxyJump &&
+		o.StrictHostKeyChecking == other.StrictHostKeyChecking &&
+		stringSlicesEqual(o.ExtraArguments, other.ExtraArguments) &&
+		o.User == other.User &&
+		o.ForcePTYForSetup == other.ForcePTYForSetup &&
+		o.RemoteCommandPrefix == other.RemoteCommandPrefix &&
+		stringMapsEqual(o.SetEnv, other.SetEnv) &&
+		stringSlicesEqual(o.CredentialCommand, other.CredentialCommand) &&
+		o.PrivilegeEscalation == other.PrivilegeEscalation
+}
+
+// stringSlicesEqual determines whether or not two string slices are equal.
+func stringSlicesEqual(first, second []string) bool {
+	// Check that slice lengths are equal.
+	if len(first) != len(second) {
+		return false
+	}
+
+	// Compare contents.
+	for i, f := range first {
+		if second[i] != f {
+			return false
+		}
+	}
+
+	// The slices are equal.
+	return true
+}
+
+// stringMapsEqual determines whether or not two string maps are equal. A nil
+// map is considered equal to an empty map.
+func stringMapsEqual(first, second map[string]string) bool {
+	// Check that map lengths are equal.
+	if len(first) != len(second) {
+		return false
+	}
+
+	// Compare contents.
+	for key, f := range first {
+		if s, ok := second[key]; !ok || s != f {
+			return false
+		}
+	}
+
+	// The maps are equal.
+	return true
+}
+
+// Flags converts the options to flags that can be passed to scp or ssh. The
+// port is not included since its flag differs between scp and ssh, nor is the
+// user since it's composed into the destination (see ResolveUser), nor is the
+// forced pseudo-terminal allocation since it only applies to certain commands
+// (see ForcePTYFlag), nor is the remote command prefix since it's composed into
+// the remote command (see WrapRemoteCommand), nor is the credential command
+// since its credentials are obtained at connection time (see
+// CredentialProvider), nor is the privilege escalation command since it's
+// composed into agent invocations (see EscalateCommand). The options should be
+// valid (as determined by EnsureValid).
+func (o *Options) Flags() []string {
+	// A nil set of options corresponds to no flags.
+	if o == nil {
+		return nil
+	}
+
+	// Add flags as necessary.
+	var result []string
+	for _, path := range o.IdentityFiles {
+		result = append(result, IdentityFileFlag(path))
+	}
+	if o.ProxyJump != "" {
+		result = append(result, ProxyJumpFlag(o.ProxyJump))
+	}
+	if o.StrictHostKeyChecking != "" {
+		result = append(result, StrictHostKeyCheckingFlag(o.StrictHostKeyChecking))
+	}
+	result = append(result, SetEnvArguments(o.SetEnv)...)
+	result = append(result, o.ExtraArguments...)
+
+	// Done.
+	return result
+}
+
+// ResolveUser determines the login user to use on the remote host given the
+// user (if any) specified in the endpoint URL. The user specified in the
+// options takes precedence, with the URL user used only if the options don't
+// specify a user. The options may be nil, in which case the URL user is used.
+func (o *Options) ResolveUser(urlUser string) string {
+	if user := o.GetUser(); user != "" {
+		return user
+	}
+	return urlUser
+}
+
+// quotePOSIXShellArgument quotes a value so that a POSIX shell will interpret it
+// as a single literal argument. The value is enclosed in single quotes, within
+// which no characters are special, with any embedded single quotes being
+// terminated, escaped, and reopened.
+func quotePOSIXShellArgument(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// WrapRemoteCommand wraps the specified remote command with the remote command
+// prefix (if any). The command is quoted and appended to the prefix as a single
+// argument, so the prefix should end with a command that accepts a command
+// string (e.g. "sh -c"). If no prefix is specified, then the command is returned
+// unmodified. The options may be nil.
+func (o *Options) WrapRemoteCommand(command string) string {
+	if prefix := o.GetRemoteCommandPrefix(); prefix != "" {
+		return prefix + " " + quotePOSIXShellArgument(command)
+	}
+	return command
+}
+
+// MergeOptions merges two sets of options of differing priorities. Each option
+// specified in the higher-priority set overrides the corresponding option in
+// the lower-priority set. Either set may be nil.
+func MergeOptions(lower, higher *Options) *Options {
+	// Convert nil options to empty options.
+	if lower == nil {
+		lower = &Options{}
+	}
+	if higher == nil {
+		higher = &Options{}
+	}
+
+	// Create the resulting options.
+	result := &Options{}
+
+	// Merge port.
+	if higher.Port != 0 {
+		result.Port = higher.Port
+	} else {
+		result.Port = lower.Port
+	}
+
+	// Merge identity files.
+	if len(higher.IdentityFiles) > 0 {
+		result.IdentityFiles = higher.IdentityFiles
+	} else {
+		result.IdentityFiles = lower.IdentityFiles
+	}
+
+	// Merge proxy jump specification.
+	if higher.ProxyJump != "" {
+		result.ProxyJump = higher.ProxyJump
+	} else {
+		result.ProxyJump = lower.ProxyJump
+	}
+
+	// Merge strict host key checking value.
+	if higher.StrictHostKeyChecking != "" {
+		result.StrictHostKeyChecking = higher.StrictHostKeyChecking
+	} else {
+		result.StrictHostKeyChecking = lower.StrictHostKeyChecking
+	}
+
+	// Merge extra arguments.
+	if len(higher.ExtraArguments) > 0 {
+		result.ExtraArguments = higher.ExtraArguments
+	} else {
+		result.ExtraArguments = lower.ExtraArguments
+	}
+
+	// Merge user.
+	if higher.User != "" {
+		result.User = higher.User
+	} else {
+		result.User = lower.User
+	}
+
+	// Merge forced pseudo-terminal allocation. It's enabled if either set
+	// enables it.
+	result.ForcePTYForSetup = lower.ForcePTYForSetup || higher.ForcePTYForSetup
+
+	// Merge remote command prefix.
+	if higher.RemoteCommandPrefix != "" {
+		result.RemoteCommandPrefix = higher.RemoteCommandPrefix
+	} else {
+		result.RemoteCommandPrefix = lower.RemoteCommandPrefix
+	}
+
+	// Merge environment variables. Like other options, a higher-priority set of
+	// variables replaces (rather than augments) a lower-priority set.
+	if len(higher.SetEnv) > 0 {
+		result.SetEnv = higher.SetEnv
+	} else {
+		result.SetEnv = lower.SetEnv
+	}
+
+	// Merge credential command.
+	if len(higher.CredentialCommand) > 0 {
+		result.CredentialCommand = higher.CredentialCommand
+	} else {
+		result.CredentialCommand = lower.CredentialCommand
+	}
+
+	// Merge privilege escalation command.
+	if higher.PrivilegeEscalation != "" {
+		result.PrivilegeEscalation = higher.PrivilegeEscalation
+	} else {
+		result.PrivilegeEscalation = lower.PrivilegeEscalation
+	}
+
+	// Done.
+	return result
+}
+
+// LoadOptionsFromURLParameters loads options from Mutagen URL parameters. The
+// supported parameters are "port", "identityfile" (which may specify multiple
+// comma-separated paths), "proxyjump", and "stricthostkeychecking". Extra
+// arguments and environment variables can't be specified via URL parameters,
+// nor can the user, which is instead specified in the URL's host component.
+func LoadOptionsFromURLParameters(parameters map[string]string) (*Options, error) {
+	// Create an empty result (corresponding to no options).
+	result := &Options{}
+
+	// Validate and convert parameters.
+	for key, value := range parameters {
+		switch key {
+		case "port":
+			port, err := strconv.ParseUint(value, 10, 16)
+			if err != nil || port == 0 {
+				return nil, errors.Errorf("port parameter has invalid value: %s", value)
+			}
+			result.Port = uint32(port)
+		case "identityfile":
+			if value == "" {
+				return nil, errors.New("identityfile parameter has empty value")
+			}
+			result.IdentityFiles = strings.Split(value, ",")
+		case "proxyjump":
+			if value == "" {
+				return nil, errors.New("proxyjump parameter has empty value")
+			}
+			result.ProxyJump = value
+		case "stricthostkeychecking":
+			if value == "" {
+				return nil, errors.New("stricthostkeychecking parameter has empty value")
+			}
+			result.StrictHostKeyChecking = value
+		default:
+			return nil, errors.Errorf("unknown parameter: %s", key)
+		}
+	}
+
+	// Ensure that the resulting options are valid.
+	if err := result.EnsureValid(); err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return result, nil
+}
@@ -0,0 +1,291 @@
+package ssh
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestOptionsEnsureValid(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		options       *Options
+		expectedError string
+	}{
+		{nil, ""},
+		{&Options{}, ""},
+		{&Options{Port: 22, IdentityFiles: []string{"/key"}, ProxyJump: "bastion", StrictHostKeyChecking: "yes", ExtraArguments: []string{"-4"}}, ""},
+		{&Options{Port: 65536}, "invalid port"},
+		{&Options{IdentityFiles: []string{""}}, "invalid identity file"},
+		{&Options{StrictHostKeyChecking: "sometimes"}, "invalid strict host key checking value"},
+		{&Options{ExtraArguments: []string{"host"}}, "invalid extra argument"},
+		{&Options{User: "deploy"}, ""},
+		{&Options{User: "-oProxyCommand=evil"}, "invalid user"},
+		{&Options{User: "deploy@example.org"}, "invalid user"},
+		{&Options{RemoteCommandPrefix: "sudo -u service sh -c"}, ""},
+		{&Options{RemoteCommandPrefix: "sudo -u service\nsh -c"}, "invalid remote command prefix"},
+		{&Options{SetEnv: map[string]string{"LANG": "en_US.UTF-8", "_private1": ""}}, ""},
+		{&Options{SetEnv: map[string]string{"1LANG": "C"}}, "invalid SetEnv variable name"},
+		{&Options{SetEnv: map[string]string{"MY-VAR": "C"}}, "invalid SetEnv variable name"},
+		{&Options{SetEnv: map[string]string{"": "C"}}, "invalid SetEnv variable name"},
+		{&Options{SetEnv: map[string]string{"LANG": "C\nEVIL=1"}}, "invalid SetEnv variable value"},
+		{&Options{SetEnv: map[string]string{"LANG": "C\r"}}, "invalid SetEnv variable value"},
+		{&Options{SetEnv: map[string]string{"LANG": "say \"hi\""}}, "invalid SetEnv variable value"},
+		{&Options{CredentialCommand: []string{"fetch-credentials", "--fresh"}}, ""},
+		{&Options{CredentialCommand: []string{"", "--fresh"}}, "invalid credential command"},
+		{&Options{PrivilegeEscalation: "sudo -u root"}, ""},
+		{&Options{PrivilegeEscalation: "doas"}, ""},
+		{&Options{PrivilegeEscalation: "su -c"}, "unsupported privilege escalation command"},
+		{&Options{PrivilegeEscalation: " "}, "invalid privilege escalation command"},
+		{&Options{PrivilegeEscalation: "sudo\nreboot"}, "invalid privilege escalation command"},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		err := testCase.options.EnsureValid()
+		if testCase.expectedError == "" {
+			if err != nil {
+				t.Errorf("test case %d: unexpected validation failure: %v", i, err)
+			}
+		} else if err == nil {
+			t.Errorf("test case %d: invalid options passed validation", i)
+		} else if !strings.Contains(err.Error(), testCase.expectedError) {
+			t.Errorf("test case %d: error does not identify failing option: %v", i, err)
+		}
+	}
+}
+
+func TestOptionsEqual(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		first    *Options
+		second   *Options
+		expected bool
+	}{
+		{nil, nil, true},
+		{nil, &Options{}, true},
+		{&Options{Port: 22}, nil, false},
+		{&Options{Port: 22}, &Options{Port: 22}, true},
+		{&Options{IdentityFiles: []string{"/a"}}, &Options{IdentityFiles: []string{"/b"}}, false},
+		{&Options{ExtraArguments: []string{"-4"}}, &Options{ExtraArguments: []string{"-4"}}, true},
+		{&Options{User: "first"}, &Options{User: "second"}, false},
+		{&Options{ForcePTYForSetup: true}, &Options{}, false},
+		{&Options{RemoteCommandPrefix: "sh -c"}, &Options{RemoteCommandPrefix: "sh -c"}, true},
+		{&Options{RemoteCommandPrefix: "sh -c"}, &Options{}, false},
+		{&Options{PrivilegeEscalation: "sudo"}, &Options{}, false},
+		{&Options{SetEnv: map[string]string{"A": "1"}}, &Options{SetEnv: map[string]string{"A": "1"}}, true},
+		{&Options{SetEnv: map[string]string{"A": "1"}}, &Options{SetEnv: map[string]string{"A": "2"}}, false},
+		{&Options{SetEnv: map[string]string{"A": ""}}, &Options{SetEnv: map[string]string{"B": ""}}, false},
+		{&Options{SetEnv: map[string]string{}}, &Options{}, true},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		if equal := testCase.first.Equal(testCase.second); equal != testCase.expected {
+			t.Errorf("test case %d: equality does not match expected: %t != %t", i, equal, testCase.expected)
+		}
+	}
+}
+
+func TestOptionsFlags(t *testing.T) {
+	// Create options.
+	options := &Options{
+		Port:                  2222,
+		IdentityFiles:         []string{"/first", "/second"},
+		ProxyJump:             "bastion",
+		StrictHostKeyChecking: "no",
+		ExtraArguments:        []string{"-4", "-C"},
+		User:                  "deploy",
+		ForcePTYForSetup:      true,
+		RemoteCommandPrefix:   "sh -c",
+		SetEnv:                map[string]string{"LANG": "C"},
+	}
+
+	// Compute the expected flags. The port, user, forced pseudo-terminal
+	// allocation, and remote command prefix aren't expected to be included.
+	expected := []string{
+		"-oIdentityFile=/first",
+		"-oIdentityFile=/second",
+		"-oProxyJump=bastion",
+		"-oStrictHostKeyChecking=no",
+		"-oSetEnv=LANG=C",
+		"-4",
+		"-C",
+	}
+
+	// Verify that the flags match.
+	flags := options.Flags()
+	if len(flags) != len(expected) {
+		t.Fatal("flag count mismatch:", len(flags), "!=", len(expected))
+	}
+	for f, flag := range flags {
+		if flag != expected[f] {
+			t.Error("flag mismatch:", flag, "!=", expected[f])
+		}
+	}
+
+	// Verify that nil options yield no flags.
+	if flags := (*Options)(nil).Flags(); len(flags) != 0 {
+		t.Error("nil options yielded flags:", flags)
+	}
+}
+
+func TestLoadOptionsFromURLParameters(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		parameters  map[string]string
+		expected    *Options
+		expectError bool
+	}{
+		{nil, &Options{}, false},
+		{map[string]string{"port": "2222"}, &Options{Port: 2222}, false},
+		{map[string]string{"port": "0"}, nil, true},
+		{map[string]string{"port": "65536"}, nil, true},
+		{map[string]string{"identityfile": "/a,/b"}, &Options{IdentityFiles: []string{"/a", "/b"}}, false},
+		{map[string]string{"identityfile": "/a,"}, nil, true},
+		{map[string]string{"proxyjump": "bastion"}, &Options{ProxyJump: "bastion"}, false},
+		{map[string]string{"proxyjump": ""}, nil, true},
+		{map[string]string{"stricthostkeychecking": "accept-new"}, &Options{StrictHostKeyChecking: "accept-new"}, false},
+		{map[string]string{"stricthostkeychecking": "sometimes"}, nil, true},
+		{map[string]string{"compression": "yes"}, nil, true},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		options, err := LoadOptionsFromURLParameters(testCase.parameters)
+		if testCase.expectError {
+			if err == nil {
+				t.Errorf("test case %d: invalid parameters loaded successfully", i)
+			}
+		} else if err != nil {
+			t.Errorf("test case %d: unable to load parameters: %v", i, err)
+		} else if !options.Equal(testCase.expected) {
+			t.Errorf("test case %d: options do not match expected: %v != %v", i, options, testCase.expected)
+		}
+	}
+}
+
+func TestMergeOptionsURLPrecedence(t *testing.T) {
+	// Create a set of options as they might be specified in a configuration.
+	configured := &Options{
+		Port:                  2222,
+		IdentityFiles:         []string{"/configured"},
+		ProxyJump:             "bastion",
+		StrictHostKeyChecking: "yes",
+		ExtraArguments:        []string{"-4"},
+		User:                  "configured",
+		ForcePTYForSetup:      true,
+		RemoteCommandPrefix:   "sudo -u service sh -c",
+		SetEnv:                map[string]string{"LANG": "C"},
+	}
+
+	// Load options from URL parameters.
+	fromURL, err := LoadOptionsFromURLParameters(map[string]string{
+		"port":         "2200",
+		"identityfile": "/url",
+	})
+	if err != nil {
+		t.Fatal("unable to load URL parameters:", err)
+	}
+
+	// Verify that URL-based options take precedence, with unspecified options
+	// falling back to their configured values.
+	expected := &Options{
+		Port:                  2200,
+		IdentityFiles:         []string{"/url"},
+		ProxyJump:             "bastion",
+		StrictHostKeyChecking: "yes",
+		ExtraArguments:        []string{"-4"},
+		User:                  "configured",
+		ForcePTYForSetup:      true,
+		RemoteCommandPrefix:   "sudo -u service sh -c",
+		SetEnv:                map[string]string{"LANG": "C"},
+	}
+	if merged := MergeOptions(configured, fromURL); !merged.Equal(expected) {
+		t.Error("merged options do not match expected:", merged, "!=", expected)
+	}
+
+	// Verify that merging handles nil options.
+	if merged := MergeOptions(nil, nil); !merged.Equal(nil) {
+		t.Error("merging nil options yielded non-empty options:", merged)
+	}
+	if merged := MergeOptions(configured, nil); !merged.Equal(configured) {
+		t.Error("merging with nil higher-priority options altered options:", merged)
+	}
+}
+
+func TestOptionsResolveUser(t *testing.T) {
+	// Define test cases covering a user specified only in the URL, only in the
+	// options, and in both, in which case the options take precedence.
+	testCases := []struct {
+		options  *Options
+		urlUser  string
+		expected string
+	}{
+		{nil, "", ""},
+		{nil, "url", "url"},
+		{&Options{}, "url", "url"},
+		{&Options{User: "configured"}, "", "configured"},
+		{&Options{User: "configured"}, "url", "configured"},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		if user := testCase.options.ResolveUser(testCase.urlUser); user != testCase.expected {
+			t.Errorf("test case %d: resolved user does not match expected: %s != %s", i, user, testCase.expected)
+		}
+	}
+}
+
+func TestOptionsWrapRemoteCommand(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		options  *Options
+		command  string
+		expected string
+	}{
+		{nil, "agent synchronizer", "agent synchronizer"},
+		{&Options{}, "agent synchronizer", "agent synchronizer"},
+		{
+			&Options{RemoteCommandPrefix: "sudo -u service sh -c"},
+			".mutagen/agents/0.12.0/mutagen-agent synchronizer",
+			"sudo -u service sh -c '.mutagen/agents/0.12.0/mutagen-agent synchronizer'",
+		},
+		{
+			&Options{RemoteCommandPrefix: "bash -lc"},
+			`echo "it's $HOME"`,
+			`bash -lc 'echo "it'\''s $HOME"'`,
+		},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		if wrapped := testCase.options.WrapRemoteCommand(testCase.command); wrapped != testCase.expected {
+			t.Errorf("test case %d: wrapped command does not match expected: %s != %s", i, wrapped, testCase.expected)
+		}
+	}
+}
+
+func TestOptionsWrapRemoteCommandShellRoundTrip(t *testing.T) {
+	// Skip this test on Windows, where there's no POSIX shell.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Wrap a command containing quotes and expansions with a representative
+	// environment-modifying wrapper.
+	options := &Options{RemoteCommandPrefix: "env WRAPPED=yes sh -c"}
+	command := `printf '%s|%s' "$WRAPPED" "it's"`
+	wrapped := options.WrapRemoteCommand(command)
+
+	// Verify that the wrapped command survives interpretation by a shell (as
+	// it would on the remote) and that the wrapper's environment is visible to
+	// the command.
+	output, err := exec.Command("sh", "-c", wrapped).Output()
+	if err != nil {
+		t.Fatal("unable to run wrapped command:", err)
+	} else if string(output) != "yes|it's" {
+		t.Error("wrapped command output does not match expected:", string(output))
+	}
+}
@@ -5,8 +5,9 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-
-	"github.com/pkg/errors"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/mutagen-io/mutagen/pkg/process"
 )
@@ -51,6 +52,208 @@ func ServerAliveFlags(interval, countMax int) []string {
 	}
 }
 
+// ForcePTYFlag returns a flag that can be passed to ssh to force pseudo-terminal
+// allocation, even if ssh's standard input isn't a terminal. Some legacy
+// servers refuse to execute commands without a pseudo-terminal, but a
+// pseudo-terminal's line discipline can corrupt binary data (e.g. by translating
+// line endings or interpreting control characters) and merges standard error
+// into standard output. This flag must therefore only be used for commands with
+// textual input and output, and never for binary streams such as agent
+// connections.
+func ForcePTYFlag() string {
+	return "-tt"
+}
+
+// ThirdPartyCopyFlag returns a flag that can be passed to scp to route a copy
+// between two remote hosts through the local host, rather than having the
+// source host connect directly to the destination host. This is necessary in
+// relay scenarios where the remote hosts can't reach one another. It's only
+// valid for copies where both the source and destination are remote.
+func ThirdPartyCopyFlag() string {
+	return "-3"
+}
+
+// StrictHostKeyCheckingFlag returns a flag that can be passed to scp or ssh to
+// control OpenSSH's StrictHostKeyChecking configuration option. The provided
+// value must be non-empty (e.g. "yes", "accept-new", or "no"), otherwise this
+// function will panic.
+func StrictHostKeyCheckingFlag(value string) string {
+	// Validate the value.
+	if value == "" {
+		panic("empty host key checking value")
+	}
+
+	// Format the flag.
+	return fmt.Sprintf("-oStrictHostKeyChecking=%s", value)
+}
+
+// UserKnownHostsFileFlag returns a flag that can be passed to scp or ssh to
+// control OpenSSH's UserKnownHostsFile configuration option. The provided path
+// must be non-empty, otherwise this function will panic.
+func UserKnownHostsFileFlag(path string) string {
+	// Validate the path.
+	if path == "" {
+		panic("empty known hosts file path")
+	}
+
+	// Format the flag.
+	return fmt.Sprintf("-oUserKnownHostsFile=%s", path)
+}
+
+// IdentityFileFlag returns a flag that can be passed to scp or ssh to control
+// OpenSSH's IdentityFile configuration option. The provided path must be
+// non-empty, otherwise this function will panic.
+func IdentityFileFlag(path string) string {
+	// Validate the path.
+	if path == "" {
+		panic("empty identity file path")
+	}
+
+	// Format the flag.
+	return fmt.Sprintf("-oIdentityFile=%s", path)
+}
+
+// CertificateFileFlag returns a flag that can be passed to scp or ssh to
+// control OpenSSH's CertificateFile configuration option. The provided path
+// must be non-empty, otherwise this function will panic.
+func CertificateFileFlag(path string) string {
+	// Validate the path.
+	if path == "" {
+		panic("empty certificate file path")
+	}
+
+	// Format the flag.
+	return fmt.Sprintf("-oCertificateFile=%s", path)
+}
+
+// ProxyJumpFlag returns a flag that can be passed to scp or ssh to control
+// OpenSSH's ProxyJump configuration option. The provided value must be
+// non-empty, otherwise this function will panic.
+func ProxyJumpFlag(value string) string {
+	// Validate the value.
+	if value == "" {
+		panic("empty proxy jump value")
+	}
+
+	// Format the flag.
+	return fmt.Sprintf("-oProxyJump=%s", value)
+}
+
+// environmentVariableNamePattern matches valid environment variable names.
+var environmentVariableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ensureSetEnvValid ensures that the specified environment variables can be
+// represented using OpenSSH's SetEnv configuration option, returning an
+// *InvalidArgumentError if not. Names must be valid environment variable names.
+// Values may not contain line breaks or null bytes, which would split or
+// truncate the configuration option, nor backslashes or double quotes, which
+// different OpenSSH versions interpret inconsistently.
+func ensureSetEnvValid(vars map[string]string) error {
+	for key, value := range vars {
+		if !environmentVariableNamePattern.MatchString(key) {
+			return &InvalidArgumentError{
+				Argument: "SetEnv variable name",
+				Reason:   fmt.Sprintf("%q is not a valid environment variable name", key),
+			}
+		} else if strings.ContainsAny(value, "\x00\r\n") {
+			return &InvalidArgumentError{
+				Argument: "SetEnv variable value",
+				Reason:   fmt.Sprintf("value for %s contains line break or null byte", key),
+			}
+		} else if strings.ContainsAny(value, "\\\"") {
+			return &InvalidArgumentError{
+				Argument: "SetEnv variable value",
+				Reason:   fmt.Sprintf("value for %s contains backslash or double quote", key),
+			}
+		}
+	}
+	return nil
+}
+
+// SetEnvArguments returns the flags that can be passed to scp or ssh to set the
+// specified environment variables on the remote host using OpenSSH's SetEnv
+// configuration option. Since OpenSSH only uses the first SetEnv option that it
+// obtains, all variables are combined into a single flag, with variables sorted
+// by name and with assignments containing whitespace or single quotes enclosed
+// in double quotes. If no variables are specified, then no flags are returned.
+// The variables must be valid (see Options.EnsureValid), otherwise this
+// function will panic.
+func SetEnvArguments(vars map[string]string) []string {
+	// Handle the case of no variables.
+	if len(vars) == 0 {
+		return nil
+	}
+
+	// Validate the variables.
+	if err := ensureSetEnvValid(vars); err != nil {
+		panic(err.Error())
+	}
+
+	// Sort the variable names to ensure a deterministic flag.
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Format the assignments.
+	assignments := make([]string, len(keys))
+	for k, key := range keys {
+		assignment := key + "=" + vars[key]
+		if strings.ContainsAny(assignment, " \t'") {
+			assignment = "\"" + assignment + "\""
+		}
+		assignments[k] = assignment
+	}
+
+	// Format the flag.
+	return []string{"-oSetEnv=" + strings.Join(assignments, " ")}
+}
+
+// setEnvRejectionFragments are (lowercase) fragments of the error output that
+// OpenSSH generates when rejecting a SetEnv configuration option.
+var setEnvRejectionFragments = []string{
+	"bad configuration option: setenv",
+	"unsupported option \"setenv\"",
+}
+
+// ClassifySetEnvRejection determines whether or not the error output from an
+// OpenSSH command indicates that a SetEnv configuration option (see
+// SetEnvArguments) was rejected, returning a *SetEnvRejectedError if so and nil
+// otherwise. Note that OpenSSH servers silently ignore variables that they
+// aren't configured to accept (rather than rejecting them), so such cases can't
+// be detected.
+func ClassifySetEnvRejection(errorOutput string) error {
+	// Check each line of output for a rejection.
+	for _, line := range strings.Split(errorOutput, "\n") {
+		lowered := strings.ToLower(line)
+		for _, fragment := range setEnvRejectionFragments {
+			if strings.Contains(lowered, fragment) {
+				return &SetEnvRejectedError{Output: strings.TrimSpace(line)}
+			}
+		}
+	}
+
+	// No rejection was identified.
+	return nil
+}
+
+// EphemeralHostFlags returns a set of flags that can be passed to scp or ssh to
+// treat the target host as ephemeral. Host keys for previously unseen hosts are
+// accepted automatically and are recorded to a null known hosts file, meaning
+// that every host is treated as previously unseen. This is designed for hosts
+// that are regularly recreated (e.g. throwaway CI hosts), but it significantly
+// reduces the security of the connection since it leaves it vulnerable to
+// man-in-the-middle attacks. We use /dev/null as the null known hosts file on
+// all platforms, since the OpenSSH implementations that we support on Windows
+// run inside POSIX-style environments (e.g. MSYS or Cygwin).
+func EphemeralHostFlags() []string {
+	return []string{
+		StrictHostKeyCheckingFlag("accept-new"),
+		UserKnownHostsFileFlag("/dev/null"),
+	}
+}
+
 // sshCommandPath returns the full path to use for invoking ssh. It will use the
 // MUTAGEN_SSH_PATH environment variable if provided, otherwise falling back to
 // a platform-specific implementation.
@@ -64,13 +267,34 @@ func sshCommandPath() (string, error) {
 	return sshCommandPathForPlatform()
 }
 
+// ensureCommandParametersValid ensures that the context and arguments for an
+// OpenSSH command are valid, returning an *InvalidArgumentError if not.
+func ensureCommandParametersValid(context context.Context, args []string) error {
+	if context == nil {
+		return &InvalidArgumentError{Argument: "context", Reason: "nil context"}
+	}
+	for _, arg := range args {
+		if strings.IndexByte(arg, 0) != -1 {
+			return &InvalidArgumentError{Argument: "command argument", Reason: "argument contains null byte"}
+		}
+	}
+	return nil
+}
+
 // SSHCommand prepares (but does not start) an SSH command with the specified
-// arguments and scoped to lifetime of the provided context.
+// arguments and scoped to lifetime of the provided context. If the context or
+// arguments are invalid, then an *InvalidArgumentError is returned. If the ssh
+// command can't be located, then a *CommandNotFoundError is returned.
 func SSHCommand(context context.Context, args ...string) (*exec.Cmd, error) {
+	// Validate parameters.
+	if err := ensureCommandParametersValid(context, args); err != nil {
+		return nil, err
+	}
+
 	// Identify the command name or path.
 	nameOrPath, err := sshCommandPath()
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to identify 'ssh' command")
+		return nil, &CommandNotFoundError{Command: "ssh", Err: err}
 	}
 
 	// Create the command.
@@ -91,14 +315,75 @@ func scpCommandPath() (string, error) {
 }
 
 // SCPCommand prepares (but does not start) an SCP command with the specified
-// arguments and scoped to lifetime of the provided context.
+// arguments and scoped to lifetime of the provided context. If the context or
+// arguments are invalid, then an *InvalidArgumentError is returned. If the scp
+// command can't be located, then a *CommandNotFoundError is returned.
 func SCPCommand(context context.Context, args ...string) (*exec.Cmd, error) {
+	// Validate parameters.
+	if err := ensureCommandParametersValid(context, args); err != nil {
+		return nil, err
+	}
+
 	// Identify the command name or path.
 	nameOrPath, err := scpCommandPath()
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to identify 'scp' command")
+		return nil, &CommandNotFoundError{Command: "scp", Err: err}
 	}
 
 	// Create the command.
 	return exec.CommandContext(context, nameOrPath, args...), nil
 }
+
+// isRemoteSCPOperand determines whether or not an scp operand refers to a
+// remote location. It uses the same heuristic as scp: an operand is remote if
+// it's an scp:// URI or if it contains a colon that isn't preceded by a path
+// separator.
+func isRemoteSCPOperand(operand string) bool {
+	if strings.HasPrefix(operand, "scp://") {
+		return true
+	}
+	colon := strings.IndexByte(operand, ':')
+	if colon < 1 {
+		return false
+	}
+	return !strings.ContainsAny(operand[:colon], "/\\")
+}
+
+// thirdPartySCPArguments computes the arguments for a third-party scp copy
+// between the specified source and destination, with the specified flags
+// preceding the operands. It returns an *InvalidArgumentError if either operand
+// isn't remote.
+func thirdPartySCPArguments(source, destination string, flags []string) ([]string, error) {
+	// Validate that both operands are remote.
+	if !isRemoteSCPOperand(source) {
+		return nil, &InvalidArgumentError{Argument: "third-party copy source", Reason: "location is not remote"}
+	} else if !isRemoteSCPOperand(destination) {
+		return nil, &InvalidArgumentError{Argument: "third-party copy destination", Reason: "location is not remote"}
+	}
+
+	// Compute the arguments.
+	arguments := make([]string, 0, len(flags)+3)
+	arguments = append(arguments, ThirdPartyCopyFlag())
+	arguments = append(arguments, flags...)
+	arguments = append(arguments, source, destination)
+
+	// Done.
+	return arguments, nil
+}
+
+// ThirdPartySCPCommand prepares (but does not start) an SCP command that copies
+// from one remote location to another, routing the transfer through the local
+// host (see ThirdPartyCopyFlag). The specified flags are passed to scp before
+// the source and destination operands. Both operands must be remote (i.e. of
+// the form [user@]host:path or scp://...), otherwise an *InvalidArgumentError
+// is returned. The command is scoped to the lifetime of the provided context.
+func ThirdPartySCPCommand(context context.Context, source, destination string, flags ...string) (*exec.Cmd, error) {
+	// Compute the arguments.
+	arguments, err := thirdPartySCPArguments(source, destination, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the command.
+	return SCPCommand(context, arguments...)
+}
@@ -1,9 +1,232 @@
 package ssh
 
 import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
 	"testing"
 )
 
+func TestStrictHostKeyCheckingFlag(t *testing.T) {
+	if flag := StrictHostKeyCheckingFlag("accept-new"); flag != "-oStrictHostKeyChecking=accept-new" {
+		t.Error("unexpected host key checking flag:", flag)
+	}
+}
+
+func TestUserKnownHostsFileFlag(t *testing.T) {
+	if flag := UserKnownHostsFileFlag("/dev/null"); flag != "-oUserKnownHostsFile=/dev/null" {
+		t.Error("unexpected known hosts file flag:", flag)
+	}
+}
+
+func TestIdentityFileFlag(t *testing.T) {
+	if flag := IdentityFileFlag("/home/user/.ssh/id_ed25519"); flag != "-oIdentityFile=/home/user/.ssh/id_ed25519" {
+		t.Error("unexpected identity file flag:", flag)
+	}
+}
+
+func TestProxyJumpFlag(t *testing.T) {
+	if flag := ProxyJumpFlag("bastion.example.com"); flag != "-oProxyJump=bastion.example.com" {
+		t.Error("unexpected proxy jump flag:", flag)
+	}
+}
+
+func TestSetEnvArguments(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		vars     map[string]string
+		expected []string
+	}{
+		{nil, nil},
+		{map[string]string{}, nil},
+		{map[string]string{"LANG": "C"}, []string{"-oSetEnv=LANG=C"}},
+		{map[string]string{"EMPTY": ""}, []string{"-oSetEnv=EMPTY="}},
+		{
+			map[string]string{"TZ": "UTC", "LANG": "en_US.UTF-8", "_A": "x=y"},
+			[]string{"-oSetEnv=LANG=en_US.UTF-8 TZ=UTC _A=x=y"},
+		},
+		{
+			map[string]string{"GREETING": "hello world", "NAME": "o'brien"},
+			[]string{`-oSetEnv="GREETING=hello world" "NAME=o'brien"`},
+		},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		arguments := SetEnvArguments(testCase.vars)
+		if len(arguments) != len(testCase.expected) {
+			t.Errorf("test case %d: argument count mismatch: %d != %d", i, len(arguments), len(testCase.expected))
+			continue
+		}
+		for a, argument := range arguments {
+			if argument != testCase.expected[a] {
+				t.Errorf("test case %d: argument mismatch: %s != %s", i, argument, testCase.expected[a])
+			}
+		}
+	}
+}
+
+func TestSetEnvArgumentsInvalidPanics(t *testing.T) {
+	// Define test cases.
+	testCases := []map[string]string{
+		{"BAD NAME": "value"},
+		{"9LIVES": "value"},
+		{"LANG": "C\nEVIL=1"},
+		{"LANG": "C\x00"},
+		{"PATH": `C:\\bin`},
+	}
+
+	// Process test cases.
+	for i, vars := range testCases {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("test case %d: invalid variables did not cause panic", i)
+				}
+			}()
+			SetEnvArguments(vars)
+		}()
+	}
+}
+
+func TestEnsureSetEnvValidErrorType(t *testing.T) {
+	err := ensureSetEnvValid(map[string]string{"LANG": "C\r\n"})
+	if err == nil {
+		t.Fatal("invalid value passed validation")
+	} else if !errors.Is(err, ErrInvalidArgument) {
+		t.Error("validation error does not match ErrInvalidArgument:", err)
+	}
+}
+
+func TestClassifySetEnvRejection(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		output   string
+		rejected bool
+	}{
+		{"", false},
+		{"Permission denied (publickey).", false},
+		{"command-line line 0: Bad configuration option: setenv\n", true},
+		{"Warning: Permanently added 'host'\ncommand-line: line 0: Bad configuration option: SetEnv", true},
+		{"command-line line 0: Bad configuration option: sendenv2", false},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		err := ClassifySetEnvRejection(testCase.output)
+		if testCase.rejected {
+			if err == nil {
+				t.Errorf("test case %d: rejection not identified", i)
+			} else if !errors.Is(err, ErrSetEnvRejected) {
+				t.Errorf("test case %d: rejection error does not match ErrSetEnvRejected: %v", i, err)
+			}
+		} else if err != nil {
+			t.Errorf("test case %d: rejection incorrectly identified: %v", i, err)
+		}
+	}
+}
+
+func TestEphemeralHostFlags(t *testing.T) {
+	// Compute the expected flags.
+	expected := []string{
+		"-oStrictHostKeyChecking=accept-new",
+		"-oUserKnownHostsFile=/dev/null",
+	}
+
+	// Verify that the flags match.
+	flags := EphemeralHostFlags()
+	if len(flags) != len(expected) {
+		t.Fatal("ephemeral host flag count mismatch:", len(flags), "!=", len(expected))
+	}
+	for f, flag := range flags {
+		if flag != expected[f] {
+			t.Error("ephemeral host flag mismatch:", flag, "!=", expected[f])
+		}
+	}
+}
+
+func TestThirdPartyCopyFlag(t *testing.T) {
+	if flag := ThirdPartyCopyFlag(); flag != "-3" {
+		t.Error("unexpected third-party copy flag:", flag)
+	}
+}
+
+func TestIsRemoteSCPOperand(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		operand  string
+		expected bool
+	}{
+		{"host:path", true},
+		{"user@host:path", true},
+		{"host:", true},
+		{"scp://user@host:2222/path", true},
+		{"path", false},
+		{"/absolute/path", false},
+		{"./relative:path", false},
+		{"directory/file:name", false},
+		{":path", false},
+		{"", false},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		if remote := isRemoteSCPOperand(testCase.operand); remote != testCase.expected {
+			t.Errorf("test case %d: remote status does not match expected: %t != %t", i, remote, testCase.expected)
+		}
+	}
+}
+
+func TestThirdPartySCPArguments(t *testing.T) {
+	// Compute arguments.
+	arguments, err := thirdPartySCPArguments(
+		"user@first:agent",
+		"second:agent",
+		[]string{CompressionFlag(), ConnectTimeoutFlag(5)},
+	)
+	if err != nil {
+		t.Fatal("unable to compute third-party copy arguments:", err)
+	}
+
+	// Verify that the arguments match.
+	expected := []string{"-3", "-C", "-oConnectTimeout=5", "user@first:agent", "second:agent"}
+	if len(arguments) != len(expected) {
+		t.Fatal("argument count mismatch:", len(arguments), "!=", len(expected))
+	}
+	for a, argument := range arguments {
+		if argument != expected[a] {
+			t.Error("argument mismatch:", argument, "!=", expected[a])
+		}
+	}
+}
+
+func TestThirdPartySCPArgumentsRequireRemoteEndpoints(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		source      string
+		destination string
+	}{
+		{"agent", "host:agent"},
+		{"host:agent", "agent"},
+		{"/local/agent", "/other/agent"},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		if _, err := thirdPartySCPArguments(testCase.source, testCase.destination, nil); err == nil {
+			t.Errorf("test case %d: third-party copy allowed with local endpoint", i)
+		}
+	}
+}
+
+func TestThirdPartySCPCommandLocalEndpoint(t *testing.T) {
+	if _, err := ThirdPartySCPCommand(context.Background(), "/local/agent", "host:agent"); err == nil {
+		t.Error("third-party copy command created with local source")
+	}
+}
+
 func TestSCPCommand(t *testing.T) {
 	if commandName, err := scpCommandPath(); err != nil {
 		t.Fatal("unable to locate SCP command:", err)
@@ -19,3 +242,99 @@ func TestSSHCommand(t *testing.T) {
 		t.Error("SSH command path is empty")
 	}
 }
+
+// testSetSearchPath sets the MUTAGEN_SSH_PATH environment variable for the
+// duration of a test, returning a function that restores its original value.
+func testSetSearchPath(t *testing.T, path string) func() {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Record the original value and set the new value.
+	original, set := os.LookupEnv("MUTAGEN_SSH_PATH")
+	if err := os.Setenv("MUTAGEN_SSH_PATH", path); err != nil {
+		t.Fatal("unable to set search path:", err)
+	}
+
+	// Create the restoration function.
+	return func() {
+		if set {
+			os.Setenv("MUTAGEN_SSH_PATH", original)
+		} else {
+			os.Unsetenv("MUTAGEN_SSH_PATH")
+		}
+	}
+}
+
+// testCommandConstructors are the command constructors tested for structured
+// error handling.
+var testCommandConstructors = []struct {
+	name        string
+	constructor func(context.Context, ...string) (*exec.Cmd, error)
+}{
+	{"ssh", SSHCommand},
+	{"scp", SCPCommand},
+}
+
+func TestCommandInvalidArgument(t *testing.T) {
+	for _, testCase := range testCommandConstructors {
+		// Verify that a nil context is rejected.
+		var invalid *InvalidArgumentError
+		if _, err := testCase.constructor(nil, "host"); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: nil context did not result in invalid argument error: %v", testCase.name, err)
+		} else if !errors.As(err, &invalid) || invalid.Argument != "context" {
+			t.Errorf("%s: nil context error has incorrect type or argument: %v", testCase.name, err)
+		}
+
+		// Verify that arguments containing null bytes are rejected.
+		if _, err := testCase.constructor(context.Background(), "host", "bad\x00argument"); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: null byte argument did not result in invalid argument error: %v", testCase.name, err)
+		}
+	}
+}
+
+func TestCommandNotFound(t *testing.T) {
+	// Create an empty search directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_ssh_search")
+	if err != nil {
+		t.Fatal("unable to create search directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Restrict the search path to the empty directory.
+	defer testSetSearchPath(t, directory)()
+
+	// Verify that command lookup fails with the correct error type.
+	for _, testCase := range testCommandConstructors {
+		var notFound *CommandNotFoundError
+		if _, err := testCase.constructor(context.Background()); !errors.Is(err, ErrCommandNotFound) {
+			t.Errorf("%s: missing command did not result in command not found error: %v", testCase.name, err)
+		} else if !errors.As(err, &notFound) || notFound.Command != testCase.name {
+			t.Errorf("%s: command not found error has incorrect type or command: %v", testCase.name, err)
+		} else if errors.Is(err, ErrInvalidArgument) || errors.Is(err, ErrExecutionFailed) {
+			t.Errorf("%s: command not found error matches incorrect sentinel", testCase.name)
+		}
+	}
+}
+
+func TestThirdPartySCPCommandInvalidArgument(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		source      string
+		destination string
+		argument    string
+	}{
+		{"/local/agent", "host:agent", "third-party copy source"},
+		{"host:agent", "/local/agent", "third-party copy destination"},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		var invalid *InvalidArgumentError
+		_, err := ThirdPartySCPCommand(context.Background(), testCase.source, testCase.destination)
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("test case %d: local operand did not result in invalid argument error: %v", i, err)
+		} else if !errors.As(err, &invalid) || invalid.Argument != testCase.argument {
+			t.Errorf("test case %d: invalid argument error has incorrect type or argument: %v", i, err)
+		}
+	}
+}
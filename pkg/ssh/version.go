@@ -0,0 +1,102 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionPattern matches the version information in the output of ssh -V for
+// OpenSSH (including the Win32-OpenSSH port).
+var versionPattern = regexp.MustCompile(`OpenSSH_(?:for_Windows_)?(\d+)\.(\d+)`)
+
+// Version represents an OpenSSH version.
+type Version struct {
+	// Major is the major version component.
+	Major int
+	// Minor is the minor version component.
+	Minor int
+}
+
+// String formats the version in the form used by OpenSSH.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// AtLeast returns whether or not the version is greater than or equal to the
+// specified minimum version.
+func (v Version) AtLeast(minimum Version) bool {
+	return v.Major > minimum.Major || (v.Major == minimum.Major && v.Minor >= minimum.Minor)
+}
+
+// parseVersion extracts the OpenSSH version from the output of ssh -V. It
+// returns a *VersionUnsupportedError if the output doesn't identify an OpenSSH
+// installation.
+func parseVersion(output string) (Version, error) {
+	// Extract the version components.
+	match := versionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return Version{}, &VersionUnsupportedError{Version: strings.TrimSpace(output)}
+	}
+
+	// Parse the version components. The pattern guarantees that these are
+	// digit sequences, so only overflow can cause failure here.
+	major, err := strconv.Atoi(match[1])
+	if err != nil {
+		return Version{}, &VersionUnsupportedError{Version: match[0]}
+	}
+	minor, err := strconv.Atoi(match[2])
+	if err != nil {
+		return Version{}, &VersionUnsupportedError{Version: match[0]}
+	}
+
+	// Success.
+	return Version{Major: major, Minor: minor}, nil
+}
+
+// SSHVersion determines the version of the OpenSSH installation by invoking
+// ssh -V. In addition to the errors returned by SSHCommand, it returns an
+// *ExecutionError if the version query fails and a *VersionUnsupportedError if
+// the installation doesn't identify itself as OpenSSH.
+func SSHVersion(ctx context.Context) (Version, error) {
+	// Create the command.
+	command, err := SSHCommand(ctx, "-V")
+	if err != nil {
+		return Version{}, err
+	}
+
+	// Run the command. OpenSSH prints its version information to standard
+	// error, so we capture both output streams.
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return Version{}, NewExecutionError("ssh", err)
+	}
+
+	// Parse the version.
+	return parseVersion(string(output))
+}
+
+// EnsureMinimumVersion ensures that the OpenSSH installation is at least the
+// specified version. It returns the same errors as SSHVersion, as well as a
+// *VersionUnsupportedError if the installation is older than the specified
+// version.
+func EnsureMinimumVersion(ctx context.Context, minimum Version) error {
+	// Determine the installed version.
+	version, err := SSHVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Compare the versions.
+	if !version.AtLeast(minimum) {
+		return &VersionUnsupportedError{
+			Version: version.String(),
+			Minimum: minimum.String(),
+		}
+	}
+
+	// Success.
+	return nil
+}
@@ -0,0 +1,91 @@
+// +build !windows
+
+package ssh
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testFakeSSH creates a temporary directory containing a fake ssh executable
+// with the specified shell script body and configures the OpenSSH search path
+// to use it. It returns a function that restores the search path and removes
+// the temporary directory.
+func testFakeSSH(t *testing.T, script string) func() {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create the temporary directory.
+	directory, err := ioutil.TempDir("", "mutagen_ssh_fake")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+
+	// Write the fake executable.
+	content := []byte("#!/bin/sh\n" + script + "\n")
+	if err := ioutil.WriteFile(filepath.Join(directory, "ssh"), content, 0700); err != nil {
+		os.RemoveAll(directory)
+		t.Fatal("unable to write fake ssh executable:", err)
+	}
+
+	// Configure the search path.
+	restore := testSetSearchPath(t, directory)
+
+	// Create the cleanup function.
+	return func() {
+		restore()
+		os.RemoveAll(directory)
+	}
+}
+
+func TestSSHVersion(t *testing.T) {
+	defer testFakeSSH(t, "echo 'OpenSSH_9.0p1, OpenSSL 3.0.2 15 Mar 2022' >&2")()
+	if version, err := SSHVersion(context.Background()); err != nil {
+		t.Fatal("unable to determine version:", err)
+	} else if version != (Version{9, 0}) {
+		t.Error("version mismatch:", version)
+	}
+	if err := EnsureMinimumVersion(context.Background(), Version{7, 6}); err != nil {
+		t.Error("supported version failed minimum version check:", err)
+	}
+}
+
+func TestSSHVersionExecutionFailure(t *testing.T) {
+	defer testFakeSSH(t, "exit 3")()
+	var execution *ExecutionError
+	if _, err := SSHVersion(context.Background()); !errors.Is(err, ErrExecutionFailed) {
+		t.Fatal("failed execution did not result in execution error:", err)
+	} else if !errors.As(err, &execution) {
+		t.Fatal("execution error has incorrect type")
+	} else if execution.ExitCode != 3 {
+		t.Error("execution error has incorrect exit code:", execution.ExitCode)
+	} else if execution.Command != "ssh" {
+		t.Error("execution error has incorrect command:", execution.Command)
+	}
+}
+
+func TestEnsureMinimumVersionUnsupported(t *testing.T) {
+	// Define test cases.
+	testCases := []string{
+		"echo 'OpenSSH_6.2p1, OSSLShim 0.9.8r 8 Dec 2011' >&2",
+		"echo 'Sun_SSH_1.1.4, SSH protocols 1.5/2.0' >&2",
+	}
+
+	// Process test cases.
+	for i, script := range testCases {
+		cleanup := testFakeSSH(t, script)
+		var unsupported *VersionUnsupportedError
+		if err := EnsureMinimumVersion(context.Background(), Version{7, 6}); !errors.Is(err, ErrVersionUnsupported) {
+			t.Errorf("test case %d: unsupported version did not result in unsupported version error: %v", i, err)
+		} else if !errors.As(err, &unsupported) {
+			t.Errorf("test case %d: unsupported version error has incorrect type", i)
+		} else if errors.Is(err, ErrExecutionFailed) {
+			t.Errorf("test case %d: unsupported version error matches execution failure", i)
+		}
+		cleanup()
+	}
+}
@@ -0,0 +1,82 @@
+package ssh
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+func TestVersionAtLeast(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		version  Version
+		minimum  Version
+		expected bool
+	}{
+		{Version{7, 6}, Version{7, 6}, true},
+		{Version{7, 9}, Version{7, 6}, true},
+		{Version{8, 0}, Version{7, 6}, true},
+		{Version{7, 5}, Version{7, 6}, false},
+		{Version{6, 9}, Version{7, 6}, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if result := testCase.version.AtLeast(testCase.minimum); result != testCase.expected {
+			t.Errorf("%s at least %s: %t != %t", testCase.version, testCase.minimum, result, testCase.expected)
+		}
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		output      string
+		expected    Version
+		expectError bool
+	}{
+		{"OpenSSH_9.2p1 Debian-2+deb12u3, OpenSSL 3.0.15 3 Sep 2024\n", Version{9, 2}, false},
+		{"OpenSSH_7.6p1, LibreSSL 2.6.2\n", Version{7, 6}, false},
+		{"OpenSSH_for_Windows_8.1p1, LibreSSL 3.0.2\r\n", Version{8, 1}, false},
+		{"Sun_SSH_1.1.4, SSH protocols 1.5/2.0\n", Version{}, true},
+		{"", Version{}, true},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		version, err := parseVersion(testCase.output)
+		if testCase.expectError {
+			var unsupported *VersionUnsupportedError
+			if !errors.Is(err, ErrVersionUnsupported) {
+				t.Errorf("test case %d: expected unsupported version error, got: %v", i, err)
+			} else if !errors.As(err, &unsupported) {
+				t.Errorf("test case %d: unsupported version error has incorrect type", i)
+			}
+		} else if err != nil {
+			t.Errorf("test case %d: unable to parse version: %v", i, err)
+		} else if version != testCase.expected {
+			t.Errorf("test case %d: version mismatch: %s != %s", i, version, testCase.expected)
+		}
+	}
+}
+
+func TestNewExecutionError(t *testing.T) {
+	// Verify that successful execution doesn't yield an error.
+	if err := NewExecutionError("ssh", nil); err != nil {
+		t.Error("successful execution yielded error:", err)
+	}
+
+	// Verify that failures without an exit code are recorded as such.
+	underlying := exec.ErrNotFound
+	var execution *ExecutionError
+	err := NewExecutionError("scp", underlying)
+	if !errors.Is(err, ErrExecutionFailed) {
+		t.Error("execution error does not match sentinel")
+	} else if !errors.Is(err, underlying) {
+		t.Error("execution error does not wrap underlying error")
+	} else if !errors.As(err, &execution) {
+		t.Error("execution error has incorrect type")
+	} else if execution.Command != "scp" || execution.ExitCode != -1 {
+		t.Error("execution error has incorrect command or exit code:", execution.Command, execution.ExitCode)
+	}
+}
@@ -0,0 +1,296 @@
+package synchronization
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+const (
+	// activityStormWindow is the window over which synchronization cycles are
+	// counted when detecting watcher storms.
+	activityStormWindow = time.Minute
+	// activityStormCycleThreshold is the number of synchronization cycles that
+	// must complete within activityStormWindow for the session to be
+	// considered to be experiencing a watcher storm.
+	activityStormCycleThreshold = 10
+	// activityPersistentChangeCycles is the number of consecutive
+	// synchronization cycles in which a path must be changed for the change to
+	// be considered persistent.
+	activityPersistentChangeCycles = 3
+	// activityMaximumTrackedPaths is the maximum number of changed paths
+	// tracked for each synchronization cycle.
+	activityMaximumTrackedPaths = 100
+	// activityMaximumExamplePaths is the maximum number of example paths
+	// included in an activity reason.
+	activityMaximumExamplePaths = 5
+)
+
+// EnsureValid ensures that ActivityReason's invariants are respected.
+func (r *ActivityReason) EnsureValid() error {
+	// A nil activity reason is not valid.
+	if r == nil {
+		return errors.New("nil activity reason")
+	}
+
+	// Ensure that the kind is valid.
+	if _, ok := ActivityReasonKind_name[int32(r.Kind)]; !ok {
+		return errors.New("unknown activity reason kind")
+	}
+
+	// Success.
+	return nil
+}
+
+// EnsureValid ensures that ActivityExplanation's invariants are respected.
+func (e *ActivityExplanation) EnsureValid() error {
+	// A nil activity explanation is not valid.
+	if e == nil {
+		return errors.New("nil activity explanation")
+	}
+
+	// Ensure that idle sessions have no reasons and that non-idle sessions
+	// have at least one.
+	if e.Idle && len(e.Reasons) > 0 {
+		return errors.New("idle session has activity reasons")
+	} else if !e.Idle && len(e.Reasons) == 0 {
+		return errors.New("non-idle session has no activity reasons")
+	}
+
+	// Ensure that all reasons are valid.
+	for _, reason := range e.Reasons {
+		if err := reason.EnsureValid(); err != nil {
+			return errors.Wrap(err, "invalid activity reason")
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// activityTracker tracks the history of recent synchronization cycles in order
+// to detect activity patterns that aren't apparent from the session state.
+// The zero value is ready for use.
+type activityTracker struct {
+	// cycleTimes are the completion times of recent synchronization cycles,
+	// ordered from oldest to newest.
+	cycleTimes []time.Time
+	// changedPaths are the paths changed by each of the most recent
+	// synchronization cycles, ordered from oldest to newest.
+	changedPaths [][]string
+}
+
+// recordCycle records the completion of a synchronization cycle at the
+// specified time that applied the specified transitions.
+func (t *activityTracker) recordCycle(now time.Time, transitions ...[]*core.Change) {
+	// Record the completion time, pruning times outside the storm window.
+	cutoff := now.Add(-activityStormWindow)
+	var retained int
+	for retained < len(t.cycleTimes) && t.cycleTimes[retained].Before(cutoff) {
+		retained++
+	}
+	t.cycleTimes = append(t.cycleTimes[retained:], now)
+
+	// Record the changed paths, retaining only the most recent cycles.
+	paths := make(map[string]bool)
+	for _, changes := range transitions {
+		for _, change := range changes {
+			if len(paths) == activityMaximumTrackedPaths {
+				break
+			}
+			paths[change.Path] = true
+		}
+	}
+	changed := make([]string, 0, len(paths))
+	for path := range paths {
+		changed = append(changed, path)
+	}
+	t.changedPaths = append(t.changedPaths, changed)
+	if len(t.changedPaths) > activityPersistentChangeCycles {
+		t.changedPaths = t.changedPaths[len(t.changedPaths)-activityPersistentChangeCycles:]
+	}
+}
+
+// stormCycles returns the number of synchronization cycles completed within
+// the storm window preceding the specified time.
+func (t *activityTracker) stormCycles(now time.Time) int {
+	cutoff := now.Add(-activityStormWindow)
+	var count int
+	for _, cycleTime := range t.cycleTimes {
+		if !cycleTime.Before(cutoff) {
+			count++
+		}
+	}
+	return count
+}
+
+// persistentPaths returns the paths changed in each of the most recent
+// activityPersistentChangeCycles synchronization cycles, in sorted order.
+func (t *activityTracker) persistentPaths() []string {
+	// If we haven't seen enough cycles, then no change can be persistent.
+	if len(t.changedPaths) < activityPersistentChangeCycles {
+		return nil
+	}
+
+	// Compute the intersection of the changed paths.
+	counts := make(map[string]int)
+	for _, changed := range t.changedPaths {
+		for _, path := range changed {
+			counts[path]++
+		}
+	}
+	var result []string
+	for path, count := range counts {
+		if count == len(t.changedPaths) {
+			result = append(result, path)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// examplePaths truncates a list of paths to activityMaximumExamplePaths.
+func examplePaths(paths []string) []string {
+	if len(paths) > activityMaximumExamplePaths {
+		return paths[:activityMaximumExamplePaths]
+	}
+	return paths
+}
+
+// explainActivity explains why the session is not idle using the session's
+// current state and recent synchronization history. The specified queue
+// position is the session's position in the manager's admission queue (with 0
+// indicating that the session isn't queued).
+func (c *controller) explainActivity(queuePosition uint64, now time.Time) *ActivityExplanation {
+	// Lock the session state and defer its release.
+	c.stateLock.Lock()
+	defer c.stateLock.UnlockWithoutNotify()
+
+	// If the session is paused, then that's the only relevant condition.
+	if c.session.Paused {
+		description := "Session is paused"
+		if c.session.PausedReason != "" {
+			description += ": " + c.session.PausedReason
+		}
+		return &ActivityExplanation{Reasons: []*ActivityReason{{
+			Kind:        ActivityReasonKind_ActivityReasonKindPaused,
+			Description: description,
+		}}}
+	}
+
+	// Track reasons.
+	var reasons []*ActivityReason
+
+	// Check for conditions identified by the session status.
+	switch status := c.state.Status; status {
+	case Status_Disconnected, Status_ConnectingAlpha, Status_ConnectingBeta:
+		description := status.Description()
+		if c.state.LastError != "" {
+			description += fmt.Sprintf(" (last error: %s)", c.state.LastError)
+		}
+		reasons = append(reasons, &ActivityReason{
+			Kind:        ActivityReasonKind_ActivityReasonKindDisconnected,
+			Description: description,
+		})
+	case Status_HaltedOnRootEmptied, Status_HaltedOnRootDeletion, Status_HaltedOnRootTypeChange:
+		reasons = append(reasons, &ActivityReason{
+			Kind:        ActivityReasonKind_ActivityReasonKindHalted,
+			Description: status.Description() + ", reset or recreate the session to continue",
+		})
+	case Status_StagingAlpha, Status_StagingBeta:
+		reason := &ActivityReason{
+			Kind:        ActivityReasonKind_ActivityReasonKindStaging,
+			Description: status.Description(),
+		}
+		if staging := c.state.StagingStatus; staging != nil {
+			if staging.Total > staging.Received {
+				reason.Count = staging.Total - staging.Received
+			}
+			reason.Description += fmt.Sprintf(" (%d of %d received)", staging.Received, staging.Total)
+			if staging.Path != "" {
+				reason.Paths = []string{staging.Path}
+			}
+		}
+		reasons = append(reasons, reason)
+	case Status_Scanning, Status_WaitingForRescan, Status_Reconciling, Status_Transitioning, Status_Saving:
+		reasons = append(reasons, &ActivityReason{
+			Kind:        ActivityReasonKind_ActivityReasonKindCycleInProgress,
+			Description: status.Description(),
+		})
+	}
+
+	// Check for admission queueing.
+	if queuePosition > 0 {
+		reasons = append(reasons, &ActivityReason{
+			Kind:        ActivityReasonKind_ActivityReasonKindQueued,
+			Description: fmt.Sprintf("Waiting for admission (queue position %d)", queuePosition),
+			Count:       queuePosition,
+		})
+	}
+
+	// Check for stalls.
+	if report := c.state.StallReport; report != nil {
+		reasons = append(reasons, &ActivityReason{
+			Kind:        ActivityReasonKind_ActivityReasonKindStalled,
+			Description: fmt.Sprintf("%s stalled", report.Status.Description()),
+		})
+	}
+
+	// Check for deferred transfers.
+	if deferred := c.state.DeferredTransfers; deferred > 0 {
+		reasons = append(reasons, &ActivityReason{
+			Kind:        ActivityReasonKind_ActivityReasonKindDeferredTransfers,
+			Description: fmt.Sprintf("%d transfer(s) deferred to subsequent cycles by the cycle transfer budget", deferred),
+			Count:       deferred,
+		})
+	}
+
+	// Check for conflicts.
+	if len(c.state.Conflicts) > 0 {
+		count := uint64(len(c.state.Conflicts)) + c.state.TruncatedConflicts
+		var paths []string
+		for _, conflict := range c.state.Conflicts {
+			if len(paths) == activityMaximumExamplePaths {
+				break
+			}
+			paths = append(paths, conflict.Root())
+		}
+		reasons = append(reasons, &ActivityReason{
+			Kind:        ActivityReasonKind_ActivityReasonKindConflicts,
+			Description: fmt.Sprintf("%d unresolved conflict(s)", count),
+			Count:       count,
+			Paths:       paths,
+		})
+	}
+
+	// Check for watcher storms.
+	if cycles := c.activity.stormCycles(now); cycles >= activityStormCycleThreshold {
+		reasons = append(reasons, &ActivityReason{
+			Kind:        ActivityReasonKind_ActivityReasonKindWatchStorm,
+			Description: fmt.Sprintf("%d synchronization cycles in the last %s", cycles, activityStormWindow),
+			Count:       uint64(cycles),
+		})
+	}
+
+	// Check for persistent changes.
+	if paths := c.activity.persistentPaths(); len(paths) > 0 {
+		reasons = append(reasons, &ActivityReason{
+			Kind: ActivityReasonKind_ActivityReasonKindPersistentChange,
+			Description: fmt.Sprintf("%d path(s) changed in each of the last %d cycles",
+				len(paths), activityPersistentChangeCycles,
+			),
+			Count: uint64(len(paths)),
+			Paths: examplePaths(paths),
+		})
+	}
+
+	// Done.
+	return &ActivityExplanation{
+		Idle:    len(reasons) == 0,
+		Reasons: reasons,
+	}
+}
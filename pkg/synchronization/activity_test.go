@@ -0,0 +1,306 @@
+package synchronization
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/mutagen-io/mutagen/pkg/state"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/rsync"
+)
+
+// TestActivityTracker tests activityTracker.
+func TestActivityTracker(t *testing.T) {
+	// Create a tracker and a reference time.
+	tracker := &activityTracker{}
+	now := time.Now()
+	changes := func(paths ...string) []*core.Change {
+		result := make([]*core.Change, len(paths))
+		for p, path := range paths {
+			result[p] = &core.Change{Path: path}
+		}
+		return result
+	}
+
+	// Record cycles that change overlapping paths.
+	tracker.recordCycle(now.Add(-2*activityStormWindow), changes("a", "b"))
+	tracker.recordCycle(now.Add(-2*time.Second), changes("a", "b"), changes("c"))
+	tracker.recordCycle(now.Add(-time.Second), changes("b"), changes("a"))
+	if paths := tracker.persistentPaths(); len(paths) != 2 || paths[0] != "a" || paths[1] != "b" {
+		t.Error("unexpected persistent paths:", paths)
+	}
+	if cycles := tracker.stormCycles(now); cycles != 2 {
+		t.Error("unexpected storm cycle count:", cycles, "!=", 2)
+	}
+
+	// Record a cycle that changes only one of the paths and verify that only
+	// the other path is no longer persistent.
+	tracker.recordCycle(now, changes("b"))
+	if paths := tracker.persistentPaths(); len(paths) != 1 || paths[0] != "b" {
+		t.Error("unexpected persistent paths:", paths)
+	}
+
+	// Record a cycle without changes and verify that nothing is persistent.
+	tracker.recordCycle(now)
+	if paths := tracker.persistentPaths(); len(paths) != 0 {
+		t.Error("unexpected persistent paths:", paths)
+	}
+
+	// Verify that cycle times outside the storm window were pruned.
+	if len(tracker.cycleTimes) != 4 {
+		t.Error("unexpected number of tracked cycle times:", len(tracker.cycleTimes))
+	}
+}
+
+// testActivityController creates a controller with the specified session and
+// state for activity explanation tests.
+func testActivityController(session *Session, status Status) *controller {
+	return &controller{
+		stateLock: state.NewTrackingLock(state.NewTracker()),
+		session:   session,
+		state: &State{
+			Session: session,
+			Status:  status,
+		},
+	}
+}
+
+// TestControllerExplainActivity tests that controllers explain the conditions
+// keeping them from being idle.
+func TestControllerExplainActivity(t *testing.T) {
+	// Create a reference time.
+	now := time.Now()
+
+	// Set up test cases.
+	testCases := []struct {
+		description   string
+		setup         func(c *controller)
+		queuePosition uint64
+		expectedKind  ActivityReasonKind
+		expectedCount uint64
+		expectedPaths []string
+		expectedText  string
+	}{
+		{
+			description: "paused",
+			setup: func(c *controller) {
+				c.session.Paused = true
+				c.session.PausedReason = "too many conflicts"
+			},
+			expectedKind: ActivityReasonKind_ActivityReasonKindPaused,
+			expectedText: "too many conflicts",
+		},
+		{
+			description: "disconnected",
+			setup: func(c *controller) {
+				c.state.Status = Status_Disconnected
+				c.state.LastError = "unable to connect to beta"
+			},
+			expectedKind: ActivityReasonKind_ActivityReasonKindDisconnected,
+			expectedText: "unable to connect to beta",
+		},
+		{
+			description: "halted",
+			setup: func(c *controller) {
+				c.state.Status = Status_HaltedOnRootEmptied
+			},
+			expectedKind: ActivityReasonKind_ActivityReasonKindHalted,
+			expectedText: "root emptying",
+		},
+		{
+			description:   "queued",
+			queuePosition: 2,
+			expectedKind:  ActivityReasonKind_ActivityReasonKindQueued,
+			expectedCount: 2,
+			expectedText:  "queue position 2",
+		},
+		{
+			description: "staging",
+			setup: func(c *controller) {
+				c.state.Status = Status_StagingBeta
+				c.state.StagingStatus = &rsync.ReceiverStatus{Path: "large/file", Received: 3, Total: 10}
+			},
+			expectedKind:  ActivityReasonKind_ActivityReasonKindStaging,
+			expectedCount: 7,
+			expectedPaths: []string{"large/file"},
+			expectedText:  "3 of 10",
+		},
+		{
+			description: "scanning",
+			setup: func(c *controller) {
+				c.state.Status = Status_Scanning
+			},
+			expectedKind: ActivityReasonKind_ActivityReasonKindCycleInProgress,
+			expectedText: "Scanning",
+		},
+		{
+			description: "stalled",
+			setup: func(c *controller) {
+				c.state.StallReport = &StallReport{
+					Status:            Status_Transitioning,
+					TimeSinceProgress: ptypes.DurationProto(time.Minute),
+				}
+			},
+			expectedKind: ActivityReasonKind_ActivityReasonKindStalled,
+			expectedText: "Applying changes stalled",
+		},
+		{
+			description: "deferred transfers",
+			setup: func(c *controller) {
+				c.state.DeferredTransfers = 12
+			},
+			expectedKind:  ActivityReasonKind_ActivityReasonKindDeferredTransfers,
+			expectedCount: 12,
+			expectedText:  "12 transfer(s)",
+		},
+		{
+			description: "conflicts",
+			setup: func(c *controller) {
+				c.state.Conflicts = []*core.Conflict{
+					{AlphaChanges: []*core.Change{{Path: "first"}}, BetaChanges: []*core.Change{{Path: "first"}}},
+					{AlphaChanges: []*core.Change{{Path: "second"}}, BetaChanges: []*core.Change{{Path: "second"}}},
+				}
+				c.state.TruncatedConflicts = 1
+			},
+			expectedKind:  ActivityReasonKind_ActivityReasonKindConflicts,
+			expectedCount: 3,
+			expectedPaths: []string{"first", "second"},
+			expectedText:  "3 unresolved",
+		},
+		{
+			description: "watch storm",
+			setup: func(c *controller) {
+				for i := 0; i < activityStormCycleThreshold; i++ {
+					c.activity.recordCycle(now.Add(-time.Duration(i) * time.Second))
+				}
+			},
+			expectedKind:  ActivityReasonKind_ActivityReasonKindWatchStorm,
+			expectedCount: activityStormCycleThreshold,
+		},
+		{
+			description: "persistent change",
+			setup: func(c *controller) {
+				for i := 0; i < activityPersistentChangeCycles; i++ {
+					c.activity.recordCycle(
+						now.Add(-time.Duration(i)*time.Minute),
+						[]*core.Change{{Path: "log.txt"}, {Path: "other"}},
+						[]*core.Change{{Path: "build/output"}},
+					)
+				}
+			},
+			expectedKind:  ActivityReasonKind_ActivityReasonKindPersistentChange,
+			expectedCount: 3,
+			expectedPaths: []string{"build/output", "log.txt", "other"},
+		},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		c := testActivityController(&Session{Identifier: "session"}, Status_Watching)
+		if testCase.setup != nil {
+			testCase.setup(c)
+		}
+		explanation := c.explainActivity(testCase.queuePosition, now)
+		if explanation.Idle {
+			t.Errorf("%s: session reported as idle", testCase.description)
+			continue
+		} else if len(explanation.Reasons) != 1 {
+			t.Errorf("%s: unexpected number of reasons: %d", testCase.description, len(explanation.Reasons))
+			continue
+		}
+		reason := explanation.Reasons[0]
+		if reason.Kind != testCase.expectedKind {
+			t.Errorf("%s: unexpected reason kind: %d != %d", testCase.description, reason.Kind, testCase.expectedKind)
+		}
+		if reason.Count != testCase.expectedCount {
+			t.Errorf("%s: unexpected count: %d != %d", testCase.description, reason.Count, testCase.expectedCount)
+		}
+		if len(reason.Paths) != len(testCase.expectedPaths) {
+			t.Errorf("%s: unexpected paths: %v", testCase.description, reason.Paths)
+		} else {
+			for p, path := range reason.Paths {
+				if path != testCase.expectedPaths[p] {
+					t.Errorf("%s: unexpected paths: %v", testCase.description, reason.Paths)
+					break
+				}
+			}
+		}
+		if !strings.Contains(reason.Description, testCase.expectedText) {
+			t.Errorf("%s: description doesn't contain %q: %s", testCase.description, testCase.expectedText, reason.Description)
+		}
+	}
+
+	// Verify that a watching session without any other conditions is idle.
+	c := testActivityController(&Session{Identifier: "session"}, Status_Watching)
+	c.activity.recordCycle(now)
+	if explanation := c.explainActivity(0, now); !explanation.Idle || len(explanation.Reasons) != 0 {
+		t.Error("watching session not reported as idle")
+	}
+}
+
+// TestControllerExplainActivityLive tests that activity explanations reflect
+// conditions arising from actual synchronization, namely conflicts and
+// persistently changing paths.
+func TestControllerExplainActivityLive(t *testing.T) {
+	// Create a controller whose endpoints have conflicting content.
+	content := map[string][]byte{
+		"conflicted": []byte("alpha"),
+		"changing":   []byte("0"),
+	}
+	c, parent, alpha, _ := testControllerWithSetup(t, &Configuration{}, content, nil, nil,
+		func(_, beta *testDirectoryEndpoint) {
+			if err := ioutil.WriteFile(filepath.Join(beta.root, "conflicted"), []byte("beta"), 0600); err != nil {
+				t.Fatal("unable to create beta content:", err)
+			}
+		},
+	)
+	defer os.RemoveAll(parent)
+
+	// Wait for the initial cycle and verify that the conflict is explained.
+	waitForSynchronizationCycles(t, c, 1)
+	explanation := c.explainActivity(0, time.Now())
+	if explanation.Idle || len(explanation.Reasons) != 1 {
+		t.Fatal("unexpected explanation after conflicting cycle:", explanation)
+	} else if reason := explanation.Reasons[0]; reason.Kind != ActivityReasonKind_ActivityReasonKindConflicts {
+		t.Error("unexpected reason kind:", reason.Kind)
+	} else if len(reason.Paths) != 1 || reason.Paths[0] != "conflicted" {
+		t.Error("unexpected conflict paths:", reason.Paths)
+	}
+
+	// Repeatedly modify a file, synchronizing after each modification, and
+	// verify that the persistent change is explained.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for i := 1; i <= activityPersistentChangeCycles; i++ {
+		if err := ioutil.WriteFile(filepath.Join(alpha.root, "changing"), []byte{byte('0' + i)}, 0600); err != nil {
+			t.Fatal("unable to modify file:", err)
+		} else if err = c.flush(ctx, "", false, nil, false); err != nil {
+			t.Fatal("flush failed:", err)
+		}
+	}
+	explanation = c.explainActivity(0, time.Now())
+	var found bool
+	for _, reason := range explanation.Reasons {
+		if reason.Kind == ActivityReasonKind_ActivityReasonKindPersistentChange {
+			found = true
+			if len(reason.Paths) != 1 || reason.Paths[0] != "changing" {
+				t.Error("unexpected persistent change paths:", reason.Paths)
+			}
+		}
+	}
+	if !found {
+		t.Error("persistent change not explained")
+	}
+
+	// Shut down the controller.
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+}
@@ -0,0 +1,244 @@
+package synchronization
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+)
+
+const (
+	// queuedPausedReason is the paused reason recorded for sessions that are
+	// paused because they're awaiting admission due to the manager's limit on
+	// active sessions.
+	queuedPausedReason = "queued awaiting admission"
+)
+
+// queued returns whether or not the session is paused awaiting admission.
+func (c *controller) queued() bool {
+	c.stateLock.Lock()
+	defer c.stateLock.UnlockWithoutNotify()
+	return c.session.Paused && c.session.PausedReason == queuedPausedReason
+}
+
+// active returns whether or not the session is active (i.e. not paused).
+func (c *controller) active() bool {
+	c.stateLock.Lock()
+	defer c.stateLock.UnlockWithoutNotify()
+	return !c.session.Paused
+}
+
+// enqueue marks a paused session as awaiting admission. It returns false if
+// the session isn't paused (in which case it's left untouched).
+func (c *controller) enqueue() (bool, error) {
+	// Lock the controller's lifecycle and defer its release.
+	c.lifecycleLock.Lock()
+	defer c.lifecycleLock.Unlock()
+
+	// Don't allow any operations if the controller is disabled.
+	if c.disabled {
+		return false, errors.New("controller disabled")
+	}
+
+	// If the session has a synchronization loop, then it isn't paused.
+	if c.cancel != nil {
+		return false, nil
+	}
+
+	// Record the paused reason and save the session.
+	c.stateLock.Lock()
+	c.session.PausedReason = queuedPausedReason
+	saveErr := encoding.MarshalAndSaveProtobuf(c.sessionPath, c.session)
+	c.stateLock.Unlock()
+	if saveErr != nil {
+		return true, errors.Wrap(saveErr, "unable to save session")
+	}
+
+	// Success.
+	return true, nil
+}
+
+// admit resumes a session awaiting admission. It returns false if the session
+// is no longer awaiting admission (e.g. because it was paused manually or
+// resumed by its schedule in the mean time), in which case it isn't resumed.
+func (c *controller) admit(ctx context.Context) (bool, error) {
+	// Lock the controller's lifecycle and defer its release.
+	c.lifecycleLock.Lock()
+	defer c.lifecycleLock.Unlock()
+
+	// If the controller is disabled or the session is no longer queued, then
+	// there's nothing to admit.
+	if c.disabled || !c.queued() {
+		return false, nil
+	}
+
+	// Resume the session. Even if resumption fails, the session will have a
+	// synchronization loop (which will attempt to reconnect), so it's still
+	// considered to be admitted.
+	c.logger.Info("Resuming session upon admission")
+	return true, c.resume(ctx, "", true)
+}
+
+// activeSessionCount computes the number of active sessions, including those
+// currently being admitted. The admission lock must be held by the caller.
+func (m *Manager) activeSessionCount() uint64 {
+	count := m.admitting
+	for _, c := range m.allControllers() {
+		if c.active() {
+			count++
+		}
+	}
+	return count
+}
+
+// atCapacity returns whether or not starting another session would exceed the
+// manager's limit on active sessions. The admission lock must be held by the
+// caller.
+func (m *Manager) atCapacity() bool {
+	return m.maximumActiveSessions > 0 && m.activeSessionCount() >= m.maximumActiveSessions
+}
+
+// pruneQueue removes sessions that are no longer awaiting admission from the
+// admission queue. The admission lock must be held by the caller.
+func (m *Manager) pruneQueue() {
+	// Grab the registry lock and defer its release.
+	m.sessionsLock.Lock()
+	defer m.sessionsLock.UnlockWithoutNotify()
+
+	// Filter the queue in-place.
+	queue := m.queue[:0]
+	for _, identifier := range m.queue {
+		if c, ok := m.sessions[identifier]; ok && c.queued() {
+			queue = append(queue, identifier)
+		}
+	}
+	m.queue = queue
+}
+
+// reserve determines whether or not a session may be started (or resumed)
+// without exceeding the manager's limit on active sessions. If so, then it
+// reserves a slot for the session and returns true, in which case the caller
+// must invoke release once the session has been started (or has failed to
+// start). A session is also denied admission if other sessions are awaiting
+// admission, so that queued sessions are admitted in order.
+func (m *Manager) reserve() bool {
+	// Grab the admission lock and defer its release.
+	m.admissionLock.Lock()
+	defer m.admissionLock.Unlock()
+
+	// If there's no limit, then no reservation is necessary.
+	if m.maximumActiveSessions == 0 {
+		m.admitting++
+		return true
+	}
+
+	// Check for capacity and queued sessions.
+	m.pruneQueue()
+	if len(m.queue) > 0 || m.atCapacity() {
+		return false
+	}
+
+	// Reserve a slot.
+	m.admitting++
+	return true
+}
+
+// release releases a slot reserved by reserve.
+func (m *Manager) release() {
+	m.admissionLock.Lock()
+	m.admitting--
+	m.admissionLock.Unlock()
+}
+
+// enqueue adds a paused session to the end of the admission queue.
+func (m *Manager) enqueue(c *controller) error {
+	// Grab the admission lock and defer its release.
+	m.admissionLock.Lock()
+	defer m.admissionLock.Unlock()
+
+	// If the session is already queued, then leave its position unchanged.
+	m.pruneQueue()
+	for _, identifier := range m.queue {
+		if identifier == c.session.Identifier {
+			return nil
+		}
+	}
+
+	// Mark the session as queued and add it to the queue.
+	if queued, err := c.enqueue(); err != nil {
+		return err
+	} else if queued {
+		c.logger.Info("Session queued awaiting admission")
+		m.queue = append(m.queue, c.session.Identifier)
+	}
+
+	// Success.
+	return nil
+}
+
+// admitQueuedSessions admits queued sessions, in the order in which they were
+// queued, until either the queue is empty or the limit on active sessions has
+// been reached.
+func (m *Manager) admitQueuedSessions(ctx context.Context) {
+	// Grab the admission lock and defer its release.
+	m.admissionLock.Lock()
+	defer m.admissionLock.Unlock()
+
+	// Admit sessions while there's capacity.
+	m.pruneQueue()
+	for len(m.queue) > 0 && !m.atCapacity() {
+		// Pop the session at the head of the queue.
+		identifier := m.queue[0]
+		m.queue = m.queue[1:]
+
+		// Look up the controller.
+		m.sessionsLock.Lock()
+		c, ok := m.sessions[identifier]
+		m.sessionsLock.UnlockWithoutNotify()
+		if !ok {
+			continue
+		}
+
+		// Admit the session.
+		if _, err := c.admit(ctx); err != nil {
+			m.logger.Warningf("Unable to resume session %s upon admission: %v", identifier, err)
+		}
+	}
+}
+
+// queuePositions computes the (1-based) admission queue positions of queued
+// sessions.
+func (m *Manager) queuePositions() map[string]uint64 {
+	// Grab the admission lock and defer its release.
+	m.admissionLock.Lock()
+	defer m.admissionLock.Unlock()
+
+	// Compute positions.
+	m.pruneQueue()
+	positions := make(map[string]uint64, len(m.queue))
+	for i, identifier := range m.queue {
+		positions[identifier] = uint64(i + 1)
+	}
+	return positions
+}
+
+// monitorAdmissions watches for session state changes and admits queued
+// sessions as active sessions are paused (including automatic pauses) or
+// terminated. It runs until state tracking is terminated and should be invoked
+// in a background Goroutine.
+func (m *Manager) monitorAdmissions() {
+	// Loop until state tracking is terminated.
+	var stateIndex uint64
+	for {
+		// Wait for a state change.
+		var poisoned bool
+		stateIndex, poisoned = m.tracker.WaitForChange(stateIndex)
+		if poisoned {
+			return
+		}
+
+		// Admit any queued sessions for which there's now capacity.
+		m.admitQueuedSessions(context.Background())
+	}
+}
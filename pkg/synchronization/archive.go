@@ -0,0 +1,63 @@
+package synchronization
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// loadArchive loads the archive at the specified path. If the archive fails
+// integrity validation, can't be decoded, or contains an invalid ancestor, then
+// it's treated as corrupted, in which case a warning is logged and an empty
+// archive is returned. Falling back to an empty ancestor is safe, since it only
+// results in a more conservative reconciliation (in which deletions aren't
+// propagated and differing content is treated as conflicting). If the archive
+// doesn't exist, then the resulting error can be checked with os.IsNotExist.
+func loadArchive(logger *logging.Logger, path string) (*core.Archive, error) {
+	// Load the archive, falling back to an empty archive if it's corrupted.
+	archive := &core.Archive{}
+	if err := encoding.LoadAndUnmarshalChecksummedProtobuf(path, archive); err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		} else if errors.Cause(err) != encoding.ErrCorrupted {
+			return nil, errors.Wrap(err, "unable to load archive")
+		}
+		logger.Warning("Corrupted archive found on disk, using empty ancestor:", err)
+		return &core.Archive{}, nil
+	}
+
+	// Validate the ancestor, falling back to an empty archive if it's invalid.
+	if err := archive.Root.EnsureValid(); err != nil {
+		logger.Warning("Invalid archive found on disk, using empty ancestor:", err)
+		return &core.Archive{}, nil
+	}
+
+	// Success.
+	return archive, nil
+}
+
+// saveArchive saves an archive to the specified path along with the integrity
+// information necessary for loadArchive to detect corruption. If the specified
+// compression mode is ArchiveCompressionMode_ArchiveCompressionModeGzip, then
+// the archive is compressed. The compression mode should already be resolved
+// against the session version's default. Since the saved format is
+// self-describing, loadArchive can load archives saved under any mode.
+func saveArchive(path string, archive *core.Archive, compression ArchiveCompressionMode) error {
+	if compression == ArchiveCompressionMode_ArchiveCompressionModeGzip {
+		return encoding.MarshalAndSaveCompressedChecksummedProtobuf(path, archive)
+	}
+	return encoding.MarshalAndSaveChecksummedProtobuf(path, archive)
+}
+
+// archiveCompressionMode returns the archive compression mode for the session,
+// resolved against the session version's default.
+func (c *controller) archiveCompressionMode() ArchiveCompressionMode {
+	if mode := c.session.Configuration.ArchiveCompressionMode; !mode.IsDefault() {
+		return mode
+	}
+	return c.session.Version.DefaultArchiveCompressionMode()
+}
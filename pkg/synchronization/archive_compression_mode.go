@@ -0,0 +1,58 @@
+package synchronization
+
+import (
+	"github.com/pkg/errors"
+)
+
+// IsDefault indicates whether or not the archive compression mode is
+// ArchiveCompressionMode_ArchiveCompressionModeDefault.
+func (m ArchiveCompressionMode) IsDefault() bool {
+	return m == ArchiveCompressionMode_ArchiveCompressionModeDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (m *ArchiveCompressionMode) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to a archive compression mode.
+	switch text {
+	case "none":
+		*m = ArchiveCompressionMode_ArchiveCompressionModeNone
+	case "gzip":
+		*m = ArchiveCompressionMode_ArchiveCompressionModeGzip
+	default:
+		return errors.Errorf("unknown archive compression mode specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular archive compression mode is a
+// valid, non-default value.
+func (m ArchiveCompressionMode) Supported() bool {
+	switch m {
+	case ArchiveCompressionMode_ArchiveCompressionModeNone:
+		return true
+	case ArchiveCompressionMode_ArchiveCompressionModeGzip:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of a archive compression mode.
+func (m ArchiveCompressionMode) Description() string {
+	switch m {
+	case ArchiveCompressionMode_ArchiveCompressionModeDefault:
+		return "Default"
+	case ArchiveCompressionMode_ArchiveCompressionModeNone:
+		return "None"
+	case ArchiveCompressionMode_ArchiveCompressionModeGzip:
+		return "Gzip"
+	default:
+		return "Unknown"
+	}
+}
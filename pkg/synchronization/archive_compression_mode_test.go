@@ -0,0 +1,91 @@
+package synchronization
+
+import (
+	"testing"
+)
+
+// TestArchiveCompressionModeUnmarshal tests that unmarshaling from a string
+// specification succeeeds for ArchiveCompressionMode.
+func TestArchiveCompressionModeUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text          string
+		expectedMode  ArchiveCompressionMode
+		expectFailure bool
+	}{
+		{"", ArchiveCompressionMode_ArchiveCompressionModeDefault, true},
+		{"asdf", ArchiveCompressionMode_ArchiveCompressionModeDefault, true},
+		{"none", ArchiveCompressionMode_ArchiveCompressionModeNone, false},
+		{"gzip", ArchiveCompressionMode_ArchiveCompressionModeGzip, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var mode ArchiveCompressionMode
+		if err := mode.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if mode != testCase.expectedMode {
+			t.Errorf(
+				"unmarshaled mode (%s) does not match expected (%s)",
+				mode,
+				testCase.expectedMode,
+			)
+		}
+	}
+}
+
+// TestArchiveCompressionModeSupported tests that ArchiveCompressionMode support detection
+// works as expected.
+func TestArchiveCompressionModeSupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode            ArchiveCompressionMode
+		expectSupported bool
+	}{
+		{ArchiveCompressionMode_ArchiveCompressionModeDefault, false},
+		{ArchiveCompressionMode_ArchiveCompressionModeNone, true},
+		{ArchiveCompressionMode_ArchiveCompressionModeGzip, true},
+		{(ArchiveCompressionMode_ArchiveCompressionModeGzip + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.mode.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"mode support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestArchiveCompressionModeDescription tests that ArchiveCompressionMode description
+// generation works as expected.
+func TestArchiveCompressionModeDescription(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                ArchiveCompressionMode
+		expectedDescription string
+	}{
+		{ArchiveCompressionMode_ArchiveCompressionModeDefault, "Default"},
+		{ArchiveCompressionMode_ArchiveCompressionModeNone, "None"},
+		{ArchiveCompressionMode_ArchiveCompressionModeGzip, "Gzip"},
+		{(ArchiveCompressionMode_ArchiveCompressionModeGzip + 1), "Unknown"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if description := testCase.mode.Description(); description != testCase.expectedDescription {
+			t.Errorf(
+				"mode description (%s) does not match expected (%s)",
+				description,
+				testCase.expectedDescription,
+			)
+		}
+	}
+}
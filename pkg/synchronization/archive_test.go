@@ -0,0 +1,270 @@
+package synchronization
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// testArchive is an archive used for archive loading tests.
+var testArchive = &core.Archive{
+	Root: &core.Entry{
+		Kind: core.EntryKind_Directory,
+		Contents: map[string]*core.Entry{
+			"file": {
+				Kind:   core.EntryKind_File,
+				Digest: []byte{0, 1, 2, 3},
+			},
+		},
+	},
+}
+
+// captureWarnings redirects standard logger output to a buffer for the
+// duration of the specified callback and returns the captured output.
+func captureWarnings(callback func()) string {
+	buffer := &bytes.Buffer{}
+	log.SetOutput(buffer)
+	defer log.SetOutput(os.Stderr)
+	callback()
+	return buffer.String()
+}
+
+// TestLoadArchive tests that loadArchive loads valid archives, including those
+// saved without integrity information.
+func TestLoadArchive(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_archive")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Verify that a non-existent archive is reported as such.
+	path := filepath.Join(directory, "archive")
+	if _, err := loadArchive(nil, path); !os.IsNotExist(err) {
+		t.Error("unexpected error for non-existent archive:", err)
+	}
+
+	// Verify that a checksummed archive can be saved and loaded.
+	if err := saveArchive(path, testArchive, ArchiveCompressionMode_ArchiveCompressionModeNone); err != nil {
+		t.Fatal("unable to save archive:", err)
+	}
+	if archive, err := loadArchive(nil, path); err != nil {
+		t.Error("unable to load archive:", err)
+	} else if !archive.Root.Equal(testArchive.Root) {
+		t.Error("loaded archive doesn't match saved archive")
+	}
+
+	// Verify that an archive saved without integrity information can be
+	// loaded.
+	if err := encoding.MarshalAndSaveProtobuf(path, testArchive); err != nil {
+		t.Fatal("unable to save legacy archive:", err)
+	}
+	if archive, err := loadArchive(nil, path); err != nil {
+		t.Error("unable to load legacy archive:", err)
+	} else if !archive.Root.Equal(testArchive.Root) {
+		t.Error("loaded legacy archive doesn't match saved archive")
+	}
+}
+
+// TestLoadArchiveCompressed tests that archives saved with compression can be
+// loaded, that they're smaller than their uncompressed equivalents, and that
+// archives saved without compression can still be loaded.
+func TestLoadArchiveCompressed(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_archive")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create a large archive with repetitive content.
+	large := &core.Archive{
+		Root: &core.Entry{
+			Kind:     core.EntryKind_Directory,
+			Contents: make(map[string]*core.Entry),
+		},
+	}
+	for i := 0; i < 1000; i++ {
+		large.Root.Contents[fmt.Sprintf("file%04d", i)] = &core.Entry{
+			Kind:   core.EntryKind_File,
+			Digest: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19},
+		}
+	}
+
+	// Save the archive with and without compression.
+	compressedPath := filepath.Join(directory, "compressed")
+	uncompressedPath := filepath.Join(directory, "uncompressed")
+	if err := saveArchive(compressedPath, large, ArchiveCompressionMode_ArchiveCompressionModeGzip); err != nil {
+		t.Fatal("unable to save compressed archive:", err)
+	}
+	if err := saveArchive(uncompressedPath, large, ArchiveCompressionMode_ArchiveCompressionModeNone); err != nil {
+		t.Fatal("unable to save uncompressed archive:", err)
+	}
+
+	// Verify that the archives were saved in the expected formats and that
+	// compression reduced the archive size.
+	compressed, err := ioutil.ReadFile(compressedPath)
+	if err != nil {
+		t.Fatal("unable to read compressed archive:", err)
+	}
+	uncompressed, err := ioutil.ReadFile(uncompressedPath)
+	if err != nil {
+		t.Fatal("unable to read uncompressed archive:", err)
+	}
+	if !encoding.IsCompressed(compressed) {
+		t.Error("compressed archive not saved in compressed format")
+	} else if encoding.IsCompressed(uncompressed) {
+		t.Error("uncompressed archive saved in compressed format")
+	} else if len(compressed) >= len(uncompressed) {
+		t.Errorf("compressed archive (%d bytes) not smaller than uncompressed archive (%d bytes)", len(compressed), len(uncompressed))
+	}
+
+	// Verify that both archives can be loaded.
+	for _, path := range []string{compressedPath, uncompressedPath} {
+		if archive, err := loadArchive(nil, path); err != nil {
+			t.Error("unable to load archive:", err)
+		} else if !archive.Root.Equal(large.Root) {
+			t.Error("loaded archive doesn't match saved archive:", filepath.Base(path))
+		}
+	}
+
+	// Verify that a compressed archive can replace an uncompressed archive
+	// (and vice versa) without affecting loading.
+	if err := saveArchive(uncompressedPath, testArchive, ArchiveCompressionMode_ArchiveCompressionModeGzip); err != nil {
+		t.Fatal("unable to overwrite archive with compression:", err)
+	} else if archive, err := loadArchive(nil, uncompressedPath); err != nil {
+		t.Error("unable to load overwritten archive:", err)
+	} else if !archive.Root.Equal(testArchive.Root) {
+		t.Error("loaded overwritten archive doesn't match saved archive")
+	}
+}
+
+// TestLoadArchiveCorrupted tests that loadArchive recovers from corrupted
+// archives by returning an empty archive and logging a warning.
+func TestLoadArchiveCorrupted(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_archive")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Save an archive and grab its encoded form.
+	path := filepath.Join(directory, "archive")
+	if err := saveArchive(path, testArchive, ArchiveCompressionMode_ArchiveCompressionModeNone); err != nil {
+		t.Fatal("unable to save archive:", err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal("unable to read archive:", err)
+	}
+
+	// Create an encoded archive with an invalid ancestor.
+	invalidPath := filepath.Join(directory, "invalid")
+	invalid := &core.Archive{Root: &core.Entry{Kind: core.EntryKind_Directory, Digest: []byte{0}}}
+	if err := saveArchive(invalidPath, invalid, ArchiveCompressionMode_ArchiveCompressionModeNone); err != nil {
+		t.Fatal("unable to save invalid archive:", err)
+	}
+	invalidData, err := ioutil.ReadFile(invalidPath)
+	if err != nil {
+		t.Fatal("unable to read invalid archive:", err)
+	}
+
+	// Set up corruptions.
+	flipped := append([]byte{}, data...)
+	flipped[len(flipped)-1] ^= 0x01
+	corruptions := map[string][]byte{
+		"truncated":         data[:len(data)/2],
+		"flipped bit":       flipped,
+		"invalid ancestor":  invalidData,
+		"unencoded garbage": []byte("not an archive"),
+	}
+
+	// Verify that each corruption results in an empty archive and a warning.
+	logger := logging.RootLogger.Sublogger("test")
+	for description, corrupted := range corruptions {
+		if err := ioutil.WriteFile(path, corrupted, 0600); err != nil {
+			t.Fatal("unable to write corrupted archive:", err)
+		}
+		var archive *core.Archive
+		var err error
+		output := captureWarnings(func() {
+			archive, err = loadArchive(logger, path)
+		})
+		if err != nil {
+			t.Errorf("loading of corrupted archive (%s) failed: %v", description, err)
+		} else if archive == nil || archive.Root != nil {
+			t.Error("non-empty archive returned for corrupted archive:", description)
+		}
+		if logger.Enabled(logging.LevelWarning) && !strings.Contains(output, "archive found on disk") {
+			t.Error("no warning logged for corrupted archive:", description)
+		}
+	}
+}
+
+// TestControllerCorruptedArchiveRecovery tests that a controller recovers from
+// a corrupted archive by completing a synchronization cycle with an empty
+// ancestor and saving a valid archive.
+func TestControllerCorruptedArchiveRecovery(t *testing.T) {
+	// Create a controller, wait for it to complete its initial cycle, and then
+	// pause it.
+	c, parent, alpha, beta := testController(t, testShutdownContent, nil, nil)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+	if err := c.halt(context.Background(), controllerHaltModePause, "", false); err != nil {
+		t.Fatal("unable to pause session:", err)
+	}
+
+	// Corrupt the archive by truncating it.
+	data, err := ioutil.ReadFile(c.archivePath)
+	if err != nil {
+		t.Fatal("unable to read archive:", err)
+	} else if err = ioutil.WriteFile(c.archivePath, data[:len(data)/2], 0600); err != nil {
+		t.Fatal("unable to corrupt archive:", err)
+	}
+
+	// Restart the synchronization loop and force a synchronization cycle.
+	output := captureWarnings(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		stopCtx, stop := context.WithCancel(ctx)
+		c.cancel = cancel
+		c.stop = stop
+		c.flushRequests = make(chan *controllerFlushRequest, 1)
+		c.done = make(chan struct{})
+		go c.run(ctx, stopCtx, alpha, beta, nil)
+		if err := c.flush(context.Background(), "", false, nil, false); err != nil {
+			t.Error("synchronization cycle with corrupted archive failed:", err)
+		}
+	})
+	defer c.halt(context.Background(), controllerHaltModePause, "", false)
+	if c.logger.Enabled(logging.LevelWarning) && !strings.Contains(output, "Corrupted archive found on disk") {
+		t.Error("no warning logged for corrupted archive")
+	}
+
+	// Verify that the session has no conflicts and that the archive was
+	// restored.
+	if s := c.currentState(); len(s.Conflicts) > 0 {
+		t.Error("conflicts detected after recovery:", len(s.Conflicts))
+	}
+	if archive, err := loadArchive(nil, c.archivePath); err != nil {
+		t.Error("unable to load restored archive:", err)
+	} else if archive.Root == nil || len(archive.Root.Contents) != len(testShutdownContent) {
+		t.Error("restored archive has unexpected content")
+	}
+
+	// Verify the synchronized content.
+	if count := verifyNoPartialFiles(t, beta.root); count != len(testShutdownContent) {
+		t.Error("synchronized file count incorrect:", count, "!=", len(testShutdownContent))
+	}
+}
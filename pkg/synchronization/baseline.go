@@ -0,0 +1,71 @@
+package synchronization
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+const (
+	// baselineMismatchExampleCount is the maximum number of differing paths
+	// included in the description of a baseline mismatch.
+	baselineMismatchExampleCount = 3
+)
+
+// baselineMismatchError indicates that a synchronization cycle was aborted
+// because the session's endpoint contents differed when the session's ancestor
+// was to be seeded from them due to baseline startup.
+type baselineMismatchError struct {
+	// paths are the roots of the differing content.
+	paths []string
+}
+
+// examples formats a subset of the differing paths for display.
+func (e *baselineMismatchError) examples() string {
+	examples := e.paths
+	if len(examples) > baselineMismatchExampleCount {
+		examples = examples[:baselineMismatchExampleCount]
+	}
+	quoted := make([]string, len(examples))
+	for p, path := range examples {
+		if path == "" {
+			path = "<root>"
+		}
+		quoted[p] = fmt.Sprintf("%q", path)
+	}
+	result := strings.Join(quoted, ", ")
+	if len(e.paths) > len(examples) {
+		result += ", ..."
+	}
+	return result
+}
+
+// Error implements error.Error.
+func (e *baselineMismatchError) Error() string {
+	return fmt.Sprintf("endpoint contents differ at baseline (%d differing path(s): %s)",
+		len(e.paths), e.examples(),
+	)
+}
+
+// pausedReason implements pausingError.pausedReason.
+func (e *baselineMismatchError) pausedReason() string {
+	return fmt.Sprintf("endpoint contents differ at baseline (%d differing path(s): %s), resume after making them equal",
+		len(e.paths), e.examples(),
+	)
+}
+
+// checkBaseline verifies that alpha's and beta's contents are equal and can
+// thus be used to seed the ancestor for baseline startup. If they differ, then
+// it returns a baselineMismatchError identifying the differing content.
+func checkBaseline(alpha, beta *core.Entry) error {
+	changes := core.Diff(alpha, beta)
+	if len(changes) == 0 {
+		return nil
+	}
+	paths := make([]string, len(changes))
+	for c, change := range changes {
+		paths[c] = change.Path
+	}
+	return &baselineMismatchError{paths: paths}
+}
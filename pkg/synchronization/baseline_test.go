@@ -0,0 +1,186 @@
+package synchronization
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// TestCheckBaseline tests checkBaseline.
+func TestCheckBaseline(t *testing.T) {
+	// Create test entries.
+	file := func(digest byte) *core.Entry {
+		return &core.Entry{Kind: core.EntryKind_File, Digest: []byte{digest}}
+	}
+	alpha := &core.Entry{Contents: map[string]*core.Entry{
+		"file":      file(1),
+		"directory": {Contents: map[string]*core.Entry{"nested": file(2)}},
+	}}
+	equal := &core.Entry{Contents: map[string]*core.Entry{
+		"file":      file(1),
+		"directory": {Contents: map[string]*core.Entry{"nested": file(2)}},
+	}}
+	different := &core.Entry{Contents: map[string]*core.Entry{
+		"file":      file(3),
+		"directory": {Contents: map[string]*core.Entry{"nested": file(2), "extra": file(4)}},
+	}}
+
+	// Verify that equal contents are accepted.
+	if err := checkBaseline(alpha, equal); err != nil {
+		t.Error("baseline check failed for equal contents:", err)
+	}
+	if err := checkBaseline(nil, nil); err != nil {
+		t.Error("baseline check failed for empty contents:", err)
+	}
+
+	// Verify that differing contents are rejected with the differing paths
+	// identified.
+	err := checkBaseline(alpha, different)
+	if err == nil {
+		t.Fatal("baseline check succeeded for differing contents")
+	}
+	mismatch, ok := err.(*baselineMismatchError)
+	if !ok {
+		t.Fatal("baseline check error has unexpected type")
+	} else if len(mismatch.paths) != 2 {
+		t.Error("unexpected number of differing paths:", len(mismatch.paths), "!=", 2)
+	}
+	for _, path := range []string{"file", "directory/extra"} {
+		if !strings.Contains(err.Error(), path) {
+			t.Error("baseline check error doesn't identify differing path:", path)
+		}
+	}
+	if _, ok := err.(pausingError); !ok {
+		t.Error("baseline check error doesn't require pausing")
+	}
+}
+
+// TestControllerBaselineEqual tests that baseline startup with equal endpoint
+// contents seeds the ancestor without transferring any content and that
+// subsequent changes are then synchronized.
+func TestControllerBaselineEqual(t *testing.T) {
+	// Create a controller with baseline startup whose endpoints have equal
+	// contents. Beta stages via supplying from alpha so that any transfer
+	// would be recorded.
+	content := map[string][]byte{
+		"file1": []byte("first"),
+		"file2": []byte("second"),
+	}
+	configuration := &Configuration{BaselineStartup: true}
+	c, parent, alpha, beta := testControllerWithSetup(t, configuration, content, nil, nil,
+		func(_, beta *testDirectoryEndpoint) {
+			beta.supplyStaging = true
+			for name, data := range content {
+				if err := ioutil.WriteFile(filepath.Join(beta.root, name), data, 0600); err != nil {
+					t.Fatal("unable to create beta content:", err)
+				}
+			}
+		},
+	)
+	defer os.RemoveAll(parent)
+
+	// Wait for the baseline cycle to complete.
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Verify that nothing was transferred and that the baseline was
+	// established.
+	if len(alpha.supplied) != 0 {
+		t.Error("content transferred at baseline:", alpha.supplied)
+	}
+	c.stateLock.Lock()
+	baselinePending := c.session.BaselinePending
+	c.stateLock.UnlockWithoutNotify()
+	if baselinePending {
+		t.Error("baseline still pending after cycle")
+	}
+	if archive, err := loadArchive(c.logger, c.archivePath); err != nil {
+		t.Fatal("unable to load archive:", err)
+	} else if len(archive.Root.GetContents()) != len(content) {
+		t.Error("ancestor not seeded from baseline")
+	}
+
+	// Modify a file on alpha and verify that the change is synchronized.
+	if err := ioutil.WriteFile(filepath.Join(alpha.root, "file1"), []byte("modified"), 0600); err != nil {
+		t.Fatal("unable to modify alpha content:", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.flush(ctx, "", false, nil, false); err != nil {
+		t.Fatal("flush failed:", err)
+	}
+	if synchronized, err := ioutil.ReadFile(filepath.Join(beta.root, "file1")); err != nil {
+		t.Error("unable to read synchronized file:", err)
+	} else if string(synchronized) != "modified" {
+		t.Error("change after baseline not synchronized")
+	}
+
+	// Shut down the controller.
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+}
+
+// TestControllerBaselineMismatch tests that baseline startup with differing
+// endpoint contents pauses the session with a reason identifying the differing
+// content and without transferring anything.
+func TestControllerBaselineMismatch(t *testing.T) {
+	// Create a controller with baseline startup whose endpoints have differing
+	// contents.
+	content := map[string][]byte{
+		"file1": []byte("first"),
+		"file2": []byte("second"),
+	}
+	configuration := &Configuration{BaselineStartup: true}
+	c, parent, alpha, beta := testControllerWithSetup(t, configuration, content, nil, nil,
+		func(_, beta *testDirectoryEndpoint) {
+			beta.supplyStaging = true
+			if err := ioutil.WriteFile(filepath.Join(beta.root, "file1"), []byte("first"), 0600); err != nil {
+				t.Fatal("unable to create beta content:", err)
+			} else if err = ioutil.WriteFile(filepath.Join(beta.root, "file2"), []byte("other"), 0600); err != nil {
+				t.Fatal("unable to create beta content:", err)
+			}
+		},
+	)
+	defer os.RemoveAll(parent)
+
+	// Wait for the session to be paused.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		c.stateLock.Lock()
+		paused := c.session.Paused
+		pausedReason := c.session.PausedReason
+		baselinePending := c.session.BaselinePending
+		c.stateLock.UnlockWithoutNotify()
+		if paused && pausedReason != "" {
+			if !strings.Contains(pausedReason, "differ at baseline") || !strings.Contains(pausedReason, "file2") {
+				t.Error("unexpected paused reason:", pausedReason)
+			} else if strings.Contains(pausedReason, "file1") {
+				t.Error("paused reason identifies equal content:", pausedReason)
+			}
+			if !baselinePending {
+				t.Error("baseline no longer pending after mismatch")
+			}
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("session not paused")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Verify that nothing was transferred.
+	if len(alpha.supplied) != 0 {
+		t.Error("content transferred despite baseline mismatch")
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(beta.root, "file2")); err != nil {
+		t.Error("unable to read beta content:", err)
+	} else if !bytes.Equal(data, []byte("other")) {
+		t.Error("beta content modified despite baseline mismatch")
+	}
+}
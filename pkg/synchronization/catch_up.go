@@ -0,0 +1,65 @@
+package synchronization
+
+import (
+	"os"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+const (
+	// catchUpMinimumDowntime is the minimum amount of time that must have
+	// elapsed since the archive was last saved in order for the first
+	// synchronization cycle after (re)connection to be treated as a catch-up
+	// cycle.
+	catchUpMinimumDowntime = time.Hour
+)
+
+// requiresCatchUp determines whether or not the session has been inactive for
+// long enough (based on the modification time of the persisted archive) that
+// the first synchronization cycle should prioritize targeted changes. Endpoints
+// don't maintain a watch journal across disconnections, so the archive (which
+// is saved at the end of every synchronization cycle) is the only reliable
+// record of when the session last synchronized.
+func requiresCatchUp(archivePath string, now time.Time) bool {
+	metadata, err := os.Stat(archivePath)
+	if err != nil {
+		return false
+	}
+	return now.Sub(metadata.ModTime()) >= catchUpMinimumDowntime
+}
+
+// isPriorityCatchUpTransition determines whether or not a transition should be
+// applied during the priority phase of a catch-up cycle. Priority transitions
+// are those that create, modify, or remove individual files or symbolic links
+// within directories that already existed when the session last synchronized,
+// i.e. targeted changes within obviously changed subtrees that represent the
+// most likely relevant content (such as edited source files). Transitions that
+// create or remove entire directory hierarchies (such as dependency or build
+// output directories) tend to be large and are deferred, as are conflict
+// resolution and conflict sidecar transitions.
+func isPriorityCatchUpTransition(transition *core.Change) bool {
+	if transition.Resolve || transition.ConflictSidecar {
+		return false
+	} else if transition.Old != nil && transition.Old.Kind == core.EntryKind_Directory {
+		return false
+	} else if transition.New != nil && transition.New.Kind == core.EntryKind_Directory {
+		return false
+	}
+	return true
+}
+
+// partitionCatchUpTransitions partitions transitions into those that should be
+// applied during the priority phase of a catch-up cycle and those that should
+// be deferred until the subsequent cycle. The relative ordering of transitions
+// is preserved within each partition.
+func partitionCatchUpTransitions(transitions []*core.Change) (priority, deferred []*core.Change) {
+	for _, transition := range transitions {
+		if isPriorityCatchUpTransition(transition) {
+			priority = append(priority, transition)
+		} else {
+			deferred = append(deferred, transition)
+		}
+	}
+	return
+}
@@ -0,0 +1,251 @@
+package synchronization
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/state"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+func TestRequiresCatchUp(t *testing.T) {
+	// Create a temporary archive file and defer its removal.
+	archive, err := ioutil.TempFile("", "mutagen_catch_up")
+	if err != nil {
+		t.Fatal("unable to create temporary archive:", err)
+	}
+	archive.Close()
+	defer os.Remove(archive.Name())
+
+	// Verify that a recently saved archive doesn't require catch-up.
+	now := time.Now()
+	if requiresCatchUp(archive.Name(), now) {
+		t.Error("recently saved archive requires catch-up")
+	}
+
+	// Verify that an archive saved before the downtime threshold requires
+	// catch-up.
+	saved := now.Add(-2 * catchUpMinimumDowntime)
+	if err := os.Chtimes(archive.Name(), saved, saved); err != nil {
+		t.Fatal("unable to set archive modification time:", err)
+	}
+	if !requiresCatchUp(archive.Name(), now) {
+		t.Error("archive saved before downtime threshold doesn't require catch-up")
+	}
+
+	// Verify that a missing archive doesn't require catch-up.
+	if requiresCatchUp(archive.Name()+"_missing", now) {
+		t.Error("missing archive requires catch-up")
+	}
+}
+
+func TestPartitionCatchUpTransitions(t *testing.T) {
+	// Set up transitions.
+	file := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{0}}
+	modified := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{1}}
+	symlink := &core.Entry{Kind: core.EntryKind_Symlink, Target: "target"}
+	directory := &core.Entry{Kind: core.EntryKind_Directory}
+	transitions := []*core.Change{
+		{Path: "build", New: directory},
+		{Path: "src/edited", Old: file, New: modified},
+		{Path: "src/created", New: file},
+		{Path: "legacy", Old: directory},
+		{Path: "src/removed", Old: file},
+		{Path: "src/link", New: symlink},
+		{Path: "conflict", Old: file, New: modified, Resolve: true},
+		{Path: "src/replaced", Old: file, New: directory},
+	}
+
+	// Partition the transitions.
+	priority, deferred := partitionCatchUpTransitions(transitions)
+
+	// Verify the priority transitions and their ordering.
+	expectedPriority := []string{"src/edited", "src/created", "src/removed", "src/link"}
+	if len(priority) != len(expectedPriority) {
+		t.Fatal("priority transition count incorrect:", len(priority), "!=", len(expectedPriority))
+	}
+	for i, path := range expectedPriority {
+		if priority[i].Path != path {
+			t.Error("priority transition mismatch:", priority[i].Path, "!=", path)
+		}
+	}
+
+	// Verify the deferred transitions and their ordering.
+	expectedDeferred := []string{"build", "legacy", "conflict", "src/replaced"}
+	if len(deferred) != len(expectedDeferred) {
+		t.Fatal("deferred transition count incorrect:", len(deferred), "!=", len(expectedDeferred))
+	}
+	for i, path := range expectedDeferred {
+		if deferred[i].Path != path {
+			t.Error("deferred transition mismatch:", deferred[i].Path, "!=", path)
+		}
+	}
+}
+
+// testRecordingEndpoint is a testDirectoryEndpoint that records the paths of
+// the transitions that it's asked to perform.
+type testRecordingEndpoint struct {
+	*testDirectoryEndpoint
+	// transitionsLock serializes access to transitions.
+	transitionsLock sync.Mutex
+	// transitions records the transition paths for each Transition call.
+	transitions [][]string
+}
+
+// Transition implements Endpoint.Transition.
+func (e *testRecordingEndpoint) Transition(ctx context.Context, transitions []*core.Change) ([]*core.Entry, []*core.Problem, bool, error) {
+	paths := make([]string, len(transitions))
+	for t, transition := range transitions {
+		paths[t] = transition.Path
+	}
+	e.transitionsLock.Lock()
+	e.transitions = append(e.transitions, paths)
+	e.transitionsLock.Unlock()
+	return e.testDirectoryEndpoint.Transition(ctx, transitions)
+}
+
+// recordedTransitions returns the transition paths recorded so far.
+func (e *testRecordingEndpoint) recordedTransitions() [][]string {
+	e.transitionsLock.Lock()
+	defer e.transitionsLock.Unlock()
+	return e.transitions
+}
+
+// testWriteFiles writes the specified content (keyed by slash-separated path)
+// beneath the specified root, creating parent directories as necessary.
+func testWriteFiles(t *testing.T, root string, content map[string][]byte) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Write content.
+	for path, data := range content {
+		path = filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal("unable to create parent directory:", err)
+		} else if err = ioutil.WriteFile(path, data, 0600); err != nil {
+			t.Fatal("unable to write file:", err)
+		}
+	}
+}
+
+// TestControllerCatchUpPrioritizesSubtrees tests that the first cycle after a
+// prolonged period of downtime applies targeted changes within previously
+// synchronized subtrees before a follow-up cycle applies the remainder.
+func TestControllerCatchUpPrioritizesSubtrees(t *testing.T) {
+	// Create a temporary directory to hold all test content and defer its
+	// removal.
+	parent, err := ioutil.TempDir("", "mutagen_catch_up")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Create synchronized content on both endpoints.
+	alphaRoot := filepath.Join(parent, "alpha")
+	betaRoot := filepath.Join(parent, "beta")
+	staging := filepath.Join(parent, "staging")
+	if err := os.Mkdir(staging, 0700); err != nil {
+		t.Fatal("unable to create staging directory:", err)
+	}
+	synchronized := map[string][]byte{
+		"src/main.go":  []byte("package main"),
+		"src/util.go":  []byte("package util"),
+		"docs/INDEX":   []byte("index"),
+		"assets/image": []byte("image"),
+	}
+	testWriteFiles(t, alphaRoot, synchronized)
+	testWriteFiles(t, betaRoot, synchronized)
+
+	// Record the synchronized state as the ancestor in an archive that was
+	// last saved long ago.
+	alpha := &testDirectoryEndpoint{root: alphaRoot, source: betaRoot, staging: staging}
+	beta := &testRecordingEndpoint{
+		testDirectoryEndpoint: &testDirectoryEndpoint{root: betaRoot, source: alphaRoot, staging: staging},
+	}
+	ancestor, _, _, err, _ := beta.Scan(context.Background(), nil, false, false, nil)
+	if err != nil {
+		t.Fatal("unable to scan synchronized content:", err)
+	}
+	archivePath := filepath.Join(parent, "archive")
+	if err := encoding.MarshalAndSaveProtobuf(archivePath, &core.Archive{Root: ancestor}); err != nil {
+		t.Fatal("unable to save archive:", err)
+	}
+	saved := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(archivePath, saved, saved); err != nil {
+		t.Fatal("unable to set archive modification time:", err)
+	}
+
+	// Modify alpha while the session is "down": edit files in existing
+	// subtrees and create a large new subtree.
+	testWriteFiles(t, alphaRoot, map[string][]byte{
+		"src/main.go":        []byte("package main // edited"),
+		"docs/NEW":           []byte("new documentation"),
+		"vendor/a/module.go": []byte("package a"),
+		"vendor/b/module.go": []byte("package b"),
+	})
+
+	// Create the controller.
+	session := &Session{
+		Identifier:         "catch_up",
+		Version:            Version_Version1,
+		Configuration:      &Configuration{},
+		ConfigurationAlpha: &Configuration{},
+		ConfigurationBeta:  &Configuration{},
+	}
+	c := &controller{
+		logger:                   logging.RootLogger.Sublogger("test"),
+		sessionPath:              filepath.Join(parent, "session"),
+		archivePath:              archivePath,
+		stateLock:                state.NewTrackingLock(state.NewTracker()),
+		session:                  session,
+		mergedAlphaConfiguration: &Configuration{},
+		mergedBetaConfiguration:  &Configuration{},
+		state: &State{
+			Session: session,
+		},
+		flushRequests: make(chan *controllerFlushRequest, 1),
+	}
+
+	// Run synchronization until two cycles have completed.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	synchronizeErrors := make(chan error, 1)
+	go func() {
+		synchronizeErrors <- c.synchronize(ctx, ctx, alpha, beta, nil, nil)
+	}()
+	waitForSynchronizationCycles(t, c, 2)
+	cancel()
+	<-synchronizeErrors
+
+	// Verify that the targeted changes were applied first and that the new
+	// subtree was applied in the follow-up cycle.
+	recorded := beta.recordedTransitions()
+	if len(recorded) != 2 {
+		t.Fatal("unexpected number of transition operations:", len(recorded))
+	}
+	expected := [][]string{
+		{"docs/NEW", "src/main.go"},
+		{"vendor"},
+	}
+	for i, paths := range expected {
+		sort.Strings(recorded[i])
+		if !stringSlicesEqual(recorded[i], paths) {
+			t.Errorf("transition operation %d paths incorrect: %v != %v", i, recorded[i], paths)
+		}
+	}
+
+	// Verify that beta's contents were fully synchronized.
+	for _, path := range []string{"src/main.go", "docs/NEW", "vendor/a/module.go", "vendor/b/module.go"} {
+		if _, err := os.Stat(filepath.Join(betaRoot, filepath.FromSlash(path))); err != nil {
+			t.Error("synchronized file missing on beta:", path)
+		}
+	}
+}
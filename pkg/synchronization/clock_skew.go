@@ -0,0 +1,68 @@
+package synchronization
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+const (
+	// clockSkewWarningThreshold is the magnitude of endpoint clock skew beyond
+	// which a warning problem is reported for the endpoint.
+	clockSkewWarningThreshold = 5 * time.Second
+)
+
+// ClockSkewReporter is an optional interface that endpoints can implement if
+// they're able to estimate the skew between their clock and the clock of the
+// process using them (e.g. because they're running on a remote host). Skew is
+// reported as the offset of the endpoint's clock relative to the local clock,
+// so a positive value indicates that the endpoint's clock is ahead.
+type ClockSkewReporter interface {
+	// ClockSkew returns the estimated clock skew for the endpoint.
+	ClockSkew() time.Duration
+}
+
+// endpointClockSkew returns the estimated clock skew for the specified
+// endpoint, if available.
+func endpointClockSkew(endpoint Endpoint) (time.Duration, bool) {
+	if reporter, ok := endpoint.(ClockSkewReporter); ok {
+		return reporter.ClockSkew(), true
+	}
+	return 0, false
+}
+
+// clockSkewProblem returns a problem warning about the specified clock skew if
+// its magnitude exceeds the clock skew warning threshold. Otherwise it returns
+// nil.
+func clockSkewProblem(skew time.Duration) *core.Problem {
+	// Compute the magnitude and direction of the skew.
+	magnitude, direction := skew, "ahead of"
+	if skew < 0 {
+		magnitude, direction = -skew, "behind"
+	}
+
+	// If the skew is within tolerance, then there's nothing to report.
+	if magnitude <= clockSkewWarningThreshold {
+		return nil
+	}
+
+	// Create the problem.
+	return &core.Problem{
+		Error: fmt.Sprintf(
+			"endpoint clock is %s %s the local clock (exceeding the %s warning threshold), so modification time comparisons may be unreliable",
+			magnitude.Round(time.Millisecond),
+			direction,
+			clockSkewWarningThreshold,
+		),
+	}
+}
+
+// withClockSkewProblem prepends the specified clock skew problem (if non-nil)
+// to the specified problem list.
+func withClockSkewProblem(problem *core.Problem, problems []*core.Problem) []*core.Problem {
+	if problem == nil {
+		return problems
+	}
+	return append([]*core.Problem{problem}, problems...)
+}
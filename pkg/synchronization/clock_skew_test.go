@@ -0,0 +1,96 @@
+package synchronization
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// skewedEndpoint is an Endpoint that reports a fixed clock skew.
+type skewedEndpoint struct {
+	Endpoint
+	// skew is the clock skew to report.
+	skew time.Duration
+}
+
+// ClockSkew implements ClockSkewReporter.ClockSkew.
+func (e *skewedEndpoint) ClockSkew() time.Duration {
+	return e.skew
+}
+
+// TestEndpointClockSkew tests that endpointClockSkew only reports clock skew
+// for endpoints that implement ClockSkewReporter.
+func TestEndpointClockSkew(t *testing.T) {
+	if _, ok := endpointClockSkew(nil); ok {
+		t.Error("clock skew reported for nil endpoint")
+	}
+	if _, ok := endpointClockSkew(struct{ Endpoint }{}); ok {
+		t.Error("clock skew reported for non-reporting endpoint")
+	}
+	if skew, ok := endpointClockSkew(&skewedEndpoint{skew: time.Minute}); !ok {
+		t.Error("clock skew not reported for reporting endpoint")
+	} else if skew != time.Minute {
+		t.Error("reported clock skew incorrect:", skew, "!=", time.Minute)
+	}
+}
+
+// TestClockSkewProblem tests that clockSkewProblem only generates problems for
+// clock skews exceeding the warning threshold.
+func TestClockSkewProblem(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		skew              time.Duration
+		expectProblem     bool
+		expectedDirection string
+	}{
+		{0, false, ""},
+		{time.Second, false, ""},
+		{-time.Second, false, ""},
+		{clockSkewWarningThreshold, false, ""},
+		{-clockSkewWarningThreshold, false, ""},
+		{clockSkewWarningThreshold + time.Millisecond, true, "ahead of"},
+		{time.Hour, true, "ahead of"},
+		{-time.Hour, true, "behind"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		problem := clockSkewProblem(testCase.skew)
+		if !testCase.expectProblem {
+			if problem != nil {
+				t.Errorf("problem unexpectedly reported for skew %s: %s", testCase.skew, problem.Error)
+			}
+			continue
+		}
+		if problem == nil {
+			t.Errorf("problem not reported for skew %s", testCase.skew)
+		} else if err := problem.EnsureValid(); err != nil {
+			t.Errorf("problem for skew %s invalid: %v", testCase.skew, err)
+		} else if !strings.Contains(problem.Error, testCase.expectedDirection) {
+			t.Errorf("problem for skew %s doesn't indicate direction: %s", testCase.skew, problem.Error)
+		}
+	}
+}
+
+// TestWithClockSkewProblem tests withClockSkewProblem.
+func TestWithClockSkewProblem(t *testing.T) {
+	// Create problems.
+	skewProblem := &core.Problem{Error: "skewed"}
+	transitionProblem := &core.Problem{Path: "file", Error: "failed"}
+
+	// Verify that a nil skew problem leaves the problem list untouched.
+	if problems := withClockSkewProblem(nil, nil); problems != nil {
+		t.Error("problems unexpectedly generated")
+	}
+	if problems := withClockSkewProblem(nil, []*core.Problem{transitionProblem}); len(problems) != 1 || problems[0] != transitionProblem {
+		t.Error("problems unexpectedly modified")
+	}
+
+	// Verify that skew problems are prepended.
+	problems := withClockSkewProblem(skewProblem, []*core.Problem{transitionProblem})
+	if len(problems) != 2 || problems[0] != skewProblem || problems[1] != transitionProblem {
+		t.Error("skew problem not correctly prepended")
+	}
+}
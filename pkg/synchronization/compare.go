@@ -0,0 +1,84 @@
+package synchronization
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/prompting"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// compare connects to and scans the specified endpoints and computes the
+// divergence between their contents. It doesn't stage or transition any
+// content on either endpoint. The identifier is used in lieu of a session
+// identifier when connecting to endpoints.
+func compare(
+	ctx context.Context,
+	logger *logging.Logger,
+	identifier string,
+	alpha, beta *url.URL,
+	configuration, configurationAlpha, configurationBeta *Configuration,
+	prompter string,
+) (*core.Divergence, error) {
+	// Verify that neither endpoint uses the tunnel protocol, since we can't
+	// wait for asynchronous connectivity.
+	if alpha.Protocol == url.Protocol_Tunnel || beta.Protocol == url.Protocol_Tunnel {
+		return nil, errors.New("comparison not supported for tunnel endpoints")
+	}
+
+	// Compute merged endpoint configurations.
+	mergedAlphaConfiguration := MergeConfigurations(configuration, configurationAlpha)
+	mergedBetaConfiguration := MergeConfigurations(configuration, configurationBeta)
+
+	// Connect to and scan each endpoint.
+	scan := func(description string, url *url.URL, configuration *Configuration, alpha bool) (*core.Entry, bool, error) {
+		// Connect to the endpoint and defer its shutdown.
+		prompting.Message(prompter, "Connecting to "+description+"...")
+		endpoint, err := connect(
+			ctx,
+			logger.Sublogger(description),
+			url,
+			prompter,
+			identifier,
+			Version_Version1,
+			configuration,
+			alpha,
+		)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "unable to connect to %s", description)
+		}
+		defer endpoint.Shutdown()
+
+		// Perform a full scan. We don't provide an ancestor since there isn't
+		// one.
+		prompting.Message(prompter, "Scanning "+description+"...")
+		snapshot, preservesExecutability, _, err, _ := endpoint.Scan(ctx, nil, true, false, nil)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "unable to scan %s", description)
+		}
+		return snapshot, preservesExecutability, nil
+	}
+	alphaSnapshot, alphaPreservesExecutability, err := scan("alpha", alpha, mergedAlphaConfiguration, true)
+	if err != nil {
+		return nil, err
+	}
+	betaSnapshot, betaPreservesExecutability, err := scan("beta", beta, mergedBetaConfiguration, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// If only one endpoint preserves executability, then propagate its
+	// executability bits to the other, so that the lack of executability
+	// information alone isn't reported as divergence.
+	if alphaPreservesExecutability && !betaPreservesExecutability {
+		betaSnapshot = core.PropagateExecutability(nil, alphaSnapshot, betaSnapshot)
+	} else if betaPreservesExecutability && !alphaPreservesExecutability {
+		alphaSnapshot = core.PropagateExecutability(nil, betaSnapshot, alphaSnapshot)
+	}
+
+	// Compute the divergence.
+	return core.Compare(alphaSnapshot, betaSnapshot), nil
+}
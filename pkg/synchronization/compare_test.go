@@ -0,0 +1,271 @@
+package synchronization
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/rsync"
+	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// testComparisonEndpoint is a test directory endpoint that records any attempt
+// to modify its synchronization root.
+type testComparisonEndpoint struct {
+	*testDirectoryEndpoint
+	// modificationAttempted indicates whether or not staging or transitioning
+	// was attempted.
+	modificationAttempted bool
+	// shutdown indicates whether or not the endpoint was shut down.
+	shutdown bool
+}
+
+// Stage implements Endpoint.Stage.
+func (e *testComparisonEndpoint) Stage(paths []string, digests [][]byte) ([]string, []*rsync.Signature, rsync.Receiver, error) {
+	e.modificationAttempted = true
+	return e.testDirectoryEndpoint.Stage(paths, digests)
+}
+
+// Transition implements Endpoint.Transition.
+func (e *testComparisonEndpoint) Transition(ctx context.Context, transitions []*core.Change) ([]*core.Entry, []*core.Problem, bool, error) {
+	e.modificationAttempted = true
+	return e.testDirectoryEndpoint.Transition(ctx, transitions)
+}
+
+// Shutdown implements Endpoint.Shutdown.
+func (e *testComparisonEndpoint) Shutdown() error {
+	e.shutdown = true
+	return nil
+}
+
+// testComparisonHandler is a protocol handler that creates comparison test
+// endpoints rooted at the URL path.
+type testComparisonHandler struct {
+	// endpoints are the endpoints that have been created.
+	endpoints []*testComparisonEndpoint
+}
+
+// Connect implements ProtocolHandler.Connect.
+func (h *testComparisonHandler) Connect(
+	_ context.Context,
+	_ *logging.Logger,
+	url *urlpkg.URL,
+	_ string,
+	_ string,
+	_ Version,
+	_ *Configuration,
+	_ bool,
+) (Endpoint, error) {
+	endpoint := &testComparisonEndpoint{
+		testDirectoryEndpoint: &testDirectoryEndpoint{root: url.Path},
+	}
+	h.endpoints = append(h.endpoints, endpoint)
+	return endpoint, nil
+}
+
+// testTreeState records the content and modes of every file and directory in
+// the specified tree.
+func testTreeState(t *testing.T, root string) map[string]string {
+	result := make(map[string]string)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		state := info.Mode().String()
+		if info.Mode().IsRegular() {
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			state += ":" + string(content)
+		}
+		result[path] = state
+		return nil
+	})
+	if err != nil {
+		t.Fatal("unable to record tree state:", err)
+	}
+	return result
+}
+
+// TestCompare tests that comparing divergent endpoints reports the expected
+// differences without modifying either endpoint, including when the endpoints
+// use different transports.
+func TestCompare(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	parent, err := ioutil.TempDir("", "mutagen_compare")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Create divergent trees.
+	alphaRoot := filepath.Join(parent, "alpha")
+	betaRoot := filepath.Join(parent, "beta")
+	files := []struct {
+		path       string
+		content    string
+		executable bool
+	}{
+		{"alpha/same", "same", false},
+		{"beta/same", "same", false},
+		{"alpha/alpha-only", "alpha", false},
+		{"alpha/alpha-directory/file", "alpha", false},
+		{"beta/beta-only", "beta", false},
+		{"alpha/content", "first", false},
+		{"beta/content", "second", false},
+		{"alpha/nested/content", "first", false},
+		{"beta/nested/content", "second", false},
+		{"alpha/mode", "mode", false},
+		{"beta/mode", "mode", true},
+	}
+	for _, file := range files {
+		path := filepath.Join(parent, filepath.FromSlash(file.path))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal("unable to create directory:", err)
+		}
+		mode := os.FileMode(0600)
+		if file.executable {
+			mode = 0700
+		}
+		if err := ioutil.WriteFile(path, []byte(file.content), mode); err != nil {
+			t.Fatal("unable to create file:", err)
+		}
+	}
+
+	// Record the initial state of both trees.
+	alphaState := testTreeState(t, alphaRoot)
+	betaState := testTreeState(t, betaRoot)
+
+	// Register comparison protocol handlers for the local and SSH protocols,
+	// deferring restoration of the original handlers.
+	handler := &testComparisonHandler{}
+	for _, protocol := range []urlpkg.Protocol{urlpkg.Protocol_Local, urlpkg.Protocol_SSH} {
+		original, registered := ProtocolHandlers[protocol]
+		ProtocolHandlers[protocol] = handler
+		defer func(protocol urlpkg.Protocol) {
+			if registered {
+				ProtocolHandlers[protocol] = original
+			} else {
+				delete(ProtocolHandlers, protocol)
+			}
+		}(protocol)
+	}
+
+	// Perform the comparison.
+	alpha := &urlpkg.URL{
+		Kind:     urlpkg.Kind_Synchronization,
+		Protocol: urlpkg.Protocol_Local,
+		Path:     alphaRoot,
+	}
+	beta := &urlpkg.URL{
+		Kind:     urlpkg.Kind_Synchronization,
+		Protocol: urlpkg.Protocol_SSH,
+		Host:     "example.org",
+		Path:     betaRoot,
+	}
+	divergence, err := compare(
+		context.Background(),
+		logging.RootLogger,
+		"comparison",
+		alpha, beta,
+		&Configuration{}, &Configuration{}, &Configuration{},
+		"",
+	)
+	if err != nil {
+		t.Fatal("unable to compare endpoints:", err)
+	}
+
+	// Verify the reported differences. Executability differences are only
+	// detectable on systems that preserve executability bits.
+	if expected := []string{"alpha-directory", "alpha-only"}; !testStringSlicesEqual(divergence.AlphaOnly, expected) {
+		t.Error("alpha-only paths do not match expected:", divergence.AlphaOnly, "!=", expected)
+	}
+	if expected := []string{"beta-only"}; !testStringSlicesEqual(divergence.BetaOnly, expected) {
+		t.Error("beta-only paths do not match expected:", divergence.BetaOnly, "!=", expected)
+	}
+	if expected := []string{"content", "nested/content"}; !testStringSlicesEqual(divergence.ContentDiffers, expected) {
+		t.Error("content-differing paths do not match expected:", divergence.ContentDiffers, "!=", expected)
+	}
+	if runtime.GOOS != "windows" {
+		if expected := []string{"mode"}; !testStringSlicesEqual(divergence.ModeDiffers, expected) {
+			t.Error("mode-differing paths do not match expected:", divergence.ModeDiffers, "!=", expected)
+		}
+	}
+
+	// Verify that neither endpoint was modified and that both were shut down.
+	if len(handler.endpoints) != 2 {
+		t.Fatal("unexpected endpoint count:", len(handler.endpoints))
+	}
+	for _, endpoint := range handler.endpoints {
+		if endpoint.modificationAttempted {
+			t.Error("modification attempted on endpoint:", endpoint.root)
+		}
+		if !endpoint.shutdown {
+			t.Error("endpoint not shut down:", endpoint.root)
+		}
+	}
+	if !testTreeStatesEqual(alphaState, testTreeState(t, alphaRoot)) {
+		t.Error("alpha modified by comparison")
+	}
+	if !testTreeStatesEqual(betaState, testTreeState(t, betaRoot)) {
+		t.Error("beta modified by comparison")
+	}
+}
+
+// TestCompareTunnelUnsupported tests that comparison of tunnel endpoints is
+// rejected.
+func TestCompareTunnelUnsupported(t *testing.T) {
+	alpha := &urlpkg.URL{
+		Kind:     urlpkg.Kind_Synchronization,
+		Protocol: urlpkg.Protocol_Local,
+		Path:     "/alpha",
+	}
+	beta := &urlpkg.URL{
+		Kind:     urlpkg.Kind_Synchronization,
+		Protocol: urlpkg.Protocol_Tunnel,
+		Host:     "tunnel",
+		Path:     "/beta",
+	}
+	if _, err := compare(
+		context.Background(),
+		logging.RootLogger,
+		"comparison",
+		alpha, beta,
+		&Configuration{}, &Configuration{}, &Configuration{},
+		"",
+	); err == nil {
+		t.Error("comparison of tunnel endpoint succeeded")
+	}
+}
+
+// testStringSlicesEqual determines whether or not two string slices are equal.
+func testStringSlicesEqual(first, second []string) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	for i, f := range first {
+		if second[i] != f {
+			return false
+		}
+	}
+	return true
+}
+
+// testTreeStatesEqual determines whether or not two tree states are equal.
+func testTreeStatesEqual(first, second map[string]string) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	for path, state := range first {
+		if other, ok := second[path]; !ok || other != state {
+			return false
+		}
+	}
+	return true
+}
@@ -1,12 +1,20 @@
 package synchronization
 
 import (
+	"strings"
+
 	"github.com/pkg/errors"
 
+	"github.com/mutagen-io/mutagen/pkg/agent"
 	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/ssh"
 	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
 )
 
+// maximumConcurrency is the maximum value allowed for scan and staging
+// concurrency limits.
+const maximumConcurrency = 256
+
 // stringSlicesEqual determines whether or not two string slices are equal.
 func stringSlicesEqual(first, second []string) bool {
 	// Check that slice lengths are equal.
@@ -40,16 +48,72 @@ func (c *Configuration) Equal(other *Configuration) bool {
 		c.ProbeMode == other.ProbeMode &&
 		c.ScanMode == other.ScanMode &&
 		c.StageMode == other.StageMode &&
+		c.ContentStoreMode == other.ContentStoreMode &&
+		stringSlicesEqual(c.ConflictResolverCommand, other.ConflictResolverCommand) &&
+		c.ConflictResolverTimeout == other.ConflictResolverTimeout &&
+		c.MaximumFileSize == other.MaximumFileSize &&
 		c.SymlinkMode == other.SymlinkMode &&
+		c.PreserveHardLinks == other.PreserveHardLinks &&
+		c.DeferSymlinks == other.DeferSymlinks &&
+		c.BrokenSymlinkMode == other.BrokenSymlinkMode &&
 		c.WatchMode == other.WatchMode &&
 		c.WatchPollingInterval == other.WatchPollingInterval &&
+		c.DeletionGracePeriod == other.DeletionGracePeriod &&
 		stringSlicesEqual(c.DefaultIgnores, other.DefaultIgnores) &&
 		stringSlicesEqual(c.Ignores, other.Ignores) &&
 		c.IgnoreVCSMode == other.IgnoreVCSMode &&
+		stringSlicesEqual(c.IgnoreSets, other.IgnoreSets) &&
+		c.IgnoreGitIgnored == other.IgnoreGitIgnored &&
+		c.IgnoreOpenFiles == other.IgnoreOpenFiles &&
+		c.ContentTypeMode == other.ContentTypeMode &&
 		c.DefaultFileMode == other.DefaultFileMode &&
 		c.DefaultDirectoryMode == other.DefaultDirectoryMode &&
 		c.DefaultOwner == other.DefaultOwner &&
-		c.DefaultGroup == other.DefaultGroup
+		c.DefaultGroup == other.DefaultGroup &&
+		c.AclMode == other.AclMode &&
+		c.HostVerificationMode == other.HostVerificationMode &&
+		c.SshOptions.Equal(other.SshOptions) &&
+		c.DurabilityMode == other.DurabilityMode &&
+		c.ModificationHandlingMode == other.ModificationHandlingMode &&
+		c.CloneStagingThreshold == other.CloneStagingThreshold &&
+		c.StallTimeout == other.StallTimeout &&
+		c.AbortOnStall == other.AbortOnStall &&
+		c.CompressionThreshold == other.CompressionThreshold &&
+		stringSlicesEqual(c.IncompressibleExtensions, other.IncompressibleExtensions) &&
+		stringSlicesEqual(c.ProtectedPaths, other.ProtectedPaths) &&
+		stringSlicesEqual(c.VerifiedPaths, other.VerifiedPaths) &&
+		c.ScanConcurrency == other.ScanConcurrency &&
+		c.StagingConcurrency == other.StagingConcurrency &&
+		c.ScanWarmup == other.ScanWarmup &&
+		stringSlicesEqual(c.ScheduleWindows, other.ScheduleWindows) &&
+		c.ScheduleTimezone == other.ScheduleTimezone &&
+		c.StrictCapabilities == other.StrictCapabilities &&
+		c.PreserveMacOSMetadata == other.PreserveMacOSMetadata &&
+		c.PreserveFileFlags == other.PreserveFileFlags &&
+		stringSlicesEqual(c.LineEndingPatterns, other.LineEndingPatterns) &&
+		c.LineEndingStyle == other.LineEndingStyle &&
+		c.ConflictPauseThreshold == other.ConflictPauseThreshold &&
+		c.ConflictSidecars == other.ConflictSidecars &&
+		c.AgentMemoryLimit == other.AgentMemoryLimit &&
+		c.AgentCPULimit == other.AgentCPULimit &&
+		c.AgentCacheHost == other.AgentCacheHost &&
+		c.UndoMaximumSize == other.UndoMaximumSize &&
+		c.UndoMaximumAge == other.UndoMaximumAge &&
+		c.InvalidNameMode == other.InvalidNameMode &&
+		c.LongPathMode == other.LongPathMode &&
+		c.FilenameEncoding == other.FilenameEncoding &&
+		stringSlicesEqual(c.PathMappings, other.PathMappings) &&
+		c.PathMappingOneWay == other.PathMappingOneWay &&
+		c.TransferPriority == other.TransferPriority &&
+		c.DeltaTransferMode == other.DeltaTransferMode &&
+		c.CycleTransferBudget == other.CycleTransferBudget &&
+		c.CheckAvailableSpace == other.CheckAvailableSpace &&
+		c.ComputeMerkleRoot == other.ComputeMerkleRoot &&
+		c.ArchiveCompressionMode == other.ArchiveCompressionMode &&
+		c.DeletionPauseThreshold == other.DeletionPauseThreshold &&
+		c.DeletionPausePercentage == other.DeletionPausePercentage &&
+		c.BaselineStartup == other.BaselineStartup &&
+		c.QuarantineThreshold == other.QuarantineThreshold
 }
 
 // EnsureValid ensures that Configuration's invariants are respected. The
@@ -93,6 +157,29 @@ func (c *Configuration) EnsureValid(endpointSpecific bool) error {
 		return errors.New("unknown or unsupported staging mode")
 	}
 
+	// Verify that the content store mode is unspecified or supported for usage.
+	if !(c.ContentStoreMode.IsDefault() || c.ContentStoreMode.Supported()) {
+		return errors.New("unknown or unsupported content store mode")
+	}
+
+	// Verify that the conflict resolver is unset for endpoint-specific
+	// configurations and that, if specified, its command name is non-empty.
+	// The conflict resolver timeout doesn't need to be validated - any of its
+	// values are technically valid - but it can't be specified on an
+	// endpoint-specific basis.
+	if endpointSpecific {
+		if len(c.ConflictResolverCommand) > 0 {
+			return errors.New("conflict resolver command cannot be specified on an endpoint-specific basis")
+		} else if c.ConflictResolverTimeout != 0 {
+			return errors.New("conflict resolver timeout cannot be specified on an endpoint-specific basis")
+		}
+	} else if len(c.ConflictResolverCommand) > 0 && c.ConflictResolverCommand[0] == "" {
+		return errors.New("empty conflict resolver command name")
+	}
+
+	// The maximum file size doesn't need to be validated - any of its values
+	// are technically valid regardless of the source.
+
 	// Verify that the symlink mode.
 	if endpointSpecific {
 		if !c.SymlinkMode.IsDefault() {
@@ -104,6 +191,29 @@ func (c *Configuration) EnsureValid(endpointSpecific bool) error {
 		}
 	}
 
+	// Verify that hard link preservation is unset for endpoint-specific
+	// configurations.
+	if endpointSpecific && c.PreserveHardLinks {
+		return errors.New("hard link preservation cannot be specified on an endpoint-specific basis")
+	}
+
+	// Verify that symbolic link deferral is unset for endpoint-specific
+	// configurations.
+	if endpointSpecific && c.DeferSymlinks {
+		return errors.New("symbolic link deferral cannot be specified on an endpoint-specific basis")
+	}
+
+	// Verify the broken symlink mode.
+	if endpointSpecific {
+		if !c.BrokenSymlinkMode.IsDefault() {
+			return errors.New("broken symbolic link handling mode cannot be specified on an endpoint-specific basis")
+		}
+	} else {
+		if !(c.BrokenSymlinkMode.IsDefault() || c.BrokenSymlinkMode.Supported()) {
+			return errors.New("unknown or unsupported broken symlink mode")
+		}
+	}
+
 	// Verify that the watch mode is unspecified or supported for usage.
 	if !(c.WatchMode.IsDefault() || c.WatchMode.Supported()) {
 		return errors.New("unknown or unsupported watch mode")
@@ -112,6 +222,12 @@ func (c *Configuration) EnsureValid(endpointSpecific bool) error {
 	// The watch polling interval doesn't need to be validated - any of its
 	// values are technically valid regardless of the source.
 
+	// Verify that the deletion grace period isn't specified on an
+	// endpoint-specific basis, since deletions are held by the controller.
+	if endpointSpecific && c.DeletionGracePeriod != 0 {
+		return errors.New("deletion grace period cannot be specified on an endpoint-specific basis")
+	}
+
 	// Verify that default ignores are unset for endpoint-specific
 	// configurations and that any specified ignores are valid. This field is
 	// deprecated, but existing sessions may have it set, in which case we'll
@@ -148,6 +264,31 @@ func (c *Configuration) EnsureValid(endpointSpecific bool) error {
 		}
 	}
 
+	// Verify that ignore set references are unset for endpoint-specific
+	// configurations and that any specified ignore set names are valid. We
+	// don't verify that the referenced ignore sets exist here, since that
+	// requires access to the Mutagen data directory, but it will be verified
+	// when the ignore sets are resolved.
+	if endpointSpecific && len(c.IgnoreSets) > 0 {
+		return errors.New("ignore sets cannot be specified on an endpoint-specific basis")
+	}
+	for _, name := range c.IgnoreSets {
+		if err := EnsureIgnoreSetNameValid(name); err != nil {
+			return errors.Wrapf(err, "invalid ignore set reference: %s", name)
+		}
+	}
+
+	// Verify that Git ignore behavior is unset for endpoint-specific
+	// configurations.
+	if endpointSpecific && c.IgnoreGitIgnored {
+		return errors.New("Git ignore behavior cannot be specified on an endpoint-specific basis")
+	}
+
+	// Verify that the content type mode is unspecified or supported for usage.
+	if !(c.ContentTypeMode.IsDefault() || c.ContentTypeMode.Supported()) {
+		return errors.New("unknown or unsupported content type mode")
+	}
+
 	// Verify the default file mode.
 	if c.DefaultFileMode != 0 {
 		if err := core.EnsureDefaultFileModeValid(filesystem.Mode(c.DefaultFileMode)); err != nil {
@@ -176,6 +317,291 @@ func (c *Configuration) EnsureValid(endpointSpecific bool) error {
 		}
 	}
 
+	// Verify that the ACL mode is unspecified or supported for usage.
+	if !(c.AclMode.IsDefault() || c.AclMode.Supported()) {
+		return errors.New("unknown or unsupported ACL mode")
+	}
+
+	// Verify that the host verification mode is unspecified or supported for
+	// usage.
+	if !(c.HostVerificationMode.IsDefault() || c.HostVerificationMode.Supported()) {
+		return errors.New("unknown or unsupported host verification mode")
+	}
+
+	// Verify that SSH options are valid.
+	if err := c.SshOptions.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid SSH options")
+	}
+
+	// Verify that the durability mode is unspecified or supported for usage.
+	if !(c.DurabilityMode.IsDefault() || c.DurabilityMode.Supported()) {
+		return errors.New("unknown or unsupported durability mode")
+	}
+
+	// Verify that the modification handling mode is unspecified or supported
+	// for usage.
+	if !(c.ModificationHandlingMode.IsDefault() || c.ModificationHandlingMode.Supported()) {
+		return errors.New("unknown or unsupported modification handling mode")
+	}
+
+	// The clone staging threshold doesn't need to be validated - any of its
+	// values are technically valid regardless of the source.
+
+	// Verify that stall detection parameters are unset for endpoint-specific
+	// configurations. The stall timeout doesn't need to be validated - any of
+	// its values are technically valid.
+	if endpointSpecific {
+		if c.StallTimeout != 0 {
+			return errors.New("stall timeout cannot be specified on an endpoint-specific basis")
+		} else if c.AbortOnStall {
+			return errors.New("stall abortion cannot be specified on an endpoint-specific basis")
+		}
+	}
+
+	// Verify that incompressible extensions are valid. The compression
+	// threshold doesn't need to be validated - any of its values are
+	// technically valid.
+	for _, extension := range c.IncompressibleExtensions {
+		if extension == "" || extension == "." {
+			return errors.New("empty incompressible extension")
+		} else if strings.ContainsAny(extension, "/\\") {
+			return errors.Errorf("incompressible extension contains path separator: %s", extension)
+		}
+	}
+
+	// Verify that protected path patterns are valid.
+	for _, pattern := range c.ProtectedPaths {
+		if !core.ValidProtectedPathPattern(pattern) {
+			return errors.Errorf("invalid protected path pattern: %s", pattern)
+		}
+	}
+
+	// Verify that verified path patterns are valid.
+	for _, pattern := range c.VerifiedPaths {
+		if !core.ValidProtectedPathPattern(pattern) {
+			return errors.Errorf("invalid verified path pattern: %s", pattern)
+		}
+	}
+
+	// Verify that concurrency limits are within bounds.
+	if c.ScanConcurrency > maximumConcurrency {
+		return errors.Errorf("scan concurrency exceeds maximum (%d)", maximumConcurrency)
+	} else if c.StagingConcurrency > maximumConcurrency {
+		return errors.Errorf("staging concurrency exceeds maximum (%d)", maximumConcurrency)
+	}
+
+	// Verify that schedule parameters are unset for endpoint-specific
+	// configurations and that any specified schedule is valid.
+	if endpointSpecific {
+		if len(c.ScheduleWindows) > 0 {
+			return errors.New("schedule windows cannot be specified on an endpoint-specific basis")
+		} else if c.ScheduleTimezone != "" {
+			return errors.New("schedule time zone cannot be specified on an endpoint-specific basis")
+		}
+	} else if _, err := newSchedule(c.ScheduleWindows, c.ScheduleTimezone); err != nil {
+		return errors.Wrap(err, "invalid schedule")
+	}
+
+	// Verify that strict capability enforcement isn't specified on an
+	// endpoint-specific basis, since capability mismatches are treated as
+	// session startup failures.
+	if endpointSpecific && c.StrictCapabilities {
+		return errors.New("strict capabilities cannot be specified on an endpoint-specific basis")
+	}
+
+	// Verify that macOS metadata preservation is unset for endpoint-specific
+	// configurations, since metadata captured on one endpoint must be restored
+	// on the other.
+	if endpointSpecific && c.PreserveMacOSMetadata {
+		return errors.New("macOS metadata preservation cannot be specified on an endpoint-specific basis")
+	}
+
+	// Verify that file flag preservation is unset for endpoint-specific
+	// configurations, since flags captured on one endpoint must be restored on
+	// the other.
+	if endpointSpecific && c.PreserveFileFlags {
+		return errors.New("file flag preservation cannot be specified on an endpoint-specific basis")
+	}
+
+	// Verify that line ending patterns are unset for endpoint-specific
+	// configurations (since both endpoints must agree on which files have
+	// digests computed over canonicalized content) and that they're valid.
+	if endpointSpecific && len(c.LineEndingPatterns) > 0 {
+		return errors.New("line ending patterns cannot be specified on an endpoint-specific basis")
+	}
+	for _, pattern := range c.LineEndingPatterns {
+		if !core.ValidLineEndingPattern(pattern) {
+			return errors.Errorf("invalid line ending pattern: %s", pattern)
+		}
+	}
+
+	// Verify that the line ending style is unspecified or supported for usage.
+	if !(c.LineEndingStyle.IsDefault() || c.LineEndingStyle.Supported()) {
+		return errors.New("unknown or unsupported line ending style")
+	}
+
+	// Verify that the conflict pause threshold is unset for endpoint-specific
+	// configurations. Any of its values are technically valid otherwise.
+	if endpointSpecific && c.ConflictPauseThreshold != 0 {
+		return errors.New("conflict pause threshold cannot be specified on an endpoint-specific basis")
+	}
+
+	// Verify that conflict sidecars are only enabled for session-level
+	// configurations and that they aren't combined with external conflict
+	// resolution (since both would handle the same conflicts).
+	if c.ConflictSidecars {
+		if endpointSpecific {
+			return errors.New("conflict sidecars cannot be specified on an endpoint-specific basis")
+		} else if len(c.ConflictResolverCommand) > 0 {
+			return errors.New("conflict sidecars cannot be combined with a conflict resolver command")
+		}
+	}
+
+	// Verify that the agent resource limits are valid.
+	if err := c.AgentResourceLimits().EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid agent resource limits")
+	}
+
+	// Verify that the agent cache host is unset or valid.
+	if c.AgentCacheHost != "" {
+		if _, _, err := agent.ParseCacheHost(c.AgentCacheHost); err != nil {
+			return errors.Wrap(err, "invalid agent cache host")
+		}
+	}
+
+	// Verify that undo parameters are unset for endpoint-specific
+	// configurations, since undo is coordinated at the session level. Any of
+	// their values are technically valid otherwise.
+	if endpointSpecific {
+		if c.UndoMaximumSize != 0 {
+			return errors.New("undo maximum size cannot be specified on an endpoint-specific basis")
+		} else if c.UndoMaximumAge != 0 {
+			return errors.New("undo maximum age cannot be specified on an endpoint-specific basis")
+		}
+	}
+
+	// Verify the invalid name mode.
+	if endpointSpecific {
+		if !c.InvalidNameMode.IsDefault() {
+			return errors.New("invalid name handling mode cannot be specified on an endpoint-specific basis")
+		}
+	} else {
+		if !(c.InvalidNameMode.IsDefault() || c.InvalidNameMode.Supported()) {
+			return errors.New("unknown or unsupported invalid name mode")
+		}
+	}
+
+	// Verify the long path mode.
+	if endpointSpecific {
+		if !c.LongPathMode.IsDefault() {
+			return errors.New("long path handling mode cannot be specified on an endpoint-specific basis")
+		}
+	} else {
+		if !(c.LongPathMode.IsDefault() || c.LongPathMode.Supported()) {
+			return errors.New("unknown or unsupported long path mode")
+		}
+	}
+
+	// Verify the filename encoding.
+	if !(c.FilenameEncoding.IsDefault() || c.FilenameEncoding.Supported()) {
+		return errors.New("unknown or unsupported filename encoding")
+	}
+
+	// Verify the path mapping. Mappings are applied to beta's content on a
+	// session-wide basis. Since conflict resolution transitions operate on
+	// beta's content in place, they can't be combined with path mappings.
+	if endpointSpecific {
+		if len(c.PathMappings) > 0 {
+			return errors.New("path mappings cannot be specified on an endpoint-specific basis")
+		} else if c.PathMappingOneWay {
+			return errors.New("one-way path mapping cannot be specified on an endpoint-specific basis")
+		}
+	} else if len(c.PathMappings) > 0 {
+		if _, err := core.NewPathMapping(c.PathMappings, c.PathMappingOneWay); err != nil {
+			return errors.Wrap(err, "invalid path mapping")
+		}
+		oneWaySynchronization := c.SynchronizationMode == core.SynchronizationMode_SynchronizationModeOneWaySafe ||
+			c.SynchronizationMode == core.SynchronizationMode_SynchronizationModeOneWayReplica
+		if c.PathMappingOneWay && !oneWaySynchronization {
+			return errors.New("one-way path mapping requires a one-way synchronization mode")
+		} else if c.ConflictSidecars {
+			return errors.New("path mappings cannot be combined with conflict sidecars")
+		} else if len(c.ConflictResolverCommand) > 0 {
+			return errors.New("path mappings cannot be combined with a conflict resolver command")
+		}
+	}
+
+	// Verify the transfer priority.
+	if endpointSpecific {
+		if !c.TransferPriority.IsDefault() {
+			return errors.New("transfer priority cannot be specified on an endpoint-specific basis")
+		}
+	} else {
+		if !(c.TransferPriority.IsDefault() || c.TransferPriority.Supported()) {
+			return errors.New("unknown or unsupported transfer priority")
+		}
+	}
+
+	// Verify the delta transfer mode.
+	if !(c.DeltaTransferMode.IsDefault() || c.DeltaTransferMode.Supported()) {
+		return errors.New("unknown or unsupported delta transfer mode")
+	}
+
+	// Verify that the cycle transfer budget is unset for endpoint-specific
+	// configurations, since it covers transfers in both directions. Any of its
+	// values are technically valid otherwise.
+	if endpointSpecific && c.CycleTransferBudget != 0 {
+		return errors.New("cycle transfer budget cannot be specified on an endpoint-specific basis")
+	}
+
+	// Verify that available space checking is unset for endpoint-specific
+	// configurations.
+	if endpointSpecific && c.CheckAvailableSpace {
+		return errors.New("available space checking cannot be specified on an endpoint-specific basis")
+	}
+
+	// Verify that Merkle root computation is unset for endpoint-specific
+	// configurations.
+	if endpointSpecific && c.ComputeMerkleRoot {
+		return errors.New("Merkle root computation cannot be specified on an endpoint-specific basis")
+	}
+
+	// Verify the archive compression mode.
+	if endpointSpecific {
+		if !c.ArchiveCompressionMode.IsDefault() {
+			return errors.New("archive compression mode cannot be specified on an endpoint-specific basis")
+		}
+	} else {
+		if !(c.ArchiveCompressionMode.IsDefault() || c.ArchiveCompressionMode.Supported()) {
+			return errors.New("unknown or unsupported archive compression mode")
+		}
+	}
+
+	// Verify that deletion pause parameters are unset for endpoint-specific
+	// configurations and that any deletion pause percentage is in range. Any
+	// deletion pause threshold is valid otherwise.
+	if endpointSpecific {
+		if c.DeletionPauseThreshold != 0 {
+			return errors.New("deletion pause threshold cannot be specified on an endpoint-specific basis")
+		} else if c.DeletionPausePercentage != 0 {
+			return errors.New("deletion pause percentage cannot be specified on an endpoint-specific basis")
+		}
+	} else if c.DeletionPausePercentage > 100 {
+		return errors.New("deletion pause percentage exceeds 100")
+	}
+
+	// Verify that baseline startup is unset for endpoint-specific
+	// configurations.
+	if endpointSpecific && c.BaselineStartup {
+		return errors.New("baseline startup cannot be specified on an endpoint-specific basis")
+	}
+
+	// Verify that the quarantine threshold is unset for endpoint-specific
+	// configurations. Any of its values are technically valid otherwise.
+	if endpointSpecific && c.QuarantineThreshold != 0 {
+		return errors.New("quarantine threshold cannot be specified on an endpoint-specific basis")
+	}
+
 	// Success.
 	return nil
 }
@@ -228,6 +654,35 @@ func MergeConfigurations(lower, higher *Configuration) *Configuration {
 		result.StageMode = lower.StageMode
 	}
 
+	// Merge content store mode.
+	if !higher.ContentStoreMode.IsDefault() {
+		result.ContentStoreMode = higher.ContentStoreMode
+	} else {
+		result.ContentStoreMode = lower.ContentStoreMode
+	}
+
+	// Merge conflict resolver command. Unlike ignores, commands aren't merged
+	// element-wise, so the higher-priority command (if any) takes precedence.
+	if len(higher.ConflictResolverCommand) > 0 {
+		result.ConflictResolverCommand = higher.ConflictResolverCommand
+	} else {
+		result.ConflictResolverCommand = lower.ConflictResolverCommand
+	}
+
+	// Merge conflict resolver timeout.
+	if higher.ConflictResolverTimeout != 0 {
+		result.ConflictResolverTimeout = higher.ConflictResolverTimeout
+	} else {
+		result.ConflictResolverTimeout = lower.ConflictResolverTimeout
+	}
+
+	// Merge maximum file size.
+	if higher.MaximumFileSize != 0 {
+		result.MaximumFileSize = higher.MaximumFileSize
+	} else {
+		result.MaximumFileSize = lower.MaximumFileSize
+	}
+
 	// Merge symlink mode.
 	if !higher.SymlinkMode.IsDefault() {
 		result.SymlinkMode = higher.SymlinkMode
@@ -235,6 +690,19 @@ func MergeConfigurations(lower, higher *Configuration) *Configuration {
 		result.SymlinkMode = lower.SymlinkMode
 	}
 
+	// Merge hard link preservation.
+	result.PreserveHardLinks = lower.PreserveHardLinks || higher.PreserveHardLinks
+
+	// Merge symbolic link deferral.
+	result.DeferSymlinks = lower.DeferSymlinks || higher.DeferSymlinks
+
+	// Merge broken symlink mode.
+	if !higher.BrokenSymlinkMode.IsDefault() {
+		result.BrokenSymlinkMode = higher.BrokenSymlinkMode
+	} else {
+		result.BrokenSymlinkMode = lower.BrokenSymlinkMode
+	}
+
 	// Merge watch mode.
 	if !higher.WatchMode.IsDefault() {
 		result.WatchMode = higher.WatchMode
@@ -249,6 +717,13 @@ func MergeConfigurations(lower, higher *Configuration) *Configuration {
 		result.WatchPollingInterval = lower.WatchPollingInterval
 	}
 
+	// Merge deletion grace period.
+	if higher.DeletionGracePeriod != 0 {
+		result.DeletionGracePeriod = higher.DeletionGracePeriod
+	} else {
+		result.DeletionGracePeriod = lower.DeletionGracePeriod
+	}
+
 	// Merge default ignores. In theory, at most one of these should be
 	// non-empty, but we'll still implement it as if they both might have
 	// content.
@@ -266,6 +741,23 @@ func MergeConfigurations(lower, higher *Configuration) *Configuration {
 		result.IgnoreVCSMode = lower.IgnoreVCSMode
 	}
 
+	// Merge ignore set references.
+	result.IgnoreSets = append(result.IgnoreSets, lower.IgnoreSets...)
+	result.IgnoreSets = append(result.IgnoreSets, higher.IgnoreSets...)
+
+	// Merge Git ignore behavior.
+	result.IgnoreGitIgnored = lower.IgnoreGitIgnored || higher.IgnoreGitIgnored
+
+	// Merge open file ignore behavior.
+	result.IgnoreOpenFiles = lower.IgnoreOpenFiles || higher.IgnoreOpenFiles
+
+	// Merge content type mode.
+	if !higher.ContentTypeMode.IsDefault() {
+		result.ContentTypeMode = higher.ContentTypeMode
+	} else {
+		result.ContentTypeMode = lower.ContentTypeMode
+	}
+
 	// Merge default file mode.
 	if higher.DefaultFileMode != 0 {
 		result.DefaultFileMode = higher.DefaultFileMode
@@ -294,6 +786,270 @@ func MergeConfigurations(lower, higher *Configuration) *Configuration {
 		result.DefaultGroup = lower.DefaultGroup
 	}
 
+	// Merge ACL mode.
+	if !higher.AclMode.IsDefault() {
+		result.AclMode = higher.AclMode
+	} else {
+		result.AclMode = lower.AclMode
+	}
+
+	// Merge host verification mode.
+	if !higher.HostVerificationMode.IsDefault() {
+		result.HostVerificationMode = higher.HostVerificationMode
+	} else {
+		result.HostVerificationMode = lower.HostVerificationMode
+	}
+
+	// Merge SSH options.
+	if lower.SshOptions != nil || higher.SshOptions != nil {
+		result.SshOptions = ssh.MergeOptions(lower.SshOptions, higher.SshOptions)
+	}
+
+	// Merge durability mode.
+	if !higher.DurabilityMode.IsDefault() {
+		result.DurabilityMode = higher.DurabilityMode
+	} else {
+		result.DurabilityMode = lower.DurabilityMode
+	}
+
+	// Merge modification handling mode.
+	if !higher.ModificationHandlingMode.IsDefault() {
+		result.ModificationHandlingMode = higher.ModificationHandlingMode
+	} else {
+		result.ModificationHandlingMode = lower.ModificationHandlingMode
+	}
+
+	// Merge clone staging threshold.
+	if higher.CloneStagingThreshold != 0 {
+		result.CloneStagingThreshold = higher.CloneStagingThreshold
+	} else {
+		result.CloneStagingThreshold = lower.CloneStagingThreshold
+	}
+
+	// Merge stall detection parameters.
+	if higher.StallTimeout != 0 {
+		result.StallTimeout = higher.StallTimeout
+	} else {
+		result.StallTimeout = lower.StallTimeout
+	}
+	result.AbortOnStall = lower.AbortOnStall || higher.AbortOnStall
+
+	// Merge compression threshold.
+	if higher.CompressionThreshold != 0 {
+		result.CompressionThreshold = higher.CompressionThreshold
+	} else {
+		result.CompressionThreshold = lower.CompressionThreshold
+	}
+
+	// Merge incompressible extensions. Unlike most other parameters, these are
+	// additive.
+	result.IncompressibleExtensions = append(result.IncompressibleExtensions, lower.IncompressibleExtensions...)
+	result.IncompressibleExtensions = append(result.IncompressibleExtensions, higher.IncompressibleExtensions...)
+
+	// Merge protected paths. These are also additive, since protection
+	// specified at any level should be respected.
+	result.ProtectedPaths = append(result.ProtectedPaths, lower.ProtectedPaths...)
+	result.ProtectedPaths = append(result.ProtectedPaths, higher.ProtectedPaths...)
+
+	// Merge verified paths. These are also additive, for the same reason.
+	result.VerifiedPaths = append(result.VerifiedPaths, lower.VerifiedPaths...)
+	result.VerifiedPaths = append(result.VerifiedPaths, higher.VerifiedPaths...)
+
+	// Merge scan concurrency.
+	if higher.ScanConcurrency != 0 {
+		result.ScanConcurrency = higher.ScanConcurrency
+	} else {
+		result.ScanConcurrency = lower.ScanConcurrency
+	}
+
+	// Merge staging concurrency.
+	if higher.StagingConcurrency != 0 {
+		result.StagingConcurrency = higher.StagingConcurrency
+	} else {
+		result.StagingConcurrency = lower.StagingConcurrency
+	}
+
+	// Merge scan warmup.
+	result.ScanWarmup = lower.ScanWarmup || higher.ScanWarmup
+
+	// Merge schedule parameters. Schedule windows are treated as a single unit
+	// rather than being additive, since combining windows would widen the
+	// schedule in unexpected ways.
+	if len(higher.ScheduleWindows) > 0 {
+		result.ScheduleWindows = higher.ScheduleWindows
+	} else {
+		result.ScheduleWindows = lower.ScheduleWindows
+	}
+	if higher.ScheduleTimezone != "" {
+		result.ScheduleTimezone = higher.ScheduleTimezone
+	} else {
+		result.ScheduleTimezone = lower.ScheduleTimezone
+	}
+
+	// Merge capability parameters.
+	result.StrictCapabilities = lower.StrictCapabilities || higher.StrictCapabilities
+
+	// Merge metadata parameters.
+	result.PreserveMacOSMetadata = lower.PreserveMacOSMetadata || higher.PreserveMacOSMetadata
+	result.PreserveFileFlags = lower.PreserveFileFlags || higher.PreserveFileFlags
+
+	// Merge line ending patterns. These are additive, like ignores.
+	result.LineEndingPatterns = append(result.LineEndingPatterns, lower.LineEndingPatterns...)
+	result.LineEndingPatterns = append(result.LineEndingPatterns, higher.LineEndingPatterns...)
+
+	// Merge line ending style.
+	if !higher.LineEndingStyle.IsDefault() {
+		result.LineEndingStyle = higher.LineEndingStyle
+	} else {
+		result.LineEndingStyle = lower.LineEndingStyle
+	}
+
+	// Merge conflict pause threshold.
+	if higher.ConflictPauseThreshold != 0 {
+		result.ConflictPauseThreshold = higher.ConflictPauseThreshold
+	} else {
+		result.ConflictPauseThreshold = lower.ConflictPauseThreshold
+	}
+
+	// Merge conflict sidecar behavior.
+	result.ConflictSidecars = lower.ConflictSidecars || higher.ConflictSidecars
+
+	// Merge agent memory limit.
+	if higher.AgentMemoryLimit != 0 {
+		result.AgentMemoryLimit = higher.AgentMemoryLimit
+	} else {
+		result.AgentMemoryLimit = lower.AgentMemoryLimit
+	}
+
+	// Merge agent CPU limit.
+	if higher.AgentCPULimit != 0 {
+		result.AgentCPULimit = higher.AgentCPULimit
+	} else {
+		result.AgentCPULimit = lower.AgentCPULimit
+	}
+
+	// Merge agent cache host.
+	if higher.AgentCacheHost != "" {
+		result.AgentCacheHost = higher.AgentCacheHost
+	} else {
+		result.AgentCacheHost = lower.AgentCacheHost
+	}
+
+	// Merge undo maximum size.
+	if higher.UndoMaximumSize != 0 {
+		result.UndoMaximumSize = higher.UndoMaximumSize
+	} else {
+		result.UndoMaximumSize = lower.UndoMaximumSize
+	}
+
+	// Merge undo maximum age.
+	if higher.UndoMaximumAge != 0 {
+		result.UndoMaximumAge = higher.UndoMaximumAge
+	} else {
+		result.UndoMaximumAge = lower.UndoMaximumAge
+	}
+
+	// Merge invalid name mode.
+	if !higher.InvalidNameMode.IsDefault() {
+		result.InvalidNameMode = higher.InvalidNameMode
+	} else {
+		result.InvalidNameMode = lower.InvalidNameMode
+	}
+
+	// Merge long path mode.
+	if !higher.LongPathMode.IsDefault() {
+		result.LongPathMode = higher.LongPathMode
+	} else {
+		result.LongPathMode = lower.LongPathMode
+	}
+
+	// Merge filename encoding.
+	if !higher.FilenameEncoding.IsDefault() {
+		result.FilenameEncoding = higher.FilenameEncoding
+	} else {
+		result.FilenameEncoding = lower.FilenameEncoding
+	}
+
+	// Merge path mapping. Rules are merged as a unit (along with their
+	// directionality) since their order is significant.
+	if len(higher.PathMappings) > 0 {
+		result.PathMappings = higher.PathMappings
+		result.PathMappingOneWay = higher.PathMappingOneWay
+	} else {
+		result.PathMappings = lower.PathMappings
+		result.PathMappingOneWay = lower.PathMappingOneWay
+	}
+
+	// Merge transfer priority.
+	if !higher.TransferPriority.IsDefault() {
+		result.TransferPriority = higher.TransferPriority
+	} else {
+		result.TransferPriority = lower.TransferPriority
+	}
+
+	// Merge delta transfer mode.
+	if !higher.DeltaTransferMode.IsDefault() {
+		result.DeltaTransferMode = higher.DeltaTransferMode
+	} else {
+		result.DeltaTransferMode = lower.DeltaTransferMode
+	}
+
+	// Merge cycle transfer budget.
+	if higher.CycleTransferBudget != 0 {
+		result.CycleTransferBudget = higher.CycleTransferBudget
+	} else {
+		result.CycleTransferBudget = lower.CycleTransferBudget
+	}
+
+	// Merge available space checking.
+	result.CheckAvailableSpace = lower.CheckAvailableSpace || higher.CheckAvailableSpace
+
+	// Merge integrity parameters.
+	result.ComputeMerkleRoot = lower.ComputeMerkleRoot || higher.ComputeMerkleRoot
+
+	// Merge persistence parameters.
+	if !higher.ArchiveCompressionMode.IsDefault() {
+		result.ArchiveCompressionMode = higher.ArchiveCompressionMode
+	} else {
+		result.ArchiveCompressionMode = lower.ArchiveCompressionMode
+	}
+
+	// Merge deletion pause threshold.
+	if higher.DeletionPauseThreshold != 0 {
+		result.DeletionPauseThreshold = higher.DeletionPauseThreshold
+	} else {
+		result.DeletionPauseThreshold = lower.DeletionPauseThreshold
+	}
+
+	// Merge deletion pause percentage.
+	if higher.DeletionPausePercentage != 0 {
+		result.DeletionPausePercentage = higher.DeletionPausePercentage
+	} else {
+		result.DeletionPausePercentage = lower.DeletionPausePercentage
+	}
+
+	// Merge baseline startup.
+	result.BaselineStartup = lower.BaselineStartup || higher.BaselineStartup
+
+	// Merge quarantine threshold.
+	if higher.QuarantineThreshold != 0 {
+		result.QuarantineThreshold = higher.QuarantineThreshold
+	} else {
+		result.QuarantineThreshold = lower.QuarantineThreshold
+	}
+
 	// Done.
 	return result
 }
+
+// AgentResourceLimits returns the resource limits under which remote agents
+// should be launched. It returns nil if no limits are specified.
+func (c *Configuration) AgentResourceLimits() *agent.ResourceLimits {
+	if c.AgentMemoryLimit == 0 && c.AgentCPULimit == 0 {
+		return nil
+	}
+	return &agent.ResourceLimits{
+		Memory: c.AgentMemoryLimit,
+		CPU:    c.AgentCPULimit,
+	}
+}
@@ -9,6 +9,7 @@ package synchronization
 import (
 	proto "github.com/golang/protobuf/proto"
 	behavior "github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+	ssh "github.com/mutagen-io/mutagen/pkg/ssh"
 	core "github.com/mutagen-io/mutagen/pkg/synchronization/core"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
@@ -50,15 +51,68 @@ type Configuration struct {
 	ScanMode ScanMode `protobuf:"varint,15,opt,name=scanMode,proto3,enum=synchronization.ScanMode" json:"scanMode,omitempty"`
 	// StageMode specifies the file staging mode.
 	StageMode StageMode `protobuf:"varint,16,opt,name=stageMode,proto3,enum=synchronization.StageMode" json:"stageMode,omitempty"`
+	// ContentStoreMode specifies the shared content store mode.
+	ContentStoreMode ContentStoreMode `protobuf:"varint,17,opt,name=contentStoreMode,proto3,enum=synchronization.ContentStoreMode" json:"contentStoreMode,omitempty"`
+	// ConflictResolverCommand specifies an external command (and its
+	// arguments) that should be invoked on beta to resolve conflicts between
+	// modified files in two-way-safe mode. The command is invoked with the
+	// MUTAGEN_CONFLICT_PATH environment variable set to the conflicting path
+	// (relative to the synchronization root), the MUTAGEN_CONFLICT_ALPHA and
+	// MUTAGEN_CONFLICT_BETA environment variables set to paths of temporary
+	// files containing alpha's and beta's versions of the file, and the
+	// MUTAGEN_CONFLICT_OUTPUT environment variable set to a path where the
+	// resolved content should be written. On success, the command should exit
+	// with a zero exit code and print either "resolved" (indicating that the
+	// resolved content was written to the output path) or "conflicted"
+	// (indicating that the conflict should be left in place). Any other exit
+	// code or output, as well as a failure to complete within the conflict
+	// resolver timeout, leaves the conflict in place. An empty command
+	// indicates that conflicts should not be resolved externally.
+	ConflictResolverCommand []string `protobuf:"bytes,18,rep,name=conflictResolverCommand,proto3" json:"conflictResolverCommand,omitempty"`
+	// ConflictResolverTimeout specifies the maximum amount of time (in seconds)
+	// that an invocation of the conflict resolver command may take. A value of
+	// 0 specifies that the default timeout should be used.
+	ConflictResolverTimeout uint32 `protobuf:"varint,19,opt,name=conflictResolverTimeout,proto3" json:"conflictResolverTimeout,omitempty"`
+	// MaximumFileSize is the maximum (individual) file size that endpoints
+	// will synchronize. Files exceeding this size are skipped during scanning
+	// and reported as problems. A zero value indicates no limit.
+	MaximumFileSize uint64 `protobuf:"varint,20,opt,name=maximumFileSize,proto3" json:"maximumFileSize,omitempty"`
 	// SymlinkMode specifies the symlink mode that should be used in
 	// synchronization.
 	SymlinkMode core.SymlinkMode `protobuf:"varint,1,opt,name=symlinkMode,proto3,enum=core.SymlinkMode" json:"symlinkMode,omitempty"`
+	// PreserveHardLinks specifies whether or not hard links between files
+	// should be detected during scanning and recreated during transitions
+	// (rather than being expanded into separate copies). This is only
+	// supported on POSIX systems and is always treated as a session-wide
+	// parameter.
+	PreserveHardLinks bool `protobuf:"varint,2,opt,name=preserveHardLinks,proto3" json:"preserveHardLinks,omitempty"`
+	// DeferSymlinks specifies whether or not the creation of symbolic links
+	// whose targets lie within the synchronization root should be deferred
+	// until those targets exist, avoiding transiently dangling links. Links
+	// whose targets don't appear during a transition are created at its end,
+	// while links with targets outside of the synchronization root are
+	// created immediately.
+	DeferSymlinks bool `protobuf:"varint,3,opt,name=deferSymlinks,proto3" json:"deferSymlinks,omitempty"`
+	// BrokenSymlinkMode specifies the handling of broken symbolic links (those
+	// whose targets lie outside of the synchronization root and don't exist)
+	// in portable symlink mode. It is always treated as a session-wide
+	// parameter.
+	BrokenSymlinkMode core.BrokenSymlinkMode `protobuf:"varint,4,opt,name=brokenSymlinkMode,proto3,enum=core.BrokenSymlinkMode" json:"brokenSymlinkMode,omitempty"`
 	// WatchMode specifies the filesystem watching mode.
 	WatchMode WatchMode `protobuf:"varint,21,opt,name=watchMode,proto3,enum=synchronization.WatchMode" json:"watchMode,omitempty"`
 	// WatchPollingInterval specifies the interval (in seconds) for poll-based
 	// file monitoring. A value of 0 specifies that the default interval should
 	// be used.
 	WatchPollingInterval uint32 `protobuf:"varint,22,opt,name=watchPollingInterval,proto3" json:"watchPollingInterval,omitempty"`
+	// DeletionGracePeriod specifies the amount of time (in milliseconds) for
+	// which the propagation of apparent deletions should be held after they're
+	// detected by a scan. If any deletions are detected, then both endpoints
+	// are rescanned once the grace period elapses, and only those deletions
+	// that persist are propagated. This absorbs transient gaps introduced by
+	// atomic file replacement (e.g. by editors that write a temporary file and
+	// then rename it over the original). A value of 0 disables the grace
+	// period. It is always treated as a session-wide parameter.
+	DeletionGracePeriod uint32 `protobuf:"varint,23,opt,name=deletionGracePeriod,proto3" json:"deletionGracePeriod,omitempty"`
 	// DefaultIgnores specifies the ignore patterns brought in from the global
 	// configuration.
 	// DEPRECATED: This field is no longer used when loading from global
@@ -71,6 +125,26 @@ type Configuration struct {
 	// IgnoreVCSMode specifies the VCS ignore mode that should be used in
 	// synchronization.
 	IgnoreVCSMode core.IgnoreVCSMode `protobuf:"varint,33,opt,name=ignoreVCSMode,proto3,enum=core.IgnoreVCSMode" json:"ignoreVCSMode,omitempty"`
+	// IgnoreSets specifies the names of shared ignore sets whose patterns
+	// should be loaded from the Mutagen data directory and prepended to the
+	// ignore patterns specified by Ignores.
+	IgnoreSets []string `protobuf:"bytes,34,rep,name=ignoreSets,proto3" json:"ignoreSets,omitempty"`
+	// IgnoreGitIgnored specifies whether or not paths that are ignored by Git
+	// (according to the effective ignore rules of any Git repositories
+	// containing or contained within the synchronization root) should be
+	// ignored in addition to those matched by ignore patterns.
+	IgnoreGitIgnored bool `protobuf:"varint,35,opt,name=ignoreGitIgnored,proto3" json:"ignoreGitIgnored,omitempty"`
+	// ContentTypeMode specifies the mode for filtering files based on whether
+	// their content is text or binary (as determined by sniffing the leading
+	// content of each file for NUL bytes). Files that are excluded are skipped
+	// during scanning and reported as problems.
+	ContentTypeMode core.ContentTypeMode `protobuf:"varint,36,opt,name=contentTypeMode,proto3,enum=core.ContentTypeMode" json:"contentTypeMode,omitempty"`
+	// IgnoreOpenFiles specifies whether or not files that are currently open
+	// for writing by another process should be transiently ignored (i.e.
+	// skipped during scanning and reported as problems) until they're closed.
+	// Detection is only supported on some platforms, and this behavior is
+	// disabled (with a warning) on platforms where it's not supported.
+	IgnoreOpenFiles bool `protobuf:"varint,37,opt,name=ignoreOpenFiles,proto3" json:"ignoreOpenFiles,omitempty"`
 	// DefaultFileMode specifies the default permission mode to use for new
 	// files in "portable" permission propagation mode.
 	DefaultFileMode uint32 `protobuf:"varint,63,opt,name=defaultFileMode,proto3" json:"defaultFileMode,omitempty"`
@@ -85,6 +159,235 @@ type Configuration struct {
 	// ownership of new files and directories in "portable" permission
 	// propagation mode.
 	DefaultGroup string `protobuf:"bytes,66,opt,name=defaultGroup,proto3" json:"defaultGroup,omitempty"`
+	// ACLMode specifies the POSIX ACL propagation mode.
+	AclMode core.ACLMode `protobuf:"varint,67,opt,name=aclMode,proto3,enum=core.ACLMode" json:"aclMode,omitempty"`
+	// HostVerificationMode specifies the remote host verification mode.
+	HostVerificationMode HostVerificationMode `protobuf:"varint,81,opt,name=hostVerificationMode,proto3,enum=synchronization.HostVerificationMode" json:"hostVerificationMode,omitempty"`
+	// SshOptions specifies structured OpenSSH connection options for SSH
+	// endpoints. Options specified via SSH URL parameters (and the URL port)
+	// take precedence over these options.
+	SshOptions *ssh.Options `protobuf:"bytes,82,opt,name=sshOptions,proto3" json:"sshOptions,omitempty"`
+	// DurabilityMode specifies the mode for flushing filesystem modifications
+	// to durable storage.
+	DurabilityMode core.DurabilityMode `protobuf:"varint,91,opt,name=durabilityMode,proto3,enum=core.DurabilityMode" json:"durabilityMode,omitempty"`
+	// ModificationHandlingMode specifies the mode for handling files that are
+	// modified while being transmitted for staging.
+	ModificationHandlingMode ModificationHandlingMode `protobuf:"varint,101,opt,name=modificationHandlingMode,proto3,enum=synchronization.ModificationHandlingMode" json:"modificationHandlingMode,omitempty"`
+	// CloneStagingThreshold specifies the minimum size (in bytes) of an
+	// existing file for which changes will be staged by cloning the existing
+	// file and applying changes directly to the clone, rather than by staging
+	// a full copy of the file. Cloning uses copy-on-write reflinks, so it's
+	// only performed on filesystems that support them, with full copies being
+	// staged otherwise. A value of 0 disables clone-based staging.
+	CloneStagingThreshold uint64 `protobuf:"varint,102,opt,name=cloneStagingThreshold,proto3" json:"cloneStagingThreshold,omitempty"`
+	// StallTimeout specifies the maximum amount of time (in seconds) that the
+	// scan and transition stages may go without making progress before they're
+	// considered stalled. A value of 0 disables stall detection.
+	StallTimeout uint32 `protobuf:"varint,111,opt,name=stallTimeout,proto3" json:"stallTimeout,omitempty"`
+	// AbortOnStall specifies whether or not the synchronization cycle should be
+	// aborted when a stall is detected.
+	AbortOnStall bool `protobuf:"varint,112,opt,name=abortOnStall,proto3" json:"abortOnStall,omitempty"`
+	// CompressionThreshold specifies the minimum size (in bytes) of a message
+	// for which Mutagen-layer compression will be performed on remote endpoint
+	// connections. Smaller messages are sent uncompressed. A value of 0
+	// specifies that Mutagen's internal default threshold should be used.
+	CompressionThreshold uint64 `protobuf:"varint,121,opt,name=compressionThreshold,proto3" json:"compressionThreshold,omitempty"`
+	// IncompressibleExtensions specifies file extensions (e.g. ".zip") whose
+	// contents are known to be incompressible and for which Mutagen-layer
+	// compression will be bypassed during transmission.
+	IncompressibleExtensions []string `protobuf:"bytes,122,rep,name=incompressibleExtensions,proto3" json:"incompressibleExtensions,omitempty"`
+	// ProtectedPaths specifies glob patterns (relative to the synchronization
+	// root) for paths that synchronization will never delete or overwrite.
+	// Protection applies to matching paths and their contents. Transitions
+	// that would delete or overwrite protected content are refused and
+	// reported as problems, though new content can still be created within
+	// protected directories.
+	ProtectedPaths []string `protobuf:"bytes,131,rep,name=protectedPaths,proto3" json:"protectedPaths,omitempty"`
+	// VerifiedPaths specifies glob patterns (relative to the synchronization
+	// root) for files whose staged content is read back from disk and verified
+	// against its expected digest immediately before being moved into place.
+	// Verification applies to matching paths and their contents. If
+	// verification fails, then the existing file is left untouched and the
+	// content is restaged.
+	VerifiedPaths []string `protobuf:"bytes,132,rep,name=verifiedPaths,proto3" json:"verifiedPaths,omitempty"`
+	// ScanConcurrency specifies the maximum number of files whose digests will
+	// be computed concurrently when scanning. A value of 0 specifies that
+	// Mutagen's internal default concurrency should be used.
+	ScanConcurrency uint32 `protobuf:"varint,141,opt,name=scanConcurrency,proto3" json:"scanConcurrency,omitempty"`
+	// StagingConcurrency specifies the maximum number of files that will be
+	// read concurrently when preparing to stage content. A value of 0 specifies
+	// that Mutagen's internal default concurrency should be used.
+	StagingConcurrency uint32 `protobuf:"varint,142,opt,name=stagingConcurrency,proto3" json:"stagingConcurrency,omitempty"`
+	// ScanWarmup indicates whether or not endpoints should perform a
+	// background warmup scan when they're created, populating their digest
+	// caches ahead of the first synchronization cycle. The warmup is subject
+	// to the scan concurrency limit.
+	ScanWarmup bool `protobuf:"varint,143,opt,name=scanWarmup,proto3" json:"scanWarmup,omitempty"`
+	// ScheduleWindows specifies the time windows during which the session will
+	// actively synchronize. Each window has the form "[days] HH:MM-HH:MM",
+	// where the optional days component is a comma-separated list of weekdays
+	// or weekday ranges (e.g. "mon-fri" or "sat,sun"). A window whose end time
+	// is not after its start time extends into the following day. Outside of
+	// all windows, the session will be paused. If no windows are specified,
+	// then the session isn't subject to a schedule.
+	ScheduleWindows []string `protobuf:"bytes,151,rep,name=scheduleWindows,proto3" json:"scheduleWindows,omitempty"`
+	// ScheduleTimezone specifies the IANA time zone name (e.g.
+	// "Europe/Berlin") in which schedule windows are evaluated. If empty, then
+	// the local time zone of the daemon is used.
+	ScheduleTimezone string `protobuf:"bytes,152,opt,name=scheduleTimezone,proto3" json:"scheduleTimezone,omitempty"`
+	// StrictCapabilities specifies that session startup should fail if an
+	// endpoint lacks the filesystem capabilities required by explicitly
+	// requested features (e.g. POSIX ACL propagation), rather than silently
+	// degrading those features.
+	StrictCapabilities bool `protobuf:"varint,161,opt,name=strictCapabilities,proto3" json:"strictCapabilities,omitempty"`
+	// PreserveMacOSMetadata specifies whether or not macOS metadata (Finder
+	// information, resource forks, and other com.apple.* extended attributes)
+	// should be preserved. On macOS, this metadata is captured and restored
+	// natively. On other platforms, it's stored in AppleDouble ("._"-prefixed)
+	// sidecar files so that it survives a round trip back to macOS.
+	PreserveMacOSMetadata bool `protobuf:"varint,171,opt,name=preserveMacOSMetadata,proto3" json:"preserveMacOSMetadata,omitempty"`
+	// PreserveFileFlags specifies whether or not BSD-style user file flags
+	// (e.g. hidden and user immutable) should be preserved. Flags are only
+	// captured and restored on platforms that support them (currently macOS
+	// and FreeBSD), with flags that can't be restored on an endpoint being
+	// reported as problems.
+	PreserveFileFlags bool `protobuf:"varint,172,opt,name=preserveFileFlags,proto3" json:"preserveFileFlags,omitempty"`
+	// LineEndingPatterns specifies the patterns identifying text files whose
+	// line endings should be translated to each endpoint's line ending style.
+	// Patterns containing a slash are matched against full paths, while other
+	// patterns are matched against base names. Translation is disabled if no
+	// patterns are specified.
+	LineEndingPatterns []string `protobuf:"bytes,181,rep,name=lineEndingPatterns,proto3" json:"lineEndingPatterns,omitempty"`
+	// LineEndingStyle specifies the line ending style to which files subject
+	// to line ending translation are converted when written to an endpoint.
+	LineEndingStyle core.LineEndingStyle `protobuf:"varint,182,opt,name=lineEndingStyle,proto3,enum=core.LineEndingStyle" json:"lineEndingStyle,omitempty"`
+	// ConflictPauseThreshold specifies the maximum number of conflicts that a
+	// synchronization cycle may encounter before the session is automatically
+	// paused. If a cycle encounters more conflicts than this threshold, then
+	// the session is paused (before any changes are applied) with a recorded
+	// reason, and it remains paused until manually resumed. A value of 0
+	// disables automatic pausing.
+	ConflictPauseThreshold uint64 `protobuf:"varint,191,opt,name=conflictPauseThreshold,proto3" json:"conflictPauseThreshold,omitempty"`
+	// ConflictSidecars specifies that conflicts between modified files in
+	// two-way-safe mode should be resolved in alpha's favor, with beta's version
+	// of each file preserved alongside it on beta in a conflict sidecar file
+	// (named by appending ".mutagen-conflict-beta" to the file name). Conflict
+	// sidecar files are excluded from synchronization and are removed once the
+	// file that they accompany is modified or removed. It can't be combined with
+	// a conflict resolver command.
+	ConflictSidecars bool `protobuf:"varint,192,opt,name=conflictSidecars,proto3" json:"conflictSidecars,omitempty"`
+	// AgentMemoryLimit specifies the maximum amount of memory (in bytes) that
+	// a remote agent may use. Agents that exceed this limit terminate cleanly
+	// and the failure is reported as such. A value of 0 indicates no limit.
+	AgentMemoryLimit uint64 `protobuf:"varint,201,opt,name=agentMemoryLimit,proto3" json:"agentMemoryLimit,omitempty"`
+	// AgentCPULimit specifies the maximum amount of CPU time that a remote
+	// agent may use, expressed as a percentage of a single CPU. A value of 0
+	// indicates no limit.
+	AgentCPULimit uint32 `protobuf:"varint,202,opt,name=agentCPULimit,proto3" json:"agentCPULimit,omitempty"`
+	// AgentCacheHost specifies a regional agent cache host (of the form
+	// [user@]host) through which agent executables should be installed on
+	// remotes. An empty value indicates that no cache should be used.
+	AgentCacheHost string `protobuf:"bytes,203,opt,name=agentCacheHost,proto3" json:"agentCacheHost,omitempty"`
+	// UndoMaximumSize specifies the maximum total size (in bytes) of the
+	// previous file contents that endpoints retain in order to allow the last
+	// synchronization cycle to be undone. If the content overwritten or
+	// deleted by a cycle exceeds this size, then that cycle can't be undone. A
+	// value of 0 disables undo support.
+	UndoMaximumSize uint64 `protobuf:"varint,211,opt,name=undoMaximumSize,proto3" json:"undoMaximumSize,omitempty"`
+	// UndoMaximumAge specifies the maximum amount of time (in seconds) after a
+	// synchronization cycle for which that cycle can be undone. A value of 0
+	// indicates no limit.
+	UndoMaximumAge uint32 `protobuf:"varint,212,opt,name=undoMaximumAge,proto3" json:"undoMaximumAge,omitempty"`
+	// InvalidNameMode specifies the handling of names that can't be
+	// represented on an endpoint's filesystem (e.g. names containing ':' or
+	// '?' on Windows). It is always treated as a session-wide parameter.
+	InvalidNameMode core.InvalidNameMode `protobuf:"varint,221,opt,name=invalidNameMode,proto3,enum=core.InvalidNameMode" json:"invalidNameMode,omitempty"`
+	// LongPathMode specifies the handling of content whose on-disk paths would
+	// exceed the path length limit of an endpoint's platform (e.g. MAX_PATH on
+	// Windows). It is always treated as a session-wide parameter.
+	LongPathMode core.LongPathMode `protobuf:"varint,222,opt,name=longPathMode,proto3,enum=core.LongPathMode" json:"longPathMode,omitempty"`
+	// FilenameEncoding specifies the encoding used for names on an endpoint's
+	// filesystem. Names are transcoded to and from UTF-8 by the endpoint, so
+	// synchronization is always performed using UTF-8 names. Names that can't
+	// be decoded are reported as problems. It is ignored on Windows, where
+	// names are always stored as Unicode.
+	FilenameEncoding core.FilenameEncoding `protobuf:"varint,223,opt,name=filenameEncoding,proto3,enum=core.FilenameEncoding" json:"filenameEncoding,omitempty"`
+	// PathMappings specifies rules that rewrite the paths of content placed on
+	// beta, allowing it to be stored in a differently structured hierarchy.
+	// Rules take the form "source=>destination" (for path prefixes) or
+	// "regex:pattern=>replacement" (for regular expressions), with the first
+	// matching rule applied to each path. Content whose mapped path collides
+	// with other content is reported as a problem. It is always treated as a
+	// session-wide parameter.
+	PathMappings []string `protobuf:"bytes,224,rep,name=pathMappings,proto3" json:"pathMappings,omitempty"`
+	// PathMappingOneWay indicates that path mappings are only applied in the
+	// alpha-to-beta direction, in which case beta content is only mapped back
+	// if it corresponds to known content. It is required for mappings that use
+	// regular expressions and is only supported with one-way synchronization
+	// modes. It is always treated as a session-wide parameter.
+	PathMappingOneWay bool `protobuf:"varint,225,opt,name=pathMappingOneWay,proto3" json:"pathMappingOneWay,omitempty"`
+	// TransferPriority specifies the priority with which the session's
+	// staging transfers are scheduled against the daemon's shared transfer
+	// budget when competing with other sessions. It is always treated as a
+	// session-wide parameter.
+	TransferPriority TransferPriority `protobuf:"varint,231,opt,name=transferPriority,proto3,enum=synchronization.TransferPriority" json:"transferPriority,omitempty"`
+	// DeltaTransferMode specifies how files are transmitted when staging on
+	// the opposite endpoint, i.e. whether they're transmitted as rsync deltas
+	// against existing content or in their entirety.
+	DeltaTransferMode DeltaTransferMode `protobuf:"varint,232,opt,name=deltaTransferMode,proto3,enum=synchronization.DeltaTransferMode" json:"deltaTransferMode,omitempty"`
+	// CycleTransferBudget specifies the maximum number of bytes of file
+	// content to transfer between alpha and beta during a single
+	// synchronization cycle. Changes that don't fit within the budget are
+	// deferred to subsequent cycles, though a single file that exceeds the
+	// budget on its own is transferred by itself. A value of 0 indicates no
+	// limit. It is always treated as a session-wide parameter.
+	CycleTransferBudget uint64 `protobuf:"varint,233,opt,name=cycleTransferBudget,proto3" json:"cycleTransferBudget,omitempty"`
+	// CheckAvailableSpace indicates whether or not the space available for
+	// staging on each endpoint (including any disk quota) should be checked
+	// before transferring files. If a cycle's transfers wouldn't fit, then the
+	// session is paused rather than failing partway through staging. It is
+	// always treated as a session-wide parameter.
+	CheckAvailableSpace bool `protobuf:"varint,234,opt,name=checkAvailableSpace,proto3" json:"checkAvailableSpace,omitempty"`
+	// ComputeMerkleRoot specifies that a Merkle tree digest of each
+	// endpoint's synchronization root should be computed after each scan and
+	// reported in the session state. It is always treated as a session-wide
+	// parameter.
+	ComputeMerkleRoot bool `protobuf:"varint,241,opt,name=computeMerkleRoot,proto3" json:"computeMerkleRoot,omitempty"`
+	// ArchiveCompressionMode specifies the compression to use when persisting
+	// the session's ancestor archive to disk. Archives are self-describing, so
+	// they can be loaded regardless of the mode under which they were saved.
+	// It is always treated as a session-wide parameter.
+	ArchiveCompressionMode ArchiveCompressionMode `protobuf:"varint,251,opt,name=archiveCompressionMode,proto3,enum=synchronization.ArchiveCompressionMode" json:"archiveCompressionMode,omitempty"`
+	// DeletionPauseThreshold specifies the maximum number of entries that a
+	// synchronization cycle may delete on either endpoint before the session is
+	// automatically paused. If a cycle would delete more entries than this
+	// threshold on either endpoint, then the session is paused (before any
+	// changes are applied) with a recorded reason, and it remains paused until
+	// manually resumed, with resumption confirming the deletions. A value of 0
+	// disables the check.
+	DeletionPauseThreshold uint64 `protobuf:"varint,261,opt,name=deletionPauseThreshold,proto3" json:"deletionPauseThreshold,omitempty"`
+	// DeletionPausePercentage specifies the maximum percentage of the entries
+	// recorded in the session's ancestor that a synchronization cycle may
+	// delete on either endpoint before the session is automatically paused.
+	// It is applied in the same manner as DeletionPauseThreshold. A value of 0
+	// disables the check.
+	DeletionPausePercentage uint32 `protobuf:"varint,262,opt,name=deletionPausePercentage,proto3" json:"deletionPausePercentage,omitempty"`
+	// BaselineStartup specifies that the session should treat the existing
+	// contents of its endpoints (which must be equal) as already synchronized,
+	// seeding its ancestor from them on its first synchronization cycle without
+	// staging or transitioning any content, so that only subsequent changes
+	// are synchronized. If the endpoint contents differ, then the session is
+	// paused until they're made equal. It is always treated as a session-wide
+	// parameter.
+	BaselineStartup bool `protobuf:"varint,271,opt,name=baselineStartup,proto3" json:"baselineStartup,omitempty"`
+	// QuarantineThreshold specifies the number of consecutive synchronization
+	// cycles in which a path's transition must fail before the path is
+	// quarantined. Quarantined paths are skipped (and reported in the session
+	// state) until they're manually retried, allowing the rest of the
+	// synchronization root to reach a steady state. A path's failure count is
+	// reset when its transition succeeds. A value of 0 disables quarantining.
+	// It is always treated as a session-wide parameter.
+	QuarantineThreshold uint64 `protobuf:"varint,281,opt,name=quarantineThreshold,proto3" json:"quarantineThreshold,omitempty"`
 }
 
 func (x *Configuration) Reset() {
@@ -161,6 +464,34 @@ func (x *Configuration) GetStageMode() StageMode {
 	return StageMode_StageModeDefault
 }
 
+func (x *Configuration) GetContentStoreMode() ContentStoreMode {
+	if x != nil {
+		return x.ContentStoreMode
+	}
+	return ContentStoreMode_ContentStoreModeDefault
+}
+
+func (x *Configuration) GetConflictResolverCommand() []string {
+	if x != nil {
+		return x.ConflictResolverCommand
+	}
+	return nil
+}
+
+func (x *Configuration) GetConflictResolverTimeout() uint32 {
+	if x != nil {
+		return x.ConflictResolverTimeout
+	}
+	return 0
+}
+
+func (x *Configuration) GetMaximumFileSize() uint64 {
+	if x != nil {
+		return x.MaximumFileSize
+	}
+	return 0
+}
+
 func (x *Configuration) GetSymlinkMode() core.SymlinkMode {
 	if x != nil {
 		return x.SymlinkMode
@@ -168,6 +499,27 @@ func (x *Configuration) GetSymlinkMode() core.SymlinkMode {
 	return core.SymlinkMode_SymlinkModeDefault
 }
 
+func (x *Configuration) GetPreserveHardLinks() bool {
+	if x != nil {
+		return x.PreserveHardLinks
+	}
+	return false
+}
+
+func (x *Configuration) GetDeferSymlinks() bool {
+	if x != nil {
+		return x.DeferSymlinks
+	}
+	return false
+}
+
+func (x *Configuration) GetBrokenSymlinkMode() core.BrokenSymlinkMode {
+	if x != nil {
+		return x.BrokenSymlinkMode
+	}
+	return core.BrokenSymlinkMode_BrokenSymlinkModeDefault
+}
+
 func (x *Configuration) GetWatchMode() WatchMode {
 	if x != nil {
 		return x.WatchMode
@@ -182,6 +534,13 @@ func (x *Configuration) GetWatchPollingInterval() uint32 {
 	return 0
 }
 
+func (x *Configuration) GetDeletionGracePeriod() uint32 {
+	if x != nil {
+		return x.DeletionGracePeriod
+	}
+	return 0
+}
+
 func (x *Configuration) GetDefaultIgnores() []string {
 	if x != nil {
 		return x.DefaultIgnores
@@ -203,6 +562,34 @@ func (x *Configuration) GetIgnoreVCSMode() core.IgnoreVCSMode {
 	return core.IgnoreVCSMode_IgnoreVCSModeDefault
 }
 
+func (x *Configuration) GetIgnoreSets() []string {
+	if x != nil {
+		return x.IgnoreSets
+	}
+	return nil
+}
+
+func (x *Configuration) GetIgnoreGitIgnored() bool {
+	if x != nil {
+		return x.IgnoreGitIgnored
+	}
+	return false
+}
+
+func (x *Configuration) GetContentTypeMode() core.ContentTypeMode {
+	if x != nil {
+		return x.ContentTypeMode
+	}
+	return core.ContentTypeMode_ContentTypeModeDefault
+}
+
+func (x *Configuration) GetIgnoreOpenFiles() bool {
+	if x != nil {
+		return x.IgnoreOpenFiles
+	}
+	return false
+}
+
 func (x *Configuration) GetDefaultFileMode() uint32 {
 	if x != nil {
 		return x.DefaultFileMode
@@ -231,6 +618,314 @@ func (x *Configuration) GetDefaultGroup() string {
 	return ""
 }
 
+func (x *Configuration) GetAclMode() core.ACLMode {
+	if x != nil {
+		return x.AclMode
+	}
+	return core.ACLMode_ACLModeDefault
+}
+
+func (x *Configuration) GetHostVerificationMode() HostVerificationMode {
+	if x != nil {
+		return x.HostVerificationMode
+	}
+	return HostVerificationMode_HostVerificationModeDefault
+}
+
+func (x *Configuration) GetSshOptions() *ssh.Options {
+	if x != nil {
+		return x.SshOptions
+	}
+	return nil
+}
+
+func (x *Configuration) GetDurabilityMode() core.DurabilityMode {
+	if x != nil {
+		return x.DurabilityMode
+	}
+	return core.DurabilityMode_DurabilityModeDefault
+}
+
+func (x *Configuration) GetModificationHandlingMode() ModificationHandlingMode {
+	if x != nil {
+		return x.ModificationHandlingMode
+	}
+	return ModificationHandlingMode_ModificationHandlingModeDefault
+}
+
+func (x *Configuration) GetCloneStagingThreshold() uint64 {
+	if x != nil {
+		return x.CloneStagingThreshold
+	}
+	return 0
+}
+
+func (x *Configuration) GetStallTimeout() uint32 {
+	if x != nil {
+		return x.StallTimeout
+	}
+	return 0
+}
+
+func (x *Configuration) GetAbortOnStall() bool {
+	if x != nil {
+		return x.AbortOnStall
+	}
+	return false
+}
+
+func (x *Configuration) GetCompressionThreshold() uint64 {
+	if x != nil {
+		return x.CompressionThreshold
+	}
+	return 0
+}
+
+func (x *Configuration) GetIncompressibleExtensions() []string {
+	if x != nil {
+		return x.IncompressibleExtensions
+	}
+	return nil
+}
+
+func (x *Configuration) GetProtectedPaths() []string {
+	if x != nil {
+		return x.ProtectedPaths
+	}
+	return nil
+}
+
+func (x *Configuration) GetVerifiedPaths() []string {
+	if x != nil {
+		return x.VerifiedPaths
+	}
+	return nil
+}
+
+func (x *Configuration) GetScanConcurrency() uint32 {
+	if x != nil {
+		return x.ScanConcurrency
+	}
+	return 0
+}
+
+func (x *Configuration) GetStagingConcurrency() uint32 {
+	if x != nil {
+		return x.StagingConcurrency
+	}
+	return 0
+}
+
+func (x *Configuration) GetScanWarmup() bool {
+	if x != nil {
+		return x.ScanWarmup
+	}
+	return false
+}
+
+func (x *Configuration) GetScheduleWindows() []string {
+	if x != nil {
+		return x.ScheduleWindows
+	}
+	return nil
+}
+
+func (x *Configuration) GetScheduleTimezone() string {
+	if x != nil {
+		return x.ScheduleTimezone
+	}
+	return ""
+}
+
+func (x *Configuration) GetStrictCapabilities() bool {
+	if x != nil {
+		return x.StrictCapabilities
+	}
+	return false
+}
+
+func (x *Configuration) GetPreserveMacOSMetadata() bool {
+	if x != nil {
+		return x.PreserveMacOSMetadata
+	}
+	return false
+}
+
+func (x *Configuration) GetPreserveFileFlags() bool {
+	if x != nil {
+		return x.PreserveFileFlags
+	}
+	return false
+}
+
+func (x *Configuration) GetLineEndingPatterns() []string {
+	if x != nil {
+		return x.LineEndingPatterns
+	}
+	return nil
+}
+
+func (x *Configuration) GetLineEndingStyle() core.LineEndingStyle {
+	if x != nil {
+		return x.LineEndingStyle
+	}
+	return core.LineEndingStyle_LineEndingStyleDefault
+}
+
+func (x *Configuration) GetConflictPauseThreshold() uint64 {
+	if x != nil {
+		return x.ConflictPauseThreshold
+	}
+	return 0
+}
+
+func (x *Configuration) GetConflictSidecars() bool {
+	if x != nil {
+		return x.ConflictSidecars
+	}
+	return false
+}
+
+func (x *Configuration) GetAgentMemoryLimit() uint64 {
+	if x != nil {
+		return x.AgentMemoryLimit
+	}
+	return 0
+}
+
+func (x *Configuration) GetAgentCPULimit() uint32 {
+	if x != nil {
+		return x.AgentCPULimit
+	}
+	return 0
+}
+
+func (x *Configuration) GetAgentCacheHost() string {
+	if x != nil {
+		return x.AgentCacheHost
+	}
+	return ""
+}
+
+func (x *Configuration) GetUndoMaximumSize() uint64 {
+	if x != nil {
+		return x.UndoMaximumSize
+	}
+	return 0
+}
+
+func (x *Configuration) GetUndoMaximumAge() uint32 {
+	if x != nil {
+		return x.UndoMaximumAge
+	}
+	return 0
+}
+
+func (x *Configuration) GetInvalidNameMode() core.InvalidNameMode {
+	if x != nil {
+		return x.InvalidNameMode
+	}
+	return core.InvalidNameMode_InvalidNameModeDefault
+}
+
+func (x *Configuration) GetLongPathMode() core.LongPathMode {
+	if x != nil {
+		return x.LongPathMode
+	}
+	return core.LongPathMode_LongPathModeDefault
+}
+
+func (x *Configuration) GetFilenameEncoding() core.FilenameEncoding {
+	if x != nil {
+		return x.FilenameEncoding
+	}
+	return core.FilenameEncoding_FilenameEncodingDefault
+}
+
+func (x *Configuration) GetPathMappings() []string {
+	if x != nil {
+		return x.PathMappings
+	}
+	return nil
+}
+
+func (x *Configuration) GetPathMappingOneWay() bool {
+	if x != nil {
+		return x.PathMappingOneWay
+	}
+	return false
+}
+
+func (x *Configuration) GetTransferPriority() TransferPriority {
+	if x != nil {
+		return x.TransferPriority
+	}
+	return TransferPriority_TransferPriorityDefault
+}
+
+func (x *Configuration) GetDeltaTransferMode() DeltaTransferMode {
+	if x != nil {
+		return x.DeltaTransferMode
+	}
+	return DeltaTransferMode_DeltaTransferModeDefault
+}
+
+func (x *Configuration) GetCycleTransferBudget() uint64 {
+	if x != nil {
+		return x.CycleTransferBudget
+	}
+	return 0
+}
+
+func (x *Configuration) GetCheckAvailableSpace() bool {
+	if x != nil {
+		return x.CheckAvailableSpace
+	}
+	return false
+}
+
+func (x *Configuration) GetComputeMerkleRoot() bool {
+	if x != nil {
+		return x.ComputeMerkleRoot
+	}
+	return false
+}
+
+func (x *Configuration) GetArchiveCompressionMode() ArchiveCompressionMode {
+	if x != nil {
+		return x.ArchiveCompressionMode
+	}
+	return ArchiveCompressionMode_ArchiveCompressionModeDefault
+}
+
+func (x *Configuration) GetDeletionPauseThreshold() uint64 {
+	if x != nil {
+		return x.DeletionPauseThreshold
+	}
+	return 0
+}
+
+func (x *Configuration) GetDeletionPausePercentage() uint32 {
+	if x != nil {
+		return x.DeletionPausePercentage
+	}
+	return 0
+}
+
+func (x *Configuration) GetBaselineStartup() bool {
+	if x != nil {
+		return x.BaselineStartup
+	}
+	return false
+}
+
+func (x *Configuration) GetQuarantineThreshold() uint64 {
+	if x != nil {
+		return x.QuarantineThreshold
+	}
+	return 0
+}
+
 var File_synchronization_configuration_proto protoreflect.FileDescriptor
 
 var file_synchronization_configuration_proto_rawDesc = []byte{
@@ -239,21 +934,61 @@ var file_synchronization_configuration_proto_rawDesc = []byte{
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0f, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69,
 	0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x24, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x79, 0x73, 0x74,
 	0x65, 0x6d, 0x2f, 0x62, 0x65, 0x68, 0x61, 0x76, 0x69, 0x6f, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x62,
-	0x65, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x73, 0x79,
-	0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x73, 0x63,
-	0x61, 0x6e, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x20, 0x73,
-	0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x73,
-	0x74, 0x61, 0x67, 0x65, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
-	0x20, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x2f, 0x77, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x1a, 0x2a, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x5f, 0x76,
-	0x63, 0x73, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x73,
+	0x65, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x11, 0x73, 0x73,
+	0x68, 0x2f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
+	0x2e, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2f, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
+	0x28, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x5f, 0x6d,
+	0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x29, 0x73, 0x79, 0x6e, 0x63, 0x68,
+	0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x64, 0x65, 0x6c, 0x74, 0x61,
+	0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2c, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x68, 0x6f, 0x73, 0x74, 0x5f, 0x76, 0x65, 0x72, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x30, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2f, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x5f, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x69, 0x6e, 0x67, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x73, 0x63, 0x61, 0x6e, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x20, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69,
+	0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x73, 0x74, 0x61, 0x67, 0x65, 0x5f, 0x6d, 0x6f, 0x64,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x27, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f,
+	0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65,
+	0x72, 0x5f, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x20, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x2f, 0x77, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x23, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x61, 0x63, 0x6c, 0x5f, 0x6d, 0x6f, 0x64,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2e, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f,
+	0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x62, 0x72,
+	0x6f, 0x6b, 0x65, 0x6e, 0x5f, 0x73, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x5f, 0x6d, 0x6f, 0x64,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x27, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f,
+	0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x63, 0x6f,
+	0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x2a, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2c, 0x73, 0x79,
+	0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f,
+	0x72, 0x65, 0x2f, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x5f, 0x65, 0x6e, 0x63, 0x6f,
+	0x64, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2a, 0x73, 0x79, 0x6e, 0x63,
+	0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65,
+	0x2f, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x5f, 0x76, 0x63, 0x73, 0x5f, 0x6d, 0x6f, 0x64, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2c, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e,
+	0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x69, 0x6e, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x29, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x6c, 0x6f, 0x6e, 0x67, 0x5f,
+	0x70, 0x61, 0x74, 0x68, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
+	0x2c, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x65, 0x6e, 0x64, 0x69, 0x6e,
+	0x67, 0x5f, 0x73, 0x74, 0x79, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x73,
 	0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63,
 	0x6f, 0x72, 0x65, 0x2f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x27,
 	0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f,
 	0x63, 0x6f, 0x72, 0x65, 0x2f, 0x73, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x5f, 0x6d, 0x6f, 0x64,
-	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xac, 0x06, 0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x66,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xc8, 0x1d, 0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x66,
 	0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x4b, 0x0a, 0x13, 0x73, 0x79, 0x6e,
 	0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x6f, 0x64, 0x65,
 	0x18, 0x0b, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x53, 0x79,
@@ -276,39 +1011,224 @@ var file_synchronization_configuration_proto_rawDesc = []byte{
 	0x6f, 0x64, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x73, 0x79, 0x6e, 0x63,
 	0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x67,
 	0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x09, 0x73, 0x74, 0x61, 0x67, 0x65, 0x4d, 0x6f, 0x64, 0x65,
-	0x12, 0x33, 0x0a, 0x0b, 0x73, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x4d, 0x6f, 0x64, 0x65, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x53, 0x79, 0x6d,
-	0x6c, 0x69, 0x6e, 0x6b, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x0b, 0x73, 0x79, 0x6d, 0x6c, 0x69, 0x6e,
-	0x6b, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x38, 0x0a, 0x09, 0x77, 0x61, 0x74, 0x63, 0x68, 0x4d, 0x6f,
-	0x64, 0x65, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x73, 0x79, 0x6e, 0x63, 0x68,
-	0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68,
-	0x4d, 0x6f, 0x64, 0x65, 0x52, 0x09, 0x77, 0x61, 0x74, 0x63, 0x68, 0x4d, 0x6f, 0x64, 0x65, 0x12,
-	0x32, 0x0a, 0x14, 0x77, 0x61, 0x74, 0x63, 0x68, 0x50, 0x6f, 0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x49,
-	0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x77,
-	0x61, 0x74, 0x63, 0x68, 0x50, 0x6f, 0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x49, 0x6e, 0x74, 0x65, 0x72,
-	0x76, 0x61, 0x6c, 0x12, 0x26, 0x0a, 0x0e, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x49, 0x67,
-	0x6e, 0x6f, 0x72, 0x65, 0x73, 0x18, 0x1f, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x64, 0x65, 0x66,
-	0x61, 0x75, 0x6c, 0x74, 0x49, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x69,
-	0x67, 0x6e, 0x6f, 0x72, 0x65, 0x73, 0x18, 0x20, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x69, 0x67,
-	0x6e, 0x6f, 0x72, 0x65, 0x73, 0x12, 0x39, 0x0a, 0x0d, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x56,
-	0x43, 0x53, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x21, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x63,
-	0x6f, 0x72, 0x65, 0x2e, 0x49, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x56, 0x43, 0x53, 0x4d, 0x6f, 0x64,
-	0x65, 0x52, 0x0d, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x56, 0x43, 0x53, 0x4d, 0x6f, 0x64, 0x65,
-	0x12, 0x28, 0x0a, 0x0f, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x4d,
-	0x6f, 0x64, 0x65, 0x18, 0x3f, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x64, 0x65, 0x66, 0x61, 0x75,
-	0x6c, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x32, 0x0a, 0x14, 0x64, 0x65,
+	0x12, 0x4d, 0x0a, 0x10, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x6f, 0x72, 0x65,
+	0x4d, 0x6f, 0x64, 0x65, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x21, 0x2e, 0x73, 0x79, 0x6e,
+	0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x43, 0x6f, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x10, 0x63,
+	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12,
+	0x38, 0x0a, 0x17, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x52, 0x65, 0x73, 0x6f, 0x6c,
+	0x76, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x12, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x17, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76,
+	0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x38, 0x0a, 0x17, 0x63, 0x6f, 0x6e,
+	0x66, 0x6c, 0x69, 0x63, 0x74, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x54, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x17, 0x63, 0x6f, 0x6e, 0x66,
+	0x6c, 0x69, 0x63, 0x74, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x12, 0x28, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x46, 0x69,
+	0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x14, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x6d, 0x61,
+	0x78, 0x69, 0x6d, 0x75, 0x6d, 0x46, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x33, 0x0a,
+	0x0b, 0x73, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x11, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x53, 0x79, 0x6d, 0x6c, 0x69, 0x6e,
+	0x6b, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x0b, 0x73, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x4d, 0x6f,
+	0x64, 0x65, 0x12, 0x2c, 0x0a, 0x11, 0x70, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x48, 0x61,
+	0x72, 0x64, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x70,
+	0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x48, 0x61, 0x72, 0x64, 0x4c, 0x69, 0x6e, 0x6b, 0x73,
+	0x12, 0x38, 0x0a, 0x09, 0x77, 0x61, 0x74, 0x63, 0x68, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x15, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4d, 0x6f, 0x64, 0x65, 0x52,
+	0x09, 0x77, 0x61, 0x74, 0x63, 0x68, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x32, 0x0a, 0x14, 0x77, 0x61,
+	0x74, 0x63, 0x68, 0x50, 0x6f, 0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76,
+	0x61, 0x6c, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x77, 0x61, 0x74, 0x63, 0x68, 0x50,
+	0x6f, 0x6c, 0x6c, 0x69, 0x6e, 0x67, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x30,
+	0x0a, 0x13, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x69, 0x6f, 0x6e, 0x47, 0x72, 0x61, 0x63, 0x65, 0x50,
+	0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x17, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x13, 0x64, 0x65, 0x6c,
+	0x65, 0x74, 0x69, 0x6f, 0x6e, 0x47, 0x72, 0x61, 0x63, 0x65, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64,
+	0x12, 0x26, 0x0a, 0x0e, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x49, 0x67, 0x6e, 0x6f, 0x72,
+	0x65, 0x73, 0x18, 0x1f, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c,
+	0x74, 0x49, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x69, 0x67, 0x6e, 0x6f,
+	0x72, 0x65, 0x73, 0x18, 0x20, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x69, 0x67, 0x6e, 0x6f, 0x72,
+	0x65, 0x73, 0x12, 0x39, 0x0a, 0x0d, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x56, 0x43, 0x53, 0x4d,
+	0x6f, 0x64, 0x65, 0x18, 0x21, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x63, 0x6f, 0x72, 0x65,
+	0x2e, 0x49, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x56, 0x43, 0x53, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x0d,
+	0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x56, 0x43, 0x53, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x1e, 0x0a,
+	0x0a, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x53, 0x65, 0x74, 0x73, 0x18, 0x22, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0a, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x53, 0x65, 0x74, 0x73, 0x12, 0x2a, 0x0a,
+	0x10, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x47, 0x69, 0x74, 0x49, 0x67, 0x6e, 0x6f, 0x72, 0x65,
+	0x64, 0x18, 0x23, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x47,
+	0x69, 0x74, 0x49, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x64, 0x12, 0x3f, 0x0a, 0x0f, 0x63, 0x6f, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x24, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x15, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e,
+	0x74, 0x54, 0x79, 0x70, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x0f, 0x63, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x69, 0x67,
+	0x6e, 0x6f, 0x72, 0x65, 0x4f, 0x70, 0x65, 0x6e, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x25, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0f, 0x69, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x4f, 0x70, 0x65, 0x6e, 0x46,
+	0x69, 0x6c, 0x65, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x46,
+	0x69, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x3f, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x64,
+	0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x32,
+	0x0a, 0x14, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x40, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x64, 0x65,
 	0x66, 0x61, 0x75, 0x6c, 0x74, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x4d, 0x6f,
-	0x64, 0x65, 0x18, 0x40, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x14, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c,
-	0x74, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x22,
-	0x0a, 0x0c, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x41,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x4f, 0x77, 0x6e,
-	0x65, 0x72, 0x12, 0x22, 0x0a, 0x0c, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x47, 0x72, 0x6f,
-	0x75, 0x70, 0x18, 0x42, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c,
-	0x74, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
-	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e, 0x2d, 0x69, 0x6f, 0x2f,
-	0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x79, 0x6e, 0x63,
-	0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x64, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x4f, 0x77, 0x6e,
+	0x65, 0x72, 0x18, 0x41, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c,
+	0x74, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x22, 0x0a, 0x0c, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c,
+	0x74, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x42, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x65,
+	0x66, 0x61, 0x75, 0x6c, 0x74, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x27, 0x0a, 0x07, 0x61, 0x63,
+	0x6c, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x43, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x63, 0x6f,
+	0x72, 0x65, 0x2e, 0x41, 0x43, 0x4c, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x07, 0x61, 0x63, 0x6c, 0x4d,
+	0x6f, 0x64, 0x65, 0x12, 0x59, 0x0a, 0x14, 0x68, 0x6f, 0x73, 0x74, 0x56, 0x65, 0x72, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x51, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x25, 0x2e, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x14, 0x68, 0x6f, 0x73, 0x74, 0x56, 0x65,
+	0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x2c,
+	0x0a, 0x0a, 0x73, 0x73, 0x68, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x52, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x73, 0x73, 0x68, 0x2e, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x52, 0x0a, 0x73, 0x73, 0x68, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x3c, 0x0a, 0x0e,
+	0x64, 0x75, 0x72, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x5b,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x44, 0x75, 0x72, 0x61,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x0e, 0x64, 0x75, 0x72, 0x61,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x65, 0x0a, 0x18, 0x6d, 0x6f,
+	0x64, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x69,
+	0x6e, 0x67, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x65, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x29, 0x2e, 0x73,
+	0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x4d,
+	0x6f, 0x64, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x61, 0x6e, 0x64, 0x6c,
+	0x69, 0x6e, 0x67, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x18, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x69, 0x6e, 0x67, 0x4d, 0x6f, 0x64,
+	0x65, 0x12, 0x34, 0x0a, 0x15, 0x63, 0x6c, 0x6f, 0x6e, 0x65, 0x53, 0x74, 0x61, 0x67, 0x69, 0x6e,
+	0x67, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x66, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x15, 0x63, 0x6c, 0x6f, 0x6e, 0x65, 0x53, 0x74, 0x61, 0x67, 0x69, 0x6e, 0x67, 0x54, 0x68,
+	0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x22, 0x0a, 0x0c, 0x73, 0x74, 0x61, 0x6c, 0x6c,
+	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x6f, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x73,
+	0x74, 0x61, 0x6c, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x61,
+	0x62, 0x6f, 0x72, 0x74, 0x4f, 0x6e, 0x53, 0x74, 0x61, 0x6c, 0x6c, 0x18, 0x70, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0c, 0x61, 0x62, 0x6f, 0x72, 0x74, 0x4f, 0x6e, 0x53, 0x74, 0x61, 0x6c, 0x6c, 0x12,
+	0x32, 0x0a, 0x14, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x54, 0x68,
+	0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x79, 0x20, 0x01, 0x28, 0x04, 0x52, 0x14, 0x63,
+	0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68,
+	0x6f, 0x6c, 0x64, 0x12, 0x3a, 0x0a, 0x18, 0x69, 0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73,
+	0x73, 0x69, 0x62, 0x6c, 0x65, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x7a, 0x20, 0x03, 0x28, 0x09, 0x52, 0x18, 0x69, 0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73,
+	0x73, 0x69, 0x62, 0x6c, 0x65, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12,
+	0x27, 0x0a, 0x0e, 0x70, 0x72, 0x6f, 0x74, 0x65, 0x63, 0x74, 0x65, 0x64, 0x50, 0x61, 0x74, 0x68,
+	0x73, 0x18, 0x83, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x70, 0x72, 0x6f, 0x74, 0x65, 0x63,
+	0x74, 0x65, 0x64, 0x50, 0x61, 0x74, 0x68, 0x73, 0x12, 0x25, 0x0a, 0x0d, 0x76, 0x65, 0x72, 0x69,
+	0x66, 0x69, 0x65, 0x64, 0x50, 0x61, 0x74, 0x68, 0x73, 0x18, 0x84, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0d, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x50, 0x61, 0x74, 0x68, 0x73, 0x12,
+	0x29, 0x0a, 0x0f, 0x73, 0x63, 0x61, 0x6e, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x63, 0x79, 0x18, 0x8d, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x73, 0x63, 0x61, 0x6e, 0x43,
+	0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x2f, 0x0a, 0x12, 0x73, 0x74,
+	0x61, 0x67, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79,
+	0x18, 0x8e, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x12, 0x73, 0x74, 0x61, 0x67, 0x69, 0x6e, 0x67,
+	0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x1f, 0x0a, 0x0a, 0x73,
+	0x63, 0x61, 0x6e, 0x57, 0x61, 0x72, 0x6d, 0x75, 0x70, 0x18, 0x8f, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0a, 0x73, 0x63, 0x61, 0x6e, 0x57, 0x61, 0x72, 0x6d, 0x75, 0x70, 0x12, 0x29, 0x0a, 0x0f,
+	0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x73, 0x18,
+	0x97, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0f, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65,
+	0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x73, 0x12, 0x2b, 0x0a, 0x10, 0x73, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x98, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x10, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x54, 0x69, 0x6d, 0x65,
+	0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x2f, 0x0a, 0x12, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x43, 0x61,
+	0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18, 0xa1, 0x01, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x12, 0x73, 0x74, 0x72, 0x69, 0x63, 0x74, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c,
+	0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x35, 0x0a, 0x15, 0x70, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x4d, 0x61, 0x63, 0x4f, 0x53, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0xab,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x15, 0x70, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x4d,
+	0x61, 0x63, 0x4f, 0x53, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x2d, 0x0a, 0x11,
+	0x70, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x46, 0x6c, 0x61, 0x67,
+	0x73, 0x18, 0xac, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x70, 0x72, 0x65, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x2f, 0x0a, 0x12, 0x6c,
+	0x69, 0x6e, 0x65, 0x45, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e,
+	0x73, 0x18, 0xb5, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x12, 0x6c, 0x69, 0x6e, 0x65, 0x45, 0x6e,
+	0x64, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x73, 0x12, 0x40, 0x0a, 0x0f,
+	0x6c, 0x69, 0x6e, 0x65, 0x45, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x79, 0x6c, 0x65, 0x18,
+	0xb6, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x4c, 0x69,
+	0x6e, 0x65, 0x45, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x79, 0x6c, 0x65, 0x52, 0x0f, 0x6c,
+	0x69, 0x6e, 0x65, 0x45, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x79, 0x6c, 0x65, 0x12, 0x37,
+	0x0a, 0x16, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x50, 0x61, 0x75, 0x73, 0x65, 0x54,
+	0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0xbf, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x16, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x50, 0x61, 0x75, 0x73, 0x65, 0x54, 0x68,
+	0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x2b, 0x0a, 0x10, 0x63, 0x6f, 0x6e, 0x66, 0x6c,
+	0x69, 0x63, 0x74, 0x53, 0x69, 0x64, 0x65, 0x63, 0x61, 0x72, 0x73, 0x18, 0xc0, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x10, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x53, 0x69, 0x64, 0x65,
+	0x63, 0x61, 0x72, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x64, 0x65, 0x66, 0x65, 0x72, 0x53, 0x79, 0x6d,
+	0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x64, 0x65, 0x66,
+	0x65, 0x72, 0x53, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x45, 0x0a, 0x11, 0x62, 0x72,
+	0x6f, 0x6b, 0x65, 0x6e, 0x53, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x4d, 0x6f, 0x64, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x42, 0x72, 0x6f,
+	0x6b, 0x65, 0x6e, 0x53, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x11,
+	0x62, 0x72, 0x6f, 0x6b, 0x65, 0x6e, 0x53, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x4d, 0x6f, 0x64,
+	0x65, 0x12, 0x2b, 0x0a, 0x10, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0xc9, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x10, 0x61, 0x67,
+	0x65, 0x6e, 0x74, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x25,
+	0x0a, 0x0d, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x43, 0x50, 0x55, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x18,
+	0xca, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x43, 0x50, 0x55,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x27, 0x0a, 0x0e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x43, 0x61,
+	0x63, 0x68, 0x65, 0x48, 0x6f, 0x73, 0x74, 0x18, 0xcb, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x61, 0x67, 0x65, 0x6e, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x48, 0x6f, 0x73, 0x74, 0x12, 0x29,
+	0x0a, 0x0f, 0x75, 0x6e, 0x64, 0x6f, 0x4d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x53, 0x69, 0x7a,
+	0x65, 0x18, 0xd3, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x75, 0x6e, 0x64, 0x6f, 0x4d, 0x61,
+	0x78, 0x69, 0x6d, 0x75, 0x6d, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x27, 0x0a, 0x0e, 0x75, 0x6e, 0x64,
+	0x6f, 0x4d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x41, 0x67, 0x65, 0x18, 0xd4, 0x01, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0e, 0x75, 0x6e, 0x64, 0x6f, 0x4d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x41,
+	0x67, 0x65, 0x12, 0x40, 0x0a, 0x0f, 0x69, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x4e, 0x61, 0x6d,
+	0x65, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0xdd, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x63,
+	0x6f, 0x72, 0x65, 0x2e, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x4d,
+	0x6f, 0x64, 0x65, 0x52, 0x0f, 0x69, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x4e, 0x61, 0x6d, 0x65,
+	0x4d, 0x6f, 0x64, 0x65, 0x12, 0x37, 0x0a, 0x0c, 0x6c, 0x6f, 0x6e, 0x67, 0x50, 0x61, 0x74, 0x68,
+	0x4d, 0x6f, 0x64, 0x65, 0x18, 0xde, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x12, 0x2e, 0x63, 0x6f,
+	0x72, 0x65, 0x2e, 0x4c, 0x6f, 0x6e, 0x67, 0x50, 0x61, 0x74, 0x68, 0x4d, 0x6f, 0x64, 0x65, 0x52,
+	0x0c, 0x6c, 0x6f, 0x6e, 0x67, 0x50, 0x61, 0x74, 0x68, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x43, 0x0a,
+	0x10, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e,
+	0x67, 0x18, 0xdf, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e,
+	0x46, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67,
+	0x52, 0x10, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69,
+	0x6e, 0x67, 0x12, 0x23, 0x0a, 0x0c, 0x70, 0x61, 0x74, 0x68, 0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e,
+	0x67, 0x73, 0x18, 0xe0, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x61, 0x74, 0x68, 0x4d,
+	0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x2d, 0x0a, 0x11, 0x70, 0x61, 0x74, 0x68, 0x4d,
+	0x61, 0x70, 0x70, 0x69, 0x6e, 0x67, 0x4f, 0x6e, 0x65, 0x57, 0x61, 0x79, 0x18, 0xe1, 0x01, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x11, 0x70, 0x61, 0x74, 0x68, 0x4d, 0x61, 0x70, 0x70, 0x69, 0x6e, 0x67,
+	0x4f, 0x6e, 0x65, 0x57, 0x61, 0x79, 0x12, 0x4e, 0x0a, 0x10, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0xe7, 0x01, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x21, 0x2e, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x50, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x52, 0x10, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x50, 0x72,
+	0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x51, 0x0a, 0x11, 0x64, 0x65, 0x6c, 0x74, 0x61, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0xe8, 0x01, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x22, 0x2e, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x11, 0x64, 0x65, 0x6c, 0x74, 0x61, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x65, 0x72, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x31, 0x0a, 0x13, 0x63, 0x79, 0x63,
+	0x6c, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x42, 0x75, 0x64, 0x67, 0x65, 0x74,
+	0x18, 0xe9, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x13, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x42, 0x75, 0x64, 0x67, 0x65, 0x74, 0x12, 0x31, 0x0a, 0x13,
+	0x63, 0x68, 0x65, 0x63, 0x6b, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x53, 0x70,
+	0x61, 0x63, 0x65, 0x18, 0xea, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x63, 0x68, 0x65, 0x63,
+	0x6b, 0x41, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x53, 0x70, 0x61, 0x63, 0x65, 0x12,
+	0x2d, 0x0a, 0x11, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x4d, 0x65, 0x72, 0x6b, 0x6c, 0x65,
+	0x52, 0x6f, 0x6f, 0x74, 0x18, 0xf1, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x63, 0x6f, 0x6d,
+	0x70, 0x75, 0x74, 0x65, 0x4d, 0x65, 0x72, 0x6b, 0x6c, 0x65, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x60,
+	0x0a, 0x16, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0xfb, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x27, 0x2e, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x2e, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x16, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76,
+	0x65, 0x43, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x6f, 0x64, 0x65,
+	0x12, 0x37, 0x0a, 0x16, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x61, 0x75, 0x73,
+	0x65, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x85, 0x02, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x16, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x61, 0x75, 0x73, 0x65,
+	0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x39, 0x0a, 0x17, 0x64, 0x65, 0x6c,
+	0x65, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x61, 0x75, 0x73, 0x65, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e,
+	0x74, 0x61, 0x67, 0x65, 0x18, 0x86, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x17, 0x64, 0x65, 0x6c,
+	0x65, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x61, 0x75, 0x73, 0x65, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e,
+	0x74, 0x61, 0x67, 0x65, 0x12, 0x29, 0x0a, 0x0f, 0x62, 0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x75, 0x70, 0x18, 0x8f, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f,
+	0x62, 0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x53, 0x74, 0x61, 0x72, 0x74, 0x75, 0x70, 0x12,
+	0x31, 0x0a, 0x13, 0x71, 0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x54, 0x68, 0x72,
+	0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x99, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x13, 0x71,
+	0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f,
+	0x6c, 0x64, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e, 0x2d, 0x69, 0x6f, 0x2f, 0x6d, 0x75, 0x74, 0x61,
+	0x67, 0x65, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e,
+	0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -330,23 +1250,53 @@ var file_synchronization_configuration_proto_goTypes = []interface{}{
 	(behavior.ProbeMode)(0),       // 2: behavior.ProbeMode
 	(ScanMode)(0),                 // 3: synchronization.ScanMode
 	(StageMode)(0),                // 4: synchronization.StageMode
-	(core.SymlinkMode)(0),         // 5: core.SymlinkMode
-	(WatchMode)(0),                // 6: synchronization.WatchMode
-	(core.IgnoreVCSMode)(0),       // 7: core.IgnoreVCSMode
+	(ContentStoreMode)(0),         // 5: synchronization.ContentStoreMode
+	(core.SymlinkMode)(0),         // 6: core.SymlinkMode
+	(WatchMode)(0),                // 7: synchronization.WatchMode
+	(core.IgnoreVCSMode)(0),       // 8: core.IgnoreVCSMode
+	(core.ContentTypeMode)(0),     // 9: core.ContentTypeMode
+	(core.ACLMode)(0),             // 10: core.ACLMode
+	(HostVerificationMode)(0),     // 11: synchronization.HostVerificationMode
+	(*ssh.Options)(nil),           // 12: ssh.Options
+	(core.DurabilityMode)(0),      // 13: core.DurabilityMode
+	(ModificationHandlingMode)(0), // 14: synchronization.ModificationHandlingMode
+	(core.LineEndingStyle)(0),     // 15: core.LineEndingStyle
+	(core.BrokenSymlinkMode)(0),   // 16: core.BrokenSymlinkMode
+	(core.InvalidNameMode)(0),     // 17: core.InvalidNameMode
+	(core.LongPathMode)(0),        // 18: core.LongPathMode
+	(core.FilenameEncoding)(0),    // 19: core.FilenameEncoding
+	(TransferPriority)(0),         // 20: synchronization.TransferPriority
+	(DeltaTransferMode)(0),        // 21: synchronization.DeltaTransferMode
+	(ArchiveCompressionMode)(0),   // 22: synchronization.ArchiveCompressionMode
 }
 var file_synchronization_configuration_proto_depIdxs = []int32{
-	1, // 0: synchronization.Configuration.synchronizationMode:type_name -> core.SynchronizationMode
-	2, // 1: synchronization.Configuration.probeMode:type_name -> behavior.ProbeMode
-	3, // 2: synchronization.Configuration.scanMode:type_name -> synchronization.ScanMode
-	4, // 3: synchronization.Configuration.stageMode:type_name -> synchronization.StageMode
-	5, // 4: synchronization.Configuration.symlinkMode:type_name -> core.SymlinkMode
-	6, // 5: synchronization.Configuration.watchMode:type_name -> synchronization.WatchMode
-	7, // 6: synchronization.Configuration.ignoreVCSMode:type_name -> core.IgnoreVCSMode
-	7, // [7:7] is the sub-list for method output_type
-	7, // [7:7] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	1,  // 0: synchronization.Configuration.synchronizationMode:type_name -> core.SynchronizationMode
+	2,  // 1: synchronization.Configuration.probeMode:type_name -> behavior.ProbeMode
+	3,  // 2: synchronization.Configuration.scanMode:type_name -> synchronization.ScanMode
+	4,  // 3: synchronization.Configuration.stageMode:type_name -> synchronization.StageMode
+	5,  // 4: synchronization.Configuration.contentStoreMode:type_name -> synchronization.ContentStoreMode
+	6,  // 5: synchronization.Configuration.symlinkMode:type_name -> core.SymlinkMode
+	7,  // 6: synchronization.Configuration.watchMode:type_name -> synchronization.WatchMode
+	8,  // 7: synchronization.Configuration.ignoreVCSMode:type_name -> core.IgnoreVCSMode
+	9,  // 8: synchronization.Configuration.contentTypeMode:type_name -> core.ContentTypeMode
+	10, // 9: synchronization.Configuration.aclMode:type_name -> core.ACLMode
+	11, // 10: synchronization.Configuration.hostVerificationMode:type_name -> synchronization.HostVerificationMode
+	12, // 11: synchronization.Configuration.sshOptions:type_name -> ssh.Options
+	13, // 12: synchronization.Configuration.durabilityMode:type_name -> core.DurabilityMode
+	14, // 13: synchronization.Configuration.modificationHandlingMode:type_name -> synchronization.ModificationHandlingMode
+	15, // 14: synchronization.Configuration.lineEndingStyle:type_name -> core.LineEndingStyle
+	16, // 15: synchronization.Configuration.brokenSymlinkMode:type_name -> core.BrokenSymlinkMode
+	17, // 16: synchronization.Configuration.invalidNameMode:type_name -> core.InvalidNameMode
+	18, // 17: synchronization.Configuration.longPathMode:type_name -> core.LongPathMode
+	19, // 18: synchronization.Configuration.filenameEncoding:type_name -> core.FilenameEncoding
+	20, // 19: synchronization.Configuration.transferPriority:type_name -> synchronization.TransferPriority
+	21, // 20: synchronization.Configuration.deltaTransferMode:type_name -> synchronization.DeltaTransferMode
+	22, // 21: synchronization.Configuration.archiveCompressionMode:type_name -> synchronization.ArchiveCompressionMode
+	22, // [22:22] is the sub-list for method output_type
+	22, // [22:22] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
 }
 
 func init() { file_synchronization_configuration_proto_init() }
@@ -354,8 +1304,14 @@ func file_synchronization_configuration_proto_init() {
 	if File_synchronization_configuration_proto != nil {
 		return
 	}
+	file_synchronization_archive_compression_mode_proto_init()
+	file_synchronization_content_store_mode_proto_init()
+	file_synchronization_delta_transfer_mode_proto_init()
+	file_synchronization_host_verification_mode_proto_init()
+	file_synchronization_modification_handling_mode_proto_init()
 	file_synchronization_scan_mode_proto_init()
 	file_synchronization_stage_mode_proto_init()
+	file_synchronization_transfer_priority_proto_init()
 	file_synchronization_watch_mode_proto_init()
 	if !protoimpl.UnsafeEnabled {
 		file_synchronization_configuration_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
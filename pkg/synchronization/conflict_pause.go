@@ -0,0 +1,71 @@
+package synchronization
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+)
+
+// pausingError is implemented by errors that abort a synchronization cycle and
+// require that the session be paused with a recorded reason.
+type pausingError interface {
+	error
+	// pausedReason returns the paused reason to record for the session.
+	pausedReason() string
+}
+
+// conflictThresholdError indicates that a synchronization cycle was aborted
+// because it encountered more conflicts than the session's conflict pause
+// threshold allows.
+type conflictThresholdError struct {
+	// conflicts is the number of conflicts encountered.
+	conflicts int
+	// threshold is the conflict pause threshold.
+	threshold uint64
+}
+
+// Error implements error.Error.
+func (e *conflictThresholdError) Error() string {
+	return fmt.Sprintf("conflict count (%d) exceeds conflict pause threshold (%d)", e.conflicts, e.threshold)
+}
+
+// pausedReason implements pausingError.pausedReason.
+func (e *conflictThresholdError) pausedReason() string {
+	return fmt.Sprintf("too many conflicts (%d exceeds threshold of %d)", e.conflicts, e.threshold)
+}
+
+// pauseForViolation pauses the session in response to a violation (such as a
+// conflict threshold violation) reported by the synchronization loop whose
+// completion is signaled by done, recording the violation as the session's
+// paused reason. It must be
+// invoked asynchronously by the synchronization loop, since halting waits for
+// the loop to exit. If the loop has already been halted by the time the
+// lifecycle lock is acquired, then no action is taken.
+func (c *controller) pauseForViolation(done chan struct{}, violation pausingError) {
+	// Lock the controller's lifecycle and defer its release.
+	c.lifecycleLock.Lock()
+	defer c.lifecycleLock.Unlock()
+
+	// If the controller is disabled or the synchronization loop has already
+	// been replaced, then there's nothing to pause.
+	if c.disabled || c.done != done {
+		return
+	}
+
+	// Pause the session.
+	c.logger.Warning("Pausing session:", violation)
+	if err := c.halt(context.Background(), controllerHaltModePause, "", true); err != nil {
+		c.logger.Warning("Unable to pause session:", err)
+		return
+	}
+
+	// Record the paused reason.
+	c.stateLock.Lock()
+	c.session.PausedReason = violation.pausedReason()
+	saveErr := encoding.MarshalAndSaveProtobuf(c.sessionPath, c.session)
+	c.stateLock.Unlock()
+	if saveErr != nil {
+		c.logger.Warning("Unable to save session:", saveErr)
+	}
+}
@@ -0,0 +1,166 @@
+package synchronization
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/state"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// testConflictController creates a running controller whose endpoints contain
+// the specified number of conflicting files and whose session uses the
+// specified conflict pause threshold. It returns the controller and the
+// temporary directory containing all test content (which the caller should
+// remove).
+func testConflictController(t *testing.T, conflicts int, threshold uint64) (*controller, string) {
+	// Create a temporary directory to hold all test content.
+	parent, err := ioutil.TempDir("", "mutagen_conflict_pause")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+
+	// Create endpoint directories and conflicting content.
+	alphaRoot := filepath.Join(parent, "alpha")
+	betaRoot := filepath.Join(parent, "beta")
+	staging := filepath.Join(parent, "staging")
+	for _, directory := range []string{alphaRoot, betaRoot, staging} {
+		if err := os.Mkdir(directory, 0700); err != nil {
+			os.RemoveAll(parent)
+			t.Fatal("unable to create directory:", err)
+		}
+	}
+	for i := 0; i < conflicts; i++ {
+		name := fmt.Sprintf("file%d", i)
+		if err := ioutil.WriteFile(filepath.Join(alphaRoot, name), []byte("alpha"), 0600); err != nil {
+			os.RemoveAll(parent)
+			t.Fatal("unable to create alpha content:", err)
+		} else if err = ioutil.WriteFile(filepath.Join(betaRoot, name), []byte("beta"), 0600); err != nil {
+			os.RemoveAll(parent)
+			t.Fatal("unable to create beta content:", err)
+		}
+	}
+
+	// Create an empty archive.
+	archivePath := filepath.Join(parent, "archive")
+	if err := encoding.MarshalAndSaveProtobuf(archivePath, &core.Archive{}); err != nil {
+		os.RemoveAll(parent)
+		t.Fatal("unable to save archive:", err)
+	}
+
+	// Create the controller.
+	session := &Session{
+		Identifier: "session",
+		Version:    Version_Version1,
+		Configuration: &Configuration{
+			ConflictPauseThreshold: threshold,
+		},
+		ConfigurationAlpha: &Configuration{},
+		ConfigurationBeta:  &Configuration{},
+	}
+	c := &controller{
+		logger:                   logging.RootLogger.Sublogger("test"),
+		sessionPath:              filepath.Join(parent, "session"),
+		archivePath:              archivePath,
+		stateLock:                state.NewTrackingLock(state.NewTracker()),
+		session:                  session,
+		mergedAlphaConfiguration: &Configuration{},
+		mergedBetaConfiguration:  &Configuration{},
+		state: &State{
+			Session: session,
+		},
+	}
+
+	// Start the synchronization loop.
+	alpha := &testDirectoryEndpoint{root: alphaRoot, source: betaRoot, staging: staging}
+	beta := &testDirectoryEndpoint{root: betaRoot, source: alphaRoot, staging: staging}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopCtx, stop := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.stop = stop
+	c.flushRequests = make(chan *controllerFlushRequest, 1)
+	c.done = make(chan struct{})
+	go c.run(ctx, stopCtx, alpha, beta, nil)
+
+	// Done.
+	return c, parent
+}
+
+// TestControllerConflictPauseThresholdExceeded tests that a session is paused
+// automatically (with a recorded reason) when a synchronization cycle
+// encounters more conflicts than its conflict pause threshold.
+func TestControllerConflictPauseThresholdExceeded(t *testing.T) {
+	// Create a controller with more conflicts than its threshold allows.
+	c, parent := testConflictController(t, 3, 2)
+	defer os.RemoveAll(parent)
+
+	// Wait for the session to be paused.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		c.stateLock.Lock()
+		paused := c.session.Paused
+		pausedReason := c.session.PausedReason
+		c.stateLock.UnlockWithoutNotify()
+		if paused && pausedReason != "" {
+			if !strings.Contains(pausedReason, "conflicts") {
+				t.Error("unexpected paused reason:", pausedReason)
+			}
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("session not paused")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Verify that the synchronization loop was halted.
+	c.lifecycleLock.Lock()
+	running := c.cancel != nil
+	c.lifecycleLock.Unlock()
+	if running {
+		t.Error("synchronization loop still running after pause")
+	}
+
+	// Verify that the paused reason was persisted.
+	saved := &Session{}
+	if err := encoding.LoadAndUnmarshalProtobuf(c.sessionPath, saved); err != nil {
+		t.Fatal("unable to load saved session:", err)
+	} else if !saved.Paused || saved.PausedReason == "" {
+		t.Error("paused state not persisted")
+	}
+}
+
+// TestControllerConflictPauseThresholdNotExceeded tests that a session isn't
+// paused when a synchronization cycle encounters a number of conflicts that
+// doesn't exceed its conflict pause threshold.
+func TestControllerConflictPauseThresholdNotExceeded(t *testing.T) {
+	// Create a controller with exactly as many conflicts as its threshold
+	// allows and wait for it to complete its initial cycle.
+	c, parent := testConflictController(t, 2, 2)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Verify that the conflicts were reported and that the session wasn't
+	// paused.
+	if conflicts := len(c.currentState().Conflicts); conflicts != 2 {
+		t.Error("unexpected conflict count:", conflicts)
+	}
+	c.stateLock.Lock()
+	paused := c.session.Paused
+	c.stateLock.UnlockWithoutNotify()
+	if paused {
+		t.Error("session paused below conflict threshold")
+	}
+
+	// Halt the session.
+	if err := c.halt(context.Background(), controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+}
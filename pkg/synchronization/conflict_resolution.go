@@ -0,0 +1,87 @@
+package synchronization
+
+import (
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// eligibleConflictChanges determines whether or not a conflict is eligible for
+// resolution by an endpoint, either using an external conflict resolver or by
+// using a conflict sidecar. Only conflicts consisting of a single modification
+// on each endpoint, where both endpoints have a file at the conflicting path,
+// are eligible. If the conflict is eligible, then its alpha and beta changes
+// are returned.
+func eligibleConflictChanges(conflict *core.Conflict) (*core.Change, *core.Change, bool) {
+	// Ensure that the conflict consists of a single change on each side.
+	if len(conflict.AlphaChanges) != 1 || len(conflict.BetaChanges) != 1 {
+		return nil, nil, false
+	}
+	alphaChange := conflict.AlphaChanges[0]
+	betaChange := conflict.BetaChanges[0]
+
+	// Ensure that both changes are at the same path and result in files.
+	if alphaChange.Path != betaChange.Path {
+		return nil, nil, false
+	} else if alphaChange.New == nil || alphaChange.New.Kind != core.EntryKind_File {
+		return nil, nil, false
+	} else if betaChange.New == nil || betaChange.New.Kind != core.EntryKind_File {
+		return nil, nil, false
+	}
+
+	// Success.
+	return alphaChange, betaChange, true
+}
+
+// conflictResolutionTransitions computes conflict resolution transitions to
+// be performed on beta for the specified conflicts. Only conflicts deemed
+// eligible by eligibleConflictChanges are included. Each resulting transition
+// requests that beta resolve its current version of the file (the old entry)
+// against alpha's version of the file (the new entry).
+func conflictResolutionTransitions(conflicts []*core.Conflict) []*core.Change {
+	var transitions []*core.Change
+	for _, conflict := range conflicts {
+		if alphaChange, betaChange, ok := eligibleConflictChanges(conflict); ok {
+			transitions = append(transitions, &core.Change{
+				Path:    alphaChange.Path,
+				Old:     betaChange.New,
+				New:     alphaChange.New,
+				Resolve: true,
+			})
+		}
+	}
+	return transitions
+}
+
+// conflictSidecarTransitions computes conflict sidecar transitions to be
+// performed on beta for the specified conflicts. Only conflicts deemed eligible
+// by eligibleConflictChanges are included. Each resulting transition requests
+// that beta replace its current version of the file (the old entry) with
+// alpha's version of the file (the new entry), preserving its current version
+// in a conflict sidecar file. Conflicts at the synchronization root are
+// excluded, since there's no location for their sidecar files.
+func conflictSidecarTransitions(conflicts []*core.Conflict) []*core.Change {
+	var transitions []*core.Change
+	for _, conflict := range conflicts {
+		if alphaChange, betaChange, ok := eligibleConflictChanges(conflict); ok && alphaChange.Path != "" {
+			transitions = append(transitions, &core.Change{
+				Path:            alphaChange.Path,
+				Old:             betaChange.New,
+				New:             alphaChange.New,
+				ConflictSidecar: true,
+			})
+		}
+	}
+	return transitions
+}
+
+// conflictResolutionAncestorChange computes the ancestor change to apply after
+// beta has performed the specified conflict resolution (or conflict sidecar)
+// transition with the specified result. If beta resolved the conflict, then the ancestor is updated
+// to alpha's version of the file, which will cause the resolved content on beta
+// to propagate to alpha in the next synchronization cycle. If the conflict was
+// left in place, then no ancestor change is necessary and nil is returned.
+func conflictResolutionAncestorChange(transition *core.Change, result *core.Entry) *core.Change {
+	if result.Equal(transition.Old) {
+		return nil
+	}
+	return &core.Change{Path: transition.Path, New: transition.New}
+}
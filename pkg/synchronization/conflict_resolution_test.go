@@ -0,0 +1,130 @@
+package synchronization
+
+import (
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// TestConflictResolutionTransitions tests that conflictResolutionTransitions
+// only generates resolution transitions for eligible conflicts.
+func TestConflictResolutionTransitions(t *testing.T) {
+	// Create entries for use in conflicts.
+	ancestorFile := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{0}}
+	alphaFile := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{1}}
+	betaFile := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{2}}
+	directory := &core.Entry{Kind: core.EntryKind_Directory}
+
+	// Create conflicts.
+	conflicts := []*core.Conflict{
+		{
+			AlphaChanges: []*core.Change{{Path: "modified", Old: ancestorFile, New: alphaFile}},
+			BetaChanges:  []*core.Change{{Path: "modified", Old: ancestorFile, New: betaFile}},
+		},
+		{
+			AlphaChanges: []*core.Change{{Path: "deleted", Old: ancestorFile, New: alphaFile}},
+			BetaChanges:  []*core.Change{{Path: "deleted", Old: ancestorFile}},
+		},
+		{
+			AlphaChanges: []*core.Change{{Path: "directory", Old: ancestorFile, New: directory}},
+			BetaChanges:  []*core.Change{{Path: "directory", Old: ancestorFile, New: betaFile}},
+		},
+		{
+			AlphaChanges: []*core.Change{{Path: "nested", Old: directory, New: alphaFile}},
+			BetaChanges:  []*core.Change{{Path: "nested/file", New: betaFile}},
+		},
+		{
+			AlphaChanges: []*core.Change{{Path: "created", New: alphaFile}},
+			BetaChanges:  []*core.Change{{Path: "created", New: betaFile}},
+		},
+	}
+
+	// Compute resolution transitions.
+	transitions := conflictResolutionTransitions(conflicts)
+
+	// Verify the results.
+	expectedPaths := []string{"modified", "created"}
+	if len(transitions) != len(expectedPaths) {
+		t.Fatal("transition count does not match expected:", len(transitions), "!=", len(expectedPaths))
+	}
+	for i, transition := range transitions {
+		if transition.Path != expectedPaths[i] {
+			t.Error("transition path does not match expected:", transition.Path, "!=", expectedPaths[i])
+		}
+		if !transition.Resolve {
+			t.Error("transition not marked as resolution transition")
+		}
+		if !transition.Old.Equal(betaFile) {
+			t.Error("transition old entry is not beta's entry")
+		}
+		if !transition.New.Equal(alphaFile) {
+			t.Error("transition new entry is not alpha's entry")
+		}
+		if err := transition.EnsureValid(); err != nil {
+			t.Error("transition invalid:", err)
+		}
+	}
+}
+
+// TestConflictSidecarTransitions tests that conflictSidecarTransitions only
+// generates sidecar transitions for eligible conflicts.
+func TestConflictSidecarTransitions(t *testing.T) {
+	// Create entries for use in conflicts.
+	ancestorFile := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{0}}
+	alphaFile := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{1}}
+	betaFile := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{2}}
+
+	// Create conflicts.
+	conflicts := []*core.Conflict{
+		{
+			AlphaChanges: []*core.Change{{Path: "modified", Old: ancestorFile, New: alphaFile}},
+			BetaChanges:  []*core.Change{{Path: "modified", Old: ancestorFile, New: betaFile}},
+		},
+		{
+			AlphaChanges: []*core.Change{{Path: "deleted", Old: ancestorFile}},
+			BetaChanges:  []*core.Change{{Path: "deleted", Old: ancestorFile, New: betaFile}},
+		},
+	}
+
+	// Compute sidecar transitions.
+	transitions := conflictSidecarTransitions(conflicts)
+
+	// Verify the results.
+	if len(transitions) != 1 {
+		t.Fatal("transition count does not match expected:", len(transitions), "!= 1")
+	}
+	transition := transitions[0]
+	if transition.Path != "modified" {
+		t.Error("transition path does not match expected:", transition.Path)
+	}
+	if !transition.ConflictSidecar || transition.Resolve {
+		t.Error("transition not marked as sidecar transition")
+	}
+	if !transition.Old.Equal(betaFile) || !transition.New.Equal(alphaFile) {
+		t.Error("transition entries do not match expected")
+	}
+	if err := transition.EnsureValid(); err != nil {
+		t.Error("transition invalid:", err)
+	}
+}
+
+// TestConflictResolutionAncestorChange tests conflictResolutionAncestorChange.
+func TestConflictResolutionAncestorChange(t *testing.T) {
+	// Create a resolution transition.
+	alphaFile := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{1}}
+	betaFile := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{2}}
+	resolvedFile := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{3}}
+	transition := &core.Change{Path: "file", Old: betaFile, New: alphaFile, Resolve: true}
+
+	// Verify that conflicts left in place don't generate ancestor changes.
+	if change := conflictResolutionAncestorChange(transition, betaFile); change != nil {
+		t.Error("ancestor change generated for conflict left in place")
+	}
+
+	// Verify that resolved conflicts update the ancestor to alpha's entry.
+	if change := conflictResolutionAncestorChange(transition, resolvedFile); change == nil {
+		t.Error("ancestor change not generated for resolved conflict")
+	} else if change.Path != "file" || !change.New.Equal(alphaFile) || change.Old != nil {
+		t.Error("ancestor change does not match expected")
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/mutagen-io/mutagen/pkg/limiting"
 	"github.com/mutagen-io/mutagen/pkg/logging"
 	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
 )
@@ -31,6 +32,10 @@ type ProtocolHandler interface {
 // modified during init() operations.
 var ProtocolHandlers = map[urlpkg.Protocol]ProtocolHandler{}
 
+// establishmentLimiter returns the limiter regulating concurrent connection
+// establishment. It is a variable so that it can be overridden in tests.
+var establishmentLimiter = limiting.EstablishmentLimiter
+
 // connect attempts to establish a connection to an endpoint.
 func connect(
 	ctx context.Context,
@@ -50,6 +55,24 @@ func connect(
 		panic("nil protocol handler registered")
 	}
 
+	// Resolve any shared ignore sets referenced by the configuration, since
+	// endpoints only understand flattened ignore lists.
+	configuration, err := resolveIgnoreSets(configuration)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to resolve ignore sets")
+	}
+
+	// If the endpoint is remote, then wait for a connection establishment slot
+	// and defer its release. Local endpoints don't undergo any handshake, so
+	// we don't limit their establishment.
+	if url.Protocol != urlpkg.Protocol_Local {
+		release, err := establishmentLimiter().Acquire(ctx, url.Host)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to acquire connection establishment slot")
+		}
+		defer release()
+	}
+
 	// Dispatch the dialing.
 	endpoint, err := handler.Connect(ctx, logger, url, prompter, session, version, configuration, alpha)
 	if err != nil {
@@ -1,3 +1,164 @@
 package synchronization
 
-// TODO: Implement.
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/limiting"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// testEstablishmentTrackingHandler is a protocol handler that tracks the
+// concurrency of connection establishment.
+type testEstablishmentTrackingHandler struct {
+	// lock serializes access to the fields below.
+	lock sync.Mutex
+	// current is the current establishment concurrency.
+	current int
+	// maximum is the maximum observed establishment concurrency.
+	maximum int
+}
+
+// Connect implements ProtocolHandler.Connect.
+func (h *testEstablishmentTrackingHandler) Connect(
+	_ context.Context,
+	_ *logging.Logger,
+	_ *urlpkg.URL,
+	_ string,
+	_ string,
+	_ Version,
+	_ *Configuration,
+	_ bool,
+) (Endpoint, error) {
+	h.lock.Lock()
+	h.current++
+	if h.current > h.maximum {
+		h.maximum = h.current
+	}
+	h.lock.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	h.lock.Lock()
+	h.current--
+	h.lock.Unlock()
+
+	return nil, nil
+}
+
+// TestConnectEstablishmentLimit tests that concurrent connection establishment
+// for many sessions against the same host doesn't exceed the establishment
+// limit.
+func TestConnectEstablishmentLimit(t *testing.T) {
+	// Register a tracking protocol handler for SSH URLs and defer restoration
+	// of the original handler.
+	handler := &testEstablishmentTrackingHandler{}
+	originalHandler, originalHandlerRegistered := ProtocolHandlers[urlpkg.Protocol_SSH]
+	ProtocolHandlers[urlpkg.Protocol_SSH] = handler
+	defer func() {
+		if originalHandlerRegistered {
+			ProtocolHandlers[urlpkg.Protocol_SSH] = originalHandler
+		} else {
+			delete(ProtocolHandlers, urlpkg.Protocol_SSH)
+		}
+	}()
+
+	// Override the establishment limiter and defer restoration of the original.
+	const limit = 3
+	limiter := limiting.NewLimiter(0, limit)
+	originalEstablishmentLimiter := establishmentLimiter
+	establishmentLimiter = func() *limiting.Limiter {
+		return limiter
+	}
+	defer func() {
+		establishmentLimiter = originalEstablishmentLimiter
+	}()
+
+	// Connect many session endpoints to the same host concurrently.
+	const sessions = 20
+	url := &urlpkg.URL{
+		Kind:     urlpkg.Kind_Synchronization,
+		Protocol: urlpkg.Protocol_SSH,
+		Host:     "gateway.example.org",
+		Path:     "/data",
+	}
+	var group sync.WaitGroup
+	for i := 0; i < sessions; i++ {
+		group.Add(1)
+		go func(alpha bool) {
+			defer group.Done()
+			if _, err := connect(
+				context.Background(),
+				logging.RootLogger,
+				url,
+				"",
+				"session",
+				Version_Version1,
+				&Configuration{},
+				alpha,
+			); err != nil {
+				t.Error("unable to connect:", err)
+			}
+		}(i%2 == 0)
+	}
+	group.Wait()
+
+	// Verify that the limit was respected.
+	if handler.maximum > limit {
+		t.Error("establishment concurrency exceeded limit:", handler.maximum, ">", limit)
+	} else if handler.maximum == 0 {
+		t.Error("no connections established")
+	}
+}
+
+// TestConnectEstablishmentCancellation tests that connection establishment
+// waiting on the establishment limiter can be cancelled.
+func TestConnectEstablishmentCancellation(t *testing.T) {
+	// Register a tracking protocol handler for SSH URLs and defer restoration
+	// of the original handler.
+	handler := &testEstablishmentTrackingHandler{}
+	originalHandler, originalHandlerRegistered := ProtocolHandlers[urlpkg.Protocol_SSH]
+	ProtocolHandlers[urlpkg.Protocol_SSH] = handler
+	defer func() {
+		if originalHandlerRegistered {
+			ProtocolHandlers[urlpkg.Protocol_SSH] = originalHandler
+		} else {
+			delete(ProtocolHandlers, urlpkg.Protocol_SSH)
+		}
+	}()
+
+	// Override the establishment limiter with a saturated limiter and defer
+	// restoration of the original.
+	limiter := limiting.NewLimiter(1, 0)
+	release, err := limiter.Acquire(context.Background(), "gateway.example.org")
+	if err != nil {
+		t.Fatal("unable to saturate limiter:", err)
+	}
+	defer release()
+	originalEstablishmentLimiter := establishmentLimiter
+	establishmentLimiter = func() *limiting.Limiter {
+		return limiter
+	}
+	defer func() {
+		establishmentLimiter = originalEstablishmentLimiter
+	}()
+
+	// Attempt to connect with a context that will time out.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	url := &urlpkg.URL{
+		Kind:     urlpkg.Kind_Synchronization,
+		Protocol: urlpkg.Protocol_SSH,
+		Host:     "gateway.example.org",
+		Path:     "/data",
+	}
+	if _, err := connect(ctx, logging.RootLogger, url, "", "session", Version_Version1, &Configuration{}, true); err == nil {
+		t.Fatal("connection succeeded despite saturated limiter")
+	}
+	if handler.maximum != 0 {
+		t.Error("protocol handler invoked despite saturated limiter")
+	}
+}
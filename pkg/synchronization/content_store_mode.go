@@ -0,0 +1,58 @@
+package synchronization
+
+import (
+	"github.com/pkg/errors"
+)
+
+// IsDefault indicates whether or not the content store mode is
+// ContentStoreMode_ContentStoreModeDefault.
+func (m ContentStoreMode) IsDefault() bool {
+	return m == ContentStoreMode_ContentStoreModeDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (m *ContentStoreMode) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to a content store mode.
+	switch text {
+	case "disabled":
+		*m = ContentStoreMode_ContentStoreModeDisabled
+	case "shared":
+		*m = ContentStoreMode_ContentStoreModeShared
+	default:
+		return errors.Errorf("unknown content store mode specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular content store mode is a
+// valid, non-default value.
+func (m ContentStoreMode) Supported() bool {
+	switch m {
+	case ContentStoreMode_ContentStoreModeDisabled:
+		return true
+	case ContentStoreMode_ContentStoreModeShared:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of a content store mode.
+func (m ContentStoreMode) Description() string {
+	switch m {
+	case ContentStoreMode_ContentStoreModeDefault:
+		return "Default"
+	case ContentStoreMode_ContentStoreModeDisabled:
+		return "Disabled"
+	case ContentStoreMode_ContentStoreModeShared:
+		return "Shared"
+	default:
+		return "Unknown"
+	}
+}
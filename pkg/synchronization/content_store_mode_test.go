@@ -0,0 +1,91 @@
+package synchronization
+
+import (
+	"testing"
+)
+
+// TestContentStoreModeUnmarshal tests that unmarshaling from a string
+// specification succeeeds for ContentStoreMode.
+func TestContentStoreModeUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text          string
+		expectedMode  ContentStoreMode
+		expectFailure bool
+	}{
+		{"", ContentStoreMode_ContentStoreModeDefault, true},
+		{"asdf", ContentStoreMode_ContentStoreModeDefault, true},
+		{"disabled", ContentStoreMode_ContentStoreModeDisabled, false},
+		{"shared", ContentStoreMode_ContentStoreModeShared, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var mode ContentStoreMode
+		if err := mode.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if mode != testCase.expectedMode {
+			t.Errorf(
+				"unmarshaled mode (%s) does not match expected (%s)",
+				mode,
+				testCase.expectedMode,
+			)
+		}
+	}
+}
+
+// TestContentStoreModeSupported tests that ContentStoreMode support detection
+// works as expected.
+func TestContentStoreModeSupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode            ContentStoreMode
+		expectSupported bool
+	}{
+		{ContentStoreMode_ContentStoreModeDefault, false},
+		{ContentStoreMode_ContentStoreModeDisabled, true},
+		{ContentStoreMode_ContentStoreModeShared, true},
+		{(ContentStoreMode_ContentStoreModeShared + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.mode.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"mode support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestContentStoreModeDescription tests that ContentStoreMode description
+// generation works as expected.
+func TestContentStoreModeDescription(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                ContentStoreMode
+		expectedDescription string
+	}{
+		{ContentStoreMode_ContentStoreModeDefault, "Default"},
+		{ContentStoreMode_ContentStoreModeDisabled, "Disabled"},
+		{ContentStoreMode_ContentStoreModeShared, "Shared"},
+		{(ContentStoreMode_ContentStoreModeShared + 1), "Unknown"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if description := testCase.mode.Description(); description != testCase.expectedDescription {
+			t.Errorf(
+				"mode description (%s) does not match expected (%s)",
+				description,
+				testCase.expectedDescription,
+			)
+		}
+	}
+}
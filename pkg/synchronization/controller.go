@@ -3,7 +3,11 @@ package synchronization
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
+	"runtime/pprof"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -30,6 +34,26 @@ const (
 	rescanWaitDuration = 5 * time.Second
 )
 
+// controllerFlushRequest represents a request to force a synchronization
+// cycle.
+type controllerFlushRequest struct {
+	// ignoreOverrides are temporary include patterns that take precedence over
+	// the session's ignore patterns until the forced synchronization cycle has
+	// completed. They're never persisted.
+	ignoreOverrides []string
+	// rehash indicates whether or not endpoints should discard their digest
+	// caches and recompute the digests of all files during the forced
+	// synchronization cycle.
+	rehash bool
+	// undo indicates that, rather than performing a standard synchronization
+	// cycle, the changes applied by the last synchronization cycle should be
+	// undone.
+	undo bool
+	// response is used to report the result of the forced synchronization
+	// cycle. It must be buffered with room for one error.
+	response chan error
+}
+
 // controller manages and executes a single session.
 type controller struct {
 	// logger is the controller logger.
@@ -58,11 +82,18 @@ type controller struct {
 	mergedBetaConfiguration *Configuration
 	// state represents the current synchronization state.
 	state *State
-	// lifecycleLock guards setting of the disabled, cancel, flushRequests, and
-	// done members. Access to these members is allowed for the synchronization
-	// loop without holding the lock. Any code wishing to set these members
-	// should first acquire the lock, then cancel the synchronization loop, and
-	// wait for it to complete before making any such changes.
+	// activity tracks the history of recent synchronization cycles for
+	// explaining session activity. It is guarded by stateLock.
+	activity activityTracker
+	// quarantine tracks repeatedly failing transition paths and the paths
+	// that have been quarantined as a result. It is guarded by stateLock.
+	quarantine quarantineTracker
+	// lifecycleLock guards setting of the disabled, cancel, stop,
+	// flushRequests, reconnectRequests, and done members. Access to these
+	// members is allowed for the synchronization loop without holding the
+	// lock. Any code wishing to set these members should first acquire the
+	// lock, then cancel the synchronization loop, and wait for it to complete
+	// before making any such changes.
 	lifecycleLock sync.Mutex
 	// disabled indicates that no more changes to the synchronization loop
 	// lifecycle are allowed (i.e. no more synchronization loops can be started
@@ -73,12 +104,34 @@ type controller struct {
 	// cancel cancels the synchronization loop execution context. It should be
 	// nil if and only if there is no synchronization loop running.
 	cancel context.CancelFunc
+	// stop requests that the synchronization loop stop gracefully, i.e. that it
+	// not start any new synchronization cycles and that it exit once any
+	// in-flight synchronization cycle has completed. It should be nil if and
+	// only if there is no synchronization loop running.
+	stop context.CancelFunc
 	// flushRequests is used pass flush requests to the synchronization loop. It
 	// is buffered, allowing a single request to be queued. All requests passed
-	// via this channel must be buffered and contain room for one error.
-	flushRequests chan chan error
+	// via this channel must have a response channel that is buffered and
+	// contains room for one error.
+	flushRequests chan *controllerFlushRequest
+	// reconnectRequests is used to pass reconnect requests to the
+	// synchronization loop. It is unbuffered, so requests can only be sent
+	// while the synchronization loop is waiting to reconnect. Each request is
+	// a channel that the synchronization loop closes once it has completed
+	// the requested connection attempt.
+	reconnectRequests chan chan struct{}
 	// done will be closed by the current synchronization loop when it exits.
 	done chan struct{}
+	// scheduleCancel cancels the schedule monitor, if any. It is set before
+	// the schedule monitor is started and is static thereafter.
+	scheduleCancel context.CancelFunc
+	// deletionsConfirmed indicates that the session was resumed after being
+	// paused due to excessive deletions, and thus that the synchronization loop
+	// should skip deletion checks until a synchronization cycle completes
+	// successfully. It may only be set while no synchronization loop is
+	// running (with the lifecycle lock held), after which it's owned by the
+	// synchronization loop.
+	deletionsConfirmed bool
 }
 
 // newSession creates a new session and corresponding controller.
@@ -88,6 +141,7 @@ func newSession(
 	tracker *state.Tracker,
 	identifier string,
 	alpha, beta *url.URL,
+	additionalBetas []*url.URL,
 	configuration, configurationAlpha, configurationBeta *Configuration,
 	name string,
 	labels map[string]string,
@@ -111,6 +165,13 @@ func newSession(
 	mergedAlphaConfiguration := MergeConfigurations(configuration, configurationAlpha)
 	mergedBetaConfiguration := MergeConfigurations(configuration, configurationBeta)
 
+	// Verify that any referenced ignore sets are defined. We perform this check
+	// here (rather than relying on endpoint connection) so that the check is
+	// also performed for sessions that are created pre-paused.
+	if _, err := loadIgnoreSets(configuration.IgnoreSets); err != nil {
+		return nil, errors.Wrap(err, "invalid ignore set configuration")
+	}
+
 	// If the session isn't being created paused, then try to connect to any
 	// endpoints not using the tunnel protocol. The tunnel protocol is the one
 	// case where we want to allow asynchronous connectivity (since it doesn't
@@ -128,8 +189,10 @@ func newSession(
 			betaEndpoint = nil
 		}
 	}()
+	var timings *Timings
 	if !paused && alpha.Protocol != url.Protocol_Tunnel {
 		logger.Info("Connecting to alpha endpoint")
+		connectStart := time.Now()
 		alphaEndpoint, err = connect(
 			ctx,
 			logger.Sublogger("alpha"),
@@ -144,9 +207,11 @@ func newSession(
 			logger.Info("Alpha connection failure:", err)
 			return nil, errors.Wrap(err, "unable to connect to alpha")
 		}
+		timings = timings.withConnectDuration(time.Since(connectStart))
 	}
 	if !paused && beta.Protocol != url.Protocol_Tunnel {
 		logger.Info("Connecting to beta endpoint")
+		connectStart := time.Now()
 		betaEndpoint, err = connect(
 			ctx,
 			logger.Sublogger("beta"),
@@ -161,6 +226,7 @@ func newSession(
 			logger.Info("Beta connection failure:", err)
 			return nil, errors.Wrap(err, "unable to connect to beta")
 		}
+		timings = timings.withConnectDuration(time.Since(connectStart))
 	}
 
 	// Create the session and initial archive.
@@ -173,12 +239,14 @@ func newSession(
 		CreatingVersionPatch: mutagen.VersionPatch,
 		Alpha:                alpha,
 		Beta:                 beta,
+		AdditionalBetas:      additionalBetas,
 		Configuration:        configuration,
 		ConfigurationAlpha:   configurationAlpha,
 		ConfigurationBeta:    configurationBeta,
 		Name:                 name,
 		Labels:               labels,
 		Paused:               paused,
+		BaselinePending:      configuration.BaselineStartup,
 	}
 	archive := &core.Archive{}
 
@@ -196,7 +264,11 @@ func newSession(
 	if err := encoding.MarshalAndSaveProtobuf(sessionPath, session); err != nil {
 		return nil, errors.Wrap(err, "unable to save session")
 	}
-	if err := encoding.MarshalAndSaveProtobuf(archivePath, archive); err != nil {
+	archiveCompression := configuration.ArchiveCompressionMode
+	if archiveCompression.IsDefault() {
+		archiveCompression = version.DefaultArchiveCompressionMode()
+	}
+	if err := saveArchive(archivePath, archive, archiveCompression); err != nil {
 		os.Remove(sessionPath)
 		return nil, errors.Wrap(err, "unable to save archive")
 	}
@@ -212,6 +284,7 @@ func newSession(
 		mergedBetaConfiguration:  mergedBetaConfiguration,
 		state: &State{
 			Session: session,
+			Timings: timings,
 		},
 	}
 
@@ -221,14 +294,22 @@ func newSession(
 	if !paused {
 		logger.Info("Starting synchronization loop")
 		ctx, cancel := context.WithCancel(context.Background())
+		stopCtx, stop := context.WithCancel(ctx)
 		controller.cancel = cancel
-		controller.flushRequests = make(chan chan error, 1)
+		controller.stop = stop
+		controller.flushRequests = make(chan *controllerFlushRequest, 1)
+		controller.reconnectRequests = make(chan chan struct{})
 		controller.done = make(chan struct{})
-		go controller.run(ctx, alphaEndpoint, betaEndpoint)
+		go controller.run(ctx, stopCtx, alphaEndpoint, betaEndpoint, nil)
 		alphaEndpoint = nil
 		betaEndpoint = nil
 	}
 
+	// Start schedule monitoring, if necessary.
+	if err := controller.startScheduleMonitor(systemScheduleClock{}); err != nil {
+		logger.Warning("Unable to start schedule monitoring:", err)
+	}
+
 	// Success.
 	logger.Info("Session initialized")
 	return controller, nil
@@ -287,10 +368,18 @@ func loadSession(logger *logging.Logger, tracker *state.Tracker, identifier stri
 	// If the session isn't marked as paused, start a synchronization loop.
 	if !session.Paused {
 		ctx, cancel := context.WithCancel(context.Background())
+		stopCtx, stop := context.WithCancel(ctx)
 		controller.cancel = cancel
-		controller.flushRequests = make(chan chan error, 1)
+		controller.stop = stop
+		controller.flushRequests = make(chan *controllerFlushRequest, 1)
+		controller.reconnectRequests = make(chan chan struct{})
 		controller.done = make(chan struct{})
-		go controller.run(ctx, nil, nil)
+		go controller.run(ctx, stopCtx, nil, nil, nil)
+	}
+
+	// Start schedule monitoring, if necessary.
+	if err := controller.startScheduleMonitor(systemScheduleClock{}); err != nil {
+		logger.Warning("Unable to start schedule monitoring:", err)
 	}
 
 	// Success.
@@ -313,8 +402,23 @@ func (c *controller) currentState() *State {
 // flush attempts to force a synchronization cycle for the session. If wait is
 // specified, then the method will wait until a post-flush synchronization cycle
 // has completed. The provided context (which must be non-nil) can terminate
-// this wait early.
-func (c *controller) flush(ctx context.Context, prompter string, skipWait bool) error {
+// this wait early. If ignore overrides are specified, then they are applied (as
+// temporary include patterns taking precedence over the session's ignores) for
+// the forced synchronization cycle only. They aren't persisted. If rehash is
+// specified, then endpoints discard their digest caches and recompute the
+// digests of all files during the forced synchronization cycle. It's safe to
+// request a rehash while a synchronization cycle is in progress, since it's
+// only applied at the start of the next cycle.
+func (c *controller) flush(ctx context.Context, prompter string, skipWait bool, ignoreOverrides []string, rehash bool) error {
+	// Validate ignore overrides. We refuse any overrides that could cause
+	// Mutagen's own temporary files and directories to be synchronized, since
+	// that would create a synchronization feedback loop.
+	for _, override := range ignoreOverrides {
+		if err := core.EnsureValidIgnoreOverride(override); err != nil {
+			return errors.Wrapf(err, "invalid ignore override (%s)", override)
+		}
+	}
+
 	// Update status.
 	prompting.Message(prompter, fmt.Sprintf("Forcing synchronization cycle for session %s...", c.session.Identifier))
 
@@ -338,12 +442,18 @@ func (c *controller) flush(ctx context.Context, prompter string, skipWait bool)
 	}
 
 	// Create a flush request.
-	request := make(chan error, 1)
+	request := &controllerFlushRequest{
+		ignoreOverrides: ignoreOverrides,
+		rehash:          rehash,
+		response:        make(chan error, 1),
+	}
 
 	// If we don't want to wait, then we can simply send the request in a
 	// non-blocking manner, in which case either this request (or one that's
 	// already queued) will be processed eventually. After that, we're done.
-	if skipWait {
+	// This doesn't apply to requests with ignore overrides or rehashing, since
+	// they can't be satisfied by a different queued request.
+	if skipWait && len(ignoreOverrides) == 0 && !rehash {
 		// Send the request in a non-blocking manner.
 		select {
 		case c.flushRequests <- request:
@@ -362,10 +472,15 @@ func (c *controller) flush(ctx context.Context, prompter string, skipWait bool)
 		return errors.New("flush cancelled before request could be sent")
 	}
 
+	// If we don't want to wait, then we're done.
+	if skipWait {
+		return nil
+	}
+
 	// Now we need to wait for a response to the request, again watching for
 	// cancellation in the mean time.
 	select {
-	case err := <-request:
+	case err := <-request.response:
 		if err != nil {
 			return err
 		}
@@ -427,13 +542,20 @@ func (c *controller) resume(ctx context.Context, prompter string, lifecycleLockH
 
 		// Nil out any lifecycle state.
 		c.cancel = nil
+		c.stop = nil
 		c.flushRequests = nil
+		c.reconnectRequests = nil
 		c.done = nil
 	}
 
-	// Mark the session as unpaused and save it to disk.
+	// Mark the session as unpaused and save it to disk. If the session was
+	// paused due to excessive deletions, then this resumption confirms them.
 	c.stateLock.Lock()
+	if c.session.Paused {
+		c.deletionsConfirmed = confirmsDeletions(c.session.PausedReason)
+	}
 	c.session.Paused = false
+	c.session.PausedReason = ""
 	saveErr := encoding.MarshalAndSaveProtobuf(c.sessionPath, c.session)
 	c.stateLock.Unlock()
 
@@ -441,6 +563,7 @@ func (c *controller) resume(ctx context.Context, prompter string, lifecycleLockH
 	c.stateLock.Lock()
 	c.state.Status = Status_ConnectingAlpha
 	c.stateLock.Unlock()
+	connectStart := time.Now()
 	alpha, alphaConnectErr := connect(
 		ctx,
 		c.logger.Sublogger("alpha"),
@@ -453,12 +576,16 @@ func (c *controller) resume(ctx context.Context, prompter string, lifecycleLockH
 	)
 	c.stateLock.Lock()
 	c.state.AlphaConnected = (alpha != nil)
+	if alpha != nil {
+		c.state.Timings = c.state.Timings.withConnectDuration(time.Since(connectStart))
+	}
 	c.stateLock.Unlock()
 
 	// Attempt to connect to beta.
 	c.stateLock.Lock()
 	c.state.Status = Status_ConnectingBeta
 	c.stateLock.Unlock()
+	connectStart = time.Now()
 	beta, betaConnectErr := connect(
 		ctx,
 		c.logger.Sublogger("beta"),
@@ -471,16 +598,22 @@ func (c *controller) resume(ctx context.Context, prompter string, lifecycleLockH
 	)
 	c.stateLock.Lock()
 	c.state.BetaConnected = (beta != nil)
+	if beta != nil {
+		c.state.Timings = c.state.Timings.withConnectDuration(time.Since(connectStart))
+	}
 	c.stateLock.Unlock()
 
 	// Start the synchronization loop with what we have. Alpha or beta may have
 	// failed to connect (and be nil), but in any case that'll just make the run
 	// loop keep trying to connect.
 	ctx, cancel := context.WithCancel(context.Background())
+	stopCtx, stop := context.WithCancel(ctx)
 	c.cancel = cancel
-	c.flushRequests = make(chan chan error, 1)
+	c.stop = stop
+	c.flushRequests = make(chan *controllerFlushRequest, 1)
+	c.reconnectRequests = make(chan chan struct{})
 	c.done = make(chan struct{})
-	go c.run(ctx, alpha, beta)
+	go c.run(ctx, stopCtx, alpha, beta, nil)
 
 	// Report any errors. Since we always want to start a synchronization loop,
 	// even on partial or complete failure (since it might be able to
@@ -527,8 +660,12 @@ func (m controllerHaltMode) description() string {
 
 // halt halts the session with the specified behavior. If lifecycleLockHeld is
 // true, then halt will assume that the lifecycle lock is held by the caller and
-// will not attempt to acquire it.
-func (c *controller) halt(_ context.Context, mode controllerHaltMode, prompter string, lifecycleLockHeld bool) error {
+// will not attempt to acquire it. When shutting down, halt will first request
+// that the synchronization loop stop gracefully (allowing any in-flight
+// synchronization cycle to complete and save its results) and will only cancel
+// the loop outright if it hasn't stopped by the time the provided context is
+// cancelled. For other halt modes, the loop is cancelled immediately.
+func (c *controller) halt(ctx context.Context, mode controllerHaltMode, prompter string, lifecycleLockHeld bool) error {
 	// Update status.
 	prompting.Message(prompter, fmt.Sprintf("%s session %s...", mode.description(), c.session.Identifier))
 
@@ -547,40 +684,65 @@ func (c *controller) halt(_ context.Context, mode controllerHaltMode, prompter s
 
 	// Kill any existing synchronization loop.
 	if c.cancel != nil {
+		// If we're shutting down, then request a graceful stop and wait for
+		// the synchronization loop to finish, but only for as long as the
+		// context allows.
+		if mode == controllerHaltModeShutdown {
+			c.stop()
+			select {
+			case <-c.done:
+			case <-ctx.Done():
+				c.logger.Warning("Graceful stop timed out, cancelling synchronization loop")
+			}
+		}
+
 		// Cancel the synchronization loop and wait for it to finish.
 		c.cancel()
 		<-c.done
 
 		// Nil out any lifecycle state.
 		c.cancel = nil
+		c.stop = nil
 		c.flushRequests = nil
+		c.reconnectRequests = nil
 		c.done = nil
 	}
 
 	// Handle based on the halt mode.
 	if mode == controllerHaltModePause {
-		// Mark the session as paused and save it.
+		// Mark the session as paused and save it. Any pause reason is cleared,
+		// since the caller is responsible for recording automatic pauses.
 		c.stateLock.Lock()
 		c.session.Paused = true
+		c.session.PausedReason = ""
 		saveErr := encoding.MarshalAndSaveProtobuf(c.sessionPath, c.session)
 		c.stateLock.Unlock()
 		if saveErr != nil {
 			return errors.Wrap(saveErr, "unable to save session")
 		}
 	} else if mode == controllerHaltModeShutdown {
-		// Disable the controller.
+		// Disable the controller and stop schedule monitoring.
 		c.disabled = true
+		if c.scheduleCancel != nil {
+			c.scheduleCancel()
+		}
 	} else if mode == controllerHaltModeTerminate {
-		// Disable the controller.
+		// Disable the controller and stop schedule monitoring.
 		c.disabled = true
+		if c.scheduleCancel != nil {
+			c.scheduleCancel()
+		}
 
 		// Wipe the session information from disk.
 		sessionRemoveErr := os.Remove(c.sessionPath)
 		archiveRemoveErr := os.Remove(c.archivePath)
+		additionalArchivesRemoveErr := c.removeAdditionalArchives()
 		if sessionRemoveErr != nil {
 			return errors.Wrap(sessionRemoveErr, "unable to remove session from disk")
 		} else if archiveRemoveErr != nil {
 			return errors.Wrap(archiveRemoveErr, "unable to remove archive from disk")
+		} else if additionalArchivesRemoveErr != nil {
+			return errors.Wrap(additionalArchivesRemoveErr, "unable to remove additional beta archives from disk")
 		}
 	} else {
 		panic("invalid halt mode specified")
@@ -590,6 +752,81 @@ func (c *controller) halt(_ context.Context, mode controllerHaltMode, prompter s
 	return nil
 }
 
+// startScheduleMonitor starts monitoring the session's synchronization schedule
+// (if it has one) using the specified clock. It must be called at most once,
+// before the controller is made available for concurrent usage.
+func (c *controller) startScheduleMonitor(clock scheduleClock) error {
+	// Create the schedule. If there isn't one, then there's nothing to monitor.
+	schedule, err := newSchedule(
+		c.session.Configuration.ScheduleWindows,
+		c.session.Configuration.ScheduleTimezone,
+	)
+	if err != nil {
+		return errors.Wrap(err, "invalid schedule")
+	} else if schedule == nil {
+		return nil
+	}
+
+	// Start monitoring.
+	ctx, cancel := context.WithCancel(context.Background())
+	c.scheduleCancel = cancel
+	go monitorSchedule(ctx, schedule, clock, func(active, initial bool) {
+		c.applySchedule(ctx, active, initial)
+	})
+
+	// Success.
+	return nil
+}
+
+// applySchedule pauses or resumes the session based on whether or not it is
+// within its synchronization schedule. Schedule changes override any manual
+// pause or resume operation, which means that manual operations only persist
+// until the next schedule boundary. The initial application (when schedule
+// monitoring starts) won't resume a session that was paused manually, since no
+// schedule boundary has been crossed.
+func (c *controller) applySchedule(ctx context.Context, active, initial bool) {
+	// Lock the controller's lifecycle and defer its release.
+	c.lifecycleLock.Lock()
+	defer c.lifecycleLock.Unlock()
+
+	// If the controller is disabled, then there's nothing to apply.
+	if c.disabled {
+		return
+	}
+
+	// Grab the current paused status.
+	c.stateLock.Lock()
+	paused := c.session.Paused
+	pausedReason := c.session.PausedReason
+	c.stateLock.UnlockWithoutNotify()
+
+	// Handle the schedule status.
+	if active && paused {
+		if initial && pausedReason != schedulePausedReason {
+			return
+		}
+		c.logger.Info("Resuming session at start of schedule window")
+		if err := c.resume(ctx, "", true); err != nil {
+			c.logger.Warning("Unable to resume session for schedule:", err)
+		}
+	} else if !active && (!paused || !initial) {
+		if !paused {
+			c.logger.Info("Pausing session outside of schedule window")
+			if err := c.halt(ctx, controllerHaltModePause, "", true); err != nil {
+				c.logger.Warning("Unable to pause session for schedule:", err)
+				return
+			}
+		}
+		c.stateLock.Lock()
+		c.session.PausedReason = schedulePausedReason
+		saveErr := encoding.MarshalAndSaveProtobuf(c.sessionPath, c.session)
+		c.stateLock.Unlock()
+		if saveErr != nil {
+			c.logger.Warning("Unable to save session:", saveErr)
+		}
+	}
+}
+
 // reset resets synchronization session history by pausing the session (if it's
 // running), overwriting the ancestor data stored on disk with an empty
 // ancestor, and then resuming the session (if it was previously running).
@@ -609,9 +846,10 @@ func (c *controller) reset(ctx context.Context, prompter string) error {
 	}
 
 	// Reset the session archive on disk.
-	archive := &core.Archive{}
-	if err := encoding.MarshalAndSaveProtobuf(c.archivePath, archive); err != nil {
+	if err := saveArchive(c.archivePath, &core.Archive{}, c.archiveCompressionMode()); err != nil {
 		return fmt.Errorf("unable to clear session history: %w", err)
+	} else if err = c.removeAdditionalArchives(); err != nil {
+		return fmt.Errorf("unable to clear additional beta session history: %w", err)
 	}
 
 	// Resume the session if it was previously running.
@@ -626,8 +864,31 @@ func (c *controller) reset(ctx context.Context, prompter string) error {
 }
 
 // run is the main runloop for the controller, managing connectivity and
-// synchronization.
-func (c *controller) run(ctx context.Context, alpha, beta Endpoint) {
+// synchronization. Cancellation of ctx preempts the runloop at any point, while
+// cancellation of stopCtx (which must be a subcontext of ctx) only preempts the
+// runloop when it's connecting, waiting, or scanning, allowing any in-flight
+// staging and transition operations to complete. Any additional beta endpoints
+// that are already connected can be provided via additionalBetas, which should
+// either be nil or have one (possibly nil) entry for each of the session's
+// additional beta URLs. Additional beta endpoints are connected lazily as part
+// of synchronization cycles and remain connected across session
+// synchronization failures.
+func (c *controller) run(ctx, stopCtx context.Context, alpha, beta Endpoint, additionalBetas []Endpoint) {
+	// Label this Goroutine (and, by inheritance, any Goroutines that it creates)
+	// with the session identifier so that the session's workers can be
+	// identified in Goroutine dumps.
+	pprof.SetGoroutineLabels(pprof.WithLabels(ctx, pprof.Labels(sessionGoroutineLabel, c.session.Identifier)))
+
+	// Ensure that we have a slot for each additional beta endpoint.
+	if additionalBetas == nil {
+		additionalBetas = make([]Endpoint, len(c.session.AdditionalBetas))
+	}
+
+	// Track the containers matched by any additional beta label selectors.
+	// These persist across synchronization failures, just like the additional
+	// beta endpoints themselves.
+	labelMemberships := make([]*labelMembership, len(c.session.AdditionalBetas))
+
 	// Defer resource and state cleanup.
 	defer func() {
 		// Shutdown any endpoints. These might be non-nil if the runloop was
@@ -638,11 +899,23 @@ func (c *controller) run(ctx context.Context, alpha, beta Endpoint) {
 		if beta != nil {
 			beta.Shutdown()
 		}
+		for _, endpoint := range additionalBetas {
+			if endpoint != nil {
+				endpoint.Shutdown()
+			}
+		}
+		for _, membership := range labelMemberships {
+			if membership != nil {
+				membership.shutdown()
+			}
+		}
 
-		// Reset the state.
+		// Reset the state, but preserve timings, since they're tracked across
+		// the lifetime of the controller.
 		c.stateLock.Lock()
 		c.state = &State{
 			Session: c.session,
+			Timings: c.state.Timings,
 		}
 		c.stateLock.Unlock()
 
@@ -653,6 +926,10 @@ func (c *controller) run(ctx context.Context, alpha, beta Endpoint) {
 	// Track the last time that synchronization failed.
 	var lastSynchronizationFailureTime time.Time
 
+	// Track any reconnect request awaiting the completion of a connection
+	// attempt.
+	var reconnected chan struct{}
+
 	// Loop until cancelled.
 	for {
 		// Loop until we're connected to both endpoints. We do a non-blocking
@@ -665,8 +942,9 @@ func (c *controller) run(ctx context.Context, alpha, beta Endpoint) {
 				c.stateLock.Lock()
 				c.state.Status = Status_ConnectingAlpha
 				c.stateLock.Unlock()
+				connectStart := time.Now()
 				alpha, _ = connect(
-					ctx,
+					stopCtx,
 					c.logger.Sublogger("alpha"),
 					c.session.Alpha,
 					"",
@@ -675,6 +953,11 @@ func (c *controller) run(ctx context.Context, alpha, beta Endpoint) {
 					c.mergedAlphaConfiguration,
 					true,
 				)
+				if alpha != nil {
+					c.stateLock.Lock()
+					c.state.Timings = c.state.Timings.withConnectDuration(time.Since(connectStart))
+					c.stateLock.Unlock()
+				}
 			}
 			c.stateLock.Lock()
 			c.state.AlphaConnected = (alpha != nil)
@@ -683,7 +966,7 @@ func (c *controller) run(ctx context.Context, alpha, beta Endpoint) {
 			// Check for cancellation to avoid a spurious connection to beta in
 			// case cancellation occurred while connecting to alpha.
 			select {
-			case <-ctx.Done():
+			case <-stopCtx.Done():
 				return
 			default:
 			}
@@ -693,8 +976,9 @@ func (c *controller) run(ctx context.Context, alpha, beta Endpoint) {
 				c.stateLock.Lock()
 				c.state.Status = Status_ConnectingBeta
 				c.stateLock.Unlock()
+				connectStart := time.Now()
 				beta, _ = connect(
-					ctx,
+					stopCtx,
 					c.logger.Sublogger("beta"),
 					c.session.Beta,
 					"",
@@ -703,11 +987,23 @@ func (c *controller) run(ctx context.Context, alpha, beta Endpoint) {
 					c.mergedBetaConfiguration,
 					false,
 				)
+				if beta != nil {
+					c.stateLock.Lock()
+					c.state.Timings = c.state.Timings.withConnectDuration(time.Since(connectStart))
+					c.stateLock.Unlock()
+				}
 			}
 			c.stateLock.Lock()
 			c.state.BetaConnected = (beta != nil)
 			c.stateLock.Unlock()
 
+			// If a reconnect was requested, then inform the requester that the
+			// connection attempt has completed.
+			if reconnected != nil {
+				close(reconnected)
+				reconnected = nil
+			}
+
 			// If both endpoints are connected, we're done. We perform this
 			// check here (rather than in the loop condition) because if we did
 			// it in the loop condition we'd still need a check here to avoid a
@@ -717,16 +1013,18 @@ func (c *controller) run(ctx context.Context, alpha, beta Endpoint) {
 			}
 
 			// If we failed to connect, wait and then retry. Watch for
-			// cancellation in the mean time.
+			// cancellation and reconnect requests in the mean time.
 			select {
-			case <-ctx.Done():
+			case <-stopCtx.Done():
 				return
+			case reconnected = <-c.reconnectRequests:
+				c.logger.Info("Reconnecting upon request")
 			case <-time.After(autoReconnectInterval):
 			}
 		}
 
 		// Perform synchronization.
-		err := c.synchronize(ctx, alpha, beta)
+		err := c.synchronize(ctx, stopCtx, alpha, beta, additionalBetas, labelMemberships)
 
 		// Shutdown the endpoints.
 		alpha.Shutdown()
@@ -735,32 +1033,50 @@ func (c *controller) run(ctx context.Context, alpha, beta Endpoint) {
 		beta = nil
 
 		// Reset the synchronization state, but propagate the error that caused
-		// failure.
+		// failure, any stall report (which may explain the failure), and the
+		// session timings.
 		c.stateLock.Lock()
 		c.state = &State{
-			Session:   c.session,
-			LastError: err.Error(),
+			Session:     c.session,
+			LastError:   err.Error(),
+			StallReport: c.state.StallReport,
+			Timings:     c.state.Timings,
 		}
 		c.stateLock.Unlock()
 
+		// If synchronization was aborted due to excessive conflicts or
+		// deletions, then pause the session and wait for the pause operation to
+		// cancel this loop. The pause has to be performed asynchronously, since
+		// halting waits for this loop to exit.
+		if violation, ok := err.(pausingError); ok {
+			go c.pauseForViolation(c.done, violation)
+			<-stopCtx.Done()
+			return
+		}
+
 		// When synchronization fails, we generally want to restart it as
 		// quickly as possible. Thus, if it's been longer than our usual waiting
 		// period since synchronization failed last, simply try to reconnect
 		// immediately (though still check for cancellation). If it's been less
 		// than our usual waiting period since synchronization failed last, then
 		// something is probably wrong, so wait for our usual waiting period
-		// (while checking and monitoring for cancellation).
+		// (while checking and monitoring for cancellation). A reconnect request
+		// will cut this wait short and reset the failure time, since it
+		// indicates that the cause of failure has likely been addressed.
 		now := time.Now()
 		if now.Sub(lastSynchronizationFailureTime) >= autoReconnectInterval {
 			select {
-			case <-ctx.Done():
+			case <-stopCtx.Done():
 				return
 			default:
 			}
 		} else {
 			select {
-			case <-ctx.Done():
+			case <-stopCtx.Done():
 				return
+			case reconnected = <-c.reconnectRequests:
+				c.logger.Info("Reconnecting upon request")
+				now = time.Time{}
 			case <-time.After(autoReconnectInterval):
 			}
 		}
@@ -768,8 +1084,13 @@ func (c *controller) run(ctx context.Context, alpha, beta Endpoint) {
 	}
 }
 
-// synchronize is the main synchronization loop for the controller.
-func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) error {
+// synchronize is the main synchronization loop for the controller. The contexts
+// have the same semantics as those passed to run. The additional beta endpoint
+// slice is shared with run and updated as additional beta endpoints are
+// connected and disconnected. The label membership slice is likewise shared
+// with run and tracks the containers matched by additional beta endpoints with
+// label selectors.
+func (c *controller) synchronize(ctx, stopCtx context.Context, alpha, beta Endpoint, additionalBetas []Endpoint, labelMemberships []*labelMembership) error {
 	// Clear any error state upon restart of this function. If there was a
 	// terminal error previously caused synchronization to fail, then the user
 	// will have had time to review it (while the run loop is waiting to
@@ -782,32 +1103,100 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 		c.stateLock.UnlockWithoutNotify()
 	}
 
+	// Record the clock skew for each endpoint (if known) and compute warning
+	// problems for any endpoint whose clock skew is excessive. These problems
+	// are reported alongside transition problems for as long as the endpoints
+	// are connected.
+	var αClockSkewProblem, βClockSkewProblem *core.Problem
+	αClockSkew, αClockSkewKnown := endpointClockSkew(alpha)
+	βClockSkew, βClockSkewKnown := endpointClockSkew(beta)
+	if αClockSkewKnown {
+		if αClockSkewProblem = clockSkewProblem(αClockSkew); αClockSkewProblem != nil {
+			c.logger.Warning("Alpha:", αClockSkewProblem.Error)
+		}
+	}
+	if βClockSkewKnown {
+		if βClockSkewProblem = clockSkewProblem(βClockSkew); βClockSkewProblem != nil {
+			c.logger.Warning("Beta:", βClockSkewProblem.Error)
+		}
+	}
+	c.stateLock.Lock()
+	if αClockSkewKnown {
+		c.state.AlphaClockSkew = ptypes.DurationProto(αClockSkew)
+	}
+	if βClockSkewKnown {
+		c.state.BetaClockSkew = ptypes.DurationProto(βClockSkew)
+	}
+	c.state.AlphaProblems = withClockSkewProblem(αClockSkewProblem, nil)
+	c.state.BetaProblems = withClockSkewProblem(βClockSkewProblem, nil)
+	c.stateLock.Unlock()
+
 	// Track any flush request that we've pulled from the queue but haven't
 	// marked as complete. If we bail due to an error, then close out the
 	// request.
-	var flushRequest chan error
+	var flushRequest *controllerFlushRequest
 	defer func() {
 		if flushRequest != nil {
-			flushRequest <- errors.New("synchronization cycle failed")
+			flushRequest.response <- errors.New("synchronization cycle failed")
 			flushRequest = nil
 		}
 	}()
 
-	// Load the archive and extract the ancestor.
-	archive := &core.Archive{}
-	if err := encoding.LoadAndUnmarshalProtobuf(c.archivePath, archive); err != nil {
+	// Load the archive and extract the ancestor. If the archive is corrupted,
+	// then we'll start with an empty ancestor.
+	archive, err := loadArchive(c.logger, c.archivePath)
+	if err != nil {
 		return errors.Wrap(err, "unable to load archive")
-	} else if err = archive.Root.EnsureValid(); err != nil {
-		return errors.Wrap(err, "invalid archive found on disk")
 	}
 	ancestor := archive.Root
 
+	// Determine whether or not the session has been inactive for long enough
+	// that the first synchronization cycle should be a catch-up cycle.
+	catchUp := requiresCatchUp(c.archivePath, time.Now())
+
+	// Create a tracker for files deferred by the cycle transfer budget.
+	deferrals := &transferDeferrals{}
+
+	// Create the path mapping for beta, if any.
+	pathMapping, err := core.NewPathMapping(c.session.Configuration.PathMappings, c.session.Configuration.PathMappingOneWay)
+	if err != nil {
+		return errors.Wrap(err, "unable to create path mapping")
+	}
+
+	// Load the contents of any shared ignore sets referenced by the session.
+	// The endpoints will have been connected using these contents, so if they
+	// change, we'll need to reconnect the endpoints in order for the updated
+	// patterns to take effect.
+	ignoreSetPatterns, err := loadIgnoreSets(c.session.Configuration.IgnoreSets)
+	if err != nil {
+		return errors.Wrap(err, "unable to load ignore sets")
+	}
+
 	// Compute the effective synchronization mode.
 	synchronizationMode := c.session.Configuration.SynchronizationMode
 	if synchronizationMode.IsDefault() {
 		synchronizationMode = c.session.Version.DefaultSynchronizationMode()
 	}
 
+	// Create a client for the shared transfer scheduler, weighted by the
+	// session's transfer priority, to regulate staging transfers.
+	transferPriority := c.session.Configuration.TransferPriority
+	if transferPriority.IsDefault() {
+		transferPriority = c.session.Version.DefaultTransferPriority()
+	}
+	transfers := sharedTransferScheduler().client(transferPriority.weight())
+
+	// Determine whether or not conflicts should be resolved using an external
+	// conflict resolver. External resolution is only supported in two-way-safe
+	// mode, since that's the only mode in which conflicts are left in place.
+	resolveConflictsExternally := synchronizationMode == core.SynchronizationMode_SynchronizationModeTwoWaySafe &&
+		len(c.session.Configuration.ConflictResolverCommand) > 0
+
+	// Determine whether or not conflicts should be resolved using conflict
+	// sidecars. This is likewise only supported in two-way-safe mode.
+	resolveConflictsWithSidecars := synchronizationMode == core.SynchronizationMode_SynchronizationModeTwoWaySafe &&
+		c.session.Configuration.ConflictSidecars
+
 	// Compute, on a per-endpoint basis, whether or not polling should be
 	// disabled.
 	αWatchMode := c.mergedAlphaConfiguration.WatchMode
@@ -832,6 +1221,20 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 	// Create variables to track our reasons for skipping polling.
 	var skippingPollingDueToScanError, skippingPollingDueToMissingFiles bool
 
+	// Compute the deletion grace period and create a variable to track whether
+	// or not the current scan is a re-check of held deletions.
+	deletionGracePeriod := time.Duration(c.session.Configuration.DeletionGracePeriod) * time.Millisecond
+	var holdingDeletions bool
+
+	// Track the last connection attempt time for each additional beta endpoint.
+	additionalBetaConnectAttempts := make([]time.Time, len(additionalBetas))
+
+	// Track the information necessary to undo the last synchronization cycle,
+	// if undo support is enabled. This is only valid for the current endpoint
+	// connections, since endpoints discard retained content when created.
+	undoEnabled := c.session.Configuration.UndoMaximumSize != 0
+	var lastUndo *cycleUndo
+
 	// Loop until there is a synchronization error.
 	for {
 		// Unless we've been requested to skip polling, wait for a dirty state
@@ -906,13 +1309,13 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 				pollCancel()
 				αPollErr = <-αPollResults
 			case flushRequest = <-c.flushRequests:
-				if cap(flushRequest) < 1 {
+				if cap(flushRequest.response) < 1 {
 					panic("unbuffered flush request")
 				}
 				pollCancel()
 				αPollErr = <-αPollResults
 				βPollErr = <-βPollResults
-			case <-ctx.Done():
+			case <-stopCtx.Done():
 				cancelled = true
 				pollCancel()
 				αPollErr = <-αPollResults
@@ -928,39 +1331,90 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 				return errors.Wrap(βPollErr, "beta polling error")
 			}
 		} else {
+			// Even if we're skipping polling, we don't want to start a new
+			// synchronization cycle if we've been asked to stop.
+			select {
+			case <-stopCtx.Done():
+				return errors.New("cancelled before synchronization cycle")
+			default:
+			}
 			skipPolling = false
 		}
 
+		// If an undo operation has been requested, then ensure that the last
+		// synchronization cycle can still be undone.
+		undoing := flushRequest != nil && flushRequest.undo
+		if undoing && !lastUndo.available(c.session.Configuration.UndoMaximumAge) {
+			lastUndo = nil
+			flushRequest.response <- errors.New("no synchronization cycle available to undo")
+			flushRequest = nil
+			continue
+		}
+
+		// If the session references shared ignore sets, then check whether or
+		// not their contents have changed since the endpoints were connected.
+		// If they have, then bail so that the endpoints can be reconnected with
+		// the updated patterns before the next scan.
+		if len(c.session.Configuration.IgnoreSets) > 0 {
+			if patterns, err := loadIgnoreSets(c.session.Configuration.IgnoreSets); err != nil {
+				return errors.Wrap(err, "unable to reload ignore sets")
+			} else if !stringSlicesEqual(patterns, ignoreSetPatterns) {
+				return errors.New("ignore sets modified")
+			}
+		}
+
 		// Scan both endpoints in parallel and check for errors. If a flush
 		// request is present, then force both endpoints to perform a full
-		// (warm) re-scan rather than using acceleration.
+		// (warm) re-scan rather than using acceleration, and apply any ignore
+		// overrides that it specifies.
 		c.stateLock.Lock()
 		c.state.Status = Status_Scanning
 		c.stateLock.Unlock()
-		forceFullScan := flushRequest != nil
+		forceFullScan := flushRequest != nil || holdingDeletions
+		var rehash bool
+		var ignoreOverrides []string
+		if flushRequest != nil {
+			rehash = flushRequest.rehash
+			ignoreOverrides = flushRequest.ignoreOverrides
+		}
 		var αSnapshot, βSnapshot *core.Entry
 		var αPreservesExecutability, βPreservesExecutability bool
+		var αSkipped, βSkipped []*core.Problem
 		var αScanErr, βScanErr error
 		var αTryAgain, βTryAgain bool
+		scanCtx, scanCancel := context.WithCancel(stopCtx)
+		scanWatchdog := c.watchStage(Status_Scanning, scanCancel)
+		scanStart := time.Now()
 		scanDone := &sync.WaitGroup{}
 		scanDone.Add(2)
 		go func() {
-			αSnapshot, αPreservesExecutability, αScanErr, αTryAgain = alpha.Scan(ctx, ancestor, forceFullScan)
+			αSnapshot, αPreservesExecutability, αSkipped, αScanErr, αTryAgain = alpha.Scan(scanCtx, ancestor, forceFullScan, rehash, ignoreOverrides)
+			scanWatchdog.Progress()
 			scanDone.Done()
 		}()
 		go func() {
-			βSnapshot, βPreservesExecutability, βScanErr, βTryAgain = beta.Scan(ctx, ancestor, forceFullScan)
+			βSnapshot, βPreservesExecutability, βSkipped, βScanErr, βTryAgain = beta.Scan(scanCtx, ancestor, forceFullScan, rehash, ignoreOverrides)
+			scanWatchdog.Progress()
 			scanDone.Done()
 		}()
 		scanDone.Wait()
+		scanDuration := time.Since(scanStart)
+		scanStalled := scanWatchdog.Stop()
+		scanCancel()
 
-		// Check if cancellation occurred during scanning.
+		// Check if cancellation occurred during scanning. Scanning doesn't
+		// modify either endpoint, so it's a safe point at which to stop.
 		select {
-		case <-ctx.Done():
+		case <-stopCtx.Done():
 			return errors.New("cancelled during scanning")
 		default:
 		}
 
+		// Check if scanning was aborted due to a stall.
+		if scanStalled && c.session.Configuration.AbortOnStall {
+			return errors.New("scanning stalled")
+		}
+
 		// Check for scan errors.
 		if αScanErr != nil {
 			αScanErr = errors.Wrap(αScanErr, "alpha scan error")
@@ -983,6 +1437,14 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 			}
 		}
 
+		// Record the scan duration if both scans succeeded. Failed scans may
+		// have terminated early, so their durations would skew the results.
+		if αScanErr == nil && βScanErr == nil {
+			c.stateLock.Lock()
+			c.state.Timings = c.state.Timings.withScanDuration(scanDuration)
+			c.stateLock.Unlock()
+		}
+
 		// Watch for retry recommendations from scan operations. These occur
 		// when a scan fails and concurrent modifications are suspected as the
 		// culprit. In these cases, we force another synchronization cycle. Note
@@ -1005,7 +1467,7 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 				// Wait before trying to rescan, but watch for cancellation.
 				select {
 				case <-time.After(rescanWaitDuration):
-				case <-ctx.Done():
+				case <-stopCtx.Done():
 					return errors.New("cancelled during rescan wait")
 				}
 			}
@@ -1029,6 +1491,81 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 			c.stateLock.UnlockWithoutNotify()
 		}
 
+		// If a path mapping is configured, then map beta's snapshot back to
+		// synchronization paths so that reconciliation can be performed using
+		// synchronization paths. Alpha's snapshot and the ancestor identify the
+		// known content to which beta's content can correspond.
+		var βMapping *pathMappingCycle
+		var βMappingProblems []*core.Problem
+		if pathMapping != nil {
+			βMapping, βMappingProblems = newPathMappingCycle(pathMapping, βSnapshot, αSnapshot, ancestor)
+			βSnapshot = βMapping.snapshot
+			βSkipped = translateProblems(βSkipped, βMapping.scannedPaths)
+		}
+
+		// If a deletion grace period is configured and either endpoint appears
+		// to have deleted content, then hold off on propagating the deletions,
+		// wait for the grace period to elapse, and force another cycle to
+		// re-check them. This absorbs transient gaps introduced by atomic file
+		// replacement. Deletions that persist across the re-check (which is a
+		// full scan, so it doesn't depend on watcher events from the gap) are
+		// propagated normally. As with scan retries, our flush request, if
+		// any, will remain valid because we skip polling.
+		if deletionGracePeriod > 0 && !holdingDeletions && !undoing &&
+			(contentDisappeared(ancestor, αSnapshot) || contentDisappeared(ancestor, βSnapshot)) {
+			c.stateLock.Lock()
+			c.state.Status = Status_WaitingForRescan
+			c.stateLock.Unlock()
+			select {
+			case <-time.After(deletionGracePeriod):
+			case <-stopCtx.Done():
+				return errors.New("cancelled during deletion grace period")
+			}
+			holdingDeletions = true
+			skipPolling = true
+			continue
+		}
+		holdingDeletions = false
+
+		// If requested, compute and record Merkle roots for the scanned
+		// synchronization roots.
+		if c.session.Configuration.ComputeMerkleRoot {
+			αMerkleRoot := αSnapshot.MerkleRoot()
+			βMerkleRoot := βSnapshot.MerkleRoot()
+			c.stateLock.Lock()
+			c.state.AlphaMerkleRoot = αMerkleRoot
+			c.state.BetaMerkleRoot = βMerkleRoot
+			c.stateLock.Unlock()
+		}
+
+		// If there are additional beta endpoints, then record alpha's scan
+		// results (before they're adjusted for beta) so that they can be
+		// propagated to those endpoints once beta has been synchronized.
+		var fanOut *fanOutCycle
+		if len(additionalBetas) > 0 && !undoing {
+			fanOut = &fanOutCycle{
+				alpha:                  alpha,
+				snapshot:               αSnapshot,
+				preservesExecutability: αPreservesExecutability,
+				skipped:                αSkipped,
+				synchronizationMode:    synchronizationMode,
+				forceFullScan:          forceFullScan,
+				rehash:                 rehash,
+				ignoreOverrides:        ignoreOverrides,
+			}
+		}
+
+		// Exclude any files skipped on either endpoint (due to exceeding the
+		// maximum file size) from both snapshots so that they aren't
+		// synchronized in either direction.
+		if len(αSkipped) > 0 || len(βSkipped) > 0 {
+			if αSnapshot, err = excludeSkippedFiles(αSnapshot, αSkipped, βSkipped); err != nil {
+				return errors.Wrap(err, "unable to exclude skipped files from alpha snapshot")
+			} else if βSnapshot, err = excludeSkippedFiles(βSnapshot, αSkipped, βSkipped); err != nil {
+				return errors.Wrap(err, "unable to exclude skipped files from beta snapshot")
+			}
+		}
+
 		// If one side preserves executability and the other does not, then
 		// propagate executability from the preserving side to the
 		// non-preserving side.
@@ -1038,6 +1575,22 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 			αSnapshot = core.PropagateExecutability(ancestor, βSnapshot, αSnapshot)
 		}
 
+		// If the session's ancestor is pending seeding due to baseline
+		// startup, then verify that the endpoint contents are equal, since
+		// they'll otherwise be reconciled against an empty ancestor. If they
+		// are equal, then reconciliation will simply record them in the
+		// ancestor without generating any transitions. If they aren't, then
+		// abort the cycle so that the session is paused, leaving the baseline
+		// pending until the user makes them equal and resumes the session.
+		c.stateLock.Lock()
+		baselinePending := c.session.BaselinePending
+		c.stateLock.UnlockWithoutNotify()
+		if baselinePending && !undoing {
+			if err := checkBaseline(αSnapshot, βSnapshot); err != nil {
+				return err
+			}
+		}
+
 		// Update status to reconciling.
 		c.stateLock.Lock()
 		c.state.Status = Status_Reconciling
@@ -1054,17 +1607,32 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 			c.stateLock.Lock()
 			c.state.Status = Status_HaltedOnRootEmptied
 			c.stateLock.Unlock()
-			<-ctx.Done()
+			<-stopCtx.Done()
 			return errors.New("cancelled while halted on emptied root")
 		}
 
-		// Perform reconciliation.
-		ancestorChanges, αTransitions, βTransitions, conflicts := core.Reconcile(
-			ancestor,
-			αSnapshot,
-			βSnapshot,
-			synchronizationMode,
-		)
+		// Perform reconciliation. If debug logging is enabled, then record the
+		// decisions made by reconciliation so that they can be inspected. This
+		// carries additional overhead, so we avoid it otherwise.
+		var ancestorChanges, αTransitions, βTransitions []*core.Change
+		var conflicts []*core.Conflict
+		var decisions []*core.ReconciliationDecision
+		if c.logger.Enabled(logging.LevelDebug) {
+			ancestorChanges, αTransitions, βTransitions, conflicts, decisions = core.ReconcileWithDecisions(
+				ancestor,
+				αSnapshot,
+				βSnapshot,
+				synchronizationMode,
+			)
+			c.logger.Debugf("Reconciliation made %d decision(s)", len(decisions))
+		} else {
+			ancestorChanges, αTransitions, βTransitions, conflicts = core.Reconcile(
+				ancestor,
+				αSnapshot,
+				βSnapshot,
+				synchronizationMode,
+			)
+		}
 
 		// Create a slim copy of the conflicts so that we don't need to hold
 		// the full-size versions in memory or send them over the wire.
@@ -1077,8 +1645,82 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 		}
 		c.stateLock.Lock()
 		c.state.Conflicts = slimConflicts
+		c.state.ReconciliationDecisions = decisions
+		observing := c.session.Observing
 		c.stateLock.Unlock()
 
+		// If the session is in observation mode, then report the changes that
+		// would be applied to each endpoint and complete the cycle without
+		// staging or transitioning. Since the ancestor isn't updated, the
+		// reported changes will track the endpoint contents until the session
+		// is switched to live mode, at which point they'll be applied.
+		if observing {
+			c.stateLock.Lock()
+			c.state.ObservedAlphaChanges = slimChanges(αTransitions)
+			c.state.ObservedBetaChanges = slimChanges(βTransitions)
+			c.state.AlphaProblems = withClockSkewProblem(αClockSkewProblem, withSkippedFileProblems(αSkipped, nil))
+			c.state.BetaProblems = withClockSkewProblem(βClockSkewProblem, withSkippedFileProblems(βSkipped, βMappingProblems))
+			c.state.SuccessfulSynchronizationCycles++
+			c.stateLock.Unlock()
+			if flushRequest != nil {
+				if undoing {
+					flushRequest.response <- errors.New("undo operations not supported in observation mode")
+				} else {
+					flushRequest.response <- nil
+				}
+				flushRequest = nil
+			}
+			continue
+		}
+
+		// If an undo operation has been requested, then replace the reconciled
+		// transitions with those that revert the last synchronization cycle.
+		// Any changes made since the last cycle will be rediscovered by the
+		// next cycle, since the ancestor won't be updated.
+		if undoing {
+			ancestorChanges, conflicts = nil, nil
+			αTransitions, βTransitions = lastUndo.alpha, lastUndo.beta
+		}
+
+		// If the number of conflicts exceeds the conflict pause threshold, then
+		// abort the cycle before applying any changes so that the session can
+		// be paused.
+		threshold := c.session.Configuration.ConflictPauseThreshold
+		if threshold != 0 && uint64(len(conflicts)) > threshold {
+			return &conflictThresholdError{conflicts: len(conflicts), threshold: threshold}
+		}
+
+		// If the cycle would delete too many entries on either endpoint, then
+		// abort the cycle before applying any changes so that the session can
+		// be paused for confirmation. This guards against mass deletions that
+		// result from content being restored or replaced underneath the
+		// session. Undo operations and cycles following a confirming
+		// resumption are exempt.
+		if !undoing && !c.deletionsConfirmed {
+			deletionThreshold := c.session.Configuration.DeletionPauseThreshold
+			deletionPercentage := c.session.Configuration.DeletionPausePercentage
+			if err := checkDeletionThreshold("alpha", ancestor, αTransitions, deletionThreshold, deletionPercentage); err != nil {
+				return err
+			} else if err = checkDeletionThreshold("beta", ancestor, βTransitions, deletionThreshold, deletionPercentage); err != nil {
+				return err
+			}
+		}
+
+		// If external conflict resolution is enabled, then request that beta
+		// resolve any eligible conflicts. These transitions depend on alpha's
+		// version of each file, so they'll be staged on beta like any other
+		// transition.
+		if resolveConflictsExternally && len(conflicts) > 0 {
+			βTransitions = append(βTransitions, conflictResolutionTransitions(conflicts)...)
+		}
+
+		// If conflict sidecars are enabled, then request that beta resolve any
+		// eligible conflicts in alpha's favor, preserving its own version of
+		// each file in a conflict sidecar file.
+		if resolveConflictsWithSidecars && len(conflicts) > 0 {
+			βTransitions = append(βTransitions, conflictSidecarTransitions(conflicts)...)
+		}
+
 		// Check if a root deletion operation is being propagated. This can be
 		// intentional, accidental, or an indication of a non-persistent
 		// filesystem (such as a container filesystem). In any case, we switch
@@ -1089,7 +1731,7 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 			c.stateLock.Lock()
 			c.state.Status = Status_HaltedOnRootDeletion
 			c.stateLock.Unlock()
-			<-ctx.Done()
+			<-stopCtx.Done()
 			return errors.New("cancelled while halted on root deletion")
 		}
 
@@ -1101,10 +1743,53 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 			c.stateLock.Lock()
 			c.state.Status = Status_HaltedOnRootTypeChange
 			c.stateLock.Unlock()
-			<-ctx.Done()
+			<-stopCtx.Done()
 			return errors.New("cancelled while halted on root type change")
 		}
 
+		// Exclude transitions targeting quarantined paths. Excluded transitions
+		// won't be reflected in the ancestor, so they'll be rediscovered (and
+		// excluded again) by subsequent cycles until the paths are retried.
+		// Undo operations are exempt, since they're expected to be complete.
+		if !undoing {
+			c.stateLock.Lock()
+			αTransitions = c.quarantine.filter(αTransitions)
+			βTransitions = c.quarantine.filter(βTransitions)
+			c.stateLock.Unlock()
+		}
+
+		// If this is a catch-up cycle, then restrict it to priority transitions
+		// (so that the most likely relevant content is synchronized quickly)
+		// and force an immediate follow-up cycle to apply the remainder.
+		// Transitions that aren't applied won't be reflected in the ancestor,
+		// so they'll be rediscovered by the next cycle's reconciliation. Cycles
+		// triggered by flush requests are never restricted, since they're
+		// expected to be complete.
+		if catchUp {
+			catchUp = false
+			αPriority, αDeferred := partitionCatchUpTransitions(αTransitions)
+			βPriority, βDeferred := partitionCatchUpTransitions(βTransitions)
+			prioritized := len(αPriority) + len(βPriority)
+			deferred := len(αDeferred) + len(βDeferred)
+			if flushRequest == nil && prioritized > 0 && deferred > 0 {
+				c.logger.Infof("Catching up with %d prioritized transition(s), deferring %d", prioritized, deferred)
+				αTransitions, βTransitions = αPriority, βPriority
+				skipPolling = true
+			}
+		}
+
+		// If a path mapping is configured, then convert beta's transitions to
+		// transitions of its on-disk content, which are used for staging and
+		// transitioning on beta.
+		βOnDiskTransitions := βTransitions
+		if βMapping != nil && len(βTransitions) > 0 {
+			var problems []*core.Problem
+			if βOnDiskTransitions, problems, err = βMapping.transitions(βTransitions); err != nil {
+				return errors.Wrap(err, "unable to map beta transitions")
+			}
+			βMappingProblems = append(βMappingProblems, problems...)
+		}
+
 		// Create a monitoring callback for rsync staging.
 		monitor := func(status *rsync.ReceiverStatus) error {
 			c.stateLock.Lock()
@@ -1113,50 +1798,160 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 			return nil
 		}
 
-		// Stage files on alpha.
-		c.stateLock.Lock()
-		c.state.Status = Status_StagingAlpha
-		c.stateLock.Unlock()
-		if paths, digests, err := core.TransitionDependencies(αTransitions); err != nil {
-			return errors.Wrap(err, "unable to determine paths for staging on alpha")
-		} else if len(paths) > 0 {
-			filteredPaths, signatures, receiver, err := alpha.Stage(paths, digests)
+		// If debug logging is enabled, then record the delta transfer
+		// efficiency for each staged file so that it can be inspected. This
+		// carries (minor) additional overhead, so we avoid it otherwise.
+		recordEfficiencies := c.logger.Enabled(logging.LevelDebug)
+		var efficiencies []*rsync.TransferEfficiency
+
+		// Determine the transfer budget for this cycle. Like catch-up
+		// restrictions, the budget isn't applied to undo operations or cycles
+		// triggered by flush requests, since they're expected to be complete.
+		var budgetLimit uint64
+		if !undoing && flushRequest == nil {
+			budgetLimit = c.session.Configuration.CycleTransferBudget
+		}
+
+		// Determine whether or not to verify that files fit within the space
+		// available on the staging endpoint before transferring them.
+		checkSpace := c.session.Configuration.CheckAvailableSpace
+
+		// Create a staging function that stages the files required by the
+		// specified transitions using the specified stager and supplier. It
+		// returns the number of bytes received and the paths whose staging was
+		// deferred by the transfer budget. If a budget limit is set, then the
+		// budget is shared with any bytes already received during this cycle.
+		// If space checking is enabled and the files to be transferred won't
+		// fit on the stager, then it returns an insufficientSpaceError before
+		// any transfer begins.
+		var cycleReceived uint64
+		stage := func(alphaStager bool, transitions []*core.Change) ([]string, error) {
+			name, status, stager, supplier := "beta", Status_StagingBeta, beta, alpha
+			if alphaStager {
+				name, status, stager, supplier = "alpha", Status_StagingAlpha, alpha, beta
+			}
+			c.stateLock.Lock()
+			c.state.Status = status
+			c.stateLock.Unlock()
+			paths, digests, err := core.TransitionDependencies(transitions)
 			if err != nil {
-				return errors.Wrap(err, "unable to begin staging on alpha")
+				return nil, errors.Wrapf(err, "unable to determine paths for staging on %s", name)
+			} else if len(paths) == 0 {
+				deferrals.record(alphaStager, nil)
+				return nil, nil
+			}
+			deferrals.order(alphaStager, paths, digests)
+			available := uint64(math.MaxUint64)
+			if checkSpace {
+				if available, err = stager.AvailableSpace(); err != nil {
+					return nil, errors.Wrapf(err, "unable to query available space on %s", name)
+				}
+			}
+			filteredPaths, signatures, receiver, err := stager.Stage(paths, digests)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to begin staging on %s", name)
 			}
 			if !filteredPathsAreSubset(filteredPaths, paths) {
-				return errors.New("alpha returned incorrect subset of staging paths")
+				return nil, errors.Errorf("%s returned incorrect subset of staging paths", name)
+			}
+			if len(filteredPaths) == 0 {
+				deferrals.record(alphaStager, nil)
+				return nil, nil
 			}
-			if len(filteredPaths) > 0 {
-				receiver = rsync.NewMonitoringReceiver(receiver, filteredPaths, monitor)
-				receiver = rsync.NewPreemptableReceiver(ctx, receiver)
-				if err = beta.Supply(filteredPaths, signatures, receiver); err != nil {
-					return errors.Wrap(err, "unable to stage files on alpha")
+			supplyPaths := filteredPaths
+			if βMapping != nil {
+				if alphaStager {
+					supplyPaths = translatePaths(filteredPaths, βMapping.onDiskPaths)
+				} else {
+					supplyPaths = translatePaths(filteredPaths, βMapping.synchronizationPaths)
+				}
+			}
+			if available != math.MaxUint64 {
+				required, err := supplier.Measure(supplyPaths)
+				if err != nil {
+					return nil, errors.Wrapf(err, "unable to measure files for staging on %s", name)
+				} else if err = checkAvailableSpace(name, required, available); err != nil {
+					return nil, err
 				}
 			}
+			receiver = rsync.NewMonitoringReceiver(receiver, filteredPaths, monitor)
+			var efficiency *rsync.EfficiencyReceiver
+			if recordEfficiencies {
+				efficiency = rsync.NewEfficiencyReceiver(receiver, filteredPaths, signatures)
+				receiver = efficiency
+			}
+			counter := rsync.NewCountingReceiver(receiver)
+			deferralTracker := rsync.NewDeferralTrackingReceiver(counter, filteredPaths)
+			receiver = rsync.NewPreemptableReceiver(ctx, deferralTracker)
+			receiver = rsync.NewThrottledReceiver(ctx, receiver, transfers)
+			var budget *rsync.TransmissionBudget
+			if budgetLimit != 0 {
+				budget = &rsync.TransmissionBudget{Limit: budgetLimit, Used: cycleReceived}
+			}
+			stagingStart := time.Now()
+			if err = supplier.Supply(supplyPaths, signatures, receiver, budget); err != nil {
+				return nil, errors.Wrapf(err, "unable to stage files on %s", name)
+			}
+			cycleReceived += counter.Received()
+			c.stateLock.Lock()
+			c.state.Timings = c.state.Timings.withStagingThroughput(counter.Received(), time.Since(stagingStart))
+			c.stateLock.Unlock()
+			if efficiency != nil {
+				efficiencies = append(efficiencies, efficiency.Efficiencies()...)
+			}
+			deferred := deferralTracker.Deferred()
+			deferrals.record(alphaStager, deferred)
+			return deferred, nil
 		}
 
-		// Stage files on beta.
-		c.stateLock.Lock()
-		c.state.Status = Status_StagingBeta
-		c.stateLock.Unlock()
-		if paths, digests, err := core.TransitionDependencies(βTransitions); err != nil {
-			return errors.Wrap(err, "unable to determine paths for staging on beta")
-		} else if len(paths) > 0 {
-			filteredPaths, signatures, receiver, err := beta.Stage(paths, digests)
-			if err != nil {
-				return errors.Wrap(err, "unable to begin staging on beta")
+		// Stage files on both endpoints. If files were deferred by the transfer
+		// budget in previous cycles, then the order alternates so that both
+		// directions have regular first claim to the budget.
+		var αDeferred, βDeferred []string
+		if deferrals.betaFirst {
+			if βDeferred, err = stage(false, βOnDiskTransitions); err != nil {
+				return err
+			} else if αDeferred, err = stage(true, αTransitions); err != nil {
+				return err
 			}
-			if !filteredPathsAreSubset(filteredPaths, paths) {
-				return errors.New("beta returned incorrect subset of staging paths")
+		} else {
+			if αDeferred, err = stage(true, αTransitions); err != nil {
+				return err
+			} else if βDeferred, err = stage(false, βOnDiskTransitions); err != nil {
+				return err
 			}
-			if len(filteredPaths) > 0 {
-				receiver = rsync.NewMonitoringReceiver(receiver, filteredPaths, monitor)
-				receiver = rsync.NewPreemptableReceiver(ctx, receiver)
-				if err = alpha.Supply(filteredPaths, signatures, receiver); err != nil {
-					return errors.Wrap(err, "unable to stage files on beta")
-				}
+		}
+		deferrals.complete()
+
+		// If any files were deferred by the transfer budget, then exclude them
+		// from transitions and force an immediate follow-up cycle to continue
+		// propagating changes. Excluded files won't be reflected in the
+		// ancestor, so they'll be rediscovered by the next cycle's
+		// reconciliation.
+		c.stateLock.Lock()
+		c.state.DeferredTransfers = deferrals.count()
+		c.stateLock.Unlock()
+		if len(αDeferred) > 0 || len(βDeferred) > 0 {
+			c.logger.Infof("Transfer budget reached after %d byte(s), deferring %d file(s)",
+				cycleReceived, len(αDeferred)+len(βDeferred),
+			)
+			αTransitions = core.ExcludeTransitionDependencies(αTransitions, αDeferred)
+			βOnDiskTransitions = core.ExcludeTransitionDependencies(βOnDiskTransitions, βDeferred)
+			if βMapping == nil {
+				βTransitions = βOnDiskTransitions
 			}
+			skipPolling = true
+		}
+
+		// Record transfer efficiencies, if any, ordering them from least to
+		// most efficient so that poorly transferring files are listed first.
+		if len(efficiencies) > 0 {
+			sort.SliceStable(efficiencies, func(i, j int) bool {
+				return efficiencies[i].MatchRatio() < efficiencies[j].MatchRatio()
+			})
+			c.stateLock.Lock()
+			c.state.TransferEfficiencies = efficiencies
+			c.stateLock.Unlock()
 		}
 
 		// Perform transitions on both endpoints in parallel. For each side that
@@ -1171,6 +1966,9 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 		var αMissingFiles, βMissingFiles bool
 		var αTransitionErr, βTransitionErr error
 		var αChanges, βChanges []*core.Change
+		transitionCtx, transitionCancel := context.WithCancel(ctx)
+		transitionWatchdog := c.watchStage(Status_Transitioning, transitionCancel)
+		transitionStart := time.Now()
 		transitionDone := &sync.WaitGroup{}
 		if len(αTransitions) > 0 {
 			transitionDone.Add(1)
@@ -1180,38 +1978,78 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 		}
 		if len(αTransitions) > 0 {
 			go func() {
-				αResults, αProblems, αMissingFiles, αTransitionErr = alpha.Transition(ctx, αTransitions)
+				αResults, αProblems, αMissingFiles, αTransitionErr = alpha.Transition(transitionCtx, αTransitions)
 				if αTransitionErr == nil {
 					for t, transition := range αTransitions {
 						αChanges = append(αChanges, &core.Change{Path: transition.Path, New: αResults[t]})
 					}
 				}
+				transitionWatchdog.Progress()
 				transitionDone.Done()
 			}()
 		}
 		if len(βTransitions) > 0 {
 			go func() {
-				βResults, βProblems, βMissingFiles, βTransitionErr = beta.Transition(ctx, βTransitions)
+				βResults, βProblems, βMissingFiles, βTransitionErr = beta.Transition(transitionCtx, βOnDiskTransitions)
+				if βTransitionErr == nil && βMapping != nil {
+					βProblems = translateProblems(βProblems, βMapping.synchronizationPaths)
+					βResults, βTransitionErr = βMapping.results(βTransitions, βOnDiskTransitions, βResults)
+				}
 				if βTransitionErr == nil {
 					for t, transition := range βTransitions {
+						if transition.Resolve || transition.ConflictSidecar {
+							if change := conflictResolutionAncestorChange(transition, βResults[t]); change != nil {
+								βChanges = append(βChanges, change)
+							}
+							continue
+						}
 						βChanges = append(βChanges, &core.Change{Path: transition.Path, New: βResults[t]})
 					}
 				}
+				transitionWatchdog.Progress()
 				transitionDone.Done()
 			}()
 		}
 		transitionDone.Wait()
+		transitionDuration := time.Since(transitionStart)
+		transitionStalled := transitionWatchdog.Stop()
+		transitionCancel()
 
 		// Record problems and then combine changes and propagate them to the
 		// ancestor. Even if there were transition errors, this code is still
 		// valid.
 		c.stateLock.Lock()
 		c.state.Status = Status_Saving
-		c.state.AlphaProblems = αProblems
-		c.state.BetaProblems = βProblems
+		if len(αTransitions) > 0 || len(βTransitions) > 0 {
+			c.state.Timings = c.state.Timings.withTransitionDuration(transitionDuration)
+		}
+		c.state.AlphaProblems = withClockSkewProblem(αClockSkewProblem, withSkippedFileProblems(αSkipped, αProblems))
+		c.state.BetaProblems = withClockSkewProblem(βClockSkewProblem, withSkippedFileProblems(βSkipped, withSkippedFileProblems(βMappingProblems, βProblems)))
+		var quarantined []string
+		if !undoing {
+			var attempted []*core.Change
+			var failed []*core.Problem
+			if αTransitionErr == nil {
+				attempted = append(attempted, αTransitions...)
+				failed = append(failed, αProblems...)
+			}
+			if βTransitionErr == nil {
+				attempted = append(attempted, βTransitions...)
+				failed = append(failed, βProblems...)
+			}
+			quarantined = c.quarantine.record(c.session.Configuration.QuarantineThreshold, attempted, failed)
+			c.state.QuarantinedPaths = c.quarantine.paths()
+		}
 		c.stateLock.Unlock()
-		ancestorChanges = append(ancestorChanges, αChanges...)
-		ancestorChanges = append(ancestorChanges, βChanges...)
+		if len(quarantined) > 0 {
+			c.logger.Warningf("Quarantined %d repeatedly failing path(s): %s",
+				len(quarantined), strings.Join(quarantined, ", "),
+			)
+		}
+		if !undoing {
+			ancestorChanges = append(ancestorChanges, αChanges...)
+			ancestorChanges = append(ancestorChanges, βChanges...)
+		}
 		if newAncestor, err := core.Apply(ancestor, ancestorChanges); err != nil {
 			return errors.Wrap(err, "unable to propagate changes to ancestor")
 		} else {
@@ -1233,12 +2071,28 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 
 		// Save the ancestor.
 		archive.Root = ancestor
-		if err := encoding.MarshalAndSaveProtobuf(c.archivePath, archive); err != nil {
+		if err := saveArchive(c.archivePath, archive, c.archiveCompressionMode()); err != nil {
 			return errors.Wrap(err, "unable to save ancestor")
 		}
 
-		// Now check for transition errors.
-		if αTransitionErr != nil {
+		// If the ancestor was seeded due to baseline startup, then mark the
+		// baseline as established.
+		if baselinePending && !undoing {
+			c.stateLock.Lock()
+			c.session.BaselinePending = false
+			saveErr := encoding.MarshalAndSaveProtobuf(c.sessionPath, c.session)
+			c.stateLock.Unlock()
+			if saveErr != nil {
+				return errors.Wrap(saveErr, "unable to save session")
+			}
+			c.logger.Info("Established baseline from endpoint contents")
+		}
+
+		// Now check for transition errors, starting with a stall-induced abort
+		// (which would likely be the cause of any other transition errors).
+		if transitionStalled && c.session.Configuration.AbortOnStall {
+			return errors.New("transitioning stalled")
+		} else if αTransitionErr != nil {
 			return errors.Wrap(αTransitionErr, "unable to apply changes to alpha")
 		} else if βTransitionErr != nil {
 			return errors.Wrap(βTransitionErr, "unable to apply changes to beta")
@@ -1257,15 +2111,44 @@ func (c *controller) synchronize(ctx context.Context, alpha, beta Endpoint) erro
 			skippingPollingDueToMissingFiles = false
 		}
 
-		// Increment the synchronization cycle count.
+		// If this cycle undid the last synchronization cycle, then skip polling
+		// so that the restored content is propagated to the opposite endpoint
+		// immediately. Otherwise, record the information necessary to undo
+		// this cycle (if it applied any changes).
+		if undoing {
+			lastUndo = nil
+			skipPolling = true
+		} else if undoEnabled && (len(αTransitions) > 0 || len(βTransitions) > 0) {
+			lastUndo = newCycleUndo(αTransitions, αResults, βTransitions, βResults)
+		}
+
+		// Propagate alpha's contents to any additional beta endpoints. Failures
+		// for these endpoints are isolated and recorded in their states.
+		if fanOut != nil {
+			c.synchronizeAdditionalBetas(ctx, stopCtx, fanOut, additionalBetas, labelMemberships, additionalBetaConnectAttempts)
+		}
+
+		// Increment the synchronization cycle count and record the cycle in
+		// the activity history.
 		c.stateLock.Lock()
 		c.state.SuccessfulSynchronizationCycles++
+		c.activity.recordCycle(time.Now(), αTransitions, βTransitions)
 		c.stateLock.Unlock()
 
+		// Any confirmed deletions have now been applied, so subsequent cycles
+		// are once again subject to deletion checks.
+		c.deletionsConfirmed = false
+
 		// If a flush request triggered this synchronization cycle, then tell it
-		// that the cycle has completed and remove it from our tracking.
+		// that the cycle has completed and remove it from our tracking. If the
+		// cycle was an undo operation that couldn't restore all content, then
+		// report that to the requester.
 		if flushRequest != nil {
-			flushRequest <- nil
+			if undoing && (len(αProblems) > 0 || len(βProblems) > 0) {
+				flushRequest.response <- errors.New("unable to undo all changes (see session problems)")
+			} else {
+				flushRequest.response <- nil
+			}
 			flushRequest = nil
 		}
 	}
@@ -1,3 +1,843 @@
 package synchronization
 
-// TODO: Implement.
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/state"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/rsync"
+)
+
+// testShutdownContent is the content used for controller shutdown tests.
+var testShutdownContent = map[string][]byte{
+	"first":  []byte("first file content"),
+	"second": []byte("second file content"),
+	"third":  []byte("third file content"),
+}
+
+// testDirectoryEndpoint is an Endpoint implementation that operates directly on
+// a local directory. Rather than staging content via rsync, it "stages" files
+// by copying them from a source directory, so it only supports transitions for
+// content that exists in that directory.
+type testDirectoryEndpoint struct {
+	// root is the synchronization root.
+	root string
+	// source is the directory from which files are staged.
+	source string
+	// staging is the directory in which staged files are stored.
+	staging string
+	// ignores are the ignore patterns to use when scanning.
+	ignores []string
+	// ignoreOverrides records the ignore overrides passed to each scan.
+	ignoreOverrides [][]string
+	// rehashes records whether or not a rehash was requested for each scan.
+	rehashes []bool
+	// cache is the cache from the most recent scan. It's required for
+	// transitions that modify existing files.
+	cache *core.Cache
+	// beforeTransition, if non-nil, is invoked at the start of Transition
+	// with the transition context.
+	beforeTransition func(context.Context)
+	// afterScan, if non-nil, is invoked at the end of each scan.
+	afterScan func()
+	// transitions records the changes passed to each transition.
+	transitions [][]*core.Change
+	// supplyStaging indicates that files should be staged by receiving them
+	// via rsync (from the opposite endpoint's Supply method) rather than by
+	// copying them from the source directory.
+	supplyStaging bool
+	// supplied records the number of data bytes transmitted by each call to
+	// Supply.
+	supplied []uint64
+	// availableSpace, if non-nil, is invoked to determine the space available
+	// for staging. If nil, then the available space is unknown.
+	availableSpace func() uint64
+	// failTransition, if non-nil, is invoked for each transition path to
+	// determine whether or not the transition should fail with a problem
+	// (leaving the path unmodified).
+	failTransition func(path string) bool
+}
+
+// Poll implements Endpoint.Poll. It never reports modifications.
+func (e *testDirectoryEndpoint) Poll(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// Scan implements Endpoint.Scan.
+func (e *testDirectoryEndpoint) Scan(ctx context.Context, _ *core.Entry, _, rehash bool, ignoreOverrides []string) (*core.Entry, bool, []*core.Problem, error, bool) {
+	e.ignoreOverrides = append(e.ignoreOverrides, ignoreOverrides)
+	e.rehashes = append(e.rehashes, rehash)
+	snapshot, preservesExecutability, _, cache, _, _, err := core.Scan(
+		ctx,
+		e.root,
+		nil,
+		nil,
+		Version_Version1.Hasher(),
+		nil,
+		core.WithIgnoreOverrides(e.ignores, ignoreOverrides),
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		core.SymlinkMode_SymlinkModePortable,
+		&core.ScanOptions{
+			BrokenSymlinkMode: core.BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           core.ACLMode_ACLModeIgnore,
+		},
+	)
+	e.cache = cache
+	if e.afterScan != nil {
+		e.afterScan()
+	}
+	return snapshot, preservesExecutability, nil, err, false
+}
+
+// stagedPath computes the staging path for the specified digest.
+func (e *testDirectoryEndpoint) stagedPath(digest []byte) string {
+	return filepath.Join(e.staging, fmt.Sprintf("%x", digest))
+}
+
+// Stage implements Endpoint.Stage. Unless staging via supplying is enabled, it
+// stages all files from the source directory and thus never requires content to
+// be supplied.
+func (e *testDirectoryEndpoint) Stage(paths []string, digests [][]byte) ([]string, []*rsync.Signature, rsync.Receiver, error) {
+	if e.supplyStaging {
+		return e.stageViaSupply(paths, digests)
+	}
+	for p, path := range paths {
+		content, err := ioutil.ReadFile(filepath.Join(e.source, filepath.FromSlash(path)))
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, "unable to read source file")
+		} else if err = ioutil.WriteFile(e.stagedPath(digests[p]), content, 0600); err != nil {
+			return nil, nil, nil, errors.Wrap(err, "unable to write staged file")
+		}
+	}
+	return nil, nil, nil, nil
+}
+
+// testStagingSink is an io.WriteCloser that stages content for a
+// testDirectoryEndpoint, only committing it if it has the expected digest.
+type testStagingSink struct {
+	// buffer stores the received content.
+	bytes.Buffer
+	// path is the staging path.
+	path string
+	// digest is the expected digest.
+	digest []byte
+}
+
+// Close implements io.Closer.Close.
+func (s *testStagingSink) Close() error {
+	hasher := Version_Version1.Hasher()
+	hasher.Write(s.Bytes())
+	if !bytes.Equal(hasher.Sum(nil), s.digest) {
+		return nil
+	}
+	return ioutil.WriteFile(s.path, s.Bytes(), 0600)
+}
+
+// testStagingSinker is an rsync.Sinker that stages content for a
+// testDirectoryEndpoint.
+type testStagingSinker struct {
+	// endpoint is the staging endpoint.
+	endpoint *testDirectoryEndpoint
+	// digests maps paths to their expected digests.
+	digests map[string][]byte
+}
+
+// Sink implements rsync.Sinker.Sink.
+func (s *testStagingSinker) Sink(path string) (io.WriteCloser, error) {
+	digest := s.digests[path]
+	return &testStagingSink{path: s.endpoint.stagedPath(digest), digest: digest}, nil
+}
+
+// stageViaSupply implements Stage for endpoints with staging via supplying
+// enabled, requesting all files that haven't already been staged.
+func (e *testDirectoryEndpoint) stageViaSupply(paths []string, digests [][]byte) ([]string, []*rsync.Signature, rsync.Receiver, error) {
+	var filteredPaths []string
+	var signatures []*rsync.Signature
+	sinker := &testStagingSinker{endpoint: e, digests: make(map[string][]byte)}
+	for p, path := range paths {
+		if _, err := os.Lstat(e.stagedPath(digests[p])); err == nil {
+			continue
+		}
+		filteredPaths = append(filteredPaths, path)
+		signatures = append(signatures, &rsync.Signature{})
+		sinker.digests[path] = digests[p]
+	}
+	if len(filteredPaths) == 0 {
+		return nil, nil, nil, nil
+	}
+	receiver, err := rsync.NewReceiver(e.root, filteredPaths, signatures, sinker)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "unable to create receiver")
+	}
+	return filteredPaths, signatures, receiver, nil
+}
+
+// Supply implements Endpoint.Supply.
+func (e *testDirectoryEndpoint) Supply(paths []string, signatures []*rsync.Signature, receiver rsync.Receiver, budget *rsync.TransmissionBudget) error {
+	counter := rsync.NewCountingReceiver(receiver)
+	if err := rsync.Transmit(e.root, paths, signatures, counter, 0, nil, budget); err != nil {
+		return err
+	}
+	e.supplied = append(e.supplied, counter.Received())
+	return nil
+}
+
+// AvailableSpace implements Endpoint.AvailableSpace.
+func (e *testDirectoryEndpoint) AvailableSpace() (uint64, error) {
+	if e.availableSpace == nil {
+		return math.MaxUint64, nil
+	}
+	return e.availableSpace(), nil
+}
+
+// Measure implements Endpoint.Measure.
+func (e *testDirectoryEndpoint) Measure(paths []string) (uint64, error) {
+	var result uint64
+	for _, path := range paths {
+		if metadata, err := os.Lstat(filepath.Join(e.root, path)); err == nil {
+			result += uint64(metadata.Size())
+		}
+	}
+	return result, nil
+}
+
+// Provide implements core.Provider.Provide.
+func (e *testDirectoryEndpoint) Provide(_ string, digest []byte) (string, error) {
+	path := e.stagedPath(digest)
+	if _, err := os.Lstat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Transition implements Endpoint.Transition.
+func (e *testDirectoryEndpoint) Transition(ctx context.Context, transitions []*core.Change) ([]*core.Entry, []*core.Problem, bool, error) {
+	if e.beforeTransition != nil {
+		e.beforeTransition(ctx)
+	}
+	e.transitions = append(e.transitions, transitions)
+	attempted := transitions
+	var failed map[int]bool
+	if e.failTransition != nil {
+		attempted = nil
+		failed = make(map[int]bool)
+		for t, transition := range transitions {
+			if e.failTransition(transition.Path) {
+				failed[t] = true
+			} else {
+				attempted = append(attempted, transition)
+			}
+		}
+	}
+	results, problems, missingFiles := core.Transition(
+		ctx,
+		e.root,
+		attempted,
+		e.cache,
+		core.SymlinkMode_SymlinkModePortable,
+		Version_Version1.DefaultFileMode(),
+		Version_Version1.DefaultDirectoryMode(),
+		nil,
+		false,
+		e,
+		&core.TransitionOptions{
+			DurabilityMode: core.DurabilityMode_DurabilityModeFull,
+			Syncer:         filesystem.SystemSyncer,
+			ACLMode:        core.ACLMode_ACLModeIgnore,
+		},
+	)
+	if len(failed) > 0 {
+		merged := make([]*core.Entry, len(transitions))
+		var r int
+		for t, transition := range transitions {
+			if failed[t] {
+				merged[t] = transition.Old
+				problems = append(problems, &core.Problem{Path: transition.Path, Error: "injected transition failure"})
+			} else {
+				merged[t] = results[r]
+				r++
+			}
+		}
+		results = merged
+	}
+	return results, problems, missingFiles, nil
+}
+
+// Shutdown implements Endpoint.Shutdown.
+func (e *testDirectoryEndpoint) Shutdown() error {
+	return nil
+}
+
+// testController creates a running controller that synchronizes the specified
+// content from an alpha directory to an empty beta directory using the
+// specified ignores, invoking the specified callback at the start of the beta
+// transition. It returns the controller, the temporary directory containing all
+// test content (which the caller should remove), and the endpoints.
+func testController(
+	t *testing.T,
+	content map[string][]byte,
+	ignores []string,
+	beforeTransition func(context.Context),
+) (*controller, string, *testDirectoryEndpoint, *testDirectoryEndpoint) {
+	return testControllerWithConfiguration(t, &Configuration{}, content, ignores, beforeTransition)
+}
+
+// testControllerWithConfiguration is a variant of testController that uses the
+// specified session configuration.
+func testControllerWithConfiguration(
+	t *testing.T,
+	configuration *Configuration,
+	content map[string][]byte,
+	ignores []string,
+	beforeTransition func(context.Context),
+) (*controller, string, *testDirectoryEndpoint, *testDirectoryEndpoint) {
+	return testControllerWithSetup(t, configuration, content, ignores, beforeTransition, nil)
+}
+
+// testControllerWithSetup is a variant of testControllerWithConfiguration that
+// invokes the specified callback (if non-nil) to configure the endpoints before
+// the synchronization loop is started.
+func testControllerWithSetup(
+	t *testing.T,
+	configuration *Configuration,
+	content map[string][]byte,
+	ignores []string,
+	beforeTransition func(context.Context),
+	setup func(alpha, beta *testDirectoryEndpoint),
+) (*controller, string, *testDirectoryEndpoint, *testDirectoryEndpoint) {
+	// Create a temporary directory to hold all test content.
+	parent, err := ioutil.TempDir("", "mutagen_controller")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+
+	// Create endpoint directories and alpha content.
+	alphaRoot := filepath.Join(parent, "alpha")
+	betaRoot := filepath.Join(parent, "beta")
+	staging := filepath.Join(parent, "staging")
+	for _, directory := range []string{alphaRoot, betaRoot, staging} {
+		if err := os.Mkdir(directory, 0700); err != nil {
+			os.RemoveAll(parent)
+			t.Fatal("unable to create directory:", err)
+		}
+	}
+	for name, data := range content {
+		if err := ioutil.WriteFile(filepath.Join(alphaRoot, name), data, 0600); err != nil {
+			os.RemoveAll(parent)
+			t.Fatal("unable to create alpha content:", err)
+		}
+	}
+
+	// Create an empty archive.
+	archivePath := filepath.Join(parent, "archive")
+	if err := encoding.MarshalAndSaveProtobuf(archivePath, &core.Archive{}); err != nil {
+		os.RemoveAll(parent)
+		t.Fatal("unable to save archive:", err)
+	}
+
+	// Create the controller.
+	session := &Session{
+		Identifier:         "session",
+		Version:            Version_Version1,
+		Configuration:      configuration,
+		ConfigurationAlpha: &Configuration{},
+		ConfigurationBeta:  &Configuration{},
+		BaselinePending:    configuration.BaselineStartup,
+	}
+	c := &controller{
+		logger:                   logging.RootLogger.Sublogger("test"),
+		sessionPath:              filepath.Join(parent, "session"),
+		archivePath:              archivePath,
+		stateLock:                state.NewTrackingLock(state.NewTracker()),
+		session:                  session,
+		mergedAlphaConfiguration: &Configuration{},
+		mergedBetaConfiguration:  &Configuration{},
+		state: &State{
+			Session: session,
+		},
+	}
+
+	// Start the synchronization loop.
+	alpha := &testDirectoryEndpoint{
+		root:    alphaRoot,
+		source:  alphaRoot,
+		staging: staging,
+		ignores: ignores,
+	}
+	beta := &testDirectoryEndpoint{
+		root:             betaRoot,
+		source:           alphaRoot,
+		staging:          staging,
+		ignores:          ignores,
+		beforeTransition: beforeTransition,
+	}
+	if setup != nil {
+		setup(alpha, beta)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopCtx, stop := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.stop = stop
+	c.flushRequests = make(chan *controllerFlushRequest, 1)
+	c.reconnectRequests = make(chan chan struct{})
+	c.done = make(chan struct{})
+	go c.run(ctx, stopCtx, alpha, beta, nil)
+
+	// Done.
+	return c, parent, alpha, beta
+}
+
+// testShutdownController creates a running controller for shutdown tests that
+// synchronizes testShutdownContent, invoking the specified callback at the
+// start of the beta transition. It returns the controller, the temporary
+// directory containing all test content (which the caller should remove), and
+// the beta root.
+func testShutdownController(t *testing.T, beforeTransition func(context.Context)) (*controller, string, string) {
+	c, parent, _, beta := testController(t, testShutdownContent, nil, beforeTransition)
+	return c, parent, beta.root
+}
+
+// waitForSynchronizationCycles waits for the controller to complete at least
+// the specified number of synchronization cycles and return to watching.
+func waitForSynchronizationCycles(t *testing.T, c *controller, cycles uint64) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Poll the controller state.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if s := c.currentState(); s.SuccessfulSynchronizationCycles >= cycles && s.Status == Status_Watching {
+			return
+		} else if time.Now().After(deadline) {
+			t.Fatal("synchronization cycle didn't complete")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// verifyNoPartialFiles verifies that every file in the specified root has
+// complete test content and that no temporary files remain. It returns the
+// number of files found.
+func verifyNoPartialFiles(t *testing.T, root string) int {
+	contents, err := ioutil.ReadDir(root)
+	if err != nil {
+		t.Fatal("unable to read root contents:", err)
+	}
+	for _, c := range contents {
+		if strings.HasPrefix(c.Name(), filesystem.TemporaryNamePrefix) {
+			t.Error("temporary file remains:", c.Name())
+		} else if expected, ok := testShutdownContent[c.Name()]; !ok {
+			t.Error("unexpected file:", c.Name())
+		} else if content, err := ioutil.ReadFile(filepath.Join(root, c.Name())); err != nil {
+			t.Error("unable to read file:", err)
+		} else if !bytes.Equal(content, expected) {
+			t.Error("partially written file:", c.Name())
+		}
+	}
+	return len(contents)
+}
+
+// TestControllerShutdownCompletesTransition tests that a graceful shutdown
+// triggered mid-cycle allows the in-flight transition to complete and persists
+// its results before the synchronization loop exits.
+func TestControllerShutdownCompletesTransition(t *testing.T) {
+	// Create a controller whose beta transition blocks until released.
+	transitionStarted := make(chan struct{})
+	release := make(chan struct{})
+	c, parent, betaRoot := testShutdownController(t, func(_ context.Context) {
+		close(transitionStarted)
+		<-release
+	})
+	defer os.RemoveAll(parent)
+
+	// Wait for the transition to start and then trigger shutdown.
+	select {
+	case <-transitionStarted:
+	case <-time.After(10 * time.Second):
+		t.Fatal("transition didn't start")
+	}
+	haltErrors := make(chan error, 1)
+	go func() {
+		haltErrors <- c.halt(context.Background(), controllerHaltModeShutdown, "", false)
+	}()
+
+	// Verify that shutdown waits for the in-flight transition.
+	select {
+	case err := <-haltErrors:
+		t.Fatal("shutdown completed before in-flight transition:", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Release the transition and wait for shutdown to complete.
+	close(release)
+	select {
+	case err := <-haltErrors:
+		if err != nil {
+			t.Fatal("shutdown failed:", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("shutdown didn't complete")
+	}
+
+	// Verify that all content was transitioned completely.
+	if count := verifyNoPartialFiles(t, betaRoot); count != len(testShutdownContent) {
+		t.Error("transitioned file count incorrect:", count, "!=", len(testShutdownContent))
+	}
+
+	// Verify that the transition results were persisted to the archive.
+	archive := &core.Archive{}
+	if err := encoding.LoadAndUnmarshalChecksummedProtobuf(c.archivePath, archive); err != nil {
+		t.Fatal("unable to load archive:", err)
+	} else if archive.Root == nil {
+		t.Fatal("archive root not persisted")
+	}
+	for name := range testShutdownContent {
+		if archive.Root.Contents[name] == nil {
+			t.Error("transitioned file missing from archive:", name)
+		}
+	}
+}
+
+// TestControllerShutdownTimeout tests that a graceful shutdown falls back to
+// cancellation if the in-flight transition doesn't complete in time, and that
+// cancellation doesn't leave any partially written files.
+func TestControllerShutdownTimeout(t *testing.T) {
+	// Create a controller whose beta transition blocks until cancelled.
+	transitionStarted := make(chan struct{})
+	c, parent, betaRoot := testShutdownController(t, func(ctx context.Context) {
+		close(transitionStarted)
+		<-ctx.Done()
+	})
+	defer os.RemoveAll(parent)
+
+	// Wait for the transition to start.
+	select {
+	case <-transitionStarted:
+	case <-time.After(10 * time.Second):
+		t.Fatal("transition didn't start")
+	}
+
+	// Trigger shutdown with a short timeout and verify that it completes.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	haltErrors := make(chan error, 1)
+	go func() {
+		haltErrors <- c.halt(ctx, controllerHaltModeShutdown, "", false)
+	}()
+	select {
+	case err := <-haltErrors:
+		if err != nil {
+			t.Fatal("shutdown failed:", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("shutdown didn't fall back to cancellation")
+	}
+
+	// Verify that no partially written files remain. Since the transition
+	// was cancelled before it began, nothing should have been created.
+	if count := verifyNoPartialFiles(t, betaRoot); count != 0 {
+		t.Error("cancelled transition created files:", count)
+	}
+
+	// Verify that the archive remains valid.
+	archive := &core.Archive{}
+	if err := encoding.LoadAndUnmarshalChecksummedProtobuf(c.archivePath, archive); err != nil {
+		t.Fatal("unable to load archive:", err)
+	} else if err = archive.Root.EnsureValid(); err != nil {
+		t.Error("invalid archive after cancelled transition:", err)
+	}
+}
+
+// TestControllerShutdownWhileWatching tests that a graceful shutdown of an idle
+// session completes promptly.
+func TestControllerShutdownWhileWatching(t *testing.T) {
+	// Create a controller and wait for it to complete its initial cycle.
+	c, parent, betaRoot := testShutdownController(t, nil)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Trigger shutdown, bounded by a timeout that we can use to verify that
+	// the stop was graceful.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	} else if ctx.Err() != nil {
+		t.Error("shutdown of idle session timed out")
+	}
+
+	// Verify the synchronized content.
+	if count := verifyNoPartialFiles(t, betaRoot); count != len(testShutdownContent) {
+		t.Error("synchronized file count incorrect:", count, "!=", len(testShutdownContent))
+	}
+}
+
+// TestControllerFlushIgnoreOverrides tests that ignore overrides provided with a
+// flush request apply to the forced synchronization cycle only and that they
+// aren't persisted.
+func TestControllerFlushIgnoreOverrides(t *testing.T) {
+	// Create a controller with ignored content and wait for it to complete its
+	// initial cycle.
+	content := map[string][]byte{
+		"kept":    []byte("kept content"),
+		"ignored": []byte("ignored content"),
+	}
+	c, parent, alpha, beta := testController(t, content, []string{"ignored"}, nil)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Verify that the ignored content wasn't synchronized.
+	ignoredPath := filepath.Join(beta.root, "ignored")
+	if _, err := os.Lstat(ignoredPath); !os.IsNotExist(err) {
+		t.Fatal("ignored content synchronized without override")
+	}
+
+	// Perform a flush with an override and verify that the ignored content was
+	// synchronized.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.flush(ctx, "", false, []string{"ignored"}, false); err != nil {
+		t.Fatal("unable to flush with override:", err)
+	}
+	if data, err := ioutil.ReadFile(ignoredPath); err != nil {
+		t.Fatal("overridden content not synchronized:", err)
+	} else if !bytes.Equal(data, content["ignored"]) {
+		t.Error("overridden content incorrect")
+	}
+
+	// Modify the ignored content, perform a flush without an override, and
+	// verify that the ignored content is once again ignored (i.e. neither
+	// updated nor removed).
+	if err := ioutil.WriteFile(filepath.Join(alpha.root, "ignored"), []byte("modified"), 0600); err != nil {
+		t.Fatal("unable to modify ignored content:", err)
+	}
+	if err := c.flush(ctx, "", false, nil, false); err != nil {
+		t.Fatal("unable to flush without override:", err)
+	}
+	if data, err := ioutil.ReadFile(ignoredPath); err != nil {
+		t.Fatal("previously overridden content removed:", err)
+	} else if !bytes.Equal(data, content["ignored"]) {
+		t.Error("ignored content updated without override")
+	}
+
+	// Verify that the overrides weren't persisted.
+	if len(c.session.Configuration.Ignores) != 0 {
+		t.Error("overrides persisted to session configuration")
+	}
+	archive := &core.Archive{}
+	if err := encoding.LoadAndUnmarshalChecksummedProtobuf(c.archivePath, archive); err != nil {
+		t.Fatal("unable to load archive:", err)
+	} else if archive.Root.Contents["ignored"] != nil {
+		t.Error("overridden content remains in archive")
+	}
+
+	// Halt the controller and verify that overrides were only provided to the
+	// scans for the cycle that requested them.
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("unable to halt controller:", err)
+	}
+	for _, endpoint := range []*testDirectoryEndpoint{alpha, beta} {
+		var overridden int
+		for _, overrides := range endpoint.ignoreOverrides {
+			if len(overrides) > 0 {
+				overridden++
+			}
+		}
+		if overridden != 1 {
+			t.Error("overrides applied to incorrect number of scans:", overridden, "!=", 1)
+		}
+	}
+}
+
+// TestControllerFlushRehash tests that rehash requests provided with a flush
+// request are passed to both endpoints for the forced synchronization cycle
+// only.
+func TestControllerFlushRehash(t *testing.T) {
+	// Create a controller and wait for it to complete its initial cycle.
+	c, parent, alpha, beta := testController(t, testShutdownContent, nil, nil)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Perform a flush with a rehash and then one without.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.flush(ctx, "", false, nil, true); err != nil {
+		t.Fatal("unable to flush with rehash:", err)
+	} else if err = c.flush(ctx, "", false, nil, false); err != nil {
+		t.Fatal("unable to flush without rehash:", err)
+	}
+
+	// Halt the controller and verify that a rehash was only requested for the
+	// scans in the cycle that requested it.
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("unable to halt controller:", err)
+	}
+	for _, endpoint := range []*testDirectoryEndpoint{alpha, beta} {
+		var rehashed int
+		for _, rehash := range endpoint.rehashes {
+			if rehash {
+				rehashed++
+			}
+		}
+		if rehashed != 1 {
+			t.Error("rehash requested for incorrect number of scans:", rehashed, "!=", 1)
+		}
+	}
+}
+
+// TestControllerFlushProtectedIgnoreOverrides tests that flush requests with
+// ignore overrides that could include Mutagen's temporary files and directories
+// are refused.
+func TestControllerFlushProtectedIgnoreOverrides(t *testing.T) {
+	// Create a controller and wait for it to complete its initial cycle.
+	c, parent, _ := testShutdownController(t, nil)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Verify that protected overrides are refused without triggering a cycle.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, override := range []string{
+		filesystem.TemporaryNamePrefix + "staging-session-beta",
+		".mutagen-temporary-*",
+		"**",
+	} {
+		if err := c.flush(ctx, "", false, []string{override}, false); err == nil {
+			t.Error("protected override accepted:", override)
+		}
+	}
+	if cycles := c.currentState().SuccessfulSynchronizationCycles; cycles != 1 {
+		t.Error("refused override triggered synchronization cycle")
+	}
+
+	// Halt the controller.
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("unable to halt controller:", err)
+	}
+}
+
+// testDeletionGracePeriod is the deletion grace period used for tests.
+const testDeletionGracePeriod = 250 * time.Millisecond
+
+// TestControllerDeletionGracePeriodAtomicReplace tests that a file that
+// transiently disappears (as it would during an atomic replacement) within the
+// deletion grace period isn't deleted on the other endpoint.
+func TestControllerDeletionGracePeriodAtomicReplace(t *testing.T) {
+	// Create a controller with a deletion grace period and wait for it to
+	// complete its initial cycle.
+	configuration := &Configuration{
+		DeletionGracePeriod: uint32(testDeletionGracePeriod / time.Millisecond),
+	}
+	c, parent, alpha, beta := testControllerWithConfiguration(t, configuration, testShutdownContent, nil, nil)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Remove a file on alpha and arrange for it to be replaced immediately
+	// after the next scan (i.e. during the grace period), simulating the gap
+	// in an atomic replacement that happens to be observed by a scan.
+	path := filepath.Join(alpha.root, "first")
+	replacement := []byte("replaced first file content")
+	if err := os.Remove(path); err != nil {
+		t.Fatal("unable to remove file:", err)
+	}
+	replaced := false
+	alpha.afterScan = func() {
+		if !replaced {
+			replaced = true
+			if err := ioutil.WriteFile(path, replacement, 0600); err != nil {
+				t.Error("unable to replace file:", err)
+			}
+		}
+	}
+
+	// Perform a flush.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.flush(ctx, "", false, nil, false); err != nil {
+		t.Fatal("unable to flush:", err)
+	}
+
+	// Verify that beta received the replacement content.
+	if data, err := ioutil.ReadFile(filepath.Join(beta.root, "first")); err != nil {
+		t.Fatal("replaced file not present on beta:", err)
+	} else if !bytes.Equal(data, replacement) {
+		t.Error("replaced file content incorrect on beta")
+	}
+
+	// Verify that the file was never deleted on beta.
+	for _, transitions := range beta.transitions {
+		for _, change := range transitions {
+			if change.Path == "first" && change.New == nil {
+				t.Error("transient deletion propagated to beta")
+			}
+		}
+	}
+
+	// Halt the controller.
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("unable to halt controller:", err)
+	}
+}
+
+// TestControllerDeletionGracePeriodGenuineDeletion tests that a deletion that
+// persists past the deletion grace period is propagated.
+func TestControllerDeletionGracePeriodGenuineDeletion(t *testing.T) {
+	// Create a controller with a deletion grace period and wait for it to
+	// complete its initial cycle.
+	configuration := &Configuration{
+		DeletionGracePeriod: uint32(testDeletionGracePeriod / time.Millisecond),
+	}
+	c, parent, alpha, beta := testControllerWithConfiguration(t, configuration, testShutdownContent, nil, nil)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Remove a file on alpha and perform a flush, tracking how long it takes
+	// and how many times alpha is scanned.
+	if err := os.Remove(filepath.Join(alpha.root, "first")); err != nil {
+		t.Fatal("unable to remove file:", err)
+	}
+	scans := len(alpha.rehashes)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := c.flush(ctx, "", false, nil, false); err != nil {
+		t.Fatal("unable to flush:", err)
+	}
+	elapsed := time.Since(start)
+
+	// Verify that the deletion was held and re-checked before propagation.
+	if elapsed < testDeletionGracePeriod {
+		t.Error("deletion propagated before grace period elapsed:", elapsed)
+	}
+	if rescans := len(alpha.rehashes) - scans; rescans != 2 {
+		t.Error("unexpected number of scans:", rescans, "!=", 2)
+	}
+
+	// Verify that the deletion was propagated to beta.
+	if _, err := os.Lstat(filepath.Join(beta.root, "first")); !os.IsNotExist(err) {
+		t.Error("deletion not propagated to beta")
+	}
+
+	// Halt the controller.
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("unable to halt controller:", err)
+	}
+}
@@ -0,0 +1,285 @@
+package core
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+)
+
+const (
+	// aclEncodingVersion is the version of the Linux extended attribute
+	// encoding of POSIX ACLs.
+	aclEncodingVersion = 2
+	// aclEncodingHeaderSize is the size of the header in the Linux extended
+	// attribute encoding of POSIX ACLs.
+	aclEncodingHeaderSize = 4
+	// aclEncodingEntrySize is the size of each entry in the Linux extended
+	// attribute encoding of POSIX ACLs.
+	aclEncodingEntrySize = 8
+	// aclUndefinedIdentifier is the identifier used for entries other than
+	// named user and named group entries in the Linux extended attribute
+	// encoding of POSIX ACLs.
+	aclUndefinedIdentifier = 0xffffffff
+	// aclPermissionsMask is a mask of the valid ACL entry permission bits.
+	aclPermissionsMask = 07
+)
+
+// named indicates whether or not the tag identifies a named user or named group
+// entry.
+func (t ACLTag) named() bool {
+	return t == ACLTag_ACLTagUser || t == ACLTag_ACLTagGroup
+}
+
+// decodeACLEntries decodes ACL entries from the Linux extended attribute
+// encoding of POSIX ACLs.
+func decodeACLEntries(data []byte) ([]*ACLEntry, error) {
+	// Validate the header.
+	if len(data) < aclEncodingHeaderSize {
+		return nil, errors.New("ACL data too short")
+	} else if binary.LittleEndian.Uint32(data) != aclEncodingVersion {
+		return nil, errors.New("unknown ACL encoding version")
+	} else if (len(data)-aclEncodingHeaderSize)%aclEncodingEntrySize != 0 {
+		return nil, errors.New("ACL data has invalid length")
+	}
+
+	// Decode entries.
+	data = data[aclEncodingHeaderSize:]
+	entries := make([]*ACLEntry, 0, len(data)/aclEncodingEntrySize)
+	for ; len(data) > 0; data = data[aclEncodingEntrySize:] {
+		entry := &ACLEntry{
+			Tag:         ACLTag(binary.LittleEndian.Uint16(data)),
+			Permissions: uint32(binary.LittleEndian.Uint16(data[2:])),
+		}
+		if entry.Tag.named() {
+			entry.Identifier = binary.LittleEndian.Uint32(data[4:])
+		}
+		entries = append(entries, entry)
+	}
+
+	// Validate the result.
+	if err := ensureACLEntriesValid(entries); err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return entries, nil
+}
+
+// encodeACLEntries encodes ACL entries using the Linux extended attribute
+// encoding of POSIX ACLs.
+func encodeACLEntries(entries []*ACLEntry) []byte {
+	// Allocate the result.
+	result := make([]byte, aclEncodingHeaderSize+len(entries)*aclEncodingEntrySize)
+
+	// Encode the header.
+	binary.LittleEndian.PutUint32(result, aclEncodingVersion)
+
+	// Encode entries.
+	data := result[aclEncodingHeaderSize:]
+	for _, entry := range entries {
+		binary.LittleEndian.PutUint16(data, uint16(entry.Tag))
+		binary.LittleEndian.PutUint16(data[2:], uint16(entry.Permissions))
+		if entry.Tag.named() {
+			binary.LittleEndian.PutUint32(data[4:], entry.Identifier)
+		} else {
+			binary.LittleEndian.PutUint32(data[4:], aclUndefinedIdentifier)
+		}
+		data = data[aclEncodingEntrySize:]
+	}
+
+	// Done.
+	return result
+}
+
+// ensureACLEntriesValid ensures that a list of ACL entries forms a valid POSIX
+// ACL. The validation rules mirror those enforced by the Linux kernel: entries
+// must be sorted by tag (and by identifier for named entries), the owner, group,
+// and other entries must each appear exactly once, and a mask entry must be
+// present if named entries are present.
+func ensureACLEntriesValid(entries []*ACLEntry) error {
+	var previous *ACLEntry
+	var userObject, groupObject, other, mask, named int
+	for _, entry := range entries {
+		// Ensure that the entry is valid.
+		switch entry.Tag {
+		case ACLTag_ACLTagUserObject:
+			userObject++
+		case ACLTag_ACLTagUser, ACLTag_ACLTagGroup:
+			named++
+		case ACLTag_ACLTagGroupObject:
+			groupObject++
+		case ACLTag_ACLTagMask:
+			mask++
+		case ACLTag_ACLTagOther:
+			other++
+		default:
+			return errors.New("unknown ACL entry tag")
+		}
+		if entry.Permissions&aclPermissionsMask != entry.Permissions {
+			return errors.New("invalid ACL entry permissions")
+		} else if !entry.Tag.named() && entry.Identifier != 0 {
+			return errors.New("identifier specified for unnamed ACL entry")
+		}
+
+		// Ensure that the entry is correctly ordered.
+		if previous != nil {
+			if entry.Tag < previous.Tag {
+				return errors.New("ACL entries not sorted by tag")
+			} else if entry.Tag == previous.Tag && (!entry.Tag.named() || entry.Identifier <= previous.Identifier) {
+				return errors.New("duplicate ACL entry")
+			}
+		}
+		previous = entry
+	}
+
+	// Ensure that the required entries are present.
+	if userObject != 1 || groupObject != 1 || other != 1 {
+		return errors.New("ACL missing required entries")
+	} else if mask > 1 {
+		return errors.New("duplicate ACL mask entry")
+	} else if named > 0 && mask == 0 {
+		return errors.New("ACL with named entries missing mask entry")
+	}
+
+	// Success.
+	return nil
+}
+
+// EnsureValid ensures that ACL's invariants are respected. The directory
+// argument indicates whether or not the ACL belongs to a directory.
+func (a *ACL) EnsureValid(directory bool) error {
+	// A nil ACL is valid.
+	if a == nil {
+		return nil
+	}
+
+	// Validate the access ACL.
+	if len(a.AccessEntries) > 0 {
+		if err := ensureACLEntriesValid(a.AccessEntries); err != nil {
+			return errors.Wrap(err, "invalid access ACL")
+		}
+	}
+
+	// Validate the default ACL.
+	if len(a.DefaultEntries) > 0 {
+		if !directory {
+			return errors.New("default ACL specified for non-directory")
+		} else if err := ensureACLEntriesValid(a.DefaultEntries); err != nil {
+			return errors.Wrap(err, "invalid default ACL")
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// accessEntriesWithMode returns a copy of the access ACL entries with the owner
+// and other entries set to reflect the specified permission mode. This allows
+// POSIX ACLs to compose with Mutagen's permission handling: the owner and other
+// classes are governed by Mutagen's permission mode (including executability),
+// while the group class is governed by the ACL. If the ACL doesn't have a mask
+// entry, then the group entry is also set to reflect the permission mode, since
+// it corresponds to the group class bits of the mode.
+func (a *ACL) accessEntriesWithMode(mode filesystem.Mode) []*ACLEntry {
+	// Determine whether or not the ACL has a mask entry.
+	var masked bool
+	for _, entry := range a.AccessEntries {
+		if entry.Tag == ACLTag_ACLTagMask {
+			masked = true
+			break
+		}
+	}
+
+	// Create the result.
+	result := make([]*ACLEntry, len(a.AccessEntries))
+	for e, entry := range a.AccessEntries {
+		permissions := entry.Permissions
+		switch entry.Tag {
+		case ACLTag_ACLTagUserObject:
+			permissions = uint32(mode>>6) & aclPermissionsMask
+		case ACLTag_ACLTagGroupObject:
+			if !masked {
+				permissions = uint32(mode>>3) & aclPermissionsMask
+			}
+		case ACLTag_ACLTagOther:
+			permissions = uint32(mode) & aclPermissionsMask
+		}
+		result[e] = &ACLEntry{
+			Tag:         entry.Tag,
+			Identifier:  entry.Identifier,
+			Permissions: permissions,
+		}
+	}
+
+	// Done.
+	return result
+}
+
+// readACL reads the POSIX ACLs for the entry at the specified path. If the
+// entry doesn't have ACLs that can't be represented by its permission mode
+// bits, or if ACLs aren't supported by the underlying platform or filesystem,
+// then it returns nil.
+func readACL(path string, directory bool) (*ACL, error) {
+	// Read the access ACL.
+	access, err := filesystem.ReadACL(path, filesystem.ACLKindAccess)
+	if err == filesystem.ErrACLsUnsupported {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "unable to read access ACL")
+	}
+
+	// If this is a directory, then read the default ACL.
+	var defaults []byte
+	if directory {
+		if defaults, err = filesystem.ReadACL(path, filesystem.ACLKindDefault); err != nil {
+			return nil, errors.Wrap(err, "unable to read default ACL")
+		}
+	}
+
+	// If neither ACL is present, then there's nothing to record.
+	if access == nil && defaults == nil {
+		return nil, nil
+	}
+
+	// Decode the ACLs.
+	result := &ACL{}
+	if access != nil {
+		if result.AccessEntries, err = decodeACLEntries(access); err != nil {
+			return nil, errors.Wrap(err, "unable to decode access ACL")
+		}
+	}
+	if defaults != nil {
+		if result.DefaultEntries, err = decodeACLEntries(defaults); err != nil {
+			return nil, errors.Wrap(err, "unable to decode default ACL")
+		}
+	}
+
+	// Success.
+	return result, nil
+}
+
+// writeACL sets the POSIX ACLs for the entry at the specified path, with the
+// owner and other entries of the access ACL set to reflect the specified
+// permission mode.
+func writeACL(path string, acl *ACL, mode filesystem.Mode) error {
+	// Set the access ACL.
+	if len(acl.AccessEntries) > 0 {
+		encoded := encodeACLEntries(acl.accessEntriesWithMode(mode))
+		if err := filesystem.WriteACL(path, filesystem.ACLKindAccess, encoded); err != nil {
+			return errors.Wrap(err, "unable to set access ACL")
+		}
+	}
+
+	// Set the default ACL.
+	if len(acl.DefaultEntries) > 0 {
+		encoded := encodeACLEntries(acl.DefaultEntries)
+		if err := filesystem.WriteACL(path, filesystem.ACLKindDefault, encoded); err != nil {
+			return errors.Wrap(err, "unable to set default ACL")
+		}
+	}
+
+	// Success.
+	return nil
+}
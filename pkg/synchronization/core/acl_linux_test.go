@@ -0,0 +1,184 @@
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+// testDirectoryDefaultACLEntries is a list of default ACL entries for use in
+// directory ACL tests.
+var testDirectoryDefaultACLEntries = []*ACLEntry{
+	{Tag: ACLTag_ACLTagUserObject, Permissions: 7},
+	{Tag: ACLTag_ACLTagUser, Identifier: 1000, Permissions: 7},
+	{Tag: ACLTag_ACLTagGroupObject, Permissions: 5},
+	{Tag: ACLTag_ACLTagMask, Permissions: 7},
+	{Tag: ACLTag_ACLTagOther, Permissions: 5},
+}
+
+// testSetACL sets the ACL of the specified kind on the specified path, skipping
+// the test if the filesystem doesn't support ACLs.
+func testSetACL(t *testing.T, path string, kind filesystem.ACLKind, entries []*ACLEntry) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Set the ACL.
+	if err := filesystem.WriteACL(path, kind, encodeACLEntries(entries)); err == filesystem.ErrACLsUnsupported {
+		t.Skip("POSIX ACLs not supported by temporary directory filesystem")
+	} else if err != nil {
+		t.Fatal("unable to set ACL:", err)
+	}
+}
+
+// TestACLScanTransitionRoundTrip tests that POSIX ACLs are captured by Scan and
+// restored by Transition when ACL propagation is enabled.
+func TestACLScanTransitionRoundTrip(t *testing.T) {
+	// Create a temporary directory to hold all test content and defer its
+	// removal.
+	parent, err := ioutil.TempDir("", "mutagen_acl")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Create source content.
+	source := filepath.Join(parent, "source")
+	contentMap := map[string][]byte{
+		"file":           []byte("file content"),
+		"directory/file": []byte("nested file content"),
+	}
+	if err := os.MkdirAll(filepath.Join(source, "directory"), 0700); err != nil {
+		t.Fatal("unable to create source directories:", err)
+	}
+	for path, content := range contentMap {
+		if err := ioutil.WriteFile(filepath.Join(source, path), content, 0600); err != nil {
+			t.Fatal("unable to create source file:", err)
+		}
+	}
+
+	// Set ACLs on the source content.
+	testSetACL(t, filepath.Join(source, "file"), filesystem.ACLKindAccess, testACLEntriesWithNamedUser)
+	testSetACL(t, filepath.Join(source, "directory"), filesystem.ACLKindAccess, testACLEntriesWithNamedUser)
+	testSetACL(t, filepath.Join(source, "directory"), filesystem.ACLKindDefault, testDirectoryDefaultACLEntries)
+
+	// Verify that ACLs aren't captured when ACL propagation is disabled.
+	snapshot, _, _, _, _, _, err := Scan(
+		context.Background(),
+		source,
+		nil, nil,
+		newTestHasher(), nil,
+		nil, nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	} else if snapshot.Contents["file"].Acl != nil {
+		t.Error("ACL captured with ACL propagation disabled")
+	}
+
+	// Perform a scan with ACL propagation enabled.
+	snapshot, _, _, _, _, _, err = Scan(
+		context.Background(),
+		source,
+		nil, nil,
+		newTestHasher(), nil,
+		nil, nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModePropagate,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	} else if err = snapshot.EnsureValid(); err != nil {
+		t.Fatal("scan with ACLs produced invalid snapshot:", err)
+	}
+
+	// Verify that ACLs were captured.
+	fileACL := snapshot.Contents["file"].Acl
+	if fileACL == nil {
+		t.Fatal("file ACL not captured")
+	} else if !testACLEntriesEqual(fileACL.AccessEntries, testACLEntriesWithNamedUser) {
+		t.Error("captured file access ACL does not match expected")
+	} else if len(fileACL.DefaultEntries) > 0 {
+		t.Error("default ACL captured for file")
+	}
+	directoryACL := snapshot.Contents["directory"].Acl
+	if directoryACL == nil {
+		t.Fatal("directory ACL not captured")
+	} else if !testACLEntriesEqual(directoryACL.AccessEntries, testACLEntriesWithNamedUser) {
+		t.Error("captured directory access ACL does not match expected")
+	} else if !testACLEntriesEqual(directoryACL.DefaultEntries, testDirectoryDefaultACLEntries) {
+		t.Error("captured directory default ACL does not match expected")
+	}
+	if snapshot.Contents["directory"].Contents["file"].Acl != nil {
+		t.Error("ACL captured for file without extended ACL")
+	}
+
+	// Create a provider and defer its cleanup.
+	provider, err := newTestProvider(contentMap, newTestHasher())
+	if err != nil {
+		t.Fatal("unable to create test provider:", err)
+	}
+	defer provider.finalize()
+
+	// Transition the snapshot to a new location.
+	target := filepath.Join(parent, "target")
+	if _, problems, providerMissingFiles := Transition(
+		context.Background(),
+		target,
+		[]*Change{{New: snapshot}},
+		nil,
+		SymlinkMode_SymlinkModePortable,
+		defaultFilePermissionMode,
+		defaultDirectoryPermissionMode,
+		nil,
+		false,
+		provider,
+		&TransitionOptions{
+			DurabilityMode: DurabilityMode_DurabilityModeNone,
+			Syncer:         filesystem.SystemSyncer,
+			ACLMode:        ACLMode_ACLModePropagate,
+		},
+	); len(problems) != 0 {
+		t.Fatal("problems occurred during transition:", problems[0].Error)
+	} else if providerMissingFiles {
+		t.Fatal("provider missing files during transition")
+	}
+
+	// Verify that ACLs were restored, with the owner and other entries
+	// governed by the default permission modes.
+	if acl, err := readACL(filepath.Join(target, "file"), false); err != nil {
+		t.Fatal("unable to read restored file ACL:", err)
+	} else if acl == nil {
+		t.Error("file ACL not restored")
+	} else if !testACLEntriesEqual(acl.AccessEntries, fileACL.accessEntriesWithMode(defaultFilePermissionMode)) {
+		t.Error("restored file access ACL does not match expected")
+	}
+	if acl, err := readACL(filepath.Join(target, "directory"), true); err != nil {
+		t.Fatal("unable to read restored directory ACL:", err)
+	} else if acl == nil {
+		t.Error("directory ACL not restored")
+	} else if !testACLEntriesEqual(acl.AccessEntries, directoryACL.accessEntriesWithMode(defaultDirectoryPermissionMode)) {
+		t.Error("restored directory access ACL does not match expected")
+	} else if !testACLEntriesEqual(acl.DefaultEntries, testDirectoryDefaultACLEntries) {
+		t.Error("restored directory default ACL does not match expected")
+	}
+	if acl, err := readACL(filepath.Join(target, "directory", "file"), false); err != nil {
+		t.Fatal("unable to read nested file ACL:", err)
+	} else if acl != nil {
+		t.Error("ACL present on nested file without extended ACL")
+	}
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"github.com/pkg/errors"
+)
+
+// IsDefault indicates whether or not the ACL mode is ACLMode_ACLModeDefault.
+func (m ACLMode) IsDefault() bool {
+	return m == ACLMode_ACLModeDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (m *ACLMode) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to an ACL mode.
+	switch text {
+	case "ignore":
+		*m = ACLMode_ACLModeIgnore
+	case "propagate":
+		*m = ACLMode_ACLModePropagate
+	default:
+		return errors.Errorf("unknown ACL mode specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular ACL mode is a valid,
+// non-default value.
+func (m ACLMode) Supported() bool {
+	switch m {
+	case ACLMode_ACLModeIgnore:
+		return true
+	case ACLMode_ACLModePropagate:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of an ACL mode.
+func (m ACLMode) Description() string {
+	switch m {
+	case ACLMode_ACLModeDefault:
+		return "Default"
+	case ACLMode_ACLModeIgnore:
+		return "Ignore"
+	case ACLMode_ACLModePropagate:
+		return "Propagate"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"testing"
+)
+
+// TestACLModeUnmarshal tests that unmarshaling from a string
+// specification succeeeds for ACLMode.
+func TestACLModeUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text          string
+		expectedMode  ACLMode
+		expectFailure bool
+	}{
+		{"", ACLMode_ACLModeDefault, true},
+		{"asdf", ACLMode_ACLModeDefault, true},
+		{"ignore", ACLMode_ACLModeIgnore, false},
+		{"propagate", ACLMode_ACLModePropagate, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var mode ACLMode
+		if err := mode.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if mode != testCase.expectedMode {
+			t.Errorf(
+				"unmarshaled mode (%s) does not match expected (%s)",
+				mode,
+				testCase.expectedMode,
+			)
+		}
+	}
+}
+
+// TestACLModeSupported tests that ACLMode support detection works
+// as expected.
+func TestACLModeSupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode            ACLMode
+		expectSupported bool
+	}{
+		{ACLMode_ACLModeDefault, false},
+		{ACLMode_ACLModeIgnore, true},
+		{ACLMode_ACLModePropagate, true},
+		{(ACLMode_ACLModePropagate + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.mode.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"mode support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestACLModeDescription tests that ACLMode description
+// generation works as expected.
+func TestACLModeDescription(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                ACLMode
+		expectedDescription string
+	}{
+		{ACLMode_ACLModeDefault, "Default"},
+		{ACLMode_ACLModeIgnore, "Ignore"},
+		{ACLMode_ACLModePropagate, "Propagate"},
+		{(ACLMode_ACLModePropagate + 1), "Unknown"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if description := testCase.mode.Description(); description != testCase.expectedDescription {
+			t.Errorf(
+				"mode description (%s) does not match expected (%s)",
+				description,
+				testCase.expectedDescription,
+			)
+		}
+	}
+}
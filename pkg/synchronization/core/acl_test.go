@@ -0,0 +1,228 @@
+package core
+
+import (
+	"testing"
+)
+
+// testACLEntriesEqual determines whether or not two lists of ACL entries are
+// equal.
+func testACLEntriesEqual(first, second []*ACLEntry) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	for e, entry := range first {
+		if entry.Tag != second[e].Tag ||
+			entry.Identifier != second[e].Identifier ||
+			entry.Permissions != second[e].Permissions {
+			return false
+		}
+	}
+	return true
+}
+
+// testACLEntriesWithNamedUser is a valid list of ACL entries containing named
+// user and group entries. Its mask doesn't grant execute permissions, so files
+// with this ACL won't be considered executable.
+var testACLEntriesWithNamedUser = []*ACLEntry{
+	{Tag: ACLTag_ACLTagUserObject, Permissions: 6},
+	{Tag: ACLTag_ACLTagUser, Identifier: 1000, Permissions: 6},
+	{Tag: ACLTag_ACLTagUser, Identifier: 1001, Permissions: 4},
+	{Tag: ACLTag_ACLTagGroupObject, Permissions: 4},
+	{Tag: ACLTag_ACLTagGroup, Identifier: 100, Permissions: 4},
+	{Tag: ACLTag_ACLTagMask, Permissions: 6},
+	{Tag: ACLTag_ACLTagOther, Permissions: 0},
+}
+
+func TestACLEncodingRoundTrip(t *testing.T) {
+	encoded := encodeACLEntries(testACLEntriesWithNamedUser)
+	if len(encoded) != aclEncodingHeaderSize+len(testACLEntriesWithNamedUser)*aclEncodingEntrySize {
+		t.Fatal("encoded ACL has incorrect length:", len(encoded))
+	}
+	if decoded, err := decodeACLEntries(encoded); err != nil {
+		t.Fatal("unable to decode ACL entries:", err)
+	} else if !testACLEntriesEqual(decoded, testACLEntriesWithNamedUser) {
+		t.Error("decoded ACL entries do not match original")
+	}
+}
+
+func TestACLDecodingInvalid(t *testing.T) {
+	// Set up test cases.
+	valid := encodeACLEntries(testACLEntriesWithNamedUser)
+	badVersion := append([]byte{}, valid...)
+	badVersion[0] = 1
+	testCases := [][]byte{
+		nil,
+		valid[:2],
+		valid[:len(valid)-1],
+		badVersion,
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		if _, err := decodeACLEntries(testCase); err == nil {
+			t.Errorf("test case %d: invalid ACL data decoded successfully", i)
+		}
+	}
+}
+
+func TestACLEntriesValidation(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		entries     []*ACLEntry
+		expectValid bool
+	}{
+		{testACLEntriesWithNamedUser, true},
+		{
+			[]*ACLEntry{
+				{Tag: ACLTag_ACLTagUserObject, Permissions: 7},
+				{Tag: ACLTag_ACLTagGroupObject, Permissions: 5},
+				{Tag: ACLTag_ACLTagOther, Permissions: 5},
+			},
+			true,
+		},
+		{
+			[]*ACLEntry{
+				{Tag: ACLTag_ACLTagUserObject, Permissions: 7},
+				{Tag: ACLTag_ACLTagGroupObject, Permissions: 5},
+				{Tag: ACLTag_ACLTagMask, Permissions: 5},
+				{Tag: ACLTag_ACLTagOther, Permissions: 5},
+			},
+			true,
+		},
+		{nil, false},
+		{
+			[]*ACLEntry{
+				{Tag: ACLTag_ACLTagUserObject, Permissions: 7},
+				{Tag: ACLTag_ACLTagOther, Permissions: 5},
+			},
+			false,
+		},
+		{
+			[]*ACLEntry{
+				{Tag: ACLTag_ACLTagGroupObject, Permissions: 5},
+				{Tag: ACLTag_ACLTagUserObject, Permissions: 7},
+				{Tag: ACLTag_ACLTagOther, Permissions: 5},
+			},
+			false,
+		},
+		{
+			[]*ACLEntry{
+				{Tag: ACLTag_ACLTagUserObject, Permissions: 7},
+				{Tag: ACLTag_ACLTagUser, Identifier: 1000, Permissions: 6},
+				{Tag: ACLTag_ACLTagGroupObject, Permissions: 5},
+				{Tag: ACLTag_ACLTagOther, Permissions: 5},
+			},
+			false,
+		},
+		{
+			[]*ACLEntry{
+				{Tag: ACLTag_ACLTagUserObject, Permissions: 7},
+				{Tag: ACLTag_ACLTagUser, Identifier: 1000, Permissions: 6},
+				{Tag: ACLTag_ACLTagUser, Identifier: 1000, Permissions: 4},
+				{Tag: ACLTag_ACLTagGroupObject, Permissions: 5},
+				{Tag: ACLTag_ACLTagMask, Permissions: 7},
+				{Tag: ACLTag_ACLTagOther, Permissions: 5},
+			},
+			false,
+		},
+		{
+			[]*ACLEntry{
+				{Tag: ACLTag_ACLTagUserObject, Permissions: 8},
+				{Tag: ACLTag_ACLTagGroupObject, Permissions: 5},
+				{Tag: ACLTag_ACLTagOther, Permissions: 5},
+			},
+			false,
+		},
+		{
+			[]*ACLEntry{
+				{Tag: ACLTag_ACLTagUserObject, Identifier: 1000, Permissions: 7},
+				{Tag: ACLTag_ACLTagGroupObject, Permissions: 5},
+				{Tag: ACLTag_ACLTagOther, Permissions: 5},
+			},
+			false,
+		},
+		{
+			[]*ACLEntry{
+				{Tag: ACLTag_ACLTagUserObject, Permissions: 7},
+				{Tag: ACLTag(3), Permissions: 5},
+				{Tag: ACLTag_ACLTagGroupObject, Permissions: 5},
+				{Tag: ACLTag_ACLTagOther, Permissions: 5},
+			},
+			false,
+		},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		err := ensureACLEntriesValid(testCase.entries)
+		if err != nil && testCase.expectValid {
+			t.Errorf("test case %d: valid ACL entries failed validation: %v", i, err)
+		} else if err == nil && !testCase.expectValid {
+			t.Errorf("test case %d: invalid ACL entries passed validation", i)
+		}
+	}
+}
+
+func TestACLEnsureValid(t *testing.T) {
+	// Verify that a nil ACL is valid.
+	var acl *ACL
+	if err := acl.EnsureValid(false); err != nil {
+		t.Error("nil ACL failed validation:", err)
+	}
+
+	// Verify that default ACLs are only allowed for directories.
+	acl = &ACL{
+		AccessEntries:  testACLEntriesWithNamedUser,
+		DefaultEntries: testACLEntriesWithNamedUser,
+	}
+	if err := acl.EnsureValid(true); err != nil {
+		t.Error("valid directory ACL failed validation:", err)
+	}
+	if err := acl.EnsureValid(false); err == nil {
+		t.Error("file ACL with default entries passed validation")
+	}
+
+	// Verify that invalid access entries are rejected.
+	acl = &ACL{AccessEntries: testACLEntriesWithNamedUser[:2]}
+	if err := acl.EnsureValid(false); err == nil {
+		t.Error("ACL with invalid access entries passed validation")
+	}
+}
+
+func TestACLAccessEntriesWithMode(t *testing.T) {
+	// Verify that owner and other permissions are taken from the mode while
+	// masked group permissions are retained.
+	acl := &ACL{AccessEntries: testACLEntriesWithNamedUser}
+	expected := []*ACLEntry{
+		{Tag: ACLTag_ACLTagUserObject, Permissions: 7},
+		{Tag: ACLTag_ACLTagUser, Identifier: 1000, Permissions: 6},
+		{Tag: ACLTag_ACLTagUser, Identifier: 1001, Permissions: 4},
+		{Tag: ACLTag_ACLTagGroupObject, Permissions: 4},
+		{Tag: ACLTag_ACLTagGroup, Identifier: 100, Permissions: 4},
+		{Tag: ACLTag_ACLTagMask, Permissions: 6},
+		{Tag: ACLTag_ACLTagOther, Permissions: 1},
+	}
+	if entries := acl.accessEntriesWithMode(0721); !testACLEntriesEqual(entries, expected) {
+		t.Error("masked ACL entries do not match expected")
+	}
+
+	// Verify that unmasked group permissions are taken from the mode.
+	acl = &ACL{AccessEntries: []*ACLEntry{
+		{Tag: ACLTag_ACLTagUserObject, Permissions: 6},
+		{Tag: ACLTag_ACLTagGroupObject, Permissions: 4},
+		{Tag: ACLTag_ACLTagOther, Permissions: 4},
+	}}
+	expected = []*ACLEntry{
+		{Tag: ACLTag_ACLTagUserObject, Permissions: 7},
+		{Tag: ACLTag_ACLTagGroupObject, Permissions: 5},
+		{Tag: ACLTag_ACLTagOther, Permissions: 0},
+	}
+	if entries := acl.accessEntriesWithMode(0750); !testACLEntriesEqual(entries, expected) {
+		t.Error("unmasked ACL entries do not match expected")
+	}
+
+	// Verify that the original entries weren't modified.
+	if acl.AccessEntries[0].Permissions != 6 {
+		t.Error("original ACL entries modified")
+	}
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"github.com/pkg/errors"
+)
+
+// IsDefault indicates whether or not the broken symlink mode is
+// BrokenSymlinkMode_BrokenSymlinkModeDefault.
+func (m BrokenSymlinkMode) IsDefault() bool {
+	return m == BrokenSymlinkMode_BrokenSymlinkModeDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (m *BrokenSymlinkMode) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to a broken symlink mode.
+	switch text {
+	case "sync":
+		*m = BrokenSymlinkMode_BrokenSymlinkModeSync
+	case "skip":
+		*m = BrokenSymlinkMode_BrokenSymlinkModeSkip
+	case "error":
+		*m = BrokenSymlinkMode_BrokenSymlinkModeError
+	default:
+		return errors.Errorf("unknown broken symlink mode specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular broken symlink mode is a
+// valid, non-default value.
+func (m BrokenSymlinkMode) Supported() bool {
+	switch m {
+	case BrokenSymlinkMode_BrokenSymlinkModeSync:
+		return true
+	case BrokenSymlinkMode_BrokenSymlinkModeSkip:
+		return true
+	case BrokenSymlinkMode_BrokenSymlinkModeError:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of a broken symlink mode.
+func (m BrokenSymlinkMode) Description() string {
+	switch m {
+	case BrokenSymlinkMode_BrokenSymlinkModeDefault:
+		return "Default"
+	case BrokenSymlinkMode_BrokenSymlinkModeSync:
+		return "Sync"
+	case BrokenSymlinkMode_BrokenSymlinkModeSkip:
+		return "Skip"
+	case BrokenSymlinkMode_BrokenSymlinkModeError:
+		return "Error"
+	default:
+		return "Unknown"
+	}
+}
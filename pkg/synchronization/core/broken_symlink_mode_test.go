@@ -0,0 +1,94 @@
+package core
+
+import (
+	"testing"
+)
+
+// TestBrokenSymlinkModeUnmarshal tests that unmarshaling from a string specification
+// succeeeds for BrokenSymlinkMode.
+func TestBrokenSymlinkModeUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text          string
+		expectedMode  BrokenSymlinkMode
+		expectFailure bool
+	}{
+		{"", BrokenSymlinkMode_BrokenSymlinkModeDefault, true},
+		{"asdf", BrokenSymlinkMode_BrokenSymlinkModeDefault, true},
+		{"sync", BrokenSymlinkMode_BrokenSymlinkModeSync, false},
+		{"skip", BrokenSymlinkMode_BrokenSymlinkModeSkip, false},
+		{"error", BrokenSymlinkMode_BrokenSymlinkModeError, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var mode BrokenSymlinkMode
+		if err := mode.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if mode != testCase.expectedMode {
+			t.Errorf(
+				"unmarshaled mode (%s) does not match expected (%s)",
+				mode,
+				testCase.expectedMode,
+			)
+		}
+	}
+}
+
+// TestBrokenSymlinkModeSupported tests that BrokenSymlinkMode support detection works as
+// expected.
+func TestBrokenSymlinkModeSupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode            BrokenSymlinkMode
+		expectSupported bool
+	}{
+		{BrokenSymlinkMode_BrokenSymlinkModeDefault, false},
+		{BrokenSymlinkMode_BrokenSymlinkModeSync, true},
+		{BrokenSymlinkMode_BrokenSymlinkModeSkip, true},
+		{BrokenSymlinkMode_BrokenSymlinkModeError, true},
+		{(BrokenSymlinkMode_BrokenSymlinkModeError + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.mode.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"mode support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestBrokenSymlinkModeDescription tests that BrokenSymlinkMode description generation
+// works as expected.
+func TestBrokenSymlinkModeDescription(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                BrokenSymlinkMode
+		expectedDescription string
+	}{
+		{BrokenSymlinkMode_BrokenSymlinkModeDefault, "Default"},
+		{BrokenSymlinkMode_BrokenSymlinkModeSync, "Sync"},
+		{BrokenSymlinkMode_BrokenSymlinkModeSkip, "Skip"},
+		{BrokenSymlinkMode_BrokenSymlinkModeError, "Error"},
+		{(BrokenSymlinkMode_BrokenSymlinkModeError + 1), "Unknown"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if description := testCase.mode.Description(); description != testCase.expectedDescription {
+			t.Errorf(
+				"mode description (%s) does not match expected (%s)",
+				description,
+				testCase.expectedDescription,
+			)
+		}
+	}
+}
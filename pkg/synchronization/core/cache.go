@@ -72,7 +72,8 @@ func (c *Cache) Equal(other *Cache) bool {
 			otherEntry.ModificationTime.Nanos == entry.ModificationTime.Nanos &&
 			otherEntry.Size == entry.Size &&
 			otherEntry.FileID == entry.FileID &&
-			bytes.Equal(otherEntry.Digest, entry.Digest)
+			bytes.Equal(otherEntry.Digest, entry.Digest) &&
+			otherEntry.ContentType == entry.ContentType
 		if !equivalent {
 			return false
 		}
@@ -118,6 +119,12 @@ func (c *Cache) GenerateReverseLookupMap() (*ReverseLookupMap, error) {
 
 	// Loop over entries.
 	for p, e := range c.Entries {
+		// Skip entries without digests (i.e. those for files excluded due to
+		// their content type), since they can't be used for lookups.
+		if len(e.Digest) == 0 {
+			continue
+		}
+
 		// Compute and validate the digest size and allocate the map.
 		if digestSize == -1 {
 			digestSize = len(e.Digest)
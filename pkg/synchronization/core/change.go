@@ -4,13 +4,15 @@ import (
 	"github.com/pkg/errors"
 )
 
-// copySlim creates a "slim" copy of the Change object, where both entries are
+// CopySlim creates a "slim" copy of the Change object, where both entries are
 // shallow copies with contents excluded.
-func (c *Change) copySlim() *Change {
+func (c *Change) CopySlim() *Change {
 	return &Change{
-		Path: c.Path,
-		Old:  c.Old.copySlim(),
-		New:  c.New.copySlim(),
+		Path:            c.Path,
+		Old:             c.Old.copySlim(),
+		New:             c.New.copySlim(),
+		Resolve:         c.Resolve,
+		ConflictSidecar: c.ConflictSidecar,
 	}
 }
 
@@ -27,6 +29,18 @@ func (c *Change) EnsureValid() error {
 	// the "synthetic" changes generated in unidirectional synchronization they
 	// may be identical.
 
+	// Conflict resolution and conflict sidecar requests are mutually exclusive
+	// and are only valid between file entries.
+	if c.Resolve && c.ConflictSidecar {
+		return errors.New("conflict resolution change with conflict sidecar")
+	} else if c.Resolve || c.ConflictSidecar {
+		if c.Old == nil || c.Old.Kind != EntryKind_File {
+			return errors.New("conflict resolution change with non-file old entry")
+		} else if c.New == nil || c.New.Kind != EntryKind_File {
+			return errors.New("conflict resolution change with non-file new entry")
+		}
+	}
+
 	// Success.
 	return nil
 }
@@ -13,7 +13,7 @@ func TestChangeCopySlim(t *testing.T) {
 	}
 
 	// Create a slim copy.
-	slim := change.copySlim()
+	slim := change.CopySlim()
 
 	// Check validity.
 	if err := slim.EnsureValid(); err != nil {
@@ -50,6 +50,38 @@ func TestChangeValid(t *testing.T) {
 	}
 }
 
+func TestChangeResolveValid(t *testing.T) {
+	change := &Change{Old: testFile1Entry, New: testFile2Entry, Resolve: true}
+	if err := change.EnsureValid(); err != nil {
+		t.Error("valid conflict resolution change considered invalid:", err)
+	}
+}
+
+func TestChangeResolveNonFileInvalid(t *testing.T) {
+	if (&Change{Old: testDirectory1Entry, New: testFile1Entry, Resolve: true}).EnsureValid() == nil {
+		t.Error("conflict resolution change with directory old entry considered valid")
+	}
+	if (&Change{Old: testFile1Entry, Resolve: true}).EnsureValid() == nil {
+		t.Error("conflict resolution change with nil new entry considered valid")
+	}
+}
+
+func TestChangeConflictSidecarValid(t *testing.T) {
+	change := &Change{Old: testFile1Entry, New: testFile2Entry, ConflictSidecar: true}
+	if err := change.EnsureValid(); err != nil {
+		t.Error("valid conflict sidecar change considered invalid:", err)
+	}
+}
+
+func TestChangeConflictSidecarInvalid(t *testing.T) {
+	if (&Change{Old: testDirectory1Entry, New: testFile1Entry, ConflictSidecar: true}).EnsureValid() == nil {
+		t.Error("conflict sidecar change with directory old entry considered valid")
+	}
+	if (&Change{Old: testFile1Entry, New: testFile2Entry, Resolve: true, ConflictSidecar: true}).EnsureValid() == nil {
+		t.Error("conflict sidecar change with conflict resolution considered valid")
+	}
+}
+
 // TestIsRootDeletion tests that Change.IsRootDeletion behaves as expected.
 func TestIsRootDeletion(t *testing.T) {
 	// Set up test cases.
@@ -3,8 +3,11 @@ package core
 import (
 	"crypto/sha1"
 	"hash"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
+	"testing"
 )
 
 type temporaryDirectory struct {
@@ -48,6 +51,48 @@ func testingTemporaryDirectories() []temporaryDirectory {
 	return results
 }
 
+// testCreateContent creates a temporary synchronization root containing the
+// specified files and symbolic links (with both keyed by slash-separated paths
+// relative to the root and any parent directories created as necessary). It
+// returns the path to the root, which the caller is responsible for removing.
+func testCreateContent(t *testing.T, files map[string][]byte, links map[string]string) string {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create a temporary directory.
+	root, err := ioutil.TempDir("", "mutagen_simulated")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+
+	// Create files.
+	for path, data := range files {
+		fullPath := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0700); err != nil {
+			os.RemoveAll(root)
+			t.Fatal("unable to create parent directory:", err)
+		} else if err := ioutil.WriteFile(fullPath, data, 0600); err != nil {
+			os.RemoveAll(root)
+			t.Fatal("unable to create file:", err)
+		}
+	}
+
+	// Create symbolic links.
+	for path, target := range links {
+		fullPath := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0700); err != nil {
+			os.RemoveAll(root)
+			t.Fatal("unable to create parent directory:", err)
+		} else if err := os.Symlink(target, fullPath); err != nil {
+			os.RemoveAll(root)
+			t.Fatal("unable to create symbolic link:", err)
+		}
+	}
+
+	// Done.
+	return root
+}
+
 func newTestHasher() hash.Hash {
 	return sha1.New()
 }
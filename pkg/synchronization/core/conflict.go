@@ -12,13 +12,13 @@ func (c *Conflict) CopySlim() *Conflict {
 	// Recompute alpha changes.
 	alphaChanges := make([]*Change, len(c.AlphaChanges))
 	for a, change := range c.AlphaChanges {
-		alphaChanges[a] = change.copySlim()
+		alphaChanges[a] = change.CopySlim()
 	}
 
 	// Recompute beta changes.
 	betaChanges := make([]*Change, len(c.BetaChanges))
 	for b, change := range c.BetaChanges {
-		betaChanges[b] = change.copySlim()
+		betaChanges[b] = change.CopySlim()
 	}
 
 	// Done.
@@ -0,0 +1,22 @@
+package core
+
+const (
+	// ConflictSidecarSuffix is the suffix appended to the name of a file to
+	// compute the name of the conflict sidecar file that preserves beta's
+	// version of the file when a conflict is resolved in alpha's favor.
+	ConflictSidecarSuffix = ".mutagen-conflict-beta"
+)
+
+// ConflictSidecarIgnores is the set of ignores used to exclude conflict sidecar
+// files from synchronization. Conflict sidecar files are never synchronized,
+// since they would otherwise propagate (and potentially generate conflicts of
+// their own).
+var ConflictSidecarIgnores = []string{
+	"*.mutagen-conflict-*",
+}
+
+// ConflictSidecarPath computes the path of the conflict sidecar file for the
+// file at the specified path.
+func ConflictSidecarPath(path string) string {
+	return path + ConflictSidecarSuffix
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// contentTypeSniffSize is the maximum number of bytes read from the start
+	// of a file when determining its content type. This is the same limit that
+	// Git uses when classifying files as binary.
+	contentTypeSniffSize = 8000
+)
+
+// IsDefault indicates whether or not the content type mode is
+// ContentTypeMode_ContentTypeModeDefault.
+func (m ContentTypeMode) IsDefault() bool {
+	return m == ContentTypeMode_ContentTypeModeDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (m *ContentTypeMode) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to a content type mode.
+	switch text {
+	case "all":
+		*m = ContentTypeMode_ContentTypeModeAll
+	case "text":
+		*m = ContentTypeMode_ContentTypeModeText
+	case "binary":
+		*m = ContentTypeMode_ContentTypeModeBinary
+	default:
+		return errors.Errorf("unknown content type mode specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular content type mode is a
+// valid, non-default value.
+func (m ContentTypeMode) Supported() bool {
+	switch m {
+	case ContentTypeMode_ContentTypeModeAll:
+		return true
+	case ContentTypeMode_ContentTypeModeText:
+		return true
+	case ContentTypeMode_ContentTypeModeBinary:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of a content type mode.
+func (m ContentTypeMode) Description() string {
+	switch m {
+	case ContentTypeMode_ContentTypeModeDefault:
+		return "Default"
+	case ContentTypeMode_ContentTypeModeAll:
+		return "All"
+	case ContentTypeMode_ContentTypeModeText:
+		return "Text only"
+	case ContentTypeMode_ContentTypeModeBinary:
+		return "Binary only"
+	default:
+		return "Unknown"
+	}
+}
+
+// includes determines whether or not files with the specified content type are
+// included by the content type mode.
+func (m ContentTypeMode) includes(contentType ContentType) bool {
+	switch m {
+	case ContentTypeMode_ContentTypeModeText:
+		return contentType == ContentType_ContentTypeText
+	case ContentTypeMode_ContentTypeModeBinary:
+		return contentType == ContentType_ContentTypeBinary
+	default:
+		return true
+	}
+}
+
+// Description returns a human-readable description of a content type.
+func (t ContentType) Description() string {
+	switch t {
+	case ContentType_ContentTypeText:
+		return "text"
+	case ContentType_ContentTypeBinary:
+		return "binary"
+	default:
+		return "unknown"
+	}
+}
+
+// sniffContentType determines the content type of a stream by reading (at most)
+// the first contentTypeSniffSize bytes from it using the specified buffer
+// (which must be at least contentTypeSniffSize bytes long). Content is
+// classified as binary if it contains a NUL byte within that range, otherwise
+// it's classified as text. Empty content is classified as text.
+func sniffContentType(reader io.Reader, buffer []byte) (ContentType, error) {
+	// Read the leading content.
+	count, err := io.ReadFull(reader, buffer[:contentTypeSniffSize])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return ContentType_ContentTypeUnknown, err
+	}
+
+	// Classify the content.
+	if bytes.IndexByte(buffer[:count], 0) != -1 {
+		return ContentType_ContentTypeBinary, nil
+	}
+	return ContentType_ContentTypeText, nil
+}
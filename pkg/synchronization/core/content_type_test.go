@@ -0,0 +1,125 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestContentTypeModeUnmarshal tests that unmarshaling from a string
+// specification succeeeds for ContentTypeMode.
+func TestContentTypeModeUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text          string
+		expectedMode  ContentTypeMode
+		expectFailure bool
+	}{
+		{"", ContentTypeMode_ContentTypeModeDefault, true},
+		{"asdf", ContentTypeMode_ContentTypeModeDefault, true},
+		{"all", ContentTypeMode_ContentTypeModeAll, false},
+		{"text", ContentTypeMode_ContentTypeModeText, false},
+		{"binary", ContentTypeMode_ContentTypeModeBinary, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var mode ContentTypeMode
+		if err := mode.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if mode != testCase.expectedMode {
+			t.Errorf(
+				"unmarshaled mode (%s) does not match expected (%s)",
+				mode,
+				testCase.expectedMode,
+			)
+		}
+	}
+}
+
+// TestContentTypeModeSupported tests that ContentTypeMode support detection
+// works as expected.
+func TestContentTypeModeSupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode            ContentTypeMode
+		expectSupported bool
+	}{
+		{ContentTypeMode_ContentTypeModeDefault, false},
+		{ContentTypeMode_ContentTypeModeAll, true},
+		{ContentTypeMode_ContentTypeModeText, true},
+		{ContentTypeMode_ContentTypeModeBinary, true},
+		{(ContentTypeMode_ContentTypeModeBinary + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.mode.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"mode support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestContentTypeModeDescription tests that ContentTypeMode description
+// generation works as expected.
+func TestContentTypeModeDescription(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                ContentTypeMode
+		expectedDescription string
+	}{
+		{ContentTypeMode_ContentTypeModeDefault, "Default"},
+		{ContentTypeMode_ContentTypeModeAll, "All"},
+		{ContentTypeMode_ContentTypeModeText, "Text only"},
+		{ContentTypeMode_ContentTypeModeBinary, "Binary only"},
+		{(ContentTypeMode_ContentTypeModeBinary + 1), "Unknown"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if description := testCase.mode.Description(); description != testCase.expectedDescription {
+			t.Errorf(
+				"mode description (%s) does not match expected (%s)",
+				description,
+				testCase.expectedDescription,
+			)
+		}
+	}
+}
+
+// TestSniffContentType tests that sniffContentType classifies content as
+// expected.
+func TestSniffContentType(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		content  []byte
+		expected ContentType
+	}{
+		{nil, ContentType_ContentTypeText},
+		{[]byte("Hello, world!\n"), ContentType_ContentTypeText},
+		{[]byte("caf\xc3\xa9\r\n\ttab"), ContentType_ContentTypeText},
+		{[]byte{0}, ContentType_ContentTypeBinary},
+		{[]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), ContentType_ContentTypeBinary},
+		{append(bytes.Repeat([]byte("a"), contentTypeSniffSize-1), 0), ContentType_ContentTypeBinary},
+		{append(bytes.Repeat([]byte("a"), contentTypeSniffSize), 0), ContentType_ContentTypeText},
+	}
+
+	// Process test cases.
+	buffer := make([]byte, scannerCopyBufferSize)
+	for i, testCase := range testCases {
+		if contentType, err := sniffContentType(bytes.NewReader(testCase.content), buffer); err != nil {
+			t.Errorf("test case %d: unable to sniff content type: %v", i, err)
+		} else if contentType != testCase.expected {
+			t.Errorf("test case %d: content type (%s) does not match expected (%s)",
+				i, contentType, testCase.expected,
+			)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Description returns a human-readable description of a reconciliation action.
+func (a ReconciliationAction) Description() string {
+	switch a {
+	case ReconciliationAction_ReconciliationActionNone:
+		return "None"
+	case ReconciliationAction_ReconciliationActionUpdateAncestor:
+		return "Update ancestor"
+	case ReconciliationAction_ReconciliationActionPropagateToAlpha:
+		return "Propagate to alpha"
+	case ReconciliationAction_ReconciliationActionPropagateToBeta:
+		return "Propagate to beta"
+	case ReconciliationAction_ReconciliationActionConflict:
+		return "Conflict"
+	default:
+		return "Unknown"
+	}
+}
+
+// EnsureValid ensures that ReconciliationDecision's invariants are respected.
+func (d *ReconciliationDecision) EnsureValid() error {
+	// A nil decision is not valid.
+	if d == nil {
+		return errors.New("nil reconciliation decision")
+	}
+
+	// Ensure that the action is known.
+	switch d.Action {
+	case ReconciliationAction_ReconciliationActionNone:
+	case ReconciliationAction_ReconciliationActionUpdateAncestor:
+	case ReconciliationAction_ReconciliationActionPropagateToAlpha:
+	case ReconciliationAction_ReconciliationActionPropagateToBeta:
+	case ReconciliationAction_ReconciliationActionConflict:
+	default:
+		return errors.New("unknown reconciliation action")
+	}
+
+	// Ensure that the entries are valid.
+	if err := d.Ancestor.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid ancestor entry")
+	} else if err = d.Alpha.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid alpha entry")
+	} else if err = d.Beta.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid beta entry")
+	}
+
+	// Success.
+	return nil
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"bytes"
+	"sort"
+)
+
+// Divergence describes the differences between the contents of two endpoints,
+// as determined by diffing their snapshots without an ancestor. Each path is
+// reported at the highest level at which the endpoints differ (e.g. a directory
+// that exists on only one endpoint is reported without its contents). Each list
+// is sorted by path.
+type Divergence struct {
+	// AlphaOnly are the paths that exist only on alpha.
+	AlphaOnly []string
+	// BetaOnly are the paths that exist only on beta.
+	BetaOnly []string
+	// ContentDiffers are the paths that exist on both endpoints but whose
+	// kind, content, or symbolic link target differ.
+	ContentDiffers []string
+	// ModeDiffers are the paths of files that have the same content on both
+	// endpoints but whose executability differs.
+	ModeDiffers []string
+}
+
+// Diverged indicates whether or not any differences were found.
+func (d *Divergence) Diverged() bool {
+	return len(d.AlphaOnly) > 0 ||
+		len(d.BetaOnly) > 0 ||
+		len(d.ContentDiffers) > 0 ||
+		len(d.ModeDiffers) > 0
+}
+
+// Compare computes the divergence between alpha and beta snapshots. Either
+// snapshot may be nil. This is equivalent to reconciling the snapshots against
+// an empty ancestor, except that no changes or conflicts are generated.
+func Compare(alpha, beta *Entry) *Divergence {
+	// Diff the snapshots. The diff will only recurse into locations that are
+	// shallow equal, so every change describes the highest-level divergence
+	// along its path.
+	result := &Divergence{}
+	for _, change := range Diff(alpha, beta) {
+		if change.New == nil {
+			result.AlphaOnly = append(result.AlphaOnly, change.Path)
+		} else if change.Old == nil {
+			result.BetaOnly = append(result.BetaOnly, change.Path)
+		} else if change.Old.Kind == change.New.Kind &&
+			change.Old.Kind == EntryKind_File &&
+			bytes.Equal(change.Old.Digest, change.New.Digest) {
+			result.ModeDiffers = append(result.ModeDiffers, change.Path)
+		} else {
+			result.ContentDiffers = append(result.ContentDiffers, change.Path)
+		}
+	}
+
+	// Sort the results, since diffing visits directory contents in an
+	// undefined order.
+	sort.Strings(result.AlphaOnly)
+	sort.Strings(result.BetaOnly)
+	sort.Strings(result.ContentDiffers)
+	sort.Strings(result.ModeDiffers)
+
+	// Done.
+	return result
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"testing"
+)
+
+// testStringSlicesEqual determines whether or not two string slices are equal,
+// treating nil and empty slices as equal.
+func testStringSlicesEqual(first, second []string) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	for i, f := range first {
+		if second[i] != f {
+			return false
+		}
+	}
+	return true
+}
+
+// TestCompare tests Compare.
+func TestCompare(t *testing.T) {
+	// Create divergent snapshots.
+	alpha := &Entry{
+		Kind: EntryKind_Directory,
+		Contents: map[string]*Entry{
+			"same":       testFile1Entry,
+			"alpha only": testFile1Entry,
+			"alpha only directory": {
+				Kind: EntryKind_Directory,
+				Contents: map[string]*Entry{
+					"file": testFile1Entry,
+				},
+			},
+			"content": testFile1Entry,
+			"kind":    testFile1Entry,
+			"mode":    testFile1Entry,
+			"link":    testSymlinkEntry,
+			"nested": {
+				Kind: EntryKind_Directory,
+				Contents: map[string]*Entry{
+					"content": testFile1Entry,
+				},
+			},
+		},
+	}
+	beta := &Entry{
+		Kind: EntryKind_Directory,
+		Contents: map[string]*Entry{
+			"same":      testFile1Entry,
+			"beta only": testFile3Entry,
+			"content":   testFile3Entry,
+			"kind":      {Kind: EntryKind_Directory},
+			"mode": {
+				Kind:       EntryKind_File,
+				Digest:     testFile1Entry.Digest,
+				Executable: true,
+			},
+			"link": {
+				Kind:   EntryKind_Symlink,
+				Target: "elsewhere",
+			},
+			"nested": {
+				Kind: EntryKind_Directory,
+				Contents: map[string]*Entry{
+					"content": testFile3Entry,
+				},
+			},
+		},
+	}
+	alphaCopy, betaCopy := alpha.Copy(), beta.Copy()
+
+	// Perform the comparison.
+	divergence := Compare(alpha, beta)
+
+	// Verify the results.
+	if !divergence.Diverged() {
+		t.Error("divergent snapshots not reported as diverged")
+	}
+	if expected := []string{"alpha only", "alpha only directory"}; !testStringSlicesEqual(divergence.AlphaOnly, expected) {
+		t.Error("alpha-only paths do not match expected:", divergence.AlphaOnly, "!=", expected)
+	}
+	if expected := []string{"beta only"}; !testStringSlicesEqual(divergence.BetaOnly, expected) {
+		t.Error("beta-only paths do not match expected:", divergence.BetaOnly, "!=", expected)
+	}
+	if expected := []string{"content", "kind", "link", "nested/content"}; !testStringSlicesEqual(divergence.ContentDiffers, expected) {
+		t.Error("content-differing paths do not match expected:", divergence.ContentDiffers, "!=", expected)
+	}
+	if expected := []string{"mode"}; !testStringSlicesEqual(divergence.ModeDiffers, expected) {
+		t.Error("mode-differing paths do not match expected:", divergence.ModeDiffers, "!=", expected)
+	}
+
+	// Verify that the snapshots weren't modified.
+	if !alpha.Equal(alphaCopy) || !beta.Equal(betaCopy) {
+		t.Error("snapshots modified by comparison")
+	}
+}
+
+// TestCompareIdentical tests that Compare reports no divergence for identical
+// (including empty) snapshots.
+func TestCompareIdentical(t *testing.T) {
+	// Set up test cases.
+	testCases := []*Entry{
+		nil,
+		testEmptyDirectory,
+		testFile1Entry,
+		testDirectory1Entry,
+	}
+
+	// Process test cases.
+	for i, snapshot := range testCases {
+		if divergence := Compare(snapshot, snapshot.Copy()); divergence.Diverged() {
+			t.Errorf("test case %d: identical snapshots reported as diverged", i)
+		}
+	}
+}
+
+// TestCompareRoot tests that Compare reports divergence at the root.
+func TestCompareRoot(t *testing.T) {
+	if divergence := Compare(testDirectory1Entry, nil); !testStringSlicesEqual(divergence.AlphaOnly, []string{""}) {
+		t.Error("alpha-only root not reported:", divergence.AlphaOnly)
+	}
+	if divergence := Compare(nil, testFile1Entry); !testStringSlicesEqual(divergence.BetaOnly, []string{""}) {
+		t.Error("beta-only root not reported:", divergence.BetaOnly)
+	}
+	if divergence := Compare(testFile1Entry, testDirectory1Entry); !testStringSlicesEqual(divergence.ContentDiffers, []string{""}) {
+		t.Error("root kind divergence not reported:", divergence.ContentDiffers)
+	}
+}
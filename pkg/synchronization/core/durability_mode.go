@@ -0,0 +1,77 @@
+package core
+
+import (
+	"github.com/pkg/errors"
+)
+
+// IsDefault indicates whether or not the durability mode is
+// DurabilityMode_DurabilityModeDefault.
+func (m DurabilityMode) IsDefault() bool {
+	return m == DurabilityMode_DurabilityModeDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (m *DurabilityMode) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to a durability mode.
+	switch text {
+	case "full":
+		*m = DurabilityMode_DurabilityModeFull
+	case "metadata":
+		*m = DurabilityMode_DurabilityModeMetadata
+	case "none":
+		*m = DurabilityMode_DurabilityModeNone
+	default:
+		return errors.Errorf("unknown durability mode specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular durability mode is a valid,
+// non-default value.
+func (m DurabilityMode) Supported() bool {
+	switch m {
+	case DurabilityMode_DurabilityModeFull:
+		return true
+	case DurabilityMode_DurabilityModeMetadata:
+		return true
+	case DurabilityMode_DurabilityModeNone:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of a durability mode.
+func (m DurabilityMode) Description() string {
+	switch m {
+	case DurabilityMode_DurabilityModeDefault:
+		return "Default"
+	case DurabilityMode_DurabilityModeFull:
+		return "Full"
+	case DurabilityMode_DurabilityModeMetadata:
+		return "Metadata Only"
+	case DurabilityMode_DurabilityModeNone:
+		return "None"
+	default:
+		return "Unknown"
+	}
+}
+
+// SyncsFiles indicates whether or not the durability mode requires that file
+// contents be flushed to durable storage.
+func (m DurabilityMode) SyncsFiles() bool {
+	return m == DurabilityMode_DurabilityModeFull
+}
+
+// SyncsDirectories indicates whether or not the durability mode requires that
+// directory modifications be flushed to durable storage.
+func (m DurabilityMode) SyncsDirectories() bool {
+	return m == DurabilityMode_DurabilityModeFull ||
+		m == DurabilityMode_DurabilityModeMetadata
+}
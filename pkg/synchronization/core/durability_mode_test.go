@@ -0,0 +1,130 @@
+package core
+
+import (
+	"testing"
+)
+
+// TestDurabilityModeUnmarshal tests that unmarshaling from a string
+// specification succeeeds for DurabilityMode.
+func TestDurabilityModeUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text          string
+		expectedMode  DurabilityMode
+		expectFailure bool
+	}{
+		{"", DurabilityMode_DurabilityModeDefault, true},
+		{"asdf", DurabilityMode_DurabilityModeDefault, true},
+		{"full", DurabilityMode_DurabilityModeFull, false},
+		{"metadata", DurabilityMode_DurabilityModeMetadata, false},
+		{"none", DurabilityMode_DurabilityModeNone, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var mode DurabilityMode
+		if err := mode.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if mode != testCase.expectedMode {
+			t.Errorf(
+				"unmarshaled mode (%s) does not match expected (%s)",
+				mode,
+				testCase.expectedMode,
+			)
+		}
+	}
+}
+
+// TestDurabilityModeSupported tests that DurabilityMode support detection works
+// as expected.
+func TestDurabilityModeSupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode            DurabilityMode
+		expectSupported bool
+	}{
+		{DurabilityMode_DurabilityModeDefault, false},
+		{DurabilityMode_DurabilityModeFull, true},
+		{DurabilityMode_DurabilityModeMetadata, true},
+		{DurabilityMode_DurabilityModeNone, true},
+		{(DurabilityMode_DurabilityModeNone + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.mode.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"mode support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestDurabilityModeDescription tests that DurabilityMode description
+// generation works as expected.
+func TestDurabilityModeDescription(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                DurabilityMode
+		expectedDescription string
+	}{
+		{DurabilityMode_DurabilityModeDefault, "Default"},
+		{DurabilityMode_DurabilityModeFull, "Full"},
+		{DurabilityMode_DurabilityModeMetadata, "Metadata Only"},
+		{DurabilityMode_DurabilityModeNone, "None"},
+		{(DurabilityMode_DurabilityModeNone + 1), "Unknown"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if description := testCase.mode.Description(); description != testCase.expectedDescription {
+			t.Errorf(
+				"mode description (%s) does not match expected (%s)",
+				description,
+				testCase.expectedDescription,
+			)
+		}
+	}
+}
+
+// TestDurabilityModeSyncBehavior tests that DurabilityMode reports the
+// expected flushing behavior.
+func TestDurabilityModeSyncBehavior(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                   DurabilityMode
+		expectSyncsFiles       bool
+		expectSyncsDirectories bool
+	}{
+		{DurabilityMode_DurabilityModeDefault, false, false},
+		{DurabilityMode_DurabilityModeFull, true, true},
+		{DurabilityMode_DurabilityModeMetadata, false, true},
+		{DurabilityMode_DurabilityModeNone, false, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if syncsFiles := testCase.mode.SyncsFiles(); syncsFiles != testCase.expectSyncsFiles {
+			t.Errorf(
+				"file syncing behavior (%t) does not match expected (%t) for mode %s",
+				syncsFiles,
+				testCase.expectSyncsFiles,
+				testCase.mode,
+			)
+		}
+		if syncsDirectories := testCase.mode.SyncsDirectories(); syncsDirectories != testCase.expectSyncsDirectories {
+			t.Errorf(
+				"directory syncing behavior (%t) does not match expected (%t) for mode %s",
+				syncsDirectories,
+				testCase.expectSyncsDirectories,
+				testCase.mode,
+			)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
 )
 
 // EnsureValid ensures that Entry's invariants are respected.
@@ -24,6 +26,23 @@ func (e *Entry) EnsureValid() error {
 			return errors.New("non-nil directory digest detected")
 		} else if e.Target != "" {
 			return errors.New("non-empty symlink target detected for directory")
+		} else if e.HardLink != "" {
+			return errors.New("non-empty hard link detected for directory")
+		}
+
+		// Validate ACLs.
+		if err := e.Acl.EnsureValid(true); err != nil {
+			return err
+		}
+
+		// Validate macOS metadata.
+		if err := ensureMacOSMetadataValid(e.MacOSMetadata); err != nil {
+			return err
+		}
+
+		// Validate file flags.
+		if filesystem.FileFlags(e.FileFlags)&^filesystem.FileFlagsAll != 0 {
+			return errors.New("unknown file flags detected")
 		}
 
 		// Validate contents. Nil entries are NOT allowed as contents.
@@ -48,6 +67,21 @@ func (e *Entry) EnsureValid() error {
 			return errors.New("non-empty symlink target detected for file")
 		}
 
+		// Validate ACLs.
+		if err := e.Acl.EnsureValid(false); err != nil {
+			return err
+		}
+
+		// Validate macOS metadata.
+		if err := ensureMacOSMetadataValid(e.MacOSMetadata); err != nil {
+			return err
+		}
+
+		// Validate file flags.
+		if filesystem.FileFlags(e.FileFlags)&^filesystem.FileFlagsAll != 0 {
+			return errors.New("unknown file flags detected")
+		}
+
 		// Ensure that the digest is non-empty.
 		if len(e.Digest) == 0 {
 			return errors.New("file with empty digest detected")
@@ -60,6 +94,14 @@ func (e *Entry) EnsureValid() error {
 			return errors.New("non-nil symlink digest detected")
 		} else if e.Contents != nil {
 			return errors.New("non-nil symlink contents detected")
+		} else if e.Acl != nil {
+			return errors.New("non-nil symlink ACL detected")
+		} else if e.MacOSMetadata != nil {
+			return errors.New("non-nil symlink macOS metadata detected")
+		} else if e.FileFlags != 0 {
+			return errors.New("non-zero symlink file flags detected")
+		} else if e.HardLink != "" {
+			return errors.New("non-empty hard link detected for symlink")
 		}
 
 		// Ensure that the target is non-empty.
@@ -135,7 +177,10 @@ func (e *Entry) Count() uint64 {
 
 // equalShallow returns true if and only if the existence, kind, executability,
 // and digest of the two entries are equivalent. It pays no attention to the
-// contents of either entry.
+// contents of either entry. It also pays no attention to ACLs or hard links,
+// which are treated as metadata that accompanies entries when they're
+// propagated, rather than as properties that trigger propagation (which, for
+// directories, would require a complete replacement).
 func (e *Entry) equalShallow(other *Entry) bool {
 	// If the pointers are equal, then the entries are equal. Even in the case
 	// of two nil pointers, we still consider the entries to be equal since they
@@ -201,10 +246,14 @@ func (e *Entry) copySlim() *Entry {
 
 	// Create the shallow copy.
 	return &Entry{
-		Kind:       e.Kind,
-		Executable: e.Executable,
-		Digest:     e.Digest,
-		Target:     e.Target,
+		Kind:          e.Kind,
+		Acl:           e.Acl,
+		MacOSMetadata: e.MacOSMetadata,
+		FileFlags:     e.FileFlags,
+		Executable:    e.Executable,
+		Digest:        e.Digest,
+		HardLink:      e.HardLink,
+		Target:        e.Target,
 	}
 }
 
@@ -217,10 +266,14 @@ func (e *Entry) Copy() *Entry {
 
 	// Create the result.
 	result := &Entry{
-		Kind:       e.Kind,
-		Executable: e.Executable,
-		Digest:     e.Digest,
-		Target:     e.Target,
+		Kind:          e.Kind,
+		Acl:           e.Acl,
+		MacOSMetadata: e.MacOSMetadata,
+		FileFlags:     e.FileFlags,
+		Executable:    e.Executable,
+		Digest:        e.Digest,
+		HardLink:      e.HardLink,
+		Target:        e.Target,
 	}
 
 	// If the original entry doesn't have any contents, return now to save an
@@ -86,12 +86,38 @@ type Entry struct {
 
 	// Kind encodes the type of filesystem entry being represented.
 	Kind EntryKind `protobuf:"varint,1,opt,name=kind,proto3,enum=core.EntryKind" json:"kind,omitempty"`
+	// ACL represents the POSIX ACLs for file and directory entries. It is only
+	// populated if ACL propagation is enabled and the entry has ACLs that
+	// can't be represented by permission mode bits alone.
+	Acl *ACL `protobuf:"bytes,2,opt,name=acl,proto3" json:"acl,omitempty"`
+	// MacOSMetadata represents the macOS metadata (Finder information, resource
+	// forks, and other com.apple.* extended attributes) for file and directory
+	// entries, sorted by attribute name. It is only populated if macOS metadata
+	// preservation is enabled. Like ACLs, it's treated as metadata that
+	// accompanies entries when they're propagated, rather than as a property
+	// that triggers propagation.
+	MacOSMetadata []*MacOSAttribute `protobuf:"bytes,3,rep,name=macOSMetadata,proto3" json:"macOSMetadata,omitempty"`
+	// FileFlags represents the BSD-style user file flags (e.g. hidden and
+	// user immutable) for file and directory entries, encoded as a bitmask of
+	// portable flag values (see filesystem.FileFlags). It is only populated if
+	// file flag preservation is enabled. Like ACLs, it's treated as metadata
+	// that accompanies entries when they're propagated, rather than as a
+	// property that triggers propagation.
+	FileFlags uint32 `protobuf:"varint,4,opt,name=fileFlags,proto3" json:"fileFlags,omitempty"`
 	// Contents represents a directory entry's contents.
 	Contents map[string]*Entry `protobuf:"bytes,5,rep,name=contents,proto3" json:"contents,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	// Digest represents the hash of a file entry's contents.
 	Digest []byte `protobuf:"bytes,8,opt,name=digest,proto3" json:"digest,omitempty"`
 	// Executable indicates whether or not a file entry is marked as executable.
 	Executable bool `protobuf:"varint,9,opt,name=executable,proto3" json:"executable,omitempty"`
+	// HardLink is the path (relative to the synchronization root) of another
+	// file entry with which a file entry shares its underlying file (i.e. to
+	// which it is hard linked). It is only populated if hard link preservation
+	// is enabled, in which case it's set on all but the lowest (in terms of
+	// path ordering) of the paths sharing the underlying file. Like ACLs, it's
+	// treated as metadata that accompanies file entries when they're
+	// propagated, rather than as a property that triggers propagation.
+	HardLink string `protobuf:"bytes,10,opt,name=hardLink,proto3" json:"hardLink,omitempty"`
 	// Target is the symlink target for symlink entries.
 	Target string `protobuf:"bytes,12,opt,name=target,proto3" json:"target,omitempty"`
 }
@@ -135,6 +161,27 @@ func (x *Entry) GetKind() EntryKind {
 	return EntryKind_Directory
 }
 
+func (x *Entry) GetAcl() *ACL {
+	if x != nil {
+		return x.Acl
+	}
+	return nil
+}
+
+func (x *Entry) GetMacOSMetadata() []*MacOSAttribute {
+	if x != nil {
+		return x.MacOSMetadata
+	}
+	return nil
+}
+
+func (x *Entry) GetFileFlags() uint32 {
+	if x != nil {
+		return x.FileFlags
+	}
+	return 0
+}
+
 func (x *Entry) GetContents() map[string]*Entry {
 	if x != nil {
 		return x.Contents
@@ -156,6 +203,13 @@ func (x *Entry) GetExecutable() bool {
 	return false
 }
 
+func (x *Entry) GetHardLink() string {
+	if x != nil {
+		return x.HardLink
+	}
+	return ""
+}
+
 func (x *Entry) GetTarget() string {
 	if x != nil {
 		return x.Target
@@ -163,35 +217,114 @@ func (x *Entry) GetTarget() string {
 	return ""
 }
 
+// EntryRecord encodes a single entry within an entry stream, along with its
+// path relative to the root of the streamed snapshot.
+type EntryRecord struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Path is the path of the entry relative to the snapshot root.
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// Entry is the entry at the path. For directories, it doesn't include any
+	// contents, which are instead encoded as subsequent records.
+	Entry *Entry `protobuf:"bytes,2,opt,name=entry,proto3" json:"entry,omitempty"`
+}
+
+func (x *EntryRecord) Reset() {
+	*x = EntryRecord{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_synchronization_core_entry_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EntryRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntryRecord) ProtoMessage() {}
+
+func (x *EntryRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_synchronization_core_entry_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntryRecord.ProtoReflect.Descriptor instead.
+func (*EntryRecord) Descriptor() ([]byte, []int) {
+	return file_synchronization_core_entry_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *EntryRecord) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *EntryRecord) GetEntry() *Entry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
 var File_synchronization_core_entry_proto protoreflect.FileDescriptor
 
 var file_synchronization_core_entry_proto_rawDesc = []byte{
 	0x0a, 0x20, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f,
 	0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x12, 0x04, 0x63, 0x6f, 0x72, 0x65, 0x22, 0xfd, 0x01, 0x0a, 0x05, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x12, 0x23, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e,
-	0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x4b, 0x69, 0x6e,
-	0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x35, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x65,
-	0x6e, 0x74, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x63, 0x6f, 0x72, 0x65,
-	0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x73, 0x45,
-	0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x16,
-	0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06,
-	0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x78, 0x65, 0x63, 0x75, 0x74,
-	0x61, 0x62, 0x6c, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x65, 0x78, 0x65, 0x63,
-	0x75, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74,
-	0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x1a, 0x48,
-	0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
-	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
-	0x79, 0x12, 0x21, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x0b, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x2a, 0x31, 0x0a, 0x09, 0x45, 0x6e, 0x74, 0x72,
-	0x79, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x0d, 0x0a, 0x09, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f,
-	0x72, 0x79, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65, 0x10, 0x01, 0x12, 0x0b,
-	0x0a, 0x07, 0x53, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x10, 0x02, 0x42, 0x38, 0x5a, 0x36, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65,
-	0x6e, 0x2d, 0x69, 0x6f, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x6b, 0x67,
-	0x2f, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x2f, 0x63, 0x6f, 0x72, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x6f, 0x12, 0x04, 0x63, 0x6f, 0x72, 0x65, 0x1a, 0x1e, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72,
+	0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x61,
+	0x63, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x29, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72,
+	0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x6d,
+	0x61, 0x63, 0x6f, 0x73, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0x90, 0x03, 0x0a, 0x05, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x23, 0x0a,
+	0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x63, 0x6f,
+	0x72, 0x65, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69,
+	0x6e, 0x64, 0x12, 0x1b, 0x0a, 0x03, 0x61, 0x63, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x09, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x41, 0x43, 0x4c, 0x52, 0x03, 0x61, 0x63, 0x6c, 0x12,
+	0x3a, 0x0a, 0x0d, 0x6d, 0x61, 0x63, 0x4f, 0x53, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x4d, 0x61,
+	0x63, 0x4f, 0x53, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x52, 0x0d, 0x6d, 0x61,
+	0x63, 0x4f, 0x53, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1c, 0x0a, 0x09, 0x66,
+	0x69, 0x6c, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09,
+	0x66, 0x69, 0x6c, 0x65, 0x46, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x35, 0x0a, 0x08, 0x63, 0x6f, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x63, 0x6f,
+	0x72, 0x65, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x73,
+	0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x78, 0x65, 0x63,
+	0x75, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x65, 0x78,
+	0x65, 0x63, 0x75, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x61, 0x72, 0x64,
+	0x4c, 0x69, 0x6e, 0x6b, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x61, 0x72, 0x64,
+	0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x1a, 0x48, 0x0a, 0x0d,
+	0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x21, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b,
+	0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x44, 0x0a, 0x0b, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x21, 0x0a, 0x05, 0x65, 0x6e, 0x74,
+	0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x2a, 0x31, 0x0a, 0x09,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x0d, 0x0a, 0x09, 0x44, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65,
+	0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x79, 0x6d, 0x6c, 0x69, 0x6e, 0x6b, 0x10, 0x02, 0x42,
+	0x38, 0x5a, 0x36, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x75,
+	0x74, 0x61, 0x67, 0x65, 0x6e, 0x2d, 0x69, 0x6f, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -207,21 +340,27 @@ func file_synchronization_core_entry_proto_rawDescGZIP() []byte {
 }
 
 var file_synchronization_core_entry_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_synchronization_core_entry_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_synchronization_core_entry_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_synchronization_core_entry_proto_goTypes = []interface{}{
-	(EntryKind)(0), // 0: core.EntryKind
-	(*Entry)(nil),  // 1: core.Entry
-	nil,            // 2: core.Entry.ContentsEntry
+	(EntryKind)(0),         // 0: core.EntryKind
+	(*Entry)(nil),          // 1: core.Entry
+	(*EntryRecord)(nil),    // 2: core.EntryRecord
+	nil,                    // 3: core.Entry.ContentsEntry
+	(*ACL)(nil),            // 4: core.ACL
+	(*MacOSAttribute)(nil), // 5: core.MacOSAttribute
 }
 var file_synchronization_core_entry_proto_depIdxs = []int32{
 	0, // 0: core.Entry.kind:type_name -> core.EntryKind
-	2, // 1: core.Entry.contents:type_name -> core.Entry.ContentsEntry
-	1, // 2: core.Entry.ContentsEntry.value:type_name -> core.Entry
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	4, // 1: core.Entry.acl:type_name -> core.ACL
+	5, // 2: core.Entry.macOSMetadata:type_name -> core.MacOSAttribute
+	3, // 3: core.Entry.contents:type_name -> core.Entry.ContentsEntry
+	1, // 4: core.EntryRecord.entry:type_name -> core.Entry
+	1, // 5: core.Entry.ContentsEntry.value:type_name -> core.Entry
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_synchronization_core_entry_proto_init() }
@@ -229,6 +368,8 @@ func file_synchronization_core_entry_proto_init() {
 	if File_synchronization_core_entry_proto != nil {
 		return
 	}
+	file_synchronization_core_acl_proto_init()
+	file_synchronization_core_macos_metadata_proto_init()
 	if !protoimpl.UnsafeEnabled {
 		file_synchronization_core_entry_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Entry); i {
@@ -242,6 +383,18 @@ func file_synchronization_core_entry_proto_init() {
 				return nil
 			}
 		}
+		file_synchronization_core_entry_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EntryRecord); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -249,7 +402,7 @@ func file_synchronization_core_entry_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_synchronization_core_entry_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   2,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,386 @@
+package core
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+)
+
+const (
+	// exportCopyBufferSize is the size of the copy buffer used when streaming
+	// file content into an archive.
+	exportCopyBufferSize = 32 * 1024
+	// exportCopyPreemptionInterval is the number of buffer copy operations to
+	// perform between checks for cancellation when streaming file content.
+	exportCopyPreemptionInterval = 16
+	// exportFileMode is the permission mode used for non-executable files in
+	// exported archives.
+	exportFileMode = 0644
+	// exportExecutableMode is the permission mode used for executable files in
+	// exported archives.
+	exportExecutableMode = 0755
+	// exportDirectoryMode is the permission mode used for directories in
+	// exported archives.
+	exportDirectoryMode = 0755
+	// exportSymlinkMode is the permission mode used for symbolic links in
+	// exported archives.
+	exportSymlinkMode = 0777
+)
+
+var (
+	// errExportContentModified is the error returned when file content has been
+	// modified since the snapshot being exported was scanned.
+	errExportContentModified = errors.New("file modified since scan")
+)
+
+// ExportFormat specifies the archive format used when exporting a snapshot.
+type ExportFormat uint8
+
+const (
+	// ExportFormatTar specifies that snapshots should be exported as tar
+	// archives.
+	ExportFormatTar ExportFormat = iota
+	// ExportFormatZip specifies that snapshots should be exported as zip
+	// archives.
+	ExportFormatZip
+)
+
+// archiveWriter is the interface used by Export to write archive entries.
+type archiveWriter interface {
+	// directory writes a directory entry.
+	directory(path string, modificationTime time.Time) error
+	// file writes a file entry with the specified size and returns a writer
+	// for its content.
+	file(path string, executable bool, size int64, modificationTime time.Time) (io.Writer, error)
+	// symlink writes a symbolic link entry.
+	symlink(path, target string, modificationTime time.Time) error
+	// Close finalizes the archive.
+	Close() error
+}
+
+// tarArchiveWriter implements archiveWriter for tar archives.
+type tarArchiveWriter struct {
+	// writer is the underlying tar writer.
+	writer *tar.Writer
+}
+
+// directory implements archiveWriter.directory.
+func (w *tarArchiveWriter) directory(path string, modificationTime time.Time) error {
+	return w.writer.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     path + "/",
+		Mode:     exportDirectoryMode,
+		ModTime:  modificationTime,
+	})
+}
+
+// file implements archiveWriter.file.
+func (w *tarArchiveWriter) file(path string, executable bool, size int64, modificationTime time.Time) (io.Writer, error) {
+	mode := int64(exportFileMode)
+	if executable {
+		mode = exportExecutableMode
+	}
+	if err := w.writer.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path,
+		Mode:     mode,
+		Size:     size,
+		ModTime:  modificationTime,
+	}); err != nil {
+		return nil, err
+	}
+	return w.writer, nil
+}
+
+// symlink implements archiveWriter.symlink.
+func (w *tarArchiveWriter) symlink(path, target string, modificationTime time.Time) error {
+	return w.writer.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeSymlink,
+		Name:     path,
+		Linkname: target,
+		Mode:     exportSymlinkMode,
+		ModTime:  modificationTime,
+	})
+}
+
+// Close implements archiveWriter.Close.
+func (w *tarArchiveWriter) Close() error {
+	return w.writer.Close()
+}
+
+// zipArchiveWriter implements archiveWriter for zip archives.
+type zipArchiveWriter struct {
+	// writer is the underlying zip writer.
+	writer *zip.Writer
+}
+
+// directory implements archiveWriter.directory.
+func (w *zipArchiveWriter) directory(path string, modificationTime time.Time) error {
+	header := &zip.FileHeader{
+		Name:     path + "/",
+		Method:   zip.Store,
+		Modified: modificationTime,
+	}
+	header.SetMode(os.ModeDir | exportDirectoryMode)
+	_, err := w.writer.CreateHeader(header)
+	return err
+}
+
+// file implements archiveWriter.file.
+func (w *zipArchiveWriter) file(path string, executable bool, _ int64, modificationTime time.Time) (io.Writer, error) {
+	header := &zip.FileHeader{
+		Name:     path,
+		Method:   zip.Deflate,
+		Modified: modificationTime,
+	}
+	if executable {
+		header.SetMode(exportExecutableMode)
+	} else {
+		header.SetMode(exportFileMode)
+	}
+	return w.writer.CreateHeader(header)
+}
+
+// symlink implements archiveWriter.symlink. Zip archives represent symbolic
+// links as entries with the symbolic link mode bit set whose content is the
+// link target.
+func (w *zipArchiveWriter) symlink(path, target string, modificationTime time.Time) error {
+	header := &zip.FileHeader{
+		Name:     path,
+		Method:   zip.Store,
+		Modified: modificationTime,
+	}
+	header.SetMode(os.ModeSymlink | exportSymlinkMode)
+	writer, err := w.writer.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, target)
+	return err
+}
+
+// Close implements archiveWriter.Close.
+func (w *zipArchiveWriter) Close() error {
+	return w.writer.Close()
+}
+
+// exporter provides recursive snapshot export infrastructure.
+type exporter struct {
+	// cancelled is the cancellation channel.
+	cancelled <-chan struct{}
+	// cache is the cache corresponding to the snapshot. It may be nil.
+	cache *Cache
+	// hasher is the hasher used to verify file content. It may be nil.
+	hasher hash.Hash
+	// opener is the file opener.
+	opener *filesystem.Opener
+	// archive is the archive writer.
+	archive archiveWriter
+	// exportTime is the modification time used for content without a cache
+	// entry.
+	exportTime time.Time
+	// copyBuffer is the buffer used for copying file content.
+	copyBuffer []byte
+}
+
+// modificationTime computes the modification time for the content at the
+// specified path.
+func (e *exporter) modificationTime(path string) time.Time {
+	if cacheEntry, ok := e.cache.GetEntries()[path]; ok {
+		if modificationTime, err := ptypes.Timestamp(cacheEntry.ModificationTime); err == nil {
+			return modificationTime
+		}
+	}
+	return e.exportTime
+}
+
+// exportFile streams the content of the file at the specified path into the
+// archive under the specified archive path.
+func (e *exporter) exportFile(path, archivePath string, entry *Entry) error {
+	// Open the file and defer its closure.
+	file, err := e.opener.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "unable to open file")
+	}
+	defer file.Close()
+
+	// Determine the file size. We use the size of the open file (rather than
+	// any cached size), since the size has to be known in advance for some
+	// archive formats and it must reflect the content that we'll stream.
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return errors.Wrap(err, "unable to determine file size")
+	} else if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrap(err, "unable to seek to start of file")
+	}
+
+	// Create the archive entry.
+	destination, err := e.archive.file(archivePath, entry.Executable, size, e.modificationTime(path))
+	if err != nil {
+		return errors.Wrap(err, "unable to create archive entry")
+	}
+
+	// If we're verifying content, then hash content as it's streamed.
+	if e.hasher != nil {
+		e.hasher.Reset()
+		destination = io.MultiWriter(destination, e.hasher)
+	}
+
+	// Stream the file content, watching for preemption.
+	preemptable := &preemptableWriter{
+		cancelled:     e.cancelled,
+		writer:        destination,
+		checkInterval: exportCopyPreemptionInterval,
+	}
+	if copied, err := io.CopyBuffer(preemptable, io.LimitReader(file, size), e.copyBuffer); err != nil {
+		if err == errWritePreempted {
+			return err
+		}
+		return errors.Wrap(err, "unable to stream file content")
+	} else if copied != size {
+		return errExportContentModified
+	}
+
+	// Verify the content digest, if necessary.
+	if e.hasher != nil && !bytes.Equal(e.hasher.Sum(nil), entry.Digest) {
+		return errExportContentModified
+	}
+
+	// Success.
+	return nil
+}
+
+// export is the recursive export entry point. Directory contents are exported
+// in lexicographical order so that exported archives are deterministic and so
+// that file opening follows depth-first traversal order (which the opener is
+// optimized for).
+func (e *exporter) export(path, archivePath string, entry *Entry) error {
+	// Check for cancellation.
+	select {
+	case <-e.cancelled:
+		return errWritePreempted
+	default:
+	}
+
+	// Handle the entry based on type.
+	switch entry.Kind {
+	case EntryKind_Directory:
+		if archivePath != "" {
+			if err := e.archive.directory(archivePath, e.modificationTime(path)); err != nil {
+				return errors.Wrapf(err, "unable to export directory (%s)", path)
+			}
+		}
+		names := make([]string, 0, len(entry.Contents))
+		for name := range entry.Contents {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := e.export(pathJoin(path, name), pathJoin(archivePath, name), entry.Contents[name]); err != nil {
+				return err
+			}
+		}
+	case EntryKind_File:
+		if err := e.exportFile(path, archivePath, entry); err != nil {
+			if err == errWritePreempted {
+				return err
+			}
+			return errors.Wrapf(err, "unable to export file (%s)", path)
+		}
+	case EntryKind_Symlink:
+		if err := e.archive.symlink(archivePath, entry.Target, e.modificationTime(path)); err != nil {
+			return errors.Wrapf(err, "unable to export symbolic link (%s)", path)
+		}
+	default:
+		return errors.Errorf("unable to export unsynchronizable content (%s)", path)
+	}
+
+	// Success.
+	return nil
+}
+
+// Export streams the content described by a snapshot of the specified root to
+// the specified writer as an archive in the specified format. The snapshot
+// should be produced by Scan (and thus reflects its ignores and symbolic link
+// mode), with the cache from the same scan used (if provided) to determine
+// modification times. Content is read directly from disk and streamed to the
+// writer entry-by-entry, so neither the archive nor the files that it contains
+// are held in memory or staged. If a hasher is provided, then file content is
+// verified against the snapshot as it's streamed, with an error returned if
+// content has been modified since the scan (in which case the archive will be
+// incomplete). Since verification is performed on raw content, a hasher should
+// not be provided if the scan canonicalized line endings. For directory roots, archive paths are relative to the root,
+// while file roots are exported under the root's base name. Unsynchronizable
+// content within the snapshot results in an error. A nil snapshot yields an
+// empty archive.
+func Export(
+	ctx context.Context,
+	root string,
+	snapshot *Entry,
+	cache *Cache,
+	hasher hash.Hash,
+	format ExportFormat,
+	writer io.Writer,
+) error {
+	// Create the archive writer.
+	var archive archiveWriter
+	switch format {
+	case ExportFormatTar:
+		archive = &tarArchiveWriter{tar.NewWriter(writer)}
+	case ExportFormatZip:
+		archive = &zipArchiveWriter{zip.NewWriter(writer)}
+	default:
+		return errors.New("unknown export format")
+	}
+
+	// Export content, if any.
+	if snapshot != nil {
+		// Create an opener and defer its closure.
+		opener := filesystem.NewOpener(root)
+		defer opener.Close()
+
+		// Create the exporter.
+		exporter := &exporter{
+			cancelled:  ctx.Done(),
+			cache:      cache,
+			hasher:     hasher,
+			opener:     opener,
+			archive:    archive,
+			exportTime: time.Now(),
+			copyBuffer: make([]byte, exportCopyBufferSize),
+		}
+
+		// Perform the export.
+		var archivePath string
+		if snapshot.Kind != EntryKind_Directory {
+			archivePath = filepath.Base(root)
+		}
+		if err := exporter.export("", archivePath, snapshot); err != nil {
+			if err == errWritePreempted {
+				return errors.New("export cancelled")
+			}
+			return err
+		}
+	}
+
+	// Finalize the archive.
+	if err := archive.Close(); err != nil {
+		return errors.Wrap(err, "unable to finalize archive")
+	}
+
+	// Success.
+	return nil
+}
@@ -0,0 +1,334 @@
+package core
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+// testExportScan performs a scan of the specified root for export tests using
+// the specified ignores.
+func testExportScan(t *testing.T, root string, ignores []string) (*Entry, *Cache) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Perform the scan.
+	snapshot, _, _, cache, _, _, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		newTestHasher(),
+		nil,
+		ignores,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	}
+	return snapshot, cache
+}
+
+// testExportArchiveEntry describes an entry read from an exported archive.
+type testExportArchiveEntry struct {
+	// kind is the entry kind.
+	kind EntryKind
+	// content is the file content or symbolic link target.
+	content string
+	// executable indicates whether or not a file is executable.
+	executable bool
+}
+
+// readTestExportTar reads the entries of an exported tar archive.
+func readTestExportTar(t *testing.T, archive []byte) map[string]testExportArchiveEntry {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Read entries.
+	result := make(map[string]testExportArchiveEntry)
+	reader := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal("unable to read tar header:", err)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			result[header.Name] = testExportArchiveEntry{kind: EntryKind_Directory}
+		case tar.TypeReg:
+			content, err := ioutil.ReadAll(reader)
+			if err != nil {
+				t.Fatal("unable to read tar entry content:", err)
+			}
+			result[header.Name] = testExportArchiveEntry{
+				kind:       EntryKind_File,
+				content:    string(content),
+				executable: header.Mode&0111 != 0,
+			}
+		case tar.TypeSymlink:
+			result[header.Name] = testExportArchiveEntry{kind: EntryKind_Symlink, content: header.Linkname}
+		default:
+			t.Fatal("unexpected tar entry type:", header.Typeflag)
+		}
+	}
+	return result
+}
+
+// readTestExportZip reads the entries of an exported zip archive.
+func readTestExportZip(t *testing.T, archive []byte) map[string]testExportArchiveEntry {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Open the archive.
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal("unable to open zip archive:", err)
+	}
+
+	// Read entries.
+	result := make(map[string]testExportArchiveEntry)
+	for _, file := range reader.File {
+		mode := file.Mode()
+		if mode.IsDir() {
+			result[file.Name] = testExportArchiveEntry{kind: EntryKind_Directory}
+			continue
+		}
+		contentReader, err := file.Open()
+		if err != nil {
+			t.Fatal("unable to open zip entry:", err)
+		}
+		content, err := ioutil.ReadAll(contentReader)
+		contentReader.Close()
+		if err != nil {
+			t.Fatal("unable to read zip entry content:", err)
+		}
+		if mode&os.ModeSymlink != 0 {
+			result[file.Name] = testExportArchiveEntry{kind: EntryKind_Symlink, content: string(content)}
+		} else {
+			result[file.Name] = testExportArchiveEntry{
+				kind:       EntryKind_File,
+				content:    string(content),
+				executable: mode&0111 != 0,
+			}
+		}
+	}
+	return result
+}
+
+// TestExport tests that exported archives match the content on disk and
+// respect ignores.
+func TestExport(t *testing.T) {
+	// Create test content and defer its removal.
+	root, err := ioutil.TempDir("", "mutagen_export")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(root)
+	files := map[string]string{
+		"file":                "file content",
+		"directory/subfile":   "subfile content",
+		"directory/debug.log": "ignored content",
+		"ignored/file":        "ignored content",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0700); err != nil {
+			t.Fatal("unable to create parent directory:", err)
+		} else if err := ioutil.WriteFile(fullPath, []byte(content), 0600); err != nil {
+			t.Fatal("unable to create file:", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "empty"), 0700); err != nil {
+		t.Fatal("unable to create empty directory:", err)
+	}
+	if err := os.Symlink("directory/subfile", filepath.Join(root, "symlink")); err != nil {
+		if runtime.GOOS == "windows" {
+			t.Skip("symbolic links not supported")
+		}
+		t.Fatal("unable to create symbolic link:", err)
+	}
+
+	// Compute the expected archive entries. Executability can only be
+	// detected on POSIX systems.
+	expected := map[string]testExportArchiveEntry{
+		"file":              {kind: EntryKind_File, content: "file content"},
+		"directory/":        {kind: EntryKind_Directory},
+		"directory/subfile": {kind: EntryKind_File, content: "subfile content"},
+		"empty/":            {kind: EntryKind_Directory},
+		"symlink":           {kind: EntryKind_Symlink, content: "directory/subfile"},
+	}
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(filepath.Join(root, "file"), 0700); err != nil {
+			t.Fatal("unable to mark file as executable:", err)
+		}
+		expected["file"] = testExportArchiveEntry{kind: EntryKind_File, content: "file content", executable: true}
+	}
+
+	// Perform a scan with ignores.
+	snapshot, cache := testExportScan(t, root, []string{"*.log", "ignored"})
+
+	// Export the snapshot in each format and verify the archive contents.
+	formats := []struct {
+		format ExportFormat
+		reader func(*testing.T, []byte) map[string]testExportArchiveEntry
+	}{
+		{ExportFormatTar, readTestExportTar},
+		{ExportFormatZip, readTestExportZip},
+	}
+	for _, format := range formats {
+		buffer := &bytes.Buffer{}
+		if err := Export(context.Background(), root, snapshot, cache, newTestHasher(), format.format, buffer); err != nil {
+			t.Fatal("unable to export snapshot:", err)
+		}
+		entries := format.reader(t, buffer.Bytes())
+		if len(entries) != len(expected) {
+			t.Error("archive entry count incorrect:", len(entries), "!=", len(expected))
+		}
+		for path, entry := range expected {
+			if actual, ok := entries[path]; !ok {
+				t.Error("archive entry missing:", path)
+			} else if actual != entry {
+				t.Errorf("archive entry incorrect for %s: %+v != %+v", path, actual, entry)
+			}
+		}
+	}
+}
+
+// TestExportModifiedContent tests that export fails if content has been
+// modified since the snapshot was scanned.
+func TestExportModifiedContent(t *testing.T) {
+	// Create test content and defer its removal.
+	root, err := ioutil.TempDir("", "mutagen_export")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(root)
+	path := filepath.Join(root, "file")
+	if err := ioutil.WriteFile(path, []byte("original"), 0600); err != nil {
+		t.Fatal("unable to create file:", err)
+	}
+
+	// Perform a scan and then modify the file.
+	snapshot, cache := testExportScan(t, root, nil)
+	if err := ioutil.WriteFile(path, []byte("modified"), 0600); err != nil {
+		t.Fatal("unable to modify file:", err)
+	}
+
+	// Verify that verified export fails.
+	if err := Export(context.Background(), root, snapshot, cache, newTestHasher(), ExportFormatTar, ioutil.Discard); err == nil {
+		t.Error("export of modified content succeeded")
+	}
+}
+
+// TestExportCancelled tests that export respects cancellation.
+func TestExportCancelled(t *testing.T) {
+	// Create a cancelled context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Attempt an export.
+	snapshot := &Entry{Kind: EntryKind_Directory, Contents: map[string]*Entry{"file": testFile1Entry}}
+	if err := Export(ctx, "", snapshot, nil, nil, ExportFormatTar, ioutil.Discard); err == nil {
+		t.Error("cancelled export succeeded")
+	}
+}
+
+// TestExportLarge tests that exports of large trees are streamed rather than
+// buffered in memory.
+func TestExportLarge(t *testing.T) {
+	// Skip this test in short mode.
+	if testing.Short() {
+		t.Skip()
+	}
+
+	// Create a synthetic tree containing many small files and a few large
+	// (sparse) files and defer its removal.
+	root, err := ioutil.TempDir("", "mutagen_export")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(root)
+	const (
+		directoryCount         = 20
+		smallFilesPerDirectory = 50
+		largeFileCount         = 4
+		largeFileSize          = 32 * 1024 * 1024
+	)
+	for d := 0; d < directoryCount; d++ {
+		directory := filepath.Join(root, fmt.Sprintf("directory%d", d))
+		if err := os.Mkdir(directory, 0700); err != nil {
+			t.Fatal("unable to create directory:", err)
+		}
+		for f := 0; f < smallFilesPerDirectory; f++ {
+			path := filepath.Join(directory, fmt.Sprintf("file%d", f))
+			if err := ioutil.WriteFile(path, []byte(path), 0600); err != nil {
+				t.Fatal("unable to create small file:", err)
+			}
+		}
+	}
+	for l := 0; l < largeFileCount; l++ {
+		file, err := os.Create(filepath.Join(root, fmt.Sprintf("large%d", l)))
+		if err != nil {
+			t.Fatal("unable to create large file:", err)
+		}
+		err = file.Truncate(largeFileSize)
+		file.Close()
+		if err != nil {
+			t.Fatal("unable to size large file:", err)
+		}
+	}
+
+	// Perform a scan.
+	snapshot, cache := testExportScan(t, root, nil)
+
+	// Export the tree in each format, recording the memory allocated during
+	// the export. If the archive (or any large file) were buffered in memory,
+	// then allocations would exceed the total size of the large files.
+	const allocationLimit = largeFileSize / 2
+	for _, format := range []ExportFormat{ExportFormatTar, ExportFormatZip} {
+		counter := &testCountingWriter{}
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		if err := Export(context.Background(), root, snapshot, cache, nil, format, counter); err != nil {
+			t.Fatal("unable to export snapshot:", err)
+		}
+		runtime.ReadMemStats(&after)
+		if allocated := after.TotalAlloc - before.TotalAlloc; allocated > allocationLimit {
+			t.Errorf("export allocated excessive memory: %d bytes", allocated)
+		}
+		if format == ExportFormatTar && counter.count < largeFileCount*largeFileSize {
+			t.Error("tar archive smaller than its content:", counter.count)
+		}
+	}
+}
+
+// testCountingWriter is an io.Writer that counts and discards written bytes.
+type testCountingWriter struct {
+	// count is the number of bytes written.
+	count int64
+}
+
+// Write implements io.Writer.Write.
+func (w *testCountingWriter) Write(data []byte) (int, error) {
+	w.count += int64(len(data))
+	return len(data), nil
+}
@@ -0,0 +1,170 @@
+// +build darwin freebsd
+
+package core
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+)
+
+// testClearFileFlags clears the file flags for all content within the specified
+// directory so that it can be removed.
+func testClearFileFlags(root string) {
+	filepath.Walk(root, func(path string, _ os.FileInfo, _ error) error {
+		filesystem.WriteFileFlags(path, 0)
+		return nil
+	})
+}
+
+// TestFileFlagsScanTransitionRoundTrip tests that file flags captured by Scan
+// are restored by Transition.
+func TestFileFlagsScanTransitionRoundTrip(t *testing.T) {
+	// Create a temporary directory to hold all test content and defer its
+	// removal.
+	parent, err := ioutil.TempDir("", "mutagen_file_flags")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Create source content with the hidden flag on a directory and file.
+	source := filepath.Join(parent, "source")
+	contentMap := map[string][]byte{"directory/file": testFile1Contents}
+	if err := os.MkdirAll(filepath.Join(source, "directory"), 0700); err != nil {
+		t.Fatal("unable to create source directories:", err)
+	}
+	filePath := filepath.Join(source, "directory", "file")
+	if err := ioutil.WriteFile(filePath, testFile1Contents, 0600); err != nil {
+		t.Fatal("unable to create source file:", err)
+	}
+	for _, path := range []string{filePath, filepath.Join(source, "directory")} {
+		if err := filesystem.WriteFileFlags(path, filesystem.FileFlagHidden); err != nil {
+			t.Fatal("unable to set file flags:", err)
+		}
+	}
+
+	// Perform a scan and verify that flags were captured.
+	snapshot, _ := testFileFlagsScan(t, source)
+	directory := snapshot.Contents["directory"]
+	if directory == nil {
+		t.Fatal("directory missing from snapshot")
+	} else if directory.FileFlags != uint32(filesystem.FileFlagHidden) {
+		t.Error("directory flags not captured correctly:", filesystem.FileFlags(directory.FileFlags))
+	} else if file := directory.Contents["file"]; file == nil {
+		t.Fatal("file missing from snapshot")
+	} else if file.FileFlags != uint32(filesystem.FileFlagHidden) {
+		t.Error("file flags not captured correctly:", filesystem.FileFlags(file.FileFlags))
+	}
+
+	// Transition the snapshot to a new target.
+	target := filepath.Join(parent, "target")
+	if _, problems := testFileFlagsTransition(t, target, []*Change{{New: snapshot}}, nil, contentMap); len(problems) > 0 {
+		t.Fatal("transition encountered problems:", problems)
+	}
+
+	// Verify that flags were restored on the target.
+	for _, path := range []string{"directory", "directory/file"} {
+		if flags, err := filesystem.ReadFileFlags(filepath.Join(target, filepath.FromSlash(path))); err != nil {
+			t.Fatal("unable to read restored file flags:", err)
+		} else if flags != filesystem.FileFlagHidden {
+			t.Errorf("flags for %s not restored correctly: %s", path, flags)
+		}
+	}
+
+	// Verify that a rescan of the target yields the same snapshot and flags.
+	if targetSnapshot, _ := testFileFlagsScan(t, target); !targetSnapshot.Equal(snapshot) {
+		t.Error("target snapshot does not match source snapshot")
+	} else if file := targetSnapshot.Contents["directory"].Contents["file"]; file.FileFlags != uint32(filesystem.FileFlagHidden) {
+		t.Error("restored file flags not captured correctly")
+	}
+}
+
+// TestFileFlagsImmutableUpdate tests that Transition can update and remove
+// files whose immutable flag it's aware of (reapplying the flag on update), but
+// that it refuses to modify files that were locked outside of synchronization.
+func TestFileFlagsImmutableUpdate(t *testing.T) {
+	// Create a temporary directory to hold all test content and defer its
+	// removal (after clearing any file flags that would block it).
+	parent, err := ioutil.TempDir("", "mutagen_file_flags")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+	defer testClearFileFlags(parent)
+
+	// Create an immutable file.
+	root := filepath.Join(parent, "root")
+	original := testFile1Entry.copySlim()
+	original.FileFlags = uint32(filesystem.FileFlagImmutable)
+	initial := &Entry{
+		Kind:     EntryKind_Directory,
+		Contents: map[string]*Entry{"file": original},
+	}
+	contentMap := map[string][]byte{"file": testFile1Contents}
+	if _, problems := testFileFlagsTransition(t, root, []*Change{{New: initial}}, nil, contentMap); len(problems) > 0 {
+		t.Fatal("creation encountered problems:", problems)
+	}
+	filePath := filepath.Join(root, "file")
+	if flags, err := filesystem.ReadFileFlags(filePath); err != nil {
+		t.Fatal("unable to read file flags:", err)
+	} else if flags != filesystem.FileFlagImmutable {
+		t.Fatal("immutable flag not applied:", flags)
+	}
+
+	// Update the file's contents and verify that the update succeeds and that
+	// the immutable flag is reapplied.
+	snapshot, cache := testFileFlagsScan(t, root)
+	updated := testFile3Entry.copySlim()
+	updated.FileFlags = uint32(filesystem.FileFlagImmutable)
+	contentMap = map[string][]byte{"file": testFile3Contents}
+	transitions := []*Change{{Path: "file", Old: snapshot.Contents["file"], New: updated}}
+	if _, problems := testFileFlagsTransition(t, root, transitions, cache, contentMap); len(problems) > 0 {
+		t.Fatal("update encountered problems:", problems)
+	}
+	if contents, err := ioutil.ReadFile(filePath); err != nil {
+		t.Fatal("unable to read updated file:", err)
+	} else if !bytes.Equal(contents, testFile3Contents) {
+		t.Error("file contents not updated")
+	}
+	if flags, err := filesystem.ReadFileFlags(filePath); err != nil {
+		t.Fatal("unable to read file flags:", err)
+	} else if flags != filesystem.FileFlagImmutable {
+		t.Error("immutable flag not reapplied:", flags)
+	}
+
+	// Attempt an update using an expected entry that doesn't record the
+	// immutable flag (as if it were set after the last scan) and verify that
+	// it's refused without modifying the file or its flags.
+	snapshot, cache = testFileFlagsScan(t, root)
+	unaware := snapshot.Contents["file"].copySlim()
+	unaware.FileFlags = 0
+	contentMap = map[string][]byte{"file": testFile1Contents}
+	transitions = []*Change{{Path: "file", Old: unaware, New: testFile1Entry}}
+	if results, problems := testFileFlagsTransition(t, root, transitions, cache, contentMap); len(problems) == 0 {
+		t.Error("update of externally locked file succeeded")
+	} else if len(results) != 1 || results[0] != unaware {
+		t.Error("failed update did not yield original entry")
+	}
+	if contents, err := ioutil.ReadFile(filePath); err != nil {
+		t.Fatal("unable to read file:", err)
+	} else if !bytes.Equal(contents, testFile3Contents) {
+		t.Error("externally locked file was modified")
+	}
+	if flags, err := filesystem.ReadFileFlags(filePath); err != nil {
+		t.Fatal("unable to read file flags:", err)
+	} else if flags != filesystem.FileFlagImmutable {
+		t.Error("externally locked file flags were modified:", flags)
+	}
+
+	// Remove the content and verify that removal succeeds.
+	if _, problems := testFileFlagsTransition(t, root, []*Change{{Old: snapshot}}, cache, nil); len(problems) > 0 {
+		t.Fatal("removal encountered problems:", problems)
+	} else if _, err := os.Lstat(root); !os.IsNotExist(err) {
+		t.Error("root still exists after removal")
+	}
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+// testFileFlagsScan performs a scan of the specified root with file flag
+// preservation enabled.
+func testFileFlagsScan(t *testing.T, root string) (*Entry, *Cache) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Perform the scan.
+	snapshot, _, _, cache, _, _, err := Scan(
+		context.Background(),
+		root,
+		nil, nil,
+		newTestHasher(), nil,
+		nil, nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+			PreserveFileFlags: true,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	} else if err = snapshot.EnsureValid(); err != nil {
+		t.Fatal("scan produced invalid snapshot:", err)
+	}
+	return snapshot, cache
+}
+
+// testFileFlagsTransition performs a transition of the specified root with file
+// flag preservation enabled, using the specified content map to provide files.
+func testFileFlagsTransition(t *testing.T, root string, transitions []*Change, cache *Cache, contentMap map[string][]byte) ([]*Entry, []*Problem) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create a provider and defer its cleanup.
+	provider, err := newTestProvider(contentMap, newTestHasher())
+	if err != nil {
+		t.Fatal("unable to create test provider:", err)
+	}
+	defer provider.finalize()
+
+	// Perform the transition.
+	results, problems, providerMissingFiles := Transition(
+		context.Background(),
+		root,
+		transitions,
+		cache,
+		SymlinkMode_SymlinkModePortable,
+		defaultFilePermissionMode,
+		defaultDirectoryPermissionMode,
+		nil,
+		false,
+		provider,
+		&TransitionOptions{
+			DurabilityMode:    DurabilityMode_DurabilityModeNone,
+			Syncer:            filesystem.SystemSyncer,
+			ACLMode:           ACLMode_ACLModeIgnore,
+			PreserveFileFlags: true,
+		},
+	)
+	if providerMissingFiles {
+		t.Fatal("provider missing files during transition")
+	}
+	return results, problems
+}
+
+// TestEntryEnsureValidFileFlags tests that Entry.EnsureValid rejects invalid
+// file flag specifications.
+func TestEntryEnsureValidFileFlags(t *testing.T) {
+	valid := &Entry{
+		Kind:      EntryKind_File,
+		Digest:    testFile1ContentsSHA1,
+		FileFlags: uint32(filesystem.FileFlagHidden | filesystem.FileFlagImmutable),
+	}
+	if err := valid.EnsureValid(); err != nil {
+		t.Error("valid file flags treated as invalid:", err)
+	}
+	unknown := &Entry{
+		Kind:      EntryKind_Directory,
+		FileFlags: uint32(filesystem.FileFlagsAll) + 1,
+	}
+	if unknown.EnsureValid() == nil {
+		t.Error("unknown file flags treated as valid")
+	}
+	symlink := &Entry{
+		Kind:      EntryKind_Symlink,
+		Target:    "file",
+		FileFlags: uint32(filesystem.FileFlagHidden),
+	}
+	if symlink.EnsureValid() == nil {
+		t.Error("symbolic link file flags treated as valid")
+	}
+}
+
+// TestTransitionUnsupportedFileFlags tests that file flags that can't be
+// represented on the current platform are reported as problems without
+// preventing content creation.
+func TestTransitionUnsupportedFileFlags(t *testing.T) {
+	// This test only applies to platforms without file flag support.
+	if filesystem.SupportedFileFlags != 0 {
+		t.Skip()
+	}
+
+	// Create a temporary directory and defer its removal.
+	parent, err := ioutil.TempDir("", "mutagen_file_flags")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Transition content that has file flags.
+	file := testFile1Entry.copySlim()
+	file.FileFlags = uint32(filesystem.FileFlagHidden)
+	target := &Entry{
+		Kind:     EntryKind_Directory,
+		Contents: map[string]*Entry{"file": file},
+	}
+	results, problems := testFileFlagsTransition(t,
+		filepath.Join(parent, "root"),
+		[]*Change{{New: target}},
+		nil,
+		map[string][]byte{"file": testFile1Contents},
+	)
+
+	// Verify that the content was created and that the flags were reported.
+	if len(results) != 1 || !results[0].Equal(target) {
+		t.Error("content not created correctly")
+	}
+	if len(problems) != 1 {
+		t.Fatal("unexpected number of problems:", len(problems))
+	} else if problems[0].Path != "file" {
+		t.Error("unexpected problem path:", problems[0].Path)
+	}
+}
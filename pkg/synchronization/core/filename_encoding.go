@@ -0,0 +1,106 @@
+package core
+
+import (
+	"github.com/pkg/errors"
+)
+
+// IsDefault indicates whether or not the filename encoding is
+// FilenameEncoding_FilenameEncodingDefault.
+func (e FilenameEncoding) IsDefault() bool {
+	return e == FilenameEncoding_FilenameEncodingDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (e *FilenameEncoding) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to a filename encoding.
+	switch text {
+	case "utf-8":
+		*e = FilenameEncoding_FilenameEncodingUTF8
+	case "iso-8859-1":
+		*e = FilenameEncoding_FilenameEncodingISO88591
+	case "iso-8859-15":
+		*e = FilenameEncoding_FilenameEncodingISO885915
+	case "windows-1252":
+		*e = FilenameEncoding_FilenameEncodingWindows1252
+	case "koi8-r":
+		*e = FilenameEncoding_FilenameEncodingKOI8R
+	case "shift-jis":
+		*e = FilenameEncoding_FilenameEncodingShiftJIS
+	case "euc-jp":
+		*e = FilenameEncoding_FilenameEncodingEUCJP
+	case "euc-kr":
+		*e = FilenameEncoding_FilenameEncodingEUCKR
+	case "gbk":
+		*e = FilenameEncoding_FilenameEncodingGBK
+	case "big5":
+		*e = FilenameEncoding_FilenameEncodingBig5
+	default:
+		return errors.Errorf("unknown filename encoding specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular filename encoding is a
+// valid, non-default value.
+func (e FilenameEncoding) Supported() bool {
+	switch e {
+	case FilenameEncoding_FilenameEncodingUTF8:
+		return true
+	case FilenameEncoding_FilenameEncodingISO88591:
+		return true
+	case FilenameEncoding_FilenameEncodingISO885915:
+		return true
+	case FilenameEncoding_FilenameEncodingWindows1252:
+		return true
+	case FilenameEncoding_FilenameEncodingKOI8R:
+		return true
+	case FilenameEncoding_FilenameEncodingShiftJIS:
+		return true
+	case FilenameEncoding_FilenameEncodingEUCJP:
+		return true
+	case FilenameEncoding_FilenameEncodingEUCKR:
+		return true
+	case FilenameEncoding_FilenameEncodingGBK:
+		return true
+	case FilenameEncoding_FilenameEncodingBig5:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of a filename encoding.
+func (e FilenameEncoding) Description() string {
+	switch e {
+	case FilenameEncoding_FilenameEncodingDefault:
+		return "Default"
+	case FilenameEncoding_FilenameEncodingUTF8:
+		return "UTF-8"
+	case FilenameEncoding_FilenameEncodingISO88591:
+		return "ISO-8859-1"
+	case FilenameEncoding_FilenameEncodingISO885915:
+		return "ISO-8859-15"
+	case FilenameEncoding_FilenameEncodingWindows1252:
+		return "Windows-1252"
+	case FilenameEncoding_FilenameEncodingKOI8R:
+		return "KOI8-R"
+	case FilenameEncoding_FilenameEncodingShiftJIS:
+		return "Shift JIS"
+	case FilenameEncoding_FilenameEncodingEUCJP:
+		return "EUC-JP"
+	case FilenameEncoding_FilenameEncodingEUCKR:
+		return "EUC-KR"
+	case FilenameEncoding_FilenameEncodingGBK:
+		return "GBK"
+	case FilenameEncoding_FilenameEncodingBig5:
+		return "Big5"
+	default:
+		return "Unknown"
+	}
+}
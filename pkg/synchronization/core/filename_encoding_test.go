@@ -0,0 +1,103 @@
+package core
+
+import (
+	"testing"
+)
+
+// TestFilenameEncodingUnmarshal tests that unmarshaling from a string
+// specification succeeeds for FilenameEncoding.
+func TestFilenameEncodingUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text             string
+		expectedEncoding FilenameEncoding
+		expectFailure    bool
+	}{
+		{"", FilenameEncoding_FilenameEncodingDefault, true},
+		{"asdf", FilenameEncoding_FilenameEncodingDefault, true},
+		{"utf-8", FilenameEncoding_FilenameEncodingUTF8, false},
+		{"iso-8859-1", FilenameEncoding_FilenameEncodingISO88591, false},
+		{"iso-8859-15", FilenameEncoding_FilenameEncodingISO885915, false},
+		{"windows-1252", FilenameEncoding_FilenameEncodingWindows1252, false},
+		{"koi8-r", FilenameEncoding_FilenameEncodingKOI8R, false},
+		{"shift-jis", FilenameEncoding_FilenameEncodingShiftJIS, false},
+		{"euc-jp", FilenameEncoding_FilenameEncodingEUCJP, false},
+		{"euc-kr", FilenameEncoding_FilenameEncodingEUCKR, false},
+		{"gbk", FilenameEncoding_FilenameEncodingGBK, false},
+		{"big5", FilenameEncoding_FilenameEncodingBig5, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var encoding FilenameEncoding
+		if err := encoding.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if encoding != testCase.expectedEncoding {
+			t.Errorf(
+				"unmarshaled encoding (%s) does not match expected (%s)",
+				encoding,
+				testCase.expectedEncoding,
+			)
+		}
+	}
+}
+
+// TestFilenameEncodingSupported tests that FilenameEncoding support detection
+// works as expected.
+func TestFilenameEncodingSupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		encoding        FilenameEncoding
+		expectSupported bool
+	}{
+		{FilenameEncoding_FilenameEncodingDefault, false},
+		{FilenameEncoding_FilenameEncodingUTF8, true},
+		{FilenameEncoding_FilenameEncodingISO88591, true},
+		{FilenameEncoding_FilenameEncodingShiftJIS, true},
+		{FilenameEncoding_FilenameEncodingBig5, true},
+		{(FilenameEncoding_FilenameEncodingBig5 + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.encoding.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"encoding support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestFilenameEncodingDescription tests that FilenameEncoding description
+// generation works as expected.
+func TestFilenameEncodingDescription(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		encoding            FilenameEncoding
+		expectedDescription string
+	}{
+		{FilenameEncoding_FilenameEncodingDefault, "Default"},
+		{FilenameEncoding_FilenameEncodingUTF8, "UTF-8"},
+		{FilenameEncoding_FilenameEncodingISO88591, "ISO-8859-1"},
+		{FilenameEncoding_FilenameEncodingShiftJIS, "Shift JIS"},
+		{FilenameEncoding_FilenameEncodingBig5, "Big5"},
+		{(FilenameEncoding_FilenameEncodingBig5 + 1), "Unknown"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if description := testCase.encoding.Description(); description != testCase.expectedDescription {
+			t.Errorf(
+				"encoding description (%s) does not match expected (%s)",
+				description,
+				testCase.expectedDescription,
+			)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// FilenameTranscoder transcodes names (and symbolic link targets) between the
+// UTF-8 representation used for synchronization and the encoding used on disk.
+// Transcoding is only performed for names that round-trip exactly, which
+// prevents distinct on-disk names from mapping to the same synchronized name
+// (and vice versa). All supported encodings are ASCII-compatible, so path
+// separators are unaffected by transcoding. A nil transcoder performs no
+// transcoding. It is stateless and thus safe for concurrent usage.
+type FilenameTranscoder struct {
+	// filenameEncoding is the on-disk filename encoding.
+	filenameEncoding FilenameEncoding
+	// encoding is the implementation of the on-disk filename encoding.
+	encoding encoding.Encoding
+}
+
+// NewFilenameTranscoder creates a filename transcoder for the specified
+// filename encoding. It returns nil if the encoding is UTF-8 (or unspecified)
+// or if the current platform stores names as Unicode, in which case no
+// transcoding is necessary.
+func NewFilenameTranscoder(filenameEncoding FilenameEncoding) *FilenameTranscoder {
+	// Names are always stored as Unicode on Windows.
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	// Determine the encoding implementation.
+	var implementation encoding.Encoding
+	switch filenameEncoding {
+	case FilenameEncoding_FilenameEncodingISO88591:
+		implementation = charmap.ISO8859_1
+	case FilenameEncoding_FilenameEncodingISO885915:
+		implementation = charmap.ISO8859_15
+	case FilenameEncoding_FilenameEncodingWindows1252:
+		implementation = charmap.Windows1252
+	case FilenameEncoding_FilenameEncodingKOI8R:
+		implementation = charmap.KOI8R
+	case FilenameEncoding_FilenameEncodingShiftJIS:
+		implementation = japanese.ShiftJIS
+	case FilenameEncoding_FilenameEncodingEUCJP:
+		implementation = japanese.EUCJP
+	case FilenameEncoding_FilenameEncodingEUCKR:
+		implementation = korean.EUCKR
+	case FilenameEncoding_FilenameEncodingGBK:
+		implementation = simplifiedchinese.GBK
+	case FilenameEncoding_FilenameEncodingBig5:
+		implementation = traditionalchinese.Big5
+	default:
+		return nil
+	}
+
+	// Create the transcoder.
+	return &FilenameTranscoder{
+		filenameEncoding: filenameEncoding,
+		encoding:         implementation,
+	}
+}
+
+// isASCII indicates whether or not a string consists solely of ASCII
+// characters, in which case it's represented identically by all supported
+// encodings.
+func isASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// decode converts an on-disk name (or symbolic link target) to UTF-8. It
+// returns false if the value can't be decoded or wouldn't round-trip.
+func (t *FilenameTranscoder) decode(value string) (string, bool) {
+	// Handle cases where no transcoding is necessary.
+	if t == nil || isASCII(value) {
+		return value, true
+	}
+
+	// Perform decoding. Decoders replace invalid sequences with the Unicode
+	// replacement character rather than failing, so we have to check for it
+	// explicitly. None of the supported encodings can represent it.
+	decoded, err := t.encoding.NewDecoder().String(value)
+	if err != nil || strings.ContainsRune(decoded, utf8.RuneError) {
+		return "", false
+	}
+
+	// Verify that the value round-trips.
+	if encoded, err := t.encoding.NewEncoder().String(decoded); err != nil || encoded != value {
+		return "", false
+	}
+
+	// Success.
+	return decoded, true
+}
+
+// encode converts a UTF-8 name (or symbolic link target) to its on-disk
+// representation. It returns false if the value can't be represented or
+// wouldn't round-trip.
+func (t *FilenameTranscoder) encode(value string) (string, bool) {
+	// Handle cases where no transcoding is necessary.
+	if t == nil || isASCII(value) {
+		return value, true
+	}
+
+	// Perform encoding.
+	encoded, err := t.encoding.NewEncoder().String(value)
+	if err != nil {
+		return "", false
+	}
+
+	// Verify that the value round-trips. Some encoders map multiple characters
+	// to the same on-disk representation.
+	if decoded, err := t.encoding.NewDecoder().String(encoded); err != nil || decoded != value {
+		return "", false
+	}
+
+	// Success.
+	return encoded, true
+}
+
+// undecodableNameProblem creates a problem describing content with the
+// specified on-disk name (located within the specified parent path) that
+// couldn't be decoded. Since the name isn't valid UTF-8, invalid sequences are
+// replaced with the Unicode replacement character in the problem path.
+func (t *FilenameTranscoder) undecodableNameProblem(parent, name string) *Problem {
+	return &Problem{
+		Path: pathJoin(parent, strings.ToValidUTF8(name, string(utf8.RuneError))),
+		Error: fmt.Sprintf("content skipped: name can't be decoded using filename encoding (%s)",
+			t.filenameEncoding.Description(),
+		),
+	}
+}
+
+// EncodePath computes the on-disk representation of a synchronization path. It
+// returns false if the path can't be represented.
+func (t *FilenameTranscoder) EncodePath(path string) (string, bool) {
+	if t == nil {
+		return path, true
+	}
+	return translatePath(path, t.encode)
+}
+
+// DecodePath computes the synchronization path for an on-disk path. It returns
+// false if the path can't be decoded.
+func (t *FilenameTranscoder) DecodePath(path string) (string, bool) {
+	if t == nil {
+		return path, true
+	}
+	return translatePath(path, t.decode)
+}
+
+// DecodeRecheckPath computes the synchronization path to re-check in response
+// to a change reported at the specified on-disk path. If the path can't be
+// decoded, then the synchronization path of its nearest decodable parent is
+// returned, since re-checking that parent will identify (and report) the
+// content that can't be decoded.
+func (t *FilenameTranscoder) DecodeRecheckPath(path string) string {
+	for {
+		if decoded, ok := t.DecodePath(path); ok {
+			return decoded
+		}
+		path = pathDir(path)
+	}
+}
+
+// FilesystemPath computes the on-disk path for the specified synchronization
+// path within the specified root. If the synchronization path can't be
+// represented, then it's used without transcoding, in which case operations on
+// the resulting path will fail to find the content.
+func (t *FilenameTranscoder) FilesystemPath(root, path string) string {
+	if encoded, ok := t.EncodePath(path); ok {
+		path = encoded
+	}
+	return filepath.Join(root, filepath.FromSlash(path))
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+// testFilenameTranscodingScan performs a scan of the specified root using the
+// specified filename transcoder.
+func testFilenameTranscodingScan(t *testing.T, root string, filenames *FilenameTranscoder) (*Entry, []*Problem) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Perform the scan.
+	snapshot, _, _, _, _, skipped, err := Scan(
+		context.Background(),
+		root,
+		nil, nil,
+		newTestHasher(), nil,
+		nil, nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+			Filenames:         filenames,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	} else if err = snapshot.EnsureValid(); err != nil {
+		t.Fatal("scan produced invalid snapshot:", err)
+	}
+	return snapshot, skipped
+}
+
+// TestFilenameTranscodingScanTransitionRoundTrip tests that Scan decodes
+// non-UTF-8 names to UTF-8 (reporting names that can't be decoded) and that
+// Transition encodes them back to their on-disk representation.
+func TestFilenameTranscodingScanTransitionRoundTrip(t *testing.T) {
+	// Create a temporary directory to hold all test content and defer its
+	// removal.
+	parent, err := ioutil.TempDir("", "mutagen_filename_transcoding")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Create source content with Shift JIS encoded names, including a name
+	// that isn't valid Shift JIS.
+	filenames := NewFilenameTranscoder(FilenameEncoding_FilenameEncodingShiftJIS)
+	source := filepath.Join(parent, "source")
+	if err := os.MkdirAll(filepath.Join(source, "\x93\xfa\x96{"), 0700); err != nil {
+		t.Fatal("unable to create source directory:", err)
+	}
+	contentMap := map[string][]byte{
+		"日本/テスト.txt": []byte("transcoded file"),
+		"file":       []byte("plain file"),
+	}
+	onDiskContent := map[string][]byte{
+		"\x93\xfa\x96{/\x83e\x83X\x83g.txt": contentMap["日本/テスト.txt"],
+		"file":                              contentMap["file"],
+		"\x93\xfa\x96{/bad\x81":             []byte("undecodable file"),
+	}
+	for path, data := range onDiskContent {
+		if err := ioutil.WriteFile(filepath.Join(source, path), data, 0600); err != nil {
+			t.Fatal("unable to create source file:", err)
+		}
+	}
+
+	// Perform a scan and verify that names were decoded.
+	snapshot, skipped := testFilenameTranscodingScan(t, source, filenames)
+	if len(snapshot.Contents) != 2 {
+		t.Fatal("unexpected number of root entries:", len(snapshot.Contents))
+	} else if directory := snapshot.Contents["日本"]; directory == nil || directory.Kind != EntryKind_Directory {
+		t.Fatal("transcoded directory missing from snapshot")
+	} else if len(directory.Contents) != 1 || directory.Contents["テスト.txt"] == nil {
+		t.Fatal("transcoded file missing from snapshot")
+	}
+
+	// Verify that the undecodable name was reported.
+	if len(skipped) != 1 {
+		t.Fatal("unexpected number of skipped content problems:", len(skipped))
+	} else if skipped[0].Path != "日本/bad�" {
+		t.Errorf("skipped content path (%q) does not match expected", skipped[0].Path)
+	} else if !strings.Contains(skipped[0].Error, "can't be decoded") {
+		t.Error("unexpected skipped content problem:", skipped[0].Error)
+	}
+
+	// Create a provider and defer its cleanup.
+	provider, err := newTestProvider(contentMap, newTestHasher())
+	if err != nil {
+		t.Fatal("unable to create test provider:", err)
+	}
+	defer provider.finalize()
+
+	// Transition the snapshot to a new location.
+	target := filepath.Join(parent, "target")
+	_, problems, providerMissingFiles := Transition(
+		context.Background(),
+		target,
+		[]*Change{{New: snapshot}},
+		nil,
+		SymlinkMode_SymlinkModePortable,
+		defaultFilePermissionMode,
+		defaultDirectoryPermissionMode,
+		nil,
+		false,
+		provider,
+		&TransitionOptions{
+			DurabilityMode: DurabilityMode_DurabilityModeNone,
+			Syncer:         filesystem.SystemSyncer,
+			ACLMode:        ACLMode_ACLModeIgnore,
+			Filenames:      filenames,
+		},
+	)
+	if providerMissingFiles {
+		t.Fatal("provider missing files during transition")
+	} else if len(problems) != 0 {
+		t.Fatal("transition problems encountered:", problems[0].Path, problems[0].Error)
+	}
+
+	// Verify that content was created using encoded names.
+	for path, data := range onDiskContent {
+		if strings.HasSuffix(path, "\x81") {
+			continue
+		}
+		if contents, err := ioutil.ReadFile(filepath.Join(target, path)); err != nil {
+			t.Errorf("unable to read encoded path (%q): %v", path, err)
+		} else if string(contents) != string(data) {
+			t.Errorf("content mismatch at encoded path (%q)", path)
+		}
+	}
+
+	// Verify that a rescan of the target yields the same snapshot.
+	if rescanned, _ := testFilenameTranscodingScan(t, target, filenames); !rescanned.Equal(snapshot) {
+		t.Error("rescanned snapshot does not match original")
+	}
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"runtime"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestNewFilenameTranscoderUTF8 tests that no transcoder is created for UTF-8
+// (or unspecified) filename encodings and that a nil transcoder performs no
+// transcoding.
+func TestNewFilenameTranscoderUTF8(t *testing.T) {
+	// Verify that no transcoder is created.
+	if NewFilenameTranscoder(FilenameEncoding_FilenameEncodingDefault) != nil {
+		t.Error("transcoder created for default filename encoding")
+	}
+	if NewFilenameTranscoder(FilenameEncoding_FilenameEncodingUTF8) != nil {
+		t.Error("transcoder created for UTF-8 filename encoding")
+	}
+
+	// Verify that a nil transcoder passes paths through unmodified.
+	var transcoder *FilenameTranscoder
+	if encoded, ok := transcoder.EncodePath("directory/café"); !ok || encoded != "directory/café" {
+		t.Error("nil transcoder modified path when encoding:", encoded)
+	}
+	if decoded, ok := transcoder.DecodePath("directory/café"); !ok || decoded != "directory/café" {
+		t.Error("nil transcoder modified path when decoding:", decoded)
+	}
+}
+
+// TestFilenameTranscoderRoundTrip tests that paths are transcoded to and from
+// non-UTF-8 encodings correctly.
+func TestFilenameTranscoderRoundTrip(t *testing.T) {
+	// Names are never transcoded on Windows.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Set up test cases.
+	testCases := []struct {
+		encoding FilenameEncoding
+		path     string
+		expected string
+	}{
+		{FilenameEncoding_FilenameEncodingISO88591, "directory/file", "directory/file"},
+		{FilenameEncoding_FilenameEncodingISO88591, "directory/café", "directory/caf\xe9"},
+		{FilenameEncoding_FilenameEncodingKOI8R, "привет/file", "\xd0\xd2\xc9\xd7\xc5\xd4/file"},
+		{FilenameEncoding_FilenameEncodingShiftJIS, "日本/テスト.txt", "\x93\xfa\x96{/\x83e\x83X\x83g.txt"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		transcoder := NewFilenameTranscoder(testCase.encoding)
+		if transcoder == nil {
+			t.Fatal("no transcoder created for filename encoding:", testCase.encoding)
+		}
+		if encoded, ok := transcoder.EncodePath(testCase.path); !ok {
+			t.Errorf("unable to encode path (%s) using %s", testCase.path, testCase.encoding.Description())
+		} else if encoded != testCase.expected {
+			t.Errorf("encoded path (%q) does not match expected (%q)", encoded, testCase.expected)
+		} else if decoded, ok := transcoder.DecodePath(encoded); !ok {
+			t.Errorf("unable to decode path (%q) using %s", encoded, testCase.encoding.Description())
+		} else if decoded != testCase.path {
+			t.Errorf("decoded path (%s) does not match original (%s)", decoded, testCase.path)
+		}
+	}
+}
+
+// TestFilenameTranscoderFailure tests that transcoding fails for paths that
+// can't be represented in (or decoded from) non-UTF-8 encodings.
+func TestFilenameTranscoderFailure(t *testing.T) {
+	// Names are never transcoded on Windows.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Verify that paths that can't be represented fail to encode.
+	latin1 := NewFilenameTranscoder(FilenameEncoding_FilenameEncodingISO88591)
+	if _, ok := latin1.EncodePath("directory/日本"); ok {
+		t.Error("encoding succeeded for unrepresentable path")
+	}
+
+	// Verify that invalid sequences fail to decode.
+	shiftJIS := NewFilenameTranscoder(FilenameEncoding_FilenameEncodingShiftJIS)
+	if _, ok := shiftJIS.DecodePath("directory/bad\x81"); ok {
+		t.Error("decoding succeeded for invalid path")
+	}
+
+	// Verify that re-check paths fall back to their nearest decodable parent.
+	if path := shiftJIS.DecodeRecheckPath("\x93\xfa\x96{/bad\xff/file"); path != "日本" {
+		t.Errorf("re-check path (%s) does not match expected (日本)", path)
+	}
+	if path := shiftJIS.DecodeRecheckPath("bad\xff"); path != "" {
+		t.Errorf("re-check path (%s) is not synchronization root", path)
+	}
+
+	// Verify that undecodable name problems have valid UTF-8 paths.
+	problem := shiftJIS.undecodableNameProblem("directory", "bad\x81")
+	if problem.Path != "directory/bad�" {
+		t.Errorf("problem path (%q) does not match expected", problem.Path)
+	} else if !utf8.ValidString(problem.Path) {
+		t.Error("undecodable name problem path is not valid UTF-8")
+	}
+}
@@ -0,0 +1,279 @@
+// +build !windows
+
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+// testHardLinkScan performs a scan of the specified root for hard link tests.
+func testHardLinkScan(t *testing.T, root string, preserveHardLinks bool) *Entry {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Perform the scan.
+	snapshot, _, _, _, _, _, err := Scan(
+		context.Background(),
+		root,
+		nil, nil,
+		newTestHasher(), nil,
+		nil, nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+			PreserveHardLinks: preserveHardLinks,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	} else if err = snapshot.EnsureValid(); err != nil {
+		t.Fatal("scan produced invalid snapshot:", err)
+	}
+	return snapshot
+}
+
+// testHardLinkTransition transitions the specified snapshot to a new target
+// root for hard link tests, returning any problems that occur.
+func testHardLinkTransition(t *testing.T, target string, snapshot *Entry, contentMap map[string][]byte, preserveHardLinks bool) []*Problem {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create a provider and defer its cleanup.
+	provider, err := newTestProvider(contentMap, newTestHasher())
+	if err != nil {
+		t.Fatal("unable to create test provider:", err)
+	}
+	defer provider.finalize()
+
+	// Perform the transition.
+	_, problems, providerMissingFiles := Transition(
+		context.Background(),
+		target,
+		[]*Change{{New: snapshot}},
+		nil,
+		SymlinkMode_SymlinkModePortable,
+		defaultFilePermissionMode,
+		defaultDirectoryPermissionMode,
+		nil,
+		false,
+		provider,
+		&TransitionOptions{
+			DurabilityMode:    DurabilityMode_DurabilityModeNone,
+			Syncer:            filesystem.SystemSyncer,
+			ACLMode:           ACLMode_ACLModeIgnore,
+			PreserveHardLinks: preserveHardLinks,
+		},
+	)
+	if providerMissingFiles {
+		t.Fatal("provider missing files during transition")
+	}
+	return problems
+}
+
+// testSameFile determines whether or not the specified paths within the
+// specified root reference the same underlying file.
+func testSameFile(t *testing.T, root, first, second string) bool {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Grab metadata for both paths and compare.
+	firstInfo, err := os.Stat(filepath.Join(root, first))
+	if err != nil {
+		t.Fatal("unable to grab file metadata:", err)
+	}
+	secondInfo, err := os.Stat(filepath.Join(root, second))
+	if err != nil {
+		t.Fatal("unable to grab file metadata:", err)
+	}
+	return os.SameFile(firstInfo, secondInfo)
+}
+
+// TestHardLinkScanTransitionRoundTrip tests that hard links are identified by
+// Scan and recreated by Transition when hard link preservation is enabled.
+func TestHardLinkScanTransitionRoundTrip(t *testing.T) {
+	// Create a temporary directory to hold all test content and defer its
+	// removal.
+	parent, err := ioutil.TempDir("", "mutagen_hard_link")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Create source content, with two hard links to the top-level file.
+	source := filepath.Join(parent, "source")
+	contentMap := map[string][]byte{
+		"file":            []byte("shared content"),
+		"directory/link":  []byte("shared content"),
+		"directory/other": []byte("other content"),
+		"link":            []byte("shared content"),
+	}
+	if err := os.MkdirAll(filepath.Join(source, "directory"), 0700); err != nil {
+		t.Fatal("unable to create source directories:", err)
+	}
+	for _, path := range []string{"file", "directory/other"} {
+		if err := ioutil.WriteFile(filepath.Join(source, path), contentMap[path], 0600); err != nil {
+			t.Fatal("unable to create source file:", err)
+		}
+	}
+	for _, path := range []string{"directory/link", "link"} {
+		if err := os.Link(filepath.Join(source, "file"), filepath.Join(source, path)); err != nil {
+			t.Fatal("unable to create source hard link:", err)
+		}
+	}
+
+	// Verify that hard links aren't recorded when preservation is disabled.
+	snapshot := testHardLinkScan(t, source, false)
+	snapshot.walk("", func(path string, entry *Entry) {
+		if entry.HardLink != "" {
+			t.Error("hard link recorded with preservation disabled:", path)
+		}
+	})
+
+	// Perform a scan with preservation enabled and verify that the hard link
+	// structure was recorded relative to the lowest path.
+	snapshot = testHardLinkScan(t, source, true)
+	expectedHardLinks := map[string]string{
+		"file":            "directory/link",
+		"directory/link":  "",
+		"directory/other": "",
+		"link":            "directory/link",
+	}
+	for path, expected := range expectedHardLinks {
+		entry := snapshot.Contents[path]
+		if dir := pathDir(path); dir != "" {
+			entry = snapshot.Contents[dir].Contents[PathBase(path)]
+		}
+		if entry == nil {
+			t.Fatal("entry missing from snapshot:", path)
+		} else if entry.HardLink != expected {
+			t.Errorf("hard link incorrect for %s: %q != %q", path, entry.HardLink, expected)
+		}
+	}
+
+	// Transition the snapshot to a new location and verify that the hard link
+	// structure was recreated.
+	target := filepath.Join(parent, "target")
+	if problems := testHardLinkTransition(t, target, snapshot, contentMap, true); len(problems) != 0 {
+		t.Fatal("problems occurred during transition:", problems[0].Error)
+	}
+	if !testSameFile(t, target, "directory/link", "file") {
+		t.Error("file not linked to link target")
+	}
+	if !testSameFile(t, target, "directory/link", "link") {
+		t.Error("link not linked to link target")
+	}
+	if testSameFile(t, target, "directory/link", "directory/other") {
+		t.Error("unrelated file linked to link target")
+	}
+	for path, content := range contentMap {
+		if data, err := ioutil.ReadFile(filepath.Join(target, path)); err != nil {
+			t.Fatal("unable to read transitioned file:", err)
+		} else if string(data) != string(content) {
+			t.Error("transitioned file content incorrect:", path)
+		}
+	}
+
+	// Verify that a scan of the target yields the same hard link structure.
+	targetSnapshot := testHardLinkScan(t, target, true)
+	if !targetSnapshot.Equal(snapshot) {
+		t.Error("target snapshot does not match source snapshot")
+	}
+	targetSnapshot.walk("", func(path string, entry *Entry) {
+		if entry.Kind == EntryKind_File && entry.HardLink != expectedHardLinks[path] {
+			t.Errorf("target hard link incorrect for %s: %q != %q", path, entry.HardLink, expectedHardLinks[path])
+		}
+	})
+
+	// Transition the snapshot to another location with preservation disabled
+	// and verify that separate copies are created.
+	copies := filepath.Join(parent, "copies")
+	if problems := testHardLinkTransition(t, copies, snapshot, contentMap, false); len(problems) != 0 {
+		t.Fatal("problems occurred during transition:", problems[0].Error)
+	}
+	if testSameFile(t, copies, "directory/link", "file") {
+		t.Error("file linked with preservation disabled")
+	}
+}
+
+// TestHardLinkScanBaselineUpdate tests that hard link structure is updated for
+// content that's re-used from a baseline during an accelerated scan without
+// modifying the baseline itself.
+func TestHardLinkScanBaselineUpdate(t *testing.T) {
+	// Create a temporary directory to serve as the root and defer its removal.
+	root, err := ioutil.TempDir("", "mutagen_hard_link")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(root)
+
+	// Create content.
+	if err := os.Mkdir(filepath.Join(root, "directory"), 0700); err != nil {
+		t.Fatal("unable to create directory:", err)
+	} else if err := ioutil.WriteFile(filepath.Join(root, "directory", "file"), []byte("content"), 0600); err != nil {
+		t.Fatal("unable to create file:", err)
+	}
+
+	// Perform a baseline scan.
+	baseline, _, _, cache, ignoreCache, _, err := Scan(
+		context.Background(),
+		root,
+		nil, nil,
+		newTestHasher(), nil,
+		nil, nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+			PreserveHardLinks: true,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform baseline scan:", err)
+	}
+
+	// Create a hard link (with a lower path) outside of the baseline directory
+	// and perform an accelerated scan that only re-checks the new path.
+	if err := os.Link(filepath.Join(root, "directory", "file"), filepath.Join(root, "a")); err != nil {
+		t.Fatal("unable to create hard link:", err)
+	}
+	snapshot, _, _, _, _, _, err := Scan(
+		context.Background(),
+		root,
+		baseline, map[string]bool{"a": true},
+		newTestHasher(), cache,
+		nil, ignoreCache,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+			PreserveHardLinks: true,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform accelerated scan:", err)
+	}
+
+	// Verify the hard link structure.
+	if hardLink := snapshot.Contents["directory"].Contents["file"].HardLink; hardLink != "a" {
+		t.Errorf("hard link incorrect: %q != %q", hardLink, "a")
+	}
+	if hardLink := snapshot.Contents["a"].HardLink; hardLink != "" {
+		t.Error("hard link recorded for link target:", hardLink)
+	}
+
+	// Verify that the baseline wasn't modified.
+	if hardLink := baseline.Contents["directory"].Contents["file"].HardLink; hardLink != "" {
+		t.Error("baseline modified:", hardLink)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/bmatcuk/doublestar"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
 )
 
 // ignorePattern represents a single parsed ignore pattern.
@@ -116,6 +118,64 @@ func ValidIgnorePattern(pattern string) bool {
 	return err == nil
 }
 
+// ignoreOverrideProtectedPaths are paths representing Mutagen's temporary files
+// and directories (including staging directories), which must never be
+// synchronized because doing so would create synchronization feedback loops.
+// Ignore overrides that match any of these paths are refused.
+var ignoreOverrideProtectedPaths = []string{
+	filesystem.TemporaryNamePrefix + "protected",
+	"protected/" + filesystem.TemporaryNamePrefix + "protected",
+}
+
+// EnsureValidIgnoreOverride ensures that a pattern is a valid ignore override.
+// Ignore overrides are temporary include patterns that take precedence over all
+// ignore patterns for a single synchronization cycle. They must be specified
+// without a negation prefix, and they can't re-include Mutagen's temporary
+// files and directories.
+func EnsureValidIgnoreOverride(pattern string) error {
+	// Ensure that the pattern isn't negated, since overrides are already
+	// inclusions.
+	if strings.HasPrefix(pattern, "!") {
+		return errors.New("negated override pattern")
+	}
+
+	// Parse the pattern as a negated ignore pattern.
+	override, err := newIgnorePattern("!" + pattern)
+	if err != nil {
+		return err
+	}
+
+	// Ensure that the pattern doesn't explicitly reference or match protected
+	// content.
+	if strings.Contains("/"+pattern, "/"+filesystem.TemporaryNamePrefix) {
+		return errors.New("override pattern references protected content")
+	}
+	for _, path := range ignoreOverrideProtectedPaths {
+		if match, _ := override.matches(path, true); match {
+			return errors.New("override pattern matches protected content")
+		} else if match, _ = override.matches(path, false); match {
+			return errors.New("override pattern matches protected content")
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// WithIgnoreOverrides combines a list of ignore patterns with a list of ignore
+// overrides, returning a new list in which the overrides have been appended as
+// negated patterns so that they take precedence. The overrides should already
+// have been validated using EnsureValidIgnoreOverride. Neither input list is
+// modified.
+func WithIgnoreOverrides(ignores, overrides []string) []string {
+	result := make([]string, 0, len(ignores)+len(overrides))
+	result = append(result, ignores...)
+	for _, override := range overrides {
+		result = append(result, "!"+override)
+	}
+	return result
+}
+
 // ignorer is a collection of parsed ignore patterns.
 type ignorer struct {
 	// patterns are the underlying ignore patterns.
@@ -159,6 +219,102 @@ func (i *ignorer) ignored(path string, directory bool) bool {
 	return ignored
 }
 
+// lastMatch returns the index of the last pattern matching the specified path,
+// which is the pattern that determines the path's ignore status, as well as
+// whether or not that pattern is negated. If no pattern matches, then the
+// returned index is -1.
+func (i *ignorer) lastMatch(path string, directory bool) (int, bool) {
+	index, negated := -1, false
+	for p, pattern := range i.patterns {
+		if match, n := pattern.matches(path, directory); match {
+			index, negated = p, n
+		}
+	}
+	return index, negated
+}
+
+// IgnoreSource is an ordered list of ignore patterns originating from a single
+// source (e.g. a session's configuration or a shared ignore set).
+type IgnoreSource struct {
+	// Name is a human-readable description of the source.
+	Name string
+	// Patterns are the ignore patterns provided by the source.
+	Patterns []string
+}
+
+// IgnoreDecision describes the ignore status of a path and the pattern
+// responsible for that status.
+type IgnoreDecision struct {
+	// Ignored indicates whether or not the path is ignored.
+	Ignored bool
+	// Path is the path whose matching determined the decision. It differs from
+	// the queried path if one of the queried path's parent directories is
+	// ignored, since the contents of ignored directories are never scanned.
+	Path string
+	// Pattern is the pattern that determined the decision, as specified by its
+	// source. It is empty if no pattern matches the path.
+	Pattern string
+	// Source is the name of the source providing the pattern. It is empty if
+	// no pattern matches the path.
+	Source string
+}
+
+// ExplainIgnore determines whether or not the specified synchronization-root-
+// relative path is ignored by the specified ignore sources (which are applied
+// in order) and identifies the pattern responsible for the decision. When
+// multiple patterns match a path, the last matching pattern (which may be a
+// negated pattern) takes precedence, exactly as it would during scanning.
+func ExplainIgnore(sources []*IgnoreSource, path string, directory bool) (*IgnoreDecision, error) {
+	// Verify that the path is a clean synchronization-root-relative path.
+	if path != "" && (pathpkg.Clean(path) != path || pathpkg.IsAbs(path) ||
+		path == "." || path == ".." || strings.HasPrefix(path, "../")) {
+		return nil, errors.New("path is not a normalized relative path")
+	}
+
+	// Flatten the patterns while tracking their sources.
+	var patterns []string
+	var origins []*IgnoreSource
+	for _, source := range sources {
+		for _, pattern := range source.Patterns {
+			patterns = append(patterns, pattern)
+			origins = append(origins, source)
+		}
+	}
+
+	// Create an ignorer.
+	ignorer, err := newIgnorer(patterns)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create ignorer")
+	}
+
+	// Determine the decision for the specified path, checking its parent
+	// directories first, since an ignored parent directory hides its contents
+	// regardless of any patterns matching them. The root itself is never
+	// ignored.
+	var components []string
+	if path != "" {
+		components = strings.Split(path, "/")
+	}
+	for c := range components {
+		candidate := strings.Join(components[:c+1], "/")
+		candidateIsDirectory := directory || c < len(components)-1
+		index, negated := ignorer.lastMatch(candidate, candidateIsDirectory)
+		if index < 0 {
+			continue
+		} else if !negated || c == len(components)-1 {
+			return &IgnoreDecision{
+				Ignored: !negated,
+				Path:    candidate,
+				Pattern: patterns[index],
+				Source:  origins[index].Name,
+			}, nil
+		}
+	}
+
+	// No pattern matched the path or any of its parent directories.
+	return &IgnoreDecision{Path: path}, nil
+}
+
 // IgnoreCacheKey represents a key in an ignore cache.
 type IgnoreCacheKey struct {
 	// path is the path used for testing ignore status.
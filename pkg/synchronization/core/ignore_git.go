@@ -0,0 +1,80 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+)
+
+// gitIgnorer identifies paths that are ignored by Git. It defers the evaluation
+// of Git's ignore rules (including .gitignore files, .git/info/exclude, and the
+// user's global excludes file) to the git executable itself, so that the
+// effective ignore behavior exactly matches that seen by the user. If Git is
+// unavailable or a directory isn't part of a Git repository, then no paths are
+// identified as ignored.
+type gitIgnorer struct {
+	// ctx is the context regulating the lifetime of git invocations.
+	ctx context.Context
+	// root is the path to the synchronization root.
+	root string
+	// paths is the set of ignored synchronization-root-relative paths.
+	// Directory paths are stored with a trailing slash.
+	paths map[string]bool
+}
+
+// newGitIgnorer creates a new Git ignorer for the specified synchronization
+// root and loads the ignored paths for any Git repository containing the root.
+func newGitIgnorer(ctx context.Context, root string) *gitIgnorer {
+	// Create the ignorer.
+	result := &gitIgnorer{
+		ctx:   ctx,
+		root:  root,
+		paths: make(map[string]bool),
+	}
+
+	// Load ignored paths for the repository containing the root (if any).
+	result.load("")
+
+	// Done.
+	return result
+}
+
+// load loads the Git-ignored paths for the Git repository (if any) containing
+// the specified synchronization-root-relative directory. Because git doesn't
+// descend into nested repositories when listing ignored paths, this method
+// should be invoked for the root of each nested repository encountered within
+// the synchronization root, at which point that repository's own ignore rules
+// will be applied to its contents. Failures (including the absence of a git
+// executable or a repository) result in no paths being loaded.
+func (i *gitIgnorer) load(path string) {
+	// List the ignored paths for the repository, restricted to the target
+	// directory. We use the --directory flag to avoid listing the contents of
+	// ignored directories individually. Paths are printed relative to the
+	// target directory and without quoting (due to the -z flag).
+	list := exec.CommandContext(i.ctx,
+		"git", "ls-files", "-z", "--others", "--ignored", "--exclude-standard", "--directory",
+	)
+	list.Dir = filepath.Join(i.root, filepath.FromSlash(path))
+	output, err := list.Output()
+	if err != nil {
+		return
+	}
+
+	// Record ignored paths. We skip any empty or self-referential entries,
+	// since the target directory itself can't be ignored.
+	for _, entry := range bytes.Split(output, []byte{0}) {
+		if len(entry) == 0 || string(entry) == "./" {
+			continue
+		}
+		i.paths[pathJoin(path, string(entry))] = true
+	}
+}
+
+// ignored determines whether or not the specified path is ignored by Git.
+func (i *gitIgnorer) ignored(path string, directory bool) bool {
+	if directory {
+		return i.paths[path+"/"]
+	}
+	return i.paths[path]
+}
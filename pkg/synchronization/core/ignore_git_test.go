@@ -0,0 +1,229 @@
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+// testGitRequired skips the calling test if Git isn't available.
+func testGitRequired(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+}
+
+// testGitRun runs git with the specified arguments in the specified directory.
+func testGitRun(t *testing.T, directory string, arguments ...string) {
+	command := exec.Command("git", arguments...)
+	command.Dir = directory
+	if output, err := command.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %v: %s", strings.Join(arguments, " "), err, output)
+	}
+}
+
+// createGitIgnoreTestContent creates a Git repository containing test content
+// and ignore rules in a temporary directory and returns the path to the
+// repository root. The repository contains a nested repository with its own
+// ignore rules.
+func createGitIgnoreTestContent(t *testing.T) string {
+	// Create content.
+	root := testCreateContent(t, map[string][]byte{
+		".gitignore":                []byte("*.log\n!keep.log\nbuild/\n"),
+		"main.go":                   []byte("package main\n"),
+		"debug.log":                 []byte("debug\n"),
+		"keep.log":                  []byte("keep\n"),
+		"tracked.log":               []byte("tracked\n"),
+		"build/output":              []byte("output\n"),
+		"sub/trace.log":             []byte("trace\n"),
+		"sub/source.go":             []byte("package sub\n"),
+		"sub/.gitignore":            []byte("generated/\n"),
+		"sub/generated/file.go":     []byte("package generated\n"),
+		"nested/.gitignore":         []byte("*.tmp\n"),
+		"nested/scratch.tmp":        []byte("scratch\n"),
+		"nested/nested.log":         []byte("nested\n"),
+		"nested/code.go":            []byte("package nested\n"),
+		"nested/build/artifact.txt": []byte("artifact\n"),
+	}, nil)
+
+	// Initialize the outer and nested repositories and track a file that would
+	// otherwise be ignored.
+	testGitRun(t, root, "init", "--quiet")
+	testGitRun(t, filepath.Join(root, "nested"), "init", "--quiet")
+	testGitRun(t, root, "add", "--force", "tracked.log")
+
+	// Done.
+	return root
+}
+
+// testGitIgnoreScan performs a scan of the specified root using the specified
+// ignore patterns and Git-ignored path behavior.
+func testGitIgnoreScan(t *testing.T, root string, ignores []string, ignoreGitIgnored bool) *Entry {
+	snapshot, _, _, _, _, _, err := Scan(
+		context.Background(),
+		root,
+		nil, nil,
+		newTestHasher(), nil,
+		ignores, nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			IgnoreGitIgnored:  ignoreGitIgnored,
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	}
+	return snapshot
+}
+
+// verifySnapshotPaths verifies the presence or absence of paths in a snapshot.
+func verifySnapshotPaths(t *testing.T, snapshot *Entry, expected map[string]bool) {
+	for path, present := range expected {
+		entry := snapshot
+		for _, component := range strings.Split(path, "/") {
+			if entry != nil {
+				entry = entry.Contents[component]
+			}
+		}
+		if present && entry == nil {
+			t.Error("expected path missing from snapshot:", path)
+		} else if !present && entry != nil {
+			t.Error("unexpected path present in snapshot:", path)
+		}
+	}
+}
+
+// TestScanGitIgnored tests that paths ignored by Git are excluded from scans
+// when requested, including within nested repositories.
+func TestScanGitIgnored(t *testing.T) {
+	// Ensure that Git is available.
+	testGitRequired(t)
+
+	// Create test content and defer its removal.
+	root := createGitIgnoreTestContent(t)
+	defer os.RemoveAll(root)
+
+	// Perform a scan and verify the result. Ignore patterns should be applied
+	// in addition to Git ignore rules. The outer repository's rules shouldn't
+	// apply to the nested repository, but the nested repository's own rules
+	// should.
+	snapshot := testGitIgnoreScan(t, root, []string{"sub/source.go"}, true)
+	verifySnapshotPaths(t, snapshot, map[string]bool{
+		".gitignore":                true,
+		"main.go":                   true,
+		"debug.log":                 false,
+		"keep.log":                  true,
+		"tracked.log":               true,
+		"build":                     false,
+		"sub/trace.log":             false,
+		"sub/source.go":             false,
+		"sub/.gitignore":            true,
+		"sub/generated":             false,
+		"nested/.gitignore":         true,
+		"nested/scratch.tmp":        false,
+		"nested/nested.log":         true,
+		"nested/code.go":            true,
+		"nested/build/artifact.txt": true,
+	})
+}
+
+// TestScanGitIgnoredDisabled tests that paths ignored by Git are included in
+// scans when Git-ignored path ignoring isn't requested.
+func TestScanGitIgnoredDisabled(t *testing.T) {
+	// Ensure that Git is available.
+	testGitRequired(t)
+
+	// Create test content and defer its removal.
+	root := createGitIgnoreTestContent(t)
+	defer os.RemoveAll(root)
+
+	// Perform a scan and verify the result.
+	snapshot := testGitIgnoreScan(t, root, nil, false)
+	verifySnapshotPaths(t, snapshot, map[string]bool{
+		"debug.log":          true,
+		"build/output":       true,
+		"sub/trace.log":      true,
+		"sub/generated":      true,
+		"nested/scratch.tmp": true,
+	})
+}
+
+// TestScanGitIgnoredSubdirectoryRoot tests that Git ignore rules from outside
+// the synchronization root are applied when the root is a subdirectory of a
+// Git repository.
+func TestScanGitIgnoredSubdirectoryRoot(t *testing.T) {
+	// Ensure that Git is available.
+	testGitRequired(t)
+
+	// Create test content and defer its removal.
+	root := createGitIgnoreTestContent(t)
+	defer os.RemoveAll(root)
+
+	// Perform a scan of a subdirectory and verify the result.
+	snapshot := testGitIgnoreScan(t, filepath.Join(root, "sub"), nil, true)
+	verifySnapshotPaths(t, snapshot, map[string]bool{
+		"trace.log":  false,
+		"source.go":  true,
+		".gitignore": true,
+		"generated":  false,
+	})
+}
+
+// TestScanGitIgnoredOutsideRepository tests that no paths are ignored when the
+// synchronization root isn't part of a Git repository.
+func TestScanGitIgnoredOutsideRepository(t *testing.T) {
+	// Ensure that Git is available.
+	testGitRequired(t)
+
+	// Create a temporary directory with a .gitignore file but no repository.
+	root, err := ioutil.TempDir("", "mutagen_simulated")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(root)
+	if err := ioutil.WriteFile(filepath.Join(root, ".gitignore"), []byte("*.log\n"), 0600); err != nil {
+		t.Fatal("unable to create .gitignore file:", err)
+	} else if err := ioutil.WriteFile(filepath.Join(root, "debug.log"), []byte("debug\n"), 0600); err != nil {
+		t.Fatal("unable to create file:", err)
+	}
+
+	// Perform a scan and verify the result.
+	snapshot := testGitIgnoreScan(t, root, nil, true)
+	verifySnapshotPaths(t, snapshot, map[string]bool{
+		".gitignore": true,
+		"debug.log":  true,
+	})
+}
+
+// TestScanGitIgnoredWithoutGit tests that Git-ignored path ignoring degrades
+// gracefully if Git isn't available.
+func TestScanGitIgnoredWithoutGit(t *testing.T) {
+	// Ensure that Git is available for creating test content.
+	testGitRequired(t)
+
+	// Create test content and defer its removal.
+	root := createGitIgnoreTestContent(t)
+	defer os.RemoveAll(root)
+
+	// Make Git unavailable and defer restoration of its availability.
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", "")
+	defer os.Setenv("PATH", path)
+
+	// Perform a scan and verify the result.
+	snapshot := testGitIgnoreScan(t, root, nil, true)
+	verifySnapshotPaths(t, snapshot, map[string]bool{
+		"debug.log":          true,
+		"build/output":       true,
+		"nested/scratch.tmp": true,
+	})
+}
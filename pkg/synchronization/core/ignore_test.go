@@ -250,3 +250,106 @@ func TestIgnoreInvalidPatternOnIgnorerConstruction(t *testing.T) {
 		t.Error("ignorer should be nil on failed creation")
 	}
 }
+
+func TestIgnoreOverrideValidation(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		pattern string
+		valid   bool
+	}{
+		{"node_modules", true},
+		{"build/", true},
+		{"/vendor/cache", true},
+		{"*.log", true},
+		{"", false},
+		{"!node_modules", false},
+		{"\\", false},
+		{".mutagen-temporary-staging-session-alpha", false},
+		{"/.mutagen-temporary-staging-session-alpha", false},
+		{"sub/.mutagen-temporary-atomic-write", false},
+		{".mutagen-*", false},
+		{"*", false},
+		{"**", false},
+		{"**/.mutagen*", false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if err := EnsureValidIgnoreOverride(testCase.pattern); testCase.valid && err != nil {
+			t.Errorf("valid override (%s) considered invalid: %v", testCase.pattern, err)
+		} else if !testCase.valid && err == nil {
+			t.Errorf("invalid override (%s) considered valid", testCase.pattern)
+		}
+	}
+}
+
+func TestWithIgnoreOverrides(t *testing.T) {
+	// Combine ignores with overrides.
+	ignores := make([]string, 2, 4)
+	ignores[0] = "node_modules"
+	ignores[1] = "*.log"
+	combined := WithIgnoreOverrides(ignores, []string{"node_modules"})
+
+	// Verify that the ignores weren't modified.
+	if len(ignores) != 2 || ignores[:3][2] != "" {
+		t.Error("ignores modified when applying overrides")
+	}
+
+	// Verify that overrides take precedence over ignores.
+	test := &ignoreTestCase{
+		ignores: combined,
+		tests: []ignoreTestValue{
+			{"node_modules", true, false},
+			{"sub/node_modules", true, false},
+			{"debug.log", false, true},
+			{"something", false, false},
+		},
+	}
+	test.run(t)
+}
+
+func TestExplainIgnore(t *testing.T) {
+	// Create a layered set of ignore sources.
+	vcs := &IgnoreSource{Name: "vcs", Patterns: []string{".git/"}}
+	set := &IgnoreSource{Name: "set", Patterns: []string{"*.log", "build/", "node_modules/"}}
+	inline := &IgnoreSource{Name: "inline", Patterns: []string{"!important.log", "*.tmp", "!/build/"}}
+	sources := []*IgnoreSource{vcs, set, inline}
+
+	// Define test cases.
+	testCases := []struct {
+		path      string
+		directory bool
+		expected  IgnoreDecision
+	}{
+		{"", true, IgnoreDecision{}},
+		{"main.go", false, IgnoreDecision{Path: "main.go"}},
+		{".git", true, IgnoreDecision{true, ".git", ".git/", "vcs"}},
+		{".git", false, IgnoreDecision{Path: ".git"}},
+		{"debug.log", false, IgnoreDecision{true, "debug.log", "*.log", "set"}},
+		{"logs/important.log", false, IgnoreDecision{false, "logs/important.log", "!important.log", "inline"}},
+		{"scratch.tmp", false, IgnoreDecision{true, "scratch.tmp", "*.tmp", "inline"}},
+		{"build", true, IgnoreDecision{false, "build", "!/build/", "inline"}},
+		{"build/output.log", false, IgnoreDecision{true, "build/output.log", "*.log", "set"}},
+		{"sub/build/output", false, IgnoreDecision{true, "sub/build", "build/", "set"}},
+		{"node_modules/package/important.log", false, IgnoreDecision{true, "node_modules", "node_modules/", "set"}},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		decision, err := ExplainIgnore(sources, testCase.path, testCase.directory)
+		if err != nil {
+			t.Errorf("unable to explain ignore status for \"%s\": %v", testCase.path, err)
+		} else if *decision != testCase.expected {
+			t.Errorf("ignore decision for \"%s\" not as expected: %+v != %+v", testCase.path, *decision, testCase.expected)
+		}
+	}
+
+	// Verify that invalid paths and patterns are rejected.
+	if _, err := ExplainIgnore(sources, "/absolute", false); err == nil {
+		t.Error("absolute path accepted")
+	} else if _, err = ExplainIgnore(sources, "unclean/../path", false); err == nil {
+		t.Error("unclean path accepted")
+	} else if _, err = ExplainIgnore([]*IgnoreSource{{Patterns: []string{"!"}}}, "path", false); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"github.com/pkg/errors"
+)
+
+// IsDefault indicates whether or not the invalid name mode is
+// InvalidNameMode_InvalidNameModeDefault.
+func (m InvalidNameMode) IsDefault() bool {
+	return m == InvalidNameMode_InvalidNameModeDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (m *InvalidNameMode) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to an invalid name mode.
+	switch text {
+	case "skip":
+		*m = InvalidNameMode_InvalidNameModeSkip
+	case "escape":
+		*m = InvalidNameMode_InvalidNameModeEscape
+	default:
+		return errors.Errorf("unknown invalid name mode specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular invalid name mode is a
+// valid, non-default value.
+func (m InvalidNameMode) Supported() bool {
+	switch m {
+	case InvalidNameMode_InvalidNameModeSkip:
+		return true
+	case InvalidNameMode_InvalidNameModeEscape:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of an invalid name mode.
+func (m InvalidNameMode) Description() string {
+	switch m {
+	case InvalidNameMode_InvalidNameModeDefault:
+		return "Default"
+	case InvalidNameMode_InvalidNameModeSkip:
+		return "Skip"
+	case InvalidNameMode_InvalidNameModeEscape:
+		return "Escape"
+	default:
+		return "Unknown"
+	}
+}
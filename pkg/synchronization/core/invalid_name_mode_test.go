@@ -0,0 +1,91 @@
+package core
+
+import (
+	"testing"
+)
+
+// TestInvalidNameModeUnmarshal tests that unmarshaling from a string specification
+// succeeeds for InvalidNameMode.
+func TestInvalidNameModeUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text          string
+		expectedMode  InvalidNameMode
+		expectFailure bool
+	}{
+		{"", InvalidNameMode_InvalidNameModeDefault, true},
+		{"asdf", InvalidNameMode_InvalidNameModeDefault, true},
+		{"skip", InvalidNameMode_InvalidNameModeSkip, false},
+		{"escape", InvalidNameMode_InvalidNameModeEscape, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var mode InvalidNameMode
+		if err := mode.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if mode != testCase.expectedMode {
+			t.Errorf(
+				"unmarshaled mode (%s) does not match expected (%s)",
+				mode,
+				testCase.expectedMode,
+			)
+		}
+	}
+}
+
+// TestInvalidNameModeSupported tests that InvalidNameMode support detection works as
+// expected.
+func TestInvalidNameModeSupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode            InvalidNameMode
+		expectSupported bool
+	}{
+		{InvalidNameMode_InvalidNameModeDefault, false},
+		{InvalidNameMode_InvalidNameModeSkip, true},
+		{InvalidNameMode_InvalidNameModeEscape, true},
+		{(InvalidNameMode_InvalidNameModeEscape + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.mode.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"mode support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestInvalidNameModeDescription tests that InvalidNameMode description generation
+// works as expected.
+func TestInvalidNameModeDescription(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                InvalidNameMode
+		expectedDescription string
+	}{
+		{InvalidNameMode_InvalidNameModeDefault, "Default"},
+		{InvalidNameMode_InvalidNameModeSkip, "Skip"},
+		{InvalidNameMode_InvalidNameModeEscape, "Escape"},
+		{(InvalidNameMode_InvalidNameModeEscape + 1), "Unknown"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if description := testCase.mode.Description(); description != testCase.expectedDescription {
+			t.Errorf(
+				"mode description (%s) does not match expected (%s)",
+				description,
+				testCase.expectedDescription,
+			)
+		}
+	}
+}
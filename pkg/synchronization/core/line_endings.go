@@ -0,0 +1,240 @@
+package core
+
+import (
+	"io"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+// IsDefault indicates whether or not the line ending style is
+// LineEndingStyle_LineEndingStyleDefault.
+func (s LineEndingStyle) IsDefault() bool {
+	return s == LineEndingStyle_LineEndingStyleDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (s *LineEndingStyle) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to a line ending style.
+	switch text {
+	case "lf":
+		*s = LineEndingStyle_LineEndingStyleLF
+	case "crlf":
+		*s = LineEndingStyle_LineEndingStyleCRLF
+	default:
+		return errors.Errorf("unknown line ending style specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular line ending style is a valid,
+// non-default value.
+func (s LineEndingStyle) Supported() bool {
+	switch s {
+	case LineEndingStyle_LineEndingStyleLF:
+		return true
+	case LineEndingStyle_LineEndingStyleCRLF:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of a line ending style.
+func (s LineEndingStyle) Description() string {
+	switch s {
+	case LineEndingStyle_LineEndingStyleDefault:
+		return "Default"
+	case LineEndingStyle_LineEndingStyleLF:
+		return "LF"
+	case LineEndingStyle_LineEndingStyleCRLF:
+		return "CRLF"
+	default:
+		return "Unknown"
+	}
+}
+
+// NativeLineEndingStyle returns the native line ending style for the current
+// platform.
+func NativeLineEndingStyle() LineEndingStyle {
+	if runtime.GOOS == "windows" {
+		return LineEndingStyle_LineEndingStyleCRLF
+	}
+	return LineEndingStyle_LineEndingStyleLF
+}
+
+// newLineEndingPattern validates a user-provided line ending translation
+// pattern.
+func newLineEndingPattern(pattern string) error {
+	// Check for invalid patterns.
+	if pattern == "" {
+		return errors.New("empty pattern")
+	}
+
+	// Attempt to do a match with the pattern to ensure validity. We have to
+	// match against a non-empty path (we choose something simple), otherwise
+	// bad pattern errors won't be detected.
+	if _, err := doublestar.Match(pattern, "a"); err != nil {
+		return errors.Wrap(err, "unable to validate pattern")
+	}
+
+	// Success.
+	return nil
+}
+
+// ValidLineEndingPattern checks whether or not a given pattern is a valid line
+// ending translation pattern specification.
+func ValidLineEndingPattern(pattern string) bool {
+	return newLineEndingPattern(pattern) == nil
+}
+
+// LineEndingMatcher identifies files that are subject to line ending
+// translation. Patterns that contain a slash are matched against full
+// root-relative paths, while those that don't (e.g. "*.txt") are matched
+// against base names. A nil matcher is valid and matches no paths.
+type LineEndingMatcher struct {
+	// patterns are the line ending translation patterns.
+	patterns []string
+}
+
+// NewLineEndingMatcher creates a new line ending matcher from the specified
+// patterns. If no patterns are specified, then it returns a nil matcher.
+func NewLineEndingMatcher(patterns []string) (*LineEndingMatcher, error) {
+	// If there are no patterns, then there's no need for a matcher.
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	// Validate patterns.
+	for _, pattern := range patterns {
+		if err := newLineEndingPattern(pattern); err != nil {
+			return nil, errors.Wrapf(err, "invalid line ending pattern: %s", pattern)
+		}
+	}
+
+	// Success.
+	return &LineEndingMatcher{patterns: patterns}, nil
+}
+
+// Matches indicates whether or not the file at the specified path is subject
+// to line ending translation.
+func (m *LineEndingMatcher) Matches(path string) bool {
+	// A nil matcher doesn't match anything.
+	if m == nil {
+		return false
+	}
+
+	// Compute the base name of the path.
+	base := path
+	if slash := strings.LastIndexByte(path, '/'); slash >= 0 {
+		base = path[slash+1:]
+	}
+
+	// Check each pattern. Since we've already validated the patterns in the
+	// constructor, we know match can't fail with an error.
+	for _, pattern := range m.patterns {
+		target := base
+		if strings.IndexByte(pattern, '/') >= 0 {
+			target = path
+		}
+		if match, _ := doublestar.Match(pattern, target); match {
+			return true
+		}
+	}
+
+	// No match.
+	return false
+}
+
+// LineEndingWriter is an io.Writer that translates line endings in the data
+// written to it before forwarding that data to an underlying writer. Any run of
+// carriage returns immediately preceding a line feed is treated as part of the
+// line ending, while other carriage returns are preserved. This canonicalization
+// is idempotent, so content written in any style will translate to the same
+// content when rewritten in any other style. Since a trailing carriage return
+// may be part of a line ending split across writes, Flush must be invoked once
+// all data has been written.
+type LineEndingWriter struct {
+	// writer is the underlying writer.
+	writer io.Writer
+	// style is the line ending style to which data is translated. It is never
+	// LineEndingStyle_LineEndingStyleDefault.
+	style LineEndingStyle
+	// pendingCarriageReturns is the number of carriage returns that have been
+	// written but not forwarded, since they may precede a line feed.
+	pendingCarriageReturns int
+	// buffer is a re-usable buffer for translated data.
+	buffer []byte
+}
+
+// NewLineEndingWriter creates a new line ending writer that translates data to
+// the specified style and forwards it to the specified writer. If the style is
+// LineEndingStyle_LineEndingStyleDefault, then the native line ending style is
+// used.
+func NewLineEndingWriter(writer io.Writer, style LineEndingStyle) *LineEndingWriter {
+	if style.IsDefault() {
+		style = NativeLineEndingStyle()
+	}
+	return &LineEndingWriter{
+		writer: writer,
+		style:  style,
+	}
+}
+
+// appendPendingCarriageReturns appends any pending carriage returns to the
+// translation buffer.
+func (w *LineEndingWriter) appendPendingCarriageReturns() {
+	for ; w.pendingCarriageReturns > 0; w.pendingCarriageReturns-- {
+		w.buffer = append(w.buffer, '\r')
+	}
+}
+
+// Write implements io.Writer.Write.
+func (w *LineEndingWriter) Write(data []byte) (int, error) {
+	// Translate the data.
+	w.buffer = w.buffer[:0]
+	for _, b := range data {
+		switch b {
+		case '\r':
+			w.pendingCarriageReturns++
+		case '\n':
+			w.pendingCarriageReturns = 0
+			if w.style == LineEndingStyle_LineEndingStyleCRLF {
+				w.buffer = append(w.buffer, '\r')
+			}
+			w.buffer = append(w.buffer, '\n')
+		default:
+			w.appendPendingCarriageReturns()
+			w.buffer = append(w.buffer, b)
+		}
+	}
+
+	// Forward the translated data. We report the full length of the input as
+	// written, since translation alters the length of the data.
+	if _, err := w.writer.Write(w.buffer); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// Flush forwards any pending carriage returns that weren't followed by a line
+// feed.
+func (w *LineEndingWriter) Flush() error {
+	w.buffer = w.buffer[:0]
+	w.appendPendingCarriageReturns()
+	if len(w.buffer) > 0 {
+		if _, err := w.writer.Write(w.buffer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestLineEndingStyleUnmarshal tests that unmarshaling from a string
+// specification succeeeds for LineEndingStyle.
+func TestLineEndingStyleUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text          string
+		expectedStyle LineEndingStyle
+		expectFailure bool
+	}{
+		{"", LineEndingStyle_LineEndingStyleDefault, true},
+		{"asdf", LineEndingStyle_LineEndingStyleDefault, true},
+		{"lf", LineEndingStyle_LineEndingStyleLF, false},
+		{"crlf", LineEndingStyle_LineEndingStyleCRLF, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var style LineEndingStyle
+		if err := style.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if style != testCase.expectedStyle {
+			t.Errorf(
+				"unmarshaled style (%s) does not match expected (%s)",
+				style,
+				testCase.expectedStyle,
+			)
+		}
+	}
+}
+
+// TestLineEndingStyleSupported tests that LineEndingStyle support detection
+// works as expected.
+func TestLineEndingStyleSupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		style           LineEndingStyle
+		expectSupported bool
+	}{
+		{LineEndingStyle_LineEndingStyleDefault, false},
+		{LineEndingStyle_LineEndingStyleLF, true},
+		{LineEndingStyle_LineEndingStyleCRLF, true},
+		{(LineEndingStyle_LineEndingStyleCRLF + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.style.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"style support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestLineEndingMatcher tests LineEndingMatcher.
+func TestLineEndingMatcher(t *testing.T) {
+	// Verify that invalid patterns are rejected.
+	if _, err := NewLineEndingMatcher([]string{""}); err == nil {
+		t.Error("empty pattern accepted")
+	}
+	if _, err := NewLineEndingMatcher([]string{"[a"}); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+
+	// Verify that no patterns yields a nil matcher that matches nothing.
+	if matcher, err := NewLineEndingMatcher(nil); err != nil {
+		t.Fatal("unable to create empty matcher:", err)
+	} else if matcher != nil {
+		t.Error("non-nil matcher created for empty patterns")
+	} else if matcher.Matches("file.txt") {
+		t.Error("nil matcher matched path")
+	}
+
+	// Create a matcher.
+	matcher, err := NewLineEndingMatcher([]string{"*.txt", "docs/**/*.md"})
+	if err != nil {
+		t.Fatal("unable to create matcher:", err)
+	}
+
+	// Set up test cases.
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"file.txt", true},
+		{"nested/directory/file.txt", true},
+		{"file.txt.bin", false},
+		{"file.md", false},
+		{"docs/file.md", true},
+		{"docs/nested/file.md", true},
+		{"other/docs/file.md", false},
+		{"", false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if matched := matcher.Matches(testCase.path); matched != testCase.expected {
+			t.Errorf("match result for %q (%t) does not match expected (%t)",
+				testCase.path, matched, testCase.expected,
+			)
+		}
+	}
+}
+
+// TestLineEndingWriter tests LineEndingWriter.
+func TestLineEndingWriter(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		input        string
+		expectedLF   string
+		expectedCRLF string
+	}{
+		{"", "", ""},
+		{"no line endings", "no line endings", "no line endings"},
+		{"a\nb\n", "a\nb\n", "a\r\nb\r\n"},
+		{"a\r\nb\r\n", "a\nb\n", "a\r\nb\r\n"},
+		{"a\r\nb\nc", "a\nb\nc", "a\r\nb\r\nc"},
+		{"a\rb\r", "a\rb\r", "a\rb\r"},
+		{"a\r\r\nb", "a\nb", "a\r\nb"},
+		{"\r\n\r\n", "\n\n", "\r\n\r\n"},
+	}
+
+	// translate translates data to the specified style, writing it one byte
+	// at a time if requested.
+	translate := func(input string, style LineEndingStyle, bytewise bool) string {
+		output := &bytes.Buffer{}
+		writer := NewLineEndingWriter(output, style)
+		if bytewise {
+			for i := 0; i < len(input); i++ {
+				if n, err := writer.Write([]byte{input[i]}); err != nil || n != 1 {
+					t.Fatal("unable to write data:", err)
+				}
+			}
+		} else if n, err := writer.Write([]byte(input)); err != nil || n != len(input) {
+			t.Fatal("unable to write data:", err)
+		}
+		if err := writer.Flush(); err != nil {
+			t.Fatal("unable to flush writer:", err)
+		}
+		return output.String()
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		for _, bytewise := range []bool{false, true} {
+			lf := translate(testCase.input, LineEndingStyle_LineEndingStyleLF, bytewise)
+			if lf != testCase.expectedLF {
+				t.Errorf("test case %d: LF translation (%q) does not match expected (%q)", i, lf, testCase.expectedLF)
+			}
+			crlf := translate(testCase.input, LineEndingStyle_LineEndingStyleCRLF, bytewise)
+			if crlf != testCase.expectedCRLF {
+				t.Errorf("test case %d: CRLF translation (%q) does not match expected (%q)", i, crlf, testCase.expectedCRLF)
+			}
+
+			// Verify that translated content round trips to the same canonical
+			// form, since otherwise translated files would be seen as changed.
+			if canonical := translate(crlf, LineEndingStyle_LineEndingStyleLF, bytewise); canonical != lf {
+				t.Errorf("test case %d: CRLF content not canonicalized consistently: %q != %q", i, canonical, lf)
+			}
+			if canonical := translate(lf, LineEndingStyle_LineEndingStyleLF, bytewise); canonical != lf {
+				t.Errorf("test case %d: LF content not canonicalized consistently: %q != %q", i, canonical, lf)
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package core
+
+// utf16Length computes the length of a string in UTF-16 code units, which is
+// how Windows measures path lengths.
+func utf16Length(value string) int {
+	var length int
+	for _, r := range value {
+		if r >= 0x10000 {
+			length += 2
+		} else {
+			length++
+		}
+	}
+	return length
+}
+
+// LongPathFilter excludes content from transitions if its on-disk path would
+// exceed the path length limits of an endpoint's platform. It is stateless and
+// thus safe for concurrent usage.
+type LongPathFilter struct {
+	// rootLength is the length of the synchronization root path.
+	rootLength int
+	// fileLimit is the maximum length of file and symbolic link paths.
+	fileLimit int
+	// directoryLimit is the maximum length of directory paths.
+	directoryLimit int
+}
+
+// NewLongPathFilter creates a long path filter for the synchronization root at
+// the specified path using the specified long path mode (which must be a
+// non-default value) and path length limits (e.g. those returned by
+// filesystem.PathLengthLimits). Lengths are measured in UTF-16 code units. It
+// returns nil if no filtering is necessary, either because extended-length
+// paths are being used or because no limits apply.
+func NewLongPathFilter(mode LongPathMode, root string, fileLimit, directoryLimit int) *LongPathFilter {
+	if mode != LongPathMode_LongPathModeSkip || (fileLimit == 0 && directoryLimit == 0) {
+		return nil
+	}
+	return &LongPathFilter{
+		rootLength:     utf16Length(root),
+		fileLimit:      fileLimit,
+		directoryLimit: directoryLimit,
+	}
+}
+
+// exceedsLimit determines whether or not the on-disk path for content at the
+// specified synchronization path would exceed the relevant limit.
+func (f *LongPathFilter) exceedsLimit(path string, directory bool) bool {
+	// Compute the on-disk path length, accounting for the separator between
+	// the root and the path.
+	length := f.rootLength
+	if path != "" {
+		length += 1 + utf16Length(path)
+	}
+
+	// Check against the relevant limit.
+	limit := f.fileLimit
+	if directory {
+		limit = f.directoryLimit
+	}
+	return limit != 0 && length > limit
+}
+
+// filterEntry excludes any content from the entry at the specified path whose
+// on-disk path would exceed the relevant limit, recording problems for that
+// content. Entries are treated as immutable, so unaffected subtrees are shared
+// with the original.
+func (f *LongPathFilter) filterEntry(path string, entry *Entry, problems *[]*Problem) *Entry {
+	// Handle the trivial case.
+	if entry == nil {
+		return nil
+	}
+
+	// Check whether or not the entry itself is too long.
+	if f.exceedsLimit(path, entry.IsDirectory()) {
+		*problems = append(*problems, &Problem{
+			Path:  path,
+			Error: "path exceeds platform path length limit",
+		})
+		return nil
+	}
+
+	// Filter contents, only allocating a new content map if necessary.
+	var contents map[string]*Entry
+	for name, child := range entry.Contents {
+		filtered := f.filterEntry(pathJoin(path, name), child, problems)
+		if filtered == child {
+			continue
+		}
+		if contents == nil {
+			contents = make(map[string]*Entry, len(entry.Contents))
+			for n, c := range entry.Contents {
+				contents[n] = c
+			}
+		}
+		if filtered == nil {
+			delete(contents, name)
+		} else {
+			contents[name] = filtered
+		}
+	}
+
+	// If nothing was excluded, then return the original entry.
+	if contents == nil {
+		return entry
+	}
+	result := entry.copySlim()
+	result.Contents = contents
+	return result
+}
+
+// FilterTransitions excludes content from the new entries of the specified
+// transitions if its on-disk path would exceed the relevant limit, reporting
+// problems for that content. Old entries are left intact, since existing
+// content at long paths can still be removed.
+func (f *LongPathFilter) FilterTransitions(transitions []*Change) ([]*Change, []*Problem) {
+	results := make([]*Change, len(transitions))
+	var problems []*Problem
+	for i, transition := range transitions {
+		if filtered := f.filterEntry(transition.Path, transition.New, &problems); filtered == transition.New {
+			results[i] = transition
+		} else {
+			results[i] = &Change{
+				Path:            transition.Path,
+				Old:             transition.Old,
+				New:             filtered,
+				Resolve:         transition.Resolve,
+				ConflictSidecar: transition.ConflictSidecar,
+			}
+		}
+	}
+	return results, problems
+}
+
+// FilterStagingPaths removes any staging paths (and their corresponding
+// digests) whose on-disk paths would exceed the file path length limit, since
+// the corresponding files won't be created when transitioning.
+func (f *LongPathFilter) FilterStagingPaths(paths []string, digests [][]byte) ([]string, [][]byte) {
+	filteredPaths := make([]string, 0, len(paths))
+	filteredDigests := make([][]byte, 0, len(digests))
+	for p, path := range paths {
+		if !f.exceedsLimit(path, false) {
+			filteredPaths = append(filteredPaths, path)
+			filteredDigests = append(filteredDigests, digests[p])
+		}
+	}
+	return filteredPaths, filteredDigests
+}
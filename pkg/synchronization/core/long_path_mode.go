@@ -0,0 +1,58 @@
+package core
+
+import (
+	"github.com/pkg/errors"
+)
+
+// IsDefault indicates whether or not the long path mode is
+// LongPathMode_LongPathModeDefault.
+func (m LongPathMode) IsDefault() bool {
+	return m == LongPathMode_LongPathModeDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (m *LongPathMode) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to a long path mode.
+	switch text {
+	case "extended":
+		*m = LongPathMode_LongPathModeExtended
+	case "skip":
+		*m = LongPathMode_LongPathModeSkip
+	default:
+		return errors.Errorf("unknown long path mode specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular long path mode is a
+// valid, non-default value.
+func (m LongPathMode) Supported() bool {
+	switch m {
+	case LongPathMode_LongPathModeExtended:
+		return true
+	case LongPathMode_LongPathModeSkip:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of a long path mode.
+func (m LongPathMode) Description() string {
+	switch m {
+	case LongPathMode_LongPathModeDefault:
+		return "Default"
+	case LongPathMode_LongPathModeExtended:
+		return "Extended"
+	case LongPathMode_LongPathModeSkip:
+		return "Skip"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"testing"
+)
+
+// TestLongPathModeUnmarshal tests that unmarshaling from a string specification
+// succeeeds for LongPathMode.
+func TestLongPathModeUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text          string
+		expectedMode  LongPathMode
+		expectFailure bool
+	}{
+		{"", LongPathMode_LongPathModeDefault, true},
+		{"asdf", LongPathMode_LongPathModeDefault, true},
+		{"extended", LongPathMode_LongPathModeExtended, false},
+		{"skip", LongPathMode_LongPathModeSkip, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var mode LongPathMode
+		if err := mode.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if mode != testCase.expectedMode {
+			t.Errorf(
+				"unmarshaled mode (%s) does not match expected (%s)",
+				mode,
+				testCase.expectedMode,
+			)
+		}
+	}
+}
+
+// TestLongPathModeSupported tests that LongPathMode support detection works as
+// expected.
+func TestLongPathModeSupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode            LongPathMode
+		expectSupported bool
+	}{
+		{LongPathMode_LongPathModeDefault, false},
+		{LongPathMode_LongPathModeExtended, true},
+		{LongPathMode_LongPathModeSkip, true},
+		{(LongPathMode_LongPathModeSkip + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.mode.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"mode support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestLongPathModeDescription tests that LongPathMode description generation
+// works as expected.
+func TestLongPathModeDescription(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                LongPathMode
+		expectedDescription string
+	}{
+		{LongPathMode_LongPathModeDefault, "Default"},
+		{LongPathMode_LongPathModeExtended, "Extended"},
+		{LongPathMode_LongPathModeSkip, "Skip"},
+		{(LongPathMode_LongPathModeSkip + 1), "Unknown"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if description := testCase.mode.Description(); description != testCase.expectedDescription {
+			t.Errorf(
+				"mode description (%s) does not match expected (%s)",
+				description,
+				testCase.expectedDescription,
+			)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"testing"
+)
+
+// TestUTF16Length tests utf16Length.
+func TestUTF16Length(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		value    string
+		expected int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"\u00e9t\u00e9", 3},
+		{"\U0001f600", 2},
+		{"a/\U0001f600/b", 6},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if length := utf16Length(testCase.value); length != testCase.expected {
+			t.Errorf("length of %q (%d) does not match expected (%d)", testCase.value, length, testCase.expected)
+		}
+	}
+}
+
+// TestNewLongPathFilterDisabled tests that no filter is created if extended
+// paths are being used or if the platform imposes no limits.
+func TestNewLongPathFilterDisabled(t *testing.T) {
+	if NewLongPathFilter(LongPathMode_LongPathModeExtended, "root", 259, 247) != nil {
+		t.Error("filter created in extended mode")
+	}
+	if NewLongPathFilter(LongPathMode_LongPathModeSkip, "root", 0, 0) != nil {
+		t.Error("filter created without limits")
+	}
+	if NewLongPathFilter(LongPathMode_LongPathModeSkip, "root", 259, 247) == nil {
+		t.Error("filter not created in skip mode with limits")
+	}
+}
+
+// TestLongPathFilterSkip tests that transitions exclude content whose on-disk
+// paths would exceed simulated limits and report that content as problems.
+func TestLongPathFilterSkip(t *testing.T) {
+	// Create a filter with small simulated limits. The root path is 6 code
+	// units long, so (accounting for the separator) synchronization paths can
+	// be at most 7 code units long for files and 5 for directories.
+	filter := NewLongPathFilter(LongPathMode_LongPathModeSkip, `C:\abc`, 14, 12)
+
+	// Filter transitions that create nested content.
+	nested := &Entry{
+		Kind: EntryKind_Directory,
+		Contents: map[string]*Entry{
+			"file":      testFile1Entry,
+			"long_file": testFile1Entry,
+			"dir": {
+				Kind: EntryKind_Directory,
+				Contents: map[string]*Entry{
+					"f": testFile3Entry,
+				},
+			},
+			"long_dir": {
+				Kind: EntryKind_Directory,
+				Contents: map[string]*Entry{
+					"f": testFile3Entry,
+				},
+			},
+		},
+	}
+	unaffected := &Change{Path: "ok", New: testFile1Entry}
+	transitions, problems := filter.FilterTransitions([]*Change{
+		{Path: "a", Old: testFile3Entry, New: nested},
+		unaffected,
+		{Path: "too/long/path", Old: testFile3Entry, New: testFile1Entry},
+	})
+
+	// Verify the filtered transitions.
+	if len(transitions) != 3 {
+		t.Fatal("unexpected number of transitions:", len(transitions))
+	}
+	expected := &Entry{
+		Kind: EntryKind_Directory,
+		Contents: map[string]*Entry{
+			"file": testFile1Entry,
+			"dir": {
+				Kind: EntryKind_Directory,
+				Contents: map[string]*Entry{
+					"f": testFile3Entry,
+				},
+			},
+		},
+	}
+	if transitions[0].Path != "a" || !transitions[0].New.Equal(expected) {
+		t.Error("transition with long content paths not filtered correctly")
+	} else if transitions[0].Old != testFile3Entry {
+		t.Error("old content not preserved in filtered transition")
+	}
+	if transitions[0].New.Contents["dir"] != nested.Contents["dir"] {
+		t.Error("unaffected subtree not shared with original")
+	}
+	if len(nested.Contents) != 4 {
+		t.Error("original entry modified by filtering")
+	}
+	if transitions[1] != unaffected {
+		t.Error("unaffected transition not passed through")
+	}
+	if transitions[2].New != nil || transitions[2].Old != testFile3Entry {
+		t.Error("transition with long path not converted to removal of existing content")
+	}
+
+	// Verify the problems.
+	expectedProblemPaths := map[string]bool{
+		"a/long_file":   true,
+		"a/long_dir":    true,
+		"too/long/path": true,
+	}
+	if len(problems) != len(expectedProblemPaths) {
+		t.Error("unexpected number of problems:", len(problems))
+	}
+	for _, problem := range problems {
+		if !expectedProblemPaths[problem.Path] {
+			t.Error("unexpected problem path:", problem.Path)
+		}
+	}
+}
+
+// TestLongPathFilterStagingPaths tests that staging paths for files that won't
+// be created are removed.
+func TestLongPathFilterStagingPaths(t *testing.T) {
+	filter := NewLongPathFilter(LongPathMode_LongPathModeSkip, `C:\abc`, 14, 12)
+	paths, digests := filter.FilterStagingPaths(
+		[]string{"a/file", "a/long_file", "a/\U0001f600"},
+		[][]byte{{1}, {2}, {3}},
+	)
+	if len(paths) != 2 || paths[0] != "a/file" || paths[1] != "a/\U0001f600" {
+		t.Error("staging paths not filtered correctly:", paths)
+	}
+	if len(digests) != 2 || digests[0][0] != 1 || digests[1][0] != 3 {
+		t.Error("staging digests not filtered correctly")
+	}
+}
@@ -0,0 +1,437 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+)
+
+const (
+	// macOSAttributePrefix is the prefix shared by macOS metadata extended
+	// attribute names.
+	macOSAttributePrefix = "com.apple."
+	// macOSFinderInfoAttribute is the name of the extended attribute used by
+	// macOS to expose Finder information.
+	macOSFinderInfoAttribute = "com.apple.FinderInfo"
+	// macOSResourceForkAttribute is the name of the extended attribute used by
+	// macOS to expose resource forks.
+	macOSResourceForkAttribute = "com.apple.ResourceFork"
+	// macOSFinderInfoSize is the size of Finder information.
+	macOSFinderInfoSize = 32
+	// macOSMaximumAttributeNameLength is the maximum length of an extended
+	// attribute name on macOS (excluding its null terminator).
+	macOSMaximumAttributeNameLength = 127
+
+	// appleDoublePrefix is the name prefix used for AppleDouble sidecar files.
+	appleDoublePrefix = "._"
+	// appleDoubleMagic is the magic number for AppleDouble files.
+	appleDoubleMagic = 0x00051607
+	// appleDoubleVersion is the AppleDouble format version.
+	appleDoubleVersion = 0x00020000
+	// appleDoubleHeaderSize is the size of the AppleDouble header (excluding
+	// entry descriptors).
+	appleDoubleHeaderSize = 26
+	// appleDoubleEntryDescriptorSize is the size of an AppleDouble entry
+	// descriptor.
+	appleDoubleEntryDescriptorSize = 12
+	// appleDoubleEntryResourceFork is the AppleDouble entry ID for resource
+	// forks.
+	appleDoubleEntryResourceFork = 2
+	// appleDoubleEntryFinderInfo is the AppleDouble entry ID for Finder
+	// information.
+	appleDoubleEntryFinderInfo = 9
+	// appleDoubleAttributesMagic is the magic number for the extended
+	// attribute header that macOS embeds in the Finder information entry.
+	appleDoubleAttributesMagic = 0x41545452
+	// appleDoubleAttributesPadding is the padding between the Finder
+	// information and the extended attribute header.
+	appleDoubleAttributesPadding = 2
+	// appleDoubleAttributesHeaderSize is the size of the extended attribute
+	// header.
+	appleDoubleAttributesHeaderSize = 36
+	// appleDoubleAttributeEntryFixedSize is the size of the fixed portion of
+	// an extended attribute entry (excluding its name).
+	appleDoubleAttributeEntryFixedSize = 11
+)
+
+// appleDoubleFiller is the filler written to AppleDouble headers. It matches
+// the value written by macOS.
+var appleDoubleFiller = []byte("Mac OS X        ")
+
+// macOSExcludedAttributes are com.apple.* extended attributes that aren't
+// treated as macOS metadata because they're managed by the system (e.g. for
+// security or compression purposes) and shouldn't be propagated.
+var macOSExcludedAttributes = map[string]bool{
+	"com.apple.quarantine": true,
+	"com.apple.provenance": true,
+	"com.apple.decmpfs":    true,
+	"com.apple.rootless":   true,
+}
+
+// isMacOSMetadataAttribute determines whether or not the specified extended
+// attribute name represents macOS metadata.
+func isMacOSMetadataAttribute(name string) bool {
+	return strings.HasPrefix(name, macOSAttributePrefix) &&
+		len(name) > len(macOSAttributePrefix) &&
+		len(name) <= macOSMaximumAttributeNameLength &&
+		strings.IndexByte(name, 0) == -1 &&
+		!macOSExcludedAttributes[name] &&
+		!strings.HasPrefix(name, "com.apple.system.")
+}
+
+// isAppleDoubleName determines whether or not the specified file name is that
+// of an AppleDouble sidecar file.
+func isAppleDoubleName(name string) bool {
+	return len(name) > len(appleDoublePrefix) && strings.HasPrefix(name, appleDoublePrefix)
+}
+
+// appleDoublePath computes the path of the AppleDouble sidecar file for the
+// specified path.
+func appleDoublePath(path string) string {
+	return filepath.Join(filepath.Dir(path), appleDoublePrefix+filepath.Base(path))
+}
+
+// ensureMacOSMetadataValid ensures that macOS metadata attributes are valid,
+// i.e. that they're non-nil, have valid names, and are sorted by name without
+// duplicates.
+func ensureMacOSMetadataValid(attributes []*MacOSAttribute) error {
+	for a, attribute := range attributes {
+		if attribute == nil {
+			return errors.New("nil macOS metadata attribute detected")
+		} else if !isMacOSMetadataAttribute(attribute.Name) {
+			return errors.Errorf("invalid macOS metadata attribute name: %s", attribute.Name)
+		} else if a > 0 && attributes[a-1].Name >= attribute.Name {
+			return errors.New("macOS metadata attributes unsorted or duplicated")
+		} else if attribute.Name == macOSFinderInfoAttribute && len(attribute.Value) != macOSFinderInfoSize {
+			return errors.New("invalid Finder information size")
+		}
+	}
+	return nil
+}
+
+// sortMacOSMetadata sorts macOS metadata attributes by name.
+func sortMacOSMetadata(attributes []*MacOSAttribute) {
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Name < attributes[j].Name
+	})
+}
+
+// encodeAppleDouble encodes macOS metadata attributes in the AppleDouble format
+// used by macOS for storing metadata on filesystems that don't support it
+// natively. Finder information and resource forks are stored in their
+// dedicated entries, while other attributes are stored in the extended
+// attribute area that macOS embeds in the Finder information entry.
+func encodeAppleDouble(attributes []*MacOSAttribute) []byte {
+	// Separate out Finder information and resource fork data.
+	finderInfo := make([]byte, macOSFinderInfoSize)
+	var resourceFork []byte
+	var extended []*MacOSAttribute
+	for _, attribute := range attributes {
+		switch attribute.Name {
+		case macOSFinderInfoAttribute:
+			copy(finderInfo, attribute.Value)
+		case macOSResourceForkAttribute:
+			resourceFork = attribute.Value
+		default:
+			extended = append(extended, attribute)
+		}
+	}
+
+	// Compute the layout. The Finder information entry begins immediately
+	// after the header and its two entry descriptors.
+	finderInfoOffset := appleDoubleHeaderSize + 2*appleDoubleEntryDescriptorSize
+	finderInfoLength := macOSFinderInfoSize
+	var entriesSize, dataSize int
+	if len(extended) > 0 {
+		for _, attribute := range extended {
+			entriesSize += (appleDoubleAttributeEntryFixedSize + len(attribute.Name) + 1 + 3) &^ 3
+			dataSize += len(attribute.Value)
+		}
+		finderInfoLength += appleDoubleAttributesPadding + appleDoubleAttributesHeaderSize + entriesSize + dataSize
+	}
+	resourceForkOffset := finderInfoOffset + finderInfoLength
+
+	// Write the header and entry descriptors.
+	buffer := &bytes.Buffer{}
+	buffer.Grow(resourceForkOffset + len(resourceFork))
+	write := func(value interface{}) {
+		binary.Write(buffer, binary.BigEndian, value)
+	}
+	write(uint32(appleDoubleMagic))
+	write(uint32(appleDoubleVersion))
+	buffer.Write(appleDoubleFiller)
+	write(uint16(2))
+	write(uint32(appleDoubleEntryFinderInfo))
+	write(uint32(finderInfoOffset))
+	write(uint32(finderInfoLength))
+	write(uint32(appleDoubleEntryResourceFork))
+	write(uint32(resourceForkOffset))
+	write(uint32(len(resourceFork)))
+
+	// Write the Finder information.
+	buffer.Write(finderInfo)
+
+	// Write the extended attribute area, if any.
+	if len(extended) > 0 {
+		buffer.Write(make([]byte, appleDoubleAttributesPadding))
+		dataStart := finderInfoOffset + macOSFinderInfoSize + appleDoubleAttributesPadding +
+			appleDoubleAttributesHeaderSize + entriesSize
+		write(uint32(appleDoubleAttributesMagic))
+		write(uint32(0))
+		write(uint32(dataStart + dataSize))
+		write(uint32(dataStart))
+		write(uint32(dataSize))
+		write([3]uint32{})
+		write(uint16(0))
+		write(uint16(len(extended)))
+		dataOffset := dataStart
+		for _, attribute := range extended {
+			write(uint32(dataOffset))
+			write(uint32(len(attribute.Value)))
+			write(uint16(0))
+			write(uint8(len(attribute.Name) + 1))
+			buffer.WriteString(attribute.Name)
+			entrySize := appleDoubleAttributeEntryFixedSize + len(attribute.Name) + 1
+			buffer.Write(make([]byte, ((entrySize+3)&^3)-entrySize+1))
+			dataOffset += len(attribute.Value)
+		}
+		for _, attribute := range extended {
+			buffer.Write(attribute.Value)
+		}
+	}
+
+	// Write the resource fork.
+	buffer.Write(resourceFork)
+
+	// Done.
+	return buffer.Bytes()
+}
+
+// decodeAppleDouble decodes macOS metadata attributes from AppleDouble data.
+// Attributes that don't represent macOS metadata are discarded. Finder
+// information consisting entirely of zeros and empty resource forks are
+// treated as absent, matching the behavior of macOS. The resulting attributes
+// are sorted by name.
+func decodeAppleDouble(data []byte) ([]*MacOSAttribute, error) {
+	// Validate the header.
+	if len(data) < appleDoubleHeaderSize {
+		return nil, errors.New("truncated header")
+	} else if binary.BigEndian.Uint32(data[0:4]) != appleDoubleMagic {
+		return nil, errors.New("invalid magic number")
+	} else if binary.BigEndian.Uint32(data[4:8]) != appleDoubleVersion {
+		return nil, errors.New("unsupported version")
+	}
+	entryCount := int(binary.BigEndian.Uint16(data[24:26]))
+	if len(data) < appleDoubleHeaderSize+entryCount*appleDoubleEntryDescriptorSize {
+		return nil, errors.New("truncated entry descriptors")
+	}
+
+	// Process entries.
+	var result []*MacOSAttribute
+	for e := 0; e < entryCount; e++ {
+		descriptor := data[appleDoubleHeaderSize+e*appleDoubleEntryDescriptorSize:]
+		id := binary.BigEndian.Uint32(descriptor[0:4])
+		offset := uint64(binary.BigEndian.Uint32(descriptor[4:8]))
+		length := uint64(binary.BigEndian.Uint32(descriptor[8:12]))
+		if offset+length > uint64(len(data)) {
+			return nil, errors.New("entry exceeds data bounds")
+		}
+		entry := data[offset : offset+length]
+		switch id {
+		case appleDoubleEntryResourceFork:
+			if len(entry) > 0 {
+				result = append(result, &MacOSAttribute{
+					Name:  macOSResourceForkAttribute,
+					Value: append([]byte(nil), entry...),
+				})
+			}
+		case appleDoubleEntryFinderInfo:
+			if len(entry) < macOSFinderInfoSize {
+				return nil, errors.New("truncated Finder information")
+			}
+			if finderInfo := entry[:macOSFinderInfoSize]; !bytes.Equal(finderInfo, make([]byte, macOSFinderInfoSize)) {
+				result = append(result, &MacOSAttribute{
+					Name:  macOSFinderInfoAttribute,
+					Value: append([]byte(nil), finderInfo...),
+				})
+			}
+			extended, err := decodeAppleDoubleAttributes(data, entry[macOSFinderInfoSize:])
+			if err != nil {
+				return nil, errors.Wrap(err, "unable to decode extended attributes")
+			}
+			result = append(result, extended...)
+		}
+	}
+
+	// Sort the result and ensure that it's valid.
+	sortMacOSMetadata(result)
+	if err := ensureMacOSMetadataValid(result); err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return result, nil
+}
+
+// decodeAppleDoubleAttributes decodes the extended attribute area (if any)
+// following the Finder information in an AppleDouble Finder information entry.
+// Attribute data offsets are relative to the start of the AppleDouble data.
+func decodeAppleDoubleAttributes(data, area []byte) ([]*MacOSAttribute, error) {
+	// If there's no extended attribute header, then there are no attributes.
+	if len(area) < appleDoubleAttributesPadding+appleDoubleAttributesHeaderSize {
+		return nil, nil
+	}
+	area = area[appleDoubleAttributesPadding:]
+	if binary.BigEndian.Uint32(area[0:4]) != appleDoubleAttributesMagic {
+		return nil, nil
+	}
+	count := int(binary.BigEndian.Uint16(area[34:36]))
+	entries := area[appleDoubleAttributesHeaderSize:]
+
+	// Process attribute entries.
+	var result []*MacOSAttribute
+	for a := 0; a < count; a++ {
+		if len(entries) < appleDoubleAttributeEntryFixedSize {
+			return nil, errors.New("truncated attribute entry")
+		}
+		offset := uint64(binary.BigEndian.Uint32(entries[0:4]))
+		length := uint64(binary.BigEndian.Uint32(entries[4:8]))
+		nameLength := int(entries[10])
+		entrySize := (appleDoubleAttributeEntryFixedSize + nameLength + 3) &^ 3
+		if nameLength == 0 || len(entries) < appleDoubleAttributeEntryFixedSize+nameLength {
+			return nil, errors.New("truncated attribute name")
+		} else if offset+length > uint64(len(data)) {
+			return nil, errors.New("attribute data exceeds bounds")
+		}
+		name := string(bytes.TrimRight(entries[appleDoubleAttributeEntryFixedSize:appleDoubleAttributeEntryFixedSize+nameLength], "\x00"))
+		if isMacOSMetadataAttribute(name) && name != macOSFinderInfoAttribute && name != macOSResourceForkAttribute {
+			result = append(result, &MacOSAttribute{
+				Name:  name,
+				Value: append([]byte(nil), data[offset:offset+length]...),
+			})
+		}
+		if entrySize > len(entries) {
+			entrySize = len(entries)
+		}
+		entries = entries[entrySize:]
+	}
+
+	// Success.
+	return result, nil
+}
+
+// readMacOSMetadata reads the macOS metadata for the file or directory at the
+// specified path. If the platform and filesystem support extended attributes,
+// then the metadata is read natively, otherwise it's read from the entry's
+// AppleDouble sidecar file (if any). If the entry has no macOS metadata, then
+// it returns nil.
+func readMacOSMetadata(path string) ([]*MacOSAttribute, error) {
+	// Attempt to read metadata natively.
+	names, err := filesystem.ListExtendedAttributes(path)
+	if err == filesystem.ErrExtendedAttributesUnsupported {
+		return readAppleDouble(appleDoublePath(path))
+	} else if err != nil {
+		return nil, errors.Wrap(err, "unable to list extended attributes")
+	}
+	var result []*MacOSAttribute
+	for _, name := range names {
+		if !isMacOSMetadataAttribute(name) {
+			continue
+		}
+		value, err := filesystem.ReadExtendedAttribute(path, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read extended attribute (%s)", name)
+		}
+		result = append(result, &MacOSAttribute{Name: name, Value: value})
+	}
+
+	// Sort the result and ensure that it's valid.
+	sortMacOSMetadata(result)
+	if err := ensureMacOSMetadataValid(result); err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return result, nil
+}
+
+// readAppleDouble reads macOS metadata from the AppleDouble sidecar file at
+// the specified path. If the file doesn't exist, then it returns nil.
+func readAppleDouble(path string) ([]*MacOSAttribute, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "unable to read AppleDouble file")
+	}
+	attributes, err := decodeAppleDouble(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to decode AppleDouble file")
+	}
+	return attributes, nil
+}
+
+// writeMacOSMetadata replaces the macOS metadata for the file or directory at
+// the specified path with the specified attributes. If the platform and
+// filesystem support extended attributes, then the metadata is written
+// natively, otherwise it's written to the entry's AppleDouble sidecar file
+// (which is created with the specified permissions, or removed if there are no
+// attributes).
+func writeMacOSMetadata(path string, attributes []*MacOSAttribute, permissions os.FileMode) error {
+	// Attempt to list existing attributes natively. If that's not supported,
+	// then fall back to the AppleDouble sidecar file.
+	existing, err := filesystem.ListExtendedAttributes(path)
+	if err == filesystem.ErrExtendedAttributesUnsupported {
+		return writeAppleDouble(appleDoublePath(path), attributes, permissions)
+	} else if err != nil {
+		return errors.Wrap(err, "unable to list extended attributes")
+	}
+
+	// Remove existing metadata attributes that aren't present in the target.
+	targets := make(map[string]bool, len(attributes))
+	for _, attribute := range attributes {
+		targets[attribute.Name] = true
+	}
+	for _, name := range existing {
+		if isMacOSMetadataAttribute(name) && !targets[name] {
+			if err := filesystem.RemoveExtendedAttribute(path, name); err != nil {
+				return errors.Wrapf(err, "unable to remove extended attribute (%s)", name)
+			}
+		}
+	}
+
+	// Set target attributes.
+	for _, attribute := range attributes {
+		if err := filesystem.WriteExtendedAttribute(path, attribute.Name, attribute.Value); err != nil {
+			return errors.Wrapf(err, "unable to write extended attribute (%s)", attribute.Name)
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// writeAppleDouble writes macOS metadata to the AppleDouble sidecar file at the
+// specified path with the specified permissions. If there are no attributes,
+// then any existing sidecar file is removed.
+func writeAppleDouble(path string, attributes []*MacOSAttribute, permissions os.FileMode) error {
+	if len(attributes) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "unable to remove AppleDouble file")
+		}
+		return nil
+	}
+	if err := filesystem.WriteFileAtomic(path, encodeAppleDouble(attributes), permissions); err != nil {
+		return errors.Wrap(err, "unable to write AppleDouble file")
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+)
+
+// TestMacOSMetadataNativeStorage tests that macOS metadata is stored natively
+// (rather than in AppleDouble sidecar files) on macOS.
+func TestMacOSMetadataNativeStorage(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_macos_metadata")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create a file and write metadata to it.
+	path := filepath.Join(directory, "file")
+	if err := ioutil.WriteFile(path, []byte("content"), 0600); err != nil {
+		t.Fatal("unable to create file:", err)
+	}
+	attributes := newTestMacOSMetadata(true)
+	if err := writeMacOSMetadata(path, attributes, 0600); err != nil {
+		t.Fatal("unable to write metadata:", err)
+	}
+
+	// Verify that the Finder tags and resource fork were stored natively.
+	for _, attribute := range attributes {
+		if value, err := filesystem.ReadExtendedAttribute(path, attribute.Name); err != nil {
+			t.Errorf("unable to read extended attribute (%s): %v", attribute.Name, err)
+		} else if !bytes.Equal(value, attribute.Value) {
+			t.Errorf("extended attribute (%s) value mismatch", attribute.Name)
+		}
+	}
+
+	// Verify that no sidecar file was created.
+	if _, err := os.Lstat(appleDoublePath(path)); !os.IsNotExist(err) {
+		t.Error("AppleDouble file created despite native support")
+	}
+
+	// Clear the metadata and verify that it's removed.
+	if err := writeMacOSMetadata(path, nil, 0600); err != nil {
+		t.Fatal("unable to clear metadata:", err)
+	} else if remaining, err := readMacOSMetadata(path); err != nil {
+		t.Fatal("unable to read metadata:", err)
+	} else if len(remaining) != 0 {
+		t.Error("metadata remains after clearing:", len(remaining))
+	}
+}
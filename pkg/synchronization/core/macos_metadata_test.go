@@ -0,0 +1,313 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+// testMacOSFinderTags is a Finder tags attribute value (a binary property list
+// containing the tags "Red" and "Work").
+var testMacOSFinderTags = []byte("bplist00\xa2\x01\x02UWork\nRed\n6\x08\x0b\x11\x00\x00\x00\x00\x00\x00\x01\x01\x00\x00\x00\x00\x00\x00\x00\x03\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x17")
+
+// newTestMacOSMetadata creates a set of macOS metadata attributes for testing,
+// optionally including a resource fork.
+func newTestMacOSMetadata(resourceFork bool) []*MacOSAttribute {
+	finderInfo := make([]byte, macOSFinderInfoSize)
+	copy(finderInfo, "TEXTttxt")
+	result := []*MacOSAttribute{
+		{Name: macOSFinderInfoAttribute, Value: finderInfo},
+		{Name: "com.apple.metadata:_kMDItemUserTags", Value: testMacOSFinderTags},
+	}
+	if resourceFork {
+		result = append(result, &MacOSAttribute{
+			Name:  macOSResourceForkAttribute,
+			Value: []byte("resource fork content"),
+		})
+	}
+	sortMacOSMetadata(result)
+	return result
+}
+
+// testMacOSMetadataEqual determines whether or not two sets of macOS metadata
+// attributes are equal.
+func testMacOSMetadataEqual(first, second []*MacOSAttribute) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	for a, attribute := range first {
+		if attribute.Name != second[a].Name || !bytes.Equal(attribute.Value, second[a].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestIsMacOSMetadataAttribute tests isMacOSMetadataAttribute.
+func TestIsMacOSMetadataAttribute(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected bool
+	}{
+		{"", false},
+		{"com.apple.", false},
+		{"user.comment", false},
+		{macOSFinderInfoAttribute, true},
+		{macOSResourceForkAttribute, true},
+		{"com.apple.metadata:_kMDItemUserTags", true},
+		{"com.apple.quarantine", false},
+		{"com.apple.decmpfs", false},
+		{"com.apple.system.Security", false},
+	}
+	for _, testCase := range testCases {
+		if result := isMacOSMetadataAttribute(testCase.name); result != testCase.expected {
+			t.Errorf("result for %q does not match expected: %t != %t",
+				testCase.name, result, testCase.expected,
+			)
+		}
+	}
+}
+
+// TestIsAppleDoubleName tests isAppleDoubleName.
+func TestIsAppleDoubleName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected bool
+	}{
+		{"", false},
+		{"._", false},
+		{"._file", true},
+		{"file", false},
+		{"file._", false},
+	}
+	for _, testCase := range testCases {
+		if result := isAppleDoubleName(testCase.name); result != testCase.expected {
+			t.Errorf("result for %q does not match expected: %t != %t",
+				testCase.name, result, testCase.expected,
+			)
+		}
+	}
+}
+
+// TestEnsureMacOSMetadataValid tests ensureMacOSMetadataValid.
+func TestEnsureMacOSMetadataValid(t *testing.T) {
+	if err := ensureMacOSMetadataValid(nil); err != nil {
+		t.Error("empty metadata treated as invalid:", err)
+	}
+	if err := ensureMacOSMetadataValid(newTestMacOSMetadata(true)); err != nil {
+		t.Error("valid metadata treated as invalid:", err)
+	}
+	invalid := [][]*MacOSAttribute{
+		{nil},
+		{{Name: "user.comment"}},
+		{{Name: macOSFinderInfoAttribute, Value: []byte("short")}},
+		{{Name: "com.apple.metadata:_kMDItemUserTags"}, {Name: macOSResourceForkAttribute}},
+		{{Name: macOSResourceForkAttribute}, {Name: macOSResourceForkAttribute}},
+	}
+	for i, attributes := range invalid {
+		if ensureMacOSMetadataValid(attributes) == nil {
+			t.Errorf("invalid metadata %d treated as valid", i)
+		}
+	}
+}
+
+// TestAppleDoubleRoundTrip tests that macOS metadata survives an AppleDouble
+// encoding round trip.
+func TestAppleDoubleRoundTrip(t *testing.T) {
+	for _, resourceFork := range []bool{false, true} {
+		attributes := newTestMacOSMetadata(resourceFork)
+		decoded, err := decodeAppleDouble(encodeAppleDouble(attributes))
+		if err != nil {
+			t.Fatal("unable to decode AppleDouble data:", err)
+		} else if !testMacOSMetadataEqual(decoded, attributes) {
+			t.Errorf("decoded metadata (resource fork: %t) does not match original", resourceFork)
+		}
+	}
+}
+
+// TestAppleDoubleDecodeInvalid tests that decodeAppleDouble rejects invalid
+// data.
+func TestAppleDoubleDecodeInvalid(t *testing.T) {
+	valid := encodeAppleDouble(newTestMacOSMetadata(true))
+	invalid := [][]byte{
+		nil,
+		valid[:appleDoubleHeaderSize-1],
+		append([]byte{0xff}, valid[1:]...),
+		valid[:len(valid)-1],
+	}
+	for i, data := range invalid {
+		if _, err := decodeAppleDouble(data); err == nil {
+			t.Errorf("invalid data %d decoded successfully", i)
+		}
+	}
+}
+
+// TestMacOSMetadataScanTransitionRoundTrip tests that macOS metadata captured
+// by Scan is restored by Transition. On macOS this exercises native storage,
+// while on other platforms it exercises AppleDouble sidecar files.
+func TestMacOSMetadataScanTransitionRoundTrip(t *testing.T) {
+	// Create a temporary directory to hold all test content and defer its
+	// removal.
+	parent, err := ioutil.TempDir("", "mutagen_macos_metadata")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Create source content with metadata on a directory and file.
+	source := filepath.Join(parent, "source")
+	contentMap := map[string][]byte{"directory/file": []byte("file content")}
+	if err := os.MkdirAll(filepath.Join(source, "directory"), 0700); err != nil {
+		t.Fatal("unable to create source directories:", err)
+	}
+	filePath := filepath.Join(source, "directory", "file")
+	if err := ioutil.WriteFile(filePath, contentMap["directory/file"], 0600); err != nil {
+		t.Fatal("unable to create source file:", err)
+	}
+	fileMetadata := newTestMacOSMetadata(true)
+	if err := writeMacOSMetadata(filePath, fileMetadata, 0600); err != nil {
+		t.Fatal("unable to write file metadata:", err)
+	}
+	directoryMetadata := newTestMacOSMetadata(false)
+	if err := writeMacOSMetadata(filepath.Join(source, "directory"), directoryMetadata, 0600); err != nil {
+		t.Fatal("unable to write directory metadata:", err)
+	}
+
+	// Perform a scan with metadata preservation enabled.
+	snapshot, _, _, _, _, _, err := Scan(
+		context.Background(),
+		source,
+		nil, nil,
+		newTestHasher(), nil,
+		nil, nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode:     BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:               ACLMode_ACLModeIgnore,
+			PreserveMacOSMetadata: true,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	} else if err = snapshot.EnsureValid(); err != nil {
+		t.Fatal("scan produced invalid snapshot:", err)
+	}
+
+	// Verify that metadata was captured and that sidecar files weren't treated
+	// as content.
+	directory := snapshot.Contents["directory"]
+	if directory == nil {
+		t.Fatal("directory missing from snapshot")
+	} else if len(directory.Contents) != 1 {
+		t.Fatal("directory has unexpected content count:", len(directory.Contents))
+	} else if !testMacOSMetadataEqual(directory.MacOSMetadata, directoryMetadata) {
+		t.Error("directory metadata not captured correctly")
+	} else if file := directory.Contents["file"]; file == nil {
+		t.Fatal("file missing from snapshot")
+	} else if !testMacOSMetadataEqual(file.MacOSMetadata, fileMetadata) {
+		t.Error("file metadata not captured correctly")
+	}
+
+	// Create a provider and defer its cleanup.
+	provider, err := newTestProvider(contentMap, newTestHasher())
+	if err != nil {
+		t.Fatal("unable to create test provider:", err)
+	}
+	defer provider.finalize()
+
+	// Transition the snapshot to a new target with metadata preservation
+	// enabled.
+	target := filepath.Join(parent, "target")
+	_, problems, providerMissingFiles := Transition(
+		context.Background(),
+		target,
+		[]*Change{{New: snapshot}},
+		nil,
+		SymlinkMode_SymlinkModePortable,
+		defaultFilePermissionMode,
+		defaultDirectoryPermissionMode,
+		nil,
+		false,
+		provider,
+		&TransitionOptions{
+			DurabilityMode:        DurabilityMode_DurabilityModeNone,
+			Syncer:                filesystem.SystemSyncer,
+			ACLMode:               ACLMode_ACLModeIgnore,
+			PreserveMacOSMetadata: true,
+		},
+	)
+	if providerMissingFiles {
+		t.Fatal("provider missing files during transition")
+	} else if len(problems) > 0 {
+		t.Fatal("transition encountered problems:", problems)
+	}
+
+	// Verify that metadata was restored on the target.
+	if restored, err := readMacOSMetadata(filepath.Join(target, "directory")); err != nil {
+		t.Fatal("unable to read restored directory metadata:", err)
+	} else if !testMacOSMetadataEqual(restored, directoryMetadata) {
+		t.Error("directory metadata not restored correctly")
+	}
+	if restored, err := readMacOSMetadata(filepath.Join(target, "directory", "file")); err != nil {
+		t.Fatal("unable to read restored file metadata:", err)
+	} else if !testMacOSMetadataEqual(restored, fileMetadata) {
+		t.Error("file metadata not restored correctly")
+	}
+
+	// Rescan the target to verify that its metadata is captured and to obtain
+	// a cache for removal.
+	targetSnapshot, _, _, targetCache, _, _, err := Scan(
+		context.Background(),
+		target,
+		nil, nil,
+		newTestHasher(), nil,
+		nil, nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode:     BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:               ACLMode_ACLModeIgnore,
+			PreserveMacOSMetadata: true,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform target scan:", err)
+	} else if !targetSnapshot.Equal(snapshot) {
+		t.Fatal("target snapshot does not match source snapshot")
+	} else if file := targetSnapshot.Contents["directory"].Contents["file"]; !testMacOSMetadataEqual(file.MacOSMetadata, fileMetadata) {
+		t.Error("restored file metadata not captured correctly")
+	}
+
+	// Remove the target content and verify that any sidecar files are removed
+	// along with it.
+	_, problems, _ = Transition(
+		context.Background(),
+		target,
+		[]*Change{{Old: targetSnapshot}},
+		targetCache,
+		SymlinkMode_SymlinkModePortable,
+		defaultFilePermissionMode,
+		defaultDirectoryPermissionMode,
+		nil,
+		false,
+		provider,
+		&TransitionOptions{
+			DurabilityMode:        DurabilityMode_DurabilityModeNone,
+			Syncer:                filesystem.SystemSyncer,
+			ACLMode:               ACLMode_ACLModeIgnore,
+			PreserveMacOSMetadata: true,
+		},
+	)
+	if len(problems) > 0 {
+		t.Fatal("removal transition encountered problems:", problems)
+	} else if _, err := os.Lstat(target); !os.IsNotExist(err) {
+		t.Error("target root still exists after removal")
+	}
+}
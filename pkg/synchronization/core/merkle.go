@@ -0,0 +1,78 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"sort"
+)
+
+const (
+	// merkleNodeNil is the node type prefix for nil entries.
+	merkleNodeNil byte = iota
+	// merkleNodeDirectory is the node type prefix for directory entries.
+	merkleNodeDirectory
+	// merkleNodeFile is the node type prefix for file entries.
+	merkleNodeFile
+	// merkleNodeSymlink is the node type prefix for symbolic link entries.
+	merkleNodeSymlink
+)
+
+// MerkleRootLength is the length of digests returned by Entry.MerkleRoot.
+const MerkleRootLength = sha256.Size
+
+// merkleWriteBytes writes a length-prefixed byte sequence to a hasher. Length
+// prefixing ensures that adjacent values can't be ambiguously combined.
+func merkleWriteBytes(hasher hash.Hash, value []byte) {
+	var length [binary.MaxVarintLen64]byte
+	hasher.Write(length[:binary.PutUvarint(length[:], uint64(len(value)))])
+	hasher.Write(value)
+}
+
+// MerkleRoot computes a Merkle tree digest of the entry and its contents. Each
+// node's digest combines its type with its content digest and executability
+// (for files), its target (for symbolic links), or the names and digests of
+// its contents in sorted order (for directories). The result is thus stable
+// across scans of unchanged content, but sensitive to any change in file
+// content, executability, symbolic link targets, or hierarchy structure. Other
+// metadata (e.g. ACLs or file flags) isn't included. The entry may be nil.
+func (e *Entry) MerkleRoot() []byte {
+	// Create a hasher for this node.
+	hasher := sha256.New()
+
+	// Hash the node based on its type.
+	if e == nil {
+		hasher.Write([]byte{merkleNodeNil})
+	} else if e.Kind == EntryKind_Directory {
+		// Write the node type.
+		hasher.Write([]byte{merkleNodeDirectory})
+
+		// Sort content names so that the digest is independent of map
+		// iteration order.
+		names := make([]string, 0, len(e.Contents))
+		for name := range e.Contents {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		// Write each content name and the digest of its content.
+		for _, name := range names {
+			merkleWriteBytes(hasher, []byte(name))
+			hasher.Write(e.Contents[name].MerkleRoot())
+		}
+	} else if e.Kind == EntryKind_File {
+		hasher.Write([]byte{merkleNodeFile})
+		if e.Executable {
+			hasher.Write([]byte{1})
+		} else {
+			hasher.Write([]byte{0})
+		}
+		merkleWriteBytes(hasher, e.Digest)
+	} else if e.Kind == EntryKind_Symlink {
+		hasher.Write([]byte{merkleNodeSymlink})
+		merkleWriteBytes(hasher, []byte(e.Target))
+	}
+
+	// Done.
+	return hasher.Sum(nil)
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+// TestMerkleRootLength tests that Merkle roots have the expected length.
+func TestMerkleRootLength(t *testing.T) {
+	for _, entry := range []*Entry{testNilEntry, testFile1Entry, testDirectory1Entry} {
+		if root := entry.MerkleRoot(); len(root) != MerkleRootLength {
+			t.Error("Merkle root has unexpected length:", len(root))
+		}
+	}
+}
+
+// TestMerkleRootStable tests that Merkle roots are identical for equal entries,
+// regardless of how they were constructed.
+func TestMerkleRootStable(t *testing.T) {
+	// Construct equivalent directories with contents inserted in different
+	// orders.
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	forward := &Entry{Contents: make(map[string]*Entry)}
+	reverse := &Entry{Contents: make(map[string]*Entry)}
+	for i := range names {
+		forward.Contents[names[i]] = testFile1Entry
+		reverse.Contents[names[len(names)-1-i]] = testFile1Entry
+	}
+	if !bytes.Equal(forward.MerkleRoot(), reverse.MerkleRoot()) {
+		t.Error("Merkle root depends on construction order")
+	}
+
+	// Verify that copies have identical roots.
+	if !bytes.Equal(testDirectory1Entry.MerkleRoot(), testDirectory1Entry.Copy().MerkleRoot()) {
+		t.Error("Merkle root of copy differs from original")
+	}
+}
+
+// TestMerkleRootSensitivity tests that Merkle roots change in response to any
+// change in file content, executability, symbolic link targets, or structure.
+func TestMerkleRootSensitivity(t *testing.T) {
+	// Set up test cases. Each modifier operates on a copy of
+	// testDirectory1Entry.
+	testCases := []struct {
+		description string
+		modifier    func(*Entry) *Entry
+	}{
+		{"file content", func(e *Entry) *Entry {
+			e.Contents["file"] = testFile3Entry
+			return e
+		}},
+		{"nested file content", func(e *Entry) *Entry {
+			e.Contents["directory"].Contents["subfile"] = testFile1Entry
+			return e
+		}},
+		{"executability", func(e *Entry) *Entry {
+			e.Contents["file"] = &Entry{Kind: EntryKind_File, Digest: testFile1Entry.Digest, Executable: true}
+			return e
+		}},
+		{"symbolic link target", func(e *Entry) *Entry {
+			e.Contents["symlink"] = &Entry{Kind: EntryKind_Symlink, Target: "file"}
+			return e
+		}},
+		{"rename", func(e *Entry) *Entry {
+			e.Contents["renamed file"] = e.Contents["file"]
+			delete(e.Contents, "file")
+			return e
+		}},
+		{"move", func(e *Entry) *Entry {
+			e.Contents["directory"].Contents["file"] = e.Contents["file"]
+			delete(e.Contents, "file")
+			return e
+		}},
+		{"deletion", func(e *Entry) *Entry {
+			delete(e.Contents, "file")
+			return e
+		}},
+		{"empty directory creation", func(e *Entry) *Entry {
+			e.Contents["new directory"] = &Entry{}
+			return e
+		}},
+		{"type change", func(e *Entry) *Entry {
+			e.Contents["file"] = &Entry{}
+			return e
+		}},
+		{"root deletion", func(e *Entry) *Entry {
+			return nil
+		}},
+		{"root emptied", func(e *Entry) *Entry {
+			return &Entry{}
+		}},
+	}
+
+	// Process test cases, ensuring that each modification yields a unique
+	// root.
+	base := testDirectory1Entry.MerkleRoot()
+	seen := map[string]string{string(base): "original"}
+	for _, testCase := range testCases {
+		root := testCase.modifier(testDirectory1Entry.Copy()).MerkleRoot()
+		if previous, ok := seen[string(root)]; ok {
+			t.Errorf("%s: Merkle root matches that for %s", testCase.description, previous)
+		}
+		seen[string(root)] = testCase.description
+	}
+}
+
+// testMerkleRootScan scans the specified root and returns the Merkle root of
+// the resulting snapshot.
+func testMerkleRootScan(t *testing.T, root string) []byte {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Perform the scan.
+	snapshot, _, _, _, _, _, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		newTestHasher(),
+		nil,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	}
+
+	// Compute the root.
+	return snapshot.MerkleRoot()
+}
+
+// TestMerkleRootRescan tests that Merkle roots are stable across re-scans of
+// unchanged content and change when on-disk content changes.
+func TestMerkleRootRescan(t *testing.T) {
+	// Create test content on disk and defer its removal.
+	root, parent, err := testTransitionCreate("", testDirectory1Entry, testDirectory1ContentMap, false)
+	if err != nil {
+		t.Fatal("unable to create test content:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Verify that repeated scans yield the same root.
+	initial := testMerkleRootScan(t, root)
+	if !bytes.Equal(testMerkleRootScan(t, root), initial) {
+		t.Fatal("Merkle root not stable across scans")
+	}
+
+	// Modify file content and verify that the root changes.
+	if err := ioutil.WriteFile(filepath.Join(root, "file"), testFile3Contents, 0600); err != nil {
+		t.Fatal("unable to modify file:", err)
+	}
+	modified := testMerkleRootScan(t, root)
+	if bytes.Equal(modified, initial) {
+		t.Error("Merkle root unchanged after content modification")
+	}
+
+	// Create a directory and verify that the root changes.
+	if err := os.Mkdir(filepath.Join(root, "new directory"), 0700); err != nil {
+		t.Fatal("unable to create directory:", err)
+	}
+	if bytes.Equal(testMerkleRootScan(t, root), modified) {
+		t.Error("Merkle root unchanged after directory creation")
+	}
+}
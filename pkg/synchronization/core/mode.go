@@ -22,6 +22,8 @@ func (m *SynchronizationMode) UnmarshalText(textBytes []byte) error {
 		*m = SynchronizationMode_SynchronizationModeTwoWaySafe
 	case "two-way-resolved":
 		*m = SynchronizationMode_SynchronizationModeTwoWayResolved
+	case "two-way-resolved-beta":
+		*m = SynchronizationMode_SynchronizationModeTwoWayResolvedBeta
 	case "one-way-safe":
 		*m = SynchronizationMode_SynchronizationModeOneWaySafe
 	case "one-way-replica":
@@ -42,6 +44,8 @@ func (m SynchronizationMode) Supported() bool {
 		return true
 	case SynchronizationMode_SynchronizationModeTwoWayResolved:
 		return true
+	case SynchronizationMode_SynchronizationModeTwoWayResolvedBeta:
+		return true
 	case SynchronizationMode_SynchronizationModeOneWaySafe:
 		return true
 	case SynchronizationMode_SynchronizationModeOneWayReplica:
@@ -51,6 +55,14 @@ func (m SynchronizationMode) Supported() bool {
 	}
 }
 
+// IsUnidirectional indicates whether or not a particular synchronization mode
+// only propagates changes from alpha to beta, i.e. whether or not alpha is
+// never modified by synchronization.
+func (m SynchronizationMode) IsUnidirectional() bool {
+	return m == SynchronizationMode_SynchronizationModeOneWaySafe ||
+		m == SynchronizationMode_SynchronizationModeOneWayReplica
+}
+
 // Description returns a human-readable description of a synchronization mode.
 func (m SynchronizationMode) Description() string {
 	switch m {
@@ -60,6 +72,8 @@ func (m SynchronizationMode) Description() string {
 		return "Two Way Safe"
 	case SynchronizationMode_SynchronizationModeTwoWayResolved:
 		return "Two Way Resolved"
+	case SynchronizationMode_SynchronizationModeTwoWayResolvedBeta:
+		return "Two Way Resolved (Beta)"
 	case SynchronizationMode_SynchronizationModeOneWaySafe:
 		return "One Way Safe"
 	case SynchronizationMode_SynchronizationModeOneWayReplica:
@@ -58,6 +58,12 @@ const (
 	// (verbatim) to beta, overwriting any conflicting contents on beta and
 	// deleting any extraneous contents on beta.
 	SynchronizationMode_SynchronizationModeOneWayReplica SynchronizationMode = 4
+	// SynchronizationMode_SynchronizationModeTwoWayResolvedBeta is the same as
+	// SynchronizationMode_SynchronizationModeTwoWayResolved, but specifies that
+	// the beta endpoint should win automatically in any conflict between alpha
+	// and beta, including cases where beta has deleted contents that alpha has
+	// modified.
+	SynchronizationMode_SynchronizationModeTwoWayResolvedBeta SynchronizationMode = 5
 )
 
 // Enum value maps for SynchronizationMode.
@@ -68,13 +74,15 @@ var (
 		2: "SynchronizationModeTwoWayResolved",
 		3: "SynchronizationModeOneWaySafe",
 		4: "SynchronizationModeOneWayReplica",
+		5: "SynchronizationModeTwoWayResolvedBeta",
 	}
 	SynchronizationMode_value = map[string]int32{
-		"SynchronizationModeDefault":        0,
-		"SynchronizationModeTwoWaySafe":     1,
-		"SynchronizationModeTwoWayResolved": 2,
-		"SynchronizationModeOneWaySafe":     3,
-		"SynchronizationModeOneWayReplica":  4,
+		"SynchronizationModeDefault":            0,
+		"SynchronizationModeTwoWaySafe":         1,
+		"SynchronizationModeTwoWayResolved":     2,
+		"SynchronizationModeOneWaySafe":         3,
+		"SynchronizationModeOneWayReplica":      4,
+		"SynchronizationModeTwoWayResolvedBeta": 5,
 	}
 )
 
@@ -110,7 +118,7 @@ var File_synchronization_core_mode_proto protoreflect.FileDescriptor
 var file_synchronization_core_mode_proto_rawDesc = []byte{
 	0x0a, 0x1f, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f,
 	0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x6d, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x12, 0x04, 0x63, 0x6f, 0x72, 0x65, 0x2a, 0xc8, 0x01, 0x0a, 0x13, 0x53, 0x79, 0x6e, 0x63,
+	0x6f, 0x12, 0x04, 0x63, 0x6f, 0x72, 0x65, 0x2a, 0xf3, 0x01, 0x0a, 0x13, 0x53, 0x79, 0x6e, 0x63,
 	0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x6f, 0x64, 0x65, 0x12,
 	0x1e, 0x0a, 0x1a, 0x53, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69,
 	0x6f, 0x6e, 0x4d, 0x6f, 0x64, 0x65, 0x44, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x10, 0x00, 0x12,
@@ -123,11 +131,13 @@ var file_synchronization_core_mode_proto_rawDesc = []byte{
 	0x4f, 0x6e, 0x65, 0x57, 0x61, 0x79, 0x53, 0x61, 0x66, 0x65, 0x10, 0x03, 0x12, 0x24, 0x0a, 0x20,
 	0x53, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d,
 	0x6f, 0x64, 0x65, 0x4f, 0x6e, 0x65, 0x57, 0x61, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61,
-	0x10, 0x04, 0x42, 0x38, 0x5a, 0x36, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
-	0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e, 0x2d, 0x69, 0x6f, 0x2f, 0x6d, 0x75, 0x74, 0x61,
-	0x67, 0x65, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e,
-	0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x62, 0x06, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x33,
+	0x10, 0x04, 0x12, 0x29, 0x0a, 0x25, 0x53, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x6f, 0x64, 0x65, 0x54, 0x77, 0x6f, 0x57, 0x61, 0x79, 0x52,
+	0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x64, 0x42, 0x65, 0x74, 0x61, 0x10, 0x05, 0x42, 0x38, 0x5a,
+	0x36, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x75, 0x74, 0x61,
+	0x67, 0x65, 0x6e, 0x2d, 0x69, 0x6f, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e, 0x2f, 0x70,
+	0x6b, 0x67, 0x2f, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -17,6 +17,7 @@ func TestSynchronizationModeUnmarshal(t *testing.T) {
 		{"asdf", SynchronizationMode_SynchronizationModeDefault, true},
 		{"two-way-safe", SynchronizationMode_SynchronizationModeTwoWaySafe, false},
 		{"two-way-resolved", SynchronizationMode_SynchronizationModeTwoWayResolved, false},
+		{"two-way-resolved-beta", SynchronizationMode_SynchronizationModeTwoWayResolvedBeta, false},
 		{"one-way-safe", SynchronizationMode_SynchronizationModeOneWaySafe, false},
 		{"one-way-replica", SynchronizationMode_SynchronizationModeOneWayReplica, false},
 	}
@@ -53,7 +54,8 @@ func TestSynchronizationModeSupported(t *testing.T) {
 		{SynchronizationMode_SynchronizationModeTwoWayResolved, true},
 		{SynchronizationMode_SynchronizationModeOneWaySafe, true},
 		{SynchronizationMode_SynchronizationModeOneWayReplica, true},
-		{(SynchronizationMode_SynchronizationModeOneWayReplica + 1), false},
+		{SynchronizationMode_SynchronizationModeTwoWayResolvedBeta, true},
+		{(SynchronizationMode_SynchronizationModeTwoWayResolvedBeta + 1), false},
 	}
 
 	// Process test cases.
@@ -68,6 +70,34 @@ func TestSynchronizationModeSupported(t *testing.T) {
 	}
 }
 
+// TestSynchronizationModeIsUnidirectional tests that SynchronizationMode
+// directionality detection works as expected.
+func TestSynchronizationModeIsUnidirectional(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                 SynchronizationMode
+		expectUnidirectional bool
+	}{
+		{SynchronizationMode_SynchronizationModeDefault, false},
+		{SynchronizationMode_SynchronizationModeTwoWaySafe, false},
+		{SynchronizationMode_SynchronizationModeTwoWayResolved, false},
+		{SynchronizationMode_SynchronizationModeOneWaySafe, true},
+		{SynchronizationMode_SynchronizationModeOneWayReplica, true},
+		{SynchronizationMode_SynchronizationModeTwoWayResolvedBeta, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if unidirectional := testCase.mode.IsUnidirectional(); unidirectional != testCase.expectUnidirectional {
+			t.Errorf(
+				"mode directionality (%t) does not match expected (%t)",
+				unidirectional,
+				testCase.expectUnidirectional,
+			)
+		}
+	}
+}
+
 // TestSynchronizationModeDescription tests that SynchronizationMode description
 // generation works as expected.
 func TestSynchronizationModeDescription(t *testing.T) {
@@ -81,7 +111,8 @@ func TestSynchronizationModeDescription(t *testing.T) {
 		{SynchronizationMode_SynchronizationModeTwoWayResolved, "Two Way Resolved"},
 		{SynchronizationMode_SynchronizationModeOneWaySafe, "One Way Safe"},
 		{SynchronizationMode_SynchronizationModeOneWayReplica, "One Way Replica"},
-		{(SynchronizationMode_SynchronizationModeOneWayReplica + 1), "Unknown"},
+		{SynchronizationMode_SynchronizationModeTwoWayResolvedBeta, "Two Way Resolved (Beta)"},
+		{(SynchronizationMode_SynchronizationModeTwoWayResolvedBeta + 1), "Unknown"},
 	}
 
 	// Process test cases.
@@ -0,0 +1,337 @@
+package core
+
+import (
+	"runtime"
+	"strings"
+)
+
+const (
+	// nameEscapeOffset is the offset used to map characters that can't be
+	// represented in names to Unicode private use characters.
+	nameEscapeOffset = 0xF000
+	// nameEscapeMaximum is the largest character that escaping will generate.
+	nameEscapeMaximum = nameEscapeOffset + 0x7F
+)
+
+// windowsReservedNames are the (uppercase) device names reserved on Windows.
+// Names are reserved if their portion before the first dot matches one of these
+// names case-insensitively.
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// characterInvalidOnWindows indicates whether or not a character is disallowed
+// in names on Windows.
+func characterInvalidOnWindows(r rune) bool {
+	return r < 0x20 || strings.ContainsRune(`<>:"/\|?*`, r)
+}
+
+// windowsReservedStemLength returns the length of the portion of a name before
+// its first dot if that portion is a reserved device name on Windows and 0
+// otherwise.
+func windowsReservedStemLength(name string) int {
+	stem := name
+	if dot := strings.IndexByte(name, '.'); dot >= 0 {
+		stem = name[:dot]
+	}
+	if windowsReservedNames[strings.ToUpper(stem)] {
+		return len(stem)
+	}
+	return 0
+}
+
+// nameInvalidOnWindows indicates whether or not a name can't be represented on
+// Windows.
+func nameInvalidOnWindows(name string) bool {
+	if strings.IndexFunc(name, characterInvalidOnWindows) >= 0 {
+		return true
+	} else if last := name[len(name)-1]; last == '.' || last == ' ' {
+		return true
+	}
+	return windowsReservedStemLength(name) > 0
+}
+
+// containsEscapeCharacters indicates whether or not a name contains characters
+// in the range used for escaping.
+func containsEscapeCharacters(name string) bool {
+	return strings.IndexFunc(name, func(r rune) bool {
+		return r >= nameEscapeOffset && r <= nameEscapeMaximum
+	}) >= 0
+}
+
+// escapeName escapes a name that can't be represented on Windows. It maps
+// disallowed characters, any trailing dot or space, and the final character of
+// any reserved device name stem to the private use range.
+func escapeName(name string) string {
+	// Escape disallowed characters.
+	characters := []rune(name)
+	for c, r := range characters {
+		if characterInvalidOnWindows(r) {
+			characters[c] = r + nameEscapeOffset
+		}
+	}
+
+	// Escape any trailing dot or space.
+	if last := characters[len(characters)-1]; last == '.' || last == ' ' {
+		characters[len(characters)-1] = last + nameEscapeOffset
+	}
+
+	// Escape the final character of any reserved device name stem. Reserved
+	// stems consist only of ASCII characters, so their length in bytes is
+	// equal to their length in characters.
+	if length := windowsReservedStemLength(name); length > 0 {
+		characters[length-1] += nameEscapeOffset
+	}
+
+	// Done.
+	return string(characters)
+}
+
+// unescapeName reverses escapeName.
+func unescapeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= nameEscapeOffset && r <= nameEscapeMaximum {
+			return r - nameEscapeOffset
+		}
+		return r
+	}, name)
+}
+
+// NameTranslator translates between the names of synchronized content and the
+// names used to represent that content on disk for filesystems that can't
+// represent all names. Depending on its mode, it either skips content with
+// names that can't be represented or escapes those names on disk. It is
+// stateless and thus safe for concurrent usage.
+type NameTranslator struct {
+	// escape indicates whether or not names that can't be represented should
+	// be escaped (rather than skipped).
+	escape bool
+}
+
+// NewNameTranslator creates a name translator using the specified invalid name
+// mode, which must be a non-default value. It returns nil if all names can be
+// represented on the current platform, in which case no translation is
+// necessary.
+func NewNameTranslator(mode InvalidNameMode) *NameTranslator {
+	if runtime.GOOS != "windows" {
+		return nil
+	}
+	return &NameTranslator{escape: mode == InvalidNameMode_InvalidNameModeEscape}
+}
+
+// encodeName computes the on-disk representation of a name. It returns false
+// if the name can't be represented.
+func (t *NameTranslator) encodeName(name string) (string, bool) {
+	if t.escape && containsEscapeCharacters(name) {
+		return "", false
+	} else if !nameInvalidOnWindows(name) {
+		return name, true
+	} else if !t.escape {
+		return "", false
+	}
+	return escapeName(name), true
+}
+
+// decodeName computes the synchronized name for an on-disk name. Names that
+// aren't in the form generated by encodeName are left untranslated (and will
+// be treated as unrepresentable if they're transitioned), which prevents
+// distinct on-disk names from mapping to the same synchronized name.
+func (t *NameTranslator) decodeName(name string) string {
+	if !t.escape || !containsEscapeCharacters(name) {
+		return name
+	}
+	decoded := unescapeName(name)
+	if decoded == "." || decoded == ".." {
+		return name
+	} else if !nameInvalidOnWindows(decoded) || escapeName(decoded) != name {
+		return name
+	}
+	return decoded
+}
+
+// translatePath applies a name translation function to each component of a
+// slash-separated path, leaving empty, "." and ".." components untouched. It
+// returns false if any component can't be translated.
+func translatePath(path string, translate func(string) (string, bool)) (string, bool) {
+	components := strings.Split(path, "/")
+	for c, component := range components {
+		if component == "" || component == "." || component == ".." {
+			continue
+		}
+		translated, ok := translate(component)
+		if !ok {
+			return "", false
+		}
+		components[c] = translated
+	}
+	return strings.Join(components, "/"), true
+}
+
+// EncodePath computes the on-disk representation of a synchronization path. It
+// returns false if the path can't be represented.
+func (t *NameTranslator) EncodePath(path string) (string, bool) {
+	return translatePath(path, t.encodeName)
+}
+
+// DecodePath computes the synchronization path for an on-disk path.
+func (t *NameTranslator) DecodePath(path string) string {
+	decoded, _ := translatePath(path, func(name string) (string, bool) {
+		return t.decodeName(name), true
+	})
+	return decoded
+}
+
+// encodeEntry computes the on-disk representation of the entry at the
+// specified path, excluding any content with names that can't be represented
+// and recording problems for that content.
+func (t *NameTranslator) encodeEntry(path string, entry *Entry, problems *[]*Problem) *Entry {
+	// Handle the trivial case.
+	if entry == nil {
+		return nil
+	}
+
+	// Create a shallow copy of the entry and translate its path references. If
+	// a hard link target can't be represented, then it will have been excluded
+	// and the hard link will fail, so we leave it untranslated.
+	result := entry.copySlim()
+	if entry.Kind == EntryKind_Symlink {
+		if target, ok := t.EncodePath(entry.Target); !ok {
+			*problems = append(*problems, &Problem{
+				Path:  path,
+				Error: "symbolic link target can't be represented on this platform",
+			})
+			return nil
+		} else {
+			result.Target = target
+		}
+	}
+	if entry.HardLink != "" {
+		if hardLink, ok := t.EncodePath(entry.HardLink); ok {
+			result.HardLink = hardLink
+		}
+	}
+
+	// Translate contents.
+	if len(entry.Contents) > 0 {
+		result.Contents = make(map[string]*Entry, len(entry.Contents))
+		for name, child := range entry.Contents {
+			childPath := pathJoin(path, name)
+			if encodedName, ok := t.encodeName(name); !ok {
+				*problems = append(*problems, &Problem{
+					Path:  childPath,
+					Error: "name can't be represented on this platform",
+				})
+			} else if encodedChild := t.encodeEntry(childPath, child, problems); encodedChild != nil {
+				result.Contents[encodedName] = encodedChild
+			}
+		}
+	}
+
+	// Done.
+	return result
+}
+
+// EncodeTransitions computes the on-disk representations of the specified
+// transitions. Content with names that can't be represented is excluded from
+// the transitions (and reported as problems). Transitions whose paths can't be
+// represented are replaced by no-op transitions, since no content can exist at
+// those paths.
+func (t *NameTranslator) EncodeTransitions(transitions []*Change) ([]*Change, []*Problem) {
+	results := make([]*Change, len(transitions))
+	var problems []*Problem
+	for i, transition := range transitions {
+		if path, ok := t.EncodePath(transition.Path); !ok {
+			results[i] = &Change{Path: transition.Path}
+			if transition.New != nil {
+				problems = append(problems, &Problem{
+					Path:  transition.Path,
+					Error: "name can't be represented on this platform",
+				})
+			}
+		} else {
+			results[i] = &Change{
+				Path:            path,
+				Old:             t.encodeEntry(transition.Path, transition.Old, &problems),
+				New:             t.encodeEntry(transition.Path, transition.New, &problems),
+				Resolve:         transition.Resolve,
+				ConflictSidecar: transition.ConflictSidecar,
+			}
+		}
+	}
+	return results, problems
+}
+
+// DecodeEntry computes the synchronized representation of an on-disk entry. If
+// no translation is necessary, then the original entry is returned. Entries are
+// treated as immutable, so untranslated subtrees are shared with the original.
+func (t *NameTranslator) DecodeEntry(entry *Entry) *Entry {
+	// In skip mode, on-disk names are always identical to synchronized names.
+	if !t.escape || entry == nil {
+		return entry
+	}
+
+	// Translate path references.
+	var result *Entry
+	if entry.Kind == EntryKind_Symlink {
+		if target := t.DecodePath(entry.Target); target != entry.Target {
+			result = entry.copySlim()
+			result.Target = target
+		}
+	}
+	if entry.HardLink != "" {
+		if hardLink := t.DecodePath(entry.HardLink); hardLink != entry.HardLink {
+			if result == nil {
+				result = entry.copySlim()
+			}
+			result.HardLink = hardLink
+		}
+	}
+
+	// Translate contents, only allocating a new content map if necessary.
+	var contents map[string]*Entry
+	for name, child := range entry.Contents {
+		decodedName, decodedChild := t.decodeName(name), t.DecodeEntry(child)
+		if contents == nil && (decodedName != name || decodedChild != child) {
+			contents = make(map[string]*Entry, len(entry.Contents))
+			for n, c := range entry.Contents {
+				contents[n] = c
+			}
+		}
+		if contents != nil {
+			delete(contents, name)
+			contents[decodedName] = decodedChild
+		}
+	}
+	if contents != nil {
+		if result == nil {
+			result = entry.copySlim()
+		}
+		result.Contents = contents
+	}
+
+	// If nothing was translated, then return the original entry.
+	if result == nil {
+		return entry
+	} else if contents == nil {
+		result.Contents = entry.Contents
+	}
+	return result
+}
+
+// DecodeProblems computes the synchronized paths for problems reported at
+// on-disk paths. If no translation is necessary, then the original problems are
+// returned.
+func (t *NameTranslator) DecodeProblems(problems []*Problem) []*Problem {
+	if !t.escape || len(problems) == 0 {
+		return problems
+	}
+	results := make([]*Problem, len(problems))
+	for p, problem := range problems {
+		results[p] = &Problem{Path: t.DecodePath(problem.Path), Error: problem.Error}
+	}
+	return results
+}
@@ -0,0 +1,237 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+// testDirectoryWithInvalidNames is a directory containing content with names
+// that are valid on POSIX systems but can't be represented on Windows.
+var testDirectoryWithInvalidNames = &Entry{
+	Kind: EntryKind_Directory,
+	Contents: map[string]*Entry{
+		"valid":    testFile1Entry,
+		"a:b":      testFile1Entry,
+		"what?":    testFile3Entry,
+		"trailer.": testFile3Entry,
+		"con.txt":  testFile1Entry,
+		"dir|ectory": {
+			Kind: EntryKind_Directory,
+			Contents: map[string]*Entry{
+				"nested<>": testFile3Entry,
+				"fine":     testFile1Entry,
+			},
+		},
+	},
+}
+
+// TestNameInvalidOnWindows tests nameInvalidOnWindows.
+func TestNameInvalidOnWindows(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		name     string
+		expected bool
+	}{
+		{"name", false},
+		{"name.txt", false},
+		{".hidden", false},
+		{"console", false},
+		{"COM10", false},
+		{"a:b", true},
+		{"what?", true},
+		{"star*", true},
+		{"back\\slash", true},
+		{"quote\"", true},
+		{"pipe|", true},
+		{"angle<>", true},
+		{"tab\t", true},
+		{"trailing.", true},
+		{"trailing ", true},
+		{"CON", true},
+		{"con.txt", true},
+		{"Lpt1.tar.gz", true},
+		{"nul", true},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if invalid := nameInvalidOnWindows(testCase.name); invalid != testCase.expected {
+			t.Errorf("invalidity of %q (%t) does not match expected (%t)", testCase.name, invalid, testCase.expected)
+		}
+	}
+}
+
+// TestNameTranslatorNames tests name encoding and decoding in both skip and
+// escape modes.
+func TestNameTranslatorNames(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		name          string
+		escaped       string
+		representable bool
+		skipAllowed   bool
+	}{
+		{"name", "name", true, true},
+		{"a:b", "a\uf03ab", true, false},
+		{"what?", "what\uf03f", true, false},
+		{"trailing.", "trailing\uf02e", true, false},
+		{"a. ", "a.\uf020", true, false},
+		{"con.txt", "co\uf06e.txt", true, false},
+		{"AUX", "AU\uf058", true, false},
+		{"x\uf03a", "", false, true},
+	}
+
+	// Create translators.
+	skip := &NameTranslator{}
+	escape := &NameTranslator{escape: true}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if encoded, ok := escape.encodeName(testCase.name); ok != testCase.representable {
+			t.Errorf("representability of %q (%t) does not match expected (%t)", testCase.name, ok, testCase.representable)
+		} else if ok && encoded != testCase.escaped {
+			t.Errorf("escaped form of %q (%q) does not match expected (%q)", testCase.name, encoded, testCase.escaped)
+		} else if ok && nameInvalidOnWindows(encoded) {
+			t.Errorf("escaped form of %q is invalid on Windows", testCase.name)
+		} else if ok && escape.decodeName(encoded) != testCase.name {
+			t.Errorf("escaped form of %q does not round-trip", testCase.name)
+		}
+		if encoded, ok := skip.encodeName(testCase.name); ok != testCase.skipAllowed {
+			t.Errorf("skip mode allowance of %q (%t) does not match expected (%t)", testCase.name, ok, testCase.skipAllowed)
+		} else if ok && encoded != testCase.name {
+			t.Errorf("skip mode modified %q", testCase.name)
+		}
+	}
+
+	// Verify that on-disk names that aren't in canonical escaped form aren't
+	// decoded, since they would collide with other names, and that names
+	// aren't decoded to special directory names.
+	for _, name := range []string{"a\uf061", "\uf03a\uf03a\uf03a.", "\uf02e", ".\uf02e"} {
+		if decoded := escape.decodeName(name); decoded != name {
+			t.Errorf("non-canonical name %q decoded to %q", name, decoded)
+		}
+	}
+}
+
+// TestNameTranslatorSkip tests that transitions in skip mode exclude content
+// with names that can't be represented and report that content as problems.
+func TestNameTranslatorSkip(t *testing.T) {
+	// Translate transitions that create content with invalid names.
+	translator := &NameTranslator{}
+	transitions, problems := translator.EncodeTransitions([]*Change{
+		{Path: "root", New: testDirectoryWithInvalidNames},
+		{Path: "root/x:y", New: testFile1Entry},
+	})
+
+	// Verify the translated transitions.
+	if len(transitions) != 2 {
+		t.Fatal("unexpected number of transitions:", len(transitions))
+	}
+	expected := &Entry{
+		Kind: EntryKind_Directory,
+		Contents: map[string]*Entry{
+			"valid": testFile1Entry,
+		},
+	}
+	if transitions[0].Path != "root" || !transitions[0].New.Equal(expected) {
+		t.Error("transition with invalid content names not filtered correctly")
+	}
+	if transitions[1].Path != "root/x:y" || transitions[1].Old != nil || transitions[1].New != nil {
+		t.Error("transition with invalid path not converted to no-op")
+	}
+
+	// Verify the problems.
+	expectedProblemPaths := map[string]bool{
+		"root/a:b":        true,
+		"root/what?":      true,
+		"root/trailer.":   true,
+		"root/con.txt":    true,
+		"root/dir|ectory": true,
+		"root/x:y":        true,
+	}
+	if len(problems) != len(expectedProblemPaths) {
+		t.Error("unexpected number of problems:", len(problems))
+	}
+	for _, problem := range problems {
+		if !expectedProblemPaths[problem.Path] {
+			t.Error("unexpected problem path:", problem.Path)
+		}
+	}
+
+	// Verify that skip mode doesn't modify scanned entries.
+	if translator.DecodeEntry(testDirectoryWithInvalidNames) != testDirectoryWithInvalidNames {
+		t.Error("skip mode modified scanned entry")
+	}
+}
+
+// TestNameTranslatorEscapeRoundTrip tests that content with names that can't be
+// represented on Windows can be created on disk in escaped form and that a scan
+// of that content yields the original names.
+func TestNameTranslatorEscapeRoundTrip(t *testing.T) {
+	// Translate a creation transition.
+	translator := &NameTranslator{escape: true}
+	transitions, problems := translator.EncodeTransitions([]*Change{{New: testDirectoryWithInvalidNames}})
+	if len(problems) != 0 {
+		t.Fatal("problems encountered translating representable names:", problems)
+	}
+	encoded := transitions[0].New
+	var verify func(string, *Entry)
+	verify = func(path string, entry *Entry) {
+		for name, child := range entry.Contents {
+			if nameInvalidOnWindows(name) {
+				t.Errorf("on-disk name %q in %q is invalid on Windows", name, path)
+			}
+			verify(pathJoin(path, name), child)
+		}
+	}
+	verify("", encoded)
+
+	// Compute the content map for the on-disk paths.
+	contentMap := make(map[string][]byte)
+	encoded.walk("", func(path string, entry *Entry) {
+		if entry.Kind == EntryKind_File {
+			if bytes.Equal(entry.Digest, testFile1ContentsSHA1) {
+				contentMap[path] = testFile1Contents
+			} else {
+				contentMap[path] = testFile3Contents
+			}
+		}
+	})
+
+	// Create the content on disk and defer its removal.
+	root, parent, err := testTransitionCreate("", encoded, contentMap, false)
+	if err != nil {
+		t.Fatal("unable to create escaped content:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Scan the content and verify that it decodes to the original entry.
+	snapshot, _, _, _, _, _, err := Scan(
+		context.Background(),
+		root,
+		nil, nil,
+		newTestHasher(), nil,
+		nil, nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to scan escaped content:", err)
+	} else if !translator.DecodeEntry(snapshot).Equal(testDirectoryWithInvalidNames) {
+		t.Error("scanned content does not round-trip to original names")
+	}
+
+	// Verify that problems reported at on-disk paths are translated.
+	decoded := translator.DecodeProblems([]*Problem{{Path: "dir\uf07cectory/nested\uf03c\uf03e"}})
+	if decoded[0].Path != "dir|ectory/nested<>" {
+		t.Error("problem path not decoded:", decoded[0].Path)
+	}
+}
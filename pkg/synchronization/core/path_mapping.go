@@ -0,0 +1,475 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// pathMappingRuleSeparator separates the source and destination portions
+	// of a path mapping rule specification.
+	pathMappingRuleSeparator = "=>"
+	// pathMappingRegexPrefix is the prefix that identifies path mapping rules
+	// whose source is a regular expression.
+	pathMappingRegexPrefix = "regex:"
+)
+
+// pathMappingRule is a single path mapping rule.
+type pathMappingRule struct {
+	// source is the source path prefix for prefix rules.
+	source string
+	// pattern is the source pattern for regular expression rules. If nil, then
+	// the rule is a prefix rule.
+	pattern *regexp.Regexp
+	// destination is the destination path prefix for prefix rules or the
+	// replacement template for regular expression rules.
+	destination string
+}
+
+// validPathMappingPrefix determines whether or not a prefix used in a prefix
+// path mapping rule is valid, i.e. whether it's either empty (indicating the
+// synchronization root) or a clean root-relative path.
+func validPathMappingPrefix(prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	for _, component := range strings.Split(prefix, "/") {
+		if component == "" || component == "." || component == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePathMappingRule parses a path mapping rule specification.
+func parsePathMappingRule(specification string) (*pathMappingRule, error) {
+	// Split the specification into its source and destination.
+	separator := strings.Index(specification, pathMappingRuleSeparator)
+	if separator == -1 {
+		return nil, errors.Errorf("rule missing '%s' separator", pathMappingRuleSeparator)
+	}
+	source := specification[:separator]
+	destination := specification[separator+len(pathMappingRuleSeparator):]
+
+	// Handle regular expression rules.
+	if strings.HasPrefix(source, pathMappingRegexPrefix) {
+		pattern, err := regexp.Compile(strings.TrimPrefix(source, pathMappingRegexPrefix))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid regular expression")
+		}
+		return &pathMappingRule{pattern: pattern, destination: destination}, nil
+	}
+
+	// Handle prefix rules.
+	if !validPathMappingPrefix(source) {
+		return nil, errors.Errorf("invalid source prefix: %s", source)
+	} else if !validPathMappingPrefix(destination) {
+		return nil, errors.Errorf("invalid destination prefix: %s", destination)
+	} else if source == destination {
+		return nil, errors.New("source and destination prefixes are identical")
+	}
+	return &pathMappingRule{source: source, destination: destination}, nil
+}
+
+// trimPathPrefix determines whether or not a path lies at or within the
+// specified prefix and, if so, returns the remainder of the path.
+func trimPathPrefix(path, prefix string) (string, bool) {
+	if prefix == "" {
+		return path, true
+	} else if path == prefix {
+		return "", true
+	} else if pathWithin(prefix, path) {
+		return path[len(prefix)+1:], true
+	}
+	return "", false
+}
+
+// joinPathPrefix joins a path remainder to a prefix.
+func joinPathPrefix(prefix, remainder string) string {
+	if remainder == "" {
+		return prefix
+	}
+	return pathJoin(prefix, remainder)
+}
+
+// apply applies the rule to a path. It returns false if the rule doesn't match.
+func (r *pathMappingRule) apply(path string) (string, bool) {
+	if r.pattern != nil {
+		if !r.pattern.MatchString(path) {
+			return "", false
+		}
+		return r.pattern.ReplaceAllString(path, r.destination), true
+	}
+	if remainder, ok := trimPathPrefix(path, r.source); ok {
+		return joinPathPrefix(r.destination, remainder), true
+	}
+	return "", false
+}
+
+// PathMapping rewrites the paths of content placed on an endpoint, allowing
+// content to be stored in a differently structured hierarchy (e.g. with paths
+// prefixed or directories flattened). Mappings are specified as a list of rules
+// of the form "source=>destination", where source and destination are path
+// prefixes (with an empty prefix indicating the synchronization root), or of
+// the form "regex:pattern=>replacement", where pattern is a regular expression
+// matched against full paths and replacement is an expansion template (e.g.
+// using "$1" to reference submatches). The first matching rule is applied to
+// each path, and paths not matched by any rule are left unmodified.
+//
+// Mappings are applied to the leaf content of snapshots (i.e. files, symbolic
+// links, and empty directories), with intermediate directories created as
+// necessary. Directory metadata is therefore not propagated for directories
+// with contents. Content whose mapped path collides with other mapped content
+// is reported as a problem and skipped.
+//
+// Since regular expression rules can't be reversed in general, mappings that
+// contain them must be declared one-way, in which case on-disk content can
+// only be mapped back to synchronization paths if it corresponds to known
+// content (i.e. content that was previously mapped).
+type PathMapping struct {
+	// rules are the mapping rules.
+	rules []*pathMappingRule
+	// oneWay indicates whether or not the mapping is one-way.
+	oneWay bool
+}
+
+// NewPathMapping creates a new path mapping from the specified rules. It
+// returns nil if no rules are specified. If any of the rules use regular
+// expressions, then the mapping must be declared one-way.
+func NewPathMapping(rules []string, oneWay bool) (*PathMapping, error) {
+	// If there are no rules, then no mapping is necessary.
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	// Parse rules.
+	mapping := &PathMapping{oneWay: oneWay}
+	for _, specification := range rules {
+		rule, err := parsePathMappingRule(specification)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid path mapping rule (%s)", specification)
+		} else if rule.pattern != nil && !oneWay {
+			return nil, errors.Errorf("regular expression rule (%s) requires a one-way mapping", specification)
+		}
+		mapping.rules = append(mapping.rules, rule)
+	}
+
+	// Success.
+	return mapping, nil
+}
+
+// MapPath computes the mapped path for the specified synchronization path. It
+// returns false if the mapped path isn't a valid non-root path.
+func (m *PathMapping) MapPath(path string) (string, bool) {
+	// Apply the first matching rule.
+	mapped := path
+	for _, rule := range m.rules {
+		if result, ok := rule.apply(path); ok {
+			mapped = result
+			break
+		}
+	}
+
+	// Validate the result.
+	if mapped == "" || !validPathMappingPrefix(mapped) {
+		return "", false
+	}
+	return mapped, true
+}
+
+// unmapPath computes the synchronization path for a mapped path without the
+// benefit of known content. It's only supported for mappings that aren't
+// one-way. It returns false if no synchronization path maps to the path.
+func (m *PathMapping) unmapPath(mapped string) (string, bool) {
+	// One-way mappings can't be reversed.
+	if m.oneWay {
+		return "", false
+	}
+
+	// Check whether or not the path maps to itself.
+	if result, ok := m.MapPath(mapped); ok && result == mapped {
+		return mapped, true
+	}
+
+	// Otherwise check for rules whose reversal yields a path that maps back to
+	// the mapped path.
+	for _, rule := range m.rules {
+		if remainder, ok := trimPathPrefix(mapped, rule.destination); ok {
+			candidate := joinPathPrefix(rule.source, remainder)
+			if candidate == "" {
+				continue
+			}
+			if result, ok := m.MapPath(candidate); ok && result == mapped {
+				return candidate, true
+			}
+		}
+	}
+
+	// No path was found.
+	return "", false
+}
+
+// PathMappingResult is the result of applying (or reversing) a path mapping.
+type PathMappingResult struct {
+	// Snapshot is the resulting snapshot.
+	Snapshot *Entry
+	// Paths maps the paths of leaf content in the resulting snapshot to their
+	// paths in the original snapshot.
+	Paths map[string]string
+	// Unmapped are the paths of leaf content in the original snapshot that
+	// couldn't be placed in the resulting snapshot.
+	Unmapped []string
+	// Problems are the problems encountered while placing content.
+	Problems []*Problem
+}
+
+// leafEntries computes the leaf entries (files, symbolic links, and empty
+// directories) of a directory snapshot, returning their paths in sorted order.
+func leafEntries(snapshot *Entry) ([]string, map[string]*Entry) {
+	leaves := make(map[string]*Entry)
+	snapshot.walk("", func(path string, entry *Entry) {
+		if path != "" && entry != nil && (entry.Kind != EntryKind_Directory || len(entry.Contents) == 0) {
+			leaves[path] = entry
+		}
+	})
+	paths := make([]string, 0, len(leaves))
+	for path := range leaves {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, leaves
+}
+
+// entryAtPath returns the entry at the specified path within a snapshot, if
+// any.
+func entryAtPath(snapshot *Entry, path string) *Entry {
+	if path == "" {
+		return snapshot
+	}
+	entry := snapshot
+	for _, component := range strings.Split(path, "/") {
+		if entry == nil {
+			return nil
+		}
+		entry = entry.Contents[component]
+	}
+	return entry
+}
+
+// mappingBuilder constructs mapped snapshots.
+type mappingBuilder struct {
+	// root is the root of the snapshot being constructed.
+	root *Entry
+	// template is an existing snapshot from which the metadata for
+	// intermediate directories is copied (if present at the same path).
+	template *Entry
+	// origins maps placed paths to their origin paths.
+	origins map[string]string
+}
+
+// place places the leaf entry originating at the specified path at the
+// specified target path. If the placement collides with existing content, then
+// it returns the origin path of that content and false.
+func (b *mappingBuilder) place(target string, entry *Entry, origin string) (string, bool) {
+	// Walk down to the parent directory, creating directories as necessary.
+	parent := b.root
+	components := strings.Split(target, "/")
+	var path string
+	for _, component := range components[:len(components)-1] {
+		path = pathJoin(path, component)
+		child := parent.Contents[component]
+		if child == nil {
+			if existing := entryAtPath(b.template, path); existing != nil && existing.Kind == EntryKind_Directory {
+				child = existing.copySlim()
+			} else {
+				child = &Entry{Kind: EntryKind_Directory}
+			}
+			child.Contents = make(map[string]*Entry)
+			if parent.Contents == nil {
+				parent.Contents = make(map[string]*Entry)
+			}
+			parent.Contents[component] = child
+		} else if child.Kind != EntryKind_Directory {
+			return b.origins[path], false
+		}
+		parent = child
+	}
+
+	// Place the entry, allowing empty directories to coincide with existing
+	// directories.
+	name := components[len(components)-1]
+	if existing := parent.Contents[name]; existing != nil {
+		if existing.Kind == EntryKind_Directory && entry.Kind == EntryKind_Directory {
+			return "", true
+		} else if origin, ok := b.origins[target]; ok {
+			return origin, false
+		}
+		return b.firstOriginWithin(target), false
+	}
+	if entry.Kind == EntryKind_Directory {
+		entry = entry.copySlim()
+		entry.Contents = make(map[string]*Entry)
+	}
+	if parent.Contents == nil {
+		parent.Contents = make(map[string]*Entry)
+	}
+	parent.Contents[name] = entry
+	b.origins[target] = origin
+	return "", true
+}
+
+// firstOriginWithin returns the first origin path (in sorted order) of content
+// placed within the specified path.
+func (b *mappingBuilder) firstOriginWithin(path string) string {
+	var result string
+	for placed, origin := range b.origins {
+		if pathWithin(path, placed) && (result == "" || origin < result) {
+			result = origin
+		}
+	}
+	return result
+}
+
+// collisionProblem creates a problem describing content that couldn't be
+// placed due to a collision.
+func collisionProblem(path, target, other string) *Problem {
+	return &Problem{
+		Path:  path,
+		Error: fmt.Sprintf("content skipped: mapped path (%s) collides with content from %s", target, other),
+	}
+}
+
+// Map applies the mapping to a snapshot, computing the snapshot that should
+// exist on disk. The metadata of intermediate directories is taken from the
+// specified base snapshot (typically the existing on-disk snapshot), if
+// present. The leaf content at the specified preserved paths within the base
+// snapshot (typically content that couldn't be mapped back to synchronization
+// paths) is retained in the result. If the snapshot's root isn't a directory,
+// then it's returned unmodified.
+func (m *PathMapping) Map(snapshot, base *Entry, preserved []string) *PathMappingResult {
+	// If the root isn't a directory, then no mapping is possible.
+	if snapshot == nil || snapshot.Kind != EntryKind_Directory {
+		return &PathMappingResult{Snapshot: snapshot, Paths: map[string]string{"": ""}}
+	}
+
+	// Create the builder, taking the root's metadata from the base if
+	// possible.
+	root := snapshot.copySlim()
+	if base != nil && base.Kind == EntryKind_Directory {
+		root = base.copySlim()
+	}
+	root.Contents = make(map[string]*Entry)
+	builder := &mappingBuilder{
+		root:     root,
+		template: base,
+		origins:  make(map[string]string),
+	}
+	result := &PathMappingResult{Snapshot: root}
+
+	// Place preserved content.
+	for _, path := range preserved {
+		if entry := entryAtPath(base, path); entry != nil {
+			builder.place(path, entry, "")
+		}
+	}
+
+	// Place mapped content.
+	paths, leaves := leafEntries(snapshot)
+	for _, path := range paths {
+		if target, ok := m.MapPath(path); !ok {
+			result.Unmapped = append(result.Unmapped, path)
+			result.Problems = append(result.Problems, &Problem{
+				Path:  path,
+				Error: "content skipped: mapped path is invalid",
+			})
+		} else if other, ok := builder.place(target, leaves[path], path); !ok {
+			result.Unmapped = append(result.Unmapped, path)
+			if other == "" {
+				other = "unmapped content"
+			}
+			result.Problems = append(result.Problems, collisionProblem(path, target, other))
+		}
+	}
+
+	// Record mapped paths.
+	result.Paths = make(map[string]string, len(builder.origins))
+	for target, origin := range builder.origins {
+		if origin != "" {
+			result.Paths[target] = origin
+		}
+	}
+
+	// Done.
+	return result
+}
+
+// Unmap reverses the mapping for an on-disk snapshot, computing the
+// corresponding snapshot of synchronization paths. Content is mapped back using
+// the specified known snapshots (typically the opposite endpoint's snapshot and
+// the ancestor), with the first (in sorted order) of any colliding known paths
+// taking precedence, consistent with Map. Otherwise, for mappings that aren't
+// one-way, the rules are reversed. Content that can't be mapped back is
+// reported in the result's Unmapped paths and, for mappings that aren't
+// one-way, as a problem.
+func (m *PathMapping) Unmap(mapped *Entry, known ...*Entry) *PathMappingResult {
+	// If the root isn't a directory, then no mapping is possible.
+	if mapped == nil || mapped.Kind != EntryKind_Directory {
+		return &PathMappingResult{Snapshot: mapped, Paths: map[string]string{"": ""}}
+	}
+
+	// Compute the reverse mapping for known content.
+	reverse := make(map[string]string)
+	for _, snapshot := range known {
+		if snapshot == nil || snapshot.Kind != EntryKind_Directory {
+			continue
+		}
+		paths, _ := leafEntries(snapshot)
+		for _, path := range paths {
+			if target, ok := m.MapPath(path); ok {
+				if existing, ok := reverse[target]; !ok || path < existing {
+					reverse[target] = path
+				}
+			}
+		}
+	}
+
+	// Create the builder.
+	root := mapped.copySlim()
+	root.Contents = make(map[string]*Entry)
+	builder := &mappingBuilder{
+		root:    root,
+		origins: make(map[string]string),
+	}
+	result := &PathMappingResult{Snapshot: root}
+
+	// Place content.
+	paths, leaves := leafEntries(mapped)
+	for _, path := range paths {
+		target, ok := reverse[path]
+		if !ok {
+			target, ok = m.unmapPath(path)
+		}
+		if !ok {
+			result.Unmapped = append(result.Unmapped, path)
+			if !m.oneWay {
+				result.Problems = append(result.Problems, &Problem{
+					Path:  path,
+					Error: "content skipped: path doesn't correspond to a mapped path",
+				})
+			}
+		} else if other, ok := builder.place(target, leaves[path], path); !ok {
+			result.Unmapped = append(result.Unmapped, path)
+			result.Problems = append(result.Problems, collisionProblem(path, target, other))
+		}
+	}
+
+	// Record mapped paths.
+	result.Paths = builder.origins
+
+	// Done.
+	return result
+}
@@ -0,0 +1,203 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestNewPathMappingInvalid tests that NewPathMapping rejects invalid rules.
+func TestNewPathMappingInvalid(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		rules  []string
+		oneWay bool
+	}{
+		{[]string{"source"}, false},
+		{[]string{"/source=>destination"}, false},
+		{[]string{"source=>destination/"}, false},
+		{[]string{"source/../other=>destination"}, false},
+		{[]string{"source=>source"}, false},
+		{[]string{"regex:(=>destination"}, true},
+		{[]string{"regex:^source$=>destination"}, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if _, err := NewPathMapping(testCase.rules, testCase.oneWay); err == nil {
+			t.Errorf("invalid path mapping rules accepted: %v", testCase.rules)
+		}
+	}
+
+	// Verify that no mapping is created if no rules are specified.
+	if mapping, err := NewPathMapping(nil, false); err != nil {
+		t.Error("unable to create empty path mapping:", err)
+	} else if mapping != nil {
+		t.Error("path mapping created without rules")
+	}
+}
+
+// TestPathMappingMapPath tests PathMapping.MapPath with prefix and regular
+// expression rules.
+func TestPathMappingMapPath(t *testing.T) {
+	// Create a mapping.
+	mapping, err := NewPathMapping([]string{
+		"src/lib=>lib",
+		"regex:^docs/(.*)/([^/]+)\\.md$=>manual/$1-$2.md",
+		"regex:^invalid/(.*)$=>/$1",
+		"=>mirror",
+	}, true)
+	if err != nil {
+		t.Fatal("unable to create path mapping:", err)
+	}
+
+	// Set up test cases.
+	testCases := []struct {
+		path     string
+		expected string
+		valid    bool
+	}{
+		{"src/lib", "lib", true},
+		{"src/lib/file", "lib/file", true},
+		{"src/library", "mirror/src/library", true},
+		{"docs/guide/intro.md", "manual/guide-intro.md", true},
+		{"docs/guide/image.png", "mirror/docs/guide/image.png", true},
+		{"invalid/file", "", false},
+		{"file", "mirror/file", true},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if mapped, ok := mapping.MapPath(testCase.path); ok != testCase.valid {
+			t.Errorf("unexpected validity for mapped path of %s: %t", testCase.path, ok)
+		} else if mapped != testCase.expected {
+			t.Errorf("mapped path of %s (%s) does not match expected (%s)", testCase.path, mapped, testCase.expected)
+		}
+	}
+}
+
+// testPathMappingSnapshot creates a directory snapshot with files at the
+// specified paths.
+func testPathMappingSnapshot(t *testing.T, paths ...string) *Entry {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create the snapshot.
+	root := &Entry{Kind: EntryKind_Directory, Contents: make(map[string]*Entry)}
+	for _, path := range paths {
+		parent := root
+		components := strings.Split(path, "/")
+		for _, component := range components[:len(components)-1] {
+			child := parent.Contents[component]
+			if child == nil {
+				child = &Entry{Kind: EntryKind_Directory, Contents: make(map[string]*Entry)}
+				parent.Contents[component] = child
+			}
+			parent = child
+		}
+		parent.Contents[components[len(components)-1]] = &Entry{
+			Kind:   EntryKind_File,
+			Digest: []byte(path),
+		}
+	}
+	if err := root.EnsureValid(); err != nil {
+		t.Fatal("invalid test snapshot:", err)
+	}
+	return root
+}
+
+// TestPathMappingPrefixRoundTrip tests that prefix mappings place content
+// correctly and that on-disk content is mapped back to its original paths.
+func TestPathMappingPrefixRoundTrip(t *testing.T) {
+	// Create a mapping.
+	mapping, err := NewPathMapping([]string{"src/lib=>lib", "=>mirror"}, false)
+	if err != nil {
+		t.Fatal("unable to create path mapping:", err)
+	}
+
+	// Map a snapshot and verify placement.
+	snapshot := testPathMappingSnapshot(t, "src/lib/a", "src/lib/b/c", "src/main", "README")
+	result := mapping.Map(snapshot, nil, nil)
+	if len(result.Problems) != 0 {
+		t.Fatal("unexpected problems:", result.Problems[0].Path, result.Problems[0].Error)
+	}
+	expected := map[string]string{
+		"lib/a":           "src/lib/a",
+		"lib/b/c":         "src/lib/b/c",
+		"mirror/src/main": "src/main",
+		"mirror/README":   "README",
+	}
+	for path, origin := range expected {
+		if placed := entryAtPath(result.Snapshot, path); placed == nil {
+			t.Errorf("content missing at mapped path: %s", path)
+		} else if !placed.Equal(entryAtPath(snapshot, origin)) {
+			t.Errorf("content at mapped path (%s) incorrect", path)
+		}
+	}
+	if result.Paths["lib/b/c"] != "src/lib/b/c" {
+		t.Error("mapped path not recorded")
+	}
+
+	// Add unmapped content to the on-disk snapshot and verify that it's
+	// reported when mapping back without the benefit of known content.
+	onDisk := result.Snapshot.Copy()
+	onDisk.Contents["stray"] = &Entry{Kind: EntryKind_File, Digest: []byte("stray")}
+	unmapped := mapping.Unmap(onDisk)
+	if !unmapped.Snapshot.Equal(snapshot) {
+		t.Error("unmapped snapshot does not match original")
+	}
+	if len(unmapped.Unmapped) != 1 || unmapped.Unmapped[0] != "stray" {
+		t.Error("unexpected unmapped content:", unmapped.Unmapped)
+	} else if len(unmapped.Problems) != 1 || unmapped.Problems[0].Path != "stray" {
+		t.Error("unmapped content not reported")
+	}
+
+	// Verify that unmapped content is preserved when mapping.
+	preserved := mapping.Map(snapshot, onDisk, unmapped.Unmapped)
+	if !preserved.Snapshot.Equal(onDisk) {
+		t.Error("unmapped content not preserved")
+	}
+}
+
+// TestPathMappingRegexCollisions tests that one-way regular expression
+// mappings report collisions and only map back known content.
+func TestPathMappingRegexCollisions(t *testing.T) {
+	// Create a mapping that flattens directories.
+	mapping, err := NewPathMapping([]string{"regex:^.*/([^/]+)$=>flat/$1"}, true)
+	if err != nil {
+		t.Fatal("unable to create path mapping:", err)
+	}
+
+	// Map a snapshot with colliding names and a name that collides with an
+	// intermediate directory.
+	snapshot := testPathMappingSnapshot(t, "a/file", "b/file", "c/other", "flat")
+	result := mapping.Map(snapshot, nil, nil)
+	if entry := entryAtPath(result.Snapshot, "flat/file"); entry == nil || string(entry.Digest) != "a/file" {
+		t.Error("first colliding content not placed")
+	} else if entry = entryAtPath(result.Snapshot, "flat/other"); entry == nil {
+		t.Error("non-colliding content not placed")
+	}
+	if len(result.Problems) != 2 {
+		t.Fatal("unexpected number of collision problems:", len(result.Problems))
+	}
+	if result.Problems[0].Path != "b/file" || !strings.Contains(result.Problems[0].Error, "collides with content from a/file") {
+		t.Error("unexpected collision problem:", result.Problems[0].Path, result.Problems[0].Error)
+	}
+	if result.Problems[1].Path != "flat" || !strings.Contains(result.Problems[1].Error, "collides") {
+		t.Error("unexpected collision problem:", result.Problems[1].Path, result.Problems[1].Error)
+	}
+
+	// Verify that on-disk content is only mapped back if it's known, with
+	// colliding content mapped back to the path that was placed.
+	onDisk := result.Snapshot.Copy()
+	onDisk.Contents["flat"].Contents["stray"] = &Entry{Kind: EntryKind_File, Digest: []byte("stray")}
+	unmapped := mapping.Unmap(onDisk, snapshot)
+	expected := testPathMappingSnapshot(t, "a/file", "c/other")
+	if !unmapped.Snapshot.Equal(expected) {
+		t.Error("unmapped snapshot does not match expected")
+	}
+	if len(unmapped.Unmapped) != 1 || unmapped.Unmapped[0] != "flat/stray" {
+		t.Error("unexpected unmapped content:", unmapped.Unmapped)
+	} else if len(unmapped.Problems) != 0 {
+		t.Error("unmapped content reported for one-way mapping")
+	}
+}
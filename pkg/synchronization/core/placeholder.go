@@ -0,0 +1,161 @@
+package core
+
+import (
+	"bytes"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+)
+
+const (
+	// materializationTemporaryNamePrefix is the file name prefix to use for
+	// intermediate temporary files used when materializing placeholders.
+	materializationTemporaryNamePrefix = filesystem.TemporaryNamePrefix + "materialize"
+)
+
+// Fetcher is the interface for callbacks that provide content when
+// materializing placeholders. It should return a stream containing the content
+// with the specified digest for the specified path. The stream will be closed
+// by the caller.
+type Fetcher func(path string, digest []byte) (io.ReadCloser, error)
+
+// Materializer materializes placeholder files (created by Transition when
+// placeholder creation is enabled) within a synchronization root on demand,
+// replacing them with their content. It is safe for concurrent usage.
+type Materializer struct {
+	// root is the path to the synchronization root.
+	root string
+	// fetch is the callback used to fetch content.
+	fetch Fetcher
+	// lock serializes materialization operations and access to hasher.
+	lock sync.Mutex
+	// hasher is the hashing function used to verify fetched content.
+	hasher hash.Hash
+}
+
+// NewMaterializer creates a new materializer for the specified synchronization
+// root, using the specified hasher to verify content provided by the specified
+// fetch callback.
+func NewMaterializer(root string, hasher hash.Hash, fetch Fetcher) *Materializer {
+	return &Materializer{
+		root:   root,
+		fetch:  fetch,
+		hasher: hasher,
+	}
+}
+
+// Materialize ensures that the file at the specified path within the
+// synchronization root has its content, replacing it with fetched content if
+// it's a placeholder. It returns whether or not materialization was performed.
+// The fetched content is verified against the placeholder's digest before
+// being moved into place, and the placeholder is left intact on failure.
+func (m *Materializer) Materialize(path string) (bool, error) {
+	// Lock the materializer and defer its release.
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// Compute the filesystem path.
+	target := filepath.Join(m.root, filepath.FromSlash(path))
+
+	// Grab metadata for the file and check whether or not it's a placeholder.
+	// Placeholders are always empty, so we only need to check for a marker if
+	// the file is empty.
+	metadata, err := os.Lstat(target)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to grab file metadata")
+	} else if !metadata.Mode().IsRegular() {
+		return false, errors.New("path does not reference a file")
+	} else if metadata.Size() != 0 {
+		return false, nil
+	}
+	digest, err := filesystem.ReadPlaceholder(target)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to read placeholder marker")
+	} else if digest == nil {
+		return false, nil
+	}
+
+	// Fetch the content and defer closure of the stream.
+	content, err := m.fetch(path, digest)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to fetch content")
+	}
+	defer content.Close()
+
+	// Create a temporary file alongside the placeholder. We can't defer its
+	// closure because we'll want to rename it or remove it on failure, which we
+	// can't do (on some platforms) if the file handle is open.
+	temporary, err := ioutil.TempFile(filepath.Dir(target), materializationTemporaryNamePrefix)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to create temporary file")
+	}
+
+	// Copy the content into the temporary file, computing its digest as we go,
+	// and then close the file.
+	m.hasher.Reset()
+	_, err = io.Copy(io.MultiWriter(temporary, m.hasher), content)
+	temporary.Close()
+	if err != nil {
+		os.Remove(temporary.Name())
+		return false, errors.Wrap(err, "unable to write content")
+	}
+
+	// Verify the content digest.
+	if !bytes.Equal(m.hasher.Sum(nil), digest) {
+		os.Remove(temporary.Name())
+		return false, errors.New("fetched content does not match placeholder digest")
+	}
+
+	// Set permissions on the temporary file to match the placeholder.
+	//
+	// TODO: We should also propagate ownership and ACLs from the placeholder,
+	// though in most cases materialization will be performed by the user who
+	// owns the synchronization root and will inherit the correct ownership.
+	if err := os.Chmod(temporary.Name(), metadata.Mode().Perm()); err != nil {
+		os.Remove(temporary.Name())
+		return false, errors.Wrap(err, "unable to set file permissions")
+	}
+
+	// Verify that the placeholder hasn't been modified or replaced while we
+	// were fetching content.
+	//
+	// RACE: There is a race condition here between this check and the rename
+	// that we have to live with due to limitations in filesystem APIs. The
+	// worst case fallout is replacement of contents that are modified during
+	// this window.
+	if current, err := os.Lstat(target); err != nil {
+		os.Remove(temporary.Name())
+		return false, errors.Wrap(err, "unable to grab file metadata")
+	} else if !os.SameFile(metadata, current) || current.Size() != 0 || !current.ModTime().Equal(metadata.ModTime()) {
+		os.Remove(temporary.Name())
+		return false, errors.New("placeholder modified during materialization")
+	}
+
+	// Move the content into place.
+	if err := os.Rename(temporary.Name(), target); err != nil {
+		os.Remove(temporary.Name())
+		return false, errors.Wrap(err, "unable to relocate materialized file")
+	}
+
+	// Success.
+	return true, nil
+}
+
+// Open opens the file at the specified path within the synchronization root for
+// reading, materializing it first if it's a placeholder.
+func (m *Materializer) Open(path string) (*os.File, error) {
+	// Ensure that the file is materialized.
+	if _, err := m.Materialize(path); err != nil {
+		return nil, errors.Wrap(err, "unable to materialize file")
+	}
+
+	// Open the file.
+	return os.Open(filepath.Join(m.root, filepath.FromSlash(path)))
+}
@@ -0,0 +1,230 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+// testPlaceholderContent is the content used in placeholder tests.
+var testPlaceholderContent = map[string][]byte{
+	"file":             []byte("file content"),
+	"directory/file":   []byte("nested file content"),
+	"directory/script": []byte("#!/bin/sh\necho script\n"),
+}
+
+// testPlaceholdersSupported skips the calling test if placeholders aren't
+// supported in the specified directory.
+func testPlaceholdersSupported(t *testing.T, directory string) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create a probe file and attempt to mark it.
+	probe := filepath.Join(directory, "probe")
+	if err := ioutil.WriteFile(probe, nil, 0600); err != nil {
+		t.Fatal("unable to create placeholder probe file:", err)
+	}
+	defer os.Remove(probe)
+	if err := filesystem.MarkPlaceholder(probe, []byte{0}); err == filesystem.ErrPlaceholdersUnsupported {
+		t.Skip("placeholders not supported by temporary directory filesystem")
+	} else if err != nil {
+		t.Fatal("unable to mark placeholder probe file:", err)
+	}
+}
+
+// testPlaceholderScan performs a scan of the specified root for placeholder
+// tests.
+func testPlaceholderScan(t *testing.T, root string) *Entry {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Perform the scan.
+	snapshot, _, _, _, _, _, err := Scan(
+		context.Background(),
+		root,
+		nil, nil,
+		newTestHasher(), nil,
+		nil, nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	}
+	return snapshot
+}
+
+// testPlaceholderFetcher returns a fetch callback that provides content from
+// the specified content map, counting the number of fetches performed.
+func testPlaceholderFetcher(contentMap map[string][]byte, fetches *int) Fetcher {
+	return func(path string, _ []byte) (io.ReadCloser, error) {
+		*fetches++
+		content, ok := contentMap[path]
+		if !ok {
+			return nil, errors.New("unable to find content for path")
+		}
+		return ioutil.NopCloser(bytes.NewReader(content)), nil
+	}
+}
+
+// TestPlaceholderCreationAndMaterialization tests that Transition creates
+// placeholders (without using the provider) when placeholder creation is
+// enabled, that placeholders are scanned as if they had their full content, and
+// that they're materialized on demand by Materializer.
+func TestPlaceholderCreationAndMaterialization(t *testing.T) {
+	// Create a temporary directory to hold all test content and defer its
+	// removal.
+	parent, err := ioutil.TempDir("", "mutagen_placeholder")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Ensure that placeholders are supported.
+	testPlaceholdersSupported(t, parent)
+
+	// Create source content and scan it.
+	source := filepath.Join(parent, "source")
+	if err := os.MkdirAll(filepath.Join(source, "directory"), 0700); err != nil {
+		t.Fatal("unable to create source directories:", err)
+	}
+	for path, content := range testPlaceholderContent {
+		mode := os.FileMode(0600)
+		if path == "directory/script" {
+			mode = 0700
+		}
+		if err := ioutil.WriteFile(filepath.Join(source, filepath.FromSlash(path)), content, mode); err != nil {
+			t.Fatal("unable to create source file:", err)
+		}
+	}
+	snapshot := testPlaceholderScan(t, source)
+
+	// Create a provider without any content, which will cause failures if it's
+	// used, and defer its cleanup.
+	provider, err := newTestProvider(nil, newTestHasher())
+	if err != nil {
+		t.Fatal("unable to create test provider:", err)
+	}
+	defer provider.finalize()
+
+	// Transition the snapshot to a new location using placeholders.
+	target := filepath.Join(parent, "target")
+	if _, problems, providerMissingFiles := Transition(
+		context.Background(),
+		target,
+		[]*Change{{New: snapshot}},
+		nil,
+		SymlinkMode_SymlinkModePortable,
+		defaultFilePermissionMode,
+		defaultDirectoryPermissionMode,
+		nil,
+		false,
+		provider,
+		&TransitionOptions{
+			DurabilityMode:     DurabilityMode_DurabilityModeNone,
+			Syncer:             filesystem.SystemSyncer,
+			ACLMode:            ACLMode_ACLModeIgnore,
+			CreatePlaceholders: true,
+		},
+	); len(problems) != 0 {
+		t.Fatal("problems occurred during transition:", problems[0].Error)
+	} else if providerMissingFiles {
+		t.Fatal("provider missing files during transition")
+	}
+
+	// Verify that placeholders were created.
+	for path := range testPlaceholderContent {
+		targetPath := filepath.Join(target, filepath.FromSlash(path))
+		if metadata, err := os.Lstat(targetPath); err != nil {
+			t.Fatal("unable to grab placeholder metadata:", err)
+		} else if metadata.Size() != 0 {
+			t.Error("placeholder has non-zero size:", path)
+		}
+		if digest, err := filesystem.ReadPlaceholder(targetPath); err != nil {
+			t.Fatal("unable to read placeholder marker:", err)
+		} else if digest == nil {
+			t.Error("placeholder not marked:", path)
+		}
+	}
+
+	// Verify that a scan of the placeholders matches the source.
+	if !testPlaceholderScan(t, target).Equal(snapshot) {
+		t.Error("placeholder snapshot does not match source snapshot")
+	}
+
+	// Verify that materialization fails (and leaves the placeholder intact)
+	// if fetched content doesn't match the placeholder digest.
+	var fetches int
+	corrupt := NewMaterializer(target, newTestHasher(), testPlaceholderFetcher(
+		map[string][]byte{"file": []byte("corrupted content")}, &fetches,
+	))
+	if _, err := corrupt.Materialize("file"); err == nil {
+		t.Error("materialization succeeded with mismatched content")
+	} else if digest, err := filesystem.ReadPlaceholder(filepath.Join(target, "file")); err != nil {
+		t.Fatal("unable to read placeholder marker:", err)
+	} else if digest == nil {
+		t.Error("placeholder not intact after failed materialization")
+	}
+
+	// Create a materializer.
+	fetches = 0
+	materializer := NewMaterializer(target, newTestHasher(), testPlaceholderFetcher(testPlaceholderContent, &fetches))
+
+	// Open a file via the materializer and verify its content.
+	if file, err := materializer.Open("directory/file"); err != nil {
+		t.Fatal("unable to open file via materializer:", err)
+	} else {
+		content, err := ioutil.ReadAll(file)
+		file.Close()
+		if err != nil {
+			t.Fatal("unable to read materialized file:", err)
+		} else if !bytes.Equal(content, testPlaceholderContent["directory/file"]) {
+			t.Error("materialized file content incorrect")
+		}
+	}
+	if fetches != 1 {
+		t.Error("unexpected number of fetches:", fetches, "!= 1")
+	}
+
+	// Verify that re-materialization is a no-op.
+	if materialized, err := materializer.Materialize("directory/file"); err != nil {
+		t.Fatal("unable to re-materialize file:", err)
+	} else if materialized {
+		t.Error("materialized file materialized again")
+	} else if fetches != 1 {
+		t.Error("content fetched for materialized file")
+	}
+
+	// Materialize the remaining files and verify that executability was
+	// preserved.
+	for _, path := range []string{"file", "directory/script"} {
+		if materialized, err := materializer.Materialize(path); err != nil {
+			t.Fatal("unable to materialize file:", err)
+		} else if !materialized {
+			t.Error("placeholder not materialized:", path)
+		}
+	}
+	if metadata, err := os.Lstat(filepath.Join(target, "directory", "script")); err != nil {
+		t.Fatal("unable to grab materialized file metadata:", err)
+	} else if metadata.Mode()&0100 == 0 {
+		t.Error("materialized file executability not preserved")
+	}
+
+	// Verify that a scan of the materialized content matches the source.
+	if !testPlaceholderScan(t, target).Equal(snapshot) {
+		t.Error("materialized snapshot does not match source snapshot")
+	}
+}
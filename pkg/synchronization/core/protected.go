@@ -0,0 +1,107 @@
+package core
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/bmatcuk/doublestar"
+)
+
+var (
+	// errProtectedDeletion is the error recorded when a transition would
+	// delete protected content.
+	errProtectedDeletion = errors.New("refusing to delete protected path")
+	// errProtectedOverwrite is the error recorded when a transition would
+	// overwrite protected content.
+	errProtectedOverwrite = errors.New("refusing to overwrite protected path")
+)
+
+// newProtectedPathPattern validates a user-provided protected path pattern.
+func newProtectedPathPattern(pattern string) error {
+	// Check for invalid patterns.
+	if pattern == "" {
+		return errors.New("empty pattern")
+	}
+
+	// Attempt to do a match with the pattern to ensure validity. We have to
+	// match against a non-empty path (we choose something simple), otherwise
+	// bad pattern errors won't be detected.
+	if _, err := doublestar.Match(pattern, "a"); err != nil {
+		return errors.Wrap(err, "unable to validate pattern")
+	}
+
+	// Success.
+	return nil
+}
+
+// ValidProtectedPathPattern checks whether or not a given pattern is a valid
+// protected path specification.
+func ValidProtectedPathPattern(pattern string) bool {
+	return newProtectedPathPattern(pattern) == nil
+}
+
+// ProtectedPathMatcher identifies protected paths. Patterns are matched against
+// full root-relative paths, and paths are protected if they or any of their
+// parent paths match a pattern. A nil matcher is valid and protects no paths.
+type ProtectedPathMatcher struct {
+	// patterns are the protected path patterns.
+	patterns []string
+}
+
+// NewProtectedPathMatcher creates a new protected path matcher from the
+// specified patterns. If no patterns are specified, then it returns a nil
+// matcher.
+func NewProtectedPathMatcher(patterns []string) (*ProtectedPathMatcher, error) {
+	// If there are no patterns, then there's no need for a matcher.
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	// Validate patterns.
+	for _, pattern := range patterns {
+		if err := newProtectedPathPattern(pattern); err != nil {
+			return nil, errors.Wrapf(err, "invalid protected path pattern: %s", pattern)
+		}
+	}
+
+	// Success.
+	return &ProtectedPathMatcher{patterns: patterns}, nil
+}
+
+// matches indicates whether or not a path matches any pattern, without
+// considering its parent paths.
+func (m *ProtectedPathMatcher) matches(path string) bool {
+	// A nil matcher doesn't match anything.
+	if m == nil {
+		return false
+	}
+
+	// Check each pattern. Since we've already validated the patterns in the
+	// constructor, we know match can't fail with an error.
+	for _, pattern := range m.patterns {
+		if match, _ := doublestar.Match(pattern, path); match {
+			return true
+		}
+	}
+
+	// No match.
+	return false
+}
+
+// Protected indicates whether or not a path is protected, i.e. whether or not
+// it or any of its parent paths match a pattern.
+func (m *ProtectedPathMatcher) Protected(path string) bool {
+	// A nil matcher doesn't protect anything.
+	if m == nil {
+		return false
+	}
+
+	// Check the path and its parents.
+	for {
+		if m.matches(path) {
+			return true
+		} else if path == "" {
+			return false
+		}
+		path = pathDir(path)
+	}
+}
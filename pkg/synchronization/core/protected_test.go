@@ -0,0 +1,219 @@
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+// TestNewProtectedPathMatcherNoPatterns tests that NewProtectedPathMatcher
+// returns a nil matcher when no patterns are specified and that a nil matcher
+// protects nothing.
+func TestNewProtectedPathMatcherNoPatterns(t *testing.T) {
+	matcher, err := NewProtectedPathMatcher(nil)
+	if err != nil {
+		t.Fatal("unable to create matcher:", err)
+	} else if matcher != nil {
+		t.Fatal("non-nil matcher created without patterns")
+	}
+	for _, path := range []string{"", "a", "a/b"} {
+		if matcher.Protected(path) {
+			t.Error("nil matcher protected path:", path)
+		}
+	}
+}
+
+// TestNewProtectedPathMatcherInvalidPatterns tests that NewProtectedPathMatcher
+// rejects invalid patterns.
+func TestNewProtectedPathMatcherInvalidPatterns(t *testing.T) {
+	for _, pattern := range []string{"", "["} {
+		if ValidProtectedPathPattern(pattern) {
+			t.Error("invalid pattern treated as valid:", pattern)
+		}
+		if _, err := NewProtectedPathMatcher([]string{"valid", pattern}); err == nil {
+			t.Error("matcher created with invalid pattern:", pattern)
+		}
+	}
+}
+
+// TestProtectedPathMatcherProtected tests protected path matching.
+func TestProtectedPathMatcherProtected(t *testing.T) {
+	// Create a matcher.
+	matcher, err := NewProtectedPathMatcher([]string{".env", "config/*.yml", "**/secrets"})
+	if err != nil {
+		t.Fatal("unable to create matcher:", err)
+	}
+
+	// Define test cases.
+	testCases := []struct {
+		path      string
+		protected bool
+	}{
+		{"", false},
+		{".env", true},
+		{"sub/.env", false},
+		{"config", false},
+		{"config/app.yml", true},
+		{"config/app.yaml", false},
+		{"config/nested/app.yml", false},
+		{"secrets", true},
+		{"a/b/secrets", true},
+		{"a/b/secrets/key", true},
+		{"a/b/secrets.txt", false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if protected := matcher.Protected(testCase.path); protected != testCase.protected {
+			t.Errorf("protection status for %s incorrect: %t != %t", testCase.path, protected, testCase.protected)
+		}
+	}
+}
+
+// TestTransitionProtectedPaths tests that transitions refuse to delete or
+// overwrite protected paths, leaving their content on disk and reporting
+// problems, while still performing unrelated transitions.
+func TestTransitionProtectedPaths(t *testing.T) {
+	// Create test content on disk and defer its removal.
+	root, parent, err := testTransitionCreate("", testDirectory1Entry, testDirectory1ContentMap, false)
+	if err != nil {
+		t.Fatal("unable to create test content:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Perform a scan to grab Unicode recomposition behavior and a cache.
+	_, _, recomposeUnicode, cache, _, _, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		newTestHasher(),
+		nil,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	}
+
+	// Create a protected path matcher.
+	protectedPaths, err := NewProtectedPathMatcher([]string{
+		"file",
+		"second directory",
+		"directory/subfile",
+	})
+	if err != nil {
+		t.Fatal("unable to create protected path matcher:", err)
+	}
+
+	// Create a provider for the new file content and ensure its cleanup.
+	provider, err := newTestProvider(map[string][]byte{
+		"file":     testFile2Contents,
+		"new file": testFile1Contents,
+	}, newTestHasher())
+	if err != nil {
+		t.Fatal("unable to create provider:", err)
+	}
+	defer provider.finalize()
+
+	// Set up transitions that delete and overwrite protected content, delete a
+	// directory containing protected content, and perform unrelated removals
+	// and creations.
+	transitions := []*Change{
+		{Path: "file", Old: testFile1Entry, New: testFile2Entry},
+		{Path: "second directory/subfile.exe", Old: testFile3Entry},
+		{Path: "directory", Old: testDirectory1Entry.Contents["directory"]},
+		{Path: "symlink", Old: testDirectory1Entry.Contents["symlink"]},
+		{Path: "new file", New: testFile1Entry},
+	}
+
+	// Perform the transition.
+	results, problems, providerMissingFiles := Transition(
+		context.Background(),
+		root,
+		transitions,
+		cache,
+		SymlinkMode_SymlinkModePortable,
+		defaultFilePermissionMode,
+		defaultDirectoryPermissionMode,
+		nil,
+		recomposeUnicode,
+		provider,
+		&TransitionOptions{
+			DurabilityMode: DurabilityMode_DurabilityModeFull,
+			Syncer:         filesystem.SystemSyncer,
+			ACLMode:        ACLMode_ACLModeIgnore,
+			ProtectedPaths: protectedPaths,
+		},
+	)
+	if providerMissingFiles {
+		t.Error("provider indicated missing files")
+	}
+
+	// Verify that the refused transitions were reported as problems.
+	expectedProblems := map[string]string{
+		"file":                         errProtectedOverwrite.Error(),
+		"second directory/subfile.exe": errProtectedDeletion.Error(),
+		"directory/subfile":            errProtectedDeletion.Error(),
+	}
+	if len(problems) != len(expectedProblems) {
+		t.Error("unexpected number of problems:", len(problems), "!=", len(expectedProblems))
+	}
+	for _, problem := range problems {
+		if expected, ok := expectedProblems[problem.Path]; !ok {
+			t.Error("unexpected problem:", problem.Path, problem.Error)
+		} else if problem.Error != expected {
+			t.Errorf("problem for %s incorrect: %s != %s", problem.Path, problem.Error, expected)
+		}
+	}
+
+	// Verify the resulting entries. Refused transitions should yield the
+	// original content and the partially removed directory should retain only
+	// the protected content.
+	if len(results) != len(transitions) {
+		t.Fatal("unexpected number of results:", len(results), "!=", len(transitions))
+	}
+	expectedResults := []*Entry{
+		testFile1Entry,
+		testFile3Entry,
+		{
+			Kind:     EntryKind_Directory,
+			Contents: map[string]*Entry{"subfile": testFile3Entry},
+		},
+		nil,
+		testFile1Entry,
+	}
+	for r, result := range results {
+		if !result.Equal(expectedResults[r]) {
+			t.Error("result incorrect for path:", transitions[r].Path)
+		}
+	}
+
+	// Verify the on-disk state.
+	if contents, err := ioutil.ReadFile(filepath.Join(root, "file")); err != nil {
+		t.Error("unable to read protected file:", err)
+	} else if string(contents) != string(testFile1Contents) {
+		t.Error("protected file overwritten")
+	}
+	for _, path := range []string{"second directory/subfile.exe", "directory/subfile", "new file"} {
+		if _, err := os.Lstat(filepath.Join(root, filepath.FromSlash(path))); err != nil {
+			t.Error("expected content missing:", path)
+		}
+	}
+	for _, path := range []string{"symlink", "directory/subdirectory", "directory/another symlink"} {
+		if _, err := os.Lstat(filepath.Join(root, filepath.FromSlash(path))); !os.IsNotExist(err) {
+			t.Error("unprotected content not removed:", path)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package core
+
+// PruneAncestor prunes an ancestor against a snapshot of one of its endpoints,
+// removing any content whose existence, kind, executability, or digest differs
+// between the two. The result contains only content that the ancestor and
+// snapshot agree upon. This is useful when an endpoint's synchronization root
+// changes, because it prevents content absent from the new root from being
+// treated as deleted while preserving history for content that still matches.
+// The provided entries are not modified.
+func PruneAncestor(ancestor, snapshot *Entry) *Entry {
+	// If the entries differ, then the ancestor's view of this location is no
+	// longer valid.
+	if !ancestor.equalShallow(snapshot) {
+		return nil
+	}
+
+	// Both entries are now either nil or equivalent, so create a slim copy of
+	// the ancestor (which will be nil if both entries are nil).
+	result := ancestor.copySlim()
+
+	// If this is a directory, then prune its contents, retaining only those
+	// that are still valid.
+	if result != nil && result.Kind == EntryKind_Directory {
+		for name, child := range ancestor.Contents {
+			if pruned := PruneAncestor(child, snapshot.Contents[name]); pruned != nil {
+				if result.Contents == nil {
+					result.Contents = make(map[string]*Entry)
+				}
+				result.Contents[name] = pruned
+			}
+		}
+	}
+
+	// Done.
+	return result
+}
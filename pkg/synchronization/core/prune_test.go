@@ -0,0 +1,51 @@
+package core
+
+import (
+	"testing"
+)
+
+// TestPruneAncestor tests PruneAncestor.
+func TestPruneAncestor(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		ancestor *Entry
+		snapshot *Entry
+		expected *Entry
+	}{
+		{nil, nil, nil},
+		{nil, testFile1Entry, nil},
+		{testFile1Entry, nil, nil},
+		{testFile1Entry, testFile1Entry, testFile1Entry},
+		{testFile1Entry, testFile3Entry, nil},
+		{testFile1Entry, testDirectory1Entry, nil},
+		{testDirectory1Entry, testDirectory1Entry, testDirectory1Entry},
+		{testDirectory1Entry, testEmptyDirectory, testEmptyDirectory},
+		{testDirectory1Entry, testSymlinkEntry, nil},
+		{
+			testDirectory1Entry,
+			testDirectory2Entry,
+			&Entry{
+				Kind: EntryKind_Directory,
+				Contents: map[string]*Entry{
+					"empty dir\xc3\xa9ctory": {
+						Kind: EntryKind_Directory,
+					},
+					"second directory": {
+						Kind: EntryKind_Directory,
+					},
+					"executable file": testFile2Entry,
+				},
+			},
+		},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		ancestor := testCase.ancestor.Copy()
+		if result := PruneAncestor(testCase.ancestor, testCase.snapshot); !result.Equal(testCase.expected) {
+			t.Errorf("test case %d: pruned ancestor does not match expected", i)
+		} else if !testCase.ancestor.Equal(ancestor) {
+			t.Errorf("test case %d: ancestor modified by pruning", i)
+		}
+	}
+}
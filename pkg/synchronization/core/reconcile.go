@@ -32,6 +32,27 @@ type reconciler struct {
 	betaChanges []*Change
 	// conflicts are the conflicts currently being tracked.
 	conflicts []*Conflict
+	// explain indicates whether or not decisions should be recorded.
+	explain bool
+	// decisions are the decisions currently being tracked, if enabled.
+	decisions []*ReconciliationDecision
+}
+
+// decide records a reconciliation decision, if decision recording is enabled.
+func (r *reconciler) decide(
+	path string, ancestor, alpha, beta *Entry,
+	action ReconciliationAction, rationale string,
+) {
+	if r.explain {
+		r.decisions = append(r.decisions, &ReconciliationDecision{
+			Path:      path,
+			Ancestor:  ancestor.copySlim(),
+			Alpha:     alpha.copySlim(),
+			Beta:      beta.copySlim(),
+			Action:    action,
+			Rationale: rationale,
+		})
+	}
 }
 
 // reconcile performs a recursive three-way merge.
@@ -44,20 +65,12 @@ func (r *reconciler) reconcile(path string, ancestor, alpha, beta *Entry) {
 		alphaContents := alpha.GetContents()
 		betaContents := beta.GetContents()
 
-		// See if the ancestor also agrees. If it disagrees, record the change
-		// for this path and ignore ancestor contents. Since the ancestor is
-		// updated with Apply, the Old value will be ignored anyway (since it
-		// doesn't need to be transitioned away like on-disk contents do during
-		// a transition), so we just leave it nil, rather than set it to the old
-		// ancestor contents. Additionally, since we'll be wiping out the old
-		// ancestor value at this path, we don't want to recursively add
-		// deletion changes for its old contents as well, so we nil them out at
-		// this point.
-		if !ancestor.equalShallow(alpha) {
-			r.ancestorChanges = append(r.ancestorChanges, &Change{
-				Path: path,
-				New:  alpha.copySlim(),
-			})
+		// See if the ancestor also agrees. If it disagrees, then an ancestor
+		// change will be recorded for this path and we ignore the ancestor
+		// contents. Since we'll be wiping out the old ancestor value at this
+		// path, we don't want to recursively add deletion changes for its old
+		// contents as well, so we nil them out at this point.
+		if r.updateAncestorIfDisagreeing(path, ancestor, alpha, beta) {
 			ancestorContents = nil
 		}
 
@@ -76,12 +89,45 @@ func (r *reconciler) reconcile(path string, ancestor, alpha, beta *Entry) {
 	}
 
 	// Since there was a disagreement about the contents of this path, we need
-	// to disaptch to the appropriate handler.
+	// to handle the disagreement.
+	r.handleDisagreement(path, ancestor, alpha, beta)
+}
+
+// updateAncestorIfDisagreeing records an ancestor change for a path where alpha
+// and beta agree if the ancestor disagrees with them. It returns whether or not
+// a change was recorded.
+func (r *reconciler) updateAncestorIfDisagreeing(path string, ancestor, alpha, beta *Entry) bool {
+	// If the ancestor agrees, then there's nothing to record.
+	if ancestor.equalShallow(alpha) {
+		return false
+	}
+
+	// Record the change. Since the ancestor is updated with Apply, the Old
+	// value will be ignored anyway (since it doesn't need to be transitioned
+	// away like on-disk contents do during a transition), so we just leave it
+	// nil, rather than set it to the old ancestor contents.
+	r.ancestorChanges = append(r.ancestorChanges, &Change{
+		Path: path,
+		New:  alpha.copySlim(),
+	})
+	r.decide(path, ancestor, alpha, beta,
+		ReconciliationAction_ReconciliationActionUpdateAncestor,
+		"alpha and beta agree but differ from ancestor",
+	)
+	return true
+}
+
+// handleDisagreement dispatches handling of a path where alpha and beta
+// disagree to the appropriate handler for the synchronization mode. The
+// entries must be complete (i.e. include their full contents).
+func (r *reconciler) handleDisagreement(path string, ancestor, alpha, beta *Entry) {
 	switch r.synchronizationMode {
 	case SynchronizationMode_SynchronizationModeTwoWaySafe:
 		r.handleDisagreementBidirectional(path, ancestor, alpha, beta)
 	case SynchronizationMode_SynchronizationModeTwoWayResolved:
 		r.handleDisagreementBidirectional(path, ancestor, alpha, beta)
+	case SynchronizationMode_SynchronizationModeTwoWayResolvedBeta:
+		r.handleDisagreementBidirectional(path, ancestor, alpha, beta)
 	case SynchronizationMode_SynchronizationModeOneWaySafe:
 		r.handleDisagreementUnidirectional(path, ancestor, alpha, beta)
 	case SynchronizationMode_SynchronizationModeOneWayReplica:
@@ -105,6 +151,10 @@ func (r *reconciler) handleDisagreementBidirectional(path string, ancestor, alph
 			Old:  ancestor,
 			New:  beta,
 		})
+		r.decide(path, ancestor, alpha, beta,
+			ReconciliationAction_ReconciliationActionPropagateToAlpha,
+			"alpha is unmodified and beta is modified",
+		)
 		return
 	}
 	betaDelta := diff(path, ancestor, beta)
@@ -114,6 +164,10 @@ func (r *reconciler) handleDisagreementBidirectional(path string, ancestor, alph
 			Old:  ancestor,
 			New:  alpha,
 		})
+		r.decide(path, ancestor, alpha, beta,
+			ReconciliationAction_ReconciliationActionPropagateToBeta,
+			"beta is unmodified and alpha is modified",
+		)
 		return
 	}
 
@@ -131,6 +185,25 @@ func (r *reconciler) handleDisagreementBidirectional(path string, ancestor, alph
 			Old:  beta,
 			New:  alpha,
 		})
+		r.decide(path, ancestor, alpha, beta,
+			ReconciliationAction_ReconciliationActionPropagateToBeta,
+			"both endpoints are modified and alpha wins conflicts",
+		)
+		return
+	}
+
+	// Similarly, if our synchronization mode states that beta is the
+	// unequivocal winner, then we can simply propagate its contents to alpha.
+	if r.synchronizationMode == SynchronizationMode_SynchronizationModeTwoWayResolvedBeta {
+		r.alphaChanges = append(r.alphaChanges, &Change{
+			Path: path,
+			Old:  alpha,
+			New:  beta,
+		})
+		r.decide(path, ancestor, alpha, beta,
+			ReconciliationAction_ReconciliationActionPropagateToAlpha,
+			"both endpoints are modified and beta wins conflicts",
+		)
 		return
 	}
 
@@ -147,6 +220,10 @@ func (r *reconciler) handleDisagreementBidirectional(path string, ancestor, alph
 			Old:  alpha,
 			New:  beta,
 		})
+		r.decide(path, ancestor, alpha, beta,
+			ReconciliationAction_ReconciliationActionPropagateToAlpha,
+			"both endpoints are modified but alpha has only deletions",
+		)
 		return
 	} else if len(betaDeltaNonDeletion) == 0 {
 		r.betaChanges = append(r.betaChanges, &Change{
@@ -154,6 +231,10 @@ func (r *reconciler) handleDisagreementBidirectional(path string, ancestor, alph
 			Old:  beta,
 			New:  alpha,
 		})
+		r.decide(path, ancestor, alpha, beta,
+			ReconciliationAction_ReconciliationActionPropagateToBeta,
+			"both endpoints are modified but beta has only deletions",
+		)
 		return
 	}
 
@@ -165,6 +246,10 @@ func (r *reconciler) handleDisagreementBidirectional(path string, ancestor, alph
 		AlphaChanges: alphaDeltaNonDeletion,
 		BetaChanges:  betaDeltaNonDeletion,
 	})
+	r.decide(path, ancestor, alpha, beta,
+		ReconciliationAction_ReconciliationActionConflict,
+		"both endpoints have non-deletion modifications",
+	)
 }
 
 func (r *reconciler) handleDisagreementUnidirectional(path string, ancestor, alpha, beta *Entry) {
@@ -177,6 +262,10 @@ func (r *reconciler) handleDisagreementUnidirectional(path string, ancestor, alp
 			Old:  beta,
 			New:  alpha,
 		})
+		r.decide(path, ancestor, alpha, beta,
+			ReconciliationAction_ReconciliationActionPropagateToBeta,
+			"beta is mirrored from alpha",
+		)
 		return
 	}
 
@@ -195,6 +284,10 @@ func (r *reconciler) handleDisagreementUnidirectional(path string, ancestor, alp
 			Old:  beta,
 			New:  alpha,
 		})
+		r.decide(path, ancestor, alpha, beta,
+			ReconciliationAction_ReconciliationActionPropagateToBeta,
+			"beta is unmodified or has only deletions",
+		)
 		return
 	}
 
@@ -268,6 +361,15 @@ func (r *reconciler) handleDisagreementUnidirectional(path string, ancestor, alp
 			// away like on-disk contents do during a transition), so we just
 			// leave it nil, rather than set it to the old ancestor contents.
 			r.ancestorChanges = append(r.ancestorChanges, &Change{Path: path})
+			r.decide(path, ancestor, alpha, beta,
+				ReconciliationAction_ReconciliationActionUpdateAncestor,
+				"alpha has no content and beta's modifications are retained",
+			)
+		} else {
+			r.decide(path, ancestor, alpha, beta,
+				ReconciliationAction_ReconciliationActionNone,
+				"alpha has no content and beta's creations are retained",
+			)
 		}
 		return
 	}
@@ -294,6 +396,10 @@ func (r *reconciler) handleDisagreementUnidirectional(path string, ancestor, alp
 		AlphaChanges: alphaDelta,
 		BetaChanges:  betaDeltaNonDeletion,
 	})
+	r.decide(path, ancestor, alpha, beta,
+		ReconciliationAction_ReconciliationActionConflict,
+		"beta has non-deletion modifications that block mirroring",
+	)
 }
 
 // Reconcile performs a recursive three-way merge and generates a list of
@@ -313,3 +419,25 @@ func Reconcile(
 	// Done.
 	return r.ancestorChanges, r.alphaChanges, r.betaChanges, r.conflicts
 }
+
+// ReconcileWithDecisions is identical to Reconcile, except that it also records
+// and returns the decisions made at each path where reconciliation generated
+// changes or conflicts. Paths where all entries agree aren't recorded. Decision
+// recording carries additional overhead, so it should only be used for
+// debugging.
+func ReconcileWithDecisions(
+	ancestor, alpha, beta *Entry,
+	synchronizationMode SynchronizationMode,
+) ([]*Change, []*Change, []*Change, []*Conflict, []*ReconciliationDecision) {
+	// Create the reconciler.
+	r := &reconciler{
+		synchronizationMode: synchronizationMode,
+		explain:             true,
+	}
+
+	// Perform reconciliation.
+	r.reconcile("", ancestor, alpha, beta)
+
+	// Done.
+	return r.ancestorChanges, r.alphaChanges, r.betaChanges, r.conflicts, r.decisions
+}
@@ -0,0 +1,311 @@
+package core
+
+import (
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// pathWithin returns whether or not a path lies strictly within the subtree
+// rooted at the specified parent path.
+func pathWithin(parent, path string) bool {
+	if parent == "" {
+		return path != ""
+	}
+	return len(path) > len(parent)+1 &&
+		path[len(parent)] == '/' &&
+		strings.HasPrefix(path, parent)
+}
+
+// pathLessInStream returns whether or not the first path precedes the second in
+// entry stream order, i.e. whether or not it precedes it when comparing paths
+// component-wise. This is equivalent to a byte-wise comparison where the path
+// separator sorts before all other bytes.
+func pathLessInStream(first, second string) bool {
+	for i := 0; i < len(first) && i < len(second); i++ {
+		f, s := first[i], second[i]
+		if f == s {
+			continue
+		} else if f == '/' {
+			return true
+		} else if s == '/' {
+			return false
+		}
+		return f < s
+	}
+	return len(first) < len(second)
+}
+
+// entryStreamCursor provides single-record lookahead on an entry stream. It
+// also verifies that records are strictly ordered.
+type entryStreamCursor struct {
+	// stream is the underlying stream.
+	stream EntryStream
+	// head is the next record in the stream, or nil if the stream has been
+	// exhausted.
+	head *EntryRecord
+	// started indicates whether or not a record has been read from the stream.
+	started bool
+	// previous is the path of the most recently read record.
+	previous string
+}
+
+// newEntryStreamCursor creates a new cursor for the specified stream.
+func newEntryStreamCursor(stream EntryStream) (*entryStreamCursor, error) {
+	result := &entryStreamCursor{stream: stream}
+	if err := result.advance(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// advance loads the next record from the underlying stream.
+func (c *entryStreamCursor) advance() error {
+	record, err := c.stream.Next()
+	if err == io.EOF {
+		c.head = nil
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "unable to read record")
+	} else if record.Entry == nil {
+		return errors.Errorf("nil entry in record for path \"%s\"", record.Path)
+	} else if len(record.Entry.Contents) > 0 {
+		return errors.Errorf("record for path \"%s\" contains directory contents", record.Path)
+	} else if c.started && !pathLessInStream(c.previous, record.Path) {
+		return errors.Errorf("record for path \"%s\" out of order", record.Path)
+	}
+	c.head = record
+	c.started = true
+	c.previous = record.Path
+	return nil
+}
+
+// take returns the entry at the specified path and advances the cursor if the
+// cursor is positioned at the path. Otherwise it returns nil.
+func (c *entryStreamCursor) take(path string) (*Entry, error) {
+	if c.head == nil || c.head.Path != path {
+		return nil, nil
+	}
+	entry := c.head.Entry
+	return entry, c.advance()
+}
+
+// childName returns the name of the child of the specified path at which the
+// cursor is positioned, or an empty string if the cursor isn't positioned
+// within the subtree rooted at the path.
+func (c *entryStreamCursor) childName(path string) (string, error) {
+	// Check whether or not the cursor is within the subtree.
+	if c.head == nil || !pathWithin(path, c.head.Path) {
+		return "", nil
+	}
+
+	// Extract the name. Since streams are ordered depth-first and we consume
+	// subtrees in their entirety, the cursor should be positioned at a direct
+	// child of the path.
+	name := c.head.Path
+	if path != "" {
+		name = name[len(path)+1:]
+	}
+	if strings.IndexByte(name, '/') != -1 {
+		return "", errors.Errorf("record for path \"%s\" not preceded by parent record", c.head.Path)
+	}
+	return name, nil
+}
+
+// ensureNoContents ensures that the cursor isn't positioned within the subtree
+// rooted at the specified path. It should be used for paths where the stream
+// doesn't contain a directory.
+func (c *entryStreamCursor) ensureNoContents(path string) error {
+	if c.head != nil && pathWithin(path, c.head.Path) {
+		return errors.Errorf("record for path \"%s\" not preceded by parent directory record", c.head.Path)
+	}
+	return nil
+}
+
+// skip discards all records within the subtree rooted at the specified path.
+func (c *entryStreamCursor) skip(path string) error {
+	for c.head != nil && pathWithin(path, c.head.Path) {
+		if err := c.advance(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// materialize consumes all records within the subtree rooted at the specified
+// path and attaches them to the specified (previously taken) root entry for the
+// path, returning the complete entry.
+func (c *entryStreamCursor) materialize(path string, root *Entry) (*Entry, error) {
+	// Track the directories within the subtree so that we can attach their
+	// contents.
+	directories := make(map[string]*Entry)
+	if root != nil && root.Kind == EntryKind_Directory {
+		directories[path] = root
+	}
+
+	// Attach records.
+	for c.head != nil && pathWithin(path, c.head.Path) {
+		parent, ok := directories[pathDir(c.head.Path)]
+		if !ok {
+			return nil, errors.Errorf("record for path \"%s\" not preceded by parent directory record", c.head.Path)
+		}
+		if parent.Contents == nil {
+			parent.Contents = make(map[string]*Entry)
+		}
+		parent.Contents[PathBase(c.head.Path)] = c.head.Entry
+		if c.head.Entry.Kind == EntryKind_Directory {
+			directories[c.head.Path] = c.head.Entry
+		}
+		if err := c.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Done.
+	return root, nil
+}
+
+// streamingReconciler provides the recursive implementation of streaming
+// reconciliation.
+type streamingReconciler struct {
+	// reconciler is the underlying reconciler used to record changes and
+	// handle disagreements.
+	reconciler
+	// ancestor is the cursor for the ancestor stream.
+	ancestor *entryStreamCursor
+	// alpha is the cursor for the alpha stream.
+	alpha *entryStreamCursor
+	// beta is the cursor for the beta stream.
+	beta *entryStreamCursor
+}
+
+// reconcile performs a recursive three-way merge of the subtrees rooted at the
+// specified path. It mirrors reconciler.reconcile, except that entries are
+// consumed from the streams as it proceeds and subtrees are only materialized
+// in memory where alpha and beta disagree.
+func (r *streamingReconciler) reconcile(path string) error {
+	// Extract the entries at this path.
+	ancestor, err := r.ancestor.take(path)
+	if err != nil {
+		return errors.Wrap(err, "unable to read ancestor")
+	}
+	alpha, err := r.alpha.take(path)
+	if err != nil {
+		return errors.Wrap(err, "unable to read alpha")
+	}
+	beta, err := r.beta.take(path)
+	if err != nil {
+		return errors.Wrap(err, "unable to read beta")
+	}
+
+	// If alpha and beta disagree on the contents of this path, then we need to
+	// materialize their full contents (and those of the ancestor) to determine
+	// how to handle the disagreement.
+	if !alpha.equalShallow(beta) {
+		if ancestor, err = r.ancestor.materialize(path, ancestor); err != nil {
+			return errors.Wrap(err, "unable to read ancestor")
+		} else if alpha, err = r.alpha.materialize(path, alpha); err != nil {
+			return errors.Wrap(err, "unable to read alpha")
+		} else if beta, err = r.beta.materialize(path, beta); err != nil {
+			return errors.Wrap(err, "unable to read beta")
+		}
+		r.handleDisagreement(path, ancestor, alpha, beta)
+		return nil
+	}
+
+	// See if the ancestor also agrees. If not, then we discard its contents,
+	// for the same reason as in the in-memory case.
+	if r.updateAncestorIfDisagreeing(path, ancestor, alpha, beta) {
+		if err := r.ancestor.skip(path); err != nil {
+			return errors.Wrap(err, "unable to read ancestor")
+		}
+	}
+
+	// Ensure that only directories have contents in the streams.
+	if !ancestor.IsDirectory() {
+		if err := r.ancestor.ensureNoContents(path); err != nil {
+			return errors.Wrap(err, "invalid ancestor")
+		}
+	}
+	if !alpha.IsDirectory() {
+		if err := r.alpha.ensureNoContents(path); err != nil {
+			return errors.Wrap(err, "invalid alpha")
+		}
+	}
+	if !beta.IsDirectory() {
+		if err := r.beta.ensureNoContents(path); err != nil {
+			return errors.Wrap(err, "invalid beta")
+		}
+	}
+
+	// Recursively handle contents, visiting names in sorted order.
+	for {
+		// Determine the next name in any of the streams.
+		var name string
+		for _, cursor := range [3]*entryStreamCursor{r.ancestor, r.alpha, r.beta} {
+			if candidate, err := cursor.childName(path); err != nil {
+				return err
+			} else if candidate != "" && (name == "" || candidate < name) {
+				name = candidate
+			}
+		}
+		if name == "" {
+			break
+		}
+
+		// Handle the content.
+		if err := r.reconcile(pathJoin(path, name)); err != nil {
+			return err
+		}
+	}
+
+	// Done.
+	return nil
+}
+
+// ReconcileStreaming performs the same three-way merge as Reconcile, but reads
+// the ancestor, alpha, and beta snapshots from entry streams (which may, for
+// example, be backed by on-disk stores created with WriteEntryStream). Entries
+// are processed in stream order and discarded once they've been reconciled,
+// with subtrees only being materialized in memory at paths where alpha and beta
+// disagree (since their full contents are required to compute changes and
+// conflicts). Memory usage is thus bounded by the depth of the hierarchy and
+// the size of the changes, rather than by the size of the snapshots. The
+// results are identical to those of Reconcile, up to ordering. This is library
+// functionality only: synchronization sessions still reconcile using Reconcile,
+// since endpoints transmit complete snapshots.
+func ReconcileStreaming(
+	ancestor, alpha, beta EntryStream,
+	synchronizationMode SynchronizationMode,
+) ([]*Change, []*Change, []*Change, []*Conflict, error) {
+	// Create the reconciler.
+	r := &streamingReconciler{
+		reconciler: reconciler{
+			synchronizationMode: synchronizationMode,
+		},
+	}
+	var err error
+	if r.ancestor, err = newEntryStreamCursor(ancestor); err != nil {
+		return nil, nil, nil, nil, errors.Wrap(err, "unable to read ancestor")
+	} else if r.alpha, err = newEntryStreamCursor(alpha); err != nil {
+		return nil, nil, nil, nil, errors.Wrap(err, "unable to read alpha")
+	} else if r.beta, err = newEntryStreamCursor(beta); err != nil {
+		return nil, nil, nil, nil, errors.Wrap(err, "unable to read beta")
+	}
+
+	// Perform reconciliation.
+	if err := r.reconcile(""); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	// Ensure that all streams were consumed.
+	for _, cursor := range [3]*entryStreamCursor{r.ancestor, r.alpha, r.beta} {
+		if cursor.head != nil {
+			return nil, nil, nil, nil, errors.Errorf("unexpected record for path \"%s\"", cursor.head.Path)
+		}
+	}
+
+	// Done.
+	return r.ancestorChanges, r.alphaChanges, r.betaChanges, r.conflicts, nil
+}
@@ -0,0 +1,301 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// testRandomEntryNames are the names used for content in randomly generated
+// entries. They include names whose sort order differs from that of the
+// corresponding paths, which exercises ordering in streams.
+var testRandomEntryNames = []string{"a", "a.b", "a-b", "b", "c"}
+
+// testRandomEntry generates a random entry hierarchy with the specified
+// maximum depth. Contents are drawn from a small set of digests and targets so
+// that random entries frequently agree.
+func testRandomEntry(random *rand.Rand, depth int) *Entry {
+	// Select the entry kind, only allowing directories if we haven't reached
+	// the maximum depth.
+	kinds := 3
+	if depth == 0 {
+		kinds = 2
+	}
+	switch random.Intn(kinds) {
+	case 0:
+		return &Entry{
+			Kind:       EntryKind_File,
+			Digest:     []byte{byte(random.Intn(3))},
+			Executable: random.Intn(4) == 0,
+		}
+	case 1:
+		return &Entry{
+			Kind:   EntryKind_Symlink,
+			Target: testRandomEntryNames[random.Intn(2)],
+		}
+	default:
+		result := &Entry{Kind: EntryKind_Directory}
+		for _, name := range testRandomEntryNames {
+			if random.Intn(2) == 0 {
+				if result.Contents == nil {
+					result.Contents = make(map[string]*Entry)
+				}
+				result.Contents[name] = testRandomEntry(random, depth-1)
+			}
+		}
+		return result
+	}
+}
+
+// testMutateEntry generates a randomly mutated version of an entry hierarchy.
+// Unmodified portions of the hierarchy are shared with the original.
+func testMutateEntry(random *rand.Rand, entry *Entry, depth int) *Entry {
+	// Decide on a mutation.
+	mutation := random.Intn(10)
+
+	// Handle absent entries, which may be created.
+	if entry == nil {
+		if mutation == 0 {
+			return testRandomEntry(random, depth)
+		}
+		return nil
+	}
+
+	// Handle deletion and replacement.
+	if mutation == 0 {
+		return nil
+	} else if mutation == 1 {
+		return testRandomEntry(random, depth)
+	}
+
+	// Handle modification of files.
+	if entry.Kind == EntryKind_File && mutation == 2 {
+		result := entry.copySlim()
+		result.Digest = []byte{byte(random.Intn(3))}
+		return result
+	}
+
+	// Leave non-directory entries unmodified.
+	if entry.Kind != EntryKind_Directory {
+		return entry
+	}
+
+	// Mutate directory contents, which may also create new content.
+	result := entry.copySlim()
+	for _, name := range testRandomEntryNames {
+		if child := testMutateEntry(random, entry.Contents[name], depth-1); child != nil {
+			if result.Contents == nil {
+				result.Contents = make(map[string]*Entry)
+			}
+			result.Contents[name] = child
+		}
+	}
+	return result
+}
+
+// testEntryStream creates an entry stream for an in-memory snapshot. If
+// diskBacked is true, then the stream is encoded and decoded (as it would be
+// if stored on disk) rather than generated directly from the snapshot.
+func testEntryStream(t *testing.T, snapshot *Entry, diskBacked bool) EntryStream {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Handle the in-memory case.
+	if !diskBacked {
+		return NewEntryStream(snapshot)
+	}
+
+	// Encode the stream.
+	buffer := &bytes.Buffer{}
+	if err := WriteEntryStream(buffer, NewEntryStream(snapshot)); err != nil {
+		t.Fatal("unable to write entry stream:", err)
+	}
+
+	// Create a decoding stream.
+	return NewEntryStreamReader(buffer)
+}
+
+// testEntryStreamRecords is an EntryStream implementation that yields a fixed
+// list of records.
+type testEntryStreamRecords []*EntryRecord
+
+// Next implements EntryStream.Next.
+func (s *testEntryStreamRecords) Next() (*EntryRecord, error) {
+	if len(*s) == 0 {
+		return nil, io.EOF
+	}
+	record := (*s)[0]
+	*s = (*s)[1:]
+	return record, nil
+}
+
+// TestEntryStreamOrderingAndRoundTrip tests that entry streams yield records in
+// depth-first pre-order with sorted names and that snapshots can be
+// reconstructed from both in-memory and disk-backed streams.
+func TestEntryStreamOrderingAndRoundTrip(t *testing.T) {
+	// Create a snapshot.
+	snapshot := &Entry{
+		Kind: EntryKind_Directory,
+		Contents: map[string]*Entry{
+			"a.b": testFile1Entry,
+			"a":   testDirectory1Entry,
+			"b":   testSymlinkEntry,
+		},
+	}
+
+	// Verify ordering and the reconstructed snapshot for each stream type.
+	for _, diskBacked := range []bool{false, true} {
+		// Read all records, verifying ordering and reconstructing the
+		// snapshot.
+		stream := testEntryStream(t, snapshot, diskBacked)
+		var records int
+		reconstructed := make(map[string]*Entry)
+		var previousTopLevel string
+		for {
+			record, err := stream.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal("unable to read record:", err)
+			}
+			records++
+			if record.Path == "" {
+				if records != 1 {
+					t.Error("root record not first")
+				}
+			} else if topLevel := record.Path; pathDir(topLevel) == "" {
+				if topLevel <= previousTopLevel {
+					t.Error("top-level records not sorted:", previousTopLevel, topLevel)
+				}
+				previousTopLevel = topLevel
+			} else if _, ok := reconstructed[pathDir(record.Path)]; !ok {
+				t.Error("record not preceded by parent record:", record.Path)
+			}
+			if len(record.Entry.Contents) != 0 {
+				t.Error("record includes directory contents:", record.Path)
+			}
+			reconstructed[record.Path] = record.Entry
+		}
+		if uint64(records) != snapshot.Count() {
+			t.Error("record count does not match entry count:", records, "!=", snapshot.Count())
+		}
+
+		// Verify that a cursor can reconstruct the snapshot.
+		cursor, err := newEntryStreamCursor(testEntryStream(t, snapshot, diskBacked))
+		if err != nil {
+			t.Fatal("unable to create stream cursor:", err)
+		}
+		root, err := cursor.take("")
+		if err != nil {
+			t.Fatal("unable to take root:", err)
+		}
+		if root, err = cursor.materialize("", root); err != nil {
+			t.Fatal("unable to materialize snapshot:", err)
+		} else if !root.Equal(snapshot) {
+			t.Error("materialized snapshot does not match original")
+		} else if cursor.head != nil {
+			t.Error("stream not exhausted by materialization")
+		}
+	}
+
+	// Verify that a nil snapshot yields no records.
+	if _, err := NewEntryStream(nil).Next(); err != io.EOF {
+		t.Error("nil snapshot stream not empty")
+	}
+}
+
+// TestReconcileStreamingMatchesInMemory is a differential test that verifies
+// that streaming reconciliation produces results identical to in-memory
+// reconciliation across randomized hierarchies and all synchronization modes.
+func TestReconcileStreamingMatchesInMemory(t *testing.T) {
+	// Create a deterministic random number generator.
+	random := rand.New(rand.NewSource(0))
+
+	// Set up synchronization modes.
+	modes := []SynchronizationMode{
+		SynchronizationMode_SynchronizationModeTwoWaySafe,
+		SynchronizationMode_SynchronizationModeTwoWayResolved,
+		SynchronizationMode_SynchronizationModeOneWaySafe,
+		SynchronizationMode_SynchronizationModeOneWayReplica,
+	}
+
+	// Perform randomized comparisons.
+	for i := 0; i < 2000; i++ {
+		// Generate an ancestor and mutated endpoints. In some cases, derive
+		// beta from alpha so that the endpoints agree on more content.
+		var ancestor *Entry
+		if random.Intn(10) != 0 {
+			ancestor = testRandomEntry(random, 3)
+		}
+		alpha := testMutateEntry(random, ancestor, 3)
+		var beta *Entry
+		if random.Intn(4) == 0 {
+			beta = testMutateEntry(random, alpha, 3)
+		} else {
+			beta = testMutateEntry(random, ancestor, 3)
+		}
+
+		// Compare results in each mode.
+		for _, mode := range modes {
+			expectedAncestorChanges, expectedAlphaChanges, expectedBetaChanges, expectedConflicts :=
+				Reconcile(ancestor, alpha, beta, mode)
+			diskBacked := i%2 == 1
+			ancestorChanges, alphaChanges, betaChanges, conflicts, err := ReconcileStreaming(
+				testEntryStream(t, ancestor, diskBacked),
+				testEntryStream(t, alpha, diskBacked),
+				testEntryStream(t, beta, diskBacked),
+				mode,
+			)
+			if err != nil {
+				t.Fatalf("iteration %d, mode %s: streaming reconciliation failed: %v", i, mode, err)
+			}
+			if !changeListsEqual(ancestorChanges, expectedAncestorChanges) {
+				t.Errorf("iteration %d, mode %s: ancestor changes differ", i, mode)
+			}
+			if !changeListsEqual(alphaChanges, expectedAlphaChanges) {
+				t.Errorf("iteration %d, mode %s: alpha changes differ", i, mode)
+			}
+			if !changeListsEqual(betaChanges, expectedBetaChanges) {
+				t.Errorf("iteration %d, mode %s: beta changes differ", i, mode)
+			}
+			if !conflictListsEqual(conflicts, expectedConflicts) {
+				t.Errorf("iteration %d, mode %s: conflicts differ", i, mode)
+			}
+		}
+	}
+}
+
+// TestReconcileStreamingInvalidStreams tests that streaming reconciliation
+// rejects streams that aren't correctly ordered.
+func TestReconcileStreamingInvalidStreams(t *testing.T) {
+	// Create a directory entry and a file entry for use in records.
+	directory := &Entry{Kind: EntryKind_Directory}
+	file := &Entry{Kind: EntryKind_File, Digest: []byte{0}}
+
+	// Define invalid record sequences.
+	testCases := [][]*EntryRecord{
+		{{Path: "a", Entry: file}},
+		{{Path: "", Entry: directory}, {Path: "b", Entry: file}, {Path: "a", Entry: file}},
+		{{Path: "", Entry: directory}, {Path: "a/b", Entry: file}},
+		{{Path: "", Entry: file}, {Path: "a", Entry: file}},
+		{{Path: "", Entry: directory}, {Path: "a", Entry: nil}},
+		{{Path: "", Entry: directory}, {Path: "a", Entry: file}, {Path: "a", Entry: file}},
+	}
+
+	// Verify that each sequence is rejected, both when reconciled against a
+	// matching stream and a differing stream.
+	for i, testCase := range testCases {
+		for _, beta := range []*Entry{testDirectory1Entry, nil} {
+			invalid := testEntryStreamRecords(append([]*EntryRecord(nil), testCase...))
+			if _, _, _, _, err := ReconcileStreaming(
+				NewEntryStream(nil),
+				&invalid,
+				NewEntryStream(beta),
+				SynchronizationMode_SynchronizationModeTwoWaySafe,
+			); err == nil {
+				t.Errorf("test case %d: invalid stream accepted", i)
+			}
+		}
+	}
+}
@@ -82,6 +82,66 @@ func conflictListsEqual(actualConflicts, expectedConflicts []*Conflict) bool {
 	return true
 }
 
+// decisionsMatchChanges determines whether or not a list of reconciliation
+// decisions accurately explains the specified changes and conflicts, i.e.
+// whether or not there is exactly one decision for each change and conflict,
+// with an action corresponding to the change or conflict type. Decisions with
+// no associated changes are permitted.
+func decisionsMatchChanges(
+	decisions []*ReconciliationDecision,
+	ancestorChanges, alphaChanges, betaChanges []*Change,
+	conflicts []*Conflict,
+) bool {
+	// Index the expected actions by path. Each path is expected to have only a
+	// single action.
+	expected := make(map[string]ReconciliationAction)
+	record := func(path string, action ReconciliationAction) bool {
+		if _, ok := expected[path]; ok {
+			return false
+		}
+		expected[path] = action
+		return true
+	}
+	for _, c := range ancestorChanges {
+		if !record(c.Path, ReconciliationAction_ReconciliationActionUpdateAncestor) {
+			return false
+		}
+	}
+	for _, c := range alphaChanges {
+		if !record(c.Path, ReconciliationAction_ReconciliationActionPropagateToAlpha) {
+			return false
+		}
+	}
+	for _, c := range betaChanges {
+		if !record(c.Path, ReconciliationAction_ReconciliationActionPropagateToBeta) {
+			return false
+		}
+	}
+	for _, c := range conflicts {
+		if !record(c.Root(), ReconciliationAction_ReconciliationActionConflict) {
+			return false
+		}
+	}
+
+	// Verify that each decision corresponds to an expected action and that
+	// every expected action has a decision.
+	explained := 0
+	for _, d := range decisions {
+		if err := d.EnsureValid(); err != nil {
+			return false
+		} else if d.Action == ReconciliationAction_ReconciliationActionNone {
+			if _, ok := expected[d.Path]; ok {
+				return false
+			}
+			continue
+		} else if action, ok := expected[d.Path]; !ok || action != d.Action {
+			return false
+		}
+		explained++
+	}
+	return explained == len(expected)
+}
+
 // reconcileTestCase is a utility type for reconciliation tests.
 type reconcileTestCase struct {
 	// ancestor is the ancestor contents for reconciliation.
@@ -151,6 +211,21 @@ func (c *reconcileTestCase) run(t *testing.T) {
 				"using", synchronizationMode,
 			)
 		}
+
+		// Perform reconciliation with decision recording and check that the
+		// results are identical and that the decisions explain them.
+		ancestorChangesExplained, alphaChangesExplained, betaChangesExplained, conflictsExplained, decisions := ReconcileWithDecisions(
+			c.ancestor, c.alpha, c.beta,
+			synchronizationMode,
+		)
+		if !changeListsEqual(ancestorChangesExplained, ancestorChanges) ||
+			!changeListsEqual(alphaChangesExplained, alphaChanges) ||
+			!changeListsEqual(betaChangesExplained, betaChanges) ||
+			!conflictListsEqual(conflictsExplained, conflicts) {
+			t.Error("reconciliation with decisions yielded different results using", synchronizationMode)
+		} else if !decisionsMatchChanges(decisions, ancestorChanges, alphaChanges, betaChanges, conflicts) {
+			t.Error("decisions do not explain reconciliation results:", decisions, "using", synchronizationMode)
+		}
 	}
 }
 
@@ -182,6 +257,7 @@ func TestReconcileAllNil(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 			SynchronizationMode_SynchronizationModeOneWaySafe,
 			SynchronizationMode_SynchronizationModeOneWayReplica,
 		},
@@ -204,6 +280,7 @@ func TestReconcileDirectoryNothingChanged(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 			SynchronizationMode_SynchronizationModeOneWaySafe,
 			SynchronizationMode_SynchronizationModeOneWayReplica,
 		},
@@ -226,6 +303,7 @@ func TestReconcileFileNothingChanged(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 			SynchronizationMode_SynchronizationModeOneWaySafe,
 			SynchronizationMode_SynchronizationModeOneWayReplica,
 		},
@@ -248,6 +326,7 @@ func TestReconcileAlphaModifiedRoot(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 			SynchronizationMode_SynchronizationModeOneWaySafe,
 			SynchronizationMode_SynchronizationModeOneWayReplica,
 		},
@@ -272,6 +351,7 @@ func TestReconcileBetaModifiedRootBidirectional(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 		},
 		expectedAncestorChanges: nil,
 		expectedAlphaChanges: []*Change{
@@ -349,6 +429,7 @@ func TestReconcileAlphaDeletedRoot(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 			SynchronizationMode_SynchronizationModeOneWaySafe,
 			SynchronizationMode_SynchronizationModeOneWayReplica,
 		},
@@ -373,6 +454,7 @@ func TestReconcileBetaDeletedRootBidirectional(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 		},
 		expectedAncestorChanges: nil,
 		expectedAlphaChanges: []*Change{
@@ -417,6 +499,7 @@ func TestReconcileBothDeletedRoot(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 			SynchronizationMode_SynchronizationModeOneWaySafe,
 			SynchronizationMode_SynchronizationModeOneWayReplica,
 		},
@@ -441,6 +524,7 @@ func TestReconcileAlphaCreatedRoot(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 			SynchronizationMode_SynchronizationModeOneWaySafe,
 			SynchronizationMode_SynchronizationModeOneWayReplica,
 		},
@@ -465,6 +549,7 @@ func TestReconcileBetaCreatedRootBidirectional(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 		},
 		expectedAncestorChanges: nil,
 		expectedAlphaChanges: []*Change{
@@ -527,6 +612,7 @@ func TestReconcileBothCreatedSameFile(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 			SynchronizationMode_SynchronizationModeOneWaySafe,
 			SynchronizationMode_SynchronizationModeOneWayReplica,
 		},
@@ -551,6 +637,7 @@ func TestReconcileBothCreatedSameDirectory(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 			SynchronizationMode_SynchronizationModeOneWaySafe,
 			SynchronizationMode_SynchronizationModeOneWayReplica,
 		},
@@ -973,6 +1060,7 @@ func TestReconcileAlphaPartiallyDeletedDirectory(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 			SynchronizationMode_SynchronizationModeOneWaySafe,
 			SynchronizationMode_SynchronizationModeOneWayReplica,
 		},
@@ -996,6 +1084,7 @@ func TestReconcileBetaPartiallyDeletedDirectoryBidirectional(t *testing.T) {
 		synchronizationModes: []SynchronizationMode{
 			SynchronizationMode_SynchronizationModeTwoWaySafe,
 			SynchronizationMode_SynchronizationModeTwoWayResolved,
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
 		},
 		expectedAncestorChanges: nil,
 		expectedAlphaChanges:    diff("", testDirectory2Entry, testDirectory3Entry),
@@ -1131,3 +1220,309 @@ func TestReconcileAlphaPartiallyDeletedDirectoryBetaReplacedDirectoryOneWaySafe(
 	// Run the test case.
 	testCase.run(t)
 }
+
+func TestReconcileBothModifiedFileTwoWayResolved(t *testing.T) {
+	// Set up the test case.
+	testCase := reconcileTestCase{
+		ancestor: testFile1Entry,
+		alpha:    testFile2Entry,
+		beta:     testFile3Entry,
+		synchronizationModes: []SynchronizationMode{
+			SynchronizationMode_SynchronizationModeTwoWayResolved,
+		},
+		expectedAncestorChanges: nil,
+		expectedAlphaChanges:    nil,
+		expectedBetaChanges: []*Change{
+			{Old: testFile3Entry, New: testFile2Entry},
+		},
+		expectedConflicts: nil,
+	}
+
+	// Run the test case.
+	testCase.run(t)
+}
+
+func TestReconcileBothModifiedFileTwoWayResolvedBeta(t *testing.T) {
+	// Set up the test case.
+	testCase := reconcileTestCase{
+		ancestor: testFile1Entry,
+		alpha:    testFile2Entry,
+		beta:     testFile3Entry,
+		synchronizationModes: []SynchronizationMode{
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
+		},
+		expectedAncestorChanges: nil,
+		expectedAlphaChanges: []*Change{
+			{Old: testFile2Entry, New: testFile3Entry},
+		},
+		expectedBetaChanges: nil,
+		expectedConflicts:   nil,
+	}
+
+	// Run the test case.
+	testCase.run(t)
+}
+
+func TestReconcileBothCreatedPartiallyMatchingContentsTwoWayResolvedBeta(t *testing.T) {
+	// Set up the test case. Non-conflicting creations should propagate in both
+	// directions, with only the conflicting creation resolved in beta's favor.
+	testCase := reconcileTestCase{
+		ancestor: &Entry{},
+		alpha: &Entry{
+			Contents: map[string]*Entry{
+				"same":      testDirectory1Entry,
+				"alpha":     testFile1Entry,
+				"different": testFile1Entry,
+			},
+		},
+		beta: &Entry{
+			Contents: map[string]*Entry{
+				"same":      testDirectory1Entry,
+				"beta":      testFile2Entry,
+				"different": testDirectory3Entry,
+			},
+		},
+		synchronizationModes: []SynchronizationMode{
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
+		},
+		expectedAncestorChanges: testDecomposeEntry("same", testDirectory1Entry, true),
+		expectedAlphaChanges: []*Change{
+			{Path: "beta", New: testFile2Entry},
+			{Path: "different", Old: testFile1Entry, New: testDirectory3Entry},
+		},
+		expectedBetaChanges: []*Change{
+			{Path: "alpha", New: testFile1Entry},
+		},
+		expectedConflicts: nil,
+	}
+
+	// Run the test case.
+	testCase.run(t)
+}
+
+func TestReconcileBothCreatedDifferentTypesTwoWayResolvedBeta(t *testing.T) {
+	// Set up the test case.
+	testCase := reconcileTestCase{
+		ancestor: nil,
+		alpha:    testDirectory1Entry,
+		beta:     testFile1Entry,
+		synchronizationModes: []SynchronizationMode{
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
+		},
+		expectedAncestorChanges: nil,
+		expectedAlphaChanges: []*Change{
+			{
+				Old: testDirectory1Entry,
+				New: testFile1Entry,
+			},
+		},
+		expectedBetaChanges: nil,
+		expectedConflicts:   nil,
+	}
+
+	// Run the test case.
+	testCase.run(t)
+}
+
+func TestReconcileAlphaDeletedRootBetaCreatedFileTwoWayResolvedBeta(t *testing.T) {
+	// Set up the test case.
+	testCase := reconcileTestCase{
+		ancestor: testDirectory1Entry,
+		alpha:    nil,
+		beta:     testFile1Entry,
+		synchronizationModes: []SynchronizationMode{
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
+		},
+		expectedAncestorChanges: nil,
+		expectedAlphaChanges: []*Change{
+			{New: testFile1Entry},
+		},
+		expectedBetaChanges: nil,
+		expectedConflicts:   nil,
+	}
+
+	// Run the test case.
+	testCase.run(t)
+}
+
+func TestReconcileAlphaCreatedFileBetaDeletedRootTwoWayResolvedBeta(t *testing.T) {
+	// Set up the test case. Beta's deletion should win over alpha's
+	// modification.
+	testCase := reconcileTestCase{
+		ancestor: testDirectory1Entry,
+		alpha:    testFile1Entry,
+		beta:     nil,
+		synchronizationModes: []SynchronizationMode{
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
+		},
+		expectedAncestorChanges: nil,
+		expectedAlphaChanges: []*Change{
+			{Old: testFile1Entry},
+		},
+		expectedBetaChanges: nil,
+		expectedConflicts:   nil,
+	}
+
+	// Run the test case.
+	testCase.run(t)
+}
+
+func TestReconcileAlphaCreatedDirectoryBetaDeletedRootTwoWayResolvedBeta(t *testing.T) {
+	// Set up the test case. Beta's deletion should win over alpha's
+	// modification.
+	testCase := reconcileTestCase{
+		ancestor: testFile1Entry,
+		alpha:    testDirectory1Entry,
+		beta:     nil,
+		synchronizationModes: []SynchronizationMode{
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
+		},
+		expectedAncestorChanges: nil,
+		expectedAlphaChanges: []*Change{
+			{Old: testDirectory1Entry},
+		},
+		expectedBetaChanges: nil,
+		expectedConflicts:   nil,
+	}
+
+	// Run the test case.
+	testCase.run(t)
+}
+
+func TestReconcileAlphaReplacedDirectoryBetaPartiallyDeletedDirectoryTwoWayResolvedBeta(t *testing.T) {
+	// Set up the test case. Worth noting here is that testDirectory3Entry is a
+	// subtree of testDirectory2Entry. Even though beta has only deletions, it
+	// should still win the conflict.
+	testCase := reconcileTestCase{
+		ancestor: testDirectory2Entry,
+		alpha:    testFile1Entry,
+		beta:     testDirectory3Entry,
+		synchronizationModes: []SynchronizationMode{
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
+		},
+		expectedAncestorChanges: nil,
+		expectedAlphaChanges: []*Change{
+			{
+				Old: testFile1Entry,
+				New: testDirectory3Entry,
+			},
+		},
+		expectedBetaChanges: nil,
+		expectedConflicts:   nil,
+	}
+
+	// Run the test case.
+	testCase.run(t)
+}
+
+func TestReconcileAlphaPartiallyDeletedDirectoryBetaReplacedDirectoryTwoWayResolvedBeta(t *testing.T) {
+	// Set up the test case. Worth noting here is that testDirectory3Entry is a
+	// subtree of testDirectory2Entry.
+	testCase := reconcileTestCase{
+		ancestor: testDirectory2Entry,
+		alpha:    testDirectory3Entry,
+		beta:     testFile1Entry,
+		synchronizationModes: []SynchronizationMode{
+			SynchronizationMode_SynchronizationModeTwoWayResolvedBeta,
+		},
+		expectedAncestorChanges: nil,
+		expectedAlphaChanges: []*Change{
+			{
+				Old: testDirectory3Entry,
+				New: testFile1Entry,
+			},
+		},
+		expectedBetaChanges: nil,
+		expectedConflicts:   nil,
+	}
+
+	// Run the test case.
+	testCase.run(t)
+}
+
+func TestReconcileWithDecisionsInputsAndActions(t *testing.T) {
+	// Set up known states with a propagation in each direction and a conflict.
+	ancestor := &Entry{
+		Contents: map[string]*Entry{
+			"modified": testFile1Entry,
+		},
+	}
+	alpha := &Entry{
+		Contents: map[string]*Entry{
+			"modified":  testFile2Entry,
+			"alpha":     testFile1Entry,
+			"different": testFile1Entry,
+		},
+	}
+	beta := &Entry{
+		Contents: map[string]*Entry{
+			"modified":  testFile1Entry,
+			"beta":      testFile2Entry,
+			"different": testDirectory3Entry,
+		},
+	}
+
+	// Perform reconciliation.
+	_, alphaChanges, betaChanges, conflicts, decisions := ReconcileWithDecisions(
+		ancestor, alpha, beta,
+		SynchronizationMode_SynchronizationModeTwoWaySafe,
+	)
+
+	// Define the expected decisions.
+	expected := map[string]struct {
+		action   ReconciliationAction
+		ancestor *Entry
+		alpha    *Entry
+		beta     *Entry
+	}{
+		"modified":  {ReconciliationAction_ReconciliationActionPropagateToBeta, testFile1Entry, testFile2Entry, testFile1Entry},
+		"alpha":     {ReconciliationAction_ReconciliationActionPropagateToBeta, nil, testFile1Entry, nil},
+		"beta":      {ReconciliationAction_ReconciliationActionPropagateToAlpha, nil, nil, testFile2Entry},
+		"different": {ReconciliationAction_ReconciliationActionConflict, nil, testFile1Entry, testDirectory3Entry.copySlim()},
+	}
+
+	// Verify the decisions.
+	if len(decisions) != len(expected) {
+		t.Fatal("decision count does not match expected:", len(decisions), "!=", len(expected))
+	}
+	for _, d := range decisions {
+		e, ok := expected[d.Path]
+		if !ok {
+			t.Error("unexpected decision for path:", d.Path)
+			continue
+		}
+		if d.Action != e.action {
+			t.Errorf("decision action for %s does not match expected: %s != %s", d.Path, d.Action.Description(), e.action.Description())
+		}
+		if !d.Ancestor.Equal(e.ancestor) || !d.Alpha.Equal(e.alpha) || !d.Beta.Equal(e.beta) {
+			t.Error("decision inputs do not match expected for path:", d.Path)
+		}
+		if d.Rationale == "" {
+			t.Error("decision has no rationale for path:", d.Path)
+		}
+	}
+
+	// Verify that the decisions match the generated changes.
+	if len(alphaChanges) != 1 || alphaChanges[0].Path != "beta" {
+		t.Error("alpha changes do not match decisions:", alphaChanges)
+	}
+	if len(betaChanges) != 2 {
+		t.Error("beta changes do not match decisions:", betaChanges)
+	}
+	if len(conflicts) != 1 || conflicts[0].Root() != "different" {
+		t.Error("conflicts do not match decisions:", conflicts)
+	}
+}
+
+func TestReconcileWithDecisionsAgreementNotRecorded(t *testing.T) {
+	// Perform reconciliation on identical states.
+	_, _, _, _, decisions := ReconcileWithDecisions(
+		testDirectory1Entry, testDirectory1Entry, testDirectory1Entry,
+		SynchronizationMode_SynchronizationModeTwoWaySafe,
+	)
+
+	// Verify that no decisions were recorded.
+	if len(decisions) != 0 {
+		t.Error("decisions recorded for agreeing states:", decisions)
+	}
+}
@@ -9,8 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
-	"time"
 
 	"golang.org/x/text/unicode/norm"
 
@@ -41,6 +41,11 @@ const (
 	// doubling on insert without always allocating a huge cache. Its value is
 	// somewhat arbitrary.
 	defaultInitialCacheCapacity = 1024
+
+	// OpenFileSkippedError is the error message used for problems describing
+	// files that were skipped because they're open for writing by another
+	// process.
+	OpenFileSkippedError = "file skipped: open for writing by another process"
 )
 
 var (
@@ -107,8 +112,15 @@ type scanner struct {
 	ignorer *ignorer
 	// ignoreCache is the cache of ignored path behavior.
 	ignoreCache IgnoreCache
+	// gitIgnorer is the ignorer identifying paths ignored by Git. It is nil if
+	// Git-ignored paths aren't being ignored. Its results aren't recorded in
+	// the ignore cache, since they may change as Git ignore rules change.
+	gitIgnorer *gitIgnorer
 	// symlinkMode is the symlink mode to use for synchronization.
 	symlinkMode SymlinkMode
+	// brokenSymlinkMode is the broken symlink mode to use for portable
+	// symlinks. It is never BrokenSymlinkMode_BrokenSymlinkModeDefault.
+	brokenSymlinkMode BrokenSymlinkMode
 	// newCache is the new file digest cache to populate.
 	newCache *Cache
 	// newIgnoreCache is the new ignored path behavior cache to populate.
@@ -121,9 +133,437 @@ type scanner struct {
 	// due to Unicode decomposition behavior on the synchronization root
 	// filesystem.
 	recomposeUnicode bool
+	// filenames is the transcoder used to decode on-disk names and symbolic
+	// link targets. It may be nil if no transcoding is necessary.
+	filenames *FilenameTranscoder
 	// preservesExecutability indicates whether or not the synchronization root
 	// filesystem preserves POSIX executability bits.
 	preservesExecutability bool
+	// maximumFileSize is the maximum size of files to include in the scan. A
+	// value of 0 indicates no limit.
+	maximumFileSize uint64
+	// contentTypeMode is the content type mode to use for filtering files. It
+	// is never ContentTypeMode_ContentTypeModeDefault.
+	contentTypeMode ContentTypeMode
+	// lineEndings is the matcher identifying files whose digests should be
+	// computed over their content with canonicalized line endings. It may be
+	// nil if no files are subject to line ending translation.
+	lineEndings *LineEndingMatcher
+	// openFiles is the set of paths for files that are open for writing by
+	// other processes and should thus be skipped. It may be nil if no files
+	// should be skipped for this reason.
+	openFiles map[string]bool
+	// skipped is the list of problems describing files that were skipped due
+	// to exceeding the maximum file size, being excluded by the content type
+	// mode, being open for writing, or having names that can't be decoded.
+	skipped []*Problem
+	// captureACLs indicates whether or not POSIX ACLs should be captured for
+	// files and directories.
+	captureACLs bool
+	// preserveHardLinks indicates whether or not hard link structure should
+	// be recorded for files.
+	preserveHardLinks bool
+	// preserveMacOSMetadata indicates whether or not macOS metadata should be
+	// captured for files and directories (and AppleDouble sidecar files
+	// excluded from the scan).
+	preserveMacOSMetadata bool
+	// preserveFileFlags indicates whether or not file flags should be captured
+	// for files and directories.
+	preserveFileFlags bool
+	// digestJobs is the queue of deferred digest computations for concurrent
+	// digest workers. It is nil if digests are computed serially.
+	digestJobs chan *digestJob
+	// digestWorkers tracks the digest worker Goroutines.
+	digestWorkers sync.WaitGroup
+	// digestErrorLock serializes access to digestError.
+	digestErrorLock sync.Mutex
+	// digestError is the first error encountered by a digest worker.
+	digestError error
+}
+
+// digestJob represents a deferred digest computation for a file.
+type digestJob struct {
+	// path is the path of the file.
+	path string
+	// file is the open file. It is closed by the digest worker.
+	file filesystem.ReadableFile
+	// size is the expected size of the file.
+	size uint64
+	// entry is the entry for the file, whose digest will be set.
+	entry *Entry
+	// cacheEntry is the cache entry for the file, whose digest will be set.
+	cacheEntry *CacheEntry
+}
+
+// hash computes the digest of a file's contents using the specified hasher and
+// copy buffer, verifying that the expected amount of data is read.
+func (s *scanner) hash(
+	hasher hash.Hash,
+	copyBuffer []byte,
+	path string,
+	file filesystem.ReadableFile,
+	size uint64,
+) ([]byte, error) {
+	// Reset the hash state.
+	hasher.Reset()
+
+	// If the file is large enough to contain holes, then read it using hole
+	// detection so that holes don't need to be read from disk.
+	var source io.Reader = file
+	if size >= filesystem.SparseBlockSize {
+		source = filesystem.NewSparseReader(file)
+	}
+
+	// Copy data into the hash and verify that we copied the amount expected.
+	// We use a preemptable wrapper around the hasher to enable timely
+	// cancellation. If the file is subject to line ending translation, then we
+	// hash its content with canonicalized line endings, so that its digest is
+	// independent of the line ending style in which it's stored.
+	var destination io.Writer = &preemptableWriter{
+		cancelled:     s.cancelled,
+		writer:        hasher,
+		checkInterval: scannerCopyPreemptionInterval,
+	}
+	var canonicalizer *LineEndingWriter
+	if s.lineEndings.Matches(path) {
+		canonicalizer = NewLineEndingWriter(destination, LineEndingStyle_LineEndingStyleLF)
+		destination = canonicalizer
+	}
+	if copied, err := io.CopyBuffer(destination, source, copyBuffer); err != nil {
+		if err == errWritePreempted {
+			return nil, errScanCancelled
+		}
+		return nil, fmt.Errorf("unable to hash file contents (%s): %w", path, err)
+	} else if uint64(copied) != size {
+		return nil, fmt.Errorf("hashed size mismatch (%s): %d != %d", path, copied, size)
+	} else if canonicalizer != nil {
+		if err := canonicalizer.Flush(); err != nil {
+			return nil, fmt.Errorf("unable to hash file contents (%s): %w", path, err)
+		}
+	}
+
+	// Compute the digest.
+	return hasher.Sum(nil), nil
+}
+
+// startDigestWorkers starts concurrent digest workers, one for each of the
+// specified hashers.
+func (s *scanner) startDigestWorkers(hashers []hash.Hash) {
+	s.digestJobs = make(chan *digestJob, len(hashers))
+	for _, hasher := range hashers {
+		s.digestWorkers.Add(1)
+		go s.digestWorker(hasher)
+	}
+}
+
+// digestWorker is the run loop for a digest worker.
+func (s *scanner) digestWorker(hasher hash.Hash) {
+	// Signal completion when we're done.
+	defer s.digestWorkers.Done()
+
+	// Allocate a copy buffer for this worker.
+	copyBuffer := make([]byte, scannerCopyBufferSize)
+
+	// Process jobs until the queue is closed. Once a failure has occurred, the
+	// scan will fail, so we just discard any remaining jobs.
+	for job := range s.digestJobs {
+		s.digestErrorLock.Lock()
+		failed := s.digestError != nil
+		s.digestErrorLock.Unlock()
+		if failed {
+			job.file.Close()
+			continue
+		}
+		digest, err := s.hash(hasher, copyBuffer, job.path, job.file, job.size)
+		job.file.Close()
+		if err != nil {
+			s.digestErrorLock.Lock()
+			if s.digestError == nil {
+				s.digestError = err
+			}
+			s.digestErrorLock.Unlock()
+			continue
+		}
+		job.entry.Digest = digest
+		job.cacheEntry.Digest = digest
+	}
+}
+
+// stopDigestWorkers waits for any concurrent digest workers to complete all
+// queued jobs and returns the first error that they encountered, if any.
+func (s *scanner) stopDigestWorkers() error {
+	if s.digestJobs == nil {
+		return nil
+	}
+	close(s.digestJobs)
+	s.digestWorkers.Wait()
+	return s.digestError
+}
+
+// deferDigest opens a file and queues the computation of its digest for a
+// concurrent digest worker, returning the file's entry with a digest that will
+// be populated once the workers have completed.
+func (s *scanner) deferDigest(
+	path string,
+	parent *filesystem.Directory,
+	metadata *filesystem.Metadata,
+	executable bool,
+	contentType ContentType,
+) (*Entry, error) {
+	// Convert the modification time to Protocol Buffers format.
+	modificationTimeProto, err := ptypes.TimestampProto(metadata.ModificationTime)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert file modification time (%s): %w", path, err)
+	}
+
+	// Capture ACLs.
+	acl, err := s.acl(path, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Capture macOS metadata.
+	macOSMetadata, err := s.macOSMetadata(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Capture file flags.
+	fileFlags, err := s.fileFlags(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Open the file. Its closure is handled by the digest worker.
+	file, err := parent.OpenFile(metadata.Name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file (%s): %w", path, err)
+	}
+
+	// Create the cache entry and entry.
+	cacheEntry := &CacheEntry{
+		Mode:             uint32(metadata.Mode),
+		ModificationTime: modificationTimeProto,
+		Size:             metadata.Size,
+		FileID:           metadata.FileID,
+		ContentType:      contentType,
+	}
+	s.newCache.Entries[path] = cacheEntry
+	entry := &Entry{
+		Kind:          EntryKind_File,
+		Acl:           acl,
+		MacOSMetadata: macOSMetadata,
+		FileFlags:     fileFlags,
+		Executable:    executable,
+	}
+
+	// Queue the job.
+	select {
+	case s.digestJobs <- &digestJob{
+		path:       path,
+		file:       file,
+		size:       metadata.Size,
+		entry:      entry,
+		cacheEntry: cacheEntry,
+	}:
+	case <-s.cancelled:
+		file.Close()
+		return nil, errScanCancelled
+	}
+
+	// Success.
+	return entry, nil
+}
+
+// acl captures the POSIX ACLs for the file or directory at the specified path,
+// if ACL capture is enabled.
+func (s *scanner) acl(path string, directory bool) (*ACL, error) {
+	if !s.captureACLs {
+		return nil, nil
+	}
+	acl, err := readACL(s.filenames.FilesystemPath(s.root, path), directory)
+	if err != nil {
+		return nil, fmt.Errorf("unable to capture ACLs (%s): %w", path, err)
+	}
+	return acl, nil
+}
+
+// macOSMetadata captures the macOS metadata for the file or directory at the
+// specified path, if macOS metadata preservation is enabled. Metadata isn't
+// captured for the synchronization root, since its AppleDouble sidecar file
+// would reside outside of the synchronization root.
+func (s *scanner) macOSMetadata(path string) ([]*MacOSAttribute, error) {
+	if !s.preserveMacOSMetadata || path == "" {
+		return nil, nil
+	}
+	metadata, err := readMacOSMetadata(s.filenames.FilesystemPath(s.root, path))
+	if err != nil {
+		return nil, fmt.Errorf("unable to capture macOS metadata (%s): %w", path, err)
+	}
+	return metadata, nil
+}
+
+// fileFlags captures the file flags for the file or directory at the specified
+// path, if file flag preservation is enabled and file flags are supported on
+// this platform. Flags aren't captured for the synchronization root, since a
+// locked root (e.g. one marked as immutable) would block synchronization.
+func (s *scanner) fileFlags(path string) (uint32, error) {
+	if !s.preserveFileFlags || path == "" || filesystem.SupportedFileFlags == 0 {
+		return 0, nil
+	}
+	flags, err := filesystem.ReadFileFlags(s.filenames.FilesystemPath(s.root, path))
+	if err != nil {
+		return 0, fmt.Errorf("unable to capture file flags (%s): %w", path, err)
+	}
+	return uint32(flags), nil
+}
+
+// exceedsMaximumFileSize determines whether or not a file with the specified
+// metadata exceeds the scanner's maximum file size. If it does, then a problem
+// describing the skipped file is recorded.
+func (s *scanner) exceedsMaximumFileSize(path string, metadata *filesystem.Metadata) bool {
+	if s.maximumFileSize == 0 || metadata.Size <= s.maximumFileSize {
+		return false
+	}
+	s.skipped = append(s.skipped, &Problem{
+		Path: path,
+		Error: fmt.Sprintf("file skipped: size (%d bytes) exceeds maximum file size (%d bytes)",
+			metadata.Size, s.maximumFileSize,
+		),
+	})
+	return true
+}
+
+// openForWriting determines whether or not the file at the specified path is
+// open for writing by another process. If it is, then a problem describing the
+// skipped file is recorded.
+func (s *scanner) openForWriting(path string) bool {
+	if !s.openFiles[path] {
+		return false
+	}
+	s.skipped = append(s.skipped, &Problem{
+		Path:  path,
+		Error: OpenFileSkippedError,
+	})
+	return true
+}
+
+// cachedContent looks up the cache entry for a file and determines whether or
+// not the file's content is unchanged since the entry was created. In order for
+// the content to be considered unchanged, we require that type, modification
+// time, file size, and file ID haven't changed. Permission bit changes aren't
+// considered since they don't affect content.
+func (s *scanner) cachedContent(path string, metadata *filesystem.Metadata) (*CacheEntry, bool, error) {
+	// Try to find cached data for this path.
+	cached, cacheHit := s.cache.Entries[path]
+	if !cacheHit {
+		return nil, false, nil
+	}
+
+	// Convert the timestamp for this cache entry to Go format. We go this way
+	// (instead of converting the metadata timestamp to Protocol Buffers format)
+	// because it avoids allocation (unlike the other direction).
+	cachedModificationTime, err := ptypes.Timestamp(cached.ModificationTime)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to convert cached modification time (%s): %w", path, err)
+	}
+
+	// Check for content changes.
+	contentMatch := (metadata.Mode&filesystem.ModeTypeMask) == (filesystem.Mode(cached.Mode)&filesystem.ModeTypeMask) &&
+		metadata.ModificationTime.Equal(cachedModificationTime) &&
+		metadata.Size == cached.Size &&
+		metadata.FileID == cached.FileID
+
+	// Done.
+	return cached, contentMatch, nil
+}
+
+// contentType determines the content type of a file and whether or not it's
+// excluded by the scanner's content type mode. If content type filtering is
+// disabled, then ContentType_ContentTypeUnknown is returned and the file is
+// never excluded. The content type is pulled from the cache if the file's
+// content is unchanged, otherwise it's determined by sniffing the start of the
+// file. If the file is excluded, then a problem describing the skipped file is
+// recorded and a cache entry (without a digest) is created to avoid sniffing
+// the file again on subsequent scans. The semantics of parent and file are the
+// same as for the file method, and if file is provided, then it will be
+// rewound after sniffing.
+func (s *scanner) contentType(
+	path string,
+	parent *filesystem.Directory,
+	metadata *filesystem.Metadata,
+	file filesystem.ReadableFile,
+) (ContentType, bool, error) {
+	// If content type filtering is disabled, then there's nothing to do.
+	if s.contentTypeMode == ContentTypeMode_ContentTypeModeAll {
+		return ContentType_ContentTypeUnknown, false, nil
+	}
+
+	// Attempt to use a cached content type.
+	cached, contentMatch, err := s.cachedContent(path, metadata)
+	if err != nil {
+		return ContentType_ContentTypeUnknown, false, err
+	}
+	var contentType ContentType
+	if contentMatch {
+		contentType = cached.ContentType
+	}
+
+	// If there wasn't a cached content type, then sniff the file's content.
+	if contentType == ContentType_ContentTypeUnknown {
+		if file == nil {
+			sniffed, err := parent.OpenFile(metadata.Name)
+			if err != nil {
+				return ContentType_ContentTypeUnknown, false, fmt.Errorf("unable to open file (%s): %w", path, err)
+			}
+			contentType, err = sniffContentType(sniffed, s.copyBuffer)
+			sniffed.Close()
+			if err != nil {
+				return ContentType_ContentTypeUnknown, false, fmt.Errorf("unable to determine content type (%s): %w", path, err)
+			}
+		} else {
+			if contentType, err = sniffContentType(file, s.copyBuffer); err != nil {
+				return ContentType_ContentTypeUnknown, false, fmt.Errorf("unable to determine content type (%s): %w", path, err)
+			} else if _, err = file.Seek(0, io.SeekStart); err != nil {
+				return ContentType_ContentTypeUnknown, false, fmt.Errorf("unable to rewind file (%s): %w", path, err)
+			}
+		}
+	}
+
+	// If the file is included, then we're done.
+	if s.contentTypeMode.includes(contentType) {
+		return contentType, false, nil
+	}
+
+	// Record the skipped file.
+	s.skipped = append(s.skipped, &Problem{
+		Path: path,
+		Error: fmt.Sprintf("file skipped: %s content excluded by content type mode (%s)",
+			contentType.Description(), s.contentTypeMode.Description(),
+		),
+	})
+
+	// Add an entry to the new cache, re-using the existing cache entry if
+	// possible.
+	if contentMatch && filesystem.Mode(cached.Mode) == metadata.Mode &&
+		cached.Digest == nil && cached.ContentType == contentType {
+		s.newCache.Entries[path] = cached
+	} else {
+		modificationTimeProto, err := ptypes.TimestampProto(metadata.ModificationTime)
+		if err != nil {
+			return ContentType_ContentTypeUnknown, false, fmt.Errorf("unable to convert file modification time (%s): %w", path, err)
+		}
+		s.newCache.Entries[path] = &CacheEntry{
+			Mode:             uint32(metadata.Mode),
+			ModificationTime: modificationTimeProto,
+			Size:             metadata.Size,
+			FileID:           metadata.FileID,
+			ContentType:      contentType,
+		}
+	}
+
+	// Done.
+	return contentType, true, nil
 }
 
 // file performs processing of a file entry. Exactly one of parent or file will
@@ -132,53 +572,55 @@ type scanner struct {
 // file will be provided and the caller will be responsible for its closure
 // (i.e. this function should not close it). Otherwise, the parent of the path
 // is provided and this function is responsible for opening and closing the file
-// as necessary.
+// as necessary. The content type is recorded in the file's cache entry.
 func (s *scanner) file(
 	path string,
 	parent *filesystem.Directory,
 	metadata *filesystem.Metadata,
 	file filesystem.ReadableFile,
+	contentType ContentType,
 ) (*Entry, error) {
 	// Compute executability.
 	executable := s.preservesExecutability && anyExecutableBitSet(metadata.Mode)
 
-	// Try to find cached data for this path.
-	cached, cacheHit := s.cache.Entries[path]
-
-	// Convert the timestamp for this cache entry (if there is one) to Go
-	// format. We go this way (instead of converting the metadata timestamp to
-	// Protocol Buffers format) because it avoids allocation (unlike the other
-	// direction).
-	var cachedModificationTime time.Time
-	var err error
-	if cacheHit {
-		cachedModificationTime, err = ptypes.Timestamp(cached.ModificationTime)
-		if err != nil {
-			return nil, fmt.Errorf("unable to convert cached modification time (%s): %w", path, err)
-		}
-	}
-
 	// Check if we can reuse the cached digest (in order to avoid recomputation)
-	// and the cache entry itself (in order to avoid allocation). In order for
-	// the cached digest to be considered valid, we require that type,
-	// modification time, file size, and file ID haven't changed. We don't check
+	// and the cache entry itself (in order to avoid allocation). We don't check
 	// for permission bit changes when assessing digest reusability since they
 	// don't affect content, but we do check for full mode equivalence when
 	// assessing cache entry reusability since permission changes need to be
 	// detected during transition operations (where the cache is also used).
-	cacheContentMatch := cacheHit &&
-		(metadata.Mode&filesystem.ModeTypeMask) == (filesystem.Mode(cached.Mode)&filesystem.ModeTypeMask) &&
-		metadata.ModificationTime.Equal(cachedModificationTime) &&
-		metadata.Size == cached.Size &&
-		metadata.FileID == cached.FileID
-	cacheEntryReusable := cacheContentMatch && filesystem.Mode(cached.Mode) == metadata.Mode
-
-	// Compute the digest, either by pulling it from the cache or computing it
-	// from the on-disk contents.
+	// Cache entries for files previously excluded by content type don't have
+	// digests, so they can't be reused.
+	cached, cacheContentMatch, err := s.cachedContent(path, metadata)
+	if err != nil {
+		return nil, err
+	}
+	cacheContentMatch = cacheContentMatch && cached.Digest != nil
+	cacheEntryReusable := cacheContentMatch &&
+		filesystem.Mode(cached.Mode) == metadata.Mode &&
+		cached.ContentType == contentType
+
+	// Compute the digest, either by pulling it from the cache, extracting it
+	// from a placeholder marker, or computing it from the on-disk contents.
+	// Placeholders are always empty, so we only need to check for a marker if
+	// the file is empty. If a placeholder's content is written in-place, then
+	// its marker is ignored.
 	var digest []byte
 	if cacheContentMatch {
 		digest = cached.Digest
-	} else {
+	} else if metadata.Size == 0 {
+		if digest, err = filesystem.ReadPlaceholder(s.filenames.FilesystemPath(s.root, path)); err != nil {
+			return nil, fmt.Errorf("unable to read placeholder marker (%s): %w", path, err)
+		}
+	}
+	if digest == nil {
+		// If we're computing digests concurrently (which is only done for files
+		// below the synchronization root), then defer the computation to the
+		// digest workers.
+		if file == nil && s.digestJobs != nil {
+			return s.deferDigest(path, parent, metadata, executable, contentType)
+		}
+
 		// Open the file if it's not open already. If we do open it, then defer
 		// its closure.
 		if file == nil {
@@ -189,28 +631,10 @@ func (s *scanner) file(
 			defer file.Close()
 		}
 
-		// Reset the hash state.
-		s.hasher.Reset()
-
-		// Copy data into the hash and verify that we copied the amount
-		// expected. We use a preemptable wrapper around the hasher to enable
-		// timely cancellation.
-		preemptableHasher := &preemptableWriter{
-			cancelled:     s.cancelled,
-			writer:        s.hasher,
-			checkInterval: scannerCopyPreemptionInterval,
-		}
-		if copied, err := io.CopyBuffer(preemptableHasher, file, s.copyBuffer); err != nil {
-			if err == errWritePreempted {
-				return nil, errScanCancelled
-			}
-			return nil, fmt.Errorf("unable to hash file contents (%s): %w", path, err)
-		} else if uint64(copied) != metadata.Size {
-			return nil, fmt.Errorf("hashed size mismatch (%s): %d != %d", path, copied, metadata.Size)
-		}
-
 		// Compute the digest.
-		digest = s.hasher.Sum(nil)
+		if digest, err = s.hash(s.hasher, s.copyBuffer, path, file, metadata.Size); err != nil {
+			return nil, err
+		}
 	}
 
 	// Add an entry to the new cache. We check to see if we can re-use the
@@ -232,18 +656,68 @@ func (s *scanner) file(
 			Size:             metadata.Size,
 			FileID:           metadata.FileID,
 			Digest:           digest,
+			ContentType:      contentType,
 		}
 	}
 
+	// Capture ACLs.
+	acl, err := s.acl(path, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Capture macOS metadata.
+	macOSMetadata, err := s.macOSMetadata(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Capture file flags.
+	fileFlags, err := s.fileFlags(path)
+	if err != nil {
+		return nil, err
+	}
+
 	// Success.
 	return &Entry{
-		Kind:       EntryKind_File,
-		Executable: executable,
-		Digest:     digest,
+		Kind:          EntryKind_File,
+		Acl:           acl,
+		MacOSMetadata: macOSMetadata,
+		FileFlags:     fileFlags,
+		Executable:    executable,
+		Digest:        digest,
 	}, nil
 }
 
-// symbolicLink performs processing of a symbolic link entry.
+// brokenSymbolicLink determines whether or not the symbolic link at the
+// specified path (with the specified target) is broken. A link is only
+// considered broken if its target lies outside of the synchronization root and
+// can't be resolved. Links with targets inside the synchronization root are
+// never considered broken, since their targets may simply not have been
+// synchronized yet.
+func (s *scanner) brokenSymbolicLink(path, target string) bool {
+	// Normalize separators on Windows, where targets may use backslashes.
+	if runtime.GOOS == "windows" {
+		target = strings.ReplaceAll(target, "\\", "/")
+	}
+
+	// Check whether or not the target lies within the synchronization root.
+	// Targets containing colons are treated as lying outside the root since
+	// they may represent Windows absolute paths.
+	if !strings.Contains(target, ":") {
+		if _, inTree := inTreeSymlinkTarget(path, target); inTree {
+			return false
+		}
+	}
+
+	// Check whether or not the target can be resolved.
+	_, err := os.Stat(s.filenames.FilesystemPath(s.root, path))
+	return err != nil
+}
+
+// symbolicLink performs processing of a symbolic link entry. If the link is
+// broken and broken links are being skipped, then a problem describing the link
+// is recorded and a nil entry is returned.
 func (s *scanner) symbolicLink(
 	path string,
 	parent *filesystem.Directory,
@@ -256,6 +730,29 @@ func (s *scanner) symbolicLink(
 		return nil, fmt.Errorf("unable to read symbolic link target (%s): %w", path, err)
 	}
 
+	// Decode the link target if necessary.
+	if decoded, ok := s.filenames.decode(target); !ok {
+		return nil, fmt.Errorf("unable to decode symbolic link target (%s) using filename encoding (%s)",
+			path, s.filenames.filenameEncoding.Description(),
+		)
+	} else {
+		target = decoded
+	}
+
+	// If we're enforcing portability and broken links require special
+	// handling, then check whether or not the link is broken.
+	if enforcePortable && s.brokenSymlinkMode != BrokenSymlinkMode_BrokenSymlinkModeSync &&
+		s.brokenSymbolicLink(path, target) {
+		if s.brokenSymlinkMode == BrokenSymlinkMode_BrokenSymlinkModeSkip {
+			s.skipped = append(s.skipped, &Problem{
+				Path:  path,
+				Error: "symbolic link skipped: target outside synchronization root does not exist",
+			})
+			return nil, nil
+		}
+		return nil, fmt.Errorf("broken symbolic link (%s): target outside synchronization root does not exist", path)
+	}
+
 	// If requested, enforce that the link is portable, otherwise just ensure
 	// that it's non-empty (this is required even in POSIX raw mode).
 	if enforcePortable {
@@ -327,6 +824,20 @@ func (s *scanner) directory(
 	// advantageous, because it gives us some opportunity to detect concurrent
 	// filesystem modifications.
 
+	// If we're ignoring Git-ignored paths and this directory (other than the
+	// synchronization root, which is handled when creating the Git ignorer)
+	// is the root of a Git repository or submodule, then load the paths
+	// ignored by that repository, since they won't have been identified by any
+	// containing repository.
+	if s.gitIgnorer != nil && path != "" {
+		for _, contentMetadata := range directoryContents {
+			if contentMetadata.Name == ".git" {
+				s.gitIgnorer.load(path)
+				break
+			}
+		}
+	}
+
 	// Compute entries.
 	contents := make(map[string]*Entry, len(directoryContents))
 	for _, contentMetadata := range directoryContents {
@@ -345,6 +856,22 @@ func (s *scanner) directory(
 			continue
 		}
 
+		// If we're preserving macOS metadata, then ignore AppleDouble sidecar
+		// files, since their contents are captured as metadata.
+		if s.preserveMacOSMetadata && isAppleDoubleName(contentName) {
+			continue
+		}
+
+		// Decode the content name if necessary. Content with names that can't
+		// be decoded is skipped and reported, since it can't be represented
+		// faithfully on other endpoints.
+		if decoded, ok := s.filenames.decode(contentName); !ok {
+			s.skipped = append(s.skipped, s.filenames.undecodableNameProblem(path, contentName))
+			continue
+		} else {
+			contentName = decoded
+		}
+
 		// Recompose Unicode in the content name if necessary.
 		if s.recomposeUnicode {
 			contentName = norm.NFC.String(contentName)
@@ -379,6 +906,11 @@ func (s *scanner) directory(
 			continue
 		}
 
+		// Determine whether or not this path is ignored by Git.
+		if s.gitIgnorer != nil && s.gitIgnorer.ignored(contentPath, contentIsDirectory) {
+			continue
+		}
+
 		// If we have a baseline, then check if that baseline has content with
 		// the same name and kind as what we see on disk. If so, then we can use
 		// that as a baseline for the content.
@@ -404,14 +936,26 @@ func (s *scanner) directory(
 		var entry *Entry
 		var err error
 		if contentKind == EntryKind_File {
-			entry, err = s.file(contentPath, directory, contentMetadata, nil)
+			if s.exceedsMaximumFileSize(contentPath, contentMetadata) || s.openForWriting(contentPath) {
+				continue
+			}
+			contentType, excluded, err := s.contentType(contentPath, directory, contentMetadata, nil)
+			if err != nil {
+				return nil, err
+			} else if excluded {
+				continue
+			}
+			entry, err = s.file(contentPath, directory, contentMetadata, nil, contentType)
 		} else if contentKind == EntryKind_Symlink {
 			if s.symlinkMode == SymlinkMode_SymlinkModePortable {
-				entry, err = s.symbolicLink(contentPath, directory, contentName, true)
+				entry, err = s.symbolicLink(contentPath, directory, contentMetadata.Name, true)
+				if err == nil && entry == nil {
+					continue
+				}
 			} else if s.symlinkMode == SymlinkMode_SymlinkModeIgnore {
 				continue
 			} else if s.symlinkMode == SymlinkMode_SymlinkModePOSIXRaw {
-				entry, err = s.symbolicLink(contentPath, directory, contentName, false)
+				entry, err = s.symbolicLink(contentPath, directory, contentMetadata.Name, false)
 			} else {
 				panic("unsupported symlink mode")
 			}
@@ -430,15 +974,182 @@ func (s *scanner) directory(
 		contents[contentName] = entry
 	}
 
+	// Capture ACLs.
+	acl, err := s.acl(path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Capture macOS metadata.
+	macOSMetadata, err := s.macOSMetadata(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Capture file flags.
+	fileFlags, err := s.fileFlags(path)
+	if err != nil {
+		return nil, err
+	}
+
 	// Success.
 	return &Entry{
-		Kind:     EntryKind_Directory,
-		Contents: contents,
+		Kind:          EntryKind_Directory,
+		Acl:           acl,
+		MacOSMetadata: macOSMetadata,
+		FileFlags:     fileFlags,
+		Contents:      contents,
 	}, nil
 }
 
+// linkHardLinks identifies files in the scan result that share an underlying
+// file (i.e. are hard links to one another) and records the hard link
+// structure by setting the HardLink field of each such file entry (except the
+// one with the lowest path) to the lowest path in its group. Since scans don't
+// cross filesystem boundaries, file IDs (which are taken from the new cache to
+// cover entries re-used from the baseline) uniquely identify underlying files.
+// Entries may be shared with the baseline, so modified entries (and their
+// parents) are copied rather than updated in place.
+func (s *scanner) linkHardLinks(result *Entry) *Entry {
+	// Identify the lowest path for each underlying file, as well as the number
+	// of paths referencing it. Platforms that don't provide file IDs (notably
+	// Windows) report a file ID of 0, in which case we can't identify links.
+	lowest := make(map[uint64]string)
+	counts := make(map[uint64]int)
+	result.walk("", func(path string, entry *Entry) {
+		if entry.Kind != EntryKind_File {
+			return
+		}
+		cached, ok := s.newCache.Entries[path]
+		if !ok || cached.FileID == 0 {
+			return
+		}
+		if existing, ok := lowest[cached.FileID]; !ok || path < existing {
+			lowest[cached.FileID] = path
+		}
+		counts[cached.FileID]++
+	})
+
+	// Update the result.
+	return s.applyHardLinks("", result, lowest, counts)
+}
+
+// applyHardLinks is the recursive implementation of linkHardLinks, returning
+// the entry (or a modified copy thereof) with hard link information applied.
+func (s *scanner) applyHardLinks(path string, entry *Entry, lowest map[uint64]string, counts map[uint64]int) *Entry {
+	// Handle the entry based on kind.
+	if entry.Kind == EntryKind_File {
+		// Compute the expected hard link path.
+		var hardLink string
+		if cached, ok := s.newCache.Entries[path]; ok && counts[cached.FileID] > 1 {
+			if canonical := lowest[cached.FileID]; canonical != path {
+				hardLink = canonical
+			}
+		}
+
+		// If the entry needs updating, then copy it.
+		if entry.HardLink != hardLink {
+			entry = entry.copySlim()
+			entry.HardLink = hardLink
+		}
+	} else if entry.Kind == EntryKind_Directory {
+		// Process contents, creating a copy of the directory if any content
+		// entries are modified.
+		var contents map[string]*Entry
+		for name, content := range entry.Contents {
+			updated := s.applyHardLinks(pathJoin(path, name), content, lowest, counts)
+			if updated != content && contents == nil {
+				contents = make(map[string]*Entry, len(entry.Contents))
+				for n, c := range entry.Contents {
+					contents[n] = c
+				}
+			}
+			if contents != nil {
+				contents[name] = updated
+			}
+		}
+		if contents != nil {
+			entry = entry.copySlim()
+			entry.Contents = contents
+		}
+	}
+
+	// Done.
+	return entry
+}
+
+// ScanOptions specifies optional scanning behavior. The zero value corresponds
+// to the default scanning behavior.
+type ScanOptions struct {
+	// BaselineSkipped are the problems describing files skipped when
+	// generating the baseline. They must be provided if a baseline is provided
+	// so that they can be propagated for content that isn't explicitly
+	// revisited.
+	BaselineSkipped []*Problem
+	// IgnoreGitIgnored indicates that paths ignored by any Git repository
+	// containing or contained within the root should be excluded in addition
+	// to those matched by the ignore patterns. This behavior is silently
+	// disabled if Git isn't available.
+	IgnoreGitIgnored bool
+	// BrokenSymlinkMode specifies how symbolic links whose targets lie outside
+	// the root and don't exist are handled in portable symlink mode, being
+	// either treated like any other symbolic link, excluded in the same manner
+	// as files exceeding the maximum file size, or treated as a scan failure.
+	// The default mode treats them like any other symbolic link.
+	BrokenSymlinkMode BrokenSymlinkMode
+	// MaximumFileSize is the maximum size of files to include in the scan. If
+	// non-zero, then files exceeding this size are excluded from the scan and
+	// problems describing them are returned.
+	MaximumFileSize uint64
+	// ContentTypeMode specifies the content types to include in the scan. If
+	// it's ContentTypeMode_ContentTypeModeText or
+	// ContentTypeMode_ContentTypeModeBinary, then files are classified by
+	// sniffing their leading content for NUL bytes and those of the other
+	// content type are excluded in the same manner as files exceeding the
+	// maximum file size, with the classification recorded in the cache.
+	ContentTypeMode ContentTypeMode
+	// LineEndings is an optional line ending matcher. The digests of files that
+	// it matches are computed over their content with canonicalized line
+	// endings (see LineEndingWriter), so that their digests don't depend on the
+	// line ending style in which they're stored.
+	LineEndings *LineEndingMatcher
+	// ACLMode specifies the handling of POSIX ACLs. If it's
+	// ACLMode_ACLModePropagate, then ACLs that can't be represented by
+	// permission mode bits alone are captured for files and directories on
+	// supporting platforms and filesystems (and silently omitted elsewhere).
+	ACLMode ACLMode
+	// PreserveHardLinks indicates that files within a directory root that share
+	// an underlying file should be recorded as hard links (on platforms that
+	// support their identification).
+	PreserveHardLinks bool
+	// PreserveMacOSMetadata indicates that macOS metadata should be captured
+	// for files and directories below the root (natively on macOS and from
+	// AppleDouble sidecar files elsewhere), with AppleDouble sidecar files
+	// excluded from the scan.
+	PreserveMacOSMetadata bool
+	// PreserveFileFlags indicates that file flags should be captured for files
+	// and directories below the root on platforms that support them.
+	PreserveFileFlags bool
+	// DigestHashers are optional hashers used to compute file digests
+	// concurrently, with one worker per digest hasher. They're only used if
+	// more than one is provided.
+	DigestHashers []hash.Hash
+	// OpenFiles is an optional set of open file paths (e.g. as computed by
+	// filesystem.FilesOpenForWriting). Files at these paths are excluded in
+	// the same manner as files exceeding the maximum file size, with their
+	// problems using OpenFileSkippedError as their error message.
+	OpenFiles map[string]bool
+	// Filenames is an optional filename transcoder. If provided, then on-disk
+	// names and symbolic link targets are decoded to UTF-8 (with paths in the
+	// result, the caches, and any re-check paths and open file paths all using
+	// decoded names), and content with names that can't be decoded is excluded
+	// in the same manner as files exceeding the maximum file size.
+	Filenames *FilenameTranscoder
+}
+
 // Scan provides recursive filesystem scanning facilities for synchronization
-// roots.
+// roots. Optional scanning behavior is controlled by the specified options,
+// which may be nil to use the default behavior.
 func Scan(
 	ctx context.Context,
 	root string,
@@ -450,10 +1161,16 @@ func Scan(
 	ignoreCache IgnoreCache,
 	probeMode behavior.ProbeMode,
 	symlinkMode SymlinkMode,
-) (*Entry, bool, bool, *Cache, IgnoreCache, error) {
+	options *ScanOptions,
+) (*Entry, bool, bool, *Cache, IgnoreCache, []*Problem, error) {
+	// Use the default options if none were specified.
+	if options == nil {
+		options = &ScanOptions{}
+	}
+
 	// Verify that the symlink mode is valid for this platform.
 	if symlinkMode == SymlinkMode_SymlinkModePOSIXRaw && runtime.GOOS == "windows" {
-		return nil, false, false, nil, nil, errors.New("raw POSIX symlinks not supported on Windows")
+		return nil, false, false, nil, nil, nil, errors.New("raw POSIX symlinks not supported on Windows")
 	}
 
 	// Open the root and defer its closure. We explicitly disallow symbolic
@@ -461,9 +1178,9 @@ func Scan(
 	rootObject, metadata, err := filesystem.Open(root, false)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, false, false, &Cache{}, nil, nil
+			return nil, false, false, &Cache{}, nil, nil, nil
 		} else {
-			return nil, false, false, nil, nil, fmt.Errorf("unable to open synchronization root: %w", err)
+			return nil, false, false, nil, nil, nil, fmt.Errorf("unable to open synchronization root: %w", err)
 		}
 	}
 	defer rootObject.Close()
@@ -508,7 +1225,7 @@ func Scan(
 		if cachedDecomposesOk {
 			decomposesUnicode = cachedDecomposes
 		} else if decomposes, usedFiles, err := behavior.DecomposesUnicode(directoryRoot, probeMode); err != nil {
-			return nil, false, false, nil, nil, fmt.Errorf("unable to probe root Unicode decomposition behavior: %w", err)
+			return nil, false, false, nil, nil, nil, fmt.Errorf("unable to probe root Unicode decomposition behavior: %w", err)
 		} else {
 			decomposesUnicode = decomposes
 			usedProbeFiles = usedProbeFiles || usedFiles
@@ -518,7 +1235,7 @@ func Scan(
 		if cachedPreservesOk {
 			preservesExecutability = cachedPreserves
 		} else if preserves, usedFiles, err := behavior.PreservesExecutability(directoryRoot, probeMode); err != nil {
-			return nil, false, false, nil, nil, fmt.Errorf("unable to probe root executability preservation behavior: %w", err)
+			return nil, false, false, nil, nil, nil, fmt.Errorf("unable to probe root executability preservation behavior: %w", err)
 		} else {
 			preservesExecutability = preserves
 			usedProbeFiles = usedProbeFiles || usedFiles
@@ -567,7 +1284,7 @@ func Scan(
 		if cachedPreservesOk {
 			preservesExecutability = cachedPreserves
 		} else if preserves, usedFiles, err := behavior.PreservesExecutabilityByPath(filepath.Dir(root), probeMode); err != nil {
-			return nil, false, false, nil, nil, fmt.Errorf("unable to probe root parent executability preservation behavior: %w", err)
+			return nil, false, false, nil, nil, nil, fmt.Errorf("unable to probe root parent executability preservation behavior: %w", err)
 		} else {
 			preservesExecutability = preserves
 			usedProbeFiles = usedProbeFiles || usedFiles
@@ -598,7 +1315,7 @@ func Scan(
 	// correspond to the baseline, because doing so is expensive. We place the
 	// burden of enforcing that invariant on the caller.
 	if baseline != nil && len(recheckPaths) == 0 {
-		return baseline, preservesExecutability, decomposesUnicode, cache, ignoreCache, nil
+		return baseline, preservesExecutability, decomposesUnicode, cache, ignoreCache, options.BaselineSkipped, nil
 	}
 
 	// Convert the list of re-check paths into a set of dirty paths. The rule is
@@ -627,7 +1344,14 @@ func Scan(
 	// Create the ignorer.
 	ignorer, err := newIgnorer(ignores)
 	if err != nil {
-		return nil, false, false, nil, nil, fmt.Errorf("unable to create ignorer: %w", err)
+		return nil, false, false, nil, nil, nil, fmt.Errorf("unable to create ignorer: %w", err)
+	}
+
+	// If we're ignoring Git-ignored paths, then create the Git ignorer. This
+	// is only relevant for directory roots.
+	var gitIgnorer *gitIgnorer
+	if options.IgnoreGitIgnored && rootKind == EntryKind_Directory {
+		gitIgnorer = newGitIgnorer(ctx, root)
 	}
 
 	// Create a new cache to populate. Estimate its capacity based on the
@@ -650,6 +1374,18 @@ func Scan(
 	}
 	newIgnoreCache := make(IgnoreCache, initialIgnoreCacheCapacity)
 
+	// Treat the default content type mode as including all files.
+	contentTypeMode := options.ContentTypeMode
+	if contentTypeMode == ContentTypeMode_ContentTypeModeDefault {
+		contentTypeMode = ContentTypeMode_ContentTypeModeAll
+	}
+
+	// Treat the default broken symlink mode as synchronizing broken symlinks.
+	brokenSymlinkMode := options.BrokenSymlinkMode
+	if brokenSymlinkMode == BrokenSymlinkMode_BrokenSymlinkModeDefault {
+		brokenSymlinkMode = BrokenSymlinkMode_BrokenSymlinkModeSync
+	}
+
 	// Create a scanner.
 	s := &scanner{
 		cancelled:              ctx.Done(),
@@ -659,26 +1395,54 @@ func Scan(
 		cache:                  cache,
 		ignorer:                ignorer,
 		ignoreCache:            ignoreCache,
+		gitIgnorer:             gitIgnorer,
 		symlinkMode:            symlinkMode,
+		brokenSymlinkMode:      brokenSymlinkMode,
 		newCache:               newCache,
 		newIgnoreCache:         newIgnoreCache,
 		copyBuffer:             make([]byte, scannerCopyBufferSize),
 		deviceID:               metadata.DeviceID,
 		recomposeUnicode:       decomposesUnicode,
+		filenames:              options.Filenames,
 		preservesExecutability: preservesExecutability,
+		maximumFileSize:        options.MaximumFileSize,
+		contentTypeMode:        contentTypeMode,
+		lineEndings:            options.LineEndings,
+		openFiles:              options.OpenFiles,
+		captureACLs:            options.ACLMode == ACLMode_ACLModePropagate,
+		preserveHardLinks:      options.PreserveHardLinks,
+		preserveMacOSMetadata:  options.PreserveMacOSMetadata,
+		preserveFileFlags:      options.PreserveFileFlags,
 	}
 
-	// Handle the scan based on the root type.
+	// If we're computing digests concurrently, then start the digest workers.
+	if len(options.DigestHashers) > 1 {
+		s.startDigestWorkers(options.DigestHashers)
+	}
+
+	// Handle the scan based on the root type. If the root is a file that
+	// exceeds the maximum file size, is excluded by the content type mode, or
+	// is open for writing, then it's treated as non-existent. Once complete, wait for any digest
+	// workers to finish populating digests.
 	var result *Entry
 	if rootKind == EntryKind_Directory {
 		result, err = s.directory("", nil, metadata, directoryRoot, baseline)
 	} else if rootKind == EntryKind_File {
-		result, err = s.file("", nil, metadata, fileRoot)
+		if !s.exceedsMaximumFileSize("", metadata) && !s.openForWriting("") {
+			var contentType ContentType
+			var excluded bool
+			if contentType, excluded, err = s.contentType("", nil, metadata, fileRoot); err == nil && !excluded {
+				result, err = s.file("", nil, metadata, fileRoot, contentType)
+			}
+		}
 	} else {
 		panic("unhandled root kind")
 	}
+	if digestErr := s.stopDigestWorkers(); err == nil {
+		err = digestErr
+	}
 	if err != nil {
-		return nil, false, false, nil, nil, err
+		return nil, false, false, nil, nil, nil, err
 	}
 
 	// If we have a baseline, then backfill the ignore and digest caches to
@@ -727,10 +1491,32 @@ func Scan(
 
 		// Abort if we encountered missing cache entries.
 		if missingCacheEntries {
-			return nil, false, false, nil, nil, errors.New("old cache entries don't correspond to baseline")
+			return nil, false, false, nil, nil, nil, errors.New("old cache entries don't correspond to baseline")
 		}
+
+		// Propagate problems describing skipped files that weren't revisited.
+		// A skipped file is only revisited if its parent directory was
+		// rescanned, which only occurs if the parent directory is dirty. We
+		// also propagate any cache entries for these files (which exist for
+		// files excluded by content type) so that they needn't be sniffed
+		// again once revisited.
+		for _, problem := range options.BaselineSkipped {
+			if problem.Path != "" && !dirtyPaths[pathDir(problem.Path)] {
+				s.skipped = append(s.skipped, problem)
+				if oldCacheEntry, ok := cache.Entries[problem.Path]; ok {
+					newCache.Entries[problem.Path] = oldCacheEntry
+				}
+			}
+		}
+	}
+
+	// If we're preserving hard links, then record the hard link structure of
+	// the result. This has to be done after cache propagation since it relies
+	// on file IDs from the cache.
+	if options.PreserveHardLinks && result != nil && rootKind == EntryKind_Directory {
+		result = s.linkHardLinks(result)
 	}
 
 	// Success.
-	return result, preservesExecutability, decomposesUnicode, newCache, newIgnoreCache, nil
+	return result, preservesExecutability, decomposesUnicode, newCache, newIgnoreCache, s.skipped, nil
 }
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha1"
 	"fmt"
@@ -9,7 +10,10 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -44,7 +48,7 @@ func testCreateScanCycle(temporaryDirectory string, entry *Entry, contentMap map
 	hasher := newTestHasher()
 
 	// Perform a scan.
-	snapshot, preservesExecutability, decomposesUnicode, cache, ignoreCache, err := Scan(
+	snapshot, preservesExecutability, decomposesUnicode, cache, ignoreCache, _, err := Scan(
 		context.Background(),
 		root,
 		nil, nil,
@@ -52,6 +56,10 @@ func testCreateScanCycle(temporaryDirectory string, entry *Entry, contentMap map
 		ignores, nil,
 		behavior.ProbeMode_ProbeModeProbe,
 		symlinkMode,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
 	)
 	if !preservesExecutability {
 		snapshot = PropagateExecutability(nil, entry, snapshot)
@@ -68,7 +76,7 @@ func testCreateScanCycle(temporaryDirectory string, entry *Entry, contentMap map
 
 	// Perform an accelerated scan (with a re-check path) using the snapshot as
 	// a baseline.
-	newSnapshot, newPreservesExecutability, newDecomposesUnicode, newCache, newIgnoreCache, err := Scan(
+	newSnapshot, newPreservesExecutability, newDecomposesUnicode, newCache, newIgnoreCache, _, err := Scan(
 		context.Background(),
 		root,
 		snapshot, map[string]bool{"fake path": true},
@@ -76,6 +84,10 @@ func testCreateScanCycle(temporaryDirectory string, entry *Entry, contentMap map
 		ignores, ignoreCache,
 		behavior.ProbeMode_ProbeModeProbe,
 		symlinkMode,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
 	)
 	if !newPreservesExecutability {
 		newSnapshot = PropagateExecutability(nil, entry, newSnapshot)
@@ -106,7 +118,7 @@ func testCreateScanCycle(temporaryDirectory string, entry *Entry, contentMap map
 
 	// Perform an accelerated scan (without any re-check paths) using the
 	// snapshot as a baseline.
-	newSnapshot, newPreservesExecutability, newDecomposesUnicode, newCache, newIgnoreCache, err = Scan(
+	newSnapshot, newPreservesExecutability, newDecomposesUnicode, newCache, newIgnoreCache, _, err = Scan(
 		context.Background(),
 		root,
 		snapshot, nil,
@@ -114,6 +126,10 @@ func testCreateScanCycle(temporaryDirectory string, entry *Entry, contentMap map
 		ignores, ignoreCache,
 		behavior.ProbeMode_ProbeModeProbe,
 		symlinkMode,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
 	)
 	if !newPreservesExecutability {
 		newSnapshot = PropagateExecutability(nil, entry, newSnapshot)
@@ -352,7 +368,7 @@ func TestScanSymlinkRoot(t *testing.T) {
 	}
 
 	// Attempt a scan of the symlink.
-	if _, _, _, _, _, err := Scan(
+	if _, _, _, _, _, _, err := Scan(
 		context.Background(),
 		root,
 		nil,
@@ -363,6 +379,10 @@ func TestScanSymlinkRoot(t *testing.T) {
 		nil,
 		behavior.ProbeMode_ProbeModeProbe,
 		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
 	); err == nil {
 		t.Error("scan of symlink root allowed")
 	}
@@ -396,7 +416,7 @@ func TestEfficientRescan(t *testing.T) {
 	hasher := newTestHasher()
 
 	// Create an initial snapshot and validate the results.
-	snapshot, preservesExecutability, _, cache, _, err := Scan(
+	snapshot, preservesExecutability, _, cache, _, _, err := Scan(
 		context.Background(),
 		root,
 		nil,
@@ -407,6 +427,10 @@ func TestEfficientRescan(t *testing.T) {
 		nil,
 		behavior.ProbeMode_ProbeModeProbe,
 		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
 	)
 	if !preservesExecutability {
 		snapshot = PropagateExecutability(nil, testDirectory1Entry, snapshot)
@@ -424,7 +448,7 @@ func TestEfficientRescan(t *testing.T) {
 
 	// Attempt a rescan and ensure that no hashing occurs.
 	hasher = &rescanHashProxy{hasher, t}
-	snapshot, preservesExecutability, _, cache, _, err = Scan(
+	snapshot, preservesExecutability, _, cache, _, _, err = Scan(
 		context.Background(),
 		root,
 		nil,
@@ -435,6 +459,10 @@ func TestEfficientRescan(t *testing.T) {
 		nil,
 		behavior.ProbeMode_ProbeModeProbe,
 		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
 	)
 	if !preservesExecutability {
 		snapshot = PropagateExecutability(nil, testDirectory1Entry, snapshot)
@@ -476,7 +504,7 @@ func TestScanCrossDeviceFail(t *testing.T) {
 	hasher := newTestHasher()
 
 	// Perform a scan and ensure that it fails.
-	if _, _, _, _, _, err := Scan(
+	if _, _, _, _, _, _, err := Scan(
 		context.Background(),
 		parent,
 		nil,
@@ -487,7 +515,813 @@ func TestScanCrossDeviceFail(t *testing.T) {
 		nil,
 		behavior.ProbeMode_ProbeModeProbe,
 		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
 	); err == nil {
 		t.Error("scan across device boundary did not fail")
 	}
 }
+
+// createMaximumFileSizeTestContent creates content for maximum file size tests
+// in a temporary directory and returns the root path.
+func createMaximumFileSizeTestContent(t *testing.T) string {
+	// Create content that straddles a maximum file size of 10 bytes.
+	return testCreateContent(t, map[string][]byte{
+		"small":        make([]byte, 5),
+		"exact":        make([]byte, 10),
+		"large":        make([]byte, 11),
+		"sub/small":    make([]byte, 1),
+		"sub/large":    make([]byte, 100),
+		"other/medium": make([]byte, 7),
+	}, nil)
+}
+
+// verifySkippedFileProblems verifies that a list of skipped file problems
+// contains exactly the specified paths.
+func verifySkippedFileProblems(t *testing.T, skipped []*Problem, expected []string) {
+	if len(skipped) != len(expected) {
+		t.Fatal("skipped file count incorrect:", len(skipped), "!=", len(expected))
+	}
+	paths := make(map[string]bool, len(skipped))
+	for _, problem := range skipped {
+		if err := problem.EnsureValid(); err != nil {
+			t.Error("invalid skipped file problem:", err)
+		}
+		paths[problem.Path] = true
+	}
+	for _, path := range expected {
+		if !paths[path] {
+			t.Error("skipped file problem missing for path:", path)
+		}
+	}
+}
+
+// TestScanMaximumFileSize tests that files exceeding the maximum file size are
+// excluded from scans and reported as skipped.
+func TestScanMaximumFileSize(t *testing.T) {
+	// Create test content and defer its removal.
+	root := createMaximumFileSizeTestContent(t)
+	defer os.RemoveAll(root)
+
+	// Perform a scan.
+	snapshot, _, _, _, _, skipped, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		newTestHasher(),
+		nil,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			MaximumFileSize:   10,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	}
+
+	// Verify that only files within the limit are present in the snapshot.
+	testCases := []struct {
+		path    string
+		present bool
+	}{
+		{"small", true},
+		{"exact", true},
+		{"large", false},
+		{"sub/small", true},
+		{"sub/large", false},
+		{"other/medium", true},
+	}
+	for _, testCase := range testCases {
+		entry := snapshot
+		for _, component := range strings.Split(testCase.path, "/") {
+			if entry != nil {
+				entry = entry.Contents[component]
+			}
+		}
+		if present := entry != nil; present != testCase.present {
+			t.Error("presence incorrect for path:", testCase.path, present, "!=", testCase.present)
+		}
+	}
+
+	// Verify the skipped file problems.
+	verifySkippedFileProblems(t, skipped, []string{"large", "sub/large"})
+
+	// Perform a scan without a maximum file size and verify that nothing is
+	// skipped.
+	if _, _, _, _, _, skipped, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		newTestHasher(),
+		nil,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	); err != nil {
+		t.Fatal("unable to perform unlimited scan:", err)
+	} else if len(skipped) != 0 {
+		t.Error("files skipped without maximum file size:", len(skipped))
+	}
+}
+
+// TestScanMaximumFileSizeAccelerated tests that skipped file problems are
+// preserved across accelerated rescans.
+func TestScanMaximumFileSizeAccelerated(t *testing.T) {
+	// Create test content and defer its removal.
+	root := createMaximumFileSizeTestContent(t)
+	defer os.RemoveAll(root)
+
+	// Perform a baseline scan.
+	hasher := newTestHasher()
+	baseline, _, _, cache, ignoreCache, baselineSkipped, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		hasher,
+		nil,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			MaximumFileSize:   10,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform baseline scan:", err)
+	}
+	verifySkippedFileProblems(t, baselineSkipped, []string{"large", "sub/large"})
+
+	// Set up test cases.
+	testCases := []struct {
+		recheckPaths map[string]bool
+	}{
+		{nil},
+		{map[string]bool{"other/medium": true}},
+		{map[string]bool{"sub/small": true}},
+		{map[string]bool{"sub/large": true}},
+	}
+
+	// Perform accelerated rescans and verify that the skipped file problems
+	// are neither lost nor duplicated.
+	for _, testCase := range testCases {
+		_, _, _, _, _, skipped, err := Scan(
+			context.Background(),
+			root,
+			baseline,
+			testCase.recheckPaths,
+			hasher,
+			cache,
+			nil,
+			ignoreCache,
+			behavior.ProbeMode_ProbeModeProbe,
+			SymlinkMode_SymlinkModePortable,
+			&ScanOptions{
+				BaselineSkipped:   baselineSkipped,
+				BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+				MaximumFileSize:   10,
+				ACLMode:           ACLMode_ACLModeIgnore,
+			},
+		)
+		if err != nil {
+			t.Fatal("unable to perform accelerated scan:", err)
+		}
+		verifySkippedFileProblems(t, skipped, []string{"large", "sub/large"})
+	}
+}
+
+// createContentTypeTestContent creates content for content type tests in a
+// temporary directory and returns the root path.
+func createContentTypeTestContent(t *testing.T) string {
+	// Create text and binary content, including binary content whose first
+	// NUL byte lies beyond the sniffed range (and is thus treated as text).
+	return testCreateContent(t, map[string][]byte{
+		"text":            []byte("Hello, world!\n"),
+		"empty":           nil,
+		"binary":          {0x7f, 'E', 'L', 'F', 0, 0, 0, 1},
+		"sub/text":        []byte("package main\n"),
+		"sub/binary":      []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"),
+		"sub/late-binary": append(bytes.Repeat([]byte("a"), contentTypeSniffSize), 0),
+	}, nil)
+}
+
+// scanContentTypeTestContent performs a scan of content type test content
+// using the specified content type mode, cache, and digest hashers.
+func scanContentTypeTestContent(
+	t *testing.T,
+	root string,
+	contentTypeMode ContentTypeMode,
+	cache *Cache,
+	digestHashers []hash.Hash,
+) (*Entry, *Cache, []*Problem) {
+	snapshot, _, _, newCache, _, skipped, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		newTestHasher(),
+		cache,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ContentTypeMode:   contentTypeMode,
+			ACLMode:           ACLMode_ACLModeIgnore,
+			DigestHashers:     digestHashers,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	} else if err = snapshot.EnsureValid(); err != nil {
+		t.Fatal("scan produced invalid snapshot:", err)
+	} else if err = newCache.EnsureValid(); err != nil {
+		t.Fatal("scan produced invalid cache:", err)
+	}
+	return snapshot, newCache, skipped
+}
+
+// TestScanOpenFiles tests that files open for writing are excluded from scans
+// and reported as skipped, and that they're included once they're no longer
+// open.
+func TestScanOpenFiles(t *testing.T) {
+	// Create test content and defer its removal.
+	root := createMaximumFileSizeTestContent(t)
+	defer os.RemoveAll(root)
+
+	// Perform a scan with some files marked as open for writing.
+	openFiles := map[string]bool{"small": true, "sub/large": true}
+	snapshot, _, _, _, _, skipped, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		newTestHasher(),
+		nil,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+			OpenFiles:         openFiles,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	}
+
+	// Verify that open files are absent from the snapshot and reported as
+	// skipped.
+	if snapshot.Contents["small"] != nil {
+		t.Error("open file present in snapshot")
+	} else if snapshot.Contents["sub"].Contents["large"] != nil {
+		t.Error("open subfile present in snapshot")
+	} else if snapshot.Contents["exact"] == nil {
+		t.Error("closed file missing from snapshot")
+	}
+	verifySkippedFileProblems(t, skipped, []string{"small", "sub/large"})
+	for _, problem := range skipped {
+		if problem.Error != OpenFileSkippedError {
+			t.Error("skipped file problem has incorrect error:", problem.Error)
+		}
+	}
+
+	// Perform a scan once the files have been closed and verify that nothing
+	// is skipped.
+	if snapshot, _, _, _, _, skipped, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		newTestHasher(),
+		nil,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+			OpenFiles:         map[string]bool{},
+		},
+	); err != nil {
+		t.Fatal("unable to perform scan after closure:", err)
+	} else if len(skipped) != 0 {
+		t.Error("files skipped after closure:", len(skipped))
+	} else if snapshot.Contents["small"] == nil {
+		t.Error("closed file missing from snapshot")
+	}
+}
+
+// TestScanContentType tests that files are classified by content type and that
+// files excluded by the content type mode are excluded from scans, reported as
+// skipped, and recorded in the cache without a digest.
+func TestScanContentType(t *testing.T) {
+	// Create test content and defer its removal.
+	root := createContentTypeTestContent(t)
+	defer os.RemoveAll(root)
+
+	// Set up the expected classifications.
+	text := []string{"text", "empty", "sub/text", "sub/late-binary"}
+	binary := []string{"binary", "sub/binary"}
+
+	// Set up test cases.
+	testCases := []struct {
+		mode          ContentTypeMode
+		digestHashers []hash.Hash
+		included      []string
+		excluded      []string
+	}{
+		{ContentTypeMode_ContentTypeModeDefault, nil, append(text, binary...), nil},
+		{ContentTypeMode_ContentTypeModeAll, nil, append(text, binary...), nil},
+		{ContentTypeMode_ContentTypeModeText, nil, text, binary},
+		{ContentTypeMode_ContentTypeModeBinary, nil, binary, text},
+		{ContentTypeMode_ContentTypeModeText, []hash.Hash{newTestHasher(), newTestHasher()}, text, binary},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		snapshot, cache, skipped := scanContentTypeTestContent(t, root, testCase.mode, nil, testCase.digestHashers)
+
+		// Determine whether or not classifications should be recorded.
+		classified := testCase.mode == ContentTypeMode_ContentTypeModeText ||
+			testCase.mode == ContentTypeMode_ContentTypeModeBinary
+
+		// Verify included files.
+		for _, path := range testCase.included {
+			entry := snapshot
+			for _, component := range strings.Split(path, "/") {
+				if entry != nil {
+					entry = entry.Contents[component]
+				}
+			}
+			if entry == nil {
+				t.Errorf("test case %d: included file missing from snapshot: %s", i, path)
+			} else if cacheEntry := cache.Entries[path]; cacheEntry == nil {
+				t.Errorf("test case %d: included file missing from cache: %s", i, path)
+			} else if !bytes.Equal(cacheEntry.Digest, entry.Digest) {
+				t.Errorf("test case %d: cached digest mismatch for included file: %s", i, path)
+			} else if classified && !testCase.mode.includes(cacheEntry.ContentType) {
+				t.Errorf("test case %d: included file has incorrect content type: %s (%s)",
+					i, path, cacheEntry.ContentType,
+				)
+			} else if !classified && cacheEntry.ContentType != ContentType_ContentTypeUnknown {
+				t.Errorf("test case %d: content type recorded without filtering: %s", i, path)
+			}
+		}
+
+		// Verify excluded files.
+		for _, path := range testCase.excluded {
+			entry := snapshot
+			for _, component := range strings.Split(path, "/") {
+				if entry != nil {
+					entry = entry.Contents[component]
+				}
+			}
+			if entry != nil {
+				t.Errorf("test case %d: excluded file present in snapshot: %s", i, path)
+			} else if cacheEntry := cache.Entries[path]; cacheEntry == nil {
+				t.Errorf("test case %d: excluded file missing from cache: %s", i, path)
+			} else if cacheEntry.Digest != nil {
+				t.Errorf("test case %d: excluded file has cached digest: %s", i, path)
+			} else if testCase.mode.includes(cacheEntry.ContentType) {
+				t.Errorf("test case %d: excluded file has incorrect content type: %s (%s)",
+					i, path, cacheEntry.ContentType,
+				)
+			}
+		}
+
+		// Verify the skipped file problems.
+		verifySkippedFileProblems(t, skipped, testCase.excluded)
+
+		// Verify that the cache can still be used for reverse lookups.
+		if _, err := cache.GenerateReverseLookupMap(); err != nil {
+			t.Errorf("test case %d: unable to generate reverse lookup map: %v", i, err)
+		}
+	}
+}
+
+// TestScanContentTypeCached tests that cached content type classifications are
+// used for unmodified files and that classifications are updated for modified
+// files.
+func TestScanContentTypeCached(t *testing.T) {
+	// Create test content and defer its removal.
+	root := createContentTypeTestContent(t)
+	defer os.RemoveAll(root)
+
+	// Perform an initial scan.
+	_, cache, _ := scanContentTypeTestContent(t, root, ContentTypeMode_ContentTypeModeText, nil, nil)
+
+	// Overwrite the binary file with text content of the same size in-place,
+	// preserving its modification time, so that it's indistinguishable from
+	// the cached version.
+	path := filepath.Join(root, "binary")
+	metadata, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal("unable to query file metadata:", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal("unable to open file:", err)
+	} else if _, err = file.Write([]byte("plaintxt")); err != nil {
+		file.Close()
+		t.Fatal("unable to write file:", err)
+	} else if err = file.Close(); err != nil {
+		t.Fatal("unable to close file:", err)
+	} else if err = os.Chtimes(path, metadata.ModTime(), metadata.ModTime()); err != nil {
+		t.Fatal("unable to reset modification time:", err)
+	}
+
+	// Verify that the cached classification is used.
+	snapshot, cache, skipped := scanContentTypeTestContent(t, root, ContentTypeMode_ContentTypeModeText, cache, nil)
+	if snapshot.Contents["binary"] != nil {
+		t.Error("cached content type not used for unmodified file")
+	}
+	verifySkippedFileProblems(t, skipped, []string{"binary", "sub/binary"})
+
+	// Verify that the file is reclassified without the cache.
+	snapshot, _, skipped = scanContentTypeTestContent(t, root, ContentTypeMode_ContentTypeModeText, nil, nil)
+	if snapshot.Contents["binary"] == nil {
+		t.Error("modified file not reclassified")
+	}
+	verifySkippedFileProblems(t, skipped, []string{"sub/binary"})
+
+	// Verify that the cached entries for excluded files are reclassified (and
+	// digested) when filtering is disabled.
+	snapshot, cache, skipped = scanContentTypeTestContent(t, root, ContentTypeMode_ContentTypeModeAll, cache, nil)
+	if entry := snapshot.Contents["sub"].Contents["binary"]; entry == nil {
+		t.Error("previously excluded file missing from snapshot")
+	} else if len(entry.Digest) == 0 {
+		t.Error("previously excluded file has empty digest")
+	} else if !bytes.Equal(cache.Entries["sub/binary"].Digest, entry.Digest) {
+		t.Error("previously excluded file has incorrect cached digest")
+	}
+	verifySkippedFileProblems(t, skipped, nil)
+}
+
+// TestScanContentTypeAccelerated tests that skipped file problems and cache
+// entries for files excluded by content type are preserved across accelerated
+// rescans.
+func TestScanContentTypeAccelerated(t *testing.T) {
+	// Create test content and defer its removal.
+	root := createContentTypeTestContent(t)
+	defer os.RemoveAll(root)
+
+	// Perform a baseline scan.
+	hasher := newTestHasher()
+	baseline, _, _, cache, ignoreCache, baselineSkipped, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		hasher,
+		nil,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ContentTypeMode:   ContentTypeMode_ContentTypeModeText,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform baseline scan:", err)
+	}
+	verifySkippedFileProblems(t, baselineSkipped, []string{"binary", "sub/binary"})
+
+	// Set up test cases.
+	testCases := []struct {
+		recheckPaths map[string]bool
+	}{
+		{nil},
+		{map[string]bool{"text": true}},
+		{map[string]bool{"sub/text": true}},
+		{map[string]bool{"sub/binary": true}},
+	}
+
+	// Perform accelerated rescans and verify that the skipped file problems
+	// and cache entries are neither lost nor duplicated.
+	for i, testCase := range testCases {
+		_, _, _, newCache, _, skipped, err := Scan(
+			context.Background(),
+			root,
+			baseline,
+			testCase.recheckPaths,
+			hasher,
+			cache,
+			nil,
+			ignoreCache,
+			behavior.ProbeMode_ProbeModeProbe,
+			SymlinkMode_SymlinkModePortable,
+			&ScanOptions{
+				BaselineSkipped:   baselineSkipped,
+				BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+				ContentTypeMode:   ContentTypeMode_ContentTypeModeText,
+				ACLMode:           ACLMode_ACLModeIgnore,
+			},
+		)
+		if err != nil {
+			t.Fatal("unable to perform accelerated scan:", err)
+		}
+		verifySkippedFileProblems(t, skipped, []string{"binary", "sub/binary"})
+		if !newCache.Equal(cache) {
+			t.Errorf("test case %d: accelerated cache does not match baseline cache", i)
+		}
+	}
+}
+
+// TestScanContentTypeFileRoot tests that a file root excluded by the content
+// type mode is treated as non-existent.
+func TestScanContentTypeFileRoot(t *testing.T) {
+	// Create test content and defer its removal.
+	root := createContentTypeTestContent(t)
+	defer os.RemoveAll(root)
+
+	// Perform scans of text and binary file roots.
+	for _, path := range []string{"text", "binary"} {
+		for _, mode := range []ContentTypeMode{ContentTypeMode_ContentTypeModeText, ContentTypeMode_ContentTypeModeBinary} {
+			snapshot, _, skipped := scanContentTypeTestContent(t, filepath.Join(root, path), mode, nil, nil)
+			excluded := (path == "text") != (mode == ContentTypeMode_ContentTypeModeText)
+			if excluded && snapshot != nil {
+				t.Errorf("excluded file root (%s) present in snapshot with mode %s", path, mode)
+			} else if !excluded && (snapshot == nil || snapshot.Kind != EntryKind_File) {
+				t.Errorf("included file root (%s) missing from snapshot with mode %s", path, mode)
+			} else if excluded {
+				verifySkippedFileProblems(t, skipped, []string{""})
+			} else {
+				verifySkippedFileProblems(t, skipped, nil)
+			}
+		}
+	}
+}
+
+// testConcurrencyTracker tracks the number of concurrently active operations
+// and the maximum number observed.
+type testConcurrencyTracker struct {
+	// lock serializes access to active and maximum.
+	lock sync.Mutex
+	// active is the number of currently active operations.
+	active int
+	// maximum is the maximum number of concurrently active operations.
+	maximum int
+}
+
+// enter records the start of an operation.
+func (t *testConcurrencyTracker) enter() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.active++
+	if t.active > t.maximum {
+		t.maximum = t.active
+	}
+}
+
+// exit records the end of an operation.
+func (t *testConcurrencyTracker) exit() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.active--
+}
+
+// testTrackingHasher is a hash.Hash implementation that records each digest
+// computation (from Reset to Sum) as an operation with a concurrency tracker.
+// It artificially delays digest completion to encourage overlap.
+type testTrackingHasher struct {
+	hash.Hash
+	// tracker is the concurrency tracker.
+	tracker *testConcurrencyTracker
+}
+
+// Reset implements hash.Hash.Reset.
+func (h *testTrackingHasher) Reset() {
+	h.tracker.enter()
+	h.Hash.Reset()
+}
+
+// Sum implements hash.Hash.Sum.
+func (h *testTrackingHasher) Sum(b []byte) []byte {
+	time.Sleep(5 * time.Millisecond)
+	h.tracker.exit()
+	return h.Hash.Sum(b)
+}
+
+// TestScanLineEndings tests that files matched by a line ending matcher have
+// digests computed over their content with canonicalized line endings, both
+// serially and concurrently, and that other files don't.
+func TestScanLineEndings(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	root, err := ioutil.TempDir("", "mutagen_scan_line_endings")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(root)
+
+	// Create files with differing line endings.
+	contents := map[string]string{
+		"lf.txt":   "one\ntwo\n",
+		"crlf.txt": "one\r\ntwo\r\n",
+		"crlf.dat": "one\r\ntwo\r\n",
+	}
+	for name, content := range contents {
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0600); err != nil {
+			t.Fatal("unable to create test file:", err)
+		}
+	}
+
+	// Compute expected digests.
+	canonical := sha1.Sum([]byte("one\ntwo\n"))
+	raw := sha1.Sum([]byte("one\r\ntwo\r\n"))
+	expected := map[string][]byte{
+		"lf.txt":   canonical[:],
+		"crlf.txt": canonical[:],
+		"crlf.dat": raw[:],
+	}
+
+	// Create the matcher.
+	matcher, err := NewLineEndingMatcher([]string{"*.txt"})
+	if err != nil {
+		t.Fatal("unable to create line ending matcher:", err)
+	}
+
+	// Perform scans with serial and concurrent digest computation.
+	for _, digestHashers := range [][]hash.Hash{nil, {newTestHasher(), newTestHasher()}} {
+		snapshot, _, _, _, _, _, err := Scan(
+			context.Background(),
+			root,
+			nil,
+			nil,
+			newTestHasher(),
+			nil,
+			nil,
+			nil,
+			behavior.ProbeMode_ProbeModeProbe,
+			SymlinkMode_SymlinkModePortable,
+			&ScanOptions{
+				BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+				LineEndings:       matcher,
+				ACLMode:           ACLMode_ACLModeIgnore,
+				DigestHashers:     digestHashers,
+			},
+		)
+		if err != nil {
+			t.Fatal("unable to perform scan:", err)
+		}
+		for name, digest := range expected {
+			if entry := snapshot.Contents[name]; entry == nil {
+				t.Error("file missing from snapshot:", name)
+			} else if !bytes.Equal(entry.Digest, digest) {
+				t.Errorf("digest mismatch for %s (%d digest hashers)", name, len(digestHashers))
+			}
+		}
+	}
+}
+
+// TestScanDigestConcurrency tests that scans with multiple digest hashers
+// compute digests concurrently without exceeding the number of hashers and
+// that they produce the same results as serial scans.
+func TestScanDigestConcurrency(t *testing.T) {
+	// Create a temporary directory with test content and defer its removal.
+	root, err := ioutil.TempDir("", "mutagen_scan_concurrency")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(root)
+	for i := 0; i < 32; i++ {
+		directory := filepath.Join(root, fmt.Sprintf("directory%d", i%4))
+		if err := os.MkdirAll(directory, 0700); err != nil {
+			t.Fatal("unable to create directory:", err)
+		}
+		content := []byte(fmt.Sprintf("content %d", i))
+		if err := ioutil.WriteFile(filepath.Join(directory, fmt.Sprintf("file%d", i)), content, 0600); err != nil {
+			t.Fatal("unable to create file:", err)
+		}
+	}
+
+	// Perform a serial scan to establish the expected results.
+	expected, _, _, expectedCache, _, _, err := Scan(
+		context.Background(),
+		root,
+		nil, nil,
+		newTestHasher(), nil,
+		nil, nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform serial scan:", err)
+	}
+
+	// Perform concurrent scans with varying numbers of hashers.
+	for _, concurrency := range []int{2, 4} {
+		// Create tracking hashers.
+		tracker := &testConcurrencyTracker{}
+		hashers := make([]hash.Hash, concurrency)
+		for h := range hashers {
+			hashers[h] = &testTrackingHasher{newTestHasher(), tracker}
+		}
+
+		// Perform the scan.
+		snapshot, _, _, cache, _, _, err := Scan(
+			context.Background(),
+			root,
+			nil, nil,
+			newTestHasher(), nil,
+			nil, nil,
+			behavior.ProbeMode_ProbeModeProbe,
+			SymlinkMode_SymlinkModePortable,
+			&ScanOptions{
+				BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+				ACLMode:           ACLMode_ACLModeIgnore,
+				DigestHashers:     hashers,
+			},
+		)
+		if err != nil {
+			t.Fatalf("unable to perform scan with concurrency %d: %v", concurrency, err)
+		}
+
+		// Verify results.
+		if !snapshot.Equal(expected) {
+			t.Errorf("snapshot with concurrency %d does not match serial snapshot", concurrency)
+		}
+		if len(cache.Entries) != len(expectedCache.Entries) {
+			t.Errorf("cache with concurrency %d has incorrect size", concurrency)
+		}
+		for path, entry := range cache.Entries {
+			if expectedEntry, ok := expectedCache.Entries[path]; !ok {
+				t.Errorf("unexpected cache entry with concurrency %d: %s", concurrency, path)
+			} else if !bytes.Equal(entry.Digest, expectedEntry.Digest) {
+				t.Errorf("cache digest with concurrency %d incorrect for %s", concurrency, path)
+			}
+		}
+
+		// Verify that the concurrency limit was respected and that digests
+		// were actually computed concurrently.
+		if tracker.maximum > concurrency {
+			t.Errorf("digest concurrency exceeded limit: %d > %d", tracker.maximum, concurrency)
+		} else if tracker.maximum < 2 {
+			t.Errorf("digests not computed concurrently with concurrency %d", concurrency)
+		}
+	}
+}
+
+// TestScanDigestConcurrencyCancellation tests that concurrent digest workers
+// respect scan cancellation.
+func TestScanDigestConcurrencyCancellation(t *testing.T) {
+	// Create a temporary directory with test content and defer its removal.
+	root, err := ioutil.TempDir("", "mutagen_scan_concurrency")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(root)
+	for i := 0; i < 8; i++ {
+		if err := ioutil.WriteFile(filepath.Join(root, fmt.Sprintf("file%d", i)), []byte("content"), 0600); err != nil {
+			t.Fatal("unable to create file:", err)
+		}
+	}
+
+	// Perform a scan with a cancelled context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, _, _, _, _, err := Scan(
+		ctx,
+		root,
+		nil, nil,
+		newTestHasher(), nil,
+		nil, nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+			DigestHashers:     []hash.Hash{newTestHasher(), newTestHasher()},
+		},
+	); err == nil {
+		t.Error("cancelled scan succeeded")
+	}
+}
@@ -0,0 +1,179 @@
+package core
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// EnsureValid ensures that SnapshotDiff's invariants are respected.
+func (d *SnapshotDiff) EnsureValid() error {
+	// A nil snapshot diff is not valid.
+	if d == nil {
+		return errors.New("nil snapshot diff")
+	}
+
+	// Ensure that all records are present and have entries.
+	for _, added := range d.Added {
+		if added == nil || added.Entry == nil {
+			return errors.New("invalid added entry")
+		}
+	}
+	for _, removed := range d.Removed {
+		if removed == nil || removed.Entry == nil {
+			return errors.New("invalid removed entry")
+		}
+	}
+	for _, modified := range d.Modified {
+		if modified == nil || modified.Old == nil || modified.New == nil {
+			return errors.New("invalid modified entry")
+		}
+	}
+	for _, moved := range d.Moved {
+		if moved == nil || moved.Old == nil || moved.New == nil {
+			return errors.New("invalid moved entry")
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// KindChanged indicates whether or not the entry kind changed.
+func (m *SnapshotModification) KindChanged() bool {
+	return m.Old.Kind != m.New.Kind
+}
+
+// DigestChanged indicates whether or not the entry digest changed.
+func (m *SnapshotModification) DigestChanged() bool {
+	return !bytes.Equal(m.Old.Digest, m.New.Digest)
+}
+
+// ExecutabilityChanged indicates whether or not the entry executability
+// changed.
+func (m *SnapshotModification) ExecutabilityChanged() bool {
+	return m.Old.Executable != m.New.Executable
+}
+
+// TargetChanged indicates whether or not the entry's symbolic link target
+// changed.
+func (m *SnapshotModification) TargetChanged() bool {
+	return m.Old.Target != m.New.Target
+}
+
+// flattenSnapshot creates a map of paths to (slim) entries for a snapshot.
+func flattenSnapshot(snapshot *Entry) map[string]*Entry {
+	result := make(map[string]*Entry)
+	snapshot.walk("", func(path string, entry *Entry) {
+		if entry != nil {
+			result[path] = entry.copySlim()
+		}
+	})
+	return result
+}
+
+// DiffSnapshots computes a structured diff from the base snapshot to the target
+// snapshot. Either snapshot may be nil. If detectMoves is true, then removed
+// and added files with identical digests are paired and reported as moves.
+// Where multiple candidates exist for a given digest, they are paired in path
+// order.
+func DiffSnapshots(base, target *Entry, detectMoves bool) *SnapshotDiff {
+	// Flatten both snapshots.
+	baseEntries := flattenSnapshot(base)
+	targetEntries := flattenSnapshot(target)
+
+	// Classify paths.
+	result := &SnapshotDiff{}
+	for path, baseEntry := range baseEntries {
+		if _, ok := targetEntries[path]; !ok {
+			result.Removed = append(result.Removed, &SnapshotEntry{Path: path, Entry: baseEntry})
+		}
+	}
+	for path, targetEntry := range targetEntries {
+		if baseEntry, ok := baseEntries[path]; !ok {
+			result.Added = append(result.Added, &SnapshotEntry{Path: path, Entry: targetEntry})
+		} else if !baseEntry.equalShallow(targetEntry) {
+			result.Modified = append(result.Modified, &SnapshotModification{
+				Path: path,
+				Old:  baseEntry,
+				New:  targetEntry,
+			})
+		}
+	}
+
+	// Sort the results by path.
+	sort.Slice(result.Added, func(i, j int) bool {
+		return result.Added[i].Path < result.Added[j].Path
+	})
+	sort.Slice(result.Removed, func(i, j int) bool {
+		return result.Removed[i].Path < result.Removed[j].Path
+	})
+	sort.Slice(result.Modified, func(i, j int) bool {
+		return result.Modified[i].Path < result.Modified[j].Path
+	})
+
+	// Perform move detection, if requested.
+	if detectMoves {
+		result.detectMoves()
+	}
+
+	// Done.
+	return result
+}
+
+// detectMoves pairs removed and added files with identical digests, moving
+// them into the list of moves.
+func (d *SnapshotDiff) detectMoves() {
+	// Index removed files by digest, preserving path order.
+	removedByDigest := make(map[string][]int)
+	for r, removed := range d.Removed {
+		if removed.Entry.Kind == EntryKind_File {
+			digest := string(removed.Entry.Digest)
+			removedByDigest[digest] = append(removedByDigest[digest], r)
+		}
+	}
+
+	// Pair added files with removed files, tracking which entries have been
+	// paired.
+	pairedRemovals := make(map[int]bool)
+	pairedAdditions := make(map[int]bool)
+	for a, added := range d.Added {
+		if added.Entry.Kind != EntryKind_File {
+			continue
+		}
+		digest := string(added.Entry.Digest)
+		candidates := removedByDigest[digest]
+		if len(candidates) == 0 {
+			continue
+		}
+		r := candidates[0]
+		removedByDigest[digest] = candidates[1:]
+		pairedRemovals[r] = true
+		pairedAdditions[a] = true
+		d.Moved = append(d.Moved, &SnapshotMove{
+			OldPath: d.Removed[r].Path,
+			NewPath: added.Path,
+			Old:     d.Removed[r].Entry,
+			New:     added.Entry,
+		})
+	}
+
+	// Filter out paired entries.
+	if len(d.Moved) > 0 {
+		var added []*SnapshotEntry
+		for a, entry := range d.Added {
+			if !pairedAdditions[a] {
+				added = append(added, entry)
+			}
+		}
+		d.Added = added
+		var removed []*SnapshotEntry
+		for r, entry := range d.Removed {
+			if !pairedRemovals[r] {
+				removed = append(removed, entry)
+			}
+		}
+		d.Removed = removed
+	}
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"testing"
+)
+
+// testSnapshotDiffBase is the base snapshot for snapshot diff tests.
+var testSnapshotDiffBase = &Entry{
+	Contents: map[string]*Entry{
+		"unchanged": {Kind: EntryKind_File, Digest: []byte{0}},
+		"modified":  {Kind: EntryKind_File, Digest: []byte{1}},
+		"chmod":     {Kind: EntryKind_File, Digest: []byte{2}},
+		"removed":   {Kind: EntryKind_File, Digest: []byte{3}},
+		"original":  {Kind: EntryKind_File, Digest: []byte{4}},
+		"link":      {Kind: EntryKind_Symlink, Target: "unchanged"},
+		"directory": {
+			Contents: map[string]*Entry{
+				"child": {Kind: EntryKind_File, Digest: []byte{5}},
+			},
+		},
+	},
+}
+
+// testSnapshotDiffTarget is the target snapshot for snapshot diff tests.
+var testSnapshotDiffTarget = &Entry{
+	Contents: map[string]*Entry{
+		"unchanged": {Kind: EntryKind_File, Digest: []byte{0}},
+		"modified":  {Kind: EntryKind_File, Digest: []byte{6}},
+		"chmod":     {Kind: EntryKind_File, Digest: []byte{2}, Executable: true},
+		"renamed":   {Kind: EntryKind_File, Digest: []byte{4}},
+		"link":      {Kind: EntryKind_Symlink, Target: "modified"},
+		"directory": {Kind: EntryKind_File, Digest: []byte{7}},
+		"added": {
+			Contents: map[string]*Entry{
+				"child": {Kind: EntryKind_File, Digest: []byte{8}},
+			},
+		},
+	},
+}
+
+// snapshotEntryPaths extracts the paths from a list of snapshot entries.
+func snapshotEntryPaths(entries []*SnapshotEntry) []string {
+	var result []string
+	for _, e := range entries {
+		result = append(result, e.Path)
+	}
+	return result
+}
+
+// pathListsEqual determines whether or not two (ordered) path lists are equal.
+func pathListsEqual(first, second []string) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	for p, path := range first {
+		if second[p] != path {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDiffSnapshotsWithoutMoveDetection(t *testing.T) {
+	// Compute the diff.
+	diff := DiffSnapshots(testSnapshotDiffBase, testSnapshotDiffTarget, false)
+
+	// Verify additions and removals. The rename should appear as a removal and
+	// an addition.
+	expectedAdded := []string{"added", "added/child", "renamed"}
+	if added := snapshotEntryPaths(diff.Added); !pathListsEqual(added, expectedAdded) {
+		t.Error("added paths do not match expected:", added, "!=", expectedAdded)
+	}
+	expectedRemoved := []string{"directory/child", "original", "removed"}
+	if removed := snapshotEntryPaths(diff.Removed); !pathListsEqual(removed, expectedRemoved) {
+		t.Error("removed paths do not match expected:", removed, "!=", expectedRemoved)
+	}
+	if len(diff.Moved) != 0 {
+		t.Error("moves detected with move detection disabled:", len(diff.Moved))
+	}
+
+	// Verify that added directories are recorded without their contents.
+	if diff.Added[0].Entry.Contents != nil {
+		t.Error("added directory recorded with contents")
+	}
+
+	// Define the expected modifications.
+	expectedModified := []struct {
+		path          string
+		kind          bool
+		digest        bool
+		executability bool
+		target        bool
+	}{
+		{"chmod", false, false, true, false},
+		{"directory", true, true, false, false},
+		{"link", false, false, false, true},
+		{"modified", false, true, false, false},
+	}
+
+	// Verify modifications.
+	if len(diff.Modified) != len(expectedModified) {
+		t.Fatal("modification count does not match expected:", len(diff.Modified), "!=", len(expectedModified))
+	}
+	for m, modification := range diff.Modified {
+		expected := expectedModified[m]
+		if modification.Path != expected.path {
+			t.Error("modified path does not match expected:", modification.Path, "!=", expected.path)
+			continue
+		}
+		if modification.KindChanged() != expected.kind {
+			t.Error("kind change incorrect for", modification.Path)
+		}
+		if modification.DigestChanged() != expected.digest {
+			t.Error("digest change incorrect for", modification.Path)
+		}
+		if modification.ExecutabilityChanged() != expected.executability {
+			t.Error("executability change incorrect for", modification.Path)
+		}
+		if modification.TargetChanged() != expected.target {
+			t.Error("target change incorrect for", modification.Path)
+		}
+	}
+}
+
+func TestDiffSnapshotsWithMoveDetection(t *testing.T) {
+	// Compute the diff.
+	diff := DiffSnapshots(testSnapshotDiffBase, testSnapshotDiffTarget, true)
+
+	// Verify that the rename was detected as a move.
+	if len(diff.Moved) != 1 {
+		t.Fatal("move count does not match expected:", len(diff.Moved), "!=", 1)
+	} else if move := diff.Moved[0]; move.OldPath != "original" || move.NewPath != "renamed" {
+		t.Error("move paths do not match expected:", move.OldPath, "->", move.NewPath)
+	}
+
+	// Verify that the move's paths were excluded from additions and removals.
+	expectedAdded := []string{"added", "added/child"}
+	if added := snapshotEntryPaths(diff.Added); !pathListsEqual(added, expectedAdded) {
+		t.Error("added paths do not match expected:", added, "!=", expectedAdded)
+	}
+	expectedRemoved := []string{"directory/child", "removed"}
+	if removed := snapshotEntryPaths(diff.Removed); !pathListsEqual(removed, expectedRemoved) {
+		t.Error("removed paths do not match expected:", removed, "!=", expectedRemoved)
+	}
+}
+
+func TestDiffSnapshotsAmbiguousMoves(t *testing.T) {
+	// Create snapshots where two files with identical content are renamed.
+	base := &Entry{
+		Contents: map[string]*Entry{
+			"a": {Kind: EntryKind_File, Digest: []byte{1}},
+			"b": {Kind: EntryKind_File, Digest: []byte{1}},
+		},
+	}
+	target := &Entry{
+		Contents: map[string]*Entry{
+			"c": {Kind: EntryKind_File, Digest: []byte{1}},
+			"d": {Kind: EntryKind_File, Digest: []byte{1}},
+		},
+	}
+
+	// Verify that candidates are paired in path order.
+	diff := DiffSnapshots(base, target, true)
+	if len(diff.Moved) != 2 {
+		t.Fatal("move count does not match expected:", len(diff.Moved), "!=", 2)
+	} else if diff.Moved[0].OldPath != "a" || diff.Moved[0].NewPath != "c" {
+		t.Error("first move incorrect:", diff.Moved[0].OldPath, "->", diff.Moved[0].NewPath)
+	} else if diff.Moved[1].OldPath != "b" || diff.Moved[1].NewPath != "d" {
+		t.Error("second move incorrect:", diff.Moved[1].OldPath, "->", diff.Moved[1].NewPath)
+	}
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Error("paired moves remain in additions or removals")
+	}
+}
+
+func TestDiffSnapshotsNil(t *testing.T) {
+	// Verify that diffing nil snapshots yields an empty diff.
+	diff := DiffSnapshots(nil, nil, true)
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 || len(diff.Modified) != 0 || len(diff.Moved) != 0 {
+		t.Error("diff of nil snapshots is non-empty")
+	}
+
+	// Verify that creation of a root file is an addition at the root path.
+	diff = DiffSnapshots(nil, testFile1Entry, true)
+	if len(diff.Added) != 1 || diff.Added[0].Path != "" {
+		t.Error("root creation not reported as root addition")
+	}
+}
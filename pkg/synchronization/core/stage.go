@@ -74,3 +74,89 @@ func TransitionDependencies(transitions []*Change) ([]string, [][]byte, error) {
 	// Success.
 	return finder.paths, finder.digests, nil
 }
+
+// withoutFiles returns a copy of the specified entry (located at the specified
+// path) with the file entries at the excluded paths removed. If the entry is
+// itself an excluded file, then nil is returned. Entries that don't contain
+// excluded files are returned without copying.
+func withoutFiles(path string, entry *Entry, excluded map[string]bool) *Entry {
+	// Handle based on type.
+	if entry == nil {
+		return nil
+	} else if entry.Kind == EntryKind_File {
+		if excluded[path] {
+			return nil
+		}
+		return entry
+	} else if entry.Kind != EntryKind_Directory {
+		return entry
+	}
+
+	// Process directory contents, only creating a copy if content is excluded.
+	var result *Entry
+	for name, child := range entry.Contents {
+		filtered := withoutFiles(pathJoin(path, name), child, excluded)
+		if filtered == child {
+			continue
+		}
+		if result == nil {
+			result = entry.copySlim()
+			result.Contents = make(map[string]*Entry, len(entry.Contents))
+			for n, c := range entry.Contents {
+				result.Contents[n] = c
+			}
+		}
+		if filtered == nil {
+			delete(result.Contents, name)
+		} else {
+			result.Contents[name] = filtered
+		}
+	}
+	if result == nil {
+		return entry
+	}
+	return result
+}
+
+// ExcludeTransitionDependencies removes the specified file paths (typically
+// those whose staging has been deferred) from the targets of a list of
+// transitions, allowing the remainder of the transitions to be applied. Any
+// transition that targets an excluded file is dropped entirely, while directory
+// targets are pruned of excluded files. Since the excluded files won't be
+// reflected in transition results, they'll be rediscovered by subsequent
+// reconciliation. The original transitions are not modified.
+func ExcludeTransitionDependencies(transitions []*Change, excluded []string) []*Change {
+	// If there are no exclusions, then there's nothing to prune.
+	if len(excluded) == 0 {
+		return transitions
+	}
+
+	// Create a lookup set for excluded paths.
+	excludedSet := make(map[string]bool, len(excluded))
+	for _, path := range excluded {
+		excludedSet[path] = true
+	}
+
+	// Prune transitions.
+	result := make([]*Change, 0, len(transitions))
+	for _, t := range transitions {
+		if t.New == nil {
+			result = append(result, t)
+		} else if target := withoutFiles(t.Path, t.New, excludedSet); target == nil {
+			continue
+		} else if target == t.New {
+			result = append(result, t)
+		} else {
+			result = append(result, &Change{
+				Path:            t.Path,
+				Old:             t.Old,
+				New:             target,
+				Resolve:         t.Resolve,
+				ConflictSidecar: t.ConflictSidecar,
+			})
+		}
+	}
+
+	// Done.
+	return result
+}
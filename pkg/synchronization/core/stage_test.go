@@ -78,3 +78,52 @@ func TestTransitionDependenciesOnlyExecutableBitChange(t *testing.T) {
 		t.Error("digest count does not match path count")
 	}
 }
+
+func TestExcludeTransitionDependencies(t *testing.T) {
+	transitions := []*Change{
+		{
+			Path: "directory",
+			New:  testDirectory1Entry,
+		},
+		{
+			Path: "file",
+			New:  testFile1Entry,
+		},
+		{
+			Path: "removed",
+			Old:  testFile2Entry,
+		},
+	}
+	paths, _, err := TransitionDependencies(transitions)
+	if err != nil {
+		t.Fatal("transition dependency finding failed:", err)
+	} else if len(paths) != 5 {
+		t.Fatal("unexpected number of paths")
+	}
+	excluded := []string{"file"}
+	for _, path := range paths {
+		if path != "file" {
+			excluded = append(excluded, path)
+			break
+		}
+	}
+	pruned := ExcludeTransitionDependencies(transitions, excluded)
+	if len(pruned) != 2 {
+		t.Fatal("unexpected number of pruned transitions")
+	} else if pruned[0].Path != "directory" || pruned[1].Path != "removed" {
+		t.Error("pruned transitions do not match expected")
+	} else if remaining, _, err := TransitionDependencies(pruned); err != nil {
+		t.Error("transition dependency finding failed for pruned transitions:", err)
+	} else if len(remaining) != 3 {
+		t.Error("unexpected number of paths for pruned transitions")
+	} else {
+		for _, path := range remaining {
+			if path == excluded[1] {
+				t.Error("excluded path remains in pruned transitions")
+			}
+		}
+	}
+	if paths, _, _ := TransitionDependencies(transitions); len(paths) != 5 {
+		t.Error("original transitions modified")
+	}
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"bufio"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+)
+
+// EntryStream provides sequential access to the entries of a snapshot. Entries
+// are yielded as records in depth-first pre-order, with the contents of each
+// directory yielded immediately after the directory itself, sorted by name.
+// Directory entries are yielded without contents. A stream for a nil snapshot
+// yields no records.
+type EntryStream interface {
+	// Next returns the next record in the stream. It returns io.EOF (and only
+	// io.EOF) once the stream has been exhausted.
+	Next() (*EntryRecord, error)
+}
+
+// entryStream implements EntryStream for an in-memory snapshot.
+type entryStream struct {
+	// pending is the stack of records pending emission.
+	pending []*EntryRecord
+	// contents is the stack of contents maps for pending directory records,
+	// indexed in parallel with pending.
+	contents []map[string]*Entry
+}
+
+// NewEntryStream creates an entry stream for an in-memory snapshot. The stream
+// only holds references to the snapshot's entries and yields them lazily.
+func NewEntryStream(snapshot *Entry) EntryStream {
+	result := &entryStream{}
+	if snapshot != nil {
+		result.push("", snapshot)
+	}
+	return result
+}
+
+// push pushes a record for the specified entry onto the pending stack.
+func (s *entryStream) push(path string, entry *Entry) {
+	s.pending = append(s.pending, &EntryRecord{Path: path, Entry: entry.copySlim()})
+	if entry.Kind == EntryKind_Directory {
+		s.contents = append(s.contents, entry.Contents)
+	} else {
+		s.contents = append(s.contents, nil)
+	}
+}
+
+// Next implements EntryStream.Next.
+func (s *entryStream) Next() (*EntryRecord, error) {
+	// Check if the stream is exhausted.
+	if len(s.pending) == 0 {
+		return nil, io.EOF
+	}
+
+	// Pop the next record off the stack.
+	last := len(s.pending) - 1
+	record, contents := s.pending[last], s.contents[last]
+	s.pending[last], s.contents[last] = nil, nil
+	s.pending, s.contents = s.pending[:last], s.contents[:last]
+
+	// Push the record's contents onto the stack in reverse name order so that
+	// they're popped in name order.
+	if len(contents) > 0 {
+		names := make([]string, 0, len(contents))
+		for name := range contents {
+			names = append(names, name)
+		}
+		sort.Sort(sort.Reverse(sort.StringSlice(names)))
+		for _, name := range names {
+			s.push(pathJoin(record.Path, name), contents[name])
+		}
+	}
+
+	// Done.
+	return record, nil
+}
+
+// WriteEntryStream encodes the records of an entry stream to the specified
+// writer, allowing a snapshot to be stored on disk and later streamed using
+// NewEntryStreamReader.
+func WriteEntryStream(writer io.Writer, stream EntryStream) error {
+	// Create a buffered writer and an encoder. We encode and flush each record
+	// individually to keep the encoder's buffer small.
+	buffered := bufio.NewWriter(writer)
+	encoder := encoding.NewProtobufEncoder(buffered)
+
+	// Encode records.
+	for {
+		record, err := stream.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return errors.Wrap(err, "unable to read record")
+		} else if err = encoder.Encode(record); err != nil {
+			return errors.Wrap(err, "unable to encode record")
+		}
+	}
+
+	// Flush buffered data.
+	if err := buffered.Flush(); err != nil {
+		return errors.Wrap(err, "unable to flush records")
+	}
+
+	// Success.
+	return nil
+}
+
+// entryStreamReader implements EntryStream for records encoded by
+// WriteEntryStream.
+type entryStreamReader struct {
+	// decoder is the underlying record decoder.
+	decoder *encoding.ProtobufDecoder
+}
+
+// NewEntryStreamReader creates an entry stream that decodes records encoded by
+// WriteEntryStream from the specified reader.
+func NewEntryStreamReader(reader io.Reader) EntryStream {
+	return &entryStreamReader{
+		decoder: encoding.NewProtobufDecoder(reader),
+	}
+}
+
+// Next implements EntryStream.Next.
+func (r *entryStreamReader) Next() (*EntryRecord, error) {
+	record := &EntryRecord{}
+	if err := r.decoder.Decode(record); err != nil {
+		if errors.Cause(err) == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	} else if record.Entry == nil {
+		return nil, errors.New("record has nil entry")
+	}
+	return record, nil
+}
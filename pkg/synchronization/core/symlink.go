@@ -1,6 +1,7 @@
 package core
 
 import (
+	pathpkg "path"
 	"runtime"
 	"strings"
 
@@ -104,3 +105,24 @@ func normalizeSymlinkAndEnsurePortable(path, target string) (string, error) {
 	// Success.
 	return target, nil
 }
+
+// inTreeSymlinkTarget computes the root-relative path of the target of the
+// symbolic link at the specified path. It returns false if the target is
+// absolute or references a location outside of the synchronization root. The
+// target must use forward slashes as separators.
+func inTreeSymlinkTarget(path, target string) (string, bool) {
+	// Absolute targets are never considered to be within the root.
+	if target == "" || target[0] == '/' {
+		return "", false
+	}
+
+	// Resolve the target relative to the symbolic link's parent directory and
+	// ensure that it doesn't escape the synchronization root.
+	resolved := pathpkg.Join(pathpkg.Dir(path), target)
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return "", false
+	} else if resolved == "." {
+		resolved = ""
+	}
+	return resolved, true
+}
@@ -3,7 +3,15 @@
 package core
 
 import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
 )
 
 func TestSymlinkPOSIXBackslashInvalid(t *testing.T) {
@@ -11,3 +19,377 @@ func TestSymlinkPOSIXBackslashInvalid(t *testing.T) {
 		t.Fatal("symlink with backslash in target treated as sane")
 	}
 }
+
+// testObservingProvider is a testProvider that invokes a callback before
+// providing each file, allowing tests to observe intermediate transition state.
+type testObservingProvider struct {
+	*testProvider
+	// observe is the observation callback.
+	observe func(path string)
+}
+
+// Provide implements Provider.Provide.
+func (p *testObservingProvider) Provide(path string, digest []byte) (string, error) {
+	p.observe(path)
+	return p.testProvider.Provide(path, digest)
+}
+
+// testDanglingSymlinks returns the root-relative paths of any dangling symbolic
+// links within the specified root.
+func testDanglingSymlinks(t *testing.T, root string) []string {
+	var dangling []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		} else if _, err := os.Stat(path); err != nil {
+			relative, _ := filepath.Rel(root, path)
+			dangling = append(dangling, filepath.ToSlash(relative))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("unable to walk root:", err)
+	}
+	return dangling
+}
+
+// TestTransitionDeferSymlinksInDirectory tests that symbolic link deferral
+// avoids transiently dangling links when creating a directory whose symbolic
+// links point to content that may be created after them.
+func TestTransitionDeferSymlinksInDirectory(t *testing.T) {
+	// Create the target entry. Since directory contents are created in an
+	// unspecified order, we'll perform the transition several times to ensure
+	// that links are encountered before their targets.
+	target := &Entry{
+		Kind: EntryKind_Directory,
+		Contents: map[string]*Entry{
+			"a_link":   {Kind: EntryKind_Symlink, Target: "z_directory/file"},
+			"b_chain":  {Kind: EntryKind_Symlink, Target: "a_link"},
+			"c_link":   {Kind: EntryKind_Symlink, Target: "m_file"},
+			"dangling": {Kind: EntryKind_Symlink, Target: "missing"},
+			"m_file":   testFile2Entry,
+			"z_directory": {
+				Kind: EntryKind_Directory,
+				Contents: map[string]*Entry{
+					"file": testFile1Entry,
+				},
+			},
+		},
+	}
+	contentMap := map[string][]byte{
+		"m_file":           testFile2Contents,
+		"z_directory/file": testFile1Contents,
+	}
+
+	// Perform the transitions.
+	for i := 0; i < 10; i++ {
+		// Create a temporary directory to act as the parent of our root.
+		parent, err := ioutil.TempDir("", "mutagen_defer_symlinks")
+		if err != nil {
+			t.Fatal("unable to create temporary directory:", err)
+		}
+		root := filepath.Join(parent, "root")
+
+		// Create a provider that verifies that no unexpected dangling links
+		// exist whenever a file is provided.
+		baseProvider, err := newTestProvider(contentMap, newTestHasher())
+		if err != nil {
+			os.RemoveAll(parent)
+			t.Fatal("unable to create test provider:", err)
+		}
+		provider := &testObservingProvider{baseProvider, func(path string) {
+			if _, err := os.Lstat(root); err != nil {
+				return
+			}
+			for _, dangling := range testDanglingSymlinks(t, root) {
+				if dangling != "dangling" {
+					t.Errorf("transiently dangling symbolic link observed at %s when providing %s", dangling, path)
+				}
+			}
+		}}
+
+		// Perform the transition.
+		results, problems, missingFiles := Transition(
+			context.Background(),
+			root,
+			[]*Change{{New: target}},
+			nil,
+			SymlinkMode_SymlinkModePortable,
+			defaultFilePermissionMode,
+			defaultDirectoryPermissionMode,
+			nil,
+			false,
+			provider,
+			&TransitionOptions{
+				DeferSymlinks:  true,
+				DurabilityMode: DurabilityMode_DurabilityModeFull,
+				Syncer:         filesystem.SystemSyncer,
+				ACLMode:        ACLMode_ACLModeIgnore,
+			},
+		)
+		baseProvider.finalize()
+
+		// Verify the results.
+		if len(problems) != 0 {
+			t.Error("transition problems encountered:", problems)
+		} else if missingFiles {
+			t.Error("provider reported missing files")
+		} else if len(results) != 1 || !results[0].Equal(target) {
+			t.Error("transition results do not match expected")
+		}
+		for _, name := range []string{"a_link", "b_chain", "c_link", "dangling"} {
+			if destination, err := os.Readlink(filepath.Join(root, name)); err != nil {
+				t.Error("unable to read symbolic link:", err)
+			} else if destination != target.Contents[name].Target {
+				t.Errorf("symbolic link target for %s incorrect: %s", name, destination)
+			}
+		}
+
+		// Clean up.
+		os.RemoveAll(parent)
+	}
+}
+
+// TestTransitionDeferSymlinksOrdering tests that symbolic link deferral defers
+// links whose in-tree targets are created by later transitions, but not links
+// whose targets lie outside of the synchronization root.
+func TestTransitionDeferSymlinksOrdering(t *testing.T) {
+	// Create a temporary directory to act as the root.
+	root, err := ioutil.TempDir("", "mutagen_defer_symlinks")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(root)
+
+	// Create a provider that records the state of the links when the target
+	// file is provided.
+	baseProvider, err := newTestProvider(map[string][]byte{"late": testFile1Contents}, newTestHasher())
+	if err != nil {
+		t.Fatal("unable to create test provider:", err)
+	}
+	defer baseProvider.finalize()
+	var outsideExisted, earlyExisted bool
+	provider := &testObservingProvider{baseProvider, func(_ string) {
+		_, err := os.Lstat(filepath.Join(root, "outside"))
+		outsideExisted = err == nil
+		_, err = os.Lstat(filepath.Join(root, "early"))
+		earlyExisted = err == nil
+	}}
+
+	// Perform the transition. We use POSIX raw mode so that we can create a
+	// link with a target outside of the root.
+	transitions := []*Change{
+		{Path: "outside", New: &Entry{Kind: EntryKind_Symlink, Target: "/nonexistent/target"}},
+		{Path: "early", New: &Entry{Kind: EntryKind_Symlink, Target: "late"}},
+		{Path: "late", New: testFile1Entry},
+	}
+	results, problems, missingFiles := Transition(
+		context.Background(),
+		root,
+		transitions,
+		nil,
+		SymlinkMode_SymlinkModePOSIXRaw,
+		defaultFilePermissionMode,
+		defaultDirectoryPermissionMode,
+		nil,
+		false,
+		provider,
+		&TransitionOptions{
+			DeferSymlinks:  true,
+			DurabilityMode: DurabilityMode_DurabilityModeFull,
+			Syncer:         filesystem.SystemSyncer,
+			ACLMode:        ACLMode_ACLModeIgnore,
+		},
+	)
+
+	// Verify the intermediate state.
+	if !outsideExisted {
+		t.Error("symbolic link with out-of-tree target was deferred")
+	}
+	if earlyExisted {
+		t.Error("symbolic link created before its in-tree target")
+	}
+
+	// Verify the results.
+	if len(problems) != 0 {
+		t.Fatal("transition problems encountered:", problems)
+	} else if missingFiles {
+		t.Fatal("provider reported missing files")
+	} else if len(results) != len(transitions) {
+		t.Fatal("unexpected number of results:", len(results))
+	}
+	for r, result := range results {
+		if !result.Equal(transitions[r].New) {
+			t.Errorf("result for %s does not match expected", transitions[r].Path)
+		}
+	}
+	if len(testDanglingSymlinks(t, root)) != 1 {
+		t.Error("unexpected dangling symbolic links after transition")
+	}
+}
+
+// createBrokenSymlinkTestContent creates a temporary synchronization root
+// containing a file, an in-tree symbolic link whose target exists, and an
+// in-tree symbolic link whose target doesn't exist (yet). If requested, it also
+// creates symbolic links with relative and absolute targets outside of the root
+// that don't exist. It returns the path to the root.
+func createBrokenSymlinkTestContent(t *testing.T, outOfTree bool) string {
+	// Create content.
+	links := map[string]string{
+		"sub/present": "../file",
+		"pending":     "sub/missing/target",
+	}
+	if outOfTree {
+		links["sub/outside"] = "../../mutagen_nonexistent_target"
+		links["absolute"] = "/mutagen_nonexistent_directory/target"
+	}
+	return testCreateContent(t, map[string][]byte{"file": []byte("content")}, links)
+}
+
+// scanBrokenSymlinkTestContent scans a synchronization root in portable symlink
+// mode using the specified broken symlink mode.
+func scanBrokenSymlinkTestContent(root string, mode BrokenSymlinkMode) (*Entry, []*Problem, error) {
+	snapshot, _, _, _, _, skipped, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		newTestHasher(),
+		nil,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: mode,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	return snapshot, skipped, err
+}
+
+// verifyInTreeSymlinks verifies that a snapshot contains the in-tree symbolic
+// links created by createBrokenSymlinkTestContent, including the one whose
+// target doesn't exist.
+func verifyInTreeSymlinks(t *testing.T, snapshot *Entry) {
+	if present := snapshot.Contents["sub"].Contents["present"]; present == nil || present.Kind != EntryKind_Symlink {
+		t.Error("in-tree symbolic link with existing target missing from snapshot")
+	}
+	if pending := snapshot.Contents["pending"]; pending == nil || pending.Kind != EntryKind_Symlink {
+		t.Error("in-tree symbolic link with missing target missing from snapshot")
+	} else if pending.Target != "sub/missing/target" {
+		t.Error("unexpected in-tree symbolic link target:", pending.Target)
+	}
+}
+
+// TestScanBrokenSymlinkInTree tests that symbolic links whose targets lie
+// within the synchronization root are included in scans regardless of whether
+// or not their targets exist and regardless of broken symlink mode.
+func TestScanBrokenSymlinkInTree(t *testing.T) {
+	// Create test content and defer its removal.
+	root := createBrokenSymlinkTestContent(t, false)
+	defer os.RemoveAll(root)
+
+	// Verify behavior for each broken symlink mode.
+	modes := []BrokenSymlinkMode{
+		BrokenSymlinkMode_BrokenSymlinkModeDefault,
+		BrokenSymlinkMode_BrokenSymlinkModeSync,
+		BrokenSymlinkMode_BrokenSymlinkModeSkip,
+		BrokenSymlinkMode_BrokenSymlinkModeError,
+	}
+	for _, mode := range modes {
+		snapshot, skipped, err := scanBrokenSymlinkTestContent(root, mode)
+		if err != nil {
+			t.Errorf("unable to perform scan with mode %s: %v", mode, err)
+			continue
+		}
+		verifyInTreeSymlinks(t, snapshot)
+		if len(skipped) != 0 {
+			t.Errorf("content skipped with mode %s: %d", mode, len(skipped))
+		}
+	}
+}
+
+// TestScanBrokenSymlinkSync tests that broken symbolic links with targets
+// outside of the synchronization root are handled like any other symbolic link
+// in the sync broken symlink mode, which (since they're not portable) causes the
+// scan to fail.
+func TestScanBrokenSymlinkSync(t *testing.T) {
+	// Create test content and defer its removal.
+	root := createBrokenSymlinkTestContent(t, true)
+	defer os.RemoveAll(root)
+
+	// Verify that the scan fails due to portability enforcement.
+	if _, _, err := scanBrokenSymlinkTestContent(root, BrokenSymlinkMode_BrokenSymlinkModeSync); err == nil {
+		t.Fatal("scan succeeded with non-portable symbolic links")
+	} else if strings.Contains(err.Error(), "broken symbolic link") {
+		t.Error("symbolic link treated as broken in sync mode:", err)
+	}
+}
+
+// TestScanBrokenSymlinkSkip tests that broken symbolic links with targets
+// outside of the synchronization root are excluded from scans and reported as
+// skipped in the skip broken symlink mode.
+func TestScanBrokenSymlinkSkip(t *testing.T) {
+	// Create test content and defer its removal.
+	root := createBrokenSymlinkTestContent(t, true)
+	defer os.RemoveAll(root)
+
+	// Perform a scan.
+	snapshot, skipped, err := scanBrokenSymlinkTestContent(root, BrokenSymlinkMode_BrokenSymlinkModeSkip)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	}
+
+	// Verify that in-tree links are present and that broken links are absent.
+	verifyInTreeSymlinks(t, snapshot)
+	if _, ok := snapshot.Contents["sub"].Contents["outside"]; ok {
+		t.Error("broken relative symbolic link included in snapshot")
+	}
+	if _, ok := snapshot.Contents["absolute"]; ok {
+		t.Error("broken absolute symbolic link included in snapshot")
+	}
+
+	// Verify the skipped problems.
+	verifySkippedFileProblems(t, skipped, []string{"sub/outside", "absolute"})
+}
+
+// TestScanBrokenSymlinkSkipExistingTarget tests that symbolic links with
+// existing targets outside of the synchronization root aren't considered broken
+// in the skip broken symlink mode and are still subject to portability
+// enforcement.
+func TestScanBrokenSymlinkSkipExistingTarget(t *testing.T) {
+	// Create test content and defer its removal.
+	root := createBrokenSymlinkTestContent(t, false)
+	defer os.RemoveAll(root)
+
+	// Create a symbolic link to an existing location outside the root.
+	if err := os.Symlink(filepath.Dir(root), filepath.Join(root, "parent")); err != nil {
+		t.Fatal("unable to create symbolic link:", err)
+	}
+
+	// Verify that the scan fails due to portability enforcement.
+	if _, _, err := scanBrokenSymlinkTestContent(root, BrokenSymlinkMode_BrokenSymlinkModeSkip); err == nil {
+		t.Fatal("scan succeeded with non-portable symbolic link")
+	} else if strings.Contains(err.Error(), "broken symbolic link") {
+		t.Error("symbolic link with existing target treated as broken:", err)
+	}
+}
+
+// TestScanBrokenSymlinkError tests that broken symbolic links with targets
+// outside of the synchronization root cause scans to fail in the error broken
+// symlink mode.
+func TestScanBrokenSymlinkError(t *testing.T) {
+	// Create test content and defer its removal.
+	root := createBrokenSymlinkTestContent(t, true)
+	defer os.RemoveAll(root)
+
+	// Verify that the scan fails due to the broken links.
+	if _, _, err := scanBrokenSymlinkTestContent(root, BrokenSymlinkMode_BrokenSymlinkModeError); err == nil {
+		t.Fatal("scan succeeded with broken symbolic links")
+	} else if !strings.Contains(err.Error(), "broken symbolic link") {
+		t.Error("unexpected scan error:", err)
+	}
+}
@@ -70,3 +70,37 @@ func TestSymlinkDotSubdirectoryValid(t *testing.T) {
 		t.Error("normalized symlink target incorrect:", target, "!=", "subdirectory/other")
 	}
 }
+
+func TestInTreeSymlinkTarget(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		path           string
+		target         string
+		expectedPath   string
+		expectedInTree bool
+	}{
+		{"link", "file", "file", true},
+		{"link", "./directory/file", "directory/file", true},
+		{"directory/link", "../file", "file", true},
+		{"directory/link", "..", "", true},
+		{"directory/link", ".", "directory", true},
+		{"link", "../file", "", false},
+		{"directory/link", "../../file", "", false},
+		{"link", "/absolute/file", "", false},
+		{"link", "", "", false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		path, inTree := inTreeSymlinkTarget(testCase.path, testCase.target)
+		if inTree != testCase.expectedInTree {
+			t.Errorf("in-tree status (%t) for %s -> %s does not match expected (%t)",
+				inTree, testCase.path, testCase.target, testCase.expectedInTree,
+			)
+		} else if path != testCase.expectedPath {
+			t.Errorf("resolved path (%q) for %s -> %s does not match expected (%q)",
+				path, testCase.path, testCase.target, testCase.expectedPath,
+			)
+		}
+	}
+}
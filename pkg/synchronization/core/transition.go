@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -15,14 +16,28 @@ import (
 
 	"github.com/golang/protobuf/ptypes"
 
+	"github.com/google/uuid"
+
 	"github.com/mutagen-io/mutagen/pkg/filesystem"
 )
 
 const (
+	// caseRenameTemporaryNamePrefix is the file name prefix to use for the
+	// intermediate names used when performing case-only renames.
+	caseRenameTemporaryNamePrefix = filesystem.TemporaryNamePrefix + "case-rename"
+
 	// crossDeviceRenameTemporaryNamePrefix is the file name prefix to use for
 	// intermediate temporary files used in cross-device renames.
 	crossDeviceRenameTemporaryNamePrefix = filesystem.TemporaryNamePrefix + "cross-device-rename"
 
+	// hardLinkTemporaryNamePrefix is the file name prefix to use for
+	// intermediate hard links created when preserving hard link structure.
+	hardLinkTemporaryNamePrefix = filesystem.TemporaryNamePrefix + "hard-link"
+
+	// placeholderTemporaryNamePrefix is the file name prefix to use for
+	// intermediate temporary files used when creating placeholders.
+	placeholderTemporaryNamePrefix = filesystem.TemporaryNamePrefix + "placeholder"
+
 	// transitionCopyBufferSize specifies the size of the internal buffer that a
 	// transitioner uses to copy file data (e.g. when performing cross-device
 	// renames).
@@ -66,6 +81,9 @@ type transitioner struct {
 	// symlinkMode is the symlink mode to use for synchronization. It's required
 	// to verify existing symlinks (which may require normalization).
 	symlinkMode SymlinkMode
+	// deferSymlinks indicates whether or not the creation of symbolic links
+	// with in-tree targets should be deferred until those targets exist.
+	deferSymlinks bool
 	// defaultFilePermissionMode is the default file permission mode to use in
 	// "portable" permission propagation.
 	defaultFilePermissionMode filesystem.Mode
@@ -81,8 +99,53 @@ type transitioner struct {
 	// due to Unicode decomposition behavior on the synchronization root
 	// filesystem.
 	recomposeUnicode bool
+	// filenames is the transcoder used to encode names and symbolic link
+	// targets for storage on disk (and to decode those read from disk). It may
+	// be nil if no transcoding is necessary.
+	filenames *FilenameTranscoder
+	// durabilityMode is the durability mode to use when flushing modifications
+	// to durable storage.
+	durabilityMode DurabilityMode
+	// syncer is the syncer used to flush modifications to durable storage.
+	syncer filesystem.Syncer
+	// restoreACLs indicates whether or not POSIX ACLs should be restored for
+	// files and directories.
+	restoreACLs bool
 	// provider is the staged file provider.
 	provider Provider
+	// preserveHardLinks indicates whether or not hard link structure recorded
+	// in target entries should be recreated.
+	preserveHardLinks bool
+	// preserveMacOSMetadata indicates whether or not macOS metadata recorded in
+	// target entries should be restored.
+	preserveMacOSMetadata bool
+	// preserveFileFlags indicates whether or not file flags recorded in target
+	// entries should be restored.
+	preserveFileFlags bool
+	// createPlaceholders indicates whether or not placeholders should be
+	// created for files instead of moving staged content into place.
+	createPlaceholders bool
+	// caseInsensitive indicates whether or not the synchronization root
+	// filesystem treats names differing only in case as equivalent.
+	caseInsensitive bool
+	// protectedPaths identifies paths that must not be deleted or overwritten.
+	protectedPaths *ProtectedPathMatcher
+	// verifier verifies staged content for paths requiring verification.
+	verifier *StagedContentVerifier
+	// placedFiles tracks files placed from staging during the transition, keyed
+	// by path. It is only populated if hard links are being preserved.
+	placedFiles map[string]*placedFile
+	// deferredSymlinks tracks symbolic links whose creation has been deferred
+	// until their in-tree targets exist. It is only populated if symbolic link
+	// creation is being deferred.
+	deferredSymlinks []*deferredSymlink
+	// deferredFileFlags tracks entries whose file flags will be restored once
+	// all other transition operations are complete. It is only populated if
+	// file flags are being preserved.
+	deferredFileFlags []*deferredFileFlags
+	// deferredDirectoryPermissions tracks directories whose permissions will be
+	// restricted once all other transition operations are complete.
+	deferredDirectoryPermissions []*deferredDirectoryPermissions
 	// problems are the problems currently being tracked.
 	problems []*Problem
 	// providerMissingFiles indicates that the staged file provider returned an
@@ -90,14 +153,292 @@ type transitioner struct {
 	providerMissingFiles bool
 }
 
+// placedFile records information about a file placed from staging during a
+// transition.
+type placedFile struct {
+	// target is the entry for which the file was placed.
+	target *Entry
+	// metadata is the metadata of the file immediately after placement.
+	metadata *filesystem.Metadata
+}
+
+// deferredSymlink records a symbolic link whose creation has been deferred
+// until its in-tree target exists.
+type deferredSymlink struct {
+	// path is the path of the symbolic link.
+	path string
+	// target is the entry for the symbolic link.
+	target *Entry
+	// created is invoked if the symbolic link is successfully created in order
+	// to record its creation in the transition results.
+	created func()
+}
+
 // recordProblem records a new problem.
 func (t *transitioner) recordProblem(path string, err error) {
 	t.problems = append(t.problems, &Problem{Path: path, Error: err.Error()})
 }
 
+// restoreACL restores the POSIX ACLs for the target entry (if any) onto the
+// file or directory at the specified filesystem path, using the specified mode
+// for the owner and other classes. Failures are recorded as problems for the
+// synchronization path but are otherwise non-fatal, since the entry's contents
+// will have still been correctly propagated.
+func (t *transitioner) restoreACL(path, filesystemPath string, target *Entry, mode filesystem.Mode) {
+	if !t.restoreACLs || target.Acl == nil {
+		return
+	}
+	if err := writeACL(filesystemPath, target.Acl, mode); err != nil {
+		if errors.Cause(err) == filesystem.ErrACLsUnsupported {
+			err = errors.New("POSIX ACLs not supported by filesystem")
+		}
+		t.recordProblem(path, errors.Wrap(err, "unable to restore POSIX ACLs"))
+	}
+}
+
+// restoreMacOSMetadata restores the macOS metadata for the target entry onto
+// the file or directory at the specified path, writing it natively if possible
+// and to an AppleDouble sidecar file otherwise. As with POSIX ACLs, failures
+// are recorded as problems but are otherwise non-fatal.
+func (t *transitioner) restoreMacOSMetadata(path string, target *Entry) {
+	if !t.preserveMacOSMetadata || path == "" {
+		return
+	}
+	filesystemPath := t.filenames.FilesystemPath(t.root, path)
+	permissions := os.FileMode(t.defaultFilePermissionMode)
+	if err := writeMacOSMetadata(filesystemPath, target.MacOSMetadata, permissions); err != nil {
+		t.recordProblem(path, errors.Wrap(err, "unable to restore macOS metadata"))
+	}
+}
+
+// removeAppleDouble removes the AppleDouble sidecar file (if any) for the entry
+// specified by name within the specified directory. It's a no-op if macOS
+// metadata isn't being preserved.
+func (t *transitioner) removeAppleDouble(parent *filesystem.Directory, name, path string) {
+	if !t.preserveMacOSMetadata {
+		return
+	}
+	if err := parent.RemoveFile(appleDoublePrefix + name); err != nil && !os.IsNotExist(errors.Cause(err)) {
+		t.recordProblem(path, errors.Wrap(err, "unable to remove AppleDouble file"))
+	}
+}
+
+// deferredFileFlags records an entry whose file flags will be restored at the
+// end of a transition.
+type deferredFileFlags struct {
+	// path is the path of the entry.
+	path string
+	// target is the entry whose file flags will be restored.
+	target *Entry
+}
+
+// deferFileFlags records that the file flags for the target entry should be
+// restored onto the file or directory at the specified path. Restoration is
+// deferred until all other transition operations are complete, since locking
+// flags (e.g. user immutable) would otherwise block subsequent operations such
+// as the creation of deferred symbolic links or hard links. It's a no-op if
+// file flags aren't being preserved.
+func (t *transitioner) deferFileFlags(path string, target *Entry) {
+	if !t.preserveFileFlags || path == "" {
+		return
+	}
+	t.deferredFileFlags = append(t.deferredFileFlags, &deferredFileFlags{path, target})
+}
+
+// restoreFileFlags restores the file flags for the target entry onto the file
+// or directory at the specified path. Flags that can't be represented on this
+// platform are reported as problems. As with POSIX ACLs, failures are recorded
+// as problems but are otherwise non-fatal.
+func (t *transitioner) restoreFileFlags(path string, target *Entry) {
+	flags := filesystem.FileFlags(target.FileFlags)
+	if unsupported := flags &^ filesystem.SupportedFileFlags; unsupported != 0 {
+		t.recordProblem(path, errors.Errorf("file flags can't be represented on this platform: %s", unsupported))
+		flags &= filesystem.SupportedFileFlags
+	}
+	if filesystem.SupportedFileFlags == 0 {
+		return
+	}
+	if err := filesystem.WriteFileFlags(t.filenames.FilesystemPath(t.root, path), flags); err != nil {
+		t.recordProblem(path, errors.Wrap(err, "unable to restore file flags"))
+	}
+}
+
+// restoreDeferredFileFlags restores the file flags for entries recorded by
+// deferFileFlags. Entries are processed in the order in which they were
+// recorded, which ensures that the contents of directories are processed
+// before the directories themselves.
+func (t *transitioner) restoreDeferredFileFlags() {
+	for _, deferred := range t.deferredFileFlags {
+		t.restoreFileFlags(deferred.path, deferred.target)
+	}
+}
+
+// directoryPopulationPermissions are the owner permissions required to create
+// content within a directory.
+const directoryPopulationPermissions = filesystem.ModePermissionUserWrite | filesystem.ModePermissionUserExecute
+
+// deferredDirectoryPermissions records a directory whose permissions will be
+// restricted at the end of a transition.
+type deferredDirectoryPermissions struct {
+	// path is the path of the directory.
+	path string
+	// target is the entry for the directory.
+	target *Entry
+}
+
+// directoryCreationPermissions computes the permission mode with which a
+// directory should be created in order to populate it with the contents of
+// the target entry. If the directory permission mode would prevent the owner
+// from creating content within the directory, then the returned mode is
+// loosened to allow population and the second return value is true, in which
+// case the caller should record the directory with deferDirectoryPermissions
+// once its contents are created. Loosening isn't necessary if the directory has
+// no contents.
+func (t *transitioner) directoryCreationPermissions(target *Entry) (filesystem.Mode, bool) {
+	mode := t.defaultDirectoryPermissionMode
+	if len(target.Contents) == 0 || mode&directoryPopulationPermissions == directoryPopulationPermissions {
+		return mode, false
+	}
+	return mode | directoryPopulationPermissions, true
+}
+
+// deferDirectoryPermissions records that the directory at the specified path
+// should have the directory permission mode applied once all other transition
+// operations are complete. Restriction is deferred until then (rather than
+// until the directory's contents have been created) because deferred symbolic
+// links and hard links may still be created within the directory.
+func (t *transitioner) deferDirectoryPermissions(path string, target *Entry) {
+	t.deferredDirectoryPermissions = append(t.deferredDirectoryPermissions, &deferredDirectoryPermissions{path, target})
+}
+
+// restoreDeferredDirectoryPermissions applies the directory permission mode to
+// directories recorded by deferDirectoryPermissions. Directories are processed
+// in the order in which they were recorded, which ensures that subdirectories
+// are restricted before their parents (whose restriction might otherwise block
+// access to them). Any POSIX ACLs for the directories are rewritten to reflect
+// the final permission mode. As with POSIX ACLs, failures are recorded as
+// problems but are otherwise non-fatal.
+func (t *transitioner) restoreDeferredDirectoryPermissions() {
+	for _, deferred := range t.deferredDirectoryPermissions {
+		filesystemPath := t.filenames.FilesystemPath(t.root, deferred.path)
+		if err := filesystem.SetPermissionsByPath(filesystemPath, nil, t.defaultDirectoryPermissionMode); err != nil {
+			t.recordProblem(deferred.path, errors.Wrap(err, "unable to set directory permissions"))
+			continue
+		}
+		t.restoreACL(deferred.path, filesystemPath, deferred.target, t.defaultDirectoryPermissionMode)
+	}
+}
+
+// unlockFileFlags clears any locking file flags (e.g. user immutable) from the
+// file or directory at the specified path so that it can be modified or
+// removed. Only locking flags recorded by the expected entry are cleared, so
+// content that was locked outside of synchronization since the last scan
+// remains locked (and its modification will fail). It returns whether or not
+// any flags were cleared, in which case the caller should restore the expected
+// entry's flags (using restoreFileFlags) if its modification fails.
+func (t *transitioner) unlockFileFlags(path string, expected *Entry) (bool, error) {
+	// Determine which locking flags (if any) we're allowed to clear.
+	locking := filesystem.FileFlags(expected.FileFlags) & filesystem.FileFlagsLocking & filesystem.SupportedFileFlags
+	if !t.preserveFileFlags || path == "" || locking == 0 {
+		return false, nil
+	}
+
+	// Read the current flags and clear any permitted locking flags.
+	filesystemPath := t.filenames.FilesystemPath(t.root, path)
+	flags, err := filesystem.ReadFileFlags(filesystemPath)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to read file flags")
+	} else if flags&locking == 0 {
+		return false, nil
+	} else if err = filesystem.WriteFileFlags(filesystemPath, flags&^locking); err != nil {
+		return false, errors.Wrap(err, "unable to clear locking file flags")
+	}
+
+	// Success.
+	return true, nil
+}
+
+// syncStagedFile flushes the contents of the staged file at the specified path
+// to durable storage if required by the durability mode.
+func (t *transitioner) syncStagedFile(stagedPath string) error {
+	// If the durability mode doesn't require flushing file contents, then
+	// there's nothing to do.
+	if !t.durabilityMode.SyncsFiles() {
+		return nil
+	}
+
+	// Open the staged file. We need to open the file for writing since some
+	// platforms (notably Windows) won't flush files opened as read-only.
+	file, err := os.OpenFile(stagedPath, os.O_WRONLY, 0)
+	if err != nil {
+		return errors.Wrap(err, "unable to open staged file")
+	}
+
+	// Flush the file contents.
+	if err := t.syncer.SyncFile(file); err != nil {
+		file.Close()
+		return errors.Wrap(err, "unable to flush staged file")
+	}
+
+	// Close the file.
+	if err := file.Close(); err != nil {
+		return errors.Wrap(err, "unable to close staged file")
+	}
+
+	// Success.
+	return nil
+}
+
+// verifyStagedContent reads back the content of the specified file and
+// verifies that it matches the expected digest for the specified path. If
+// verification fails, then the specified removal function is invoked to discard
+// the content and the content is treated as missing from the provider, so that
+// it will be restaged.
+func (t *transitioner) verifyStagedContent(
+	path string,
+	digest []byte,
+	file io.ReadCloser,
+	remove func(),
+) error {
+	// Verify the content and close the file.
+	err := t.verifier.verify(path, file, digest, t.cancelled)
+	file.Close()
+
+	// Handle verification failure.
+	if err == errWritePreempted {
+		return errTransitionCancelled
+	} else if err == errVerificationFailed {
+		remove()
+		t.providerMissingFiles = true
+		return err
+	} else if err != nil {
+		return errors.Wrap(err, "unable to verify staged content")
+	}
+
+	// Success.
+	return nil
+}
+
+// syncDirectory flushes modifications to the contents of the specified
+// directory to durable storage if required by the durability mode. Since the
+// modifications themselves will have already succeeded, failures are recorded
+// as problems for the specified path rather than affecting transition results.
+func (t *transitioner) syncDirectory(directory *filesystem.Directory, path string) {
+	// If the durability mode doesn't require flushing directories, then
+	// there's nothing to do.
+	if !t.durabilityMode.SyncsDirectories() {
+		return
+	}
+
+	// Flush the directory.
+	if err := t.syncer.SyncDirectory(directory); err != nil {
+		t.recordProblem(path, errors.Wrap(err, "unable to flush parent directory"))
+	}
+}
+
 // nameExistsInDirectoryWithProperCase is a utility method that checks if a name
-// exists within the specified directory, recomposing the names of the
-// directory's contents if necessary.
+// exists within the specified directory, decoding and recomposing the names of
+// the directory's contents if necessary.
 func (t *transitioner) nameExistsInDirectoryWithProperCase(
 	name string,
 	directory *filesystem.Directory,
@@ -110,11 +451,14 @@ func (t *transitioner) nameExistsInDirectoryWithProperCase(
 
 	// Check if this path component exists in the contents. It's important
 	// to note that the contents are not guaranteed to be ordered, and we
-	// may need to recompose Unicode, so we can't do a binary search here.
+	// may need to decode names or recompose Unicode, so we can't do a binary
+	// search here. Names that can't be decoded can't match.
 	for _, n := range names {
-		if !t.recomposeUnicode && n == name {
+		if decoded, ok := t.filenames.decode(n); !ok {
+			continue
+		} else if !t.recomposeUnicode && decoded == name {
 			return true, nil
-		} else if t.recomposeUnicode && norm.NFC.String(n) == name {
+		} else if t.recomposeUnicode && norm.NFC.String(decoded) == name {
 			return true, nil
 		}
 	}
@@ -135,7 +479,8 @@ func (t *transitioner) nameExistsInDirectoryWithProperCase(
 // The purpose of this function is to transform any transition operation into
 // one where we have a Directory object for race-free filesystem access, the
 // base name of the content within that directory which needs to be transformed,
-// and (external to this function) the old and new content specifications.
+// and (external to this function) the old and new content specifications. The
+// returned base name is in its on-disk (i.e. encoded) form.
 //
 // This method's implementation also has the side effect of enforcing case
 // correctness. If verifyLeafCasing is true, then this method will additionally
@@ -214,7 +559,12 @@ func (t *transitioner) walkToParentAndComputeLeafName(
 		// correct casing on the next synchronization cycle.
 
 		// Open the next directory.
-		if p, err := parent.OpenDirectory(component); err != nil {
+		encodedComponent, ok := t.filenames.encode(component)
+		if !ok {
+			parent.Close()
+			return nil, "", errors.New("parent path can't be represented using filename encoding")
+		}
+		if p, err := parent.OpenDirectory(encodedComponent); err != nil {
 			parent.Close()
 			return nil, "", errors.Wrap(err, "unable to open parent component")
 		} else {
@@ -235,8 +585,15 @@ func (t *transitioner) walkToParentAndComputeLeafName(
 		}
 	}
 
+	// Compute the on-disk leaf name.
+	encodedLeafName, ok := t.filenames.encode(leafName)
+	if !ok {
+		parent.Close()
+		return nil, "", errors.New("leaf name can't be represented using filename encoding")
+	}
+
 	// Success.
-	return parent, leafName, nil
+	return parent, encodedLeafName, nil
 }
 
 // ensureExpectedFile ensures that the file specified by name within the
@@ -306,6 +663,12 @@ func (t *transitioner) ensureExpectedSymbolicLink(parent *filesystem.Directory,
 		return errors.Wrap(err, "unable to read symlink target")
 	}
 
+	// Decode the target if necessary.
+	target, ok := t.filenames.decode(target)
+	if !ok {
+		return errors.New("unable to decode symlink target using filename encoding")
+	}
+
 	// If we're in portable symlink mode, then we need to normalize the target
 	// coming from disk, because some systems (e.g. Windows) won't round-trip
 	// the target correctly.
@@ -357,8 +720,25 @@ func (t *transitioner) removeFile(parent *filesystem.Directory, name, path strin
 	// The worst case fallout is removal of contents that are modified during
 	// this window.
 
+	// Clear any locking file flags on the file.
+	unlocked, err := t.unlockFileFlags(path, expected)
+	if err != nil {
+		return err
+	}
+
 	// Remove the file.
-	return parent.RemoveFile(name)
+	if err := parent.RemoveFile(name); err != nil {
+		if unlocked {
+			t.restoreFileFlags(path, expected)
+		}
+		return err
+	}
+
+	// Remove any AppleDouble sidecar file for the file.
+	t.removeAppleDouble(parent, name, path)
+
+	// Success.
+	return nil
 }
 
 // removeSymbolicLink removes the symbolic link specified by name within the
@@ -406,6 +786,15 @@ func (t *transitioner) removeDirectory(parent *filesystem.Directory, name, path
 		return false
 	}
 
+	// Clear any locking file flags on the directory so that its contents can
+	// be removed.
+	unlocked, err := t.unlockFileFlags(path, expected)
+	if err != nil {
+		directory.Close()
+		t.recordProblem(path, err)
+		return false
+	}
+
 	// RACE: There is a race condition here between directory content listing
 	// and removal that we have to live with due to limitations in filesystem
 	// APIs. The worst case fallout from this race is that directory removal
@@ -430,38 +819,68 @@ ContentLoop:
 		default:
 		}
 
-		// Compute the content name, renormalizing Unicode if necessary.
-		contentName := c.Name
+		// Compute the content name, decoding it and renormalizing Unicode if
+		// necessary. Content with a name that can't be decoded can't have been
+		// scanned, so it's treated as unknown.
+		contentName, ok := t.filenames.decode(c.Name)
+		if !ok {
+			unknownContentEncountered = true
+			problem := t.filenames.undecodableNameProblem(path, c.Name)
+			t.recordProblem(problem.Path, errors.New("unknown content with undecodable name encountered on disk"))
+			continue
+		}
 		if t.recomposeUnicode {
 			contentName = norm.NFC.String(contentName)
 		}
 
-		// Compute the content path.
+		// Compute the content path and the name to use when accessing the
+		// content. If names are being transcoded, then we use the on-disk
+		// name directly.
 		contentPath := pathJoin(path, contentName)
+		contentAccessName := contentName
+		if t.filenames != nil {
+			contentAccessName = c.Name
+		}
 
 		// Grab the corresponding entry. If we don't know anything about this
-		// entry, then mark that as a problem and ignore for now.
+		// entry, then mark that as a problem and ignore for now. If we're
+		// preserving macOS metadata, then AppleDouble sidecar files are
+		// metadata (not content) and can be removed along with the directory.
 		entry, ok := expected.Contents[contentName]
-		if !ok {
+		if !ok && t.preserveMacOSMetadata && isAppleDoubleName(contentName) {
+			if err := directory.RemoveFile(c.Name); err != nil && !os.IsNotExist(errors.Cause(err)) {
+				contentRemovalFailed = true
+				t.recordProblem(contentPath, errors.Wrap(err, "unable to remove AppleDouble file"))
+			}
+			continue
+		} else if !ok {
 			unknownContentEncountered = true
 			t.recordProblem(contentPath, errors.New("unknown content encountered on disk"))
 			continue
 		}
 
+		// If the content is protected, then refuse to remove it. Its parent
+		// paths will have already been checked.
+		if t.protectedPaths.matches(contentPath) {
+			contentRemovalFailed = true
+			t.recordProblem(contentPath, errProtectedDeletion)
+			continue
+		}
+
 		// Handle content removal based on type.
 		if entry.Kind == EntryKind_Directory {
-			if !t.removeDirectory(directory, contentName, contentPath, entry) {
+			if !t.removeDirectory(directory, contentAccessName, contentPath, entry) {
 				contentRemovalFailed = true
 				continue
 			}
 		} else if entry.Kind == EntryKind_File {
-			if err = t.removeFile(directory, contentName, contentPath, entry); err != nil {
+			if err = t.removeFile(directory, contentAccessName, contentPath, entry); err != nil {
 				contentRemovalFailed = true
 				t.recordProblem(contentPath, errors.Wrap(err, "unable to remove file"))
 				continue
 			}
 		} else if entry.Kind == EntryKind_Symlink {
-			if err = t.removeSymbolicLink(directory, contentName, contentPath, entry); err != nil {
+			if err = t.removeSymbolicLink(directory, contentAccessName, contentPath, entry); err != nil {
 				contentRemovalFailed = true
 				t.recordProblem(contentPath, errors.Wrap(err, "unable to remove symbolic link"))
 				continue
@@ -513,13 +932,17 @@ ContentLoop:
 		if err := parent.RemoveDirectory(name); err != nil {
 			t.recordProblem(path, errors.Wrap(err, "unable to remove directory"))
 		} else {
+			t.removeAppleDouble(parent, name, path)
 			return true
 		}
 	}
 
 	// At this point, we must have encountered some sort of problem earlier, but
-	// it will already have been recorded, so we just need to make the removal
-	// as failed.
+	// it will already have been recorded, so we just need to restore any file
+	// flags that we cleared and mark the removal as failed.
+	if unlocked {
+		t.restoreFileFlags(path, expected)
+	}
 	return false
 }
 
@@ -565,6 +988,9 @@ func (t *transitioner) remove(path string, entry *Entry) *Entry {
 		return entry
 	}
 
+	// Flush the parent directory.
+	t.syncDirectory(parent, path)
+
 	// Success.
 	return nil
 }
@@ -599,11 +1025,36 @@ func (t *transitioner) findAndMoveStagedFileIntoPlace(
 		return errors.Wrap(err, "unable to locate staged file")
 	}
 
+	// Flush the staged file contents to durable storage (if required) before
+	// moving the file into place. This ensures that the file can't appear at
+	// its target location with incomplete contents after a crash.
+	if err := t.syncStagedFile(stagedPath); err != nil {
+		return err
+	}
+
+	// If the path requires verification, then read back the staged content
+	// from disk and verify it before it's moved into place.
+	verify := t.verifier.Requires(path)
+	if verify {
+		stagedFile, err := os.Open(stagedPath)
+		if err != nil {
+			return errors.Wrap(err, "unable to open staged file for verification")
+		}
+		if err := t.verifyStagedContent(path, target.Digest, stagedFile, func() {
+			os.Remove(stagedPath)
+		}); err != nil {
+			return err
+		}
+	}
+
 	// Set permissions for the staged file.
 	if err := filesystem.SetPermissionsByPath(stagedPath, t.defaultOwnership, mode); err != nil {
 		return errors.Wrap(err, "unable to set staged file permissions")
 	}
 
+	// Restore ACLs for the staged file.
+	t.restoreACL(path, stagedPath, target, mode)
+
 	// Attempt to atomically rename the file. If we succeed, we're done.
 	renameErr := filesystem.Rename(nil, stagedPath, parent, name)
 	if renameErr == nil {
@@ -639,15 +1090,30 @@ func (t *transitioner) findAndMoveStagedFileIntoPlace(
 		return errors.Wrap(err, "unable to create temporary file for cross-device rename")
 	}
 
-	// Wrap the temporary file in a preemptable writer to enable cancellation.
+	// Wrap the temporary file in a sparse writer to preserve holes and then in
+	// a preemptable writer to enable cancellation.
+	sparseTemporary := filesystem.NewSparseWriter(temporary)
 	preemptableTemporary := &preemptableWriter{
 		cancelled:     t.cancelled,
-		writer:        temporary,
+		writer:        sparseTemporary,
 		checkInterval: transitionCopyPreemptionInterval,
 	}
 
-	// Copy the file contents. We'll handle errors below.
-	_, copyErr := io.CopyBuffer(preemptableTemporary, stagedFile, t.copyBuffer)
+	// Copy the file contents, reading the staged file using hole detection,
+	// and then complete writing of any trailing hole. We'll handle errors
+	// below.
+	_, copyErr := io.CopyBuffer(preemptableTemporary, filesystem.NewSparseReader(stagedFile), t.copyBuffer)
+	if copyErr == nil {
+		copyErr = sparseTemporary.Finish()
+	}
+
+	// If the copy succeeded, then flush the temporary file contents to durable
+	// storage (if required) before moving the file into place.
+	if copyErr == nil && t.durabilityMode.SyncsFiles() {
+		if err := t.syncer.SyncFile(temporary); err != nil {
+			copyErr = errors.Wrap(err, "unable to flush intermediate file")
+		}
+	}
 
 	// Close out files.
 	stagedFile.Close()
@@ -662,6 +1128,23 @@ func (t *transitioner) findAndMoveStagedFileIntoPlace(
 		return errors.Wrap(copyErr, "unable to copy file contents")
 	}
 
+	// If the path requires verification, then also verify the intermediate
+	// file, since its content is what will be moved into place. If it fails
+	// verification, then the staged file is assumed to be corrupt as well.
+	if verify {
+		intermediate, err := parent.OpenFile(temporaryName)
+		if err != nil {
+			parent.RemoveFile(temporaryName)
+			return errors.Wrap(err, "unable to open intermediate file for verification")
+		}
+		if err := t.verifyStagedContent(path, target.Digest, intermediate, func() {
+			os.Remove(stagedPath)
+		}); err != nil {
+			parent.RemoveFile(temporaryName)
+			return err
+		}
+	}
+
 	// Set permissions on the temporary file.
 	if err := parent.SetPermissions(temporaryName, t.defaultOwnership, mode); err != nil {
 		parent.RemoveFile(temporaryName)
@@ -682,6 +1165,325 @@ func (t *transitioner) findAndMoveStagedFileIntoPlace(
 	return nil
 }
 
+// placePlaceholder creates a placeholder for the specified combination of path
+// and entry and moves it to the location specified by the combination of parent
+// directory and content name. The placeholder is an empty file with the
+// permissions of the target entry that's marked with the digest of the
+// target's content, allowing scans to treat it as if it had that content until
+// it's materialized.
+func (t *transitioner) placePlaceholder(
+	path string,
+	target *Entry,
+	parent *filesystem.Directory,
+	name string,
+) error {
+	// Compute the new file mode based on the new entry's executability.
+	mode := t.defaultFilePermissionMode
+	if target.Executable {
+		mode = markExecutableForReaders(mode)
+	}
+
+	// Create a temporary file in the target directory and close it, since we
+	// don't need to write any content.
+	temporaryName, temporary, err := parent.CreateTemporaryFile(placeholderTemporaryNamePrefix)
+	if err != nil {
+		return errors.Wrap(err, "unable to create temporary file for placeholder")
+	}
+	temporary.Close()
+
+	// Compute the filesystem path of the temporary file. Marking has to be
+	// performed by path, so we compute this based on the synchronization path.
+	var temporaryPath string
+	if path == "" {
+		temporaryPath = filepath.Join(filepath.Dir(t.root), temporaryName)
+	} else {
+		temporaryPath = filepath.Join(t.filenames.FilesystemPath(t.root, pathDir(path)), temporaryName)
+	}
+
+	// Mark the temporary file as a placeholder. This has to be done before the
+	// file is moved into place, otherwise a concurrent scan could see it as an
+	// empty file.
+	if err := filesystem.MarkPlaceholder(temporaryPath, target.Digest); err != nil {
+		parent.RemoveFile(temporaryName)
+		if errors.Cause(err) == filesystem.ErrPlaceholdersUnsupported {
+			return errors.New("placeholders not supported by filesystem")
+		}
+		return errors.Wrap(err, "unable to mark placeholder")
+	}
+
+	// Set permissions on the temporary file.
+	if err := parent.SetPermissions(temporaryName, t.defaultOwnership, mode); err != nil {
+		parent.RemoveFile(temporaryName)
+		return errors.Wrap(err, "unable to set placeholder permissions")
+	}
+
+	// Restore ACLs for the temporary file.
+	t.restoreACL(path, temporaryPath, target, mode)
+
+	// Rename the file.
+	if err := filesystem.Rename(parent, temporaryName, parent, name); err != nil {
+		parent.RemoveFile(temporaryName)
+		return errors.Wrap(err, "unable to relocate placeholder")
+	}
+
+	// Success.
+	return nil
+}
+
+// placeFile places content for the specified combination of path and entry at
+// the location specified by the combination of parent directory and content
+// name, either by moving a staged file into place or by creating a
+// placeholder, depending on the transition mode.
+func (t *transitioner) placeFile(
+	path string,
+	target *Entry,
+	parent *filesystem.Directory,
+	name string,
+) error {
+	if t.createPlaceholders {
+		return t.placePlaceholder(path, target, parent, name)
+	}
+	return t.findAndMoveStagedFileIntoPlace(path, target, parent, name)
+}
+
+// recordPlacedFile records the placement of a file from staging so that it can
+// be replaced with a hard link once all transitions have been performed. This
+// is a no-op if hard links aren't being preserved. If the placed file's
+// metadata can't be read, then the placement isn't recorded and the file will
+// remain a separate copy.
+func (t *transitioner) recordPlacedFile(parent *filesystem.Directory, name, path string, target *Entry) {
+	if !t.preserveHardLinks {
+		return
+	}
+	if metadata, err := parent.ReadContentMetadata(name); err == nil {
+		t.placedFiles[path] = &placedFile{target, metadata}
+	}
+}
+
+// ensurePlacedFile ensures that the file specified by name within the specified
+// directory hasn't been modified since its placement.
+func ensurePlacedFile(parent *filesystem.Directory, name string, placed *placedFile) error {
+	// Grab metadata for the file.
+	metadata, err := parent.ReadContentMetadata(name)
+	if err != nil {
+		return errors.Wrap(err, "unable to grab file statistics")
+	}
+
+	// Compare with the metadata recorded at placement.
+	match := metadata.Mode == placed.metadata.Mode &&
+		metadata.ModificationTime.Equal(placed.metadata.ModificationTime) &&
+		metadata.Size == placed.metadata.Size &&
+		metadata.FileID == placed.metadata.FileID
+	if !match {
+		return errors.New("modification detected")
+	}
+
+	// Success.
+	return nil
+}
+
+// linkPlacedFile replaces a file placed from staging with a hard link to the
+// file specified by the HardLink field of its target entry, verifying that
+// neither file has been modified. On failure, the placed file is left intact.
+func (t *transitioner) linkPlacedFile(path string, placed *placedFile) error {
+	// Walk down to the parent of the placed file and compute its leaf name. If
+	// we are successful, defer closure of the parent.
+	parent, name, err := t.walkToParentAndComputeLeafName(path, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to walk to transition root")
+	}
+	defer parent.Close()
+
+	// Ensure that the placed file hasn't been modified.
+	if err := ensurePlacedFile(parent, name, placed); err != nil {
+		return errors.Wrap(err, "unable to validate placed file")
+	}
+
+	// Walk down to the parent of the link target and compute its leaf name. If
+	// we are successful, defer closure of the parent.
+	targetPath := placed.target.HardLink
+	targetParent, targetName, err := t.walkToParentAndComputeLeafName(targetPath, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to walk to link target")
+	}
+	defer targetParent.Close()
+
+	// Ensure that the link target has the expected contents. If it was placed
+	// during this transition, then we compare against its placement metadata,
+	// otherwise we compare against the cache.
+	if targetPlaced, ok := t.placedFiles[targetPath]; ok {
+		if err := ensurePlacedFile(targetParent, targetName, targetPlaced); err != nil {
+			return errors.Wrap(err, "unable to validate link target")
+		}
+	} else if err := t.ensureExpectedFile(targetParent, targetName, targetPath, placed.target); err != nil {
+		return errors.Wrap(err, "unable to validate link target")
+	}
+
+	// RACE: There is a race condition here between the file checks and the
+	// file replacement that we have to live with due to limitations in
+	// filesystem APIs. The worst case fallout is replacement of contents that
+	// are modified during this window.
+
+	// Create the hard link under a temporary name.
+	temporaryName, err := parent.CreateTemporaryHardLink(hardLinkTemporaryNamePrefix, targetParent, targetName)
+	if err != nil {
+		if errors.Cause(err) == filesystem.ErrHardLinksUnsupported {
+			return errors.New("hard links not supported on this platform")
+		} else if filesystem.IsCrossDeviceError(errors.Cause(err)) {
+			return errors.New("link target resides on a different device")
+		}
+		return err
+	}
+
+	// Move the hard link into place.
+	if err := filesystem.Rename(parent, temporaryName, parent, name); err != nil {
+		parent.RemoveFile(temporaryName)
+		return errors.Wrap(err, "unable to relocate hard link")
+	}
+
+	// Flush the parent directory.
+	t.syncDirectory(parent, path)
+
+	// Success.
+	return nil
+}
+
+// linkPlacedFiles replaces files placed from staging during the transition with
+// hard links to the files indicated by their target entries, recreating the
+// target hard link structure. This is performed after all transitions since
+// link targets may not exist until then. Failures are recorded as problems but
+// are otherwise non-fatal, since the files will still have the correct
+// contents (just as separate copies).
+func (t *transitioner) linkPlacedFiles() {
+	// Compute the paths needing linking. We sort them to make the order of
+	// operations (and problem reporting) deterministic.
+	var paths []string
+	for path, placed := range t.placedFiles {
+		if placed.target.HardLink != "" {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	// Perform linking.
+	for _, path := range paths {
+		// Check for cancellation. Unlinked files still have the correct
+		// contents, so there's no need to record any problems.
+		select {
+		case <-t.cancelled:
+			return
+		default:
+		}
+
+		// Replace the file with a hard link.
+		if err := t.linkPlacedFile(path, t.placedFiles[path]); err != nil {
+			t.recordProblem(path, errors.Wrap(err, "unable to preserve hard link"))
+		}
+	}
+}
+
+// deferSymlink defers creation of the symbolic link at the specified path if
+// symbolic link creation is being deferred and the link's target lies within
+// the synchronization root but doesn't exist yet. It returns true if creation
+// was deferred, in which case created will be invoked if and when the link is
+// successfully created.
+func (t *transitioner) deferSymlink(path string, target *Entry, created func()) bool {
+	// Check whether or not deferral is required.
+	if !t.deferSymlinks {
+		return false
+	} else if targetPath, inTree := inTreeSymlinkTarget(path, target.Target); !inTree {
+		return false
+	} else if t.symlinkTargetExists(targetPath) {
+		return false
+	}
+
+	// Record the deferral.
+	t.deferredSymlinks = append(t.deferredSymlinks, &deferredSymlink{
+		path:    path,
+		target:  target,
+		created: created,
+	})
+	return true
+}
+
+// symlinkTargetExists determines whether or not content exists at the
+// specified in-tree symbolic link target path. Symbolic links along the path
+// are followed, so a dangling link at the path doesn't count as existing.
+func (t *transitioner) symlinkTargetExists(targetPath string) bool {
+	_, err := os.Stat(t.filenames.FilesystemPath(t.root, targetPath))
+	return err == nil
+}
+
+// createDeferredSymlink creates a symbolic link whose creation was deferred.
+func (t *transitioner) createDeferredSymlink(deferred *deferredSymlink) {
+	// Walk down to the parent of the link and compute its leaf name, deferring
+	// closure of the parent.
+	parent, name, err := t.walkToParentAndComputeLeafName(deferred.path, false)
+	if err != nil {
+		t.recordProblem(deferred.path, errors.Wrap(err, "unable to walk to transition root parent"))
+		return
+	}
+	defer parent.Close()
+
+	// Create the link and flush the parent directory.
+	if err := t.createSymbolicLink(parent, name, deferred.path, deferred.target); err != nil {
+		t.recordProblem(deferred.path, errors.Wrap(err, "unable to create symlink"))
+		return
+	}
+	t.syncDirectory(parent, deferred.path)
+
+	// Record the creation.
+	deferred.created()
+}
+
+// createDeferredSymlinks creates symbolic links whose creation was deferred.
+// Links are created in passes, with each pass creating those links whose
+// targets have since come into existence (possibly due to the creation of other
+// deferred links). Once no further progress can be made, any remaining links
+// are created anyway, since their targets didn't appear during the transition
+// and they would thus be dangling on the source endpoint as well.
+func (t *transitioner) createDeferredSymlinks() {
+	// Sort the deferred links by path to make the order of operations (and
+	// problem reporting) deterministic.
+	pending := t.deferredSymlinks
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].path < pending[j].path
+	})
+
+	// Perform creation passes.
+	for len(pending) > 0 {
+		// Check for cancellation.
+		select {
+		case <-t.cancelled:
+			for _, deferred := range pending {
+				t.recordProblem(deferred.path, errTransitionCancelled)
+			}
+			return
+		default:
+		}
+
+		// Create links whose targets now exist.
+		var remaining []*deferredSymlink
+		for _, deferred := range pending {
+			targetPath, _ := inTreeSymlinkTarget(deferred.path, deferred.target.Target)
+			if t.symlinkTargetExists(targetPath) {
+				t.createDeferredSymlink(deferred)
+			} else {
+				remaining = append(remaining, deferred)
+			}
+		}
+
+		// If no progress was made, then create the remaining links.
+		if len(remaining) == len(pending) {
+			for _, deferred := range remaining {
+				t.createDeferredSymlink(deferred)
+			}
+			return
+		}
+		pending = remaining
+	}
+}
+
 // swapFile atomically swaps files at the specified path, enforcing that the
 // existing file matches what's expected.
 func (t *transitioner) swapFile(path string, oldEntry, newEntry *Entry) error {
@@ -704,6 +1506,13 @@ func (t *transitioner) swapFile(path string, oldEntry, newEntry *Entry) error {
 	// APIs. The worst case fallout is replacement of contents that are modified
 	// during this window.
 
+	// Clear any locking file flags on the existing file so that it can be
+	// modified or replaced.
+	unlocked, err := t.unlockFileFlags(path, oldEntry)
+	if err != nil {
+		return err
+	}
+
 	// If both files have the same contents (differing only in executability),
 	// then we won't have staged the file, so we just change the permissions on
 	// the existing file.
@@ -723,15 +1532,40 @@ func (t *transitioner) swapFile(path string, oldEntry, newEntry *Entry) error {
 		// the transitioner, we could skip this call on systems where
 		// executability information is not preserved.
 		if err := parent.SetPermissions(name, t.defaultOwnership, mode); err != nil {
+			if unlocked {
+				t.restoreFileFlags(path, oldEntry)
+			}
 			return errors.Wrap(err, "unable to change file permissions")
 		}
 
+		// Restore ACLs, macOS metadata, and file flags for the file.
+		t.restoreACL(path, t.filenames.FilesystemPath(t.root, path), newEntry, mode)
+		t.restoreMacOSMetadata(path, newEntry)
+		t.deferFileFlags(path, newEntry)
+
 		// Success.
 		return nil
 	}
 
-	// Otherwise, we will have a staged file, so find it and move it into place.
-	return t.findAndMoveStagedFileIntoPlace(path, newEntry, parent, name)
+	// Otherwise, we will have a staged file (or will be creating a
+	// placeholder), so put the new content in place.
+	if err := t.placeFile(path, newEntry, parent, name); err != nil {
+		if unlocked {
+			t.restoreFileFlags(path, oldEntry)
+		}
+		return err
+	}
+
+	// Restore macOS metadata and file flags and record the placement.
+	t.restoreMacOSMetadata(path, newEntry)
+	t.deferFileFlags(path, newEntry)
+	t.recordPlacedFile(parent, name, path, newEntry)
+
+	// Flush the parent directory.
+	t.syncDirectory(parent, path)
+
+	// Success.
+	return nil
 }
 
 // createFile creates the target file at the specified path.
@@ -747,8 +1581,18 @@ func (t *transitioner) createFile(parent *filesystem.Directory, name, path strin
 	// filesystem APIs. The worst case fallout is replacement of contents that
 	// are created during this window.
 
-	// Find the staged file and move it into place.
-	return t.findAndMoveStagedFileIntoPlace(path, target, parent, name)
+	// Find the staged file (or create a placeholder) and move it into place.
+	if err := t.placeFile(path, target, parent, name); err != nil {
+		return err
+	}
+
+	// Restore macOS metadata and file flags and record the placement.
+	t.restoreMacOSMetadata(path, target)
+	t.deferFileFlags(path, target)
+	t.recordPlacedFile(parent, name, path, target)
+
+	// Success.
+	return nil
 }
 
 // createSymbolicLink creates the target symbolic link at the specified path.
@@ -774,8 +1618,12 @@ func (t *transitioner) createSymbolicLink(parent *filesystem.Directory, name, pa
 	// in filesystem APIs. The worst case fallout is replacement of contents
 	// that are created during this window.
 
-	// Create the symbolic link.
-	if err := parent.CreateSymbolicLink(name, target.Target); err != nil {
+	// Create the symbolic link, encoding its target if necessary.
+	encodedTarget, ok := t.filenames.encode(target.Target)
+	if !ok {
+		return errors.New("symbolic link target can't be represented using filename encoding")
+	}
+	if err := parent.CreateSymbolicLink(name, encodedTarget); err != nil {
 		return err
 	}
 
@@ -840,12 +1688,23 @@ func (t *transitioner) createDirectory(parent *filesystem.Directory, name, path
 	// Set directory permissions. If this fails, we abort the remainder of the
 	// operation because it's indicative of the fact that something's wrong.
 	// However, since we did succeed in creating the directory, we return that
-	// portion.
-	if err := parent.SetPermissions(name, t.defaultOwnership, t.defaultDirectoryPermissionMode); err != nil {
+	// portion. If the directory permission mode would prevent us from creating
+	// the directory's contents, then we use a looser mode for now and restrict
+	// permissions after all other operations are complete.
+	mode, restrict := t.directoryCreationPermissions(target)
+	if err := parent.SetPermissions(name, t.defaultOwnership, mode); err != nil {
 		t.recordProblem(path, errors.Wrap(err, "unable to set directory permissions"))
 		return created
 	}
 
+	// Restore ACLs for the directory. This is done before creating contents so
+	// that any default ACL is inherited by subdirectories (as it would have
+	// been on the source).
+	t.restoreACL(path, t.filenames.FilesystemPath(t.root, path), target, mode)
+
+	// Restore macOS metadata for the directory.
+	t.restoreMacOSMetadata(path, target)
+
 	// If there are contents in the target, allocate a map for created, because
 	// we'll need to populate it, and open the directory for operations
 	// (deferring its closure).
@@ -876,22 +1735,31 @@ ContentLoop:
 		default:
 		}
 
-		// Compute the content path.
+		// Compute the content path and on-disk name.
 		contentPath := pathJoin(path, name)
+		encodedName, ok := t.filenames.encode(name)
+		if !ok {
+			t.recordProblem(contentPath, errors.New("name can't be represented using filename encoding"))
+			continue
+		}
 
 		// Handle content creation based on type.
 		if entry.Kind == EntryKind_Directory {
-			if c := t.createDirectory(directory, name, contentPath, entry); c != nil {
+			if c := t.createDirectory(directory, encodedName, contentPath, entry); c != nil {
 				created.Contents[name] = c
 			}
 		} else if entry.Kind == EntryKind_File {
-			if err := t.createFile(directory, name, contentPath, entry); err != nil {
+			if err := t.createFile(directory, encodedName, contentPath, entry); err != nil {
 				t.recordProblem(contentPath, errors.Wrap(err, "unable to create file"))
 			} else {
 				created.Contents[name] = entry
 			}
 		} else if entry.Kind == EntryKind_Symlink {
-			if err := t.createSymbolicLink(directory, name, contentPath, entry); err != nil {
+			name, entry := name, entry
+			if t.deferSymlink(contentPath, entry, func() { created.Contents[name] = entry }) {
+				continue
+			}
+			if err := t.createSymbolicLink(directory, encodedName, contentPath, entry); err != nil {
 				t.recordProblem(contentPath, errors.Wrap(err, "unable to create symbolic link"))
 			} else {
 				created.Contents[name] = entry
@@ -901,6 +1769,22 @@ ContentLoop:
 		}
 	}
 
+	// If we created contents within the directory, then flush the directory.
+	if directory != nil {
+		t.syncDirectory(directory, path)
+	}
+
+	// Restrict permissions for the directory, if necessary. Since this is
+	// recorded after the directory's contents, subdirectories will be
+	// restricted first.
+	if restrict {
+		t.deferDirectoryPermissions(path, target)
+	}
+
+	// Restore file flags for the directory. Since this is recorded after the
+	// directory's contents, the flags will be restored after theirs.
+	t.deferFileFlags(path, target)
+
 	// Return the portion of the target that was created.
 	return created
 }
@@ -924,34 +1808,302 @@ func (t *transitioner) create(path string, target *Entry) *Entry {
 	defer parent.Close()
 
 	// Handle creation based on type.
+	var created *Entry
 	if target.Kind == EntryKind_Directory {
-		return t.createDirectory(parent, name, path, target)
+		created = t.createDirectory(parent, name, path, target)
 	} else if target.Kind == EntryKind_File {
 		if err := t.createFile(parent, name, path, target); err != nil {
 			t.recordProblem(path, errors.Wrap(err, "unable to create file"))
-			return nil
 		} else {
-			return target
+			created = target
 		}
 	} else if target.Kind == EntryKind_Symlink {
 		if err := t.createSymbolicLink(parent, name, path, target); err != nil {
 			t.recordProblem(path, errors.Wrap(err, "unable to create symlink"))
-			return nil
 		} else {
-			return target
+			created = target
 		}
 	} else {
 		t.recordProblem(path, errors.New("creation requested for unknown entry type"))
-		return nil
 	}
+
+	// If we created any content, then flush the parent directory.
+	if created != nil {
+		t.syncDirectory(parent, path)
+	}
+
+	// Done.
+	return created
+}
+
+// caseOnlyRename identifies a pair of transitions that together change only the
+// casing of a name.
+type caseOnlyRename struct {
+	// removal is the index of the transition removing the old name.
+	removal int
+	// creation is the index of the transition creating the new name.
+	creation int
+}
+
+// findCaseOnlyRenames identifies pairs of transitions that remove and create
+// names differing only in case within the same parent directory. On a
+// case-insensitive filesystem, these names refer to the same location, so such
+// pairs can't be performed independently. Names are only paired if no other
+// transition in the parent involves a name that's equivalent to them, since
+// the rename would otherwise be ambiguous. Pairs are returned in the order of
+// their removal transitions.
+func findCaseOnlyRenames(transitions []*Change) []caseOnlyRename {
+	// Group pure removals and creations by parent path and case-folded leaf
+	// name, tracking any other transitions involving the same locations. The
+	// synchronization root can't be renamed, so it's never grouped.
+	type group struct {
+		removals  []int
+		creations []int
+		others    int
+	}
+	groups := make(map[string]*group)
+	var keys []string
+	for i, t := range transitions {
+		if t.Path == "" {
+			continue
+		}
+		key := pathDir(t.Path) + "/" + strings.ToLower(PathBase(t.Path))
+		g, ok := groups[key]
+		if !ok {
+			g = &group{}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		if t.Old != nil && t.New == nil {
+			g.removals = append(g.removals, i)
+		} else if t.Old == nil && t.New != nil {
+			g.creations = append(g.creations, i)
+		} else {
+			g.others++
+		}
+	}
+
+	// Identify unambiguous pairs whose names actually differ.
+	var result []caseOnlyRename
+	for _, key := range keys {
+		g := groups[key]
+		if len(g.removals) != 1 || len(g.creations) != 1 || g.others != 0 {
+			continue
+		}
+		removal, creation := g.removals[0], g.creations[0]
+		if transitions[removal].Path == transitions[creation].Path {
+			continue
+		}
+		result = append(result, caseOnlyRename{removal, creation})
+	}
+
+	// Sort pairs by removal index.
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].removal < result[j].removal
+	})
+
+	// Done.
+	return result
+}
+
+// renameCaseOnly renames the content at the specified path to the specified
+// name with different casing, verifying that the content matches the expected
+// entry. The rename is performed in two steps through an intermediate name,
+// because some case-insensitive filesystems treat a direct case-only rename as
+// a no-op.
+func (t *transitioner) renameCaseOnly(path, newPath string, expected *Entry) error {
+	// Walk down to the parent and compute the leaf names.
+	parent, name, err := t.walkToParentAndComputeLeafName(path, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to walk to transition root")
+	}
+	defer parent.Close()
+	newName, ok := t.filenames.encode(PathBase(newPath))
+	if !ok {
+		return errors.New("new name can't be represented using filename encoding")
+	}
+
+	// Ensure that the existing content hasn't been modified from what we're
+	// expecting. For directories, we only verify that a directory exists,
+	// since the rename won't remove any of its contents.
+	switch expected.Kind {
+	case EntryKind_Directory:
+		if metadata, err := parent.ReadContentMetadata(name); err != nil {
+			return errors.Wrap(err, "unable to grab directory metadata")
+		} else if metadata.Mode&filesystem.ModeTypeMask != filesystem.ModeTypeDirectory {
+			return errors.New("modification detected")
+		}
+	case EntryKind_File:
+		if err := t.ensureExpectedFile(parent, name, path, expected); err != nil {
+			return errors.Wrap(err, "unable to validate existing file")
+		}
+	case EntryKind_Symlink:
+		if err := t.ensureExpectedSymbolicLink(parent, name, path, expected); err != nil {
+			return errors.Wrap(err, "unable to validate existing symbolic link")
+		}
+	default:
+		return errors.New("rename requested for unknown entry type")
+	}
+
+	// Clear any locking file flags so that the content can be renamed.
+	unlocked, err := t.unlockFileFlags(path, expected)
+	if err != nil {
+		return err
+	}
+
+	// Generate an intermediate name and ensure that it's unused.
+	randomUUID, err := uuid.NewRandom()
+	if err != nil {
+		return errors.Wrap(err, "unable to generate intermediate name")
+	}
+	temporaryName := caseRenameTemporaryNamePrefix + randomUUID.String()
+	if err := t.ensureNotExists(parent, temporaryName); err != nil {
+		return errors.Wrap(err, "unable to ensure intermediate name does not exist")
+	}
+
+	// Perform the rename in two steps, attempting to restore the original name
+	// if the second step fails.
+	if err := filesystem.Rename(parent, name, parent, temporaryName); err != nil {
+		if unlocked {
+			t.restoreFileFlags(path, expected)
+		}
+		return errors.Wrap(err, "unable to rename to intermediate name")
+	}
+	if err := filesystem.Rename(parent, temporaryName, parent, newName); err != nil {
+		filesystem.Rename(parent, temporaryName, parent, name)
+		if unlocked {
+			t.restoreFileFlags(path, expected)
+		}
+		return errors.Wrap(err, "unable to rename from intermediate name")
+	}
+
+	// Restore any file flags that were cleared.
+	if unlocked {
+		t.deferFileFlags(newPath, expected)
+	}
+
+	// Flush the parent directory.
+	t.syncDirectory(parent, newPath)
+
+	// Success.
+	return nil
+}
+
+// performCaseOnlyRenames performs case-only renames identified within the
+// specified transitions. If the old and new content are identical, then the
+// content is renamed in place. Otherwise, the removal is performed so that the
+// creation (which is left to the standard transition loop) won't collide with
+// the old name. It returns the results for the transitions that it handled,
+// keyed by their indices. Transitions that it doesn't handle (e.g. due to
+// cancellation or protection) are left to the standard transition loop.
+func (t *transitioner) performCaseOnlyRenames(transitions []*Change) map[int]*Entry {
+	results := make(map[int]*Entry)
+	for _, rename := range findCaseOnlyRenames(transitions) {
+		// Check for cancellation.
+		select {
+		case <-t.cancelled:
+			return results
+		default:
+		}
+
+		// Leave protected content to the standard transition loop, which will
+		// refuse its removal.
+		removal, creation := transitions[rename.removal], transitions[rename.creation]
+		if t.protectedPaths.Protected(removal.Path) {
+			continue
+		}
+
+		// If the content is unchanged, then rename it in place. If this fails,
+		// then neither transition has been performed.
+		if removal.Old.Equal(creation.New) {
+			if err := t.renameCaseOnly(removal.Path, creation.Path, removal.Old); err != nil {
+				t.recordProblem(creation.Path, errors.Wrap(err, "unable to perform case-only rename"))
+				results[rename.removal] = removal.Old
+				results[rename.creation] = nil
+			} else {
+				results[rename.removal] = nil
+				results[rename.creation] = creation.New
+			}
+			continue
+		}
+
+		// Otherwise perform the removal ahead of the creation.
+		results[rename.removal] = t.remove(removal.Path, removal.Old)
+	}
+	return results
+}
+
+// TransitionOptions specifies optional transitioning behavior. The zero value
+// corresponds to the default transitioning behavior.
+type TransitionOptions struct {
+	// DeferSymlinks indicates that symbolic links with targets inside the
+	// synchronization root should only be created once those targets exist,
+	// with links whose targets don't appear during the transition being
+	// created at its end.
+	DeferSymlinks bool
+	// DurabilityMode specifies which modifications are flushed to durable
+	// storage. The default mode doesn't flush any modifications.
+	DurabilityMode DurabilityMode
+	// Syncer is the syncer used to flush modifications to durable storage. It
+	// must be non-nil if the durability mode requires flushing.
+	Syncer filesystem.Syncer
+	// ACLMode specifies the handling of POSIX ACLs. If it's
+	// ACLMode_ACLModePropagate, then ACLs recorded in target entries are
+	// restored on a best-effort basis, with failures reported as problems.
+	ACLMode ACLMode
+	// PreserveHardLinks indicates that files created from staging with a
+	// recorded hard link should be replaced with hard links to their link
+	// targets once all transitions are complete, with failures (e.g. on
+	// platforms or filesystems that don't support hard links) leaving separate
+	// copies and being reported as problems.
+	PreserveHardLinks bool
+	// PreserveMacOSMetadata indicates that macOS metadata recorded in target
+	// entries should be restored natively where possible and to AppleDouble
+	// sidecar files otherwise, with failures being reported as problems.
+	PreserveMacOSMetadata bool
+	// PreserveFileFlags indicates that file flags recorded in target entries
+	// should be restored once all other operations are complete (with flags
+	// that can't be represented on this platform being reported as problems),
+	// and that locking flags (e.g. user immutable) recorded in expected entries
+	// should be cleared before those entries are modified or removed.
+	PreserveFileFlags bool
+	// CreatePlaceholders indicates that new file content should be represented
+	// by (empty) placeholder files marked with the content digest, rather than
+	// being moved into place from the provider (which isn't used), with the
+	// content being materialized on demand by a Materializer.
+	CreatePlaceholders bool
+	// CaseInsensitive indicates that the filesystem is case-insensitive, in
+	// which case pairs of transitions that only change the casing of a name are
+	// performed as a rename through an intermediate name, since the removal and
+	// creation would otherwise collide.
+	CaseInsensitive bool
+	// ProtectedPaths is an optional protected path matcher. Transitions that
+	// would delete or overwrite protected content are refused (leaving that
+	// content in place) and reported as problems.
+	ProtectedPaths *ProtectedPathMatcher
+	// Verifier is an optional staged content verifier. Staged content for paths
+	// requiring verification is read back from disk and verified before being
+	// moved into place, with content that fails verification being discarded
+	// (leaving any existing content in place and treating the content as
+	// missing from the provider).
+	Verifier *StagedContentVerifier
+	// Filenames is an optional filename transcoder. If provided, then names and
+	// symbolic link targets are encoded when stored on disk (and decoded when
+	// read from disk), with content whose name or target can't be represented
+	// reported as a problem.
+	Filenames *FilenameTranscoder
 }
 
 // Transition provides recursive filesystem transitioning facilities for
 // synchronization roots, allowing the application of changes after
 // reconciliation. The path to the provided synchronization root must be
-// absolute and normalized (using filepath.Clean). The function returns a slice
-// of the resulting entries, problems, and a boolean indicating whether or not
-// the provider was missing files.
+// absolute and normalized (using filepath.Clean). Optional transitioning
+// behavior is controlled by the specified options, which may be nil to use the
+// default behavior. If the directory permission mode would prevent the
+// creation of content within directories, then directories are populated with
+// looser permissions and restricted once all other operations are complete.
+// The function returns a slice of the resulting entries, problems, and a
+// boolean indicating whether or not the provider was missing files.
 func Transition(
 	ctx context.Context,
 	root string,
@@ -963,7 +2115,13 @@ func Transition(
 	defaultOwnership *filesystem.OwnershipSpecification,
 	recomposeUnicode bool,
 	provider Provider,
+	options *TransitionOptions,
 ) ([]*Entry, []*Problem, bool) {
+	// Use the default options if none were specified.
+	if options == nil {
+		options = &TransitionOptions{}
+	}
+
 	// Extract the cancellation channel.
 	cancelled := ctx.Done()
 
@@ -973,19 +2131,49 @@ func Transition(
 		root:                           root,
 		cache:                          cache,
 		symlinkMode:                    symlinkMode,
+		deferSymlinks:                  options.DeferSymlinks,
 		defaultFilePermissionMode:      defaultFilePermissionMode,
 		defaultDirectoryPermissionMode: defaultDirectoryPermissionMode,
 		defaultOwnership:               defaultOwnership,
 		copyBuffer:                     make([]byte, transitionCopyBufferSize),
 		recomposeUnicode:               recomposeUnicode,
+		filenames:                      options.Filenames,
+		durabilityMode:                 options.DurabilityMode,
+		syncer:                         options.Syncer,
 		provider:                       provider,
+		restoreACLs:                    options.ACLMode == ACLMode_ACLModePropagate,
+		preserveHardLinks:              options.PreserveHardLinks,
+		preserveMacOSMetadata:          options.PreserveMacOSMetadata,
+		preserveFileFlags:              options.PreserveFileFlags,
+		createPlaceholders:             options.CreatePlaceholders,
+		caseInsensitive:                options.CaseInsensitive,
+		protectedPaths:                 options.ProtectedPaths,
+		verifier:                       options.Verifier,
+	}
+	if options.PreserveHardLinks {
+		transitioner.placedFiles = make(map[string]*placedFile)
 	}
 
 	// Set up results.
 	var results []*Entry
 
+	// If the filesystem is case-insensitive, then perform any case-only renames
+	// before other transitions, since the creation of the new name would
+	// otherwise collide with the existing content at the old name.
+	var renamed map[int]*Entry
+	if options.CaseInsensitive {
+		renamed = transitioner.performCaseOnlyRenames(transitions)
+	}
+
 	// Iterate through transitions.
-	for _, t := range transitions {
+	for i, t := range transitions {
+		// If the transition was handled as part of a case-only rename, then
+		// just record its result.
+		if result, ok := renamed[i]; ok {
+			results = append(results, result)
+			continue
+		}
+
 		// Check for cancellation. Even if cancelled, we still need to yield a
 		// result, so we'll continue looping through transitions and just mark
 		// them as having encountered cancellation.
@@ -997,6 +2185,19 @@ func Transition(
 		default:
 		}
 
+		// If the transition would delete or overwrite protected content at its
+		// path, then refuse it. Protected content below the path is handled
+		// during removal.
+		if t.Old != nil && transitioner.protectedPaths.Protected(t.Path) {
+			results = append(results, t.Old)
+			if t.New == nil {
+				transitioner.recordProblem(t.Path, errProtectedDeletion)
+			} else {
+				transitioner.recordProblem(t.Path, errProtectedOverwrite)
+			}
+			continue
+		}
+
 		// Handle the special case where both old and new are a file. In this
 		// case we can do a simple swap. It makes sense to handle this specially
 		// because it is a very common case and doing it with a swap will remove
@@ -1022,12 +2223,39 @@ func Transition(
 			continue
 		}
 
-		// At this point, we should have nil on disk. Transition to whatever the
-		// new entry is (or at least as much of it as we can create). If the new
-		// entry is nil, this is a no-op.
+		// At this point, we should have nil on disk. If the new entry is a
+		// symbolic link whose creation needs to be deferred, then record a nil
+		// result for now and update it if the link is created later.
+		if t.New != nil && t.New.Kind == EntryKind_Symlink {
+			index, target := len(results), t.New
+			if transitioner.deferSymlink(t.Path, target, func() { results[index] = target }) {
+				results = append(results, nil)
+				continue
+			}
+		}
+
+		// Transition to whatever the new entry is (or at least as much of it as
+		// we can create). If the new entry is nil, this is a no-op.
 		results = append(results, transitioner.create(t.Path, t.New))
 	}
 
+	// Create any symbolic links whose creation was deferred.
+	transitioner.createDeferredSymlinks()
+
+	// If we're preserving hard links, then recreate the hard link structure
+	// for files that we've placed.
+	if options.PreserveHardLinks {
+		transitioner.linkPlacedFiles()
+	}
+
+	// Restrict directory permissions now that the directories' contents have
+	// been created. This must occur before file flags are restored, since
+	// locking flags would prevent permission changes.
+	transitioner.restoreDeferredDirectoryPermissions()
+
+	// Restore file flags now that all other operations are complete.
+	transitioner.restoreDeferredFileFlags()
+
 	// Done.
 	return results, transitioner.problems, transitioner.providerMissingFiles
 }
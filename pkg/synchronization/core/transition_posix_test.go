@@ -0,0 +1,198 @@
+// +build !windows
+
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+const (
+	// restrictiveDirectoryPermissionMode is a directory permission mode that
+	// prevents the owner from creating content within directories.
+	restrictiveDirectoryPermissionMode = filesystem.ModePermissionUserRead | filesystem.ModePermissionUserExecute |
+		filesystem.ModePermissionGroupRead | filesystem.ModePermissionGroupExecute |
+		filesystem.ModePermissionOthersRead | filesystem.ModePermissionOthersExecute
+)
+
+// TestDirectoryCreationPermissions tests that directory creation permissions
+// are only loosened for restrictive modes and directories with contents.
+func TestDirectoryCreationPermissions(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		mode     filesystem.Mode
+		target   *Entry
+		expected filesystem.Mode
+		restrict bool
+	}{
+		{defaultDirectoryPermissionMode, testDirectory1Entry, defaultDirectoryPermissionMode, false},
+		{restrictiveDirectoryPermissionMode, testEmptyDirectory, restrictiveDirectoryPermissionMode, false},
+		{restrictiveDirectoryPermissionMode, testDirectory1Entry, restrictiveDirectoryPermissionMode | filesystem.ModePermissionUserWrite, true},
+		{filesystem.ModePermissionUserWrite, testDirectory1Entry, directoryPopulationPermissions, true},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		transitioner := &transitioner{defaultDirectoryPermissionMode: testCase.mode}
+		mode, restrict := transitioner.directoryCreationPermissions(testCase.target)
+		if mode != testCase.expected {
+			t.Errorf("test case %d: creation mode incorrect: %o != %o", i, mode, testCase.expected)
+		}
+		if restrict != testCase.restrict {
+			t.Errorf("test case %d: restriction requirement incorrect: %t != %t", i, restrict, testCase.restrict)
+		}
+	}
+}
+
+// makeTreeWritable makes all directories within the specified root writable so
+// that the root can be removed.
+func makeTreeWritable(root string) {
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() {
+			os.Chmod(path, 0700)
+		}
+		return nil
+	})
+}
+
+// TestTransitionRestrictiveDirectoryPermissions tests that directories created
+// with a restrictive permission mode are populated with their contents (as
+// well as deferred symbolic links) before being restricted, while other
+// content in the transition is simultaneously modified.
+func TestTransitionRestrictiveDirectoryPermissions(t *testing.T) {
+	// Create test content on disk with the default directory permission mode
+	// and defer its removal.
+	root, parent, err := testTransitionCreate("", testDirectory1Entry, testDirectory1ContentMap, false)
+	if err != nil {
+		t.Fatal("unable to create test content:", err)
+	}
+	defer func() {
+		makeTreeWritable(parent)
+		os.RemoveAll(parent)
+	}()
+
+	// Perform a scan to grab Unicode recomposition behavior and a cache.
+	_, _, recomposeUnicode, cache, _, _, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		newTestHasher(),
+		nil,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	}
+
+	// Compute a replacement for the directory with modified contents.
+	oldDirectory := testDirectory1Entry.Contents["directory"]
+	newDirectory := &Entry{
+		Kind: EntryKind_Directory,
+		Contents: map[string]*Entry{
+			"subdirectory": {
+				Kind: EntryKind_Directory,
+				Contents: map[string]*Entry{
+					"new file": testFile1Entry,
+				},
+			},
+			"subfile": testFile2Entry,
+			"another symlink": {
+				Kind:   EntryKind_Symlink,
+				Target: "../executable file",
+			},
+		},
+	}
+
+	// Create a provider and defer its cleanup.
+	provider, err := newTestProvider(map[string][]byte{
+		"directory/subdirectory/new file": testFile1Contents,
+		"directory/subfile":               testFile2Contents,
+		"file":                            testFile2Contents,
+	}, newTestHasher())
+	if err != nil {
+		t.Fatal("unable to create provider:", err)
+	}
+	defer provider.finalize()
+
+	// Replace the directory using a restrictive directory permission mode while
+	// also swapping a file at the root. We defer symbolic link creation so that
+	// links within the directory are created after its contents.
+	changes := []*Change{
+		{Path: "directory", Old: oldDirectory, New: newDirectory},
+		{Path: "file", Old: testFile1Entry, New: testFile2Entry},
+	}
+	results, problems, providerMissingFiles := Transition(
+		context.Background(),
+		root,
+		changes,
+		cache,
+		SymlinkMode_SymlinkModePortable,
+		defaultFilePermissionMode,
+		restrictiveDirectoryPermissionMode,
+		nil,
+		recomposeUnicode,
+		provider,
+		&TransitionOptions{
+			DeferSymlinks:  true,
+			DurabilityMode: DurabilityMode_DurabilityModeFull,
+			Syncer:         filesystem.SystemSyncer,
+			ACLMode:        ACLMode_ACLModeIgnore,
+		},
+	)
+
+	// Verify that all changes were applied successfully.
+	if len(problems) != 0 {
+		t.Fatal("problems encountered during transition:", problems[0].Path, problems[0].Error)
+	} else if providerMissingFiles {
+		t.Error("provider indicated missing files")
+	}
+	if len(results) != len(changes) {
+		t.Fatal("result count incorrect:", len(results), "!=", len(changes))
+	}
+	for i, change := range changes {
+		if !results[i].Equal(change.New) {
+			t.Error("result does not match target for path:", change.Path)
+		}
+	}
+
+	// Verify file contents.
+	for path, expected := range map[string][]byte{
+		"directory/subdirectory/new file": testFile1Contents,
+		"directory/subfile":               testFile2Contents,
+		"file":                            testFile2Contents,
+	} {
+		if contents, err := ioutil.ReadFile(filepath.Join(root, filepath.FromSlash(path))); err != nil {
+			t.Error("unable to read file:", err)
+		} else if string(contents) != string(expected) {
+			t.Error("file contents incorrect for path:", path)
+		}
+	}
+
+	// Verify that the deferred symbolic link was created.
+	if _, err := os.Lstat(filepath.Join(root, "directory", "another symlink")); err != nil {
+		t.Error("unable to find deferred symbolic link:", err)
+	}
+
+	// Verify that the directories were restricted.
+	for _, path := range []string{"directory", "directory/subdirectory"} {
+		if info, err := os.Lstat(filepath.Join(root, filepath.FromSlash(path))); err != nil {
+			t.Error("unable to query directory:", err)
+		} else if mode := filesystem.Mode(info.Mode().Perm()); mode != restrictiveDirectoryPermissionMode {
+			t.Errorf("directory permissions incorrect for %s: %o != %o", path, mode, restrictiveDirectoryPermissionMode)
+		}
+	}
+}
@@ -204,6 +204,11 @@ func testTransitionCreate(temporaryDirectory string, entry *Entry, contentMap ma
 		nil,
 		recomposeUnicode,
 		provider,
+		&TransitionOptions{
+			DurabilityMode: DurabilityMode_DurabilityModeFull,
+			Syncer:         filesystem.SystemSyncer,
+			ACLMode:        ACLMode_ACLModeIgnore,
+		},
 	); len(problems) != 0 {
 		os.RemoveAll(parent)
 		return "", "", errors.New("problems occurred during creation transition")
@@ -261,6 +266,11 @@ func testTransitionRemove(root string, expected *Entry, cache *Cache, symlinkMod
 		nil,
 		recomposeUnicode,
 		nil,
+		&TransitionOptions{
+			DurabilityMode: DurabilityMode_DurabilityModeFull,
+			Syncer:         filesystem.SystemSyncer,
+			ACLMode:        ACLMode_ACLModeIgnore,
+		},
 	); len(problems) != 0 {
 		return errors.New("problems occurred during removal transition")
 	} else if len(entries) != len(transitions) {
@@ -302,7 +312,7 @@ func testTransitionCycle(temporaryDirectory string, entry *Entry, contentMap map
 	}
 
 	// Perform a scan.
-	snapshot, preservesExecutability, _, cache, _, err := Scan(
+	snapshot, preservesExecutability, _, cache, _, _, err := Scan(
 		context.Background(),
 		root,
 		nil,
@@ -313,6 +323,10 @@ func testTransitionCycle(temporaryDirectory string, entry *Entry, contentMap map
 		nil,
 		behavior.ProbeMode_ProbeModeProbe,
 		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
 	)
 	if !preservesExecutability {
 		snapshot = PropagateExecutability(nil, expected, snapshot)
@@ -406,7 +420,7 @@ func TestTransitionSwapFile(t *testing.T) {
 	// attempt an additional create transition.
 	modifier := func(root string, expected *Entry) (*Entry, error) {
 		// Perform a scan to grab Unicode recomposition behavior and a cache.
-		_, _, recomposeUnicode, cache, _, err := Scan(
+		_, _, recomposeUnicode, cache, _, _, err := Scan(
 			context.Background(),
 			root,
 			nil,
@@ -417,6 +431,10 @@ func TestTransitionSwapFile(t *testing.T) {
 			nil,
 			behavior.ProbeMode_ProbeModeProbe,
 			SymlinkMode_SymlinkModePortable,
+			&ScanOptions{
+				BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+				ACLMode:           ACLMode_ACLModeIgnore,
+			},
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "unable to perform scan")
@@ -456,6 +474,11 @@ func TestTransitionSwapFile(t *testing.T) {
 			nil,
 			recomposeUnicode,
 			provider,
+			&TransitionOptions{
+				DurabilityMode: DurabilityMode_DurabilityModeFull,
+				Syncer:         filesystem.SystemSyncer,
+				ACLMode:        ACLMode_ACLModeIgnore,
+			},
 		); len(problems) != 0 {
 			return nil, errors.New("file swap transition failed")
 		} else if providerMissingFiles {
@@ -483,7 +506,7 @@ func TestTransitionSwapFileOnlyExecutableChange(t *testing.T) {
 	// attempt an additional create transition.
 	modifier := func(root string, expected *Entry) (*Entry, error) {
 		// Perform a scan to grab Unicode recomposition behavior and a cache.
-		_, _, recomposeUnicode, cache, _, err := Scan(
+		_, _, recomposeUnicode, cache, _, _, err := Scan(
 			context.Background(),
 			root,
 			nil,
@@ -494,6 +517,10 @@ func TestTransitionSwapFileOnlyExecutableChange(t *testing.T) {
 			nil,
 			behavior.ProbeMode_ProbeModeProbe,
 			SymlinkMode_SymlinkModePortable,
+			&ScanOptions{
+				BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+				ACLMode:           ACLMode_ACLModeIgnore,
+			},
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "unable to perform scan")
@@ -525,6 +552,11 @@ func TestTransitionSwapFileOnlyExecutableChange(t *testing.T) {
 			nil,
 			recomposeUnicode,
 			nil,
+			&TransitionOptions{
+				DurabilityMode: DurabilityMode_DurabilityModeFull,
+				Syncer:         filesystem.SystemSyncer,
+				ACLMode:        ACLMode_ACLModeIgnore,
+			},
 		); len(problems) != 0 {
 			return nil, errors.New("file swap transition failed")
 		} else if len(entries) != 1 {
@@ -592,7 +624,7 @@ func TestTransitionFailCreateInvalidPathCase(t *testing.T) {
 	// attempt an additional create transition.
 	modifier := func(root string, expected *Entry) (*Entry, error) {
 		// Perform a scan to grab Unicode recomposition behavior and a cache.
-		_, _, recomposeUnicode, cache, _, err := Scan(
+		_, _, recomposeUnicode, cache, _, _, err := Scan(
 			context.Background(),
 			root,
 			nil,
@@ -603,6 +635,10 @@ func TestTransitionFailCreateInvalidPathCase(t *testing.T) {
 			nil,
 			behavior.ProbeMode_ProbeModeProbe,
 			SymlinkMode_SymlinkModePortable,
+			&ScanOptions{
+				BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+				ACLMode:           ACLMode_ACLModeIgnore,
+			},
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "unable to perform scan")
@@ -646,6 +682,11 @@ func TestTransitionFailCreateInvalidPathCase(t *testing.T) {
 			nil,
 			recomposeUnicode,
 			provider,
+			&TransitionOptions{
+				DurabilityMode: DurabilityMode_DurabilityModeFull,
+				Syncer:         filesystem.SystemSyncer,
+				ACLMode:        ACLMode_ACLModeIgnore,
+			},
 		); len(problems) == 0 {
 			return nil, errors.New("transition succeeded unexpectedly")
 		} else if providerMissingFiles {
@@ -746,6 +787,11 @@ func TestTransitionFailOnParentPathIsFile(t *testing.T) {
 		nil,
 		false,
 		provider,
+		&TransitionOptions{
+			DurabilityMode: DurabilityMode_DurabilityModeFull,
+			Syncer:         filesystem.SystemSyncer,
+			ACLMode:        ACLMode_ACLModeIgnore,
+		},
 	); len(problems) != 1 {
 		t.Error("transition succeeded unexpectedly")
 	} else if providerMissingFiles {
@@ -756,3 +802,440 @@ func TestTransitionFailOnParentPathIsFile(t *testing.T) {
 		t.Error("failed creation transition returned non-nil entry")
 	}
 }
+
+// testRecordingSyncer is a filesystem.Syncer implementation that records flush
+// operations without performing them.
+type testRecordingSyncer struct {
+	// fileSyncs is the number of file flushes requested.
+	fileSyncs int
+	// directorySyncs is the number of directory flushes requested.
+	directorySyncs int
+}
+
+// SyncFile implements filesystem.Syncer.SyncFile.
+func (s *testRecordingSyncer) SyncFile(_ filesystem.SyncableFile) error {
+	s.fileSyncs++
+	return nil
+}
+
+// SyncDirectory implements filesystem.Syncer.SyncDirectory.
+func (s *testRecordingSyncer) SyncDirectory(_ *filesystem.Directory) error {
+	s.directorySyncs++
+	return nil
+}
+
+// testTransitionWithSyncer performs the specified transitions at the specified
+// root using the specified durability mode and syncer, returning a cache
+// generated by a subsequent scan.
+func testTransitionWithSyncer(
+	root string,
+	transitions []*Change,
+	cache *Cache,
+	contentMap map[string][]byte,
+	durabilityMode DurabilityMode,
+	syncer filesystem.Syncer,
+) (*Cache, error) {
+	// Create a provider and ensure its cleanup.
+	provider, err := newTestProvider(contentMap, newTestHasher())
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create test provider")
+	}
+	defer provider.finalize()
+
+	// Perform the transition.
+	if _, problems, providerMissingFiles := Transition(
+		context.Background(),
+		root,
+		transitions,
+		cache,
+		SymlinkMode_SymlinkModePortable,
+		defaultFilePermissionMode,
+		defaultDirectoryPermissionMode,
+		nil,
+		false,
+		provider,
+		&TransitionOptions{
+			DurabilityMode: durabilityMode,
+			Syncer:         syncer,
+			ACLMode:        ACLMode_ACLModeIgnore,
+		},
+	); len(problems) != 0 {
+		return nil, errors.New("problems occurred during transition")
+	} else if providerMissingFiles {
+		return nil, errors.New("provider indicated missing files")
+	}
+
+	// Perform a scan to generate a cache for subsequent transitions.
+	_, _, _, cache, _, _, err = Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		newTestHasher(),
+		nil,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to perform scan")
+	}
+
+	// Success.
+	return cache, nil
+}
+
+// TestTransitionDurabilityMode tests that Transition flushes files and
+// directories as required by the durability mode when creating, swapping, and
+// removing files.
+func TestTransitionDurabilityMode(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                   DurabilityMode
+		expectedFileSyncs      int
+		expectedDirectorySyncs int
+	}{
+		{DurabilityMode_DurabilityModeFull, 2, 3},
+		{DurabilityMode_DurabilityModeMetadata, 0, 3},
+		{DurabilityMode_DurabilityModeNone, 0, 0},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		// Create a temporary directory to act as the parent of our root.
+		parent, err := ioutil.TempDir("", "mutagen_simulated")
+		if err != nil {
+			t.Fatal("unable to create temporary root parent:", err)
+		}
+		root := filepath.Join(parent, "root")
+
+		// Create, swap, and remove a file.
+		syncer := &testRecordingSyncer{}
+		cache, err := testTransitionWithSyncer(
+			root, []*Change{{New: testFile1Entry}}, nil,
+			testFile1ContentMap, testCase.mode, syncer,
+		)
+		if err == nil {
+			cache, err = testTransitionWithSyncer(
+				root, []*Change{{Old: testFile1Entry, New: testFile2Entry}}, cache,
+				testFile2ContentMap, testCase.mode, syncer,
+			)
+		}
+		if err == nil {
+			_, err = testTransitionWithSyncer(
+				root, []*Change{{Old: testFile2Entry}}, cache,
+				nil, testCase.mode, syncer,
+			)
+		}
+		os.RemoveAll(parent)
+		if err != nil {
+			t.Fatalf("unable to perform transitions in %s mode: %v", testCase.mode, err)
+		}
+
+		// Verify flush operations.
+		if syncer.fileSyncs != testCase.expectedFileSyncs {
+			t.Errorf(
+				"file flush count (%d) does not match expected (%d) in %s mode",
+				syncer.fileSyncs,
+				testCase.expectedFileSyncs,
+				testCase.mode,
+			)
+		}
+		if syncer.directorySyncs != testCase.expectedDirectorySyncs {
+			t.Errorf(
+				"directory flush count (%d) does not match expected (%d) in %s mode",
+				syncer.directorySyncs,
+				testCase.expectedDirectorySyncs,
+				testCase.mode,
+			)
+		}
+	}
+}
+
+// TestFindCaseOnlyRenames tests that case-only renames are identified only for
+// unambiguous pairs of removals and creations.
+func TestFindCaseOnlyRenames(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		description string
+		transitions []*Change
+		expected    []caseOnlyRename
+	}{
+		{
+			"no transitions",
+			nil,
+			nil,
+		},
+		{
+			"case-only rename",
+			[]*Change{
+				{Path: "README.md", New: testFile1Entry},
+				{Path: "readme.md", Old: testFile1Entry},
+			},
+			[]caseOnlyRename{{removal: 1, creation: 0}},
+		},
+		{
+			"nested case-only renames",
+			[]*Change{
+				{Path: "a/file", Old: testFile1Entry},
+				{Path: "a/File", New: testFile2Entry},
+				{Path: "b/Directory", Old: testDirectory1Entry},
+				{Path: "b/directory", New: testDirectory1Entry},
+			},
+			[]caseOnlyRename{{removal: 0, creation: 1}, {removal: 2, creation: 3}},
+		},
+		{
+			"different parents",
+			[]*Change{
+				{Path: "a/file", Old: testFile1Entry},
+				{Path: "b/FILE", New: testFile1Entry},
+			},
+			nil,
+		},
+		{
+			"different names",
+			[]*Change{
+				{Path: "file", Old: testFile1Entry},
+				{Path: "other", New: testFile1Entry},
+			},
+			nil,
+		},
+		{
+			"modification",
+			[]*Change{
+				{Path: "file", Old: testFile1Entry, New: testFile2Entry},
+			},
+			nil,
+		},
+		{
+			"ambiguous creations",
+			[]*Change{
+				{Path: "file", Old: testFile1Entry},
+				{Path: "FILE", New: testFile1Entry},
+				{Path: "File", New: testFile1Entry},
+			},
+			nil,
+		},
+		{
+			"conflicting modification",
+			[]*Change{
+				{Path: "file", Old: testFile1Entry},
+				{Path: "FILE", New: testFile1Entry},
+				{Path: "File", Old: testFile1Entry, New: testFile2Entry},
+			},
+			nil,
+		},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		renames := findCaseOnlyRenames(testCase.transitions)
+		if len(renames) != len(testCase.expected) {
+			t.Errorf("%s: unexpected number of renames: %d != %d",
+				testCase.description, len(renames), len(testCase.expected),
+			)
+			continue
+		}
+		for r, rename := range renames {
+			if rename != testCase.expected[r] {
+				t.Errorf("%s: rename %d does not match expected: %v != %v",
+					testCase.description, r, rename, testCase.expected[r],
+				)
+			}
+		}
+	}
+}
+
+// testTransitionCaseOnlyRename creates testDirectory1Entry on disk, performs
+// the specified transitions using the specified provider contents, and verifies
+// that they yield the expected results without problems. The transitions are
+// performed as if on a case-insensitive filesystem, regardless of whether or
+// not the test filesystem actually is, so that the same code paths are
+// exercised on all platforms. It returns the synchronization root path and the
+// parent path that should be removed by the caller.
+func testTransitionCaseOnlyRename(
+	t *testing.T,
+	transitions []*Change,
+	contentMap map[string][]byte,
+	expectedResults []*Entry,
+) (string, string) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create test content on disk.
+	root, parent, err := testTransitionCreate("", testDirectory1Entry, testDirectory1ContentMap, false)
+	if err != nil {
+		t.Fatal("unable to create test content:", err)
+	}
+
+	// Perform a scan to grab Unicode recomposition behavior and a cache.
+	_, _, recomposeUnicode, cache, _, _, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		newTestHasher(),
+		nil,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		os.RemoveAll(parent)
+		t.Fatal("unable to perform scan:", err)
+	}
+
+	// Create a provider and ensure its cleanup.
+	provider, err := newTestProvider(contentMap, newTestHasher())
+	if err != nil {
+		os.RemoveAll(parent)
+		t.Fatal("unable to create provider:", err)
+	}
+	defer provider.finalize()
+
+	// Perform the transition.
+	results, problems, providerMissingFiles := Transition(
+		context.Background(),
+		root,
+		transitions,
+		cache,
+		SymlinkMode_SymlinkModePortable,
+		defaultFilePermissionMode,
+		defaultDirectoryPermissionMode,
+		nil,
+		recomposeUnicode,
+		provider,
+		&TransitionOptions{
+			DurabilityMode:  DurabilityMode_DurabilityModeFull,
+			Syncer:          filesystem.SystemSyncer,
+			ACLMode:         ACLMode_ACLModeIgnore,
+			CaseInsensitive: true,
+		},
+	)
+	for _, problem := range problems {
+		t.Error("unexpected problem:", problem.Path, problem.Error)
+	}
+	if providerMissingFiles {
+		t.Error("provider indicated missing files")
+	}
+
+	// Verify results.
+	if len(results) != len(expectedResults) {
+		os.RemoveAll(parent)
+		t.Fatal("unexpected number of results:", len(results), "!=", len(expectedResults))
+	}
+	for r, result := range results {
+		if !result.Equal(expectedResults[r]) {
+			t.Error("result does not match expected for path:", transitions[r].Path)
+		}
+	}
+
+	// Done.
+	return root, parent
+}
+
+// testVerifyRootNames verifies that the synchronization root contains the
+// expected names (with their exact casing) and doesn't contain the unexpected
+// names.
+func testVerifyRootNames(t *testing.T, root string, expected, unexpected []string) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Read the root contents.
+	contents, err := ioutil.ReadDir(root)
+	if err != nil {
+		t.Fatal("unable to read root contents:", err)
+	}
+	names := make(map[string]bool, len(contents))
+	for _, c := range contents {
+		names[c.Name()] = true
+	}
+
+	// Verify names.
+	for _, name := range expected {
+		if !names[name] {
+			t.Error("expected name not found:", name)
+		}
+	}
+	for _, name := range unexpected {
+		if names[name] {
+			t.Error("unexpected name found:", name)
+		}
+	}
+}
+
+// TestTransitionCaseOnlyRename tests that case-only renames of files and
+// directories propagate on case-insensitive filesystems without requiring
+// staged content, even when the creation is ordered before the removal.
+func TestTransitionCaseOnlyRename(t *testing.T) {
+	// Set up transitions. The provider is empty, so the creations can only
+	// succeed by renaming the existing content.
+	directory := testDirectory1Entry.Contents["directory"]
+	transitions := []*Change{
+		{Path: "FILE", New: testFile1Entry},
+		{Path: "file", Old: testFile1Entry},
+		{Path: "directory", Old: directory},
+		{Path: "Directory", New: directory},
+	}
+	expectedResults := []*Entry{testFile1Entry, nil, nil, directory}
+
+	// Perform the transitions and defer cleanup.
+	root, parent := testTransitionCaseOnlyRename(t, transitions, nil, expectedResults)
+	defer os.RemoveAll(parent)
+
+	// Verify that the names have the new casing.
+	testVerifyRootNames(t, root, []string{"FILE", "Directory"}, []string{"file", "directory"})
+
+	// Verify that the content was preserved.
+	if contents, err := ioutil.ReadFile(filepath.Join(root, "FILE")); err != nil {
+		t.Error("unable to read renamed file:", err)
+	} else if !bytes.Equal(contents, testFile1Contents) {
+		t.Error("renamed file contents do not match expected")
+	}
+	if contents, err := ioutil.ReadFile(filepath.Join(root, "Directory", "subfile")); err != nil {
+		t.Error("unable to read file in renamed directory:", err)
+	} else if !bytes.Equal(contents, testFile3Contents) {
+		t.Error("file in renamed directory contents do not match expected")
+	}
+
+	// Verify that no intermediate content remains.
+	if names, err := filepath.Glob(filepath.Join(root, caseRenameTemporaryNamePrefix+"*")); err != nil {
+		t.Error("unable to search for intermediate content:", err)
+	} else if len(names) > 0 {
+		t.Error("intermediate content remains:", names)
+	}
+}
+
+// TestTransitionCaseOnlyRenameModified tests that a case-only rename combined
+// with a content modification propagates on case-insensitive filesystems.
+func TestTransitionCaseOnlyRenameModified(t *testing.T) {
+	// Set up transitions.
+	transitions := []*Change{
+		{Path: "FILE", New: testFile3Entry},
+		{Path: "file", Old: testFile1Entry},
+	}
+	contentMap := map[string][]byte{"FILE": testFile3Contents}
+	expectedResults := []*Entry{testFile3Entry, nil}
+
+	// Perform the transitions and defer cleanup.
+	root, parent := testTransitionCaseOnlyRename(t, transitions, contentMap, expectedResults)
+	defer os.RemoveAll(parent)
+
+	// Verify that the name has the new casing and the new content.
+	testVerifyRootNames(t, root, []string{"FILE"}, []string{"file"})
+	if contents, err := ioutil.ReadFile(filepath.Join(root, "FILE")); err != nil {
+		t.Error("unable to read renamed file:", err)
+	} else if !bytes.Equal(contents, testFile3Contents) {
+		t.Error("renamed file contents do not match expected")
+	}
+}
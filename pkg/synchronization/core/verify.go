@@ -0,0 +1,113 @@
+package core
+
+import (
+	"bytes"
+	"hash"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// verifierCopyBufferSize is the size of the copy buffer used when reading
+	// back staged content for verification.
+	verifierCopyBufferSize = 32 * 1024
+	// verifierCopyPreemptionInterval is the number of buffer copy operations to
+	// perform between checks for cancellation when reading back staged content.
+	verifierCopyPreemptionInterval = 16
+)
+
+var (
+	// errVerificationFailed is the error returned when staged content doesn't
+	// match its expected digest.
+	errVerificationFailed = errors.New("staged content failed verification")
+)
+
+// StagedContentVerifier verifies staged content against its expected digest by
+// reading it back from disk immediately before it's moved into place. This
+// guards against corruption of staged content after it has been received,
+// which wouldn't be detected by the in-memory digest computed while staging.
+// Verification is only performed for paths matching the verifier's patterns,
+// which use the same semantics as protected path patterns. A nil verifier is
+// valid and verifies no paths. It is not safe for concurrent usage.
+type StagedContentVerifier struct {
+	// paths identifies the paths requiring verification.
+	paths *ProtectedPathMatcher
+	// hasher is the hasher used to compute digests.
+	hasher hash.Hash
+	// lineEndings identifies files whose digests are computed over content
+	// with canonicalized line endings.
+	lineEndings *LineEndingMatcher
+	// copyBuffer is the buffer used for reading content.
+	copyBuffer []byte
+}
+
+// NewStagedContentVerifier creates a new staged content verifier that verifies
+// content for paths matching the specified patterns, computing digests with the
+// specified hasher. Files matched by lineEndings have their digests computed
+// over content with canonicalized line endings (consistent with Scan). If no
+// patterns are specified, then it returns a nil verifier.
+func NewStagedContentVerifier(patterns []string, hasher hash.Hash, lineEndings *LineEndingMatcher) (*StagedContentVerifier, error) {
+	// Create the path matcher. If there are no patterns, then there's no need
+	// for a verifier.
+	paths, err := NewProtectedPathMatcher(patterns)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid verified path patterns")
+	} else if paths == nil {
+		return nil, nil
+	}
+
+	// Success.
+	return &StagedContentVerifier{
+		paths:       paths,
+		hasher:      hasher,
+		lineEndings: lineEndings,
+		copyBuffer:  make([]byte, verifierCopyBufferSize),
+	}, nil
+}
+
+// Requires indicates whether or not content for the specified path requires
+// verification.
+func (v *StagedContentVerifier) Requires(path string) bool {
+	return v != nil && v.paths.Protected(path)
+}
+
+// verify reads content for the specified path and ensures that it matches the
+// expected digest. It returns errVerificationFailed if the digest doesn't
+// match, errWritePreempted if the specified cancellation channel is closed
+// while reading, or another error if the content can't be read.
+func (v *StagedContentVerifier) verify(path string, content io.Reader, digest []byte, cancelled <-chan struct{}) error {
+	// Reset the hash state.
+	v.hasher.Reset()
+
+	// Read the content into the hasher, canonicalizing line endings if
+	// necessary.
+	var destination io.Writer = &preemptableWriter{
+		cancelled:     cancelled,
+		writer:        v.hasher,
+		checkInterval: verifierCopyPreemptionInterval,
+	}
+	var canonicalizer *LineEndingWriter
+	if v.lineEndings.Matches(path) {
+		canonicalizer = NewLineEndingWriter(destination, LineEndingStyle_LineEndingStyleLF)
+		destination = canonicalizer
+	}
+	if _, err := io.CopyBuffer(destination, content, v.copyBuffer); err != nil {
+		if err == errWritePreempted {
+			return err
+		}
+		return errors.Wrap(err, "unable to read content")
+	} else if canonicalizer != nil {
+		if err := canonicalizer.Flush(); err != nil {
+			return errors.Wrap(err, "unable to read content")
+		}
+	}
+
+	// Compare digests.
+	if !bytes.Equal(v.hasher.Sum(nil), digest) {
+		return errVerificationFailed
+	}
+
+	// Success.
+	return nil
+}
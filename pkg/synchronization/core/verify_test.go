@@ -0,0 +1,280 @@
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+// TestNewStagedContentVerifierNoPatterns tests that NewStagedContentVerifier
+// returns a nil verifier when no patterns are specified and that a nil verifier
+// requires no verification.
+func TestNewStagedContentVerifierNoPatterns(t *testing.T) {
+	verifier, err := NewStagedContentVerifier(nil, newTestHasher(), nil)
+	if err != nil {
+		t.Fatal("unable to create verifier:", err)
+	} else if verifier != nil {
+		t.Fatal("non-nil verifier created without patterns")
+	}
+	if verifier.Requires("file") {
+		t.Error("nil verifier requires verification")
+	}
+}
+
+// TestNewStagedContentVerifierInvalidPatterns tests that
+// NewStagedContentVerifier rejects invalid patterns.
+func TestNewStagedContentVerifierInvalidPatterns(t *testing.T) {
+	for _, pattern := range []string{"", "["} {
+		if _, err := NewStagedContentVerifier([]string{pattern}, newTestHasher(), nil); err == nil {
+			t.Errorf("invalid pattern (%s) accepted", pattern)
+		}
+	}
+}
+
+// TestStagedContentVerifierRequires tests that StagedContentVerifier.Requires
+// matches paths and their contents.
+func TestStagedContentVerifierRequires(t *testing.T) {
+	// Create the verifier.
+	verifier, err := NewStagedContentVerifier([]string{"config/*.yml", "live"}, newTestHasher(), nil)
+	if err != nil {
+		t.Fatal("unable to create verifier:", err)
+	}
+
+	// Define test cases.
+	testCases := []struct {
+		path     string
+		required bool
+	}{
+		{"config/app.yml", true},
+		{"config/app.yaml", false},
+		{"live", true},
+		{"live/file", true},
+		{"other", false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if required := verifier.Requires(testCase.path); required != testCase.required {
+			t.Errorf("verification requirement for %s incorrect: %t != %t", testCase.path, required, testCase.required)
+		}
+	}
+}
+
+// testCorruptingProvider is a Provider that corrupts staged content after it
+// has been staged, simulating corruption at the disk level.
+type testCorruptingProvider struct {
+	*testProvider
+	// stagedPaths records the paths of provided files.
+	stagedPaths []string
+}
+
+// Provide implements Provider.Provide.
+func (p *testCorruptingProvider) Provide(path string, digest []byte) (string, error) {
+	// Stage the file.
+	stagedPath, err := p.testProvider.Provide(path, digest)
+	if err != nil {
+		return "", err
+	}
+	p.stagedPaths = append(p.stagedPaths, stagedPath)
+
+	// Corrupt its content in place.
+	file, err := os.OpenFile(stagedPath, os.O_WRONLY, 0)
+	if err != nil {
+		return "", err
+	}
+	_, err = file.WriteAt([]byte{0xff}, 0)
+	file.Close()
+	if err != nil {
+		return "", err
+	}
+
+	// Done.
+	return stagedPath, nil
+}
+
+// testTransitionVerifiedSwap creates test content on disk and attempts to swap
+// the file at "file" with new content using the specified provider and
+// verifier. It returns the path to the root (whose parent should be removed by
+// the caller), the transition results, problems, and whether or not the
+// provider was missing files.
+func testTransitionVerifiedSwap(
+	t *testing.T,
+	provider Provider,
+	verifier *StagedContentVerifier,
+) (string, []*Entry, []*Problem, bool) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create test content on disk.
+	root, _, err := testTransitionCreate("", testDirectory1Entry, testDirectory1ContentMap, false)
+	if err != nil {
+		t.Fatal("unable to create test content:", err)
+	}
+
+	// Perform a scan to grab Unicode recomposition behavior and a cache.
+	_, _, recomposeUnicode, cache, _, _, err := Scan(
+		context.Background(),
+		root,
+		nil,
+		nil,
+		newTestHasher(),
+		nil,
+		nil,
+		nil,
+		behavior.ProbeMode_ProbeModeProbe,
+		SymlinkMode_SymlinkModePortable,
+		&ScanOptions{
+			BrokenSymlinkMode: BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           ACLMode_ACLModeIgnore,
+		},
+	)
+	if err != nil {
+		os.RemoveAll(filepath.Dir(root))
+		t.Fatal("unable to perform scan:", err)
+	}
+
+	// Perform the transition.
+	results, problems, providerMissingFiles := Transition(
+		context.Background(),
+		root,
+		[]*Change{{Path: "file", Old: testFile1Entry, New: testFile2Entry}},
+		cache,
+		SymlinkMode_SymlinkModePortable,
+		defaultFilePermissionMode,
+		defaultDirectoryPermissionMode,
+		nil,
+		recomposeUnicode,
+		provider,
+		&TransitionOptions{
+			DurabilityMode: DurabilityMode_DurabilityModeFull,
+			Syncer:         filesystem.SystemSyncer,
+			ACLMode:        ACLMode_ACLModeIgnore,
+			Verifier:       verifier,
+		},
+	)
+
+	// Done.
+	return root, results, problems, providerMissingFiles
+}
+
+// TestTransitionVerifiedPathCorrupted tests that staged content corrupted after
+// staging is detected for verified paths and that the live file is left
+// untouched.
+func TestTransitionVerifiedPathCorrupted(t *testing.T) {
+	// Create a corrupting provider and defer its cleanup.
+	base, err := newTestProvider(map[string][]byte{"file": testFile2Contents}, newTestHasher())
+	if err != nil {
+		t.Fatal("unable to create provider:", err)
+	}
+	defer base.finalize()
+	provider := &testCorruptingProvider{testProvider: base}
+
+	// Create the verifier.
+	verifier, err := NewStagedContentVerifier([]string{"file"}, newTestHasher(), nil)
+	if err != nil {
+		t.Fatal("unable to create verifier:", err)
+	}
+
+	// Perform the swap.
+	root, results, problems, providerMissingFiles := testTransitionVerifiedSwap(t, provider, verifier)
+	defer os.RemoveAll(filepath.Dir(root))
+
+	// Verify that the swap failed and that the content will be restaged.
+	if len(problems) != 1 || problems[0].Path != "file" {
+		t.Fatal("verification failure not reported as problem")
+	}
+	if len(results) != 1 || !results[0].Equal(testFile1Entry) {
+		t.Error("result does not reflect original content")
+	}
+	if !providerMissingFiles {
+		t.Error("corrupted content not treated as missing")
+	}
+
+	// Verify that the live file is untouched.
+	if contents, err := ioutil.ReadFile(filepath.Join(root, "file")); err != nil {
+		t.Error("unable to read live file:", err)
+	} else if string(contents) != string(testFile1Contents) {
+		t.Error("live file modified")
+	}
+
+	// Verify that the corrupted staged file was discarded.
+	for _, stagedPath := range provider.stagedPaths {
+		if _, err := os.Lstat(stagedPath); !os.IsNotExist(err) {
+			t.Error("corrupted staged file not removed")
+		}
+	}
+}
+
+// TestTransitionUnverifiedPathCorrupted tests that staged content corrupted
+// after staging is moved into place for paths not requiring verification,
+// demonstrating that verification is what protects verified paths.
+func TestTransitionUnverifiedPathCorrupted(t *testing.T) {
+	// Create a corrupting provider and defer its cleanup.
+	base, err := newTestProvider(map[string][]byte{"file": testFile2Contents}, newTestHasher())
+	if err != nil {
+		t.Fatal("unable to create provider:", err)
+	}
+	defer base.finalize()
+	provider := &testCorruptingProvider{testProvider: base}
+
+	// Create a verifier that doesn't cover the swapped path.
+	verifier, err := NewStagedContentVerifier([]string{"other"}, newTestHasher(), nil)
+	if err != nil {
+		t.Fatal("unable to create verifier:", err)
+	}
+
+	// Perform the swap and verify that it succeeded.
+	root, _, problems, _ := testTransitionVerifiedSwap(t, provider, verifier)
+	defer os.RemoveAll(filepath.Dir(root))
+	if len(problems) != 0 {
+		t.Fatal("problems encountered during unverified swap")
+	}
+
+	// Verify that the corrupted content was moved into place.
+	if contents, err := ioutil.ReadFile(filepath.Join(root, "file")); err != nil {
+		t.Error("unable to read live file:", err)
+	} else if string(contents) == string(testFile2Contents) {
+		t.Error("corrupted content not moved into place")
+	}
+}
+
+// TestTransitionVerifiedPathIntact tests that intact staged content for
+// verified paths is moved into place.
+func TestTransitionVerifiedPathIntact(t *testing.T) {
+	// Create a provider and defer its cleanup.
+	provider, err := newTestProvider(map[string][]byte{"file": testFile2Contents}, newTestHasher())
+	if err != nil {
+		t.Fatal("unable to create provider:", err)
+	}
+	defer provider.finalize()
+
+	// Create the verifier.
+	verifier, err := NewStagedContentVerifier([]string{"file"}, newTestHasher(), nil)
+	if err != nil {
+		t.Fatal("unable to create verifier:", err)
+	}
+
+	// Perform the swap and verify that it succeeded.
+	root, results, problems, providerMissingFiles := testTransitionVerifiedSwap(t, provider, verifier)
+	defer os.RemoveAll(filepath.Dir(root))
+	if len(problems) != 0 {
+		t.Fatal("problems encountered during verified swap:", problems[0].Error)
+	} else if providerMissingFiles {
+		t.Error("provider indicated missing files")
+	}
+	if len(results) != 1 || !results[0].Equal(testFile2Entry) {
+		t.Error("result does not reflect new content")
+	}
+
+	// Verify the live file content.
+	if contents, err := ioutil.ReadFile(filepath.Join(root, "file")); err != nil {
+		t.Error("unable to read live file:", err)
+	} else if string(contents) != string(testFile2Contents) {
+		t.Error("live file not updated")
+	}
+}
@@ -0,0 +1,37 @@
+package synchronization
+
+import (
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// contentDisappeared determines whether or not any content present in the
+// ancestor is absent from the snapshot, i.e. whether or not a scan has observed
+// an apparent deletion. Type changes (e.g. a directory replaced by a file)
+// aren't considered disappearances, since they don't result from transient
+// gaps in content.
+func contentDisappeared(ancestor, snapshot *core.Entry) bool {
+	// If there was no content in the ancestor, then nothing can have
+	// disappeared.
+	if ancestor == nil {
+		return false
+	}
+
+	// If there's no content in the snapshot, then the ancestor content has
+	// disappeared.
+	if snapshot == nil {
+		return true
+	}
+
+	// If both entries are directories, then check whether or not any of their
+	// contents have disappeared.
+	if ancestor.IsDirectory() && snapshot.IsDirectory() {
+		for name, child := range ancestor.Contents {
+			if contentDisappeared(child, snapshot.Contents[name]) {
+				return true
+			}
+		}
+	}
+
+	// Nothing has disappeared.
+	return false
+}
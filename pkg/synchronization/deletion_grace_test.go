@@ -0,0 +1,74 @@
+package synchronization
+
+import (
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// TestContentDisappeared tests contentDisappeared.
+func TestContentDisappeared(t *testing.T) {
+	// Create test entries.
+	file := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{0}}
+	modifiedFile := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{1}}
+	directory := &core.Entry{
+		Kind: core.EntryKind_Directory,
+		Contents: map[string]*core.Entry{
+			"file": file,
+			"subdirectory": {
+				Kind:     core.EntryKind_Directory,
+				Contents: map[string]*core.Entry{"file": file},
+			},
+		},
+	}
+	modifiedDirectory := &core.Entry{
+		Kind: core.EntryKind_Directory,
+		Contents: map[string]*core.Entry{
+			"file": modifiedFile,
+			"subdirectory": {
+				Kind:     core.EntryKind_Directory,
+				Contents: map[string]*core.Entry{"file": file},
+			},
+			"new": file,
+		},
+	}
+	nestedDeletion := &core.Entry{
+		Kind: core.EntryKind_Directory,
+		Contents: map[string]*core.Entry{
+			"file":         file,
+			"subdirectory": {Kind: core.EntryKind_Directory},
+		},
+	}
+	typeChange := &core.Entry{
+		Kind: core.EntryKind_Directory,
+		Contents: map[string]*core.Entry{
+			"file":         file,
+			"subdirectory": file,
+		},
+	}
+
+	// Set up test cases.
+	testCases := []struct {
+		ancestor *core.Entry
+		snapshot *core.Entry
+		expected bool
+	}{
+		{nil, nil, false},
+		{nil, file, false},
+		{file, nil, true},
+		{file, modifiedFile, false},
+		{directory, nil, true},
+		{directory, directory, false},
+		{directory, modifiedDirectory, false},
+		{directory, &core.Entry{Kind: core.EntryKind_Directory}, true},
+		{directory, nestedDeletion, true},
+		{directory, typeChange, false},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		if result := contentDisappeared(testCase.ancestor, testCase.snapshot); result != testCase.expected {
+			t.Errorf("test index %d: result does not match expected: %t != %t", i, result, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package synchronization
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+const (
+	// deletionPausedReasonPrefix is the prefix of paused reasons recorded for
+	// sessions that are paused because a synchronization cycle would have
+	// deleted too many entries. It's used to identify resumptions that confirm
+	// the deletions.
+	deletionPausedReasonPrefix = "too many deletions"
+)
+
+// deletedEntryCount computes the number of entries that would be deleted by
+// transitioning from old to new. Entries replaced by entries of a different
+// kind are considered to be deleted.
+func deletedEntryCount(old, new *core.Entry) uint64 {
+	// If there's no old content, then nothing can be deleted.
+	if old == nil {
+		return 0
+	}
+
+	// If the new content is absent or of a different kind, then all old
+	// content is deleted.
+	if new == nil || new.Kind != old.Kind {
+		return old.Count()
+	}
+
+	// If both entries are directories, then count deletions within their
+	// contents.
+	var result uint64
+	if old.Kind == core.EntryKind_Directory {
+		for name, child := range old.Contents {
+			result += deletedEntryCount(child, new.Contents[name])
+		}
+	}
+
+	// Done.
+	return result
+}
+
+// transitionDeletionCount computes the total number of entries that would be
+// deleted by the specified transitions.
+func transitionDeletionCount(transitions []*core.Change) uint64 {
+	var result uint64
+	for _, transition := range transitions {
+		result += deletedEntryCount(transition.Old, transition.New)
+	}
+	return result
+}
+
+// deletionThresholdError indicates that a synchronization cycle was aborted
+// because it would have deleted more entries on an endpoint than the session's
+// deletion pause threshold or percentage allows.
+type deletionThresholdError struct {
+	// endpoint is the name of the endpoint on which entries would have been
+	// deleted.
+	endpoint string
+	// deletions is the number of entries that would have been deleted.
+	deletions uint64
+	// entries is the number of entries in the ancestor.
+	entries uint64
+}
+
+// Error implements error.Error.
+func (e *deletionThresholdError) Error() string {
+	return fmt.Sprintf("deletion count on %s (%d of %d entries) exceeds deletion pause threshold",
+		e.endpoint, e.deletions, e.entries,
+	)
+}
+
+// pausedReason implements pausingError.pausedReason.
+func (e *deletionThresholdError) pausedReason() string {
+	return fmt.Sprintf("%s on %s (%d of %d entries), resume to confirm",
+		deletionPausedReasonPrefix, e.endpoint, e.deletions, e.entries,
+	)
+}
+
+// checkDeletionThreshold verifies that the specified transitions for an
+// endpoint don't delete more entries than the specified threshold (a count) or
+// percentage (of the entries in the ancestor) allow. A value of 0 disables the
+// corresponding check.
+func checkDeletionThreshold(endpoint string, ancestor *core.Entry, transitions []*core.Change, threshold uint64, percentage uint32) error {
+	// If neither check is enabled, then there's nothing to verify.
+	if threshold == 0 && percentage == 0 {
+		return nil
+	}
+
+	// Count deletions. If there aren't any, then there's nothing to verify.
+	deletions := transitionDeletionCount(transitions)
+	if deletions == 0 {
+		return nil
+	}
+
+	// Perform checks.
+	entries := ancestor.Count()
+	if (threshold != 0 && deletions > threshold) ||
+		(percentage != 0 && deletions*100 > uint64(percentage)*entries) {
+		return &deletionThresholdError{endpoint: endpoint, deletions: deletions, entries: entries}
+	}
+
+	// Success.
+	return nil
+}
+
+// confirmsDeletions determines whether or not resuming a session with the
+// specified paused reason confirms deletions that previously caused the
+// session to be paused.
+func confirmsDeletions(pausedReason string) bool {
+	return strings.HasPrefix(pausedReason, deletionPausedReasonPrefix)
+}
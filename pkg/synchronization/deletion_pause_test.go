@@ -0,0 +1,293 @@
+package synchronization
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// TestDeletedEntryCount tests deletedEntryCount.
+func TestDeletedEntryCount(t *testing.T) {
+	// Create test entries.
+	file := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{0}}
+	modified := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{1}}
+	directory := &core.Entry{
+		Kind: core.EntryKind_Directory,
+		Contents: map[string]*core.Entry{
+			"file":  file,
+			"other": file,
+			"subdirectory": {
+				Kind:     core.EntryKind_Directory,
+				Contents: map[string]*core.Entry{"file": file},
+			},
+		},
+	}
+	pruned := &core.Entry{
+		Kind: core.EntryKind_Directory,
+		Contents: map[string]*core.Entry{
+			"file":  modified,
+			"other": file,
+		},
+	}
+
+	// Set up test cases.
+	testCases := []struct {
+		old      *core.Entry
+		new      *core.Entry
+		expected uint64
+	}{
+		{nil, nil, 0},
+		{nil, directory, 0},
+		{file, nil, 1},
+		{file, modified, 0},
+		{directory, nil, 5},
+		{directory, file, 5},
+		{directory, directory, 0},
+		{directory, pruned, 2},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		if count := deletedEntryCount(testCase.old, testCase.new); count != testCase.expected {
+			t.Errorf("test case %d: deleted entry count incorrect: %d != %d", i, count, testCase.expected)
+		}
+	}
+}
+
+// TestCheckDeletionThreshold tests checkDeletionThreshold.
+func TestCheckDeletionThreshold(t *testing.T) {
+	// Create an ancestor with 10 entries (including the root) and transitions
+	// that delete 3 of them.
+	contents := make(map[string]*core.Entry, 9)
+	for i := 0; i < 9; i++ {
+		contents[fmt.Sprintf("file%d", i)] = &core.Entry{Kind: core.EntryKind_File}
+	}
+	ancestor := &core.Entry{Kind: core.EntryKind_Directory, Contents: contents}
+	transitions := []*core.Change{
+		{Path: "file0", Old: contents["file0"]},
+		{Path: "file1", Old: contents["file1"]},
+		{Path: "file2", Old: contents["file2"]},
+	}
+
+	// Set up test cases.
+	testCases := []struct {
+		threshold     uint64
+		percentage    uint32
+		expectFailure bool
+	}{
+		{0, 0, false},
+		{3, 0, false},
+		{2, 0, true},
+		{0, 30, false},
+		{0, 29, true},
+		{10, 20, true},
+		{2, 100, true},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		err := checkDeletionThreshold("beta", ancestor, transitions, testCase.threshold, testCase.percentage)
+		if testCase.expectFailure && err == nil {
+			t.Errorf("test case %d: deletion check succeeded unexpectedly", i)
+		} else if !testCase.expectFailure && err != nil {
+			t.Errorf("test case %d: deletion check failed unexpectedly: %v", i, err)
+		} else if err != nil {
+			if _, ok := err.(pausingError); !ok {
+				t.Errorf("test case %d: deletion check error doesn't require pausing", i)
+			}
+		}
+	}
+}
+
+// testDeletionProtocolHandler is a protocol handler that yields pre-existing
+// endpoints.
+type testDeletionProtocolHandler struct {
+	// alpha is the alpha endpoint.
+	alpha Endpoint
+	// beta is the beta endpoint.
+	beta Endpoint
+}
+
+// Connect implements ProtocolHandler.Connect.
+func (h *testDeletionProtocolHandler) Connect(
+	_ context.Context,
+	_ *logging.Logger,
+	_ *urlpkg.URL,
+	_ string,
+	_ string,
+	_ Version,
+	_ *Configuration,
+	alpha bool,
+) (Endpoint, error) {
+	if alpha {
+		return h.alpha, nil
+	}
+	return h.beta, nil
+}
+
+// testDeletionController creates a running controller that synchronizes the
+// specified number of files from alpha to beta using the specified deletion
+// pause threshold, waits for the initial synchronization cycle, and then
+// deletes the specified number of files from alpha and forces another cycle.
+// Local URLs are handled by a protocol handler yielding the controller's
+// endpoints, allowing the controller to be resumed. It returns the controller,
+// the temporary directory containing all test content (which the caller should
+// remove), the beta root, and a function to restore the original protocol
+// handler (which the caller should defer).
+func testDeletionController(t *testing.T, files, deletions int, threshold uint64) (*controller, string, string, func()) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create a running controller and wait for it to complete its initial
+	// synchronization cycle.
+	content := make(map[string][]byte, files)
+	for i := 0; i < files; i++ {
+		content[fmt.Sprintf("file%d", i)] = []byte(fmt.Sprintf("content %d", i))
+	}
+	configuration := &Configuration{DeletionPauseThreshold: threshold}
+	c, parent, alpha, beta := testControllerWithConfiguration(t, configuration, content, nil, nil)
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Register a protocol handler for local URLs that yields the controller's
+	// endpoints.
+	originalHandler, originalHandlerRegistered := ProtocolHandlers[urlpkg.Protocol_Local]
+	ProtocolHandlers[urlpkg.Protocol_Local] = &testDeletionProtocolHandler{alpha: alpha, beta: beta}
+	restore := func() {
+		if originalHandlerRegistered {
+			ProtocolHandlers[urlpkg.Protocol_Local] = originalHandler
+		} else {
+			delete(ProtocolHandlers, urlpkg.Protocol_Local)
+		}
+	}
+	c.session.Alpha = &urlpkg.URL{Kind: urlpkg.Kind_Synchronization, Protocol: urlpkg.Protocol_Local, Path: alpha.root}
+	c.session.Beta = &urlpkg.URL{Kind: urlpkg.Kind_Synchronization, Protocol: urlpkg.Protocol_Local, Path: beta.root}
+
+	// Delete files from alpha and force a synchronization cycle.
+	for i := 0; i < deletions; i++ {
+		if err := os.Remove(filepath.Join(alpha.root, fmt.Sprintf("file%d", i))); err != nil {
+			restore()
+			os.RemoveAll(parent)
+			t.Fatal("unable to delete alpha content:", err)
+		}
+	}
+	if err := c.flush(context.Background(), "", true, nil, false); err != nil {
+		restore()
+		os.RemoveAll(parent)
+		t.Fatal("unable to force synchronization cycle:", err)
+	}
+
+	// Done.
+	return c, parent, beta.root, restore
+}
+
+// countTestDeletionFiles counts the files remaining in the specified root.
+func countTestDeletionFiles(t *testing.T, root string) int {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Count files.
+	contents, err := ioutil.ReadDir(root)
+	if err != nil {
+		t.Fatal("unable to read root contents:", err)
+	}
+	return len(contents)
+}
+
+// TestControllerDeletionPauseThresholdExceeded tests that a session is paused
+// for confirmation (with a recorded reason and without applying any deletions)
+// when a synchronization cycle would delete more entries than its deletion
+// pause threshold, and that resuming the session confirms the deletions.
+func TestControllerDeletionPauseThresholdExceeded(t *testing.T) {
+	// Create a controller that deletes more files than its threshold allows.
+	c, parent, betaRoot, restore := testDeletionController(t, 5, 3, 2)
+	defer os.RemoveAll(parent)
+	defer restore()
+
+	// Wait for the session to be paused.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		c.stateLock.Lock()
+		paused := c.session.Paused
+		pausedReason := c.session.PausedReason
+		c.stateLock.UnlockWithoutNotify()
+		if paused && pausedReason != "" {
+			if !confirmsDeletions(pausedReason) || !strings.Contains(pausedReason, "beta") {
+				t.Error("unexpected paused reason:", pausedReason)
+			}
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("session not paused")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Verify that the paused reason was persisted.
+	saved := &Session{}
+	if err := encoding.LoadAndUnmarshalProtobuf(c.sessionPath, saved); err != nil {
+		t.Fatal("unable to load saved session:", err)
+	} else if !saved.Paused || !confirmsDeletions(saved.PausedReason) {
+		t.Error("paused state not persisted")
+	}
+
+	// Verify that no deletions were applied.
+	if count := countTestDeletionFiles(t, betaRoot); count != 5 {
+		t.Error("deletions applied before confirmation:", count, "files remain")
+	}
+
+	// Resume the session to confirm the deletions and verify that they're
+	// applied.
+	if err := c.resume(context.Background(), "", false); err != nil {
+		t.Fatal("unable to resume session:", err)
+	}
+	waitForSynchronizationCycles(t, c, 1)
+	if count := countTestDeletionFiles(t, betaRoot); count != 2 {
+		t.Error("confirmed deletions not applied:", count, "files remain")
+	}
+
+	// Halt the session and verify that the confirmation only applied until a
+	// cycle completed successfully.
+	if err := c.halt(context.Background(), controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	} else if c.deletionsConfirmed {
+		t.Error("deletion confirmation persisted after successful cycle")
+	}
+}
+
+// TestControllerDeletionPauseThresholdNotExceeded tests that a session isn't
+// paused when a synchronization cycle deletes a number of entries that doesn't
+// exceed its deletion pause threshold.
+func TestControllerDeletionPauseThresholdNotExceeded(t *testing.T) {
+	// Create a controller that deletes exactly as many files as its threshold
+	// allows and wait for it to complete the deletion cycle.
+	c, parent, betaRoot, restore := testDeletionController(t, 5, 2, 2)
+	defer os.RemoveAll(parent)
+	defer restore()
+	waitForSynchronizationCycles(t, c, 2)
+
+	// Verify that the session wasn't paused and that the deletions were
+	// applied.
+	c.stateLock.Lock()
+	paused := c.session.Paused
+	c.stateLock.UnlockWithoutNotify()
+	if paused {
+		t.Error("session paused below deletion threshold")
+	}
+	if count := countTestDeletionFiles(t, betaRoot); count != 3 {
+		t.Error("deletions not applied:", count, "files remain")
+	}
+
+	// Halt the session.
+	if err := c.halt(context.Background(), controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+}
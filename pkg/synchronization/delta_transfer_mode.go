@@ -0,0 +1,82 @@
+package synchronization
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/rsync"
+)
+
+// IsDefault indicates whether or not the delta transfer mode is
+// DeltaTransferMode_DeltaTransferModeDefault.
+func (m DeltaTransferMode) IsDefault() bool {
+	return m == DeltaTransferMode_DeltaTransferModeDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (m *DeltaTransferMode) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to a delta transfer mode.
+	switch text {
+	case "auto":
+		*m = DeltaTransferMode_DeltaTransferModeAuto
+	case "delta":
+		*m = DeltaTransferMode_DeltaTransferModeDelta
+	case "whole-file":
+		*m = DeltaTransferMode_DeltaTransferModeWholeFile
+	default:
+		return errors.Errorf("unknown delta transfer mode specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular delta transfer mode is a
+// valid, non-default value.
+func (m DeltaTransferMode) Supported() bool {
+	switch m {
+	case DeltaTransferMode_DeltaTransferModeAuto:
+		return true
+	case DeltaTransferMode_DeltaTransferModeDelta:
+		return true
+	case DeltaTransferMode_DeltaTransferModeWholeFile:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of a delta transfer mode.
+func (m DeltaTransferMode) Description() string {
+	switch m {
+	case DeltaTransferMode_DeltaTransferModeDefault:
+		return "Default"
+	case DeltaTransferMode_DeltaTransferModeAuto:
+		return "Auto"
+	case DeltaTransferMode_DeltaTransferModeDelta:
+		return "Delta"
+	case DeltaTransferMode_DeltaTransferModeWholeFile:
+		return "Whole file"
+	default:
+		return "Unknown"
+	}
+}
+
+// TransferHeuristic returns the rsync transfer heuristic corresponding to the
+// delta transfer mode. Under the automatic mode, files with the specified
+// extensions are probed in addition to those with well-known compressed
+// formats. It returns nil (indicating that all files should be transmitted as
+// deltas) for unknown and default modes.
+func (m DeltaTransferMode) TransferHeuristic(compressedExtensions []string) *rsync.TransferHeuristic {
+	switch m {
+	case DeltaTransferMode_DeltaTransferModeAuto:
+		return rsync.NewAutomaticTransferHeuristic(compressedExtensions)
+	case DeltaTransferMode_DeltaTransferModeWholeFile:
+		return &rsync.TransferHeuristic{WholeFile: true}
+	default:
+		return nil
+	}
+}
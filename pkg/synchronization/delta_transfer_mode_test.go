@@ -0,0 +1,94 @@
+package synchronization
+
+import (
+	"testing"
+)
+
+// TestDeltaTransferModeUnmarshal tests that unmarshaling from a string
+// specification succeeeds for DeltaTransferMode.
+func TestDeltaTransferModeUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text          string
+		expectedMode  DeltaTransferMode
+		expectFailure bool
+	}{
+		{"", DeltaTransferMode_DeltaTransferModeDefault, true},
+		{"asdf", DeltaTransferMode_DeltaTransferModeDefault, true},
+		{"auto", DeltaTransferMode_DeltaTransferModeAuto, false},
+		{"delta", DeltaTransferMode_DeltaTransferModeDelta, false},
+		{"whole-file", DeltaTransferMode_DeltaTransferModeWholeFile, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var mode DeltaTransferMode
+		if err := mode.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if mode != testCase.expectedMode {
+			t.Errorf(
+				"unmarshaled mode (%s) does not match expected (%s)",
+				mode,
+				testCase.expectedMode,
+			)
+		}
+	}
+}
+
+// TestDeltaTransferModeSupported tests that DeltaTransferMode support detection
+// works as expected.
+func TestDeltaTransferModeSupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode            DeltaTransferMode
+		expectSupported bool
+	}{
+		{DeltaTransferMode_DeltaTransferModeDefault, false},
+		{DeltaTransferMode_DeltaTransferModeAuto, true},
+		{DeltaTransferMode_DeltaTransferModeDelta, true},
+		{DeltaTransferMode_DeltaTransferModeWholeFile, true},
+		{(DeltaTransferMode_DeltaTransferModeWholeFile + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.mode.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"mode support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestDeltaTransferModeDescription tests that DeltaTransferMode description
+// generation works as expected.
+func TestDeltaTransferModeDescription(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                DeltaTransferMode
+		expectedDescription string
+	}{
+		{DeltaTransferMode_DeltaTransferModeDefault, "Default"},
+		{DeltaTransferMode_DeltaTransferModeAuto, "Auto"},
+		{DeltaTransferMode_DeltaTransferModeDelta, "Delta"},
+		{DeltaTransferMode_DeltaTransferModeWholeFile, "Whole file"},
+		{(DeltaTransferMode_DeltaTransferModeWholeFile + 1), "Unknown"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if description := testCase.mode.Description(); description != testCase.expectedDescription {
+			t.Errorf(
+				"mode description (%s) does not match expected (%s)",
+				description,
+				testCase.expectedDescription,
+			)
+		}
+	}
+}
@@ -25,12 +25,19 @@ type Endpoint interface {
 	// endpoint is remote. The ancestor may be nil, in which transfers from
 	// remote endpoints may be less than optimal. The full parameter forces the
 	// function to perform a full (warm) scan, avoiding any acceleration that
-	// might be available on the endpoint. The function returns the scan result,
-	// a boolean indicating whether or not the synchronization root preserves
-	// POSIX executability bits, any error that occurred while trying to create
-	// the scan, and a boolean indicating whether or not to re-try the scan (in
-	// the event of an error).
-	Scan(ctx context.Context, ancestor *core.Entry, full bool) (*core.Entry, bool, error, bool)
+	// might be available on the endpoint. The rehash parameter forces the
+	// endpoint to discard its digest cache and recompute the digests of all
+	// files (which implies a full scan). The ignoreOverrides parameter
+	// specifies temporary include patterns (which must be valid according to
+	// core.EnsureValidIgnoreOverride) that take precedence over the endpoint's
+	// ignore patterns for this scan only. Scans with ignore overrides are
+	// always full scans. The function returns the scan result, a boolean
+	// indicating whether or not the synchronization root preserves POSIX
+	// executability bits, a list of problems describing files that were
+	// excluded from the scan result due to exceeding the maximum file size, any
+	// error that occurred while trying to create the scan, and a boolean
+	// indicating whether or not to re-try the scan (in the event of an error).
+	Scan(ctx context.Context, ancestor *core.Entry, full, rehash bool, ignoreOverrides []string) (*core.Entry, bool, []*core.Problem, error, bool)
 
 	// Stage performs staging on the endpoint. It accepts a list of file paths
 	// and a separate list of desired digests corresponding to those paths. For
@@ -51,8 +58,19 @@ type Endpoint interface {
 	Stage(paths []string, digests [][]byte) ([]string, []*rsync.Signature, rsync.Receiver, error)
 
 	// Supply transmits files in a streaming fashion using the rsync algorithm
-	// to the specified receiver.
-	Supply(paths []string, signatures []*rsync.Signature, receiver rsync.Receiver) error
+	// to the specified receiver. If a budget is specified, then files that
+	// don't fit within it are deferred (see rsync.TransmissionBudget).
+	Supply(paths []string, signatures []*rsync.Signature, receiver rsync.Receiver, budget *rsync.TransmissionBudget) error
+
+	// AvailableSpace returns the number of bytes available for staging files
+	// on the endpoint, taking disk quotas into account where possible. If the
+	// available space can't be determined, then math.MaxUint64 is returned.
+	AvailableSpace() (uint64, error)
+
+	// Measure returns the total size of the files at the specified paths, which
+	// should be paths that would be passed to Supply. Files that no longer
+	// exist are ignored, since their transmission would fail anyway.
+	Measure(paths []string) (uint64, error)
 
 	// Transition performs the specified transitions on the endpoint. It returns
 	// a list of successfully applied changes and a list of problems that
@@ -0,0 +1,154 @@
+package local
+
+import (
+	"sync"
+)
+
+const (
+	// defaultStagingBufferBudget is the default total size (in bytes) of the
+	// process-wide staging buffer pool.
+	defaultStagingBufferBudget = 16 * 1024 * 1024
+	// stagingCopyBufferSize is the size of the buffers used when copying file
+	// contents during staging.
+	stagingCopyBufferSize = 32 * 1024
+)
+
+// bufferPool is a pool of byte buffers with a bounded total size. Requests for
+// buffers that would exceed the pool's budget block until enough outstanding
+// buffers have been returned. Requests are granted in the order in which they
+// were made, which prevents large requests from being starved by small ones. A
+// request larger than the budget is granted only once all other buffers have
+// been returned. Returned buffers are retained for re-use, but retained buffers
+// are discarded as necessary to keep the total size of allocated buffers within
+// the budget. It is safe for concurrent usage.
+type bufferPool struct {
+	// budget is the maximum total size of buffers allocated by the pool.
+	budget uint64
+	// lock serializes access to the fields below.
+	lock sync.Mutex
+	// available is used to signal that the pool state has changed.
+	available *sync.Cond
+	// outstanding is the total size of buffers currently held by callers.
+	outstanding uint64
+	// retained are returned buffers held for re-use.
+	retained [][]byte
+	// retainedSize is the total size of retained buffers.
+	retainedSize uint64
+	// nextTicket is the ticket to be assigned to the next request.
+	nextTicket uint64
+	// servingTicket is the ticket of the request currently eligible to be
+	// granted.
+	servingTicket uint64
+}
+
+// newBufferPool creates a new buffer pool with the specified budget.
+func newBufferPool(budget uint64) *bufferPool {
+	pool := &bufferPool{budget: budget}
+	pool.available = sync.NewCond(&pool.lock)
+	return pool
+}
+
+var (
+	// sharedStagingBuffersLock serializes access to sharedStagingBuffers.
+	sharedStagingBuffersLock sync.Mutex
+	// sharedStagingBuffers is the process-wide staging buffer pool. It's
+	// created lazily with the default budget if not configured explicitly.
+	sharedStagingBuffers *bufferPool
+)
+
+// ConfigureStagingBufferBudget sets the total size (in bytes) of the
+// process-wide staging buffer pool used by endpoints hosted in this process. A
+// budget of 0 indicates that defaultStagingBufferBudget should be used.
+// Endpoints created before reconfiguration continue to use the previous pool.
+func ConfigureStagingBufferBudget(budget uint64) {
+	// Use the default budget if none was specified.
+	if budget == 0 {
+		budget = defaultStagingBufferBudget
+	}
+
+	// Replace the pool.
+	sharedStagingBuffersLock.Lock()
+	sharedStagingBuffers = newBufferPool(budget)
+	sharedStagingBuffersLock.Unlock()
+}
+
+// stagingBufferPool returns the process-wide staging buffer pool, creating it
+// with the default budget if it hasn't been configured.
+func stagingBufferPool() *bufferPool {
+	// Lock the pool state and defer its release.
+	sharedStagingBuffersLock.Lock()
+	defer sharedStagingBuffersLock.Unlock()
+
+	// Create the pool if necessary.
+	if sharedStagingBuffers == nil {
+		sharedStagingBuffers = newBufferPool(defaultStagingBufferBudget)
+	}
+
+	// Done.
+	return sharedStagingBuffers
+}
+
+// get acquires a buffer of the specified size, blocking until the pool's budget
+// allows it to be granted. The buffer must be returned to the pool using put
+// once it's no longer being used.
+func (p *bufferPool) get(size int) []byte {
+	// Lock the pool and defer its release.
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// Take a ticket and wait until it's our turn and the request fits within
+	// the budget. Oversized requests are granted only once the pool is idle.
+	ticket := p.nextTicket
+	p.nextTicket++
+	for ticket != p.servingTicket ||
+		(p.outstanding > 0 && p.outstanding+uint64(size) > p.budget) {
+		p.available.Wait()
+	}
+
+	// Record the grant and allow the next request to proceed.
+	p.outstanding += uint64(size)
+	p.servingTicket++
+	p.available.Broadcast()
+
+	// Look for a retained buffer with sufficient capacity whose excess capacity
+	// still fits within the budget.
+	for r, buffer := range p.retained {
+		if cap(buffer) < size {
+			continue
+		}
+		if excess := uint64(cap(buffer) - size); p.outstanding+excess <= p.budget {
+			p.retained = append(p.retained[:r], p.retained[r+1:]...)
+			p.retainedSize -= uint64(cap(buffer))
+			p.outstanding += excess
+			return buffer[:size]
+		}
+	}
+
+	// Discard retained buffers as necessary to allow for a new allocation.
+	for len(p.retained) > 0 && p.outstanding+p.retainedSize > p.budget {
+		p.retainedSize -= uint64(cap(p.retained[0]))
+		p.retained[0] = nil
+		p.retained = p.retained[1:]
+	}
+
+	// Allocate a new buffer.
+	return make([]byte, size)
+}
+
+// put returns a buffer acquired using get to the pool.
+func (p *bufferPool) put(buffer []byte) {
+	// Lock the pool and defer its release.
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// Release the buffer's allocation and retain the buffer if there's room.
+	size := uint64(cap(buffer))
+	p.outstanding -= size
+	if p.outstanding+p.retainedSize+size <= p.budget {
+		p.retained = append(p.retained, buffer[:0])
+		p.retainedSize += size
+	}
+
+	// Signal waiters.
+	p.available.Broadcast()
+}
@@ -0,0 +1,185 @@
+package local
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestBufferPoolBudgetUnderLoad tests that the total size of buffers held by
+// callers (and allocated by the pool) never exceeds the pool's budget when
+// many concurrent callers request buffers.
+func TestBufferPoolBudgetUnderLoad(t *testing.T) {
+	// Create a pool.
+	const budget = 64 * 1024
+	pool := newBufferPool(budget)
+
+	// Start workers that repeatedly acquire and release buffers of varying
+	// sizes, tracking the total size of held buffers.
+	const workers = 32
+	const iterations = 200
+	var held, maximum int64
+	var exceeded, invariantViolated int32
+	var group sync.WaitGroup
+	group.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(seed int64) {
+			defer group.Done()
+			random := rand.New(rand.NewSource(seed))
+			for i := 0; i < iterations; i++ {
+				buffer := pool.get(1024 + random.Intn(16*1024))
+				total := atomic.AddInt64(&held, int64(cap(buffer)))
+				if total > budget {
+					atomic.StoreInt32(&exceeded, 1)
+				}
+				for {
+					current := atomic.LoadInt64(&maximum)
+					if total <= current || atomic.CompareAndSwapInt64(&maximum, current, total) {
+						break
+					}
+				}
+				pool.lock.Lock()
+				if pool.outstanding+pool.retainedSize > budget {
+					atomic.StoreInt32(&invariantViolated, 1)
+				}
+				pool.lock.Unlock()
+				if random.Intn(4) == 0 {
+					time.Sleep(time.Microsecond)
+				}
+				atomic.AddInt64(&held, -int64(cap(buffer)))
+				pool.put(buffer)
+			}
+		}(int64(w))
+	}
+	group.Wait()
+
+	// Verify the results.
+	if exceeded != 0 {
+		t.Error("held buffer size exceeded budget")
+	}
+	if invariantViolated != 0 {
+		t.Error("allocated buffer size exceeded budget")
+	}
+	if maximum == 0 {
+		t.Error("no buffers were held")
+	}
+	if pool.outstanding != 0 {
+		t.Error("outstanding buffer size non-zero after all buffers returned:", pool.outstanding)
+	}
+}
+
+// TestBufferPoolBackpressure tests that requests exceeding the pool's budget
+// block until sufficient buffers have been returned.
+func TestBufferPoolBackpressure(t *testing.T) {
+	// Create a pool and exhaust its budget.
+	pool := newBufferPool(4096)
+	first := pool.get(4096)
+
+	// Request another buffer in the background.
+	acquired := make(chan []byte, 1)
+	go func() {
+		acquired <- pool.get(1024)
+	}()
+
+	// Verify that the request is blocked.
+	select {
+	case <-acquired:
+		t.Fatal("buffer acquired despite exhausted budget")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Return the first buffer and verify that the request is granted.
+	pool.put(first)
+	select {
+	case second := <-acquired:
+		if len(second) != 1024 {
+			t.Error("acquired buffer has incorrect length:", len(second))
+		}
+		pool.put(second)
+	case <-time.After(10 * time.Second):
+		t.Fatal("buffer not acquired after budget became available")
+	}
+}
+
+// TestBufferPoolOversized tests that a request larger than the pool's budget is
+// granted once the pool is idle and blocks other requests until returned.
+func TestBufferPoolOversized(t *testing.T) {
+	// Create a pool and acquire an oversized buffer.
+	pool := newBufferPool(1024)
+	oversized := pool.get(4096)
+	if len(oversized) != 4096 {
+		t.Fatal("oversized buffer has incorrect length:", len(oversized))
+	}
+
+	// Request another buffer in the background and verify that it's blocked.
+	acquired := make(chan []byte, 1)
+	go func() {
+		acquired <- pool.get(512)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("buffer acquired while oversized buffer held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Return the oversized buffer and verify that it isn't retained and that
+	// the request is granted.
+	pool.put(oversized)
+	select {
+	case buffer := <-acquired:
+		pool.put(buffer)
+	case <-time.After(10 * time.Second):
+		t.Fatal("buffer not acquired after oversized buffer returned")
+	}
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	if pool.retainedSize > pool.budget {
+		t.Error("retained buffer size exceeds budget:", pool.retainedSize)
+	}
+}
+
+// TestBufferPoolReuse tests that returned buffers are re-used.
+func TestBufferPoolReuse(t *testing.T) {
+	pool := newBufferPool(4096)
+	first := pool.get(2048)
+	pool.put(first)
+	if second := pool.get(1024); &second[:1][0] != &first[:1][0] {
+		t.Error("returned buffer not re-used")
+	} else if len(second) != 1024 {
+		t.Error("re-used buffer has incorrect length:", len(second))
+	} else {
+		pool.put(second)
+	}
+	if pool.outstanding != 0 {
+		t.Error("outstanding buffer size non-zero after all buffers returned:", pool.outstanding)
+	}
+}
+
+// TestConfigureStagingBufferBudget tests ConfigureStagingBufferBudget.
+func TestConfigureStagingBufferBudget(t *testing.T) {
+	// Defer restoration of the shared pool.
+	defer func() {
+		sharedStagingBuffersLock.Lock()
+		sharedStagingBuffers = nil
+		sharedStagingBuffersLock.Unlock()
+	}()
+
+	// Set up test cases.
+	testCases := []struct {
+		budget   uint64
+		expected uint64
+	}{
+		{0, defaultStagingBufferBudget},
+		{1048576, 1048576},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		ConfigureStagingBufferBudget(testCase.budget)
+		if pool := stagingBufferPool(); pool.budget != testCase.expected {
+			t.Errorf("test case %d: budget mismatch: %d != %d", i, pool.budget, testCase.expected)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// probeCapabilities determines the capabilities of the filesystem on which the
+// synchronization root resides. If the root doesn't exist yet, then the nearest
+// existing parent directory is probed instead, since that's where the root
+// will be created. If probing fails, then the platform's assumed capabilities
+// are returned.
+func probeCapabilities(logger *logging.Logger, root string, probeMode behavior.ProbeMode) *behavior.Capabilities {
+	// Find the nearest existing directory.
+	path := root
+	for {
+		if metadata, err := os.Stat(path); err == nil && metadata.IsDir() {
+			break
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+
+	// Perform probing, falling back to assumed capabilities on failure.
+	capabilities, _, err := behavior.ProbeCapabilitiesByPath(path, probeMode)
+	if err != nil {
+		logger.Debug("Unable to probe filesystem capabilities:", err)
+		capabilities, _, _ = behavior.ProbeCapabilitiesByPath(path, behavior.ProbeMode_ProbeModeAssume)
+	}
+
+	// Log the detected capabilities.
+	logger.Debugf(
+		"Filesystem capabilities: case sensitive: %t, timestamp resolution: %s, extended attributes: %t, POSIX ACLs: %t",
+		capabilities.CaseSensitive,
+		capabilities.TimestampResolution,
+		capabilities.ExtendedAttributes,
+		capabilities.POSIXACLs,
+	)
+
+	// Done.
+	return capabilities
+}
+
+// missingCapabilities returns descriptions of the filesystem capabilities that
+// are required by features explicitly requested in the specified configuration
+// but which aren't provided by the specified capabilities. Features that are
+// only enabled by default aren't considered.
+func missingCapabilities(capabilities *behavior.Capabilities, configuration *synchronization.Configuration) []string {
+	var missing []string
+	if configuration.AclMode == core.ACLMode_ACLModePropagate && !capabilities.POSIXACLs {
+		missing = append(missing, "POSIX ACLs (required by ACL propagation)")
+	}
+	return missing
+}
+
+// checkCapabilities verifies that the specified capabilities satisfy the
+// features explicitly requested in the specified configuration. If they don't,
+// then it returns an error listing the missing capabilities if strict
+// capabilities are enabled, otherwise it logs a warning and the affected
+// features are degraded.
+func checkCapabilities(logger *logging.Logger, capabilities *behavior.Capabilities, configuration *synchronization.Configuration) error {
+	missing := missingCapabilities(capabilities, configuration)
+	if len(missing) == 0 {
+		return nil
+	} else if configuration.StrictCapabilities {
+		return errors.Errorf("filesystem lacks required capabilities: %s", strings.Join(missing, ", "))
+	}
+	logger.Warning("Filesystem lacks capabilities for requested features, which will be degraded:", strings.Join(missing, ", "))
+	return nil
+}
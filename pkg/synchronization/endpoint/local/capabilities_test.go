@@ -0,0 +1,122 @@
+package local
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// TestCheckCapabilities tests that capability checking fails only for strict
+// configurations that explicitly request features unsupported by the
+// endpoint's filesystem.
+func TestCheckCapabilities(t *testing.T) {
+	// Create capabilities with and without POSIX ACL support.
+	full := &behavior.Capabilities{
+		CaseSensitive:       true,
+		TimestampResolution: time.Nanosecond,
+		ExtendedAttributes:  true,
+		POSIXACLs:           true,
+	}
+	withoutACLs := &behavior.Capabilities{
+		CaseSensitive:       true,
+		TimestampResolution: time.Nanosecond,
+	}
+
+	// Set up test cases.
+	testCases := []struct {
+		capabilities *behavior.Capabilities
+		aclMode      core.ACLMode
+		strict       bool
+		expectError  bool
+	}{
+		{full, core.ACLMode_ACLModeDefault, false, false},
+		{full, core.ACLMode_ACLModeDefault, true, false},
+		{full, core.ACLMode_ACLModePropagate, false, false},
+		{full, core.ACLMode_ACLModePropagate, true, false},
+		{withoutACLs, core.ACLMode_ACLModeDefault, false, false},
+		{withoutACLs, core.ACLMode_ACLModeDefault, true, false},
+		{withoutACLs, core.ACLMode_ACLModeIgnore, true, false},
+		{withoutACLs, core.ACLMode_ACLModePropagate, false, false},
+		{withoutACLs, core.ACLMode_ACLModePropagate, true, true},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		configuration := &synchronization.Configuration{
+			AclMode:            testCase.aclMode,
+			StrictCapabilities: testCase.strict,
+		}
+		err := checkCapabilities(logging.RootLogger, testCase.capabilities, configuration)
+		if err != nil && !testCase.expectError {
+			t.Errorf("test case %d: unexpected error: %v", i, err)
+		} else if err == nil && testCase.expectError {
+			t.Errorf("test case %d: expected error", i)
+		} else if err != nil && !strings.Contains(err.Error(), "POSIX ACLs") {
+			t.Errorf("test case %d: error doesn't list missing capability: %v", i, err)
+		}
+	}
+}
+
+// TestEndpointStrictCapabilities tests that endpoint creation with strict
+// capabilities fails if and only if the filesystem lacks the capabilities
+// required by explicitly requested features, and that non-strict endpoint
+// creation always succeeds.
+func TestEndpointStrictCapabilities(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_local_endpoint")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Determine whether or not the filesystem supports POSIX ACLs.
+	capabilities, _, err := behavior.ProbeCapabilitiesByPath(directory, behavior.ProbeMode_ProbeModeProbe)
+	if err != nil {
+		t.Fatal("unable to probe capabilities:", err)
+	}
+
+	// Create endpoints requesting ACL propagation with and without strict
+	// capabilities.
+	for _, strict := range []bool{false, true} {
+		configuration := &synchronization.Configuration{
+			WatchMode:          synchronization.WatchMode_WatchModeNoWatch,
+			ProbeMode:          behavior.ProbeMode_ProbeModeProbe,
+			AclMode:            core.ACLMode_ACLModePropagate,
+			StrictCapabilities: strict,
+		}
+		localEndpoint, err := NewEndpoint(
+			logging.RootLogger,
+			filepath.Join(directory, "root"),
+			"capabilities",
+			synchronization.Version_Version1,
+			configuration,
+			false,
+			WithCachePathCallback(func(_ string, _ bool) (string, error) {
+				return filepath.Join(directory, "cache"), nil
+			}),
+			WithStagingRootCallback(func(_ string, _ bool) (string, bool, error) {
+				return filepath.Join(directory, "staging"), false, nil
+			}),
+		)
+		if strict && !capabilities.POSIXACLs {
+			if err == nil {
+				localEndpoint.Shutdown()
+				t.Error("endpoint creation succeeded with missing capabilities")
+			} else if !strings.Contains(err.Error(), "POSIX ACLs") {
+				t.Error("endpoint creation error doesn't list missing capability:", err)
+			}
+		} else if err != nil {
+			t.Errorf("unable to create endpoint (strict: %t): %v", strict, err)
+		} else {
+			localEndpoint.Shutdown()
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package local
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// concurrently invokes work for each index in the range [0, count) using at
+// most concurrency worker Goroutines, waiting for all invocations to complete
+// before returning. Each invocation is also provided with the index of the
+// worker performing it (in the range [0, concurrency)), allowing callers to
+// maintain per-worker state that needn't be safe for concurrent usage. If
+// concurrency is less than 2 (or there's only a single item), then all
+// invocations are performed serially on the calling Goroutine (as worker 0).
+func concurrently(count, concurrency int, work func(worker, index int)) {
+	// Handle the serial case.
+	if concurrency < 2 || count < 2 {
+		for i := 0; i < count; i++ {
+			work(0, i)
+		}
+		return
+	}
+
+	// Don't start more workers than there are items.
+	if concurrency > count {
+		concurrency = count
+	}
+
+	// Start workers, with each claiming the next unprocessed index until all
+	// indices have been claimed.
+	var next int64 = -1
+	var workers sync.WaitGroup
+	workers.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func(worker int) {
+			defer workers.Done()
+			for {
+				index := int(atomic.AddInt64(&next, 1))
+				if index >= count {
+					return
+				}
+				work(worker, index)
+			}
+		}(w)
+	}
+
+	// Wait for all workers to complete.
+	workers.Wait()
+}
@@ -0,0 +1,80 @@
+package local
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrently tests that concurrently invokes work exactly once for each
+// index, never exceeds the concurrency limit, and only uses valid worker
+// indices.
+func TestConcurrently(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		count       int
+		concurrency int
+	}{
+		{0, 4},
+		{1, 4},
+		{16, 0},
+		{16, 1},
+		{16, 3},
+		{3, 16},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		// Track invocations and concurrency.
+		var lock sync.Mutex
+		invocations := make([]int, testCase.count)
+		var active, maximum int
+		var invalidWorker bool
+
+		// Perform work.
+		concurrently(testCase.count, testCase.concurrency, func(worker, index int) {
+			lock.Lock()
+			invocations[index]++
+			if worker < 0 || (worker > 0 && worker >= testCase.concurrency) {
+				invalidWorker = true
+			}
+			active++
+			if active > maximum {
+				maximum = active
+			}
+			lock.Unlock()
+			time.Sleep(time.Millisecond)
+			lock.Lock()
+			active--
+			lock.Unlock()
+		})
+
+		// Compute the expected maximum concurrency.
+		expectedMaximum := testCase.concurrency
+		if expectedMaximum < 1 {
+			expectedMaximum = 1
+		}
+		if expectedMaximum > testCase.count {
+			expectedMaximum = testCase.count
+		}
+
+		// Verify results.
+		for index, count := range invocations {
+			if count != 1 {
+				t.Errorf("index %d invoked %d times (count: %d, concurrency: %d)",
+					index, count, testCase.count, testCase.concurrency,
+				)
+			}
+		}
+		if maximum > expectedMaximum {
+			t.Errorf("concurrency exceeded limit: %d > %d (count: %d, concurrency: %d)",
+				maximum, expectedMaximum, testCase.count, testCase.concurrency,
+			)
+		}
+		if invalidWorker {
+			t.Errorf("invalid worker index provided (count: %d, concurrency: %d)",
+				testCase.count, testCase.concurrency,
+			)
+		}
+	}
+}
@@ -0,0 +1,406 @@
+package local
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/locking"
+)
+
+const (
+	// contentStoreLockName is the name of the lock file used to coordinate
+	// access to a content store across processes.
+	contentStoreLockName = "lock"
+	// contentStoreObjectsDirectoryName is the name of the directory in which a
+	// content store keeps content, keyed by digest.
+	contentStoreObjectsDirectoryName = "objects"
+	// contentStoreReferencesDirectoryName is the name of the directory in which
+	// a content store keeps the reference list for each owner.
+	contentStoreReferencesDirectoryName = "references"
+	// contentStorePrefixLength is the byte length to use for prefix directories
+	// when load-balancing stored content.
+	contentStorePrefixLength = 1
+	// contentStoreTemporaryNamePrefix is the file name prefix to use for
+	// intermediate temporary files used when inserting content.
+	contentStoreTemporaryNamePrefix = filesystem.TemporaryNamePrefix + "content-store"
+)
+
+// contentStore is a persistent content-addressable store that can be shared by
+// multiple endpoints (and processes) using the same Mutagen data directory. It
+// stores content keyed by digest and tracks, for each owner (an endpoint of a
+// particular session), the set of digests that the owner references. Content
+// is evicted once no owner references it. Access is serialized within a
+// process using a mutex and across processes using a file lock, so all methods
+// are safe for concurrent usage.
+type contentStore struct {
+	// root is the content store root path.
+	root string
+	// lock serializes access to the store within the current process. It is
+	// required in addition to locker because file locks are held on a
+	// per-process basis.
+	lock sync.Mutex
+	// locker serializes access to the store across processes.
+	locker *locking.Locker
+}
+
+var (
+	// contentStoresLock serializes access to contentStores.
+	contentStoresLock sync.Mutex
+	// contentStores maps content store root paths to their corresponding
+	// content stores. We only allow a single instance for any given root
+	// within a process to ensure that file locks behave correctly.
+	contentStores = make(map[string]*contentStore)
+)
+
+// sharedContentStore returns the content store located in the Mutagen data
+// directory, creating it if necessary.
+func sharedContentStore() (*contentStore, error) {
+	// Compute/create the content store directory.
+	root, err := filesystem.Mutagen(true, filesystem.MutagenSynchronizationContentDirectoryName)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to compute/create content store directory")
+	}
+
+	// Return the corresponding content store.
+	return contentStoreAt(root)
+}
+
+// contentStoreAt returns the content store located at the specified root,
+// creating it if necessary.
+func contentStoreAt(root string) (*contentStore, error) {
+	// Lock the content store registry and defer its release.
+	contentStoresLock.Lock()
+	defer contentStoresLock.Unlock()
+
+	// If we already have a content store for this root, then use it.
+	if store, ok := contentStores[root]; ok {
+		return store, nil
+	}
+
+	// Create the objects and references directories.
+	for _, name := range []string{contentStoreObjectsDirectoryName, contentStoreReferencesDirectoryName} {
+		if err := os.MkdirAll(filepath.Join(root, name), 0700); err != nil {
+			return nil, errors.Wrap(err, "unable to create content store directory")
+		}
+	}
+
+	// Create the locker.
+	locker, err := locking.NewLocker(filepath.Join(root, contentStoreLockName), 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create content store lock")
+	}
+
+	// Create and register the store.
+	store := &contentStore{
+		root:   root,
+		locker: locker,
+	}
+	contentStores[root] = store
+
+	// Success.
+	return store, nil
+}
+
+// acquire acquires exclusive access to the store.
+func (s *contentStore) acquire() error {
+	s.lock.Lock()
+	if err := s.locker.Lock(true); err != nil {
+		s.lock.Unlock()
+		return errors.Wrap(err, "unable to acquire content store lock")
+	}
+	return nil
+}
+
+// release releases exclusive access to the store.
+func (s *contentStore) release() {
+	s.locker.Unlock()
+	s.lock.Unlock()
+}
+
+// pathForObject computes the path to the stored content with the specified
+// digest, as well as the path of the prefix directory containing it.
+func (s *contentStore) pathForObject(digest []byte) (string, string, error) {
+	// Ensure that the digest is long enough to be split into a prefix.
+	if len(digest) <= contentStorePrefixLength {
+		return "", "", errors.New("digest too short")
+	}
+
+	// Compute the prefix and object paths.
+	name := hex.EncodeToString(digest)
+	prefix := filepath.Join(s.root, contentStoreObjectsDirectoryName, name[:2*contentStorePrefixLength])
+	return filepath.Join(prefix, name), prefix, nil
+}
+
+// pathForReferences computes the path to the reference list for the specified
+// owner.
+func (s *contentStore) pathForReferences(owner string) string {
+	return filepath.Join(s.root, contentStoreReferencesDirectoryName, owner)
+}
+
+// loadReferences loads the reference list for the specified owner. The store
+// must be locked.
+func (s *contentStore) loadReferences(owner string) (map[string]bool, error) {
+	// Read the reference list. A non-existent list is treated as empty.
+	contents, err := ioutil.ReadFile(s.pathForReferences(owner))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]bool), nil
+		}
+		return nil, errors.Wrap(err, "unable to read reference list")
+	}
+
+	// Parse the reference list.
+	lines := bytes.Split(contents, []byte{'\n'})
+	references := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		if len(line) > 0 {
+			references[string(line)] = true
+		}
+	}
+
+	// Success.
+	return references, nil
+}
+
+// saveReferences saves the reference list for the specified owner, removing
+// it entirely if empty. The store must be locked.
+func (s *contentStore) saveReferences(owner string, references map[string]bool) error {
+	// Compute the reference list path.
+	path := s.pathForReferences(owner)
+
+	// If there aren't any references, then remove the list.
+	if len(references) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "unable to remove reference list")
+		}
+		return nil
+	}
+
+	// Otherwise serialize and write the list.
+	var buffer bytes.Buffer
+	for name := range references {
+		buffer.WriteString(name)
+		buffer.WriteByte('\n')
+	}
+	if err := filesystem.WriteFileAtomic(path, buffer.Bytes(), 0600); err != nil {
+		return errors.Wrap(err, "unable to write reference list")
+	}
+
+	// Success.
+	return nil
+}
+
+// open opens the stored content with the specified digest. If the content isn't
+// present in the store, then an os.IsNotExist-compatible error is returned. On
+// POSIX systems, the opened content remains readable even if it's evicted
+// before being closed.
+func (s *contentStore) open(digest []byte) (*os.File, error) {
+	// Compute the object path.
+	path, _, err := s.pathForObject(digest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Acquire exclusive access to the store and defer its release.
+	if err := s.acquire(); err != nil {
+		return nil, err
+	}
+	defer s.release()
+
+	// Open the object.
+	return os.Open(path)
+}
+
+// insert records a reference to the specified digest for the specified owner,
+// copying the content at the specified path into the store if the store doesn't
+// already contain it. The caller is responsible for ensuring that the content
+// at the specified path matches the digest.
+func (s *contentStore) insert(owner string, digest []byte, path string) error {
+	// Compute the object path.
+	objectPath, prefix, err := s.pathForObject(digest)
+	if err != nil {
+		return err
+	}
+
+	// Acquire exclusive access to the store and defer its release.
+	if err := s.acquire(); err != nil {
+		return err
+	}
+	defer s.release()
+
+	// Copy the content into the store if it's not already present. We don't
+	// link the content into place because the source will be relocated into a
+	// synchronization root where it may be modified in-place.
+	if _, err := os.Lstat(objectPath); err != nil {
+		if !os.IsNotExist(err) {
+			return errors.Wrap(err, "unable to query stored content")
+		} else if err := os.MkdirAll(prefix, 0700); err != nil {
+			return errors.Wrap(err, "unable to create prefix directory")
+		} else if err := copyIntoStore(path, prefix, objectPath); err != nil {
+			return err
+		}
+	}
+
+	// Record the reference.
+	references, err := s.loadReferences(owner)
+	if err != nil {
+		return err
+	}
+	name := hex.EncodeToString(digest)
+	if references[name] {
+		return nil
+	}
+	references[name] = true
+	return s.saveReferences(owner, references)
+}
+
+// copyIntoStore copies the file at the specified source path to the specified
+// destination path via an intermediate temporary file in the specified prefix
+// directory.
+func copyIntoStore(source, prefix, destination string) error {
+	// Open the source file and defer its closure.
+	sourceFile, err := os.Open(source)
+	if err != nil {
+		return errors.Wrap(err, "unable to open source content")
+	}
+	defer sourceFile.Close()
+
+	// Create a temporary file.
+	temporary, err := ioutil.TempFile(prefix, contentStoreTemporaryNamePrefix)
+	if err != nil {
+		return errors.Wrap(err, "unable to create temporary file")
+	}
+
+	// Copy the content and close out the file.
+	if _, err = io.Copy(temporary, sourceFile); err != nil {
+		temporary.Close()
+		os.Remove(temporary.Name())
+		return errors.Wrap(err, "unable to copy content")
+	} else if err = temporary.Close(); err != nil {
+		os.Remove(temporary.Name())
+		return errors.Wrap(err, "unable to close temporary file")
+	}
+
+	// Relocate the file to the destination.
+	if err = os.Rename(temporary.Name(), destination); err != nil {
+		os.Remove(temporary.Name())
+		return errors.Wrap(err, "unable to relocate content")
+	}
+
+	// Success.
+	return nil
+}
+
+// drop releases the specified owner's references to any digests for which the
+// specified retention predicate returns false (or to all digests if retain is
+// nil). Any content that is no longer referenced by any owner is evicted.
+func (s *contentStore) drop(owner string, retain func([]byte) bool) error {
+	// Acquire exclusive access to the store and defer its release.
+	if err := s.acquire(); err != nil {
+		return err
+	}
+	defer s.release()
+
+	// Load the owner's references.
+	references, err := s.loadReferences(owner)
+	if err != nil {
+		return err
+	}
+
+	// Determine which references are being dropped.
+	var dropped [][]byte
+	for name := range references {
+		digest, err := hex.DecodeString(name)
+		if err != nil || retain == nil || !retain(digest) {
+			delete(references, name)
+			if err == nil {
+				dropped = append(dropped, digest)
+			}
+		}
+	}
+	if len(dropped) == 0 {
+		return nil
+	}
+
+	// Save the updated references.
+	if err := s.saveReferences(owner, references); err != nil {
+		return err
+	}
+
+	// Load the references held by all other owners.
+	owners, err := ioutil.ReadDir(filepath.Join(s.root, contentStoreReferencesDirectoryName))
+	if err != nil {
+		return errors.Wrap(err, "unable to read reference lists")
+	}
+	referenced := make(map[string]bool)
+	for _, o := range owners {
+		if o.Name() == owner || filesystem.IsTemporaryFileName(o.Name()) {
+			continue
+		}
+		otherReferences, err := s.loadReferences(o.Name())
+		if err != nil {
+			return err
+		}
+		for name := range otherReferences {
+			referenced[name] = true
+		}
+	}
+
+	// Evict any content that's no longer referenced.
+	for _, digest := range dropped {
+		if referenced[hex.EncodeToString(digest)] {
+			continue
+		}
+		path, _, err := s.pathForObject(digest)
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "unable to evict content")
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// referenceCount returns the number of owners that reference the specified
+// digest.
+func (s *contentStore) referenceCount(digest []byte) (int, error) {
+	// Acquire exclusive access to the store and defer its release.
+	if err := s.acquire(); err != nil {
+		return 0, err
+	}
+	defer s.release()
+
+	// Count the owners that reference the digest.
+	owners, err := ioutil.ReadDir(filepath.Join(s.root, contentStoreReferencesDirectoryName))
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to read reference lists")
+	}
+	name := hex.EncodeToString(digest)
+	var count int
+	for _, o := range owners {
+		if filesystem.IsTemporaryFileName(o.Name()) {
+			continue
+		}
+		references, err := s.loadReferences(o.Name())
+		if err != nil {
+			return 0, err
+		}
+		if references[name] {
+			count++
+		}
+	}
+
+	// Success.
+	return count, nil
+}
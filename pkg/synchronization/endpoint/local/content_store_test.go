@@ -0,0 +1,144 @@
+package local
+
+import (
+	"crypto/sha1"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestContentStoreSharedReferences tests that content inserted into a content
+// store by one owner can be read by another and that content is only evicted
+// once all owners have released their references.
+func TestContentStoreSharedReferences(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_content_store")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create a content store.
+	store, err := contentStoreAt(filepath.Join(directory, "store"))
+	if err != nil {
+		t.Fatal("unable to create content store:", err)
+	}
+
+	// Verify that we get the same content store instance for the same root.
+	if other, err := contentStoreAt(filepath.Join(directory, "store")); err != nil {
+		t.Fatal("unable to re-open content store:", err)
+	} else if other != store {
+		t.Error("content store instance not shared for identical root")
+	}
+
+	// Create content to insert.
+	content := []byte("shared content")
+	digest := sha1.Sum(content)
+	contentPath := filepath.Join(directory, "content")
+	if err := ioutil.WriteFile(contentPath, content, 0600); err != nil {
+		t.Fatal("unable to write content:", err)
+	}
+
+	// Verify that the content isn't yet available.
+	if _, err := store.open(digest[:]); !os.IsNotExist(err) {
+		t.Fatal("content unexpectedly available before insertion")
+	}
+
+	// Insert the content on behalf of the first owner, then remove the source
+	// to ensure that the store holds its own copy.
+	if err := store.insert("first", digest[:], contentPath); err != nil {
+		t.Fatal("unable to insert content:", err)
+	}
+	if err := os.Remove(contentPath); err != nil {
+		t.Fatal("unable to remove source content:", err)
+	}
+
+	// Verify that the content can be read from the store.
+	if file, err := store.open(digest[:]); err != nil {
+		t.Fatal("unable to open stored content:", err)
+	} else if stored, err := ioutil.ReadAll(file); err != nil {
+		file.Close()
+		t.Fatal("unable to read stored content:", err)
+	} else if string(stored) != string(content) {
+		file.Close()
+		t.Error("stored content does not match expected")
+	} else {
+		file.Close()
+	}
+
+	// Record a reference for the second owner. The source path doesn't exist,
+	// so this also verifies that existing content is reused.
+	if err := store.insert("second", digest[:], contentPath); err != nil {
+		t.Fatal("unable to record second reference:", err)
+	}
+	if count, err := store.referenceCount(digest[:]); err != nil {
+		t.Fatal("unable to compute reference count:", err)
+	} else if count != 2 {
+		t.Error("reference count incorrect:", count, "!=", 2)
+	}
+
+	// Verify that retained references survive dropping.
+	if err := store.drop("first", func([]byte) bool { return true }); err != nil {
+		t.Fatal("unable to drop references:", err)
+	}
+	if count, err := store.referenceCount(digest[:]); err != nil {
+		t.Fatal("unable to compute reference count:", err)
+	} else if count != 2 {
+		t.Error("reference count incorrect after retaining drop:", count, "!=", 2)
+	}
+
+	// Drop the first owner's references and verify that the content remains.
+	if err := store.drop("first", nil); err != nil {
+		t.Fatal("unable to drop references:", err)
+	}
+	if count, err := store.referenceCount(digest[:]); err != nil {
+		t.Fatal("unable to compute reference count:", err)
+	} else if count != 1 {
+		t.Error("reference count incorrect after first drop:", count, "!=", 1)
+	}
+	if file, err := store.open(digest[:]); err != nil {
+		t.Error("content evicted while still referenced:", err)
+	} else {
+		file.Close()
+	}
+
+	// Drop the second owner's references and verify that the content has been
+	// evicted.
+	if err := store.drop("second", nil); err != nil {
+		t.Fatal("unable to drop references:", err)
+	}
+	if count, err := store.referenceCount(digest[:]); err != nil {
+		t.Fatal("unable to compute reference count:", err)
+	} else if count != 0 {
+		t.Error("reference count incorrect after second drop:", count, "!=", 0)
+	}
+	if _, err := store.open(digest[:]); !os.IsNotExist(err) {
+		t.Error("content not evicted after all references dropped")
+	}
+}
+
+// TestContentStoreInvalidDigest tests that a content store rejects digests that
+// are too short to be stored.
+func TestContentStoreInvalidDigest(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_content_store")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create a content store.
+	store, err := contentStoreAt(directory)
+	if err != nil {
+		t.Fatal("unable to create content store:", err)
+	}
+
+	// Verify that short digests are rejected.
+	if _, err := store.open([]byte{0}); err == nil {
+		t.Error("content store accepted short digest for opening")
+	}
+	if err := store.insert("owner", nil, filepath.Join(directory, "missing")); err == nil {
+		t.Error("content store accepted empty digest for insertion")
+	}
+}
@@ -1,9 +1,13 @@
 package local
 
 import (
+	"bytes"
 	"context"
 	"hash"
 	"io"
+	"math"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"sync"
 	"time"
@@ -41,6 +45,11 @@ const (
 	// human-perceptible delay, but large enough to group events occurring in
 	// rapid succession.
 	recursiveWatchingEventCoalescingWindow = 10 * time.Millisecond
+
+	// openFileRecheckInterval is the interval after which a scan that skipped
+	// files open for writing will trigger a re-scan to see if those files have
+	// been closed.
+	openFileRecheckInterval = 2 * time.Second
 )
 
 // endpoint provides a local, in-memory implementation of
@@ -66,9 +75,21 @@ type endpoint struct {
 	// synchronization root that this endpoint will support synchronizing. This
 	// field is static and thus safe for concurrent reads.
 	maximumEntryCount uint64
+	// maximumFileSize is the maximum size of files that this endpoint will
+	// include in scans. A value of 0 indicates no limit. This field is static
+	// and thus safe for concurrent reads.
+	maximumFileSize uint64
+	// contentTypeMode is the content type mode that this endpoint will use to
+	// filter files in scans. This field is static and thus safe for concurrent
+	// reads.
+	contentTypeMode core.ContentTypeMode
 	// probeMode is the probe mode for the session. This field is static and
 	// thus safe for concurrent reads.
 	probeMode behavior.ProbeMode
+	// capabilities are the capabilities of the filesystem on which the
+	// synchronization root resides, as detected at endpoint creation. This
+	// field is static and thus safe for concurrent reads.
+	capabilities *behavior.Capabilities
 	// accelerationAllowed indicates whether or not scan acceleration is allowed
 	// for the endpoint. This is computed based off of the scan mode. This field
 	// is static and thus safe for concurrent reads.
@@ -76,9 +97,23 @@ type endpoint struct {
 	// symlinkMode is the symlink mode for the session. This field is static and
 	// thus safe for concurrent reads.
 	symlinkMode core.SymlinkMode
+	// brokenSymlinkMode is the broken symlink mode for the session. This field
+	// is static and thus safe for concurrent reads.
+	brokenSymlinkMode core.BrokenSymlinkMode
+	// deferSymlinks indicates whether or not the creation of symbolic links
+	// with in-tree targets should be deferred until those targets exist when
+	// transitioning. This field is static and thus safe for concurrent reads.
+	deferSymlinks bool
 	// ignores is the list of ignored paths for the session. This field is
 	// static and thus safe for concurrent reads.
 	ignores []string
+	// ignoreGitIgnored indicates whether or not paths ignored by Git should be
+	// ignored. This field is static and thus safe for concurrent reads.
+	ignoreGitIgnored bool
+	// ignoreOpenFiles indicates whether or not files open for writing by other
+	// processes should be skipped during scans. This field is static and thus
+	// safe for concurrent reads.
+	ignoreOpenFiles bool
 	// defaultFileMode is the default file permission mode to use in "portable"
 	// permission propagation. This field is static and thus safe for concurrent
 	// reads.
@@ -91,6 +126,80 @@ type endpoint struct {
 	// "portable" permission propagation. This field is static and thus safe for
 	// concurrent reads.
 	defaultOwnership *filesystem.OwnershipSpecification
+	// aclMode is the POSIX ACL mode to use when scanning and transitioning.
+	// This field is static and thus safe for concurrent reads.
+	aclMode core.ACLMode
+	// preserveHardLinks indicates whether or not hard links should be
+	// preserved when scanning and transitioning. This field is static and thus
+	// safe for concurrent reads.
+	preserveHardLinks bool
+	// preserveMacOSMetadata indicates whether or not macOS resource forks and
+	// Finder metadata should be captured and restored. This field is static
+	// and thus safe for concurrent reads.
+	preserveMacOSMetadata bool
+	// preserveFileFlags indicates whether or not BSD-style user file flags
+	// should be captured and restored. This field is static and thus safe for
+	// concurrent reads.
+	preserveFileFlags bool
+	// durabilityMode is the durability mode to use when transitioning. This
+	// field is static and thus safe for concurrent reads.
+	durabilityMode core.DurabilityMode
+	// syncer is the syncer used to flush modifications to durable storage when
+	// transitioning. This field is static and thus safe for concurrent reads.
+	syncer filesystem.Syncer
+	// readThrough indicates whether or not the endpoint is operating in
+	// read-through mode, in which case content isn't staged and placeholders
+	// are created instead. This field is static and thus safe for concurrent
+	// reads.
+	readThrough bool
+	// protectedPaths is the matcher for paths that transitions must never
+	// delete or overwrite. It may be nil if no paths are protected. This field
+	// is static and thus safe for concurrent reads.
+	protectedPaths *core.ProtectedPathMatcher
+	// verifier verifies staged content for paths requiring verification before
+	// it's moved into place. It may be nil if no paths require verification.
+	// This field is static, but the verifier itself is only used during
+	// transitions, which are serialized by scanLock.
+	verifier *core.StagedContentVerifier
+	// nameTranslator translates between synchronized names and the names used
+	// to represent content on disk. It may be nil if all names can be
+	// represented on disk. This field is static and thus safe for concurrent
+	// reads.
+	nameTranslator *core.NameTranslator
+	// filenameTranscoder transcodes between the UTF-8 names used for
+	// synchronization and the names used on disk. It may be nil if names are
+	// stored on disk as UTF-8. It is applied beneath nameTranslator, i.e. to
+	// names that have already been translated. This field is static and thus
+	// safe for concurrent reads.
+	filenameTranscoder *core.FilenameTranscoder
+	// longPathFilter excludes content with on-disk paths exceeding the
+	// platform's path length limits from transitions. It may be nil if such
+	// content should be created using extended-length paths or if the platform
+	// imposes no limits. This field is static and thus safe for concurrent
+	// reads.
+	longPathFilter *core.LongPathFilter
+	// lineEndings is the matcher for files that are subject to line ending
+	// translation. It may be nil if no files are subject to line ending
+	// translation. This field is static and thus safe for concurrent reads.
+	lineEndings *core.LineEndingMatcher
+	// stagingConcurrency is the maximum number of files read concurrently when
+	// preparing to stage content. This field is static and thus safe for
+	// concurrent reads.
+	stagingConcurrency int
+	// stagingBuffers is the pool from which buffers used to read files when
+	// preparing to stage content are drawn. It bounds the total memory used by
+	// these buffers independent of staging concurrency. This field is static
+	// and thus safe for concurrent reads.
+	stagingBuffers *bufferPool
+	// maximumTransmissionRetries is the maximum number of times that the
+	// transmission of a file modified while being supplied will be restarted.
+	// This field is static and thus safe for concurrent reads.
+	maximumTransmissionRetries uint
+	// transferHeuristic determines whether files being supplied are
+	// transmitted as deltas or in their entirety. It may be nil if all files
+	// should be transmitted as deltas. This field is static and thus safe for
+	// concurrent reads.
+	transferHeuristic *rsync.TransferHeuristic
 	// watchIsRecursive indicates that a watching Goroutine exists and that it
 	// is using native recursive watching. This field is static and thus safe
 	// for concurrent reads.
@@ -98,6 +207,10 @@ type endpoint struct {
 	// workerCancel cancels any background worker Goroutines for the endpoint.
 	// This field is static and safe for concurrent invocation.
 	workerCancel context.CancelFunc
+	// warmupDone is closed once the endpoint's background warmup scan has
+	// completed, failed, or been cancelled. It is nil if warmup is disabled.
+	// This field is static and thus safe for concurrent reads.
+	warmupDone chan struct{}
 	// pollEvents is the channel used to inform a call to Poll that there are
 	// filesystem modifications (and thus it can return). It is a buffered
 	// channel with a capacity of one. Senders should always perform a
@@ -125,9 +238,9 @@ type endpoint struct {
 	// operations.
 	recursiveWatchReenableAcceleration chan struct{}
 	// scanLock locks the endpoint's scan-related fields, specifically
-	// accelerateScan, snapshot, recheckPaths, hasher, cache, ignoreCache,
-	// cacheWriteError, preservesExecutability, decomposesUnicode,
-	// lastScanEntryCount, scannedSinceLastStageCall, and
+	// accelerateScan, snapshot, snapshotOverridden, skipped, recheckPaths,
+	// hasher, cache, ignoreCache, cacheWriteError, preservesExecutability,
+	// decomposesUnicode, lastScanEntryCount, scannedSinceLastStageCall, and
 	// scannedSinceLastTransitionCall. This lock is not necessitated by the
 	// Endpoint interface (since it doesn't allow concurrent usage), but rather
 	// the endpoint's background worker Goroutines for cache saving and
@@ -138,12 +251,22 @@ type endpoint struct {
 	accelerateScan bool
 	// snapshot is the snapshot from the last scan.
 	snapshot *core.Entry
+	// snapshotOverridden indicates whether or not the last scan was performed
+	// with ignore overrides, in which case its snapshot can't be used as the
+	// basis for accelerated scans.
+	snapshotOverridden bool
+	// skipped is the list of problems describing files skipped by the last
+	// scan due to exceeding the maximum file size.
+	skipped []*core.Problem
 	// recheckPaths is the set of recheck paths to use when accelerating scans
 	// in recursive watching mode. This map will always be initialized (non-nil)
 	// and ready for writes.
 	recheckPaths map[string]bool
 	// hasher is the hasher used for scans.
 	hasher hash.Hash
+	// digestHashers are the hashers used by concurrent digest workers during
+	// scans. It is nil if digests are computed serially.
+	digestHashers []hash.Hash
 	// cache is the cache from the last successful scan on the endpoint.
 	cache *core.Cache
 	// ignoreCache is the ignore cache from the last successful scan on the
@@ -171,6 +294,30 @@ type endpoint struct {
 	// stager will only be used in at most one of Stage or Transition methods at
 	// any given time.
 	stager *stager
+	// contentStore is the shared content store used to satisfy staging, if
+	// any. It is nil if the shared content store is disabled.
+	contentStore *contentStore
+	// contentStoreOwner is the owner name used for the endpoint's references
+	// in the shared content store.
+	contentStoreOwner string
+	// contentStorePrunePending indicates whether or not the endpoint's shared
+	// content store references should be pruned after the next scan.
+	contentStorePrunePending bool
+	// undoArea is the area used to retain previous content so that the last
+	// transition operation can be undone. It is nil if undo support is
+	// disabled.
+	undoArea *undoArea
+	// conflictResolver is the external conflict resolver used to handle
+	// conflict resolution transitions. It is nil if no conflict resolver
+	// command is configured. This field is static and thus safe for concurrent
+	// reads.
+	conflictResolver *conflictResolver
+	// conflictSidecars maps the on-disk paths of files whose conflicts were
+	// resolved using conflict sidecar files to the digests of the winning
+	// files. It is used to remove sidecar files once the winning files are
+	// modified or removed. This map will always be initialized (non-nil) and
+	// ready for writes. It is protected by the scan lock.
+	conflictSidecars map[string][]byte
 }
 
 // NewEndpoint creates a new local endpoint instance using the specified session
@@ -217,6 +364,9 @@ func NewEndpoint(
 		probeMode = version.DefaultProbeMode()
 	}
 
+	// Determine the capabilities of the filesystem on which the root resides.
+	capabilities := probeCapabilities(logger, root, probeMode)
+
 	// Compute the effective scan mode and whether or not scan acceleration is
 	// allowed.
 	scanMode := configuration.ScanMode
@@ -231,6 +381,26 @@ func NewEndpoint(
 		symlinkMode = version.DefaultSymlinkMode()
 	}
 
+	// Compute the effective broken symlink mode.
+	brokenSymlinkMode := configuration.BrokenSymlinkMode
+	if brokenSymlinkMode.IsDefault() {
+		brokenSymlinkMode = version.DefaultBrokenSymlinkMode()
+	}
+
+	// Compute the effective invalid name mode.
+	invalidNameMode := configuration.InvalidNameMode
+	if invalidNameMode.IsDefault() {
+		invalidNameMode = version.DefaultInvalidNameMode()
+	}
+
+	// Compute the effective long path mode and the platform's path length
+	// limits.
+	longPathMode := configuration.LongPathMode
+	if longPathMode.IsDefault() {
+		longPathMode = version.DefaultLongPathMode()
+	}
+	maximumFilePathLength, maximumDirectoryPathLength := filesystem.PathLengthLimits()
+
 	// Compute the effective VCS ignore mode.
 	ignoreVCSMode := configuration.IgnoreVCSMode
 	if ignoreVCSMode.IsDefault() {
@@ -244,6 +414,27 @@ func NewEndpoint(
 	}
 	ignores = append(ignores, configuration.DefaultIgnores...)
 	ignores = append(ignores, configuration.Ignores...)
+	if configuration.ConflictSidecars {
+		ignores = append(ignores, core.ConflictSidecarIgnores...)
+	}
+
+	// If Git-ignored paths are to be ignored, then warn if Git isn't available,
+	// since this behavior will be silently disabled during scanning.
+	if configuration.IgnoreGitIgnored {
+		if _, err := exec.LookPath("git"); err != nil {
+			logger.Warning("Git not found, Git-ignored paths will be propagated")
+		}
+	}
+
+	// If files open for writing are to be ignored, then warn if detection isn't
+	// supported on this platform and disable the behavior.
+	ignoreOpenFiles := configuration.IgnoreOpenFiles
+	if ignoreOpenFiles {
+		if _, err := filesystem.FilesOpenForWriting(root); err == filesystem.ErrOpenFileDetectionUnsupported {
+			logger.Warning("Open file detection not supported, open files will be propagated")
+			ignoreOpenFiles = false
+		}
+	}
 
 	// Compute the effective default file mode.
 	defaultFileMode := filesystem.Mode(configuration.DefaultFileMode)
@@ -278,6 +469,64 @@ func NewEndpoint(
 		return nil, errors.Wrap(err, "unable to create ownership specification")
 	}
 
+	// Compute the effective ACL mode.
+	aclMode := configuration.AclMode
+	if aclMode.IsDefault() {
+		aclMode = version.DefaultACLMode()
+	}
+
+	// Verify that the filesystem supports any explicitly requested features.
+	if err := checkCapabilities(logger, capabilities, configuration); err != nil {
+		return nil, err
+	}
+
+	// Compute the effective durability mode.
+	durabilityMode := configuration.DurabilityMode
+	if durabilityMode.IsDefault() {
+		durabilityMode = version.DefaultDurabilityMode()
+	}
+
+	// Compute the effective modification handling mode.
+	modificationHandlingMode := configuration.ModificationHandlingMode
+	if modificationHandlingMode.IsDefault() {
+		modificationHandlingMode = version.DefaultModificationHandlingMode()
+	}
+
+	// Compute the effective delta transfer mode.
+	deltaTransferMode := configuration.DeltaTransferMode
+	if deltaTransferMode.IsDefault() {
+		deltaTransferMode = version.DefaultDeltaTransferMode()
+	}
+
+	// Compute the effective scan concurrency and create hashers for concurrent
+	// digest workers if necessary.
+	scanConcurrency := configuration.ScanConcurrency
+	if scanConcurrency == 0 {
+		scanConcurrency = version.DefaultScanConcurrency()
+	}
+	var digestHashers []hash.Hash
+	if scanConcurrency > 1 {
+		digestHashers = make([]hash.Hash, scanConcurrency)
+		for h := range digestHashers {
+			digestHashers[h] = version.Hasher()
+		}
+	}
+
+	// Compute the effective staging concurrency.
+	stagingConcurrency := configuration.StagingConcurrency
+	if stagingConcurrency == 0 {
+		stagingConcurrency = version.DefaultStagingConcurrency()
+	}
+
+	// Grab the staging buffer pool.
+	stagingBuffers := stagingBufferPool()
+
+	// Determine the syncer to use for flushing modifications.
+	syncer := filesystem.SystemSyncer
+	if endpointOptions.syncer != nil {
+		syncer = endpointOptions.syncer
+	}
+
 	// Compute the cache path if this isn't an ephemeral endpoint.
 	var cachePath string
 	if endpointOptions.cachePathCallback != nil {
@@ -311,18 +560,77 @@ func NewEndpoint(
 	var hideStagingRoot bool
 	if endpointOptions.stagingRootCallback != nil {
 		stagingRoot, hideStagingRoot, err = endpointOptions.stagingRootCallback(sessionIdentifier, alpha)
-	} else if stageMode == synchronization.StageMode_StageModeMutagen {
-		stagingRoot, err = pathForMutagenStagingRoot(sessionIdentifier, alpha)
-	} else if stageMode == synchronization.StageMode_StageModeNeighboring {
-		stagingRoot, err = pathForNeighboringStagingRoot(root, sessionIdentifier, alpha)
-		hideStagingRoot = true
 	} else {
-		panic("unhandled staging mode")
+		stagingRoot, err = StagingRoot(sessionIdentifier, alpha, root, stageMode)
+		hideStagingRoot = stageMode == synchronization.StageMode_StageModeNeighboring
 	}
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to compute staging root")
 	}
 
+	// Compute the effective content store mode and grab the shared content
+	// store if it's enabled.
+	contentStoreMode := configuration.ContentStoreMode
+	if contentStoreMode.IsDefault() {
+		contentStoreMode = version.DefaultContentStoreMode()
+	}
+	var store *contentStore
+	contentStoreOwner := contentStoreOwnerForEndpoint(sessionIdentifier, alpha)
+	if contentStoreMode == synchronization.ContentStoreMode_ContentStoreModeShared {
+		if store, err = sharedContentStore(); err != nil {
+			return nil, errors.Wrap(err, "unable to access shared content store")
+		}
+	}
+
+	// Create the undo area if undo support is enabled.
+	var undo *undoArea
+	if configuration.UndoMaximumSize != 0 {
+		undoAreaPath, err := pathForUndoArea(sessionIdentifier, alpha)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to compute undo area path")
+		}
+		undo, err = newUndoArea(
+			undoAreaPath,
+			configuration.UndoMaximumSize,
+			time.Duration(configuration.UndoMaximumAge)*time.Second,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to create undo area")
+		}
+	}
+
+	// Create the protected path matcher.
+	protectedPaths, err := core.NewProtectedPathMatcher(configuration.ProtectedPaths)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create protected path matcher")
+	}
+
+	// Create the line ending matcher.
+	lineEndings, err := core.NewLineEndingMatcher(configuration.LineEndingPatterns)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create line ending matcher")
+	}
+
+	// Create the staged content verifier.
+	verifier, err := core.NewStagedContentVerifier(configuration.VerifiedPaths, version.Hasher(), lineEndings)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create staged content verifier")
+	}
+
+	// Create the conflict resolver if a conflict resolver command has been
+	// specified.
+	var resolver *conflictResolver
+	if len(configuration.ConflictResolverCommand) > 0 {
+		conflictResolverTimeout := configuration.ConflictResolverTimeout
+		if conflictResolverTimeout == 0 {
+			conflictResolverTimeout = version.DefaultConflictResolverTimeout()
+		}
+		resolver = &conflictResolver{
+			command: configuration.ConflictResolverCommand,
+			timeout: time.Duration(conflictResolverTimeout) * time.Second,
+		}
+	}
+
 	// Compute the effective watch mode.
 	watchMode := configuration.WatchMode
 	if watchMode.IsDefault() {
@@ -343,13 +651,37 @@ func NewEndpoint(
 		root:                               root,
 		readOnly:                           readOnly,
 		maximumEntryCount:                  maximumEntryCount,
+		maximumFileSize:                    configuration.MaximumFileSize,
+		contentTypeMode:                    configuration.ContentTypeMode,
 		probeMode:                          probeMode,
+		capabilities:                       capabilities,
 		accelerationAllowed:                accelerationAllowed,
 		symlinkMode:                        symlinkMode,
+		brokenSymlinkMode:                  brokenSymlinkMode,
+		deferSymlinks:                      configuration.DeferSymlinks,
 		ignores:                            ignores,
+		ignoreGitIgnored:                   configuration.IgnoreGitIgnored,
+		ignoreOpenFiles:                    ignoreOpenFiles,
 		defaultFileMode:                    defaultFileMode,
 		defaultDirectoryMode:               defaultDirectoryMode,
 		defaultOwnership:                   defaultOwnership,
+		aclMode:                            aclMode,
+		preserveHardLinks:                  configuration.PreserveHardLinks,
+		preserveMacOSMetadata:              configuration.PreserveMacOSMetadata,
+		preserveFileFlags:                  configuration.PreserveFileFlags,
+		durabilityMode:                     durabilityMode,
+		maximumTransmissionRetries:         modificationHandlingMode.MaximumRetries(),
+		transferHeuristic:                  deltaTransferMode.TransferHeuristic(configuration.IncompressibleExtensions),
+		syncer:                             syncer,
+		readThrough:                        endpointOptions.readThrough,
+		protectedPaths:                     protectedPaths,
+		verifier:                           verifier,
+		nameTranslator:                     core.NewNameTranslator(invalidNameMode),
+		filenameTranscoder:                 core.NewFilenameTranscoder(configuration.FilenameEncoding),
+		longPathFilter:                     core.NewLongPathFilter(longPathMode, root, maximumFilePathLength, maximumDirectoryPathLength),
+		lineEndings:                        lineEndings,
+		stagingConcurrency:                 int(stagingConcurrency),
+		stagingBuffers:                     stagingBuffers,
 		watchIsRecursive:                   watchIsRecursive,
 		workerCancel:                       workerCancel,
 		pollEvents:                         make(chan struct{}, 1),
@@ -357,18 +689,37 @@ func NewEndpoint(
 		recursiveWatchReenableAcceleration: make(chan struct{}, 1),
 		recheckPaths:                       make(map[string]bool, recheckPathsMaximumCapacity),
 		hasher:                             version.Hasher(),
+		digestHashers:                      digestHashers,
 		cache:                              cache,
 		stager: newStager(
 			stagingRoot,
 			hideStagingRoot,
 			version.Hasher(),
 			maximumStagingFileSize,
+			store,
+			contentStoreOwner,
+			configuration.CloneStagingThreshold,
+			lineEndings,
+			configuration.LineEndingStyle,
 		),
+		contentStore:             store,
+		contentStoreOwner:        contentStoreOwner,
+		contentStorePrunePending: true,
+		undoArea:                 undo,
+		conflictResolver:         resolver,
+		conflictSidecars:         make(map[string][]byte),
 	}
 
 	// Start the cache saving Goroutine.
 	go endpoint.saveCacheRegularly(workerContext, cachePath)
 
+	// Start the warmup Goroutine, if requested. We start it before any watching
+	// Goroutine so that it has the first opportunity to acquire the scan lock.
+	if configuration.ScanWarmup {
+		endpoint.warmupDone = make(chan struct{})
+		go endpoint.warmup(workerContext)
+	}
+
 	// Compute the effective watch polling interval.
 	watchPollingInterval := configuration.WatchPollingInterval
 	if watchPollingInterval == 0 {
@@ -434,6 +785,41 @@ func (e *endpoint) saveCacheRegularly(context context.Context, cachePath string)
 	}
 }
 
+// warmup performs a full (warm) scan of the synchronization root in the
+// background in order to populate the digest cache ahead of the first Scan
+// call, which then only needs to compute digests for files modified in the
+// interim. It uses the same digest hashers as regular scans and is thus subject
+// to the scan concurrency limit. It runs as a background Goroutine for
+// endpoints with warmup enabled and closes warmupDone when it returns.
+func (e *endpoint) warmup(ctx context.Context) {
+	// Signal completion when we return.
+	defer close(e.warmupDone)
+
+	// Create a sublogger for warmup.
+	logger := e.logger.Sublogger("warmup")
+
+	// Grab the scan lock and defer its release.
+	e.scanLock.Lock()
+	defer e.scanLock.Unlock()
+
+	// If a scan has already been performed (e.g. by a watching Goroutine),
+	// then the cache is already populated.
+	if e.snapshot != nil {
+		logger.Debug("Skipping warmup since the root has already been scanned")
+		return
+	}
+
+	// Perform the scan. If it fails (e.g. due to cancellation or concurrent
+	// modifications), then we simply leave it to the first Scan call to
+	// populate the cache.
+	logger.Debug("Performing warmup scan")
+	if err := e.scan(ctx, nil, nil, nil); err != nil {
+		logger.Debug("Warmup scan failed:", err)
+		return
+	}
+	logger.Debug("Warmup scan complete with", len(e.cache.Entries), "cache entries")
+}
+
 // stopAndDrainTimer is a convenience function that stops a timer and performs a
 // non-blocking drain on its channel. This allows a timer to be stopped/drained
 // without any knowledge of its current state.
@@ -581,7 +967,7 @@ WatchEstablishment:
 				// try again after the polling interval.
 				logger.Debug("Performing baseline scan")
 				e.scanLock.Lock()
-				if err := e.scan(ctx, nil, nil); err != nil {
+				if err := e.scan(ctx, nil, nil, nil); err != nil {
 					logger.Debug("Unable to perform baseline scan:", err)
 					scanTimer.Reset(pollingDuration)
 				} else {
@@ -611,7 +997,7 @@ WatchEstablishment:
 				// try again after the polling interval.
 				logger.Debug("Performing baseline scan")
 				e.scanLock.Lock()
-				if err := e.scan(ctx, nil, nil); err != nil {
+				if err := e.scan(ctx, nil, nil, nil); err != nil {
 					logger.Debug("Unable to perform baseline scan:", err)
 					scanTimer.Reset(pollingDuration)
 				} else {
@@ -685,16 +1071,20 @@ WatchEstablishment:
 				// would overflow its allowed size, then temporarily disable
 				// acceleration, clear out the re-check path set, and reset the
 				// scan timer (which may or may not be running) to force a full
-				// (warm) scan (and re-enable acceleration).
+				// (warm) scan (and re-enable acceleration). We do the same if
+				// Git-ignored paths are being ignored and the path is a Git
+				// ignore file, since a change in ignore rules can affect paths
+				// that wouldn't otherwise be re-checked.
 				if e.accelerationAllowed {
 					e.scanLock.Lock()
-					if len(e.recheckPaths) == recheckPathsMaximumCapacity {
+					gitIgnoreModified := e.ignoreGitIgnored && core.PathBase(path) == ".gitignore"
+					if len(e.recheckPaths) == recheckPathsMaximumCapacity || gitIgnoreModified {
 						e.accelerateScan = false
 						e.recheckPaths = make(map[string]bool, recheckPathsMaximumCapacity)
 						stopAndDrainTimer(scanTimer)
 						scanTimer.Reset(pollingDuration)
 					}
-					e.recheckPaths[path] = true
+					e.recheckPaths[e.filenameTranscoder.DecodeRecheckPath(path)] = true
 					e.scanLock.Unlock()
 				}
 
@@ -723,9 +1113,30 @@ func (e *endpoint) watchPoll(
 	// Create a sublogger for watching.
 	logger := e.logger.Sublogger("polling")
 
-	// Create a ticker to regulate polling and defer its shutdown.
-	ticker := time.NewTicker(time.Duration(pollingInterval) * time.Second)
-	defer ticker.Stop()
+	// Create a ticker to regulate polling and defer its shutdown. If the
+	// synchronization root resides on a network filesystem, then we instead
+	// use a timer that's aligned to our slot in the polling schedule shared
+	// with other endpoints on the same filesystem, which we re-arm after each
+	// tick. This staggers scans so that they don't all hit the filesystem
+	// server at once.
+	interval := time.Duration(pollingInterval) * time.Second
+	var ticks <-chan time.Time
+	var staggerTimer *time.Timer
+	staggerFilesystem, stagger := probeScanStaggering(e.root)
+	if stagger {
+		logger.Debug("Staggering polling scans on network filesystem")
+		sharedScanStaggerer.register(staggerFilesystem, e.contentStoreOwner)
+		defer sharedScanStaggerer.unregister(staggerFilesystem, e.contentStoreOwner)
+		staggerTimer = time.NewTimer(sharedScanStaggerer.delay(
+			staggerFilesystem, e.contentStoreOwner, interval, time.Now(),
+		))
+		defer staggerTimer.Stop()
+		ticks = staggerTimer.C
+	} else {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
 
 	// Track whether or not it's our first iteration in the polling loop. We
 	// adjust some behaviors in that case.
@@ -820,9 +1231,17 @@ func (e *endpoint) watchPoll(
 
 				// Terminate polling.
 				return
-			case <-ticker.C:
+			case <-ticks:
 				// Log the tick.
 				logger.Trace("Received polling signal")
+
+				// If we're staggering scans, then re-arm the timer for the
+				// start of our next slot.
+				if stagger {
+					staggerTimer.Reset(sharedScanStaggerer.delay(
+						staggerFilesystem, e.contentStoreOwner, interval, time.Now(),
+					))
+				}
 			case err := <-nonRecursiveWatcherErrors:
 				// Log the error.
 				logger.Debug("Non-recursive watching error:", err)
@@ -871,7 +1290,7 @@ func (e *endpoint) watchPoll(
 		// concurrent modification. In that case, release the scan lock and
 		// strobe the poll events channel. The controller can then perform a
 		// full scan.
-		if err := e.scan(ctx, nil, nil); err != nil {
+		if err := e.scan(ctx, nil, nil, nil); err != nil {
 			// Log the error.
 			logger.Debug("Scan failed:", err)
 
@@ -907,7 +1326,7 @@ func (e *endpoint) watchPoll(
 		if nonRecursiveWatcher != nil {
 			changes := core.Diff(previousSnapshot, snapshot)
 			for _, change := range changes {
-				nonRecursiveWatcher.Watch(filepath.Join(e.root, change.Path))
+				nonRecursiveWatcher.Watch(e.filenameTranscoder.FilesystemPath(e.root, change.Path))
 			}
 		}
 
@@ -956,29 +1375,71 @@ func (e *endpoint) Poll(context context.Context) error {
 }
 
 // scan is the internal function which performs a scan operation on the root and
-// updates the endpoint scan parameters. The caller must hold the endpoint's
-// scan lock.
-func (e *endpoint) scan(ctx context.Context, baseline *core.Entry, recheckPaths map[string]bool) error {
+// updates the endpoint scan parameters. If ignore overrides are specified, then
+// they're applied for this scan only. The caller must hold the endpoint's scan
+// lock.
+func (e *endpoint) scan(ctx context.Context, baseline *core.Entry, recheckPaths map[string]bool, ignoreOverrides []string) error {
+	// Determine the ignores to use. If overrides are specified, then we can't
+	// use the ignore cache, since its contents don't reflect the overrides.
+	ignores, ignoreCache := e.ignores, e.ignoreCache
+	if len(ignoreOverrides) > 0 {
+		ignores = core.WithIgnoreOverrides(e.ignores, ignoreOverrides)
+		ignoreCache = nil
+	}
+
+	// If files open for writing are being skipped, then determine which files
+	// are currently open for writing.
+	var openFiles map[string]bool
+	if e.ignoreOpenFiles {
+		var err error
+		if openFiles, err = filesystem.FilesOpenForWriting(e.root); err != nil {
+			return errors.Wrap(err, "unable to determine files open for writing")
+		}
+		if e.filenameTranscoder != nil {
+			openFiles = e.decodeOpenFiles(openFiles)
+		}
+	}
+
 	// Perform a full (warm) scan, watching for errors.
-	snapshot, preservesExecutability, decomposesUnicode, newCache, newIgnoreCache, err := core.Scan(
+	snapshot, preservesExecutability, decomposesUnicode, newCache, newIgnoreCache, skipped, err := core.Scan(
 		ctx,
 		e.root,
 		baseline, recheckPaths,
 		e.hasher, e.cache,
-		e.ignores, e.ignoreCache,
+		ignores, ignoreCache,
 		e.probeMode,
 		e.symlinkMode,
+		&core.ScanOptions{
+			BaselineSkipped:       e.skipped,
+			IgnoreGitIgnored:      e.ignoreGitIgnored,
+			BrokenSymlinkMode:     e.brokenSymlinkMode,
+			MaximumFileSize:       e.maximumFileSize,
+			ContentTypeMode:       e.contentTypeMode,
+			LineEndings:           e.lineEndings,
+			ACLMode:               e.aclMode,
+			PreserveHardLinks:     e.preserveHardLinks,
+			PreserveMacOSMetadata: e.preserveMacOSMetadata,
+			PreserveFileFlags:     e.preserveFileFlags,
+			DigestHashers:         e.digestHashers,
+			OpenFiles:             openFiles,
+			Filenames:             e.filenameTranscoder,
+		},
 	)
 	if err != nil {
 		return err
 	}
 
-	// Update the internal snapshot.
+	// Update the internal snapshot and skipped file problems.
 	e.snapshot = snapshot
+	e.snapshotOverridden = len(ignoreOverrides) > 0
+	e.skipped = skipped
 
-	// Update caches.
+	// Update caches. We don't retain the ignore cache from scans with ignore
+	// overrides, since it won't be valid for subsequent scans.
 	e.cache = newCache
-	e.ignoreCache = newIgnoreCache
+	if len(ignoreOverrides) == 0 {
+		e.ignoreCache = newIgnoreCache
+	}
 
 	// Update behavior data.
 	e.preservesExecutability = preservesExecutability
@@ -987,6 +1448,12 @@ func (e *endpoint) scan(ctx context.Context, baseline *core.Entry, recheckPaths
 	// Update the last scan entry count.
 	e.lastScanEntryCount = snapshot.Count()
 
+	// Prune shared content store references, if necessary.
+	if e.contentStorePrunePending {
+		e.pruneContentStoreReferences()
+		e.contentStorePrunePending = false
+	}
+
 	// Update call states.
 	e.scannedSinceLastStageCall = true
 	e.scannedSinceLastTransitionCall = true
@@ -995,8 +1462,47 @@ func (e *endpoint) scan(ctx context.Context, baseline *core.Entry, recheckPaths
 	return nil
 }
 
+// pruneContentStoreReferences releases the endpoint's references to any shared
+// content store content that no longer exists within the synchronization root
+// (according to the cache), allowing that content to be evicted if no other
+// endpoint references it. Pruning is performed after any scan that follows a
+// transition (as well as after the endpoint's initial scan, which cleans up
+// references left over from previous endpoint instances). We don't monitor for
+// errors here, since the content store is only an optimization and any
+// outstanding references will be released after a subsequent prune.
+func (e *endpoint) pruneContentStoreReferences() {
+	// If there's no content store, then there's nothing to prune.
+	if e.contentStore == nil {
+		return
+	}
+
+	// Generate a reverse lookup map from the cache.
+	reverseLookupMap, err := e.cache.GenerateReverseLookupMap()
+	if err != nil {
+		return
+	}
+
+	// Release references to content that no longer exists.
+	e.contentStore.drop(e.contentStoreOwner, func(digest []byte) bool {
+		_, ok := reverseLookupMap.Lookup(digest)
+		return ok
+	})
+}
+
 // Scan implements the Scan method for local endpoints.
-func (e *endpoint) Scan(ctx context.Context, _ *core.Entry, full bool) (*core.Entry, bool, error, bool) {
+func (e *endpoint) Scan(ctx context.Context, _ *core.Entry, full, rehash bool, ignoreOverrides []string) (*core.Entry, bool, []*core.Problem, error, bool) {
+	// If a warmup scan is in progress, then wait for it to complete, since its
+	// results will be used to accelerate this scan. We perform this wait
+	// before acquiring the scan lock (which the warmup holds) so that it can
+	// be cancelled.
+	if e.warmupDone != nil {
+		select {
+		case <-e.warmupDone:
+		case <-ctx.Done():
+			return nil, false, nil, errors.New("scan cancelled while waiting for warmup"), false
+		}
+	}
+
 	// Grab the scan lock and defer its release.
 	e.scanLock.Lock()
 	defer e.scanLock.Unlock()
@@ -1005,7 +1511,18 @@ func (e *endpoint) Scan(ctx context.Context, _ *core.Entry, full bool) (*core.En
 	// that may have occurred during background cache writes. If we see any
 	// error, then we skip scanning and report them here.
 	if e.cacheWriteError != nil {
-		return nil, false, errors.Wrap(e.cacheWriteError, "unable to save cache to disk"), false
+		return nil, false, nil, errors.Wrap(e.cacheWriteError, "unable to save cache to disk"), false
+	}
+
+	// If a rehash has been requested, then discard the digest cache so that
+	// the digests of all files are recomputed. This is useful if the cache has
+	// been invalidated in a way that can't be detected using file metadata
+	// (e.g. by a filesystem restore that preserved modification times). We
+	// also have to avoid acceleration in this case, since it would re-use
+	// digests from the baseline snapshot.
+	if rehash {
+		e.cache = &core.Cache{}
+		full = true
 	}
 
 	// Perform a scan.
@@ -1020,31 +1537,82 @@ func (e *endpoint) Scan(ctx context.Context, _ *core.Entry, full bool) (*core.En
 	// that a full scan has been explicitly requested, but we don't make any
 	// change to the state of acceleration availability, because performing a
 	// full warm scan will only improve the accuracy of the baseline (most
-	// recent) snapshot, so acceleration will still work.
+	// recent) snapshot, so acceleration will still work. Similarly, we avoid
+	// acceleration if ignore overrides have been specified or were specified
+	// for the last scan, since the baseline snapshot wouldn't reflect the
+	// correct ignores.
 	//
 	// If we see any error while scanning, we just have to assume that it's due
 	// to concurrent modifications and suggest a retry.
-	if e.accelerateScan && !full {
+	if e.accelerateScan && !full && len(ignoreOverrides) == 0 && !e.snapshotOverridden {
 		if e.watchIsRecursive {
-			if err := e.scan(ctx, e.snapshot, e.recheckPaths); err != nil {
-				return nil, false, err, true
+			if err := e.scan(ctx, e.snapshot, e.recheckPaths, nil); err != nil {
+				return nil, false, nil, err, true
 			} else {
 				e.recheckPaths = make(map[string]bool, recheckPathsMaximumCapacity)
 			}
 		}
 	} else {
-		if err := e.scan(ctx, nil, nil); err != nil {
-			return nil, false, err, true
+		if err := e.scan(ctx, nil, nil, ignoreOverrides); err != nil {
+			return nil, false, nil, err, true
 		}
 	}
 
+	// If files were skipped because they're open for writing, then ensure that
+	// they're revisited once they're closed. There's no guarantee that closing
+	// them will generate a filesystem event, so we register them as re-check
+	// paths (for accelerated scans) and schedule a poll event to trigger a
+	// re-scan.
+	e.recheckOpenFiles()
+
+	// Remove any conflict sidecar files whose winning files have changed.
+	e.pruneConflictSidecars()
+
 	// Verify that we haven't exceeded the maximum entry count.
 	if e.lastScanEntryCount > e.maximumEntryCount {
-		return nil, false, errors.New("exceeded allowed entry count"), true
+		return nil, false, nil, errors.New("exceeded allowed entry count"), true
+	}
+
+	// Translate on-disk names, if necessary.
+	snapshot, skipped := e.snapshot, e.skipped
+	if e.nameTranslator != nil {
+		snapshot = e.nameTranslator.DecodeEntry(snapshot)
+		skipped = e.nameTranslator.DecodeProblems(skipped)
 	}
 
 	// Success.
-	return e.snapshot, e.preservesExecutability, nil, false
+	return snapshot, e.preservesExecutability, skipped, nil, false
+}
+
+// recheckOpenFiles registers files skipped by the last scan because they were
+// open for writing as re-check paths and schedules a poll event so that they'll
+// be revisited by a subsequent scan. If the re-check paths set would overflow
+// its allowed size, then acceleration is disabled until the recursive watching
+// Goroutine performs a full (warm) scan.
+// The caller must hold the endpoint's scan lock.
+func (e *endpoint) recheckOpenFiles() {
+	var found bool
+	for _, problem := range e.skipped {
+		if problem.Error != core.OpenFileSkippedError {
+			continue
+		}
+		found = true
+		if e.accelerateScan && e.watchIsRecursive {
+			if len(e.recheckPaths) == recheckPathsMaximumCapacity {
+				e.accelerateScan = false
+				e.recheckPaths = make(map[string]bool, recheckPathsMaximumCapacity)
+				select {
+				case e.recursiveWatchReenableAcceleration <- struct{}{}:
+				default:
+				}
+			} else {
+				e.recheckPaths[problem.Path] = true
+			}
+		}
+	}
+	if found {
+		time.AfterFunc(openFileRecheckInterval, e.strobePollEvents)
+	}
 }
 
 // stageFromRoot attempts to perform staging from local files by using a reverse
@@ -1062,7 +1630,7 @@ func (e *endpoint) stageFromRoot(
 	}
 
 	// Open the source file and defer its closure.
-	source, err := opener.Open(sourcePath)
+	source, err := opener.Open(e.onDiskPath(sourcePath))
 	if err != nil {
 		return false
 	}
@@ -1074,8 +1642,11 @@ func (e *endpoint) stageFromRoot(
 		return false
 	}
 
-	// Copy data to the sink and close it, then check for copy errors.
-	_, err = io.Copy(sink, source)
+	// Copy data to the sink and close it, then check for copy errors. We read
+	// the source using hole detection so that holes are preserved.
+	buffer := e.stagingBuffers.get(stagingCopyBufferSize)
+	_, err = io.CopyBuffer(sink, filesystem.NewSparseReader(source), buffer)
+	e.stagingBuffers.put(buffer)
 	sink.Close()
 	if err != nil {
 		return false
@@ -1086,6 +1657,125 @@ func (e *endpoint) stageFromRoot(
 	return err == nil
 }
 
+// stageFromContentStore attempts to perform staging from the shared content
+// store.
+func (e *endpoint) stageFromContentStore(path string, digest []byte) bool {
+	// If there's no content store, then there's nothing we can do.
+	if e.contentStore == nil {
+		return false
+	}
+
+	// Open the stored content and defer its closure.
+	source, err := e.contentStore.open(digest)
+	if err != nil {
+		return false
+	}
+	defer source.Close()
+
+	// Stage the content. Since the staging location is determined by the
+	// digest of the data written to the sink, verification also guards against
+	// corrupted content in the store.
+	return e.stageFromFile(path, digest, source)
+}
+
+// stageFromUndoArea attempts to perform staging from the undo area, which
+// indicates that a previous transition operation is being undone.
+func (e *endpoint) stageFromUndoArea(path string, digest []byte) bool {
+	// If undo support is disabled, then there's nothing we can do.
+	if e.undoArea == nil {
+		return false
+	}
+
+	// Open the retained content and defer its closure.
+	source, err := e.undoArea.open(digest)
+	if err != nil {
+		return false
+	}
+	defer source.Close()
+
+	// Stage the content. Verification guards against content that was
+	// modified between scanning and retention.
+	return e.stageFromFile(path, digest, source)
+}
+
+// stageFromFile stages the content of the specified file for the specified
+// path, verifying that it matches the specified digest.
+func (e *endpoint) stageFromFile(path string, digest []byte, source *os.File) bool {
+	// Create a staging sink. We explicitly manage its closure below.
+	sink, err := e.stager.Sink(path)
+	if err != nil {
+		return false
+	}
+
+	// Copy data to the sink and close it, then check for copy errors. We read
+	// the source using hole detection so that holes are preserved.
+	buffer := e.stagingBuffers.get(stagingCopyBufferSize)
+	_, err = io.CopyBuffer(sink, filesystem.NewSparseReader(source), buffer)
+	e.stagingBuffers.put(buffer)
+	sink.Close()
+	if err != nil {
+		return false
+	}
+
+	// Ensure that everything staged correctly.
+	_, err = e.stager.Provide(path, digest)
+	return err == nil
+}
+
+// computeSignatures computes the rsync signatures of the existing base files
+// for the specified paths, reading up to stagingConcurrency files concurrently.
+// Block buffers are drawn from the staging buffer pool, so workers may block
+// until buffers are available. For paths that don't exist or that can't be
+// read, an empty signature is used, which means to expect/use an empty base
+// when deltafying/patching. The provided opener is used by the first worker,
+// with additional workers using their own openers (since openers aren't safe
+// for concurrent usage).
+func (e *endpoint) computeSignatures(paths []string, opener *filesystem.Opener) []*rsync.Signature {
+	// Create per-worker rsync engines and openers.
+	workers := e.stagingConcurrency
+	if workers < 1 {
+		workers = 1
+	} else if workers > len(paths) {
+		workers = len(paths)
+	}
+	engines := make([]*rsync.Engine, workers)
+	openers := make([]*filesystem.Opener, workers)
+	for w := range engines {
+		engines[w] = rsync.NewEngine()
+		if w == 0 {
+			openers[w] = opener
+		} else {
+			openers[w] = filesystem.NewOpener(e.root)
+			defer openers[w].Close()
+		}
+	}
+
+	// Compute signatures.
+	signatures := make([]*rsync.Signature, len(paths))
+	concurrently(len(paths), workers, func(worker, p int) {
+		base, err := openers[worker].Open(e.onDiskPath(paths[p]))
+		if err != nil {
+			signatures[p] = &rsync.Signature{}
+			return
+		}
+		defer base.Close()
+		blockSize, err := rsync.OptimalBlockSizeForBase(base)
+		if err != nil {
+			blockSize = rsync.DefaultBlockSize
+		}
+		buffer := e.stagingBuffers.get(int(blockSize))
+		defer e.stagingBuffers.put(buffer)
+		if signature, err := engines[worker].SignatureWithBuffer(base, buffer); err != nil {
+			signatures[p] = &rsync.Signature{}
+		} else {
+			signatures[p] = signature
+		}
+	})
+
+	// Done.
+	return signatures
+}
+
 // Stage implements the Stage method for local endpoints.
 func (e *endpoint) Stage(paths []string, digests [][]byte) ([]string, []*rsync.Signature, rsync.Receiver, error) {
 	// If we're in a read-only mode, we shouldn't be staging files.
@@ -1113,6 +1803,13 @@ func (e *endpoint) Stage(paths []string, digests [][]byte) ([]string, []*rsync.S
 		return nil, nil, nil, errors.New("staging would exceeded allowed entry count")
 	}
 
+	// If we're operating in read-through mode, then placeholders will be
+	// created instead of staged content, so there's nothing to stage.
+	if e.readThrough {
+		e.scanLock.Unlock()
+		return nil, nil, nil, nil
+	}
+
 	// Generate a reverse lookup map from the cache, which we'll use shortly to
 	// detect renames and copies.
 	reverseLookupMap, err := e.cache.GenerateReverseLookupMap()
@@ -1124,6 +1821,18 @@ func (e *endpoint) Stage(paths []string, digests [][]byte) ([]string, []*rsync.S
 	// Release the scan lock.
 	e.scanLock.Unlock()
 
+	// Translate paths to their on-disk representations, if necessary. Paths
+	// that can't be represented won't be created when transitioning, so
+	// there's no need to stage content for them.
+	if e.nameTranslator != nil {
+		paths, digests = e.encodeStagingPaths(paths, digests)
+	}
+
+	// Similarly, exclude paths that are too long to be created.
+	if e.longPathFilter != nil {
+		paths, digests = e.longPathFilter.FilterStagingPaths(paths, digests)
+	}
+
 	// Create an opener that we can use file opening and defer its closure. We
 	// can't cache this across synchronization cycles since its path references
 	// may become invalidated or may prevent modifications.
@@ -1139,6 +1848,13 @@ func (e *endpoint) Stage(paths []string, digests [][]byte) ([]string, []*rsync.S
 	// can find (and stage) any files locally, which indicates that a file has
 	// been copied or renamed.
 	//
+	// Third, check if the content is available in the shared content store,
+	// which indicates that it's been staged by another endpoint (potentially
+	// belonging to another session).
+	//
+	// Fourth, check if the content is available in the undo area, which
+	// indicates that the last transition operation is being undone.
+	//
 	// If we manage to handle all files, then we can abort the staging
 	// operation.
 	filteredPaths := paths[:0]
@@ -1148,6 +1864,10 @@ func (e *endpoint) Stage(paths []string, digests [][]byte) ([]string, []*rsync.S
 			continue
 		} else if e.stageFromRoot(path, digest, reverseLookupMap, opener) {
 			continue
+		} else if e.stageFromContentStore(path, digest) {
+			continue
+		} else if e.stageFromUndoArea(path, digest) {
+			continue
 		} else {
 			filteredPaths = append(filteredPaths, path)
 		}
@@ -1156,40 +1876,295 @@ func (e *endpoint) Stage(paths []string, digests [][]byte) ([]string, []*rsync.S
 		return nil, nil, nil, nil
 	}
 
-	// Create an rsync engine.
-	engine := rsync.NewEngine()
-
-	// Compute signatures for each of the unstaged paths. For paths that don't
-	// exist or that can't be read, just use an empty signature, which means to
-	// expect/use an empty base when deltafying/patching.
-	signatures := make([]*rsync.Signature, len(filteredPaths))
-	for p, path := range filteredPaths {
-		if base, err := opener.Open(path); err != nil {
-			signatures[p] = &rsync.Signature{}
-			continue
-		} else if signature, err := engine.Signature(base, 0); err != nil {
-			base.Close()
-			signatures[p] = &rsync.Signature{}
-			continue
-		} else {
-			base.Close()
-			signatures[p] = signature
+	// Compute signatures for each of the unstaged paths.
+	signatures := e.computeSignatures(filteredPaths, opener)
+
+	// Create a receiver. If names are transcoded on disk, then the receiver
+	// needs to open base files using their on-disk paths, but content still
+	// needs to be staged using synchronization paths.
+	var receiver rsync.Receiver
+	if e.filenameTranscoder != nil {
+		receiverPaths := make([]string, len(filteredPaths))
+		for p, path := range filteredPaths {
+			receiverPaths[p] = e.onDiskPath(path)
 		}
+		sinker := newTranscodingSinker(e.stager, receiverPaths, filteredPaths)
+		receiver, err = rsync.NewReceiver(e.root, receiverPaths, signatures, sinker)
+	} else {
+		receiver, err = rsync.NewReceiver(e.root, filteredPaths, signatures, e.stager)
 	}
-
-	// Create a receiver.
-	receiver, err := rsync.NewReceiver(e.root, filteredPaths, signatures, e.stager)
 	if err != nil {
 		return nil, nil, nil, errors.Wrap(err, "unable to create rsync receiver")
 	}
 
+	// Translate the requested paths back to their synchronized form, since the
+	// controller will use them to request content from the opposite endpoint.
+	requestedPaths := filteredPaths
+	if e.nameTranslator != nil {
+		requestedPaths = make([]string, len(filteredPaths))
+		for p, path := range filteredPaths {
+			requestedPaths[p] = e.nameTranslator.DecodePath(path)
+		}
+	}
+
 	// Done.
-	return filteredPaths, signatures, receiver, nil
+	return requestedPaths, signatures, receiver, nil
+}
+
+// encodeStagingPaths translates the specified staging paths to their on-disk
+// representations, dropping any paths (and their corresponding digests) that
+// can't be represented.
+func (e *endpoint) encodeStagingPaths(paths []string, digests [][]byte) ([]string, [][]byte) {
+	encodedPaths := make([]string, 0, len(paths))
+	encodedDigests := make([][]byte, 0, len(digests))
+	for p, path := range paths {
+		if encoded, ok := e.nameTranslator.EncodePath(path); ok {
+			encodedPaths = append(encodedPaths, encoded)
+			encodedDigests = append(encodedDigests, digests[p])
+		}
+	}
+	return encodedPaths, encodedDigests
+}
+
+// onDiskPath computes the on-disk representation of the specified path (as seen
+// by core scanning and transitioning) relative to the synchronization root. If
+// the path can't be represented, then it's returned untranscoded, in which case
+// operations using it will fail to find the content.
+func (e *endpoint) onDiskPath(path string) string {
+	if encoded, ok := e.filenameTranscoder.EncodePath(path); ok {
+		return encoded
+	}
+	return path
+}
+
+// decodeOpenFiles translates the on-disk paths of files open for writing to
+// their synchronization paths, dropping any paths that can't be decoded (since
+// scans will skip their content anyway).
+func (e *endpoint) decodeOpenFiles(openFiles map[string]bool) map[string]bool {
+	decoded := make(map[string]bool, len(openFiles))
+	for path := range openFiles {
+		if decodedPath, ok := e.filenameTranscoder.DecodePath(path); ok {
+			decoded[decodedPath] = true
+		}
+	}
+	return decoded
+}
+
+// supplyPaths translates the specified synchronization paths to the on-disk
+// paths from which they're supplied.
+func (e *endpoint) supplyPaths(paths []string) []string {
+	// Translate paths to their on-disk representations, if necessary. Paths
+	// come from our own scans, so they can always be represented, but if one
+	// can't then we leave it untranslated and let transmission fail to find it.
+	if e.nameTranslator != nil {
+		encodedPaths := make([]string, len(paths))
+		for p, path := range paths {
+			if encoded, ok := e.nameTranslator.EncodePath(path); ok {
+				encodedPaths[p] = encoded
+			} else {
+				encodedPaths[p] = path
+			}
+		}
+		paths = encodedPaths
+	}
+
+	// Transcode paths to their on-disk encoding, if necessary.
+	if e.filenameTranscoder != nil {
+		encodedPaths := make([]string, len(paths))
+		for p, path := range paths {
+			encodedPaths[p] = e.onDiskPath(path)
+		}
+		paths = encodedPaths
+	}
+
+	// Done.
+	return paths
 }
 
 // Supply implements the supply method for local endpoints.
-func (e *endpoint) Supply(paths []string, signatures []*rsync.Signature, receiver rsync.Receiver) error {
-	return rsync.Transmit(e.root, paths, signatures, receiver)
+func (e *endpoint) Supply(paths []string, signatures []*rsync.Signature, receiver rsync.Receiver, budget *rsync.TransmissionBudget) error {
+	return rsync.Transmit(e.root, e.supplyPaths(paths), signatures, receiver, e.maximumTransmissionRetries, e.transferHeuristic, budget)
+}
+
+// AvailableSpace implements the AvailableSpace method for local endpoints.
+func (e *endpoint) AvailableSpace() (uint64, error) {
+	// Staged files are written to the staging root, which may not exist yet,
+	// so query the nearest existing directory containing it.
+	path := e.stager.root
+	for {
+		if _, err := os.Lstat(path); err == nil {
+			break
+		} else if parent := filepath.Dir(path); parent == path {
+			return math.MaxUint64, nil
+		} else {
+			path = parent
+		}
+	}
+
+	// Query the available space. Failure to do so isn't fatal to the endpoint,
+	// we just can't provide an answer.
+	available, err := filesystem.AvailableSpace(path)
+	if err != nil {
+		if err != filesystem.ErrSpaceQueryUnsupported {
+			e.logger.Warning("Unable to query available space:", err)
+		}
+		return math.MaxUint64, nil
+	}
+	return available, nil
+}
+
+// Measure implements the Measure method for local endpoints.
+func (e *endpoint) Measure(paths []string) (uint64, error) {
+	var result uint64
+	for _, path := range e.supplyPaths(paths) {
+		if metadata, err := os.Lstat(filepath.Join(e.root, path)); err == nil && metadata.Mode().IsRegular() {
+			result += uint64(metadata.Size())
+		}
+	}
+	return result, nil
+}
+
+// resolveConflict performs external resolution for the specified conflict
+// resolution transition. If the conflict is resolved, then a standard
+// transition that replaces beta's version of the file with the (staged)
+// resolved content is returned. If the conflict is left in place, then a nil
+// transition and nil error are returned. If resolution fails, then an error is
+// returned and the conflict should be left in place. The scan lock must be
+// held by the caller.
+func (e *endpoint) resolveConflict(ctx context.Context, transition *core.Change) (*core.Change, error) {
+	// Ensure that we have a conflict resolver.
+	if e.conflictResolver == nil {
+		return nil, errors.New("conflict resolver not configured")
+	}
+
+	// Open alpha's version of the file from the stager.
+	alphaPath, err := e.stager.Provide(transition.Path, transition.New.Digest)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to locate alpha content")
+	}
+	alpha, err := os.Open(alphaPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open alpha content")
+	}
+	defer alpha.Close()
+
+	// Open beta's version of the file from the synchronization root.
+	opener := filesystem.NewOpener(e.root)
+	defer opener.Close()
+	beta, err := opener.Open(e.onDiskPath(transition.Path))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open beta content")
+	}
+	defer beta.Close()
+
+	// Invoke the resolver.
+	resolved, err := e.conflictResolver.resolve(ctx, transition.Path, alpha, beta)
+	if err != nil {
+		return nil, err
+	} else if resolved == nil {
+		return nil, nil
+	}
+	defer resolved.Close()
+
+	// Stage the resolved content, computing its digest along the way. We're
+	// holding the scan lock, so it's safe to use the scan hasher. If the file
+	// is subject to line ending translation, then we compute the digest over
+	// canonicalized content, consistent with scanning and staging.
+	sink, err := e.stager.Sink(transition.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create staging sink for resolved content")
+	}
+	e.hasher.Reset()
+	var digester io.Writer = e.hasher
+	var canonicalizer *core.LineEndingWriter
+	if e.lineEndings.Matches(transition.Path) {
+		canonicalizer = core.NewLineEndingWriter(e.hasher, core.LineEndingStyle_LineEndingStyleLF)
+		digester = canonicalizer
+	}
+	_, err = io.Copy(io.MultiWriter(sink, digester), resolved)
+	sink.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to stage resolved content")
+	}
+	if canonicalizer != nil {
+		canonicalizer.Flush()
+	}
+	digest := e.hasher.Sum(nil)
+
+	// If the resolved content is identical to beta's existing content, then
+	// there's no transition that would resolve the conflict from the
+	// controller's perspective, so leave it in place.
+	if bytes.Equal(digest, transition.Old.Digest) {
+		return nil, errors.New("resolved content identical to beta content")
+	}
+
+	// Create a transition that replaces beta's version of the file.
+	return &core.Change{
+		Path: transition.Path,
+		Old:  transition.Old,
+		New: &core.Entry{
+			Kind:       core.EntryKind_File,
+			Executable: transition.Old.Executable,
+			Digest:     digest,
+		},
+	}, nil
+}
+
+// resolveConflicts performs external resolution for any conflict resolution
+// transitions in the specified transition list and writes conflict sidecar
+// files for any conflict sidecar transitions. It returns the transitions that
+// should be passed to core.Transition (with resolved conflicts converted to
+// standard transitions), a list of conflicts left in place (and their results),
+// and any problems encountered during resolution. The scan lock must be held by
+// the caller.
+func (e *endpoint) resolveConflicts(ctx context.Context, transitions []*core.Change) ([]*core.Change, []unresolvedConflict, []*core.Problem) {
+	// Check whether or not any conflict resolution has been requested. If not,
+	// then we can just pass through the transitions.
+	var requested bool
+	for _, transition := range transitions {
+		if transition.Resolve || transition.ConflictSidecar {
+			requested = true
+			break
+		}
+	}
+	if !requested {
+		return transitions, nil, nil
+	}
+
+	// Process transitions. We create a new transition list, rather than
+	// modifying the existing one in-place, since the controller may be holding
+	// on to it.
+	pending := make([]*core.Change, 0, len(transitions))
+	var unresolved []unresolvedConflict
+	var problems []*core.Problem
+	for t, transition := range transitions {
+		if transition.ConflictSidecar {
+			if err := e.writeConflictSidecar(transition); err != nil {
+				unresolved = append(unresolved, unresolvedConflict{t, transition.Old})
+				problems = append(problems, &core.Problem{
+					Path:  transition.Path,
+					Error: errors.Wrap(err, "unable to create conflict sidecar").Error(),
+				})
+			} else {
+				pending = append(pending, transition)
+			}
+		} else if !transition.Resolve {
+			pending = append(pending, transition)
+		} else if resolved, err := e.resolveConflict(ctx, transition); err != nil {
+			unresolved = append(unresolved, unresolvedConflict{t, transition.Old})
+			problems = append(problems, &core.Problem{
+				Path:  transition.Path,
+				Error: errors.Wrap(err, "unable to resolve conflict").Error(),
+			})
+		} else if resolved == nil {
+			unresolved = append(unresolved, unresolvedConflict{t, transition.Old})
+		} else {
+			pending = append(pending, resolved)
+		}
+	}
+
+	// Done.
+	return pending, unresolved, problems
 }
 
 // Transition implements the Transition method for local endpoints.
@@ -1240,11 +2215,38 @@ func (e *endpoint) Transition(ctx context.Context, transitions []*core.Change) (
 		}
 	}
 
+	// Translate transitions to their on-disk representations, if necessary,
+	// excluding any content with names that can't be represented.
+	var translationProblems []*core.Problem
+	if e.nameTranslator != nil {
+		transitions, translationProblems = e.nameTranslator.EncodeTransitions(transitions)
+	}
+
+	// Exclude any content with on-disk paths that are too long to be created.
+	var longPathProblems []*core.Problem
+	if e.longPathFilter != nil {
+		transitions, longPathProblems = e.longPathFilter.FilterTransitions(transitions)
+	}
+
+	// Handle any conflict resolution transitions, converting resolved conflicts
+	// into standard transitions and setting aside those left in place.
+	pending, unresolved, resolutionProblems := e.resolveConflicts(ctx, transitions)
+
+	// If undo support is enabled, then retain the previous content of any files
+	// that will be overwritten or removed. If this isn't possible, then the
+	// transition simply won't be undoable.
+	if e.undoArea != nil && !e.readThrough {
+		if err := e.undoArea.record(e.root, e.filenameTranscoder, pending); err != nil {
+			e.logger.Debug("Unable to retain previous content for undo:", err)
+			e.undoArea.wipe()
+		}
+	}
+
 	// Perform the transition.
 	results, problems, stagerMissingFiles := core.Transition(
 		ctx,
 		e.root,
-		transitions,
+		pending,
 		e.cache,
 		e.symlinkMode,
 		e.defaultFileMode,
@@ -1252,8 +2254,44 @@ func (e *endpoint) Transition(ctx context.Context, transitions []*core.Change) (
 		e.defaultOwnership,
 		e.decomposesUnicode,
 		e.stager,
+		&core.TransitionOptions{
+			DeferSymlinks:         e.deferSymlinks,
+			DurabilityMode:        e.durabilityMode,
+			Syncer:                e.syncer,
+			ACLMode:               e.aclMode,
+			PreserveHardLinks:     e.preserveHardLinks,
+			PreserveMacOSMetadata: e.preserveMacOSMetadata,
+			PreserveFileFlags:     e.preserveFileFlags,
+			CreatePlaceholders:    e.readThrough,
+			CaseInsensitive:       !e.capabilities.CaseSensitive,
+			ProtectedPaths:        e.protectedPaths,
+			Verifier:              e.verifier,
+			Filenames:             e.filenameTranscoder,
+		},
 	)
 
+	// Track (or clean up) any conflict sidecar files that we've created.
+	e.trackConflictSidecars(pending, results)
+
+	// Merge in the results and problems for conflicts left in place.
+	if len(unresolved) > 0 {
+		results = spliceUnresolvedConflicts(results, unresolved)
+	}
+	if len(resolutionProblems) > 0 {
+		problems = append(resolutionProblems, problems...)
+	}
+	if len(longPathProblems) > 0 {
+		problems = append(longPathProblems, problems...)
+	}
+
+	// Translate results and problems back to their synchronized form.
+	if e.nameTranslator != nil {
+		for r, result := range results {
+			results[r] = e.nameTranslator.DecodeEntry(result)
+		}
+		problems = append(translationProblems, e.nameTranslator.DecodeProblems(problems)...)
+	}
+
 	// In case there's a recursive watching Goroutine that doesn't currently
 	// have a watch established (due to non-existence of the synchronization
 	// root), send a signal that watch establishment should be retried
@@ -1309,6 +2347,10 @@ func (e *endpoint) Transition(ctx context.Context, transitions []*core.Change) (
 	// files.
 	e.stager.wipe()
 
+	// Mark shared content store references for pruning once the next scan has
+	// updated the cache to reflect the transition.
+	e.contentStorePrunePending = true
+
 	// Done.
 	return results, problems, stagerMissingFiles, nil
 }
@@ -1,5 +1,9 @@
 package local
 
+import (
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+)
+
 // endpointOptions controls the override behavior for a local endpoint.
 type endpointOptions struct {
 	// cachePathCallback can specify a callback that will be used to compute the
@@ -8,6 +12,12 @@ type endpointOptions struct {
 	// stagingRootCallback can specify a callback that will be used to compute
 	// the staging root.
 	stagingRootCallback func(string, bool) (string, bool, error)
+	// syncer can specify a syncer that will be used to flush modifications to
+	// durable storage.
+	syncer filesystem.Syncer
+	// readThrough indicates that the endpoint should operate in read-through
+	// mode.
+	readThrough bool
 }
 
 // EndpointOption is the interface for specifying endpoint options. It cannot be
@@ -61,3 +71,28 @@ func WithStagingRootCallback(callback func(string, bool) (string, bool, error))
 		options.stagingRootCallback = callback
 	})
 }
+
+// WithSyncer overrides the syncer that the endpoint uses to flush modifications
+// to durable storage when transitioning. The durability mode still determines
+// which flushing operations are performed.
+func WithSyncer(syncer filesystem.Syncer) EndpointOption {
+	return newFunctionEndpointOption(func(options *endpointOptions) {
+		options.syncer = syncer
+	})
+}
+
+// WithReadThrough enables an experimental read-through mode for the endpoint.
+// In this mode, the endpoint doesn't stage any content and instead represents
+// incoming files with placeholders (empty files marked with the digest of their
+// content) that are scanned as if they had their full content. Placeholders can
+// then be materialized on demand using a core.Materializer with a suitable
+// content fetching callback. Placeholders are only supported on Linux and macOS
+// (on filesystems supporting extended attributes), with files that can't be
+// represented by placeholders being reported as transition problems. Note that
+// the endpoint can't supply the content of unmaterialized placeholders to the
+// opposite endpoint.
+func WithReadThrough() EndpointOption {
+	return newFunctionEndpointOption(func(options *endpointOptions) {
+		options.readThrough = true
+	})
+}
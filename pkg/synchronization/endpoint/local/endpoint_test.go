@@ -1,3 +1,1263 @@
 package local
 
-// TODO: Implement.
+import (
+	"context"
+	"crypto/sha1"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/rsync"
+)
+
+// testEndpointSynchronizeFile drives the specified endpoint through a full
+// synchronization cycle that creates (or, if remove is true, removes) a file
+// with the specified name and content, sourcing any content that needs to be
+// transmitted from sourceRoot. It returns the list of paths for which content
+// had to be transmitted.
+func testEndpointSynchronizeFile(
+	t *testing.T,
+	endpoint synchronization.Endpoint,
+	sourceRoot, name string,
+	content []byte,
+	remove bool,
+) []string {
+	// Compute the file entry.
+	digest := sha1.Sum(content)
+	entry := &core.Entry{Kind: core.EntryKind_File, Digest: digest[:]}
+
+	// Perform a scan.
+	if _, _, _, err, _ := endpoint.Scan(context.Background(), nil, true, false, nil); err != nil {
+		t.Fatal("unable to perform scan:", err)
+	}
+
+	// Compute the transition and perform any required staging.
+	var transition *core.Change
+	var transmitted []string
+	if remove {
+		transition = &core.Change{Path: name, Old: entry}
+	} else {
+		transition = &core.Change{Path: name, New: entry}
+		paths, signatures, receiver, err := endpoint.Stage([]string{name}, [][]byte{digest[:]})
+		if err != nil {
+			t.Fatal("unable to perform staging:", err)
+		}
+		if receiver != nil {
+			if err := rsync.Transmit(sourceRoot, paths, signatures, receiver, 0, nil, nil); err != nil {
+				t.Fatal("unable to transmit content:", err)
+			}
+		}
+		transmitted = paths
+	}
+
+	// Perform the transition.
+	if _, problems, missing, err := endpoint.Transition(context.Background(), []*core.Change{transition}); err != nil {
+		t.Fatal("unable to perform transition:", err)
+	} else if len(problems) > 0 {
+		t.Fatal("transition encountered problems:", problems[0].Error)
+	} else if missing {
+		t.Fatal("transition reported missing staged files")
+	}
+
+	// Perform a post-transition scan, which is when content store references
+	// are pruned.
+	if _, _, _, err, _ := endpoint.Scan(context.Background(), nil, true, false, nil); err != nil {
+		t.Fatal("unable to perform post-transition scan:", err)
+	}
+
+	// Done.
+	return transmitted
+}
+
+// TestEndpointContentStoreSharedAcrossSessions tests that content staged by one
+// session's endpoint is reused by another session's endpoint via the shared
+// content store and that it's evicted once neither endpoint references it.
+func TestEndpointContentStoreSharedAcrossSessions(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_local_endpoint")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Redirect the data directory and defer restoration of the environment.
+	previous, previousSet := os.LookupEnv("MUTAGEN_DATA_DIRECTORY")
+	if err := os.Setenv("MUTAGEN_DATA_DIRECTORY", filepath.Join(directory, "data")); err != nil {
+		t.Fatal("unable to set data directory environment variable:", err)
+	}
+	defer func() {
+		if previousSet {
+			os.Setenv("MUTAGEN_DATA_DIRECTORY", previous)
+		} else {
+			os.Unsetenv("MUTAGEN_DATA_DIRECTORY")
+		}
+	}()
+
+	// Create a source root with the content to be synchronized.
+	content := []byte("content shared between sessions")
+	digest := sha1.Sum(content)
+	sourceRoot := filepath.Join(directory, "source")
+	if err := os.Mkdir(sourceRoot, 0700); err != nil {
+		t.Fatal("unable to create source root:", err)
+	} else if err := ioutil.WriteFile(filepath.Join(sourceRoot, "file"), content, 0600); err != nil {
+		t.Fatal("unable to create source content:", err)
+	}
+
+	// Create two endpoints belonging to different sessions.
+	configuration := &synchronization.Configuration{
+		WatchMode:        synchronization.WatchMode_WatchModeNoWatch,
+		ContentStoreMode: synchronization.ContentStoreMode_ContentStoreModeShared,
+	}
+	var endpoints [2]synchronization.Endpoint
+	var roots [2]string
+	for i, session := range []string{"first", "second"} {
+		roots[i] = filepath.Join(directory, session)
+		if err := os.Mkdir(roots[i], 0700); err != nil {
+			t.Fatal("unable to create synchronization root:", err)
+		}
+		endpoints[i], err = NewEndpoint(
+			logging.RootLogger,
+			roots[i],
+			session,
+			synchronization.Version_Version1,
+			configuration,
+			false,
+		)
+		if err != nil {
+			t.Fatal("unable to create endpoint:", err)
+		}
+		defer endpoints[i].Shutdown()
+	}
+
+	// Grab the shared content store.
+	store, err := sharedContentStore()
+	if err != nil {
+		t.Fatal("unable to access shared content store:", err)
+	}
+
+	// Synchronize the file to the first endpoint and verify that its content
+	// had to be transmitted and that it was recorded in the content store.
+	if transmitted := testEndpointSynchronizeFile(t, endpoints[0], sourceRoot, "file", content, false); len(transmitted) != 1 {
+		t.Error("content not transmitted to first endpoint")
+	}
+	if count, err := store.referenceCount(digest[:]); err != nil {
+		t.Fatal("unable to compute reference count:", err)
+	} else if count != 1 {
+		t.Error("reference count incorrect after first staging:", count, "!=", 1)
+	}
+
+	// Synchronize the file to the second endpoint and verify that its content
+	// was satisfied from the content store without transmission.
+	if transmitted := testEndpointSynchronizeFile(t, endpoints[1], sourceRoot, "file", content, false); len(transmitted) != 0 {
+		t.Error("content transmitted to second endpoint despite being stored")
+	}
+	if count, err := store.referenceCount(digest[:]); err != nil {
+		t.Fatal("unable to compute reference count:", err)
+	} else if count != 2 {
+		t.Error("reference count incorrect after second staging:", count, "!=", 2)
+	}
+
+	// Verify the synchronized content on both endpoints.
+	for _, root := range roots {
+		if synchronized, err := ioutil.ReadFile(filepath.Join(root, "file")); err != nil {
+			t.Fatal("unable to read synchronized content:", err)
+		} else if string(synchronized) != string(content) {
+			t.Error("synchronized content does not match expected")
+		}
+	}
+
+	// Remove the file from the first endpoint and verify that the content is
+	// retained for the second endpoint.
+	testEndpointSynchronizeFile(t, endpoints[0], sourceRoot, "file", content, true)
+	if count, err := store.referenceCount(digest[:]); err != nil {
+		t.Fatal("unable to compute reference count:", err)
+	} else if count != 1 {
+		t.Error("reference count incorrect after first removal:", count, "!=", 1)
+	}
+	if file, err := store.open(digest[:]); err != nil {
+		t.Error("content evicted while still referenced:", err)
+	} else {
+		file.Close()
+	}
+
+	// Remove the file from the second endpoint and verify that the content has
+	// been evicted.
+	testEndpointSynchronizeFile(t, endpoints[1], sourceRoot, "file", content, true)
+	if count, err := store.referenceCount(digest[:]); err != nil {
+		t.Fatal("unable to compute reference count:", err)
+	} else if count != 0 {
+		t.Error("reference count incorrect after second removal:", count, "!=", 0)
+	}
+	if _, err := store.open(digest[:]); !os.IsNotExist(err) {
+		t.Error("content not evicted after all references released")
+	}
+}
+
+// TestEndpointConflictResolution tests that endpoints handle conflict
+// resolution transitions using an external conflict resolver.
+func TestEndpointConflictResolution(t *testing.T) {
+	// Stub resolvers are POSIX shell scripts.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Compute alpha's content, beta's content, and the merged content.
+	alphaContent := []byte("alpha\n")
+	alphaDigest := sha1.Sum(alphaContent)
+	alphaEntry := &core.Entry{Kind: core.EntryKind_File, Digest: alphaDigest[:]}
+	betaContent := []byte("beta\n")
+	betaDigest := sha1.Sum(betaContent)
+	betaEntry := &core.Entry{Kind: core.EntryKind_File, Digest: betaDigest[:]}
+	mergedContent := []byte("alpha\nbeta\n")
+	mergedDigest := sha1.Sum(mergedContent)
+	mergedEntry := &core.Entry{Kind: core.EntryKind_File, Digest: mergedDigest[:]}
+
+	// Set up test cases.
+	testCases := []struct {
+		description     string
+		script          string
+		expectedResult  *core.Entry
+		expectProblem   bool
+		expectedContent []byte
+	}{
+		{"merge", testConflictResolverMergeScript, mergedEntry, false, mergedContent},
+		{"conflicted", testConflictResolverConflictedScript, betaEntry, false, betaContent},
+		{"failure", testConflictResolverFailureScript, betaEntry, true, betaContent},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		// Create a temporary directory.
+		directory, err := ioutil.TempDir("", "mutagen_local_endpoint")
+		if err != nil {
+			t.Fatal("unable to create temporary directory:", err)
+		}
+
+		// Create a source root containing alpha's content and a
+		// synchronization root containing beta's content.
+		sourceRoot := filepath.Join(directory, "source")
+		root := filepath.Join(directory, "root")
+		if err := os.Mkdir(sourceRoot, 0700); err != nil {
+			t.Fatal("unable to create source root:", err)
+		} else if err := ioutil.WriteFile(filepath.Join(sourceRoot, "file"), alphaContent, 0600); err != nil {
+			t.Fatal("unable to create alpha content:", err)
+		} else if err := os.Mkdir(root, 0700); err != nil {
+			t.Fatal("unable to create synchronization root:", err)
+		} else if err := ioutil.WriteFile(filepath.Join(root, "file"), betaContent, 0600); err != nil {
+			t.Fatal("unable to create beta content:", err)
+		}
+
+		// Create the endpoint.
+		resolver := testConflictResolver(t, directory, testCase.script, 10*time.Second)
+		configuration := &synchronization.Configuration{
+			WatchMode:               synchronization.WatchMode_WatchModeNoWatch,
+			ConflictResolverCommand: resolver.command,
+		}
+		endpoint, err := NewEndpoint(
+			logging.RootLogger,
+			root,
+			"resolution",
+			synchronization.Version_Version1,
+			configuration,
+			false,
+			WithCachePathCallback(func(_ string, _ bool) (string, error) {
+				return filepath.Join(directory, "cache"), nil
+			}),
+			WithStagingRootCallback(func(_ string, _ bool) (string, bool, error) {
+				return filepath.Join(directory, "staging"), false, nil
+			}),
+		)
+		if err != nil {
+			t.Fatal("unable to create endpoint:", err)
+		}
+
+		// Perform a scan.
+		if _, _, _, err, _ := endpoint.Scan(context.Background(), nil, true, false, nil); err != nil {
+			t.Fatal("unable to perform scan:", err)
+		}
+
+		// Stage alpha's content.
+		paths, signatures, receiver, err := endpoint.Stage([]string{"file"}, [][]byte{alphaDigest[:]})
+		if err != nil {
+			t.Fatal("unable to perform staging:", err)
+		} else if receiver != nil {
+			if err := rsync.Transmit(sourceRoot, paths, signatures, receiver, 0, nil, nil); err != nil {
+				t.Fatal("unable to transmit content:", err)
+			}
+		}
+
+		// Perform the conflict resolution transition.
+		transition := &core.Change{Path: "file", Old: betaEntry, New: alphaEntry, Resolve: true}
+		results, problems, missing, err := endpoint.Transition(context.Background(), []*core.Change{transition})
+		if err != nil {
+			t.Fatalf("%s: unable to perform transition: %v", testCase.description, err)
+		} else if missing {
+			t.Errorf("%s: transition reported missing staged files", testCase.description)
+		} else if len(results) != 1 {
+			t.Fatalf("%s: unexpected result count: %d != 1", testCase.description, len(results))
+		} else if !results[0].Equal(testCase.expectedResult) {
+			t.Errorf("%s: transition result does not match expected", testCase.description)
+		}
+		if testCase.expectProblem && len(problems) == 0 {
+			t.Errorf("%s: transition did not report problems", testCase.description)
+		} else if !testCase.expectProblem && len(problems) > 0 {
+			t.Errorf("%s: transition reported problems: %s", testCase.description, problems[0].Error)
+		}
+
+		// Verify the content on disk.
+		if content, err := ioutil.ReadFile(filepath.Join(root, "file")); err != nil {
+			t.Errorf("%s: unable to read content: %v", testCase.description, err)
+		} else if string(content) != string(testCase.expectedContent) {
+			t.Errorf("%s: content does not match expected: %q != %q",
+				testCase.description, content, testCase.expectedContent,
+			)
+		}
+
+		// Shut down the endpoint and remove the temporary directory.
+		endpoint.Shutdown()
+		os.RemoveAll(directory)
+	}
+}
+
+// TestEndpointConflictSidecar tests that endpoints handle conflict sidecar
+// transitions by preserving their content in sidecar files, that sidecar files
+// are excluded from scans, and that sidecar files are removed once the winning
+// file is modified.
+func TestEndpointConflictSidecar(t *testing.T) {
+	// Compute alpha's content and beta's content.
+	alphaContent := []byte("alpha\n")
+	alphaDigest := sha1.Sum(alphaContent)
+	alphaEntry := &core.Entry{Kind: core.EntryKind_File, Digest: alphaDigest[:]}
+	betaContent := []byte("beta\n")
+	betaDigest := sha1.Sum(betaContent)
+	betaEntry := &core.Entry{Kind: core.EntryKind_File, Digest: betaDigest[:]}
+
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_local_endpoint")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create a source root containing alpha's content and a synchronization
+	// root containing beta's content.
+	sourceRoot := filepath.Join(directory, "source")
+	root := filepath.Join(directory, "root")
+	if err := os.Mkdir(sourceRoot, 0700); err != nil {
+		t.Fatal("unable to create source root:", err)
+	} else if err := ioutil.WriteFile(filepath.Join(sourceRoot, "file"), alphaContent, 0600); err != nil {
+		t.Fatal("unable to create alpha content:", err)
+	} else if err := os.Mkdir(root, 0700); err != nil {
+		t.Fatal("unable to create synchronization root:", err)
+	} else if err := ioutil.WriteFile(filepath.Join(root, "file"), betaContent, 0600); err != nil {
+		t.Fatal("unable to create beta content:", err)
+	}
+
+	// Create the endpoint and defer its shutdown.
+	endpoint, err := NewEndpoint(
+		logging.RootLogger,
+		root,
+		"sidecar",
+		synchronization.Version_Version1,
+		&synchronization.Configuration{
+			WatchMode:        synchronization.WatchMode_WatchModeNoWatch,
+			ConflictSidecars: true,
+		},
+		false,
+		WithCachePathCallback(func(_ string, _ bool) (string, error) {
+			return filepath.Join(directory, "cache"), nil
+		}),
+		WithStagingRootCallback(func(_ string, _ bool) (string, bool, error) {
+			return filepath.Join(directory, "staging"), false, nil
+		}),
+	)
+	if err != nil {
+		t.Fatal("unable to create endpoint:", err)
+	}
+	defer endpoint.Shutdown()
+
+	// Perform a scan.
+	if _, _, _, err, _ := endpoint.Scan(context.Background(), nil, true, false, nil); err != nil {
+		t.Fatal("unable to perform scan:", err)
+	}
+
+	// Stage alpha's content.
+	paths, signatures, receiver, err := endpoint.Stage([]string{"file"}, [][]byte{alphaDigest[:]})
+	if err != nil {
+		t.Fatal("unable to perform staging:", err)
+	} else if receiver != nil {
+		if err := rsync.Transmit(sourceRoot, paths, signatures, receiver, 0, nil, nil); err != nil {
+			t.Fatal("unable to transmit content:", err)
+		}
+	}
+
+	// Perform the conflict sidecar transition.
+	transition := &core.Change{Path: "file", Old: betaEntry, New: alphaEntry, ConflictSidecar: true}
+	results, problems, missing, err := endpoint.Transition(context.Background(), []*core.Change{transition})
+	if err != nil {
+		t.Fatal("unable to perform transition:", err)
+	} else if missing {
+		t.Error("transition reported missing staged files")
+	} else if len(problems) > 0 {
+		t.Error("transition reported problems:", problems[0].Error)
+	} else if len(results) != 1 || !results[0].Equal(alphaEntry) {
+		t.Fatal("transition result does not match expected")
+	}
+
+	// Verify the content on disk.
+	sidecarPath := filepath.Join(root, "file"+core.ConflictSidecarSuffix)
+	if content, err := ioutil.ReadFile(filepath.Join(root, "file")); err != nil {
+		t.Error("unable to read content:", err)
+	} else if string(content) != string(alphaContent) {
+		t.Error("content does not match alpha content")
+	}
+	if content, err := ioutil.ReadFile(sidecarPath); err != nil {
+		t.Fatal("unable to read sidecar content:", err)
+	} else if string(content) != string(betaContent) {
+		t.Error("sidecar content does not match beta content")
+	}
+
+	// Verify that the sidecar file is excluded from scans.
+	snapshot, _, _, err, _ := endpoint.Scan(context.Background(), nil, true, false, nil)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	} else if _, ok := snapshot.Contents["file"+core.ConflictSidecarSuffix]; ok {
+		t.Error("sidecar file included in snapshot")
+	} else if _, err := os.Lstat(sidecarPath); err != nil {
+		t.Error("sidecar file removed without modification:", err)
+	}
+
+	// Modify the winning file and verify that the next scan removes the sidecar
+	// file.
+	if err := ioutil.WriteFile(filepath.Join(root, "file"), []byte("modified\n"), 0600); err != nil {
+		t.Fatal("unable to modify content:", err)
+	}
+	if _, _, _, err, _ := endpoint.Scan(context.Background(), nil, true, false, nil); err != nil {
+		t.Fatal("unable to perform scan:", err)
+	} else if _, err := os.Lstat(sidecarPath); !os.IsNotExist(err) {
+		t.Error("sidecar file not removed after modification")
+	}
+}
+
+// TestEndpointScanIgnoreOverrides tests that ignore overrides provided to Scan
+// apply to that scan only.
+func TestEndpointScanIgnoreOverrides(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_local_endpoint")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Redirect the data directory and defer restoration of the environment.
+	previous, previousSet := os.LookupEnv("MUTAGEN_DATA_DIRECTORY")
+	if err := os.Setenv("MUTAGEN_DATA_DIRECTORY", filepath.Join(directory, "data")); err != nil {
+		t.Fatal("unable to set data directory environment variable:", err)
+	}
+	defer func() {
+		if previousSet {
+			os.Setenv("MUTAGEN_DATA_DIRECTORY", previous)
+		} else {
+			os.Unsetenv("MUTAGEN_DATA_DIRECTORY")
+		}
+	}()
+
+	// Create a synchronization root with normal and ignored content.
+	root := filepath.Join(directory, "root")
+	if err := os.Mkdir(root, 0700); err != nil {
+		t.Fatal("unable to create synchronization root:", err)
+	}
+	for _, name := range []string{"kept", "ignored"} {
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte(name), 0600); err != nil {
+			t.Fatal("unable to create content:", err)
+		}
+	}
+
+	// Create an endpoint that ignores some of the content.
+	endpoint, err := NewEndpoint(
+		logging.RootLogger,
+		root,
+		"session",
+		synchronization.Version_Version1,
+		&synchronization.Configuration{
+			WatchMode: synchronization.WatchMode_WatchModeNoWatch,
+			Ignores:   []string{"ignored"},
+		},
+		true,
+	)
+	if err != nil {
+		t.Fatal("unable to create endpoint:", err)
+	}
+	defer endpoint.Shutdown()
+
+	// Perform a sequence of scans with and without overrides and verify that
+	// overrides only apply to the scans for which they're specified.
+	testCases := []struct {
+		ignoreOverrides []string
+		expectIgnored   bool
+	}{
+		{nil, false},
+		{[]string{"ignored"}, true},
+		{nil, false},
+		{nil, false},
+	}
+	for i, testCase := range testCases {
+		snapshot, _, _, err, _ := endpoint.Scan(context.Background(), nil, false, false, testCase.ignoreOverrides)
+		if err != nil {
+			t.Fatalf("unable to perform scan %d: %v", i, err)
+		} else if snapshot.Contents["kept"] == nil {
+			t.Errorf("normal content missing from scan %d", i)
+		} else if (snapshot.Contents["ignored"] != nil) != testCase.expectIgnored {
+			t.Errorf("ignored content inclusion incorrect for scan %d", i)
+		}
+	}
+}
+
+// TestEndpointScanRehash tests that requesting a rehash from Scan discards the
+// endpoint's digest cache and recomputes the digests of all files, even if
+// their metadata hasn't changed.
+func TestEndpointScanRehash(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_local_endpoint")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Redirect the data directory and defer restoration of the environment.
+	previous, previousSet := os.LookupEnv("MUTAGEN_DATA_DIRECTORY")
+	if err := os.Setenv("MUTAGEN_DATA_DIRECTORY", filepath.Join(directory, "data")); err != nil {
+		t.Fatal("unable to set data directory environment variable:", err)
+	}
+	defer func() {
+		if previousSet {
+			os.Setenv("MUTAGEN_DATA_DIRECTORY", previous)
+		} else {
+			os.Unsetenv("MUTAGEN_DATA_DIRECTORY")
+		}
+	}()
+
+	// Create a synchronization root with a file.
+	root := filepath.Join(directory, "root")
+	if err := os.Mkdir(root, 0700); err != nil {
+		t.Fatal("unable to create synchronization root:", err)
+	}
+	path := filepath.Join(root, "file")
+	if err := ioutil.WriteFile(path, []byte("original"), 0600); err != nil {
+		t.Fatal("unable to create file:", err)
+	}
+
+	// Create an endpoint.
+	endpoint, err := NewEndpoint(
+		logging.RootLogger,
+		root,
+		"session",
+		synchronization.Version_Version1,
+		&synchronization.Configuration{
+			WatchMode: synchronization.WatchMode_WatchModeNoWatch,
+		},
+		true,
+	)
+	if err != nil {
+		t.Fatal("unable to create endpoint:", err)
+	}
+	defer endpoint.Shutdown()
+
+	// Perform an initial scan to populate the digest cache.
+	original, _, _, err, _ := endpoint.Scan(context.Background(), nil, true, false, nil)
+	if err != nil {
+		t.Fatal("unable to perform initial scan:", err)
+	}
+
+	// Modify the file contents in place without changing its size, and then
+	// restore its modification time so that the modification can't be detected
+	// from metadata.
+	metadata, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal("unable to grab file metadata:", err)
+	} else if err = ioutil.WriteFile(path, []byte("modified"), 0600); err != nil {
+		t.Fatal("unable to modify file:", err)
+	} else if err = os.Chtimes(path, metadata.ModTime(), metadata.ModTime()); err != nil {
+		t.Fatal("unable to restore file modification time:", err)
+	}
+
+	// Verify that a normal full scan re-uses the cached digest.
+	snapshot, _, _, err, _ := endpoint.Scan(context.Background(), nil, true, false, nil)
+	if err != nil {
+		t.Fatal("unable to perform cached scan:", err)
+	} else if !snapshot.Equal(original) {
+		t.Fatal("cached scan did not re-use cached digest")
+	}
+
+	// Verify that a rehashing scan computes the new digest.
+	expectedDigest := sha1.Sum([]byte("modified"))
+	snapshot, _, _, err, _ = endpoint.Scan(context.Background(), nil, false, true, nil)
+	if err != nil {
+		t.Fatal("unable to perform rehashing scan:", err)
+	} else if file := snapshot.Contents["file"]; file == nil {
+		t.Fatal("file missing from rehashing scan")
+	} else if string(file.Digest) != string(expectedDigest[:]) {
+		t.Error("rehashing scan did not recompute file digest")
+	}
+}
+
+// TestEndpointScanWarmup tests that an endpoint's warmup scan populates its
+// digest cache and that its first scan then re-uses the cached digests rather
+// than re-hashing files.
+func TestEndpointScanWarmup(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_local_endpoint")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create a synchronization root with files.
+	root := filepath.Join(directory, "root")
+	if err := os.Mkdir(root, 0700); err != nil {
+		t.Fatal("unable to create synchronization root:", err)
+	}
+	names := []string{"first", "second", "third"}
+	for _, name := range names {
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte("original"), 0600); err != nil {
+			t.Fatal("unable to create file:", err)
+		}
+	}
+
+	// Create an endpoint with warmup enabled and defer its shutdown.
+	localEndpoint, err := NewEndpoint(
+		logging.RootLogger,
+		root,
+		"warmup",
+		synchronization.Version_Version1,
+		&synchronization.Configuration{
+			WatchMode:       synchronization.WatchMode_WatchModeNoWatch,
+			ScanConcurrency: 2,
+			ScanWarmup:      true,
+		},
+		true,
+		WithCachePathCallback(func(_ string, _ bool) (string, error) {
+			return filepath.Join(directory, "cache"), nil
+		}),
+		WithStagingRootCallback(func(_ string, _ bool) (string, bool, error) {
+			return filepath.Join(directory, "staging"), false, nil
+		}),
+	)
+	if err != nil {
+		t.Fatal("unable to create endpoint:", err)
+	}
+	defer localEndpoint.Shutdown()
+
+	// Wait for the warmup to complete.
+	e := localEndpoint.(*endpoint)
+	select {
+	case <-e.warmupDone:
+	case <-time.After(10 * time.Second):
+		t.Fatal("warmup did not complete")
+	}
+
+	// Verify that the cache was populated.
+	expectedDigest := sha1.Sum([]byte("original"))
+	e.scanLock.Lock()
+	for _, name := range names {
+		if cached, ok := e.cache.Entries[name]; !ok {
+			t.Error("warmup did not populate cache entry for", name)
+		} else if string(cached.Digest) != string(expectedDigest[:]) {
+			t.Error("warmup cache entry has incorrect digest for", name)
+		}
+	}
+	e.scanLock.Unlock()
+
+	// Modify a file's contents in place without changing its size, and then
+	// restore its modification time so that the modification can't be detected
+	// from metadata.
+	path := filepath.Join(root, "first")
+	metadata, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal("unable to grab file metadata:", err)
+	} else if err = ioutil.WriteFile(path, []byte("modified"), 0600); err != nil {
+		t.Fatal("unable to modify file:", err)
+	} else if err = os.Chtimes(path, metadata.ModTime(), metadata.ModTime()); err != nil {
+		t.Fatal("unable to restore file modification time:", err)
+	}
+
+	// Verify that the first scan re-uses the digests computed by the warmup.
+	snapshot, _, _, err, _ := localEndpoint.Scan(context.Background(), nil, false, false, nil)
+	if err != nil {
+		t.Fatal("unable to perform scan:", err)
+	}
+	for _, name := range names {
+		if file := snapshot.Contents[name]; file == nil {
+			t.Error("file missing from scan:", name)
+		} else if string(file.Digest) != string(expectedDigest[:]) {
+			t.Error("scan re-hashed file after warmup:", name)
+		}
+	}
+}
+
+// testRecordingSyncer is a filesystem.Syncer implementation that records flush
+// operations without performing them.
+type testRecordingSyncer struct {
+	// fileSyncs is the number of file flushes requested.
+	fileSyncs int
+	// directorySyncs is the number of directory flushes requested.
+	directorySyncs int
+}
+
+// SyncFile implements filesystem.Syncer.SyncFile.
+func (s *testRecordingSyncer) SyncFile(_ filesystem.SyncableFile) error {
+	s.fileSyncs++
+	return nil
+}
+
+// SyncDirectory implements filesystem.Syncer.SyncDirectory.
+func (s *testRecordingSyncer) SyncDirectory(_ *filesystem.Directory) error {
+	s.directorySyncs++
+	return nil
+}
+
+// TestEndpointDurabilityMode tests that endpoints flush transitioned content in
+// accordance with their configured durability mode, with the default mode
+// performing full flushing.
+func TestEndpointDurabilityMode(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                   core.DurabilityMode
+		expectedFileSyncs      int
+		expectedDirectorySyncs int
+	}{
+		{core.DurabilityMode_DurabilityModeDefault, 1, 1},
+		{core.DurabilityMode_DurabilityModeFull, 1, 1},
+		{core.DurabilityMode_DurabilityModeMetadata, 0, 1},
+		{core.DurabilityMode_DurabilityModeNone, 0, 0},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		// Create a temporary directory.
+		directory, err := ioutil.TempDir("", "mutagen_local_endpoint")
+		if err != nil {
+			t.Fatal("unable to create temporary directory:", err)
+		}
+
+		// Create a source root with the content to be synchronized and an empty
+		// synchronization root.
+		content := []byte("durable content")
+		sourceRoot := filepath.Join(directory, "source")
+		root := filepath.Join(directory, "root")
+		if err := os.Mkdir(sourceRoot, 0700); err != nil {
+			t.Fatal("unable to create source root:", err)
+		} else if err := ioutil.WriteFile(filepath.Join(sourceRoot, "file"), content, 0600); err != nil {
+			t.Fatal("unable to create source content:", err)
+		} else if err := os.Mkdir(root, 0700); err != nil {
+			t.Fatal("unable to create synchronization root:", err)
+		}
+
+		// Create the endpoint.
+		syncer := &testRecordingSyncer{}
+		configuration := &synchronization.Configuration{
+			WatchMode:      synchronization.WatchMode_WatchModeNoWatch,
+			DurabilityMode: testCase.mode,
+		}
+		endpoint, err := NewEndpoint(
+			logging.RootLogger,
+			root,
+			"durability",
+			synchronization.Version_Version1,
+			configuration,
+			false,
+			WithCachePathCallback(func(_ string, _ bool) (string, error) {
+				return filepath.Join(directory, "cache"), nil
+			}),
+			WithStagingRootCallback(func(_ string, _ bool) (string, bool, error) {
+				return filepath.Join(directory, "staging"), false, nil
+			}),
+			WithSyncer(syncer),
+		)
+		if err != nil {
+			t.Fatal("unable to create endpoint:", err)
+		}
+
+		// Synchronize the file.
+		testEndpointSynchronizeFile(t, endpoint, sourceRoot, "file", content, false)
+
+		// Shut down the endpoint and remove the temporary directory.
+		endpoint.Shutdown()
+		os.RemoveAll(directory)
+
+		// Verify flush operations.
+		if syncer.fileSyncs != testCase.expectedFileSyncs {
+			t.Errorf(
+				"file flush count (%d) does not match expected (%d) in %s mode",
+				syncer.fileSyncs,
+				testCase.expectedFileSyncs,
+				testCase.mode,
+			)
+		}
+		if syncer.directorySyncs != testCase.expectedDirectorySyncs {
+			t.Errorf(
+				"directory flush count (%d) does not match expected (%d) in %s mode",
+				syncer.directorySyncs,
+				testCase.expectedDirectorySyncs,
+				testCase.mode,
+			)
+		}
+	}
+}
+
+func TestEndpointCapabilitiesProbing(t *testing.T) {
+	// Create a temporary directory and defer its removal. We use a root that
+	// doesn't exist yet in order to verify that probing falls back to the
+	// nearest existing parent.
+	directory, err := ioutil.TempDir("", "mutagen_local_endpoint")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+	root := filepath.Join(directory, "root")
+
+	// Create the endpoint and defer its shutdown.
+	configuration := &synchronization.Configuration{
+		WatchMode: synchronization.WatchMode_WatchModeNoWatch,
+		ProbeMode: behavior.ProbeMode_ProbeModeProbe,
+	}
+	localEndpoint, err := NewEndpoint(
+		logging.RootLogger,
+		root,
+		"capabilities",
+		synchronization.Version_Version1,
+		configuration,
+		false,
+		WithCachePathCallback(func(_ string, _ bool) (string, error) {
+			return filepath.Join(directory, "cache"), nil
+		}),
+		WithStagingRootCallback(func(_ string, _ bool) (string, bool, error) {
+			return filepath.Join(directory, "staging"), false, nil
+		}),
+	)
+	if err != nil {
+		t.Fatal("unable to create endpoint:", err)
+	}
+	defer localEndpoint.Shutdown()
+
+	// Verify that capabilities were recorded.
+	capabilities := localEndpoint.(*endpoint).capabilities
+	if capabilities == nil {
+		t.Fatal("filesystem capabilities not recorded")
+	} else if runtime.GOOS == "linux" && !capabilities.CaseSensitive {
+		t.Error("temporary directory not detected as case sensitive")
+	}
+
+	// Verify that no probe files were left behind.
+	if contents, err := ioutil.ReadDir(directory); err != nil {
+		t.Fatal("unable to read directory contents:", err)
+	} else {
+		for _, content := range contents {
+			if strings.HasPrefix(content.Name(), filesystem.TemporaryNamePrefix) {
+				t.Error("probe file not removed:", content.Name())
+			}
+		}
+	}
+}
+
+// TestEndpointAvailableSpaceAndMeasure tests that local endpoints report the
+// space available for staging (even if the staging root doesn't exist yet) and
+// measure the size of files to be supplied.
+func TestEndpointAvailableSpaceAndMeasure(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_local_endpoint")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+	root := filepath.Join(directory, "root")
+
+	// Create content to measure.
+	if err := os.Mkdir(root, 0700); err != nil {
+		t.Fatal("unable to create root:", err)
+	} else if err = ioutil.WriteFile(filepath.Join(root, "first"), make([]byte, 100), 0600); err != nil {
+		t.Fatal("unable to create file:", err)
+	} else if err = ioutil.WriteFile(filepath.Join(root, "second"), make([]byte, 50), 0600); err != nil {
+		t.Fatal("unable to create file:", err)
+	}
+
+	// Create the endpoint and defer its shutdown.
+	configuration := &synchronization.Configuration{
+		WatchMode: synchronization.WatchMode_WatchModeNoWatch,
+	}
+	localEndpoint, err := NewEndpoint(
+		logging.RootLogger,
+		root,
+		"space",
+		synchronization.Version_Version1,
+		configuration,
+		false,
+		WithCachePathCallback(func(_ string, _ bool) (string, error) {
+			return filepath.Join(directory, "cache"), nil
+		}),
+		WithStagingRootCallback(func(_ string, _ bool) (string, bool, error) {
+			return filepath.Join(directory, "staging", "root"), false, nil
+		}),
+	)
+	if err != nil {
+		t.Fatal("unable to create endpoint:", err)
+	}
+	defer localEndpoint.Shutdown()
+
+	// Verify that available space is reported.
+	if available, err := localEndpoint.AvailableSpace(); err != nil {
+		t.Error("unable to query available space:", err)
+	} else if available == 0 {
+		t.Error("no space available for staging")
+	}
+
+	// Verify measurement, including that missing files are ignored.
+	if size, err := localEndpoint.Measure([]string{"first", "second", "missing"}); err != nil {
+		t.Error("unable to measure files:", err)
+	} else if size != 150 {
+		t.Error("measured size incorrect:", size, "!=", 150)
+	}
+}
+
+// TestEndpointConcurrencyConfiguration tests that scan and staging concurrency
+// are configured independently and that concurrent signature computation
+// produces the same results as serial computation.
+func TestEndpointConcurrencyConfiguration(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_local_endpoint")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create a synchronization root with existing content.
+	root := filepath.Join(directory, "root")
+	if err := os.Mkdir(root, 0700); err != nil {
+		t.Fatal("unable to create synchronization root:", err)
+	}
+	paths := []string{"a", "b", "c", "d", "e", "missing"}
+	for _, path := range paths[:len(paths)-1] {
+		content := []byte(strings.Repeat(path, 4096))
+		if err := ioutil.WriteFile(filepath.Join(root, path), content, 0600); err != nil {
+			t.Fatal("unable to create content:", err)
+		}
+	}
+
+	// Set up test cases.
+	testCases := []struct {
+		scanConcurrency            uint32
+		stagingConcurrency         uint32
+		expectedDigestHashers      int
+		expectedStagingConcurrency int
+	}{
+		{0, 0, 0, 1},
+		{4, 0, 4, 1},
+		{0, 3, 0, 3},
+		{8, 1, 8, 1},
+		{1, 8, 0, 8},
+	}
+
+	// Process test cases.
+	var expectedSignatures []*rsync.Signature
+	for _, testCase := range testCases {
+		// Create the endpoint.
+		configuration := &synchronization.Configuration{
+			WatchMode:          synchronization.WatchMode_WatchModeNoWatch,
+			ScanConcurrency:    testCase.scanConcurrency,
+			StagingConcurrency: testCase.stagingConcurrency,
+		}
+		e, err := NewEndpoint(
+			logging.RootLogger,
+			root,
+			"concurrency",
+			synchronization.Version_Version1,
+			configuration,
+			false,
+			WithCachePathCallback(func(_ string, _ bool) (string, error) {
+				return filepath.Join(directory, "cache"), nil
+			}),
+			WithStagingRootCallback(func(_ string, _ bool) (string, bool, error) {
+				return filepath.Join(directory, "staging"), false, nil
+			}),
+		)
+		if err != nil {
+			t.Fatal("unable to create endpoint:", err)
+		}
+		local := e.(*endpoint)
+
+		// Verify the concurrency configuration.
+		if len(local.digestHashers) != testCase.expectedDigestHashers {
+			t.Errorf("digest hasher count incorrect for scan concurrency %d: %d != %d",
+				testCase.scanConcurrency, len(local.digestHashers), testCase.expectedDigestHashers,
+			)
+		}
+		if local.stagingConcurrency != testCase.expectedStagingConcurrency {
+			t.Errorf("staging concurrency incorrect for staging concurrency %d: %d != %d",
+				testCase.stagingConcurrency, local.stagingConcurrency, testCase.expectedStagingConcurrency,
+			)
+		}
+
+		// Verify that scanning succeeds.
+		if _, _, _, err, _ := e.Scan(context.Background(), nil, true, false, nil); err != nil {
+			t.Error("unable to perform scan:", err)
+		}
+
+		// Compute signatures and verify that they match those computed in the
+		// default (serial) configuration.
+		opener := filesystem.NewOpener(root)
+		signatures := local.computeSignatures(paths, opener)
+		opener.Close()
+		if expectedSignatures == nil {
+			expectedSignatures = signatures
+		} else {
+			for p, signature := range signatures {
+				if !proto.Equal(signature, expectedSignatures[p]) {
+					t.Errorf("signature for %s incorrect with staging concurrency %d",
+						paths[p], testCase.stagingConcurrency,
+					)
+				}
+			}
+		}
+		if len(signatures[len(paths)-1].Hashes) != 0 {
+			t.Error("non-empty signature computed for missing file")
+		}
+
+		// Shut down the endpoint.
+		e.Shutdown()
+	}
+}
+
+// TestEndpointComputeSignaturesBufferBudget tests that concurrent signature
+// computation completes correctly when its staging buffer budget only allows a
+// single block buffer to be held at a time, and that all buffers are returned
+// to the pool.
+func TestEndpointComputeSignaturesBufferBudget(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_local_endpoint")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create a synchronization root with existing content.
+	root := filepath.Join(directory, "root")
+	if err := os.Mkdir(root, 0700); err != nil {
+		t.Fatal("unable to create synchronization root:", err)
+	}
+	paths := []string{"a", "b", "c", "d", "e", "f", "g", "h", "missing"}
+	for _, path := range paths[:len(paths)-1] {
+		content := []byte(strings.Repeat(path, 65536))
+		if err := ioutil.WriteFile(filepath.Join(root, path), content, 0600); err != nil {
+			t.Fatal("unable to create content:", err)
+		}
+	}
+
+	// Create the endpoint and defer its shutdown.
+	configuration := &synchronization.Configuration{
+		WatchMode:          synchronization.WatchMode_WatchModeNoWatch,
+		StagingConcurrency: 8,
+	}
+	e, err := NewEndpoint(
+		logging.RootLogger,
+		root,
+		"buffers",
+		synchronization.Version_Version1,
+		configuration,
+		false,
+		WithCachePathCallback(func(_ string, _ bool) (string, error) {
+			return filepath.Join(directory, "cache"), nil
+		}),
+		WithStagingRootCallback(func(_ string, _ bool) (string, bool, error) {
+			return filepath.Join(directory, "staging"), false, nil
+		}),
+	)
+	if err != nil {
+		t.Fatal("unable to create endpoint:", err)
+	}
+	defer e.Shutdown()
+	local := e.(*endpoint)
+
+	// Compute signatures using the shared pool.
+	opener := filesystem.NewOpener(root)
+	defer opener.Close()
+	expectedSignatures := local.computeSignatures(paths, opener)
+
+	// Compute signatures using a constrained pool and verify that they match.
+	blockSize := rsync.OptimalBlockSizeForBaseLength(65536)
+	pool := newBufferPool(blockSize + blockSize/2)
+	local.stagingBuffers = pool
+	signatures := local.computeSignatures(paths, opener)
+	for p, signature := range signatures {
+		if !proto.Equal(signature, expectedSignatures[p]) {
+			t.Error("signature incorrect with constrained buffer budget:", paths[p])
+		}
+	}
+
+	// Verify that all buffers were returned to the pool.
+	if pool.outstanding != 0 {
+		t.Error("outstanding buffer size non-zero after signature computation:", pool.outstanding)
+	} else if pool.retainedSize > pool.budget {
+		t.Error("retained buffer size exceeds budget:", pool.retainedSize)
+	}
+}
+
+// TestEndpointLineEndingTranslation tests that files subject to line ending
+// translation are written in each endpoint's line ending style when
+// synchronized in either direction and that the translated files aren't seen
+// as changed by subsequent scans.
+func TestEndpointLineEndingTranslation(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_local_endpoint")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create an alpha root with CRLF content (some of which isn't subject to
+	// translation) and an empty beta root.
+	alphaRoot := filepath.Join(directory, "alpha")
+	betaRoot := filepath.Join(directory, "beta")
+	if err := os.Mkdir(alphaRoot, 0700); err != nil {
+		t.Fatal("unable to create alpha root:", err)
+	} else if err := os.Mkdir(betaRoot, 0700); err != nil {
+		t.Fatal("unable to create beta root:", err)
+	}
+	initial := map[string]string{
+		"text.txt":  "one\r\ntwo\r\n",
+		"other.dat": "one\r\ntwo\r\n",
+	}
+	for name, content := range initial {
+		if err := ioutil.WriteFile(filepath.Join(alphaRoot, name), []byte(content), 0600); err != nil {
+			t.Fatal("unable to create alpha content:", err)
+		}
+	}
+
+	// Create the endpoints, with alpha using CRLF line endings and beta using
+	// LF line endings.
+	createEndpoint := func(name, root string, style core.LineEndingStyle) synchronization.Endpoint {
+		configuration := &synchronization.Configuration{
+			WatchMode:          synchronization.WatchMode_WatchModeNoWatch,
+			LineEndingPatterns: []string{"*.txt"},
+			LineEndingStyle:    style,
+		}
+		endpoint, err := NewEndpoint(
+			logging.RootLogger,
+			root,
+			"line_endings",
+			synchronization.Version_Version1,
+			configuration,
+			name == "alpha",
+			WithCachePathCallback(func(_ string, _ bool) (string, error) {
+				return filepath.Join(directory, name+"_cache"), nil
+			}),
+			WithStagingRootCallback(func(_ string, _ bool) (string, bool, error) {
+				return filepath.Join(directory, name+"_staging"), false, nil
+			}),
+		)
+		if err != nil {
+			t.Fatal("unable to create endpoint:", err)
+		}
+		return endpoint
+	}
+	alpha := createEndpoint("alpha", alphaRoot, core.LineEndingStyle_LineEndingStyleCRLF)
+	defer alpha.Shutdown()
+	beta := createEndpoint("beta", betaRoot, core.LineEndingStyle_LineEndingStyleLF)
+	defer beta.Shutdown()
+
+	// scan performs a full scan of an endpoint.
+	scan := func(endpoint synchronization.Endpoint) *core.Entry {
+		snapshot, _, _, err, _ := endpoint.Scan(context.Background(), nil, true, false, nil)
+		if err != nil {
+			t.Fatal("unable to perform scan:", err)
+		}
+		return snapshot
+	}
+
+	// propagate propagates the differences between a source snapshot and a
+	// target snapshot to the target endpoint.
+	propagate := func(source, target *core.Entry, sourceRoot string, endpoint synchronization.Endpoint) {
+		changes := core.Diff(target, source)
+		var paths []string
+		var digests [][]byte
+		for _, change := range changes {
+			if change.New != nil && change.New.Kind == core.EntryKind_File {
+				paths = append(paths, change.Path)
+				digests = append(digests, change.New.Digest)
+			}
+		}
+		if len(paths) > 0 {
+			paths, signatures, receiver, err := endpoint.Stage(paths, digests)
+			if err != nil {
+				t.Fatal("unable to perform staging:", err)
+			}
+			if receiver != nil {
+				if err := rsync.Transmit(sourceRoot, paths, signatures, receiver, 0, nil, nil); err != nil {
+					t.Fatal("unable to transmit content:", err)
+				}
+			}
+		}
+		if _, problems, missing, err := endpoint.Transition(context.Background(), changes); err != nil {
+			t.Fatal("unable to perform transition:", err)
+		} else if len(problems) > 0 {
+			t.Fatal("transition encountered problems:", problems[0].Error)
+		} else if missing {
+			t.Fatal("transition reported missing staged files")
+		}
+	}
+
+	// verify verifies the content of a file.
+	verify := func(root, name, expected string) {
+		if content, err := ioutil.ReadFile(filepath.Join(root, name)); err != nil {
+			t.Fatal("unable to read synchronized content:", err)
+		} else if string(content) != expected {
+			t.Errorf("content of %s (%q) does not match expected (%q)", name, content, expected)
+		}
+	}
+
+	// Propagate alpha's content to beta and verify that the translated file
+	// was converted to LF line endings while the other file was left as-is.
+	alphaSnapshot := scan(alpha)
+	propagate(alphaSnapshot, scan(beta), alphaRoot, beta)
+	verify(betaRoot, "text.txt", "one\ntwo\n")
+	verify(betaRoot, "other.dat", "one\r\ntwo\r\n")
+
+	// Verify that beta's content is seen as identical to alpha's, even though
+	// it's stored differently, and that alpha's content is unchanged.
+	betaSnapshot := scan(beta)
+	if changes := core.Diff(alphaSnapshot, betaSnapshot); len(changes) > 0 {
+		t.Error("translated content seen as changed:", changes[0].Path)
+	}
+	if changes := core.Diff(alphaSnapshot, scan(alpha)); len(changes) > 0 {
+		t.Error("alpha content changed by synchronization:", changes[0].Path)
+	}
+
+	// Modify the translated file on beta, propagate the change to alpha, and
+	// verify that it was converted to CRLF line endings.
+	if err := ioutil.WriteFile(filepath.Join(betaRoot, "text.txt"), []byte("one\ntwo\nthree\n"), 0600); err != nil {
+		t.Fatal("unable to modify beta content:", err)
+	}
+	betaSnapshot = scan(beta)
+	propagate(betaSnapshot, alphaSnapshot, betaRoot, alpha)
+	verify(alphaRoot, "text.txt", "one\r\ntwo\r\nthree\r\n")
+
+	// Verify that both endpoints now agree and that repeated scans don't see
+	// any further changes.
+	alphaSnapshot = scan(alpha)
+	if changes := core.Diff(alphaSnapshot, betaSnapshot); len(changes) > 0 {
+		t.Error("translated content seen as changed:", changes[0].Path)
+	}
+	if changes := core.Diff(betaSnapshot, scan(beta)); len(changes) > 0 {
+		t.Error("beta content changed by rescan:", changes[0].Path)
+	}
+}
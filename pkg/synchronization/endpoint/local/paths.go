@@ -43,6 +43,43 @@ func pathForCache(session string, alpha bool) (string, error) {
 	return filepath.Join(cachesDirectoryPath, cacheName), nil
 }
 
+// pathForUndoArea computes the path to the undo area for the given session
+// identifier and endpoint role. It ensures that the undo subdirectory of the
+// Mutagen data directory exists, but it does not create the undo area itself.
+func pathForUndoArea(session string, alpha bool) (string, error) {
+	// Compute/create the undo directory.
+	undoDirectoryPath, err := filesystem.Mutagen(true, filesystem.MutagenSynchronizationUndoDirectoryName)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to compute/create undo directory")
+	}
+
+	// Compute the endpoint name.
+	endpointName := alphaName
+	if !alpha {
+		endpointName = betaName
+	}
+
+	// Compute the undo area name.
+	undoAreaName := fmt.Sprintf("%s_%s", session, endpointName)
+
+	// Success.
+	return filepath.Join(undoDirectoryPath, undoAreaName), nil
+}
+
+// contentStoreOwnerForEndpoint computes the owner name to use for shared
+// content store references held by the endpoint with the given session
+// identifier and endpoint role.
+func contentStoreOwnerForEndpoint(session string, alpha bool) string {
+	// Compute the endpoint name.
+	endpointName := alphaName
+	if !alpha {
+		endpointName = betaName
+	}
+
+	// Compute the owner name.
+	return fmt.Sprintf("%s_%s", session, endpointName)
+}
+
 // pathForMutagenStagingRoot computes the path to the staging root in the
 // Mutagen data directory for the given session identifier and endpoint. It
 // ensures that staging subdirectory of the Mutagen data directory exists, but
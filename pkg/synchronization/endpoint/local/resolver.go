@@ -0,0 +1,186 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+const (
+	// conflictResolverOutputResolved is the output that a conflict resolver
+	// prints to indicate that it has written resolved content to the output
+	// path.
+	conflictResolverOutputResolved = "resolved"
+	// conflictResolverOutputConflicted is the output that a conflict resolver
+	// prints to indicate that the conflict should be left in place.
+	conflictResolverOutputConflicted = "conflicted"
+	// conflictResolverMaximumOutputSize is the maximum number of bytes of
+	// standard output that we'll accept from a conflict resolver. The only
+	// valid outputs are short status words, so anything longer is malformed.
+	conflictResolverMaximumOutputSize = 64
+)
+
+// conflictResolver invokes an external command to resolve conflicts between
+// two versions of a file.
+type conflictResolver struct {
+	// command is the resolver command name and its arguments.
+	command []string
+	// timeout is the maximum duration of a single resolver invocation.
+	timeout time.Duration
+}
+
+// writeResolverInput writes the contents of the specified reader to a new file
+// at the specified path.
+func writeResolverInput(path string, contents io.Reader) error {
+	// Create the file.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return errors.Wrap(err, "unable to create file")
+	}
+
+	// Copy the contents and close the file.
+	if _, err = io.Copy(file, contents); err != nil {
+		file.Close()
+		return errors.Wrap(err, "unable to copy contents")
+	} else if err = file.Close(); err != nil {
+		return errors.Wrap(err, "unable to close file")
+	}
+
+	// Success.
+	return nil
+}
+
+// resolve invokes the resolver for the file at the specified path (relative to
+// the synchronization root), providing it with copies of alpha's and beta's
+// versions of the file (read from the specified readers). If the resolver
+// resolves the conflict, then an open handle to the resolved content is
+// returned (and the caller is responsible for closing it). If the resolver
+// indicates that the conflict should be left in place, then a nil handle and
+// nil error are returned. Any resolver failure (including a non-zero exit code,
+// malformed output, or a timeout) results in an error, in which case the
+// conflict should also be left in place.
+func (r *conflictResolver) resolve(ctx context.Context, path string, alpha, beta io.Reader) (*os.File, error) {
+	// Create a temporary directory to contain the inputs and outputs of the
+	// resolver and defer its removal. We provide the resolver with copies of
+	// the inputs so that it can't modify the originals.
+	directory, err := ioutil.TempDir("", "mutagen-conflict-resolution")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create temporary directory")
+	}
+	defer os.RemoveAll(directory)
+
+	// Copy the inputs and compute the output path.
+	alphaCopy := filepath.Join(directory, "alpha")
+	betaCopy := filepath.Join(directory, "beta")
+	output := filepath.Join(directory, "output")
+	status := filepath.Join(directory, "status")
+	if err := writeResolverInput(alphaCopy, alpha); err != nil {
+		return nil, errors.Wrap(err, "unable to copy alpha content")
+	} else if err := writeResolverInput(betaCopy, beta); err != nil {
+		return nil, errors.Wrap(err, "unable to copy beta content")
+	}
+
+	// Create a subcontext that enforces the timeout and defer its
+	// cancellation.
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// Create the resolver command.
+	command := exec.CommandContext(ctx, r.command[0], r.command[1:]...)
+	command.Env = append(os.Environ(),
+		"MUTAGEN_CONFLICT_PATH="+path,
+		"MUTAGEN_CONFLICT_ALPHA="+alphaCopy,
+		"MUTAGEN_CONFLICT_BETA="+betaCopy,
+		"MUTAGEN_CONFLICT_OUTPUT="+output,
+	)
+	command.Dir = directory
+
+	// Redirect the command's standard output to a file. We use a file (rather
+	// than an in-memory buffer) so that termination of the command on timeout
+	// isn't blocked by any subprocesses that inherit its standard output.
+	stdout, err := os.OpenFile(status, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create status file")
+	}
+	defer stdout.Close()
+	command.Stdout = stdout
+
+	// Run the command and check for timeouts and errors.
+	if err := command.Run(); ctx.Err() == context.DeadlineExceeded {
+		return nil, errors.New("conflict resolver timed out")
+	} else if err != nil {
+		return nil, errors.Wrap(err, "conflict resolver failed")
+	}
+
+	// Read the output, watching for excessively large output.
+	if _, err := stdout.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "unable to rewind status file")
+	}
+	statusBytes, err := ioutil.ReadAll(io.LimitReader(stdout, conflictResolverMaximumOutputSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read conflict resolver output")
+	} else if len(statusBytes) > conflictResolverMaximumOutputSize {
+		return nil, errors.New("conflict resolver output malformed")
+	}
+
+	// Interpret the output.
+	switch string(bytes.TrimSpace(statusBytes)) {
+	case conflictResolverOutputResolved:
+		// Verify that the resolved content is a regular file.
+		if metadata, err := os.Lstat(output); err != nil {
+			return nil, errors.Wrap(err, "unable to query resolved content")
+		} else if !metadata.Mode().IsRegular() {
+			return nil, errors.New("resolved content is not a regular file")
+		}
+
+		// Open the resolved content. On POSIX systems, the file will remain
+		// readable after the temporary directory is removed. On Windows, the
+		// removal will fail and leave the directory in the system's temporary
+		// directory, which isn't ideal but is harmless.
+		resolved, err := os.Open(output)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to open resolved content")
+		}
+
+		// Success.
+		return resolved, nil
+	case conflictResolverOutputConflicted:
+		return nil, nil
+	default:
+		return nil, errors.New("conflict resolver output malformed")
+	}
+}
+
+// unresolvedConflict records the result for a conflict resolution transition
+// that was left in place.
+type unresolvedConflict struct {
+	// index is the index of the transition in the original transition list.
+	index int
+	// result is the result for the transition.
+	result *core.Entry
+}
+
+// spliceUnresolvedConflicts merges the results for conflicts left in place
+// (which must be sorted by index) into the results for the remaining
+// transitions, yielding results that correspond to the original transition
+// list.
+func spliceUnresolvedConflicts(results []*core.Entry, unresolved []unresolvedConflict) []*core.Entry {
+	spliced := make([]*core.Entry, 0, len(results)+len(unresolved))
+	for _, u := range unresolved {
+		for len(spliced) < u.index {
+			spliced = append(spliced, results[0])
+			results = results[1:]
+		}
+		spliced = append(spliced, u.result)
+	}
+	return append(spliced, results...)
+}
@@ -0,0 +1,191 @@
+package local
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+const (
+	// testConflictResolverMergeScript is a stub conflict resolver script that
+	// resolves conflicts by concatenating alpha's and beta's content.
+	testConflictResolverMergeScript = `#!/bin/sh
+cat "$MUTAGEN_CONFLICT_ALPHA" "$MUTAGEN_CONFLICT_BETA" > "$MUTAGEN_CONFLICT_OUTPUT"
+echo resolved
+`
+	// testConflictResolverConflictedScript is a stub conflict resolver script
+	// that leaves conflicts in place.
+	testConflictResolverConflictedScript = `#!/bin/sh
+echo conflicted
+`
+	// testConflictResolverFailureScript is a stub conflict resolver script that
+	// exits with a non-zero exit code after writing output.
+	testConflictResolverFailureScript = `#!/bin/sh
+echo merged > "$MUTAGEN_CONFLICT_OUTPUT"
+echo resolved
+exit 1
+`
+	// testConflictResolverMalformedScript is a stub conflict resolver script
+	// that prints unrecognized output.
+	testConflictResolverMalformedScript = `#!/bin/sh
+echo "resolved, probably"
+`
+	// testConflictResolverMissingOutputScript is a stub conflict resolver
+	// script that claims resolution without writing any output.
+	testConflictResolverMissingOutputScript = `#!/bin/sh
+echo resolved
+`
+	// testConflictResolverSlowScript is a stub conflict resolver script that
+	// doesn't complete in a timely fashion.
+	testConflictResolverSlowScript = `#!/bin/sh
+exec sleep 10
+`
+)
+
+// testConflictResolver creates a conflict resolver that invokes the specified
+// script, which is written to the specified directory.
+func testConflictResolver(t *testing.T, directory, script string, timeout time.Duration) *conflictResolver {
+	// Write the script.
+	path := filepath.Join(directory, "resolver.sh")
+	if err := ioutil.WriteFile(path, []byte(script), 0700); err != nil {
+		t.Fatal("unable to write resolver script:", err)
+	}
+
+	// Create the resolver.
+	return &conflictResolver{
+		command: []string{"/bin/sh", path},
+		timeout: timeout,
+	}
+}
+
+// TestConflictResolver tests conflict resolver invocation using a variety of
+// stub resolvers.
+func TestConflictResolver(t *testing.T) {
+	// Stub resolvers are POSIX shell scripts.
+	if runtime.GOOS == "windows" {
+		t.Skip()
+	}
+
+	// Set up test cases.
+	testCases := []struct {
+		description     string
+		script          string
+		timeout         time.Duration
+		expectFailure   bool
+		expectConflict  bool
+		expectedContent string
+	}{
+		{"merge", testConflictResolverMergeScript, 10 * time.Second, false, false, "alpha\nbeta\n"},
+		{"conflicted", testConflictResolverConflictedScript, 10 * time.Second, false, true, ""},
+		{"non-zero exit", testConflictResolverFailureScript, 10 * time.Second, true, false, ""},
+		{"malformed output", testConflictResolverMalformedScript, 10 * time.Second, true, false, ""},
+		{"missing output", testConflictResolverMissingOutputScript, 10 * time.Second, true, false, ""},
+		{"timeout", testConflictResolverSlowScript, 100 * time.Millisecond, true, false, ""},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		// Create a temporary directory for the resolver script.
+		directory, err := ioutil.TempDir("", "mutagen_conflict_resolver")
+		if err != nil {
+			t.Fatal("unable to create temporary directory:", err)
+		}
+
+		// Invoke the resolver.
+		resolver := testConflictResolver(t, directory, testCase.script, testCase.timeout)
+		resolved, err := resolver.resolve(
+			context.Background(),
+			"file",
+			strings.NewReader("alpha\n"),
+			strings.NewReader("beta\n"),
+		)
+
+		// Check the outcome.
+		if testCase.expectFailure {
+			if err == nil {
+				t.Errorf("%s: resolution unexpectedly succeeded", testCase.description)
+			}
+		} else if err != nil {
+			t.Errorf("%s: resolution failed: %v", testCase.description, err)
+		} else if testCase.expectConflict {
+			if resolved != nil {
+				t.Errorf("%s: conflict unexpectedly resolved", testCase.description)
+			}
+		} else if resolved == nil {
+			t.Errorf("%s: conflict unexpectedly left in place", testCase.description)
+		} else if content, err := ioutil.ReadAll(resolved); err != nil {
+			t.Errorf("%s: unable to read resolved content: %v", testCase.description, err)
+		} else if string(content) != testCase.expectedContent {
+			t.Errorf("%s: resolved content does not match expected: %q != %q",
+				testCase.description, content, testCase.expectedContent,
+			)
+		}
+		if resolved != nil {
+			resolved.Close()
+		}
+
+		// Remove the temporary directory.
+		os.RemoveAll(directory)
+	}
+}
+
+// TestSpliceUnresolvedConflicts tests that spliceUnresolvedConflicts restores
+// results to their original transition indices.
+func TestSpliceUnresolvedConflicts(t *testing.T) {
+	// Create distinct entries to track results.
+	entries := make([]*core.Entry, 5)
+	for e := range entries {
+		entries[e] = &core.Entry{Kind: core.EntryKind_File, Digest: []byte{byte(e)}}
+	}
+
+	// Set up test cases.
+	testCases := []struct {
+		results    []*core.Entry
+		unresolved []unresolvedConflict
+		expected   []*core.Entry
+	}{
+		{
+			[]*core.Entry{entries[0], entries[1]},
+			nil,
+			[]*core.Entry{entries[0], entries[1]},
+		},
+		{
+			nil,
+			[]unresolvedConflict{{0, entries[0]}, {1, entries[1]}},
+			[]*core.Entry{entries[0], entries[1]},
+		},
+		{
+			[]*core.Entry{entries[1], entries[3]},
+			[]unresolvedConflict{{0, entries[0]}, {2, entries[2]}, {4, entries[4]}},
+			entries,
+		},
+		{
+			[]*core.Entry{entries[0], entries[4]},
+			[]unresolvedConflict{{1, entries[1]}, {2, entries[2]}, {3, entries[3]}},
+			entries,
+		},
+	}
+
+	// Process test cases.
+	for c, testCase := range testCases {
+		spliced := spliceUnresolvedConflicts(testCase.results, testCase.unresolved)
+		if len(spliced) != len(testCase.expected) {
+			t.Errorf("spliced result count incorrect for test case %d: %d != %d",
+				c, len(spliced), len(testCase.expected),
+			)
+			continue
+		}
+		for r, result := range spliced {
+			if result != testCase.expected[r] {
+				t.Errorf("spliced result %d incorrect for test case %d", r, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/filesystem/behavior"
+)
+
+// scanStaggerer staggers the start times of polling scans performed by
+// endpoints whose synchronization roots reside on the same filesystem. Each
+// filesystem's polling interval is divided into equal slots, with endpoints
+// assigned to slots in the order of their identities, and each endpoint's
+// scans are aligned (relative to the Unix epoch) to the start of its slot. This
+// makes the staggering deterministic given the set of endpoints on the
+// filesystem and prevents scans from drifting back into alignment over time.
+// It is safe for concurrent usage.
+type scanStaggerer struct {
+	// lock serializes access to filesystems.
+	lock sync.Mutex
+	// filesystems maps filesystem identifiers to the set of identities of the
+	// endpoints registered for that filesystem.
+	filesystems map[uint64]map[string]bool
+}
+
+// newScanStaggerer creates a new scan staggerer.
+func newScanStaggerer() *scanStaggerer {
+	return &scanStaggerer{
+		filesystems: make(map[uint64]map[string]bool),
+	}
+}
+
+// sharedScanStaggerer is the process-wide scan staggerer.
+var sharedScanStaggerer = newScanStaggerer()
+
+// register registers an endpoint with the specified identity as scanning the
+// specified filesystem.
+func (s *scanStaggerer) register(filesystem uint64, identity string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	identities, ok := s.filesystems[filesystem]
+	if !ok {
+		identities = make(map[string]bool)
+		s.filesystems[filesystem] = identities
+	}
+	identities[identity] = true
+}
+
+// unregister removes the registration of an endpoint with the specified
+// identity for the specified filesystem.
+func (s *scanStaggerer) unregister(filesystem uint64, identity string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	identities := s.filesystems[filesystem]
+	delete(identities, identity)
+	if len(identities) == 0 {
+		delete(s.filesystems, filesystem)
+	}
+}
+
+// offset computes the offset of the slot within the polling interval assigned
+// to the endpoint with the specified identity on the specified filesystem. If
+// the endpoint isn't registered, then it's assigned the first slot.
+func (s *scanStaggerer) offset(filesystem uint64, identity string, interval time.Duration) time.Duration {
+	// Compute the sorted list of identities.
+	s.lock.Lock()
+	identities := make([]string, 0, len(s.filesystems[filesystem]))
+	for i := range s.filesystems[filesystem] {
+		identities = append(identities, i)
+	}
+	s.lock.Unlock()
+	sort.Strings(identities)
+
+	// Determine the slot index.
+	index := sort.SearchStrings(identities, identity)
+	if index == len(identities) || identities[index] != identity {
+		return 0
+	}
+
+	// Compute the slot offset.
+	return time.Duration(int64(interval) * int64(index) / int64(len(identities)))
+}
+
+// delay computes the amount of time that the endpoint with the specified
+// identity on the specified filesystem should wait before its next scan. The
+// result is always positive and no greater than the polling interval.
+func (s *scanStaggerer) delay(filesystem uint64, identity string, interval time.Duration, now time.Time) time.Duration {
+	// Handle degenerate intervals.
+	if interval <= 0 {
+		return 0
+	}
+
+	// Compute the time until the next start of our slot.
+	offset := s.offset(filesystem, identity, interval)
+	phase := time.Duration(now.UnixNano() % int64(interval))
+	delay := offset - phase
+	if delay <= 0 {
+		delay += interval
+	}
+	return delay
+}
+
+// probeScanStaggering determines whether or not polling scans of the specified
+// synchronization root should be staggered with those of other endpoints on the
+// same filesystem. Staggering is only performed for network filesystems, where
+// simultaneous scans are likely to overwhelm the server. If staggering should
+// be performed, then the identifier of the filesystem is returned. If the root
+// doesn't exist yet, then the nearest existing parent directory is probed
+// instead, since that's where the root will be created.
+func probeScanStaggering(root string) (uint64, bool) {
+	// Find the nearest existing directory.
+	path := root
+	for {
+		if metadata, err := os.Stat(path); err == nil && metadata.IsDir() {
+			break
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+
+	// Check whether or not this is a network filesystem.
+	if !behavior.IsNetworkFilesystemByPath(path) {
+		return 0, false
+	}
+
+	// Identify the filesystem by its device identifier.
+	directory, metadata, err := filesystem.OpenDirectory(path, true)
+	if err != nil {
+		return 0, false
+	}
+	directory.Close()
+	return metadata.DeviceID, true
+}
@@ -0,0 +1,147 @@
+package local
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+// testScanStaggerFilesystem is the filesystem identifier used in scan
+// staggering tests.
+const testScanStaggerFilesystem = 42
+
+// testScanStaggerInterval is the polling interval used in scan staggering
+// tests.
+const testScanStaggerInterval = 10 * time.Second
+
+// testScanStaggerIdentities returns endpoint identities for the specified
+// number of sessions sharing a filesystem.
+func testScanStaggerIdentities(sessions int) []string {
+	var result []string
+	for i := 0; i < sessions; i++ {
+		result = append(result, contentStoreOwnerForEndpoint(fmt.Sprintf("sync_%d", i), i%2 == 0))
+	}
+	return result
+}
+
+// scanStarts computes the next scan start time for each of the specified
+// identities, relative to the specified time.
+func scanStarts(staggerer *scanStaggerer, identities []string, now time.Time) map[string]time.Time {
+	result := make(map[string]time.Time, len(identities))
+	for _, identity := range identities {
+		delay := staggerer.delay(testScanStaggerFilesystem, identity, testScanStaggerInterval, now)
+		result[identity] = now.Add(delay)
+	}
+	return result
+}
+
+// TestScanStaggerSpreadsScans tests that the scan start times for several
+// sessions on the same filesystem are evenly spread out within the polling
+// interval.
+func TestScanStaggerSpreadsScans(t *testing.T) {
+	// Register several sessions on the same filesystem.
+	const sessions = 5
+	staggerer := newScanStaggerer()
+	identities := testScanStaggerIdentities(sessions)
+	for _, identity := range identities {
+		staggerer.register(testScanStaggerFilesystem, identity)
+	}
+
+	// Compute scan start times and verify that they all fall within a single
+	// polling interval.
+	now := time.Unix(1600000000, 123456789)
+	starts := scanStarts(staggerer, identities, now)
+	var sorted []time.Time
+	for identity, start := range starts {
+		if !start.After(now) || start.Sub(now) > testScanStaggerInterval {
+			t.Error("scan start outside of polling interval for", identity, ":", start.Sub(now))
+		}
+		sorted = append(sorted, start)
+	}
+
+	// Verify that consecutive scan starts are evenly separated.
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	expected := testScanStaggerInterval / sessions
+	for i := 1; i < len(sorted); i++ {
+		if separation := sorted[i].Sub(sorted[i-1]); separation != expected {
+			t.Error("scan start separation incorrect:", separation, "!=", expected)
+		}
+	}
+
+	// Verify that subsequent scans occur exactly one interval later, i.e. that
+	// scans don't drift.
+	for identity, start := range starts {
+		next := start.Add(staggerer.delay(testScanStaggerFilesystem, identity, testScanStaggerInterval, start))
+		if next.Sub(start) != testScanStaggerInterval {
+			t.Error("subsequent scan drifted for", identity, ":", next.Sub(start))
+		}
+	}
+}
+
+// TestScanStaggerDeterministic tests that scan start times depend only on the
+// set of session identities and not the order in which they're registered.
+func TestScanStaggerDeterministic(t *testing.T) {
+	// Register sessions in forward and reverse order with separate staggerers.
+	identities := testScanStaggerIdentities(4)
+	forward, reverse := newScanStaggerer(), newScanStaggerer()
+	for i := range identities {
+		forward.register(testScanStaggerFilesystem, identities[i])
+		reverse.register(testScanStaggerFilesystem, identities[len(identities)-1-i])
+	}
+
+	// Verify that the scan start times match.
+	now := time.Unix(1600000000, 0)
+	forwardStarts := scanStarts(forward, identities, now)
+	reverseStarts := scanStarts(reverse, identities, now)
+	for _, identity := range identities {
+		if !forwardStarts[identity].Equal(reverseStarts[identity]) {
+			t.Error("scan start depends on registration order for", identity)
+		}
+	}
+}
+
+// TestScanStaggerFilesystemIsolation tests that sessions on different
+// filesystems aren't staggered relative to one another.
+func TestScanStaggerFilesystemIsolation(t *testing.T) {
+	staggerer := newScanStaggerer()
+	staggerer.register(testScanStaggerFilesystem, "a")
+	staggerer.register(testScanStaggerFilesystem+1, "b")
+	for _, filesystem := range []uint64{testScanStaggerFilesystem, testScanStaggerFilesystem + 1} {
+		for _, identity := range []string{"a", "b"} {
+			if offset := staggerer.offset(filesystem, identity, testScanStaggerInterval); offset != 0 {
+				t.Error("non-zero offset for isolated session:", offset)
+			}
+		}
+	}
+}
+
+// TestScanStaggerUnregister tests that unregistering a session redistributes
+// the remaining sessions across the polling interval.
+func TestScanStaggerUnregister(t *testing.T) {
+	// Register sessions.
+	staggerer := newScanStaggerer()
+	for _, identity := range []string{"a", "b", "c", "d"} {
+		staggerer.register(testScanStaggerFilesystem, identity)
+	}
+	if offset := staggerer.offset(testScanStaggerFilesystem, "c", testScanStaggerInterval); offset != testScanStaggerInterval/2 {
+		t.Error("offset incorrect before unregistration:", offset)
+	}
+
+	// Unregister sessions and verify redistribution.
+	staggerer.unregister(testScanStaggerFilesystem, "b")
+	staggerer.unregister(testScanStaggerFilesystem, "d")
+	if offset := staggerer.offset(testScanStaggerFilesystem, "c", testScanStaggerInterval); offset != testScanStaggerInterval/2 {
+		t.Error("offset incorrect after unregistration:", offset)
+	}
+	staggerer.unregister(testScanStaggerFilesystem, "a")
+	if offset := staggerer.offset(testScanStaggerFilesystem, "c", testScanStaggerInterval); offset != 0 {
+		t.Error("offset incorrect for sole session:", offset)
+	}
+
+	// Verify that empty filesystem registrations are removed.
+	staggerer.unregister(testScanStaggerFilesystem, "c")
+	if len(staggerer.filesystems) != 0 {
+		t.Error("empty filesystem registration not removed")
+	}
+}
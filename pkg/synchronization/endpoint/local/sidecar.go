@@ -0,0 +1,108 @@
+package local
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// lookupEntry locates the entry at the specified path within a snapshot,
+// returning nil if no such entry exists.
+func lookupEntry(snapshot *core.Entry, path string) *core.Entry {
+	if path == "" {
+		return snapshot
+	}
+	for _, component := range strings.Split(path, "/") {
+		if snapshot == nil || snapshot.Kind != core.EntryKind_Directory {
+			return nil
+		}
+		snapshot = snapshot.Contents[component]
+	}
+	return snapshot
+}
+
+// writeConflictSidecar preserves beta's current version of the file targeted
+// by the specified conflict sidecar transition in a conflict sidecar file
+// alongside it, overwriting any existing sidecar file. The scan lock must be
+// held by the caller.
+func (e *endpoint) writeConflictSidecar(transition *core.Change) error {
+	// Conflicts at the synchronization root can't have sidecar files.
+	if transition.Path == "" {
+		return errors.New("synchronization root cannot have a conflict sidecar")
+	}
+
+	// Open beta's version of the file from the synchronization root.
+	opener := filesystem.NewOpener(e.root)
+	defer opener.Close()
+	source, err := opener.Open(e.onDiskPath(transition.Path))
+	if err != nil {
+		return errors.Wrap(err, "unable to open beta content")
+	}
+	defer source.Close()
+
+	// Create the sidecar file.
+	path := e.filenameTranscoder.FilesystemPath(e.root, core.ConflictSidecarPath(transition.Path))
+	sidecar, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(e.defaultFileMode))
+	if err != nil {
+		return errors.Wrap(err, "unable to create sidecar file")
+	}
+
+	// Copy the contents and close the file. If either operation fails, then
+	// remove the partial sidecar file.
+	if _, err = io.Copy(sidecar, source); err != nil {
+		sidecar.Close()
+		os.Remove(path)
+		return errors.Wrap(err, "unable to copy beta content")
+	} else if err = sidecar.Close(); err != nil {
+		os.Remove(path)
+		return errors.Wrap(err, "unable to close sidecar file")
+	}
+
+	// Success.
+	return nil
+}
+
+// trackConflictSidecars records the sidecar files created for the specified
+// transitions (which should be those passed to core.Transition) given their
+// results. If a conflict sidecar transition was applied, then its sidecar file
+// is tracked against the digest of the winning file so that it can be removed
+// once that file changes. If the transition wasn't applied, then the conflict
+// remains in place and the sidecar file is removed. The scan lock must be held
+// by the caller.
+func (e *endpoint) trackConflictSidecars(transitions []*core.Change, results []*core.Entry) {
+	for t, transition := range transitions {
+		if !transition.ConflictSidecar {
+			continue
+		}
+		if results[t].Equal(transition.New) {
+			e.conflictSidecars[transition.Path] = transition.New.Digest
+		} else {
+			os.Remove(e.filenameTranscoder.FilesystemPath(e.root, core.ConflictSidecarPath(transition.Path)))
+			delete(e.conflictSidecars, transition.Path)
+		}
+	}
+}
+
+// pruneConflictSidecars removes tracked sidecar files whose winning files have
+// been modified or removed since their conflicts were resolved, as indicated by
+// the snapshot from the last scan. Tracking is performed in-memory, so sidecar
+// files created by previous endpoint instances are left in place. The scan lock
+// must be held by the caller.
+func (e *endpoint) pruneConflictSidecars() {
+	for path, digest := range e.conflictSidecars {
+		entry := lookupEntry(e.snapshot, path)
+		if entry != nil && entry.Kind == core.EntryKind_File && bytes.Equal(entry.Digest, digest) {
+			continue
+		}
+		if err := os.Remove(e.filenameTranscoder.FilesystemPath(e.root, core.ConflictSidecarPath(path))); err != nil && !os.IsNotExist(err) {
+			e.logger.Debug("Unable to remove conflict sidecar file:", err)
+		}
+		delete(e.conflictSidecars, path)
+	}
+}
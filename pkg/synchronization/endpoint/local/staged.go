@@ -0,0 +1,189 @@
+package local
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+)
+
+// StagingRoot computes the path to the staging root used by local endpoints
+// for the specified session identifier, endpoint role, synchronization root,
+// and staging mode. It does not create the staging root.
+func StagingRoot(session string, alpha bool, root string, stageMode synchronization.StageMode) (string, error) {
+	switch stageMode {
+	case synchronization.StageMode_StageModeMutagen:
+		return pathForMutagenStagingRoot(session, alpha)
+	case synchronization.StageMode_StageModeNeighboring:
+		return pathForNeighboringStagingRoot(root, session, alpha)
+	default:
+		return "", errors.New("unknown or unsupported staging mode")
+	}
+}
+
+// loadStagingManifest loads the mapping from staged file names to
+// synchronization paths from the manifest in the specified staging root.
+// Malformed entries (e.g. partially written entries) are skipped.
+func loadStagingManifest(root string) (map[string]string, error) {
+	// Open the manifest. If it doesn't exist, then no paths are known.
+	manifest, err := os.Open(filepath.Join(root, stagingManifestName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer manifest.Close()
+
+	// Parse entries.
+	result := make(map[string]string)
+	scanner := bufio.NewScanner(manifest)
+	for scanner.Scan() {
+		components := strings.SplitN(scanner.Text(), " ", 2)
+		if len(components) != 2 {
+			continue
+		}
+		path, err := strconv.Unquote(components[1])
+		if err != nil {
+			continue
+		}
+		result[components[0]] = path
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return result, nil
+}
+
+// verifyStagedFile computes the digest of the staged file at the specified path
+// using the specified hasher and compares it against the expected digest.
+func verifyStagedFile(path string, hasher hash.Hash, expected []byte) (bool, error) {
+	// Open the file.
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	// Compute the digest.
+	hasher.Reset()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return false, err
+	}
+
+	// Compare digests.
+	return bytes.Equal(hasher.Sum(nil), expected), nil
+}
+
+// ListStaged lists the files that have been staged in the specified staging
+// root. If verify is true, then the content of each staged file is digested
+// using the specified hasher (which should be the hasher for the session
+// version) and compared against its recorded digest. This function is
+// read-only and is safe to invoke while staging or transitioning is underway
+// (in which case files being concurrently created or removed may or may not be
+// included). Entries are sorted by path. If the staging root doesn't exist,
+// then an empty list is returned.
+func ListStaged(root string, hasher hash.Hash, verify bool) ([]*synchronization.StagedEntry, error) {
+	// Load the staging manifest.
+	manifest, err := loadStagingManifest(root)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load staging manifest")
+	}
+
+	// Read the prefix directories.
+	prefixes, err := ioutil.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "unable to read staging root")
+	}
+
+	// Process staged files. We ignore anything that isn't a prefix directory
+	// (such as in-progress temporary storage files or the manifest), as well
+	// as files that don't have a valid staging name. We also tolerate files
+	// and prefix directories that disappear while we're processing them.
+	var result []*synchronization.StagedEntry
+	for _, prefix := range prefixes {
+		if !prefix.IsDir() {
+			continue
+		}
+		prefixPath := filepath.Join(root, prefix.Name())
+		contents, err := ioutil.ReadDir(prefixPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrap(err, "unable to read staging prefix directory")
+		}
+		for _, content := range contents {
+			// Extract the digest from the staging name.
+			if !content.Mode().IsRegular() {
+				continue
+			}
+			name := content.Name()
+			separator := strings.IndexByte(name, '_')
+			if separator < 0 {
+				continue
+			}
+			digest, err := hex.DecodeString(name[separator+1:])
+			if err != nil || len(digest) == 0 {
+				continue
+			}
+
+			// Create the entry.
+			stagedAt, err := ptypes.TimestampProto(content.ModTime())
+			if err != nil {
+				return nil, errors.Wrap(err, "unable to convert staging time")
+			}
+			entry := &synchronization.StagedEntry{
+				Path:     manifest[name],
+				Digest:   digest,
+				Size:     uint64(content.Size()),
+				StagedAt: stagedAt,
+			}
+
+			// Perform verification if requested.
+			if verify {
+				if valid, err := verifyStagedFile(filepath.Join(prefixPath, name), hasher, digest); err != nil {
+					if os.IsNotExist(err) {
+						continue
+					}
+					return nil, errors.Wrap(err, "unable to verify staged file")
+				} else {
+					entry.DigestValid = valid
+				}
+			}
+
+			// Record the entry.
+			result = append(result, entry)
+		}
+	}
+
+	// Sort entries by path, falling back to digest for entries whose path is
+	// unknown or duplicated.
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		return bytes.Compare(result[i].Digest, result[j].Digest) < 0
+	})
+
+	// Success.
+	return result, nil
+}
@@ -0,0 +1,169 @@
+package local
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// testStagedContent is the content staged by staged file listing tests.
+var testStagedContent = map[string][]byte{
+	"file":               []byte("file content"),
+	"directory/file":     []byte("nested file content"),
+	"directory/other":    []byte("other content"),
+	"name with\nnewline": []byte("unusual name content"),
+}
+
+// stageTestContent stages testStagedContent in a new staging root within the
+// specified parent directory and returns the staging root path.
+func stageTestContent(t *testing.T, parent string) string {
+	// Create a stager.
+	root := filepath.Join(parent, "staging")
+	stager := newStager(root, false, sha1.New(), ^uint64(0), nil, "", 0, nil, core.LineEndingStyle_LineEndingStyleDefault)
+
+	// Stage content.
+	for path, data := range testStagedContent {
+		sink, err := stager.Sink(path)
+		if err != nil {
+			t.Fatal("unable to create staging sink:", err)
+		}
+		if _, err := sink.Write(data); err != nil {
+			sink.Close()
+			t.Fatal("unable to write staged content:", err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatal("unable to close staging sink:", err)
+		}
+	}
+
+	// Done.
+	return root
+}
+
+// TestListStaged tests that staged files are listed with their paths, digests,
+// and sizes, and that verification identifies corrupted content.
+func TestListStaged(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	parent, err := ioutil.TempDir("", "mutagen_staged")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Stage content.
+	start := time.Now().Add(-time.Minute)
+	root := stageTestContent(t, parent)
+
+	// Corrupt the staged content for one path.
+	corruptedDigest := sha1.Sum(testStagedContent["directory/other"])
+	corruptedPath, _, err := pathForStaging(root, "directory/other", corruptedDigest[:])
+	if err != nil {
+		t.Fatal("unable to compute staging path:", err)
+	} else if err := ioutil.WriteFile(corruptedPath, []byte("corrupted content"), 0600); err != nil {
+		t.Fatal("unable to corrupt staged content:", err)
+	}
+
+	// List staged files without verification.
+	entries, err := ListStaged(root, sha1.New(), false)
+	if err != nil {
+		t.Fatal("unable to list staged files:", err)
+	} else if len(entries) != len(testStagedContent) {
+		t.Fatal("staged entry count incorrect:", len(entries), "!=", len(testStagedContent))
+	}
+	for i, entry := range entries {
+		if i > 0 && entries[i-1].Path >= entry.Path {
+			t.Error("staged entries not sorted by path")
+		}
+		data, ok := testStagedContent[entry.Path]
+		if !ok {
+			t.Error("unexpected staged path:", entry.Path)
+			continue
+		}
+		digest := sha1.Sum(data)
+		if !bytes.Equal(entry.Digest, digest[:]) {
+			t.Error("staged digest incorrect for path:", entry.Path)
+		}
+		if entry.Path != "directory/other" && entry.Size != uint64(len(data)) {
+			t.Error("staged size incorrect for path:", entry.Path, entry.Size, "!=", len(data))
+		}
+		if stagedAt, err := ptypes.Timestamp(entry.StagedAt); err != nil || stagedAt.Before(start) {
+			t.Error("staging time incorrect for path:", entry.Path)
+		}
+		if entry.DigestValid {
+			t.Error("digest marked as valid without verification for path:", entry.Path)
+		}
+	}
+
+	// List staged files with verification.
+	entries, err = ListStaged(root, sha1.New(), true)
+	if err != nil {
+		t.Fatal("unable to list and verify staged files:", err)
+	} else if len(entries) != len(testStagedContent) {
+		t.Fatal("verified staged entry count incorrect:", len(entries), "!=", len(testStagedContent))
+	}
+	for _, entry := range entries {
+		expected := entry.Path != "directory/other"
+		if entry.DigestValid != expected {
+			t.Error("digest verification result incorrect for path:", entry.Path, entry.DigestValid, "!=", expected)
+		}
+	}
+}
+
+// TestListStagedWithoutManifest tests that staged files are still listed (with
+// unknown paths) if the staging manifest is missing.
+func TestListStagedWithoutManifest(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	parent, err := ioutil.TempDir("", "mutagen_staged")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Stage content and remove the manifest.
+	root := stageTestContent(t, parent)
+	if err := os.Remove(filepath.Join(root, stagingManifestName)); err != nil {
+		t.Fatal("unable to remove staging manifest:", err)
+	}
+
+	// List and verify staged files.
+	entries, err := ListStaged(root, sha1.New(), true)
+	if err != nil {
+		t.Fatal("unable to list staged files:", err)
+	} else if len(entries) != len(testStagedContent) {
+		t.Fatal("staged entry count incorrect:", len(entries), "!=", len(testStagedContent))
+	}
+	for _, entry := range entries {
+		if entry.Path != "" {
+			t.Error("staged path unexpectedly known:", entry.Path)
+		}
+		if !entry.DigestValid {
+			t.Error("staged content failed verification")
+		}
+	}
+}
+
+// TestListStagedNonExistent tests that listing a non-existent staging root
+// yields an empty list.
+func TestListStagedNonExistent(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	parent, err := ioutil.TempDir("", "mutagen_staged")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// List staged files.
+	if entries, err := ListStaged(filepath.Join(parent, "staging"), sha1.New(), true); err != nil {
+		t.Fatal("unable to list staged files:", err)
+	} else if len(entries) != 0 {
+		t.Error("staged files found in non-existent staging root")
+	}
+}
@@ -1,20 +1,28 @@
 package local
 
 import (
+	"fmt"
 	"hash"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/pkg/errors"
 
 	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
 )
 
 const (
 	// numberOfByteValues is the number of values a byte can take.
 	numberOfByteValues = 1 << 8
+
+	// stagingManifestName is the name of the manifest file within the staging
+	// root that records the synchronization paths corresponding to staged
+	// files, since these can't be recovered from staged file names.
+	stagingManifestName = "manifest"
 )
 
 // stagingSink is an io.WriteCloser designed to be returned by stager.
@@ -26,6 +34,8 @@ type stagingSink struct {
 	path string
 	// storage is the temporary storage for the data.
 	storage *os.File
+	// writer is a sparse writer that writes to storage, preserving holes.
+	writer *filesystem.SparseWriter
 	// digester is the hash of the data already written.
 	digester hash.Hash
 	// maximumSize is the maximum number of bytes allowed to be written to the
@@ -43,7 +53,7 @@ func (s *stagingSink) Write(data []byte) (int, error) {
 	}
 
 	// Write to the underlying storage.
-	n, err := s.storage.Write(data)
+	n, err := s.writer.Write(data)
 
 	// Write as much to the digester as we wrote to the underlying storage. This
 	// can't fail.
@@ -58,44 +68,229 @@ func (s *stagingSink) Write(data []byte) (int, error) {
 	return n, err
 }
 
+// WriteHole writes a run of zero bytes to the sink, creating a hole in the
+// underlying storage if possible. It implements rsync.HoleWriter.
+func (s *stagingSink) WriteHole(length uint64) error {
+	// Watch for size violations.
+	if (s.maximumSize - s.currentSize) < length {
+		return errors.New("maximum file size reached")
+	}
+
+	// Write the corresponding zero bytes to the digester. This can't fail.
+	var zeros [filesystem.SparseBlockSize]byte
+	for remaining := length; remaining > 0; {
+		size := uint64(len(zeros))
+		if size > remaining {
+			size = remaining
+		}
+		s.digester.Write(zeros[:size])
+		remaining -= size
+	}
+
+	// Write the hole to the underlying storage. Sparse writers defer holes
+	// until data or completion, so this can't fail.
+	s.writer.WriteHole(length)
+
+	// Update the current size. The check above is sufficient to ensure that
+	// this won't overflow.
+	s.currentSize += length
+
+	// Success.
+	return nil
+}
+
 // Close closes the sink and moves the file into place.
 func (s *stagingSink) Close() error {
+	// Complete writing of any trailing hole.
+	if err := s.writer.Finish(); err != nil {
+		s.storage.Close()
+		os.Remove(s.storage.Name())
+		return errors.Wrap(err, "unable to complete sparse writing")
+	}
+
 	// Close the underlying storage.
 	if err := s.storage.Close(); err != nil {
 		return errors.Wrap(err, "unable to close underlying storage")
 	}
 
-	// Compute the final digest.
-	digest := s.digester.Sum(nil)
+	// Move the file into place.
+	return s.stager.commit(s.storage.Name(), s.path, s.digester.Sum(nil))
+}
 
-	// Compute where the file should be relocated.
-	destination, prefix, err := pathForStaging(s.stager.root, s.path, digest)
-	if err != nil {
-		os.Remove(s.storage.Name())
-		return errors.Wrap(err, "unable to compute staging destination")
+// cloningSink is an io.WriteCloser designed to be returned by stager when
+// staging is performed by cloning the existing file. It implements
+// rsync.InPlaceWriter, allowing unchanged content in the clone to be retained.
+// It deliberately doesn't implement rsync.HoleWriter, since holes can't be
+// created by seeking over existing content.
+type cloningSink struct {
+	// stager is the parent stager.
+	stager *stager
+	// path is the path that is being staged. It is not the path to the storage
+	// or the staging destination.
+	path string
+	// storage is the clone of the existing file into which data is written.
+	storage *os.File
+	// digester is the hash of the data already written or retained.
+	digester hash.Hash
+	// maximumSize is the maximum number of bytes allowed to be written to the
+	// file.
+	maximumSize uint64
+	// currentSize is the number of bytes that have been written to or retained
+	// in the file.
+	currentSize uint64
+}
+
+// Write writes data to the sink.
+func (s *cloningSink) Write(data []byte) (int, error) {
+	// Watch for size violations.
+	if (s.maximumSize - s.currentSize) < uint64(len(data)) {
+		return 0, errors.New("maximum file size reached")
 	}
 
-	// Ensure the prefix directory exists.
-	if err = s.stager.ensurePrefixExists(prefix); err != nil {
-		os.Remove(s.storage.Name())
-		return errors.Wrap(err, "unable to create prefix directory")
+	// Write to the underlying storage.
+	n, err := s.storage.Write(data)
+
+	// Write as much to the digester as we wrote to the underlying storage. This
+	// can't fail.
+	s.digester.Write(data[:n])
+
+	// Update the current size. The check above is sufficient to ensure that
+	// this won't overflow.
+	s.currentSize += uint64(n)
+
+	// Done.
+	return n, err
+}
+
+// Offset implements rsync.InPlaceWriter.Offset.
+func (s *cloningSink) Offset() uint64 {
+	return s.currentSize
+}
+
+// Retain implements rsync.InPlaceWriter.Retain.
+func (s *cloningSink) Retain(length uint64) error {
+	// Watch for size violations.
+	if (s.maximumSize - s.currentSize) < length {
+		return errors.New("maximum file size reached")
 	}
 
-	// Relocate the file to the destination.
-	if err = os.Rename(s.storage.Name(), destination); err != nil {
-		os.Remove(s.storage.Name())
-		return errors.Wrap(err, "unable to relocate file")
+	// Digest the retained content. We read it from the clone rather than the
+	// base, since the clone's content is what will be committed.
+	retained := io.NewSectionReader(s.storage, int64(s.currentSize), int64(length))
+	if n, err := io.Copy(s.digester, retained); err != nil {
+		return errors.Wrap(err, "unable to digest retained content")
+	} else if uint64(n) != length {
+		return errors.New("retained content truncated")
 	}
 
+	// Skip over the retained content in the underlying storage.
+	if _, err := s.storage.Seek(int64(length), io.SeekCurrent); err != nil {
+		return errors.Wrap(err, "unable to skip retained content")
+	}
+
+	// Update the current size.
+	s.currentSize += length
+
 	// Success.
 	return nil
 }
 
+// Close closes the sink and moves the file into place.
+func (s *cloningSink) Close() error {
+	// Truncate any trailing content from the existing file.
+	if err := s.storage.Truncate(int64(s.currentSize)); err != nil {
+		s.storage.Close()
+		os.Remove(s.storage.Name())
+		return errors.Wrap(err, "unable to truncate clone")
+	}
+
+	// Close the underlying storage.
+	if err := s.storage.Close(); err != nil {
+		return errors.Wrap(err, "unable to close underlying storage")
+	}
+
+	// Move the file into place.
+	return s.stager.commit(s.storage.Name(), s.path, s.digester.Sum(nil))
+}
+
+// translatingSink is an io.WriteCloser designed to be returned by stager when
+// staging a file that's subject to line ending translation. It writes content
+// with line endings translated to the stager's line ending style and computes
+// the digest of the content with canonicalized line endings. It deliberately
+// doesn't implement rsync.HoleWriter, since translated text files are written
+// densely.
+type translatingSink struct {
+	// stager is the parent stager.
+	stager *stager
+	// path is the path that is being staged. It is not the path to the storage
+	// or the staging destination.
+	path string
+	// storage is the temporary storage for the data.
+	storage *os.File
+	// translator translates data to the stager's line ending style and writes
+	// it to storage.
+	translator *core.LineEndingWriter
+	// canonicalizer canonicalizes line endings and writes data to digester.
+	canonicalizer *core.LineEndingWriter
+	// digester is the hash of the canonicalized data already written.
+	digester hash.Hash
+	// maximumSize is the maximum number of bytes allowed to be written to the
+	// file.
+	maximumSize uint64
+	// currentSize is the number of bytes that have been written to the file.
+	currentSize uint64
+}
+
+// Write writes data to the sink.
+func (s *translatingSink) Write(data []byte) (int, error) {
+	// Watch for size violations. We track the untranslated size, since that's
+	// what the sender is limited by.
+	if (s.maximumSize - s.currentSize) < uint64(len(data)) {
+		return 0, errors.New("maximum file size reached")
+	}
+
+	// Write translated data to the underlying storage. Translation either
+	// succeeds or fails completely, so we only digest data on success.
+	if _, err := s.translator.Write(data); err != nil {
+		return 0, err
+	}
+
+	// Write canonicalized data to the digester. This can't fail.
+	s.canonicalizer.Write(data)
+
+	// Update the current size. The check above is sufficient to ensure that
+	// this won't overflow.
+	s.currentSize += uint64(len(data))
+
+	// Done.
+	return len(data), nil
+}
+
+// Close closes the sink and moves the file into place.
+func (s *translatingSink) Close() error {
+	// Flush any trailing carriage returns.
+	if err := s.translator.Flush(); err != nil {
+		s.storage.Close()
+		os.Remove(s.storage.Name())
+		return errors.Wrap(err, "unable to complete line ending translation")
+	}
+	s.canonicalizer.Flush()
+
+	// Close the underlying storage.
+	if err := s.storage.Close(); err != nil {
+		return errors.Wrap(err, "unable to close underlying storage")
+	}
+
+	// Move the file into place.
+	return s.stager.commit(s.storage.Name(), s.path, s.digester.Sum(nil))
+}
+
 // stager is an ephemeral content-addressable store implementation. It allows
 // files to be staged in a load-balanced fashion in a temporary directory and
-// then rapidly located by their digests. It implements both rsync.Sinker and
-// sync.Provider. It is not safe for concurrent access, and each sink that it
-// produces should be closed before any other method is invoked.
+// then rapidly located by their digests. It implements rsync.Sinker,
+// rsync.BaseSinker, and sync.Provider. It is not safe for concurrent access,
+// and each sink that it produces should be closed before any other method is
+// invoked.
 type stager struct {
 	// root is the staging root path.
 	root string
@@ -113,18 +308,59 @@ type stager struct {
 	// indicating whether or not the prefix has been created by us since the
 	// last wipe.
 	prefixCreated map[string]bool
+	// contentStore is the shared content store into which staged content
+	// should be recorded. It may be nil if no content store is being used.
+	contentStore *contentStore
+	// contentStoreOwner is the owner name to use for content store references.
+	contentStoreOwner string
+	// cloneThreshold is the minimum size of an existing file for which staging
+	// will be performed by cloning the existing file. A value of 0 disables
+	// clone-based staging.
+	cloneThreshold uint64
+	// cloningUnsupported indicates that cloning has been found to be
+	// unsupported for the staging root, in which case full copies are staged.
+	cloningUnsupported bool
+	// lineEndings is the matcher identifying files that are subject to line
+	// ending translation. It may be nil if no files are subject to line ending
+	// translation.
+	lineEndings *core.LineEndingMatcher
+	// lineEndingStyle is the line ending style to which files subject to line
+	// ending translation are translated.
+	lineEndingStyle core.LineEndingStyle
 }
 
 // newStager creates a new stager. Parent should be a common directory in which
 // staging roots are created, and rootName should be the endpoint-unique name of
-// the staging root to create/delete within the parent.
-func newStager(root string, hideRoot bool, digester hash.Hash, maximumFileSize uint64) *stager {
+// the staging root to create/delete within the parent. If contentStore is
+// non-nil, then staged content will be recorded in the content store under
+// references owned by contentStoreOwner. If cloneThreshold is non-zero, then
+// changes to existing files of at least that size will be staged by cloning
+// the existing file where supported. Files matched by lineEndings are staged
+// with their line endings translated to lineEndingStyle, with their digests
+// computed over their content with canonicalized line endings (consistent with
+// core.Scan).
+func newStager(
+	root string,
+	hideRoot bool,
+	digester hash.Hash,
+	maximumFileSize uint64,
+	contentStore *contentStore,
+	contentStoreOwner string,
+	cloneThreshold uint64,
+	lineEndings *core.LineEndingMatcher,
+	lineEndingStyle core.LineEndingStyle,
+) *stager {
 	return &stager{
-		root:            root,
-		hideRoot:        hideRoot,
-		digester:        digester,
-		maximumFileSize: maximumFileSize,
-		prefixCreated:   make(map[string]bool, numberOfByteValues),
+		root:              root,
+		hideRoot:          hideRoot,
+		digester:          digester,
+		maximumFileSize:   maximumFileSize,
+		prefixCreated:     make(map[string]bool, numberOfByteValues),
+		contentStore:      contentStore,
+		contentStoreOwner: contentStoreOwner,
+		cloneThreshold:    cloneThreshold,
+		lineEndings:       lineEndings,
+		lineEndingStyle:   lineEndingStyle,
 	}
 }
 
@@ -148,6 +384,87 @@ func (s *stager) ensurePrefixExists(prefix string) error {
 	return nil
 }
 
+// ensureRootExists ensures that the staging root exists, using a cache to avoid
+// inefficient recreation.
+func (s *stager) ensureRootExists() error {
+	// Check if we've already created the root.
+	if s.rootCreated {
+		return nil
+	}
+
+	// Attempt to create the directory.
+	if err := os.Mkdir(s.root, 0700); err != nil {
+		return errors.Wrap(err, "unable to create staging root")
+	}
+
+	// Mark the directory as hidden, if requested.
+	if s.hideRoot {
+		if err := filesystem.MarkHidden(s.root); err != nil {
+			return errors.Wrap(err, "unable to make staging root as hidden")
+		}
+	}
+
+	// Update our creation tracking.
+	s.rootCreated = true
+
+	// Success.
+	return nil
+}
+
+// commit relocates the temporary storage file at the specified path, which
+// contains the staged content for the specified path with the specified
+// digest, to its final location in the staging root.
+func (s *stager) commit(storage, path string, digest []byte) error {
+	// Compute where the file should be relocated.
+	destination, prefix, err := pathForStaging(s.root, path, digest)
+	if err != nil {
+		os.Remove(storage)
+		return errors.Wrap(err, "unable to compute staging destination")
+	}
+
+	// Ensure the prefix directory exists.
+	if err = s.ensurePrefixExists(prefix); err != nil {
+		os.Remove(storage)
+		return errors.Wrap(err, "unable to create prefix directory")
+	}
+
+	// Relocate the file to the destination.
+	if err = os.Rename(storage, destination); err != nil {
+		os.Remove(storage)
+		return errors.Wrap(err, "unable to relocate file")
+	}
+
+	// Record the staged file in the staging manifest.
+	s.record(destination, path)
+
+	// If a content store is attached, then record the staged content in the
+	// store. This is a best-effort operation, since the content store is only
+	// an optimization and its failure shouldn't prevent staging.
+	if s.contentStore != nil {
+		s.contentStore.insert(s.contentStoreOwner, digest, destination)
+	}
+
+	// Success.
+	return nil
+}
+
+// record records the synchronization path corresponding to a staged file in the
+// staging manifest. This is a best-effort operation, since the manifest is only
+// used for inspection of staged content and its failure shouldn't prevent
+// staging.
+func (s *stager) record(destination, path string) {
+	manifest, err := os.OpenFile(
+		filepath.Join(s.root, stagingManifestName),
+		os.O_WRONLY|os.O_CREATE|os.O_APPEND,
+		0600,
+	)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(manifest, "%s %s\n", filepath.Base(destination), strconv.Quote(path))
+	manifest.Close()
+}
+
 // wipe removes the staging root.
 func (s *stager) wipe() error {
 	// Reset the prefix creation tracker.
@@ -168,21 +485,8 @@ func (s *stager) wipe() error {
 // Sink implements the Sink method of rsync.Sinker.
 func (s *stager) Sink(path string) (io.WriteCloser, error) {
 	// Create the staging root if we haven't already.
-	if !s.rootCreated {
-		// Attempt to create the directory.
-		if err := os.Mkdir(s.root, 0700); err != nil {
-			return nil, errors.Wrap(err, "unable to create staging root")
-		}
-
-		// Mark the directory as hidden, if requested.
-		if s.hideRoot {
-			if err := filesystem.MarkHidden(s.root); err != nil {
-				return nil, errors.Wrap(err, "unable to make staging root as hidden")
-			}
-		}
-
-		// Update our creation tracking.
-		s.rootCreated = true
+	if err := s.ensureRootExists(); err != nil {
+		return nil, err
 	}
 
 	// Create a temporary storage file in the staging root.
@@ -194,8 +498,89 @@ func (s *stager) Sink(path string) (io.WriteCloser, error) {
 	// Reset the hash function state.
 	s.digester.Reset()
 
+	// If the file is subject to line ending translation, then return a
+	// translating sink.
+	if s.lineEndings.Matches(path) {
+		return &translatingSink{
+			stager:        s,
+			path:          path,
+			storage:       storage,
+			translator:    core.NewLineEndingWriter(storage, s.lineEndingStyle),
+			canonicalizer: core.NewLineEndingWriter(s.digester, core.LineEndingStyle_LineEndingStyleLF),
+			digester:      s.digester,
+			maximumSize:   s.maximumFileSize,
+		}, nil
+	}
+
 	// Success.
 	return &stagingSink{
+		stager:      s,
+		path:        path,
+		storage:     storage,
+		writer:      filesystem.NewSparseWriter(storage),
+		digester:    s.digester,
+		maximumSize: s.maximumFileSize,
+	}, nil
+}
+
+// SinkWithBase implements the SinkWithBase method of rsync.BaseSinker. If
+// clone-based staging is enabled, the base is at least the clone threshold in
+// size, and cloning is supported for the staging root, then it returns a sink
+// that writes changes directly into a clone of the base. Otherwise it falls
+// back to staging a full copy.
+func (s *stager) SinkWithBase(path string, base *os.File) (io.WriteCloser, error) {
+	// Check whether or not clone-based staging is enabled and supported. Files
+	// subject to line ending translation are never staged by cloning, since
+	// their content is rewritten during staging.
+	if s.cloneThreshold == 0 || s.cloningUnsupported || s.lineEndings.Matches(path) {
+		return s.Sink(path)
+	}
+
+	// Check whether or not the base is large enough to warrant cloning.
+	if metadata, err := base.Stat(); err != nil || uint64(metadata.Size()) < s.cloneThreshold {
+		return s.Sink(path)
+	}
+
+	// Create the staging root if we haven't already.
+	if err := s.ensureRootExists(); err != nil {
+		return nil, err
+	}
+
+	// Reserve a unique name for the clone within the staging root. Cloning
+	// requires that the target not exist, so we remove the reservation and
+	// rely on exclusive creation to detect (highly unlikely) conflicts.
+	reservation, err := ioutil.TempFile(s.root, "staging")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to reserve clone name")
+	}
+	clonePath := reservation.Name()
+	reservation.Close()
+	os.Remove(clonePath)
+
+	// Clone the base. If cloning isn't supported (e.g. because the filesystem
+	// doesn't support reflinks or the staging root resides on a different
+	// filesystem), then record that fact and fall back to a full copy. Other
+	// failures also fall back to a full copy, since cloning is only an
+	// optimization.
+	if err := filesystem.CloneFile(base, clonePath); err != nil {
+		if err == filesystem.ErrCloningUnsupported {
+			s.cloningUnsupported = true
+		}
+		return s.Sink(path)
+	}
+
+	// Open the clone for writing.
+	storage, err := os.OpenFile(clonePath, os.O_RDWR, 0)
+	if err != nil {
+		os.Remove(clonePath)
+		return nil, errors.Wrap(err, "unable to open clone")
+	}
+
+	// Reset the hash function state.
+	s.digester.Reset()
+
+	// Success.
+	return &cloningSink{
 		stager:      s,
 		path:        path,
 		storage:     storage,
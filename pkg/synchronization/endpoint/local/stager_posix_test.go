@@ -0,0 +1,64 @@
+// +build !windows
+
+package local
+
+import (
+	"crypto/sha1"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// availableSpace returns the number of bytes available on the filesystem
+// containing the specified path. It flushes pending writes first so that
+// delayed allocations are accounted for.
+func availableSpace(t *testing.T, path string) uint64 {
+	syscall.Sync()
+	var metadata syscall.Statfs_t
+	if err := syscall.Statfs(path, &metadata); err != nil {
+		t.Fatal("unable to query filesystem metadata:", err)
+	}
+	return uint64(metadata.Bavail) * uint64(metadata.Bsize)
+}
+
+// TestStagerCloneStagingSpaceUsage tests that clone-based staging of changes to
+// a large file consumes substantially less space than a full copy of the file.
+// It is skipped if cloning isn't supported in the test environment.
+func TestStagerCloneStagingSpaceUsage(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	parent, err := ioutil.TempDir("", "mutagen_stager")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Verify that cloning is supported.
+	if !testCloningSupported(t, parent) {
+		t.Skip("cloning not supported in test environment")
+	}
+
+	// Create the base and target content and write them to disk before taking
+	// our initial space measurement.
+	const length = 64 << 20
+	base, target := testCloneStagingContent(length)
+	stager := newStager(filepath.Join(parent, "staging"), false, sha1.New(), ^uint64(0), nil, "", 1, nil, core.LineEndingStyle_LineEndingStyleDefault)
+	before := availableSpace(t, parent) - 2*length
+
+	// Stage the content and measure the space consumed by staging (beyond the
+	// source and destination copies).
+	stageUsingRsync(t, parent, stager, base, target)
+	after := availableSpace(t, parent)
+	var consumed uint64
+	if after < before {
+		consumed = before - after
+	}
+
+	// Verify that staging consumed substantially less space than a full copy.
+	if consumed > length/4 {
+		t.Error("clone-based staging consumed too much space:", consumed)
+	}
+}
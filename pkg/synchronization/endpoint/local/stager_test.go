@@ -1,3 +1,189 @@
 package local
 
-// TODO: Implement.
+import (
+	"bytes"
+	"crypto/sha1"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/rsync"
+)
+
+// testCloneStagingPath is the path staged by clone-based staging tests.
+const testCloneStagingPath = "file"
+
+// testCloneStagingContent generates base content of the specified length and
+// target content that differs from the base by a handful of mutations and a
+// truncation of its final bytes.
+func testCloneStagingContent(length int) ([]byte, []byte) {
+	random := rand.New(rand.NewSource(473))
+	base := make([]byte, length)
+	random.Read(base)
+	target := append([]byte(nil), base[:length-100]...)
+	for _, index := range []int{length / 7, length / 3, length / 2} {
+		target[index]++
+	}
+	return base, target
+}
+
+// testCloningSupported determines whether or not cloning is supported within
+// the specified directory.
+func testCloningSupported(t *testing.T, directory string) bool {
+	// Create a file to clone and defer its removal.
+	source, err := ioutil.TempFile(directory, "clone_probe")
+	if err != nil {
+		t.Fatal("unable to create cloning probe file:", err)
+	}
+	defer func() {
+		source.Close()
+		os.Remove(source.Name())
+	}()
+
+	// Attempt to clone the file.
+	target := source.Name() + "_clone"
+	if err := filesystem.CloneFile(source, target); err != nil {
+		return false
+	}
+	os.Remove(target)
+	return true
+}
+
+// stageUsingRsync creates a synchronization root in the specified parent
+// directory containing the specified base content, stages the specified target
+// content with the specified stager using the rsync algorithm, and returns the
+// path to the staged file.
+func stageUsingRsync(t *testing.T, parent string, stager *stager, base, target []byte) string {
+	// Create the source and destination roots.
+	sourceRoot := filepath.Join(parent, "source")
+	destinationRoot := filepath.Join(parent, "destination")
+	for _, root := range []string{sourceRoot, destinationRoot} {
+		if err := os.Mkdir(root, 0700); err != nil {
+			t.Fatal("unable to create root:", err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(sourceRoot, testCloneStagingPath), target, 0600); err != nil {
+		t.Fatal("unable to write target content:", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(destinationRoot, testCloneStagingPath), base, 0600); err != nil {
+		t.Fatal("unable to write base content:", err)
+	}
+
+	// Compute the base signature.
+	engine := rsync.NewEngine()
+	signature := engine.BytesSignature(base, 0)
+
+	// Perform transmission.
+	paths := []string{testCloneStagingPath}
+	signatures := []*rsync.Signature{signature}
+	receiver, err := rsync.NewReceiver(destinationRoot, paths, signatures, stager)
+	if err != nil {
+		t.Fatal("unable to create receiver:", err)
+	}
+	if err := rsync.Transmit(sourceRoot, paths, signatures, receiver, 0, nil, nil); err != nil {
+		t.Fatal("unable to transmit content:", err)
+	}
+
+	// Locate the staged file.
+	digest := sha1.Sum(target)
+	staged, err := stager.Provide(testCloneStagingPath, digest[:])
+	if err != nil {
+		t.Fatal("unable to locate staged file:", err)
+	}
+	return staged
+}
+
+// TestStagerCloneStaging tests that clone-based staging produces the correct
+// content, falling back to staging a full copy if cloning isn't supported.
+func TestStagerCloneStaging(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	parent, err := ioutil.TempDir("", "mutagen_stager")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Stage content with clone-based staging enabled.
+	base, target := testCloneStagingContent(1 << 20)
+	stager := newStager(filepath.Join(parent, "staging"), false, sha1.New(), ^uint64(0), nil, "", 1, nil, core.LineEndingStyle_LineEndingStyleDefault)
+	staged := stageUsingRsync(t, parent, stager, base, target)
+
+	// Verify the staged content.
+	if content, err := ioutil.ReadFile(staged); err != nil {
+		t.Fatal("unable to read staged content:", err)
+	} else if !bytes.Equal(content, target) {
+		t.Error("staged content does not match target")
+	}
+
+	// Verify that cloning support was correctly detected.
+	if supported := testCloningSupported(t, parent); stager.cloningUnsupported == supported {
+		t.Error("cloning support detection incorrect: supported =", supported)
+	}
+}
+
+// TestCloningSink tests that a cloning sink applies changes in place to the
+// existing content, retaining unchanged content and truncating trailing
+// content. Since cloning may not be supported in the test environment, it uses
+// a full copy of the base content in place of a clone.
+func TestCloningSink(t *testing.T) {
+	// Create a temporary directory and defer its removal.
+	parent, err := ioutil.TempDir("", "mutagen_stager")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Create a stager and its staging root.
+	stager := newStager(filepath.Join(parent, "staging"), false, sha1.New(), ^uint64(0), nil, "", 1, nil, core.LineEndingStyle_LineEndingStyleDefault)
+	if err := stager.ensureRootExists(); err != nil {
+		t.Fatal("unable to create staging root:", err)
+	}
+
+	// Create a copy of the base content in the staging root.
+	base, target := testCloneStagingContent(1 << 18)
+	storage, err := ioutil.TempFile(stager.root, "staging")
+	if err != nil {
+		t.Fatal("unable to create storage:", err)
+	} else if _, err = storage.Write(base); err != nil {
+		t.Fatal("unable to write base content to storage:", err)
+	} else if _, err = storage.Seek(0, 0); err != nil {
+		t.Fatal("unable to reset storage:", err)
+	}
+
+	// Create a cloning sink.
+	stager.digester.Reset()
+	sink := &cloningSink{
+		stager:      stager,
+		path:        testCloneStagingPath,
+		storage:     storage,
+		digester:    stager.digester,
+		maximumSize: stager.maximumFileSize,
+	}
+
+	// Compute and apply a delta.
+	engine := rsync.NewEngine()
+	signature := engine.BytesSignature(base, 0)
+	delta := engine.DeltafyBytes(target, signature, 0)
+	for _, operation := range delta {
+		if err := engine.Patch(sink, bytes.NewReader(base), signature, operation); err != nil {
+			t.Fatal("unable to apply operation:", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal("unable to close sink:", err)
+	}
+
+	// Verify the staged content.
+	digest := sha1.Sum(target)
+	if staged, err := stager.Provide(testCloneStagingPath, digest[:]); err != nil {
+		t.Fatal("unable to locate staged file:", err)
+	} else if content, err := ioutil.ReadFile(staged); err != nil {
+		t.Fatal("unable to read staged content:", err)
+	} else if !bytes.Equal(content, target) {
+		t.Error("staged content does not match target")
+	}
+}
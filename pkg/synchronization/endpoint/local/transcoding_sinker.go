@@ -0,0 +1,49 @@
+package local
+
+import (
+	"io"
+	"os"
+)
+
+// transcodingSinker is an rsync.Sinker (and rsync.BaseSinker) that adapts a
+// stager for use with receivers operating on transcoded on-disk paths. It maps
+// the on-disk paths that it receives back to their synchronization paths,
+// since staged content is tracked (and later provided) by synchronization path.
+type transcodingSinker struct {
+	// stager is the underlying stager.
+	stager *stager
+	// paths maps on-disk paths to synchronization paths.
+	paths map[string]string
+}
+
+// newTranscodingSinker creates a new transcoding sinker that stages content
+// using the specified stager. The on-disk and synchronization path slices must
+// be of equal length and correspond element-wise.
+func newTranscodingSinker(stager *stager, onDiskPaths, paths []string) *transcodingSinker {
+	mapping := make(map[string]string, len(paths))
+	for p, path := range paths {
+		mapping[onDiskPaths[p]] = path
+	}
+	return &transcodingSinker{
+		stager: stager,
+		paths:  mapping,
+	}
+}
+
+// path computes the synchronization path for the specified on-disk path.
+func (s *transcodingSinker) path(onDiskPath string) string {
+	if path, ok := s.paths[onDiskPath]; ok {
+		return path
+	}
+	return onDiskPath
+}
+
+// Sink implements the Sink method of rsync.Sinker.
+func (s *transcodingSinker) Sink(path string) (io.WriteCloser, error) {
+	return s.stager.Sink(s.path(path))
+}
+
+// SinkWithBase implements the SinkWithBase method of rsync.BaseSinker.
+func (s *transcodingSinker) SinkWithBase(path string, base *os.File) (io.WriteCloser, error) {
+	return s.stager.SinkWithBase(s.path(path), base)
+}
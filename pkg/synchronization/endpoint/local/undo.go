@@ -0,0 +1,157 @@
+package local
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+const (
+	// undoTimestampName is the name of the file within an undo area whose
+	// modification time records when the area's content was retained.
+	undoTimestampName = "timestamp"
+)
+
+// errUndoSizeExceeded indicates that the content to be retained by an undo
+// area exceeds the area's size budget.
+var errUndoSizeExceeded = errors.New("previous content exceeds undo maximum size")
+
+// undoArea retains the previous content of files overwritten or removed by the
+// most recent transition operation on an endpoint, keyed by digest, so that
+// the transition can later be reverted by staging content from the area. The
+// area is bounded by a size budget and an age budget. It is not safe for
+// concurrent usage, but since it's only used from within the Stage and
+// Transition methods, it will never be used concurrently.
+type undoArea struct {
+	// root is the undo area root path.
+	root string
+	// maximumSize is the maximum total size of retained content.
+	maximumSize uint64
+	// maximumAge is the maximum age of retained content. A value of 0
+	// indicates no limit.
+	maximumAge time.Duration
+}
+
+// newUndoArea creates a new undo area at the specified root path with the
+// specified budgets. Any content previously retained at the root is removed,
+// since it can only be meaningfully used by the controller that drove the
+// corresponding transition.
+func newUndoArea(root string, maximumSize uint64, maximumAge time.Duration) (*undoArea, error) {
+	// Create the area.
+	area := &undoArea{
+		root:        root,
+		maximumSize: maximumSize,
+		maximumAge:  maximumAge,
+	}
+
+	// Remove any previously retained content.
+	if err := area.wipe(); err != nil {
+		return nil, errors.Wrap(err, "unable to remove previously retained content")
+	}
+
+	// Success.
+	return area, nil
+}
+
+// wipe removes all retained content.
+func (a *undoArea) wipe() error {
+	return os.RemoveAll(a.root)
+}
+
+// record replaces the area's content with the current on-disk content of all
+// files that the specified transitions will overwrite or remove within the
+// specified synchronization root (whose names are transcoded using the
+// specified transcoder, which may be nil). If the content exceeds the size budget, then
+// errUndoSizeExceeded is returned and the area should be wiped. Content is
+// only validated against its expected digest when it's staged from the area.
+func (a *undoArea) record(root string, filenames *core.FilenameTranscoder, transitions []*core.Change) error {
+	// Remove any previously retained content and recreate the area.
+	if err := a.wipe(); err != nil {
+		return errors.Wrap(err, "unable to remove previously retained content")
+	} else if err = os.MkdirAll(a.root, 0700); err != nil {
+		return errors.Wrap(err, "unable to create undo area")
+	}
+
+	// Retain the content of the files being replaced.
+	var size uint64
+	for _, transition := range transitions {
+		if err := a.retain(root, filenames, transition.Path, transition.Old, &size); err != nil {
+			return err
+		}
+	}
+
+	// Record the time at which the content was retained.
+	timestamp, err := os.Create(filepath.Join(a.root, undoTimestampName))
+	if err != nil {
+		return errors.Wrap(err, "unable to create undo timestamp")
+	} else if err = timestamp.Close(); err != nil {
+		return errors.Wrap(err, "unable to close undo timestamp")
+	}
+
+	// Success.
+	return nil
+}
+
+// retain recursively copies the content of all files within the specified
+// entry (located at the specified path within the specified synchronization
+// root) into the area, tracking the total retained size.
+func (a *undoArea) retain(root string, filenames *core.FilenameTranscoder, path string, entry *core.Entry, size *uint64) error {
+	// Handle the entry based on its kind. Only file content needs to be
+	// retained, since everything else is fully described by the entry itself.
+	if entry == nil {
+		return nil
+	} else if entry.Kind == core.EntryKind_Directory {
+		for name, child := range entry.Contents {
+			childPath := name
+			if path != "" {
+				childPath = path + "/" + name
+			}
+			if err := a.retain(root, filenames, childPath, child, size); err != nil {
+				return err
+			}
+		}
+		return nil
+	} else if entry.Kind != core.EntryKind_File {
+		return nil
+	}
+
+	// If content with this digest has already been retained, then we're done.
+	destination := filepath.Join(a.root, hex.EncodeToString(entry.Digest))
+	if _, err := os.Lstat(destination); err == nil {
+		return nil
+	}
+
+	// Verify that the content fits within the size budget.
+	source := filenames.FilesystemPath(root, path)
+	metadata, err := os.Lstat(source)
+	if err != nil {
+		return errors.Wrap(err, "unable to query previous content")
+	} else if *size += uint64(metadata.Size()); *size > a.maximumSize {
+		return errUndoSizeExceeded
+	}
+
+	// Copy the content into the area.
+	return copyIntoStore(source, a.root, destination)
+}
+
+// open opens the retained content with the specified digest. It fails if the
+// content isn't available or if it has exceeded the age budget, in which case
+// all retained content is removed.
+func (a *undoArea) open(digest []byte) (*os.File, error) {
+	// Verify that retained content exists and hasn't expired.
+	timestamp, err := os.Lstat(filepath.Join(a.root, undoTimestampName))
+	if err != nil {
+		return nil, errors.New("no previous content retained")
+	} else if a.maximumAge != 0 && time.Since(timestamp.ModTime()) > a.maximumAge {
+		a.wipe()
+		return nil, errors.New("previous content has expired")
+	}
+
+	// Open the content.
+	return os.Open(filepath.Join(a.root, hex.EncodeToString(digest)))
+}
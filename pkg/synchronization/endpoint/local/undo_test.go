@@ -0,0 +1,148 @@
+package local
+
+import (
+	"crypto/sha1"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// testUndoRoot creates a temporary directory containing a synchronization root
+// with a file and a subdirectory containing a file. It returns the temporary
+// directory (which the caller should remove), the root path, and a
+// transition that removes the root's contents.
+func testUndoRoot(t *testing.T) (string, string, *core.Change) {
+	// Create a temporary directory.
+	directory, err := ioutil.TempDir("", "mutagen_undo")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+
+	// Create the synchronization root and its content.
+	root := filepath.Join(directory, "root")
+	if err := os.MkdirAll(filepath.Join(root, "subdirectory"), 0700); err != nil {
+		os.RemoveAll(directory)
+		t.Fatal("unable to create synchronization root:", err)
+	}
+	content := map[string][]byte{
+		"file":              []byte("file content"),
+		"subdirectory/file": []byte("nested content"),
+	}
+	for path, data := range content {
+		if err := ioutil.WriteFile(filepath.Join(root, filepath.FromSlash(path)), data, 0600); err != nil {
+			os.RemoveAll(directory)
+			t.Fatal("unable to create file:", err)
+		}
+	}
+
+	// Create a transition that removes the content.
+	fileDigest := sha1.Sum(content["file"])
+	nestedDigest := sha1.Sum(content["subdirectory/file"])
+	transition := &core.Change{
+		Old: &core.Entry{
+			Kind: core.EntryKind_Directory,
+			Contents: map[string]*core.Entry{
+				"file": {Kind: core.EntryKind_File, Digest: fileDigest[:]},
+				"subdirectory": {
+					Kind: core.EntryKind_Directory,
+					Contents: map[string]*core.Entry{
+						"file": {Kind: core.EntryKind_File, Digest: nestedDigest[:]},
+					},
+				},
+			},
+		},
+		New: &core.Entry{Kind: core.EntryKind_Directory},
+	}
+
+	// Done.
+	return directory, root, transition
+}
+
+// TestUndoAreaRecord tests that an undo area retains the previous content of
+// files replaced by a transition.
+func TestUndoAreaRecord(t *testing.T) {
+	// Create a synchronization root and defer its removal.
+	directory, root, transition := testUndoRoot(t)
+	defer os.RemoveAll(directory)
+
+	// Create an undo area and record the transition.
+	area, err := newUndoArea(filepath.Join(directory, "undo"), 1024, 0)
+	if err != nil {
+		t.Fatal("unable to create undo area:", err)
+	} else if err = area.record(root, nil, []*core.Change{transition}); err != nil {
+		t.Fatal("unable to record transition:", err)
+	}
+
+	// Verify that the nested content is available even once it's been removed
+	// from the synchronization root.
+	if err := os.RemoveAll(filepath.Join(root, "subdirectory")); err != nil {
+		t.Fatal("unable to remove content:", err)
+	}
+	nested := transition.Old.Contents["subdirectory"].Contents["file"]
+	if file, err := area.open(nested.Digest); err != nil {
+		t.Fatal("unable to open retained content:", err)
+	} else if retained, err := ioutil.ReadAll(file); err != nil {
+		file.Close()
+		t.Fatal("unable to read retained content:", err)
+	} else if string(retained) != "nested content" {
+		file.Close()
+		t.Error("retained content incorrect:", string(retained))
+	} else {
+		file.Close()
+	}
+
+	// Verify that recording a subsequent transition replaces the content.
+	if err := area.record(root, nil, nil); err != nil {
+		t.Fatal("unable to record empty transition list:", err)
+	} else if _, err = area.open(nested.Digest); err == nil {
+		t.Error("content from previous transition still available")
+	}
+}
+
+// TestUndoAreaBudgets tests that an undo area enforces its size and age
+// budgets.
+func TestUndoAreaBudgets(t *testing.T) {
+	// Create a synchronization root and defer its removal.
+	directory, root, transition := testUndoRoot(t)
+	defer os.RemoveAll(directory)
+	digest := transition.Old.Contents["file"].Digest
+
+	// Verify that content exceeding the size budget isn't retained.
+	area, err := newUndoArea(filepath.Join(directory, "undo"), 16, 0)
+	if err != nil {
+		t.Fatal("unable to create undo area:", err)
+	} else if err = area.record(root, nil, []*core.Change{transition}); err != errUndoSizeExceeded {
+		t.Error("size budget not enforced:", err)
+	}
+	area.wipe()
+	if _, err := area.open(digest); err == nil {
+		t.Error("content available after exceeding size budget")
+	}
+
+	// Verify that content is retained within the size budget but that it
+	// expires after the age budget has elapsed.
+	area, err = newUndoArea(filepath.Join(directory, "undo"), 1024, time.Hour)
+	if err != nil {
+		t.Fatal("unable to create undo area:", err)
+	} else if err = area.record(root, nil, []*core.Change{transition}); err != nil {
+		t.Fatal("unable to record transition:", err)
+	}
+	if file, err := area.open(digest); err != nil {
+		t.Fatal("unable to open retained content:", err)
+	} else {
+		file.Close()
+	}
+	expired := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(area.root, undoTimestampName), expired, expired); err != nil {
+		t.Fatal("unable to backdate undo timestamp:", err)
+	}
+	if _, err := area.open(digest); err == nil {
+		t.Error("expired content available")
+	} else if _, err = os.Lstat(area.root); !os.IsNotExist(err) {
+		t.Error("expired content not removed")
+	}
+}
@@ -3,10 +3,12 @@ package remote
 import (
 	"context"
 	"net"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 
 	"github.com/mutagen-io/mutagen/pkg/compression"
 	"github.com/mutagen-io/mutagen/pkg/encoding"
@@ -24,9 +26,27 @@ type endpointClient struct {
 	encoder *encoding.ProtobufEncoder
 	// decoder is the control stream decoder.
 	decoder *encoding.ProtobufDecoder
+	// compressor is the compressor underlying the control stream encoder.
+	compressor *compression.Writer
+	// incompressibleExtensions are the extensions of files for which
+	// compression should be bypassed during transmission.
+	incompressibleExtensions []string
 	// lastSnapshotBytes is the serialized form of the last snapshot received
 	// from the remote endpoint.
 	lastSnapshotBytes []byte
+	// clockSkew is the estimated skew of the remote endpoint's clock relative
+	// to the local clock, as measured during initialization.
+	clockSkew time.Duration
+}
+
+// estimateClockSkew estimates the skew of a remote clock relative to the local
+// clock using the local times at which a request was sent and its response was
+// received, as well as the remote times at which the request was received and
+// the response was sent. It assumes that network latency is symmetric, in
+// which case any time that the remote spends processing the request doesn't
+// affect the estimate.
+func estimateClockSkew(requestSent, requestReceived, responseSent, responseReceived time.Time) time.Duration {
+	return (requestReceived.Sub(requestSent) + responseSent.Sub(responseReceived)) / 2
 }
 
 // NewEndpoint creates a new remote synchronization.Endpoint operating over the
@@ -66,6 +86,7 @@ func NewEndpoint(
 		Configuration: configuration,
 		Alpha:         alpha,
 	}
+	requestSentTime := time.Now()
 	if err := encoder.Encode(request); err != nil {
 		return nil, errors.Wrap(err, "unable to send initialize request")
 	}
@@ -79,16 +100,45 @@ func NewEndpoint(
 	} else if response.Error != "" {
 		return nil, errors.Errorf("remote error: %s", response.Error)
 	}
+	responseReceivedTime := time.Now()
+
+	// Estimate the remote clock skew, if the remote provided clock readings.
+	// We've already validated these readings, so we know that they'll convert
+	// successfully.
+	var clockSkew time.Duration
+	if response.RequestReceivedTime != nil {
+		requestReceivedTime, _ := ptypes.Timestamp(response.RequestReceivedTime)
+		responseSentTime, _ := ptypes.Timestamp(response.ResponseSentTime)
+		clockSkew = estimateClockSkew(
+			requestSentTime,
+			requestReceivedTime,
+			responseSentTime,
+			responseReceivedTime,
+		)
+	}
+
+	// Apply the compression threshold. We wait until initialization has
+	// succeeded to do this, because it ensures that the session version is
+	// supported.
+	writer.SetMinimumSize(compressionThreshold(version, configuration))
 
 	// Success.
 	successful = true
 	return &endpointClient{
-		connection: connection,
-		encoder:    encoder,
-		decoder:    decoder,
+		connection:               connection,
+		encoder:                  encoder,
+		decoder:                  decoder,
+		compressor:               writer,
+		incompressibleExtensions: configuration.IncompressibleExtensions,
+		clockSkew:                clockSkew,
 	}, nil
 }
 
+// ClockSkew implements synchronization.ClockSkewReporter.ClockSkew.
+func (e *endpointClient) ClockSkew() time.Duration {
+	return e.clockSkew
+}
+
 // Poll implements the Poll method for remote endpoints.
 func (e *endpointClient) Poll(ctx context.Context) error {
 	// Create and send the poll request.
@@ -161,7 +211,7 @@ func (e *endpointClient) Poll(ctx context.Context) error {
 }
 
 // Scan implements the Scan method for remote endpoints.
-func (e *endpointClient) Scan(ctx context.Context, ancestor *core.Entry, full bool) (*core.Entry, bool, error, bool) {
+func (e *endpointClient) Scan(ctx context.Context, ancestor *core.Entry, full, rehash bool, ignoreOverrides []string) (*core.Entry, bool, []*core.Problem, error, bool) {
 	// Create an rsync engine.
 	engine := rsync.NewEngine()
 
@@ -175,7 +225,7 @@ func (e *endpointClient) Scan(ctx context.Context, ancestor *core.Entry, full bo
 		buffer := proto.NewBuffer(nil)
 		buffer.SetDeterministic(true)
 		if err := buffer.Marshal(&core.Archive{Root: ancestor}); err != nil {
-			return nil, false, errors.Wrap(err, "unable to marshal ancestor"), false
+			return nil, false, nil, errors.Wrap(err, "unable to marshal ancestor"), false
 		}
 		baseBytes = buffer.Bytes()
 	}
@@ -188,10 +238,12 @@ func (e *endpointClient) Scan(ctx context.Context, ancestor *core.Entry, full bo
 		Scan: &ScanRequest{
 			BaseSnapshotSignature: baseSignature,
 			Full:                  full,
+			IgnoreOverrides:       ignoreOverrides,
+			Rehash:                rehash,
 		},
 	}
 	if err := e.encoder.Encode(request); err != nil {
-		return nil, false, errors.Wrap(err, "unable to send scan request"), false
+		return nil, false, nil, errors.Wrap(err, "unable to send scan request"), false
 	}
 
 	// Create a subcontext that we can cancel to regulate transmission of the
@@ -243,39 +295,39 @@ func (e *endpointClient) Scan(ctx context.Context, ancestor *core.Entry, full bo
 
 	// Check for transmission errors.
 	if responseReceiveErr != nil {
-		return nil, false, responseReceiveErr, false
+		return nil, false, nil, responseReceiveErr, false
 	} else if completionSendErr != nil {
-		return nil, false, completionSendErr, false
+		return nil, false, nil, completionSendErr, false
 	}
 
 	// Check for remote errors.
 	if response.Error != "" {
-		return nil, false, errors.Errorf("remote error: %s", response.Error), response.TryAgain
+		return nil, false, nil, errors.Errorf("remote error: %s", response.Error), response.TryAgain
 	}
 
 	// Apply the remote's deltas to the expected snapshot.
 	snapshotBytes, err := engine.PatchBytes(baseBytes, baseSignature, response.SnapshotDelta)
 	if err != nil {
-		return nil, false, errors.Wrap(err, "unable to patch base snapshot"), false
+		return nil, false, nil, errors.Wrap(err, "unable to patch base snapshot"), false
 	}
 
 	// Unmarshal the snapshot.
 	archive := &core.Archive{}
 	if err := proto.Unmarshal(snapshotBytes, archive); err != nil {
-		return nil, false, errors.Wrap(err, "unable to unmarshal snapshot"), false
+		return nil, false, nil, errors.Wrap(err, "unable to unmarshal snapshot"), false
 	}
 	snapshot := archive.Root
 
 	// Ensure that the snapshot is valid since it came over the network.
 	if err = snapshot.EnsureValid(); err != nil {
-		return nil, false, errors.Wrap(err, "invalid snapshot received"), false
+		return nil, false, nil, errors.Wrap(err, "invalid snapshot received"), false
 	}
 
 	// Store the bytes that gave us a successful snapshot.
 	e.lastSnapshotBytes = snapshotBytes
 
 	// Success.
-	return snapshot, response.PreservesExecutability, nil, false
+	return snapshot, response.PreservesExecutability, response.Skipped, nil, false
 }
 
 // Stage implements the Stage method for remote endpoints.
@@ -316,7 +368,7 @@ func (e *endpointClient) Stage(paths []string, digests [][]byte) ([]string, []*r
 
 	// Create an encoding receiver that can transmit rsync operations to the
 	// remote.
-	encoder := newProtobufRsyncEncoder(e.encoder)
+	encoder := newProtobufRsyncEncoder(e.encoder, e.compressor, response.Paths, e.incompressibleExtensions)
 	receiver := rsync.NewEncodingReceiver(encoder)
 
 	// Success.
@@ -324,7 +376,7 @@ func (e *endpointClient) Stage(paths []string, digests [][]byte) ([]string, []*r
 }
 
 // Supply implements the Supply method for remote endpoints.
-func (e *endpointClient) Supply(paths []string, signatures []*rsync.Signature, receiver rsync.Receiver) error {
+func (e *endpointClient) Supply(paths []string, signatures []*rsync.Signature, receiver rsync.Receiver, budget *rsync.TransmissionBudget) error {
 	// Create and send the supply request.
 	request := &EndpointRequest{
 		Supply: &SupplyRequest{
@@ -332,6 +384,10 @@ func (e *endpointClient) Supply(paths []string, signatures []*rsync.Signature, r
 			Signatures: signatures,
 		},
 	}
+	if budget != nil {
+		request.Supply.BudgetLimit = budget.Limit
+		request.Supply.BudgetUsed = budget.Used
+	}
 	if err := e.encoder.Encode(request); err != nil {
 		// TODO: Should we find a way to finalize the receiver here? That's a
 		// private rsync method, and there shouldn't be any resources in the
@@ -359,6 +415,55 @@ func (e *endpointClient) Supply(paths []string, signatures []*rsync.Signature, r
 	return nil
 }
 
+// AvailableSpace implements the AvailableSpace method for remote endpoints.
+func (e *endpointClient) AvailableSpace() (uint64, error) {
+	// Create and send the available space request.
+	request := &EndpointRequest{AvailableSpace: &AvailableSpaceRequest{}}
+	if err := e.encoder.Encode(request); err != nil {
+		return 0, errors.Wrap(err, "unable to send available space request")
+	}
+
+	// Receive the response and check for remote errors.
+	response := &AvailableSpaceResponse{}
+	if err := e.decoder.Decode(response); err != nil {
+		return 0, errors.Wrap(err, "unable to receive available space response")
+	} else if err = response.ensureValid(); err != nil {
+		return 0, errors.Wrap(err, "invalid available space response")
+	} else if response.Error != "" {
+		return 0, errors.Errorf("remote error: %s", response.Error)
+	}
+
+	// Success.
+	return response.Available, nil
+}
+
+// Measure implements the Measure method for remote endpoints.
+func (e *endpointClient) Measure(paths []string) (uint64, error) {
+	// If there are no paths to measure, then we're done.
+	if len(paths) == 0 {
+		return 0, nil
+	}
+
+	// Create and send the measure request.
+	request := &EndpointRequest{Measure: &MeasureRequest{Paths: paths}}
+	if err := e.encoder.Encode(request); err != nil {
+		return 0, errors.Wrap(err, "unable to send measure request")
+	}
+
+	// Receive the response and check for remote errors.
+	response := &MeasureResponse{}
+	if err := e.decoder.Decode(response); err != nil {
+		return 0, errors.Wrap(err, "unable to receive measure response")
+	} else if err = response.ensureValid(); err != nil {
+		return 0, errors.Wrap(err, "invalid measure response")
+	} else if response.Error != "" {
+		return 0, errors.Errorf("remote error: %s", response.Error)
+	}
+
+	// Success.
+	return response.Size, nil
+}
+
 // Transition implements the Transition method for remote endpoints.
 func (e *endpointClient) Transition(ctx context.Context, transitions []*core.Change) ([]*core.Entry, []*core.Problem, bool, error) {
 	// Create and send the transition request.
@@ -1,3 +1,138 @@
 package remote
 
-// TODO: Implement.
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/mutagen-io/mutagen/pkg/compression"
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+)
+
+// TestEstimateClockSkew tests estimateClockSkew.
+func TestEstimateClockSkew(t *testing.T) {
+	// Create a reference time.
+	base := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// Set up test cases.
+	testCases := []struct {
+		requestSent      time.Duration
+		requestReceived  time.Duration
+		responseSent     time.Duration
+		responseReceived time.Duration
+		expected         time.Duration
+	}{
+		{0, 0, 0, 0, 0},
+		{0, 50 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond, 0},
+		{0, 50 * time.Millisecond, 5 * time.Second, 5050 * time.Millisecond, 0},
+		{0, time.Hour + 50*time.Millisecond, time.Hour + 2*time.Second, 2100 * time.Millisecond, time.Hour - 25*time.Millisecond},
+		{0, -time.Minute + 10*time.Millisecond, -time.Minute + 20*time.Millisecond, 30 * time.Millisecond, -time.Minute},
+	}
+
+	// Process test cases.
+	for c, testCase := range testCases {
+		skew := estimateClockSkew(
+			base.Add(testCase.requestSent),
+			base.Add(testCase.requestReceived),
+			base.Add(testCase.responseSent),
+			base.Add(testCase.responseReceived),
+		)
+		if skew != testCase.expected {
+			t.Errorf("estimated clock skew incorrect for test case %d: %s != %s", c, skew, testCase.expected)
+		}
+	}
+}
+
+// serveSkewedInitialization acts as a minimal endpoint server whose clock is
+// skewed by the specified amount. It handles only the initialization exchange,
+// optionally delaying between receipt of the request and transmission of the
+// response to simulate endpoint setup, and then closes the connection.
+func serveSkewedInitialization(connection net.Conn, skew, delay time.Duration, errs chan<- error) {
+	// Defer closure of the connection.
+	defer connection.Close()
+
+	// Create an encoder and decoder.
+	encoder := encoding.NewProtobufEncoder(compression.NewCompressingWriter(connection))
+	decoder := encoding.NewProtobufDecoder(compression.NewDecompressingReader(connection))
+
+	// Receive the initialize request.
+	request := &InitializeSynchronizationRequest{}
+	if err := decoder.Decode(request); err != nil {
+		errs <- err
+		return
+	}
+	requestReceivedTime, err := ptypes.TimestampProto(time.Now().Add(skew))
+	if err != nil {
+		errs <- err
+		return
+	}
+
+	// Simulate endpoint setup.
+	time.Sleep(delay)
+
+	// Send the response.
+	responseSentTime, err := ptypes.TimestampProto(time.Now().Add(skew))
+	if err != nil {
+		errs <- err
+		return
+	}
+	errs <- encoder.Encode(&InitializeSynchronizationResponse{
+		RequestReceivedTime: requestReceivedTime,
+		ResponseSentTime:    responseSentTime,
+	})
+}
+
+// TestEndpointClientClockSkew tests that endpoint clients estimate the clock
+// skew of an endpoint server with a skewed clock.
+func TestEndpointClientClockSkew(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		skew  time.Duration
+		delay time.Duration
+	}{
+		{0, 0},
+		{time.Hour, 0},
+		{-10 * time.Minute, 0},
+		{30 * time.Second, 250 * time.Millisecond},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		// Create an in-memory connection and serve a skewed endpoint.
+		clientConnection, serverConnection := net.Pipe()
+		serverErrors := make(chan error, 1)
+		go serveSkewedInitialization(serverConnection, testCase.skew, testCase.delay, serverErrors)
+
+		// Create a client.
+		endpoint, err := NewEndpoint(
+			clientConnection,
+			"/root",
+			"session",
+			synchronization.Version_Version1,
+			&synchronization.Configuration{},
+			true,
+		)
+		if err != nil {
+			t.Fatal("unable to create endpoint client:", err)
+		}
+		if err := <-serverErrors; err != nil {
+			t.Fatal("endpoint server failed:", err)
+		}
+
+		// Verify the estimated clock skew. In-memory latency is small, so the
+		// estimate should be quite close regardless of server-side delays.
+		reporter, ok := endpoint.(synchronization.ClockSkewReporter)
+		if !ok {
+			t.Fatal("endpoint client doesn't report clock skew")
+		}
+		if difference := reporter.ClockSkew() - testCase.skew; difference > 100*time.Millisecond || difference < -100*time.Millisecond {
+			t.Errorf("estimated clock skew too far from actual: %s vs. %s", reporter.ClockSkew(), testCase.skew)
+		}
+
+		// Shut down the client.
+		endpoint.Shutdown()
+	}
+}
@@ -0,0 +1,14 @@
+package remote
+
+import (
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+)
+
+// compressionThreshold computes the effective compression threshold for an
+// endpoint connection based on the session version and endpoint configuration.
+func compressionThreshold(version synchronization.Version, configuration *synchronization.Configuration) uint64 {
+	if configuration.CompressionThreshold != 0 {
+		return configuration.CompressionThreshold
+	}
+	return version.DefaultCompressionThreshold()
+}
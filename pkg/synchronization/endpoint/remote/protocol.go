@@ -2,6 +2,10 @@ package remote
 
 import (
 	"github.com/pkg/errors"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
 )
 
 // ensureValid ensures that the InitializeSynchronizationRequest's invariants
@@ -46,6 +50,18 @@ func (r *InitializeSynchronizationResponse) ensureValid() error {
 		return errors.New("nil initialize response")
 	}
 
+	// Ensure that the endpoint clock readings are either both present (and
+	// valid) or both absent.
+	if (r.RequestReceivedTime == nil) != (r.ResponseSentTime == nil) {
+		return errors.New("incomplete endpoint clock readings")
+	} else if r.RequestReceivedTime != nil {
+		if _, err := ptypes.Timestamp(r.RequestReceivedTime); err != nil {
+			return errors.Wrap(err, "invalid request received time")
+		} else if _, err := ptypes.Timestamp(r.ResponseSentTime); err != nil {
+			return errors.Wrap(err, "invalid response sent time")
+		}
+	}
+
 	// Success.
 	return nil
 }
@@ -98,6 +114,13 @@ func (r *ScanRequest) ensureValid() error {
 
 	// Full is correct regardless of value, so no validation is required.
 
+	// Ensure that all ignore overrides are valid.
+	for _, override := range r.IgnoreOverrides {
+		if err := core.EnsureValidIgnoreOverride(override); err != nil {
+			return errors.Wrapf(err, "invalid ignore override (%s)", override)
+		}
+	}
+
 	// Success.
 	return nil
 }
@@ -128,6 +151,13 @@ func (r *ScanResponse) ensureValid() error {
 		}
 	}
 
+	// Ensure that each skipped file problem is valid.
+	for _, problem := range r.Skipped {
+		if err := problem.EnsureValid(); err != nil {
+			return errors.Wrap(err, "invalid skipped file problem")
+		}
+	}
+
 	// If an error is set, make sure that certain other fields are not. This
 	// isn't really an invariant that *needs* to be enforced, but it is a good
 	// sanity check.
@@ -136,6 +166,8 @@ func (r *ScanResponse) ensureValid() error {
 			return errors.New("non-empty snapshot delta present on error")
 		} else if r.PreservesExecutability {
 			return errors.New("executability preservation information present on error")
+		} else if len(r.Skipped) > 0 {
+			return errors.New("skipped file problems present on error")
 		}
 	}
 
@@ -297,6 +329,52 @@ func (r *TransitionResponse) ensureValid(expectedCount int) error {
 	return nil
 }
 
+// ensureValid ensures that the AvailableSpaceRequest's invariants are
+// respected.
+func (r *AvailableSpaceRequest) ensureValid() error {
+	// A nil available space request is not valid.
+	if r == nil {
+		return errors.New("nil available space request")
+	}
+
+	// Success.
+	return nil
+}
+
+// ensureValid ensures that the AvailableSpaceResponse's invariants are
+// respected.
+func (r *AvailableSpaceResponse) ensureValid() error {
+	// A nil available space response is not valid.
+	if r == nil {
+		return errors.New("nil available space response")
+	}
+
+	// Success.
+	return nil
+}
+
+// ensureValid ensures that the MeasureRequest's invariants are respected.
+func (r *MeasureRequest) ensureValid() error {
+	// A nil measure request is not valid.
+	if r == nil {
+		return errors.New("nil measure request")
+	}
+
+	// Success.
+	return nil
+}
+
+// ensureValid ensures that the MeasureResponse's invariants are respected.
+func (r *MeasureResponse) ensureValid() error {
+	// A nil measure response is not valid.
+	if r == nil {
+		return errors.New("nil measure response")
+	}
+
+	// Success.
+	return nil
+}
+
 // ensureValid ensures that EndpointRequest's invariants are respected.
 func (r *EndpointRequest) ensureValid() error {
 	// A nil endpoint request is not valid.
@@ -321,6 +399,12 @@ func (r *EndpointRequest) ensureValid() error {
 	if r.Transition != nil {
 		set++
 	}
+	if r.AvailableSpace != nil {
+		set++
+	}
+	if r.Measure != nil {
+		set++
+	}
 	if set != 1 {
 		return errors.New("invalid number of fields set")
 	}
@@ -1,3 +1,40 @@
 package remote
 
-// TODO: Implement.
+import (
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+// TestInitializeSynchronizationResponseClockReadings tests validation of clock
+// readings in InitializeSynchronizationResponse.
+func TestInitializeSynchronizationResponseClockReadings(t *testing.T) {
+	// Set up test cases.
+	valid := ptypes.TimestampNow()
+	invalid := &timestamp.Timestamp{Nanos: -1}
+	testCases := []struct {
+		response    *InitializeSynchronizationResponse
+		expectValid bool
+	}{
+		{&InitializeSynchronizationResponse{}, true},
+		{&InitializeSynchronizationResponse{Error: "failed"}, true},
+		{&InitializeSynchronizationResponse{RequestReceivedTime: valid, ResponseSentTime: valid}, true},
+		{&InitializeSynchronizationResponse{RequestReceivedTime: valid}, false},
+		{&InitializeSynchronizationResponse{ResponseSentTime: valid}, false},
+		{&InitializeSynchronizationResponse{RequestReceivedTime: invalid, ResponseSentTime: valid}, false},
+		{&InitializeSynchronizationResponse{RequestReceivedTime: valid, ResponseSentTime: invalid}, false},
+	}
+
+	// Process test cases.
+	for c, testCase := range testCases {
+		err := testCase.response.ensureValid()
+		if testCase.expectValid && err != nil {
+			t.Errorf("test case %d unexpectedly classified as invalid: %v", c, err)
+		} else if !testCase.expectValid && err == nil {
+			t.Errorf("test case %d unexpectedly classified as valid", c)
+		}
+	}
+}
+
+// TODO: Implement tests for additional messages.
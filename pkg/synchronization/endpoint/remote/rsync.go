@@ -3,6 +3,7 @@ package remote
 import (
 	"github.com/pkg/errors"
 
+	"github.com/mutagen-io/mutagen/pkg/compression"
 	"github.com/mutagen-io/mutagen/pkg/encoding"
 	"github.com/mutagen-io/mutagen/pkg/synchronization/rsync"
 )
@@ -14,10 +15,22 @@ const (
 )
 
 // protobufRsyncEncoder adapts a Protocol Buffers encoder to the rsync.Encoder
-// interface.
+// interface. If provided with a compressor and a list of incompressible
+// extensions, it will bypass compression for transmissions of files with those
+// extensions.
 type protobufRsyncEncoder struct {
 	// encoder is the underlying Protocol Buffers encoder.
 	encoder *encoding.ProtobufEncoder
+	// compressor is the compressor underlying the encoder. It may be nil.
+	compressor *compression.Writer
+	// incompressible indicates, for each path being transmitted (in order),
+	// whether or not its contents are considered incompressible. It is nil if
+	// compression bypassing isn't being performed.
+	incompressible []bool
+	// index is the index of the path currently being transmitted.
+	index int
+	// bypassing indicates whether or not compression is currently bypassed.
+	bypassing bool
 	// buffered is the number of transmissions currently buffered.
 	buffered int
 	// error indicates any previous encountered transmission error. It renders
@@ -25,8 +38,41 @@ type protobufRsyncEncoder struct {
 	error error
 }
 
-func newProtobufRsyncEncoder(encoder *encoding.ProtobufEncoder) *protobufRsyncEncoder {
-	return &protobufRsyncEncoder{encoder: encoder}
+// newProtobufRsyncEncoder creates a new Protocol Buffers rsync encoder for
+// transmitting the specified paths. If compressor is non-nil, then compression
+// will be bypassed for transmissions of paths with any of the specified
+// incompressible extensions.
+func newProtobufRsyncEncoder(
+	encoder *encoding.ProtobufEncoder,
+	compressor *compression.Writer,
+	paths []string,
+	incompressibleExtensions []string,
+) *protobufRsyncEncoder {
+	// Determine which paths are incompressible.
+	var incompressible []bool
+	if compressor != nil && len(incompressibleExtensions) > 0 {
+		incompressible = make([]bool, len(paths))
+		for p, path := range paths {
+			incompressible[p] = compression.Incompressible(path, incompressibleExtensions)
+		}
+	}
+
+	// Create the encoder.
+	return &protobufRsyncEncoder{
+		encoder:        encoder,
+		compressor:     compressor,
+		incompressible: incompressible,
+	}
+}
+
+// flush writes any buffered transmissions.
+func (e *protobufRsyncEncoder) flush() error {
+	if err := e.encoder.Flush(); err != nil {
+		e.error = errors.Wrap(err, "unable to write encoded messages")
+		return e.error
+	}
+	e.buffered = 0
+	return nil
 }
 
 // Encode implements transmission encoding.
@@ -36,23 +82,41 @@ func (e *protobufRsyncEncoder) Encode(transmission *rsync.Transmission) error {
 		return errors.Wrap(e.error, "previous error encountered")
 	}
 
+	// If we're bypassing compression for incompressible files and the
+	// compressibility of the current file differs from that of the buffered
+	// transmissions, then flush the buffered transmissions (which are written
+	// as a single compression frame) and update the bypass setting.
+	if e.incompressible != nil && e.index < len(e.incompressible) {
+		if bypass := e.incompressible[e.index]; bypass != e.bypassing {
+			if e.buffered > 0 {
+				if err := e.flush(); err != nil {
+					return err
+				}
+			}
+			e.compressor.SetBypass(bypass)
+			e.bypassing = bypass
+		}
+	}
+
 	// Encode the transmission without sending.
 	if err := e.encoder.EncodeWithoutFlush(transmission); err != nil {
 		e.error = errors.Wrap(err, "unable to encode transmission")
 		return e.error
 	}
 
-	// Increment the buffered message count.
+	// Increment the buffered message count and, if the transmission completes
+	// the current file, the path index.
 	e.buffered++
+	if transmission.Done {
+		e.index++
+	}
 
 	// If we've reached the maximum number of transmissions that we're willing
 	// to buffer, then flush the buffer and reset the count.
 	if e.buffered == rsyncTransmissionGroupSize {
-		if err := e.encoder.Flush(); err != nil {
-			e.error = errors.Wrap(err, "unable to write encoded messages")
-			return e.error
+		if err := e.flush(); err != nil {
+			return err
 		}
-		e.buffered = 0
 	}
 
 	// Success.
@@ -73,8 +137,16 @@ func (e *protobufRsyncEncoder) Finalize() error {
 		return errors.Wrap(err, "unable to write encoded messages")
 	}
 
-	// Reset the buffered message count, in case this encoder is reused.
+	// Reset the buffered message count and path index, in case this encoder is
+	// reused.
 	e.buffered = 0
+	e.index = 0
+
+	// Restore compression for subsequent messages.
+	if e.bypassing {
+		e.compressor.SetBypass(false)
+		e.bypassing = false
+	}
 
 	// Success.
 	return nil
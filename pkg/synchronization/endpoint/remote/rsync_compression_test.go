@@ -0,0 +1,97 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/compression"
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/rsync"
+)
+
+// testFrameCount parses a stream produced by a compressing writer and returns
+// the number of frames and their total payload size.
+func testFrameCount(t *testing.T, stream []byte) (int, int) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Parse frames.
+	var frames, payload int
+	reader := bytes.NewReader(stream)
+	for reader.Len() > 0 {
+		reader.ReadByte()
+		length, err := binary.ReadUvarint(reader)
+		if err != nil {
+			t.Fatal("unable to read frame length:", err)
+		}
+		reader.Seek(int64(length), io.SeekCurrent)
+		frames++
+		payload += int(length)
+	}
+
+	// Done.
+	return frames, payload
+}
+
+// TestProtobufRsyncEncoderIncompressibleBypass tests that transmissions of
+// files with incompressible extensions bypass compression while those of other
+// files are compressed.
+func TestProtobufRsyncEncoderIncompressibleBypass(t *testing.T) {
+	// Set up test cases.
+	data := bytes.Repeat([]byte("compressible"), 1024)
+	testCases := []struct {
+		path              string
+		expectCompression bool
+	}{
+		{"file.txt", true},
+		{"archive.zip", false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		// Create the encoder.
+		stream := &bytes.Buffer{}
+		compressor := compression.NewCompressingWriter(stream)
+		encoder := newProtobufRsyncEncoder(
+			encoding.NewProtobufEncoder(compressor),
+			compressor,
+			[]string{testCase.path},
+			[]string{".zip"},
+		)
+
+		// Transmit the file.
+		transmissions := []*rsync.Transmission{
+			{Operation: &rsync.Operation{Data: data}},
+			{Done: true},
+		}
+		for _, transmission := range transmissions {
+			if err := encoder.Encode(transmission); err != nil {
+				t.Fatal("unable to encode transmission:", err)
+			}
+		}
+		if err := encoder.Finalize(); err != nil {
+			t.Fatal("unable to finalize encoder:", err)
+		}
+
+		// Verify that a single frame was written with the expected payload
+		// size.
+		frames, payload := testFrameCount(t, stream.Bytes())
+		if frames != 1 {
+			t.Errorf("%s: transmission written as %d frames", testCase.path, frames)
+		} else if compressed := payload < len(data); compressed != testCase.expectCompression {
+			t.Errorf("%s: compression (%t) does not match expected (%t)",
+				testCase.path, compressed, testCase.expectCompression,
+			)
+		}
+
+		// Verify that compression was restored after transmission.
+		stream.Reset()
+		if _, err := compressor.Write(data); err != nil {
+			t.Fatal("unable to write data:", err)
+		} else if _, payload := testFrameCount(t, stream.Bytes()); payload >= len(data) {
+			t.Errorf("%s: compression not restored after transmission", testCase.path)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 
 	"github.com/mutagen-io/mutagen/pkg/compression"
 	"github.com/mutagen-io/mutagen/pkg/encoding"
@@ -25,6 +26,11 @@ type endpointServer struct {
 	encoder *encoding.ProtobufEncoder
 	// decoder is the control stream decoder.
 	decoder *encoding.ProtobufDecoder
+	// compressor is the compressor underlying the control stream encoder.
+	compressor *compression.Writer
+	// incompressibleExtensions are the extensions of files for which
+	// compression should be bypassed during transmission.
+	incompressibleExtensions []string
 	// endpoint is the underlying local endpoint.
 	endpoint synchronization.Endpoint
 }
@@ -59,6 +65,10 @@ func ServeEndpoint(logger *logging.Logger, connection net.Conn, options ...Endpo
 		return err
 	}
 
+	// Record the time at which the request was received. This will be sent
+	// back to the client to allow it to estimate clock skew.
+	requestReceivedTime := ptypes.TimestampNow()
+
 	// If a root path override has been specified, then apply it.
 	if endpointServerOptions.root != "" {
 		request.Root = endpointServerOptions.root
@@ -130,15 +140,24 @@ func ServeEndpoint(logger *logging.Logger, connection net.Conn, options ...Endpo
 	defer endpoint.Shutdown()
 
 	// Send a successful initialize response.
-	if err = encoder.Encode(&InitializeSynchronizationResponse{}); err != nil {
+	response := &InitializeSynchronizationResponse{
+		RequestReceivedTime: requestReceivedTime,
+		ResponseSentTime:    ptypes.TimestampNow(),
+	}
+	if err = encoder.Encode(response); err != nil {
 		return errors.Wrap(err, "unable to send initialize response")
 	}
 
+	// Apply the compression threshold.
+	writer.SetMinimumSize(compressionThreshold(request.Version, request.Configuration))
+
 	// Create the server.
 	server := &endpointServer{
-		endpoint: endpoint,
-		encoder:  encoder,
-		decoder:  decoder,
+		endpoint:                 endpoint,
+		encoder:                  encoder,
+		decoder:                  decoder,
+		compressor:               writer,
+		incompressibleExtensions: request.Configuration.IncompressibleExtensions,
 	}
 
 	// Server until an error occurs.
@@ -181,6 +200,14 @@ func (s *endpointServer) serve() error {
 			if err := s.serveTransition(request.Transition); err != nil {
 				return errors.Wrap(err, "unable to serve transition request")
 			}
+		} else if request.AvailableSpace != nil {
+			if err := s.serveAvailableSpace(request.AvailableSpace); err != nil {
+				return errors.Wrap(err, "unable to serve available space request")
+			}
+		} else if request.Measure != nil {
+			if err := s.serveMeasure(request.Measure); err != nil {
+				return errors.Wrap(err, "unable to serve measure request")
+			}
 		} else {
 			// TODO: Should we panic here? The request validation already
 			// ensures that one and only one message component is set, so we
@@ -297,7 +324,7 @@ func (s *endpointServer) serveScan(request *ScanRequest) error {
 
 		// Perform a scan and set up the response.
 		var response *ScanResponse
-		snapshot, preservesExecutability, err, tryAgain := s.endpoint.Scan(ctx, nil, request.Full)
+		snapshot, preservesExecutability, skipped, err, tryAgain := s.endpoint.Scan(ctx, nil, request.Full, request.Rehash, request.IgnoreOverrides)
 		if err != nil {
 			response = &ScanResponse{
 				Error:    err.Error(),
@@ -313,6 +340,7 @@ func (s *endpointServer) serveScan(request *ScanRequest) error {
 			response = &ScanResponse{
 				SnapshotDelta:          delta,
 				PreservesExecutability: preservesExecutability,
+				Skipped:                skipped,
 			}
 		}
 
@@ -401,11 +429,20 @@ func (s *endpointServer) serveSupply(request *SupplyRequest) error {
 
 	// Create an encoding receiver that can transmit rsync operations to the
 	// remote.
-	encoder := newProtobufRsyncEncoder(s.encoder)
+	encoder := newProtobufRsyncEncoder(s.encoder, s.compressor, request.Paths, s.incompressibleExtensions)
 	receiver := rsync.NewEncodingReceiver(encoder)
 
+	// Determine the transmission budget, if any.
+	var budget *rsync.TransmissionBudget
+	if request.BudgetLimit != 0 {
+		budget = &rsync.TransmissionBudget{
+			Limit: request.BudgetLimit,
+			Used:  request.BudgetUsed,
+		}
+	}
+
 	// Perform supplying.
-	if err := s.endpoint.Supply(request.Paths, request.Signatures, receiver); err != nil {
+	if err := s.endpoint.Supply(request.Paths, request.Signatures, receiver, budget); err != nil {
 		return errors.Wrap(err, "unable to perform supplying")
 	}
 
@@ -413,6 +450,52 @@ func (s *endpointServer) serveSupply(request *SupplyRequest) error {
 	return nil
 }
 
+// serveAvailableSpace serves an available space request.
+func (s *endpointServer) serveAvailableSpace(request *AvailableSpaceRequest) error {
+	// Ensure the request is valid.
+	if err := request.ensureValid(); err != nil {
+		return errors.Wrap(err, "invalid available space request")
+	}
+
+	// Query the available space.
+	available, err := s.endpoint.AvailableSpace()
+	if err != nil {
+		s.encoder.Encode(&AvailableSpaceResponse{Error: err.Error()})
+		return errors.Wrap(err, "unable to query available space")
+	}
+
+	// Send the response.
+	if err = s.encoder.Encode(&AvailableSpaceResponse{Available: available}); err != nil {
+		return errors.Wrap(err, "unable to send available space response")
+	}
+
+	// Success.
+	return nil
+}
+
+// serveMeasure serves a measure request.
+func (s *endpointServer) serveMeasure(request *MeasureRequest) error {
+	// Ensure the request is valid.
+	if err := request.ensureValid(); err != nil {
+		return errors.Wrap(err, "invalid measure request")
+	}
+
+	// Perform measurement.
+	size, err := s.endpoint.Measure(request.Paths)
+	if err != nil {
+		s.encoder.Encode(&MeasureResponse{Error: err.Error()})
+		return errors.Wrap(err, "unable to perform measurement")
+	}
+
+	// Send the response.
+	if err = s.encoder.Encode(&MeasureResponse{Size: size}); err != nil {
+		return errors.Wrap(err, "unable to send measure response")
+	}
+
+	// Success.
+	return nil
+}
+
 // serveTransitino serves a transition request.
 func (s *endpointServer) serveTransition(request *TransitionRequest) error {
 	// Ensure the request is valid.
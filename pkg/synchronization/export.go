@@ -0,0 +1,83 @@
+package synchronization
+
+import (
+	"context"
+	"hash"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/identifier"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// export scans the session's alpha or beta endpoint and streams its content to
+// the specified writer as an archive in the specified format. The scan honors
+// the endpoint's ignores and symbolic link mode. Since content is read directly
+// from the synchronization root, only local endpoints are supported. The
+// endpoint is scanned using a separate connection, so exporting doesn't
+// interact with the synchronization loop.
+func (c *controller) export(ctx context.Context, beta bool, format core.ExportFormat, writer io.Writer) error {
+	// Grab the endpoint parameters. The endpoint URLs can change if the
+	// session is relocated, so we access them under the state lock.
+	c.stateLock.Lock()
+	description, url, configuration := "alpha", c.session.Alpha, c.mergedAlphaConfiguration
+	if beta {
+		description, url, configuration = "beta", c.session.Beta, c.mergedBetaConfiguration
+	}
+	version := c.session.Version
+	c.stateLock.UnlockWithoutNotify()
+
+	// Verify that the endpoint is local.
+	if url.Protocol != urlpkg.Protocol_Local {
+		return errors.New("export only supported for local endpoints")
+	}
+
+	// Create a unique identifier to use in lieu of the session identifier, so
+	// that the export endpoint doesn't share state with the session's
+	// endpoint.
+	exportIdentifier, err := identifier.New(identifier.PrefixSynchronization)
+	if err != nil {
+		return errors.Wrap(err, "unable to generate identifier for export")
+	}
+
+	// Connect to the endpoint and defer its shutdown.
+	endpoint, err := connect(
+		ctx,
+		c.logger.Sublogger("export"),
+		url,
+		"",
+		exportIdentifier,
+		version,
+		configuration,
+		!beta,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "unable to connect to %s", description)
+	}
+	defer endpoint.Shutdown()
+
+	// Perform a full scan. We don't provide an ancestor since we don't need
+	// one.
+	snapshot, _, _, err, _ := endpoint.Scan(ctx, nil, true, false, nil)
+	if err != nil {
+		return errors.Wrapf(err, "unable to scan %s", description)
+	}
+
+	// Verify content against the snapshot as it's streamed, unless the scan
+	// canonicalized line endings (in which case digests won't match the raw
+	// content).
+	var hasher hash.Hash
+	if len(configuration.LineEndingPatterns) == 0 {
+		hasher = version.Hasher()
+	}
+
+	// Perform the export.
+	if err := core.Export(ctx, url.Path, snapshot, nil, hasher, format, writer); err != nil {
+		return errors.Wrapf(err, "unable to export %s", description)
+	}
+
+	// Success.
+	return nil
+}
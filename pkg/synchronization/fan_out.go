@@ -0,0 +1,347 @@
+package synchronization
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/rsync"
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// additionalBetaEndpointSession computes the session identifier to provide to
+// the additional beta endpoint with the specified index. Endpoints use the
+// session identifier to compute the paths for their caches and staging roots,
+// so a distinct identifier is necessary to avoid collisions between beta
+// endpoints residing on the same system.
+func additionalBetaEndpointSession(session string, index int) string {
+	return fmt.Sprintf("%s-beta%d", session, index+1)
+}
+
+// fanOutCycle contains the alpha-side results of a synchronization cycle that
+// are propagated to additional beta endpoints.
+type fanOutCycle struct {
+	// alpha is the alpha endpoint.
+	alpha Endpoint
+	// snapshot is the alpha snapshot.
+	snapshot *core.Entry
+	// preservesExecutability indicates whether or not alpha preserves
+	// executability.
+	preservesExecutability bool
+	// skipped are the problems for files skipped during the alpha scan.
+	skipped []*core.Problem
+	// synchronizationMode is the effective synchronization mode.
+	synchronizationMode core.SynchronizationMode
+	// forceFullScan indicates whether or not beta scans should be forced to
+	// perform a full (warm) re-scan.
+	forceFullScan bool
+	// rehash indicates whether or not beta scans should be forced to discard
+	// their digest caches.
+	rehash bool
+	// ignoreOverrides are the ignore overrides to apply to beta scans.
+	ignoreOverrides []string
+	// supplyLock serializes supply operations on alpha, since endpoints don't
+	// support concurrent operations.
+	supplyLock sync.Mutex
+}
+
+// updateAdditionalBetaState updates the state for the additional beta endpoint
+// with the specified index. If err is nil, then the synchronization cycle for
+// the endpoint is considered to have succeeded and the problems are recorded.
+// For endpoints with label selectors, members are the names of the currently
+// matched containers.
+func (c *controller) updateAdditionalBetaState(index int, connected bool, members []string, problems []*core.Problem, err error) {
+	// Lock the state and defer its release.
+	c.stateLock.Lock()
+	defer c.stateLock.Unlock()
+
+	// Ensure that additional beta states are present. They may have been wiped
+	// out by a state reset in the run loop.
+	if len(c.state.AdditionalBetas) != len(c.session.AdditionalBetas) {
+		c.state.AdditionalBetas = make([]*AdditionalBetaState, len(c.session.AdditionalBetas))
+	}
+	previous := c.state.AdditionalBetas[index]
+	if previous == nil {
+		previous = &AdditionalBetaState{}
+	}
+
+	// Create the new state. We replace the existing state rather than modifying
+	// it because state copies share the individual states.
+	state := &AdditionalBetaState{
+		Connected:                       connected,
+		SuccessfulSynchronizationCycles: previous.SuccessfulSynchronizationCycles,
+		Members:                         members,
+	}
+	if err != nil {
+		state.LastError = err.Error()
+		state.Problems = previous.Problems
+	} else {
+		state.SuccessfulSynchronizationCycles++
+		state.Problems = problems
+	}
+	c.state.AdditionalBetas[index] = state
+}
+
+// synchronizeAdditionalBetas performs a synchronization cycle for each of the
+// additional beta endpoints in parallel, propagating the alpha-side results of
+// the session's synchronization cycle. Any disconnected endpoint is connected
+// first, though connection attempts for any single endpoint are limited to one
+// per auto-reconnect interval. Failures are isolated to the individual
+// endpoints: a failure is recorded in the endpoint's state (and the endpoint is
+// disconnected if the failure is terminal), but it doesn't affect the session
+// or the other additional beta endpoints. Connected endpoints are stored in the
+// endpoints slice, which is shared with the run loop. Additional beta endpoints
+// with Docker label selectors have their matching containers tracked in the
+// memberships slice (which is also shared with the run loop) and fan out to
+// each of those containers.
+func (c *controller) synchronizeAdditionalBetas(
+	ctx, stopCtx context.Context,
+	cycle *fanOutCycle,
+	endpoints []Endpoint,
+	memberships []*labelMembership,
+	lastConnectAttempts []time.Time,
+) {
+	// Synchronize each endpoint in a separate Goroutine.
+	done := &sync.WaitGroup{}
+	for i := range endpoints {
+		done.Add(1)
+		go func(index int) {
+			defer done.Done()
+
+			// Handle endpoints with label selectors separately.
+			logger := c.logger.Sublogger(fmt.Sprintf("beta%d", index+1))
+			if selector, ok := c.session.AdditionalBetas[index].DockerLabelSelector(); ok {
+				if memberships[index] == nil {
+					memberships[index] = newLabelMembership()
+				}
+				c.synchronizeLabelSelectorBeta(ctx, stopCtx, logger, index, selector, cycle, memberships[index])
+				return
+			}
+
+			// Perform synchronization.
+			attempted, problems, err := c.synchronizeAdditionalBetaEndpoint(
+				ctx, stopCtx,
+				logger,
+				c.session.AdditionalBetas[index],
+				additionalBetaEndpointSession(c.session.Identifier, index),
+				pathForAdditionalArchive(c.archivePath, index),
+				cycle,
+				&endpoints[index],
+				&lastConnectAttempts[index],
+			)
+			if !attempted {
+				return
+			}
+
+			// Record the result.
+			c.updateAdditionalBetaState(index, endpoints[index] != nil, nil, problems, err)
+		}(i)
+	}
+
+	// Wait for synchronization to complete.
+	done.Wait()
+}
+
+// synchronizeAdditionalBetaEndpoint connects to an additional beta endpoint (if
+// necessary) and performs a synchronization cycle for it. The endpoint and the
+// time of its last connection attempt are tracked via the provided pointers,
+// which are updated in place. It returns whether or not a cycle was attempted
+// (which won't be the case if connection attempts are being rate limited), any
+// transition problems, and any error that occurred.
+func (c *controller) synchronizeAdditionalBetaEndpoint(
+	ctx, stopCtx context.Context,
+	logger *logging.Logger,
+	betaURL *url.URL,
+	endpointSession string,
+	archivePath string,
+	cycle *fanOutCycle,
+	endpoint *Endpoint,
+	lastConnectAttempt *time.Time,
+) (bool, []*core.Problem, error) {
+	// Connect to the endpoint, if necessary.
+	if *endpoint == nil {
+		if time.Since(*lastConnectAttempt) < autoReconnectInterval {
+			return false, nil, nil
+		}
+		*lastConnectAttempt = time.Now()
+		connected, err := connect(
+			stopCtx,
+			logger,
+			betaURL,
+			"",
+			endpointSession,
+			c.session.Version,
+			c.mergedBetaConfiguration,
+			false,
+		)
+		if err != nil {
+			logger.Warning("Connection failure:", err)
+			return true, nil, errors.Wrap(err, "unable to connect")
+		}
+		*endpoint = connected
+	}
+
+	// Perform synchronization.
+	problems, disconnect, err := c.synchronizeAdditionalBeta(ctx, stopCtx, archivePath, cycle, *endpoint)
+	if err != nil {
+		logger.Warning("Synchronization failure:", err)
+	}
+
+	// Disconnect the endpoint if the failure was terminal.
+	if disconnect {
+		(*endpoint).Shutdown()
+		*endpoint = nil
+	}
+
+	// Done.
+	return true, problems, err
+}
+
+// synchronizeAdditionalBeta performs a synchronization cycle for an additional
+// beta endpoint whose archive is stored at the specified path. It returns any
+// transition problems, whether or not the endpoint should be disconnected, and
+// any error that occurred.
+func (c *controller) synchronizeAdditionalBeta(
+	ctx, stopCtx context.Context,
+	archivePath string,
+	cycle *fanOutCycle,
+	beta Endpoint,
+) ([]*core.Problem, bool, error) {
+	// Load the endpoint's archive and extract the ancestor. If there's no
+	// archive, then synchronization hasn't yet been performed for the endpoint,
+	// so we start with an empty ancestor. The same applies if the archive is
+	// corrupted.
+	archive, err := loadArchive(c.logger, archivePath)
+	if os.IsNotExist(err) {
+		archive = &core.Archive{}
+	} else if err != nil {
+		return nil, false, errors.Wrap(err, "unable to load archive")
+	}
+	ancestor := archive.Root
+
+	// Perform the scan. If the scan fails but recommends a retry, then we keep
+	// the endpoint connected and retry on the next cycle.
+	βSnapshot, βPreservesExecutability, βSkipped, err, tryAgain := beta.Scan(
+		stopCtx, ancestor, cycle.forceFullScan, cycle.rehash, cycle.ignoreOverrides,
+	)
+	if err != nil {
+		return nil, !tryAgain, errors.Wrap(err, "scan error")
+	}
+
+	// Exclude any files skipped on either endpoint from both snapshots.
+	αSnapshot := cycle.snapshot
+	if len(cycle.skipped) > 0 || len(βSkipped) > 0 {
+		if αSnapshot, err = excludeSkippedFiles(αSnapshot, cycle.skipped, βSkipped); err != nil {
+			return nil, false, errors.Wrap(err, "unable to exclude skipped files from alpha snapshot")
+		} else if βSnapshot, err = excludeSkippedFiles(βSnapshot, cycle.skipped, βSkipped); err != nil {
+			return nil, false, errors.Wrap(err, "unable to exclude skipped files from beta snapshot")
+		}
+	}
+
+	// Propagate executability from alpha if beta doesn't preserve it. Changes
+	// never propagate to alpha in fan-out synchronization, so there's no need
+	// to handle the reverse case.
+	if cycle.preservesExecutability && !βPreservesExecutability {
+		βSnapshot = core.PropagateExecutability(ancestor, αSnapshot, βSnapshot)
+	}
+
+	// Perform safety checks. Rather than halting the session, we simply refuse
+	// to synchronize the endpoint until the condition is resolved.
+	if oneEndpointEmptiedRoot(ancestor, αSnapshot, βSnapshot) {
+		return nil, false, errors.New("halted due to one-sided root emptying")
+	}
+
+	// Perform reconciliation.
+	ancestorChanges, αTransitions, βTransitions, _ := core.Reconcile(
+		ancestor,
+		αSnapshot,
+		βSnapshot,
+		cycle.synchronizationMode,
+	)
+	if len(αTransitions) > 0 {
+		return nil, false, errors.New("reconciliation generated alpha transitions")
+	} else if containsRootDeletion(βTransitions) {
+		return nil, false, errors.New("halted due to root deletion")
+	} else if containsRootTypeChange(βTransitions) {
+		return nil, false, errors.New("halted due to root type change")
+	}
+
+	// Stage files on the endpoint.
+	if paths, digests, err := core.TransitionDependencies(βTransitions); err != nil {
+		return nil, false, errors.Wrap(err, "unable to determine paths for staging")
+	} else if len(paths) > 0 {
+		filteredPaths, signatures, receiver, err := beta.Stage(paths, digests)
+		if err != nil {
+			return nil, true, errors.Wrap(err, "unable to begin staging")
+		}
+		if !filteredPathsAreSubset(filteredPaths, paths) {
+			return nil, true, errors.New("endpoint returned incorrect subset of staging paths")
+		}
+		if len(filteredPaths) > 0 {
+			receiver = rsync.NewPreemptableReceiver(ctx, receiver)
+			cycle.supplyLock.Lock()
+			err = cycle.alpha.Supply(filteredPaths, signatures, receiver, nil)
+			cycle.supplyLock.Unlock()
+			if err != nil {
+				return nil, true, errors.Wrap(err, "unable to stage files")
+			}
+		}
+	}
+
+	// Perform transitions.
+	var problems []*core.Problem
+	var transitionErr error
+	if len(βTransitions) > 0 {
+		var results []*core.Entry
+		results, problems, _, transitionErr = beta.Transition(ctx, βTransitions)
+		if transitionErr == nil {
+			for t, transition := range βTransitions {
+				ancestorChanges = append(ancestorChanges, &core.Change{Path: transition.Path, New: results[t]})
+			}
+		}
+	}
+
+	// Propagate changes to the ancestor, validate it, and save it.
+	if newAncestor, err := core.Apply(ancestor, ancestorChanges); err != nil {
+		return nil, false, errors.Wrap(err, "unable to propagate changes to ancestor")
+	} else if err = newAncestor.EnsureValid(); err != nil {
+		return nil, false, errors.Wrap(err, "new ancestor is invalid")
+	} else {
+		archive.Root = newAncestor
+	}
+	if err := saveArchive(archivePath, archive, c.archiveCompressionMode()); err != nil {
+		return nil, false, errors.Wrap(err, "unable to save ancestor")
+	}
+
+	// Check for transition errors.
+	if transitionErr != nil {
+		return nil, true, errors.Wrap(transitionErr, "unable to apply changes")
+	}
+
+	// Success.
+	return withSkippedFileProblems(βSkipped, problems), false, nil
+}
+
+// removeAdditionalArchives removes the archives for the session's additional
+// beta endpoints (including those for containers matched by label selectors),
+// if present.
+func (c *controller) removeAdditionalArchives() error {
+	for i := range c.session.AdditionalBetas {
+		archivePaths, err := additionalArchivePaths(c.archivePath, i)
+		if err != nil {
+			return err
+		}
+		for _, archivePath := range archivePaths {
+			if err := os.Remove(archivePath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package synchronization
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/state"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// testFailingScanEndpoint is a testDirectoryEndpoint whose scans always fail
+// with a recommendation to retry.
+type testFailingScanEndpoint struct {
+	*testDirectoryEndpoint
+}
+
+// Scan implements Endpoint.Scan.
+func (e *testFailingScanEndpoint) Scan(_ context.Context, _ *core.Entry, _, _ bool, _ []string) (*core.Entry, bool, []*core.Problem, error, bool) {
+	return nil, false, nil, errors.New("scan failed"), true
+}
+
+// TestControllerFanOut tests that content is propagated from alpha to multiple
+// beta endpoints and that a failure on one additional beta endpoint doesn't
+// affect the others or the session.
+func TestControllerFanOut(t *testing.T) {
+	// Create a temporary directory to hold all test content and defer its
+	// removal.
+	parent, err := ioutil.TempDir("", "mutagen_fan_out")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Create endpoint directories and alpha content.
+	alphaRoot := filepath.Join(parent, "alpha")
+	staging := filepath.Join(parent, "staging")
+	betaRoots := []string{
+		filepath.Join(parent, "beta"),
+		filepath.Join(parent, "beta1"),
+		filepath.Join(parent, "beta2"),
+		filepath.Join(parent, "beta3"),
+	}
+	for _, directory := range append([]string{alphaRoot, staging}, betaRoots...) {
+		if err := os.Mkdir(directory, 0700); err != nil {
+			t.Fatal("unable to create directory:", err)
+		}
+	}
+	for name, data := range testShutdownContent {
+		if err := ioutil.WriteFile(filepath.Join(alphaRoot, name), data, 0600); err != nil {
+			t.Fatal("unable to create alpha content:", err)
+		}
+	}
+
+	// Create an empty archive.
+	archivePath := filepath.Join(parent, "archive")
+	if err := encoding.MarshalAndSaveProtobuf(archivePath, &core.Archive{}); err != nil {
+		t.Fatal("unable to save archive:", err)
+	}
+
+	// Create the controller.
+	session := &Session{
+		Identifier: "session",
+		Version:    Version_Version1,
+		AdditionalBetas: []*url.URL{
+			{Kind: url.Kind_Synchronization, Path: betaRoots[1]},
+			{Kind: url.Kind_Synchronization, Path: betaRoots[2]},
+			{Kind: url.Kind_Synchronization, Path: betaRoots[3]},
+		},
+		Configuration: &Configuration{
+			SynchronizationMode: core.SynchronizationMode_SynchronizationModeOneWayReplica,
+		},
+		ConfigurationAlpha: &Configuration{},
+		ConfigurationBeta:  &Configuration{},
+	}
+	c := &controller{
+		logger:                   logging.RootLogger.Sublogger("test"),
+		sessionPath:              filepath.Join(parent, "session"),
+		archivePath:              archivePath,
+		stateLock:                state.NewTrackingLock(state.NewTracker()),
+		session:                  session,
+		mergedAlphaConfiguration: &Configuration{},
+		mergedBetaConfiguration:  &Configuration{},
+		state: &State{
+			Session: session,
+		},
+	}
+
+	// Create endpoints, with the second additional beta failing to scan.
+	alpha := &testDirectoryEndpoint{root: alphaRoot, source: alphaRoot, staging: staging}
+	var betas []*testDirectoryEndpoint
+	for _, root := range betaRoots {
+		betas = append(betas, &testDirectoryEndpoint{root: root, source: alphaRoot, staging: staging})
+	}
+	additionalBetas := []Endpoint{betas[1], &testFailingScanEndpoint{betas[2]}, betas[3]}
+
+	// Start the synchronization loop and wait for multiple cycles to complete.
+	ctx, cancel := context.WithCancel(context.Background())
+	stopCtx, stop := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.stop = stop
+	c.flushRequests = make(chan *controllerFlushRequest, 1)
+	c.done = make(chan struct{})
+	go c.run(ctx, stopCtx, alpha, betas[0], additionalBetas)
+	waitForSynchronizationCycles(t, c, 1)
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer flushCancel()
+	if err := c.flush(flushCtx, "", false, nil, false); err != nil {
+		t.Fatal("unable to flush session:", err)
+	}
+
+	// Verify that content was propagated to all healthy beta endpoints but not
+	// to the failing endpoint.
+	for b, root := range betaRoots {
+		for name, expected := range testShutdownContent {
+			content, err := ioutil.ReadFile(filepath.Join(root, name))
+			if b == 2 {
+				if !os.IsNotExist(err) {
+					t.Error("content propagated to failing endpoint:", name)
+				}
+			} else if err != nil {
+				t.Errorf("unable to read content from beta %d: %v", b, err)
+			} else if !bytes.Equal(content, expected) {
+				t.Errorf("content mismatch on beta %d: %s", b, name)
+			}
+		}
+	}
+
+	// Verify that the additional beta states reflect the isolated failure and
+	// that the session itself is unaffected.
+	current := c.currentState()
+	if current.LastError != "" {
+		t.Error("additional beta failure propagated to session:", current.LastError)
+	} else if current.SuccessfulSynchronizationCycles < 2 {
+		t.Error("session stopped cycling")
+	}
+	if len(current.AdditionalBetas) != len(additionalBetas) {
+		t.Fatal("incorrect number of additional beta states:", len(current.AdditionalBetas))
+	}
+	for b, betaState := range current.AdditionalBetas {
+		if b == 1 {
+			if betaState.LastError == "" {
+				t.Error("failing endpoint has no last error")
+			} else if betaState.SuccessfulSynchronizationCycles != 0 {
+				t.Error("failing endpoint recorded successful cycles")
+			}
+		} else if betaState.LastError != "" {
+			t.Errorf("additional beta %d has error: %s", b, betaState.LastError)
+		} else if !betaState.Connected || betaState.SuccessfulSynchronizationCycles < 2 {
+			t.Errorf("additional beta %d not synchronized", b)
+		}
+	}
+
+	// Verify that archives were created for the healthy additional betas.
+	for _, b := range []int{0, 2} {
+		if _, err := os.Lstat(pathForAdditionalArchive(archivePath, b)); err != nil {
+			t.Error("additional beta archive not saved:", err)
+		}
+	}
+
+	// Halt the controller.
+	if err := c.halt(flushCtx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("unable to halt controller:", err)
+	}
+}
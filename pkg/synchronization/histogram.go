@@ -0,0 +1,206 @@
+package synchronization
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// durationHistogramBounds are the bucket upper bounds (in seconds) used for
+	// duration histograms.
+	durationHistogramBounds = []float64{
+		0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300,
+	}
+	// throughputHistogramBounds are the bucket upper bounds (in bytes per
+	// second) used for throughput histograms.
+	throughputHistogramBounds = []float64{
+		1 << 10, 1 << 13, 1 << 16, 1 << 18, 1 << 20, 1 << 22, 1 << 24, 1 << 26, 1 << 28, 1 << 30,
+	}
+)
+
+// EnsureValid ensures that Histogram's invariants are respected.
+func (h *Histogram) EnsureValid() error {
+	// A nil histogram is considered valid (it represents an empty histogram).
+	if h == nil {
+		return nil
+	}
+
+	// Ensure that bounds are finite and strictly increasing.
+	for b, bound := range h.Bounds {
+		if math.IsNaN(bound) || math.IsInf(bound, 0) {
+			return errors.New("non-finite histogram bound")
+		} else if b > 0 && bound <= h.Bounds[b-1] {
+			return errors.New("histogram bounds not strictly increasing")
+		}
+	}
+
+	// Ensure that there's one count for each bound, plus an overflow count, and
+	// that the counts sum to the total count.
+	if len(h.Counts) != len(h.Bounds)+1 {
+		return errors.New("histogram count and bound lengths inconsistent")
+	}
+	var total uint64
+	for _, count := range h.Counts {
+		total += count
+	}
+	if total != h.Count {
+		return errors.New("histogram counts inconsistent with total count")
+	}
+
+	// Success.
+	return nil
+}
+
+// withObservation returns a copy of the histogram with the specified value
+// recorded. If the histogram is nil, then a new histogram with the specified
+// bounds is created. Values are recorded in the first bucket whose upper bound
+// is greater than or equal to the value, or in the overflow bucket if no such
+// bucket exists. The receiver isn't modified, allowing histograms to be treated
+// as immutable once they're stored in the session state. Since the number of
+// buckets is fixed, the histogram's size doesn't grow with observations.
+func (h *Histogram) withObservation(bounds []float64, value float64) *Histogram {
+	// Create the result, copying any existing counts.
+	result := &Histogram{
+		Bounds: bounds,
+		Counts: make([]uint64, len(bounds)+1),
+	}
+	if h != nil {
+		result.Bounds = h.Bounds
+		result.Counts = make([]uint64, len(h.Counts))
+		copy(result.Counts, h.Counts)
+		result.Count = h.Count
+		result.Sum = h.Sum
+	}
+
+	// Find the bucket for the value. We use a linear search since the number
+	// of buckets is small.
+	bucket := len(result.Bounds)
+	for b, bound := range result.Bounds {
+		if value <= bound {
+			bucket = b
+			break
+		}
+	}
+
+	// Record the observation.
+	result.Counts[bucket]++
+	result.Count++
+	result.Sum += value
+
+	// Done.
+	return result
+}
+
+// Mean returns the mean of the values recorded in the histogram. It returns 0
+// if no values have been recorded.
+func (h *Histogram) Mean() float64 {
+	if h == nil || h.Count == 0 {
+		return 0
+	}
+	return h.Sum / float64(h.Count)
+}
+
+// Quantile estimates the specified quantile (which should be in the range
+// [0, 1]) of the values recorded in the histogram, returning the upper bound of
+// the bucket in which the quantile falls. It returns positive infinity if the
+// quantile falls in the overflow bucket and 0 if no values have been recorded.
+func (h *Histogram) Quantile(quantile float64) float64 {
+	// Handle empty histograms.
+	if h == nil || h.Count == 0 {
+		return 0
+	}
+
+	// Compute the rank of the quantile and find the bucket containing it.
+	rank := uint64(math.Ceil(quantile * float64(h.Count)))
+	if rank == 0 {
+		rank = 1
+	}
+	var cumulative uint64
+	for b, count := range h.Counts {
+		cumulative += count
+		if cumulative >= rank {
+			if b < len(h.Bounds) {
+				return h.Bounds[b]
+			}
+			break
+		}
+	}
+	return math.Inf(1)
+}
+
+// EnsureValid ensures that Timings' invariants are respected.
+func (t *Timings) EnsureValid() error {
+	// A nil timings object is considered valid (it represents empty timings).
+	if t == nil {
+		return nil
+	}
+
+	// Ensure that each histogram is valid.
+	if err := t.ConnectDurations.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid connect durations")
+	} else if err = t.ScanDurations.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid scan durations")
+	} else if err = t.StagingThroughputs.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid staging throughputs")
+	} else if err = t.TransitionDurations.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid transition durations")
+	}
+
+	// Success.
+	return nil
+}
+
+// copy creates a shallow copy of the timings. It returns an empty timings
+// object if the receiver is nil.
+func (t *Timings) copy() *Timings {
+	if t == nil {
+		return &Timings{}
+	}
+	return &Timings{
+		ConnectDurations:    t.ConnectDurations,
+		ScanDurations:       t.ScanDurations,
+		StagingThroughputs:  t.StagingThroughputs,
+		TransitionDurations: t.TransitionDurations,
+	}
+}
+
+// withConnectDuration returns a copy of the timings with the specified
+// endpoint connection duration recorded.
+func (t *Timings) withConnectDuration(duration time.Duration) *Timings {
+	result := t.copy()
+	result.ConnectDurations = result.ConnectDurations.withObservation(durationHistogramBounds, duration.Seconds())
+	return result
+}
+
+// withScanDuration returns a copy of the timings with the specified scan
+// duration recorded.
+func (t *Timings) withScanDuration(duration time.Duration) *Timings {
+	result := t.copy()
+	result.ScanDurations = result.ScanDurations.withObservation(durationHistogramBounds, duration.Seconds())
+	return result
+}
+
+// withStagingThroughput returns a copy of the timings with the staging
+// throughput corresponding to the specified number of bytes received over the
+// specified duration recorded. If either the byte count or the duration is 0,
+// then no throughput is recorded, since the measurement wouldn't be meaningful.
+func (t *Timings) withStagingThroughput(received uint64, duration time.Duration) *Timings {
+	if received == 0 || duration <= 0 {
+		return t
+	}
+	result := t.copy()
+	result.StagingThroughputs = result.StagingThroughputs.withObservation(
+		throughputHistogramBounds, float64(received)/duration.Seconds(),
+	)
+	return result
+}
+
+// withTransitionDuration returns a copy of the timings with the specified
+// transition duration recorded.
+func (t *Timings) withTransitionDuration(duration time.Duration) *Timings {
+	result := t.copy()
+	result.TransitionDurations = result.TransitionDurations.withObservation(durationHistogramBounds, duration.Seconds())
+	return result
+}
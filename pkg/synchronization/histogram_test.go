@@ -0,0 +1,150 @@
+package synchronization
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// TestHistogramObservation tests that histogram observations are recorded in
+// the correct buckets.
+func TestHistogramObservation(t *testing.T) {
+	// Feed synthetic timings into a histogram, including values on bucket
+	// boundaries and values beyond the last bucket.
+	bounds := []float64{1, 10, 100}
+	var histogram *Histogram
+	for _, value := range []float64{0, 0.5, 1, 1.5, 10, 50, 99, 100, 101, 1000} {
+		previous := histogram
+		histogram = histogram.withObservation(bounds, value)
+		if previous != nil && previous.Count != histogram.Count-1 {
+			t.Fatal("observation modified previous histogram")
+		}
+	}
+
+	// Verify the histogram.
+	if err := histogram.EnsureValid(); err != nil {
+		t.Fatal("histogram invalid:", err)
+	}
+	expectedCounts := []uint64{3, 2, 3, 2}
+	if len(histogram.Counts) != len(expectedCounts) {
+		t.Fatal("histogram bucket count incorrect:", len(histogram.Counts), "!=", len(expectedCounts))
+	}
+	for b, expected := range expectedCounts {
+		if histogram.Counts[b] != expected {
+			t.Errorf("bucket %d count incorrect: %d != %d", b, histogram.Counts[b], expected)
+		}
+	}
+	if histogram.Count != 10 {
+		t.Error("histogram total count incorrect:", histogram.Count, "!= 10")
+	}
+	if histogram.Sum != 1363 {
+		t.Error("histogram sum incorrect:", histogram.Sum, "!= 1363")
+	}
+
+	// Verify summary statistics.
+	if mean := histogram.Mean(); mean != 136.3 {
+		t.Error("histogram mean incorrect:", mean, "!= 136.3")
+	}
+	if median := histogram.Quantile(0.5); median != 10 {
+		t.Error("histogram median incorrect:", median, "!= 10")
+	}
+	if p90 := histogram.Quantile(0.9); !math.IsInf(p90, 1) {
+		t.Error("histogram 90th percentile not in overflow bucket:", p90)
+	}
+}
+
+// TestHistogramEnsureValid tests that invalid histograms are rejected.
+func TestHistogramEnsureValid(t *testing.T) {
+	testCases := []struct {
+		histogram   *Histogram
+		expectValid bool
+	}{
+		{nil, true},
+		{&Histogram{Counts: []uint64{0}}, true},
+		{&Histogram{Bounds: []float64{1, 2}, Counts: []uint64{1, 0, 2}, Count: 3}, true},
+		{&Histogram{}, false},
+		{&Histogram{Bounds: []float64{2, 1}, Counts: []uint64{0, 0, 0}}, false},
+		{&Histogram{Bounds: []float64{math.NaN()}, Counts: []uint64{0, 0}}, false},
+		{&Histogram{Bounds: []float64{1}, Counts: []uint64{0}}, false},
+		{&Histogram{Bounds: []float64{1}, Counts: []uint64{1, 1}, Count: 3}, false},
+	}
+	for i, testCase := range testCases {
+		if err := testCase.histogram.EnsureValid(); (err == nil) != testCase.expectValid {
+			t.Errorf("test case %d: validity incorrect: %v", i, err)
+		}
+	}
+}
+
+// TestTimingsObservation tests that session timings record observations in the
+// appropriate histograms with bounded bucketing.
+func TestTimingsObservation(t *testing.T) {
+	// Record synthetic timings.
+	var timings *Timings
+	for i := 0; i < 1000; i++ {
+		timings = timings.withScanDuration(20 * time.Millisecond)
+	}
+	timings = timings.withConnectDuration(2 * time.Second)
+	timings = timings.withConnectDuration(time.Hour)
+	timings = timings.withStagingThroughput(1<<20, time.Second)
+	timings = timings.withStagingThroughput(0, time.Second)
+	timings = timings.withStagingThroughput(1<<20, 0)
+	timings = timings.withTransitionDuration(3 * time.Millisecond)
+
+	// Verify the timings.
+	if err := timings.EnsureValid(); err != nil {
+		t.Fatal("timings invalid:", err)
+	}
+
+	// Verify that scan durations landed in a single bucket and that the
+	// histogram size didn't grow with the number of observations.
+	if len(timings.ScanDurations.Counts) != len(durationHistogramBounds)+1 {
+		t.Error("scan duration histogram size incorrect")
+	}
+	for b, count := range timings.ScanDurations.Counts {
+		if b < len(durationHistogramBounds) && durationHistogramBounds[b] == 0.025 {
+			if count != 1000 {
+				t.Error("scan duration bucket count incorrect:", count, "!= 1000")
+			}
+		} else if count != 0 {
+			t.Errorf("unexpected scan durations in bucket %d: %d", b, count)
+		}
+	}
+
+	// Verify connect durations, including the overflow bucket.
+	connectCounts := timings.ConnectDurations.Counts
+	if connectCounts[len(connectCounts)-1] != 1 {
+		t.Error("connect duration not recorded in overflow bucket")
+	} else if timings.ConnectDurations.Count != 2 {
+		t.Error("connect duration count incorrect:", timings.ConnectDurations.Count, "!= 2")
+	} else if timings.ConnectDurations.Quantile(0.5) != 2.5 {
+		t.Error("connect duration median incorrect:", timings.ConnectDurations.Quantile(0.5), "!= 2.5")
+	}
+
+	// Verify that only meaningful throughputs were recorded.
+	if timings.StagingThroughputs.Count != 1 {
+		t.Error("staging throughput count incorrect:", timings.StagingThroughputs.Count, "!= 1")
+	} else if timings.StagingThroughputs.Quantile(1) != 1<<20 {
+		t.Error("staging throughput bucket incorrect:", timings.StagingThroughputs.Quantile(1))
+	}
+
+	// Verify transition durations.
+	if timings.TransitionDurations.Counts[0] != 1 {
+		t.Error("transition duration not recorded in first bucket")
+	}
+
+	// Verify that timings survive a serialization round trip, which is how
+	// they're exposed to clients.
+	state := &State{Timings: timings}
+	encoded, err := proto.Marshal(state)
+	if err != nil {
+		t.Fatal("unable to marshal state:", err)
+	}
+	decoded := &State{}
+	if err := proto.Unmarshal(encoded, decoded); err != nil {
+		t.Fatal("unable to unmarshal state:", err)
+	} else if !proto.Equal(decoded.Timings, timings) {
+		t.Error("timings not preserved by serialization round trip")
+	}
+}
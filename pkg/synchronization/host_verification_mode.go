@@ -0,0 +1,70 @@
+package synchronization
+
+import (
+	"github.com/pkg/errors"
+)
+
+// IsDefault indicates whether or not the host verification mode is
+// HostVerificationMode_HostVerificationModeDefault.
+func (m HostVerificationMode) IsDefault() bool {
+	return m == HostVerificationMode_HostVerificationModeDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (m *HostVerificationMode) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to a host verification mode.
+	switch text {
+	case "strict":
+		*m = HostVerificationMode_HostVerificationModeStrict
+	case "ephemeral":
+		*m = HostVerificationMode_HostVerificationModeEphemeral
+	default:
+		return errors.Errorf("unknown host verification mode specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular host verification mode is a
+// valid, non-default value.
+func (m HostVerificationMode) Supported() bool {
+	switch m {
+	case HostVerificationMode_HostVerificationModeStrict:
+		return true
+	case HostVerificationMode_HostVerificationModeEphemeral:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of a host verification mode.
+func (m HostVerificationMode) Description() string {
+	switch m {
+	case HostVerificationMode_HostVerificationModeDefault:
+		return "Default"
+	case HostVerificationMode_HostVerificationModeStrict:
+		return "Strict"
+	case HostVerificationMode_HostVerificationModeEphemeral:
+		return "Ephemeral"
+	default:
+		return "Unknown"
+	}
+}
+
+// Warning returns a human-readable warning describing the security implications
+// of a host verification mode, or an empty string if the mode doesn't relax
+// host verification.
+func (m HostVerificationMode) Warning() string {
+	switch m {
+	case HostVerificationMode_HostVerificationModeEphemeral:
+		return "host verification is relaxed: unknown host keys are accepted automatically and not recorded, leaving connections vulnerable to man-in-the-middle attacks"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,118 @@
+package synchronization
+
+import (
+	"testing"
+)
+
+// TestHostVerificationModeUnmarshal tests that unmarshaling from a string
+// specification succeeeds for HostVerificationMode.
+func TestHostVerificationModeUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text          string
+		expectedMode  HostVerificationMode
+		expectFailure bool
+	}{
+		{"", HostVerificationMode_HostVerificationModeDefault, true},
+		{"asdf", HostVerificationMode_HostVerificationModeDefault, true},
+		{"strict", HostVerificationMode_HostVerificationModeStrict, false},
+		{"ephemeral", HostVerificationMode_HostVerificationModeEphemeral, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var mode HostVerificationMode
+		if err := mode.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if mode != testCase.expectedMode {
+			t.Errorf(
+				"unmarshaled mode (%s) does not match expected (%s)",
+				mode,
+				testCase.expectedMode,
+			)
+		}
+	}
+}
+
+// TestHostVerificationModeSupported tests that HostVerificationMode support
+// detection works as expected.
+func TestHostVerificationModeSupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode            HostVerificationMode
+		expectSupported bool
+	}{
+		{HostVerificationMode_HostVerificationModeDefault, false},
+		{HostVerificationMode_HostVerificationModeStrict, true},
+		{HostVerificationMode_HostVerificationModeEphemeral, true},
+		{(HostVerificationMode_HostVerificationModeEphemeral + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.mode.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"mode support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestHostVerificationModeDescription tests that HostVerificationMode
+// description generation works as expected.
+func TestHostVerificationModeDescription(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                HostVerificationMode
+		expectedDescription string
+	}{
+		{HostVerificationMode_HostVerificationModeDefault, "Default"},
+		{HostVerificationMode_HostVerificationModeStrict, "Strict"},
+		{HostVerificationMode_HostVerificationModeEphemeral, "Ephemeral"},
+		{(HostVerificationMode_HostVerificationModeEphemeral + 1), "Unknown"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if description := testCase.mode.Description(); description != testCase.expectedDescription {
+			t.Errorf(
+				"mode description (%s) does not match expected (%s)",
+				description,
+				testCase.expectedDescription,
+			)
+		}
+	}
+}
+
+// TestHostVerificationModeWarning tests that HostVerificationMode warning
+// generation works as expected.
+func TestHostVerificationModeWarning(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode          HostVerificationMode
+		expectWarning bool
+	}{
+		{HostVerificationMode_HostVerificationModeDefault, false},
+		{HostVerificationMode_HostVerificationModeStrict, false},
+		{HostVerificationMode_HostVerificationModeEphemeral, true},
+		{(HostVerificationMode_HostVerificationModeEphemeral + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if warning := testCase.mode.Warning(); (warning != "") != testCase.expectWarning {
+			t.Errorf(
+				"mode warning presence (%t) does not match expected (%t) for mode %s",
+				warning != "",
+				testCase.expectWarning,
+				testCase.mode,
+			)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package synchronization
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/selection"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+const (
+	// ignoreSetExtension is the file extension used for serialized ignore
+	// sets.
+	ignoreSetExtension = ".yml"
+)
+
+// ignoreSet is the on-disk representation of a shared ignore set.
+type ignoreSet struct {
+	// Paths are the ignore patterns contained in the ignore set.
+	Paths []string `yaml:"paths"`
+}
+
+// EnsureIgnoreSetNameValid ensures that an ignore set name is valid. Ignore set
+// names follow the same rules as session names, except that they can't be
+// empty.
+func EnsureIgnoreSetNameValid(name string) error {
+	if name == "" {
+		return errors.New("empty ignore set name")
+	}
+	return selection.EnsureNameValid(name)
+}
+
+// pathForIgnoreSet computes the path to the serialized ignore set with the
+// specified name.
+func pathForIgnoreSet(name string) (string, error) {
+	// Compute/create the ignore sets directory.
+	ignoreSetsDirectoryPath, err := filesystem.Mutagen(true, filesystem.MutagenSynchronizationIgnoreSetsDirectoryName)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to compute/create ignore sets directory")
+	}
+
+	// Success.
+	return filepath.Join(ignoreSetsDirectoryPath, name+ignoreSetExtension), nil
+}
+
+// SaveIgnoreSet validates and stores a shared ignore set under the specified
+// name, replacing any existing set with the same name. Sessions referencing
+// the set will pick up the new patterns on their next synchronization cycle.
+func SaveIgnoreSet(name string, patterns []string) error {
+	// Validate the name and patterns.
+	if err := EnsureIgnoreSetNameValid(name); err != nil {
+		return errors.Wrap(err, "invalid ignore set name")
+	}
+	for _, pattern := range patterns {
+		if !core.ValidIgnorePattern(pattern) {
+			return errors.Errorf("invalid ignore pattern: %s", pattern)
+		}
+	}
+
+	// Compute the ignore set path.
+	path, err := pathForIgnoreSet(name)
+	if err != nil {
+		return err
+	}
+
+	// Save the ignore set.
+	return encoding.MarshalAndSave(path, func() ([]byte, error) {
+		return yaml.Marshal(&ignoreSet{Paths: patterns})
+	})
+}
+
+// LoadIgnoreSet loads the patterns of the shared ignore set with the specified
+// name. It returns an error if the ignore set is undefined or contains invalid
+// patterns.
+func LoadIgnoreSet(name string) ([]string, error) {
+	// Validate the name.
+	if err := EnsureIgnoreSetNameValid(name); err != nil {
+		return nil, errors.Wrap(err, "invalid ignore set name")
+	}
+
+	// Compute the ignore set path.
+	path, err := pathForIgnoreSet(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the ignore set.
+	set := &ignoreSet{}
+	if err := encoding.LoadAndUnmarshalYAML(path, set); err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("undefined ignore set: %s", name)
+		}
+		return nil, errors.Wrapf(err, "unable to load ignore set %s", name)
+	}
+
+	// Validate the patterns.
+	for _, pattern := range set.Paths {
+		if !core.ValidIgnorePattern(pattern) {
+			return nil, errors.Errorf("invalid ignore pattern in ignore set %s: %s", name, pattern)
+		}
+	}
+
+	// Success.
+	return set.Paths, nil
+}
+
+// DeleteIgnoreSet removes the shared ignore set with the specified name.
+func DeleteIgnoreSet(name string) error {
+	// Validate the name.
+	if err := EnsureIgnoreSetNameValid(name); err != nil {
+		return errors.Wrap(err, "invalid ignore set name")
+	}
+
+	// Compute the ignore set path.
+	path, err := pathForIgnoreSet(name)
+	if err != nil {
+		return err
+	}
+
+	// Remove the ignore set.
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return errors.Errorf("undefined ignore set: %s", name)
+		}
+		return errors.Wrap(err, "unable to remove ignore set")
+	}
+
+	// Success.
+	return nil
+}
+
+// loadIgnoreSets loads and concatenates the patterns of the specified shared
+// ignore sets, preserving the order in which the sets are specified.
+func loadIgnoreSets(names []string) ([]string, error) {
+	var patterns []string
+	for _, name := range names {
+		if set, err := LoadIgnoreSet(name); err != nil {
+			return nil, err
+		} else {
+			patterns = append(patterns, set...)
+		}
+	}
+	return patterns, nil
+}
+
+// resolveIgnoreSets computes a copy of the specified configuration with any
+// shared ignore set references replaced by the patterns that they contain. The
+// patterns from ignore sets are placed before the configuration's own ignore
+// patterns so that the latter can refine (e.g. negate) the former. If the
+// configuration doesn't reference any ignore sets, then it is returned as-is.
+func resolveIgnoreSets(configuration *Configuration) (*Configuration, error) {
+	// If there aren't any ignore sets referenced, then there's nothing to do.
+	if len(configuration.IgnoreSets) == 0 {
+		return configuration, nil
+	}
+
+	// Load the referenced ignore sets.
+	patterns, err := loadIgnoreSets(configuration.IgnoreSets)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a shallow copy of the configuration with the ignore set
+	// references flattened into the ignore list.
+	result := MergeConfigurations(&Configuration{}, configuration)
+	result.Ignores = append(patterns, configuration.Ignores...)
+	result.IgnoreSets = nil
+
+	// Success.
+	return result, nil
+}
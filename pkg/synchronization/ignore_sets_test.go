@@ -0,0 +1,216 @@
+package synchronization
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// withTemporaryDataDirectory runs the specified test callback with the Mutagen
+// data directory redirected to a temporary directory.
+func withTemporaryDataDirectory(t *testing.T, callback func()) {
+	// Create a temporary directory and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_data_directory")
+	if err != nil {
+		t.Fatal("unable to create temporary data directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Redirect the data directory and defer restoration of the environment.
+	previous, previousSet := os.LookupEnv("MUTAGEN_DATA_DIRECTORY")
+	if err := os.Setenv("MUTAGEN_DATA_DIRECTORY", directory); err != nil {
+		t.Fatal("unable to set data directory environment variable:", err)
+	}
+	defer func() {
+		if previousSet {
+			os.Setenv("MUTAGEN_DATA_DIRECTORY", previous)
+		} else {
+			os.Unsetenv("MUTAGEN_DATA_DIRECTORY")
+		}
+	}()
+
+	// Invoke the callback.
+	callback()
+}
+
+// TestEnsureIgnoreSetNameValid tests EnsureIgnoreSetNameValid.
+func TestEnsureIgnoreSetNameValid(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		name        string
+		expectValid bool
+	}{
+		{"", false},
+		{"node", true},
+		{"build-outputs", true},
+		{"9lives", false},
+		{"with space", false},
+		{"../escape", false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		err := EnsureIgnoreSetNameValid(testCase.name)
+		if testCase.expectValid && err != nil {
+			t.Errorf("ignore set name \"%s\" unexpectedly classified as invalid: %v", testCase.name, err)
+		} else if !testCase.expectValid && err == nil {
+			t.Errorf("ignore set name \"%s\" unexpectedly classified as valid", testCase.name)
+		}
+	}
+}
+
+// TestIgnoreSetSaveLoadDelete tests the storage lifecycle of ignore sets.
+func TestIgnoreSetSaveLoadDelete(t *testing.T) {
+	withTemporaryDataDirectory(t, func() {
+		// Save an ignore set.
+		if err := SaveIgnoreSet("node", []string{"node_modules", "*.log"}); err != nil {
+			t.Fatal("unable to save ignore set:", err)
+		}
+
+		// Load the ignore set and verify its contents.
+		if patterns, err := LoadIgnoreSet("node"); err != nil {
+			t.Fatal("unable to load ignore set:", err)
+		} else if !stringSlicesEqual(patterns, []string{"node_modules", "*.log"}) {
+			t.Error("loaded ignore set patterns do not match expected:", patterns)
+		}
+
+		// Overwrite the ignore set and verify that the update is visible.
+		if err := SaveIgnoreSet("node", []string{"node_modules"}); err != nil {
+			t.Fatal("unable to overwrite ignore set:", err)
+		}
+		if patterns, err := LoadIgnoreSet("node"); err != nil {
+			t.Fatal("unable to load updated ignore set:", err)
+		} else if !stringSlicesEqual(patterns, []string{"node_modules"}) {
+			t.Error("updated ignore set patterns do not match expected:", patterns)
+		}
+
+		// Delete the ignore set and verify that it's no longer defined.
+		if err := DeleteIgnoreSet("node"); err != nil {
+			t.Fatal("unable to delete ignore set:", err)
+		}
+		if _, err := LoadIgnoreSet("node"); err == nil {
+			t.Error("deleted ignore set still loadable")
+		}
+		if err := DeleteIgnoreSet("node"); err == nil {
+			t.Error("deletion of undefined ignore set succeeded")
+		}
+	})
+}
+
+// TestSaveIgnoreSetInvalidPattern tests that SaveIgnoreSet rejects invalid
+// patterns.
+func TestSaveIgnoreSetInvalidPattern(t *testing.T) {
+	withTemporaryDataDirectory(t, func() {
+		if err := SaveIgnoreSet("broken", []string{"valid", "!"}); err == nil {
+			t.Error("ignore set with invalid pattern saved successfully")
+		}
+	})
+}
+
+// TestResolveIgnoreSets tests that ignore set references are flattened into
+// the ignore list, with set patterns (in reference order) preceding inline
+// patterns.
+func TestResolveIgnoreSets(t *testing.T) {
+	withTemporaryDataDirectory(t, func() {
+		// Create ignore sets.
+		if err := SaveIgnoreSet("node", []string{"node_modules"}); err != nil {
+			t.Fatal("unable to save ignore set:", err)
+		}
+		if err := SaveIgnoreSet("build", []string{"build", "dist"}); err != nil {
+			t.Fatal("unable to save ignore set:", err)
+		}
+
+		// Create a configuration referencing the sets with inline patterns.
+		configuration := &Configuration{
+			Ignores:    []string{"!dist/keep"},
+			IgnoreSets: []string{"node", "build"},
+		}
+
+		// Resolve the configuration.
+		resolved, err := resolveIgnoreSets(configuration)
+		if err != nil {
+			t.Fatal("unable to resolve ignore sets:", err)
+		}
+
+		// Verify the resolved ignores.
+		expected := []string{"node_modules", "build", "dist", "!dist/keep"}
+		if !stringSlicesEqual(resolved.Ignores, expected) {
+			t.Error("resolved ignores do not match expected:", resolved.Ignores, "!=", expected)
+		}
+		if len(resolved.IgnoreSets) != 0 {
+			t.Error("resolved configuration still references ignore sets")
+		}
+		if err := resolved.EnsureValid(false); err != nil {
+			t.Error("resolved configuration invalid:", err)
+		}
+
+		// Verify that the original configuration wasn't modified.
+		if !stringSlicesEqual(configuration.Ignores, []string{"!dist/keep"}) {
+			t.Error("original configuration ignores modified")
+		}
+		if !stringSlicesEqual(configuration.IgnoreSets, []string{"node", "build"}) {
+			t.Error("original configuration ignore sets modified")
+		}
+
+		// Edit a shared set and verify that resolution picks up the change.
+		if err := SaveIgnoreSet("node", []string{"node_modules", ".npm"}); err != nil {
+			t.Fatal("unable to update ignore set:", err)
+		}
+		resolved, err = resolveIgnoreSets(configuration)
+		if err != nil {
+			t.Fatal("unable to resolve ignore sets after update:", err)
+		}
+		expected = []string{"node_modules", ".npm", "build", "dist", "!dist/keep"}
+		if !stringSlicesEqual(resolved.Ignores, expected) {
+			t.Error("re-resolved ignores do not match expected:", resolved.Ignores, "!=", expected)
+		}
+	})
+}
+
+// TestResolveIgnoreSetsNoReferences tests that configurations without ignore
+// set references are returned unmodified.
+func TestResolveIgnoreSetsNoReferences(t *testing.T) {
+	configuration := &Configuration{Ignores: []string{"a"}}
+	if resolved, err := resolveIgnoreSets(configuration); err != nil {
+		t.Fatal("unable to resolve configuration:", err)
+	} else if resolved != configuration {
+		t.Error("configuration without ignore sets unexpectedly copied")
+	}
+}
+
+// TestResolveIgnoreSetsUndefined tests that references to undefined ignore
+// sets result in an error.
+func TestResolveIgnoreSetsUndefined(t *testing.T) {
+	withTemporaryDataDirectory(t, func() {
+		configuration := &Configuration{IgnoreSets: []string{"missing"}}
+		if _, err := resolveIgnoreSets(configuration); err == nil {
+			t.Error("resolution of undefined ignore set succeeded")
+		}
+	})
+}
+
+// TestConfigurationIgnoreSetValidation tests ignore set reference validation in
+// Configuration.EnsureValid.
+func TestConfigurationIgnoreSetValidation(t *testing.T) {
+	if err := (&Configuration{IgnoreSets: []string{"node"}}).EnsureValid(false); err != nil {
+		t.Error("valid ignore set reference rejected:", err)
+	}
+	if err := (&Configuration{IgnoreSets: []string{"node"}}).EnsureValid(true); err == nil {
+		t.Error("endpoint-specific ignore set reference accepted")
+	}
+	if err := (&Configuration{IgnoreSets: []string{""}}).EnsureValid(false); err == nil {
+		t.Error("empty ignore set reference accepted")
+	}
+}
+
+// TestMergeConfigurationsIgnoreSets tests that ignore set references are
+// concatenated when merging configurations.
+func TestMergeConfigurationsIgnoreSets(t *testing.T) {
+	merged := MergeConfigurations(
+		&Configuration{IgnoreSets: []string{"global"}},
+		&Configuration{IgnoreSets: []string{"session"}},
+	)
+	if !stringSlicesEqual(merged.IgnoreSets, []string{"global", "session"}) {
+		t.Error("merged ignore sets do not match expected:", merged.IgnoreSets)
+	}
+}
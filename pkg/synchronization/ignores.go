@@ -0,0 +1,75 @@
+package synchronization
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// effectiveIgnoreSources computes the ordered ignore sources that endpoints
+// apply for a session with the specified version and configuration, loading
+// any shared ignore sets that the configuration references. The sources are
+// ordered (and flattened by endpoints) exactly as they're applied during
+// scanning. Paths ignored due to Git ignore behavior aren't reflected, since
+// their evaluation requires access to the synchronization root.
+func effectiveIgnoreSources(version Version, configuration *Configuration) ([]*core.IgnoreSource, error) {
+	// Compute the effective VCS ignore mode.
+	ignoreVCSMode := configuration.IgnoreVCSMode
+	if ignoreVCSMode.IsDefault() {
+		ignoreVCSMode = version.DefaultIgnoreVCSMode()
+	}
+
+	// Add default VCS ignores, if any.
+	var sources []*core.IgnoreSource
+	if ignoreVCSMode == core.IgnoreVCSMode_IgnoreVCSModeIgnore {
+		sources = append(sources, &core.IgnoreSource{
+			Name:     "default VCS ignores",
+			Patterns: core.DefaultVCSIgnores,
+		})
+	}
+
+	// Add deprecated default ignores, if any.
+	if len(configuration.DefaultIgnores) > 0 {
+		sources = append(sources, &core.IgnoreSource{
+			Name:     "default ignores",
+			Patterns: configuration.DefaultIgnores,
+		})
+	}
+
+	// Add shared ignore sets, which precede the configuration's own ignores.
+	for _, name := range configuration.IgnoreSets {
+		patterns, err := LoadIgnoreSet(name)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, &core.IgnoreSource{
+			Name:     "ignore set " + name,
+			Patterns: patterns,
+		})
+	}
+
+	// Add the configuration's own ignores.
+	if len(configuration.Ignores) > 0 {
+		sources = append(sources, &core.IgnoreSource{
+			Name:     "session configuration",
+			Patterns: configuration.Ignores,
+		})
+	}
+
+	// Done.
+	return sources, nil
+}
+
+// explainIgnore determines whether or not the specified synchronization-root-
+// relative path is ignored by the session and identifies the pattern (and its
+// source) responsible for the decision.
+func (c *controller) explainIgnore(path string, directory bool) (*core.IgnoreDecision, error) {
+	// Compute the session's effective ignore sources.
+	sources, err := effectiveIgnoreSources(c.session.Version, c.session.Configuration)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to compute effective ignores")
+	}
+
+	// Evaluate the path.
+	return core.ExplainIgnore(sources, path, directory)
+}
@@ -0,0 +1,65 @@
+package synchronization
+
+import (
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// TestControllerExplainIgnore tests that ignore decisions are attributed to the
+// correct pattern and source across VCS ignores, shared ignore sets, and a
+// session's own ignores.
+func TestControllerExplainIgnore(t *testing.T) {
+	withTemporaryDataDirectory(t, func() {
+		// Create a shared ignore set.
+		if err := SaveIgnoreSet("node", []string{"node_modules/", "*.log"}); err != nil {
+			t.Fatal("unable to save ignore set:", err)
+		}
+
+		// Create a controller for a session with layered ignores.
+		c := &controller{session: &Session{
+			Version: Version_Version1,
+			Configuration: &Configuration{
+				IgnoreVCSMode: core.IgnoreVCSMode_IgnoreVCSModeIgnore,
+				IgnoreSets:    []string{"node"},
+				Ignores:       []string{"!keep.log", "*.bak"},
+			},
+		}}
+
+		// Define test cases.
+		testCases := []struct {
+			path            string
+			directory       bool
+			expectedIgnored bool
+			expectedPattern string
+			expectedSource  string
+		}{
+			{"src/main.js", false, false, "", ""},
+			{".git", true, true, ".git/", "default VCS ignores"},
+			{"node_modules/left-pad/index.js", false, true, "node_modules/", "ignore set node"},
+			{"debug.log", false, true, "*.log", "ignore set node"},
+			{"keep.log", false, false, "!keep.log", "session configuration"},
+			{"data.bak", false, true, "*.bak", "session configuration"},
+		}
+
+		// Process test cases.
+		for _, testCase := range testCases {
+			decision, err := c.explainIgnore(testCase.path, testCase.directory)
+			if err != nil {
+				t.Errorf("unable to explain ignore status for \"%s\": %v", testCase.path, err)
+			} else if decision.Ignored != testCase.expectedIgnored {
+				t.Errorf("ignore status for \"%s\" incorrect: %t", testCase.path, decision.Ignored)
+			} else if decision.Pattern != testCase.expectedPattern {
+				t.Errorf("pattern for \"%s\" incorrect: \"%s\"", testCase.path, decision.Pattern)
+			} else if decision.Source != testCase.expectedSource {
+				t.Errorf("source for \"%s\" incorrect: \"%s\"", testCase.path, decision.Source)
+			}
+		}
+
+		// Verify that undefined ignore sets are reported.
+		c.session.Configuration.IgnoreSets = []string{"undefined"}
+		if _, err := c.explainIgnore("debug.log", false); err == nil {
+			t.Error("undefined ignore set not reported")
+		}
+	})
+}
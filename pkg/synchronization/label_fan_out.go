@@ -0,0 +1,230 @@
+package synchronization
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/mutagen-io/mutagen/pkg/docker"
+	"github.com/mutagen-io/mutagen/pkg/environment"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// additionalBetaMemberEndpointSession computes the session identifier to
+// provide to the endpoint for a container matched by the label selector of the
+// additional beta endpoint with the specified index.
+func additionalBetaMemberEndpointSession(session string, index int, member string) string {
+	return additionalBetaEndpointSession(session, index) + "-" + member
+}
+
+// resolveDockerLabelSelector determines the names of the containers currently
+// matched by the label selector of a Docker URL. The URL's Docker environment
+// variables and daemon connection parameters are used for the query, just as
+// they would be for a container-specific URL.
+func resolveDockerLabelSelector(u *url.URL, selector string) ([]string, error) {
+	// Compute daemon connection flags.
+	daemonFlags, err := docker.LoadDaemonConnectionFlagsFromURLParameters(u.Parameters)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to compute Docker daemon connection flags")
+	}
+
+	// Compute the environment, locking in any Docker environment variables
+	// stored in the URL and removing any that weren't.
+	env := environment.ToMap(os.Environ())
+	for _, variable := range url.DockerEnvironmentVariables {
+		if value, ok := u.Environment[variable]; ok {
+			env[variable] = value
+		} else {
+			delete(env, variable)
+		}
+	}
+
+	// Query matching containers.
+	return docker.ContainersMatchingLabels(*daemonFlags, env, selector)
+}
+
+// labelMember tracks a container matched by a label selector.
+type labelMember struct {
+	// url is the container-specific URL for the member.
+	url *url.URL
+	// endpoint is the member's endpoint, if connected.
+	endpoint Endpoint
+	// lastConnectAttempt is the time of the last connection attempt.
+	lastConnectAttempt time.Time
+	// problems are the transition problems from the member's last successful
+	// synchronization cycle.
+	problems []*core.Problem
+	// err is the error from the member's last synchronization cycle, if any.
+	err error
+}
+
+// labelMembership tracks the set of containers matched by the label selector
+// of an additional beta endpoint. It is not safe for concurrent usage.
+type labelMembership struct {
+	// members maps container names to their members.
+	members map[string]*labelMember
+}
+
+// newLabelMembership creates a new empty label membership.
+func newLabelMembership() *labelMembership {
+	return &labelMembership{members: make(map[string]*labelMember)}
+}
+
+// names returns the sorted names of the current members.
+func (m *labelMembership) names() []string {
+	names := make([]string, 0, len(m.members))
+	for name := range m.members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// update updates the membership to contain exactly the specified containers,
+// creating container-specific URLs for new members based on the selector URL.
+// Endpoints for members that are no longer present are shut down. The names of
+// removed members are returned.
+func (m *labelMembership) update(selectorURL *url.URL, names []string) []string {
+	// Add new members.
+	current := make(map[string]bool, len(names))
+	for _, name := range names {
+		current[name] = true
+		if _, ok := m.members[name]; !ok {
+			memberURL := proto.Clone(selectorURL).(*url.URL)
+			memberURL.Host = name
+			m.members[name] = &labelMember{url: memberURL}
+		}
+	}
+
+	// Remove any members that are no longer present.
+	var removed []string
+	for name, member := range m.members {
+		if current[name] {
+			continue
+		}
+		if member.endpoint != nil {
+			member.endpoint.Shutdown()
+		}
+		delete(m.members, name)
+		removed = append(removed, name)
+	}
+	sort.Strings(removed)
+
+	// Done.
+	return removed
+}
+
+// shutdown shuts down the endpoints for all members.
+func (m *labelMembership) shutdown() {
+	for _, member := range m.members {
+		if member.endpoint != nil {
+			member.endpoint.Shutdown()
+			member.endpoint = nil
+		}
+	}
+}
+
+// synchronizeLabelSelectorBeta performs a synchronization cycle for the
+// additional beta endpoint with the specified index, whose URL specifies a
+// Docker label selector. The selector is re-resolved on every cycle, with newly
+// matched containers being added to the membership (and synchronized from
+// scratch) and containers that no longer match being disconnected and having
+// their archives removed. Members are synchronized in parallel and their
+// results are aggregated into the endpoint's state.
+func (c *controller) synchronizeLabelSelectorBeta(
+	ctx, stopCtx context.Context,
+	logger *logging.Logger,
+	index int,
+	selector string,
+	cycle *fanOutCycle,
+	membership *labelMembership,
+) {
+	// Resolve the selector. If resolution fails, then we leave the membership
+	// intact and try again on the next cycle.
+	selectorURL := c.session.AdditionalBetas[index]
+	names, err := resolveDockerLabelSelector(selectorURL, selector)
+	if err != nil {
+		logger.Warning("Label selector resolution failure:", err)
+		c.updateAdditionalBetaState(index, false, membership.names(), nil, errors.Wrap(err, "unable to resolve label selector"))
+		return
+	}
+
+	// Update the membership and remove archives for removed members so that
+	// they start from scratch if they're matched again.
+	for _, name := range membership.update(selectorURL, names) {
+		logger.Info("Removing container no longer matched by label selector:", name)
+		archivePath := pathForAdditionalMemberArchive(c.archivePath, index, name)
+		if err := os.Remove(archivePath); err != nil && !os.IsNotExist(err) {
+			logger.Warning("Unable to remove archive for removed container:", err)
+		}
+	}
+
+	// Synchronize each member in a separate Goroutine. Members whose
+	// connection attempts are rate limited retain their previous results.
+	done := &sync.WaitGroup{}
+	for name, member := range membership.members {
+		done.Add(1)
+		go func(name string, member *labelMember) {
+			defer done.Done()
+			attempted, problems, err := c.synchronizeAdditionalBetaEndpoint(
+				ctx, stopCtx,
+				logger.Sublogger(name),
+				member.url,
+				additionalBetaMemberEndpointSession(c.session.Identifier, index, name),
+				pathForAdditionalMemberArchive(c.archivePath, index, name),
+				cycle,
+				&member.endpoint,
+				&member.lastConnectAttempt,
+			)
+			if !attempted {
+				return
+			}
+			if err != nil {
+				member.err = err
+			} else {
+				member.err = nil
+				member.problems = problems
+			}
+		}(name, member)
+	}
+	done.Wait()
+
+	// Aggregate member results. The endpoint is considered connected if all of
+	// its members are connected.
+	memberNames := membership.names()
+	connected := true
+	var problems []*core.Problem
+	var memberErrors []string
+	for _, name := range memberNames {
+		member := membership.members[name]
+		if member.endpoint == nil {
+			connected = false
+		}
+		for _, problem := range member.problems {
+			problems = append(problems, &core.Problem{
+				Path:  problem.Path,
+				Error: fmt.Sprintf("%s: %s", name, problem.Error),
+			})
+		}
+		if member.err != nil {
+			memberErrors = append(memberErrors, fmt.Sprintf("%s: %v", name, member.err))
+		}
+	}
+	var aggregateErr error
+	if len(memberErrors) > 0 {
+		aggregateErr = errors.New(strings.Join(memberErrors, "; "))
+	}
+
+	// Record the result.
+	c.updateAdditionalBetaState(index, connected, memberNames, problems, aggregateErr)
+}
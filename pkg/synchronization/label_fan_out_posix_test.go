@@ -0,0 +1,178 @@
+// +build !windows
+
+package synchronization
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// testShutdownTrackingEndpoint is an endpoint that records whether or not it
+// has been shut down. Only its Shutdown method may be invoked.
+type testShutdownTrackingEndpoint struct {
+	Endpoint
+	// shutdown indicates whether or not the endpoint has been shut down.
+	shutdown bool
+}
+
+// Shutdown implements Endpoint.Shutdown.
+func (e *testShutdownTrackingEndpoint) Shutdown() error {
+	e.shutdown = true
+	return nil
+}
+
+// TestLabelMembershipDynamicMembership tests that label selector resolution
+// and membership tracking follow a changing set of matching containers.
+func TestLabelMembershipDynamicMembership(t *testing.T) {
+	// Create a temporary directory to hold a fake docker command and the list
+	// of containers that it reports, and defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_label_fan_out")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+
+	// Create the fake docker command. It verifies that the label filter was
+	// passed through before reporting the current container list.
+	containers := filepath.Join(directory, "containers")
+	script := "#!/bin/sh\n" +
+		"case \"$*\" in *'--filter label=app=web'*) ;; *) exit 1 ;; esac\n" +
+		"cat '" + containers + "'\n"
+	if err := ioutil.WriteFile(filepath.Join(directory, "docker"), []byte(script), 0700); err != nil {
+		t.Fatal("unable to write fake docker command:", err)
+	}
+
+	// Point Docker command resolution at the fake command.
+	previous, hadPrevious := os.LookupEnv("MUTAGEN_DOCKER_PATH")
+	os.Setenv("MUTAGEN_DOCKER_PATH", directory)
+	defer func() {
+		if hadPrevious {
+			os.Setenv("MUTAGEN_DOCKER_PATH", previous)
+		} else {
+			os.Unsetenv("MUTAGEN_DOCKER_PATH")
+		}
+	}()
+
+	// Create the selector URL.
+	selectorURL, err := url.Parse("docker://label:app=web/code", url.Kind_Synchronization, false)
+	if err != nil {
+		t.Fatal("unable to parse selector URL:", err)
+	}
+	selector, ok := selectorURL.DockerLabelSelector()
+	if !ok {
+		t.Fatal("selector URL not recognized as label selector")
+	}
+
+	// Create the membership.
+	membership := newLabelMembership()
+	endpoints := make(map[string]*testShutdownTrackingEndpoint)
+
+	// Define the membership transitions to test.
+	steps := []struct {
+		reported string
+		expected []string
+		removed  []string
+	}{
+		{"a\nb\nb\n", []string{"a", "b"}, nil},
+		{"c\nb\n", []string{"b", "c"}, []string{"a"}},
+		{"", []string{}, []string{"b", "c"}},
+	}
+
+	// Process each transition.
+	for s, step := range steps {
+		// Update the reported containers.
+		if err := ioutil.WriteFile(containers, []byte(step.reported), 0600); err != nil {
+			t.Fatal("unable to write container list:", err)
+		}
+
+		// Resolve the selector and update the membership.
+		names, err := resolveDockerLabelSelector(selectorURL, selector)
+		if err != nil {
+			t.Fatalf("step %d: unable to resolve label selector: %v", s, err)
+		}
+		removed := membership.update(selectorURL, names)
+
+		// Verify membership.
+		if members := membership.names(); !reflect.DeepEqual(members, step.expected) {
+			t.Errorf("step %d: membership mismatch: %v != %v", s, members, step.expected)
+		}
+		if !reflect.DeepEqual(removed, step.removed) {
+			t.Errorf("step %d: removed members mismatch: %v != %v", s, removed, step.removed)
+		}
+
+		// Verify that removed members were shut down.
+		for _, name := range removed {
+			if !endpoints[name].shutdown {
+				t.Errorf("step %d: endpoint for removed member %s not shut down", s, name)
+			}
+		}
+
+		// Verify that members have container-specific URLs and that retained
+		// members keep their endpoints. Simulate connections for new members.
+		for name, member := range membership.members {
+			if member.url.Host != name || member.url.Path != selectorURL.Path {
+				t.Errorf("step %d: incorrect URL for member %s: %s", s, name, member.url.Format(""))
+			}
+			if _, ok := member.url.DockerLabelSelector(); ok {
+				t.Errorf("step %d: member URL uses label selector", s)
+			}
+			if existing, ok := endpoints[name]; ok {
+				if member.endpoint != existing {
+					t.Errorf("step %d: endpoint for retained member %s not preserved", s, name)
+				} else if existing.shutdown {
+					t.Errorf("step %d: endpoint for retained member %s shut down", s, name)
+				}
+			} else {
+				endpoints[name] = &testShutdownTrackingEndpoint{}
+				member.endpoint = endpoints[name]
+			}
+		}
+	}
+
+	// Verify that the selector URL wasn't modified.
+	if selectorURL.Host != url.DockerLabelSelectorPrefix+"app=web" {
+		t.Error("selector URL modified:", selectorURL.Host)
+	}
+}
+
+// TestResolveDockerLabelSelectorFailure tests that label selector resolution
+// fails if the docker command fails.
+func TestResolveDockerLabelSelectorFailure(t *testing.T) {
+	// Create a temporary directory to hold a failing fake docker command and
+	// defer its removal.
+	directory, err := ioutil.TempDir("", "mutagen_label_fan_out")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(directory)
+	if err := ioutil.WriteFile(filepath.Join(directory, "docker"), []byte("#!/bin/sh\nexit 1\n"), 0700); err != nil {
+		t.Fatal("unable to write fake docker command:", err)
+	}
+
+	// Point Docker command resolution at the fake command.
+	previous, hadPrevious := os.LookupEnv("MUTAGEN_DOCKER_PATH")
+	os.Setenv("MUTAGEN_DOCKER_PATH", directory)
+	defer func() {
+		if hadPrevious {
+			os.Setenv("MUTAGEN_DOCKER_PATH", previous)
+		} else {
+			os.Unsetenv("MUTAGEN_DOCKER_PATH")
+		}
+	}()
+
+	// Attempt resolution.
+	selectorURL := &url.URL{
+		Kind:     url.Kind_Synchronization,
+		Protocol: url.Protocol_Docker,
+		Host:     url.DockerLabelSelectorPrefix + "app=web",
+		Path:     "/code",
+	}
+	if _, err := resolveDockerLabelSelector(selectorURL, "app=web"); err == nil {
+		t.Error("label selector resolution succeeded with failing docker command")
+	}
+}
@@ -2,18 +2,30 @@ package synchronization
 
 import (
 	"context"
+	"io"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/mutagen-io/mutagen/pkg/filesystem"
 	"github.com/mutagen-io/mutagen/pkg/identifier"
 	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/notification"
 	"github.com/mutagen-io/mutagen/pkg/selection"
 	"github.com/mutagen-io/mutagen/pkg/state"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
 	"github.com/mutagen-io/mutagen/pkg/url"
 )
 
+const (
+	// gracefulShutdownTimeout is the maximum amount of time that the manager
+	// will wait for sessions to complete in-flight synchronization cycles when
+	// shutting down.
+	gracefulShutdownTimeout = 10 * time.Second
+)
+
 // Manager provides synchronization session management facilities. Its methods
 // are safe for concurrent usage, so it can be easily exported via an RPC
 // interface.
@@ -26,10 +38,32 @@ type Manager struct {
 	sessionsLock *state.TrackingLock
 	// sessions maps sessions to their respective controllers.
 	sessions map[string]*controller
+	// notifier is the notifier used to report session conditions. It may be
+	// nil.
+	notifier *notification.Notifier
+	// maximumActiveSessions is the maximum number of sessions that may be
+	// active (i.e. unpaused) at any given time. If 0, then there is no limit.
+	// It is static after initialization.
+	maximumActiveSessions uint64
+	// admissionLock serializes admission decisions and guards the admitting
+	// and queue members.
+	admissionLock sync.Mutex
+	// admitting is the number of sessions that have been reserved slots and
+	// are in the process of being started.
+	admitting uint64
+	// queue is the list of identifiers for sessions awaiting admission, in the
+	// order in which they were queued. It may contain stale entries for
+	// sessions that are no longer awaiting admission, which are pruned before
+	// the queue is used.
+	queue []string
 }
 
-// NewManager creates a new Manager instance.
-func NewManager(logger *logging.Logger) (*Manager, error) {
+// NewManager creates a new Manager instance. If a notifier is provided, then it
+// will be used to report sessions entering conflicted or errored conditions. If
+// maximumActiveSessions is non-zero, then it limits the number of sessions that
+// may be active at any given time, with sessions beyond the limit queued until
+// active sessions are paused or terminated.
+func NewManager(logger *logging.Logger, notifier *notification.Notifier, maximumActiveSessions uint64) (*Manager, error) {
 	// Create a tracker and corresponding lock to watch for state changes.
 	tracker := state.NewTracker()
 	sessionsLock := state.NewTrackingLock(tracker)
@@ -37,6 +71,20 @@ func NewManager(logger *logging.Logger) (*Manager, error) {
 	// Create the session registry.
 	sessions := make(map[string]*controller)
 
+	// Migrate persisted sessions to the current data format. If migration
+	// fails, then the original files will have been restored, and they remain
+	// loadable, so we just log the failure.
+	if report, err := MigrateDataDirectory(logger.Sublogger("migration")); err != nil {
+		logger.Warning("Unable to migrate data directory:", err)
+	} else if report.Format != report.PreviousFormat {
+		logger.Infof("Migrated data directory from format %d to %d (%d sessions, %d archives)",
+			report.PreviousFormat, report.Format, len(report.Sessions), len(report.Archives),
+		)
+		if report.BackupPath != "" {
+			logger.Info("Backup of migrated files stored at", report.BackupPath)
+		}
+	}
+
 	// Load existing sessions.
 	logger.Info("Looking for existing sessions")
 	sessionsDirectory, err := pathForSession("")
@@ -57,14 +105,45 @@ func NewManager(logger *logging.Logger) (*Manager, error) {
 		}
 	}
 
+	// Create the manager.
+	manager := &Manager{
+		logger:                logger,
+		tracker:               tracker,
+		sessionsLock:          sessionsLock,
+		sessions:              sessions,
+		notifier:              notifier,
+		maximumActiveSessions: maximumActiveSessions,
+	}
+
+	// Restore the admission queue from sessions that were awaiting admission,
+	// ordering them by creation time.
+	for _, controller := range sessions {
+		if controller.queued() {
+			manager.queue = append(manager.queue, controller.session.Identifier)
+		}
+	}
+	sort.Slice(manager.queue, func(i, j int) bool {
+		iTime := sessions[manager.queue[i]].session.CreationTime
+		jTime := sessions[manager.queue[j]].session.CreationTime
+		return iTime.Seconds < jTime.Seconds ||
+			(iTime.Seconds == jTime.Seconds && iTime.Nanos < jTime.Nanos)
+	})
+
+	// Start notification monitoring if necessary.
+	if notifier != nil {
+		go manager.monitorNotifications()
+	}
+
+	// Start admission monitoring if necessary. If there's no limit on active
+	// sessions, then any sessions restored to the admission queue are admitted
+	// immediately by the initial pass.
+	if maximumActiveSessions > 0 || len(manager.queue) > 0 {
+		go manager.monitorAdmissions()
+	}
+
 	// Success.
 	logger.Info("Session manager initialized")
-	return &Manager{
-		logger:       logger,
-		tracker:      tracker,
-		sessionsLock: sessionsLock,
-		sessions:     sessions,
-	}, nil
+	return manager, nil
 }
 
 // allControllers creates a list of all controllers managed by the manager.
@@ -159,7 +238,9 @@ func (m *Manager) selectControllers(selection *selection.Selection) ([]*controll
 	}
 }
 
-// Shutdown tells the manager to gracefully halt sessions.
+// Shutdown tells the manager to gracefully halt sessions. Sessions are given
+// until gracefulShutdownTimeout elapses to complete any in-flight
+// synchronization cycles, after which they're halted abruptly.
 func (m *Manager) Shutdown() {
 	// Log the shutdown.
 	m.logger.Info("Shutting down")
@@ -171,20 +252,32 @@ func (m *Manager) Shutdown() {
 	m.sessionsLock.Lock()
 	defer m.sessionsLock.UnlockWithoutNotify()
 
-	// Attempt to halt each session so that it can shutdown cleanly. Ignore but
-	// log any that fail to halt.
-	for _, controller := range m.sessions {
-		m.logger.Info("Halting session", controller.session.Identifier)
-		if err := controller.halt(context.Background(), controllerHaltModeShutdown, "", false); err != nil {
-			// TODO: Log this halt failure.
-		}
+	// Create a context to bound the graceful shutdown period.
+	ctx, cancel := context.WithTimeout(context.Background(), gracefulShutdownTimeout)
+	defer cancel()
+
+	// Attempt to halt each session so that it can shutdown cleanly. We halt
+	// sessions concurrently so that they share the graceful shutdown period.
+	// Ignore but log any that fail to halt.
+	halted := &sync.WaitGroup{}
+	for _, c := range m.sessions {
+		m.logger.Info("Halting session", c.session.Identifier)
+		halted.Add(1)
+		go func(c *controller) {
+			if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+				m.logger.Warningf("Unable to halt session %s: %v", c.session.Identifier, err)
+			}
+			halted.Done()
+		}(c)
 	}
+	halted.Wait()
 }
 
 // Create tells the manager to create a new session.
 func (m *Manager) Create(
 	ctx context.Context,
 	alpha, beta *url.URL,
+	additionalBetas []*url.URL,
 	configuration, configurationAlpha, configurationBeta *Configuration,
 	name string,
 	labels map[string]string,
@@ -197,6 +290,19 @@ func (m *Manager) Create(
 		return "", errors.Wrap(err, "unable to generate identifier for session")
 	}
 
+	// If the session isn't being created paused, then determine whether or not
+	// it can be admitted immediately. If not, then it's created paused and
+	// queued awaiting admission.
+	var queue bool
+	if !paused {
+		if m.reserve() {
+			defer m.release()
+		} else {
+			queue = true
+			paused = true
+		}
+	}
+
 	// Attempt to create a session.
 	controller, err := newSession(
 		ctx,
@@ -204,6 +310,7 @@ func (m *Manager) Create(
 		m.tracker,
 		identifier,
 		alpha, beta,
+		additionalBetas,
 		configuration, configurationAlpha, configurationBeta,
 		name,
 		labels,
@@ -219,6 +326,16 @@ func (m *Manager) Create(
 	m.sessions[controller.session.Identifier] = controller
 	m.sessionsLock.Unlock()
 
+	// Queue the session awaiting admission, if necessary. Since sessions may
+	// have been paused or terminated in the mean time, we also attempt to
+	// admit queued sessions.
+	if queue {
+		if err := m.enqueue(controller); err != nil {
+			return "", errors.Wrap(err, "unable to queue session")
+		}
+		m.admitQueuedSessions(ctx)
+	}
+
 	// Done.
 	return controller.session.Identifier, nil
 }
@@ -241,10 +358,13 @@ func (m *Manager) List(_ context.Context, selection *selection.Selection, previo
 		return 0, nil, errors.Wrap(err, "unable to locate requested sessions")
 	}
 
-	// Extract the state from each controller.
+	// Extract the state from each controller, recording the admission queue
+	// position of any queued sessions.
+	positions := m.queuePositions()
 	states := make([]*State, len(controllers))
 	for i, controller := range controllers {
 		states[i] = controller.currentState()
+		states[i].QueuePosition = positions[controller.session.Identifier]
 	}
 
 	// Sort session states by session creation time.
@@ -260,7 +380,11 @@ func (m *Manager) List(_ context.Context, selection *selection.Selection, previo
 }
 
 // Flush tells the manager to flush sessions matching the given specifications.
-func (m *Manager) Flush(ctx context.Context, selection *selection.Selection, prompter string, skipWait bool) error {
+// If ignore overrides are specified, then they are applied as temporary include
+// patterns for the forced synchronization cycle only. If rehash is specified,
+// then the sessions' endpoints discard their digest caches and recompute the
+// digests of all files during the forced synchronization cycle.
+func (m *Manager) Flush(ctx context.Context, selection *selection.Selection, prompter string, skipWait bool, ignoreOverrides []string, rehash bool) error {
 	// Extract the controllers for the sessions of interest.
 	controllers, err := m.selectControllers(selection)
 	if err != nil {
@@ -269,7 +393,7 @@ func (m *Manager) Flush(ctx context.Context, selection *selection.Selection, pro
 
 	// Attempt to flush the sessions.
 	for _, controller := range controllers {
-		if err := controller.flush(ctx, prompter, skipWait); err != nil {
+		if err := controller.flush(ctx, prompter, skipWait, ignoreOverrides, rehash); err != nil {
 			return errors.Wrap(err, "unable to flush session")
 		}
 	}
@@ -278,6 +402,88 @@ func (m *Manager) Flush(ctx context.Context, selection *selection.Selection, pro
 	return nil
 }
 
+// Undo tells the manager to undo the last synchronization cycle for sessions
+// matching the given specifications.
+func (m *Manager) Undo(ctx context.Context, selection *selection.Selection, prompter string) error {
+	// Extract the controllers for the sessions of interest.
+	controllers, err := m.selectControllers(selection)
+	if err != nil {
+		return errors.Wrap(err, "unable to locate requested sessions")
+	}
+
+	// Attempt to undo the last synchronization cycle for the sessions.
+	for _, controller := range controllers {
+		if err := controller.undo(ctx, prompter); err != nil {
+			return errors.Wrap(err, "unable to undo last synchronization cycle")
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// RetryQuarantined tells the manager to release the quarantined paths of
+// sessions matching the given specifications so that they're retried by the
+// sessions' next synchronization cycles. It doesn't itself force a
+// synchronization cycle.
+func (m *Manager) RetryQuarantined(selection *selection.Selection) error {
+	// Extract the controllers for the sessions of interest.
+	controllers, err := m.selectControllers(selection)
+	if err != nil {
+		return errors.Wrap(err, "unable to locate requested sessions")
+	}
+
+	// Release the quarantined paths for the sessions.
+	for _, controller := range controllers {
+		controller.retryQuarantined()
+	}
+
+	// Success.
+	return nil
+}
+
+// ExplainIgnore reports whether or not the specified synchronization-root-
+// relative path is ignored by the session with the specified specification, as
+// well as which pattern (and which source of patterns) determined the decision.
+func (m *Manager) ExplainIgnore(specification, path string, directory bool) (*core.IgnoreDecision, error) {
+	// Extract the controller for the session of interest.
+	controllers, err := m.findControllersBySpecification([]string{specification})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to locate requested session")
+	} else if len(controllers) != 1 {
+		return nil, errors.Errorf("specification \"%s\" matched multiple sessions", specification)
+	}
+
+	// Evaluate the path.
+	decision, err := controllers[0].explainIgnore(path, directory)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to evaluate ignores")
+	}
+
+	// Success.
+	return decision, nil
+}
+
+// ExplainActivity explains why the session with the specified specification is
+// not idle, identifying the conditions (such as staging, conflicts, or
+// repeatedly changing paths) that are keeping it busy. The explanation is
+// computed from the session's current state and recent synchronization history,
+// so it's cheap to compute on demand.
+func (m *Manager) ExplainActivity(specification string) (*ActivityExplanation, error) {
+	// Extract the controller for the session of interest.
+	controllers, err := m.findControllersBySpecification([]string{specification})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to locate requested session")
+	} else if len(controllers) != 1 {
+		return nil, errors.Errorf("specification \"%s\" matched multiple sessions", specification)
+	}
+
+	// Explain the session's activity.
+	controller := controllers[0]
+	queuePosition := m.queuePositions()[controller.session.Identifier]
+	return controller.explainActivity(queuePosition, time.Now()), nil
+}
+
 // Pause tells the manager to pause sessions matching the given specifications.
 func (m *Manager) Pause(ctx context.Context, selection *selection.Selection, prompter string) error {
 	// Extract the controllers for the sessions of interest.
@@ -286,7 +492,10 @@ func (m *Manager) Pause(ctx context.Context, selection *selection.Selection, pro
 		return errors.Wrap(err, "unable to locate requested sessions")
 	}
 
-	// Attempt to pause the sessions.
+	// Attempt to pause the sessions. Paused sessions free up capacity for
+	// queued sessions, so we attempt to admit queued sessions afterward
+	// (regardless of whether or not all sessions were paused successfully).
+	defer m.admitQueuedSessions(ctx)
 	for _, controller := range controllers {
 		if err := controller.halt(ctx, controllerHaltModePause, prompter, false); err != nil {
 			return errors.Wrap(err, "unable to pause session")
@@ -298,18 +507,34 @@ func (m *Manager) Pause(ctx context.Context, selection *selection.Selection, pro
 }
 
 // Resume tells the manager to resume sessions matching the given
-// specifications.
-func (m *Manager) Resume(ctx context.Context, selection *selection.Selection, prompter string) error {
+// specifications, optionally switching them into or out of observation mode.
+func (m *Manager) Resume(ctx context.Context, selection *selection.Selection, prompter string, observationModeChange ObservationModeChange) error {
 	// Extract the controllers for the sessions of interest.
 	controllers, err := m.selectControllers(selection)
 	if err != nil {
 		return errors.Wrap(err, "unable to locate requested sessions")
 	}
 
-	// Attempt to resume.
+	// Attempt to resume, applying any observation mode change beforehand so
+	// that newly started synchronization loops observe it from their first
+	// synchronization cycle. Paused sessions that can't be admitted due to the
+	// limit on active sessions are queued awaiting admission instead.
 	for _, controller := range controllers {
-		if err := controller.resume(ctx, prompter, false); err != nil {
-			return errors.Wrap(err, "unable to resume session")
+		if err := controller.setObservationMode(observationModeChange); err != nil {
+			return errors.Wrap(err, "unable to change observation mode")
+		}
+		if controller.active() {
+			if err := controller.resume(ctx, prompter, false); err != nil {
+				return errors.Wrap(err, "unable to resume session")
+			}
+		} else if m.reserve() {
+			err := controller.resume(ctx, prompter, false)
+			m.release()
+			if err != nil {
+				return errors.Wrap(err, "unable to resume session")
+			}
+		} else if err := m.enqueue(controller); err != nil {
+			return errors.Wrap(err, "unable to queue session")
 		}
 	}
 
@@ -347,7 +572,10 @@ func (m *Manager) Terminate(ctx context.Context, selection *selection.Selection,
 	}
 
 	// Attempt to terminate the sessions. Since we're terminating them, we're
-	// responsible for removing them from the session map.
+	// responsible for removing them from the session map. Terminated sessions
+	// free up capacity for queued sessions, so we attempt to admit queued
+	// sessions afterward.
+	defer m.admitQueuedSessions(ctx)
 	for _, controller := range controllers {
 		if err := controller.halt(ctx, controllerHaltModeTerminate, prompter, false); err != nil {
 			return errors.Wrap(err, "unable to terminate session")
@@ -360,3 +588,132 @@ func (m *Manager) Terminate(ctx context.Context, selection *selection.Selection,
 	// Success.
 	return nil
 }
+
+// Relocate tells the manager to relocate the alpha or beta endpoint of the
+// paused session matching the given specification. It returns a warning if the
+// new location diverges from the session's synchronization history.
+func (m *Manager) Relocate(ctx context.Context, specification string, beta bool, target *url.URL, prompter string) (string, error) {
+	// Extract the controller for the session of interest.
+	controllers, err := m.findControllersBySpecification([]string{specification})
+	if err != nil {
+		return "", errors.Wrap(err, "unable to locate requested session")
+	} else if len(controllers) != 1 {
+		return "", errors.Errorf("specification \"%s\" matched multiple sessions", specification)
+	}
+
+	// Attempt to relocate the session.
+	warning, err := controllers[0].relocate(ctx, beta, target, prompter)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to relocate session")
+	}
+
+	// Success.
+	return warning, nil
+}
+
+// Reconnect tells the manager to force an immediate connection attempt for the
+// session matching the given specification, bypassing any remaining wait
+// before its next automatic reconnection attempt. The session must be waiting
+// to reconnect. It returns the session state after the connection attempt.
+func (m *Manager) Reconnect(ctx context.Context, specification string, prompter string) (*State, error) {
+	// Extract the controller for the session of interest.
+	controllers, err := m.findControllersBySpecification([]string{specification})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to locate requested session")
+	} else if len(controllers) != 1 {
+		return nil, errors.Errorf("specification \"%s\" matched multiple sessions", specification)
+	}
+
+	// Attempt to reconnect the session.
+	state, err := controllers[0].reconnect(ctx, prompter)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to reconnect session")
+	}
+
+	// Success.
+	return state, nil
+}
+
+// ListStaged lists the content staged (but not yet committed) by the alpha and
+// beta endpoints of the session matching the given specification. If verify is
+// true, then the content of each staged file is compared against its recorded
+// digest. Listing is read-only and safe to perform while the session is
+// synchronizing. Endpoints whose protocol doesn't support listing staged
+// content are reported as unlisted.
+func (m *Manager) ListStaged(specification string, verify bool) (*StagedContent, *StagedContent, error) {
+	// Extract the controller for the session of interest.
+	controllers, err := m.findControllersBySpecification([]string{specification})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to locate requested session")
+	} else if len(controllers) != 1 {
+		return nil, nil, errors.Errorf("specification \"%s\" matched multiple sessions", specification)
+	}
+
+	// Perform listing.
+	return controllers[0].listStaged(verify)
+}
+
+// Export scans the alpha or beta endpoint of the session matching the given
+// specification and streams its content to the specified writer as an archive
+// in the specified format. Only local endpoints are supported.
+func (m *Manager) Export(ctx context.Context, specification string, beta bool, format core.ExportFormat, writer io.Writer) error {
+	// Extract the controller for the session of interest.
+	controllers, err := m.findControllersBySpecification([]string{specification})
+	if err != nil {
+		return errors.Wrap(err, "unable to locate requested session")
+	} else if len(controllers) != 1 {
+		return errors.Errorf("specification \"%s\" matched multiple sessions", specification)
+	}
+
+	// Perform the export.
+	return controllers[0].export(ctx, beta, format, writer)
+}
+
+// DiffSnapshots computes a structured diff describing the changes made on the
+// alpha or beta endpoint of the session matching the given specification since
+// the session's last synchronization cycle. If detectMoves is true, then
+// removed and added files with identical digests are reported as moves.
+func (m *Manager) DiffSnapshots(ctx context.Context, specification string, beta, detectMoves bool, prompter string) (*core.SnapshotDiff, error) {
+	// Extract the controller for the session of interest.
+	controllers, err := m.findControllersBySpecification([]string{specification})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to locate requested session")
+	} else if len(controllers) != 1 {
+		return nil, errors.Errorf("specification \"%s\" matched multiple sessions", specification)
+	}
+
+	// Perform diffing.
+	return controllers[0].diffSnapshots(ctx, beta, detectMoves, prompter)
+}
+
+// Compare connects to and scans the specified endpoints and reports the
+// divergence between their contents without synchronizing them. No session is
+// created and neither endpoint is modified.
+func (m *Manager) Compare(
+	ctx context.Context,
+	alpha, beta *url.URL,
+	configuration, configurationAlpha, configurationBeta *Configuration,
+	prompter string,
+) (*core.Divergence, error) {
+	// Create a unique identifier to use in lieu of a session identifier.
+	identifier, err := identifier.New(identifier.PrefixSynchronization)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate identifier for comparison")
+	}
+
+	// Perform the comparison.
+	divergence, err := compare(
+		ctx,
+		m.logger.Sublogger(identifier),
+		identifier,
+		alpha, beta,
+		configuration, configurationAlpha, configurationBeta,
+		prompter,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to compare endpoints")
+	}
+
+	// Success.
+	return divergence, nil
+}
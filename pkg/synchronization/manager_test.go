@@ -1,3 +1,219 @@
 package synchronization
 
-// TODO: Implement.
+import (
+	"context"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/selection"
+	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// testNullProtocolHandler is a protocol handler that successfully connects
+// without yielding an endpoint, leaving sessions in a reconnecting state.
+type testNullProtocolHandler struct{}
+
+// Connect implements ProtocolHandler.Connect.
+func (h *testNullProtocolHandler) Connect(
+	_ context.Context,
+	_ *logging.Logger,
+	_ *urlpkg.URL,
+	_ string,
+	_ string,
+	_ Version,
+	_ *Configuration,
+	_ bool,
+) (Endpoint, error) {
+	return nil, nil
+}
+
+// withTestManager runs the specified test callback with a manager that uses a
+// temporary data directory and the specified limit on active sessions. Local
+// URLs are handled by testNullProtocolHandler for the duration of the callback.
+func withTestManager(t *testing.T, maximumActiveSessions uint64, callback func(*Manager)) {
+	// Register a null protocol handler for local URLs and defer restoration of
+	// the original handler.
+	originalHandler, originalHandlerRegistered := ProtocolHandlers[urlpkg.Protocol_Local]
+	ProtocolHandlers[urlpkg.Protocol_Local] = &testNullProtocolHandler{}
+	defer func() {
+		if originalHandlerRegistered {
+			ProtocolHandlers[urlpkg.Protocol_Local] = originalHandler
+		} else {
+			delete(ProtocolHandlers, urlpkg.Protocol_Local)
+		}
+	}()
+
+	// Create the manager within a temporary data directory and invoke the
+	// callback.
+	withTemporaryDataDirectory(t, func() {
+		manager, err := NewManager(logging.RootLogger.Sublogger("test"), nil, maximumActiveSessions)
+		if err != nil {
+			t.Fatal("unable to create manager:", err)
+		}
+		defer manager.Shutdown()
+		callback(manager)
+	})
+}
+
+// testManagerCreate creates the specified number of unpaused sessions using the
+// manager, returning their identifiers in creation order.
+func testManagerCreate(t *testing.T, manager *Manager, count int) []string {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create sessions.
+	alpha := &urlpkg.URL{Kind: urlpkg.Kind_Synchronization, Protocol: urlpkg.Protocol_Local, Path: "/alpha"}
+	beta := &urlpkg.URL{Kind: urlpkg.Kind_Synchronization, Protocol: urlpkg.Protocol_Local, Path: "/beta"}
+	identifiers := make([]string, count)
+	for i := range identifiers {
+		identifier, err := manager.Create(
+			context.Background(),
+			alpha, beta,
+			nil,
+			&Configuration{}, &Configuration{}, &Configuration{},
+			"",
+			nil,
+			false,
+			"",
+		)
+		if err != nil {
+			t.Fatal("unable to create session:", err)
+		}
+		identifiers[i] = identifier
+	}
+	return identifiers
+}
+
+// verifyManagerAdmission verifies that exactly the specified sessions are
+// active and that the specified sessions are queued in the specified order.
+func verifyManagerAdmission(t *testing.T, manager *Manager, active, queued []string) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Grab session states.
+	_, states, err := manager.List(context.Background(), &selection.Selection{All: true}, 0)
+	if err != nil {
+		t.Fatal("unable to list sessions:", err)
+	}
+	byIdentifier := make(map[string]*State, len(states))
+	for _, state := range states {
+		byIdentifier[state.Session.Identifier] = state
+	}
+
+	// Verify active sessions.
+	var activeCount int
+	for _, state := range states {
+		if !state.Session.Paused {
+			activeCount++
+		}
+	}
+	if activeCount != len(active) {
+		t.Error("active session count incorrect:", activeCount, "!=", len(active))
+	}
+	for _, identifier := range active {
+		if state, ok := byIdentifier[identifier]; !ok {
+			t.Error("active session missing:", identifier)
+		} else if state.Session.Paused {
+			t.Error("session unexpectedly paused:", identifier)
+		} else if state.QueuePosition != 0 {
+			t.Error("active session has queue position:", identifier)
+		}
+	}
+
+	// Verify queued sessions.
+	for i, identifier := range queued {
+		if state, ok := byIdentifier[identifier]; !ok {
+			t.Error("queued session missing:", identifier)
+		} else if !state.Session.Paused || state.Session.PausedReason != queuedPausedReason {
+			t.Error("session not queued:", identifier)
+		} else if state.QueuePosition != uint64(i+1) {
+			t.Errorf("queue position incorrect for %s: %d != %d", identifier, state.QueuePosition, i+1)
+		}
+	}
+}
+
+// TestManagerActiveSessionLimit tests that sessions created beyond the limit on
+// active sessions are queued and then admitted in order as active sessions are
+// paused or terminated.
+func TestManagerActiveSessionLimit(t *testing.T) {
+	withTestManager(t, 2, func(manager *Manager) {
+		// Create more sessions than the limit allows and verify that only the
+		// first sessions are admitted.
+		ids := testManagerCreate(t, manager, 5)
+		verifyManagerAdmission(t, manager, ids[:2], ids[2:])
+
+		// Pause an active session and verify that the session at the head of
+		// the queue is admitted.
+		if err := manager.Pause(context.Background(), &selection.Selection{Specifications: []string{ids[0]}}, ""); err != nil {
+			t.Fatal("unable to pause session:", err)
+		}
+		verifyManagerAdmission(t, manager, []string{ids[1], ids[2]}, []string{ids[3], ids[4]})
+
+		// Resume the paused session and verify that it's queued behind the
+		// sessions that were already waiting.
+		if err := manager.Resume(context.Background(), &selection.Selection{Specifications: []string{ids[0]}}, "", ObservationModeChangeNone); err != nil {
+			t.Fatal("unable to resume session:", err)
+		}
+		verifyManagerAdmission(t, manager, []string{ids[1], ids[2]}, []string{ids[3], ids[4], ids[0]})
+
+		// Terminate an active session and verify that the next queued session
+		// is admitted.
+		if err := manager.Terminate(context.Background(), &selection.Selection{Specifications: []string{ids[1]}}, ""); err != nil {
+			t.Fatal("unable to terminate session:", err)
+		}
+		verifyManagerAdmission(t, manager, []string{ids[2], ids[3]}, []string{ids[4], ids[0]})
+
+		// Pause a queued session and verify that it's removed from the queue
+		// without any session being admitted.
+		if err := manager.Pause(context.Background(), &selection.Selection{Specifications: []string{ids[4]}}, ""); err != nil {
+			t.Fatal("unable to pause session:", err)
+		}
+		verifyManagerAdmission(t, manager, []string{ids[2], ids[3]}, []string{ids[0]})
+	})
+}
+
+// TestManagerActiveSessionLimitCreatePaused tests that sessions created paused
+// aren't subject to admission.
+func TestManagerActiveSessionLimitCreatePaused(t *testing.T) {
+	withTestManager(t, 1, func(manager *Manager) {
+		// Create a paused session.
+		alpha := &urlpkg.URL{Kind: urlpkg.Kind_Synchronization, Protocol: urlpkg.Protocol_Local, Path: "/alpha"}
+		beta := &urlpkg.URL{Kind: urlpkg.Kind_Synchronization, Protocol: urlpkg.Protocol_Local, Path: "/beta"}
+		paused, err := manager.Create(
+			context.Background(),
+			alpha, beta,
+			nil,
+			&Configuration{}, &Configuration{}, &Configuration{},
+			"",
+			nil,
+			true,
+			"",
+		)
+		if err != nil {
+			t.Fatal("unable to create session:", err)
+		}
+
+		// Create an unpaused session and verify that it's admitted.
+		ids := testManagerCreate(t, manager, 1)
+		verifyManagerAdmission(t, manager, ids, nil)
+
+		// Verify that the paused session remains paused but isn't queued.
+		_, states, err := manager.List(context.Background(), &selection.Selection{Specifications: []string{paused}}, 0)
+		if err != nil {
+			t.Fatal("unable to list sessions:", err)
+		} else if len(states) != 1 {
+			t.Fatal("unexpected number of session states:", len(states))
+		} else if !states[0].Session.Paused || states[0].Session.PausedReason != "" || states[0].QueuePosition != 0 {
+			t.Error("paused session subjected to admission")
+		}
+	})
+}
+
+// TestManagerNoActiveSessionLimit tests that sessions aren't queued if there's
+// no limit on active sessions.
+func TestManagerNoActiveSessionLimit(t *testing.T) {
+	withTestManager(t, 0, func(manager *Manager) {
+		ids := testManagerCreate(t, manager, 3)
+		verifyManagerAdmission(t, manager, ids, nil)
+	})
+}
@@ -0,0 +1,326 @@
+package synchronization
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+const (
+	// legacyDataFormat is the format version assumed for persisted session
+	// data that predates format versioning. In this format, archives could be
+	// stored without integrity checksums and sessions could be stored without
+	// endpoint-specific configurations.
+	legacyDataFormat = 1
+	// currentDataFormat is the current format version for persisted session
+	// data. In this format, archives are always stored with integrity
+	// checksums and sessions are always stored with endpoint-specific
+	// configurations.
+	currentDataFormat = 2
+)
+
+// MigrationReport describes the result of a data directory migration.
+type MigrationReport struct {
+	// PreviousFormat is the format version detected before migration.
+	PreviousFormat uint64
+	// Format is the format version after migration.
+	Format uint64
+	// BackupPath is the path to the backup of the migrated files. It is empty
+	// if no files needed to be migrated.
+	BackupPath string
+	// Sessions are the identifiers of the migrated sessions.
+	Sessions []string
+	// Archives are the names of the migrated archives.
+	Archives []string
+}
+
+// migrationFile represents a file being migrated.
+type migrationFile struct {
+	// path is the path to the file.
+	path string
+	// backup is the path to the file's backup.
+	backup string
+}
+
+// migrator performs data directory migrations.
+type migrator struct {
+	// logger is the logger for the migrator.
+	logger *logging.Logger
+	// formatPath is the path to the format version record.
+	formatPath string
+	// sessionsDirectory is the path to the sessions directory.
+	sessionsDirectory string
+	// archivesDirectory is the path to the archives directory.
+	archivesDirectory string
+	// backupsDirectory is the path to the backups directory.
+	backupsDirectory string
+	// saveArchive is the function used to save migrated archives.
+	saveArchive func(string, *core.Archive) error
+}
+
+// newMigrator creates a migrator for the Mutagen data directory.
+func newMigrator(logger *logging.Logger) (*migrator, error) {
+	// Compute (and create) the data directory and relevant subdirectories.
+	dataDirectory, err := filesystem.Mutagen(true)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to compute data directory")
+	}
+	sessionsDirectory, err := pathForSession("")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to compute sessions directory")
+	}
+	archivesDirectory, err := filesystem.Mutagen(true, filesystem.MutagenSynchronizationArchivesDirectoryName)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to compute archives directory")
+	}
+
+	// Create the migrator.
+	return &migrator{
+		logger:            logger,
+		formatPath:        filepath.Join(dataDirectory, filesystem.MutagenSynchronizationFormatName),
+		sessionsDirectory: sessionsDirectory,
+		archivesDirectory: archivesDirectory,
+		backupsDirectory:  filepath.Join(dataDirectory, filesystem.MutagenSynchronizationBackupsDirectoryName),
+		// Migrated archives are saved uncompressed. If a session requests
+		// compression, then its archive will be compressed the next time
+		// that it's saved.
+		saveArchive: func(path string, archive *core.Archive) error {
+			return saveArchive(path, archive, ArchiveCompressionMode_ArchiveCompressionModeNone)
+		},
+	}, nil
+}
+
+// readFormat reads the recorded data format version, returning the legacy
+// format version if no format version is recorded.
+func (m *migrator) readFormat() (uint64, error) {
+	data, err := ioutil.ReadFile(m.formatPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return legacyDataFormat, nil
+		}
+		return 0, errors.Wrap(err, "unable to read format record")
+	}
+	format, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to parse format record")
+	}
+	return format, nil
+}
+
+// directoryContents lists the names of the files within the specified
+// directory, excluding temporary files.
+func directoryContents(path string) ([]string, error) {
+	contents, err := filesystem.DirectoryContentsByPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, c := range contents {
+		if c.Mode().IsRegular() && !strings.HasPrefix(c.Name(), filesystem.TemporaryNamePrefix) {
+			names = append(names, c.Name())
+		}
+	}
+	return names, nil
+}
+
+// legacySessions identifies sessions stored in the legacy format. Sessions that
+// can't be loaded are skipped, since they'd be skipped when loading sessions
+// anyway.
+func (m *migrator) legacySessions() (map[string]*Session, error) {
+	identifiers, err := directoryContents(m.sessionsDirectory)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read contents of sessions directory")
+	}
+	result := make(map[string]*Session)
+	for _, identifier := range identifiers {
+		session := &Session{}
+		if err := encoding.LoadAndUnmarshalProtobuf(filepath.Join(m.sessionsDirectory, identifier), session); err != nil {
+			m.logger.Warning("Unable to load session for migration:", identifier, err)
+			continue
+		}
+		if session.ConfigurationAlpha == nil || session.ConfigurationBeta == nil {
+			result[identifier] = session
+		}
+	}
+	return result, nil
+}
+
+// legacyArchives identifies archives stored in the legacy format. Archives that
+// can't be decoded are skipped, since they'll be treated as corrupted (and
+// replaced) when loaded.
+func (m *migrator) legacyArchives() (map[string]*core.Archive, error) {
+	names, err := directoryContents(m.archivesDirectory)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read contents of archives directory")
+	}
+	result := make(map[string]*core.Archive)
+	for _, name := range names {
+		path := filepath.Join(m.archivesDirectory, name)
+		if data, err := ioutil.ReadFile(path); err != nil {
+			return nil, errors.Wrapf(err, "unable to read archive (%s)", name)
+		} else if encoding.IsChecksummed(data) {
+			continue
+		}
+		archive := &core.Archive{}
+		if err := encoding.LoadAndUnmarshalChecksummedProtobuf(path, archive); err != nil {
+			m.logger.Warning("Unable to load archive for migration:", name, err)
+			continue
+		}
+		result[name] = archive
+	}
+	return result, nil
+}
+
+// backup creates a backup of the specified file. If a backup already exists
+// (e.g. from an aborted migration), then it's left in place, since it will
+// contain the file's original contents.
+func (m *migrator) backup(file migrationFile) error {
+	if _, err := os.Lstat(file.backup); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return errors.Wrap(err, "unable to check for existing backup")
+	}
+	data, err := ioutil.ReadFile(file.path)
+	if err != nil {
+		return errors.Wrap(err, "unable to read file")
+	}
+	if err := os.MkdirAll(filepath.Dir(file.backup), 0700); err != nil {
+		return errors.Wrap(err, "unable to create backup directory")
+	}
+	if err := filesystem.WriteFileAtomic(file.backup, data, 0600); err != nil {
+		return errors.Wrap(err, "unable to write backup")
+	}
+	return nil
+}
+
+// restore restores the specified files from their backups.
+func (m *migrator) restore(files []migrationFile) error {
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file.backup)
+		if err != nil {
+			return errors.Wrapf(err, "unable to read backup (%s)", file.backup)
+		} else if err = filesystem.WriteFileAtomic(file.path, data, 0600); err != nil {
+			return errors.Wrapf(err, "unable to restore file (%s)", file.path)
+		}
+	}
+	return nil
+}
+
+// abort restores the specified files from their backups after a migration
+// failure, returning an error describing the failure.
+func (m *migrator) abort(files []migrationFile, err error) error {
+	if restoreErr := m.restore(files); restoreErr != nil {
+		return errors.Errorf("migration failed (%v) and restoration failed (%v)", err, restoreErr)
+	}
+	return errors.Wrap(err, "migration failed (original files restored)")
+}
+
+// migrate performs the migration.
+func (m *migrator) migrate() (*MigrationReport, error) {
+	// Determine the current format version. If it's already current, then
+	// there's nothing to do.
+	format, err := m.readFormat()
+	if err != nil {
+		return nil, err
+	} else if format > currentDataFormat {
+		return nil, errors.Errorf("data format (%d) is newer than supported (%d)", format, currentDataFormat)
+	}
+	report := &MigrationReport{PreviousFormat: format, Format: format}
+	if format == currentDataFormat {
+		return report, nil
+	}
+
+	// Identify legacy sessions and archives.
+	sessions, err := m.legacySessions()
+	if err != nil {
+		return nil, err
+	}
+	archives, err := m.legacyArchives()
+	if err != nil {
+		return nil, err
+	}
+
+	// Back up the files to be migrated. We back up all files before modifying
+	// any of them so that a failed migration can be fully restored.
+	var files []migrationFile
+	if len(sessions) > 0 || len(archives) > 0 {
+		report.BackupPath = filepath.Join(m.backupsDirectory, fmt.Sprintf("synchronization-format-%d", format))
+	}
+	for identifier := range sessions {
+		files = append(files, migrationFile{
+			path:   filepath.Join(m.sessionsDirectory, identifier),
+			backup: filepath.Join(report.BackupPath, filesystem.MutagenSynchronizationSessionsDirectoryName, identifier),
+		})
+		report.Sessions = append(report.Sessions, identifier)
+	}
+	for name := range archives {
+		files = append(files, migrationFile{
+			path:   filepath.Join(m.archivesDirectory, name),
+			backup: filepath.Join(report.BackupPath, filesystem.MutagenSynchronizationArchivesDirectoryName, name),
+		})
+		report.Archives = append(report.Archives, name)
+	}
+	sort.Strings(report.Sessions)
+	sort.Strings(report.Archives)
+	for _, file := range files {
+		if err := m.backup(file); err != nil {
+			return nil, errors.Wrapf(err, "unable to back up file (%s)", file.path)
+		}
+	}
+
+	// Migrate sessions by populating any missing endpoint-specific
+	// configurations, as is done when loading sessions.
+	for identifier, session := range sessions {
+		if session.ConfigurationAlpha == nil {
+			session.ConfigurationAlpha = &Configuration{}
+		}
+		if session.ConfigurationBeta == nil {
+			session.ConfigurationBeta = &Configuration{}
+		}
+		if err := encoding.MarshalAndSaveProtobuf(filepath.Join(m.sessionsDirectory, identifier), session); err != nil {
+			return nil, m.abort(files, errors.Wrapf(err, "unable to save session (%s)", identifier))
+		}
+	}
+
+	// Migrate archives by re-saving them with integrity checksums.
+	for name, archive := range archives {
+		if err := m.saveArchive(filepath.Join(m.archivesDirectory, name), archive); err != nil {
+			return nil, m.abort(files, errors.Wrapf(err, "unable to save archive (%s)", name))
+		}
+	}
+
+	// Record the new format version.
+	formatRecord := []byte(fmt.Sprintf("%d\n", currentDataFormat))
+	if err := filesystem.WriteFileAtomic(m.formatPath, formatRecord, 0600); err != nil {
+		return nil, m.abort(files, errors.Wrap(err, "unable to record format"))
+	}
+
+	// Success.
+	report.Format = currentDataFormat
+	return report, nil
+}
+
+// MigrateDataDirectory migrates persisted sessions and archives within the
+// Mutagen data directory to the current data format, backing up migrated files
+// beforehand. It detects the format version of the data directory and is a
+// no-op if the data directory is already in the current format, so it's safe
+// to invoke repeatedly. If migration fails, then the original files are
+// restored from their backups. Backups are retained after migration.
+func MigrateDataDirectory(logger *logging.Logger) (*MigrationReport, error) {
+	migrator, err := newMigrator(logger)
+	if err != nil {
+		return nil, err
+	}
+	return migrator.migrate()
+}
@@ -0,0 +1,255 @@
+package synchronization
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// testMigrationArchiveRoot is the archive root used for migration tests.
+var testMigrationArchiveRoot = &core.Entry{
+	Kind: core.EntryKind_Directory,
+	Contents: map[string]*core.Entry{
+		"file": {Kind: core.EntryKind_File, Digest: []byte{0x01, 0x02}},
+	},
+}
+
+// seedLegacyDataDirectory populates the current data directory with a mix of
+// legacy and current format sessions and archives. It returns the original
+// contents of the legacy files, keyed by their paths relative to the data
+// directory.
+func seedLegacyDataDirectory(t *testing.T) map[string][]byte {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Compute paths.
+	legacySessionPath, err := pathForSession("sync_legacy")
+	if err != nil {
+		t.Fatal("unable to compute session path:", err)
+	}
+	currentSessionPath, err := pathForSession("sync_current")
+	if err != nil {
+		t.Fatal("unable to compute session path:", err)
+	}
+	legacyArchivePath, err := pathForArchive("sync_legacy")
+	if err != nil {
+		t.Fatal("unable to compute archive path:", err)
+	}
+	currentArchivePath, err := pathForArchive("sync_current")
+	if err != nil {
+		t.Fatal("unable to compute archive path:", err)
+	}
+
+	// Save a legacy session (without endpoint-specific configurations) and a
+	// current session.
+	legacySession := &Session{
+		Identifier:    "sync_legacy",
+		Version:       Version_Version1,
+		Configuration: &Configuration{},
+	}
+	if err := encoding.MarshalAndSaveProtobuf(legacySessionPath, legacySession); err != nil {
+		t.Fatal("unable to save legacy session:", err)
+	}
+	currentSession := &Session{
+		Identifier:         "sync_current",
+		Version:            Version_Version1,
+		Configuration:      &Configuration{},
+		ConfigurationAlpha: &Configuration{},
+		ConfigurationBeta:  &Configuration{},
+	}
+	if err := encoding.MarshalAndSaveProtobuf(currentSessionPath, currentSession); err != nil {
+		t.Fatal("unable to save current session:", err)
+	}
+
+	// Save legacy archives (without checksums) and a current archive.
+	archive := &core.Archive{Root: testMigrationArchiveRoot}
+	for _, path := range []string{legacyArchivePath, pathForAdditionalArchive(legacyArchivePath, 1)} {
+		if err := encoding.MarshalAndSaveProtobuf(path, archive); err != nil {
+			t.Fatal("unable to save legacy archive:", err)
+		}
+	}
+	if err := saveArchive(currentArchivePath, archive, ArchiveCompressionMode_ArchiveCompressionModeNone); err != nil {
+		t.Fatal("unable to save current archive:", err)
+	}
+
+	// Record the original contents of the legacy files.
+	result := make(map[string][]byte)
+	for _, path := range []string{
+		filepath.Join(filesystem.MutagenSynchronizationSessionsDirectoryName, "sync_legacy"),
+		filepath.Join(filesystem.MutagenSynchronizationArchivesDirectoryName, "sync_legacy"),
+		filepath.Join(filesystem.MutagenSynchronizationArchivesDirectoryName, "sync_legacy.beta2"),
+	} {
+		fullPath, err := filesystem.Mutagen(false, path)
+		if err != nil {
+			t.Fatal("unable to compute path:", err)
+		}
+		if result[path], err = ioutil.ReadFile(fullPath); err != nil {
+			t.Fatal("unable to read legacy file:", err)
+		}
+	}
+	return result
+}
+
+// verifyFiles verifies that the specified files (with paths relative to the
+// specified directory within the data directory) have the specified contents.
+func verifyFiles(t *testing.T, directory string, files map[string][]byte) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Verify file contents.
+	for path, expected := range files {
+		fullPath, err := filesystem.Mutagen(false, directory, path)
+		if err != nil {
+			t.Fatal("unable to compute path:", err)
+		}
+		if contents, err := ioutil.ReadFile(fullPath); err != nil {
+			t.Error("unable to read file:", err)
+		} else if !bytes.Equal(contents, expected) {
+			t.Error("file contents do not match expected:", path)
+		}
+	}
+}
+
+// TestMigrateDataDirectory tests that migration of a legacy data directory
+// upgrades legacy files, retains backups of their original contents, and is
+// idempotent.
+func TestMigrateDataDirectory(t *testing.T) {
+	withTemporaryDataDirectory(t, func() {
+		// Seed the data directory.
+		originals := seedLegacyDataDirectory(t)
+
+		// Perform the migration and verify the report.
+		logger := logging.RootLogger.Sublogger("migration")
+		report, err := MigrateDataDirectory(logger)
+		if err != nil {
+			t.Fatal("unable to migrate data directory:", err)
+		}
+		if report.PreviousFormat != legacyDataFormat || report.Format != currentDataFormat {
+			t.Error("unexpected formats in report:", report.PreviousFormat, report.Format)
+		}
+		if len(report.Sessions) != 1 || report.Sessions[0] != "sync_legacy" {
+			t.Error("unexpected migrated sessions:", report.Sessions)
+		}
+		if len(report.Archives) != 2 || report.Archives[0] != "sync_legacy" || report.Archives[1] != "sync_legacy.beta2" {
+			t.Error("unexpected migrated archives:", report.Archives)
+		}
+
+		// Verify that the migrated session has endpoint-specific configurations.
+		sessionPath, _ := pathForSession("sync_legacy")
+		session := &Session{}
+		if err := encoding.LoadAndUnmarshalProtobuf(sessionPath, session); err != nil {
+			t.Fatal("unable to load migrated session:", err)
+		} else if session.ConfigurationAlpha == nil || session.ConfigurationBeta == nil {
+			t.Error("migrated session lacks endpoint-specific configurations")
+		} else if session.Identifier != "sync_legacy" {
+			t.Error("migrated session identifier not preserved")
+		}
+
+		// Verify that the migrated archives have checksums and are unchanged in
+		// content.
+		archivePath, _ := pathForArchive("sync_legacy")
+		for _, path := range []string{archivePath, pathForAdditionalArchive(archivePath, 1)} {
+			if data, err := ioutil.ReadFile(path); err != nil {
+				t.Fatal("unable to read migrated archive:", err)
+			} else if !encoding.IsChecksummed(data) {
+				t.Error("migrated archive lacks checksum:", path)
+			}
+			if archive, err := loadArchive(logger, path); err != nil {
+				t.Fatal("unable to load migrated archive:", err)
+			} else if !archive.Root.Equal(testMigrationArchiveRoot) {
+				t.Error("migrated archive contents not preserved:", path)
+			}
+		}
+
+		// Verify that backups of the original files were created.
+		if report.BackupPath == "" {
+			t.Fatal("no backup path reported")
+		}
+		backupDirectory := filepath.Join(
+			filesystem.MutagenSynchronizationBackupsDirectoryName,
+			filepath.Base(report.BackupPath),
+		)
+		verifyFiles(t, backupDirectory, originals)
+
+		// Verify that repeated migration is a no-op and that backups are
+		// retained.
+		report, err = MigrateDataDirectory(logger)
+		if err != nil {
+			t.Fatal("unable to repeat migration:", err)
+		} else if report.PreviousFormat != currentDataFormat || report.Format != currentDataFormat {
+			t.Error("unexpected formats in repeated migration report:", report.PreviousFormat, report.Format)
+		} else if len(report.Sessions) != 0 || len(report.Archives) != 0 || report.BackupPath != "" {
+			t.Error("repeated migration migrated files")
+		}
+		verifyFiles(t, backupDirectory, originals)
+	})
+}
+
+// TestMigrateDataDirectoryFailure tests that a failed migration restores the
+// original files and that a subsequent migration succeeds.
+func TestMigrateDataDirectoryFailure(t *testing.T) {
+	withTemporaryDataDirectory(t, func() {
+		// Seed the data directory.
+		originals := seedLegacyDataDirectory(t)
+
+		// Create a migrator that fails to save the second archive.
+		logger := logging.RootLogger.Sublogger("migration")
+		migrator, err := newMigrator(logger)
+		if err != nil {
+			t.Fatal("unable to create migrator:", err)
+		}
+		var saved int
+		migrator.saveArchive = func(path string, archive *core.Archive) error {
+			if saved++; saved == 2 {
+				return errors.New("simulated failure")
+			}
+			return saveArchive(path, archive, ArchiveCompressionMode_ArchiveCompressionModeNone)
+		}
+
+		// Perform the migration and verify that it fails and that the original
+		// files are restored.
+		if _, err := migrator.migrate(); err == nil {
+			t.Fatal("migration succeeded unexpectedly")
+		}
+		verifyFiles(t, "", originals)
+		if format, err := migrator.readFormat(); err != nil {
+			t.Fatal("unable to read format:", err)
+		} else if format != legacyDataFormat {
+			t.Error("format recorded after failed migration:", format)
+		}
+
+		// Verify that a subsequent migration succeeds.
+		if report, err := MigrateDataDirectory(logger); err != nil {
+			t.Fatal("unable to migrate data directory after failure:", err)
+		} else if report.Format != currentDataFormat || len(report.Sessions) != 1 || len(report.Archives) != 2 {
+			t.Error("unexpected report after failure:", report)
+		}
+	})
+}
+
+// TestMigrateDataDirectoryNewerFormat tests that migration refuses to operate
+// on data directories with a newer format.
+func TestMigrateDataDirectoryNewerFormat(t *testing.T) {
+	withTemporaryDataDirectory(t, func() {
+		formatPath, err := filesystem.Mutagen(true, filesystem.MutagenSynchronizationFormatName)
+		if err != nil {
+			t.Fatal("unable to compute format path:", err)
+		}
+		os.Remove(formatPath)
+		if err := ioutil.WriteFile(formatPath, []byte("99\n"), 0600); err != nil {
+			t.Fatal("unable to write format record:", err)
+		}
+		if _, err := MigrateDataDirectory(logging.RootLogger); err == nil {
+			t.Error("migration of newer format succeeded")
+		}
+	})
+}
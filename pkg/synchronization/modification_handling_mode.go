@@ -0,0 +1,71 @@
+package synchronization
+
+import (
+	"github.com/pkg/errors"
+)
+
+// IsDefault indicates whether or not the modification handling mode is
+// ModificationHandlingMode_ModificationHandlingModeDefault.
+func (m ModificationHandlingMode) IsDefault() bool {
+	return m == ModificationHandlingMode_ModificationHandlingModeDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (m *ModificationHandlingMode) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to a modification handling mode.
+	switch text {
+	case "retry":
+		*m = ModificationHandlingMode_ModificationHandlingModeRetry
+	case "defer":
+		*m = ModificationHandlingMode_ModificationHandlingModeDefer
+	default:
+		return errors.Errorf("unknown modification handling mode specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular modification handling mode
+// is a valid, non-default value.
+func (m ModificationHandlingMode) Supported() bool {
+	switch m {
+	case ModificationHandlingMode_ModificationHandlingModeRetry:
+		return true
+	case ModificationHandlingMode_ModificationHandlingModeDefer:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of a modification handling
+// mode.
+func (m ModificationHandlingMode) Description() string {
+	switch m {
+	case ModificationHandlingMode_ModificationHandlingModeDefault:
+		return "Default"
+	case ModificationHandlingMode_ModificationHandlingModeRetry:
+		return "Retry"
+	case ModificationHandlingMode_ModificationHandlingModeDefer:
+		return "Defer"
+	default:
+		return "Unknown"
+	}
+}
+
+// MaximumRetries returns the maximum number of times that the transmission of
+// a modified file should be restarted under the modification handling mode. It
+// returns 0 for unknown and default modes.
+func (m ModificationHandlingMode) MaximumRetries() uint {
+	switch m {
+	case ModificationHandlingMode_ModificationHandlingModeRetry:
+		return 3
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,117 @@
+package synchronization
+
+import (
+	"testing"
+)
+
+// TestModificationHandlingModeUnmarshal tests that unmarshaling from a string
+// specification succeeeds for ModificationHandlingMode.
+func TestModificationHandlingModeUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text          string
+		expectedMode  ModificationHandlingMode
+		expectFailure bool
+	}{
+		{"", ModificationHandlingMode_ModificationHandlingModeDefault, true},
+		{"asdf", ModificationHandlingMode_ModificationHandlingModeDefault, true},
+		{"retry", ModificationHandlingMode_ModificationHandlingModeRetry, false},
+		{"defer", ModificationHandlingMode_ModificationHandlingModeDefer, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var mode ModificationHandlingMode
+		if err := mode.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if mode != testCase.expectedMode {
+			t.Errorf(
+				"unmarshaled mode (%s) does not match expected (%s)",
+				mode,
+				testCase.expectedMode,
+			)
+		}
+	}
+}
+
+// TestModificationHandlingModeSupported tests that ModificationHandlingMode
+// support detection works as expected.
+func TestModificationHandlingModeSupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode            ModificationHandlingMode
+		expectSupported bool
+	}{
+		{ModificationHandlingMode_ModificationHandlingModeDefault, false},
+		{ModificationHandlingMode_ModificationHandlingModeRetry, true},
+		{ModificationHandlingMode_ModificationHandlingModeDefer, true},
+		{(ModificationHandlingMode_ModificationHandlingModeDefer + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.mode.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"mode support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestModificationHandlingModeDescription tests that ModificationHandlingMode
+// description generation works as expected.
+func TestModificationHandlingModeDescription(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode                ModificationHandlingMode
+		expectedDescription string
+	}{
+		{ModificationHandlingMode_ModificationHandlingModeDefault, "Default"},
+		{ModificationHandlingMode_ModificationHandlingModeRetry, "Retry"},
+		{ModificationHandlingMode_ModificationHandlingModeDefer, "Defer"},
+		{(ModificationHandlingMode_ModificationHandlingModeDefer + 1), "Unknown"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if description := testCase.mode.Description(); description != testCase.expectedDescription {
+			t.Errorf(
+				"mode description (%s) does not match expected (%s)",
+				description,
+				testCase.expectedDescription,
+			)
+		}
+	}
+}
+
+// TestModificationHandlingModeMaximumRetries tests that ModificationHandlingMode
+// retry limit computation works as expected.
+func TestModificationHandlingModeMaximumRetries(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		mode            ModificationHandlingMode
+		expectedRetries uint
+	}{
+		{ModificationHandlingMode_ModificationHandlingModeDefault, 0},
+		{ModificationHandlingMode_ModificationHandlingModeRetry, 3},
+		{ModificationHandlingMode_ModificationHandlingModeDefer, 0},
+		{(ModificationHandlingMode_ModificationHandlingModeDefer + 1), 0},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if retries := testCase.mode.MaximumRetries(); retries != testCase.expectedRetries {
+			t.Errorf(
+				"maximum retries (%d) does not match expected (%d)",
+				retries,
+				testCase.expectedRetries,
+			)
+		}
+	}
+}
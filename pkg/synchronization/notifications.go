@@ -0,0 +1,102 @@
+package synchronization
+
+import (
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/notification"
+)
+
+// notificationConditions encodes the notification-worthy conditions of a
+// session.
+type notificationConditions struct {
+	// conflicted indicates whether or not the session has conflicts.
+	conflicted bool
+	// errored indicates whether or not the session has a last error.
+	errored bool
+}
+
+// currentNotificationConditions computes the current notification-worthy
+// conditions of the session. It avoids the cost of copying the full session
+// state, which would otherwise be incurred on every state change.
+func (c *controller) currentNotificationConditions() notificationConditions {
+	// Lock the session state and defer its release. As with currentState, we
+	// have to unlock without a notification to avoid a notification cycle.
+	c.stateLock.Lock()
+	defer c.stateLock.UnlockWithoutNotify()
+
+	// Compute conditions.
+	return notificationConditions{
+		conflicted: len(c.state.Conflicts) > 0,
+		errored:    c.state.LastError != "",
+	}
+}
+
+// newNotificationEvent creates a notification event of the specified kind from
+// the specified session state.
+func newNotificationEvent(kind notification.Kind, state *State) *notification.Event {
+	return &notification.Event{
+		Time:      time.Now(),
+		Kind:      kind,
+		Session:   state.Session.Identifier,
+		Name:      state.Session.Name,
+		Labels:    state.Session.Labels,
+		Alpha:     state.Session.Alpha.Format(""),
+		Beta:      state.Session.Beta.Format(""),
+		Status:    state.Status.Description(),
+		Conflicts: uint64(len(state.Conflicts)) + state.TruncatedConflicts,
+		Error:     state.LastError,
+	}
+}
+
+// monitorNotifications watches for session state changes and sends
+// notifications via the manager's notifier when sessions enter conflicted or
+// errored conditions. Notifications are only sent when a condition is entered,
+// not while it persists. It runs until state tracking is terminated and should
+// be invoked in a background Goroutine.
+func (m *Manager) monitorNotifications() {
+	// Track the conditions of each session.
+	previous := make(map[string]notificationConditions)
+
+	// Loop until state tracking is terminated.
+	var stateIndex uint64
+	for {
+		// Wait for a state change.
+		var poisoned bool
+		stateIndex, poisoned = m.tracker.WaitForChange(stateIndex)
+		if poisoned {
+			return
+		}
+
+		// Compute the current conditions of each session and notify for any
+		// newly entered conditions.
+		current := make(map[string]notificationConditions, len(previous))
+		for _, c := range m.allControllers() {
+			identifier := c.session.Identifier
+			conditions := c.currentNotificationConditions()
+			current[identifier] = conditions
+			entered := previous[identifier]
+			entered.conflicted = conditions.conflicted && !entered.conflicted
+			entered.errored = conditions.errored && !entered.errored
+			if !entered.conflicted && !entered.errored {
+				continue
+			}
+			state := c.currentState()
+			if entered.conflicted {
+				m.notifier.Notify(newNotificationEvent(notification.KindConflicted, state))
+			}
+			if entered.errored {
+				m.notifier.Notify(newNotificationEvent(notification.KindErrored, state))
+			}
+		}
+
+		// Discard debouncing information for sessions that no longer exist.
+		for identifier := range previous {
+			if _, ok := current[identifier]; !ok {
+				m.notifier.Forget(identifier)
+			}
+		}
+
+		// Update conditions.
+		previous = current
+	}
+}
@@ -0,0 +1,78 @@
+package synchronization
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// ObservationModeChange specifies how a resume operation should change the
+// observation mode of a session. Sessions in observation mode scan and
+// reconcile continuously, reporting the changes that they would apply in their
+// state, but never stage or transition content. Since the ancestor isn't
+// updated while observing, switching a session back to live mode causes its
+// next synchronization cycle to apply the changes that it last reported.
+type ObservationModeChange uint8
+
+const (
+	// ObservationModeChangeNone indicates that the observation mode of a
+	// session should be left unchanged.
+	ObservationModeChangeNone ObservationModeChange = iota
+	// ObservationModeChangeObserve indicates that a session should be switched
+	// to observation mode.
+	ObservationModeChangeObserve
+	// ObservationModeChangeLive indicates that a session should be switched
+	// out of observation mode.
+	ObservationModeChangeLive
+)
+
+// slimChanges creates slim copies of the specified changes so that observed
+// changes can be reported without holding or transmitting full entry trees.
+func slimChanges(changes []*core.Change) []*core.Change {
+	if len(changes) == 0 {
+		return nil
+	}
+	result := make([]*core.Change, len(changes))
+	for c, change := range changes {
+		result[c] = change.CopySlim()
+	}
+	return result
+}
+
+// setObservationMode applies the specified observation mode change to the
+// session and saves the session. Running synchronization loops pick up the
+// change at the start of their next reconciliation.
+func (c *controller) setObservationMode(change ObservationModeChange) error {
+	// If there's no change, then there's nothing to do.
+	if change == ObservationModeChangeNone {
+		return nil
+	}
+
+	// Lock the controller's lifecycle and defer its release.
+	c.lifecycleLock.Lock()
+	defer c.lifecycleLock.Unlock()
+
+	// Don't allow any changes if the controller is disabled.
+	if c.disabled {
+		return errors.New("controller disabled")
+	}
+
+	// Update the session and save it to disk. If we're leaving observation
+	// mode, then clear any observed changes, since they're no longer being
+	// tracked.
+	c.stateLock.Lock()
+	c.session.Observing = change == ObservationModeChangeObserve
+	if !c.session.Observing {
+		c.state.ObservedAlphaChanges = nil
+		c.state.ObservedBetaChanges = nil
+	}
+	err := encoding.MarshalAndSaveProtobuf(c.sessionPath, c.session)
+	c.stateLock.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "unable to save session")
+	}
+
+	// Success.
+	return nil
+}
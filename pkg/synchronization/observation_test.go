@@ -0,0 +1,208 @@
+package synchronization
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/state"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/rsync"
+)
+
+// testObservedEndpoint is a testDirectoryEndpoint that counts staging and
+// transition operations.
+type testObservedEndpoint struct {
+	*testDirectoryEndpoint
+	// mutations is the number of staging and transition operations performed.
+	mutations int
+}
+
+// Stage implements Endpoint.Stage.
+func (e *testObservedEndpoint) Stage(paths []string, digests [][]byte) ([]string, []*rsync.Signature, rsync.Receiver, error) {
+	e.mutations++
+	return e.testDirectoryEndpoint.Stage(paths, digests)
+}
+
+// Transition implements Endpoint.Transition.
+func (e *testObservedEndpoint) Transition(ctx context.Context, transitions []*core.Change) ([]*core.Entry, []*core.Problem, bool, error) {
+	e.mutations++
+	return e.testDirectoryEndpoint.Transition(ctx, transitions)
+}
+
+// testObservationController creates a running controller for a session in
+// observation mode that would synchronize the specified content from an alpha
+// directory to an empty beta directory. It returns the controller, the
+// temporary directory containing all test content (which the caller should
+// remove), and the endpoints.
+func testObservationController(t *testing.T, content map[string][]byte) (*controller, string, *testObservedEndpoint, *testObservedEndpoint) {
+	// Create a temporary directory to hold all test content.
+	parent, err := ioutil.TempDir("", "mutagen_observation")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+
+	// Create endpoint directories and alpha content.
+	alphaRoot := filepath.Join(parent, "alpha")
+	betaRoot := filepath.Join(parent, "beta")
+	staging := filepath.Join(parent, "staging")
+	for _, directory := range []string{alphaRoot, betaRoot, staging} {
+		if err := os.Mkdir(directory, 0700); err != nil {
+			os.RemoveAll(parent)
+			t.Fatal("unable to create directory:", err)
+		}
+	}
+	for name, data := range content {
+		if err := ioutil.WriteFile(filepath.Join(alphaRoot, name), data, 0600); err != nil {
+			os.RemoveAll(parent)
+			t.Fatal("unable to create alpha content:", err)
+		}
+	}
+
+	// Create an empty archive.
+	archivePath := filepath.Join(parent, "archive")
+	if err := encoding.MarshalAndSaveProtobuf(archivePath, &core.Archive{}); err != nil {
+		os.RemoveAll(parent)
+		t.Fatal("unable to save archive:", err)
+	}
+
+	// Create the controller.
+	session := &Session{
+		Identifier:         "session",
+		Version:            Version_Version1,
+		Configuration:      &Configuration{},
+		ConfigurationAlpha: &Configuration{},
+		ConfigurationBeta:  &Configuration{},
+		Observing:          true,
+	}
+	c := &controller{
+		logger:                   logging.RootLogger.Sublogger("test"),
+		sessionPath:              filepath.Join(parent, "session"),
+		archivePath:              archivePath,
+		stateLock:                state.NewTrackingLock(state.NewTracker()),
+		session:                  session,
+		mergedAlphaConfiguration: &Configuration{},
+		mergedBetaConfiguration:  &Configuration{},
+		state: &State{
+			Session: session,
+		},
+	}
+
+	// Start the synchronization loop.
+	alpha := &testObservedEndpoint{testDirectoryEndpoint: &testDirectoryEndpoint{
+		root:    alphaRoot,
+		source:  betaRoot,
+		staging: staging,
+	}}
+	beta := &testObservedEndpoint{testDirectoryEndpoint: &testDirectoryEndpoint{
+		root:    betaRoot,
+		source:  alphaRoot,
+		staging: staging,
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopCtx, stop := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.stop = stop
+	c.flushRequests = make(chan *controllerFlushRequest, 1)
+	c.done = make(chan struct{})
+	go c.run(ctx, stopCtx, alpha, beta, nil)
+
+	// Done.
+	return c, parent, alpha, beta
+}
+
+// verifyObservedChanges verifies that the controller reports the specified
+// observed beta change paths and no observed alpha changes.
+func verifyObservedChanges(t *testing.T, c *controller, expected []string) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Extract and verify the paths.
+	state := c.currentState()
+	if len(state.ObservedAlphaChanges) > 0 {
+		t.Error("unexpected observed alpha changes:", len(state.ObservedAlphaChanges))
+	}
+	var paths []string
+	for _, change := range state.ObservedBetaChanges {
+		paths = append(paths, change.Path)
+	}
+	sort.Strings(paths)
+	if !stringSlicesEqual(paths, expected) {
+		t.Errorf("observed beta changes incorrect: %v != %v", paths, expected)
+	}
+}
+
+// TestControllerObservation tests that sessions in observation mode report the
+// changes that they would apply (tracking changes to endpoint contents) without
+// applying them, and that they apply those changes once switched to live mode.
+func TestControllerObservation(t *testing.T) {
+	// Create a controller and wait for it to complete its initial cycle.
+	content := map[string][]byte{
+		"first":  []byte("first content"),
+		"second": []byte("second content"),
+	}
+	c, parent, alpha, beta := testObservationController(t, content)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+	verifyObservedChanges(t, c, []string{"first", "second"})
+
+	// Modify alpha's content and force additional synchronization cycles.
+	if err := os.Remove(filepath.Join(alpha.root, "first")); err != nil {
+		t.Fatal("unable to remove file:", err)
+	} else if err = ioutil.WriteFile(filepath.Join(alpha.root, "third"), []byte("third content"), 0600); err != nil {
+		t.Fatal("unable to create file:", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := c.flush(context.Background(), "", false, nil, false); err != nil {
+			t.Fatal("flush failed:", err)
+		}
+	}
+	verifyObservedChanges(t, c, []string{"second", "third"})
+
+	// Verify that no mutations occurred.
+	if alpha.mutations != 0 || beta.mutations != 0 {
+		t.Error("staging or transition performed in observation mode")
+	}
+	if contents, err := ioutil.ReadDir(beta.root); err != nil {
+		t.Fatal("unable to read beta contents:", err)
+	} else if len(contents) != 0 {
+		t.Error("beta modified in observation mode")
+	}
+
+	// Verify that undo operations are rejected in observation mode.
+	c.session.Configuration.UndoMaximumSize = 1024
+	if err := c.undo(context.Background(), ""); err == nil {
+		t.Error("undo succeeded in observation mode")
+	}
+
+	// Switch to live mode and verify that the observed changes are applied.
+	if err := c.setObservationMode(ObservationModeChangeLive); err != nil {
+		t.Fatal("unable to switch to live mode:", err)
+	} else if err = c.flush(context.Background(), "", false, nil, false); err != nil {
+		t.Fatal("flush failed:", err)
+	}
+	for name := range map[string]bool{"second": true, "third": true} {
+		if _, err := os.Lstat(filepath.Join(beta.root, name)); err != nil {
+			t.Error("observed change not applied:", err)
+		}
+	}
+	verifyObservedChanges(t, c, nil)
+
+	// Verify that the mode change was persisted.
+	session := &Session{}
+	if err := encoding.LoadAndUnmarshalProtobuf(c.sessionPath, session); err != nil {
+		t.Fatal("unable to load session:", err)
+	} else if session.Observing {
+		t.Error("live mode not persisted")
+	}
+
+	// Halt the session.
+	if err := c.halt(context.Background(), controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+}
@@ -0,0 +1,135 @@
+package synchronization
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// pathMappingCycle tracks the state necessary to apply a path mapping to beta
+// during a single synchronization cycle. Beta's snapshot is mapped back to
+// synchronization paths after scanning so that reconciliation is performed
+// using synchronization paths, and beta's transitions are converted to
+// transitions of its on-disk content before staging and transitioning.
+type pathMappingCycle struct {
+	// mapping is the path mapping.
+	mapping *core.PathMapping
+	// onDisk is beta's on-disk snapshot.
+	onDisk *core.Entry
+	// snapshot is beta's snapshot in terms of synchronization paths.
+	snapshot *core.Entry
+	// known are the snapshots containing known synchronization paths.
+	known []*core.Entry
+	// unmapped are the on-disk paths of content that doesn't correspond to any
+	// synchronization path. This content is left untouched.
+	unmapped []string
+	// onDiskPaths maps synchronization paths to on-disk paths for the scanned
+	// content.
+	onDiskPaths map[string]string
+	// scannedPaths maps on-disk paths to synchronization paths for the scanned
+	// content.
+	scannedPaths map[string]string
+	// synchronizationPaths maps on-disk paths to synchronization paths for the
+	// target of the cycle's transitions.
+	synchronizationPaths map[string]string
+}
+
+// newPathMappingCycle creates a new path mapping cycle for the specified
+// on-disk snapshot, mapping it back to synchronization paths using the
+// specified known snapshots. It returns the cycle and any problems encountered
+// while mapping.
+func newPathMappingCycle(mapping *core.PathMapping, onDisk *core.Entry, known ...*core.Entry) (*pathMappingCycle, []*core.Problem) {
+	// Reverse the mapping.
+	result := mapping.Unmap(onDisk, known...)
+
+	// Invert the path index.
+	scannedPaths := make(map[string]string, len(result.Paths))
+	for path, onDiskPath := range result.Paths {
+		scannedPaths[onDiskPath] = path
+	}
+
+	// Create the cycle.
+	return &pathMappingCycle{
+		mapping:      mapping,
+		onDisk:       onDisk,
+		snapshot:     result.Snapshot,
+		known:        known,
+		unmapped:     result.Unmapped,
+		onDiskPaths:  result.Paths,
+		scannedPaths: scannedPaths,
+	}, result.Problems
+}
+
+// translatePaths translates paths using the specified index, leaving paths
+// that aren't indexed unmodified. A new slice is always returned.
+func translatePaths(paths []string, index map[string]string) []string {
+	result := make([]string, len(paths))
+	for p, path := range paths {
+		if translated, ok := index[path]; ok {
+			result[p] = translated
+		} else {
+			result[p] = path
+		}
+	}
+	return result
+}
+
+// translateProblems translates the paths of problems using the specified
+// index, leaving problems whose paths aren't indexed unmodified. The original
+// problems aren't modified.
+func translateProblems(problems []*core.Problem, index map[string]string) []*core.Problem {
+	if len(problems) == 0 {
+		return problems
+	}
+	result := make([]*core.Problem, len(problems))
+	for p, problem := range problems {
+		if translated, ok := index[problem.Path]; ok {
+			result[p] = &core.Problem{Path: translated, Error: problem.Error}
+		} else {
+			result[p] = problem
+		}
+	}
+	return result
+}
+
+// transitions converts transitions expressed in terms of synchronization paths
+// into transitions of beta's on-disk content. It returns the on-disk
+// transitions and any problems encountered while mapping.
+func (c *pathMappingCycle) transitions(transitions []*core.Change) ([]*core.Change, []*core.Problem, error) {
+	// Compute the target snapshot in terms of synchronization paths.
+	target, err := core.Apply(c.snapshot, transitions)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to compute transition target")
+	}
+
+	// Map the target, preserving content that doesn't correspond to any
+	// synchronization path.
+	mapped := c.mapping.Map(target, c.onDisk, c.unmapped)
+	c.synchronizationPaths = mapped.Paths
+
+	// Compute the on-disk transitions.
+	return core.Diff(c.onDisk, mapped.Snapshot), mapped.Problems, nil
+}
+
+// results converts the results of on-disk transitions into the results of the
+// corresponding transitions expressed in terms of synchronization paths.
+func (c *pathMappingCycle) results(transitions, onDiskTransitions []*core.Change, onDiskResults []*core.Entry) ([]*core.Entry, error) {
+	// Compute the resulting on-disk snapshot.
+	changes := make([]*core.Change, len(onDiskTransitions))
+	for t, transition := range onDiskTransitions {
+		changes[t] = &core.Change{Path: transition.Path, New: onDiskResults[t]}
+	}
+	onDisk, err := core.Apply(c.onDisk, changes)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to compute on-disk transition results")
+	}
+
+	// Map the result back to synchronization paths and extract the results
+	// for each transition.
+	snapshot := c.mapping.Unmap(onDisk, c.known...).Snapshot
+	results := make([]*core.Entry, len(transitions))
+	for t, transition := range transitions {
+		results[t] = entryAtPath(snapshot, transition.Path)
+	}
+	return results, nil
+}
@@ -0,0 +1,145 @@
+package synchronization
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// TestControllerPathMappingPrefix tests that a bidirectional prefix path
+// mapping places content under the mapped paths on beta and that content
+// created under the mapped paths on beta is propagated back to alpha.
+func TestControllerPathMappingPrefix(t *testing.T) {
+	// Create a controller that places all content under a prefix on beta. Both
+	// endpoints stage by supply so that staging uses on-disk paths on beta.
+	content := map[string][]byte{
+		"file1": []byte("first"),
+		"file2": []byte("second"),
+	}
+	configuration := &Configuration{PathMappings: []string{"=>mirror"}}
+	c, parent, alpha, beta := testControllerWithSetup(t, configuration, content, nil, nil,
+		func(alpha, beta *testDirectoryEndpoint) {
+			alpha.supplyStaging = true
+			beta.supplyStaging = true
+		},
+	)
+	defer os.RemoveAll(parent)
+
+	// Wait for the initial content to be propagated.
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Verify placement on beta.
+	for name, data := range content {
+		if synchronized, err := ioutil.ReadFile(filepath.Join(beta.root, "mirror", name)); err != nil {
+			t.Errorf("unable to read mapped file (%s): %v", name, err)
+		} else if !bytes.Equal(synchronized, data) {
+			t.Errorf("mapped file (%s) content incorrect", name)
+		}
+		if _, err := os.Lstat(filepath.Join(beta.root, name)); !os.IsNotExist(err) {
+			t.Errorf("file (%s) placed at unmapped path", name)
+		}
+	}
+
+	// Create content under the mapped path on beta and verify that it's
+	// propagated back to the corresponding path on alpha.
+	if err := ioutil.WriteFile(filepath.Join(beta.root, "mirror", "file3"), []byte("third"), 0600); err != nil {
+		t.Fatal("unable to create beta content:", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.flush(ctx, "", false, nil, false); err != nil {
+		t.Fatal("flush failed:", err)
+	}
+	if synchronized, err := ioutil.ReadFile(filepath.Join(alpha.root, "file3")); err != nil {
+		t.Error("unable to read reverse-propagated file:", err)
+	} else if string(synchronized) != "third" {
+		t.Error("reverse-propagated file content incorrect")
+	}
+	if _, err := os.Lstat(filepath.Join(alpha.root, "mirror")); !os.IsNotExist(err) {
+		t.Error("mapped path propagated to alpha")
+	}
+
+	// Verify that no problems were reported.
+	if problems := c.currentState().BetaProblems; len(problems) != 0 {
+		t.Error("unexpected beta problems:", problems)
+	}
+
+	// Shut down the controller.
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+}
+
+// TestControllerPathMappingRegexCollision tests that a one-way regular
+// expression path mapping places content under the mapped paths on beta and
+// reports content whose mapped path collides with other content.
+func TestControllerPathMappingRegexCollision(t *testing.T) {
+	// Create a controller that renames numbered files on beta, with one file
+	// mapped to the same path as another.
+	content := map[string][]byte{
+		"file1": []byte("first"),
+		"file2": []byte("second"),
+		"other": []byte("other"),
+		"plain": []byte("plain"),
+	}
+	configuration := &Configuration{
+		SynchronizationMode: core.SynchronizationMode_SynchronizationModeOneWayReplica,
+		PathMappings: []string{
+			"regex:^file([0-9])$=>numbered/$1",
+			"regex:^other$=>numbered/1",
+		},
+		PathMappingOneWay: true,
+	}
+	c, parent, _, beta := testControllerWithSetup(t, configuration, content, nil, nil,
+		func(_, beta *testDirectoryEndpoint) {
+			beta.supplyStaging = true
+		},
+	)
+	defer os.RemoveAll(parent)
+
+	// Wait for the content to be propagated.
+	waitForSynchronizationCycles(t, c, 1)
+	state := c.currentState()
+
+	// Shut down the controller.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+
+	// Verify placement on beta.
+	expected := map[string]string{
+		"numbered/1": "file1",
+		"numbered/2": "file2",
+		"plain":      "plain",
+	}
+	for path, name := range expected {
+		if synchronized, err := ioutil.ReadFile(filepath.Join(beta.root, path)); err != nil {
+			t.Errorf("unable to read mapped file (%s): %v", path, err)
+		} else if !bytes.Equal(synchronized, content[name]) {
+			t.Errorf("mapped file (%s) content incorrect", path)
+		}
+	}
+	for _, name := range []string{"file1", "file2", "other"} {
+		if _, err := os.Lstat(filepath.Join(beta.root, name)); !os.IsNotExist(err) {
+			t.Errorf("file (%s) placed at unmapped path", name)
+		}
+	}
+
+	// Verify that the collision was reported.
+	if len(state.BetaProblems) != 1 {
+		t.Fatal("unexpected number of beta problems:", len(state.BetaProblems))
+	} else if problem := state.BetaProblems[0]; problem.Path != "other" {
+		t.Error("collision reported for unexpected path:", problem.Path)
+	} else if !strings.Contains(problem.Error, "collides with content from file1") {
+		t.Error("unexpected collision problem:", problem.Error)
+	}
+}
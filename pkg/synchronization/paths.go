@@ -1,6 +1,7 @@
 package synchronization
 
 import (
+	"fmt"
 	"path/filepath"
 
 	"github.com/pkg/errors"
@@ -34,3 +35,28 @@ func pathForArchive(session string) (string, error) {
 	// Success.
 	return filepath.Join(archivesDirectoryPath, session), nil
 }
+
+// pathForAdditionalArchive computes the path to the serialized archive for the
+// additional beta endpoint with the specified index, based on the path to the
+// session's primary archive.
+func pathForAdditionalArchive(archivePath string, index int) string {
+	return fmt.Sprintf("%s.beta%d", archivePath, index+1)
+}
+
+// pathForAdditionalMemberArchive computes the path to the serialized archive
+// for a container matched by the label selector of the additional beta
+// endpoint with the specified index.
+func pathForAdditionalMemberArchive(archivePath string, index int, member string) string {
+	return pathForAdditionalArchive(archivePath, index) + "-" + member
+}
+
+// additionalArchivePaths returns the paths of any existing archives for the
+// additional beta endpoint with the specified index, including those for
+// containers matched by a label selector. The returned paths may not exist.
+func additionalArchivePaths(archivePath string, index int) ([]string, error) {
+	members, err := filepath.Glob(pathForAdditionalMemberArchive(archivePath, index, "*"))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list label selector member archives")
+	}
+	return append([]string{pathForAdditionalArchive(archivePath, index)}, members...), nil
+}
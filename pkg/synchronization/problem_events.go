@@ -0,0 +1,196 @@
+package synchronization
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/mutagen-io/mutagen/pkg/selection"
+)
+
+// EnsureValid ensures that ProblemEventKind's invariants are respected.
+func (k ProblemEventKind) EnsureValid() error {
+	switch k {
+	case ProblemEventKind_ProblemEventKindAdded:
+	case ProblemEventKind_ProblemEventKindResolved:
+	default:
+		return errors.New("unknown problem event kind")
+	}
+	return nil
+}
+
+// EnsureValid ensures that ProblemEventCategory's invariants are respected.
+func (c ProblemEventCategory) EnsureValid() error {
+	switch c {
+	case ProblemEventCategory_ProblemEventCategoryAlphaProblem:
+	case ProblemEventCategory_ProblemEventCategoryBetaProblem:
+	case ProblemEventCategory_ProblemEventCategoryConflict:
+	default:
+		return errors.New("unknown problem event category")
+	}
+	return nil
+}
+
+// EnsureValid ensures that ProblemEvent's invariants are respected.
+func (e *ProblemEvent) EnsureValid() error {
+	// A nil problem event is not valid.
+	if e == nil {
+		return errors.New("nil problem event")
+	}
+
+	// Ensure that the session identifier is non-empty.
+	if e.Session == "" {
+		return errors.New("empty session identifier")
+	}
+
+	// Ensure that the kind and category are valid.
+	if err := e.Kind.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid kind")
+	} else if err = e.Category.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid category")
+	}
+
+	// Ensure that the time is valid.
+	if _, err := ptypes.Timestamp(e.Time); err != nil {
+		return errors.Wrap(err, "invalid time")
+	}
+
+	// Success.
+	return nil
+}
+
+// problemKey identifies a problem or conflict within a session. Problems whose
+// error messages change are treated as distinct problems.
+type problemKey struct {
+	// category is the problem category.
+	category ProblemEventCategory
+	// path is the problem path (or conflict root).
+	path string
+	// error is the problem error message.
+	error string
+}
+
+// currentProblems computes the set of problems and conflicts currently reported
+// by the session. As with currentNotificationConditions, it avoids the cost of
+// copying the full session state. Problems and conflicts omitted from the
+// session state due to truncation aren't included.
+func (c *controller) currentProblems() map[problemKey]bool {
+	// Lock the session state and defer its release. As with currentState, we
+	// have to unlock without a notification to avoid a notification cycle.
+	c.stateLock.Lock()
+	defer c.stateLock.UnlockWithoutNotify()
+
+	// Compute the problem set.
+	result := make(map[problemKey]bool, len(c.state.AlphaProblems)+len(c.state.BetaProblems)+len(c.state.Conflicts))
+	for _, problem := range c.state.AlphaProblems {
+		result[problemKey{ProblemEventCategory_ProblemEventCategoryAlphaProblem, problem.Path, problem.Error}] = true
+	}
+	for _, problem := range c.state.BetaProblems {
+		result[problemKey{ProblemEventCategory_ProblemEventCategoryBetaProblem, problem.Path, problem.Error}] = true
+	}
+	for _, conflict := range c.state.Conflicts {
+		result[problemKey{category: ProblemEventCategory_ProblemEventCategoryConflict, path: conflict.Root()}] = true
+	}
+	return result
+}
+
+// TailProblems streams problem and conflict events for the specified sessions
+// to the provided handler. The handler is first invoked with an event for each
+// problem and conflict currently reported (even if there are none), after which
+// it's invoked with additions and resolutions as they're observed. Sessions
+// that are created (if matched by the selection) or terminated have their
+// problems reported as added or resolved, respectively. Events within each
+// invocation are sorted by session, category, and path. The method runs until
+// the handler returns an error, the selection can't be resolved, state tracking
+// is terminated, or the provided context is cancelled.
+func (m *Manager) TailProblems(ctx context.Context, selection *selection.Selection, handler func([]*ProblemEvent) error) error {
+	// Track the problems of each session.
+	previous := make(map[string]map[problemKey]bool)
+
+	// Loop until cancellation or failure.
+	var stateIndex uint64
+	for initial := true; ; initial = false {
+		// Wait for a state change. On the first iteration, this returns
+		// immediately since the tracker's index starts at 1.
+		// TODO: As with List, we can't use the provided context to preempt
+		// this wait, so cancellation is only detected on state changes.
+		var poisoned bool
+		stateIndex, poisoned = m.tracker.WaitForChange(stateIndex)
+		if poisoned {
+			return errors.New("state tracking terminated")
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Extract the controllers for the sessions of interest.
+		controllers, err := m.selectControllers(selection)
+		if err != nil {
+			return errors.Wrap(err, "unable to locate requested sessions")
+		}
+
+		// Compute the current problems of each session and record additions.
+		now := ptypes.TimestampNow()
+		var events []*ProblemEvent
+		current := make(map[string]map[problemKey]bool, len(controllers))
+		for _, c := range controllers {
+			identifier := c.session.Identifier
+			problems := c.currentProblems()
+			current[identifier] = problems
+			for key := range problems {
+				if !previous[identifier][key] {
+					events = append(events, &ProblemEvent{
+						Session:  identifier,
+						Kind:     ProblemEventKind_ProblemEventKindAdded,
+						Category: key.category,
+						Path:     key.path,
+						Error:    key.error,
+						Time:     now,
+					})
+				}
+			}
+		}
+
+		// Record resolutions, including those for sessions that no longer
+		// exist (or no longer match the selection).
+		for identifier, problems := range previous {
+			for key := range problems {
+				if !current[identifier][key] {
+					events = append(events, &ProblemEvent{
+						Session:  identifier,
+						Kind:     ProblemEventKind_ProblemEventKindResolved,
+						Category: key.category,
+						Path:     key.path,
+						Error:    key.error,
+						Time:     now,
+					})
+				}
+			}
+		}
+
+		// Update problems.
+		previous = current
+
+		// Skip empty deltas, but always report the initial set.
+		if len(events) == 0 && !initial {
+			continue
+		}
+
+		// Sort events and invoke the handler.
+		sort.Slice(events, func(i, j int) bool {
+			if events[i].Session != events[j].Session {
+				return events[i].Session < events[j].Session
+			} else if events[i].Category != events[j].Category {
+				return events[i].Category < events[j].Category
+			} else if events[i].Path != events[j].Path {
+				return events[i].Path < events[j].Path
+			}
+			return events[i].Error < events[j].Error
+		})
+		if err := handler(events); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package synchronization
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/selection"
+	"github.com/mutagen-io/mutagen/pkg/state"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// testProblemEventTimeout is the maximum amount of time that tests will wait
+// for problem events.
+const testProblemEventTimeout = 5 * time.Second
+
+// testProblemEvent is a simplified representation of a problem event used for
+// comparison in tests.
+type testProblemEvent struct {
+	// session is the session identifier.
+	session string
+	// kind is the event kind.
+	kind ProblemEventKind
+	// category is the problem category.
+	category ProblemEventCategory
+	// path is the problem path.
+	path string
+}
+
+// receiveProblemEvents waits for the next batch of problem events and verifies
+// that it matches the expected events.
+func receiveProblemEvents(t *testing.T, batches <-chan []*ProblemEvent, expected []testProblemEvent) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Wait for a batch.
+	var batch []*ProblemEvent
+	select {
+	case batch = <-batches:
+	case <-time.After(testProblemEventTimeout):
+		t.Fatal("timed out waiting for problem events")
+	}
+
+	// Verify the batch.
+	if len(batch) != len(expected) {
+		t.Fatalf("received %d events, expected %d", len(batch), len(expected))
+	}
+	for e, event := range batch {
+		if err := event.EnsureValid(); err != nil {
+			t.Error("invalid problem event:", err)
+		}
+		actual := testProblemEvent{event.Session, event.Kind, event.Category, event.Path}
+		if actual != expected[e] {
+			t.Errorf("event %d (%v) does not match expected (%v)", e, actual, expected[e])
+		}
+	}
+}
+
+// TestManagerTailProblems tests that problem tailing reports the current set of
+// problems and conflicts to subscribers, followed by additions and resolutions.
+func TestManagerTailProblems(t *testing.T) {
+	// Create a manager with two sessions, one of which has existing problems.
+	tracker := state.NewTracker()
+	sessions := make(map[string]*controller)
+	for _, identifier := range []string{"first", "second"} {
+		session := &Session{Identifier: identifier}
+		sessions[identifier] = &controller{
+			logger:    logging.RootLogger.Sublogger(identifier),
+			stateLock: state.NewTrackingLock(tracker),
+			session:   session,
+			state:     &State{Session: session},
+		}
+	}
+	first, second := sessions["first"], sessions["second"]
+	first.state.AlphaProblems = []*core.Problem{{Path: "a", Error: "alpha failure"}}
+	first.state.Conflicts = []*core.Conflict{{
+		AlphaChanges: []*core.Change{{Path: "c", New: &core.Entry{}}},
+		BetaChanges:  []*core.Change{{Path: "c", New: &core.Entry{Kind: core.EntryKind_File}}},
+	}}
+	manager := &Manager{
+		logger:       logging.RootLogger.Sublogger("manager"),
+		tracker:      tracker,
+		sessionsLock: state.NewTrackingLock(tracker),
+		sessions:     sessions,
+	}
+
+	// Start tailing problems for all sessions.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	batches := make(chan []*ProblemEvent, 16)
+	tailErrors := make(chan error, 1)
+	go func() {
+		tailErrors <- manager.TailProblems(ctx, &selection.Selection{All: true}, func(events []*ProblemEvent) error {
+			batches <- events
+			return nil
+		})
+	}()
+
+	// Verify that the current problem set is reported first.
+	receiveProblemEvents(t, batches, []testProblemEvent{
+		{"first", ProblemEventKind_ProblemEventKindAdded, ProblemEventCategory_ProblemEventCategoryAlphaProblem, "a"},
+		{"first", ProblemEventKind_ProblemEventKindAdded, ProblemEventCategory_ProblemEventCategoryConflict, "c"},
+	})
+
+	// Trigger a beta problem in the second session and verify that only the
+	// addition is reported.
+	second.stateLock.Lock()
+	second.state.BetaProblems = []*core.Problem{{Path: "b", Error: "beta failure"}}
+	second.stateLock.Unlock()
+	receiveProblemEvents(t, batches, []testProblemEvent{
+		{"second", ProblemEventKind_ProblemEventKindAdded, ProblemEventCategory_ProblemEventCategoryBetaProblem, "b"},
+	})
+
+	// Resolve the first session's alpha problem and conflict and verify that
+	// the resolutions are reported.
+	first.stateLock.Lock()
+	first.state.AlphaProblems = nil
+	first.state.Conflicts = nil
+	first.stateLock.Unlock()
+	receiveProblemEvents(t, batches, []testProblemEvent{
+		{"first", ProblemEventKind_ProblemEventKindResolved, ProblemEventCategory_ProblemEventCategoryAlphaProblem, "a"},
+		{"first", ProblemEventKind_ProblemEventKindResolved, ProblemEventCategory_ProblemEventCategoryConflict, "c"},
+	})
+
+	// Verify that a late subscriber to a single session receives that
+	// session's current problem set.
+	lateBatches := make(chan []*ProblemEvent, 16)
+	go manager.TailProblems(ctx, &selection.Selection{Specifications: []string{"second"}}, func(events []*ProblemEvent) error {
+		lateBatches <- events
+		return nil
+	})
+	receiveProblemEvents(t, lateBatches, []testProblemEvent{
+		{"second", ProblemEventKind_ProblemEventKindAdded, ProblemEventCategory_ProblemEventCategoryBetaProblem, "b"},
+	})
+
+	// Verify that state changes that don't affect problems aren't reported.
+	first.stateLock.Lock()
+	first.state.SuccessfulSynchronizationCycles++
+	first.stateLock.Unlock()
+	select {
+	case batch := <-batches:
+		t.Error("unexpected problem events:", batch)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Cancel tailing and verify that it terminates on the next state change.
+	cancel()
+	tracker.NotifyOfChange()
+	select {
+	case err := <-tailErrors:
+		if err != context.Canceled {
+			t.Error("unexpected tailing error:", err)
+		}
+	case <-time.After(testProblemEventTimeout):
+		t.Fatal("timed out waiting for tailing to terminate")
+	}
+}
@@ -45,8 +45,14 @@ func (h *protocolHandler) Connect(
 		panic("non-Docker URL dispatched to Docker protocol handler")
 	}
 
+	// Label selectors are resolved to individual containers by the controller,
+	// so we can't connect to them directly.
+	if _, ok := url.DockerLabelSelector(); ok {
+		return nil, errors.New("unable to connect to label selector (only supported for additional beta endpoints)")
+	}
+
 	// Create a Docker agent transport.
-	transport, err := docker.NewTransport(url.Host, url.User, url.Environment, url.Parameters, prompter)
+	transport, err := docker.NewTransport(url.Host, url.User, url.Environment, url.Parameters, prompter, configuration.AgentResourceLimits())
 	if err != nil {
 		return nil, fmt.Errorf("unable to create Docker transport: %w", err)
 	}
@@ -51,6 +51,38 @@ func (h *protocolHandler) Connect(
 	return endpoint, nil
 }
 
+// ListStaged lists the content staged by a local endpoint.
+func (h *protocolHandler) ListStaged(
+	url *urlpkg.URL,
+	session string,
+	version synchronization.Version,
+	configuration *synchronization.Configuration,
+	alpha bool,
+	verify bool,
+) ([]*synchronization.StagedEntry, error) {
+	// Verify that the URL is of the correct kind and protocol.
+	if url.Kind != urlpkg.Kind_Synchronization {
+		panic("non-synchronization URL dispatched to synchronization protocol handler")
+	} else if url.Protocol != urlpkg.Protocol_Local {
+		panic("non-local URL dispatched to local protocol handler")
+	}
+
+	// Compute the effective staging mode.
+	stageMode := configuration.StageMode
+	if stageMode.IsDefault() {
+		stageMode = version.DefaultStageMode()
+	}
+
+	// Compute the staging root.
+	root, err := local.StagingRoot(session, alpha, url.Path, stageMode)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to compute staging root")
+	}
+
+	// Perform listing.
+	return local.ListStaged(root, version.Hasher(), verify)
+}
+
 func init() {
 	// Register the local protocol handler with the synchronization package.
 	synchronization.ProtocolHandlers[urlpkg.Protocol_Local] = &protocolHandler{}
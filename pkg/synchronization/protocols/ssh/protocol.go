@@ -9,6 +9,7 @@ import (
 	"github.com/mutagen-io/mutagen/pkg/agent"
 	"github.com/mutagen-io/mutagen/pkg/agent/transports/ssh"
 	"github.com/mutagen-io/mutagen/pkg/logging"
+	sshpkg "github.com/mutagen-io/mutagen/pkg/ssh"
 	"github.com/mutagen-io/mutagen/pkg/synchronization"
 	"github.com/mutagen-io/mutagen/pkg/synchronization/endpoint/remote"
 	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
@@ -45,16 +46,42 @@ func (h *protocolHandler) Connect(
 		panic("non-SSH URL dispatched to SSH protocol handler")
 	}
 
-	// Ensure that no environment variables or parameters are specified. These
-	// are neither expected nor supported for SSH URLs.
+	// Ensure that no environment variables are specified. These are neither
+	// expected nor supported for SSH URLs.
 	if len(url.Environment) > 0 {
 		return nil, errors.New("SSH URL contains environment variables")
-	} else if len(url.Parameters) > 0 {
-		return nil, errors.New("SSH URL contains internal parameters")
 	}
 
+	// Load any SSH options specified via URL parameters (and the URL port) and
+	// merge them with the session's SSH options, with the URL-based options
+	// taking precedence.
+	urlOptions, err := sshpkg.LoadOptionsFromURLParameters(url.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH URL parameters: %w", err)
+	}
+	if url.Port != 0 {
+		urlOptions.Port = url.Port
+	}
+	options := sshpkg.MergeOptions(configuration.SshOptions, urlOptions)
+
+	// Note that the user specified in the URL isn't part of the URL-based
+	// options, so a user specified in the session's SSH options survives the
+	// merge and (by design) takes precedence over the URL user when the
+	// transport composes the destination.
+
+	// Compute the effective host verification mode and warn if it relaxes host
+	// key verification.
+	hostVerificationMode := configuration.HostVerificationMode
+	if hostVerificationMode.IsDefault() {
+		hostVerificationMode = version.DefaultHostVerificationMode()
+	}
+	if warning := hostVerificationMode.Warning(); warning != "" {
+		logger.Warningf("Connecting to %s: %s", url.Host, warning)
+	}
+	ephemeralHost := hostVerificationMode == synchronization.HostVerificationMode_HostVerificationModeEphemeral
+
 	// Create an SSH agent transport.
-	transport, err := ssh.NewTransport(url.User, url.Host, uint16(url.Port), prompter)
+	transport, err := ssh.NewTransport(url.User, url.Host, options, prompter, ephemeralHost, configuration.AgentResourceLimits(), configuration.AgentCacheHost)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create SSH transport: %w", err)
 	}
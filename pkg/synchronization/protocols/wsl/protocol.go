@@ -0,0 +1,101 @@
+package wsl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/mutagen-io/mutagen/pkg/agent"
+	"github.com/mutagen-io/mutagen/pkg/agent/transports/wsl"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/endpoint/remote"
+	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
+	wslpkg "github.com/mutagen-io/mutagen/pkg/wsl"
+)
+
+// protocolHandler implements the synchronization.ProtocolHandler interface for
+// connecting to remote endpoints inside WSL distributions. It uses the agent
+// infrastructure over a WSL transport.
+type protocolHandler struct{}
+
+// dialResult provides asynchronous agent dialing results.
+type dialResult struct {
+	// connection is the connection returned by agent dialing.
+	connection net.Conn
+	// error is the error returned by agent dialing.
+	error error
+}
+
+// Connect connects to a WSL endpoint.
+func (h *protocolHandler) Connect(
+	ctx context.Context,
+	logger *logging.Logger,
+	url *urlpkg.URL,
+	prompter string,
+	session string,
+	version synchronization.Version,
+	configuration *synchronization.Configuration,
+	alpha bool,
+) (synchronization.Endpoint, error) {
+	// Verify that the URL is of the correct kind and protocol.
+	if url.Kind != urlpkg.Kind_Synchronization {
+		panic("non-synchronization URL dispatched to synchronization protocol handler")
+	} else if url.Protocol != urlpkg.Protocol_WSL {
+		panic("non-WSL URL dispatched to WSL protocol handler")
+	}
+
+	// Translate the synchronization root to its location inside the
+	// distribution. Windows paths are mapped to their automount locations,
+	// while Linux paths are used as-is.
+	path, err := wslpkg.WindowsPathToLinux(url.Host, url.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to translate synchronization root: %w", err)
+	}
+
+	// Create a WSL agent transport.
+	transport, err := wsl.NewTransport(url.Host, url.User)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create WSL transport: %w", err)
+	}
+
+	// Create a channel to deliver the dialing result.
+	results := make(chan dialResult)
+
+	// Perform dialing in a background Goroutine so that we can monitor for
+	// cancellation.
+	go func() {
+		// Perform the dialing operation.
+		connection, err := agent.Dial(logger, transport, agent.ModeSynchronizer, prompter)
+
+		// Transmit the result or, if cancelled, close the connection.
+		select {
+		case results <- dialResult{connection, err}:
+		case <-ctx.Done():
+			if connection != nil {
+				connection.Close()
+			}
+		}
+	}()
+
+	// Wait for dialing results or cancellation.
+	var connection net.Conn
+	select {
+	case result := <-results:
+		if result.error != nil {
+			return nil, fmt.Errorf("unable to dial agent endpoint: %w", result.error)
+		}
+		connection = result.connection
+	case <-ctx.Done():
+		return nil, errors.New("connect operation cancelled")
+	}
+
+	// Create the endpoint client.
+	return remote.NewEndpoint(connection, path, session, version, configuration, alpha)
+}
+
+func init() {
+	// Register the WSL protocol handler with the synchronization package.
+	synchronization.ProtocolHandlers[urlpkg.Protocol_WSL] = &protocolHandler{}
+}
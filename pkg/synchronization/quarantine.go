@@ -0,0 +1,141 @@
+package synchronization
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// pathAtOrWithin returns whether or not a path is equal to or lies within the
+// specified parent path.
+func pathAtOrWithin(parent, path string) bool {
+	if parent == "" || path == parent {
+		return true
+	}
+	return len(path) > len(parent)+1 &&
+		path[len(parent)] == '/' &&
+		strings.HasPrefix(path, parent)
+}
+
+// quarantineTracker tracks consecutive per-path transition failures and the
+// set of paths that have been quarantined as a result. Quarantined paths are
+// excluded from transitions until they're cleared. Tracking isn't persisted, so
+// failure counts and quarantined paths are reset when the daemon restarts. The
+// zero value is ready for use.
+type quarantineTracker struct {
+	// failures maps paths to the number of consecutive synchronization cycles
+	// in which their transitions have failed.
+	failures map[string]uint64
+	// quarantined is the set of quarantined paths.
+	quarantined map[string]bool
+}
+
+// isQuarantined returns whether or not the specified path is at or within a
+// quarantined path.
+func (q *quarantineTracker) isQuarantined(path string) bool {
+	for quarantined := range q.quarantined {
+		if pathAtOrWithin(quarantined, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the subset of transitions that don't target quarantined
+// paths. If no paths are quarantined, then the transitions are returned
+// unmodified.
+func (q *quarantineTracker) filter(transitions []*core.Change) []*core.Change {
+	if len(q.quarantined) == 0 {
+		return transitions
+	}
+	var result []*core.Change
+	for _, transition := range transitions {
+		if !q.isQuarantined(transition.Path) {
+			result = append(result, transition)
+		}
+	}
+	return result
+}
+
+// record records the outcome of a transition operation on an endpoint. Paths
+// with problems have their failure counts incremented, with paths whose counts
+// reach the specified threshold being quarantined, while tracked paths at or
+// within a transition path that encountered no problems have their failure
+// counts reset. It returns any newly quarantined paths in sorted order. A zero
+// threshold disables tracking.
+func (q *quarantineTracker) record(threshold uint64, transitions []*core.Change, problems []*core.Problem) []string {
+	// If quarantining is disabled, then there's nothing to track.
+	if threshold == 0 {
+		return nil
+	}
+
+	// Determine which paths failed.
+	failed := make(map[string]bool, len(problems))
+	for _, problem := range problems {
+		failed[problem.Path] = true
+	}
+
+	// Reset the failure counts of tracked paths that were transitioned
+	// successfully.
+	for path := range q.failures {
+		if failed[path] {
+			continue
+		}
+		for _, transition := range transitions {
+			if pathAtOrWithin(transition.Path, path) {
+				delete(q.failures, path)
+				break
+			}
+		}
+	}
+
+	// Increment the failure counts of failed paths and quarantine those that
+	// have reached the threshold.
+	var quarantined []string
+	for path := range failed {
+		if q.failures == nil {
+			q.failures = make(map[string]uint64)
+		}
+		q.failures[path]++
+		if q.failures[path] >= threshold {
+			if q.quarantined == nil {
+				q.quarantined = make(map[string]bool)
+			}
+			q.quarantined[path] = true
+			delete(q.failures, path)
+			quarantined = append(quarantined, path)
+		}
+	}
+	sort.Strings(quarantined)
+	return quarantined
+}
+
+// paths returns the quarantined paths in sorted order.
+func (q *quarantineTracker) paths() []string {
+	if len(q.quarantined) == 0 {
+		return nil
+	}
+	result := make([]string, 0, len(q.quarantined))
+	for path := range q.quarantined {
+		result = append(result, path)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// clear releases all quarantined paths and resets all failure counts so that
+// the paths are retried.
+func (q *quarantineTracker) clear() {
+	q.failures = nil
+	q.quarantined = nil
+}
+
+// retryQuarantined releases the session's quarantined paths (and resets all
+// failure counts) so that they're retried by the next synchronization cycle.
+func (c *controller) retryQuarantined() {
+	c.stateLock.Lock()
+	c.quarantine.clear()
+	c.state.QuarantinedPaths = nil
+	c.stateLock.Unlock()
+}
@@ -0,0 +1,183 @@
+package synchronization
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// TestQuarantineTracker tests quarantineTracker.
+func TestQuarantineTracker(t *testing.T) {
+	// Create a tracker and helpers for creating transitions and problems.
+	tracker := &quarantineTracker{}
+	transitions := func(paths ...string) []*core.Change {
+		result := make([]*core.Change, len(paths))
+		for p, path := range paths {
+			result[p] = &core.Change{Path: path}
+		}
+		return result
+	}
+	problems := func(paths ...string) []*core.Problem {
+		result := make([]*core.Problem, len(paths))
+		for p, path := range paths {
+			result[p] = &core.Problem{Path: path, Error: "failed"}
+		}
+		return result
+	}
+	const threshold = 3
+
+	// Verify that nothing is tracked if quarantining is disabled.
+	for i := 0; i < threshold; i++ {
+		if quarantined := tracker.record(0, transitions("bad"), problems("bad")); len(quarantined) != 0 {
+			t.Fatal("path quarantined with quarantining disabled")
+		}
+	}
+	if len(tracker.failures) != 0 {
+		t.Error("failures tracked with quarantining disabled")
+	}
+
+	// Record failures short of the threshold and verify that a success resets
+	// the failure count.
+	for i := 0; i < threshold-1; i++ {
+		if quarantined := tracker.record(threshold, transitions("bad", "good"), problems("bad")); len(quarantined) != 0 {
+			t.Fatal("path quarantined before reaching threshold")
+		}
+	}
+	tracker.record(threshold, transitions("bad"), nil)
+	if len(tracker.failures) != 0 {
+		t.Error("failure count not reset by success")
+	}
+
+	// Record consecutive failures up to the threshold and verify that the path
+	// is quarantined. Failures within a transitioned directory are attributed
+	// to the failing path.
+	for i := 0; i < threshold-1; i++ {
+		tracker.record(threshold, transitions("bad", "directory"), problems("bad", "directory/bad"))
+	}
+	quarantined := tracker.record(threshold, transitions("bad", "directory/bad"), problems("bad", "directory/bad"))
+	if len(quarantined) != 2 || quarantined[0] != "bad" || quarantined[1] != "directory/bad" {
+		t.Fatal("unexpected newly quarantined paths:", quarantined)
+	}
+	if paths := tracker.paths(); len(paths) != 2 || paths[0] != "bad" || paths[1] != "directory/bad" {
+		t.Error("unexpected quarantined paths:", paths)
+	}
+
+	// Verify that transitions at or within quarantined paths are filtered.
+	filtered := tracker.filter(transitions("bad", "bad/child", "badge", "directory", "directory/bad", "good"))
+	var filteredPaths []string
+	for _, transition := range filtered {
+		filteredPaths = append(filteredPaths, transition.Path)
+	}
+	if !stringSlicesEqual(filteredPaths, []string{"badge", "directory", "good"}) {
+		t.Error("unexpected filtered transitions:", filteredPaths)
+	}
+
+	// Verify that clearing releases all paths.
+	tracker.clear()
+	if paths := tracker.paths(); len(paths) != 0 {
+		t.Error("quarantined paths remain after clearing:", paths)
+	} else if filtered := tracker.filter(transitions("bad")); len(filtered) != 1 {
+		t.Error("transitions filtered after clearing")
+	}
+}
+
+// TestControllerQuarantine tests that a path whose transition fails
+// persistently is quarantined after the configured number of consecutive
+// failures, that other paths continue to be synchronized, and that the path is
+// synchronized once retried.
+func TestControllerQuarantine(t *testing.T) {
+	// Create a controller with a quarantine threshold whose beta endpoint fails
+	// the transition for one path.
+	const threshold = 2
+	content := map[string][]byte{
+		"good": []byte("good content"),
+		"bad":  []byte("bad content"),
+	}
+	var failing int32 = 1
+	configuration := &Configuration{QuarantineThreshold: threshold}
+	c, parent, alpha, beta := testControllerWithSetup(t, configuration, content, nil, nil,
+		func(_, beta *testDirectoryEndpoint) {
+			beta.failTransition = func(path string) bool {
+				return path == "bad" && atomic.LoadInt32(&failing) == 1
+			}
+		},
+	)
+	defer os.RemoveAll(parent)
+
+	// Wait for the initial cycle and verify that the path isn't yet
+	// quarantined but that its failure is reported.
+	waitForSynchronizationCycles(t, c, 1)
+	state := c.currentState()
+	if len(state.QuarantinedPaths) != 0 {
+		t.Error("path quarantined before reaching threshold:", state.QuarantinedPaths)
+	} else if len(state.BetaProblems) != 1 || state.BetaProblems[0].Path != "bad" {
+		t.Error("unexpected beta problems:", state.BetaProblems)
+	}
+
+	// Force cycles until the threshold is reached and verify that the path is
+	// quarantined.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for i := 1; i < threshold; i++ {
+		if err := c.flush(ctx, "", false, nil, false); err != nil {
+			t.Fatal("flush failed:", err)
+		}
+	}
+	state = c.currentState()
+	if len(state.QuarantinedPaths) != 1 || state.QuarantinedPaths[0] != "bad" {
+		t.Fatal("unexpected quarantined paths:", state.QuarantinedPaths)
+	}
+
+	// Create a new file on alpha and verify that it's synchronized without the
+	// quarantined path being attempted or reported as a problem.
+	if err := ioutil.WriteFile(filepath.Join(alpha.root, "new"), []byte("new content"), 0600); err != nil {
+		t.Fatal("unable to create alpha content:", err)
+	} else if err = c.flush(ctx, "", false, nil, false); err != nil {
+		t.Fatal("flush failed:", err)
+	}
+	for _, name := range []string{"good", "new"} {
+		if _, err := os.Stat(filepath.Join(beta.root, name)); err != nil {
+			t.Error("file not synchronized to beta:", name)
+		}
+	}
+	if _, err := os.Lstat(filepath.Join(beta.root, "bad")); err == nil {
+		t.Error("failing file unexpectedly synchronized to beta")
+	}
+	for _, transition := range beta.transitions[len(beta.transitions)-1] {
+		if transition.Path == "bad" {
+			t.Error("quarantined path transitioned")
+		}
+	}
+	if state = c.currentState(); len(state.BetaProblems) != 0 {
+		t.Error("unexpected beta problems after quarantining:", state.BetaProblems)
+	} else if len(state.QuarantinedPaths) != 1 {
+		t.Error("quarantined path released unexpectedly")
+	}
+
+	// Fix the failure, retry the quarantined path, and verify that it's
+	// synchronized.
+	atomic.StoreInt32(&failing, 0)
+	c.retryQuarantined()
+	if err := c.flush(ctx, "", false, nil, false); err != nil {
+		t.Fatal("flush failed:", err)
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(beta.root, "bad")); err != nil {
+		t.Error("retried file not synchronized to beta:", err)
+	} else if string(data) != "bad content" {
+		t.Error("retried file content incorrect")
+	}
+	if state = c.currentState(); len(state.QuarantinedPaths) != 0 {
+		t.Error("quarantined paths remain after retry:", state.QuarantinedPaths)
+	}
+
+	// Shut down the controller.
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+}
@@ -0,0 +1,60 @@
+package synchronization
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/prompting"
+)
+
+// reconnect forces an immediate connection attempt for a session that's
+// waiting to reconnect to its endpoints (e.g. after a connection failure),
+// bypassing the remainder of the synchronization loop's reconnection wait and
+// resetting its failure tracking. It waits for the connection attempt to
+// complete and returns the resulting session state. It fails if the session is
+// paused or isn't currently waiting to reconnect (e.g. because it's already
+// connected or a connection attempt is already in progress).
+func (c *controller) reconnect(ctx context.Context, prompter string) (*State, error) {
+	// Update status.
+	prompting.Message(prompter, fmt.Sprintf("Reconnecting session %s...", c.session.Identifier))
+
+	// Lock the controller's lifecycle and grab the channels that we need to
+	// communicate with the synchronization loop. We don't hold the lifecycle
+	// lock while waiting for the connection attempt, since connection attempts
+	// can take a while and shouldn't block other lifecycle operations.
+	c.lifecycleLock.Lock()
+	if c.disabled {
+		c.lifecycleLock.Unlock()
+		return nil, errors.New("controller disabled")
+	} else if c.cancel == nil {
+		c.lifecycleLock.Unlock()
+		return nil, errors.New("session is paused")
+	}
+	requests, done := c.reconnectRequests, c.done
+	c.lifecycleLock.Unlock()
+
+	// Send the reconnect request in a non-blocking manner. Since the request
+	// channel is unbuffered, this will only succeed if the synchronization loop
+	// is waiting to reconnect.
+	completed := make(chan struct{})
+	select {
+	case requests <- completed:
+	default:
+		return nil, errors.New("session is not awaiting reconnection")
+	}
+
+	// Wait for the connection attempt to complete, watching for termination of
+	// the synchronization loop and cancellation in the mean time.
+	select {
+	case <-completed:
+	case <-done:
+		return nil, errors.New("synchronization loop terminated during reconnection")
+	case <-ctx.Done():
+		return nil, errors.New("reconnection cancelled while waiting for connection attempt")
+	}
+
+	// Success.
+	return c.currentState(), nil
+}
@@ -0,0 +1,176 @@
+package synchronization
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/selection"
+	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// testReconnectProtocolHandler is a protocol handler that yields pre-existing
+// endpoints once they've been marked as available and fails to connect
+// otherwise.
+type testReconnectProtocolHandler struct {
+	// alpha is the alpha endpoint.
+	alpha Endpoint
+	// beta is the beta endpoint.
+	beta Endpoint
+	// lock guards available and attempts.
+	lock sync.Mutex
+	// available indicates whether or not the endpoints are available.
+	available bool
+	// attempts is the number of connection attempts made.
+	attempts int
+}
+
+// setAvailable sets the availability of the handler's endpoints.
+func (h *testReconnectProtocolHandler) setAvailable(available bool) {
+	h.lock.Lock()
+	h.available = available
+	h.lock.Unlock()
+}
+
+// connectionAttempts returns the number of connection attempts made.
+func (h *testReconnectProtocolHandler) connectionAttempts() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.attempts
+}
+
+// Connect implements ProtocolHandler.Connect.
+func (h *testReconnectProtocolHandler) Connect(
+	_ context.Context,
+	_ *logging.Logger,
+	_ *urlpkg.URL,
+	_ string,
+	_ string,
+	_ Version,
+	_ *Configuration,
+	alpha bool,
+) (Endpoint, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.attempts++
+	if !h.available {
+		return nil, errors.New("endpoint unavailable")
+	} else if alpha {
+		return h.alpha, nil
+	}
+	return h.beta, nil
+}
+
+// TestControllerReconnect tests that a session waiting to reconnect after a
+// connection failure reconnects immediately upon request, without waiting for
+// its next automatic reconnection attempt, and that reconnection is rejected
+// for paused sessions.
+func TestControllerReconnect(t *testing.T) {
+	// Create a controller and wait for it to complete its initial
+	// synchronization cycle.
+	content := map[string][]byte{"file": []byte("content")}
+	c, parent, alpha, beta := testControllerWithConfiguration(t, &Configuration{}, content, nil, nil)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Register a protocol handler for local URLs that yields the controller's
+	// endpoints once they're available and defer restoration of the original
+	// handler.
+	handler := &testReconnectProtocolHandler{alpha: alpha, beta: beta}
+	originalHandler, originalHandlerRegistered := ProtocolHandlers[urlpkg.Protocol_Local]
+	ProtocolHandlers[urlpkg.Protocol_Local] = handler
+	defer func() {
+		if originalHandlerRegistered {
+			ProtocolHandlers[urlpkg.Protocol_Local] = originalHandler
+		} else {
+			delete(ProtocolHandlers, urlpkg.Protocol_Local)
+		}
+	}()
+	c.session.Alpha = &urlpkg.URL{Kind: urlpkg.Kind_Synchronization, Protocol: urlpkg.Protocol_Local, Path: alpha.root}
+	c.session.Beta = &urlpkg.URL{Kind: urlpkg.Kind_Synchronization, Protocol: urlpkg.Protocol_Local, Path: beta.root}
+
+	// Pause the session and verify that reconnection is rejected.
+	if err := c.halt(context.Background(), controllerHaltModePause, "", false); err != nil {
+		t.Fatal("unable to pause session:", err)
+	}
+	if _, err := c.reconnect(context.Background(), ""); err == nil {
+		t.Error("reconnection succeeded for paused session")
+	} else if !strings.Contains(err.Error(), "paused") {
+		t.Error("unexpected reconnection error for paused session:", err)
+	}
+
+	// Resume the session while its endpoints are unavailable and wait for its
+	// synchronization loop to fail its own connection attempts, which will
+	// leave it waiting to reconnect. Each attempt involves connecting to both
+	// alpha and beta.
+	if err := c.resume(context.Background(), "", false); err == nil {
+		t.Error("resumption succeeded with unavailable endpoints")
+	}
+	deadline := time.Now().Add(autoReconnectInterval / 3)
+	for handler.connectionAttempts() < 4 {
+		if time.Now().After(deadline) {
+			t.Fatal("synchronization loop didn't attempt connection")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Make the endpoints available and request reconnection. The
+	// synchronization loop may still be recording its connection failure, in
+	// which case reconnection will be rejected, so we retry for a period
+	// that's well short of the automatic reconnection interval.
+	handler.setAvailable(true)
+	start := time.Now()
+	var state *State
+	for {
+		var err error
+		if state, err = c.reconnect(context.Background(), ""); err == nil {
+			break
+		} else if !strings.Contains(err.Error(), "not awaiting reconnection") {
+			t.Fatal("reconnection failed:", err)
+		} else if time.Since(start) > autoReconnectInterval/3 {
+			t.Fatal("session never awaited reconnection")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Verify that the session reconnected before its next automatic
+	// reconnection attempt and that the returned state reflects this.
+	if time.Since(start) >= autoReconnectInterval {
+		t.Error("reconnection not performed immediately")
+	}
+	if !state.AlphaConnected || !state.BetaConnected {
+		t.Error("reconnected state doesn't indicate connection")
+	}
+
+	// Verify that reconnection is rejected for a connected session.
+	if _, err := c.reconnect(context.Background(), ""); err == nil {
+		t.Error("reconnection succeeded for connected session")
+	}
+
+	// Halt the session.
+	if err := c.halt(context.Background(), controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+}
+
+// TestManagerReconnectPaused tests that the manager rejects reconnection of a
+// paused session.
+func TestManagerReconnectPaused(t *testing.T) {
+	withTestManager(t, 0, func(manager *Manager) {
+		// Create a session and pause it.
+		identifier := testManagerCreate(t, manager, 1)[0]
+		if err := manager.Pause(context.Background(), &selection.Selection{Specifications: []string{identifier}}, ""); err != nil {
+			t.Fatal("unable to pause session:", err)
+		}
+
+		// Verify that reconnection is rejected.
+		if _, err := manager.Reconnect(context.Background(), identifier, ""); err == nil {
+			t.Error("reconnection succeeded for paused session")
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package synchronization
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/prompting"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// relocate changes the URL of one of the session's primary endpoints. The
+// session must be paused. The new synchronization root is scanned and any
+// ancestor content that it doesn't agree with is pruned from the session's
+// archive(s), so that content missing from or different at the new location
+// is reconciled conservatively (i.e. as creations or conflicts) rather than
+// being treated as deleted or modified. The session's identity, labels, and
+// remaining history are preserved. If any history is discarded, then a
+// warning describing the divergence is returned.
+func (c *controller) relocate(ctx context.Context, beta bool, target *url.URL, prompter string) (string, error) {
+	// Update status.
+	prompting.Message(prompter, fmt.Sprintf("Relocating session %s...", c.session.Identifier))
+
+	// Lock the controller's lifecycle and defer its release.
+	c.lifecycleLock.Lock()
+	defer c.lifecycleLock.Unlock()
+
+	// Don't allow any relocation operations if the controller is disabled.
+	if c.disabled {
+		return "", errors.New("controller disabled")
+	}
+
+	// Relocation is only allowed for paused sessions, since we need to modify
+	// the archive(s) without the synchronization loop using them.
+	if c.cancel != nil {
+		return "", errors.New("session must be paused to relocate")
+	}
+
+	// Determine which endpoint is being relocated and validate the target.
+	description, current, opposite := "alpha", c.session.Alpha, c.session.Beta
+	configuration := c.mergedAlphaConfiguration
+	if beta {
+		description, current, opposite = "beta", c.session.Beta, c.session.Alpha
+		configuration = c.mergedBetaConfiguration
+	}
+	if err := target.EnsureValid(); err != nil {
+		return "", errors.Wrap(err, "invalid URL")
+	} else if target.Kind != url.Kind_Synchronization {
+		return "", errors.New("URL is not a synchronization URL")
+	} else if target.Protocol != current.Protocol {
+		return "", errors.New("relocation can't change endpoint protocol")
+	} else if target.Equal(current) {
+		return "", errors.Errorf("URL is identical to current %s URL", description)
+	} else if target.Equal(opposite) {
+		return "", errors.New("URL is identical to opposite endpoint URL")
+	}
+
+	// Connect to the new location. This validates the new synchronization
+	// root using the same checks as session creation.
+	endpoint, err := connect(
+		ctx,
+		c.logger.Sublogger(description),
+		target,
+		prompter,
+		c.session.Identifier,
+		c.session.Version,
+		configuration,
+		!beta,
+	)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to connect to new %s", description)
+	}
+
+	// Perform a full scan of the new location, forcing digest recomputation
+	// since any cached digests describe the old location. We don't provide an
+	// ancestor because we don't want its content to be treated as a baseline
+	// for the new location.
+	prompting.Message(prompter, fmt.Sprintf("Scanning new %s...", description))
+	snapshot, preservesExecutability, _, scanErr, _ := endpoint.Scan(ctx, nil, true, true, nil)
+	endpoint.Shutdown()
+	if scanErr != nil {
+		return "", errors.Wrapf(scanErr, "unable to scan new %s", description)
+	}
+
+	// Load the primary archive and determine which archives need to be
+	// pruned. Additional beta archives are only affected by alpha relocation.
+	archive, err := loadArchive(c.logger, c.archivePath)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to load archive")
+	}
+	archivePaths := []string{c.archivePath}
+	archives := []*core.Archive{archive}
+	if !beta {
+		for i := range c.session.AdditionalBetas {
+			additionalPaths, err := additionalArchivePaths(c.archivePath, i)
+			if err != nil {
+				return "", err
+			}
+			for _, archivePath := range additionalPaths {
+				if additional, err := loadArchive(c.logger, archivePath); os.IsNotExist(err) {
+					continue
+				} else if err != nil {
+					return "", errors.Wrap(err, "unable to load additional beta archive")
+				} else {
+					archivePaths = append(archivePaths, archivePath)
+					archives = append(archives, additional)
+				}
+			}
+		}
+	}
+
+	// Prune the archives against the snapshot. If the new location doesn't
+	// preserve executability, then we propagate executability from each
+	// ancestor so that executability bits alone don't invalidate history.
+	var total, retained uint64
+	for _, archive := range archives {
+		comparison := snapshot
+		if !preservesExecutability {
+			comparison = core.PropagateExecutability(archive.Root, archive.Root, snapshot)
+		}
+		total += archive.Root.Count()
+		archive.Root = core.PruneAncestor(archive.Root, comparison)
+		retained += archive.Root.Count()
+	}
+
+	// Save the pruned archives.
+	for a, archive := range archives {
+		if err := saveArchive(archivePaths[a], archive, c.archiveCompressionMode()); err != nil {
+			return "", errors.Wrap(err, "unable to save archive")
+		}
+	}
+
+	// Update the session and save it to disk.
+	c.stateLock.Lock()
+	if beta {
+		c.session.Beta = target
+	} else {
+		c.session.Alpha = target
+	}
+	saveErr := encoding.MarshalAndSaveProtobuf(c.sessionPath, c.session)
+	c.stateLock.Unlock()
+	if saveErr != nil {
+		return "", errors.Wrap(saveErr, "unable to save session")
+	}
+
+	// If history was discarded, then generate a warning.
+	if retained < total {
+		warning := fmt.Sprintf(
+			"%d of %d previously synchronized entries differ at the new %s location and will be conservatively reconciled",
+			total-retained, total, description,
+		)
+		c.logger.Warning(warning)
+		return warning, nil
+	}
+
+	// Success.
+	return "", nil
+}
@@ -0,0 +1,273 @@
+package synchronization
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// testRelocationHandler is a protocol handler that creates test directory
+// endpoints rooted at the URL path.
+type testRelocationHandler struct {
+	// source is the directory from which files are staged.
+	source string
+	// staging is the directory in which staged files are stored.
+	staging string
+}
+
+// Connect implements ProtocolHandler.Connect.
+func (h *testRelocationHandler) Connect(
+	_ context.Context,
+	_ *logging.Logger,
+	url *urlpkg.URL,
+	_ string,
+	_ string,
+	_ Version,
+	_ *Configuration,
+	_ bool,
+) (Endpoint, error) {
+	return &testDirectoryEndpoint{
+		root:    url.Path,
+		source:  h.source,
+		staging: h.staging,
+	}, nil
+}
+
+// testRelocationController creates a controller using testController, waits
+// for it to complete its initial synchronization cycle, assigns URLs and
+// labels to its session, and then pauses it. It also registers a local
+// protocol handler for relocation, returning a function that restores the
+// original handler. The caller is responsible for removing the returned parent
+// directory.
+func testRelocationController(t *testing.T) (*controller, string, *testDirectoryEndpoint, *testDirectoryEndpoint, func()) {
+	// Create the controller and wait for the initial cycle to complete.
+	c, parent, alpha, beta := testController(t, testShutdownContent, nil, nil)
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Pause the controller.
+	if err := c.halt(context.Background(), controllerHaltModePause, "", false); err != nil {
+		os.RemoveAll(parent)
+		t.Fatal("unable to pause controller:", err)
+	}
+
+	// Assign URLs and labels to the session.
+	c.session.Alpha = &urlpkg.URL{
+		Kind:     urlpkg.Kind_Synchronization,
+		Protocol: urlpkg.Protocol_Local,
+		Path:     alpha.root,
+	}
+	c.session.Beta = &urlpkg.URL{
+		Kind:     urlpkg.Kind_Synchronization,
+		Protocol: urlpkg.Protocol_Local,
+		Path:     beta.root,
+	}
+	c.session.Labels = map[string]string{"project": "relocation"}
+
+	// Register the relocation protocol handler.
+	originalHandler, originalHandlerRegistered := ProtocolHandlers[urlpkg.Protocol_Local]
+	ProtocolHandlers[urlpkg.Protocol_Local] = &testRelocationHandler{
+		source:  alpha.root,
+		staging: alpha.staging,
+	}
+	restore := func() {
+		if originalHandlerRegistered {
+			ProtocolHandlers[urlpkg.Protocol_Local] = originalHandler
+		} else {
+			delete(ProtocolHandlers, urlpkg.Protocol_Local)
+		}
+	}
+
+	// Done.
+	return c, parent, alpha, beta, restore
+}
+
+// restartTestController restarts the synchronization loop for a paused test
+// controller using the specified endpoints.
+func restartTestController(c *controller, alpha, beta *testDirectoryEndpoint) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopCtx, stop := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.stop = stop
+	c.flushRequests = make(chan *controllerFlushRequest, 1)
+	c.done = make(chan struct{})
+	go c.run(ctx, stopCtx, alpha, beta, nil)
+}
+
+// TestControllerRelocate tests that relocating a session's beta endpoint to a
+// copy of its synchronization root preserves the session's identity, labels,
+// and history, and that subsequent synchronization (including deletion
+// propagation) behaves correctly.
+func TestControllerRelocate(t *testing.T) {
+	// Create a paused controller and defer cleanup.
+	c, parent, alpha, beta, restore := testRelocationController(t)
+	defer os.RemoveAll(parent)
+	defer restore()
+
+	// Load the original archive.
+	original := &core.Archive{}
+	if err := encoding.LoadAndUnmarshalChecksummedProtobuf(c.archivePath, original); err != nil {
+		t.Fatal("unable to load original archive:", err)
+	}
+
+	// Move the beta root and relocate the session to the new location.
+	relocatedRoot := filepath.Join(parent, "relocated")
+	if err := os.Rename(beta.root, relocatedRoot); err != nil {
+		t.Fatal("unable to move beta root:", err)
+	}
+	target := &urlpkg.URL{
+		Kind:     urlpkg.Kind_Synchronization,
+		Protocol: urlpkg.Protocol_Local,
+		Path:     relocatedRoot,
+	}
+	warning, err := c.relocate(context.Background(), true, target, "")
+	if err != nil {
+		t.Fatal("unable to relocate session:", err)
+	} else if warning != "" {
+		t.Error("relocation to identical content generated warning:", warning)
+	}
+
+	// Verify that the session was updated on disk and that its identity and
+	// labels were preserved.
+	session := &Session{}
+	if err := encoding.LoadAndUnmarshalProtobuf(c.sessionPath, session); err != nil {
+		t.Fatal("unable to load session:", err)
+	} else if session.Identifier != "session" {
+		t.Error("session identifier changed:", session.Identifier)
+	} else if session.Labels["project"] != "relocation" {
+		t.Error("session labels not preserved")
+	} else if !session.Beta.Equal(target) {
+		t.Error("session beta URL not updated")
+	} else if !session.Alpha.Equal(c.session.Alpha) {
+		t.Error("session alpha URL modified")
+	}
+
+	// Verify that the archive was preserved.
+	archive := &core.Archive{}
+	if err := encoding.LoadAndUnmarshalChecksummedProtobuf(c.archivePath, archive); err != nil {
+		t.Fatal("unable to load archive:", err)
+	} else if !archive.Root.Equal(original.Root) {
+		t.Error("archive modified by relocation")
+	}
+
+	// Remove a file on alpha, restart synchronization using the new location,
+	// and verify that the deletion propagates.
+	if err := os.Remove(filepath.Join(alpha.root, "first")); err != nil {
+		t.Fatal("unable to remove alpha file:", err)
+	}
+	cycles := c.currentState().SuccessfulSynchronizationCycles
+	restartTestController(c, alpha, &testDirectoryEndpoint{
+		root:    relocatedRoot,
+		source:  alpha.root,
+		staging: beta.staging,
+	})
+	waitForSynchronizationCycles(t, c, cycles+1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("unable to halt controller:", err)
+	}
+	if _, err := os.Lstat(filepath.Join(relocatedRoot, "first")); !os.IsNotExist(err) {
+		t.Error("deletion not propagated to relocated root")
+	}
+	if count := verifyNoPartialFiles(t, relocatedRoot); count != len(testShutdownContent)-1 {
+		t.Error("relocated file count incorrect:", count, "!=", len(testShutdownContent)-1)
+	}
+}
+
+// TestControllerRelocateDivergent tests that relocating a session's beta
+// endpoint to an empty directory generates a warning, prunes the session's
+// history, and results in beta being repopulated rather than alpha's content
+// being deleted.
+func TestControllerRelocateDivergent(t *testing.T) {
+	// Create a paused controller and defer cleanup.
+	c, parent, alpha, beta, restore := testRelocationController(t)
+	defer os.RemoveAll(parent)
+	defer restore()
+
+	// Create an empty directory and relocate the session to it.
+	relocatedRoot := filepath.Join(parent, "relocated")
+	if err := os.Mkdir(relocatedRoot, 0700); err != nil {
+		t.Fatal("unable to create new beta root:", err)
+	}
+	target := &urlpkg.URL{
+		Kind:     urlpkg.Kind_Synchronization,
+		Protocol: urlpkg.Protocol_Local,
+		Path:     relocatedRoot,
+	}
+	warning, err := c.relocate(context.Background(), true, target, "")
+	if err != nil {
+		t.Fatal("unable to relocate session:", err)
+	} else if warning == "" {
+		t.Error("relocation to divergent content didn't generate warning")
+	}
+
+	// Verify that the archive was pruned.
+	archive := &core.Archive{}
+	if err := encoding.LoadAndUnmarshalChecksummedProtobuf(c.archivePath, archive); err != nil {
+		t.Fatal("unable to load archive:", err)
+	} else if count := archive.Root.Count(); count != 1 {
+		t.Error("pruned archive has unexpected entry count:", count, "!=", 1)
+	}
+
+	// Restart synchronization using the new location and verify that alpha's
+	// content is preserved and propagated to the new location.
+	cycles := c.currentState().SuccessfulSynchronizationCycles
+	restartTestController(c, alpha, &testDirectoryEndpoint{
+		root:    relocatedRoot,
+		source:  alpha.root,
+		staging: beta.staging,
+	})
+	waitForSynchronizationCycles(t, c, cycles+1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("unable to halt controller:", err)
+	}
+	for _, root := range []string{alpha.root, relocatedRoot} {
+		if count := verifyNoPartialFiles(t, root); count != len(testShutdownContent) {
+			t.Error("file count incorrect:", count, "!=", len(testShutdownContent))
+		}
+	}
+	for name, expected := range testShutdownContent {
+		if content, err := ioutil.ReadFile(filepath.Join(relocatedRoot, name)); err != nil {
+			t.Error("unable to read relocated file:", err)
+		} else if !bytes.Equal(content, expected) {
+			t.Error("relocated file content incorrect:", name)
+		}
+	}
+}
+
+// TestControllerRelocateRunning tests that relocation of a running session is
+// refused.
+func TestControllerRelocateRunning(t *testing.T) {
+	// Create a controller and wait for it to complete its initial cycle.
+	c, parent, _, beta := testController(t, testShutdownContent, nil, nil)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Attempt relocation.
+	target := &urlpkg.URL{
+		Kind:     urlpkg.Kind_Synchronization,
+		Protocol: urlpkg.Protocol_Local,
+		Path:     beta.root + "-relocated",
+	}
+	if _, err := c.relocate(context.Background(), true, target, ""); err == nil {
+		t.Error("relocation of running session succeeded")
+	}
+
+	// Halt the controller.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("unable to halt controller:", err)
+	}
+}
@@ -0,0 +1,95 @@
+package rsync
+
+import (
+	"github.com/pkg/errors"
+)
+
+// DeferredTransmissionError is the error reported to receivers for files whose
+// transmission has been deferred because it could exceed the transmission
+// budget.
+const DeferredTransmissionError = "transmission deferred due to transfer budget"
+
+// TransmissionBudget limits the amount of literal file data transmitted by
+// Transmit. Since a file's literal data can't exceed the file's size, a file is
+// only transmitted if its size fits within the remaining budget. Files that
+// don't fit are deferred, i.e. reported to the receiver with a non-terminal
+// DeferredTransmissionError, though smaller files after them may still be
+// transmitted. A nil budget is unlimited.
+type TransmissionBudget struct {
+	// Limit is the maximum number of bytes of literal data to transmit.
+	Limit uint64
+	// Used is the number of bytes already consumed from the budget by other
+	// transmissions (e.g. in the opposite direction). If no bytes have been
+	// consumed, then the first file is transmitted even if it exceeds the
+	// limit, since it could otherwise never be transmitted.
+	Used uint64
+}
+
+// admits determines whether or not a file of the specified size can be
+// transmitted after the specified number of bytes have been transmitted.
+func (b *TransmissionBudget) admits(transmitted, size uint64) bool {
+	// A nil budget admits everything.
+	if b == nil {
+		return true
+	}
+
+	// If nothing has been consumed yet, then the file is always admitted.
+	used := b.Used + transmitted
+	if used == 0 {
+		return true
+	}
+
+	// Otherwise check whether the file fits.
+	return used <= b.Limit && size <= b.Limit-used
+}
+
+// DeferralTrackingReceiver is a Receiver implementation that tracks which
+// files had their transmission deferred due to a transmission budget.
+type DeferralTrackingReceiver struct {
+	// receiver is the underlying receiver.
+	receiver Receiver
+	// paths are the paths being received.
+	paths []string
+	// received is the number of files received so far.
+	received int
+	// deferred are the paths whose transmission was deferred.
+	deferred []string
+}
+
+// NewDeferralTrackingReceiver wraps a receiver and tracks which of the
+// specified paths had their transmission deferred. The deferred paths can be
+// queried using the Deferred method.
+func NewDeferralTrackingReceiver(receiver Receiver, paths []string) *DeferralTrackingReceiver {
+	return &DeferralTrackingReceiver{
+		receiver: receiver,
+		paths:    paths,
+	}
+}
+
+// Receive records any deferral indicated by the transmission and forwards it
+// to the underlying receiver.
+func (r *DeferralTrackingReceiver) Receive(transmission *Transmission) error {
+	// Track file completion.
+	if transmission.Done {
+		if r.received == len(r.paths) {
+			return errors.New("unexpected file transmission")
+		}
+		if transmission.Error == DeferredTransmissionError {
+			r.deferred = append(r.deferred, r.paths[r.received])
+		}
+		r.received++
+	}
+
+	// Forward the transmission.
+	return r.receiver.Receive(transmission)
+}
+
+// finalize invokes finalize on the underlying receiver.
+func (r *DeferralTrackingReceiver) finalize() error {
+	return r.receiver.finalize()
+}
+
+// Deferred returns the paths whose transmission was deferred.
+func (r *DeferralTrackingReceiver) Deferred() []string {
+	return r.deferred
+}
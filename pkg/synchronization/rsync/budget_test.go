@@ -0,0 +1,104 @@
+package rsync
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTransmitBudget tests that Transmit defers files that don't fit within a
+// transmission budget.
+func TestTransmitBudget(t *testing.T) {
+	// Create a temporary source directory and defer its removal.
+	source, err := ioutil.TempDir("", "mutagen_rsync_budget")
+	if err != nil {
+		t.Fatal("unable to create temporary source directory:", err)
+	}
+	defer os.RemoveAll(source)
+
+	// Create source files.
+	paths := []string{"a", "b", "c", "d"}
+	sizes := []int{600, 600, 300, 200}
+	contents := make(map[string][]byte, len(paths))
+	for p, path := range paths {
+		contents[path] = testRandomData(int64(p), sizes[p])
+		if err := ioutil.WriteFile(filepath.Join(source, path), contents[path], 0600); err != nil {
+			t.Fatal("unable to create source file:", err)
+		}
+	}
+
+	// Set up test cases.
+	testCases := []struct {
+		description string
+		budget      *TransmissionBudget
+		expected    []string
+	}{
+		{"unlimited", nil, paths},
+		{"partial", &TransmissionBudget{Limit: 1000}, []string{"a", "c"}},
+		{"oversized first file", &TransmissionBudget{Limit: 100}, []string{"a"}},
+		{"partially used", &TransmissionBudget{Limit: 1000, Used: 500}, []string{"c", "d"}},
+		{"exhausted", &TransmissionBudget{Limit: 1000, Used: 1000}, nil},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		// Perform transmission.
+		signatures := make([]*Signature, len(paths))
+		for p := range signatures {
+			signatures[p] = &Signature{}
+		}
+		sinker := &testMemorySinker{contents: make(map[string][]byte)}
+		receiver, err := NewReceiver(source, paths, signatures, sinker)
+		if err != nil {
+			t.Fatal("unable to create receiver:", err)
+		}
+		counter := NewCountingReceiver(receiver)
+		deferrals := NewDeferralTrackingReceiver(counter, paths)
+		if err := Transmit(source, paths, signatures, deferrals, 0, nil, testCase.budget); err != nil {
+			t.Fatalf("%s: unable to transmit files: %v", testCase.description, err)
+		}
+
+		// Verify that the expected files were transmitted and that all others
+		// were deferred.
+		transmitted := make(map[string]bool, len(testCase.expected))
+		var expectedCount uint64
+		for _, path := range testCase.expected {
+			transmitted[path] = true
+			expectedCount += uint64(len(contents[path]))
+			if !bytes.Equal(sinker.contents[path], contents[path]) {
+				t.Errorf("%s: transmitted file (%s) content incorrect", testCase.description, path)
+			}
+		}
+		var expectedDeferred []string
+		for _, path := range paths {
+			if !transmitted[path] {
+				expectedDeferred = append(expectedDeferred, path)
+			}
+		}
+		if deferred := deferrals.Deferred(); len(deferred) != len(expectedDeferred) {
+			t.Errorf("%s: unexpected deferred files: %v", testCase.description, deferred)
+		} else {
+			for d, path := range deferred {
+				if path != expectedDeferred[d] {
+					t.Errorf("%s: deferred file (%s) does not match expected (%s)",
+						testCase.description, path, expectedDeferred[d],
+					)
+				}
+			}
+		}
+		if received := counter.Received(); received != expectedCount {
+			t.Errorf("%s: received byte count incorrect: %d != %d", testCase.description, received, expectedCount)
+		}
+	}
+}
+
+// TestDeferralTrackingReceiverUnexpectedTransmission tests that deferral
+// tracking receivers reject transmissions beyond the expected paths.
+func TestDeferralTrackingReceiverUnexpectedTransmission(t *testing.T) {
+	receiver := NewDeferralTrackingReceiver(&testModifyingReceiver{}, nil)
+	if err := receiver.Receive(&Transmission{Done: true}); err == nil {
+		t.Error("unexpected transmission accepted")
+	}
+}
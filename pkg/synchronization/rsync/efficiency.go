@@ -0,0 +1,126 @@
+package rsync
+
+import (
+	"github.com/pkg/errors"
+)
+
+// EnsureValid ensures that TransferEfficiency's invariants are respected.
+func (e *TransferEfficiency) EnsureValid() error {
+	// A nil transfer efficiency is not valid.
+	if e == nil {
+		return errors.New("nil transfer efficiency")
+	}
+
+	// Matched bytes are only possible with a base.
+	if e.BlockSize == 0 && e.MatchedBytes > 0 {
+		return errors.New("matched bytes recorded without block size")
+	}
+
+	// Success.
+	return nil
+}
+
+// TotalBytes returns the total size of the received file.
+func (e *TransferEfficiency) TotalBytes() uint64 {
+	return e.MatchedBytes + e.LiteralBytes
+}
+
+// MatchRatio returns the fraction of the received file that was reconstructed
+// from blocks of the file's base. Empty files are considered to be fully
+// matched.
+func (e *TransferEfficiency) MatchRatio() float64 {
+	total := e.TotalBytes()
+	if total == 0 {
+		return 1
+	}
+	return float64(e.MatchedBytes) / float64(total)
+}
+
+// EfficiencyReceiver is a Receiver implementation that records the delta
+// transfer efficiency for each file that it receives. It only tracks a few
+// counters per transmission, so its overhead is low, but it does retain a
+// record for each successfully received file.
+type EfficiencyReceiver struct {
+	// receiver is the underlying receiver.
+	receiver Receiver
+	// paths is the list of paths the receiver is expecting.
+	paths []string
+	// signatures is the list of base signatures for the expected paths.
+	signatures []*Signature
+	// received is the number of paths received so far.
+	received int
+	// matched is the number of matched bytes for the current file.
+	matched uint64
+	// literal is the number of literal bytes for the current file.
+	literal uint64
+	// efficiencies are the efficiencies recorded so far.
+	efficiencies []*TransferEfficiency
+}
+
+// NewEfficiencyReceiver wraps a receiver and records the delta transfer
+// efficiency for each received file. The paths and signatures must be those
+// used for the transmission. The efficiencies can be queried using the
+// Efficiencies method.
+func NewEfficiencyReceiver(receiver Receiver, paths []string, signatures []*Signature) *EfficiencyReceiver {
+	return &EfficiencyReceiver{
+		receiver:   receiver,
+		paths:      paths,
+		signatures: signatures,
+	}
+}
+
+// Receive records efficiency information for the transmission and forwards it
+// to the underlying receiver.
+func (r *EfficiencyReceiver) Receive(transmission *Transmission) error {
+	// Forward the transmission to the underlying receiver.
+	if err := r.receiver.Receive(transmission); err != nil {
+		return err
+	}
+
+	// Make sure that we're not seeing a transmission after receiving all files.
+	if r.received >= len(r.paths) {
+		return errors.New("unexpected file transmission")
+	}
+	signature := r.signatures[r.received]
+
+	// Update counters based on the transmission. Holes are neither matched
+	// nor transmitted as data, so they aren't counted.
+	if transmission.Restart {
+		r.matched, r.literal = 0, 0
+	} else if operation := transmission.Operation; operation != nil {
+		if len(operation.Data) > 0 {
+			r.literal += uint64(len(operation.Data))
+		} else if operation.Count > 0 {
+			r.matched += operation.Count * signature.BlockSize
+			if operation.Start+operation.Count == uint64(len(signature.Hashes)) {
+				r.matched -= signature.BlockSize - signature.LastBlockSize
+			}
+		}
+	} else if transmission.Done {
+		if transmission.Error == "" {
+			r.efficiencies = append(r.efficiencies, &TransferEfficiency{
+				Path:         r.paths[r.received],
+				BlockSize:    signature.BlockSize,
+				MatchedBytes: r.matched,
+				LiteralBytes: r.literal,
+			})
+		}
+		r.received++
+		r.matched, r.literal = 0, 0
+	}
+
+	// Success.
+	return nil
+}
+
+// Efficiencies returns the efficiencies recorded for files received so far.
+// Files whose transmission failed aren't included. It shouldn't be called
+// concurrently with Receive.
+func (r *EfficiencyReceiver) Efficiencies() []*TransferEfficiency {
+	return r.efficiencies
+}
+
+// finalize invokes finalize on the underlying receiver.
+func (r *EfficiencyReceiver) finalize() error {
+	return r.receiver.finalize()
+}
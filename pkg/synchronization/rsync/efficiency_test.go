@@ -0,0 +1,157 @@
+package rsync
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testEfficiencyBlockSize is the block size used for efficiency tests.
+const testEfficiencyBlockSize = 1024
+
+// testRandomData generates deterministic pseudorandom data of the specified
+// length.
+func testRandomData(seed int64, length int) []byte {
+	result := make([]byte, length)
+	rand.New(rand.NewSource(seed)).Read(result)
+	return result
+}
+
+// TestEfficiencyReceiver tests that efficiency receivers report accurate
+// efficiency metrics for files with known similarity to their bases.
+func TestEfficiencyReceiver(t *testing.T) {
+	// Set up test cases. A nil base indicates that the file has no base.
+	shared := testRandomData(1, 16*testEfficiencyBlockSize)
+	testCases := []struct {
+		description       string
+		base              []byte
+		target            []byte
+		expectedBlockSize uint64
+		expectedMatched   uint64
+		expectedLiteral   uint64
+	}{
+		{
+			"identical",
+			shared,
+			shared,
+			testEfficiencyBlockSize, uint64(len(shared)), 0,
+		},
+		{
+			"identical with short final block",
+			shared[:len(shared)-100],
+			shared[:len(shared)-100],
+			testEfficiencyBlockSize, uint64(len(shared) - 100), 0,
+		},
+		{
+			"half modified",
+			shared,
+			append(append([]byte{}, shared[:len(shared)/2]...), testRandomData(2, len(shared)/2)...),
+			testEfficiencyBlockSize, uint64(len(shared) / 2), uint64(len(shared) / 2),
+		},
+		{
+			"completely modified",
+			shared,
+			testRandomData(3, len(shared)),
+			testEfficiencyBlockSize, 0, uint64(len(shared)),
+		},
+		{
+			"no base",
+			nil,
+			shared,
+			0, 0, uint64(len(shared)),
+		},
+	}
+
+	// Create temporary source and base directories and defer their removal.
+	source, err := ioutil.TempDir("", "mutagen_rsync_efficiency")
+	if err != nil {
+		t.Fatal("unable to create temporary source directory:", err)
+	}
+	defer os.RemoveAll(source)
+	base, err := ioutil.TempDir("", "mutagen_rsync_efficiency")
+	if err != nil {
+		t.Fatal("unable to create temporary base directory:", err)
+	}
+	defer os.RemoveAll(base)
+
+	// Create files and signatures for each test case.
+	engine := NewEngine()
+	paths := make([]string, len(testCases))
+	signatures := make([]*Signature, len(testCases))
+	for i, testCase := range testCases {
+		paths[i] = string(rune('a' + i))
+		if err := ioutil.WriteFile(filepath.Join(source, paths[i]), testCase.target, 0600); err != nil {
+			t.Fatal("unable to create source file:", err)
+		}
+		if testCase.base == nil {
+			signatures[i] = &Signature{}
+			continue
+		}
+		if err := ioutil.WriteFile(filepath.Join(base, paths[i]), testCase.base, 0600); err != nil {
+			t.Fatal("unable to create base file:", err)
+		}
+		if signatures[i], err = engine.Signature(bytes.NewReader(testCase.base), testEfficiencyBlockSize); err != nil {
+			t.Fatal("unable to compute base signature:", err)
+		}
+	}
+
+	// Perform transmission.
+	sinker := &testMemorySinker{contents: make(map[string][]byte)}
+	receiver, err := NewReceiver(base, paths, signatures, sinker)
+	if err != nil {
+		t.Fatal("unable to create receiver:", err)
+	}
+	efficiency := NewEfficiencyReceiver(receiver, paths, signatures)
+	if err := Transmit(source, paths, signatures, efficiency, 0, nil, nil); err != nil {
+		t.Fatal("unable to transmit files:", err)
+	}
+
+	// Verify the recorded efficiencies.
+	efficiencies := efficiency.Efficiencies()
+	if len(efficiencies) != len(testCases) {
+		t.Fatal("unexpected number of efficiencies:", len(efficiencies))
+	}
+	for i, testCase := range testCases {
+		e := efficiencies[i]
+		if err := e.EnsureValid(); err != nil {
+			t.Errorf("%s: invalid efficiency: %v", testCase.description, err)
+		}
+		if !bytes.Equal(sinker.contents[paths[i]], testCase.target) {
+			t.Errorf("%s: received content does not match target", testCase.description)
+		}
+		if e.Path != paths[i] {
+			t.Errorf("%s: path (%s) does not match expected (%s)", testCase.description, e.Path, paths[i])
+		}
+		if e.BlockSize != testCase.expectedBlockSize {
+			t.Errorf("%s: block size (%d) does not match expected (%d)",
+				testCase.description, e.BlockSize, testCase.expectedBlockSize,
+			)
+		}
+		if e.MatchedBytes != testCase.expectedMatched || e.LiteralBytes != testCase.expectedLiteral {
+			t.Errorf("%s: matched/literal bytes (%d/%d) do not match expected (%d/%d)",
+				testCase.description,
+				e.MatchedBytes, e.LiteralBytes,
+				testCase.expectedMatched, testCase.expectedLiteral,
+			)
+		}
+		if e.TotalBytes() != uint64(len(testCase.target)) {
+			t.Errorf("%s: total bytes (%d) does not match target size (%d)",
+				testCase.description, e.TotalBytes(), len(testCase.target),
+			)
+		}
+	}
+
+	// Verify match ratios for representative cases.
+	if ratio := efficiencies[0].MatchRatio(); ratio != 1 {
+		t.Error("identical file match ratio incorrect:", ratio)
+	}
+	if ratio := efficiencies[2].MatchRatio(); ratio != 0.5 {
+		t.Error("half modified file match ratio incorrect:", ratio)
+	}
+	if ratio := efficiencies[4].MatchRatio(); ratio != 0 {
+		t.Error("file without base match ratio incorrect:", ratio)
+	}
+}
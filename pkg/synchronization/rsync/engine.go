@@ -91,6 +91,14 @@ func (o *Operation) EnsureValid() error {
 			return errors.New("data operation with non-0 block start index")
 		} else if o.Count != 0 {
 			return errors.New("data operation with non-0 block count")
+		} else if o.Hole != 0 {
+			return errors.New("data operation with non-0 hole length")
+		}
+	} else if o.Hole > 0 {
+		if o.Start != 0 {
+			return errors.New("hole operation with non-0 block start index")
+		} else if o.Count != 0 {
+			return errors.New("hole operation with non-0 block count")
 		}
 	} else if o.Count == 0 {
 		return errors.New("block operation with 0 block count")
@@ -114,6 +122,7 @@ func (o *Operation) Copy() *Operation {
 		Data:  data,
 		Start: o.Start,
 		Count: o.Count,
+		Hole:  o.Hole,
 	}
 }
 
@@ -124,15 +133,16 @@ func (o *Operation) resetToZeroMaintainingCapacity() {
 	// Reset the data slice, but maintain its capacity.
 	o.Data = o.Data[:0]
 
-	// Reset start and count.
+	// Reset start, count, and hole length.
 	o.Start = 0
 	o.Count = 0
+	o.Hole = 0
 }
 
 // isZeroValue indicates whether or not an Operation has its zero-value. It's
 // worth noting that the zero-value state is not a valid state for an Operation.
 func (o *Operation) isZeroValue() bool {
-	return len(o.Data) == 0 && o.Start == 0 && o.Count == 0
+	return len(o.Data) == 0 && o.Start == 0 && o.Count == 0 && o.Hole == 0
 }
 
 const (
@@ -156,8 +166,66 @@ const (
 	// will be used if a zero value is passed into Engine.Deltafy or
 	// Engine.DeltafyBytes for the maxDataOpSize parameter.
 	DefaultMaximumDataOperationSize = 1 << 14
+	// minimumHoleSize is the minimum length of a run of zero bytes that will
+	// be transmitted as a hole operation rather than as data. It's chosen to
+	// match the most common filesystem block size, since shorter runs can't
+	// be stored as holes anyway.
+	minimumHoleSize = 1 << 12
+	// holeSearchStride is the granularity at which runs of zero bytes are
+	// identified when searching for holes in data. Runs of zero bytes that
+	// don't cover a full stride are transmitted as data.
+	holeSearchStride = 1 << 9
 )
 
+// zeroBuffer is a buffer of zero bytes used to identify and write holes.
+var zeroBuffer [minimumHoleSize]byte
+
+// isZero determines whether or not a byte slice consists entirely of zero
+// bytes.
+func isZero(data []byte) bool {
+	for len(data) > 0 {
+		size := len(data)
+		if size > len(zeroBuffer) {
+			size = len(zeroBuffer)
+		}
+		if !bytes.Equal(data[:size], zeroBuffer[:size]) {
+			return false
+		}
+		data = data[size:]
+	}
+	return true
+}
+
+// findHole locates the first run of zero bytes in data that should be
+// transmitted as a hole, returning its offset and length. A run qualifies if it
+// is at least minimumHoleSize in length, if it begins the data and continues a
+// pending hole, or if it ends the data and covers at least one search stride (in
+// which case it may be continued by subsequent data). If there is no such run, then it returns the length of the
+// data and a length of 0.
+func findHole(data []byte, continuing bool) (int, int) {
+	var start, length int
+	for offset := 0; offset < len(data); offset += holeSearchStride {
+		end := offset + holeSearchStride
+		if end > len(data) {
+			end = len(data)
+		}
+		if isZero(data[offset:end]) {
+			if length == 0 {
+				start = offset
+			}
+			length += end - offset
+		} else if length >= minimumHoleSize || (length > 0 && start == 0 && continuing) {
+			return start, length
+		} else {
+			length = 0
+		}
+	}
+	if length >= holeSearchStride || (length > 0 && start == 0 && continuing) {
+		return start, length
+	}
+	return len(data), 0
+}
+
 // OptimalBlockSizeForBaseLength uses a simpler heuristic to choose a block
 // size based on the base length. It starts by choosing the optimal block length
 // using the formula given in the rsync thesis. It then enforces that the block
@@ -200,6 +268,27 @@ func OptimalBlockSizeForBase(base io.Seeker) (uint64, error) {
 	}
 }
 
+// HoleWriter is an optional interface that destinations passed to Engine.Patch
+// can implement to efficiently write runs of zero bytes, e.g. by creating holes
+// in sparse files.
+type HoleWriter interface {
+	// WriteHole writes the specified number of zero bytes.
+	WriteHole(length uint64) error
+}
+
+// InPlaceWriter is an optional interface that destinations passed to
+// Engine.Patch can implement if their content is initialized with the content
+// of the base (e.g. if they're clones of the base). It allows blocks that would
+// be copied to the same offset at which they reside in the base to be retained
+// rather than rewritten.
+type InPlaceWriter interface {
+	// Offset returns the current write offset.
+	Offset() uint64
+	// Retain advances the write offset by the specified number of bytes,
+	// retaining the existing content in that range.
+	Retain(length uint64) error
+}
+
 // OperationTransmitter transmits an operation. Operation objects and their data
 // buffers are re-used between calls to the transmitter, so the transmitter
 // should not return until it has either transmitted the operation or copied it
@@ -223,6 +312,10 @@ type Engine struct {
 	// operation is a re-usable operation object used for transmissions to avoid
 	// allocations.
 	operation *Operation
+	// hole is the length of a pending hole that has been identified in the
+	// target but not yet transmitted. Pending holes are coalesced so that long
+	// holes span a single operation.
+	hole uint64
 }
 
 // NewEngine creates a new rsync engine.
@@ -344,14 +437,26 @@ func (e *Engine) Signature(base io.Reader, blockSize uint64) (*Signature, error)
 		}
 	}
 
+	// Compute the signature using the engine's internal buffer.
+	return e.SignatureWithBuffer(base, e.bufferWithSize(blockSize))
+}
+
+// SignatureWithBuffer computes the signature for a base stream using the
+// provided buffer to read blocks. The length of the buffer determines the block
+// size and must be non-zero. This method is useful for callers that need to
+// control the allocation of block buffers.
+func (e *Engine) SignatureWithBuffer(base io.Reader, buffer []byte) (*Signature, error) {
+	// Validate the buffer.
+	if len(buffer) == 0 {
+		return nil, errors.New("empty block buffer")
+	}
+	blockSize := uint64(len(buffer))
+
 	// Create the result.
 	result := &Signature{
 		BlockSize: blockSize,
 	}
 
-	// Create a buffer with which to read blocks.
-	buffer := e.bufferWithSize(blockSize)
-
 	// Read blocks and append their hashes until we reach EOF.
 	eof := false
 	for !eof {
@@ -426,21 +531,62 @@ func min(a, b uint64) uint64 {
 	return b
 }
 
-// transmitData transmits a data operation using the engine's internal operation
-// object.
-func (e *Engine) transmitData(data []byte, transmit OperationTransmitter) error {
-	// Set the operation parameters.
+// transmitHole transmits any pending hole as a hole operation using the
+// engine's internal operation object.
+func (e *Engine) transmitHole(transmit OperationTransmitter) error {
+	// If there's no pending hole, then there's nothing to transmit.
+	if e.hole == 0 {
+		return nil
+	}
+
+	// Set the operation parameters and reset the pending hole.
 	*e.operation = Operation{
-		Data: data,
+		Hole: e.hole,
 	}
+	e.hole = 0
 
 	// Transmit.
 	return transmit(e.operation)
 }
 
+// transmitData transmits data operations using the engine's internal operation
+// object. Any sufficiently long runs of zero bytes are added to the pending
+// hole rather than being transmitted as data.
+func (e *Engine) transmitData(data []byte, transmit OperationTransmitter) error {
+	for len(data) > 0 {
+		// Locate the next hole.
+		start, length := findHole(data, e.hole > 0)
+
+		// Transmit any data preceding the hole, preceded by any pending hole.
+		if start > 0 {
+			if err := e.transmitHole(transmit); err != nil {
+				return err
+			}
+			*e.operation = Operation{
+				Data: data[:start],
+			}
+			if err := transmit(e.operation); err != nil {
+				return err
+			}
+		}
+
+		// Add the hole to the pending hole.
+		e.hole += uint64(length)
+		data = data[start+length:]
+	}
+
+	// Success.
+	return nil
+}
+
 // transmitBlock transmits a block operation using the engine's internal
-// operation object.
+// operation object. Any pending hole is transmitted first.
 func (e *Engine) transmitBlock(start, count uint64, transmit OperationTransmitter) error {
+	// Transmit any pending hole.
+	if err := e.transmitHole(transmit); err != nil {
+		return err
+	}
+
 	// Set the operation parameters.
 	*e.operation = Operation{
 		Start: start,
@@ -463,13 +609,19 @@ func (e *Engine) chunkAndTransmitAll(target io.Reader, maxDataOpSize uint64, tra
 	// Create a buffer to transmit data operations.
 	buffer := e.bufferWithSize(maxDataOpSize)
 
-	// Loop until the entire target has been transmitted as data operations.
+	// Loop until the entire target has been transmitted as data operations,
+	// followed by any trailing hole.
 	for {
 		if n, err := io.ReadFull(target, buffer); err == io.EOF {
+			if err = e.transmitHole(transmit); err != nil {
+				return errors.Wrap(err, "unable to transmit hole operation")
+			}
 			return nil
 		} else if err == io.ErrUnexpectedEOF {
 			if err = e.transmitData(buffer[:n], transmit); err != nil {
 				return errors.Wrap(err, "unable to transmit data operation")
+			} else if err = e.transmitHole(transmit); err != nil {
+				return errors.Wrap(err, "unable to transmit hole operation")
 			}
 			return nil
 		} else if err != nil {
@@ -498,6 +650,9 @@ func (e *Engine) Deltafy(target io.Reader, base *Signature, maxDataOpSize uint64
 		maxDataOpSize = DefaultMaximumDataOperationSize
 	}
 
+	// Reset any pending hole left by a previously failed operation.
+	e.hole = 0
+
 	// If the base is empty, then there's no way we'll find any matching blocks,
 	// so just send the entire file.
 	if len(base.Hashes) == 0 {
@@ -715,6 +870,11 @@ func (e *Engine) Deltafy(target io.Reader, base *Signature, maxDataOpSize uint64
 		}
 	}
 
+	// Send any final pending hole.
+	if err := e.transmitHole(transmit); err != nil {
+		return errors.Wrap(err, "unable to send final hole operation")
+	}
+
 	// Success.
 	return nil
 }
@@ -764,12 +924,33 @@ func (e *Engine) DeltafyBytes(target []byte, base *Signature, maxDataOpSize uint
 // untrusted locations (e.g. over the network). An invalid signature or
 // operation can result in undefined behavior.
 func (e *Engine) Patch(destination io.Writer, base io.ReadSeeker, signature *Signature, operation *Operation) error {
+	// Determine whether or not the destination supports writing holes or
+	// writing in place.
+	holeWriter, writesHoles := destination.(HoleWriter)
+	inPlaceWriter, writesInPlace := destination.(InPlaceWriter)
+
 	// Handle the operation based on type.
 	if len(operation.Data) > 0 {
 		// Write data operations directly to the destination.
 		if _, err := destination.Write(operation.Data); err != nil {
 			return errors.Wrap(err, "unable to write data")
 		}
+	} else if operation.Hole > 0 {
+		// Write hole operations as holes if the destination supports them,
+		// otherwise write the corresponding zero bytes.
+		if writesHoles {
+			if err := holeWriter.WriteHole(operation.Hole); err != nil {
+				return errors.Wrap(err, "unable to write hole")
+			}
+		} else {
+			for remaining := operation.Hole; remaining > 0; {
+				size := min(remaining, uint64(len(zeroBuffer)))
+				if _, err := destination.Write(zeroBuffer[:size]); err != nil {
+					return errors.Wrap(err, "unable to write hole data")
+				}
+				remaining -= size
+			}
+		}
 	} else {
 		// Seek to the start of the requested block in base.
 		// TODO: We should technically validate that operation.Index
@@ -787,12 +968,31 @@ func (e *Engine) Patch(destination io.Writer, base io.ReadSeeker, signature *Sig
 				copyLength = signature.LastBlockSize
 			}
 
+			// If the destination is being written in place and we're already
+			// at the offset of the block in the base, then the destination
+			// already contains the block, so we can retain it and skip past it
+			// in the base.
+			if writesInPlace && inPlaceWriter.Offset() == (operation.Start+c)*signature.BlockSize {
+				if err := inPlaceWriter.Retain(copyLength); err != nil {
+					return errors.Wrap(err, "unable to retain block")
+				} else if _, err = base.Seek(int64(copyLength), io.SeekCurrent); err != nil {
+					return errors.Wrap(err, "unable to skip base block")
+				}
+				continue
+			}
+
 			// Create a buffer of the required size.
 			buffer := e.bufferWithSize(copyLength)
 
-			// Copy the block.
+			// Copy the block. If the block consists entirely of zero bytes
+			// and the destination supports holes, then write it as a hole so
+			// that holes in the base are preserved.
 			if _, err := io.ReadFull(base, buffer); err != nil {
 				return errors.Wrap(err, "unable to read block data")
+			} else if writesHoles && isZero(buffer) {
+				if err = holeWriter.WriteHole(copyLength); err != nil {
+					return errors.Wrap(err, "unable to write block hole")
+				}
 			} else if _, err = destination.Write(buffer); err != nil {
 				return errors.Wrap(err, "unable to write block data")
 			}
@@ -138,6 +138,33 @@ func TestOperationDataAndCountInvalid(t *testing.T) {
 	}
 }
 
+// TestOperationDataAndHoleInvalid verifies the EnsureValid behavior of
+// Operation when data and a hole length are provided.
+func TestOperationDataAndHoleInvalid(t *testing.T) {
+	operation := &Operation{Data: []byte{0}, Hole: 4}
+	if operation.EnsureValid() == nil {
+		t.Error("operation with data and hole considered valid")
+	}
+}
+
+// TestOperationHoleAndStartInvalid verifies the EnsureValid behavior of
+// Operation when a hole length and a block start index are provided.
+func TestOperationHoleAndStartInvalid(t *testing.T) {
+	operation := &Operation{Hole: 4096, Start: 4}
+	if operation.EnsureValid() == nil {
+		t.Error("operation with hole and start considered valid")
+	}
+}
+
+// TestOperationHoleAndCountInvalid verifies the EnsureValid behavior of
+// Operation when a hole length and a block count are provided.
+func TestOperationHoleAndCountInvalid(t *testing.T) {
+	operation := &Operation{Hole: 4096, Count: 4}
+	if operation.EnsureValid() == nil {
+		t.Error("operation with hole and count considered valid")
+	}
+}
+
 // TestOperationZeroCountInvalid verifies the EnsureValid behavior of Operation
 // when the block count is zero.
 func TestOperationZeroCountInvalid(t *testing.T) {
@@ -165,6 +192,15 @@ func TestOperationBlocksValid(t *testing.T) {
 	}
 }
 
+// TestOperationHoleValid verifies the EnsureValid behavior of Operation in the
+// case of a valid hole operation.
+func TestOperationHoleValid(t *testing.T) {
+	operation := &Operation{Hole: 4096}
+	if err := operation.EnsureValid(); err != nil {
+		t.Error("valid hole operation considered invalid")
+	}
+}
+
 // TestMinimumBlockSize verifies that OptimalBlockSizeForBaseLength returns a
 // sane minimum block size.
 func TestMinimumBlockSize(t *testing.T) {
@@ -208,6 +244,40 @@ func TestOptimalBlockSizeForBase(t *testing.T) {
 	}
 }
 
+// TestSignatureWithBuffer verifies that signatures computed with a provided
+// buffer match those computed with the engine's internal buffer.
+func TestSignatureWithBuffer(t *testing.T) {
+	// Create an engine and base data.
+	engine := NewEngine()
+	base := testDataGenerator{length: 12345, seed: 2021}.generate()
+
+	// Compute the expected signature.
+	expected, err := engine.Signature(bytes.NewReader(base), DefaultBlockSize)
+	if err != nil {
+		t.Fatal("unable to compute expected signature:", err)
+	}
+
+	// Compute the signature using a provided buffer and compare.
+	signature, err := engine.SignatureWithBuffer(bytes.NewReader(base), make([]byte, DefaultBlockSize))
+	if err != nil {
+		t.Fatal("unable to compute signature with buffer:", err)
+	} else if signature.BlockSize != expected.BlockSize || signature.LastBlockSize != expected.LastBlockSize {
+		t.Error("signature block sizes don't match expected")
+	} else if len(signature.Hashes) != len(expected.Hashes) {
+		t.Fatal("signature hash count doesn't match expected")
+	}
+	for h, hash := range signature.Hashes {
+		if hash.Weak != expected.Hashes[h].Weak || !bytes.Equal(hash.Strong, expected.Hashes[h].Strong) {
+			t.Error("signature hash doesn't match expected at index", h)
+		}
+	}
+
+	// Ensure that an empty buffer is rejected.
+	if _, err := engine.SignatureWithBuffer(bytes.NewReader(base), nil); err == nil {
+		t.Error("signature computation with empty buffer succeeded")
+	}
+}
+
 // testDataGenerator generates repeatable random byte sequences with optional
 // mutations and data prepending.
 type testDataGenerator struct {
@@ -528,3 +598,198 @@ func TestDifferentDataDifferentLength(t *testing.T) {
 	}
 	test.run(t)
 }
+
+// testHoleWriter is a HoleWriter implementation that records written holes.
+type testHoleWriter struct {
+	bytes.Buffer
+	// holes is the total length of holes written.
+	holes uint64
+}
+
+// WriteHole implements HoleWriter.WriteHole.
+func (w *testHoleWriter) WriteHole(length uint64) error {
+	w.holes += length
+	w.Write(make([]byte, length))
+	return nil
+}
+
+// testSparseTarget generates a target consisting of random data separated by
+// runs of zero bytes.
+func testSparseTarget() []byte {
+	random := rand.New(rand.NewSource(473))
+	var target []byte
+	for i := 0; i < 3; i++ {
+		data := make([]byte, 5000)
+		random.Read(data)
+		target = append(target, data...)
+		target = append(target, make([]byte, 100000)...)
+	}
+	return target
+}
+
+// TestHolesAgainstEmptyBase verifies that runs of zero bytes sent against an
+// empty base are transmitted as coalesced hole operations rather than data.
+func TestHolesAgainstEmptyBase(t *testing.T) {
+	// Create an engine and compute a delta against an empty base.
+	engine := NewEngine()
+	signature := engine.BytesSignature(nil, 0)
+	target := testSparseTarget()
+	delta := engine.DeltafyBytes(target, signature, 0)
+
+	// Validate the delta and count data and holes. Zero bytes preceding the
+	// first search stride boundary of each run may be transmitted as data.
+	var dataLength, holeLength uint64
+	var holeOperations int
+	for _, o := range delta {
+		if err := o.EnsureValid(); err != nil {
+			t.Fatal("invalid operation:", err)
+		} else if o.Hole > 0 {
+			holeLength += o.Hole
+			holeOperations++
+		}
+		dataLength += uint64(len(o.Data))
+	}
+	if holeOperations != 3 {
+		t.Error("unexpected number of hole operations:", holeOperations, "!=", 3)
+	}
+	if dataLength+holeLength != uint64(len(target)) {
+		t.Error("operations don't cover target:", dataLength+holeLength, "!=", len(target))
+	}
+	if maximum := uint64(3 * (5000 + holeSearchStride)); dataLength > maximum {
+		t.Error("zero bytes transmitted as data:", dataLength, ">", maximum)
+	}
+
+	// Verify that patching without hole support works.
+	if patched, err := engine.PatchBytes(nil, signature, delta); err != nil {
+		t.Fatal("unable to patch bytes:", err)
+	} else if !bytes.Equal(patched, target) {
+		t.Error("patched data did not match expected")
+	}
+
+	// Verify that patching with hole support writes holes.
+	destination := &testHoleWriter{}
+	for _, o := range delta {
+		if err := engine.Patch(destination, nil, signature, o); err != nil {
+			t.Fatal("unable to apply operation:", err)
+		}
+	}
+	if !bytes.Equal(destination.Bytes(), target) {
+		t.Error("patched data did not match expected")
+	} else if destination.holes != holeLength {
+		t.Error("hole length mismatch:", destination.holes, "!=", holeLength)
+	}
+}
+
+// TestHolesMatchingBase verifies that holes in the base are preserved when
+// patching with block operations.
+func TestHolesMatchingBase(t *testing.T) {
+	// Compute a delta for an identical target.
+	engine := NewEngine()
+	base := testSparseTarget()
+	signature := engine.BytesSignature(base, 0)
+	delta := engine.DeltafyBytes(base, signature, 0)
+
+	// Apply the delta with hole support and verify that zero blocks were
+	// written as holes.
+	destination := &testHoleWriter{}
+	for _, o := range delta {
+		if err := engine.Patch(destination, bytes.NewReader(base), signature, o); err != nil {
+			t.Fatal("unable to apply operation:", err)
+		}
+	}
+	if !bytes.Equal(destination.Bytes(), base) {
+		t.Error("patched data did not match expected")
+	} else if destination.holes == 0 {
+		t.Error("no holes written for zero blocks")
+	}
+}
+
+// testInPlaceWriter is an InPlaceWriter implementation that operates on a copy
+// of the base and records written and retained content lengths.
+type testInPlaceWriter struct {
+	// content is the destination content, initialized to a copy of the base.
+	content []byte
+	// offset is the current write offset.
+	offset uint64
+	// written is the total length of written content.
+	written uint64
+	// retained is the total length of retained content.
+	retained uint64
+}
+
+// Write implements io.Writer.Write.
+func (w *testInPlaceWriter) Write(data []byte) (int, error) {
+	if end := w.offset + uint64(len(data)); end > uint64(len(w.content)) {
+		w.content = append(w.content, make([]byte, end-uint64(len(w.content)))...)
+	}
+	copy(w.content[w.offset:], data)
+	w.offset += uint64(len(data))
+	w.written += uint64(len(data))
+	return len(data), nil
+}
+
+// Offset implements InPlaceWriter.Offset.
+func (w *testInPlaceWriter) Offset() uint64 {
+	return w.offset
+}
+
+// Retain implements InPlaceWriter.Retain.
+func (w *testInPlaceWriter) Retain(length uint64) error {
+	w.offset += length
+	w.retained += length
+	return nil
+}
+
+// TestInPlacePatching verifies that patching a destination initialized with the
+// base content produces the target while retaining blocks that are unchanged
+// and at the same offset.
+func TestInPlacePatching(t *testing.T) {
+	// Define test cases.
+	testCases := []struct {
+		description    string
+		base           testDataGenerator
+		target         testDataGenerator
+		suffix         []byte
+		expectRetained bool
+	}{
+		{"mutation", testDataGenerator{10240, 473, nil, nil}, testDataGenerator{10240, 473, []int{1300, 7000}, nil}, nil, true},
+		{"truncation", testDataGenerator{10240, 473, nil, nil}, testDataGenerator{5000, 473, nil, nil}, nil, true},
+		{"append", testDataGenerator{10240, 473, nil, nil}, testDataGenerator{10240, 473, nil, nil}, []byte("appended"), true},
+		{"prepend", testDataGenerator{10240, 473, nil, nil}, testDataGenerator{10240, 473, nil, []byte{1, 2, 3}}, nil, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		// Generate base and target data.
+		base := testCase.base.generate()
+		target := append(testCase.target.generate(), testCase.suffix...)
+
+		// Compute a delta.
+		engine := NewEngine()
+		signature := engine.BytesSignature(base, 1024)
+		delta := engine.DeltafyBytes(target, signature, 1024)
+
+		// Apply the delta in place.
+		destination := &testInPlaceWriter{content: append([]byte(nil), base...)}
+		for _, o := range delta {
+			if err := engine.Patch(destination, bytes.NewReader(base), signature, o); err != nil {
+				t.Fatal(testCase.description, ": unable to apply operation:", err)
+			}
+		}
+
+		// Verify the result and statistics.
+		if destination.offset != uint64(len(target)) {
+			t.Error(testCase.description, ": final offset incorrect:", destination.offset, "!=", len(target))
+		} else if !bytes.Equal(destination.content[:destination.offset], target) {
+			t.Error(testCase.description, ": patched data did not match expected")
+		}
+		if destination.written+destination.retained != uint64(len(target)) {
+			t.Error(testCase.description, ": written and retained content don't cover target")
+		}
+		if testCase.expectRetained && destination.written >= uint64(len(target))/2 {
+			t.Error(testCase.description, ": too much content rewritten:", destination.written)
+		} else if !testCase.expectRetained && destination.retained != 0 {
+			t.Error(testCase.description, ": shifted content unexpectedly retained")
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package rsync
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/mutagen-io/mutagen/pkg/compression"
+)
+
+const (
+	// probeSampleCount is the maximum number of target regions sampled when
+	// probing a file's similarity with its base.
+	probeSampleCount = 16
+	// minimumDeltaSavingsDivisor controls the fraction of a file's size that a
+	// delta transfer of a file with a compressed format must be estimated to
+	// save in order to be chosen over a whole-file transfer. Deltafying such
+	// files requires reading and hashing the entire base and target for what's
+	// typically a small number of matches, and similarity estimates derived
+	// from a handful of samples are imprecise, so a delta transfer is only
+	// chosen if it's estimated to save at least a quarter of the file's size.
+	minimumDeltaSavingsDivisor = 4
+	// estimatedBlockOperationSize is the estimated encoded size (in bytes) of
+	// the operation used to transmit a single matched block. This ignores the
+	// coalescing of adjacent block operations, so it's conservative.
+	estimatedBlockOperationSize = 8
+)
+
+// DefaultCompressedExtensions are the extensions of common file formats whose
+// content is compressed. Small changes to the underlying content of these
+// files typically alter most of their encoded representation, so delta
+// transfers are rarely beneficial for them.
+var DefaultCompressedExtensions = []string{
+	".7z", ".apk", ".br", ".bz2", ".docx", ".gif", ".gz", ".jar", ".jpeg",
+	".jpg", ".lz4", ".mkv", ".mov", ".mp3", ".mp4", ".png", ".pptx", ".rar",
+	".tgz", ".webm", ".webp", ".whl", ".xlsx", ".xz", ".zip", ".zst",
+}
+
+// TransferHeuristic determines whether files are transmitted as deltas against
+// their base or in their entirety. A nil heuristic transmits all files as
+// deltas.
+type TransferHeuristic struct {
+	// WholeFile indicates that all files should be transmitted in their
+	// entirety, regardless of their similarity with their base.
+	WholeFile bool
+	// CompressedExtensions are the extensions of files whose similarity with
+	// their base should be probed before transmission, with the file being
+	// transmitted using whichever of a delta or whole-file transfer is
+	// estimated to be smaller. Files with other extensions are transmitted as
+	// deltas. Extensions are matched case insensitively and may be specified
+	// with or without a leading dot.
+	CompressedExtensions []string
+}
+
+// NewAutomaticTransferHeuristic creates a new transfer heuristic that probes
+// files with the specified extensions, in addition to those specified by
+// DefaultCompressedExtensions.
+func NewAutomaticTransferHeuristic(extensions []string) *TransferHeuristic {
+	compressedExtensions := make([]string, 0, len(DefaultCompressedExtensions)+len(extensions))
+	compressedExtensions = append(compressedExtensions, DefaultCompressedExtensions...)
+	compressedExtensions = append(compressedExtensions, extensions...)
+	return &TransferHeuristic{CompressedExtensions: compressedExtensions}
+}
+
+// useDelta determines whether or not the file at the specified path should be
+// transmitted as a delta against the specified base. The file is only probed
+// if its extension indicates a compressed format and its contents (of the
+// specified size) can be read using random access.
+func (h *TransferHeuristic) useDelta(engine *Engine, path string, file io.Reader, size int64, base *Signature) bool {
+	// If there's no heuristic, then always use delta transfers.
+	if h == nil {
+		return true
+	}
+
+	// If the base is empty, then a delta transfer is already equivalent to a
+	// whole-file transfer.
+	if base.isEmpty() {
+		return true
+	}
+
+	// Handle forced whole-file transfers.
+	if h.WholeFile {
+		return false
+	}
+
+	// If the file doesn't have a compressed format, then delta transfers are
+	// typically beneficial and not worth probing.
+	if !compression.Incompressible(path, h.CompressedExtensions) {
+		return true
+	}
+
+	// If the file doesn't support random access, then we can't probe it
+	// without consuming it, so fall back to a delta transfer.
+	target, ok := file.(io.ReaderAt)
+	if !ok || size <= 0 {
+		return true
+	}
+
+	// Estimate the size of a delta transfer and compare it against that of a
+	// whole-file transfer.
+	return engine.estimateDeltaSize(target, uint64(size), base)+uint64(size)/minimumDeltaSavingsDivisor <= uint64(size)
+}
+
+// estimateDeltaSize estimates the amount of data required to transmit the
+// specified target as a delta against the specified base. It does so by
+// sampling evenly spaced regions of the target and searching each region for
+// a block matching the base, allowing for matches offset by up to a block
+// size from the sampled location (e.g. due to insertions or deletions). The
+// fraction of regions containing a match is used as an estimate of the
+// fraction of the target that can be transmitted as block operations. Read
+// errors are treated as a lack of matches.
+func (e *Engine) estimateDeltaSize(target io.ReaderAt, size uint64, base *Signature) uint64 {
+	// Compute the number of full base blocks that can be matched. If the last
+	// block is short, then we exclude it since we only search for full blocks.
+	blockSize := base.BlockSize
+	blocks := len(base.Hashes)
+	if base.LastBlockSize != blockSize {
+		blocks--
+	}
+
+	// If there are no base blocks to match or the target doesn't contain a
+	// full block, then the entire target will be transmitted as data.
+	targetBlocks := size / blockSize
+	if blocks == 0 || targetBlocks == 0 {
+		return size
+	}
+
+	// Index the weak hashes of the base blocks.
+	weakHashes := make(map[uint32][]int, blocks)
+	for i, h := range base.Hashes[:blocks] {
+		weakHashes[h.Weak] = append(weakHashes[h.Weak], i)
+	}
+
+	// Compute the number of regions to sample.
+	samples := uint64(probeSampleCount)
+	if targetBlocks < samples {
+		samples = targetBlocks
+	}
+
+	// Sample regions. Each region spans two blocks, so that matches starting
+	// anywhere within the first block can be detected.
+	buffer := e.bufferWithSize(2 * blockSize)
+	var matches uint64
+	for s := uint64(0); s < samples; s++ {
+		// Read the region.
+		offset := (s * targetBlocks / samples) * blockSize
+		n, err := target.ReadAt(buffer, int64(offset))
+		if err != nil && err != io.EOF {
+			continue
+		} else if uint64(n) < blockSize {
+			continue
+		}
+		region := buffer[:n]
+
+		// Search for a matching block within the region.
+		weak, r1, r2 := e.weakHash(region[:blockSize], blockSize)
+		for start := uint64(0); ; start++ {
+			if e.matchesBlock(region[start:start+blockSize], weak, weakHashes, base) {
+				matches++
+				break
+			} else if start+blockSize >= uint64(len(region)) {
+				break
+			}
+			weak, r1, r2 = e.rollWeakHash(r1, r2, region[start], region[start+blockSize], blockSize)
+		}
+	}
+
+	// Estimate the delta size based on the fraction of sampled regions that
+	// contained a match.
+	matchedBytes := size * matches / samples
+	return size - matchedBytes + (matchedBytes/blockSize)*estimatedBlockOperationSize
+}
+
+// matchesBlock determines whether or not the specified data (with the
+// specified weak hash) matches a block in the base.
+func (e *Engine) matchesBlock(data []byte, weak uint32, weakHashes map[uint32][]int, base *Signature) bool {
+	candidates, ok := weakHashes[weak]
+	if !ok {
+		return false
+	}
+	strong := e.strongHash(data, false)
+	for _, c := range candidates {
+		if bytes.Equal(strong, base.Hashes[c].Strong) {
+			return true
+		}
+	}
+	return false
+}
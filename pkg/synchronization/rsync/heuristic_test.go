@@ -0,0 +1,217 @@
+package rsync
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testHeuristicText generates deterministic pseudorandom text containing the
+// specified number of lines.
+func testHeuristicText(seed int64, lines int) []byte {
+	words := []string{"alpha", "beta", "gamma", "delta", "epsilon", "zeta", "eta", "theta"}
+	random := rand.New(rand.NewSource(seed))
+	var result bytes.Buffer
+	for l := 0; l < lines; l++ {
+		for w := 0; w < 8; w++ {
+			fmt.Fprintf(&result, "%s%d ", words[random.Intn(len(words))], random.Intn(1000))
+		}
+		result.WriteByte('\n')
+	}
+	return result.Bytes()
+}
+
+// testHeuristicGzip compresses the specified data using gzip.
+func testHeuristicGzip(t *testing.T, data []byte) []byte {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Compress the data.
+	var result bytes.Buffer
+	compressor := gzip.NewWriter(&result)
+	if _, err := compressor.Write(data); err != nil {
+		t.Fatal("unable to compress data:", err)
+	} else if err = compressor.Close(); err != nil {
+		t.Fatal("unable to finish compression:", err)
+	}
+	return result.Bytes()
+}
+
+// testHeuristicModify returns a copy of the specified data with a slight
+// modification at the specified offset.
+func testHeuristicModify(data []byte, offset int) []byte {
+	result := make([]byte, 0, len(data)+8)
+	result = append(result, data[:offset]...)
+	result = append(result, "modified"...)
+	return append(result, data[offset:]...)
+}
+
+// testRecordingReceiver is a Receiver that records the operations that it
+// receives for a single file.
+type testRecordingReceiver struct {
+	// operations are the operations received.
+	operations []*Operation
+	// blockOperations is the number of block operations received.
+	blockOperations int
+	// done indicates whether or not the file's stream has been completed.
+	done bool
+	// err is any error reported for the file.
+	err string
+}
+
+// Receive implements Receiver.Receive.
+func (r *testRecordingReceiver) Receive(transmission *Transmission) error {
+	if transmission.Done {
+		r.done = true
+		r.err = transmission.Error
+	} else if transmission.Operation != nil {
+		if transmission.Operation.Count > 0 {
+			r.blockOperations++
+		}
+		r.operations = append(r.operations, transmission.Operation.Copy())
+	}
+	return nil
+}
+
+// finalize implements Receiver.finalize.
+func (r *testRecordingReceiver) finalize() error {
+	return nil
+}
+
+// testTransmitWithHeuristic transmits the specified target (stored with the
+// specified name) against the specified base using the specified heuristic.
+// It verifies that the target can be reconstituted from the transmission and
+// returns the number of block operations used.
+func testTransmitWithHeuristic(t *testing.T, name string, base, target []byte, heuristic *TransferHeuristic) int {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create a temporary directory to serve as the source and defer its
+	// removal.
+	source, err := ioutil.TempDir("", "mutagen_rsync_heuristic")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(source)
+
+	// Write the target.
+	if err := ioutil.WriteFile(filepath.Join(source, name), target, 0600); err != nil {
+		t.Fatal("unable to write target:", err)
+	}
+
+	// Compute the base signature and transmit the target.
+	engine := NewEngine()
+	signature := engine.BytesSignature(base, 0)
+	receiver := &testRecordingReceiver{}
+	if err := Transmit(source, []string{name}, []*Signature{signature}, receiver, 0, heuristic, nil); err != nil {
+		t.Fatal("unable to transmit target:", err)
+	} else if !receiver.done {
+		t.Fatal("transmission not completed")
+	} else if receiver.err != "" {
+		t.Fatal("transmission failed:", receiver.err)
+	}
+
+	// Verify that the target can be reconstituted.
+	if result, err := engine.PatchBytes(base, signature, receiver.operations); err != nil {
+		t.Fatal("unable to patch base:", err)
+	} else if !bytes.Equal(result, target) {
+		t.Fatal("patched base does not match target")
+	}
+
+	// Done.
+	return receiver.blockOperations
+}
+
+// TestTransferHeuristicIncompressibleFile tests that a compressed file whose
+// underlying content has changed slightly is transmitted in its entirety,
+// since the change alters most of its encoded representation.
+func TestTransferHeuristicIncompressibleFile(t *testing.T) {
+	// Create a compressed base and a compressed target with a modification
+	// near the start of its underlying content.
+	text := testHeuristicText(1, 20000)
+	base := testHeuristicGzip(t, text)
+	target := testHeuristicGzip(t, testHeuristicModify(text, 100))
+
+	// Verify that the heuristic chooses a whole-file transfer.
+	heuristic := NewAutomaticTransferHeuristic(nil)
+	engine := NewEngine()
+	signature := engine.BytesSignature(base, 0)
+	if heuristic.useDelta(engine, "archive.gz", bytes.NewReader(target), int64(len(target)), signature) {
+		t.Error("heuristic chose delta transfer for modified compressed file")
+	}
+
+	// Verify that the file is transmitted without block operations.
+	if blockOperations := testTransmitWithHeuristic(t, "archive.gz", base, target, heuristic); blockOperations != 0 {
+		t.Error("compressed file transmitted with block operations:", blockOperations)
+	}
+}
+
+// TestTransferHeuristicSimilarCompressedFile tests that a compressed file that
+// remains largely identical to its base is transmitted as a delta.
+func TestTransferHeuristicSimilarCompressedFile(t *testing.T) {
+	// Create a compressed base and a target with data appended.
+	base := testHeuristicGzip(t, testHeuristicText(1, 20000))
+	target := append(append([]byte{}, base...), testRandomData(2, 1024)...)
+
+	// Verify that the heuristic chooses a delta transfer and that the file is
+	// transmitted using block operations.
+	heuristic := NewAutomaticTransferHeuristic(nil)
+	engine := NewEngine()
+	signature := engine.BytesSignature(base, 0)
+	if !heuristic.useDelta(engine, "archive.gz", bytes.NewReader(target), int64(len(target)), signature) {
+		t.Error("heuristic chose whole-file transfer for similar compressed file")
+	}
+	if blockOperations := testTransmitWithHeuristic(t, "archive.gz", base, target, heuristic); blockOperations == 0 {
+		t.Error("similar compressed file transmitted without block operations")
+	}
+}
+
+// TestTransferHeuristicTextFile tests that a text file with a slight change is
+// transmitted as a delta.
+func TestTransferHeuristicTextFile(t *testing.T) {
+	// Create a base and a target with a modification near the start.
+	base := testHeuristicText(1, 20000)
+	target := testHeuristicModify(base, 100)
+
+	// Verify that the heuristic chooses a delta transfer and that the file is
+	// transmitted using block operations.
+	heuristic := NewAutomaticTransferHeuristic(nil)
+	engine := NewEngine()
+	signature := engine.BytesSignature(base, 0)
+	if !heuristic.useDelta(engine, "notes.txt", bytes.NewReader(target), int64(len(target)), signature) {
+		t.Error("heuristic chose whole-file transfer for text file")
+	}
+	if blockOperations := testTransmitWithHeuristic(t, "notes.txt", base, target, heuristic); blockOperations == 0 {
+		t.Error("text file transmitted without block operations")
+	}
+}
+
+// TestTransferHeuristicOverrides tests that transfer heuristics can override
+// the default choice of transfer.
+func TestTransferHeuristicOverrides(t *testing.T) {
+	// Create a base and a target with a modification near the start.
+	base := testHeuristicText(1, 20000)
+	target := testHeuristicModify(base, 100)
+
+	// Verify that a forced whole-file transfer doesn't use block operations.
+	if blockOperations := testTransmitWithHeuristic(t, "notes.txt", base, target, &TransferHeuristic{WholeFile: true}); blockOperations != 0 {
+		t.Error("forced whole-file transfer used block operations:", blockOperations)
+	}
+
+	// Verify that additional extensions are probed. The modification near the
+	// start shifts the content, but the probe should still detect matches.
+	heuristic := NewAutomaticTransferHeuristic([]string{"txt"})
+	if blockOperations := testTransmitWithHeuristic(t, "notes.txt", base, target, heuristic); blockOperations == 0 {
+		t.Error("probed text file transmitted without block operations")
+	}
+
+	// Verify that a nil heuristic always uses delta transfers.
+	if blockOperations := testTransmitWithHeuristic(t, "notes.txt", base, target, nil); blockOperations == 0 {
+		t.Error("delta transfer used no block operations")
+	}
+}
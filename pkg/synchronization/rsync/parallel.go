@@ -0,0 +1,161 @@
+package rsync
+
+import (
+	"io"
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultParallelRangeSize is the default size of the byte ranges into
+	// which targets are split by DeltafyParallel. It's chosen to be large
+	// enough that per-range overhead (engine setup and the loss of block
+	// matches spanning range boundaries) is negligible.
+	DefaultParallelRangeSize = 1 << 26
+	// parallelDeltafyThreshold is the minimum target size for which Transmit
+	// will use parallel deltafication.
+	parallelDeltafyThreshold = 4 * DefaultParallelRangeSize
+	// parallelOperationBufferSize is the number of operations that each range
+	// may buffer while waiting for preceding ranges to be transmitted.
+	parallelOperationBufferSize = 64
+)
+
+// errParallelDeltafyCancelled is returned by range transmitters when parallel
+// deltafication has been cancelled.
+var errParallelDeltafyCancelled = errors.New("parallel deltafication cancelled")
+
+// parallelRange represents a single byte range of a target being deltafied by
+// DeltafyParallel.
+type parallelRange struct {
+	// offset is the offset of the range within the target.
+	offset uint64
+	// length is the length of the range.
+	length uint64
+	// operations receives copies of the operations generated for the range. It
+	// is closed once deltafication of the range is complete.
+	operations chan *Operation
+	// err is the error (if any) that occurred while deltafying the range. It
+	// may only be read once operations has been closed.
+	err error
+}
+
+// DeltafyParallel computes delta operations to reconstitute a target using the
+// base (based on the provided base signature), much like Engine.Deltafy, but
+// splits the target into independent byte ranges whose deltas are computed in
+// parallel by a bounded pool of engines. The resulting operations are streamed
+// to the provided transmission function in target order, so they can be
+// applied using Engine.Patch exactly as those generated by Engine.Deltafy. The
+// range size is rounded up to a multiple of the base block size so that range
+// boundaries align with block boundaries, meaning that blocks at unchanged
+// offsets will still be matched. If the range size is 0, then
+// DefaultParallelRangeSize is used, and if the number of workers is less than
+// 1, then the number of available CPUs is used. The transmission function is
+// only invoked from the calling goroutine. The same validity requirements for
+// signatures described in Engine.Deltafy apply.
+func DeltafyParallel(target io.ReaderAt, targetLength uint64, base *Signature, maxDataOpSize, rangeSize uint64, workers int, transmit OperationTransmitter) error {
+	// Determine the range size, ensuring that it's a multiple of the block
+	// size.
+	if rangeSize == 0 {
+		rangeSize = DefaultParallelRangeSize
+	}
+	if base.BlockSize > 0 && rangeSize%base.BlockSize != 0 {
+		rangeSize += base.BlockSize - rangeSize%base.BlockSize
+	}
+
+	// Determine the number of workers.
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	// If the target would only consist of a single range, or if only a single
+	// worker is allowed, then there's no benefit to parallelization.
+	if targetLength <= rangeSize || workers == 1 {
+		return NewEngine().Deltafy(
+			io.NewSectionReader(target, 0, int64(targetLength)),
+			base, maxDataOpSize, transmit,
+		)
+	}
+
+	// Split the target into ranges.
+	var ranges []*parallelRange
+	for offset := uint64(0); offset < targetLength; offset += rangeSize {
+		ranges = append(ranges, &parallelRange{
+			offset:     offset,
+			length:     min(rangeSize, targetLength-offset),
+			operations: make(chan *Operation, parallelOperationBufferSize),
+		})
+	}
+
+	// Create a pool of engines. Acquiring an engine from the pool is what
+	// bounds the number of concurrently processed ranges.
+	if workers > len(ranges) {
+		workers = len(ranges)
+	}
+	engines := make(chan *Engine, workers)
+	for w := 0; w < workers; w++ {
+		engines <- NewEngine()
+	}
+
+	// Create a channel to signal cancellation to workers. Ensure that it's
+	// closed and that all workers have exited before we return, since the
+	// target may become invalid once we return.
+	cancelled := make(chan struct{})
+	dispatcherDone := make(chan struct{})
+	defer func() {
+		close(cancelled)
+		<-dispatcherDone
+		for w := 0; w < workers; w++ {
+			<-engines
+		}
+	}()
+
+	// Start a dispatcher that deltafies ranges in order as engines become
+	// available. Because ranges are started in order, the earliest range that
+	// hasn't been fully transmitted always has an engine, so the consumer
+	// below can't deadlock waiting on it.
+	go func() {
+		defer close(dispatcherDone)
+		for _, r := range ranges {
+			var engine *Engine
+			select {
+			case engine = <-engines:
+			case <-cancelled:
+				return
+			}
+			go func(r *parallelRange) {
+				defer func() {
+					close(r.operations)
+					engines <- engine
+				}()
+				r.err = engine.Deltafy(
+					io.NewSectionReader(target, int64(r.offset), int64(r.length)),
+					base, maxDataOpSize,
+					func(o *Operation) error {
+						select {
+						case r.operations <- o.Copy():
+							return nil
+						case <-cancelled:
+							return errParallelDeltafyCancelled
+						}
+					},
+				)
+			}(r)
+		}
+	}()
+
+	// Transmit operations from each range in order.
+	for _, r := range ranges {
+		for o := range r.operations {
+			if err := transmit(o); err != nil {
+				return errors.Wrap(err, "unable to transmit operation")
+			}
+		}
+		if r.err != nil {
+			return errors.Wrapf(r.err, "unable to deltafy range at offset %d", r.offset)
+		}
+	}
+
+	// Success.
+	return nil
+}
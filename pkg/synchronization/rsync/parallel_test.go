@@ -0,0 +1,150 @@
+package rsync
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// testParallelDeltafy performs parallel deltafication of the target against
+// the base, applies the resulting delta, and verifies that the reconstructed
+// target is byte-identical to the original. It returns the generated delta.
+func testParallelDeltafy(t *testing.T, base, target []byte, blockSize, rangeSize uint64, workers int) []*Operation {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Compute the base signature.
+	engine := NewEngine()
+	signature := engine.BytesSignature(base, blockSize)
+
+	// Perform parallel deltafication.
+	var delta []*Operation
+	transmit := func(o *Operation) error {
+		if err := o.EnsureValid(); err != nil {
+			return err
+		}
+		delta = append(delta, o.Copy())
+		return nil
+	}
+	if err := DeltafyParallel(bytes.NewReader(target), uint64(len(target)), signature, 0, rangeSize, workers, transmit); err != nil {
+		t.Fatal("unable to perform parallel deltafication:", err)
+	}
+
+	// Apply the delta and verify that the result matches the target.
+	patched, err := engine.PatchBytes(base, signature, delta)
+	if err != nil {
+		t.Fatal("unable to patch bytes:", err)
+	} else if sha1.Sum(patched) != sha1.Sum(target) || !bytes.Equal(patched, target) {
+		t.Fatal("reconstructed target does not match original")
+	}
+
+	// Done.
+	return delta
+}
+
+// TestDeltafyParallelReconstruction tests that parallel deltafication of a
+// large, modified file yields a byte-identical reconstruction.
+func TestDeltafyParallelReconstruction(t *testing.T) {
+	// Generate a base and a target with mutations spread across (and at the
+	// boundaries of) ranges, as well as some prepended data.
+	base := testDataGenerator{length: 8 << 20, seed: 473}.generate()
+	target := testDataGenerator{
+		length:    8 << 20,
+		seed:      473,
+		mutations: []int{0, 1 << 20, (1 << 20) + 1, 3 << 20, (5 << 20) - 1, (8 << 20) - 1},
+		prepend:   []byte("prepended data that shifts block alignment"),
+	}.generate()
+
+	// Perform the test using a range size that isn't a multiple of the block
+	// size and with varying numbers of workers.
+	for _, workers := range []int{0, 2, 3, 16} {
+		testParallelDeltafy(t, base, target, 4096, (1<<20)+1, workers)
+	}
+}
+
+// TestDeltafyParallelRangeAlignment tests that range boundaries are aligned with
+// block boundaries, such that identical content yields only block operations.
+func TestDeltafyParallelRangeAlignment(t *testing.T) {
+	// Generate content.
+	data := testDataGenerator{length: (4 << 20) + 123, seed: 474}.generate()
+
+	// Perform deltafication with a range size that isn't block-aligned and
+	// verify that no data operations are generated.
+	delta := testParallelDeltafy(t, data, data, 4096, 1000000, 4)
+	for _, o := range delta {
+		if len(o.Data) > 0 {
+			t.Fatal("data operation generated for identical content")
+		}
+	}
+}
+
+// TestDeltafyParallelEmptyBase tests parallel deltafication against an empty
+// base.
+func TestDeltafyParallelEmptyBase(t *testing.T) {
+	target := testDataGenerator{length: (2 << 20) + 17, seed: 475}.generate()
+	testParallelDeltafy(t, nil, target, 0, 1<<18, 4)
+}
+
+// TestDeltafyParallelSingleRange tests parallel deltafication of a target that
+// fits within a single range.
+func TestDeltafyParallelSingleRange(t *testing.T) {
+	base := testDataGenerator{length: 1 << 16, seed: 476}.generate()
+	target := testDataGenerator{length: 1 << 16, seed: 476, mutations: []int{1000}}.generate()
+	testParallelDeltafy(t, base, target, 1024, 0, 4)
+}
+
+// TestDeltafyParallelTransmitError tests that transmission errors are reported
+// by parallel deltafication.
+func TestDeltafyParallelTransmitError(t *testing.T) {
+	// Generate content.
+	target := testDataGenerator{length: 4 << 20, seed: 477}.generate()
+	signature := NewEngine().BytesSignature(nil, 0)
+
+	// Create a transmitter that fails after a few operations.
+	transmitErr := errors.New("transmit failure")
+	var transmitted int
+	transmit := func(_ *Operation) error {
+		if transmitted == 10 {
+			return transmitErr
+		}
+		transmitted++
+		return nil
+	}
+
+	// Perform deltafication and verify that the error is reported.
+	err := DeltafyParallel(bytes.NewReader(target), uint64(len(target)), signature, 0, 1<<18, 4, transmit)
+	if err == nil {
+		t.Fatal("transmission error not reported")
+	} else if transmitted != 10 {
+		t.Error("unexpected number of operations transmitted:", transmitted)
+	}
+}
+
+// BenchmarkDeltafyParallel benchmarks parallel deltafication of a large target
+// with varying numbers of workers.
+func BenchmarkDeltafyParallel(b *testing.B) {
+	// Generate a base and an unrelated target so that the search covers the
+	// entire target.
+	base := testDataGenerator{length: 16 << 20, seed: 478}.generate()
+	target := testDataGenerator{length: 16 << 20, seed: 479}.generate()
+	signature := NewEngine().BytesSignature(base, 0)
+
+	// Create a transmitter that discards operations.
+	transmit := func(_ *Operation) error {
+		return nil
+	}
+
+	// Perform benchmarks.
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("Workers%d", workers), func(b *testing.B) {
+			b.SetBytes(int64(len(target)))
+			for i := 0; i < b.N; i++ {
+				if err := DeltafyParallel(bytes.NewReader(target), uint64(len(target)), signature, 0, 1<<20, workers, transmit); err != nil {
+					b.Fatal("unable to perform parallel deltafication:", err)
+				}
+			}
+		})
+	}
+}
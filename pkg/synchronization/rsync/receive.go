@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"os"
 
 	"github.com/pkg/errors"
 
@@ -46,10 +47,21 @@ type Receiver interface {
 // Sinker provides the interface for a receiver to store incoming files.
 type Sinker interface {
 	// Sink should return a new io.WriteCloser for staging the given path. Each
-	// result it returns will be closed before Sink is invoked again.
+	// result it returns will be closed before Sink is invoked again. If the
+	// result also implements HoleWriter, then it will be used to write holes.
 	Sink(path string) (io.WriteCloser, error)
 }
 
+// BaseSinker is an optional interface that Sinkers can implement to receive the
+// base for a path when creating its sink. This allows the Sinker to return a
+// sink that's initialized with the content of the base (e.g. by cloning it), in
+// which case the sink should also implement InPlaceWriter. The base remains
+// owned by the receiver and shouldn't be closed or repositioned by the Sinker.
+type BaseSinker interface {
+	// SinkWithBase behaves like Sink, but also provides the base for the path.
+	SinkWithBase(path string, base *os.File) (io.WriteCloser, error)
+}
+
 // readSeekCloser is the union of io.Reader, io.Seeker, and io.Closer.
 type readSeekCloser interface {
 	io.Reader
@@ -139,6 +151,22 @@ func (r *receiver) Receive(transmission *Transmission) error {
 		return errors.New("unexpected file transmission")
 	}
 
+	// Check if this is a restart transmission. If so, close out the base and
+	// target if they're open and reset burning status. The next operation for
+	// the file will re-open them, restarting the file stream. The partial
+	// content written to the target is harmless, since it won't match the
+	// expected digest of the file.
+	if transmission.Restart {
+		if r.base != nil {
+			r.base.Close()
+			r.base = nil
+			r.target.Close()
+			r.target = nil
+		}
+		r.burning = false
+		return nil
+	}
+
 	// Check if we need to skip this transmission due to burning.
 	skip := r.burning
 
@@ -204,9 +232,18 @@ func (r *receiver) Receive(transmission *Transmission) error {
 			r.base = base
 		}
 
-		// Create a sink. If that fails, then we need to close out the base and
-		// burn this file stream, but it's not a terminal error.
-		if target, err := r.sinker.Sink(path); err != nil {
+		// Create a sink, providing the base to the sinker if it's a file and
+		// the sinker accepts it. If that fails, then we need to close out the
+		// base and burn this file stream, but it's not a terminal error.
+		var target io.WriteCloser
+		var err error
+		baseSinker, acceptsBase := r.sinker.(BaseSinker)
+		if baseFile, isFile := r.base.(*os.File); acceptsBase && isFile {
+			target, err = baseSinker.SinkWithBase(path, baseFile)
+		} else {
+			target, err = r.sinker.Sink(path)
+		}
+		if err != nil {
 			r.base.Close()
 			r.base = nil
 			r.burning = true
@@ -404,6 +441,96 @@ func (r *preemptableReceiver) finalize() error {
 	return r.receiver.finalize()
 }
 
+// Throttler is the interface used by a throttled receiver to regulate the rate
+// at which data is received.
+type Throttler interface {
+	// Throttle blocks until the specified number of data bytes may be received
+	// or until the specified context is cancelled, in which case it returns an
+	// error.
+	Throttle(ctx context.Context, size uint64) error
+}
+
+// throttledReceiver is a Receiver implementation that regulates the rate at
+// which data is received.
+type throttledReceiver struct {
+	// ctx is the context in which the receiver is receiving.
+	ctx context.Context
+	// receiver is the underlying receiver.
+	receiver Receiver
+	// throttler is the throttler used to regulate reception.
+	throttler Throttler
+}
+
+// NewThrottledReceiver wraps a receiver and blocks on Receive until the
+// specified throttler allows any data contained in the transmission to be
+// received. Like CountingReceiver, it only considers literal data transmitted
+// in operations. Throttling is aborted if the specified context is cancelled.
+func NewThrottledReceiver(ctx context.Context, receiver Receiver, throttler Throttler) Receiver {
+	return &throttledReceiver{
+		ctx:       ctx,
+		receiver:  receiver,
+		throttler: throttler,
+	}
+}
+
+// Receive waits for the throttler to allow any data contained in the
+// transmission and then forwards the transmission to the underlying receiver.
+func (r *throttledReceiver) Receive(transmission *Transmission) error {
+	// Throttle any data in the transmission.
+	if transmission.Operation != nil && len(transmission.Operation.Data) > 0 {
+		if err := r.throttler.Throttle(r.ctx, uint64(len(transmission.Operation.Data))); err != nil {
+			return errors.Wrap(err, "unable to throttle reception")
+		}
+	}
+
+	// Forward the transmission.
+	return r.receiver.Receive(transmission)
+}
+
+// finalize invokes finalize on the underlying receiver.
+func (r *throttledReceiver) finalize() error {
+	return r.receiver.finalize()
+}
+
+// CountingReceiver is a Receiver implementation that counts the number of data
+// bytes received. It only counts literal data transmitted in operations, so
+// content reconstructed from blocks of the base doesn't contribute to the count.
+type CountingReceiver struct {
+	// receiver is the underlying receiver.
+	receiver Receiver
+	// received is the number of data bytes received so far.
+	received uint64
+}
+
+// NewCountingReceiver wraps a receiver and counts the number of data bytes
+// received. The count can be queried using the Received method.
+func NewCountingReceiver(receiver Receiver) *CountingReceiver {
+	return &CountingReceiver{receiver: receiver}
+}
+
+// Receive counts any data contained in the transmission and forwards it to the
+// underlying receiver.
+func (r *CountingReceiver) Receive(transmission *Transmission) error {
+	// Count any data in the transmission.
+	if transmission.Operation != nil {
+		r.received += uint64(len(transmission.Operation.Data))
+	}
+
+	// Forward the transmission.
+	return r.receiver.Receive(transmission)
+}
+
+// Received returns the number of data bytes received so far. It shouldn't be
+// called concurrently with Receive.
+func (r *CountingReceiver) Received() uint64 {
+	return r.received
+}
+
+// finalize invokes finalize on the underlying receiver.
+func (r *CountingReceiver) finalize() error {
+	return r.receiver.finalize()
+}
+
 // Encoder is the interface used by an encoding receiver to forward
 // transmissions, usually across a network.
 type Encoder interface {
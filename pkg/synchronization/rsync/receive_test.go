@@ -1,3 +1,79 @@
 package rsync
 
-// TODO: Implement.
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// testNopReceiver is a Receiver that counts received transmissions.
+type testNopReceiver struct {
+	// received is the number of transmissions received.
+	received int
+}
+
+// Receive implements Receiver.Receive.
+func (r *testNopReceiver) Receive(_ *Transmission) error {
+	r.received++
+	return nil
+}
+
+// finalize implements Receiver.finalize.
+func (r *testNopReceiver) finalize() error {
+	return nil
+}
+
+// testRecordingThrottler is a Throttler that records throttled sizes.
+type testRecordingThrottler struct {
+	// sizes are the throttled sizes.
+	sizes []uint64
+	// err is the error to return from Throttle, if any.
+	err error
+}
+
+// Throttle implements Throttler.Throttle.
+func (t *testRecordingThrottler) Throttle(_ context.Context, size uint64) error {
+	if t.err != nil {
+		return t.err
+	}
+	t.sizes = append(t.sizes, size)
+	return nil
+}
+
+// TestThrottledReceiver tests that throttled receivers throttle only literal
+// data and abort reception on throttling failure.
+func TestThrottledReceiver(t *testing.T) {
+	// Create a throttled receiver.
+	underlying := &testNopReceiver{}
+	throttler := &testRecordingThrottler{}
+	receiver := NewThrottledReceiver(context.Background(), underlying, throttler)
+
+	// Transmit a data operation, a block operation, and a completion.
+	transmissions := []*Transmission{
+		{Operation: &Operation{Data: make([]byte, 100)}},
+		{Operation: &Operation{Start: 1, Count: 2}},
+		{Done: true},
+	}
+	for _, transmission := range transmissions {
+		if err := receiver.Receive(transmission); err != nil {
+			t.Fatal("unable to receive transmission:", err)
+		}
+	}
+	if underlying.received != len(transmissions) {
+		t.Error("unexpected number of forwarded transmissions:", underlying.received)
+	}
+	if len(throttler.sizes) != 1 || throttler.sizes[0] != 100 {
+		t.Error("unexpected throttled sizes:", throttler.sizes)
+	}
+
+	// Verify that throttling failures abort reception without forwarding.
+	throttler.err = errors.New("throttling failed")
+	if err := receiver.Receive(transmissions[0]); err == nil {
+		t.Error("reception succeeded despite throttling failure")
+	} else if underlying.received != len(transmissions) {
+		t.Error("transmission forwarded despite throttling failure")
+	}
+	if err := receiver.finalize(); err != nil {
+		t.Error("unable to finalize receiver:", err)
+	}
+}
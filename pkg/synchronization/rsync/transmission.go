@@ -20,6 +20,9 @@ func (t *Transmission) resetToZeroMaintainingCapacity() {
 
 	// Reset the error parameter.
 	t.Error = ""
+
+	// Reset the restart parameter.
+	t.Restart = false
 }
 
 // EnsureValid ensures that the Transmission's invariants are respected.
@@ -30,8 +33,16 @@ func (t *Transmission) EnsureValid() error {
 	}
 
 	// Handle validation based on whether or not the operation is marked as
-	// done.
-	if t.Done {
+	// done or as a restart.
+	if t.Restart {
+		if t.Done {
+			return errors.New("restart marked as end of stream")
+		} else if t.Operation != nil && !t.Operation.isZeroValue() {
+			return errors.New("operation present in restart")
+		} else if t.Error != "" {
+			return errors.New("error present in restart")
+		}
+	} else if t.Done {
 		if t.Operation != nil && !t.Operation.isZeroValue() {
 			return errors.New("operation present at end of stream")
 		}
@@ -1,17 +1,53 @@
 package rsync
 
 import (
+	"io"
+	"os"
+
 	"github.com/pkg/errors"
 
 	fs "github.com/mutagen-io/mutagen/pkg/filesystem"
 )
 
+// statter is the interface implemented by files that support metadata queries.
+type statter interface {
+	// Stat returns the metadata for the file.
+	Stat() (os.FileInfo, error)
+}
+
+// modifiedSince determines whether or not a file has been modified since the
+// specified metadata was recorded, based on its size and modification time. If
+// the file doesn't support metadata queries (or the query fails), then it's
+// assumed to be unmodified.
+func modifiedSince(file fs.ReadableFile, previous os.FileInfo) bool {
+	if previous == nil {
+		return false
+	}
+	s, ok := file.(statter)
+	if !ok {
+		return false
+	}
+	current, err := s.Stat()
+	if err != nil {
+		return false
+	}
+	return current.Size() != previous.Size() || !current.ModTime().Equal(previous.ModTime())
+}
+
 // Transmit performs streaming transmission of files (in rsync deltafied form)
 // to the specified receiver. It is the responsibility of the caller to ensure
 // that the provided signatures are valid by invoking their EnsureValid method.
 // In order for this function to perform efficiently, paths should be passed in
-// depth-first traversal order.
-func Transmit(root string, paths []string, signatures []*Signature, receiver Receiver) error {
+// depth-first traversal order. Files are re-queried after being read in order
+// to detect modifications during transmission. If a file is modified, then its
+// transmission is restarted up to maximumRetries times, after which a
+// non-terminal error is reported to the receiver for that file (which will
+// typically defer its staging to a subsequent synchronization cycle). The
+// specified heuristic determines whether each file is transmitted as a delta or
+// in its entirety. If it's nil, then all files are transmitted as deltas. The
+// specified budget (which may be nil) limits the amount of literal data
+// transmitted, with files that don't fit being deferred.
+func Transmit(root string, paths []string, signatures []*Signature, receiver Receiver, maximumRetries uint, heuristic *TransferHeuristic, budget *TransmissionBudget) error {
 	// Ensure that the transmission request is sane.
 	if len(paths) != len(signatures) {
 		receiver.finalize()
@@ -29,57 +65,126 @@ func Transmit(root string, paths []string, signatures []*Signature, receiver Rec
 	// Create a transmission object that we can re-use to avoid allocating.
 	transmission := &Transmission{}
 
+	// Create an empty signature that we can use for whole-file transfers.
+	wholeFile := &Signature{}
+
+	// Track the amount of literal data transmitted, including data from
+	// restarted transmissions.
+	var transmitted uint64
+
 	// Handle the requested files.
 	for i, p := range paths {
-		// Open the file. If this fails, it's a non-terminal error, but we
-		// need to inform the receiver. If sending the message fails, that is
-		// a terminal error.
-		file, err := opener.Open(p)
-		if err != nil {
-			*transmission = Transmission{
-				Done:  true,
-				Error: errors.Wrap(err, "unable to open file").Error(),
+		for attempt := uint(0); ; attempt++ {
+			// Open the file. If this fails, it's a non-terminal error, but we
+			// need to inform the receiver. If sending the message fails, that
+			// is a terminal error.
+			file, err := opener.Open(p)
+			if err != nil {
+				*transmission = Transmission{
+					Done:  true,
+					Error: errors.Wrap(err, "unable to open file").Error(),
+				}
+				if err = receiver.Receive(transmission); err != nil {
+					receiver.finalize()
+					return errors.Wrap(err, "unable to send error transmission")
+				}
+				break
 			}
-			if err = receiver.Receive(transmission); err != nil {
-				receiver.finalize()
-				return errors.Wrap(err, "unable to send error transmission")
+
+			// Record the file's metadata so that we can detect modifications
+			// during transmission.
+			var metadata os.FileInfo
+			if s, ok := file.(statter); ok {
+				metadata, _ = s.Stat()
 			}
-			continue
-		}
 
-		// Create an operation transmitter for deltafication and track reception
-		// errors. We can safely set transmitError on each call because as soon
-		// as it's returned non-nil, the transmit function won't be called
-		// again.
-		var transmitError error
-		transmit := func(o *Operation) error {
-			*transmission = Transmission{Operation: o}
-			transmitError = receiver.Receive(transmission)
-			return transmitError
-		}
+			// Create an operation transmitter for deltafication and track
+			// reception errors. We can safely set transmitError on each call
+			// because as soon as it's returned non-nil, the transmit function
+			// won't be called again.
+			var transmitError error
+			transmit := func(o *Operation) error {
+				transmitted += uint64(len(o.Data))
+				*transmission = Transmission{Operation: o}
+				transmitError = receiver.Receive(transmission)
+				return transmitError
+			}
 
-		// Perform deltafication.
-		err = engine.Deltafy(file, signatures[i], 0, transmit)
+			// Determine whether the file should be transmitted as a delta or
+			// in its entirety. Whole-file transfers are performed by
+			// deltafying against an empty signature.
+			var size int64
+			if metadata != nil {
+				size = metadata.Size()
+			}
 
-		// Close the file.
-		file.Close()
+			// If the file doesn't fit within the budget, then defer it. This
+			// is non-terminal, but the receiver needs to be informed.
+			if !budget.admits(transmitted, uint64(size)) {
+				file.Close()
+				*transmission = Transmission{
+					Done:  true,
+					Error: DeferredTransmissionError,
+				}
+				if err = receiver.Receive(transmission); err != nil {
+					receiver.finalize()
+					return errors.Wrap(err, "unable to send deferral transmission")
+				}
+				break
+			}
+			signature := signatures[i]
+			if !heuristic.useDelta(engine, p, file, size, signature) {
+				signature = wholeFile
+			}
 
-		// Handle any transmission errors. These are terminal.
-		if transmitError != nil {
-			receiver.finalize()
-			return errors.Wrap(transmitError, "unable to transmit delta")
-		}
+			// Perform deltafication. For very large non-sparse files that
+			// support random access, we deltafy byte ranges in parallel.
+			// Otherwise, we read the file using hole detection so that holes in
+			// sparse files don't need to be read from disk.
+			if readerAt, ok := file.(io.ReaderAt); ok && signature != wholeFile && size >= parallelDeltafyThreshold && !fs.HasHoles(file, size) {
+				err = DeltafyParallel(readerAt, uint64(size), signature, 0, 0, 0, transmit)
+			} else {
+				err = engine.Deltafy(fs.NewSparseReader(file), signature, 0, transmit)
+			}
 
-		// Inform the client the operation stream for this file is complete. Any
-		// internal (non-transmission) errors are non-terminal but should be
-		// reported to the receiver.
-		*transmission = Transmission{Done: true}
-		if err != nil {
-			transmission.Error = errors.Wrap(err, "engine error").Error()
-		}
-		if err = receiver.Receive(transmission); err != nil {
-			receiver.finalize()
-			return errors.Wrap(err, "unable to send done message")
+			// Check whether or not the file was modified during transmission
+			// and close the file.
+			modified := err == nil && modifiedSince(file, metadata)
+			file.Close()
+
+			// Handle any transmission errors. These are terminal.
+			if transmitError != nil {
+				receiver.finalize()
+				return errors.Wrap(transmitError, "unable to transmit delta")
+			}
+
+			// If the file was modified and we have retries remaining, then
+			// inform the receiver that the file stream is restarting and try
+			// again.
+			if modified && attempt < maximumRetries {
+				*transmission = Transmission{Restart: true}
+				if err = receiver.Receive(transmission); err != nil {
+					receiver.finalize()
+					return errors.Wrap(err, "unable to send restart message")
+				}
+				continue
+			}
+
+			// Inform the client the operation stream for this file is complete.
+			// Any internal (non-transmission) errors are non-terminal but
+			// should be reported to the receiver, as should modifications
+			// during transmission.
+			*transmission = Transmission{Done: true}
+			if err != nil {
+				transmission.Error = errors.Wrap(err, "engine error").Error()
+			} else if modified {
+				transmission.Error = "file modified during transmission"
+			}
+			if err = receiver.Receive(transmission); err != nil {
+				receiver.finalize()
+				return errors.Wrap(err, "unable to send done message")
+			}
+			break
 		}
 	}
 
@@ -0,0 +1,165 @@
+package rsync
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testMemorySink is an io.WriteCloser that records its contents in a
+// testMemorySinker when closed.
+type testMemorySink struct {
+	bytes.Buffer
+	// sinker is the parent sinker.
+	sinker *testMemorySinker
+	// path is the path being sinked.
+	path string
+}
+
+// Close implements io.Closer.Close.
+func (s *testMemorySink) Close() error {
+	s.sinker.contents[s.path] = s.Bytes()
+	return nil
+}
+
+// testMemorySinker is a Sinker that records sinked contents in memory.
+type testMemorySinker struct {
+	// contents are the contents of closed sinks, keyed by path.
+	contents map[string][]byte
+}
+
+// Sink implements Sinker.Sink.
+func (s *testMemorySinker) Sink(path string) (io.WriteCloser, error) {
+	return &testMemorySink{sinker: s, path: path}, nil
+}
+
+// testModifyingReceiver is a Receiver that modifies the source file upon
+// receiving the first operation of each transmission attempt, up to a specified
+// number of times. It records restarts and the error reported upon completion.
+type testModifyingReceiver struct {
+	Receiver
+	// t is the test being run.
+	t *testing.T
+	// path is the path to the source file.
+	path string
+	// modifications is the number of modifications remaining.
+	modifications int
+	// modified indicates whether or not the current attempt has been modified.
+	modified bool
+	// restarts is the number of restart transmissions received.
+	restarts int
+	// err is the error reported in the done transmission.
+	err string
+}
+
+// Receive implements Receiver.Receive.
+func (r *testModifyingReceiver) Receive(transmission *Transmission) error {
+	if transmission.Operation != nil && !r.modified && r.modifications > 0 {
+		file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			r.t.Fatal("unable to open source file for modification:", err)
+		}
+		if _, err := file.Write([]byte("modification")); err != nil {
+			file.Close()
+			r.t.Fatal("unable to modify source file:", err)
+		} else if err = file.Close(); err != nil {
+			r.t.Fatal("unable to close modified source file:", err)
+		}
+		r.modifications--
+		r.modified = true
+	} else if transmission.Restart {
+		r.restarts++
+		r.modified = false
+	} else if transmission.Done {
+		r.err = transmission.Error
+	}
+	return r.Receiver.Receive(transmission)
+}
+
+// testTransmitModifiedFile transmits a file that is modified the specified
+// number of times during transmission. It returns the number of restarts, the
+// error reported upon completion, and whether or not the received content
+// matches the final source content.
+func testTransmitModifiedFile(t *testing.T, maximumRetries uint, modifications int) (int, string, bool) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create a temporary source directory and defer its removal.
+	source, err := ioutil.TempDir("", "mutagen_rsync_modified")
+	if err != nil {
+		t.Fatal("unable to create temporary source directory:", err)
+	}
+	defer os.RemoveAll(source)
+
+	// Create the source file.
+	sourcePath := filepath.Join(source, "file")
+	if err := ioutil.WriteFile(sourcePath, bytes.Repeat([]byte("data"), 4096), 0600); err != nil {
+		t.Fatal("unable to create source file:", err)
+	}
+
+	// Create a receiver.
+	sinker := &testMemorySinker{contents: make(map[string][]byte)}
+	signatures := []*Signature{{}}
+	receiver, err := NewReceiver(source, []string{"file"}, signatures, sinker)
+	if err != nil {
+		t.Fatal("unable to create receiver:", err)
+	}
+	modifier := &testModifyingReceiver{
+		Receiver:      receiver,
+		t:             t,
+		path:          sourcePath,
+		modifications: modifications,
+	}
+
+	// Perform transmission.
+	if err := Transmit(source, []string{"file"}, signatures, modifier, maximumRetries, nil, nil); err != nil {
+		t.Fatal("unable to transmit file:", err)
+	}
+
+	// Compare the received content with the final source content.
+	expected, err := ioutil.ReadFile(sourcePath)
+	if err != nil {
+		t.Fatal("unable to read source file:", err)
+	}
+
+	// Done.
+	return modifier.restarts, modifier.err, bytes.Equal(sinker.contents["file"], expected)
+}
+
+// TestTransmitModifiedFile tests that files modified during transmission are
+// restarted or reported as modified according to the retry limit.
+func TestTransmitModifiedFile(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		description      string
+		maximumRetries   uint
+		modifications    int
+		expectedRestarts int
+		expectError      bool
+	}{
+		{"unmodified", 3, 0, 0, false},
+		{"retry with single modification", 3, 1, 1, false},
+		{"defer with single modification", 0, 1, 0, true},
+		{"retry with persistent modification", 2, 100, 2, true},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		restarts, err, matches := testTransmitModifiedFile(t, testCase.maximumRetries, testCase.modifications)
+		if restarts != testCase.expectedRestarts {
+			t.Errorf("%s: restart count (%d) does not match expected (%d)",
+				testCase.description, restarts, testCase.expectedRestarts,
+			)
+		}
+		if testCase.expectError && err == "" {
+			t.Errorf("%s: modification not reported", testCase.description)
+		} else if !testCase.expectError && err != "" {
+			t.Errorf("%s: unexpected transmission error: %s", testCase.description, err)
+		} else if !testCase.expectError && !matches {
+			t.Errorf("%s: received content does not match source content", testCase.description)
+		}
+	}
+}
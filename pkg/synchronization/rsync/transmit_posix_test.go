@@ -0,0 +1,213 @@
+// +build !windows
+
+package rsync
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	fs "github.com/mutagen-io/mutagen/pkg/filesystem"
+)
+
+const (
+	// testSparseHoleSize is the size of holes in test sparse files.
+	testSparseHoleSize = 1 << 20
+)
+
+// testSparseSink is an io.WriteCloser and HoleWriter that writes a sparse file
+// to a temporary location and then moves it into place.
+type testSparseSink struct {
+	// file is the temporary file.
+	file *os.File
+	// writer is the sparse writer for the file.
+	writer *fs.SparseWriter
+	// destination is the final path for the file.
+	destination string
+}
+
+// Write implements io.Writer.Write.
+func (s *testSparseSink) Write(data []byte) (int, error) {
+	return s.writer.Write(data)
+}
+
+// WriteHole implements HoleWriter.WriteHole.
+func (s *testSparseSink) WriteHole(length uint64) error {
+	return s.writer.WriteHole(length)
+}
+
+// Close implements io.Closer.Close.
+func (s *testSparseSink) Close() error {
+	if err := s.writer.Finish(); err != nil {
+		s.file.Close()
+		return err
+	} else if err = s.file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(s.file.Name(), s.destination)
+}
+
+// testSparseSinker is a Sinker that creates testSparseSink instances.
+type testSparseSinker struct {
+	// root is the destination root.
+	root string
+}
+
+// Sink implements Sinker.Sink.
+func (s *testSparseSinker) Sink(path string) (io.WriteCloser, error) {
+	file, err := ioutil.TempFile(s.root, "sink")
+	if err != nil {
+		return nil, err
+	}
+	return &testSparseSink{
+		file:        file,
+		writer:      fs.NewSparseWriter(file),
+		destination: filepath.Join(s.root, path),
+	}, nil
+}
+
+// testCountingReceiver is a Receiver that counts transmitted data bytes.
+type testCountingReceiver struct {
+	Receiver
+	// data is the number of data bytes received.
+	data uint64
+}
+
+// Receive implements Receiver.Receive.
+func (r *testCountingReceiver) Receive(transmission *Transmission) error {
+	if transmission.Operation != nil {
+		r.data += uint64(len(transmission.Operation.Data))
+	}
+	return r.Receiver.Receive(transmission)
+}
+
+// testAllocatedSize returns the number of bytes allocated on disk for a file.
+func testAllocatedSize(t *testing.T, path string) int64 {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Query file metadata.
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal("unable to query file metadata:", err)
+	}
+
+	// Extract the allocated block count, which is always in 512-byte units.
+	return info.Sys().(*syscall.Stat_t).Blocks * 512
+}
+
+// testWriteSparseFile writes data at the specified offset in a file of the
+// specified size, which is created (sparse) if it doesn't exist.
+func testWriteSparseFile(t *testing.T, path string, data []byte, offset, size int64) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Write the data and set the file size.
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		t.Fatal("unable to open file:", err)
+	}
+	if _, err := file.WriteAt(data, offset); err != nil {
+		file.Close()
+		t.Fatal("unable to write file data:", err)
+	} else if err = file.Truncate(size); err != nil {
+		file.Close()
+		t.Fatal("unable to set file size:", err)
+	} else if err = file.Close(); err != nil {
+		t.Fatal("unable to close file:", err)
+	}
+}
+
+// testTransmitSparse transmits the specified file from the source root to the
+// destination root (using the destination file as the base, if present) and
+// returns the number of data bytes transmitted.
+func testTransmitSparse(t *testing.T, source, destination, path string) uint64 {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Compute the base signature.
+	engine := NewEngine()
+	signature := &Signature{}
+	if base, err := os.Open(filepath.Join(destination, path)); err == nil {
+		signature, err = engine.Signature(base, 0)
+		base.Close()
+		if err != nil {
+			t.Fatal("unable to compute base signature:", err)
+		}
+	}
+
+	// Create a receiver.
+	receiver, err := NewReceiver(destination, []string{path}, []*Signature{signature}, &testSparseSinker{destination})
+	if err != nil {
+		t.Fatal("unable to create receiver:", err)
+	}
+	counter := &testCountingReceiver{Receiver: receiver}
+
+	// Perform transmission.
+	if err := Transmit(source, []string{path}, []*Signature{signature}, counter, 0, nil, nil); err != nil {
+		t.Fatal("unable to transmit file:", err)
+	}
+
+	// Done.
+	return counter.data
+}
+
+// TestTransmitSparseFile tests that transmission of sparse files preserves
+// their content and sparseness without transmitting holes as data, both for
+// initial transmission and for transmission against a sparse base.
+func TestTransmitSparseFile(t *testing.T) {
+	// Create temporary source and destination directories and defer their
+	// removal.
+	source, err := ioutil.TempDir("", "mutagen_rsync_sparse")
+	if err != nil {
+		t.Fatal("unable to create temporary source directory:", err)
+	}
+	defer os.RemoveAll(source)
+	destination, err := ioutil.TempDir("", "mutagen_rsync_sparse")
+	if err != nil {
+		t.Fatal("unable to create temporary destination directory:", err)
+	}
+	defer os.RemoveAll(destination)
+
+	// Create a source file consisting of data surrounded by holes and verify
+	// that the filesystem supports sparse files.
+	sourcePath := filepath.Join(source, "sparse")
+	destinationPath := filepath.Join(destination, "sparse")
+	size := int64(2*testSparseHoleSize + 4096)
+	testWriteSparseFile(t, sourcePath, bytes.Repeat([]byte("data"), 1024), testSparseHoleSize, size)
+	if testAllocatedSize(t, sourcePath) >= testSparseHoleSize {
+		t.Skip("filesystem does not support sparse files")
+	}
+
+	// Perform two transmission rounds, modifying the source data in between.
+	for round := 0; round < 2; round++ {
+		if round > 0 {
+			testWriteSparseFile(t, sourcePath, []byte("modified"), testSparseHoleSize, size)
+		}
+
+		// Transmit the file and verify that holes weren't transmitted.
+		if transmitted := testTransmitSparse(t, source, destination, "sparse"); transmitted > 4096 {
+			t.Errorf("too much data transmitted in round %d: %d > 4096", round, transmitted)
+		}
+
+		// Verify that the content matches.
+		expected, err := ioutil.ReadFile(sourcePath)
+		if err != nil {
+			t.Fatal("unable to read source file:", err)
+		}
+		if received, err := ioutil.ReadFile(destinationPath); err != nil {
+			t.Fatalf("unable to read destination file in round %d: %v", round, err)
+		} else if !bytes.Equal(received, expected) {
+			t.Errorf("destination content does not match source in round %d", round)
+		}
+
+		// Verify that sparseness was preserved.
+		if allocated := testAllocatedSize(t, destinationPath); allocated >= testSparseHoleSize {
+			t.Errorf("sparseness not preserved in round %d: %d bytes allocated", round, allocated)
+		}
+	}
+}
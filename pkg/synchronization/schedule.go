@@ -0,0 +1,301 @@
+package synchronization
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// schedulePausedReason is the paused reason recorded for sessions that are
+	// paused because they're outside of their synchronization schedule.
+	schedulePausedReason = "outside of synchronization schedule"
+	// scheduleMaximumWait is the maximum amount of time that a schedule monitor
+	// will wait before re-evaluating the schedule. Periodic re-evaluation
+	// ensures that schedule boundaries are still honored if the system clock
+	// is adjusted or the system is suspended, since timers don't necessarily
+	// track wall clock time in those cases.
+	scheduleMaximumWait = time.Minute
+)
+
+// scheduleWeekdays maps weekday abbreviations to their corresponding weekdays.
+var scheduleWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// parseScheduleWeekday parses a weekday abbreviation.
+func parseScheduleWeekday(value string) (time.Weekday, error) {
+	if weekday, ok := scheduleWeekdays[strings.ToLower(value)]; ok {
+		return weekday, nil
+	}
+	return time.Sunday, errors.Errorf("unknown weekday: %s", value)
+}
+
+// parseScheduleTime parses a time of day in HH:MM format, returning it as hours
+// and minutes.
+func parseScheduleTime(value string) (int, int, error) {
+	var hours, minutes int
+	if n, err := fmt.Sscanf(value, "%d:%d", &hours, &minutes); err != nil || n != 2 {
+		return 0, 0, errors.Errorf("invalid time of day: %s", value)
+	} else if len(value) != 5 {
+		return 0, 0, errors.Errorf("time of day not in HH:MM format: %s", value)
+	} else if hours < 0 || hours > 23 || minutes < 0 || minutes > 59 {
+		return 0, 0, errors.Errorf("time of day out of range: %s", value)
+	}
+	return hours, minutes, nil
+}
+
+// scheduleWindow represents a single window within a synchronization schedule.
+type scheduleWindow struct {
+	// days indicates the weekdays on which the window starts, indexed by
+	// time.Weekday.
+	days [7]bool
+	// startHours is the hour component of the window start time.
+	startHours int
+	// startMinutes is the minute component of the window start time.
+	startMinutes int
+	// endHours is the hour component of the window end time.
+	endHours int
+	// endMinutes is the minute component of the window end time.
+	endMinutes int
+}
+
+// parseScheduleWindow parses a schedule window specification of the form
+// "[days] HH:MM-HH:MM".
+func parseScheduleWindow(specification string) (*scheduleWindow, error) {
+	// Split the specification into its components.
+	fields := strings.Fields(specification)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, errors.New("window must have the form \"[days] HH:MM-HH:MM\"")
+	}
+	result := &scheduleWindow{}
+
+	// Parse the days component, if any. If none is specified, then the window
+	// applies to every day.
+	if len(fields) == 2 {
+		for _, days := range strings.Split(fields[0], ",") {
+			if bounds := strings.Split(days, "-"); len(bounds) == 1 {
+				day, err := parseScheduleWeekday(bounds[0])
+				if err != nil {
+					return nil, err
+				}
+				result.days[day] = true
+			} else if len(bounds) == 2 {
+				first, err := parseScheduleWeekday(bounds[0])
+				if err != nil {
+					return nil, err
+				}
+				last, err := parseScheduleWeekday(bounds[1])
+				if err != nil {
+					return nil, err
+				}
+				for day := first; ; day = (day + 1) % 7 {
+					result.days[day] = true
+					if day == last {
+						break
+					}
+				}
+			} else {
+				return nil, errors.Errorf("invalid weekday range: %s", days)
+			}
+		}
+	} else {
+		for day := range result.days {
+			result.days[day] = true
+		}
+	}
+
+	// Parse the time range.
+	times := strings.Split(fields[len(fields)-1], "-")
+	if len(times) != 2 {
+		return nil, errors.Errorf("invalid time range: %s", fields[len(fields)-1])
+	}
+	var err error
+	if result.startHours, result.startMinutes, err = parseScheduleTime(times[0]); err != nil {
+		return nil, errors.Wrap(err, "invalid start time")
+	}
+	if result.endHours, result.endMinutes, err = parseScheduleTime(times[1]); err != nil {
+		return nil, errors.Wrap(err, "invalid end time")
+	}
+
+	// Success.
+	return result, nil
+}
+
+// bounds computes the start and end times of the window if it starts on the
+// specified day (which should be a midnight time in the schedule location). It
+// returns false if the window doesn't start on that day. Windows whose end time
+// isn't after their start time end on the following day.
+func (w *scheduleWindow) bounds(day time.Time) (time.Time, time.Time, bool) {
+	if !w.days[day.Weekday()] {
+		return time.Time{}, time.Time{}, false
+	}
+	year, month, date := day.Date()
+	location := day.Location()
+	start := time.Date(year, month, date, w.startHours, w.startMinutes, 0, 0, location)
+	end := time.Date(year, month, date, w.endHours, w.endMinutes, 0, 0, location)
+	if !end.After(start) {
+		end = time.Date(year, month, date+1, w.endHours, w.endMinutes, 0, 0, location)
+	}
+	return start, end, true
+}
+
+// schedule represents a synchronization schedule, i.e. a set of windows during
+// which a session should actively synchronize.
+type schedule struct {
+	// windows are the schedule windows.
+	windows []*scheduleWindow
+	// location is the location in which windows are evaluated.
+	location *time.Location
+}
+
+// newSchedule creates a new schedule from the specified window specifications
+// and time zone name. If no windows are specified, then it returns a nil
+// schedule, though the time zone name is still validated.
+func newSchedule(windows []string, timezone string) (*schedule, error) {
+	// Load the location.
+	location := time.Local
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			return nil, errors.Wrap(err, "unable to load schedule time zone")
+		}
+	}
+
+	// If there are no windows, then there's no schedule.
+	if len(windows) == 0 {
+		return nil, nil
+	}
+
+	// Parse windows.
+	result := &schedule{location: location}
+	for _, specification := range windows {
+		window, err := parseScheduleWindow(specification)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid schedule window (%s)", specification)
+		}
+		result.windows = append(result.windows, window)
+	}
+
+	// Success.
+	return result, nil
+}
+
+// windowBounds invokes the specified callback with the bounds of each window
+// starting between the day before and the week after the specified time.
+func (s *schedule) windowBounds(t time.Time, callback func(start, end time.Time)) {
+	t = t.In(s.location)
+	year, month, date := t.Date()
+	for offset := -1; offset <= 7; offset++ {
+		day := time.Date(year, month, date+offset, 0, 0, 0, 0, s.location)
+		for _, window := range s.windows {
+			if start, end, ok := window.bounds(day); ok {
+				callback(start, end)
+			}
+		}
+	}
+}
+
+// active indicates whether or not the specified time falls within a schedule
+// window.
+func (s *schedule) active(t time.Time) (result bool) {
+	s.windowBounds(t, func(start, end time.Time) {
+		if !t.Before(start) && t.Before(end) {
+			result = true
+		}
+	})
+	return
+}
+
+// nextBoundary computes the earliest time after the specified time at which the
+// schedule's active status changes. If the active status never changes (e.g.
+// because windows cover all times), then it returns a zero time.
+func (s *schedule) nextBoundary(t time.Time) time.Time {
+	// Compute the current active status.
+	active := s.active(t)
+
+	// Find the earliest window boundary after the specified time at which the
+	// active status differs. Window boundaries may fall within other windows,
+	// in which case they don't represent a change in status.
+	var result time.Time
+	s.windowBounds(t, func(start, end time.Time) {
+		for _, candidate := range []time.Time{start, end} {
+			if !candidate.After(t) || (!result.IsZero() && !candidate.Before(result)) {
+				continue
+			} else if s.active(candidate) != active {
+				result = candidate
+			}
+		}
+	})
+	return result
+}
+
+// scheduleClock provides time information to schedule monitors. It allows
+// tests to control the passage of time.
+type scheduleClock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel that will receive a value after the specified
+	// duration has elapsed.
+	After(time.Duration) <-chan time.Time
+}
+
+// systemScheduleClock is a scheduleClock backed by the system clock.
+type systemScheduleClock struct{}
+
+// Now implements scheduleClock.Now.
+func (systemScheduleClock) Now() time.Time {
+	return time.Now()
+}
+
+// After implements scheduleClock.After.
+func (systemScheduleClock) After(duration time.Duration) <-chan time.Time {
+	return time.After(duration)
+}
+
+// monitorSchedule monitors a schedule and invokes apply with the schedule's
+// active status, first once when monitoring starts (with initial set to true)
+// and then each time a schedule boundary is crossed (with initial set to
+// false). It runs until the provided context is cancelled.
+func monitorSchedule(ctx context.Context, s *schedule, clock scheduleClock, apply func(active, initial bool)) {
+	// Perform the initial application.
+	now := clock.Now()
+	apply(s.active(now), true)
+
+	// Loop until cancelled, waiting for and applying boundaries.
+	for {
+		// Compute the next boundary and the amount of time to wait. If there's
+		// no boundary, then we still wait periodically, since we can't rely on
+		// the clock having been monotonic.
+		boundary := s.nextBoundary(now)
+		wait := scheduleMaximumWait
+		if !boundary.IsZero() {
+			if untilBoundary := boundary.Sub(now); untilBoundary < wait {
+				wait = untilBoundary
+			}
+		}
+
+		// Wait for the boundary or cancellation.
+		select {
+		case <-clock.After(wait):
+		case <-ctx.Done():
+			return
+		}
+
+		// If we've crossed the boundary, then apply the schedule's new status.
+		// If not, we'll simply recompute the boundary.
+		now = clock.Now()
+		if !boundary.IsZero() && !now.Before(boundary) {
+			apply(s.active(now), false)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package synchronization
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestParseScheduleWindow tests parseScheduleWindow.
+func TestParseScheduleWindow(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		specification string
+		expectFailure bool
+		expectedDays  [7]bool
+	}{
+		{"", true, [7]bool{}},
+		{"08:00", true, [7]bool{}},
+		{"8:00-20:00", true, [7]bool{}},
+		{"08:00-24:00", true, [7]bool{}},
+		{"08:60-20:00", true, [7]bool{}},
+		{"mon-fri", true, [7]bool{}},
+		{"mon-fri 08:00-20:00 extra", true, [7]bool{}},
+		{"funday 08:00-20:00", true, [7]bool{}},
+		{"mon-tue-wed 08:00-20:00", true, [7]bool{}},
+		{"08:00-20:00", false, [7]bool{true, true, true, true, true, true, true}},
+		{"mon-fri 08:00-20:00", false, [7]bool{false, true, true, true, true, true, false}},
+		{"SAT,sun 10:00-14:00", false, [7]bool{true, false, false, false, false, false, true}},
+		{"fri-mon 22:00-06:00", false, [7]bool{true, true, false, false, false, true, true}},
+		{"mon,wed-thu 00:00-00:00", false, [7]bool{false, true, false, true, true, false, false}},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		window, err := parseScheduleWindow(testCase.specification)
+		if testCase.expectFailure {
+			if err == nil {
+				t.Error("parsing succeeded unexpectedly for specification:", testCase.specification)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("parsing failed for specification (%s): %v", testCase.specification, err)
+			continue
+		}
+		if window.days != testCase.expectedDays {
+			t.Errorf("days incorrect for specification (%s): %v != %v",
+				testCase.specification, window.days, testCase.expectedDays,
+			)
+		}
+	}
+}
+
+// TestNewScheduleInvalidTimezone tests that newSchedule rejects unknown time
+// zones, even if no windows are specified.
+func TestNewScheduleInvalidTimezone(t *testing.T) {
+	if _, err := newSchedule(nil, "Invalid/Timezone"); err == nil {
+		t.Error("schedule creation succeeded with invalid time zone")
+	}
+	if _, err := newSchedule([]string{"08:00-20:00"}, "Invalid/Timezone"); err == nil {
+		t.Error("schedule creation succeeded with invalid time zone")
+	}
+}
+
+// TestScheduleActiveAndNextBoundary tests schedule window evaluation and
+// boundary computation.
+func TestScheduleActiveAndNextBoundary(t *testing.T) {
+	// Create a schedule with a weekday window and an overnight weekend window.
+	s, err := newSchedule([]string{"mon-fri 08:00-20:00", "sat 22:00-02:00"}, "UTC")
+	if err != nil {
+		t.Fatal("unable to create schedule:", err)
+	}
+
+	// Set up test cases. January 6, 2020 was a Monday.
+	date := func(day, hour, minute int) time.Time {
+		return time.Date(2020, time.January, day, hour, minute, 0, 0, time.UTC)
+	}
+	testCases := []struct {
+		time     time.Time
+		active   bool
+		boundary time.Time
+	}{
+		{date(6, 7, 59), false, date(6, 8, 0)},
+		{date(6, 8, 0), true, date(6, 20, 0)},
+		{date(6, 19, 59), true, date(6, 20, 0)},
+		{date(6, 20, 0), false, date(7, 8, 0)},
+		{date(10, 20, 0), false, date(11, 22, 0)},
+		{date(11, 23, 0), true, date(12, 2, 0)},
+		{date(12, 1, 59), true, date(12, 2, 0)},
+		{date(12, 2, 0), false, date(13, 8, 0)},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if active := s.active(testCase.time); active != testCase.active {
+			t.Errorf("active status incorrect at %v: %t != %t", testCase.time, active, testCase.active)
+		}
+		if boundary := s.nextBoundary(testCase.time); !boundary.Equal(testCase.boundary) {
+			t.Errorf("next boundary incorrect at %v: %v != %v", testCase.time, boundary, testCase.boundary)
+		}
+	}
+}
+
+// TestScheduleTimezone tests that schedule windows are evaluated in the
+// schedule's time zone.
+func TestScheduleTimezone(t *testing.T) {
+	// Create a schedule in a fixed time zone that's offset from UTC.
+	s := &schedule{location: time.FixedZone("UTC+2", 2*60*60)}
+	window, err := parseScheduleWindow("08:00-20:00")
+	if err != nil {
+		t.Fatal("unable to parse window:", err)
+	}
+	s.windows = append(s.windows, window)
+
+	// Verify that the window is applied in the schedule's time zone.
+	if s.active(time.Date(2020, time.January, 6, 7, 0, 0, 0, time.UTC)) != true {
+		t.Error("schedule inactive at 09:00 local time")
+	}
+	if s.active(time.Date(2020, time.January, 6, 19, 0, 0, 0, time.UTC)) != false {
+		t.Error("schedule active at 21:00 local time")
+	}
+}
+
+// TestScheduleAlwaysActive tests that a schedule covering all times has no
+// boundaries.
+func TestScheduleAlwaysActive(t *testing.T) {
+	s, err := newSchedule([]string{"00:00-12:00", "12:00-00:00"}, "UTC")
+	if err != nil {
+		t.Fatal("unable to create schedule:", err)
+	}
+	now := time.Date(2020, time.January, 6, 12, 0, 0, 0, time.UTC)
+	if !s.active(now) {
+		t.Error("schedule inactive")
+	}
+	if boundary := s.nextBoundary(now); !boundary.IsZero() {
+		t.Error("schedule has unexpected boundary:", boundary)
+	}
+}
+
+// testScheduleClockWaiter is a pending wait on a testScheduleClock.
+type testScheduleClockWaiter struct {
+	// deadline is the time at which the wait completes.
+	deadline time.Time
+	// channel is the channel to signal when the wait completes.
+	channel chan time.Time
+}
+
+// testScheduleClock is a scheduleClock implementation whose time only changes
+// when explicitly set.
+type testScheduleClock struct {
+	// lock serializes access to the clock.
+	lock sync.Mutex
+	// now is the current time.
+	now time.Time
+	// waiters are the pending waits.
+	waiters []testScheduleClockWaiter
+}
+
+// Now implements scheduleClock.Now.
+func (c *testScheduleClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// After implements scheduleClock.After.
+func (c *testScheduleClock) After(duration time.Duration) <-chan time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	channel := make(chan time.Time, 1)
+	deadline := c.now.Add(duration)
+	if !deadline.After(c.now) {
+		channel <- c.now
+	} else {
+		c.waiters = append(c.waiters, testScheduleClockWaiter{deadline, channel})
+	}
+	return channel
+}
+
+// set sets the current time and completes any expired waits.
+func (c *testScheduleClock) set(now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = now
+	var pending []testScheduleClockWaiter
+	for _, waiter := range c.waiters {
+		if !waiter.deadline.After(now) {
+			waiter.channel <- now
+		} else {
+			pending = append(pending, waiter)
+		}
+	}
+	c.waiters = pending
+}
+
+// waitForWaiter waits until the clock has at least one pending wait.
+func (c *testScheduleClock) waitForWaiter(t *testing.T) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Poll for a pending wait.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		c.lock.Lock()
+		waiting := len(c.waiters) > 0
+		c.lock.Unlock()
+		if waiting {
+			return
+		} else if time.Now().After(deadline) {
+			t.Fatal("schedule monitor didn't wait")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// testScheduleTransition records a schedule application.
+type testScheduleTransition struct {
+	// active is the applied active status.
+	active bool
+	// initial indicates whether or not the application was the initial one.
+	initial bool
+}
+
+// TestMonitorSchedule tests that monitorSchedule applies schedule status
+// changes as a clock crosses window boundaries, and only then.
+func TestMonitorSchedule(t *testing.T) {
+	// Create a schedule.
+	s, err := newSchedule([]string{"mon-fri 08:00-20:00"}, "UTC")
+	if err != nil {
+		t.Fatal("unable to create schedule:", err)
+	}
+
+	// Start monitoring the schedule with a test clock, starting on a Monday
+	// (January 6, 2020) before the window opens. Defer termination of the
+	// monitor.
+	date := func(day, hour, minute int) time.Time {
+		return time.Date(2020, time.January, day, hour, minute, 0, 0, time.UTC)
+	}
+	clock := &testScheduleClock{now: date(6, 7, 0)}
+	transitions := make(chan testScheduleTransition, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	monitorDone := make(chan struct{})
+	go func() {
+		monitorSchedule(ctx, s, clock, func(active, initial bool) {
+			transitions <- testScheduleTransition{active, initial}
+		})
+		close(monitorDone)
+	}()
+	defer func() {
+		cancel()
+		<-monitorDone
+	}()
+
+	// Verify the initial application.
+	select {
+	case transition := <-transitions:
+		if transition.active || !transition.initial {
+			t.Fatal("initial schedule application incorrect:", transition)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("initial schedule application didn't occur")
+	}
+
+	// Set up clock changes and their expected applications. Changes that don't
+	// cross a boundary shouldn't result in an application.
+	steps := []struct {
+		time     time.Time
+		expected *testScheduleTransition
+	}{
+		{date(6, 7, 30), nil},
+		{date(6, 8, 30), &testScheduleTransition{active: true}},
+		{date(6, 19, 59), nil},
+		{date(6, 20, 0), &testScheduleTransition{active: false}},
+		{date(7, 9, 0), &testScheduleTransition{active: true}},
+		{date(10, 20, 30), &testScheduleTransition{active: false}},
+		{date(11, 12, 0), nil},
+		{date(12, 23, 59), nil},
+		{date(13, 8, 0), &testScheduleTransition{active: true}},
+	}
+
+	// Process clock changes.
+	for _, step := range steps {
+		clock.waitForWaiter(t)
+		clock.set(step.time)
+		if step.expected != nil {
+			select {
+			case transition := <-transitions:
+				if transition != *step.expected {
+					t.Fatalf("schedule application at %v incorrect: %v != %v", step.time, transition, *step.expected)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("schedule application didn't occur at", step.time)
+			}
+		} else {
+			clock.waitForWaiter(t)
+			select {
+			case transition := <-transitions:
+				t.Fatalf("unexpected schedule application at %v: %v", step.time, transition)
+			default:
+			}
+		}
+	}
+}
+
+// TestControllerApplySchedule tests that schedule application pauses sessions
+// with a recorded reason and that the initial application doesn't override
+// manual pauses.
+func TestControllerApplySchedule(t *testing.T) {
+	// Create a controller and wait for it to complete its initial cycle.
+	c, parent, _ := testShutdownController(t, nil)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Apply an inactive schedule and verify that the session is paused with
+	// the schedule as the reason.
+	ctx := context.Background()
+	c.applySchedule(ctx, false, false)
+	if s := c.currentState(); !s.Session.Paused {
+		t.Fatal("session not paused outside of schedule")
+	} else if s.Session.PausedReason != schedulePausedReason {
+		t.Error("paused reason incorrect:", s.Session.PausedReason)
+	} else if c.cancel != nil {
+		t.Error("synchronization loop still running")
+	}
+
+	// Manually pause the session and verify that the reason is cleared.
+	if err := c.halt(ctx, controllerHaltModePause, "", false); err != nil {
+		t.Fatal("unable to pause session:", err)
+	} else if reason := c.currentState().Session.PausedReason; reason != "" {
+		t.Error("paused reason not cleared by manual pause:", reason)
+	}
+
+	// Verify that an initial active application doesn't resume a manually
+	// paused session.
+	c.applySchedule(ctx, true, true)
+	if s := c.currentState(); !s.Session.Paused || c.cancel != nil {
+		t.Error("manually paused session resumed by initial schedule application")
+	}
+
+	// Verify that crossing into an inactive period records the schedule as the
+	// pause reason for a manually paused session.
+	c.applySchedule(ctx, false, false)
+	if reason := c.currentState().Session.PausedReason; reason != schedulePausedReason {
+		t.Error("paused reason incorrect:", reason)
+	}
+}
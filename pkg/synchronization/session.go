@@ -44,6 +44,24 @@ func (s *Session) EnsureValid() error {
 		return errors.New("beta URL is not a synchronization URL")
 	}
 
+	// Ensure that neither alpha nor beta uses a Docker label selector, since
+	// label selectors are only supported for fan-out synchronization.
+	if _, ok := s.Alpha.DockerLabelSelector(); ok {
+		return errors.New("alpha URL cannot use a label selector")
+	} else if _, ok := s.Beta.DockerLabelSelector(); ok {
+		return errors.New("beta URL cannot use a label selector")
+	}
+
+	// Ensure that any additional beta URLs are valid and are synchronization
+	// URLs.
+	for _, beta := range s.AdditionalBetas {
+		if err := beta.EnsureValid(); err != nil {
+			return errors.Wrap(err, "invalid additional beta URL")
+		} else if beta.Kind != url.Kind_Synchronization {
+			return errors.New("additional beta URL is not a synchronization URL")
+		}
+	}
+
 	// Ensure that the configuration is valid.
 	if err := s.Configuration.EnsureValid(false); err != nil {
 		return errors.Wrap(err, "invalid configuration")
@@ -59,6 +77,12 @@ func (s *Session) EnsureValid() error {
 		return errors.Wrap(err, "invalid beta-specific configuration")
 	}
 
+	// Ensure that fan-out synchronization is only used with a one-way
+	// synchronization mode.
+	if len(s.AdditionalBetas) > 0 && !s.Configuration.SynchronizationMode.IsUnidirectional() {
+		return errors.New("additional beta endpoints require a one-way synchronization mode")
+	}
+
 	// Validate the session name.
 	if err := selection.EnsureNameValid(s.Name); err != nil {
 		return errors.Wrap(err, "invalid session name")
@@ -75,6 +75,26 @@ type Session struct {
 	Labels map[string]string `protobuf:"bytes,13,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	// Paused indicates whether or not the session is marked as paused.
 	Paused bool `protobuf:"varint,10,opt,name=paused,proto3" json:"paused,omitempty"`
+	// AdditionalBetas are additional beta endpoint URLs for fan-out
+	// synchronization, in which alpha's contents are propagated to multiple
+	// beta endpoints. Each additional beta uses the beta-specific configuration
+	// overrides and maintains its own ancestor. Fan-out synchronization is only
+	// supported in one-way synchronization modes. They are static.
+	AdditionalBetas []*url.URL `protobuf:"bytes,15,rep,name=additionalBetas,proto3" json:"additionalBetas,omitempty"`
+	// PausedReason records why the session was paused if it was paused
+	// automatically (e.g. because it was outside of its synchronization
+	// schedule). It is empty for sessions that are unpaused or that were paused
+	// manually.
+	PausedReason string `protobuf:"bytes,16,opt,name=pausedReason,proto3" json:"pausedReason,omitempty"`
+	// Observing indicates whether or not the session is in observation mode,
+	// in which it scans and reconciles endpoint contents (reporting the
+	// changes that it would apply) without ever staging or transitioning.
+	Observing bool `protobuf:"varint,17,opt,name=observing,proto3" json:"observing,omitempty"`
+	// BaselinePending indicates that the session's ancestor has yet to be
+	// seeded from the contents of its endpoints due to baseline startup. It is
+	// cleared once a synchronization cycle verifies that the endpoint contents
+	// are equal and records them as the ancestor.
+	BaselinePending bool `protobuf:"varint,18,opt,name=baselinePending,proto3" json:"baselinePending,omitempty"`
 }
 
 func (x *Session) Reset() {
@@ -207,6 +227,34 @@ func (x *Session) GetPaused() bool {
 	return false
 }
 
+func (x *Session) GetAdditionalBetas() []*url.URL {
+	if x != nil {
+		return x.AdditionalBetas
+	}
+	return nil
+}
+
+func (x *Session) GetPausedReason() string {
+	if x != nil {
+		return x.PausedReason
+	}
+	return ""
+}
+
+func (x *Session) GetObserving() bool {
+	if x != nil {
+		return x.Observing
+	}
+	return false
+}
+
+func (x *Session) GetBaselinePending() bool {
+	if x != nil {
+		return x.BaselinePending
+	}
+	return false
+}
+
 var File_synchronization_session_proto protoreflect.FileDescriptor
 
 var file_synchronization_session_proto_rawDesc = []byte{
@@ -220,7 +268,7 @@ var file_synchronization_session_proto_rawDesc = []byte{
 	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1d, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e,
 	0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x2e,
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0d, 0x75, 0x72, 0x6c, 0x2f, 0x75, 0x72, 0x6c, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x22, 0x80, 0x06, 0x0a, 0x07, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa0, 0x07, 0x0a, 0x07, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
 	0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x01,
 	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72,
 	0x12, 0x32, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
@@ -264,7 +312,17 @@ var file_synchronization_session_proto_rawDesc = []byte{
 	0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
 	0x2e, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x6c, 0x61,
 	0x62, 0x65, 0x6c, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x18, 0x0a,
-	0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x1a, 0x39, 0x0a, 0x0b,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x12, 0x32, 0x0a, 0x0f,
+	0x61, 0x64, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x42, 0x65, 0x74, 0x61, 0x73, 0x18,
+	0x0f, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x75, 0x72, 0x6c, 0x2e, 0x55, 0x52, 0x4c, 0x52,
+	0x0f, 0x61, 0x64, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x42, 0x65, 0x74, 0x61, 0x73,
+	0x12, 0x22, 0x0a, 0x0c, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x52, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x69, 0x6e,
+	0x67, 0x18, 0x11, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x6e, 0x67, 0x12, 0x28, 0x0a, 0x0f, 0x62, 0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x50, 0x65,
+	0x6e, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x12, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x62, 0x61, 0x73,
+	0x65, 0x6c, 0x69, 0x6e, 0x65, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x1a, 0x39, 0x0a, 0x0b,
 	0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
 	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a,
 	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61,
@@ -305,11 +363,12 @@ var file_synchronization_session_proto_depIdxs = []int32{
 	5, // 5: synchronization.Session.configurationAlpha:type_name -> synchronization.Configuration
 	5, // 6: synchronization.Session.configurationBeta:type_name -> synchronization.Configuration
 	1, // 7: synchronization.Session.labels:type_name -> synchronization.Session.LabelsEntry
-	8, // [8:8] is the sub-list for method output_type
-	8, // [8:8] is the sub-list for method input_type
-	8, // [8:8] is the sub-list for extension type_name
-	8, // [8:8] is the sub-list for extension extendee
-	0, // [0:8] is the sub-list for field type_name
+	4, // 8: synchronization.Session.additionalBetas:type_name -> url.URL
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	9, // [9:9] is the sub-list for extension type_name
+	9, // [9:9] is the sub-list for extension extendee
+	0, // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_synchronization_session_proto_init() }
@@ -0,0 +1,90 @@
+package synchronization
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// entryAtPath returns the entry at the specified path within the specified
+// snapshot, if any.
+func entryAtPath(snapshot *core.Entry, path string) *core.Entry {
+	// Handle the special case of a root path.
+	if path == "" {
+		return snapshot
+	}
+
+	// Crawl down the tree until we reach the target location.
+	entry := snapshot
+	for _, component := range strings.Split(path, "/") {
+		if entry == nil {
+			return nil
+		}
+		entry = entry.Contents[component]
+	}
+	return entry
+}
+
+// entryExistsAtPath determines whether or not the specified snapshot contains
+// an entry at the specified path.
+func entryExistsAtPath(snapshot *core.Entry, path string) bool {
+	return entryAtPath(snapshot, path) != nil
+}
+
+// excludeSkippedFiles removes the content at the paths of the specified
+// skipped file problems from a snapshot. Files that are skipped on one endpoint
+// (due to exceeding the maximum file size) are excluded from the snapshots of
+// both endpoints so that reconciliation treats them in the same manner as
+// ignored content, i.e. so that their absence isn't treated as a deletion and
+// so that no content is propagated to or from them. The snapshot isn't
+// modified, but a new snapshot may be returned.
+func excludeSkippedFiles(snapshot *core.Entry, skipped ...[]*core.Problem) (*core.Entry, error) {
+	// Determine which skipped paths exist in the snapshot.
+	var paths []string
+	for _, problems := range skipped {
+		for _, problem := range problems {
+			if entryExistsAtPath(snapshot, problem.Path) {
+				paths = append(paths, problem.Path)
+			}
+		}
+	}
+
+	// If there's nothing to exclude, then we can return the snapshot as is.
+	if len(paths) == 0 {
+		return snapshot, nil
+	}
+
+	// Sort the paths in reverse order so that content is removed before any
+	// of its parents, then compute the corresponding removal changes. We don't
+	// need to worry about duplicate paths since removal is idempotent as long
+	// as the parent still exists.
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+	changes := make([]*core.Change, len(paths))
+	for p, path := range paths {
+		changes[p] = &core.Change{Path: path}
+	}
+
+	// Apply the changes.
+	result, err := core.Apply(snapshot, changes)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to remove skipped files")
+	}
+
+	// Success.
+	return result, nil
+}
+
+// withSkippedFileProblems prepends the specified skipped file problems to the
+// specified problem list. A new list is allocated if necessary so that neither
+// of the input lists is modified.
+func withSkippedFileProblems(skipped, problems []*core.Problem) []*core.Problem {
+	if len(skipped) == 0 {
+		return problems
+	}
+	result := make([]*core.Problem, 0, len(skipped)+len(problems))
+	result = append(result, skipped...)
+	return append(result, problems...)
+}
@@ -0,0 +1,123 @@
+package synchronization
+
+import (
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// testSkippedFilesSnapshot is the snapshot used for skipped file tests.
+var testSkippedFilesSnapshot = &core.Entry{
+	Contents: map[string]*core.Entry{
+		"small": {Kind: core.EntryKind_File, Digest: []byte{0}},
+		"large": {Kind: core.EntryKind_File, Digest: []byte{1}},
+		"sub": {
+			Contents: map[string]*core.Entry{
+				"large": {Kind: core.EntryKind_File, Digest: []byte{2}},
+				"small": {Kind: core.EntryKind_File, Digest: []byte{3}},
+			},
+		},
+	},
+}
+
+// TestEntryExistsAtPath tests entryExistsAtPath.
+func TestEntryExistsAtPath(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		snapshot *core.Entry
+		path     string
+		expected bool
+	}{
+		{nil, "", false},
+		{nil, "small", false},
+		{testSkippedFilesSnapshot, "", true},
+		{testSkippedFilesSnapshot, "small", true},
+		{testSkippedFilesSnapshot, "sub/large", true},
+		{testSkippedFilesSnapshot, "missing", false},
+		{testSkippedFilesSnapshot, "sub/missing", false},
+		{testSkippedFilesSnapshot, "small/child", false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if exists := entryExistsAtPath(testCase.snapshot, testCase.path); exists != testCase.expected {
+			t.Errorf("existence incorrect for path %q: %t != %t", testCase.path, exists, testCase.expected)
+		}
+	}
+}
+
+// TestExcludeSkippedFiles tests that excludeSkippedFiles removes skipped files
+// from snapshots without modifying the original snapshot.
+func TestExcludeSkippedFiles(t *testing.T) {
+	// Create skipped file problems for both endpoints, including one for a
+	// path that doesn't exist in the snapshot and one duplicated path.
+	αSkipped := []*core.Problem{
+		{Path: "large", Error: "file skipped"},
+		{Path: "sub/large", Error: "file skipped"},
+	}
+	βSkipped := []*core.Problem{
+		{Path: "sub/large", Error: "file skipped"},
+		{Path: "missing", Error: "file skipped"},
+	}
+
+	// Perform exclusion.
+	result, err := excludeSkippedFiles(testSkippedFilesSnapshot, αSkipped, βSkipped)
+	if err != nil {
+		t.Fatal("unable to exclude skipped files:", err)
+	}
+
+	// Verify that skipped files were removed and that others remain.
+	expected := &core.Entry{
+		Contents: map[string]*core.Entry{
+			"small": {Kind: core.EntryKind_File, Digest: []byte{0}},
+			"sub": {
+				Contents: map[string]*core.Entry{
+					"small": {Kind: core.EntryKind_File, Digest: []byte{3}},
+				},
+			},
+		},
+	}
+	if !result.Equal(expected) {
+		t.Error("excluded snapshot does not match expected")
+	}
+
+	// Verify that the original snapshot wasn't modified.
+	if !entryExistsAtPath(testSkippedFilesSnapshot, "large") || !entryExistsAtPath(testSkippedFilesSnapshot, "sub/large") {
+		t.Error("original snapshot modified by exclusion")
+	}
+
+	// Verify that exclusion without any applicable problems returns the
+	// original snapshot.
+	if result, err := excludeSkippedFiles(testSkippedFilesSnapshot, βSkipped[1:]); err != nil {
+		t.Error("unable to perform no-op exclusion:", err)
+	} else if result != testSkippedFilesSnapshot {
+		t.Error("no-op exclusion returned different snapshot")
+	}
+}
+
+// TestWithSkippedFileProblems tests that withSkippedFileProblems combines
+// problem lists without modifying its inputs.
+func TestWithSkippedFileProblems(t *testing.T) {
+	// Create problem lists with spare capacity to detect aliasing.
+	skipped := make([]*core.Problem, 1, 4)
+	skipped[0] = &core.Problem{Path: "large", Error: "file skipped"}
+	problems := []*core.Problem{{Path: "other", Error: "other problem"}}
+
+	// Verify that an empty skipped list returns the original problems.
+	if result := withSkippedFileProblems(nil, problems); len(result) != 1 || result[0] != problems[0] {
+		t.Error("problems modified when no files were skipped")
+	}
+
+	// Combine the lists and verify the result.
+	result := withSkippedFileProblems(skipped, problems)
+	if len(result) != 2 {
+		t.Fatal("combined problem count incorrect:", len(result), "!=", 2)
+	} else if result[0] != skipped[0] || result[1] != problems[0] {
+		t.Error("combined problems incorrect")
+	}
+
+	// Verify that the inputs weren't modified.
+	if len(skipped) != 1 || skipped[:2][1] != nil {
+		t.Error("skipped file problems modified")
+	}
+}
@@ -0,0 +1,95 @@
+package synchronization
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/identifier"
+	"github.com/mutagen-io/mutagen/pkg/prompting"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// diffSnapshots scans the session's alpha or beta endpoint and computes a
+// structured diff from the session's last synchronized state (its ancestor) to
+// the endpoint's current content, thus describing the changes made on the
+// endpoint since the last synchronization cycle. If detectMoves is true, then
+// removed and added files with identical digests are reported as moves. The
+// endpoint is scanned using a separate connection, so diffing doesn't interact
+// with the synchronization loop.
+func (c *controller) diffSnapshots(ctx context.Context, beta, detectMoves bool, prompter string) (*core.SnapshotDiff, error) {
+	// Update status.
+	prompting.Message(prompter, fmt.Sprintf("Diffing session %s...", c.session.Identifier))
+
+	// Grab the endpoint parameters. The endpoint URLs can change if the
+	// session is relocated, so we access them under the state lock.
+	c.stateLock.Lock()
+	description, url, configuration := "alpha", c.session.Alpha, c.mergedAlphaConfiguration
+	if beta {
+		description, url, configuration = "beta", c.session.Beta, c.mergedBetaConfiguration
+	}
+	version := c.session.Version
+	c.stateLock.UnlockWithoutNotify()
+
+	// Verify that the endpoint doesn't use the tunnel protocol, since we can't
+	// wait for asynchronous connectivity.
+	if url.Protocol == urlpkg.Protocol_Tunnel {
+		return nil, errors.New("diffing not supported for tunnel endpoints")
+	}
+
+	// Load the ancestor. If the session hasn't yet completed a
+	// synchronization cycle, then there won't be an archive, in which case we
+	// diff against an empty ancestor.
+	var ancestor *core.Entry
+	if archive, err := loadArchive(c.logger, c.archivePath); err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "unable to load archive")
+	} else if err == nil {
+		ancestor = archive.Root
+	}
+
+	// Create a unique identifier to use in lieu of the session identifier, so
+	// that the diffing endpoint doesn't share state with the session's
+	// endpoint.
+	diffIdentifier, err := identifier.New(identifier.PrefixSynchronization)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate identifier for diffing")
+	}
+
+	// Connect to the endpoint and defer its shutdown.
+	prompting.Message(prompter, "Connecting to "+description+"...")
+	endpoint, err := connect(
+		ctx,
+		c.logger.Sublogger("diff"),
+		url,
+		prompter,
+		diffIdentifier,
+		version,
+		configuration,
+		!beta,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to connect to %s", description)
+	}
+	defer endpoint.Shutdown()
+
+	// Perform a full scan. We don't provide the ancestor as a baseline since
+	// we want the endpoint's content to be determined independently.
+	prompting.Message(prompter, "Scanning "+description+"...")
+	snapshot, preservesExecutability, _, err, _ := endpoint.Scan(ctx, nil, true, false, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to scan %s", description)
+	}
+
+	// If the endpoint doesn't preserve executability, then propagate
+	// executability bits from the ancestor, so that the lack of executability
+	// information alone isn't reported as a modification.
+	if !preservesExecutability {
+		snapshot = core.PropagateExecutability(nil, ancestor, snapshot)
+	}
+
+	// Compute the diff.
+	return core.DiffSnapshots(ancestor, snapshot, detectMoves), nil
+}
@@ -0,0 +1,42 @@
+package synchronization
+
+import (
+	"fmt"
+)
+
+// insufficientSpaceError indicates that a synchronization cycle was aborted
+// because the files that it would have staged on an endpoint wouldn't fit
+// within the space available for staging on that endpoint (e.g. due to a disk
+// quota).
+type insufficientSpaceError struct {
+	// endpoint is the name of the endpoint on which files would have been
+	// staged.
+	endpoint string
+	// required is the number of bytes that staging would have required.
+	required uint64
+	// available is the number of bytes available for staging.
+	available uint64
+}
+
+// Error implements error.Error.
+func (e *insufficientSpaceError) Error() string {
+	return fmt.Sprintf("insufficient space for staging on %s (%d bytes required, %d bytes available)",
+		e.endpoint, e.required, e.available,
+	)
+}
+
+// pausedReason implements pausingError.pausedReason.
+func (e *insufficientSpaceError) pausedReason() string {
+	return fmt.Sprintf("insufficient space or quota on %s (%d bytes required, %d bytes available), resume after freeing space",
+		e.endpoint, e.required, e.available,
+	)
+}
+
+// checkAvailableSpace verifies that the specified number of bytes required for
+// staging on an endpoint fit within the space available on that endpoint.
+func checkAvailableSpace(endpoint string, required, available uint64) error {
+	if required > available {
+		return &insufficientSpaceError{endpoint: endpoint, required: required, available: available}
+	}
+	return nil
+}
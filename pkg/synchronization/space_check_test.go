@@ -0,0 +1,133 @@
+package synchronization
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// TestCheckAvailableSpace tests checkAvailableSpace.
+func TestCheckAvailableSpace(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		required      uint64
+		available     uint64
+		expectFailure bool
+	}{
+		{0, 0, false},
+		{100, 100, false},
+		{100, 1000, false},
+		{101, 100, true},
+		{1, 0, true},
+	}
+
+	// Process test cases.
+	for i, testCase := range testCases {
+		err := checkAvailableSpace("beta", testCase.required, testCase.available)
+		if testCase.expectFailure && err == nil {
+			t.Errorf("test case %d: space check succeeded unexpectedly", i)
+		} else if !testCase.expectFailure && err != nil {
+			t.Errorf("test case %d: space check failed unexpectedly: %v", i, err)
+		} else if err != nil {
+			if _, ok := err.(pausingError); !ok {
+				t.Errorf("test case %d: space check error doesn't require pausing", i)
+			}
+		}
+	}
+}
+
+// TestControllerAvailableSpacePause tests that a session is paused before any
+// files are transferred if they wouldn't fit within the space available on the
+// staging endpoint, and that it completes synchronization once resumed with
+// sufficient space available.
+func TestControllerAvailableSpacePause(t *testing.T) {
+	// Create content that exceeds the initially available space.
+	const fileSize = 100
+	content := make(map[string][]byte)
+	for i := 0; i < 3; i++ {
+		content[fmt.Sprintf("file%d", i)] = bytes.Repeat([]byte{byte('a' + i)}, fileSize)
+	}
+	var available uint64 = 2 * fileSize
+
+	// Create a controller that checks available space and stages content on
+	// beta via supplying from alpha, stubbing beta's space query.
+	configuration := &Configuration{CheckAvailableSpace: true}
+	c, parent, alpha, beta := testControllerWithSetup(t, configuration, content, nil, nil,
+		func(_, beta *testDirectoryEndpoint) {
+			beta.supplyStaging = true
+			beta.availableSpace = func() uint64 {
+				return atomic.LoadUint64(&available)
+			}
+		},
+	)
+	defer os.RemoveAll(parent)
+
+	// Wait for the session to be paused.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		c.stateLock.Lock()
+		paused := c.session.Paused
+		pausedReason := c.session.PausedReason
+		c.stateLock.UnlockWithoutNotify()
+		if paused && pausedReason != "" {
+			if !strings.Contains(pausedReason, "insufficient space") || !strings.Contains(pausedReason, "beta") {
+				t.Error("unexpected paused reason:", pausedReason)
+			}
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("session not paused")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Verify that no files were transferred.
+	if len(alpha.supplied) != 0 {
+		t.Error("files supplied despite insufficient space")
+	}
+	if names, err := ioutil.ReadDir(beta.root); err != nil {
+		t.Fatal("unable to read beta contents:", err)
+	} else if len(names) != 0 {
+		t.Error("content transitioned despite insufficient space")
+	}
+
+	// Register a protocol handler for local URLs that yields the controller's
+	// endpoints so that the session can be resumed.
+	originalHandler, originalHandlerRegistered := ProtocolHandlers[urlpkg.Protocol_Local]
+	ProtocolHandlers[urlpkg.Protocol_Local] = &testDeletionProtocolHandler{alpha: alpha, beta: beta}
+	defer func() {
+		if originalHandlerRegistered {
+			ProtocolHandlers[urlpkg.Protocol_Local] = originalHandler
+		} else {
+			delete(ProtocolHandlers, urlpkg.Protocol_Local)
+		}
+	}()
+	c.session.Alpha = &urlpkg.URL{Kind: urlpkg.Kind_Synchronization, Protocol: urlpkg.Protocol_Local, Path: alpha.root}
+	c.session.Beta = &urlpkg.URL{Kind: urlpkg.Kind_Synchronization, Protocol: urlpkg.Protocol_Local, Path: beta.root}
+
+	// Make exactly enough space available, resume the session, and verify
+	// that synchronization completes.
+	atomic.StoreUint64(&available, 3*fileSize)
+	if err := c.resume(context.Background(), "", false); err != nil {
+		t.Fatal("unable to resume session:", err)
+	}
+	waitForSynchronizationCycles(t, c, 1)
+	if err := c.halt(context.Background(), controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+	for name, data := range content {
+		if synchronized, err := ioutil.ReadFile(filepath.Join(beta.root, name)); err != nil {
+			t.Errorf("unable to read synchronized file (%s): %v", name, err)
+		} else if !bytes.Equal(synchronized, data) {
+			t.Errorf("synchronized file (%s) content incorrect", name)
+		}
+	}
+}
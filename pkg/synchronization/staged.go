@@ -0,0 +1,123 @@
+package synchronization
+
+import (
+	"github.com/pkg/errors"
+
+	urlpkg "github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// StagedContentLister is an optional interface that protocol handlers can
+// implement to list the content staged by an endpoint without connecting to
+// it. Implementations must be read-only and safe to invoke while the endpoint
+// is staging or transitioning.
+type StagedContentLister interface {
+	// ListStaged lists the content staged by the endpoint at the specified URL
+	// for the specified session. If verify is true, then the content of each
+	// staged file is compared against its recorded digest.
+	ListStaged(
+		url *urlpkg.URL,
+		session string,
+		version Version,
+		configuration *Configuration,
+		alpha bool,
+		verify bool,
+	) ([]*StagedEntry, error)
+}
+
+// EnsureValid ensures that StagedEntry's invariants are respected.
+func (e *StagedEntry) EnsureValid() error {
+	// A nil staged entry is not valid.
+	if e == nil {
+		return errors.New("nil staged entry")
+	}
+
+	// Ensure that the digest is non-empty.
+	if len(e.Digest) == 0 {
+		return errors.New("empty digest")
+	}
+
+	// Ensure that the staging time is present.
+	if e.StagedAt == nil {
+		return errors.New("missing staging time")
+	}
+
+	// Success.
+	return nil
+}
+
+// EnsureValid ensures that StagedContent's invariants are respected.
+func (c *StagedContent) EnsureValid() error {
+	// A nil staged content listing is not valid.
+	if c == nil {
+		return errors.New("nil staged content")
+	}
+
+	// Ensure that entries are only present if content was listed.
+	if !c.Listed && len(c.Entries) > 0 {
+		return errors.New("staged entries present for unlisted content")
+	}
+
+	// Ensure that all entries are valid.
+	for _, entry := range c.Entries {
+		if err := entry.EnsureValid(); err != nil {
+			return errors.Wrap(err, "invalid staged entry")
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// listEndpointStaged lists the content staged by the endpoint at the specified
+// URL using the endpoint's protocol handler, if the handler supports listing.
+func listEndpointStaged(
+	url *urlpkg.URL,
+	session string,
+	version Version,
+	configuration *Configuration,
+	alpha bool,
+	verify bool,
+) (*StagedContent, error) {
+	// Determine whether or not the protocol handler supports listing.
+	lister, ok := ProtocolHandlers[url.Protocol].(StagedContentLister)
+	if !ok {
+		return &StagedContent{}, nil
+	}
+
+	// Perform listing.
+	entries, err := lister.ListStaged(url, session, version, configuration, alpha, verify)
+	if err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return &StagedContent{Listed: true, Entries: entries}, nil
+}
+
+// listStaged lists the content staged by the session's alpha and beta
+// endpoints. It doesn't interact with the synchronization loop and is thus
+// safe to invoke while a synchronization cycle is underway.
+func (c *controller) listStaged(verify bool) (*StagedContent, *StagedContent, error) {
+	// Grab the session parameters. The endpoint URLs can change if the session
+	// is relocated, so we access them under the state lock.
+	c.stateLock.Lock()
+	session := c.session.Identifier
+	version := c.session.Version
+	alphaURL, betaURL := c.session.Alpha, c.session.Beta
+	c.stateLock.UnlockWithoutNotify()
+
+	// List alpha's staged content.
+	alpha, err := listEndpointStaged(alphaURL, session, version, c.mergedAlphaConfiguration, true, verify)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to list alpha staged content")
+	}
+
+	// List beta's staged content.
+	beta, err := listEndpointStaged(betaURL, session, version, c.mergedBetaConfiguration, false, verify)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to list beta staged content")
+	}
+
+	// Success.
+	return alpha, beta, nil
+}
@@ -0,0 +1,182 @@
+package synchronization
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+)
+
+const (
+	// sessionGoroutineLabel is the profiler label used to associate Goroutines
+	// with the session that they serve. Goroutines inherit labels from the
+	// Goroutine that creates them, so setting this label on a controller's run
+	// loop Goroutine will label all of the session's workers.
+	sessionGoroutineLabel = "session"
+	// maximumStallGoroutineDumpSize is the maximum size of the Goroutine dump
+	// recorded in stall reports.
+	maximumStallGoroutineDumpSize = 64 * 1024
+)
+
+// sessionGoroutineDump returns a dump of the Goroutines labeled as serving the
+// specified session. Goroutines with identical stacks are aggregated. The dump
+// is truncated if it exceeds maximumStallGoroutineDumpSize.
+func sessionGoroutineDump(session string) string {
+	// Capture an aggregated dump of all Goroutines in a textual format that
+	// includes labels.
+	buffer := &bytes.Buffer{}
+	if err := pprof.Lookup("goroutine").WriteTo(buffer, 1); err != nil {
+		return fmt.Sprintf("unable to capture Goroutine dump: %v", err)
+	}
+
+	// Filter the dump to those records labeled with the session. Records are
+	// separated by blank lines and the first record is a header.
+	label := fmt.Sprintf("%q:%q", sessionGoroutineLabel, session)
+	var records []string
+	for _, record := range strings.Split(buffer.String(), "\n\n") {
+		for _, line := range strings.Split(record, "\n") {
+			if strings.HasPrefix(line, "# labels:") && strings.Contains(line, label) {
+				records = append(records, strings.TrimSpace(record))
+				break
+			}
+		}
+	}
+	result := strings.Join(records, "\n\n")
+
+	// Truncate the dump if necessary.
+	if len(result) > maximumStallGoroutineDumpSize {
+		result = result[:maximumStallGoroutineDumpSize] + "\n...truncated..."
+	}
+
+	// Done.
+	return result
+}
+
+// stallWatchdog monitors a synchronization stage for progress and invokes a
+// callback if the stage goes without progress for longer than a timeout. The
+// callback is invoked at most once. A nil stall watchdog is valid and performs
+// no monitoring.
+type stallWatchdog struct {
+	// progress is used to signal progress to the monitoring Goroutine.
+	progress chan struct{}
+	// done is closed to signal the monitoring Goroutine to terminate.
+	done chan struct{}
+	// finished is closed when the monitoring Goroutine has terminated.
+	finished chan struct{}
+	// stallLock serializes access to stalled.
+	stallLock sync.Mutex
+	// stalled indicates whether or not a stall was detected.
+	stalled bool
+}
+
+// newStallWatchdog creates a new stall watchdog with the specified timeout and
+// stall callback, which will be invoked (in a separate Goroutine) with the
+// amount of time since the last progress. If timeout is 0, then it returns nil.
+func newStallWatchdog(timeout time.Duration, onStall func(time.Duration)) *stallWatchdog {
+	// If stall detection is disabled, then there's nothing to monitor.
+	if timeout == 0 {
+		return nil
+	}
+
+	// Create the watchdog.
+	watchdog := &stallWatchdog{
+		progress: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+
+	// Start monitoring.
+	go watchdog.monitor(timeout, onStall)
+
+	// Done.
+	return watchdog
+}
+
+// monitor is the monitoring loop for the watchdog.
+func (w *stallWatchdog) monitor(timeout time.Duration, onStall func(time.Duration)) {
+	// Signal termination when done.
+	defer close(w.finished)
+
+	// Track the last progress time.
+	lastProgress := time.Now()
+
+	// Loop until stopped or until a stall is detected. Progress signals are
+	// infrequent, so we don't bother reusing timers.
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.progress:
+			lastProgress = time.Now()
+		case <-time.After(time.Until(lastProgress.Add(timeout))):
+			w.stallLock.Lock()
+			w.stalled = true
+			w.stallLock.Unlock()
+			onStall(time.Since(lastProgress))
+			return
+		}
+	}
+}
+
+// Progress records progress for the monitored stage.
+func (w *stallWatchdog) Progress() {
+	if w == nil {
+		return
+	}
+	select {
+	case w.progress <- struct{}{}:
+	default:
+	}
+}
+
+// Stop terminates monitoring and indicates whether or not a stall was detected.
+// It waits for any in-progress stall callback to complete.
+func (w *stallWatchdog) Stop() bool {
+	if w == nil {
+		return false
+	}
+	close(w.done)
+	<-w.finished
+	w.stallLock.Lock()
+	defer w.stallLock.Unlock()
+	return w.stalled
+}
+
+// watchStage creates a stall watchdog for the synchronization stage with the
+// specified status, based on the session's stall detection configuration. If a
+// stall is detected, then a stall report (including a dump of the session's
+// Goroutines) is recorded in the session state and, if the session is
+// configured to abort on stalls, the stage is cancelled using cancel. Note that
+// cancellation will only preempt the stage if the stalled operation respects
+// cancellation. If stall detection is disabled, then it returns nil.
+func (c *controller) watchStage(status Status, cancel context.CancelFunc) *stallWatchdog {
+	timeout := time.Duration(c.session.Configuration.StallTimeout) * time.Second
+	abort := c.session.Configuration.AbortOnStall
+	return newStallWatchdog(timeout, func(elapsed time.Duration) {
+		// Create the report.
+		report := &StallReport{
+			Status:            status,
+			TimeSinceProgress: ptypes.DurationProto(elapsed),
+			Goroutines:        sessionGoroutineDump(c.session.Identifier),
+		}
+
+		// Log the stall.
+		c.logger.Warningf("%s stalled (no progress for %s)", status.Description(), elapsed.Round(time.Second))
+		c.logger.Debug("Session Goroutines:\n" + report.Goroutines)
+
+		// Record the report.
+		c.stateLock.Lock()
+		c.state.StallReport = report
+		c.stateLock.Unlock()
+
+		// Abort the stage if requested.
+		if abort {
+			cancel()
+		}
+	})
+}
@@ -0,0 +1,197 @@
+package synchronization
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/state"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+func TestStallWatchdogDisabled(t *testing.T) {
+	// Verify that a zero timeout disables monitoring and that the resulting
+	// watchdog is still usable.
+	watchdog := newStallWatchdog(0, func(time.Duration) {
+		t.Error("stall detected with monitoring disabled")
+	})
+	if watchdog != nil {
+		t.Fatal("watchdog created with monitoring disabled")
+	}
+	watchdog.Progress()
+	if watchdog.Stop() {
+		t.Error("disabled watchdog reported stall")
+	}
+}
+
+func TestStallWatchdogDetectsStall(t *testing.T) {
+	// Create a watchdog that records stalls.
+	stalls := make(chan time.Duration, 1)
+	watchdog := newStallWatchdog(50*time.Millisecond, func(elapsed time.Duration) {
+		stalls <- elapsed
+	})
+
+	// Wait for the stall to be detected.
+	select {
+	case elapsed := <-stalls:
+		if elapsed < 50*time.Millisecond {
+			t.Error("stall reported before timeout:", elapsed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stall not detected")
+	}
+
+	// Verify that the stall is reported when stopping.
+	if !watchdog.Stop() {
+		t.Error("watchdog did not report stall")
+	}
+}
+
+func TestStallWatchdogProgress(t *testing.T) {
+	// Create a watchdog that fails on stalls.
+	watchdog := newStallWatchdog(250*time.Millisecond, func(time.Duration) {
+		t.Error("stall detected despite progress")
+	})
+
+	// Record progress for a period well in excess of the timeout.
+	for i := 0; i < 20; i++ {
+		time.Sleep(25 * time.Millisecond)
+		watchdog.Progress()
+	}
+
+	// Verify that no stall is reported.
+	if watchdog.Stop() {
+		t.Error("watchdog reported stall despite progress")
+	}
+}
+
+// testStalledFunction blocks until the specified channel is closed. It exists
+// to provide an identifiable function in Goroutine dumps.
+func testStalledFunction(release chan struct{}) {
+	<-release
+}
+
+func TestSessionGoroutineDump(t *testing.T) {
+	// Start a Goroutine labeled with a session identifier that blocks in an
+	// identifiable function, and defer its release.
+	release := make(chan struct{})
+	defer close(release)
+	pprof.Do(context.Background(), pprof.Labels(sessionGoroutineLabel, "stalled"), func(context.Context) {
+		go testStalledFunction(release)
+	})
+
+	// Verify that the Goroutine appears in the dump for its session, but not
+	// in the dump for another session. The Goroutine may take a moment to
+	// reach its blocking point.
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(sessionGoroutineDump("stalled"), "testStalledFunction") {
+		if time.Now().After(deadline) {
+			t.Fatal("stalled Goroutine not present in session dump")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if strings.Contains(sessionGoroutineDump("other"), "testStalledFunction") {
+		t.Error("stalled Goroutine present in dump for other session")
+	}
+}
+
+// testStallingScanEndpoint is a testDirectoryEndpoint whose scans block until
+// cancelled.
+type testStallingScanEndpoint struct {
+	*testDirectoryEndpoint
+}
+
+// Scan implements Endpoint.Scan.
+func (e *testStallingScanEndpoint) Scan(ctx context.Context, _ *core.Entry, _, _ bool, _ []string) (*core.Entry, bool, []*core.Problem, error, bool) {
+	<-ctx.Done()
+	return nil, false, nil, errors.New("scan cancelled"), false
+}
+
+// TestControllerStallDetection tests that a stalled scan is detected, reported
+// with a dump of the session's Goroutines, and aborted.
+func TestControllerStallDetection(t *testing.T) {
+	// Create a temporary directory to hold all test content and defer its
+	// removal.
+	parent, err := ioutil.TempDir("", "mutagen_stall")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(parent)
+
+	// Create endpoint directories.
+	alphaRoot := filepath.Join(parent, "alpha")
+	betaRoot := filepath.Join(parent, "beta")
+	staging := filepath.Join(parent, "staging")
+	for _, directory := range []string{alphaRoot, betaRoot, staging} {
+		if err := os.Mkdir(directory, 0700); err != nil {
+			t.Fatal("unable to create directory:", err)
+		}
+	}
+
+	// Create an empty archive.
+	archivePath := filepath.Join(parent, "archive")
+	if err := encoding.MarshalAndSaveProtobuf(archivePath, &core.Archive{}); err != nil {
+		t.Fatal("unable to save archive:", err)
+	}
+
+	// Create the controller with stall detection and abortion enabled.
+	session := &Session{
+		Identifier: "stalling",
+		Version:    Version_Version1,
+		Configuration: &Configuration{
+			StallTimeout: 1,
+			AbortOnStall: true,
+		},
+		ConfigurationAlpha: &Configuration{},
+		ConfigurationBeta:  &Configuration{},
+	}
+	c := &controller{
+		logger:                   logging.RootLogger.Sublogger("test"),
+		sessionPath:              filepath.Join(parent, "session"),
+		archivePath:              archivePath,
+		stateLock:                state.NewTrackingLock(state.NewTracker()),
+		session:                  session,
+		mergedAlphaConfiguration: &Configuration{},
+		mergedBetaConfiguration:  &Configuration{},
+		state: &State{
+			Session: session,
+		},
+		flushRequests: make(chan *controllerFlushRequest, 1),
+	}
+
+	// Create endpoints, with alpha's scan stalling.
+	alpha := &testStallingScanEndpoint{
+		&testDirectoryEndpoint{root: alphaRoot, source: betaRoot, staging: staging},
+	}
+	beta := &testDirectoryEndpoint{root: betaRoot, source: alphaRoot, staging: staging}
+
+	// Perform synchronization in a Goroutine labeled with the session
+	// identifier (as the run loop would be) and verify that it's aborted.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pprof.Do(ctx, pprof.Labels(sessionGoroutineLabel, session.Identifier), func(ctx context.Context) {
+		err = c.synchronize(ctx, ctx, alpha, beta, nil, nil)
+	})
+	if err == nil || !strings.Contains(err.Error(), "stalled") {
+		t.Fatal("synchronization not aborted due to stall:", err)
+	}
+
+	// Verify that the stall was reported.
+	report := c.currentState().StallReport
+	if report == nil {
+		t.Fatal("stall not reported")
+	} else if report.Status != Status_Scanning {
+		t.Error("stall reported for incorrect stage:", report.Status)
+	} else if !strings.Contains(report.Goroutines, "testStallingScanEndpoint") {
+		t.Error("stall report Goroutine dump lacks stalled scan")
+	}
+}
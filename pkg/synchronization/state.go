@@ -2,6 +2,10 @@ package synchronization
 
 import (
 	"github.com/pkg/errors"
+
+	"github.com/golang/protobuf/ptypes"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
 )
 
 // Description returns a human-readable description of the session status.
@@ -83,6 +87,78 @@ func (s *State) EnsureValid() error {
 		}
 	}
 
+	// Ensure that the additional beta states are valid.
+	for _, b := range s.AdditionalBetas {
+		if b == nil {
+			return errors.New("nil additional beta state")
+		}
+		for _, p := range b.Problems {
+			if err := p.EnsureValid(); err != nil {
+				return errors.Wrap(err, "invalid additional beta problem detected")
+			}
+		}
+		if b.TruncatedProblems > 0 && len(b.Problems) == 0 {
+			return errors.New("truncated additional beta problems reported with no problems reported")
+		}
+	}
+
+	// Ensure that all observed changes are valid.
+	for _, c := range s.ObservedAlphaChanges {
+		if err := c.EnsureValid(); err != nil {
+			return errors.Wrap(err, "invalid observed alpha change detected")
+		}
+	}
+	for _, c := range s.ObservedBetaChanges {
+		if err := c.EnsureValid(); err != nil {
+			return errors.Wrap(err, "invalid observed beta change detected")
+		}
+	}
+
+	// Ensure that all reconciliation decisions are valid.
+	for _, d := range s.ReconciliationDecisions {
+		if err := d.EnsureValid(); err != nil {
+			return errors.Wrap(err, "invalid reconciliation decision detected")
+		}
+	}
+
+	// Ensure that all transfer efficiencies are valid.
+	for _, e := range s.TransferEfficiencies {
+		if err := e.EnsureValid(); err != nil {
+			return errors.Wrap(err, "invalid transfer efficiency detected")
+		}
+	}
+
+	// Ensure that the stall report is valid, if present.
+	if s.StallReport != nil {
+		if _, err := ptypes.Duration(s.StallReport.TimeSinceProgress); err != nil {
+			return errors.Wrap(err, "invalid stall report duration")
+		}
+	}
+
+	// Ensure that the session timings are valid.
+	if err := s.Timings.EnsureValid(); err != nil {
+		return errors.Wrap(err, "invalid timings")
+	}
+
+	// Ensure that clock skews are valid, if present.
+	if s.AlphaClockSkew != nil {
+		if _, err := ptypes.Duration(s.AlphaClockSkew); err != nil {
+			return errors.Wrap(err, "invalid alpha clock skew")
+		}
+	}
+	if s.BetaClockSkew != nil {
+		if _, err := ptypes.Duration(s.BetaClockSkew); err != nil {
+			return errors.Wrap(err, "invalid beta clock skew")
+		}
+	}
+
+	// Ensure that Merkle roots, if present, have the expected length.
+	if n := len(s.AlphaMerkleRoot); n != 0 && n != core.MerkleRootLength {
+		return errors.New("invalid alpha Merkle root length")
+	} else if n = len(s.BetaMerkleRoot); n != 0 && n != core.MerkleRootLength {
+		return errors.New("invalid beta Merkle root length")
+	}
+
 	// Ensure that conflict and problem truncations have only occurred in cases
 	// where the corresponding list(s) are non-empty.
 	if s.TruncatedConflicts > 0 && len(s.Conflicts) == 0 {
@@ -91,6 +167,14 @@ func (s *State) EnsureValid() error {
 		return errors.New("truncated alpha problems reported with no alpha problems reported")
 	} else if s.TruncatedBetaProblems > 0 && len(s.BetaProblems) == 0 {
 		return errors.New("truncated beta problems reported with no beta problems reported")
+	} else if s.TruncatedReconciliationDecisions > 0 && len(s.ReconciliationDecisions) == 0 {
+		return errors.New("truncated reconciliation decisions reported with no reconciliation decisions reported")
+	} else if s.TruncatedObservedAlphaChanges > 0 && len(s.ObservedAlphaChanges) == 0 {
+		return errors.New("truncated observed alpha changes reported with no observed alpha changes reported")
+	} else if s.TruncatedObservedBetaChanges > 0 && len(s.ObservedBetaChanges) == 0 {
+		return errors.New("truncated observed beta changes reported with no observed beta changes reported")
+	} else if s.TruncatedTransferEfficiencies > 0 && len(s.TransferEfficiencies) == 0 {
+		return errors.New("truncated transfer efficiencies reported with no transfer efficiencies reported")
 	}
 
 	// Success.
@@ -110,6 +194,14 @@ func (s *State) Copy() *State {
 		*result.Session = *s.Session
 	}
 
+	// Create a shallow copy of the additional beta states, if present. The
+	// individual states are replaced (rather than modified) when updated, so
+	// they are considered to be immutable.
+	if s.AdditionalBetas != nil {
+		result.AdditionalBetas = make([]*AdditionalBetaState, len(s.AdditionalBetas))
+		copy(result.AdditionalBetas, s.AdditionalBetas)
+	}
+
 	// All other composite members are either immutable values or considered to
 	// be immutable, so we don't need to copy them.
 
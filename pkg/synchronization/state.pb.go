@@ -8,6 +8,7 @@ package synchronization
 
 import (
 	proto "github.com/golang/protobuf/proto"
+	duration "github.com/golang/protobuf/ptypes/duration"
 	core "github.com/mutagen-io/mutagen/pkg/synchronization/core"
 	rsync "github.com/mutagen-io/mutagen/pkg/synchronization/rsync"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
@@ -109,30 +110,340 @@ func (Status) EnumDescriptor() ([]byte, []int) {
 	return file_synchronization_state_proto_rawDescGZIP(), []int{0}
 }
 
+type AdditionalBetaState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Connected                       bool            `protobuf:"varint,1,opt,name=connected,proto3" json:"connected,omitempty"`
+	LastError                       string          `protobuf:"bytes,2,opt,name=lastError,proto3" json:"lastError,omitempty"`
+	SuccessfulSynchronizationCycles uint64          `protobuf:"varint,3,opt,name=successfulSynchronizationCycles,proto3" json:"successfulSynchronizationCycles,omitempty"`
+	Problems                        []*core.Problem `protobuf:"bytes,4,rep,name=problems,proto3" json:"problems,omitempty"`
+	TruncatedProblems               uint64          `protobuf:"varint,5,opt,name=truncatedProblems,proto3" json:"truncatedProblems,omitempty"`
+	Members                         []string        `protobuf:"bytes,6,rep,name=members,proto3" json:"members,omitempty"`
+}
+
+func (x *AdditionalBetaState) Reset() {
+	*x = AdditionalBetaState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_synchronization_state_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AdditionalBetaState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdditionalBetaState) ProtoMessage() {}
+
+func (x *AdditionalBetaState) ProtoReflect() protoreflect.Message {
+	mi := &file_synchronization_state_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdditionalBetaState.ProtoReflect.Descriptor instead.
+func (*AdditionalBetaState) Descriptor() ([]byte, []int) {
+	return file_synchronization_state_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AdditionalBetaState) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+func (x *AdditionalBetaState) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *AdditionalBetaState) GetSuccessfulSynchronizationCycles() uint64 {
+	if x != nil {
+		return x.SuccessfulSynchronizationCycles
+	}
+	return 0
+}
+
+func (x *AdditionalBetaState) GetProblems() []*core.Problem {
+	if x != nil {
+		return x.Problems
+	}
+	return nil
+}
+
+func (x *AdditionalBetaState) GetTruncatedProblems() uint64 {
+	if x != nil {
+		return x.TruncatedProblems
+	}
+	return 0
+}
+
+func (x *AdditionalBetaState) GetMembers() []string {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+type StallReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status            Status             `protobuf:"varint,1,opt,name=status,proto3,enum=synchronization.Status" json:"status,omitempty"`
+	TimeSinceProgress *duration.Duration `protobuf:"bytes,2,opt,name=timeSinceProgress,proto3" json:"timeSinceProgress,omitempty"`
+	Goroutines        string             `protobuf:"bytes,3,opt,name=goroutines,proto3" json:"goroutines,omitempty"`
+}
+
+func (x *StallReport) Reset() {
+	*x = StallReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_synchronization_state_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StallReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StallReport) ProtoMessage() {}
+
+func (x *StallReport) ProtoReflect() protoreflect.Message {
+	mi := &file_synchronization_state_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StallReport.ProtoReflect.Descriptor instead.
+func (*StallReport) Descriptor() ([]byte, []int) {
+	return file_synchronization_state_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StallReport) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_Disconnected
+}
+
+func (x *StallReport) GetTimeSinceProgress() *duration.Duration {
+	if x != nil {
+		return x.TimeSinceProgress
+	}
+	return nil
+}
+
+func (x *StallReport) GetGoroutines() string {
+	if x != nil {
+		return x.Goroutines
+	}
+	return ""
+}
+
+type Histogram struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bounds []float64 `protobuf:"fixed64,1,rep,packed,name=bounds,proto3" json:"bounds,omitempty"`
+	Counts []uint64  `protobuf:"varint,2,rep,packed,name=counts,proto3" json:"counts,omitempty"`
+	Count  uint64    `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Sum    float64   `protobuf:"fixed64,4,opt,name=sum,proto3" json:"sum,omitempty"`
+}
+
+func (x *Histogram) Reset() {
+	*x = Histogram{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_synchronization_state_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Histogram) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Histogram) ProtoMessage() {}
+
+func (x *Histogram) ProtoReflect() protoreflect.Message {
+	mi := &file_synchronization_state_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Histogram.ProtoReflect.Descriptor instead.
+func (*Histogram) Descriptor() ([]byte, []int) {
+	return file_synchronization_state_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Histogram) GetBounds() []float64 {
+	if x != nil {
+		return x.Bounds
+	}
+	return nil
+}
+
+func (x *Histogram) GetCounts() []uint64 {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+func (x *Histogram) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *Histogram) GetSum() float64 {
+	if x != nil {
+		return x.Sum
+	}
+	return 0
+}
+
+type Timings struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ConnectDurations    *Histogram `protobuf:"bytes,1,opt,name=connectDurations,proto3" json:"connectDurations,omitempty"`
+	ScanDurations       *Histogram `protobuf:"bytes,2,opt,name=scanDurations,proto3" json:"scanDurations,omitempty"`
+	StagingThroughputs  *Histogram `protobuf:"bytes,3,opt,name=stagingThroughputs,proto3" json:"stagingThroughputs,omitempty"`
+	TransitionDurations *Histogram `protobuf:"bytes,4,opt,name=transitionDurations,proto3" json:"transitionDurations,omitempty"`
+}
+
+func (x *Timings) Reset() {
+	*x = Timings{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_synchronization_state_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Timings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Timings) ProtoMessage() {}
+
+func (x *Timings) ProtoReflect() protoreflect.Message {
+	mi := &file_synchronization_state_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Timings.ProtoReflect.Descriptor instead.
+func (*Timings) Descriptor() ([]byte, []int) {
+	return file_synchronization_state_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Timings) GetConnectDurations() *Histogram {
+	if x != nil {
+		return x.ConnectDurations
+	}
+	return nil
+}
+
+func (x *Timings) GetScanDurations() *Histogram {
+	if x != nil {
+		return x.ScanDurations
+	}
+	return nil
+}
+
+func (x *Timings) GetStagingThroughputs() *Histogram {
+	if x != nil {
+		return x.StagingThroughputs
+	}
+	return nil
+}
+
+func (x *Timings) GetTransitionDurations() *Histogram {
+	if x != nil {
+		return x.TransitionDurations
+	}
+	return nil
+}
+
 type State struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Session                         *Session              `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
-	Status                          Status                `protobuf:"varint,2,opt,name=status,proto3,enum=synchronization.Status" json:"status,omitempty"`
-	AlphaConnected                  bool                  `protobuf:"varint,3,opt,name=alphaConnected,proto3" json:"alphaConnected,omitempty"`
-	BetaConnected                   bool                  `protobuf:"varint,4,opt,name=betaConnected,proto3" json:"betaConnected,omitempty"`
-	LastError                       string                `protobuf:"bytes,5,opt,name=lastError,proto3" json:"lastError,omitempty"`
-	SuccessfulSynchronizationCycles uint64                `protobuf:"varint,6,opt,name=successfulSynchronizationCycles,proto3" json:"successfulSynchronizationCycles,omitempty"`
-	StagingStatus                   *rsync.ReceiverStatus `protobuf:"bytes,7,opt,name=stagingStatus,proto3" json:"stagingStatus,omitempty"`
-	Conflicts                       []*core.Conflict      `protobuf:"bytes,8,rep,name=conflicts,proto3" json:"conflicts,omitempty"`
-	AlphaProblems                   []*core.Problem       `protobuf:"bytes,9,rep,name=alphaProblems,proto3" json:"alphaProblems,omitempty"`
-	BetaProblems                    []*core.Problem       `protobuf:"bytes,10,rep,name=betaProblems,proto3" json:"betaProblems,omitempty"`
-	TruncatedConflicts              uint64                `protobuf:"varint,11,opt,name=truncatedConflicts,proto3" json:"truncatedConflicts,omitempty"`
-	TruncatedAlphaProblems          uint64                `protobuf:"varint,12,opt,name=truncatedAlphaProblems,proto3" json:"truncatedAlphaProblems,omitempty"`
-	TruncatedBetaProblems           uint64                `protobuf:"varint,13,opt,name=truncatedBetaProblems,proto3" json:"truncatedBetaProblems,omitempty"`
+	Session                          *Session                       `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	Status                           Status                         `protobuf:"varint,2,opt,name=status,proto3,enum=synchronization.Status" json:"status,omitempty"`
+	AlphaConnected                   bool                           `protobuf:"varint,3,opt,name=alphaConnected,proto3" json:"alphaConnected,omitempty"`
+	BetaConnected                    bool                           `protobuf:"varint,4,opt,name=betaConnected,proto3" json:"betaConnected,omitempty"`
+	LastError                        string                         `protobuf:"bytes,5,opt,name=lastError,proto3" json:"lastError,omitempty"`
+	SuccessfulSynchronizationCycles  uint64                         `protobuf:"varint,6,opt,name=successfulSynchronizationCycles,proto3" json:"successfulSynchronizationCycles,omitempty"`
+	StagingStatus                    *rsync.ReceiverStatus          `protobuf:"bytes,7,opt,name=stagingStatus,proto3" json:"stagingStatus,omitempty"`
+	Conflicts                        []*core.Conflict               `protobuf:"bytes,8,rep,name=conflicts,proto3" json:"conflicts,omitempty"`
+	AlphaProblems                    []*core.Problem                `protobuf:"bytes,9,rep,name=alphaProblems,proto3" json:"alphaProblems,omitempty"`
+	BetaProblems                     []*core.Problem                `protobuf:"bytes,10,rep,name=betaProblems,proto3" json:"betaProblems,omitempty"`
+	TruncatedConflicts               uint64                         `protobuf:"varint,11,opt,name=truncatedConflicts,proto3" json:"truncatedConflicts,omitempty"`
+	TruncatedAlphaProblems           uint64                         `protobuf:"varint,12,opt,name=truncatedAlphaProblems,proto3" json:"truncatedAlphaProblems,omitempty"`
+	TruncatedBetaProblems            uint64                         `protobuf:"varint,13,opt,name=truncatedBetaProblems,proto3" json:"truncatedBetaProblems,omitempty"`
+	AlphaClockSkew                   *duration.Duration             `protobuf:"bytes,14,opt,name=alphaClockSkew,proto3" json:"alphaClockSkew,omitempty"`
+	BetaClockSkew                    *duration.Duration             `protobuf:"bytes,15,opt,name=betaClockSkew,proto3" json:"betaClockSkew,omitempty"`
+	AdditionalBetas                  []*AdditionalBetaState         `protobuf:"bytes,16,rep,name=additionalBetas,proto3" json:"additionalBetas,omitempty"`
+	ReconciliationDecisions          []*core.ReconciliationDecision `protobuf:"bytes,17,rep,name=reconciliationDecisions,proto3" json:"reconciliationDecisions,omitempty"`
+	TruncatedReconciliationDecisions uint64                         `protobuf:"varint,18,opt,name=truncatedReconciliationDecisions,proto3" json:"truncatedReconciliationDecisions,omitempty"`
+	StallReport                      *StallReport                   `protobuf:"bytes,19,opt,name=stallReport,proto3" json:"stallReport,omitempty"`
+	Timings                          *Timings                       `protobuf:"bytes,20,opt,name=timings,proto3" json:"timings,omitempty"`
+	ObservedAlphaChanges             []*core.Change                 `protobuf:"bytes,21,rep,name=observedAlphaChanges,proto3" json:"observedAlphaChanges,omitempty"`
+	ObservedBetaChanges              []*core.Change                 `protobuf:"bytes,22,rep,name=observedBetaChanges,proto3" json:"observedBetaChanges,omitempty"`
+	TruncatedObservedAlphaChanges    uint64                         `protobuf:"varint,23,opt,name=truncatedObservedAlphaChanges,proto3" json:"truncatedObservedAlphaChanges,omitempty"`
+	TruncatedObservedBetaChanges     uint64                         `protobuf:"varint,24,opt,name=truncatedObservedBetaChanges,proto3" json:"truncatedObservedBetaChanges,omitempty"`
+	TransferEfficiencies             []*rsync.TransferEfficiency    `protobuf:"bytes,25,rep,name=transferEfficiencies,proto3" json:"transferEfficiencies,omitempty"`
+	TruncatedTransferEfficiencies    uint64                         `protobuf:"varint,26,opt,name=truncatedTransferEfficiencies,proto3" json:"truncatedTransferEfficiencies,omitempty"`
+	AlphaMerkleRoot                  []byte                         `protobuf:"bytes,27,opt,name=alphaMerkleRoot,proto3" json:"alphaMerkleRoot,omitempty"`
+	BetaMerkleRoot                   []byte                         `protobuf:"bytes,28,opt,name=betaMerkleRoot,proto3" json:"betaMerkleRoot,omitempty"`
+	QueuePosition                    uint64                         `protobuf:"varint,29,opt,name=queuePosition,proto3" json:"queuePosition,omitempty"`
+	DeferredTransfers                uint64                         `protobuf:"varint,30,opt,name=deferredTransfers,proto3" json:"deferredTransfers,omitempty"`
+	QuarantinedPaths                 []string                       `protobuf:"bytes,31,rep,name=quarantinedPaths,proto3" json:"quarantinedPaths,omitempty"`
 }
 
 func (x *State) Reset() {
 	*x = State{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_synchronization_state_proto_msgTypes[0]
+		mi := &file_synchronization_state_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -145,7 +456,7 @@ func (x *State) String() string {
 func (*State) ProtoMessage() {}
 
 func (x *State) ProtoReflect() protoreflect.Message {
-	mi := &file_synchronization_state_proto_msgTypes[0]
+	mi := &file_synchronization_state_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -158,7 +469,7 @@ func (x *State) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use State.ProtoReflect.Descriptor instead.
 func (*State) Descriptor() ([]byte, []int) {
-	return file_synchronization_state_proto_rawDescGZIP(), []int{0}
+	return file_synchronization_state_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *State) GetSession() *Session {
@@ -252,21 +563,209 @@ func (x *State) GetTruncatedBetaProblems() uint64 {
 	return 0
 }
 
+func (x *State) GetAlphaClockSkew() *duration.Duration {
+	if x != nil {
+		return x.AlphaClockSkew
+	}
+	return nil
+}
+
+func (x *State) GetBetaClockSkew() *duration.Duration {
+	if x != nil {
+		return x.BetaClockSkew
+	}
+	return nil
+}
+
+func (x *State) GetAdditionalBetas() []*AdditionalBetaState {
+	if x != nil {
+		return x.AdditionalBetas
+	}
+	return nil
+}
+
+func (x *State) GetReconciliationDecisions() []*core.ReconciliationDecision {
+	if x != nil {
+		return x.ReconciliationDecisions
+	}
+	return nil
+}
+
+func (x *State) GetTruncatedReconciliationDecisions() uint64 {
+	if x != nil {
+		return x.TruncatedReconciliationDecisions
+	}
+	return 0
+}
+
+func (x *State) GetStallReport() *StallReport {
+	if x != nil {
+		return x.StallReport
+	}
+	return nil
+}
+
+func (x *State) GetTimings() *Timings {
+	if x != nil {
+		return x.Timings
+	}
+	return nil
+}
+
+func (x *State) GetObservedAlphaChanges() []*core.Change {
+	if x != nil {
+		return x.ObservedAlphaChanges
+	}
+	return nil
+}
+
+func (x *State) GetObservedBetaChanges() []*core.Change {
+	if x != nil {
+		return x.ObservedBetaChanges
+	}
+	return nil
+}
+
+func (x *State) GetTruncatedObservedAlphaChanges() uint64 {
+	if x != nil {
+		return x.TruncatedObservedAlphaChanges
+	}
+	return 0
+}
+
+func (x *State) GetTruncatedObservedBetaChanges() uint64 {
+	if x != nil {
+		return x.TruncatedObservedBetaChanges
+	}
+	return 0
+}
+
+func (x *State) GetTransferEfficiencies() []*rsync.TransferEfficiency {
+	if x != nil {
+		return x.TransferEfficiencies
+	}
+	return nil
+}
+
+func (x *State) GetTruncatedTransferEfficiencies() uint64 {
+	if x != nil {
+		return x.TruncatedTransferEfficiencies
+	}
+	return 0
+}
+
+func (x *State) GetAlphaMerkleRoot() []byte {
+	if x != nil {
+		return x.AlphaMerkleRoot
+	}
+	return nil
+}
+
+func (x *State) GetBetaMerkleRoot() []byte {
+	if x != nil {
+		return x.BetaMerkleRoot
+	}
+	return nil
+}
+
+func (x *State) GetQueuePosition() uint64 {
+	if x != nil {
+		return x.QueuePosition
+	}
+	return 0
+}
+
+func (x *State) GetDeferredTransfers() uint64 {
+	if x != nil {
+		return x.DeferredTransfers
+	}
+	return 0
+}
+
+func (x *State) GetQuarantinedPaths() []string {
+	if x != nil {
+		return x.QuarantinedPaths
+	}
+	return nil
+}
+
 var File_synchronization_state_proto protoreflect.FileDescriptor
 
 var file_synchronization_state_proto_rawDesc = []byte{
 	0x0a, 0x1b, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f,
 	0x6e, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0f, 0x73,
-	0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x23,
+	0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x1e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f,
+	0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x26,
 	0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f,
-	0x72, 0x73, 0x79, 0x6e, 0x63, 0x2f, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x1a, 0x1d, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f,
+	0x72, 0x73, 0x79, 0x6e, 0x63, 0x2f, 0x65, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e, 0x63, 0x79,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x23, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e,
+	0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x72, 0x73, 0x79, 0x6e, 0x63, 0x2f, 0x72, 0x65,
+	0x63, 0x65, 0x69, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1d, 0x73, 0x79, 0x6e,
+	0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x73, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x21, 0x73, 0x79, 0x6e, 0x63,
+	0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65,
+	0x2f, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x23, 0x73,
+	0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63,
+	0x6f, 0x72, 0x65, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x2e, 0x70, 0x72, 0x6f,
 	0x74, 0x6f, 0x1a, 0x23, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63,
-	0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x22, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f,
+	0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x22, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f,
 	0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x70, 0x72,
-	0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x93, 0x05, 0x0a, 0x05,
+	0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x8e, 0x02, 0x0a, 0x13,
+	0x41, 0x64, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x42, 0x65, 0x74, 0x61, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12,
+	0x48, 0x0a, 0x1f, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x66, 0x75, 0x6c, 0x53, 0x79, 0x6e,
+	0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x79, 0x63, 0x6c,
+	0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x1f, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x66, 0x75, 0x6c, 0x53, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x43, 0x79, 0x63, 0x6c, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x08, 0x70, 0x72, 0x6f,
+	0x62, 0x6c, 0x65, 0x6d, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x63, 0x6f,
+	0x72, 0x65, 0x2e, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x62,
+	0x6c, 0x65, 0x6d, 0x73, 0x12, 0x2c, 0x0a, 0x11, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65,
+	0x64, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x11, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65,
+	0x6d, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x18, 0x06, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x22, 0xa7, 0x01, 0x0a,
+	0x0b, 0x53, 0x74, 0x61, 0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x2f, 0x0a, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x73,
+	0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x47, 0x0a,
+	0x11, 0x74, 0x69, 0x6d, 0x65, 0x53, 0x69, 0x6e, 0x63, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65,
+	0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x11, 0x74, 0x69, 0x6d, 0x65, 0x53, 0x69, 0x6e, 0x63, 0x65, 0x50, 0x72,
+	0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x6f, 0x72, 0x6f, 0x75, 0x74,
+	0x69, 0x6e, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x67, 0x6f, 0x72, 0x6f,
+	0x75, 0x74, 0x69, 0x6e, 0x65, 0x73, 0x22, 0x63, 0x0a, 0x09, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x67,
+	0x72, 0x61, 0x6d, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x01, 0x52, 0x06, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x04, 0x52, 0x06, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x75, 0x6d,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x73, 0x75, 0x6d, 0x22, 0xad, 0x02, 0x0a, 0x07,
+	0x54, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x46, 0x0a, 0x10, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x52, 0x10, 0x63,
+	0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12,
+	0x40, 0x0a, 0x0d, 0x73, 0x63, 0x61, 0x6e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f,
+	0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72,
+	0x61, 0x6d, 0x52, 0x0d, 0x73, 0x63, 0x61, 0x6e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x12, 0x4a, 0x0a, 0x12, 0x73, 0x74, 0x61, 0x67, 0x69, 0x6e, 0x67, 0x54, 0x68, 0x72, 0x6f,
+	0x75, 0x67, 0x68, 0x70, 0x75, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x48, 0x69, 0x73, 0x74, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x52, 0x12, 0x73, 0x74, 0x61, 0x67, 0x69,
+	0x6e, 0x67, 0x54, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x70, 0x75, 0x74, 0x73, 0x12, 0x4c, 0x0a,
+	0x13, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x79, 0x6e,
+	0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x48, 0x69, 0x73,
+	0x74, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x52, 0x13, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xf2, 0x0d, 0x0a, 0x05,
 	0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x32, 0x0a, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f,
 	0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
@@ -308,28 +807,98 @@ var file_synchronization_state_proto_rawDesc = []byte{
 	0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x42, 0x65, 0x74, 0x61, 0x50, 0x72, 0x6f, 0x62,
 	0x6c, 0x65, 0x6d, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x04, 0x52, 0x15, 0x74, 0x72, 0x75, 0x6e,
 	0x63, 0x61, 0x74, 0x65, 0x64, 0x42, 0x65, 0x74, 0x61, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d,
-	0x73, 0x2a, 0x97, 0x02, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x10, 0x0a, 0x0c,
-	0x44, 0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x10, 0x00, 0x12, 0x17,
-	0x0a, 0x13, 0x48, 0x61, 0x6c, 0x74, 0x65, 0x64, 0x4f, 0x6e, 0x52, 0x6f, 0x6f, 0x74, 0x45, 0x6d,
-	0x70, 0x74, 0x69, 0x65, 0x64, 0x10, 0x01, 0x12, 0x18, 0x0a, 0x14, 0x48, 0x61, 0x6c, 0x74, 0x65,
-	0x64, 0x4f, 0x6e, 0x52, 0x6f, 0x6f, 0x74, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x69, 0x6f, 0x6e, 0x10,
-	0x02, 0x12, 0x1a, 0x0a, 0x16, 0x48, 0x61, 0x6c, 0x74, 0x65, 0x64, 0x4f, 0x6e, 0x52, 0x6f, 0x6f,
-	0x74, 0x54, 0x79, 0x70, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x10, 0x03, 0x12, 0x13, 0x0a,
-	0x0f, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6e, 0x67, 0x41, 0x6c, 0x70, 0x68, 0x61,
-	0x10, 0x04, 0x12, 0x12, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6e, 0x67,
-	0x42, 0x65, 0x74, 0x61, 0x10, 0x05, 0x12, 0x0c, 0x0a, 0x08, 0x57, 0x61, 0x74, 0x63, 0x68, 0x69,
-	0x6e, 0x67, 0x10, 0x06, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x63, 0x61, 0x6e, 0x6e, 0x69, 0x6e, 0x67,
-	0x10, 0x07, 0x12, 0x14, 0x0a, 0x10, 0x57, 0x61, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x46, 0x6f, 0x72,
-	0x52, 0x65, 0x73, 0x63, 0x61, 0x6e, 0x10, 0x08, 0x12, 0x0f, 0x0a, 0x0b, 0x52, 0x65, 0x63, 0x6f,
-	0x6e, 0x63, 0x69, 0x6c, 0x69, 0x6e, 0x67, 0x10, 0x09, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x74, 0x61,
-	0x67, 0x69, 0x6e, 0x67, 0x41, 0x6c, 0x70, 0x68, 0x61, 0x10, 0x0a, 0x12, 0x0f, 0x0a, 0x0b, 0x53,
-	0x74, 0x61, 0x67, 0x69, 0x6e, 0x67, 0x42, 0x65, 0x74, 0x61, 0x10, 0x0b, 0x12, 0x11, 0x0a, 0x0d,
-	0x54, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x69, 0x6e, 0x67, 0x10, 0x0c, 0x12,
-	0x0a, 0x0a, 0x06, 0x53, 0x61, 0x76, 0x69, 0x6e, 0x67, 0x10, 0x0d, 0x42, 0x33, 0x5a, 0x31, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65,
-	0x6e, 0x2d, 0x69, 0x6f, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x6b, 0x67,
-	0x2f, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x73, 0x12, 0x41, 0x0a, 0x0e, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x53,
+	0x6b, 0x65, 0x77, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0e, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x43, 0x6c, 0x6f, 0x63, 0x6b,
+	0x53, 0x6b, 0x65, 0x77, 0x12, 0x3f, 0x0a, 0x0d, 0x62, 0x65, 0x74, 0x61, 0x43, 0x6c, 0x6f, 0x63,
+	0x6b, 0x53, 0x6b, 0x65, 0x77, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x62, 0x65, 0x74, 0x61, 0x43, 0x6c, 0x6f, 0x63,
+	0x6b, 0x53, 0x6b, 0x65, 0x77, 0x12, 0x4e, 0x0a, 0x0f, 0x61, 0x64, 0x64, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x61, 0x6c, 0x42, 0x65, 0x74, 0x61, 0x73, 0x18, 0x10, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24,
+	0x2e, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x41, 0x64, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c, 0x42, 0x65, 0x74, 0x61, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x52, 0x0f, 0x61, 0x64, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c,
+	0x42, 0x65, 0x74, 0x61, 0x73, 0x12, 0x56, 0x0a, 0x17, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69,
+	0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x11, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x52, 0x65,
+	0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x65, 0x63, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x52, 0x17, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x4a, 0x0a,
+	0x20, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x63,
+	0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0x12, 0x20, 0x01, 0x28, 0x04, 0x52, 0x20, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74,
+	0x65, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x3e, 0x0a, 0x0b, 0x73, 0x74, 0x61,
+	0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c,
+	0x2e, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x53, 0x74, 0x61, 0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x0b, 0x73, 0x74,
+	0x61, 0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x32, 0x0a, 0x07, 0x74, 0x69, 0x6d,
+	0x69, 0x6e, 0x67, 0x73, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x79, 0x6e,
+	0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x54, 0x69, 0x6d,
+	0x69, 0x6e, 0x67, 0x73, 0x52, 0x07, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x40, 0x0a,
+	0x14, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x41, 0x6c, 0x70, 0x68, 0x61, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x15, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x63, 0x6f,
+	0x72, 0x65, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x14, 0x6f, 0x62, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x64, 0x41, 0x6c, 0x70, 0x68, 0x61, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12,
+	0x3e, 0x0a, 0x13, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x42, 0x65, 0x74, 0x61, 0x43,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x16, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x63,
+	0x6f, 0x72, 0x65, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x13, 0x6f, 0x62, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x64, 0x42, 0x65, 0x74, 0x61, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12,
+	0x44, 0x0a, 0x1d, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x4f, 0x62, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x64, 0x41, 0x6c, 0x70, 0x68, 0x61, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73,
+	0x18, 0x17, 0x20, 0x01, 0x28, 0x04, 0x52, 0x1d, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65,
+	0x64, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x41, 0x6c, 0x70, 0x68, 0x61, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x42, 0x0a, 0x1c, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74,
+	0x65, 0x64, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x42, 0x65, 0x74, 0x61, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x18, 0x20, 0x01, 0x28, 0x04, 0x52, 0x1c, 0x74, 0x72, 0x75,
+	0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x4f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x42, 0x65,
+	0x74, 0x61, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x4d, 0x0a, 0x14, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x65, 0x72, 0x45, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e, 0x63, 0x69, 0x65,
+	0x73, 0x18, 0x19, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x72, 0x73, 0x79, 0x6e, 0x63, 0x2e,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x45, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e,
+	0x63, 0x79, 0x52, 0x14, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x45, 0x66, 0x66, 0x69,
+	0x63, 0x69, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73, 0x12, 0x44, 0x0a, 0x1d, 0x74, 0x72, 0x75, 0x6e,
+	0x63, 0x61, 0x74, 0x65, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x45, 0x66, 0x66,
+	0x69, 0x63, 0x69, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x1d, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x45, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e, 0x63, 0x69, 0x65, 0x73, 0x12, 0x28,
+	0x0a, 0x0f, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x4d, 0x65, 0x72, 0x6b, 0x6c, 0x65, 0x52, 0x6f, 0x6f,
+	0x74, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0f, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x4d, 0x65,
+	0x72, 0x6b, 0x6c, 0x65, 0x52, 0x6f, 0x6f, 0x74, 0x12, 0x26, 0x0a, 0x0e, 0x62, 0x65, 0x74, 0x61,
+	0x4d, 0x65, 0x72, 0x6b, 0x6c, 0x65, 0x52, 0x6f, 0x6f, 0x74, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x0e, 0x62, 0x65, 0x74, 0x61, 0x4d, 0x65, 0x72, 0x6b, 0x6c, 0x65, 0x52, 0x6f, 0x6f, 0x74,
+	0x12, 0x24, 0x0a, 0x0d, 0x71, 0x75, 0x65, 0x75, 0x65, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x71, 0x75, 0x65, 0x75, 0x65, 0x50, 0x6f,
+	0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x11, 0x64, 0x65, 0x66, 0x65, 0x72, 0x72,
+	0x65, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x73, 0x18, 0x1e, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x11, 0x64, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x65, 0x72, 0x73, 0x12, 0x2a, 0x0a, 0x10, 0x71, 0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69,
+	0x6e, 0x65, 0x64, 0x50, 0x61, 0x74, 0x68, 0x73, 0x18, 0x1f, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10,
+	0x71, 0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x64, 0x50, 0x61, 0x74, 0x68, 0x73,
+	0x2a, 0x97, 0x02, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x10, 0x0a, 0x0c, 0x44,
+	0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x10, 0x00, 0x12, 0x17, 0x0a,
+	0x13, 0x48, 0x61, 0x6c, 0x74, 0x65, 0x64, 0x4f, 0x6e, 0x52, 0x6f, 0x6f, 0x74, 0x45, 0x6d, 0x70,
+	0x74, 0x69, 0x65, 0x64, 0x10, 0x01, 0x12, 0x18, 0x0a, 0x14, 0x48, 0x61, 0x6c, 0x74, 0x65, 0x64,
+	0x4f, 0x6e, 0x52, 0x6f, 0x6f, 0x74, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x69, 0x6f, 0x6e, 0x10, 0x02,
+	0x12, 0x1a, 0x0a, 0x16, 0x48, 0x61, 0x6c, 0x74, 0x65, 0x64, 0x4f, 0x6e, 0x52, 0x6f, 0x6f, 0x74,
+	0x54, 0x79, 0x70, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x10, 0x03, 0x12, 0x13, 0x0a, 0x0f,
+	0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6e, 0x67, 0x41, 0x6c, 0x70, 0x68, 0x61, 0x10,
+	0x04, 0x12, 0x12, 0x0a, 0x0e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6e, 0x67, 0x42,
+	0x65, 0x74, 0x61, 0x10, 0x05, 0x12, 0x0c, 0x0a, 0x08, 0x57, 0x61, 0x74, 0x63, 0x68, 0x69, 0x6e,
+	0x67, 0x10, 0x06, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x63, 0x61, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x10,
+	0x07, 0x12, 0x14, 0x0a, 0x10, 0x57, 0x61, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x46, 0x6f, 0x72, 0x52,
+	0x65, 0x73, 0x63, 0x61, 0x6e, 0x10, 0x08, 0x12, 0x0f, 0x0a, 0x0b, 0x52, 0x65, 0x63, 0x6f, 0x6e,
+	0x63, 0x69, 0x6c, 0x69, 0x6e, 0x67, 0x10, 0x09, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x67,
+	0x69, 0x6e, 0x67, 0x41, 0x6c, 0x70, 0x68, 0x61, 0x10, 0x0a, 0x12, 0x0f, 0x0a, 0x0b, 0x53, 0x74,
+	0x61, 0x67, 0x69, 0x6e, 0x67, 0x42, 0x65, 0x74, 0x61, 0x10, 0x0b, 0x12, 0x11, 0x0a, 0x0d, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x69, 0x6e, 0x67, 0x10, 0x0c, 0x12, 0x0a,
+	0x0a, 0x06, 0x53, 0x61, 0x76, 0x69, 0x6e, 0x67, 0x10, 0x0d, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e,
+	0x2d, 0x69, 0x6f, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f,
+	0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -345,27 +914,51 @@ func file_synchronization_state_proto_rawDescGZIP() []byte {
 }
 
 var file_synchronization_state_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_synchronization_state_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_synchronization_state_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_synchronization_state_proto_goTypes = []interface{}{
-	(Status)(0),                  // 0: synchronization.Status
-	(*State)(nil),                // 1: synchronization.State
-	(*Session)(nil),              // 2: synchronization.Session
-	(*rsync.ReceiverStatus)(nil), // 3: rsync.ReceiverStatus
-	(*core.Conflict)(nil),        // 4: core.Conflict
-	(*core.Problem)(nil),         // 5: core.Problem
+	(Status)(0),                         // 0: synchronization.Status
+	(*AdditionalBetaState)(nil),         // 1: synchronization.AdditionalBetaState
+	(*StallReport)(nil),                 // 2: synchronization.StallReport
+	(*Histogram)(nil),                   // 3: synchronization.Histogram
+	(*Timings)(nil),                     // 4: synchronization.Timings
+	(*State)(nil),                       // 5: synchronization.State
+	(*core.Problem)(nil),                // 6: core.Problem
+	(*duration.Duration)(nil),           // 7: google.protobuf.Duration
+	(*Session)(nil),                     // 8: synchronization.Session
+	(*rsync.ReceiverStatus)(nil),        // 9: rsync.ReceiverStatus
+	(*core.Conflict)(nil),               // 10: core.Conflict
+	(*core.ReconciliationDecision)(nil), // 11: core.ReconciliationDecision
+	(*core.Change)(nil),                 // 12: core.Change
+	(*rsync.TransferEfficiency)(nil),    // 13: rsync.TransferEfficiency
 }
 var file_synchronization_state_proto_depIdxs = []int32{
-	2, // 0: synchronization.State.session:type_name -> synchronization.Session
-	0, // 1: synchronization.State.status:type_name -> synchronization.Status
-	3, // 2: synchronization.State.stagingStatus:type_name -> rsync.ReceiverStatus
-	4, // 3: synchronization.State.conflicts:type_name -> core.Conflict
-	5, // 4: synchronization.State.alphaProblems:type_name -> core.Problem
-	5, // 5: synchronization.State.betaProblems:type_name -> core.Problem
-	6, // [6:6] is the sub-list for method output_type
-	6, // [6:6] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	6,  // 0: synchronization.AdditionalBetaState.problems:type_name -> core.Problem
+	0,  // 1: synchronization.StallReport.status:type_name -> synchronization.Status
+	7,  // 2: synchronization.StallReport.timeSinceProgress:type_name -> google.protobuf.Duration
+	3,  // 3: synchronization.Timings.connectDurations:type_name -> synchronization.Histogram
+	3,  // 4: synchronization.Timings.scanDurations:type_name -> synchronization.Histogram
+	3,  // 5: synchronization.Timings.stagingThroughputs:type_name -> synchronization.Histogram
+	3,  // 6: synchronization.Timings.transitionDurations:type_name -> synchronization.Histogram
+	8,  // 7: synchronization.State.session:type_name -> synchronization.Session
+	0,  // 8: synchronization.State.status:type_name -> synchronization.Status
+	9,  // 9: synchronization.State.stagingStatus:type_name -> rsync.ReceiverStatus
+	10, // 10: synchronization.State.conflicts:type_name -> core.Conflict
+	6,  // 11: synchronization.State.alphaProblems:type_name -> core.Problem
+	6,  // 12: synchronization.State.betaProblems:type_name -> core.Problem
+	7,  // 13: synchronization.State.alphaClockSkew:type_name -> google.protobuf.Duration
+	7,  // 14: synchronization.State.betaClockSkew:type_name -> google.protobuf.Duration
+	1,  // 15: synchronization.State.additionalBetas:type_name -> synchronization.AdditionalBetaState
+	11, // 16: synchronization.State.reconciliationDecisions:type_name -> core.ReconciliationDecision
+	2,  // 17: synchronization.State.stallReport:type_name -> synchronization.StallReport
+	4,  // 18: synchronization.State.timings:type_name -> synchronization.Timings
+	12, // 19: synchronization.State.observedAlphaChanges:type_name -> core.Change
+	12, // 20: synchronization.State.observedBetaChanges:type_name -> core.Change
+	13, // 21: synchronization.State.transferEfficiencies:type_name -> rsync.TransferEfficiency
+	22, // [22:22] is the sub-list for method output_type
+	22, // [22:22] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
 }
 
 func init() { file_synchronization_state_proto_init() }
@@ -376,6 +969,54 @@ func file_synchronization_state_proto_init() {
 	file_synchronization_session_proto_init()
 	if !protoimpl.UnsafeEnabled {
 		file_synchronization_state_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AdditionalBetaState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_synchronization_state_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StallReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_synchronization_state_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Histogram); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_synchronization_state_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Timings); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_synchronization_state_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*State); i {
 			case 0:
 				return &v.state
@@ -394,7 +1035,7 @@ func file_synchronization_state_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_synchronization_state_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   1,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
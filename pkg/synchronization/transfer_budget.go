@@ -0,0 +1,111 @@
+package synchronization
+
+import (
+	"sort"
+)
+
+// transferDeferrals tracks files whose staging has been deferred by the cycle
+// transfer budget, allowing subsequent cycles to prioritize them so that no
+// file is deferred indefinitely. Deferred files are prioritized in the order in
+// which they were first deferred (and then by path), and the staging direction
+// with first claim to the budget alternates between cycles with deferrals, so
+// each direction regularly stages its highest priority file (which is always
+// admitted when the budget is unused) regardless of activity in the other
+// direction.
+type transferDeferrals struct {
+	// cycle is the index of the current cycle.
+	cycle uint64
+	// alpha maps paths deferred for staging on alpha to the cycle in which
+	// they were first deferred.
+	alpha map[string]uint64
+	// beta maps paths deferred for staging on beta to the cycle in which they
+	// were first deferred.
+	beta map[string]uint64
+	// betaFirst indicates whether or not staging on beta should take place
+	// before staging on alpha.
+	betaFirst bool
+}
+
+// stagingOrder implements sort.Interface to order staging paths (and their
+// corresponding digests) by deferral priority.
+type stagingOrder struct {
+	// deferrals are the previous deferrals for the staging direction.
+	deferrals map[string]uint64
+	// paths are the staging paths.
+	paths []string
+	// digests are the staging digests.
+	digests [][]byte
+}
+
+// Len implements sort.Interface.Len.
+func (o *stagingOrder) Len() int {
+	return len(o.paths)
+}
+
+// Less implements sort.Interface.Less.
+func (o *stagingOrder) Less(i, j int) bool {
+	iCycle, iDeferred := o.deferrals[o.paths[i]]
+	jCycle, jDeferred := o.deferrals[o.paths[j]]
+	if iDeferred != jDeferred {
+		return iDeferred
+	} else if iCycle != jCycle {
+		return iCycle < jCycle
+	}
+	return o.paths[i] < o.paths[j]
+}
+
+// Swap implements sort.Interface.Swap.
+func (o *stagingOrder) Swap(i, j int) {
+	o.paths[i], o.paths[j] = o.paths[j], o.paths[i]
+	o.digests[i], o.digests[j] = o.digests[j], o.digests[i]
+}
+
+// order sorts staging paths (and their corresponding digests) in place so that
+// previously deferred paths are staged first, in the order in which they were
+// first deferred, followed by all other paths in lexicographical order.
+func (d *transferDeferrals) order(alpha bool, paths []string, digests [][]byte) {
+	deferrals := d.beta
+	if alpha {
+		deferrals = d.alpha
+	}
+	sort.Sort(&stagingOrder{deferrals, paths, digests})
+}
+
+// record records the paths deferred for staging in one direction during the
+// current cycle. Paths that aren't deferred again are forgotten.
+func (d *transferDeferrals) record(alpha bool, deferred []string) {
+	previous := d.beta
+	if alpha {
+		previous = d.alpha
+	}
+	var deferrals map[string]uint64
+	if len(deferred) > 0 {
+		deferrals = make(map[string]uint64, len(deferred))
+		for _, path := range deferred {
+			if cycle, ok := previous[path]; ok {
+				deferrals[path] = cycle
+			} else {
+				deferrals[path] = d.cycle
+			}
+		}
+	}
+	if alpha {
+		d.alpha = deferrals
+	} else {
+		d.beta = deferrals
+	}
+}
+
+// complete marks the end of the current cycle's staging. If any deferrals
+// occurred, then the staging direction order is reversed for the next cycle.
+func (d *transferDeferrals) complete() {
+	if len(d.alpha) > 0 || len(d.beta) > 0 {
+		d.betaFirst = !d.betaFirst
+	}
+	d.cycle++
+}
+
+// count returns the number of currently deferred files.
+func (d *transferDeferrals) count() uint64 {
+	return uint64(len(d.alpha) + len(d.beta))
+}
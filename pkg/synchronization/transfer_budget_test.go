@@ -0,0 +1,129 @@
+package synchronization
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestTransferDeferralsOrder tests that transferDeferrals orders staging paths
+// by deferral priority and path.
+func TestTransferDeferralsOrder(t *testing.T) {
+	// Create a tracker with deferrals recorded across two cycles.
+	deferrals := &transferDeferrals{}
+	deferrals.record(true, []string{"d"})
+	deferrals.record(false, nil)
+	deferrals.complete()
+	deferrals.record(true, []string{"b", "d"})
+	deferrals.record(false, nil)
+	deferrals.complete()
+
+	// Verify the deferral count and direction order.
+	if count := deferrals.count(); count != 2 {
+		t.Error("unexpected deferral count:", count)
+	} else if deferrals.betaFirst {
+		t.Error("staging direction order not reversed after deferrals")
+	}
+
+	// Order paths and verify that deferred paths come first (in the order of
+	// their first deferral) and that digests remain paired with paths.
+	paths := []string{"e", "a", "b", "c", "d"}
+	digests := [][]byte{{'e'}, {'a'}, {'b'}, {'c'}, {'d'}}
+	deferrals.order(true, paths, digests)
+	expected := []string{"d", "b", "a", "c", "e"}
+	for p, path := range paths {
+		if path != expected[p] {
+			t.Errorf("path at index %d (%s) does not match expected (%s)", p, path, expected[p])
+		} else if string(digests[p]) != path {
+			t.Errorf("digest at index %d not paired with path", p)
+		}
+	}
+
+	// Verify that beta ordering is unaffected by alpha deferrals.
+	deferrals.order(false, paths, digests)
+	for p, path := range paths {
+		if expected := string(rune('a' + p)); path != expected {
+			t.Errorf("beta path at index %d (%s) does not match expected (%s)", p, path, expected)
+		}
+	}
+
+	// Verify that paths are forgotten once they're no longer deferred.
+	deferrals.record(true, nil)
+	deferrals.complete()
+	if count := deferrals.count(); count != 0 {
+		t.Error("deferrals not cleared:", count)
+	}
+}
+
+// TestControllerCycleTransferBudget tests that a change set exceeding the cycle
+// transfer budget is propagated over multiple synchronization cycles, with the
+// content transferred in each cycle remaining within the budget.
+func TestControllerCycleTransferBudget(t *testing.T) {
+	// Create content that exceeds the budget several times over.
+	const fileSize = 400
+	const budget = 1000
+	content := make(map[string][]byte)
+	var total uint64
+	for i := 0; i < 5; i++ {
+		content[fmt.Sprintf("file%d", i)] = bytes.Repeat([]byte{byte('a' + i)}, fileSize)
+		total += fileSize
+	}
+
+	// Create a controller that stages content on beta via supplying from alpha.
+	configuration := &Configuration{CycleTransferBudget: budget}
+	c, parent, alpha, beta := testControllerWithSetup(t, configuration, content, nil, nil,
+		func(_, beta *testDirectoryEndpoint) {
+			beta.supplyStaging = true
+		},
+	)
+	defer os.RemoveAll(parent)
+
+	// Wait for all content to be propagated.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		s := c.currentState()
+		if s.SuccessfulSynchronizationCycles >= 3 && s.Status == Status_Watching && s.DeferredTransfers == 0 {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("content propagation didn't complete")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Halt the controller so that endpoint records can be inspected.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.halt(ctx, controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+
+	// Verify the synchronized content.
+	for name, data := range content {
+		if synchronized, err := ioutil.ReadFile(filepath.Join(beta.root, name)); err != nil {
+			t.Errorf("unable to read synchronized file (%s): %v", name, err)
+		} else if !bytes.Equal(synchronized, data) {
+			t.Errorf("synchronized file (%s) content incorrect", name)
+		}
+	}
+
+	// Verify that content was transferred over multiple cycles and that no
+	// cycle exceeded the budget.
+	if len(alpha.supplied) < 3 {
+		t.Error("content transferred in too few cycles:", len(alpha.supplied))
+	}
+	var transferred uint64
+	for i, supplied := range alpha.supplied {
+		if supplied > budget {
+			t.Errorf("transfer in cycle %d (%d bytes) exceeded budget", i, supplied)
+		}
+		transferred += supplied
+	}
+	if transferred != total {
+		t.Error("transferred byte count incorrect:", transferred, "!=", total)
+	}
+}
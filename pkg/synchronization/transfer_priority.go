@@ -0,0 +1,82 @@
+package synchronization
+
+import (
+	"github.com/pkg/errors"
+)
+
+// IsDefault indicates whether or not the transfer priority is
+// TransferPriority_TransferPriorityDefault.
+func (p TransferPriority) IsDefault() bool {
+	return p == TransferPriority_TransferPriorityDefault
+}
+
+// UnmarshalText implements the text unmarshalling interface used when loading
+// from TOML files.
+func (p *TransferPriority) UnmarshalText(textBytes []byte) error {
+	// Convert the bytes to a string.
+	text := string(textBytes)
+
+	// Convert to a transfer priority.
+	switch text {
+	case "low":
+		*p = TransferPriority_TransferPriorityLow
+	case "normal":
+		*p = TransferPriority_TransferPriorityNormal
+	case "high":
+		*p = TransferPriority_TransferPriorityHigh
+	default:
+		return errors.Errorf("unknown transfer priority specification: %s", text)
+	}
+
+	// Success.
+	return nil
+}
+
+// Supported indicates whether or not a particular transfer priority is a
+// valid, non-default value.
+func (p TransferPriority) Supported() bool {
+	switch p {
+	case TransferPriority_TransferPriorityLow:
+		return true
+	case TransferPriority_TransferPriorityNormal:
+		return true
+	case TransferPriority_TransferPriorityHigh:
+		return true
+	default:
+		return false
+	}
+}
+
+// Description returns a human-readable description of a transfer priority.
+func (p TransferPriority) Description() string {
+	switch p {
+	case TransferPriority_TransferPriorityDefault:
+		return "Default"
+	case TransferPriority_TransferPriorityLow:
+		return "Low"
+	case TransferPriority_TransferPriorityNormal:
+		return "Normal"
+	case TransferPriority_TransferPriorityHigh:
+		return "High"
+	default:
+		return "Unknown"
+	}
+}
+
+// weight returns the scheduling weight for the transfer priority, i.e. the
+// relative share of the transfer budget that a session with the priority
+// receives when competing with other sessions. Each priority level receives
+// twice the share of the level below it. It returns 0 for unknown and default
+// priorities.
+func (p TransferPriority) weight() uint64 {
+	switch p {
+	case TransferPriority_TransferPriorityLow:
+		return 1
+	case TransferPriority_TransferPriorityNormal:
+		return 2
+	case TransferPriority_TransferPriorityHigh:
+		return 4
+	default:
+		return 0
+	}
+}
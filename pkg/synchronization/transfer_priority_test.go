@@ -0,0 +1,94 @@
+package synchronization
+
+import (
+	"testing"
+)
+
+// TestTransferPriorityUnmarshal tests that unmarshaling from a string
+// specification succeeeds for TransferPriority.
+func TestTransferPriorityUnmarshal(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		text             string
+		expectedPriority TransferPriority
+		expectFailure    bool
+	}{
+		{"", TransferPriority_TransferPriorityDefault, true},
+		{"asdf", TransferPriority_TransferPriorityDefault, true},
+		{"low", TransferPriority_TransferPriorityLow, false},
+		{"normal", TransferPriority_TransferPriorityNormal, false},
+		{"high", TransferPriority_TransferPriorityHigh, false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		var priority TransferPriority
+		if err := priority.UnmarshalText([]byte(testCase.text)); err != nil {
+			if !testCase.expectFailure {
+				t.Errorf("unable to unmarshal text (%s): %s", testCase.text, err)
+			}
+		} else if testCase.expectFailure {
+			t.Error("unmarshaling succeeded unexpectedly for text:", testCase.text)
+		} else if priority != testCase.expectedPriority {
+			t.Errorf(
+				"unmarshaled priority (%s) does not match expected (%s)",
+				priority,
+				testCase.expectedPriority,
+			)
+		}
+	}
+}
+
+// TestTransferPrioritySupported tests that TransferPriority support detection
+// works as expected.
+func TestTransferPrioritySupported(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		priority        TransferPriority
+		expectSupported bool
+	}{
+		{TransferPriority_TransferPriorityDefault, false},
+		{TransferPriority_TransferPriorityLow, true},
+		{TransferPriority_TransferPriorityNormal, true},
+		{TransferPriority_TransferPriorityHigh, true},
+		{(TransferPriority_TransferPriorityHigh + 1), false},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if supported := testCase.priority.Supported(); supported != testCase.expectSupported {
+			t.Errorf(
+				"priority support status (%t) does not match expected (%t)",
+				supported,
+				testCase.expectSupported,
+			)
+		}
+	}
+}
+
+// TestTransferPriorityDescription tests that TransferPriority description
+// generation works as expected.
+func TestTransferPriorityDescription(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		priority            TransferPriority
+		expectedDescription string
+	}{
+		{TransferPriority_TransferPriorityDefault, "Default"},
+		{TransferPriority_TransferPriorityLow, "Low"},
+		{TransferPriority_TransferPriorityNormal, "Normal"},
+		{TransferPriority_TransferPriorityHigh, "High"},
+		{(TransferPriority_TransferPriorityHigh + 1), "Unknown"},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		if description := testCase.priority.Description(); description != testCase.expectedDescription {
+			t.Errorf(
+				"priority description (%s) does not match expected (%s)",
+				description,
+				testCase.expectedDescription,
+			)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package synchronization
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// transferWeightScale is the scale factor applied to transfer sizes when
+	// computing virtual time costs. It's a multiple of all transfer priority
+	// weights, which keeps costs integral.
+	transferWeightScale = 4
+)
+
+// transferRequest represents a queued request to transfer data.
+type transferRequest struct {
+	// size is the number of bytes to be transferred.
+	size uint64
+	// start is the virtual start time of the request.
+	start uint64
+	// ready is closed when the request has been granted.
+	ready chan struct{}
+}
+
+// transferScheduler schedules data transfers from multiple sessions against a
+// shared bandwidth budget. Requests that exceed the budget are queued and
+// granted using start-time fair queueing, with each session weighted by its
+// transfer priority, so competing sessions receive shares of the budget
+// proportional to their weights. Because a session's virtual time advances as
+// its requests are granted, every queued request is eventually granted, so
+// lower-priority sessions continue to make progress. The scheduler is
+// work-conserving, so a session without competition receives the full budget.
+// It is safe for concurrent usage.
+type transferScheduler struct {
+	// rate is the bandwidth budget in bytes per second. A value of 0 indicates
+	// no limit.
+	rate uint64
+	// lock serializes access to the fields below.
+	lock sync.Mutex
+	// virtualTime is the virtual start time of the most recently granted
+	// request.
+	virtualTime uint64
+	// queue is the queue of waiting requests.
+	queue []*transferRequest
+	// available is the time at which the budget will next allow a request to
+	// be granted.
+	available time.Time
+	// dispatchPending indicates whether or not a dispatch has been scheduled
+	// for when the budget next allows a request to be granted.
+	dispatchPending bool
+}
+
+// newTransferScheduler creates a new transfer scheduler with the specified
+// bandwidth budget (in bytes per second). A budget of 0 indicates that no
+// limit should be applied.
+func newTransferScheduler(rate uint64) *transferScheduler {
+	return &transferScheduler{rate: rate}
+}
+
+var (
+	// sharedTransfersLock serializes access to sharedTransfers.
+	sharedTransfersLock sync.Mutex
+	// sharedTransfers is the process-wide transfer scheduler. It's created
+	// lazily without a bandwidth limit if not configured explicitly.
+	sharedTransfers *transferScheduler
+)
+
+// ConfigureTransferBandwidthBudget sets the bandwidth budget (in bytes per
+// second) of the process-wide transfer scheduler. A budget of 0 indicates that
+// no limit should be applied. Synchronization cycles that have already started
+// continue to use the previous scheduler.
+func ConfigureTransferBandwidthBudget(rate uint64) {
+	sharedTransfersLock.Lock()
+	sharedTransfers = newTransferScheduler(rate)
+	sharedTransfersLock.Unlock()
+}
+
+// sharedTransferScheduler returns the process-wide transfer scheduler, creating
+// it without a bandwidth limit if it hasn't been configured.
+func sharedTransferScheduler() *transferScheduler {
+	// Lock the scheduler state and defer its release.
+	sharedTransfersLock.Lock()
+	defer sharedTransfersLock.Unlock()
+
+	// Create the scheduler if necessary.
+	if sharedTransfers == nil {
+		sharedTransfers = newTransferScheduler(0)
+	}
+
+	// Done.
+	return sharedTransfers
+}
+
+// dispatch grants queued requests in order of virtual start time for as long
+// as the budget allows, scheduling a subsequent dispatch if requests remain.
+// The caller must hold the scheduler lock.
+func (s *transferScheduler) dispatch() {
+	for len(s.queue) > 0 {
+		// If the budget doesn't currently allow a grant, then schedule a
+		// dispatch for when it will.
+		now := time.Now()
+		if now.Before(s.available) {
+			if !s.dispatchPending {
+				s.dispatchPending = true
+				time.AfterFunc(s.available.Sub(now), func() {
+					s.lock.Lock()
+					s.dispatchPending = false
+					s.dispatch()
+					s.lock.Unlock()
+				})
+			}
+			return
+		}
+
+		// Identify the request with the earliest virtual start time. Ties are
+		// broken in queue order.
+		index := 0
+		for r, request := range s.queue {
+			if request.start < s.queue[index].start {
+				index = r
+			}
+		}
+		request := s.queue[index]
+		s.queue = append(s.queue[:index], s.queue[index+1:]...)
+
+		// Grant the request and charge its size against the budget. Unused
+		// budget isn't accumulated while the scheduler is idle, so grants
+		// can't burst beyond the budget.
+		s.virtualTime = request.start
+		if s.available.Before(now) {
+			s.available = now
+		}
+		s.available = s.available.Add(time.Duration(float64(request.size) / float64(s.rate) * float64(time.Second)))
+		close(request.ready)
+	}
+}
+
+// client creates a new scheduling client with the specified weight, which
+// must be non-zero. Each session should use its own client.
+func (s *transferScheduler) client(weight uint64) *transferClient {
+	return &transferClient{scheduler: s, weight: weight}
+}
+
+// transferClient is a session's handle to a transfer scheduler. It implements
+// rsync.Throttler. It is safe for concurrent usage.
+type transferClient struct {
+	// scheduler is the associated scheduler.
+	scheduler *transferScheduler
+	// weight is the client's scheduling weight.
+	weight uint64
+	// finish is the virtual finish time of the client's most recently queued
+	// request. It is guarded by the scheduler lock.
+	finish uint64
+}
+
+// Throttle implements rsync.Throttler.Throttle. It blocks until the scheduler
+// grants the transfer of the specified number of bytes or until the specified
+// context is cancelled.
+func (c *transferClient) Throttle(ctx context.Context, size uint64) error {
+	// If there's no budget or nothing to transfer, then there's nothing to
+	// schedule.
+	s := c.scheduler
+	if s.rate == 0 || size == 0 {
+		return nil
+	}
+
+	// Compute the request's virtual start time (which is the later of the
+	// scheduler's virtual time and the finish time of the client's previous
+	// request) and update the client's virtual finish time.
+	s.lock.Lock()
+	request := &transferRequest{size: size, ready: make(chan struct{})}
+	request.start = s.virtualTime
+	if c.finish > request.start {
+		request.start = c.finish
+	}
+	c.finish = request.start + size*transferWeightScale/c.weight
+
+	// Queue the request and dispatch.
+	s.queue = append(s.queue, request)
+	s.dispatch()
+	s.lock.Unlock()
+
+	// Wait for the request to be granted or for cancellation.
+	select {
+	case <-request.ready:
+		return nil
+	case <-ctx.Done():
+		// Remove the request from the queue if it hasn't been granted. If it
+		// was granted between cancellation and our acquisition of the lock,
+		// then its cost has already been charged and there's nothing to undo.
+		s.lock.Lock()
+		select {
+		case <-request.ready:
+		default:
+			for r, queued := range s.queue {
+				if queued == request {
+					s.queue = append(s.queue[:r], s.queue[r+1:]...)
+					break
+				}
+			}
+		}
+		s.lock.Unlock()
+		return errors.New("transfer scheduling cancelled")
+	}
+}
@@ -0,0 +1,182 @@
+package synchronization
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testTransferChunkSize is the chunk size used when testing transfer
+// scheduling.
+const testTransferChunkSize = 1024
+
+// testCompetingTransfers runs continuous transfers from two clients with the
+// specified priorities against a shared scheduler until the specified total
+// number of chunks has been granted, returning the number of chunks granted to
+// each client.
+func testCompetingTransfers(t *testing.T, first, second TransferPriority, total uint64) (uint64, uint64) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Create a scheduler and clients.
+	scheduler := newTransferScheduler(1024 * 1024)
+	clients := []*transferClient{
+		scheduler.client(first.weight()),
+		scheduler.client(second.weight()),
+	}
+
+	// Start transfers on each client.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	counts := make([]uint64, len(clients))
+	var granted uint64
+	var wait sync.WaitGroup
+	for c, client := range clients {
+		wait.Add(1)
+		go func(c int, client *transferClient) {
+			defer wait.Done()
+			for client.Throttle(ctx, testTransferChunkSize) == nil {
+				atomic.AddUint64(&counts[c], 1)
+				if atomic.AddUint64(&granted, 1) >= total {
+					cancel()
+				}
+			}
+		}(c, client)
+	}
+
+	// Wait for transfers to complete, bounding the wait.
+	done := make(chan struct{})
+	go func() {
+		wait.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("competing transfers did not complete")
+	}
+
+	// Done.
+	return atomic.LoadUint64(&counts[0]), atomic.LoadUint64(&counts[1])
+}
+
+// TestTransferSchedulerPrioritization tests that competing clients receive
+// shares of the budget proportional to their priorities while lower-priority
+// clients continue to make progress.
+func TestTransferSchedulerPrioritization(t *testing.T) {
+	// Set up test cases.
+	testCases := []struct {
+		higher        TransferPriority
+		lower         TransferPriority
+		expectedRatio float64
+	}{
+		{TransferPriority_TransferPriorityHigh, TransferPriority_TransferPriorityLow, 4},
+		{TransferPriority_TransferPriorityHigh, TransferPriority_TransferPriorityNormal, 2},
+		{TransferPriority_TransferPriorityNormal, TransferPriority_TransferPriorityLow, 2},
+		{TransferPriority_TransferPriorityNormal, TransferPriority_TransferPriorityNormal, 1},
+	}
+
+	// Process test cases.
+	for _, testCase := range testCases {
+		higher, lower := testCompetingTransfers(t, testCase.higher, testCase.lower, 200)
+		if lower == 0 {
+			t.Errorf("%s priority client starved by %s priority client",
+				testCase.lower.Description(), testCase.higher.Description(),
+			)
+			continue
+		}
+		ratio := float64(higher) / float64(lower)
+		if ratio < testCase.expectedRatio*0.6 || ratio > testCase.expectedRatio*1.6 {
+			t.Errorf("%s/%s throughput ratio (%d/%d) not proportional to expected (%.0f)",
+				testCase.higher.Description(), testCase.lower.Description(),
+				higher, lower, testCase.expectedRatio,
+			)
+		}
+	}
+}
+
+// TestTransferSchedulerBudget tests that an uncontended client receives the
+// full budget, but no more.
+func TestTransferSchedulerBudget(t *testing.T) {
+	// Create a scheduler with a budget of 64 chunks per second and a
+	// low-priority client.
+	scheduler := newTransferScheduler(64 * testTransferChunkSize)
+	client := scheduler.client(TransferPriority_TransferPriorityLow.weight())
+
+	// Perform 33 transfers, which should take at least half a second, since
+	// the first is granted immediately.
+	start := time.Now()
+	for i := 0; i < 33; i++ {
+		if err := client.Throttle(context.Background(), testTransferChunkSize); err != nil {
+			t.Fatal("unable to perform transfer:", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 450*time.Millisecond {
+		t.Error("transfers exceeded budget:", elapsed)
+	} else if elapsed > 5*time.Second {
+		t.Error("uncontended transfers did not receive full budget:", elapsed)
+	}
+}
+
+// TestTransferSchedulerUnlimited tests that schedulers without a budget don't
+// throttle transfers.
+func TestTransferSchedulerUnlimited(t *testing.T) {
+	client := newTransferScheduler(0).client(TransferPriority_TransferPriorityLow.weight())
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		if err := client.Throttle(context.Background(), 1024*1024); err != nil {
+			t.Fatal("unable to perform transfer:", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Error("unlimited transfers were throttled:", elapsed)
+	}
+}
+
+// TestTransferSchedulerCancellation tests that cancelling a queued transfer
+// aborts the wait and removes the request from the queue.
+func TestTransferSchedulerCancellation(t *testing.T) {
+	// Create a scheduler and exhaust its budget for the foreseeable future.
+	scheduler := newTransferScheduler(1)
+	client := scheduler.client(TransferPriority_TransferPriorityNormal.weight())
+	if err := client.Throttle(context.Background(), 3600); err != nil {
+		t.Fatal("unable to perform initial transfer:", err)
+	}
+
+	// Queue a transfer and cancel it.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := client.Throttle(ctx, 1); err == nil {
+		t.Fatal("transfer succeeded unexpectedly")
+	}
+	scheduler.lock.Lock()
+	queued := len(scheduler.queue)
+	scheduler.lock.Unlock()
+	if queued != 0 {
+		t.Error("cancelled request remains queued")
+	}
+}
+
+// TestConfigureTransferBandwidthBudget tests ConfigureTransferBandwidthBudget.
+func TestConfigureTransferBandwidthBudget(t *testing.T) {
+	// Defer restoration of the shared scheduler.
+	defer func() {
+		sharedTransfersLock.Lock()
+		sharedTransfers = nil
+		sharedTransfersLock.Unlock()
+	}()
+
+	// Configure a budget and verify that it's applied.
+	ConfigureTransferBandwidthBudget(1024)
+	if scheduler := sharedTransferScheduler(); scheduler.rate != 1024 {
+		t.Error("budget not applied:", scheduler.rate)
+	}
+
+	// Clear the budget and verify that no limit is applied.
+	ConfigureTransferBandwidthBudget(0)
+	if scheduler := sharedTransferScheduler(); scheduler.rate != 0 {
+		t.Error("budget not cleared:", scheduler.rate)
+	}
+}
@@ -0,0 +1,128 @@
+package synchronization
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/prompting"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+)
+
+// cycleUndo records the information necessary to undo the transitions applied
+// by a synchronization cycle. The previous content of files overwritten or
+// removed by the cycle is retained by the endpoints themselves.
+type cycleUndo struct {
+	// alpha are the transitions that revert the changes applied to alpha.
+	alpha []*core.Change
+	// beta are the transitions that revert the changes applied to beta.
+	beta []*core.Change
+	// recorded is the time at which the cycle completed.
+	recorded time.Time
+}
+
+// reverseTransitions computes the transitions that revert the specified
+// transitions, given their results. Conflict resolution transitions and
+// transitions that failed to apply are ignored.
+func reverseTransitions(transitions []*core.Change, results []*core.Entry) []*core.Change {
+	var reversed []*core.Change
+	for t, transition := range transitions {
+		if transition.Resolve || results[t].Equal(transition.Old) {
+			continue
+		}
+		reversed = append(reversed, &core.Change{
+			Path: transition.Path,
+			Old:  results[t],
+			New:  transition.Old,
+		})
+	}
+	return reversed
+}
+
+// newCycleUndo creates the undo record for a synchronization cycle that applied
+// the specified transitions with the specified results. It returns nil if the
+// cycle can't be undone, which is the case if undoing it would require
+// deleting or changing the type of a synchronization root.
+func newCycleUndo(αTransitions []*core.Change, αResults []*core.Entry, βTransitions []*core.Change, βResults []*core.Entry) *cycleUndo {
+	// Compute the reverse transitions.
+	undo := &cycleUndo{
+		alpha:    reverseTransitions(αTransitions, αResults),
+		beta:     reverseTransitions(βTransitions, βResults),
+		recorded: time.Now(),
+	}
+
+	// Verify that the cycle can be safely undone.
+	if len(undo.alpha) == 0 && len(undo.beta) == 0 {
+		return nil
+	} else if containsRootDeletion(undo.alpha) || containsRootDeletion(undo.beta) {
+		return nil
+	} else if containsRootTypeChange(undo.alpha) || containsRootTypeChange(undo.beta) {
+		return nil
+	}
+
+	// Success.
+	return undo
+}
+
+// available determines whether or not the undo record can still be used given
+// the specified maximum age (in seconds, with 0 indicating no limit).
+func (u *cycleUndo) available(maximumAge uint32) bool {
+	return u != nil && (maximumAge == 0 ||
+		time.Since(u.recorded) <= time.Duration(maximumAge)*time.Second)
+}
+
+// undo requests that the controller undo the changes applied by its last
+// synchronization cycle and waits for the undo operation to complete. The
+// previous content of overwritten and removed files is restored using
+// standard (and thus safe) transitions, so any content modified since the last
+// cycle won't be replaced. Since the restored content differs from the
+// session's synchronized state, it's propagated to the opposite endpoint by
+// the following cycle. The provided context (which must be non-nil) can
+// terminate the wait early.
+func (c *controller) undo(ctx context.Context, prompter string) error {
+	// Update status.
+	prompting.Message(prompter, fmt.Sprintf("Undoing last synchronization cycle for session %s...", c.session.Identifier))
+
+	// Lock the controller's lifecycle and defer its release.
+	c.lifecycleLock.Lock()
+	defer c.lifecycleLock.Unlock()
+
+	// Don't allow any operations if the controller is disabled.
+	if c.disabled {
+		return errors.New("controller disabled")
+	}
+
+	// Ensure that the session supports undo operations.
+	if c.session.Configuration.UndoMaximumSize == 0 {
+		return errors.New("undo support not enabled for session")
+	}
+
+	// Check if the session is paused. See flush for an explanation of why we
+	// check for a synchronization loop instead of checking paused status.
+	if c.cancel == nil {
+		return errors.New("session is paused")
+	}
+
+	// Create an undo request.
+	request := &controllerFlushRequest{
+		undo:     true,
+		response: make(chan error, 1),
+	}
+
+	// Send the request, watching for cancellation in the mean time.
+	select {
+	case c.flushRequests <- request:
+	case <-ctx.Done():
+		return errors.New("undo cancelled before request could be sent")
+	}
+
+	// Wait for a response to the request, again watching for cancellation.
+	select {
+	case err := <-request.response:
+		return err
+	case <-ctx.Done():
+		return errors.New("undo cancelled while waiting for completion")
+	}
+}
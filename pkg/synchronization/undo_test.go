@@ -0,0 +1,276 @@
+package synchronization
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/mutagen-io/mutagen/pkg/state"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/rsync"
+)
+
+// testUndoEndpoint is a testDirectoryEndpoint that retains the previous content
+// of files overwritten or removed by its most recent transition operation and
+// uses that content when staging, mimicking the undo areas of local endpoints.
+// Staged content is discarded after each transition operation so that undo
+// operations can't be satisfied by content staged for earlier cycles.
+type testUndoEndpoint struct {
+	*testDirectoryEndpoint
+	// retained maps hex-encoded digests to retained content.
+	retained map[string][]byte
+}
+
+// retain recursively retains the content of all files within the specified
+// entry.
+func (e *testUndoEndpoint) retain(path string, entry *core.Entry) {
+	if entry == nil {
+		return
+	} else if entry.Kind == core.EntryKind_Directory {
+		for name, child := range entry.Contents {
+			childPath := name
+			if path != "" {
+				childPath = path + "/" + name
+			}
+			e.retain(childPath, child)
+		}
+	} else if entry.Kind == core.EntryKind_File {
+		if content, err := ioutil.ReadFile(filepath.Join(e.root, filepath.FromSlash(path))); err == nil {
+			e.retained[fmt.Sprintf("%x", entry.Digest)] = content
+		}
+	}
+}
+
+// Stage implements Endpoint.Stage.
+func (e *testUndoEndpoint) Stage(paths []string, digests [][]byte) ([]string, []*rsync.Signature, rsync.Receiver, error) {
+	var remainingPaths []string
+	var remainingDigests [][]byte
+	for p, path := range paths {
+		if content, ok := e.retained[fmt.Sprintf("%x", digests[p])]; !ok {
+			remainingPaths = append(remainingPaths, path)
+			remainingDigests = append(remainingDigests, digests[p])
+		} else if err := ioutil.WriteFile(e.stagedPath(digests[p]), content, 0600); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return e.testDirectoryEndpoint.Stage(remainingPaths, remainingDigests)
+}
+
+// Transition implements Endpoint.Transition.
+func (e *testUndoEndpoint) Transition(ctx context.Context, transitions []*core.Change) ([]*core.Entry, []*core.Problem, bool, error) {
+	// Retain previous content.
+	e.retained = make(map[string][]byte)
+	for _, transition := range transitions {
+		e.retain(transition.Path, transition.Old)
+	}
+
+	// Perform the transition and discard staged content.
+	results, problems, missingFiles, err := e.testDirectoryEndpoint.Transition(ctx, transitions)
+	os.RemoveAll(e.staging)
+	os.Mkdir(e.staging, 0700)
+	return results, problems, missingFiles, err
+}
+
+// testUndoController creates a running controller that synchronizes the
+// specified content from an alpha directory to an empty beta directory with
+// undo support enabled (if requested). It returns the controller, the
+// temporary directory containing all test content (which the caller should
+// remove), and the alpha and beta roots.
+func testUndoController(t *testing.T, content map[string][]byte, enabled bool) (*controller, string, string, string) {
+	// Create a temporary directory to hold all test content.
+	parent, err := ioutil.TempDir("", "mutagen_undo")
+	if err != nil {
+		t.Fatal("unable to create temporary directory:", err)
+	}
+
+	// Create endpoint directories and alpha content.
+	alphaRoot := filepath.Join(parent, "alpha")
+	betaRoot := filepath.Join(parent, "beta")
+	alphaStaging := filepath.Join(parent, "alpha-staging")
+	betaStaging := filepath.Join(parent, "beta-staging")
+	for _, directory := range []string{alphaRoot, betaRoot, alphaStaging, betaStaging} {
+		if err := os.Mkdir(directory, 0700); err != nil {
+			os.RemoveAll(parent)
+			t.Fatal("unable to create directory:", err)
+		}
+	}
+	for name, data := range content {
+		if err := ioutil.WriteFile(filepath.Join(alphaRoot, name), data, 0600); err != nil {
+			os.RemoveAll(parent)
+			t.Fatal("unable to create alpha content:", err)
+		}
+	}
+
+	// Create an empty archive.
+	archivePath := filepath.Join(parent, "archive")
+	if err := encoding.MarshalAndSaveProtobuf(archivePath, &core.Archive{}); err != nil {
+		os.RemoveAll(parent)
+		t.Fatal("unable to save archive:", err)
+	}
+
+	// Create the controller.
+	configuration := &Configuration{}
+	if enabled {
+		configuration.UndoMaximumSize = 1024 * 1024
+	}
+	session := &Session{
+		Identifier:         "session",
+		Version:            Version_Version1,
+		Configuration:      configuration,
+		ConfigurationAlpha: &Configuration{},
+		ConfigurationBeta:  &Configuration{},
+	}
+	c := &controller{
+		logger:                   logging.RootLogger.Sublogger("test"),
+		sessionPath:              filepath.Join(parent, "session"),
+		archivePath:              archivePath,
+		stateLock:                state.NewTrackingLock(state.NewTracker()),
+		session:                  session,
+		mergedAlphaConfiguration: &Configuration{},
+		mergedBetaConfiguration:  &Configuration{},
+		state: &State{
+			Session: session,
+		},
+	}
+
+	// Start the synchronization loop.
+	alpha := &testUndoEndpoint{testDirectoryEndpoint: &testDirectoryEndpoint{
+		root:    alphaRoot,
+		source:  betaRoot,
+		staging: alphaStaging,
+	}}
+	beta := &testUndoEndpoint{testDirectoryEndpoint: &testDirectoryEndpoint{
+		root:    betaRoot,
+		source:  alphaRoot,
+		staging: betaStaging,
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopCtx, stop := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.stop = stop
+	c.flushRequests = make(chan *controllerFlushRequest, 1)
+	c.done = make(chan struct{})
+	go c.run(ctx, stopCtx, alpha, beta, nil)
+
+	// Done.
+	return c, parent, alphaRoot, betaRoot
+}
+
+// verifyUndoContent verifies that the specified file in the specified root has
+// the specified content.
+func verifyUndoContent(t *testing.T, root, name, expected string) {
+	// Mark this as a helper function.
+	t.Helper()
+
+	// Verify the content.
+	if content, err := ioutil.ReadFile(filepath.Join(root, name)); err != nil {
+		t.Error("unable to read file:", err)
+	} else if string(content) != expected {
+		t.Errorf("file content incorrect: %q != %q", content, expected)
+	}
+}
+
+// TestControllerUndo tests that undoing the last synchronization cycle restores
+// the previous content on the endpoint that it modified and that the restored
+// content is then propagated to the opposite endpoint.
+func TestControllerUndo(t *testing.T) {
+	// Create a controller and wait for it to complete its initial cycle.
+	content := map[string][]byte{
+		"modified": []byte("original"),
+		"removed":  []byte("removed content"),
+	}
+	c, parent, alphaRoot, betaRoot := testUndoController(t, content, true)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+
+	// Modify content on alpha, add new content, and force a synchronization
+	// cycle to propagate the changes.
+	if err := ioutil.WriteFile(filepath.Join(alphaRoot, "modified"), []byte("updated"), 0600); err != nil {
+		t.Fatal("unable to modify file:", err)
+	} else if err = os.Remove(filepath.Join(alphaRoot, "removed")); err != nil {
+		t.Fatal("unable to remove file:", err)
+	} else if err = ioutil.WriteFile(filepath.Join(alphaRoot, "created"), []byte("new"), 0600); err != nil {
+		t.Fatal("unable to create file:", err)
+	}
+	if err := c.flush(context.Background(), "", false, nil, false); err != nil {
+		t.Fatal("flush failed:", err)
+	}
+	verifyUndoContent(t, betaRoot, "modified", "updated")
+
+	// Undo the cycle and wait for the restored content to be propagated.
+	if err := c.undo(context.Background(), ""); err != nil {
+		t.Fatal("undo failed:", err)
+	}
+	waitForSynchronizationCycles(t, c, 4)
+
+	// Verify that the previous state was restored on both endpoints.
+	for _, root := range []string{alphaRoot, betaRoot} {
+		verifyUndoContent(t, root, "modified", "original")
+		verifyUndoContent(t, root, "removed", "removed content")
+		if _, err := os.Lstat(filepath.Join(root, "created")); !os.IsNotExist(err) {
+			t.Error("created file not removed:", err)
+		}
+	}
+
+	// Halt the session.
+	if err := c.halt(context.Background(), controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+}
+
+// TestControllerUndoUnavailable tests that undo operations fail if undo support
+// is disabled or if there's no synchronization cycle to undo.
+func TestControllerUndoUnavailable(t *testing.T) {
+	// Verify that undo operations fail if undo support is disabled.
+	c, parent, _, _ := testUndoController(t, testShutdownContent, false)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+	if err := c.undo(context.Background(), ""); err == nil {
+		t.Error("undo succeeded with undo support disabled")
+	}
+	if err := c.halt(context.Background(), controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+
+	// Verify that undo operations fail if no cycle has applied changes.
+	c, parent, _, _ = testUndoController(t, nil, true)
+	defer os.RemoveAll(parent)
+	waitForSynchronizationCycles(t, c, 1)
+	if err := c.undo(context.Background(), ""); err == nil {
+		t.Error("undo succeeded without an undoable cycle")
+	}
+	if err := c.halt(context.Background(), controllerHaltModeShutdown, "", false); err != nil {
+		t.Fatal("shutdown failed:", err)
+	}
+}
+
+// TestNewCycleUndo tests that cycles whose undo operations would delete a
+// synchronization root aren't considered undoable.
+func TestNewCycleUndo(t *testing.T) {
+	// Create a cycle that created the synchronization root.
+	root := &core.Entry{Kind: core.EntryKind_Directory}
+	transitions := []*core.Change{{New: root}}
+	if newCycleUndo(nil, nil, transitions, []*core.Entry{root}) != nil {
+		t.Error("root creation considered undoable")
+	}
+
+	// Create a cycle that created a file within the synchronization root.
+	file := &core.Entry{Kind: core.EntryKind_File, Digest: []byte{1}}
+	transitions = []*core.Change{{Path: "file", New: file}}
+	undo := newCycleUndo(nil, nil, transitions, []*core.Entry{file})
+	if undo == nil {
+		t.Fatal("file creation not considered undoable")
+	} else if len(undo.beta) != 1 || undo.beta[0].Old != file || undo.beta[0].New != nil {
+		t.Error("reverse transition incorrect")
+	}
+
+	// Verify that failed transitions are ignored.
+	if newCycleUndo(nil, nil, transitions, []*core.Entry{nil}) != nil {
+		t.Error("failed transition considered undoable")
+	}
+}
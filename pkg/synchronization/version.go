@@ -93,6 +93,116 @@ func (v Version) DefaultStageMode() StageMode {
 	}
 }
 
+// DefaultContentStoreMode returns the default content store mode for the
+// session version.
+func (v Version) DefaultContentStoreMode() ContentStoreMode {
+	switch v {
+	case Version_Version1:
+		return ContentStoreMode_ContentStoreModeDisabled
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
+// DefaultHostVerificationMode returns the default host verification mode for
+// the session version.
+func (v Version) DefaultHostVerificationMode() HostVerificationMode {
+	switch v {
+	case Version_Version1:
+		return HostVerificationMode_HostVerificationModeStrict
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
+// DefaultACLMode returns the default ACL mode for the session version.
+func (v Version) DefaultACLMode() core.ACLMode {
+	switch v {
+	case Version_Version1:
+		return core.ACLMode_ACLModeIgnore
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
+// DefaultDurabilityMode returns the default durability mode for the session
+// version.
+func (v Version) DefaultDurabilityMode() core.DurabilityMode {
+	switch v {
+	case Version_Version1:
+		return core.DurabilityMode_DurabilityModeFull
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
+// DefaultModificationHandlingMode returns the default modification handling
+// mode for the session version.
+func (v Version) DefaultModificationHandlingMode() ModificationHandlingMode {
+	switch v {
+	case Version_Version1:
+		return ModificationHandlingMode_ModificationHandlingModeDefer
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
+// DefaultTransferPriority returns the default transfer priority for the
+// session version.
+func (v Version) DefaultTransferPriority() TransferPriority {
+	switch v {
+	case Version_Version1:
+		return TransferPriority_TransferPriorityNormal
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
+// DefaultDeltaTransferMode returns the default delta transfer mode for the
+// session version.
+func (v Version) DefaultDeltaTransferMode() DeltaTransferMode {
+	switch v {
+	case Version_Version1:
+		return DeltaTransferMode_DeltaTransferModeAuto
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
+// DefaultCompressionThreshold returns the default minimum message size (in
+// bytes) for which Mutagen-layer compression is performed for the session
+// version.
+func (v Version) DefaultCompressionThreshold() uint64 {
+	switch v {
+	case Version_Version1:
+		return 512
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
+// DefaultScanConcurrency returns the default maximum number of files whose
+// digests are computed concurrently when scanning for the session version.
+func (v Version) DefaultScanConcurrency() uint32 {
+	switch v {
+	case Version_Version1:
+		return 1
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
+// DefaultStagingConcurrency returns the default maximum number of files read
+// concurrently when preparing to stage content for the session version.
+func (v Version) DefaultStagingConcurrency() uint32 {
+	switch v {
+	case Version_Version1:
+		return 1
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
 // DefaultSymlinkMode returns the default symlink mode for the session version.
 func (v Version) DefaultSymlinkMode() core.SymlinkMode {
 	switch v {
@@ -103,6 +213,50 @@ func (v Version) DefaultSymlinkMode() core.SymlinkMode {
 	}
 }
 
+// DefaultBrokenSymlinkMode returns the default broken symlink mode for the
+// session version.
+func (v Version) DefaultBrokenSymlinkMode() core.BrokenSymlinkMode {
+	switch v {
+	case Version_Version1:
+		return core.BrokenSymlinkMode_BrokenSymlinkModeSync
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
+// DefaultInvalidNameMode returns the default invalid name mode for the session
+// version.
+func (v Version) DefaultInvalidNameMode() core.InvalidNameMode {
+	switch v {
+	case Version_Version1:
+		return core.InvalidNameMode_InvalidNameModeSkip
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
+// DefaultLongPathMode returns the default long path mode for the session
+// version.
+func (v Version) DefaultLongPathMode() core.LongPathMode {
+	switch v {
+	case Version_Version1:
+		return core.LongPathMode_LongPathModeExtended
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
+// DefaultArchiveCompressionMode returns the default archive compression mode
+// for the session version.
+func (v Version) DefaultArchiveCompressionMode() ArchiveCompressionMode {
+	switch v {
+	case Version_Version1:
+		return ArchiveCompressionMode_ArchiveCompressionModeNone
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
 // DefaultWatchMode returns the default watch mode for the session version.
 func (v Version) DefaultWatchMode() WatchMode {
 	switch v {
@@ -124,6 +278,17 @@ func (v Version) DefaultWatchPollingInterval() uint32 {
 	}
 }
 
+// DefaultConflictResolverTimeout returns the default conflict resolver timeout
+// (in seconds) for the session version.
+func (v Version) DefaultConflictResolverTimeout() uint32 {
+	switch v {
+	case Version_Version1:
+		return 30
+	default:
+		panic("unknown or unsupported session version")
+	}
+}
+
 // DefaultIgnoreVCSMode returns the default VCS ignore mode for the session
 // version.
 func (v Version) DefaultIgnoreVCSMode() core.IgnoreVCSMode {
@@ -14,6 +14,8 @@ func (u *URL) Format(environmentPrefix string) string {
 		return u.formatTunnel()
 	} else if u.Protocol == Protocol_Docker {
 		return u.formatDocker(environmentPrefix)
+	} else if u.Protocol == Protocol_WSL {
+		return u.formatWSL()
 	}
 	panic("unknown URL protocol")
 }
@@ -133,3 +135,43 @@ func (u *URL) formatDocker(environmentPrefix string) string {
 	// Done.
 	return result
 }
+
+// invalidWSLURLFormat is the value returned by formatWSL when a URL is provided
+// that breaks invariants.
+const invalidWSLURLFormat = "<invalid-wsl-url>"
+
+// formatWSL formats a WSL URL.
+func (u *URL) formatWSL() string {
+	// Start with the distribution name.
+	result := u.Host
+
+	// Add username if present.
+	if u.User != "" {
+		result = fmt.Sprintf("%s@%s", u.User, result)
+	}
+
+	// Append the path in a manner that depends on the URL kind.
+	if u.Kind == Kind_Synchronization {
+		// If this is a home-directory-relative path or a Windows path, then we
+		// need to prepend a slash.
+		if u.Path == "" {
+			return invalidWSLURLFormat
+		} else if u.Path[0] == '/' {
+			result += u.Path
+		} else if u.Path[0] == '~' || isWindowsPath(u.Path) {
+			result += fmt.Sprintf("/%s", u.Path)
+		} else {
+			return invalidWSLURLFormat
+		}
+	} else if u.Kind == Kind_Forwarding {
+		result += fmt.Sprintf(":%s", u.Path)
+	} else {
+		panic("unhandled URL kind")
+	}
+
+	// Add the scheme.
+	result = wslURLPrefix + result
+
+	// Done.
+	return result
+}
@@ -327,3 +327,53 @@ func TestFormatDockerWithWindowsPathPath(t *testing.T) {
 	}
 	test.run(t)
 }
+
+func TestFormatWSLInvalidBadFirstPathCharacter(t *testing.T) {
+	test := &formatTestCase{
+		url: &URL{
+			Protocol: Protocol_WSL,
+			Host:     "Ubuntu",
+			Path:     "$5",
+		},
+		expected: invalidWSLURLFormat,
+	}
+	test.run(t)
+}
+
+func TestFormatWSLWithUsernameAndHomeRelativePath(t *testing.T) {
+	test := &formatTestCase{
+		url: &URL{
+			Protocol: Protocol_WSL,
+			User:     "user",
+			Host:     "Ubuntu",
+			Path:     "~/test/path/to/the file",
+		},
+		expected: "wsl://user@Ubuntu/~/test/path/to/the file",
+	}
+	test.run(t)
+}
+
+func TestFormatWSLWithWindowsPath(t *testing.T) {
+	test := &formatTestCase{
+		url: &URL{
+			Protocol: Protocol_WSL,
+			Host:     "Ubuntu",
+			Path:     `C:\A\Windows\File Path `,
+		},
+		expected: `wsl://Ubuntu/C:\A\Windows\File Path `,
+	}
+	test.run(t)
+}
+
+func TestFormatForwardingWSL(t *testing.T) {
+	test := &formatTestCase{
+		url: &URL{
+			Kind:     Kind_Forwarding,
+			Protocol: Protocol_WSL,
+			Host:     "Ubuntu",
+			Path:     "tcp4:localhost:8080",
+		},
+		expected: "wsl://Ubuntu:tcp4:localhost:8080",
+	}
+	test.run(t)
+}
@@ -28,6 +28,8 @@ func Parse(raw string, kind Kind, first bool) (*URL, error) {
 		return parseTunnel(raw, kind)
 	} else if isDockerURL(raw) {
 		return parseDocker(raw, kind, first)
+	} else if isWSLURL(raw) {
+		return parseWSL(raw, kind)
 	} else if isSCPSSHURL(raw, kind) {
 		return parseSCPSSH(raw, kind)
 	} else {
@@ -21,6 +21,34 @@ var DockerEnvironmentVariables = []string{
 	"DOCKER_API_VERSION",
 }
 
+// DockerLabelSelectorPrefix is the prefix that identifies the container
+// component of a Docker synchronization URL as a label selector (e.g.
+// docker://label:app=web/code) rather than a container name or identifier. A
+// label selector consists of one or more comma-separated label filters (each
+// of the form key or key=value), all of which must match. Since ':' can't
+// appear in container names, the prefix is unambiguous.
+const DockerLabelSelectorPrefix = "label:"
+
+// DockerLabelSelector returns the label selector specified by a Docker URL, if
+// any.
+func (u *URL) DockerLabelSelector() (string, bool) {
+	if u.Protocol != Protocol_Docker || !strings.HasPrefix(u.Host, DockerLabelSelectorPrefix) {
+		return "", false
+	}
+	return u.Host[len(DockerLabelSelectorPrefix):], true
+}
+
+// ensureDockerLabelSelectorValid ensures that a Docker label selector consists
+// of non-empty label filters.
+func ensureDockerLabelSelectorValid(selector string) error {
+	for _, filter := range strings.Split(selector, ",") {
+		if filter == "" || strings.HasPrefix(filter, "=") {
+			return errors.New("empty label filter")
+		}
+	}
+	return nil
+}
+
 // isDockerURL checks whether or not a URL is a Docker URL. It requires the
 // presence of a Docker protocol prefix.
 func isDockerURL(raw string) bool {
@@ -77,7 +105,14 @@ func parseDocker(raw string, kind Kind, first bool) (*URL, error) {
 	}
 	if container == "" {
 		return nil, errors.New("empty container name")
-	} else if path == "" {
+	} else if strings.HasPrefix(container, DockerLabelSelectorPrefix) {
+		// This can only occur for synchronization URLs, since ':' is the split
+		// character for forwarding URLs.
+		if err := ensureDockerLabelSelectorValid(container[len(DockerLabelSelectorPrefix):]); err != nil {
+			return nil, errors.Wrap(err, "invalid label selector")
+		}
+	}
+	if path == "" {
 		if kind == Kind_Synchronization {
 			return nil, errors.New("missing path")
 		} else if kind == Kind_Forwarding {
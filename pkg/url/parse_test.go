@@ -659,6 +659,39 @@ func TestParseDockerWithBetaSpecificVariables(t *testing.T) {
 	test.run(t)
 }
 
+func TestParseDockerLabelSelector(t *testing.T) {
+	test := parseTestCase{
+		raw:  "docker://label:app=web,tier/code",
+		fail: false,
+		expected: &URL{
+			Protocol: Protocol_Docker,
+			Host:     "label:app=web,tier",
+			Path:     "/code",
+			Environment: map[string]string{
+				"DOCKER_HOST":       defaultDockerHost,
+				"DOCKER_TLS_VERIFY": betaSpecificDockerTLSVerify,
+			},
+		},
+	}
+	test.run(t)
+}
+
+func TestParseDockerEmptyLabelSelector(t *testing.T) {
+	test := parseTestCase{
+		raw:  "docker://label:/code",
+		fail: true,
+	}
+	test.run(t)
+}
+
+func TestParseDockerLabelSelectorEmptyFilter(t *testing.T) {
+	test := parseTestCase{
+		raw:  "docker://label:app=web,,tier/code",
+		fail: true,
+	}
+	test.run(t)
+}
+
 func TestParseDockerWithWindowsPathAndAlphaSpecificVariables(t *testing.T) {
 	test := parseTestCase{
 		raw:   `docker://cøntainer/C:\пат/to\the file`,
@@ -714,3 +747,75 @@ func TestParseDockerWithUsernameUserRelativePathAndAlphaSpecificVariables(t *tes
 	}
 	test.run(t)
 }
+
+func TestParseWSLEmptyDistributionInvalid(t *testing.T) {
+	test := parseTestCase{
+		raw:  "wsl:///path",
+		fail: true,
+	}
+	test.run(t)
+}
+
+func TestParseWSLMissingPathInvalid(t *testing.T) {
+	test := parseTestCase{
+		raw:  "wsl://Ubuntu",
+		fail: true,
+	}
+	test.run(t)
+}
+
+func TestParseWSL(t *testing.T) {
+	test := parseTestCase{
+		raw:  "wsl://Ubuntu-20.04/пат/to/the file",
+		fail: false,
+		expected: &URL{
+			Protocol: Protocol_WSL,
+			Host:     "Ubuntu-20.04",
+			Path:     "/пат/to/the file",
+		},
+	}
+	test.run(t)
+}
+
+func TestParseWSLWithUsernameHomeRelativePath(t *testing.T) {
+	test := parseTestCase{
+		raw:  "wsl://üsér@Ubuntu/~/пат/to/the file",
+		fail: false,
+		expected: &URL{
+			Protocol: Protocol_WSL,
+			User:     "üsér",
+			Host:     "Ubuntu",
+			Path:     "~/пат/to/the file",
+		},
+	}
+	test.run(t)
+}
+
+func TestParseWSLWithWindowsPath(t *testing.T) {
+	test := parseTestCase{
+		raw:  `wsl://Ubuntu/C:\пат/to\the file`,
+		fail: false,
+		expected: &URL{
+			Protocol: Protocol_WSL,
+			Host:     "Ubuntu",
+			Path:     `C:\пат/to\the file`,
+		},
+	}
+	test.run(t)
+}
+
+func TestParseForwardingWSL(t *testing.T) {
+	test := parseTestCase{
+		raw:  "wsl://user@Ubuntu:tcp:localhost:8080",
+		kind: Kind_Forwarding,
+		fail: false,
+		expected: &URL{
+			Kind:     Kind_Forwarding,
+			Protocol: Protocol_WSL,
+			User:     "user",
+			Host:     "Ubuntu",
+			Path:     "tcp:localhost:8080",
+		},
+	}
+	test.run(t)
+}
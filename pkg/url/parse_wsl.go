@@ -0,0 +1,112 @@
+package url
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/url/forwarding"
+)
+
+// wslURLPrefix is the lowercase version of the WSL URL prefix.
+const wslURLPrefix = "wsl://"
+
+// isWSLURL checks whether or not a URL is a WSL URL. It requires the presence of
+// a WSL protocol prefix.
+func isWSLURL(raw string) bool {
+	return strings.HasPrefix(strings.ToLower(raw), wslURLPrefix)
+}
+
+// parseWSL parses a WSL URL.
+func parseWSL(raw string, kind Kind) (*URL, error) {
+	// Strip off the prefix.
+	raw = raw[len(wslURLPrefix):]
+
+	// Determine the character that splits the distribution name from the path
+	// or forwarding endpoint component.
+	var splitCharacter rune
+	if kind == Kind_Synchronization {
+		splitCharacter = '/'
+	} else if kind == Kind_Forwarding {
+		splitCharacter = ':'
+	} else {
+		panic("unhandled URL kind")
+	}
+
+	// Parse off the username. If we hit the split character, then we've
+	// reached the end of the distribution specification and there was no
+	// username. Similarly, if we hit the end of the string without seeing an
+	// '@', then there's also no username specified.
+	var username string
+	for i, r := range raw {
+		if r == splitCharacter {
+			break
+		} else if r == '@' {
+			username = raw[:i]
+			raw = raw[i+1:]
+			break
+		}
+	}
+
+	// Split what remains into the distribution and the path (or forwarding
+	// endpoint, depending on the URL kind). As with Docker container names, we
+	// leave validation of distribution names to wsl.exe.
+	var distribution, path string
+	for i, r := range raw {
+		if r == splitCharacter {
+			distribution = raw[:i]
+			path = raw[i:]
+			break
+		}
+	}
+	if distribution == "" {
+		return nil, errors.New("empty distribution name")
+	} else if path == "" {
+		if kind == Kind_Synchronization {
+			return nil, errors.New("missing path")
+		} else if kind == Kind_Forwarding {
+			return nil, errors.New("missing forwarding endpoint")
+		} else {
+			panic("unhandled URL kind")
+		}
+	}
+
+	// Perform path processing based on URL kind.
+	if kind == Kind_Synchronization {
+		// If the path starts with "/~", then we assume that it's supposed to be
+		// a home-directory-relative path and remove the slash. At this point we
+		// already know that the path starts with "/" since we retained that as
+		// part of the path in the split operation above.
+		if len(path) > 1 && path[1] == '~' {
+			path = path[1:]
+		}
+
+		// If the path is of the form "/" + Windows path, then assume it's
+		// supposed to be a Windows path. These paths are stored as specified
+		// and translated to the distribution's mount of the corresponding drive
+		// when connecting.
+		if isWindowsPath(path[1:]) {
+			path = path[1:]
+		}
+	} else if kind == Kind_Forwarding {
+		// For forwarding paths, we need to trim the split character at the
+		// beginning.
+		path = path[1:]
+
+		// Parse the forwarding endpoint URL to ensure that it's valid.
+		if _, _, err := forwarding.Parse(path); err != nil {
+			return nil, errors.Wrap(err, "invalid forwarding endpoint URL")
+		}
+	} else {
+		panic("unhandled URL kind")
+	}
+
+	// Success.
+	return &URL{
+		Kind:     kind,
+		Protocol: Protocol_WSL,
+		User:     username,
+		Host:     distribution,
+		Path:     path,
+	}, nil
+}
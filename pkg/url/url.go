@@ -111,6 +111,21 @@ func (u *URL) EnsureValid() error {
 		} else if u.Port != 0 {
 			return errors.New("Docker URL with non-zero port")
 		}
+		if selector, ok := u.DockerLabelSelector(); ok {
+			if u.Kind != Kind_Synchronization {
+				return errors.New("non-synchronization Docker URL with label selector")
+			} else if err := ensureDockerLabelSelectorValid(selector); err != nil {
+				return errors.Wrap(err, "Docker URL with invalid label selector")
+			}
+		}
+	} else if u.Protocol == Protocol_WSL {
+		if u.Host == "" {
+			return errors.New("WSL URL with empty distribution name")
+		} else if u.Port != 0 {
+			return errors.New("WSL URL with non-zero port")
+		} else if len(u.Environment) != 0 {
+			return errors.New("WSL URL with environment variables")
+		}
 	} else {
 		return errors.New("unknown or unsupported protocol")
 	}
@@ -127,9 +142,9 @@ func (u *URL) EnsureValid() error {
 			return errors.New("local URL with relative path")
 		}
 
-		// If this is a tunnel or Docker URL, we can actually do a bit of
+		// If this is a tunnel, Docker, or WSL URL, we can actually do a bit of
 		// additional validation.
-		if u.Protocol == Protocol_Tunnel || u.Protocol == Protocol_Docker {
+		if u.Protocol == Protocol_Tunnel || u.Protocol == Protocol_Docker || u.Protocol == Protocol_WSL {
 			if !(u.Path[0] == '/' || u.Path[0] == '~' || isWindowsPath(u.Path)) {
 				return errors.New("incorrect first path character")
 			}
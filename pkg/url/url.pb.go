@@ -84,6 +84,9 @@ const (
 	Protocol_SSH Protocol = 1
 	// Tunnel indicates that the resource is available via a mutagen.io tunnel.
 	Protocol_Tunnel Protocol = 3
+	// WSL indicates that the resource is inside a Windows Subsystem for Linux
+	// distribution.
+	Protocol_WSL Protocol = 4
 	// Docker indicates that the resource is inside a Docker container.
 	Protocol_Docker Protocol = 11
 )
@@ -94,12 +97,14 @@ var (
 		0:  "Local",
 		1:  "SSH",
 		3:  "Tunnel",
+		4:  "WSL",
 		11: "Docker",
 	}
 	Protocol_value = map[string]int32{
 		"Local":  0,
 		"SSH":    1,
 		"Tunnel": 3,
+		"WSL":    4,
 		"Docker": 11,
 	}
 )
@@ -284,14 +289,14 @@ var file_url_url_proto_rawDesc = []byte{
 	0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x2a, 0x2b, 0x0a, 0x04,
 	0x4b, 0x69, 0x6e, 0x64, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e,
 	0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x46, 0x6f, 0x72,
-	0x77, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x10, 0x01, 0x2a, 0x36, 0x0a, 0x08, 0x50, 0x72, 0x6f,
+	0x77, 0x61, 0x72, 0x64, 0x69, 0x6e, 0x67, 0x10, 0x01, 0x2a, 0x3f, 0x0a, 0x08, 0x50, 0x72, 0x6f,
 	0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x09, 0x0a, 0x05, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x10, 0x00,
 	0x12, 0x07, 0x0a, 0x03, 0x53, 0x53, 0x48, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x54, 0x75, 0x6e,
-	0x6e, 0x65, 0x6c, 0x10, 0x03, 0x12, 0x0a, 0x0a, 0x06, 0x44, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x10,
-	0x0b, 0x42, 0x27, 0x5a, 0x25, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
-	0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e, 0x2d, 0x69, 0x6f, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67,
-	0x65, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x75, 0x72, 0x6c, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+	0x6e, 0x65, 0x6c, 0x10, 0x03, 0x12, 0x07, 0x0a, 0x03, 0x57, 0x53, 0x4c, 0x10, 0x04, 0x12, 0x0a,
+	0x0a, 0x06, 0x44, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x10, 0x0b, 0x42, 0x27, 0x5a, 0x25, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e,
+	0x2d, 0x69, 0x6f, 0x2f, 0x6d, 0x75, 0x74, 0x61, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x6b, 0x67, 0x2f,
+	0x75, 0x72, 0x6c, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
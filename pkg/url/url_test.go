@@ -393,6 +393,40 @@ func TestURLEnsureValidDockerWindowsPath(t *testing.T) {
 	}
 }
 
+func TestURLEnsureValidDockerLabelSelector(t *testing.T) {
+	valid := &URL{
+		Protocol: Protocol_Docker,
+		Host:     "label:app=web",
+		Path:     "/path",
+	}
+	if err := valid.EnsureValid(); err != nil {
+		t.Error("valid URL classified as invalid")
+	}
+}
+
+func TestURLEnsureValidDockerEmptyLabelSelectorInvalid(t *testing.T) {
+	invalid := &URL{
+		Protocol: Protocol_Docker,
+		Host:     "label:",
+		Path:     "/path",
+	}
+	if invalid.EnsureValid() == nil {
+		t.Error("invalid URL classified as valid")
+	}
+}
+
+func TestURLEnsureValidDockerForwardingLabelSelectorInvalid(t *testing.T) {
+	invalid := &URL{
+		Kind:     Kind_Forwarding,
+		Protocol: Protocol_Docker,
+		Host:     "label:app=web",
+		Path:     "tcp:localhost:8080",
+	}
+	if invalid.EnsureValid() == nil {
+		t.Error("invalid URL classified as valid")
+	}
+}
+
 func TestURLEnsureValidDocker(t *testing.T) {
 	valid := &URL{
 		Protocol: Protocol_Docker,
@@ -0,0 +1,3 @@
+// Package wsl provides utility functions for interfacing with the Windows
+// Subsystem for Linux.
+package wsl
@@ -0,0 +1,116 @@
+package wsl
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// AutomountRoot is the directory under which WSL mounts Windows drives
+	// inside distributions by default (e.g. C: is mounted at /mnt/c).
+	AutomountRoot = "/mnt/"
+	// networkShareHost is the host name under which WSL distributions' file
+	// systems are exposed to Windows as network shares.
+	networkShareHost = "wsl$"
+	// alternateNetworkShareHost is the host name under which newer versions of
+	// Windows also expose WSL distributions' file systems.
+	alternateNetworkShareHost = "wsl.localhost"
+)
+
+// isDriveLetter returns whether or not the specified byte is an ASCII letter.
+func isDriveLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// isWindowsSeparator returns whether or not the specified byte is a Windows
+// path separator.
+func isWindowsSeparator(b byte) bool {
+	return b == '\\' || b == '/'
+}
+
+// WindowsPathToLinux translates a Windows path to the corresponding path inside
+// the specified WSL distribution. Drive paths (e.g. C:\Users\me) are translated
+// to their automount locations (e.g. /mnt/c/Users/me) and network share paths
+// referencing the distribution's own file system (e.g. \\wsl$\distro\home\me)
+// are translated to the paths that they reference (e.g. /home/me). Paths that
+// are already Linux paths (i.e. absolute or home-directory-relative POSIX paths)
+// are returned unmodified. Any other path results in an error.
+func WindowsPathToLinux(distribution, path string) (string, error) {
+	// Pass through Linux paths.
+	if strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "//") {
+		return path, nil
+	} else if strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+
+	// Handle drive paths. We only accept absolute drive paths, since relative
+	// drive paths (e.g. C:path) depend on process state.
+	if len(path) >= 2 && isDriveLetter(path[0]) && path[1] == ':' {
+		if len(path) > 2 && !isWindowsSeparator(path[2]) {
+			return "", errors.New("drive-relative paths not supported")
+		}
+		result := AutomountRoot + strings.ToLower(path[:1])
+		if remaining := strings.Trim(strings.ReplaceAll(path[2:], "\\", "/"), "/"); remaining != "" {
+			result += "/" + remaining
+		}
+		return result, nil
+	}
+
+	// Handle network share paths.
+	if len(path) >= 2 && isWindowsSeparator(path[0]) && isWindowsSeparator(path[1]) {
+		components := strings.FieldsFunc(path[2:], func(r rune) bool {
+			return r == '\\' || r == '/'
+		})
+		if len(components) < 2 {
+			return "", errors.New("incomplete network share path")
+		}
+		host := strings.ToLower(components[0])
+		if host != networkShareHost && host != alternateNetworkShareHost {
+			return "", errors.New("network share path does not reference WSL")
+		} else if !strings.EqualFold(components[1], distribution) {
+			return "", errors.New("network share path references another distribution")
+		}
+		return "/" + strings.Join(components[2:], "/"), nil
+	}
+
+	// Reject anything else.
+	return "", errors.New("unsupported path format")
+}
+
+// LinuxPathToWindows translates an absolute path inside the specified WSL
+// distribution to the corresponding Windows path. Paths within automounted
+// drives (e.g. /mnt/c/Users/me) are translated to drive paths (e.g.
+// C:\Users\me) and all other paths (e.g. /home/me) are translated to network
+// share paths referencing the distribution's file system (e.g.
+// \\wsl$\distro\home\me).
+func LinuxPathToWindows(distribution, path string) (string, error) {
+	// Ensure that the path is absolute. We can't translate home-directory-
+	// relative paths without knowing the home directory.
+	if !strings.HasPrefix(path, "/") {
+		return "", errors.New("path is not absolute")
+	}
+
+	// Split the path into its components, ignoring empty components.
+	components := strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/'
+	})
+
+	// Handle paths within automounted drives. Only single-letter directories
+	// within the automount root correspond to drives.
+	automountRoot := strings.Trim(AutomountRoot, "/")
+	if len(components) >= 2 && components[0] == automountRoot &&
+		len(components[1]) == 1 && isDriveLetter(components[1][0]) {
+		return strings.ToUpper(components[1]) + ":\\" + strings.Join(components[2:], "\\"), nil
+	}
+
+	// Otherwise translate to a network share path.
+	if distribution == "" {
+		return "", errors.New("empty distribution name")
+	}
+	result := `\\` + networkShareHost + `\` + distribution
+	if len(components) > 0 {
+		result += `\` + strings.Join(components, `\`)
+	}
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package wsl
+
+import (
+	"testing"
+)
+
+// TestWindowsPathToLinux tests WindowsPathToLinux.
+func TestWindowsPathToLinux(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected string
+		fail     bool
+	}{
+		{`C:\Users\me\project`, "/mnt/c/Users/me/project", false},
+		{`c:/Users/me/project/`, "/mnt/c/Users/me/project", false},
+		{`D:\`, "/mnt/d", false},
+		{`E:`, "/mnt/e", false},
+		{`C:\Users\me\the file`, "/mnt/c/Users/me/the file", false},
+		{`\\wsl$\Ubuntu\home\me\project`, "/home/me/project", false},
+		{`\\wsl.localhost\ubuntu\home\me`, "/home/me", false},
+		{`\\wsl$\Ubuntu`, "/", false},
+		{"/home/me/project", "/home/me/project", false},
+		{"/mnt/c/Users", "/mnt/c/Users", false},
+		{"~/project", "~/project", false},
+		{`C:relative`, "", true},
+		{`\\wsl$\Debian\home\me`, "", true},
+		{`\\server\share\path`, "", true},
+		{`\\wsl$`, "", true},
+		{"relative/path", "", true},
+		{"", "", true},
+	}
+	for _, testCase := range testCases {
+		if result, err := WindowsPathToLinux("Ubuntu", testCase.path); err != nil {
+			if !testCase.fail {
+				t.Errorf("translation of %q failed unexpectedly: %v", testCase.path, err)
+			}
+		} else if testCase.fail {
+			t.Errorf("translation of %q succeeded unexpectedly", testCase.path)
+		} else if result != testCase.expected {
+			t.Errorf("translation of %q incorrect: %q != %q", testCase.path, result, testCase.expected)
+		}
+	}
+}
+
+// TestLinuxPathToWindows tests LinuxPathToWindows.
+func TestLinuxPathToWindows(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected string
+		fail     bool
+	}{
+		{"/mnt/c/Users/me/project", `C:\Users\me\project`, false},
+		{"/mnt/d", `D:\`, false},
+		{"/mnt/c//Users/me/", `C:\Users\me`, false},
+		{"/mnt/cd/project", `\\wsl$\Ubuntu\mnt\cd\project`, false},
+		{"/mnt", `\\wsl$\Ubuntu\mnt`, false},
+		{"/home/me/the file", `\\wsl$\Ubuntu\home\me\the file`, false},
+		{"/", `\\wsl$\Ubuntu`, false},
+		{"~/project", "", true},
+		{"relative/path", "", true},
+	}
+	for _, testCase := range testCases {
+		if result, err := LinuxPathToWindows("Ubuntu", testCase.path); err != nil {
+			if !testCase.fail {
+				t.Errorf("translation of %q failed unexpectedly: %v", testCase.path, err)
+			}
+		} else if testCase.fail {
+			t.Errorf("translation of %q succeeded unexpectedly", testCase.path)
+		} else if result != testCase.expected {
+			t.Errorf("translation of %q incorrect: %q != %q", testCase.path, result, testCase.expected)
+		}
+	}
+}
+
+// TestPathTranslationRoundTrip tests that drive and distribution paths survive
+// translation in both directions.
+func TestPathTranslationRoundTrip(t *testing.T) {
+	for _, path := range []string{"/mnt/c/Users/me/project", "/home/me/project", "/"} {
+		if windows, err := LinuxPathToWindows("Ubuntu", path); err != nil {
+			t.Errorf("unable to translate %q to Windows: %v", path, err)
+		} else if linux, err := WindowsPathToLinux("Ubuntu", windows); err != nil {
+			t.Errorf("unable to translate %q back to Linux: %v", windows, err)
+		} else if linux != path {
+			t.Errorf("round trip translation of %q incorrect: %q", path, linux)
+		}
+	}
+}
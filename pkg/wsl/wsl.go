@@ -0,0 +1,37 @@
+package wsl
+
+import (
+	"context"
+	"os"
+	"os/exec"
+
+	"github.com/pkg/errors"
+
+	"github.com/mutagen-io/mutagen/pkg/process"
+)
+
+// CommandPath returns the absolute path specification to use for invoking
+// wsl.exe. It will use the MUTAGEN_WSL_PATH environment variable if provided,
+// otherwise falling back to a search of the user's path.
+func CommandPath() (string, error) {
+	// If MUTAGEN_WSL_PATH is specified, then use it to perform the lookup.
+	if searchPath := os.Getenv("MUTAGEN_WSL_PATH"); searchPath != "" {
+		return process.FindCommand("wsl", []string{searchPath})
+	}
+
+	// Otherwise search the user's path.
+	return exec.LookPath("wsl")
+}
+
+// Command prepares (but does not start) a wsl.exe command with the specified
+// arguments and scoped to lifetime of the provided context.
+func Command(context context.Context, args ...string) (*exec.Cmd, error) {
+	// Identify the command path.
+	commandPath, err := CommandPath()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to identify 'wsl' command")
+	}
+
+	// Create the command.
+	return exec.CommandContext(context, commandPath, args...), nil
+}
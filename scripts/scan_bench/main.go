@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha1"
 	"fmt"
+	"hash"
 	"io/ioutil"
 	"os"
 	"os/signal"
@@ -27,7 +28,7 @@ const (
 	cacheFile    = "cache_test"
 )
 
-var usage = `scan_bench [-h|--help] [-p|--profile] [-i|--ignore=<pattern>] <path>
+var usage = `scan_bench [-h|--help] [-p|--profile] [-i|--ignore=<pattern>] [-c|--concurrency=<n>] <path>
 `
 
 // ignoreCachesIntersectionEqual compares two ignore caches, ensuring that keys
@@ -59,8 +60,10 @@ func main() {
 	flagSet.SetOutput(ioutil.Discard)
 	var ignores []string
 	var enableProfile bool
+	var concurrency uint
 	flagSet.StringSliceVarP(&ignores, "ignore", "i", nil, "specify ignore paths")
 	flagSet.BoolVarP(&enableProfile, "profile", "p", false, "enable profiling")
+	flagSet.UintVarP(&concurrency, "concurrency", "c", 1, "specify digest concurrency")
 	if err := flagSet.Parse(os.Args[1:]); err != nil {
 		if err == pflag.ErrHelp {
 			fmt.Fprint(os.Stdout, usage)
@@ -75,6 +78,15 @@ func main() {
 	}
 	path := arguments[0]
 
+	// Create hashers for concurrent digest computation if necessary.
+	var digestHashers []hash.Hash
+	if concurrency > 1 {
+		digestHashers = make([]hash.Hash, concurrency)
+		for h := range digestHashers {
+			digestHashers[h] = sha1.New()
+		}
+	}
+
 	// Create a context for the scan. The main reason for using a custom context
 	// instead of using context.Background() is that the latter provides a
 	// context that returns a nil result from Done(). To ensure that we fully
@@ -105,7 +117,7 @@ func main() {
 		}
 	}
 	start := time.Now()
-	snapshot, preservesExecutability, decomposesUnicode, cache, ignoreCache, err := core.Scan(
+	snapshot, preservesExecutability, decomposesUnicode, cache, ignoreCache, _, err := core.Scan(
 		ctx,
 		path,
 		nil,
@@ -116,6 +128,11 @@ func main() {
 		nil,
 		behavior.ProbeMode_ProbeModeProbe,
 		core.SymlinkMode_SymlinkModePortable,
+		&core.ScanOptions{
+			BrokenSymlinkMode: core.BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           core.ACLMode_ACLModeIgnore,
+			DigestHashers:     digestHashers,
+		},
 	)
 	if err != nil {
 		cmd.Fatal(errors.Wrap(err, "unable to create snapshot"))
@@ -141,7 +158,7 @@ func main() {
 		}
 	}
 	start = time.Now()
-	newSnapshot, newPreservesExecutability, newDecomposesUnicode, newCache, newIgnoreCache, err := core.Scan(
+	newSnapshot, newPreservesExecutability, newDecomposesUnicode, newCache, newIgnoreCache, _, err := core.Scan(
 		ctx,
 		path,
 		nil,
@@ -152,6 +169,11 @@ func main() {
 		ignoreCache,
 		behavior.ProbeMode_ProbeModeProbe,
 		core.SymlinkMode_SymlinkModePortable,
+		&core.ScanOptions{
+			BrokenSymlinkMode: core.BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           core.ACLMode_ACLModeIgnore,
+			DigestHashers:     digestHashers,
+		},
 	)
 	if err != nil {
 		cmd.Fatal(errors.Wrap(err, "unable to create snapshot"))
@@ -198,7 +220,7 @@ func main() {
 		}
 	}
 	start = time.Now()
-	newSnapshot, newPreservesExecutability, newDecomposesUnicode, newCache, newIgnoreCache, err = core.Scan(
+	newSnapshot, newPreservesExecutability, newDecomposesUnicode, newCache, newIgnoreCache, _, err = core.Scan(
 		ctx,
 		path,
 		snapshot,
@@ -209,6 +231,11 @@ func main() {
 		ignoreCache,
 		behavior.ProbeMode_ProbeModeProbe,
 		core.SymlinkMode_SymlinkModePortable,
+		&core.ScanOptions{
+			BrokenSymlinkMode: core.BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           core.ACLMode_ACLModeIgnore,
+			DigestHashers:     digestHashers,
+		},
 	)
 	if err != nil {
 		cmd.Fatal(errors.Wrap(err, "unable to create snapshot"))
@@ -253,7 +280,7 @@ func main() {
 		}
 	}
 	start = time.Now()
-	newSnapshot, newPreservesExecutability, newDecomposesUnicode, newCache, newIgnoreCache, err = core.Scan(
+	newSnapshot, newPreservesExecutability, newDecomposesUnicode, newCache, newIgnoreCache, _, err = core.Scan(
 		ctx,
 		path,
 		snapshot,
@@ -264,6 +291,11 @@ func main() {
 		ignoreCache,
 		behavior.ProbeMode_ProbeModeProbe,
 		core.SymlinkMode_SymlinkModePortable,
+		&core.ScanOptions{
+			BrokenSymlinkMode: core.BrokenSymlinkMode_BrokenSymlinkModeSync,
+			ACLMode:           core.ACLMode_ACLModeIgnore,
+			DigestHashers:     digestHashers,
+		},
 	)
 	if err != nil {
 		cmd.Fatal(errors.Wrap(err, "unable to create snapshot"))